@@ -0,0 +1,45 @@
+// Xupu gRPC 客户端：对生成的服务描述加上 gRPC 传输和认证
+import { createClient, type Client, type Interceptor } from "@connectrpc/connect";
+import { createGrpcTransport } from "@connectrpc/connect-node";
+
+import { BlueprintService } from "./gen/xupu/v1/blueprint_pb.js";
+import { ProjectService } from "./gen/xupu/v1/project_pb.js";
+import { TaskService } from "./gen/xupu/v1/task_pb.js";
+import { WorldService } from "./gen/xupu/v1/world_pb.js";
+
+export * from "./gen/xupu/v1/blueprint_pb.js";
+export * from "./gen/xupu/v1/project_pb.js";
+export * from "./gen/xupu/v1/task_pb.js";
+export * from "./gen/xupu/v1/world_pb.js";
+
+export interface XupuClientOptions {
+  /** gRPC 服务地址，如 http://localhost:9090 */
+  baseUrl: string;
+  /** 登录获得的 JWT，随每个请求放在 authorization 元数据中 */
+  token: string;
+}
+
+export interface XupuClient {
+  projects: Client<typeof ProjectService>;
+  worlds: Client<typeof WorldService>;
+  blueprints: Client<typeof BlueprintService>;
+  tasks: Client<typeof TaskService>;
+}
+
+/** 创建全部服务的客户端，共用一个 HTTP/2 连接 */
+export function createXupuClient(options: XupuClientOptions): XupuClient {
+  const auth: Interceptor = (next) => (req) => {
+    req.header.set("authorization", `Bearer ${options.token}`);
+    return next(req);
+  };
+  const transport = createGrpcTransport({
+    baseUrl: options.baseUrl,
+    interceptors: [auth],
+  });
+  return {
+    projects: createClient(ProjectService, transport),
+    worlds: createClient(WorldService, transport),
+    blueprints: createClient(BlueprintService, transport),
+    tasks: createClient(TaskService, transport),
+  };
+}
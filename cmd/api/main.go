@@ -3,31 +3,50 @@ package main
 
 import (
 	"context"
+	"flag"
+	"fmt"
+	"io/fs"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 	"time"
 
-	"github.com/gin-gonic/gin"
 	"github.com/xlei/xupu/internal/api"
 	"github.com/xlei/xupu/internal/handlers"
 	"github.com/xlei/xupu/internal/middleware"
+	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/config"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/examples"
 	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/logx"
 	"github.com/xlei/xupu/pkg/orchestrator"
 	"github.com/xlei/xupu/pkg/worldbuilder"
+	"github.com/xlei/xupu/static"
 )
 
-// 静态文件将在运行时从文件系统加载
-// TODO: 调整目录结构后使用embed
-// //go:embed static
-// var staticFiles embed.FS
+// version 发布版本号，由 make release 通过 -ldflags 注入
+var version = "dev"
 
+// @title Xupu API
+// @version 1.0
+// @description Xupu AI小说创作系统 REST API。除登录注册等公开接口外，请求需携带 Authorization: Bearer <token>
+// @BasePath /
+// @securityDefinitions.apikey Bearer
+// @in header
+// @name Authorization
+// @description 登录返回的访问令牌或 API Key，格式为 "Bearer <token>"
 func main() {
-	// 初始化数据库（首次调用会自动初始化）
+	skipMigrate := flag.Bool("skip-migrate", false, "启动时跳过数据库迁移")
+	staticDir := flag.String("static-dir", "", "从磁盘目录加载前端资源（开发用，修改后无需重新编译），默认使用内置资源")
+	flag.Parse()
+	db.SetSkipMigrate(*skipMigrate)
+
+	// 初始化数据库（首次调用会自动初始化并执行迁移）
 	_ = db.Get()
 
 	// 初始化全局调度器
@@ -47,6 +66,10 @@ func main() {
 	if err != nil {
 		log.Fatalf("Failed to load config: %v", err)
 	}
+	logx.Init(cfg.System.Logging)
+
+	// 少样本示例库：按 llm.examples 配置向带角色的LLM调用注入示例
+	examples.Install(db.Get())
 
 	// 初始化 LLM 客户端（用于叙事引擎）
 	llmClient, _, err := llm.NewClientForModule("narrative_engine")
@@ -74,53 +97,109 @@ func main() {
 	worldHandler := handlers.NewWorldHandler(nil)
 	narrativeHandler := handlers.NewNarrativeHandler(nil)
 	exportHandler := handlers.NewExportHandler()
-	authHandler := handlers.NewAuthHandler(getEnv("JWT_SECRET", "your-secret-key-change-in-production"))
+	jwtSecret := getEnv("JWT_SECRET", "your-secret-key-change-in-production")
+	authHandler := handlers.NewAuthHandler(jwtSecret)
 	chapterHandler := handlers.NewChapterHandler()
 	narrativeNodeHandler := handlers.NewNarrativeNodeHandler(db.Get(), llmClient, cfg)
 	worldSettingHandler := handlers.NewWorldSettingHandler(db.Get(), worldBuilder)
 	characterHandler := handlers.NewCharacterHandler(db.Get())
 	synopsisHandler := handlers.NewSynopsisHandler(db.Get())
 	writerHandler := handlers.NewWriterHandler(db.Get())
-	externalRankHandler := handlers.NewExternalRankHandler()
+	externalRankHandler := handlers.NewExternalRankHandler(db.Get())
 	adminHandler := handlers.NewAdminHandler(db.Get())
 
 	// 注册路由
 	server.RegisterRoutes(projectHandler, worldHandler, narrativeHandler, exportHandler, authHandler, chapterHandler, narrativeNodeHandler, worldSettingHandler, characterHandler, synopsisHandler, writerHandler, externalRankHandler, adminHandler)
 
-	// 配置静态文件服务
-	server.Engine().Static("/static", "./static")
-	// Allow accessing the test page from root for convenience
-	server.Engine().StaticFile("/fanqie_test.html", "./static/fanqie_test.html")
-
-	// Explicitly handle root path to serve index.html
-	server.Engine().GET("/", func(c *gin.Context) {
-		c.File("./static/index.html")
-	})
-
-	// SPA路由支持 - 所有未匹配的路由返回index.html
-	server.Engine().NoRoute(func(c *gin.Context) {
-		// API路由返回404
-		if len(c.Request.URL.Path) >= 4 && c.Request.URL.Path[:4] == "/api" {
-			c.JSON(http.StatusNotFound, gin.H{"error": "API endpoint not found"})
-			return
-		}
-		// 其他路由返回index.html（SPA）
-		c.File("./static/index.html")
-	})
+	// 配置静态文件服务：默认使用编译进二进制的前端资源，上传文件始终从磁盘读取
+	var assets fs.FS = static.FS
+	assetSource := "embedded"
+	if *staticDir != "" {
+		assets = os.DirFS(*staticDir)
+		assetSource = *staticDir
+	}
+	server.ServeStatic(assets, os.DirFS(handlers.UploadDir))
 
 	// 获取配置
 	port := getEnv("PORT", "80")
 	addr := ":" + port
 
 	// 启动服务器
-	log.Printf("Starting Xupu API server on %s (WITH ADMIN SUPPORT)", addr)
-	log.Printf("Static files served from ./static")
+	log.Printf("Starting Xupu API server %s on %s (WITH ADMIN SUPPORT)", version, addr)
+	log.Printf("Static files served from %s, uploads from %s", assetSource, handlers.UploadDir)
 
 	srv := &http.Server{
 		Addr:    addr,
 		Handler: server.Engine(),
 	}
 
+	// 定期快照排行榜，用于趋势分析
+	snapshotCtx, stopSnapshots := context.WithCancel(context.Background())
+	defer stopSnapshots()
+	if interval := getEnv("RANK_SNAPSHOT_INTERVAL", ""); interval != "" {
+		d, err := time.ParseDuration(interval)
+		if err != nil || d <= 0 {
+			log.Fatalf("Invalid RANK_SNAPSHOT_INTERVAL %q: %v", interval, err)
+		}
+		categories := strings.Split(getEnv("RANK_SNAPSHOT_CATEGORIES", "15"), ",")
+		go externalRankHandler.Snapshotter(categories).Run(snapshotCtx, d)
+		log.Printf("Rank snapshots enabled every %s for categories %v", d, categories)
+	}
+
+	// 定时任务：排行榜快照依赖外部榜单抓取，在这里注册
+	orchestrator.RegisterCronKind("rank_snapshot", "抓取外部排行榜并保存快照，用于趋势分析",
+		`{"categories": ["分类ID，默认取 RANK_SNAPSHOT_CATEGORIES"]}`,
+		func(ctx context.Context, job *models.CronJob) (string, error) {
+			params := struct {
+				Categories []string `json:"categories"`
+			}{Categories: strings.Split(getEnv("RANK_SNAPSHOT_CATEGORIES", "15"), ",")}
+			if err := orchestrator.DecodeCronParams(job, &params); err != nil {
+				return "", err
+			}
+			snapshots, err := externalRankHandler.Snapshotter(params.Categories).SnapshotOnce()
+			if err != nil {
+				return "", err
+			}
+			return fmt.Sprintf("保存了 %d 个分类的榜单快照", len(snapshots)), nil
+		})
+	if err := orchestrator.StartCron(db.Get()); err != nil {
+		log.Printf("Cron jobs disabled: %v", err)
+	}
+	defer orchestrator.StopCron()
+
+	// 全站LLM用量按日落库，供管理后台统计每日token消耗
+	llm.OnUsage(func(provider, model string, u llm.Usage) {
+		day := &models.LLMUsageDay{
+			Date:         time.Now().Format("2006-01-02"),
+			Provider:     provider,
+			Model:        model,
+			Requests:     u.Requests,
+			InputTokens:  u.InputTokens,
+			OutputTokens: u.OutputTokens,
+			TotalTokens:  u.TotalTokens,
+			Cost:         u.Cost,
+		}
+		go func() {
+			if err := db.Get().AddLLMUsage(day); err != nil {
+				logx.L().Debug("记录LLM用量失败", "error", err)
+			}
+		}()
+	})
+
+	// SIGHUP 热加载配置（LLM 模块映射、限流和重试配置）
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	go func() {
+		for range hup {
+			result, err := config.Reload()
+			if err != nil {
+				logx.L().Error("配置热加载失败，保持当前配置", "error", err)
+				continue
+			}
+			logx.L().Info("配置已热加载", "path", result.Path, "generation", result.Generation, "changes", result.Changes)
+		}
+	}()
+
 	// 启动goroutine
 	go func() {
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
@@ -128,6 +207,20 @@ func main() {
 		}
 	}()
 
+	// gRPC 服务：项目、世界、蓝图和生成任务，与 REST 共用处理逻辑和 JWT 认证
+	grpcAddr := ":" + getEnv("GRPC_PORT", "9090")
+	grpcServer := handlers.NewGRPCServer(orc, jwtSecret)
+	grpcListener, err := net.Listen("tcp", grpcAddr)
+	if err != nil {
+		log.Fatalf("Failed to listen for gRPC on %s: %v", grpcAddr, err)
+	}
+	log.Printf("Starting gRPC server on %s", grpcAddr)
+	go func() {
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			log.Fatalf("Failed to start gRPC server: %v", err)
+		}
+	}()
+
 	// 等待中断信号优雅关闭
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -138,6 +231,18 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
+	// gRPC 等待进行中的调用结束，WatchTask 等长连接超时后强制关闭
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
+	select {
+	case <-grpcStopped:
+	case <-ctx.Done():
+		grpcServer.Stop()
+	}
+
 	if err := srv.Shutdown(ctx); err != nil {
 		log.Fatalf("Server forced to shutdown: %v", err)
 	}
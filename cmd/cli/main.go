@@ -3,29 +3,27 @@ package main
 
 import (
 	"fmt"
+	"log/slog"
 	"os"
 
 	"github.com/fatih/color"
 	"github.com/spf13/cobra"
 	"github.com/xlei/xupu/internal/cli"
+	"github.com/xlei/xupu/pkg/config"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/logx"
 	"github.com/xlei/xupu/pkg/orchestrator"
 )
 
 var (
-	cfgFile string
-	verbose bool
+	cfgFile     string
+	verbose     bool
+	skipMigrate bool
+	dryRun      bool
 )
 
 func main() {
-	// 初始化数据库
-	_ = db.Get()
-
-	// 初始化全局调度器
-	if err := orchestrator.InitScheduler(); err != nil {
-		color.Red("初始化调度器失败: %v", err)
-		os.Exit(1)
-	}
 	defer orchestrator.StopScheduler()
 
 	var rootCmd = &cobra.Command{
@@ -34,18 +32,50 @@ func main() {
 		Long: `Xupu - AI驱动的小说创作系统
 支持世界设定构建、叙事规划、场景生成等完整创作流程。`,
 		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			// 初始化日志：级别、格式和提示词脱敏按配置，--verbose 时输出 debug 日志（含提示词）
+			if cfg, err := config.LoadDefault(); err == nil {
+				logx.Init(cfg.System.Logging)
+			}
+			if verbose {
+				logx.SetLevel(slog.LevelDebug)
+			}
+
+			// 演练模式：所有LLM调用使用本地模拟响应
+			if dryRun {
+				llm.SetDryRun(true)
+				color.Yellow("演练模式：使用模拟LLM，不会调用真实API")
+			}
+
+			// 初始化数据库（需在解析 --skip-migrate 之后）
+			db.SetSkipMigrate(skipMigrate)
+			_ = db.Get()
+
+			// 初始化全局调度器
+			if err := orchestrator.InitScheduler(); err != nil {
+				return fmt.Errorf("初始化调度器失败: %w", err)
+			}
+			return nil
+		},
 	}
 
 	// 全局标志
 	rootCmd.PersistentFlags().StringVar(&cfgFile, "config", "", "配置文件路径")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "详细输出")
+	rootCmd.PersistentFlags().BoolVar(&skipMigrate, "skip-migrate", false, "启动时跳过数据库迁移")
+	rootCmd.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "演练模式：使用确定性的模拟LLM响应，不产生API费用")
 
 	// 添加子命令
+	rootCmd.AddCommand(cli.NewInitCommand())
 	rootCmd.AddCommand(cli.NewProjectCommand())
 	rootCmd.AddCommand(cli.NewWorldCommand())
 	rootCmd.AddCommand(cli.NewBlueprintCommand())
+	rootCmd.AddCommand(cli.NewChapterCommand())
 	rootCmd.AddCommand(cli.NewGenerateCommand())
 	rootCmd.AddCommand(cli.NewExportCommand())
+	rootCmd.AddCommand(cli.NewStatusCommand())
+	rootCmd.AddCommand(cli.NewTemplateCommand())
+	rootCmd.AddCommand(cli.NewDevCommand())
 	rootCmd.AddCommand(cli.NewConfigCommand())
 	rootCmd.AddCommand(cli.NewVersionCommand())
 
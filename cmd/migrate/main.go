@@ -1,46 +1,50 @@
+// Package main 数据库迁移工具
+// API和CLI启动时会自动迁移，本工具用于手动执行迁移或查看迁移状态
 package main
 
 import (
+	"flag"
+	"fmt"
 	"log"
-	"time"
 
-	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/db"
+	"gorm.io/gorm"
 )
 
 func main() {
-	log.Println("Starting migration tool...")
-
-	// Force wait for DB
-	time.Sleep(1 * time.Second)
+	status := flag.Bool("status", false, "只显示迁移状态，不执行迁移")
+	flag.Parse()
 
+	// 由本工具显式执行迁移
+	db.SetSkipMigrate(true)
 	database := db.Get()
-	pg, ok := database.(*db.PostgresDatabase)
+	gdb, ok := database.(interface{ GetDB() *gorm.DB })
 	if !ok {
-		log.Fatal("Not using Postgres database")
+		log.Fatal("Not using a GORM-backed database")
 	}
+	gormDB := gdb.GetDB()
 
-	gormDB := pg.GetDB()
-
-	log.Println("Migrating PromptTemplate...")
-	if err := gormDB.AutoMigrate(&models.PromptTemplate{}); err != nil {
-		log.Fatalf("Failed to migrate PromptTemplate: %v", err)
+	if !*status {
+		log.Println("Running migrations...")
+		if err := db.RunMigrations(gormDB); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
 	}
 
-	log.Println("Migrating NarrativeTemplate...")
-	if err := gormDB.AutoMigrate(&models.NarrativeTemplate{}); err != nil {
-		log.Fatalf("Failed to migrate NarrativeTemplate: %v", err)
+	applied, err := db.AppliedMigrations(gormDB)
+	if err != nil {
+		log.Fatalf("Failed to read schema_migrations: %v", err)
 	}
-
-	log.Println("Migrating SysConfig...")
-	if err := gormDB.AutoMigrate(&models.SysConfig{}); err != nil {
-		log.Fatalf("Failed to migrate SysConfig: %v", err)
+	done := make(map[int]db.SchemaMigration, len(applied))
+	for _, m := range applied {
+		done[m.Version] = m
 	}
 
-	// Check if table exists
-	if !gormDB.Migrator().HasTable(&models.PromptTemplate{}) {
-		log.Fatal("Assertion failed: Table prompt_templates does not exist after migration!")
+	for _, m := range db.Migrations() {
+		if a, ok := done[m.Version]; ok {
+			fmt.Printf("  [x] %04d %s (%s)\n", m.Version, m.Description, a.AppliedAt.Format("2006-01-02 15:04:05"))
+		} else {
+			fmt.Printf("  [ ] %04d %s\n", m.Version, m.Description)
+		}
 	}
-
-	log.Println("Migration completed successfully!")
 }
@@ -2,10 +2,9 @@
 package api
 
 import (
-	"fmt"
-
 	"github.com/gin-gonic/gin"
 	"github.com/xlei/xupu/internal/handlers"
+	"github.com/xlei/xupu/internal/openapi"
 	"github.com/xlei/xupu/pkg/db"
 	"github.com/xlei/xupu/pkg/orchestrator"
 )
@@ -56,18 +55,56 @@ func (s *Server) RegisterRoutes(
 ) {
 	// 同时创建任务处理器
 	taskHandler := handlers.NewTaskHandler()
-
-	fmt.Println("DEBUG: Registering Routes...")
+	pacingHandler := handlers.NewPacingHandler(db.Get())
+	styleProfileHandler := handlers.NewStyleProfileHandler(db.Get())
+	timelineHandler := handlers.NewTimelineHandler(db.Get())
+	povHandler := handlers.NewPOVHandler(db.Get())
+	releaseHandler := handlers.NewReleaseHandler(db.Get())
+	competitorHandler := handlers.NewCompetitorHandler(db.Get())
+	tropeHandler := handlers.NewTropeHandler(db.Get())
+	complianceHandler := handlers.NewComplianceHandler(db.Get())
+	originalityHandler := handlers.NewOriginalityHandler(db.Get())
+	glossaryHandler := handlers.NewGlossaryHandler(db.Get())
+	translationHandler := handlers.NewTranslationHandler(db.Get())
+	branchHandler := handlers.NewBranchHandler(db.Get())
+	endingHandler := handlers.NewEndingHandler(db.Get())
+	searchHandler := handlers.NewSearchHandler(db.Get())
+	mentionHandler := handlers.NewMentionHandler(db.Get())
+	continuityHandler := handlers.NewContinuityHandler(db.Get())
+	manuscriptHandler := handlers.NewManuscriptHandler(db.Get())
+	vitalsHandler := handlers.NewVitalsHandler(db.Get())
+	itemHandler := handlers.NewItemHandler(db.Get())
+	cultivationHandler := handlers.NewCultivationHandler(db.Get())
+	combatHandler := handlers.NewCombatHandler(db.Get())
+	romanceHandler := handlers.NewRomanceHandler(db.Get())
+	emotionHandler := handlers.NewEmotionHandler(db.Get())
+	styleMetricsHandler := handlers.NewStyleMetricsHandler(db.Get())
+	progressHandler := handlers.NewProgressHandler(db.Get())
+	themeHandler := handlers.NewThemeHandler(db.Get())
+	beatSheetHandler := handlers.NewBeatSheetHandler(db.Get())
+	batchHandler := handlers.NewBatchHandler()
+	webhookHandler := handlers.NewWebhookHandler(db.Get())
+	notificationHandler := handlers.NewNotificationHandler(db.Get())
+	quotaHandler := handlers.NewQuotaHandler(db.Get())
+	apiKeyHandler := handlers.NewAPIKeyHandler(db.Get())
+	trashHandler := handlers.NewTrashHandler(db.Get())
+	healthHandler := handlers.NewHealthHandler(db.Get())
 
 	// 健康检查
 	s.engine.GET("/health", func(c *gin.Context) {
-		fmt.Println("DEBUG: Health check hit")
 		c.JSON(200, gin.H{
 			"status":  "ok",
 			"service": "xupu-api",
 		})
 	})
 
+	// Kubernetes 存活/就绪探针
+	s.engine.GET("/healthz", healthHandler.Healthz)
+	s.engine.GET("/readyz", healthHandler.Readyz)
+
+	// OpenAPI 文档与 Swagger UI
+	openapi.Register(s.engine)
+
 	// API v1
 	v1 := s.engine.Group("/api/v1")
 	{
@@ -95,14 +132,19 @@ func (s *Server) RegisterRoutes(
 		{
 			projects.POST("", projectHandler.CreateProject)
 			projects.POST("/import", projectHandler.ImportProject)
+			projects.POST("/import/manuscript", manuscriptHandler.ImportManuscript)
 			projects.GET("", projectHandler.ListProjects)
 			projects.GET("/:projectId", projectHandler.GetProject)
 			projects.DELETE("/:projectId", projectHandler.DeleteProject)
+			projects.PUT("/:projectId/word-count-targets", projectHandler.UpdateWordCountTargets)
+			projects.PUT("/:projectId/style-profile", projectHandler.UpdateStyleProfile)
+			projects.PUT("/:projectId/language", projectHandler.UpdateLanguage)
 			projects.POST("/:projectId/generate", projectHandler.GenerateChapter)
 			projects.POST("/:projectId/intervene", projectHandler.Intervene)
 			projects.POST("/:projectId/pause", projectHandler.PauseGeneration)
 			projects.POST("/:projectId/resume", projectHandler.ResumeGeneration)
 			projects.GET("/:projectId/progress", projectHandler.GetProgress)
+			projects.GET("/:projectId/warnings", projectHandler.GetWarnings)
 			projects.POST("/:projectId/blueprint/apply", narrativeHandler.ApplyBlueprint)
 
 			// 章节管理（使用 :projectId 作为项目ID）
@@ -135,9 +177,191 @@ func (s *Server) RegisterRoutes(
 			// 角色设定管理
 			projects.POST("/:projectId/characters/gacha", characterHandler.GachaCharacters)
 			projects.GET("/:projectId/characters", characterHandler.ListCharacters)
+			projects.GET("/:projectId/characters/sheets", characterHandler.ListCharacterSheets)
+			projects.POST("/:projectId/characters/sheets", characterHandler.CreateCharacterSheet)
+			projects.GET("/:projectId/characters/sheets/:sheetId", characterHandler.GetCharacterSheet)
+			projects.PATCH("/:projectId/characters/sheets/:sheetId", characterHandler.UpdateCharacterSheet)
+			projects.DELETE("/:projectId/characters/sheets/:sheetId", characterHandler.DeleteCharacterSheet)
 
 			// 简介设定管理
 			projects.POST("/:projectId/synopsis/gacha", synopsisHandler.GachaSynopsis)
+			projects.GET("/:projectId/marketing", synopsisHandler.GetMarketingCopy)
+			projects.PUT("/:projectId/marketing", synopsisHandler.UpdateMarketingCopy)
+			projects.POST("/:projectId/marketing/generate", synopsisHandler.GenerateMarketingCopy)
+
+			// 节奏分析
+			projects.GET("/:projectId/pacing", pacingHandler.GetPacingReport)
+			projects.GET("/:projectId/pacing/plan", pacingHandler.GetPlanPacing)
+
+			// 章节钩子评分
+			projects.GET("/:projectId/hooks", chapterHandler.ListChapterHooks)
+			projects.POST("/:projectId/titles/regenerate", chapterHandler.RegenerateChapterTitles)
+			projects.POST("/:projectId/duplicates/scan", chapterHandler.ScanProjectDuplicates)
+
+			// 故事时间线
+			projects.GET("/:projectId/timeline", timelineHandler.GetTimeline)
+			projects.PUT("/:projectId/timeline/scenes/:chapter/:scene", timelineHandler.UpdateSceneTime)
+			projects.POST("/:projectId/timeline/check", timelineHandler.CheckTimeline)
+
+			// 视角策略
+			projects.GET("/:projectId/pov-policy", povHandler.GetPOVPolicy)
+			projects.PUT("/:projectId/pov-policy", povHandler.UpdatePOVPolicy)
+
+			// 连载更新计划
+			projects.POST("/:projectId/release-plan", releaseHandler.GenerateReleasePlan)
+			projects.GET("/:projectId/release-plan", releaseHandler.GetReleasePlan)
+			projects.GET("/:projectId/release-plan/installments/:seq", releaseHandler.GetInstallmentText)
+			projects.PATCH("/:projectId/release-plan/installments/:seq", releaseHandler.UpdateInstallment)
+
+			// Webhook
+			projects.GET("/:projectId/webhooks", webhookHandler.ListWebhooks)
+			projects.POST("/:projectId/webhooks", webhookHandler.CreateWebhook)
+			projects.PUT("/:projectId/webhooks/:id", webhookHandler.UpdateWebhook)
+			projects.DELETE("/:projectId/webhooks/:id", webhookHandler.DeleteWebhook)
+			projects.POST("/:projectId/webhooks/:id/test", webhookHandler.TestWebhook)
+			projects.GET("/:projectId/webhooks/:id/deliveries", webhookHandler.ListWebhookDeliveries)
+			projects.POST("/:projectId/webhooks/:id/deliveries/:deliveryId/redeliver", webhookHandler.RedeliverWebhook)
+
+			// 竞品分析
+			projects.POST("/:projectId/competitors", competitorHandler.AnalyzeCompetitor)
+			projects.GET("/:projectId/competitors", competitorHandler.ListCompetitors)
+			projects.GET("/:projectId/competitors/:analysisId", competitorHandler.GetCompetitor)
+			projects.DELETE("/:projectId/competitors/:analysisId", competitorHandler.DeleteCompetitor)
+
+			// 套路检测
+			projects.PUT("/:projectId/trope-settings", tropeHandler.UpdateTropeSettings)
+			projects.POST("/:projectId/tropes/scan", tropeHandler.ScanTropes)
+
+			// 内容合规
+			projects.PUT("/:projectId/compliance-settings", complianceHandler.UpdateComplianceSettings)
+			projects.POST("/:projectId/compliance/scan", complianceHandler.ScanCompliance)
+			projects.POST("/:projectId/compliance/sanitize", complianceHandler.SanitizeCompliance)
+			projects.GET("/:projectId/references", originalityHandler.ListReferences)
+			projects.POST("/:projectId/references", originalityHandler.UploadReference)
+			projects.DELETE("/:projectId/references/:refId", originalityHandler.DeleteReference)
+			projects.PUT("/:projectId/originality-settings", originalityHandler.UpdateOriginalitySettings)
+			projects.POST("/:projectId/originality/scan", originalityHandler.ScanOriginality)
+
+			// 术语一致性
+			projects.POST("/:projectId/glossary/check", glossaryHandler.CheckGlossary)
+
+			// 章节翻译与双语对照
+			projects.PUT("/:projectId/translation-settings", translationHandler.UpdateTranslationSettings)
+			projects.POST("/:projectId/chapters/:chapterId/translate", translationHandler.TranslateChapter)
+			projects.GET("/:projectId/translations", translationHandler.ListTranslations)
+			projects.GET("/:projectId/export/bilingual", translationHandler.ExportBilingual)
+
+			// 假设分支
+			projects.POST("/:projectId/branches", branchHandler.CreateBranch)
+			projects.GET("/:projectId/branches", branchHandler.ListBranches)
+			projects.GET("/:projectId/branches/:branchId/compare", branchHandler.CompareBranch)
+			projects.POST("/:projectId/branches/:branchId/adopt", branchHandler.AdoptBranch)
+			projects.DELETE("/:projectId/branches/:branchId", branchHandler.DiscardBranch)
+
+			// 结局候选
+			projects.POST("/:projectId/endings", endingHandler.ProposeEndings)
+			projects.POST("/:projectId/endings/select", endingHandler.SelectEnding)
+
+			// 全文检索
+			projects.GET("/:projectId/search", searchHandler.SearchProject)
+			projects.GET("/:projectId/mentions", mentionHandler.ListMentions)
+			projects.POST("/:projectId/mentions/rebuild", mentionHandler.RebuildMentions)
+			projects.GET("/:projectId/manuscript-imports", manuscriptHandler.ListManuscriptImports)
+			projects.GET("/:projectId/continuity", continuityHandler.ListContinuityFacts)
+			projects.POST("/:projectId/continuity", continuityHandler.CreateContinuityFact)
+			projects.POST("/:projectId/continuity/check", continuityHandler.CheckContinuity)
+			projects.DELETE("/:projectId/continuity/:factId", continuityHandler.DeleteContinuityFact)
+			projects.GET("/:projectId/vitals", vitalsHandler.GetVitals)
+			projects.POST("/:projectId/vitals", vitalsHandler.CreateVitalEvent)
+			projects.POST("/:projectId/vitals/check", vitalsHandler.CheckVitals)
+			projects.DELETE("/:projectId/vitals/:eventId", vitalsHandler.DeleteVitalEvent)
+			projects.GET("/:projectId/items", itemHandler.ListItems)
+			projects.POST("/:projectId/items", itemHandler.CreateItem)
+			projects.POST("/:projectId/items/seed", itemHandler.SeedItems)
+			projects.PUT("/:projectId/items/:itemId", itemHandler.UpdateItem)
+			projects.DELETE("/:projectId/items/:itemId", itemHandler.DeleteItem)
+			projects.POST("/:projectId/items/:itemId/transfers", itemHandler.CreateItemTransfer)
+			projects.GET("/:projectId/cultivation", cultivationHandler.GetCultivation)
+			projects.POST("/:projectId/cultivation", cultivationHandler.CreateRealmEvent)
+			projects.POST("/:projectId/cultivation/check", cultivationHandler.CheckCultivation)
+			projects.DELETE("/:projectId/cultivation/:eventId", cultivationHandler.DeleteRealmEvent)
+			projects.POST("/:projectId/combat/:chapter/:scene", combatHandler.PlanCombat)
+			projects.PUT("/:projectId/combat/:chapter/:scene", combatHandler.UpdateCombat)
+			projects.DELETE("/:projectId/combat/:chapter/:scene", combatHandler.DeleteCombat)
+			projects.GET("/:projectId/romance", romanceHandler.ListRomanceArcs)
+			projects.POST("/:projectId/romance", romanceHandler.CreateRomanceArc)
+			projects.PUT("/:projectId/romance/:arcId", romanceHandler.UpdateRomanceArc)
+			projects.DELETE("/:projectId/romance/:arcId", romanceHandler.DeleteRomanceArc)
+			projects.GET("/:projectId/emotions/heatmap", emotionHandler.GetEmotionHeatmap)
+			projects.GET("/:projectId/style-metrics", styleMetricsHandler.GetStyleTrend)
+			projects.POST("/:projectId/style-metrics/analyze", styleMetricsHandler.AnalyzeStyleMetrics)
+			projects.GET("/:projectId/stats", progressHandler.GetProjectStats)
+			projects.GET("/:projectId/themes/coverage", themeHandler.GetThemeCoverage)
+			projects.POST("/:projectId/themes/analyze", themeHandler.AnalyzeThemeCoverage)
+		}
+
+		// 章节编辑锁（需要认证）
+		chapters := v1.Group("/chapters")
+		chapters.Use(authHandler.AuthMiddleware())
+		{
+			chapters.GET("/:id/lock", chapterHandler.GetChapterLock)
+			chapters.POST("/:id/lock", chapterHandler.LockChapter)
+			chapters.DELETE("/:id/lock", chapterHandler.UnlockChapter)
+			chapters.POST("/:id/scenes/:seq/regenerate", chapterHandler.RegenerateScene)
+			chapters.POST("/:id/write", chapterHandler.WriteChapter)
+			chapters.POST("/:id/duplicates", chapterHandler.ScanChapterDuplicates)
+			chapters.POST("/:id/duplicates/:index/rephrase", chapterHandler.RephraseDuplicate)
+			chapters.GET("/:id/suggestions", chapterHandler.ListEditSuggestions)
+			chapters.POST("/:id/suggestions", chapterHandler.CreateEditSuggestion)
+			chapters.GET("/:id/suggestions/:sid", chapterHandler.GetEditSuggestion)
+			chapters.POST("/:id/suggestions/:sid/resolve", chapterHandler.ResolveEditSuggestion)
+			chapters.GET("/:id/paragraphs", chapterHandler.ListParagraphs)
+			chapters.GET("/:id/annotations", chapterHandler.ListAnnotations)
+			chapters.POST("/:id/annotations", chapterHandler.CreateAnnotation)
+			chapters.PUT("/:id/annotations/:aid", chapterHandler.UpdateAnnotation)
+			chapters.DELETE("/:id/annotations/:aid", chapterHandler.DeleteAnnotation)
+			chapters.POST("/:id/annotations/:aid/replies", chapterHandler.ReplyAnnotation)
+			chapters.POST("/:id/annotations/:aid/resolve", chapterHandler.ResolveAnnotation)
+			chapters.POST("/:id/annotations/:aid/suggest", chapterHandler.SuggestFromAnnotation)
+			chapters.POST("/:id/voice-check", chapterHandler.CheckChapterVoice)
+			chapters.POST("/:id/hook-score", chapterHandler.ScoreChapterHooks)
+			chapters.GET("/:id/screenplay", chapterHandler.ExportScreenplay)
+			chapters.POST("/:id/titles", chapterHandler.GenerateChapterTitles)
+		}
+
+		// 角色（需要认证）
+		characters := v1.Group("/characters")
+		characters.Use(authHandler.AuthMiddleware())
+		{
+			characters.GET("/:id/portrait-prompt", characterHandler.GetPortraitPrompt)
+		}
+
+		// 套路库
+		v1.GET("/tropes", tropeHandler.ListTropes)
+
+		// 合规策略集
+		v1.GET("/compliance/policies", complianceHandler.ListPolicies)
+
+		// 写作风格档案（需要认证）
+		styleProfiles := v1.Group("/style-profiles")
+		styleProfiles.Use(authHandler.AuthMiddleware())
+		{
+			styleProfiles.GET("", styleProfileHandler.ListStyleProfiles)
+			styleProfiles.GET("/:id", styleProfileHandler.GetStyleProfile)
+			styleProfiles.POST("", styleProfileHandler.CreateStyleProfile)
+			styleProfiles.PUT("/:id", styleProfileHandler.UpdateStyleProfile)
+			styleProfiles.DELETE("/:id", styleProfileHandler.DeleteStyleProfile)
+		}
+
+		// 自定义节拍表（需要认证）
+		beatSheets := v1.Group("/beat-sheets")
+		beatSheets.Use(authHandler.AuthMiddleware())
+		{
+			beatSheets.GET("", beatSheetHandler.ListBeatSheets)
+			beatSheets.GET("/:id", beatSheetHandler.GetBeatSheet)
+			beatSheets.POST("", beatSheetHandler.CreateBeatSheet)
+			beatSheets.PUT("/:id", beatSheetHandler.UpdateBeatSheet)
+			beatSheets.DELETE("/:id", beatSheetHandler.DeleteBeatSheet)
 		}
 
 		// 世界设定
@@ -146,15 +370,35 @@ func (s *Server) RegisterRoutes(
 			worlds.POST("", worldHandler.CreateWorld)
 			worlds.GET("", worldHandler.ListWorlds)
 			worlds.GET("/:id", worldHandler.GetWorld)
+			worlds.GET("/:id/entities", worldHandler.ListWorldEntities)
+			worlds.GET("/:id/entities/:name", worldHandler.GetWorldEntity)
+			worlds.GET("/:id/map", worldHandler.GetWorldMap)
+			worlds.GET("/:id/glossary", worldHandler.GetGlossary)
+			worlds.GET("/:id/minor-characters", worldHandler.ListMinorCharacters)
 			worlds.DELETE("/:id", worldHandler.DeleteWorld)
 		}
 
+		// 世界设定（需要认证）：会调用 LLM 或导出完整设定的接口只对使用该世界的项目所有者开放
+		ownedWorlds := v1.Group("/worlds")
+		ownedWorlds.Use(authHandler.AuthMiddleware())
+		{
+			ownedWorlds.POST("/import", worldHandler.ImportWorld)
+			ownedWorlds.GET("/:id/export", worldHandler.ExportWorld)
+			ownedWorlds.POST("/:id/deepen", worldHandler.DeepenWorld)
+			ownedWorlds.POST("/:id/religions/organize", worldHandler.OrganizeReligions)
+			ownedWorlds.PUT("/:id/glossary", worldHandler.UpdateGlossary)
+			ownedWorlds.POST("/:id/minor-characters/generate", worldHandler.GenerateMinorPool)
+			ownedWorlds.POST("/:id/minor-characters/:name/promote", worldHandler.PromoteMinorCharacter)
+		}
+
 		// 叙事蓝图
 		blueprints := v1.Group("/blueprints")
 		{
 			blueprints.POST("", narrativeHandler.CreateBlueprint)
 			blueprints.GET("/:id", narrativeHandler.GetBlueprint)
 			blueprints.GET("/:id/export", narrativeHandler.ExportBlueprint)
+			blueprints.GET("/:id/diff/:otherID", narrativeHandler.DiffBlueprint)
+			blueprints.GET("/:id/relationship-graph", narrativeHandler.GetRelationshipGraph)
 		}
 
 		// 导出
@@ -165,10 +409,17 @@ func (s *Server) RegisterRoutes(
 			export.GET("/blueprint/:id", exportHandler.ExportBlueprint)
 		}
 
+		// 导出（需要认证）：故事圣经和有声书只对项目所有者开放
+		ownedExport := v1.Group("/export")
+		ownedExport.Use(authHandler.AuthMiddleware())
+		{
+			ownedExport.GET("/project/:id/bible", exportHandler.ExportBible)
+			ownedExport.GET("/project/:id/audiobook", exportHandler.ExportAudiobook)
+		}
+
 		// 异步任务
 		tasks := v1.Group("/tasks")
 		{
-			tasks.POST("/project", taskHandler.CreateAsyncProject)
 			tasks.GET("/:id", taskHandler.GetTaskStatus)
 			tasks.POST("/:id/cancel", taskHandler.CancelTask)
 			tasks.POST("/:id/pause", taskHandler.PauseTask)
@@ -177,11 +428,69 @@ func (s *Server) RegisterRoutes(
 			tasks.GET("/:id/wait", taskHandler.WaitForTask)
 		}
 
+		// 异步任务（需要认证）：任务记录提交者，日志只对提交者开放
+		ownedTasks := v1.Group("/tasks")
+		ownedTasks.Use(authHandler.AuthMiddleware())
+		{
+			ownedTasks.POST("/project", taskHandler.CreateAsyncProject)
+			ownedTasks.GET("/:id/logs", taskHandler.GetTaskLogs)
+		}
+
+		// 批量创作（需要认证）
+		batches := v1.Group("/batches")
+		batches.Use(authHandler.AuthMiddleware())
+		{
+			batches.POST("", batchHandler.CreateBatch)
+			batches.GET("", batchHandler.ListBatches)
+			batches.GET("/:id", batchHandler.GetBatch)
+			batches.POST("/:id/cancel", batchHandler.CancelBatch)
+		}
+
+		// 通知渠道（需要认证）
+		notifications := v1.Group("/notifications")
+		notifications.Use(authHandler.AuthMiddleware())
+		{
+			notifications.GET("/events", notificationHandler.GetNotificationEvents)
+			notifications.GET("/channels", notificationHandler.ListNotificationChannels)
+			notifications.POST("/channels", notificationHandler.CreateNotificationChannel)
+			notifications.PUT("/channels/:id", notificationHandler.UpdateNotificationChannel)
+			notifications.DELETE("/channels/:id", notificationHandler.DeleteNotificationChannel)
+			notifications.POST("/channels/:id/test", notificationHandler.TestNotificationChannel)
+		}
+
+		// API密钥（需要认证）
+		apiKeys := v1.Group("/api-keys")
+		apiKeys.Use(authHandler.AuthMiddleware())
+		{
+			apiKeys.GET("", apiKeyHandler.ListAPIKeys)
+			apiKeys.POST("", apiKeyHandler.CreateAPIKey)
+			apiKeys.DELETE("/:id", apiKeyHandler.RevokeAPIKey)
+			apiKeys.GET("/:id/audits", apiKeyHandler.ListAPIKeyAudits)
+		}
+
+		// 用户额度（需要认证）
+		quotas := v1.Group("/quota")
+		quotas.Use(authHandler.AuthMiddleware())
+		{
+			quotas.GET("", quotaHandler.GetMyQuota)
+		}
+
+		// 回收站（需要认证）
+		trash := v1.Group("/trash")
+		trash.Use(authHandler.AuthMiddleware())
+		{
+			trash.GET("", trashHandler.ListTrash)
+			trash.POST("/:kind/:id/restore", trashHandler.RestoreTrash)
+		}
+
 		// 外部数据源
 		external := v1.Group("/external")
 		{
 			// 排行榜
 			external.GET("/ranks/fanqie", externalRankHandler.GetFanqieRank)
+			external.POST("/ranks/fanqie/snapshots", authHandler.AuthMiddleware(), authHandler.AdminMiddleware(), externalRankHandler.SnapshotFanqieRank)
+			external.GET("/ranks/trends", externalRankHandler.GetRankTrends)
+			external.GET("/ranks/recommendations", externalRankHandler.GetRankRecommendations)
 
 			// 番茄小说详细API
 			fanqie := external.Group("/fanqie")
@@ -194,12 +503,13 @@ func (s *Server) RegisterRoutes(
 
 		// 管理后台
 		admin := v1.Group("/admin")
-		// admin.Use(authHandler.AdminMiddleware()) // TODO: 添加管理员认证
+		admin.Use(authHandler.AuthMiddleware(), authHandler.AdminMiddleware())
 		{
 			// 系统配置
 			admin.GET("/configs", adminHandler.GetConfigs)
 			admin.PUT("/configs/:key", adminHandler.UpdateConfig)
 			admin.POST("/configs/sync", adminHandler.SyncConfigs)
+			admin.POST("/config/reload", adminHandler.ReloadConfig)
 
 			// 提示词管理
 			admin.GET("/prompts", adminHandler.GetPrompts)
@@ -211,6 +521,57 @@ func (s *Server) RegisterRoutes(
 			admin.GET("/structures", adminHandler.GetStructures)
 			admin.PUT("/structures/:id", adminHandler.UpdateStructure)
 			admin.POST("/structures/sync", adminHandler.SyncStructures)
+
+			// 模板包导入导出
+			admin.GET("/template-packs/export", adminHandler.ExportTemplatePack)
+			admin.POST("/template-packs/import", adminHandler.ImportTemplatePack)
+
+			// 定时任务
+			admin.GET("/cron-kinds", adminHandler.GetCronKinds)
+			admin.GET("/cron-jobs", adminHandler.GetCronJobs)
+			admin.POST("/cron-jobs", adminHandler.CreateCronJob)
+			admin.GET("/cron-jobs/:id", adminHandler.GetCronJob)
+			admin.PUT("/cron-jobs/:id", adminHandler.UpdateCronJob)
+			admin.DELETE("/cron-jobs/:id", adminHandler.DeleteCronJob)
+			admin.POST("/cron-jobs/:id/run", adminHandler.RunCronJob)
+			admin.GET("/cron-jobs/:id/runs", adminHandler.GetCronRuns)
+
+			// 提示词实验
+			admin.GET("/experiments", adminHandler.GetExperiments)
+			admin.GET("/experiments/:id", adminHandler.GetExperiment)
+			admin.POST("/experiments", adminHandler.CreateExperiment)
+
+			// 少样本示例库
+			admin.GET("/examples", adminHandler.GetPromptExamples)
+			admin.POST("/examples", adminHandler.CreatePromptExample)
+			admin.GET("/examples/settings", adminHandler.GetExampleSettings)
+			admin.PUT("/examples/settings", adminHandler.UpdateExampleSettings)
+			admin.GET("/examples/stats", adminHandler.GetExampleStats)
+			admin.DELETE("/examples/stats", adminHandler.ResetExampleStats)
+			admin.GET("/examples/:id", adminHandler.GetPromptExample)
+			admin.PUT("/examples/:id", adminHandler.UpdatePromptExample)
+			admin.DELETE("/examples/:id", adminHandler.DeletePromptExample)
+
+			// LLM响应结构校验统计
+			admin.GET("/llm/schema-metrics", adminHandler.GetSchemaMetrics)
+
+			// 系统统计（运维看板）
+			admin.GET("/stats/overview", adminHandler.GetStatsOverview)
+			admin.GET("/stats/tokens", adminHandler.GetTokenUsage)
+			admin.GET("/stats/failing-roles", adminHandler.GetFailingRoles)
+			admin.GET("/stats/slow-calls", adminHandler.GetSlowestCalls)
+			admin.GET("/stats/storage", adminHandler.GetStorageUsage)
+
+			// 用户预算
+			admin.GET("/quotas/:userId", adminHandler.GetUserQuota)
+			admin.PUT("/quotas/:userId", adminHandler.UpdateUserQuota)
+			admin.DELETE("/quotas/:userId", adminHandler.DeleteUserQuota)
+
+			// 内容变更审计
+			admin.GET("/audit", adminHandler.GetAuditLogs)
+
+			// 按请求ID查询日志
+			admin.GET("/logs/:traceId", adminHandler.GetRequestLogs)
 		}
 	}
 }
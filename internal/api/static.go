@@ -0,0 +1,66 @@
+package api
+
+import (
+	"io/fs"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeStatic 注册前端页面：/static 下的资源、根路径和 SPA 路由回退都指向 index.html
+// assets 为前端资源（内置或开发时的磁盘目录），uploads 为运行时上传目录，映射到 /static/uploads
+func (s *Server) ServeStatic(assets, uploads fs.FS) {
+	fsys := http.FS(staticFS{assets: assets, uploads: uploads})
+	s.engine.StaticFS("/static", fsys)
+	// 测试页面保留根路径访问
+	s.engine.GET("/fanqie_test.html", func(c *gin.Context) {
+		c.FileFromFS("fanqie_test.html", fsys)
+	})
+
+	index := func(c *gin.Context) {
+		data, err := fs.ReadFile(assets, "index.html")
+		if err != nil {
+			c.String(http.StatusNotFound, "index.html not found")
+			return
+		}
+		c.Data(http.StatusOK, "text/html; charset=utf-8", data)
+	}
+	s.engine.GET("/", index)
+
+	// SPA路由支持 - 所有未匹配的路由返回index.html
+	s.engine.NoRoute(func(c *gin.Context) {
+		// API路由返回404
+		if strings.HasPrefix(c.Request.URL.Path, "/api") {
+			c.JSON(http.StatusNotFound, gin.H{"error": "API endpoint not found"})
+			return
+		}
+		index(c)
+	})
+}
+
+// staticFS 合并内置资源与上传目录：uploads/ 下的路径从上传目录读取，其余从前端资源读取；不列目录
+type staticFS struct {
+	assets  fs.FS
+	uploads fs.FS
+}
+
+func (s staticFS) Open(name string) (fs.File, error) {
+	fsys := s.assets
+	if name == "uploads" || strings.HasPrefix(name, "uploads/") {
+		fsys = s.uploads
+		name = strings.TrimPrefix(strings.TrimPrefix(name, "uploads"), "/")
+		if name == "" {
+			name = "."
+		}
+	}
+	f, err := fsys.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	if info, err := f.Stat(); err != nil || info.IsDir() {
+		f.Close()
+		return nil, fs.ErrNotExist
+	}
+	return f, nil
+}
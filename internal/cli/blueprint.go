@@ -2,12 +2,14 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/export"
 	"github.com/xlei/xupu/pkg/narrative"
 )
 
@@ -73,6 +75,9 @@ func newBlueprintCreateCmd() *cobra.Command {
 		length      string
 		chapters    int
 		structure   string
+		charsFile   string
+		genre       string
+		beatSheetID string
 	)
 
 	cmd := &cobra.Command{
@@ -109,6 +114,30 @@ func newBlueprintCreateCmd() *cobra.Command {
 				Length:       length,
 				ChapterCount: chapters,
 				Structure:    parseNarrativeStructure(structure),
+				Genre:        narrative.ParseGenre(genre),
+			}
+			if beatSheetID != "" {
+				sheet, err := narrative.LoadBeatSheet(database, beatSheetID)
+				if err != nil {
+					PrintError("加载节拍表失败: %v", err)
+					return
+				}
+				params.BeatSheet = sheet
+				PrintInfo("节拍表: %s（%d 个节拍）", sheet.Name, len(sheet.Beats))
+			}
+
+			// 读取用户预设角色
+			if charsFile != "" {
+				data, err := os.ReadFile(charsFile)
+				if err != nil {
+					PrintError("读取角色文件失败: %v", err)
+					return
+				}
+				if err := json.Unmarshal(data, &params.Characters); err != nil {
+					PrintError("解析角色文件失败: %v", err)
+					return
+				}
+				PrintInfo("预设角色: %d 个", len(params.Characters))
 			}
 
 			PrintInfo("正在生成叙事蓝图...")
@@ -139,6 +168,9 @@ func newBlueprintCreateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&length, "length", "medium", "故事长度 (short/medium/long)")
 	cmd.Flags().IntVar(&chapters, "chapters", 12, "章节数量")
 	cmd.Flags().StringVar(&structure, "structure", "three_act", "叙事结构 (three_act/heros_journey/save_the_cat)")
+	cmd.Flags().StringVar(&charsFile, "characters", "", "预设角色JSON文件（角色数组，可用 center 标记主角）")
+	cmd.Flags().StringVar(&genre, "genre", "", "类型演化流水线 (xianxia/romance/mystery/scifi，默认通用)")
+	cmd.Flags().StringVar(&beatSheetID, "beat-sheet", "", "自定义节拍表ID，大纲的关键事件映射到其节拍上")
 
 	return cmd
 }
@@ -204,11 +236,32 @@ func printBlueprintDetail(blueprint *models.NarrativeBlueprint) {
 
 // NewExportCommand 创建导出命令组
 func NewExportCommand() *cobra.Command {
+	var bibleProject string
+	var format string
+	var outputFile string
+
 	cmd := &cobra.Command{
 		Use:   "export",
 		Short: "导出功能",
+		Long: `导出项目、世界设定或叙事蓝图。
+
+使用 --bible <项目ID> 导出故事圣经：汇编世界设定、角色档案、冲突线索、
+时间线和伏笔台账，供协作者查阅。PDF 格式需要本机安装 pandoc。`,
+		Example: `  xupu export --bible proj_xxx -o bible.md
+  xupu export --bible proj_xxx -f pdf`,
+		Run: func(cmd *cobra.Command, args []string) {
+			if bibleProject == "" {
+				cmd.Help()
+				return
+			}
+			exportBible(bibleProject, format, outputFile)
+		},
 	}
 
+	cmd.Flags().StringVar(&bibleProject, "bible", "", "导出项目的故事圣经（项目ID）")
+	cmd.Flags().StringVarP(&format, "format", "f", "markdown", "故事圣经格式 (markdown/pdf)")
+	cmd.Flags().StringVarP(&outputFile, "output", "o", "", "输出文件路径")
+
 	cmd.AddCommand(newExportProjectCmd())
 	cmd.AddCommand(newExportWorldCmd())
 	cmd.AddCommand(newExportBlueprintCmd())
@@ -406,6 +459,39 @@ func NewConfigCommand() *cobra.Command {
 // 导出辅助函数
 // ============================================
 
+func exportBible(projectID, format, outputFile string) {
+	src, err := export.LoadBibleSource(GetDBOrExit(), projectID)
+	if err != nil {
+		PrintError("%v", err)
+		return
+	}
+	markdown := export.RenderBibleMarkdown(src)
+
+	switch format {
+	case "markdown", "md":
+		if outputFile == "" {
+			outputFile = fmt.Sprintf("%s-故事圣经.md", src.Project.Name)
+		}
+		if err := os.WriteFile(outputFile, []byte(markdown), 0644); err != nil {
+			PrintError("写入文件失败: %v", err)
+			return
+		}
+	case "pdf":
+		if outputFile == "" {
+			outputFile = fmt.Sprintf("%s-故事圣经.pdf", src.Project.Name)
+		}
+		if err := export.WriteBiblePDF(markdown, outputFile); err != nil {
+			PrintError("%v", err)
+			return
+		}
+	default:
+		PrintError("不支持的格式: %s", format)
+		return
+	}
+
+	PrintSuccess("故事圣经已导出到: %s", outputFile)
+}
+
 func exportProjectMarkdown(project interface{}, outputFile string) {
 	f, err := os.Create(outputFile)
 	if err != nil {
@@ -0,0 +1,375 @@
+// Package cli CLI命令实现 - 章节离线编辑
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/writer"
+	"gopkg.in/yaml.v3"
+)
+
+// frontMatterDelim Markdown 文件头的分隔行
+const frontMatterDelim = "---"
+
+// maxDiffHunks 推送前最多显示的修改处数
+const maxDiffHunks = 30
+
+// chapterFrontMatter 拉取的章节文件头；outline 只供参考，推送时忽略
+type chapterFrontMatter struct {
+	ChapterID  string          `yaml:"chapter_id"`
+	ProjectID  string          `yaml:"project_id"`
+	ChapterNum int             `yaml:"chapter_num"`
+	Title      string          `yaml:"title"`
+	Status     string          `yaml:"status"`  // draft / completed
+	Version    int             `yaml:"version"` // 拉取时的版本，推送时据此判断期间是否被他人修改
+	PulledAt   string          `yaml:"pulled_at"`
+	Outline    *chapterOutline `yaml:"outline,omitempty"`
+}
+
+// chapterOutline 大纲中本章的规划
+type chapterOutline struct {
+	Purpose         string   `yaml:"purpose,omitempty"`
+	KeyScenes       []string `yaml:"key_scenes,omitempty"`
+	PlotAdvancement string   `yaml:"plot_advancement,omitempty"`
+	ArcProgress     string   `yaml:"arc_progress,omitempty"`
+	EndingHook      string   `yaml:"ending_hook,omitempty"`
+	TargetWords     int      `yaml:"target_words,omitempty"`
+}
+
+// NewChapterCommand 创建章节命令组
+func NewChapterCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "chapter",
+		Short: "章节离线编辑",
+		Long:  "把章节拉取为带文件头的 Markdown，在自己的编辑器里修改后推送回去",
+	}
+
+	cmd.AddCommand(newChapterPullCmd())
+	cmd.AddCommand(newChapterPushCmd())
+
+	return cmd
+}
+
+// newChapterPullCmd 拉取章节为 Markdown 文件
+func newChapterPullCmd() *cobra.Command {
+	var (
+		output string
+		force  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "pull <chapter-id>",
+		Short: "拉取章节为 Markdown 文件",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			database := GetDBOrExit()
+			chapter, err := database.GetChapter(args[0])
+			if err != nil {
+				PrintError("章节不存在: %s", args[0])
+				return
+			}
+			project, err := database.GetProject(chapter.ProjectID)
+			if err != nil {
+				PrintError("项目不存在: %s", chapter.ProjectID)
+				return
+			}
+
+			if output == "" {
+				output = fmt.Sprintf("chapter-%03d.md", chapter.ChapterNum)
+			}
+			if _, err := os.Stat(output); err == nil && !force {
+				PrintError("文件已存在: %s（使用 --force 覆盖）", output)
+				return
+			}
+
+			blueprint := cliProjectBlueprint(database, project)
+			data, err := renderChapterFile(chapter, chapterText(database, blueprint, chapter), chapterOutlineOf(project, blueprint, chapter.ChapterNum))
+			if err != nil {
+				PrintError("生成文件失败: %v", err)
+				return
+			}
+			if err := os.WriteFile(output, data, 0644); err != nil {
+				PrintError("写入文件失败: %v", err)
+				return
+			}
+
+			PrintSuccess("已拉取第%d章《%s》到 %s", chapter.ChapterNum, chapter.Title, output)
+			PrintInfo("修改正文、标题或状态后使用 'xupu chapter push %s' 推送", output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "输出文件（默认 chapter-<章节号>.md）")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "覆盖已存在的文件")
+
+	return cmd
+}
+
+// newChapterPushCmd 推送修改过的 Markdown 文件
+func newChapterPushCmd() *cobra.Command {
+	var (
+		yes   bool
+		force bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "push <file>...",
+		Short: "推送修改过的章节文件",
+		Long:  "逐句对比文件与库中的正文并显示修改，确认后写回；章节在拉取后被他人修改过时拒绝推送，--force 强制覆盖",
+		Args:  cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			for _, path := range args {
+				if err := pushChapterFile(path, yes, force); err != nil {
+					PrintError("%s: %v", path, err)
+				}
+			}
+		},
+	}
+
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "跳过确认")
+	cmd.Flags().BoolVarP(&force, "force", "f", false, "忽略版本冲突，强制覆盖")
+
+	return cmd
+}
+
+// pushChapterFile 推送一个章节文件
+func pushChapterFile(path string, yes, force bool) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("读取文件失败: %w", err)
+	}
+	meta, body, err := parseChapterFile(data)
+	if err != nil {
+		return err
+	}
+	if meta.Status != string(models.ChapterStatusDraft) && meta.Status != string(models.ChapterStatusCompleted) {
+		return fmt.Errorf("status 只能是 draft 或 completed，当前为 %q", meta.Status)
+	}
+	if strings.TrimSpace(meta.Title) == "" {
+		return errors.New("title 不能为空")
+	}
+
+	database := GetDBOrExit()
+	repo := repositories.NewChapterRepository()
+	ctx := context.Background()
+	chapter, err := repo.GetByID(ctx, meta.ChapterID)
+	if err != nil {
+		return fmt.Errorf("章节不存在: %s", meta.ChapterID)
+	}
+	if chapter.Version != meta.Version && !force {
+		return fmt.Errorf("章节在拉取后已被修改（拉取版本 %d，当前版本 %d），请重新拉取后合并，或使用 --force 覆盖", meta.Version, chapter.Version)
+	}
+
+	project, err := database.GetProject(chapter.ProjectID)
+	if err != nil {
+		return fmt.Errorf("项目不存在: %s", chapter.ProjectID)
+	}
+	current := chapterText(database, cliProjectBlueprint(database, project), chapter)
+
+	hunks := writer.DiffSentences(strings.TrimRight(current, "\n"), body)
+	changed := 0
+	for _, h := range hunks {
+		if h.Changed() {
+			changed++
+		}
+	}
+	currentStatus := string(chapter.Status)
+	if currentStatus == "" {
+		currentStatus = string(models.ChapterStatusDraft)
+	}
+	titleChanged := meta.Title != chapter.Title
+	statusChanged := meta.Status != currentStatus
+	if changed == 0 && !titleChanged && !statusChanged {
+		PrintInfo("%s: 没有改动", path)
+		return nil
+	}
+
+	PrintSection(fmt.Sprintf("第%d章《%s》", chapter.ChapterNum, chapter.Title))
+	if titleChanged {
+		fmt.Printf("  标题: %s → %s\n", chapter.Title, meta.Title)
+	}
+	if statusChanged {
+		fmt.Printf("  状态: %s → %s\n", currentStatus, meta.Status)
+	}
+	if changed > 0 {
+		printSentenceDiff(hunks)
+		fmt.Printf("  正文: %d 处修改，%d → %d 字\n", changed, utf8.RuneCountInString(current), utf8.RuneCountInString(body))
+	}
+	fmt.Println()
+
+	if !yes && !confirmPrompt("推送以上修改?") {
+		PrintInfo("已取消")
+		return nil
+	}
+
+	chapter.Title = meta.Title
+	chapter.Status = models.ChapterStatus(meta.Status)
+	if changed > 0 {
+		chapter.Content = body
+		chapter.WordCount = utf8.RuneCountInString(body)
+	}
+	if force {
+		err = repo.Update(ctx, chapter)
+	} else {
+		err = repo.UpdateWithVersion(ctx, chapter, meta.Version)
+	}
+	if errors.Is(err, repositories.ErrChapterVersionConflict) {
+		return errors.New("章节刚刚被他人修改，请重新拉取后合并，或使用 --force 覆盖")
+	}
+	if err != nil {
+		return fmt.Errorf("保存章节失败: %w", err)
+	}
+
+	// 更新本地文件头中的版本，便于继续编辑后再次推送
+	meta.Version = chapter.Version
+	meta.PulledAt = time.Now().Format(time.RFC3339)
+	if updated, err := renderFrontMatter(meta, body); err == nil {
+		if err := os.WriteFile(path, updated, 0644); err != nil {
+			PrintWarn("更新本地文件的版本号失败: %v", err)
+		}
+	}
+
+	PrintSuccess("已推送第%d章（版本 %d）", chapter.ChapterNum, chapter.Version)
+	return nil
+}
+
+// renderChapterFile 生成章节文件：YAML 文件头加正文
+func renderChapterFile(chapter *models.Chapter, prose string, outline *chapterOutline) ([]byte, error) {
+	meta := &chapterFrontMatter{
+		ChapterID:  chapter.ID,
+		ProjectID:  chapter.ProjectID,
+		ChapterNum: chapter.ChapterNum,
+		Title:      chapter.Title,
+		Status:     string(chapter.Status),
+		Version:    chapter.Version,
+		PulledAt:   time.Now().Format(time.RFC3339),
+		Outline:    outline,
+	}
+	if meta.Status == "" {
+		meta.Status = string(models.ChapterStatusDraft)
+	}
+	return renderFrontMatter(meta, strings.TrimRight(prose, "\n"))
+}
+
+func renderFrontMatter(meta *chapterFrontMatter, body string) ([]byte, error) {
+	head, err := yaml.Marshal(meta)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	buf.WriteString(frontMatterDelim + "\n")
+	buf.Write(head)
+	buf.WriteString(frontMatterDelim + "\n\n")
+	buf.WriteString(body)
+	buf.WriteString("\n")
+	return buf.Bytes(), nil
+}
+
+// parseChapterFile 拆出文件头和正文；正文去掉开头的空行和末尾的换行
+func parseChapterFile(data []byte) (*chapterFrontMatter, string, error) {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	text = strings.TrimPrefix(text, "\ufeff")
+	if !strings.HasPrefix(text, frontMatterDelim+"\n") {
+		return nil, "", errors.New("缺少文件头，请使用 'xupu chapter pull' 拉取的文件")
+	}
+	rest := text[len(frontMatterDelim)+1:]
+	end := strings.Index(rest, "\n"+frontMatterDelim+"\n")
+	if end < 0 {
+		return nil, "", errors.New("文件头没有结束行 ---")
+	}
+
+	var meta chapterFrontMatter
+	if err := yaml.Unmarshal([]byte(rest[:end]), &meta); err != nil {
+		return nil, "", fmt.Errorf("解析文件头失败: %w", err)
+	}
+	if meta.ChapterID == "" {
+		return nil, "", errors.New("文件头缺少 chapter_id")
+	}
+	body := rest[end+len(frontMatterDelim)+2:]
+	body = strings.TrimLeft(body, "\n")
+	body = strings.TrimRight(body, "\n")
+	return &meta, body, nil
+}
+
+// printSentenceDiff 打印逐句差异，只显示修改处
+func printSentenceDiff(hunks []models.EditHunk) {
+	shown := 0
+	for _, h := range hunks {
+		if !h.Changed() {
+			continue
+		}
+		if shown == maxDiffHunks {
+			gray.Printf("  ……其余修改未显示\n")
+			break
+		}
+		if h.Original != "" {
+			red.Printf("  - %s\n", strings.TrimSpace(h.Original))
+		}
+		if h.Revised != "" {
+			green.Printf("  + %s\n", strings.TrimSpace(h.Revised))
+		}
+		shown++
+	}
+}
+
+// confirmPrompt 询问是否继续，默认否
+func confirmPrompt(question string) bool {
+	fmt.Printf("%s (y/N): ", question)
+	line, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	answer := strings.TrimSpace(line)
+	return answer == "y" || answer == "Y"
+}
+
+// cliProjectBlueprint 项目的叙事蓝图，没有时返回空蓝图
+func cliProjectBlueprint(database db.Database, project *models.Project) *models.NarrativeBlueprint {
+	if project.NarrativeID != "" {
+		if bp, err := database.GetNarrativeBlueprint(project.NarrativeID); err == nil && bp != nil {
+			return bp
+		}
+	}
+	return &models.NarrativeBlueprint{}
+}
+
+// chapterText 章节正文，没有正文时拼接蓝图中已生成的场景
+func chapterText(database db.Database, blueprint *models.NarrativeBlueprint, chapter *models.Chapter) string {
+	if strings.TrimSpace(chapter.Content) != "" || blueprint.ID == "" {
+		return chapter.Content
+	}
+	scenes := database.ListScenesByChapter(blueprint.ID, chapter.ChapterNum)
+	sort.Slice(scenes, func(i, j int) bool { return scenes[i].Scene < scenes[j].Scene })
+	parts := make([]string, 0, len(scenes))
+	for _, s := range scenes {
+		parts = append(parts, s.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// chapterOutlineOf 大纲中该章的规划，没有规划时返回 nil
+func chapterOutlineOf(project *models.Project, blueprint *models.NarrativeBlueprint, chapterNum int) *chapterOutline {
+	for _, plan := range blueprint.ChapterPlans {
+		if plan.Chapter != chapterNum {
+			continue
+		}
+		return &chapterOutline{
+			Purpose:         plan.Purpose,
+			KeyScenes:       plan.KeyScenes,
+			PlotAdvancement: plan.PlotAdvancement,
+			ArcProgress:     plan.ArcProgress,
+			EndingHook:      plan.EndingHook,
+			TargetWords:     project.WordCountTargets.For(chapterNum, plan.WordCount),
+		}
+	}
+	return nil
+}
@@ -0,0 +1,356 @@
+// Package cli CLI命令实现 - 提示词调试
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/worldbuilder"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// promptFileExt 本地提示词模板文件的扩展名，文件名（不含扩展名）为提示词键
+const promptFileExt = ".tmpl"
+
+// NewDevCommand 创建开发调试命令
+func NewDevCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "dev",
+		Short: "开发调试工具",
+	}
+
+	cmd.AddCommand(newDevWatchCmd())
+
+	return cmd
+}
+
+// devRun 一次阶段重跑的结果
+type devRun struct {
+	Seq     int
+	Prompt  string
+	Output  string
+	Err     error
+	Elapsed time.Duration
+}
+
+// newDevWatchCmd 监听本地提示词模板，修改后热替换并重跑世界构建阶段
+func newDevWatchCmd() *cobra.Command {
+	var (
+		stage    int
+		fixture  string
+		worldID  string
+		theme    string
+		dir      string
+		outDir   string
+		interval time.Duration
+		once     bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "watch",
+		Short: "监听提示词模板，修改后重跑世界构建阶段并比较输出",
+		Long: `监听本地提示词模板目录，文件保存后立即替换运行中的提示词，
+以固定的世界设定为输入重跑指定的世界构建阶段，并与上一次的提示词和输出逐行比较。
+
+模板文件名为提示词键加 .tmpl，如 world_builder.stage3_laws.tmpl，键同配置文件 prompts 下的路径；
+目录中没有所选阶段的模板时，从当前配置导出一份作为起点。
+世界设定可以是 'xupu world export' 导出的JSON包（--fixture），也可以直接取库中的世界（--world）。
+每次运行的提示词和输出保存在 --out 目录中。配合 --dry-run 可以只检查提示词渲染而不调用真实API。`,
+		Example: `  xupu world export world_xxx -o fixture.json
+  xupu dev watch --fixture fixture.json --stage 3
+  xupu --dry-run dev watch --world world_xxx --stage 1 --theme 复仇 --once`,
+		Args: cobra.NoArgs,
+		Run: func(cmd *cobra.Command, args []string) {
+			if stage < 1 || stage > worldbuilder.StageCount {
+				PrintError("--stage 应为 1-%d", worldbuilder.StageCount)
+				return
+			}
+			if (fixture == "") == (worldID == "") {
+				PrintError("请指定 --fixture 或 --world 中的一个")
+				return
+			}
+			if interval <= 0 {
+				PrintError("--interval 必须大于 0")
+				return
+			}
+
+			world, err := loadDevWorld(fixture, worldID)
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+			cfg, err := config.Current()
+			if err != nil {
+				PrintError("加载配置失败: %v", err)
+				return
+			}
+			keys := []string{worldbuilder.SystemPromptKey, worldbuilder.StagePromptKeys[stage-1]}
+			seeded, err := seedPromptFiles(dir, cfg, keys)
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+			if err := os.MkdirAll(outDir, 0755); err != nil {
+				PrintError("创建输出目录失败: %v", err)
+				return
+			}
+
+			PrintHeader("提示词调试")
+			PrintInfo("世界:     %s（%s）", world.Name, world.ID)
+			PrintInfo("阶段:     %d（%s）", stage, worldbuilder.StagePromptKeys[stage-1])
+			PrintInfo("模板目录: %s", dir)
+			PrintInfo("输出目录: %s", outDir)
+			for _, key := range seeded {
+				PrintInfo("已从当前配置导出模板: %s", promptFilePath(dir, key))
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+
+			var prev *devRun
+			seq := 0
+			rerun := func(changed []string) {
+				seq++
+				fmt.Println()
+				PrintSection(fmt.Sprintf("第 %d 次运行 %s", seq, time.Now().Format("15:04:05")))
+				if len(changed) > 0 {
+					PrintInfo("修改的提示词: %s", strings.Join(changed, ", "))
+				}
+				run := runDevStage(seq, stage, world, theme)
+				if err := saveDevRun(outDir, stage, run); err != nil {
+					PrintWarn("保存运行结果失败: %v", err)
+				}
+				printDevRun(prev, run)
+				if run.Err == nil {
+					prev = run
+				}
+			}
+
+			snapshot, prompts, err := readPromptFiles(dir)
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+			changed, err := applyDevPrompts(prompts)
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+			rerun(changed)
+			if once {
+				return
+			}
+
+			fmt.Println()
+			PrintInfo("正在监听 %s，保存模板后自动重跑，Ctrl+C 退出", dir)
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-ctx.Done():
+					fmt.Println()
+					PrintInfo("已停止监听，共运行 %d 次", seq)
+					return
+				case <-ticker.C:
+				}
+
+				next, prompts, err := readPromptFiles(dir)
+				if err != nil {
+					PrintWarn("%v", err)
+					continue
+				}
+				if next == snapshot {
+					continue
+				}
+				snapshot = next
+				changed, err := applyDevPrompts(prompts)
+				if err != nil {
+					PrintError("%v", err)
+					continue
+				}
+				if len(changed) == 0 {
+					continue
+				}
+				rerun(changed)
+			}
+		},
+	}
+
+	cmd.Flags().IntVarP(&stage, "stage", "s", 1, fmt.Sprintf("重跑的世界构建阶段（1-%d）", worldbuilder.StageCount))
+	cmd.Flags().StringVarP(&fixture, "fixture", "f", "", "作为输入的世界设定包（xupu world export 导出的JSON）")
+	cmd.Flags().StringVarP(&worldID, "world", "w", "", "作为输入的世界ID，代替 --fixture")
+	cmd.Flags().StringVar(&theme, "theme", "", "阶段1的主题")
+	cmd.Flags().StringVarP(&dir, "dir", "d", "prompts", "提示词模板目录")
+	cmd.Flags().StringVarP(&outDir, "out", "o", filepath.Join(".xupu", "dev"), "运行结果输出目录")
+	cmd.Flags().DurationVar(&interval, "interval", 500*time.Millisecond, "检查模板修改的间隔")
+	cmd.Flags().BoolVar(&once, "once", false, "只运行一次，不监听")
+
+	return cmd
+}
+
+// loadDevWorld 读取作为输入的世界设定
+func loadDevWorld(fixture, worldID string) (*models.WorldSetting, error) {
+	if worldID != "" {
+		world, err := GetDBOrExit().GetWorld(worldID)
+		if err != nil {
+			return nil, fmt.Errorf("世界不存在: %s", worldID)
+		}
+		return world, nil
+	}
+	data, err := os.ReadFile(fixture)
+	if err != nil {
+		return nil, fmt.Errorf("读取世界设定失败: %w", err)
+	}
+	bundle, err := worldbuilder.ParseBundle(data)
+	if err != nil {
+		return nil, err
+	}
+	return bundle.World, nil
+}
+
+// promptFilePath 提示词键对应的模板文件
+func promptFilePath(dir, key string) string {
+	return filepath.Join(dir, key+promptFileExt)
+}
+
+// seedPromptFiles 目录中没有的模板从当前配置导出，返回导出的键
+func seedPromptFiles(dir string, cfg *config.Config, keys []string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("创建模板目录失败: %w", err)
+	}
+	var seeded []string
+	for _, key := range keys {
+		path := promptFilePath(dir, key)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		prompt, _ := cfg.GetPrompt(key)
+		if err := os.WriteFile(path, []byte(prompt), 0644); err != nil {
+			return nil, fmt.Errorf("导出模板失败: %w", err)
+		}
+		seeded = append(seeded, key)
+	}
+	return seeded, nil
+}
+
+// readPromptFiles 读取目录中的全部模板，返回按文件修改时间和大小生成的快照，用于判断是否有修改
+func readPromptFiles(dir string) (string, map[string]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", nil, fmt.Errorf("读取模板目录失败: %w", err)
+	}
+	var snapshot strings.Builder
+	prompts := map[string]string{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, promptFileExt) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return "", nil, fmt.Errorf("读取模板 %s 失败: %w", name, err)
+		}
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			return "", nil, fmt.Errorf("读取模板 %s 失败: %w", name, err)
+		}
+		prompts[strings.TrimSuffix(name, promptFileExt)] = string(data)
+		fmt.Fprintf(&snapshot, "%s:%d:%d;", name, info.ModTime().UnixNano(), info.Size())
+	}
+	return snapshot.String(), prompts, nil
+}
+
+// applyDevPrompts 把模板替换到运行中的配置，返回内容有变化的键
+func applyDevPrompts(prompts map[string]string) ([]string, error) {
+	result, err := config.ApplyPrompts(prompts)
+	if err != nil {
+		return nil, fmt.Errorf("替换提示词失败: %w", err)
+	}
+	changed := make([]string, 0, len(result.Changes))
+	for _, c := range result.Changes {
+		changed = append(changed, strings.TrimPrefix(c, "prompts."))
+	}
+	sort.Strings(changed)
+	return changed, nil
+}
+
+// runDevStage 用当前提示词重跑阶段；世界设定器创建时取配置快照，因此每次重新创建
+func runDevStage(seq, stage int, world *models.WorldSetting, theme string) *devRun {
+	run := &devRun{Seq: seq}
+	start := time.Now()
+	defer func() { run.Elapsed = time.Since(start) }()
+
+	wb, err := worldbuilder.New()
+	if err != nil {
+		run.Err = err
+		return run
+	}
+	output, prompt, err := wb.RunStage(stage, world, theme)
+	run.Prompt = prompt
+	if err != nil {
+		run.Err = err
+		return run
+	}
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		run.Err = fmt.Errorf("序列化输出失败: %w", err)
+		return run
+	}
+	run.Output = string(data)
+	return run
+}
+
+// saveDevRun 保存一次运行的提示词和输出
+func saveDevRun(outDir string, stage int, run *devRun) error {
+	base := filepath.Join(outDir, fmt.Sprintf("stage%d-%03d", stage, run.Seq))
+	if err := os.WriteFile(base+".prompt.txt", []byte(run.Prompt), 0644); err != nil {
+		return err
+	}
+	if run.Err != nil {
+		return os.WriteFile(base+".error.txt", []byte(run.Err.Error()), 0644)
+	}
+	return os.WriteFile(base+".json", []byte(run.Output), 0644)
+}
+
+// printDevRun 打印运行结果，与上一次成功的运行逐行比较提示词和输出
+func printDevRun(prev, run *devRun) {
+	elapsed := run.Elapsed.Round(time.Millisecond)
+	if run.Err != nil {
+		PrintError("运行失败（%s）: %v", elapsed, run.Err)
+		return
+	}
+	PrintSuccess("运行完成（%s），输出 %d 行", elapsed, strings.Count(run.Output, "\n")+1)
+	if prev == nil {
+		fmt.Println(run.Output)
+		return
+	}
+	printDevDiff(fmt.Sprintf("提示词（对比第 %d 次）", prev.Seq), prev.Prompt, run.Prompt)
+	printDevDiff(fmt.Sprintf("输出（对比第 %d 次）", prev.Seq), prev.Output, run.Output)
+}
+
+// printDevDiff 打印两段文本的差异
+func printDevDiff(title, before, after string) {
+	hunks := writer.DiffSentences(before, after)
+	changes := 0
+	for _, h := range hunks {
+		if h.Changed() {
+			changes++
+		}
+	}
+	if changes == 0 {
+		PrintInfo("%s: 无变化", title)
+		return
+	}
+	PrintInfo("%s: %d 处变化", title, changes)
+	printSentenceDiff(hunks)
+}
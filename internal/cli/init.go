@@ -0,0 +1,433 @@
+// Package cli CLI命令实现 - 交互式创建向导
+package cli
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/manifoldco/promptui"
+	"github.com/spf13/cobra"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/orchestrator"
+	"github.com/xlei/xupu/pkg/scheduler"
+)
+
+// wizardOption 选择题的一个选项
+type wizardOption struct {
+	Value string
+	Label string
+}
+
+var (
+	worldTypeOptions = []wizardOption{
+		{"fantasy", "奇幻"}, {"xianxia", "仙侠"}, {"wuxia", "武侠"}, {"urban", "都市"},
+		{"scifi", "科幻"}, {"historical", "历史"}, {"mixed", "混合"},
+	}
+	worldScaleOptions = []wizardOption{
+		{"continent", "大陆"}, {"nation", "国家"}, {"city", "城市"},
+		{"village", "村庄"}, {"planet", "星球"}, {"universe", "宇宙"},
+	}
+	worldTierOptions = []wizardOption{
+		{"standard", "标准（平衡速度和细节）"}, {"quick", "快速（少量调用，适合试写）"}, {"deep", "深度（完整设定，耗时较长）"},
+	}
+	genreOptions = []wizardOption{
+		{"", "通用"}, {"xianxia", "仙侠/修真"}, {"romance", "言情"}, {"mystery", "悬疑/推理"}, {"scifi", "科幻"},
+	}
+	structureOptions = []wizardOption{
+		{"three_act", "三幕剧"}, {"heros_journey", "英雄之旅"}, {"save_the_cat", "救猫咪节拍表"},
+	}
+	lengthOptions = []wizardOption{
+		{"medium", "中篇"}, {"short", "短篇"}, {"long", "长篇"},
+	}
+	languageOptions = []wizardOption{
+		{"", "简体中文"}, {"zh-TW", "繁體中文"}, {"en", "English"}, {"ja", "日本語"},
+	}
+)
+
+// errWizardAborted 用户在向导中按 Ctrl+C 或放弃确认
+var errWizardAborted = errors.New("已取消")
+
+// NewInitCommand 创建交互式创建向导命令
+func NewInitCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "init",
+		Short: "交互式创建项目",
+		Long:  "逐步选择世界参数、类型、叙事结构、章节数和模型，确认后提交创作流程并实时显示进度",
+		Run: func(cmd *cobra.Command, args []string) {
+			PrintHeader("创建新项目")
+
+			params, model, err := runInitWizard()
+			if errors.Is(err, errWizardAborted) {
+				PrintInfo("已取消")
+				return
+			}
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+
+			if model != nil {
+				if err := useModelForAllModules(*model); err != nil {
+					PrintError("切换模型失败: %v", err)
+					return
+				}
+			}
+
+			orc, err := orchestrator.New()
+			if err != nil {
+				PrintError("初始化编排器失败: %v", err)
+				return
+			}
+			task, err := orchestrator.CreateProjectAsync(*params, orc)
+			if err != nil {
+				PrintError("创建任务失败: %v", err)
+				return
+			}
+
+			fmt.Println()
+			PrintInfo("任务ID: %s（Ctrl+C 退出后可用 'xupu task wait %s' 继续查看）", task.ID, task.ID)
+			fmt.Println()
+			if !watchCreationTask(task, params.Options.GenerateContent) {
+				return
+			}
+
+			fmt.Println()
+			PrintSuccess("项目创建成功!")
+			fmt.Println()
+			if project, err := GetDBOrExit().GetProject(task.ProjectID); err == nil {
+				printProjectDetail(project, GetDBOrExit())
+			}
+			PrintInfo("使用 'xupu status %s' 查看写作进度", task.ProjectID)
+		},
+	}
+}
+
+// runInitWizard 依次询问创建参数；返回的模型为 nil 表示沿用配置文件中的模块映射
+func runInitWizard() (*orchestrator.CreationParams, *config.ModelRef, error) {
+	params := &orchestrator.CreationParams{}
+	var err error
+
+	PrintSection("项目")
+	if params.ProjectName, err = askText("项目名称", "", true); err != nil {
+		return nil, nil, err
+	}
+	if params.Description, err = askText("项目描述（可留空）", "", false); err != nil {
+		return nil, nil, err
+	}
+
+	PrintSection("世界设定")
+	if params.WorldType, err = askSelect("世界类型", worldTypeOptions); err != nil {
+		return nil, nil, err
+	}
+	if params.WorldScale, err = askSelect("世界规模", worldScaleOptions); err != nil {
+		return nil, nil, err
+	}
+	if params.WorldTheme, err = askText("世界主题（可留空）", "", false); err != nil {
+		return nil, nil, err
+	}
+	if params.WorldStyle, err = askText("世界风格（可留空）", "", false); err != nil {
+		return nil, nil, err
+	}
+	if params.WorldTier, err = askSelect("构建档位", worldTierOptions); err != nil {
+		return nil, nil, err
+	}
+
+	PrintSection("故事")
+	if params.Genre, err = askSelect("类型", genreOptions); err != nil {
+		return nil, nil, err
+	}
+	if params.StoryType, err = askText("故事类型", "adventure", true); err != nil {
+		return nil, nil, err
+	}
+	if params.StoryTheme, err = askText("故事主题（可留空）", "", false); err != nil {
+		return nil, nil, err
+	}
+	if params.Protagonist, err = askText("主角设定（可留空）", "", false); err != nil {
+		return nil, nil, err
+	}
+	if params.Structure, err = askSelect("叙事结构", structureOptions); err != nil {
+		return nil, nil, err
+	}
+	if params.StoryLength, err = askSelect("篇幅", lengthOptions); err != nil {
+		return nil, nil, err
+	}
+	if params.ChapterCount, err = askInt("章节数量", 12, 1, 500); err != nil {
+		return nil, nil, err
+	}
+	chapterWords, err := askInt("每章目标字数（0 表示按规划估算）", 0, 0, 50000)
+	if err != nil {
+		return nil, nil, err
+	}
+	params.WordCountTargets = models.WordCountTargets{Default: chapterWords}
+	if params.Language, err = askSelect("输出语言", languageOptions); err != nil {
+		return nil, nil, err
+	}
+
+	PrintSection("模型")
+	model, err := askModel()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	generate, err := askConfirm("规划完成后直接生成正文")
+	if err != nil {
+		return nil, nil, err
+	}
+	params.Options = orchestrator.GenerationOptions{
+		GenerateContent: generate,
+		StartChapter:    1,
+		EndChapter:      params.ChapterCount,
+	}
+
+	PrintSection("确认")
+	printWizardSummary(params, model)
+	ok, err := askConfirm("开始创作")
+	if err != nil {
+		return nil, nil, err
+	}
+	if !ok {
+		return nil, nil, errWizardAborted
+	}
+	return params, model, nil
+}
+
+// askText 询问文本，required 时不允许留空
+func askText(label, def string, required bool) (string, error) {
+	prompt := promptui.Prompt{Label: label, Default: def}
+	if required {
+		prompt.Validate = func(s string) error {
+			if strings.TrimSpace(s) == "" {
+				return errors.New("不能为空")
+			}
+			return nil
+		}
+	}
+	value, err := prompt.Run()
+	if err != nil {
+		return "", wizardError(err)
+	}
+	return strings.TrimSpace(value), nil
+}
+
+// askInt 询问 [min, max] 范围内的整数
+func askInt(label string, def, min, max int) (int, error) {
+	prompt := promptui.Prompt{
+		Label:   label,
+		Default: strconv.Itoa(def),
+		Validate: func(s string) error {
+			n, err := strconv.Atoi(strings.TrimSpace(s))
+			if err != nil {
+				return errors.New("请输入整数")
+			}
+			if n < min || n > max {
+				return fmt.Errorf("取值范围 %d-%d", min, max)
+			}
+			return nil
+		},
+	}
+	value, err := prompt.Run()
+	if err != nil {
+		return 0, wizardError(err)
+	}
+	return strconv.Atoi(strings.TrimSpace(value))
+}
+
+// askSelect 单选，返回选项的值
+func askSelect(label string, options []wizardOption) (string, error) {
+	sel := promptui.Select{
+		Label: label,
+		Items: options,
+		Size:  len(options),
+		Templates: &promptui.SelectTemplates{
+			Label:    "{{ . }}",
+			Active:   "▸ {{ .Label | cyan }}",
+			Inactive: "  {{ .Label }}",
+			Selected: "✓ " + label + ": {{ .Label | green }}",
+		},
+	}
+	i, _, err := sel.Run()
+	if err != nil {
+		return "", wizardError(err)
+	}
+	return options[i].Value, nil
+}
+
+// askConfirm 是/否，默认为是
+func askConfirm(label string) (bool, error) {
+	prompt := promptui.Prompt{Label: label, IsConfirm: true, Default: "y"}
+	if _, err := prompt.Run(); err != nil {
+		if errors.Is(err, promptui.ErrAbort) {
+			return false, nil
+		}
+		return false, wizardError(err)
+	}
+	return true, nil
+}
+
+// askModel 从配置文件中各提供商的可用模型里选择本次创作使用的模型
+func askModel() (*config.ModelRef, error) {
+	cfg, err := config.Current()
+	if err != nil {
+		return nil, fmt.Errorf("加载配置失败: %w", err)
+	}
+	refs := availableModels(cfg.LLM)
+	if len(refs) == 0 {
+		PrintInfo("配置文件中没有列出可用模型，沿用各模块的模型映射")
+		return nil, nil
+	}
+
+	options := []wizardOption{{"", "沿用配置文件中各模块的模型"}}
+	for _, ref := range refs {
+		options = append(options, wizardOption{ref.String(), ref.String()})
+	}
+	value, err := askSelect("所有模块使用的模型", options)
+	if err != nil || value == "" {
+		return nil, err
+	}
+	for i := range refs {
+		if refs[i].String() == value {
+			return &refs[i], nil
+		}
+	}
+	return nil, nil
+}
+
+// availableModels 配置中各提供商的默认模型和可用模型，按提供商排序去重
+func availableModels(cfg config.LLMConfig) []config.ModelRef {
+	providers := make([]string, 0, len(cfg.Providers))
+	for name := range cfg.Providers {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+
+	refs := make([]config.ModelRef, 0)
+	seen := map[string]bool{}
+	add := func(provider, model string) {
+		ref := config.ModelRef{Provider: provider, Model: model}
+		if model == "" || seen[ref.String()] {
+			return
+		}
+		seen[ref.String()] = true
+		refs = append(refs, ref)
+	}
+	for _, name := range providers {
+		p := cfg.Providers[name]
+		add(name, p.Models.Default)
+		for _, m := range p.Models.Available {
+			add(name, m.Name)
+		}
+	}
+	return refs
+}
+
+// useModelForAllModules 本次运行中所有模块改用指定模型，不修改配置文件
+func useModelForAllModules(ref config.ModelRef) error {
+	cfg, err := config.Current()
+	if err != nil {
+		return err
+	}
+	if _, ok := cfg.LLM.Providers[ref.Provider]; !ok {
+		return fmt.Errorf("未找到提供商 %s 的配置", ref.Provider)
+	}
+	mapping := make(map[string]config.ModuleMapping, len(cfg.LLM.ModuleMapping))
+	for name, m := range cfg.LLM.ModuleMapping {
+		m.Provider = ref.Provider
+		m.Model = ref.Model
+		m.Fallbacks = nil
+		mapping[name] = m
+	}
+	cfg.LLM.ModuleMapping = mapping
+	return nil
+}
+
+// printWizardSummary 打印向导收集的参数
+func printWizardSummary(params *orchestrator.CreationParams, model *config.ModelRef) {
+	fmt.Printf("  名称:     %s\n", params.ProjectName)
+	fmt.Printf("  世界:     %s / %s / %s\n", optionLabel(worldTypeOptions, params.WorldType), optionLabel(worldScaleOptions, params.WorldScale), optionLabel(worldTierOptions, params.WorldTier))
+	fmt.Printf("  类型:     %s（%s）\n", optionLabel(genreOptions, params.Genre), params.StoryType)
+	fmt.Printf("  结构:     %s，%s，%d 章\n", optionLabel(structureOptions, params.Structure), optionLabel(lengthOptions, params.StoryLength), params.ChapterCount)
+	if params.WordCountTargets.Default > 0 {
+		fmt.Printf("  每章字数: %d\n", params.WordCountTargets.Default)
+	}
+	fmt.Printf("  语言:     %s\n", optionLabel(languageOptions, params.Language))
+	if model != nil {
+		fmt.Printf("  模型:     %s\n", model.String())
+	} else {
+		fmt.Printf("  模型:     按配置文件\n")
+	}
+	if params.Options.GenerateContent {
+		fmt.Printf("  正文:     规划完成后生成第 1-%d 章\n", params.ChapterCount)
+	} else {
+		fmt.Printf("  正文:     只做规划\n")
+	}
+	fmt.Println()
+}
+
+func optionLabel(options []wizardOption, value string) string {
+	for _, o := range options {
+		if o.Value == value {
+			return o.Label
+		}
+	}
+	return value
+}
+
+// wizardError Ctrl+C 转为取消
+func wizardError(err error) error {
+	if errors.Is(err, promptui.ErrInterrupt) || errors.Is(err, promptui.ErrEOF) {
+		return errWizardAborted
+	}
+	return err
+}
+
+// watchCreationTask 实时显示创作任务的阶段和进度，任务成功时返回 true
+func watchCreationTask(task *scheduler.Task, generate bool) bool {
+	spinner := []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	start := time.Now()
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for i := 0; ; i++ {
+		status := task.GetStatus()
+		progress := task.GetProgress()
+		elapsed := time.Since(start).Truncate(time.Second)
+
+		switch status {
+		case scheduler.StatusCompleted:
+			fmt.Printf("\r[%-50s] %5.1f%%  %-8s %s\n", getProgressBar(100), 100.0, "完成", elapsed)
+			return true
+		case scheduler.StatusFailed:
+			fmt.Println()
+			PrintError("创作失败: %s", task.Error)
+			return false
+		case scheduler.StatusCancelled:
+			fmt.Println()
+			PrintInfo("任务已取消")
+			return false
+		}
+
+		fmt.Printf("\r%s [%-50s] %5.1f%%  %-8s %s   ", cyan.Sprint(spinner[i%len(spinner)]), getProgressBar(progress), progress, creationStage(status, progress, generate), elapsed)
+		<-ticker.C
+	}
+}
+
+// creationStage 按进度推断创作流程所处的阶段
+// 异步流程进入世界设定时进度为 1/3，进入叙事规划时为 2/3，之后按已完成的正文推进
+func creationStage(status scheduler.TaskStatus, progress float64, generate bool) string {
+	planning := 100.0 / 3 * 2
+	switch {
+	case status == scheduler.StatusPending:
+		return "排队中"
+	case progress < planning-0.01:
+		return "世界设定"
+	case !generate || progress < planning+0.01:
+		return "叙事规划"
+	default:
+		return "生成正文"
+	}
+}
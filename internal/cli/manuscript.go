@@ -0,0 +1,165 @@
+// Package cli CLI命令实现 - 书稿导入
+package cli
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/spf13/cobra"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/manuscript"
+)
+
+// newProjectImportCmd 导入已有书稿并规划续写
+func newProjectImportCmd() *cobra.Command {
+	var (
+		name        string
+		author      string
+		description string
+		language    string
+		continueN   int
+		parseOnly   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "import <文件或目录>...",
+		Short: "导入已有书稿并规划续写",
+		Long: `导入已写好的章节（TXT/Markdown/DOCX），按文件名自然顺序排列，一个文件可含多章（按“第X章”或 Markdown 标题切分）。
+章节入库后通读书稿，重建角色、世界设定、细节事实、情节线和伏笔，再从下一章起规划续写。
+目录会导入其中所有支持的文件；--parse-only 只显示章节切分结果，不入库也不调用模型。`,
+		Args: cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			paths, err := manuscriptPaths(args)
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+
+			files := make([]manuscript.File, 0, len(paths))
+			names := make([]string, 0, len(paths))
+			for _, path := range paths {
+				data, err := os.ReadFile(path)
+				if err != nil {
+					PrintError("读取文件失败: %v", err)
+					return
+				}
+				files = append(files, manuscript.File{Name: path, Data: data})
+				names = append(names, filepath.Base(path))
+			}
+
+			chapters, err := manuscript.Parse(files)
+			if err != nil {
+				PrintError("解析书稿失败: %v", err)
+				return
+			}
+
+			PrintHeader("书稿章节")
+			rows := make([][]string, 0, len(chapters))
+			for _, ch := range chapters {
+				rows = append(rows, []string{
+					fmt.Sprintf("%d", ch.Num),
+					ch.Title,
+					fmt.Sprintf("%d", utf8.RuneCountInString(ch.Content)),
+					ch.Source,
+				})
+			}
+			PrintTable([]string{"章节", "标题", "字符数", "来源"}, rows)
+			if parseOnly {
+				return
+			}
+
+			if name == "" {
+				name = manuscriptName(args[0], chapters[0].Source)
+			}
+			imp, err := manuscript.Create(GetDBOrExit(), names, chapters, manuscript.Options{
+				Name:             name,
+				Author:           author,
+				Description:      description,
+				Language:         language,
+				ContinueChapters: continueN,
+			})
+			if err != nil {
+				PrintError("导入失败: %v", err)
+				return
+			}
+			PrintSuccess("已导入 %d 章，项目ID: %s", len(imp.Chapters), imp.Project.ID)
+
+			err = imp.Run(func(stage string, done, total int) {
+				switch stage {
+				case models.ManuscriptStageAnalyze:
+					PrintInfo("分析书稿 %d/%d 章", done, total)
+				case models.ManuscriptStageSynthesize:
+					PrintInfo("归纳世界设定与冲突")
+				case models.ManuscriptStagePlan:
+					PrintInfo("规划续写章节")
+				}
+			})
+			if err != nil {
+				PrintError("分析书稿失败: %v", err)
+				PrintInfo("已导入的章节保留在项目 %s 中", imp.Project.ID)
+				return
+			}
+
+			record := imp.Record
+			PrintSuccess("书稿分析完成")
+			fmt.Printf("  角色:       %d\n", record.Characters)
+			fmt.Printf("  细节事实:   %d\n", record.Facts)
+			fmt.Printf("  未回收伏笔: %d\n", record.OpenForeshadows)
+			fmt.Printf("  续写规划:   第%d章起 %d 章\n", imp.Chapters[len(imp.Chapters)-1].ChapterNum+1, record.ContinueChapters)
+			PrintInfo("使用 'xupu project show %s' 查看项目", imp.Project.ID)
+		},
+	}
+
+	cmd.Flags().StringVarP(&name, "name", "n", "", "项目名称（默认使用第一个文件名）")
+	cmd.Flags().StringVar(&author, "author", "", "作者")
+	cmd.Flags().StringVarP(&description, "description", "d", "", "项目描述")
+	cmd.Flags().StringVar(&language, "language", "", "输出语言 (zh-CN/zh-TW/en/ja，默认简体中文)")
+	cmd.Flags().IntVar(&continueN, "continue", manuscript.DefaultContinueChapters, "续写规划的章节数（最多30）")
+	cmd.Flags().BoolVar(&parseOnly, "parse-only", false, "只显示章节切分结果")
+
+	return cmd
+}
+
+// manuscriptName 默认项目名称：导入目录时为目录名，否则为排在最前的文件名
+func manuscriptName(arg, source string) string {
+	if info, err := os.Stat(arg); err == nil && info.IsDir() {
+		if abs, err := filepath.Abs(arg); err == nil {
+			return filepath.Base(abs)
+		}
+	}
+	return strings.TrimSuffix(source, filepath.Ext(source))
+}
+
+// manuscriptPaths 展开命令行参数中的目录，目录只取其中支持的书稿文件
+func manuscriptPaths(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			if !manuscript.Supported(arg) {
+				return nil, fmt.Errorf("不支持的文件格式: %s（支持 txt、md、docx）", arg)
+			}
+			paths = append(paths, arg)
+			continue
+		}
+		entries, err := os.ReadDir(arg)
+		if err != nil {
+			return nil, err
+		}
+		for _, e := range entries {
+			if !e.IsDir() && manuscript.Supported(e.Name()) {
+				paths = append(paths, filepath.Join(arg, e.Name()))
+			}
+		}
+	}
+	if len(paths) == 0 {
+		return nil, fmt.Errorf("没有找到书稿文件")
+	}
+	return paths, nil
+}
@@ -23,6 +23,7 @@ func NewProjectCommand() *cobra.Command {
 	cmd.AddCommand(newProjectShowCmd())
 	cmd.AddCommand(newProjectDeleteCmd())
 	cmd.AddCommand(newProjectGenerateCmd())
+	cmd.AddCommand(newProjectImportCmd())
 
 	return cmd
 }
@@ -82,6 +83,7 @@ func newProjectCreateCmd() *cobra.Command {
 		worldScale  string
 		worldTheme  string
 		worldStyle  string
+		worldTier   string
 		// 故事参数
 		storyType   string
 		theme       string
@@ -89,6 +91,9 @@ func newProjectCreateCmd() *cobra.Command {
 		length      string
 		chapters    int
 		structure   string
+		genre       string
+		chapterWords int
+		language    string
 		// 异步选项
 		async       bool
 	)
@@ -102,6 +107,11 @@ func newProjectCreateCmd() *cobra.Command {
 				return
 			}
 
+			if !models.ValidLanguage(language) {
+				PrintError("不支持的输出语言: %s (zh-CN/zh-TW/en/ja)", language)
+				return
+			}
+
 			PrintHeader("创建新项目")
 
 			params := orchestrator.CreationParams{
@@ -111,15 +121,19 @@ func newProjectCreateCmd() *cobra.Command {
 				WorldScale:  worldScale,
 				WorldTheme:  worldTheme,
 				WorldStyle:  worldStyle,
+				WorldTier:   worldTier,
 				StoryType:   storyType,
 				StoryTheme:  theme,
 				Protagonist: protagonist,
 				StoryLength: length,
 				ChapterCount: chapters,
 				Structure:   structure,
+				Genre:       genre,
 				Options: orchestrator.GenerationOptions{
 					GenerateContent: false, // 默认不生成内容
 				},
+				WordCountTargets: models.WordCountTargets{Default: chapterWords},
+				Language:         language,
 			}
 
 			if async {
@@ -172,6 +186,7 @@ func newProjectCreateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&worldScale, "world-scale", "continent", "世界规模 (village/city/nation/continent/planet/universe)")
 	cmd.Flags().StringVar(&worldTheme, "world-theme", "", "世界主题")
 	cmd.Flags().StringVar(&worldStyle, "world-style", "", "世界风格")
+	cmd.Flags().StringVar(&worldTier, "world-tier", "standard", "世界构建档位 (quick/standard/deep)")
 	// 故事参数
 	cmd.Flags().StringVar(&storyType, "story-type", "adventure", "故事类型")
 	cmd.Flags().StringVar(&theme, "theme", "", "故事主题")
@@ -179,6 +194,9 @@ func newProjectCreateCmd() *cobra.Command {
 	cmd.Flags().StringVar(&length, "length", "medium", "故事长度 (short/medium/long)")
 	cmd.Flags().IntVar(&chapters, "chapters", 12, "章节数量")
 	cmd.Flags().StringVar(&structure, "structure", "three_act", "叙事结构 (three_act/heros_journey/save_the_cat)")
+	cmd.Flags().StringVar(&genre, "genre", "", "类型演化流水线 (xianxia/romance/mystery/scifi，默认通用)")
+	cmd.Flags().IntVar(&chapterWords, "chapter-words", 0, "每章目标字数（0表示按规划估算）")
+	cmd.Flags().StringVar(&language, "language", "", "输出语言 (zh-CN/zh-TW/en/ja，默认简体中文)")
 	// 选项
 	cmd.Flags().BoolVar(&async, "async", false, "异步创建")
 
@@ -218,7 +236,7 @@ func newProjectDeleteCmd() *cobra.Command {
 
 			// 确认
 			if !confirm {
-				PrintWarn("此操作将删除项目及其所有数据")
+				PrintWarn("此操作将把项目及其蓝图、章节移入回收站，30天后彻底清除")
 				fmt.Print("确认删除? (y/N): ")
 				var response string
 				fmt.Scanln(&response)
@@ -233,7 +251,7 @@ func newProjectDeleteCmd() *cobra.Command {
 				return
 			}
 
-			PrintSuccess("项目已删除")
+			PrintSuccess("项目已移入回收站")
 		},
 	}
 
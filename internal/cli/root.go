@@ -134,6 +134,8 @@ func FormatProjectMode(m models.OrchestrationMode) string {
 		return "短篇模式"
 	case models.ModeScript:
 		return "剧本模式"
+	case models.ModeLocalImport:
+		return "本地导入"
 	default:
 		return string(m)
 	}
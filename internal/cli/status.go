@@ -0,0 +1,160 @@
+// Package cli CLI命令实现 - 写作统计
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/progress"
+)
+
+// NewStatusCommand 创建写作统计命令
+func NewStatusCommand() *cobra.Command {
+	var (
+		days   int
+		window int
+	)
+
+	cmd := &cobra.Command{
+		Use:   "status [project-id]",
+		Short: "查看写作进度统计",
+		Long:  "不带参数时列出所有项目的进度汇总；指定项目时显示每章字数、最近每天的字数和预计完稿日期",
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if days <= 0 || window <= 0 {
+				PrintError("--days 和 --window 必须大于 0")
+				return
+			}
+			database := GetDBOrExit()
+			opts := progress.Options{Days: days, Window: window}
+
+			if len(args) == 0 {
+				projects := database.ListProjects()
+				PrintHeader("写作进度")
+				if len(projects) == 0 {
+					PrintInfo("暂无项目")
+					return
+				}
+				rows := make([][]string, 0, len(projects))
+				for _, p := range projects {
+					stats, err := progress.Load(database, p, opts)
+					if err != nil {
+						PrintError("统计 %s 失败: %v", p.Name, err)
+						continue
+					}
+					rows = append(rows, []string{
+						shortID(p.ID),
+						p.Name,
+						fmt.Sprintf("%d/%d", stats.ChaptersCompleted, stats.ChaptersPlanned),
+						fmt.Sprintf("%d", stats.TotalWords),
+						fmt.Sprintf("%.0f", stats.WordsPerDay),
+						formatEstimate(stats),
+					})
+				}
+				PrintTable([]string{"ID", "名称", "完成/规划", "总字数", "日均字数", "预计完稿"}, rows)
+				fmt.Println()
+				PrintInfo("日均字数按最近 %d 天计算，使用 'xupu status <project-id>' 查看详情", window)
+				return
+			}
+
+			project, err := database.GetProject(args[0])
+			if err != nil {
+				PrintError("项目不存在: %s", args[0])
+				return
+			}
+			stats, err := progress.Load(database, project, opts)
+			if err != nil {
+				PrintError("统计失败: %v", err)
+				return
+			}
+
+			PrintHeader("写作进度 - " + project.Name)
+			printStatusSummary(stats)
+			printStatusChapters(stats)
+			printStatusDays(stats)
+		},
+	}
+
+	cmd.Flags().IntVar(&days, "days", 7, "列出最近多少天的字数")
+	cmd.Flags().IntVar(&window, "window", progress.DefaultWindow, "按最近多少天计算写作速度")
+
+	return cmd
+}
+
+// printStatusSummary 打印进度概览
+func printStatusSummary(stats *progress.Stats) {
+	fmt.Printf("  章节:     %d/%d 已完成，%d 章有正文\n", stats.ChaptersCompleted, stats.ChaptersPlanned, stats.ChaptersWritten)
+	fmt.Printf("  进度:     %s %.1f%%\n", getProgressBar(stats.Progress*100), stats.Progress*100)
+	fmt.Printf("  字数:     %d / %d（剩余约 %d）\n", stats.TotalWords, stats.TargetWords, stats.RemainingWords)
+	fmt.Printf("  速度:     最近 %d 天日均 %.0f 字，其中 %d 天有写作\n", stats.Window, stats.WordsPerDay, stats.ActiveDays)
+	fmt.Printf("  预计完稿: %s\n", formatEstimate(stats))
+}
+
+// printStatusChapters 打印每章字数
+func printStatusChapters(stats *progress.Stats) {
+	PrintSection("章节")
+	if len(stats.Chapters) == 0 {
+		PrintInfo("暂无章节")
+		return
+	}
+	rows := make([][]string, 0, len(stats.Chapters))
+	for _, ch := range stats.Chapters {
+		rows = append(rows, []string{
+			fmt.Sprintf("%d", ch.ChapterNum),
+			ch.Title,
+			formatChapterStatus(ch.Status),
+			fmt.Sprintf("%d/%d", ch.WordCount, ch.Target),
+			fmt.Sprintf("%+d", ch.Recent),
+			ch.LastDate,
+		})
+	}
+	PrintTable([]string{"章", "标题", "状态", "字数/目标", fmt.Sprintf("近%d天", stats.Window), "最近写作"}, rows)
+}
+
+// printStatusDays 打印最近每天的字数
+func printStatusDays(stats *progress.Stats) {
+	PrintSection("每日字数")
+	rows := make([][]string, 0, len(stats.Days))
+	for _, d := range stats.Days {
+		rows = append(rows, []string{
+			d.Date,
+			fmt.Sprintf("%+d", d.Words),
+			fmt.Sprintf("%d", d.Chapters),
+			fmt.Sprintf("%d", d.Completed),
+		})
+	}
+	PrintTable([]string{"日期", "净增字数", "修改章节", "完成章节"}, rows)
+}
+
+// formatEstimate 格式化预计完稿日期
+func formatEstimate(stats *progress.Stats) string {
+	switch {
+	case stats.Finished:
+		return green.Sprint("已完稿")
+	case stats.EstimatedDate != "":
+		return fmt.Sprintf("%s（约 %d 天）", stats.EstimatedDate, stats.DaysLeft)
+	default:
+		return gray.Sprint("最近没有进展")
+	}
+}
+
+// formatChapterStatus 格式化章节状态
+func formatChapterStatus(status string) string {
+	switch status {
+	case string(models.ChapterStatusCompleted):
+		return green.Sprint("已完成")
+	case string(models.ChapterStatusDraft):
+		return yellow.Sprint("草稿")
+	default:
+		return gray.Sprint("未开始")
+	}
+}
+
+// shortID 截短的ID，用于表格
+func shortID(id string) string {
+	if len(id) > 12 {
+		return id[:12]
+	}
+	return id
+}
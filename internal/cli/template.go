@@ -0,0 +1,243 @@
+// Package cli CLI命令实现 - 模板包
+package cli
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/xlei/xupu/pkg/templatepack"
+)
+
+// NewTemplateCommand 创建模板包命令组
+func NewTemplateCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "template",
+		Short: "模板包管理（叙事结构与提示词模板的分享）",
+	}
+
+	cmd.AddCommand(newTemplateInstallCmd())
+	cmd.AddCommand(newTemplateExportCmd())
+	cmd.AddCommand(newTemplateKeygenCmd())
+
+	return cmd
+}
+
+// newTemplateInstallCmd 安装模板包
+func newTemplateInstallCmd() *cobra.Command {
+	var (
+		conflict       string
+		trusted        []string
+		allowUnsigned  bool
+		allowUntrusted bool
+		dryRun         bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "install <file|url>",
+		Short: "从文件或URL安装模板包",
+		Long: fmt.Sprintf(`校验签名后导入模板包中的叙事结构和提示词模板。
+受信任的公钥来自 --trust 和环境变量 %s（逗号分隔）。
+与已有模板冲突时按 --conflict 处理：
+  newer      包中版本更高时覆盖（默认）
+  skip       保留已有模板
+  overwrite  总是覆盖
+  rename     以新ID导入，保留已有模板`, templatepack.TrustedKeysEnv),
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			strategy, err := templatepack.ParseConflictStrategy(conflict)
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+
+			data, err := templatepack.Load(args[0])
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+			pack, err := templatepack.Parse(data)
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+
+			database := GetDBOrExit()
+			result, err := templatepack.Install(database, pack, templatepack.InstallOptions{
+				Conflict:       strategy,
+				TrustedKeys:    append(templatepack.TrustedKeysFromEnv(), trusted...),
+				AllowUnsigned:  allowUnsigned,
+				AllowUntrusted: allowUntrusted,
+				DryRun:         dryRun,
+			})
+			if err != nil {
+				PrintError("安装失败: %v", err)
+				return
+			}
+
+			if dryRun {
+				PrintHeader(fmt.Sprintf("模板包 %s %s（演练，未写入）", result.Name, result.PackVersion))
+			} else {
+				PrintHeader(fmt.Sprintf("模板包 %s %s", result.Name, result.PackVersion))
+			}
+			switch {
+			case result.Trusted:
+				PrintInfo("签名: 受信任 (%s)", result.SignedBy)
+			case result.Signed:
+				PrintWarn("签名有效，但公钥不在信任列表中: %s", result.SignedBy)
+			default:
+				PrintWarn("模板包没有签名")
+			}
+
+			rows := make([][]string, 0, len(result.Items))
+			for _, item := range result.Items {
+				version := fmt.Sprintf("v%d", item.IncomingVersion)
+				if item.ExistingVersion > 0 {
+					version = fmt.Sprintf("v%d → v%d", item.ExistingVersion, item.IncomingVersion)
+				}
+				id := item.ID
+				if item.NewID != "" {
+					id += " → " + item.NewID
+				}
+				rows = append(rows, []string{item.Kind, id, version, item.Action})
+			}
+			PrintTable([]string{"类型", "ID", "版本", "结果"}, rows)
+
+			counts := result.Counts()
+			fmt.Println()
+			PrintSuccess("新增 %d，更新 %d，改名 %d，跳过 %d",
+				counts[templatepack.ActionCreated], counts[templatepack.ActionUpdated],
+				counts[templatepack.ActionRenamed], counts[templatepack.ActionSkipped])
+		},
+	}
+
+	cmd.Flags().StringVar(&conflict, "conflict", "newer", "冲突处理方式 (newer/skip/overwrite/rename)")
+	cmd.Flags().StringSliceVar(&trusted, "trust", nil, "额外信任的签名公钥（base64）")
+	cmd.Flags().BoolVar(&allowUnsigned, "allow-unsigned", false, "允许安装未签名的模板包")
+	cmd.Flags().BoolVar(&allowUntrusted, "allow-untrusted", false, "允许安装签名公钥不在信任列表中的模板包")
+	cmd.Flags().BoolVar(&dryRun, "dry-run-install", false, "只显示安装结果，不写入数据库")
+	return cmd
+}
+
+// newTemplateExportCmd 导出模板包
+func newTemplateExportCmd() *cobra.Command {
+	var (
+		output      string
+		name        string
+		version     string
+		author      string
+		description string
+		structures  []string
+		prompts     []string
+		keyFile     string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "export",
+		Short: "导出叙事结构和提示词模板为签名的模板包",
+		Long: fmt.Sprintf(`导出模板包。签名私钥来自 --key 指定的文件或环境变量 %s，
+两者都没有时导出未签名的包。可用 "xupu template keygen" 生成密钥。`, templatepack.SigningKeyEnv),
+		Run: func(cmd *cobra.Command, args []string) {
+			database := GetDBOrExit()
+			pack, err := templatepack.Export(database, templatepack.ExportOptions{
+				Name:         name,
+				PackVersion:  version,
+				Author:       author,
+				Description:  description,
+				StructureIDs: structures,
+				PromptKeys:   prompts,
+			})
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+
+			key, err := templatepack.SigningKeyFromEnv()
+			if keyFile != "" {
+				data, readErr := os.ReadFile(keyFile)
+				if readErr != nil {
+					PrintError("读取私钥失败: %v", readErr)
+					return
+				}
+				key, err = templatepack.ParseKey(string(data))
+			}
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+			if key != nil {
+				if err := pack.Sign(key); err != nil {
+					PrintError("签名失败: %v", err)
+					return
+				}
+			}
+
+			data, err := json.MarshalIndent(pack, "", "  ")
+			if err != nil {
+				PrintError("序列化失败: %v", err)
+				return
+			}
+			if output == "" {
+				output = fmt.Sprintf("%s.xupu-pack.json", strings.ReplaceAll(pack.Name, " ", "_"))
+			}
+			if err := os.WriteFile(output, data, 0644); err != nil {
+				PrintError("写入文件失败: %v", err)
+				return
+			}
+
+			PrintSuccess("已导出模板包: %s %s", pack.Name, pack.PackVersion)
+			PrintInfo("叙事结构: %d 个", len(pack.Structures))
+			PrintInfo("提示词: %d 个", len(pack.Prompts))
+			if pack.Signature != nil {
+				PrintInfo("签名公钥: %s", pack.Signature.PublicKey)
+			} else {
+				PrintWarn("未签名：设置 %s 或使用 --key 签名", templatepack.SigningKeyEnv)
+			}
+			PrintInfo("文件: %s", output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "输出文件路径")
+	cmd.Flags().StringVar(&name, "name", "", "包名")
+	cmd.Flags().StringVar(&version, "version", "1.0.0", "包版本")
+	cmd.Flags().StringVar(&author, "author", "", "作者")
+	cmd.Flags().StringVar(&description, "description", "", "说明")
+	cmd.Flags().StringSliceVar(&structures, "structure", nil, "要导出的叙事结构ID（默认全部内置结构）")
+	cmd.Flags().StringSliceVar(&prompts, "prompt", nil, "要导出的提示词key（默认全部）")
+	cmd.Flags().StringVar(&keyFile, "key", "", "签名私钥文件（base64 编码的种子）")
+	return cmd
+}
+
+// newTemplateKeygenCmd 生成签名密钥
+func newTemplateKeygenCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "keygen",
+		Short: "生成模板包签名密钥",
+		Run: func(cmd *cobra.Command, args []string) {
+			private, public, err := templatepack.GenerateKey()
+			if err != nil {
+				PrintError("生成密钥失败: %v", err)
+				return
+			}
+
+			if output != "" {
+				if err := os.WriteFile(output, []byte(private+"\n"), 0600); err != nil {
+					PrintError("写入私钥失败: %v", err)
+					return
+				}
+				PrintSuccess("私钥已写入: %s", output)
+			} else {
+				PrintInfo("私钥: %s", private)
+			}
+			PrintInfo("公钥: %s", public)
+			PrintInfo("把公钥发给使用者，加入其 %s 即可信任你的模板包", templatepack.TrustedKeysEnv)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "私钥输出文件（默认打印到终端）")
+	return cmd
+}
@@ -2,7 +2,9 @@
 package cli
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
 
 	"github.com/spf13/cobra"
 	"github.com/xlei/xupu/internal/models"
@@ -21,6 +23,8 @@ func NewWorldCommand() *cobra.Command {
 	cmd.AddCommand(newWorldCreateCmd())
 	cmd.AddCommand(newWorldShowCmd())
 	cmd.AddCommand(newWorldDeleteCmd())
+	cmd.AddCommand(newWorldExportCmd())
+	cmd.AddCommand(newWorldImportCmd())
 
 	return cmd
 }
@@ -70,6 +74,7 @@ func newWorldCreateCmd() *cobra.Command {
 		scale    string
 		theme    string
 		style    string
+		tier     string
 		quick    bool
 	)
 
@@ -86,6 +91,7 @@ func newWorldCreateCmd() *cobra.Command {
 			PrintInfo("名称: %s", name)
 			PrintInfo("类型: %s", worldType)
 			PrintInfo("规模: %s", scale)
+			PrintInfo("档位: %s", models.ParseWorldBuildTier(tier))
 
 			// 创建世界构建器
 			builder, err := worldbuilder.New()
@@ -101,6 +107,7 @@ func newWorldCreateCmd() *cobra.Command {
 				Scale:  parseWorldScale(scale),
 				Theme:  theme,
 				Style:  style,
+				Tier:   models.ParseWorldBuildTier(tier),
 			})
 
 			if err != nil {
@@ -132,6 +139,7 @@ func newWorldCreateCmd() *cobra.Command {
 	cmd.Flags().StringVarP(&scale, "scale", "s", "continent", "世界规模 (village/city/nation/continent/planet/universe)")
 	cmd.Flags().StringVarP(&theme, "theme", "T", "", "世界主题")
 	cmd.Flags().StringVar(&style, "style", "", "世界风格")
+	cmd.Flags().StringVar(&tier, "tier", "standard", "构建档位 (quick≈10轮/standard≈40轮/deep完整流水线)")
 	cmd.Flags().BoolVarP(&quick, "quick", "q", false, "快速模式（不显示详情）")
 
 	return cmd
@@ -187,7 +195,7 @@ func newWorldDeleteCmd() *cobra.Command {
 				return
 			}
 
-			PrintSuccess("世界已删除")
+			PrintSuccess("世界已移入回收站")
 		},
 	}
 
@@ -195,6 +203,79 @@ func newWorldDeleteCmd() *cobra.Command {
 	return cmd
 }
 
+// newWorldExportCmd 导出世界设定包
+func newWorldExportCmd() *cobra.Command {
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "export <id>",
+		Short: "导出世界设定为JSON包",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			database := GetDBOrExit()
+			bundle, err := worldbuilder.ExportWorld(database, args[0])
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+
+			data, err := json.MarshalIndent(bundle, "", "  ")
+			if err != nil {
+				PrintError("序列化失败: %v", err)
+				return
+			}
+
+			if output == "" {
+				output = fmt.Sprintf("world_%s.json", bundle.World.ID)
+			}
+			if err := os.WriteFile(output, data, 0644); err != nil {
+				PrintError("写入文件失败: %v", err)
+				return
+			}
+
+			PrintSuccess("已导出世界: %s", bundle.World.Name)
+			PrintInfo("角色: %d 个", len(bundle.Characters))
+			PrintInfo("文件: %s", output)
+		},
+	}
+
+	cmd.Flags().StringVarP(&output, "output", "o", "", "输出文件路径")
+	return cmd
+}
+
+// newWorldImportCmd 导入世界设定包
+func newWorldImportCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import <file>",
+		Short: "从JSON包导入世界设定",
+		Args:  cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			data, err := os.ReadFile(args[0])
+			if err != nil {
+				PrintError("读取文件失败: %v", err)
+				return
+			}
+
+			bundle, err := worldbuilder.ParseBundle(data)
+			if err != nil {
+				PrintError("%v", err)
+				return
+			}
+
+			database := GetDBOrExit()
+			result, err := worldbuilder.ImportWorld(database, bundle)
+			if err != nil {
+				PrintError("导入失败: %v", err)
+				return
+			}
+
+			PrintSuccess("已导入世界: %s", result.World.Name)
+			PrintInfo("世界ID: %s", result.World.ID)
+			PrintInfo("角色: %d 个", len(bundle.Characters))
+		},
+	}
+}
+
 // printWorldDetail 打印世界详情
 func printWorldDetail(world *models.WorldSetting, database db.Database) {
 	// 基本信息
@@ -204,6 +285,9 @@ func printWorldDetail(world *models.WorldSetting, database db.Database) {
 	if world.Style != "" {
 		fmt.Printf("风格: %s\n", world.Style)
 	}
+	if world.BuildTier != "" {
+		fmt.Printf("构建档位: %s\n", world.BuildTier)
+	}
 	fmt.Printf("ID: %s\n", world.ID)
 	fmt.Println()
 
@@ -1,14 +1,22 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
+	"log"
 	"net/http"
 	"sort"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/config"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/narrative"
+	"github.com/xlei/xupu/pkg/quota"
+	"github.com/xlei/xupu/pkg/templatepack"
 )
 
 type AdminHandler struct {
@@ -24,6 +32,11 @@ func NewAdminHandler(database db.Database) *AdminHandler {
 // ============================================
 
 // GetConfigs 获取所有系统配置
+// @Summary 获取系统配置
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/configs [get]
 func (h *AdminHandler) GetConfigs(c *gin.Context) {
 	configs, err := h.db.GetSysConfigs()
 	if err != nil {
@@ -34,6 +47,14 @@ func (h *AdminHandler) GetConfigs(c *gin.Context) {
 }
 
 // UpdateConfig 更新系统配置
+// @Summary 更新系统配置
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key path string true "配置键"
+// @Param request body models.SysConfig true "配置内容"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/configs/{key} [put]
 func (h *AdminHandler) UpdateConfig(c *gin.Context) {
 	key := c.Param("key")
 	var req models.SysConfig
@@ -54,6 +75,11 @@ func (h *AdminHandler) UpdateConfig(c *gin.Context) {
 }
 
 // SyncConfigs 同步默认系统配置
+// @Summary 同步默认系统配置
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/configs/sync [post]
 func (h *AdminHandler) SyncConfigs(c *gin.Context) {
 	defaultConfigs := []models.SysConfig{
 		{
@@ -98,6 +124,27 @@ func (h *AdminHandler) SyncConfigs(c *gin.Context) {
 			Description: "是否开放用户注册",
 			Group:       "system",
 		},
+		{
+			Key:         quota.ConfigMonthlyTokens,
+			Value:       "0",
+			Type:        "int",
+			Description: "每个用户每月的LLM token上限（0为不限）",
+			Group:       "quota",
+		},
+		{
+			Key:         quota.ConfigMonthlyCost,
+			Value:       "0",
+			Type:        "float",
+			Description: "每个用户每月的LLM费用上限，按模型单价估算（0为不限）",
+			Group:       "quota",
+		},
+		{
+			Key:         quota.ConfigWarnRatio,
+			Value:       "0.8",
+			Type:        "float",
+			Description: "用量达到上限的该比例时提醒用户 (0.0 - 1.0)",
+			Group:       "quota",
+		},
 	}
 
 	syncedCount := 0
@@ -123,6 +170,11 @@ func (h *AdminHandler) SyncConfigs(c *gin.Context) {
 // ============================================
 
 // GetPrompts 获取所有提示词模板
+// @Summary 获取提示词模板列表
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/prompts [get]
 func (h *AdminHandler) GetPrompts(c *gin.Context) {
 	prompts, err := h.db.GetPromptTemplates()
 	if err != nil {
@@ -137,6 +189,12 @@ func (h *AdminHandler) GetPrompts(c *gin.Context) {
 }
 
 // GetPrompt 获取单个提示词模板
+// @Summary 获取提示词模板
+// @Tags admin
+// @Produce json
+// @Param key path string true "提示词键"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/prompts/{key} [get]
 func (h *AdminHandler) GetPrompt(c *gin.Context) {
 	key := c.Param("key")
 	prompt, err := h.db.GetPromptTemplate(key)
@@ -148,6 +206,14 @@ func (h *AdminHandler) GetPrompt(c *gin.Context) {
 }
 
 // UpdatePrompt 更新提示词模板
+// @Summary 更新提示词模板
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param key path string true "提示词键"
+// @Param request body models.PromptTemplate true "提示词模板"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/prompts/{key} [put]
 func (h *AdminHandler) UpdatePrompt(c *gin.Context) {
 	key := c.Param("key")
 	var req models.PromptTemplate
@@ -171,11 +237,22 @@ func (h *AdminHandler) UpdatePrompt(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存提示词失败", err.Error()))
 		return
 	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditUpdate,
+		ResourceType: models.AuditResourceTemplate,
+		ResourceID:   "prompt:" + key,
+		Summary:      fmt.Sprintf("修改提示词 %s 至版本 %d", key, req.Version),
+	}, old, &req)
 
 	c.JSON(http.StatusOK, successResponse(req))
 }
 
 // SyncFromConfig 从配置文件同步到数据库（初始化/重置）
+// @Summary 从配置文件同步提示词
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/sync [post]
 func (h *AdminHandler) SyncFromConfig(c *gin.Context) {
 	cfg := config.Get() // 获取全局配置
 	allPrompts := cfg.GetAllPrompts()
@@ -198,6 +275,12 @@ func (h *AdminHandler) SyncFromConfig(c *gin.Context) {
 
 			if err := h.db.SavePromptTemplate(newPrompt); err == nil {
 				syncedCount++
+				recordAudit(c, &models.AuditLog{
+					Action:       models.AuditCreate,
+					ResourceType: models.AuditResourceTemplate,
+					ResourceID:   "prompt:" + key,
+					Summary:      "从配置文件同步提示词 " + key,
+				}, nil, newPrompt)
 			}
 		}
 	}
@@ -212,6 +295,12 @@ func (h *AdminHandler) SyncFromConfig(c *gin.Context) {
 // Narrative Templates
 // ============================================
 
+// GetStructures 获取所有叙事结构
+// @Summary 获取叙事结构列表
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/structures [get]
 func (h *AdminHandler) GetStructures(c *gin.Context) {
 	templates, err := h.db.GetNarrativeTemplates()
 	if err != nil {
@@ -221,6 +310,15 @@ func (h *AdminHandler) GetStructures(c *gin.Context) {
 	c.JSON(http.StatusOK, successResponse(templates))
 }
 
+// UpdateStructure 更新叙事结构
+// @Summary 更新叙事结构
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "叙事结构ID"
+// @Param request body models.NarrativeTemplate true "叙事结构"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/structures/{id} [put]
 func (h *AdminHandler) UpdateStructure(c *gin.Context) {
 	id := c.Param("id")
 	var req models.NarrativeTemplate
@@ -229,15 +327,34 @@ func (h *AdminHandler) UpdateStructure(c *gin.Context) {
 		return
 	}
 	req.ID = id
+	old, _ := h.db.GetNarrativeTemplate(id)
+	if old != nil {
+		req.Version = old.Version + 1
+		req.UserID = old.UserID
+		req.CreatedAt = old.CreatedAt
+	} else {
+		req.Version = 1
+	}
 
 	if err := h.db.SaveNarrativeTemplate(&req); err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存模板失败", err.Error()))
 		return
 	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditUpdate,
+		ResourceType: models.AuditResourceTemplate,
+		ResourceID:   "structure:" + id,
+		Summary:      fmt.Sprintf("修改叙事结构 %s 至版本 %d", id, req.Version),
+	}, old, &req)
 	c.JSON(http.StatusOK, successResponse(req))
 }
 
 // SyncStructures 同步默认叙事结构
+// @Summary 同步默认叙事结构
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/structures/sync [post]
 func (h *AdminHandler) SyncStructures(c *gin.Context) {
 	templates := []models.NarrativeTemplate{
 		{
@@ -309,6 +426,12 @@ func (h *AdminHandler) SyncStructures(c *gin.Context) {
 			tmpl.IsActive = true
 			if err := h.db.SaveNarrativeTemplate(&tmpl); err == nil {
 				syncedCount++
+				recordAudit(c, &models.AuditLog{
+					Action:       models.AuditCreate,
+					ResourceType: models.AuditResourceTemplate,
+					ResourceID:   "structure:" + tmpl.ID,
+					Summary:      "同步默认叙事结构 " + tmpl.ID,
+				}, nil, &tmpl)
 			}
 		}
 	}
@@ -318,3 +441,312 @@ func (h *AdminHandler) SyncStructures(c *gin.Context) {
 		"synced_count": syncedCount,
 	}))
 }
+
+// ============================================
+// Template Packs
+// ============================================
+
+// ImportTemplatePackRequest 导入模板包请求，pack 与 url 二选一
+type ImportTemplatePackRequest struct {
+	Pack           json.RawMessage `json:"pack" swaggertype:"object"`
+	URL            string          `json:"url"`
+	Conflict       string          `json:"conflict"` // newer/skip/overwrite/rename，默认 newer
+	AllowUnsigned  bool            `json:"allow_unsigned"`
+	AllowUntrusted bool            `json:"allow_untrusted"`
+	DryRun         bool            `json:"dry_run"` // 只返回导入结果，不写入
+}
+
+// ExportTemplatePack 导出模板包，配置了签名私钥时自动签名
+// @Router /api/v1/admin/template-packs/export [get]
+func (h *AdminHandler) ExportTemplatePack(c *gin.Context) {
+	opts := templatepack.ExportOptions{
+		Name:        c.Query("name"),
+		PackVersion: c.DefaultQuery("version", "1.0.0"),
+		Author:      c.Query("author"),
+		Description: c.Query("description"),
+	}
+	if ids := c.Query("structures"); ids != "" {
+		opts.StructureIDs = strings.Split(ids, ",")
+	}
+	if keys := c.Query("prompts"); keys != "" {
+		opts.PromptKeys = strings.Split(keys, ",")
+	}
+
+	pack, err := templatepack.Export(h.db, opts)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("EXPORT_FAILED", "导出模板包失败", err.Error()))
+		return
+	}
+	key, err := templatepack.SigningKeyFromEnv()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SIGNING_KEY_INVALID", "签名私钥配置无效", err.Error()))
+		return
+	}
+	if key != nil {
+		if err := pack.Sign(key); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("SIGN_FAILED", "签名失败", err.Error()))
+			return
+		}
+	}
+	c.JSON(http.StatusOK, successResponse(pack))
+}
+
+// ImportTemplatePack 校验签名后导入模板包，信任列表来自服务端环境变量
+// @Router /api/v1/admin/template-packs/import [post]
+func (h *AdminHandler) ImportTemplatePack(c *gin.Context) {
+	var req ImportTemplatePackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效请求", err.Error()))
+		return
+	}
+	strategy, err := templatepack.ParseConflictStrategy(req.Conflict)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效的冲突处理方式", err.Error()))
+		return
+	}
+
+	data := []byte(req.Pack)
+	switch {
+	case len(req.Pack) > 0:
+	case req.URL != "":
+		if !strings.HasPrefix(req.URL, "http://") && !strings.HasPrefix(req.URL, "https://") {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "url 必须是 http(s) 地址", ""))
+			return
+		}
+		if data, err = templatepack.Load(req.URL); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("DOWNLOAD_FAILED", "下载模板包失败", err.Error()))
+			return
+		}
+	default:
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "需要提供 pack 或 url", ""))
+		return
+	}
+
+	pack, err := templatepack.Parse(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_PACK", "模板包格式无效", err.Error()))
+		return
+	}
+	result, err := templatepack.Install(h.db, pack, templatepack.InstallOptions{
+		Conflict:       strategy,
+		TrustedKeys:    templatepack.TrustedKeysFromEnv(),
+		AllowUnsigned:  req.AllowUnsigned,
+		AllowUntrusted: req.AllowUntrusted,
+		DryRun:         req.DryRun,
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("IMPORT_FAILED", "导入模板包失败", err.Error()))
+		return
+	}
+	if !req.DryRun {
+		recordAudit(c, &models.AuditLog{
+			Action:       models.AuditImport,
+			ResourceType: models.AuditResourceTemplate,
+			ResourceID:   "pack:" + result.Name,
+			Summary:      fmt.Sprintf("导入模板包 %s %s", result.Name, result.PackVersion),
+		}, nil, result.Items)
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"result": result,
+		"counts": result.Counts(),
+	}))
+}
+
+// ============================================
+// Prompt Experiments
+// ============================================
+
+// CreateExperimentRequest 创建提示词实验请求
+type CreateExperimentRequest struct {
+	WorldID  string                     `json:"world_id" binding:"required"`
+	Role     string                     `json:"role"`  // 默认 conflict_designer
+	Phase    int                        `json:"phase"` // 默认 4（冲突系统设计）
+	Variants []ExperimentVariantRequest `json:"variants" binding:"required,min=2,dive"`
+}
+
+// ExperimentVariantRequest 实验变体
+type ExperimentVariantRequest struct {
+	Name         string `json:"name" binding:"required"`
+	SystemPrompt string `json:"system_prompt"`
+	PromptKey    string `json:"prompt_key"` // 引用已保存的提示词模板
+}
+
+// GetExperiments 获取所有提示词实验
+// @Summary 获取提示词实验列表
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/experiments [get]
+func (h *AdminHandler) GetExperiments(c *gin.Context) {
+	experiments, err := h.db.GetPromptExperiments()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取实验失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(experiments))
+}
+
+// GetExperiment 获取单个提示词实验
+// @Summary 获取提示词实验
+// @Tags admin
+// @Produce json
+// @Param id path string true "实验ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/experiments/{id} [get]
+func (h *AdminHandler) GetExperiment(c *gin.Context) {
+	id := c.Param("id")
+	experiment, err := h.db.GetPromptExperiment(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "实验不存在", ""))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(experiment))
+}
+
+// CreateExperiment 创建并异步运行提示词实验
+// @Summary 创建提示词实验
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body CreateExperimentRequest true "实验参数"
+// @Success 202 {object} APIResponse
+// @Router /api/v1/admin/experiments [post]
+func (h *AdminHandler) CreateExperiment(c *gin.Context) {
+	var req CreateExperimentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效请求", err.Error()))
+		return
+	}
+
+	if _, err := h.db.GetWorld(req.WorldID); err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界不存在", ""))
+		return
+	}
+
+	params := narrative.ExperimentParams{
+		WorldID: req.WorldID,
+		Role:    req.Role,
+		Phase:   req.Phase,
+	}
+	if params.Role == "" {
+		params.Role = narrative.DefaultExperimentRole
+	}
+	if params.Phase == 0 {
+		params.Phase = narrative.DefaultExperimentPhase
+	}
+
+	for _, v := range req.Variants {
+		variant := narrative.PromptVariant{Name: strings.TrimSpace(v.Name), SystemPrompt: v.SystemPrompt}
+		if variant.SystemPrompt == "" && v.PromptKey != "" {
+			prompt, err := h.db.GetPromptTemplate(v.PromptKey)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "提示词模板不存在", v.PromptKey))
+				return
+			}
+			variant.SystemPrompt = prompt.Content
+		}
+		params.Variants = append(params.Variants, variant)
+	}
+	// 在保存和启动后台运行之前校验，避免接受后才在后台失败
+	if err := params.Validate(); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "实验参数无效", err.Error()))
+		return
+	}
+
+	variants, _ := json.Marshal(params.Variants)
+	experiment := &models.PromptExperiment{
+		ID:       db.GenerateID("exp"),
+		WorldID:  params.WorldID,
+		Role:     params.Role,
+		Phase:    params.Phase,
+		Status:   models.ExperimentStatusRunning,
+		Variants: models.JSON(variants),
+	}
+	if err := h.db.SavePromptExperiment(experiment); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存实验失败", err.Error()))
+		return
+	}
+
+	go h.runExperiment(experiment, params)
+
+	c.JSON(http.StatusAccepted, successResponse(experiment))
+}
+
+// runExperiment 后台运行实验并保存报告
+func (h *AdminHandler) runExperiment(experiment *models.PromptExperiment, params narrative.ExperimentParams) {
+	engine, err := narrative.NewEvolutionEngine()
+	if err != nil {
+		h.finishExperiment(experiment, nil, err)
+		return
+	}
+
+	report, err := narrative.NewOrchestrator(engine).RunPromptExperiment(params)
+	h.finishExperiment(experiment, report, err)
+}
+
+// finishExperiment 记录实验结果
+func (h *AdminHandler) finishExperiment(experiment *models.PromptExperiment, report *narrative.ExperimentReport, runErr error) {
+	if report != nil {
+		if data, err := json.Marshal(report); err == nil {
+			experiment.Report = models.JSON(data)
+		}
+		experiment.Winner = report.Winner
+	}
+
+	if runErr != nil {
+		experiment.Status = models.ExperimentStatusFailed
+		experiment.Error = runErr.Error()
+	} else {
+		experiment.Status = models.ExperimentStatusCompleted
+	}
+
+	if err := h.db.SavePromptExperiment(experiment); err != nil {
+		log.Printf("[Admin] 保存实验结果失败 %s: %v", experiment.ID, err)
+	}
+}
+
+// ============================================
+// LLM Response Schemas
+// ============================================
+
+// GetSchemaMetrics 获取各提示词角色的响应结构校验与修复统计
+// @Summary 响应结构校验统计
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/llm/schema-metrics [get]
+func (h *AdminHandler) GetSchemaMetrics(c *gin.Context) {
+	metrics := llm.SchemaMetrics()
+
+	calls, repaired, failed := 0, 0, 0
+	for _, m := range metrics {
+		calls += m.Calls
+		repaired += m.Repaired
+		failed += m.Failed
+	}
+	repairRate := 0.0
+	if calls > 0 {
+		repairRate = float64(repaired+failed) / float64(calls)
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"roles":       metrics,
+		"calls":       calls,
+		"repaired":    repaired,
+		"failed":      failed,
+		"repair_rate": repairRate,
+	}))
+}
+
+// GetRequestLogs 按请求ID获取日志
+// @Summary 按请求ID获取日志
+// @Description 获取一次HTTP请求（X-Request-ID）处理过程中各模块输出的日志（内存缓冲）
+// @Tags admin
+// @Produce json
+// @Param traceId path string true "请求ID"
+// @Param level query string false "最低日志级别 debug/info/warn/error"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/logs/{traceId} [get]
+func (h *AdminHandler) GetRequestLogs(c *gin.Context) {
+	respondTraceLogs(c, c.Param("traceId"))
+}
@@ -0,0 +1,89 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/audit"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// ============================================
+// Audit Log
+// ============================================
+
+// recordAudit 记录本次请求造成的内容变更，操作者、API密钥、trace_id 和来源IP取自请求上下文
+func recordAudit(c *gin.Context, entry *models.AuditLog, before, after interface{}) {
+	entry.UserID, _ = GetUserID(c)
+	entry.APIKeyID = requestAPIKeyID(c)
+	entry.TraceID = c.GetString("RequestID")
+	entry.ClientIP = c.ClientIP()
+	entry.Before = audit.Summarize(before)
+	entry.After = audit.Summarize(after)
+	audit.Record(db.Get(), entry)
+}
+
+// GetAuditLogs 查询内容变更记录
+// @Summary 查询内容变更记录
+// @Description 按操作者、资源、项目、动作和时间范围查询，新的在前。时间支持 RFC3339 或 YYYY-MM-DD
+// @Tags admin
+// @Produce json
+// @Param user_id query string false "操作者ID"
+// @Param resource_type query string false "资源类型（world/chapter/template/beat_sheet/style_profile/release/project）"
+// @Param resource_id query string false "资源ID"
+// @Param project_id query string false "项目ID"
+// @Param action query string false "动作（create/update/delete/regenerate/import/publish）"
+// @Param since query string false "起始时间（含）"
+// @Param until query string false "截止时间（不含）"
+// @Param limit query int false "条数，默认100"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/audit [get]
+func (h *AdminHandler) GetAuditLogs(c *gin.Context) {
+	limit, ok := positiveQuery(c, "limit", 100)
+	if !ok {
+		return
+	}
+	filter := models.AuditFilter{
+		UserID:       c.Query("user_id"),
+		Action:       c.Query("action"),
+		ResourceType: c.Query("resource_type"),
+		ResourceID:   c.Query("resource_id"),
+		ProjectID:    c.Query("project_id"),
+		Limit:        limit,
+	}
+	if filter.Since, ok = timeQuery(c, "since"); !ok {
+		return
+	}
+	if filter.Until, ok = timeQuery(c, "until"); !ok {
+		return
+	}
+
+	logs, err := h.db.ListAuditLogs(filter)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "查询变更记录失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"logs":  logs,
+		"count": len(logs),
+	}))
+}
+
+// timeQuery 解析时间参数（RFC3339 或 YYYY-MM-DD，后者按服务器时区），未提供时返回 nil
+func timeQuery(c *gin.Context, key string) (*time.Time, bool) {
+	v := c.Query(key)
+	if v == "" {
+		return nil, true
+	}
+	t, err := time.Parse(time.RFC3339, v)
+	if err != nil {
+		t, err = time.ParseInLocation("2006-01-02", v, time.Local)
+	}
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效的"+key, v))
+		return nil, false
+	}
+	return &t, true
+}
@@ -0,0 +1,67 @@
+// Package handlers 审计日志接口权限测试
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/internal/services/auth"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// TestAuditLogsAdminOnly 审计日志只对管理员开放：未登录返回401，普通用户返回403，管理员可以查询
+func TestAuditLogsAdminOnly(t *testing.T) {
+	ctx := context.Background()
+	authHandler := NewAuthHandler("test-secret")
+
+	user, userToken, _, err := authHandler.authService.Register(ctx, auth.RegisterRequest{Username: "audit_user", Email: "audit_user@example.com", Password: "Passw0rd!x"})
+	if err != nil {
+		t.Fatalf("注册普通用户失败: %v", err)
+	}
+	if user.Tier == "admin" {
+		t.Fatalf("新注册用户不应是管理员")
+	}
+	admin, adminToken, _, err := authHandler.authService.Register(ctx, auth.RegisterRequest{Username: "audit_admin", Email: "audit_admin@example.com", Password: "Passw0rd!x"})
+	if err != nil {
+		t.Fatalf("注册管理员失败: %v", err)
+	}
+	admin.Tier = "admin"
+	if err := repositories.NewUserRepository().Update(ctx, admin); err != nil {
+		t.Fatalf("设置管理员失败: %v", err)
+	}
+
+	router := gin.New()
+	group := router.Group("/api/v1/admin")
+	group.Use(authHandler.AuthMiddleware(), authHandler.AdminMiddleware())
+	group.GET("/audit", NewAdminHandler(db.Get()).GetAuditLogs)
+
+	tests := []struct {
+		name     string
+		token    string
+		wantCode int
+		wantBody string
+	}{
+		{name: "未登录", wantCode: http.StatusUnauthorized},
+		{name: "普通用户", token: userToken, wantCode: http.StatusForbidden, wantBody: "FORBIDDEN"},
+		{name: "管理员", token: adminToken, wantCode: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/admin/audit", nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+			if w.Code != tt.wantCode || !strings.Contains(w.Body.String(), tt.wantBody) {
+				t.Errorf("状态码 = %d, 期望 %d: %s", w.Code, tt.wantCode, w.Body.String())
+			}
+		})
+	}
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/config"
+)
+
+// ============================================
+// Config Reload
+// ============================================
+
+// ReloadConfig 热加载配置文件
+// @Summary 热加载配置文件
+// @Description 重新读取配置文件（含环境配置文件和 XUPU__ 环境变量覆盖），热替换 LLM 提供商、模块映射、限流和重试配置；其余配置需重启生效。校验失败时保持当前配置
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Failure 422 {object} APIResponse
+// @Router /api/v1/admin/config/reload [post]
+func (h *AdminHandler) ReloadConfig(c *gin.Context) {
+	result, err := config.Reload()
+	if err != nil {
+		requestLogger(c).Warn("配置热加载失败", "error", err)
+		c.JSON(http.StatusUnprocessableEntity, errorResponse("CONFIG_INVALID", "配置热加载失败，当前配置未改变", err.Error()))
+		return
+	}
+	requestLogger(c).Info("配置已热加载", "generation", result.Generation, "changes", len(result.Changes))
+	c.JSON(http.StatusOK, successResponse(result))
+}
@@ -0,0 +1,223 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/orchestrator"
+)
+
+// ============================================
+// Cron Jobs
+// ============================================
+
+// CronJobRequest 创建或更新定时任务请求
+type CronJobRequest struct {
+	Name     string          `json:"name" binding:"required"`
+	Kind     string          `json:"kind" binding:"required"` // 见 /admin/cron-kinds
+	Spec     string          `json:"spec" binding:"required"` // cron 表达式：分 时 日 月 周，或 @daily 等
+	Timezone string          `json:"timezone"`                // IANA 时区，为空使用服务器时区
+	Params   json.RawMessage `json:"params" swaggertype:"object"`
+	Enabled  *bool           `json:"enabled"` // 默认启用
+}
+
+// GetCronKinds 获取可调度的任务类型
+// @Summary 获取定时任务类型
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/cron-kinds [get]
+func (h *AdminHandler) GetCronKinds(c *gin.Context) {
+	c.JSON(http.StatusOK, successResponse(orchestrator.CronKinds()))
+}
+
+// GetCronJobs 获取所有定时任务
+// @Summary 获取定时任务
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/cron-jobs [get]
+func (h *AdminHandler) GetCronJobs(c *gin.Context) {
+	jobs, err := h.db.ListCronJobs()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取定时任务失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(jobs))
+}
+
+// GetCronJob 获取单个定时任务
+// @Summary 获取定时任务详情
+// @Tags admin
+// @Produce json
+// @Param id path string true "定时任务ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/cron-jobs/{id} [get]
+func (h *AdminHandler) GetCronJob(c *gin.Context) {
+	job, err := h.db.GetCronJob(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "定时任务不存在", ""))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(job))
+}
+
+// CreateCronJob 创建定时任务
+// @Summary 创建定时任务
+// @Description 按 cron 表达式周期执行的任务，如每晚抓取排行榜、每周复查连续性、定时发布连载更新
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body CronJobRequest true "定时任务"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/admin/cron-jobs [post]
+func (h *AdminHandler) CreateCronJob(c *gin.Context) {
+	var req CronJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效请求", err.Error()))
+		return
+	}
+
+	job := &models.CronJob{ID: db.GenerateID("cron")}
+	if userID, ok := GetUserID(c); ok {
+		job.CreatedBy = userID
+	}
+	if !h.applyCronJobRequest(c, job, &req) {
+		return
+	}
+	if err := h.db.SaveCronJob(job); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存定时任务失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, successResponse(job))
+}
+
+// UpdateCronJob 更新定时任务，下次执行时间按新的表达式重新计算
+// @Summary 更新定时任务
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "定时任务ID"
+// @Param request body CronJobRequest true "定时任务"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/cron-jobs/{id} [put]
+func (h *AdminHandler) UpdateCronJob(c *gin.Context) {
+	job, err := h.db.GetCronJob(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "定时任务不存在", ""))
+		return
+	}
+
+	var req CronJobRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效请求", err.Error()))
+		return
+	}
+	if !h.applyCronJobRequest(c, job, &req) {
+		return
+	}
+	if err := h.db.SaveCronJob(job); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存定时任务失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(job))
+}
+
+// DeleteCronJob 删除定时任务及其执行记录
+// @Summary 删除定时任务
+// @Tags admin
+// @Produce json
+// @Param id path string true "定时任务ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/cron-jobs/{id} [delete]
+func (h *AdminHandler) DeleteCronJob(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.db.GetCronJob(id); err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "定时任务不存在", ""))
+		return
+	}
+	if err := h.db.DeleteCronJob(id); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "删除定时任务失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"id": id}))
+}
+
+// RunCronJob 立即执行一次定时任务
+// @Summary 手动执行定时任务
+// @Description 立即提交一次执行，不影响下次执行时间；上一次执行尚未结束时记录为跳过
+// @Tags admin
+// @Produce json
+// @Param id path string true "定时任务ID"
+// @Success 202 {object} APIResponse
+// @Router /api/v1/admin/cron-jobs/{id}/run [post]
+func (h *AdminHandler) RunCronJob(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.db.GetCronJob(id); err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "定时任务不存在", ""))
+		return
+	}
+
+	run, err := orchestrator.RunCronJob(h.db, id)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("RUN_FAILED", "执行定时任务失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusAccepted, successResponse(run))
+}
+
+// GetCronRuns 获取定时任务的执行记录，新的在前
+// @Summary 获取定时任务执行记录
+// @Tags admin
+// @Produce json
+// @Param id path string true "定时任务ID"
+// @Param limit query int false "返回条数，默认50"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/cron-jobs/{id}/runs [get]
+func (h *AdminHandler) GetCronRuns(c *gin.Context) {
+	id := c.Param("id")
+	if _, err := h.db.GetCronJob(id); err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "定时任务不存在", ""))
+		return
+	}
+
+	limit := 50
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效的limit", v))
+			return
+		}
+		limit = n
+	}
+
+	runs, err := h.db.ListCronRuns(id, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取执行记录失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(runs))
+}
+
+// applyCronJobRequest 把请求写入任务并校验，失败时已写入响应
+func (h *AdminHandler) applyCronJobRequest(c *gin.Context, job *models.CronJob, req *CronJobRequest) bool {
+	job.Name = req.Name
+	job.Kind = req.Kind
+	job.Spec = req.Spec
+	job.Timezone = req.Timezone
+	job.Params = models.JSON(req.Params)
+	job.Enabled = req.Enabled == nil || *req.Enabled
+
+	if err := orchestrator.PrepareCronJob(job, time.Now()); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_CRON_JOB", "定时任务无效", err.Error()))
+		return false
+	}
+	return true
+}
@@ -0,0 +1,290 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/examples"
+)
+
+// ============================================
+// Few-shot Examples
+// ============================================
+
+// PromptExampleRequest 创建或更新少样本示例请求
+type PromptExampleRequest struct {
+	Role    string   `json:"role" binding:"required"` // 提示词角色，如 writer.scene
+	Genre   string   `json:"genre"`                   // 题材，为空表示通用
+	Title   string   `json:"title"`
+	Input   string   `json:"input"`
+	Output  string   `json:"output" binding:"required"`
+	Tags    []string `json:"tags"`
+	Enabled *bool    `json:"enabled"` // 默认启用
+}
+
+// ExampleSettingsRequest 运行时切换示例注入模式请求
+type ExampleSettingsRequest struct {
+	Reset   bool    `json:"reset"` // 为 true 时恢复为配置文件的值，忽略其余字段
+	Mode    string  `json:"mode"`  // off/on/ab
+	Max     int     `json:"max"`
+	Holdout float64 `json:"holdout"`
+}
+
+// GetPromptExamples 获取少样本示例
+// @Summary 获取少样本示例
+// @Tags admin
+// @Produce json
+// @Param role query string false "按提示词角色过滤"
+// @Param genre query string false "按题材过滤"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/examples [get]
+func (h *AdminHandler) GetPromptExamples(c *gin.Context) {
+	list, err := h.db.ListPromptExamples(c.Query("role"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取示例失败", err.Error()))
+		return
+	}
+	if genre := c.Query("genre"); genre != "" {
+		filtered := list[:0]
+		for _, e := range list {
+			if e.Genre == genre {
+				filtered = append(filtered, e)
+			}
+		}
+		list = filtered
+	}
+	c.JSON(http.StatusOK, successResponse(list))
+}
+
+// GetPromptExample 获取单个少样本示例
+// @Summary 获取少样本示例详情
+// @Tags admin
+// @Produce json
+// @Param id path string true "示例ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/examples/{id} [get]
+func (h *AdminHandler) GetPromptExample(c *gin.Context) {
+	example, err := h.db.GetPromptExample(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "示例不存在", ""))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(example))
+}
+
+// CreatePromptExample 创建少样本示例
+// @Summary 创建少样本示例
+// @Description 示例按提示词角色和题材检索，注入模式见 /admin/examples/settings
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body PromptExampleRequest true "示例"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/admin/examples [post]
+func (h *AdminHandler) CreatePromptExample(c *gin.Context) {
+	var req PromptExampleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效请求", err.Error()))
+		return
+	}
+
+	example := &models.PromptExample{ID: db.GenerateID("example")}
+	if userID, ok := GetUserID(c); ok {
+		example.CreatedBy = userID
+	}
+	applyPromptExampleRequest(example, &req)
+	if err := h.db.SavePromptExample(example); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存示例失败", err.Error()))
+		return
+	}
+	invalidateExamples()
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditCreate,
+		ResourceType: models.AuditResourceTemplate,
+		ResourceID:   "example:" + example.ID,
+		Summary:      fmt.Sprintf("新增 %s 的少样本示例", example.Role),
+	}, nil, example)
+
+	c.JSON(http.StatusCreated, successResponse(example))
+}
+
+// UpdatePromptExample 更新少样本示例
+// @Summary 更新少样本示例
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "示例ID"
+// @Param request body PromptExampleRequest true "示例"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/examples/{id} [put]
+func (h *AdminHandler) UpdatePromptExample(c *gin.Context) {
+	example, err := h.db.GetPromptExample(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "示例不存在", ""))
+		return
+	}
+
+	var req PromptExampleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效请求", err.Error()))
+		return
+	}
+	before := *example
+	applyPromptExampleRequest(example, &req)
+	if err := h.db.SavePromptExample(example); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存示例失败", err.Error()))
+		return
+	}
+	invalidateExamples()
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditUpdate,
+		ResourceType: models.AuditResourceTemplate,
+		ResourceID:   "example:" + example.ID,
+		Summary:      fmt.Sprintf("修改 %s 的少样本示例", example.Role),
+	}, &before, example)
+
+	c.JSON(http.StatusOK, successResponse(example))
+}
+
+// DeletePromptExample 删除少样本示例
+// @Summary 删除少样本示例
+// @Tags admin
+// @Produce json
+// @Param id path string true "示例ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/examples/{id} [delete]
+func (h *AdminHandler) DeletePromptExample(c *gin.Context) {
+	id := c.Param("id")
+	example, err := h.db.GetPromptExample(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "示例不存在", ""))
+		return
+	}
+	if err := h.db.DeletePromptExample(id); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "删除示例失败", err.Error()))
+		return
+	}
+	invalidateExamples()
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditDelete,
+		ResourceType: models.AuditResourceTemplate,
+		ResourceID:   "example:" + id,
+		Summary:      fmt.Sprintf("删除 %s 的少样本示例", example.Role),
+	}, example, nil)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{"id": id}))
+}
+
+// GetExampleSettings 获取示例注入模式
+// @Summary 获取少样本示例注入模式
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/examples/settings [get]
+func (h *AdminHandler) GetExampleSettings(c *gin.Context) {
+	lib := examples.Default()
+	if lib == nil {
+		c.JSON(http.StatusServiceUnavailable, errorResponse("NOT_AVAILABLE", "示例库未启用", ""))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(lib.Settings()))
+}
+
+// UpdateExampleSettings 运行时切换示例注入模式
+// @Summary 切换少样本示例注入模式
+// @Description 临时覆盖 llm.examples 配置（不写回配置文件，配置热加载或重启后恢复），用于开启或结束 A/B 对照；切换后清空对照统计
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param request body ExampleSettingsRequest true "注入模式"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/examples/settings [put]
+func (h *AdminHandler) UpdateExampleSettings(c *gin.Context) {
+	lib := examples.Default()
+	if lib == nil {
+		c.JSON(http.StatusServiceUnavailable, errorResponse("NOT_AVAILABLE", "示例库未启用", ""))
+		return
+	}
+
+	var req ExampleSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效请求", err.Error()))
+		return
+	}
+	var override *config.ExamplesConfig
+	if !req.Reset {
+		override = &config.ExamplesConfig{Mode: strings.TrimSpace(req.Mode), Max: req.Max, Holdout: req.Holdout}
+	}
+	before := lib.Settings()
+	if err := lib.SetOverride(override); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效的注入模式", err.Error()))
+		return
+	}
+	after := lib.Settings()
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditUpdate,
+		ResourceType: models.AuditResourceTemplate,
+		ResourceID:   "example-settings",
+		Summary:      fmt.Sprintf("少样本示例注入模式 %s → %s", before.Mode, after.Mode),
+	}, before, after)
+
+	c.JSON(http.StatusOK, successResponse(after))
+}
+
+// GetExampleStats 获取示例注入的对照统计
+// @Summary 获取少样本示例对照统计
+// @Description 按提示词角色列出注入示例组和对照组的结构校验通过率、修复次数、平均耗时和示例token开销
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/examples/stats [get]
+func (h *AdminHandler) GetExampleStats(c *gin.Context) {
+	lib := examples.Default()
+	if lib == nil {
+		c.JSON(http.StatusServiceUnavailable, errorResponse("NOT_AVAILABLE", "示例库未启用", ""))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"settings": lib.Settings(),
+		"arms":     lib.Stats(),
+	}))
+}
+
+// ResetExampleStats 清空对照统计
+// @Summary 清空少样本示例对照统计
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/examples/stats [delete]
+func (h *AdminHandler) ResetExampleStats(c *gin.Context) {
+	lib := examples.Default()
+	if lib == nil {
+		c.JSON(http.StatusServiceUnavailable, errorResponse("NOT_AVAILABLE", "示例库未启用", ""))
+		return
+	}
+	lib.ResetStats()
+	c.JSON(http.StatusOK, successResponse(nil))
+}
+
+// applyPromptExampleRequest 把请求写入示例
+func applyPromptExampleRequest(example *models.PromptExample, req *PromptExampleRequest) {
+	example.Role = strings.TrimSpace(req.Role)
+	example.Genre = strings.TrimSpace(req.Genre)
+	example.Title = req.Title
+	example.Input = req.Input
+	example.Output = req.Output
+	example.Tags = req.Tags
+	example.Enabled = req.Enabled == nil || *req.Enabled
+}
+
+// invalidateExamples 示例变更后清除示例库缓存，下一次调用即使用新示例
+func invalidateExamples() {
+	if lib := examples.Default(); lib != nil {
+		lib.Invalidate()
+	}
+}
@@ -0,0 +1,98 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/quota"
+)
+
+// ============================================
+// User Quotas
+// ============================================
+
+// UserQuotaRequest 设置用户月度预算请求，字段为空时沿用系统配置中的默认值，上限为 0 表示不限
+type UserQuotaRequest struct {
+	MonthlyTokens *int     `json:"monthly_tokens" binding:"omitempty,min=0"`
+	MonthlyCost   *float64 `json:"monthly_cost" binding:"omitempty,min=0"`
+	WarnRatio     *float64 `json:"warn_ratio" binding:"omitempty,min=0,max=1"`
+	Note          string   `json:"note"`
+}
+
+// GetUserQuota 获取用户的预算设置和本月使用情况
+// @Summary 获取用户预算
+// @Tags admin
+// @Produce json
+// @Param userId path string true "用户ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/quotas/{userId} [get]
+func (h *AdminHandler) GetUserQuota(c *gin.Context) {
+	userID := c.Param("userId")
+	status, err := quota.GetStatus(h.db, userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取预算失败", err.Error()))
+		return
+	}
+
+	// 没有单独设置时 override 为空
+	override, _ := h.db.GetUserQuota(userID)
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"status":   status,
+		"override": override,
+	}))
+}
+
+// UpdateUserQuota 设置用户的月度预算
+// @Summary 设置用户预算
+// @Description 覆盖系统配置中的默认上限（quota_monthly_tokens、quota_monthly_cost、quota_warn_ratio）
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param userId path string true "用户ID"
+// @Param request body UserQuotaRequest true "预算"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/quotas/{userId} [put]
+func (h *AdminHandler) UpdateUserQuota(c *gin.Context) {
+	userID := c.Param("userId")
+	if _, err := h.db.GetUser(userID); err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "用户不存在", ""))
+		return
+	}
+
+	var req UserQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效请求", err.Error()))
+		return
+	}
+
+	q := &models.UserQuota{
+		UserID:        userID,
+		MonthlyTokens: req.MonthlyTokens,
+		MonthlyCost:   req.MonthlyCost,
+		WarnRatio:     req.WarnRatio,
+		Note:          req.Note,
+	}
+	if err := h.db.SaveUserQuota(q); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存预算失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(q))
+}
+
+// DeleteUserQuota 删除用户的预算设置，恢复使用系统默认值
+// @Summary 恢复用户默认预算
+// @Tags admin
+// @Produce json
+// @Param userId path string true "用户ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/quotas/{userId} [delete]
+func (h *AdminHandler) DeleteUserQuota(c *gin.Context) {
+	userID := c.Param("userId")
+	if err := h.db.DeleteUserQuota(userID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "删除预算失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"user_id": userID}))
+}
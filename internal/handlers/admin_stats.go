@@ -0,0 +1,209 @@
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// ============================================
+// System Stats
+// ============================================
+
+// TokenUsageDay 一天的LLM用量，models 为按提供商/模型的明细
+type TokenUsageDay struct {
+	Date  string               `json:"date"`
+	Usage llm.Usage            `json:"usage"`
+	Items []models.LLMUsageDay `json:"models"`
+}
+
+// GetStatsOverview 系统概况
+// @Summary 系统概况
+// @Description 用户数与活跃用户数、各状态项目数、进程启动以来的LLM请求统计和今日用量
+// @Tags admin
+// @Produce json
+// @Param active_days query int false "活跃用户统计窗口（天），默认7"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/stats/overview [get]
+func (h *AdminHandler) GetStatsOverview(c *gin.Context) {
+	activeDays, ok := positiveQuery(c, "active_days", 7)
+	if !ok {
+		return
+	}
+
+	total, active, err := h.db.CountUsers(time.Now().AddDate(0, 0, -activeDays))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "统计用户失败", err.Error()))
+		return
+	}
+	byStatus, err := h.db.CountProjectsByStatus()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "统计项目失败", err.Error()))
+		return
+	}
+	projects := 0
+	for _, n := range byStatus {
+		projects += n
+	}
+
+	var today llm.Usage
+	if days, err := h.db.ListLLMUsage(time.Now().Format("2006-01-02")); err == nil {
+		for _, d := range days {
+			today.Add(usageOfDay(d))
+		}
+	}
+	inflight, limit := llm.ConcurrencyStats()
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"users": gin.H{
+			"total":       total,
+			"active":      active,
+			"active_days": activeDays,
+		},
+		"projects": gin.H{
+			"total":     projects,
+			"by_status": byStatus,
+		},
+		"llm": gin.H{
+			"calls":          llm.CallMetrics(),
+			"today":          today,
+			"inflight":       inflight,
+			"max_concurrent": limit,
+		},
+	}))
+}
+
+// GetTokenUsage 每日LLM用量
+// @Summary 每日token用量
+// @Description 最近 days 天（含今天）的每日token和费用，没有调用的日期补零
+// @Tags admin
+// @Produce json
+// @Param days query int false "天数，默认30"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/stats/tokens [get]
+func (h *AdminHandler) GetTokenUsage(c *gin.Context) {
+	n, ok := positiveQuery(c, "days", 30)
+	if !ok {
+		return
+	}
+
+	start := time.Now().AddDate(0, 0, 1-n)
+	rows, err := h.db.ListLLMUsage(start.Format("2006-01-02"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取用量失败", err.Error()))
+		return
+	}
+
+	days := make([]TokenUsageDay, n)
+	index := make(map[string]int, n)
+	for i := range days {
+		days[i] = TokenUsageDay{Date: start.AddDate(0, 0, i).Format("2006-01-02"), Items: []models.LLMUsageDay{}}
+		index[days[i].Date] = i
+	}
+	var total llm.Usage
+	for _, row := range rows {
+		i, ok := index[row.Date]
+		if !ok {
+			continue
+		}
+		days[i].Usage.Add(usageOfDay(row))
+		days[i].Items = append(days[i].Items, row)
+		total.Add(usageOfDay(row))
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"days":  days,
+		"total": total,
+	}))
+}
+
+// GetFailingRoles 失败最多的提示词角色
+// @Summary 失败最多的提示词角色
+// @Description 按请求失败数与修复后仍未通过校验数之和降序，只列出有失败的角色（进程启动以来）
+// @Tags admin
+// @Produce json
+// @Param limit query int false "返回条数，默认10"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/stats/failing-roles [get]
+func (h *AdminHandler) GetFailingRoles(c *gin.Context) {
+	limit, ok := positiveQuery(c, "limit", 10)
+	if !ok {
+		return
+	}
+
+	roles := make([]llm.SchemaStats, 0)
+	for _, st := range llm.SchemaMetrics() {
+		if st.Failed+st.CallErrors > 0 {
+			roles = append(roles, st)
+		}
+	}
+	sort.SliceStable(roles, func(i, j int) bool {
+		return roles[i].Failed+roles[i].CallErrors > roles[j].Failed+roles[j].CallErrors
+	})
+	if len(roles) > limit {
+		roles = roles[:limit]
+	}
+	c.JSON(http.StatusOK, successResponse(roles))
+}
+
+// GetSlowestCalls 耗时最长的LLM请求
+// @Summary 最慢的LLM请求
+// @Description 进程启动以来耗时最长的请求，按耗时降序
+// @Tags admin
+// @Produce json
+// @Param limit query int false "返回条数，默认20"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/stats/slow-calls [get]
+func (h *AdminHandler) GetSlowestCalls(c *gin.Context) {
+	limit, ok := positiveQuery(c, "limit", 20)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(llm.SlowestCalls(limit)))
+}
+
+// GetStorageUsage 存储占用
+// @Summary 存储占用
+// @Description 数据库大小和各表行数（PostgreSQL 另含各表占用）
+// @Tags admin
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/admin/stats/storage [get]
+func (h *AdminHandler) GetStorageUsage(c *gin.Context) {
+	usage, err := h.db.StorageUsage()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取存储占用失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(usage))
+}
+
+// usageOfDay 把每日用量行转换为 llm.Usage 便于累加
+func usageOfDay(d models.LLMUsageDay) llm.Usage {
+	return llm.Usage{
+		Requests:     d.Requests,
+		InputTokens:  d.InputTokens,
+		OutputTokens: d.OutputTokens,
+		TotalTokens:  d.TotalTokens,
+		Cost:         d.Cost,
+	}
+}
+
+// positiveQuery 读取正整数查询参数，缺省时返回 def，非法时已写入响应
+func positiveQuery(c *gin.Context, key string, def int) (int, bool) {
+	v := c.Query(key)
+	if v == "" {
+		return def, true
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效的"+key, v))
+		return 0, false
+	}
+	return n, true
+}
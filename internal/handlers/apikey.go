@@ -0,0 +1,331 @@
+// Package handlers HTTP处理器 - API密钥
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/services/auth"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/logx"
+)
+
+// DefaultAPIKeyRateLimit 未设置时每个API密钥每分钟的请求数上限
+const DefaultAPIKeyRateLimit = 60
+
+// 上下文键
+const (
+	ctxAPIKeyID = "api_key_id" // 请求使用的API密钥
+	ctxJobID    = "job_id"     // 请求提交的任务/批次/项目，写入API密钥请求记录
+)
+
+// APIKeyHandler API密钥处理器
+type APIKeyHandler struct {
+	db db.Database
+}
+
+// NewAPIKeyHandler 创建API密钥处理器
+func NewAPIKeyHandler(database db.Database) *APIKeyHandler {
+	return &APIKeyHandler{db: database}
+}
+
+// APIKeyResponse API密钥响应，创建时附带明文密钥
+type APIKeyResponse struct {
+	*models.APIKey
+	Key string `json:"key,omitempty"`
+}
+
+// ListAPIKeys 列出当前用户的API密钥
+// @Summary 列出API密钥
+// @Tags api-keys
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/api-keys [get]
+func (h *APIKeyHandler) ListAPIKeys(c *gin.Context) {
+	userID, _ := GetUserID(c)
+
+	keys, err := h.db.ListAPIKeys(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取API密钥失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(keys))
+}
+
+// CreateAPIKey 创建API密钥
+// @Summary 创建API密钥
+// @Description 供脚本和CI调用接口的长期密钥，通过 X-API-Key 请求头或 Authorization: Bearer 传入；明文只在创建时返回
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param request body CreateAPIKeyRequest true "API密钥"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/api-keys [post]
+func (h *APIKeyHandler) CreateAPIKey(c *gin.Context) {
+	var req CreateAPIKeyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未授权", ""))
+		return
+	}
+	// 密钥不能再签发密钥，否则泄露一把即可无限续期
+	if _, viaKey := c.Get(ctxAPIKeyID); viaKey {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "不能使用API密钥创建API密钥", ""))
+		return
+	}
+
+	scopes := req.Scopes
+	if len(scopes) == 0 {
+		scopes = models.APIKeyScopes
+	}
+	for _, scope := range scopes {
+		if !models.ValidAPIKeyScope(scope) {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_SCOPE", "不支持的权限范围", scope))
+			return
+		}
+	}
+
+	plain, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("CREATE_FAILED", "生成API密钥失败", err.Error()))
+		return
+	}
+	key := &models.APIKey{
+		ID:        db.GenerateID("apikey"),
+		UserID:    userID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   hash,
+		Scopes:    scopes,
+		RateLimit: req.RateLimit,
+	}
+	if req.ExpiresInDays > 0 {
+		expires := time.Now().AddDate(0, 0, req.ExpiresInDays)
+		key.ExpiresAt = &expires
+	}
+	if err := h.db.SaveAPIKey(key); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存API密钥失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, successResponse(APIKeyResponse{APIKey: key, Key: plain}))
+}
+
+// RevokeAPIKey 吊销API密钥，吊销后立即失效，请求记录保留
+// @Summary 吊销API密钥
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API密钥ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/api-keys/{id} [delete]
+func (h *APIKeyHandler) RevokeAPIKey(c *gin.Context) {
+	key, ok := h.loadOwnedKey(c)
+	if !ok {
+		return
+	}
+
+	if key.RevokedAt == nil {
+		now := time.Now()
+		key.RevokedAt = &now
+		if err := h.db.SaveAPIKey(key); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "吊销API密钥失败", err.Error()))
+			return
+		}
+	}
+	c.JSON(http.StatusOK, successResponse(key))
+}
+
+// ListAPIKeyAudits 获取API密钥发起的写请求记录，新的在前
+// @Summary 获取API密钥请求记录
+// @Description 每条记录包含请求路径、响应状态和该请求提交的任务/批次/项目ID
+// @Tags api-keys
+// @Produce json
+// @Param id path string true "API密钥ID"
+// @Param limit query int false "返回条数，默认50"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/api-keys/{id}/audits [get]
+func (h *APIKeyHandler) ListAPIKeyAudits(c *gin.Context) {
+	key, ok := h.loadOwnedKey(c)
+	if !ok {
+		return
+	}
+	limit, ok := positiveQuery(c, "limit", 50)
+	if !ok {
+		return
+	}
+
+	audits, err := h.db.ListAPIKeyAudits(key.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取请求记录失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(audits))
+}
+
+// loadOwnedKey 加载当前用户的API密钥，失败时已写入响应
+func (h *APIKeyHandler) loadOwnedKey(c *gin.Context) (*models.APIKey, bool) {
+	key, err := h.db.GetAPIKey(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "API密钥不存在", ""))
+		return nil, false
+	}
+	userID, exists := GetUserID(c)
+	if !exists || key.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权访问", ""))
+		return nil, false
+	}
+	return key, true
+}
+
+// ============================================
+// 认证
+// ============================================
+
+// apiKeyFromRequest 请求携带的API密钥：X-API-Key 请求头，或 Authorization: Bearer xpk_...
+func apiKeyFromRequest(c *gin.Context) string {
+	if key := c.GetHeader("X-API-Key"); key != "" {
+		return key
+	}
+	if header := c.GetHeader("Authorization"); len(header) > 7 && header[:7] == "Bearer " && auth.IsAPIKey(header[7:]) {
+		return header[7:]
+	}
+	return ""
+}
+
+// apiKeyError API密钥认证失败：HTTP状态码、错误码和提示，限流时附带距窗口重置的时间
+type apiKeyError struct {
+	status  int
+	code    string
+	message string
+	details string
+	retry   time.Duration
+}
+
+func (e *apiKeyError) Error() string {
+	return e.message
+}
+
+// validateAPIKey 校验API密钥的有效期、所需权限范围和每分钟请求数，返回密钥及其所属用户
+// HTTP 中间件和 gRPC 拦截器共用
+func validateAPIKey(database db.Database, plain, scope string, now time.Time) (*models.APIKey, *models.User, *apiKeyError) {
+	key, err := database.GetAPIKeyByHash(auth.HashAPIKey(plain))
+	if err != nil || !key.Active(now) {
+		return nil, nil, &apiKeyError{status: http.StatusUnauthorized, code: "INVALID_API_KEY", message: "无效、已吊销或已过期的API密钥"}
+	}
+	if !key.HasScope(scope) {
+		return nil, nil, &apiKeyError{status: http.StatusForbidden, code: "INSUFFICIENT_SCOPE", message: "API密钥没有该权限", details: scope}
+	}
+
+	limit := key.RateLimit
+	if limit <= 0 {
+		limit = DefaultAPIKeyRateLimit
+	}
+	if ok, retry := apiKeyLimiter.allow(key.ID, limit, now); !ok {
+		return nil, nil, &apiKeyError{status: http.StatusTooManyRequests, code: "RATE_LIMITED", message: "API密钥请求过于频繁", retry: retry}
+	}
+
+	user, err := database.GetUser(key.UserID)
+	if err != nil || user.Status != "active" {
+		return nil, nil, &apiKeyError{status: http.StatusUnauthorized, code: "INVALID_API_KEY", message: "API密钥所属用户不存在或已被禁用"}
+	}
+
+	// 最近使用时间精确到分钟即可，避免每个请求都写库
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > time.Minute {
+		go database.TouchAPIKey(key.ID, now)
+	}
+	return key, user, nil
+}
+
+// authenticateAPIKey 用API密钥认证请求：校验有效期、权限范围和每分钟请求数，写请求结束后记录请求日志
+func authenticateAPIKey(c *gin.Context, plain string) {
+	database := db.Get()
+	now := time.Now()
+	readOnly := c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead
+	scope := models.APIKeyScopeWrite
+	if readOnly {
+		scope = models.APIKeyScopeRead
+	}
+	key, user, keyErr := validateAPIKey(database, plain, scope, now)
+	if keyErr != nil {
+		if keyErr.retry > 0 {
+			c.Header("Retry-After", strconv.Itoa(int(keyErr.retry.Seconds()+0.999)))
+		}
+		c.AbortWithStatusJSON(keyErr.status, errorResponse(keyErr.code, keyErr.message, keyErr.details))
+		return
+	}
+
+	c.Set("user_id", user.ID)
+	c.Set("user", user)
+	c.Set(ctxAPIKeyID, key.ID)
+	c.Next()
+
+	if readOnly {
+		return
+	}
+	audit := &models.APIKeyAudit{
+		ID:        db.GenerateID("keyaudit"),
+		KeyID:     key.ID,
+		UserID:    user.ID,
+		Method:    c.Request.Method,
+		Path:      c.Request.URL.Path,
+		Status:    c.Writer.Status(),
+		JobID:     c.GetString(ctxJobID),
+		ClientIP:  c.ClientIP(),
+		CreatedAt: now,
+	}
+	if err := database.SaveAPIKeyAudit(audit); err != nil {
+		logx.L().Warn("保存API密钥请求记录失败", "key", key.ID, "error", err)
+	}
+}
+
+// recordJob 记录请求提交的任务/批次/项目，通过API密钥发起时写入请求记录
+func recordJob(c *gin.Context, jobID string) {
+	c.Set(ctxJobID, jobID)
+}
+
+// requestAPIKeyID 请求使用的API密钥，JWT 会话时为空
+func requestAPIKeyID(c *gin.Context) string {
+	return c.GetString(ctxAPIKeyID)
+}
+
+// ============================================
+// 限流
+// ============================================
+
+// keyLimiter 按API密钥的固定窗口限流，窗口为一分钟
+type keyLimiter struct {
+	mu      sync.Mutex
+	windows map[string]*keyWindow
+}
+
+type keyWindow struct {
+	start time.Time
+	count int
+}
+
+var apiKeyLimiter = &keyLimiter{windows: make(map[string]*keyWindow)}
+
+// allow 是否放行本次请求，不放行时返回距窗口重置的时间
+func (l *keyLimiter) allow(keyID string, limit int, now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	w, ok := l.windows[keyID]
+	if !ok || now.Sub(w.start) >= time.Minute {
+		w = &keyWindow{start: now}
+		l.windows[keyID] = w
+	}
+	if w.count >= limit {
+		return false, w.start.Add(time.Minute).Sub(now)
+	}
+	w.count++
+	return true, 0
+}
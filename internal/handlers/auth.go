@@ -451,9 +451,15 @@ func getJWTSecret() string {
 	return secret
 }
 
-// AuthMiddleware JWT认证中间件
+// AuthMiddleware JWT认证中间件，同时接受API密钥
 func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
+		// API密钥（脚本/CI）
+		if key := apiKeyFromRequest(c); key != "" {
+			authenticateAPIKey(c, key)
+			return
+		}
+
 		// 从Authorization header获取token
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -505,6 +511,19 @@ func (h *AuthHandler) AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
+// AdminMiddleware 管理员认证中间件，须在 AuthMiddleware 之后使用，只允许 admin 等级的用户访问
+func (h *AuthHandler) AdminMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		value, _ := c.Get("user")
+		user, ok := value.(*models.User)
+		if !ok || user.Tier != "admin" {
+			c.AbortWithStatusJSON(http.StatusForbidden, errorResponse("FORBIDDEN", "需要管理员权限", ""))
+			return
+		}
+		c.Next()
+	}
+}
+
 // GetUserID 从上下文获取用户ID的辅助函数
 func GetUserID(c *gin.Context) (string, bool) {
 	userID, exists := c.Get("user_id")
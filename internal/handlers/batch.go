@@ -0,0 +1,165 @@
+// Package handlers HTTP处理器 - 批量创作
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/orchestrator"
+	"github.com/xlei/xupu/pkg/scheduler"
+)
+
+// BatchHandler 批量创作处理器
+type BatchHandler struct{}
+
+// NewBatchHandler 创建批量创作处理器
+func NewBatchHandler() *BatchHandler {
+	return &BatchHandler{}
+}
+
+// BatchStatusResponse 批量创作状态响应
+type BatchStatusResponse struct {
+	*orchestrator.BatchStatus
+	LLMInFlight    int `json:"llm_in_flight"`   // 全局正在进行的LLM请求数
+	LLMConcurrency int `json:"llm_concurrency"` // 全局LLM并发上限，0表示不限制
+}
+
+// CreateBatch 批量创建项目
+// @Summary 批量创建项目
+// @Description 按参数矩阵一次提交多个异步创作任务，每项按各自优先级排队，LLM请求受全局并发上限约束
+// @Tags batches
+// @Accept json
+// @Produce json
+// @Param request body CreateBatchRequest true "批量创作参数"
+// @Success 202 {object} APIResponse
+// @Router /api/v1/batches [post]
+func (h *BatchHandler) CreateBatch(c *gin.Context) {
+	var req CreateBatchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	userID, exists := GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未授权", ""))
+		return
+	}
+
+	if !checkQuota(c, db.Get(), userID) {
+		return
+	}
+
+	items := make([]orchestrator.BatchItem, 0, len(req.Items))
+	for i := range req.Items {
+		item := &req.Items[i]
+		if item.Params == nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", fmt.Sprintf("第%d项缺少创作参数", i+1), ""))
+			return
+		}
+		if !validateCreateProjectRequest(c, &item.CreateProjectRequest, userID) {
+			return
+		}
+		params := toCreationParams(&item.CreateProjectRequest, userID)
+		params.APIKeyID = requestAPIKeyID(c)
+		items = append(items, orchestrator.BatchItem{
+			Params:   params,
+			Priority: scheduler.TaskPriority(item.Priority),
+		})
+	}
+
+	orc, err := orchestrator.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化失败", err.Error()))
+		return
+	}
+
+	batch, err := orchestrator.SubmitBatch(userID, items, orc)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("CREATE_FAILED", "提交批量创作失败", err.Error()))
+		return
+	}
+	recordJob(c, batch.ID)
+
+	c.JSON(http.StatusAccepted, successResponse(toBatchStatusResponse(batch)))
+}
+
+// ListBatches 列出当前用户的批量创作
+// @Summary 列出批量创作
+// @Tags batches
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/batches [get]
+func (h *BatchHandler) ListBatches(c *gin.Context) {
+	userID, _ := GetUserID(c)
+
+	batches := orchestrator.ListBatches(userID)
+	response := make([]BatchStatusResponse, 0, len(batches))
+	for _, batch := range batches {
+		response = append(response, toBatchStatusResponse(batch))
+	}
+
+	c.JSON(http.StatusOK, successResponse(response))
+}
+
+// GetBatch 获取批量创作的分项进度和汇总费用
+// @Summary 获取批量创作状态
+// @Tags batches
+// @Produce json
+// @Param id path string true "批量创作ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/batches/{id} [get]
+func (h *BatchHandler) GetBatch(c *gin.Context) {
+	batch, ok := loadOwnedBatch(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(toBatchStatusResponse(batch)))
+}
+
+// CancelBatch 取消批量创作中尚未结束的项
+// @Summary 取消批量创作
+// @Tags batches
+// @Produce json
+// @Param id path string true "批量创作ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/batches/{id}/cancel [post]
+func (h *BatchHandler) CancelBatch(c *gin.Context) {
+	batch, ok := loadOwnedBatch(c)
+	if !ok {
+		return
+	}
+
+	batch.Cancel()
+	c.JSON(http.StatusOK, successResponse(toBatchStatusResponse(batch)))
+}
+
+// loadOwnedBatch 加载当前用户的批量创作，失败时已写入响应
+func loadOwnedBatch(c *gin.Context) (*orchestrator.Batch, bool) {
+	batch, err := orchestrator.GetBatch(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "批量创作不存在", ""))
+		return nil, false
+	}
+
+	userID, exists := GetUserID(c)
+	if !exists || batch.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权访问", ""))
+		return nil, false
+	}
+	return batch, true
+}
+
+// toBatchStatusResponse 转换批量创作状态响应
+func toBatchStatusResponse(batch *orchestrator.Batch) BatchStatusResponse {
+	inFlight, concurrency := llm.ConcurrencyStats()
+	return BatchStatusResponse{
+		BatchStatus:    batch.Status(),
+		LLMInFlight:    inFlight,
+		LLMConcurrency: concurrency,
+	}
+}
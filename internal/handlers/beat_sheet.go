@@ -0,0 +1,262 @@
+// Package handlers HTTP处理器 - 自定义节拍表
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// BeatSheetHandler 自定义节拍表处理器
+type BeatSheetHandler struct {
+	db db.Database
+}
+
+// NewBeatSheetHandler 创建自定义节拍表处理器
+func NewBeatSheetHandler(database db.Database) *BeatSheetHandler {
+	return &BeatSheetHandler{db: database}
+}
+
+// ListBeatSheets 列出当前用户的节拍表
+// @Summary 获取节拍表列表
+// @Description 返回当前用户自定义的节拍表；内置的五种叙事结构通过 structure 参数选择，不在此列出
+// @Tags beat-sheets
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/beat-sheets [get]
+func (h *BeatSheetHandler) ListBeatSheets(c *gin.Context) {
+	userID, exists := GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未授权", ""))
+		return
+	}
+
+	templates, err := h.db.ListUserNarrativeTemplates(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取节拍表失败", err.Error()))
+		return
+	}
+
+	sheets := make([]gin.H, 0, len(templates))
+	for i := range templates {
+		if templates[i].IsBeatSheet() {
+			sheets = append(sheets, beatSheetResponse(&templates[i]))
+		}
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"beat_sheets": sheets,
+		"total":       len(sheets),
+	}))
+}
+
+// GetBeatSheet 获取节拍表详情
+// @Summary 获取节拍表
+// @Tags beat-sheets
+// @Produce json
+// @Param id path string true "节拍表ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/beat-sheets/{id} [get]
+func (h *BeatSheetHandler) GetBeatSheet(c *gin.Context) {
+	tmpl, ok := h.loadOwnedBeatSheet(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"beat_sheet": beatSheetResponse(tmpl),
+	}))
+}
+
+// CreateBeatSheet 创建自定义节拍表
+// @Summary 创建节拍表
+// @Description 节拍的位置为在全书中的百分比（0-100），保存时按位置排序；创建蓝图或项目时通过 beat_sheet_id 选用
+// @Tags beat-sheets
+// @Accept json
+// @Produce json
+// @Param request body BeatSheetRequest true "节拍表"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/beat-sheets [post]
+func (h *BeatSheetHandler) CreateBeatSheet(c *gin.Context) {
+	var req BeatSheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	userID, exists := GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未授权", ""))
+		return
+	}
+
+	tmpl := &models.NarrativeTemplate{
+		ID:        db.GenerateID("beatsheet"),
+		UserID:    userID,
+		IsActive:  true,
+		Version:   1,
+		CreatedAt: time.Now(),
+	}
+	if !applyBeatSheetRequest(c, tmpl, &req) {
+		return
+	}
+
+	if err := h.db.SaveNarrativeTemplate(tmpl); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("CREATE_FAILED", "创建节拍表失败", err.Error()))
+		return
+	}
+	auditBeatSheet(c, models.AuditCreate, nil, tmpl)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"beat_sheet": beatSheetResponse(tmpl),
+	}))
+}
+
+// UpdateBeatSheet 更新自定义节拍表
+// @Summary 更新节拍表
+// @Description 只影响之后创建的蓝图，已生成的蓝图保留当时的节拍落点
+// @Tags beat-sheets
+// @Accept json
+// @Produce json
+// @Param id path string true "节拍表ID"
+// @Param request body BeatSheetRequest true "节拍表"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/beat-sheets/{id} [put]
+func (h *BeatSheetHandler) UpdateBeatSheet(c *gin.Context) {
+	var req BeatSheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	tmpl, ok := h.loadOwnedBeatSheet(c)
+	if !ok {
+		return
+	}
+	before := *tmpl
+	if !applyBeatSheetRequest(c, tmpl, &req) {
+		return
+	}
+	tmpl.Version++
+
+	if err := h.db.SaveNarrativeTemplate(tmpl); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存节拍表失败", err.Error()))
+		return
+	}
+	auditBeatSheet(c, models.AuditUpdate, &before, tmpl)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"beat_sheet": beatSheetResponse(tmpl),
+	}))
+}
+
+// DeleteBeatSheet 删除自定义节拍表
+// @Summary 删除节拍表
+// @Tags beat-sheets
+// @Produce json
+// @Param id path string true "节拍表ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/beat-sheets/{id} [delete]
+func (h *BeatSheetHandler) DeleteBeatSheet(c *gin.Context) {
+	tmpl, ok := h.loadOwnedBeatSheet(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteNarrativeTemplate(tmpl.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "删除节拍表失败", err.Error()))
+		return
+	}
+	auditBeatSheet(c, models.AuditDelete, tmpl, nil)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"message": "节拍表已删除",
+	}))
+}
+
+// loadOwnedBeatSheet 按路径参数加载当前用户的节拍表，失败时已写入响应
+func (h *BeatSheetHandler) loadOwnedBeatSheet(c *gin.Context) (*models.NarrativeTemplate, bool) {
+	userID, _ := GetUserID(c)
+	return loadBeatSheetTemplate(c, h.db, c.Param("id"), userID)
+}
+
+// loadBeatSheetTemplate 加载 ownerID 可使用的节拍表模板，失败时已写入响应
+func loadBeatSheetTemplate(c *gin.Context, database db.Database, id, ownerID string) (*models.NarrativeTemplate, bool) {
+	tmpl, err := database.GetNarrativeTemplate(id)
+	if err != nil || tmpl == nil || !tmpl.IsBeatSheet() {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "节拍表不存在", ""))
+		return nil, false
+	}
+	if tmpl.UserID == "" || tmpl.UserID != ownerID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权访问", ""))
+		return nil, false
+	}
+	return tmpl, true
+}
+
+// resolveBeatSheet 创建蓝图时选用的节拍表，id 为空时返回 nil；失败时已写入响应
+func resolveBeatSheet(c *gin.Context, database db.Database, id, ownerID string) (*models.BeatSheet, bool) {
+	if id == "" {
+		return nil, true
+	}
+	tmpl, ok := loadBeatSheetTemplate(c, database, id, ownerID)
+	if !ok {
+		return nil, false
+	}
+	sheet, err := tmpl.BeatSheet()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "节拍表无效", err.Error()))
+		return nil, false
+	}
+	return sheet, true
+}
+
+// applyBeatSheetRequest 校验并写入节拍表字段，失败时已写入响应
+func applyBeatSheetRequest(c *gin.Context, tmpl *models.NarrativeTemplate, req *BeatSheetRequest) bool {
+	sheet := &models.BeatSheet{Name: strings.TrimSpace(req.Name), Beats: req.Beats}
+	if err := sheet.Normalize(); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "节拍表无效", err.Error()))
+		return false
+	}
+	if err := tmpl.SetBeats(sheet.Beats); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "保存节拍表失败", err.Error()))
+		return false
+	}
+	tmpl.Name = sheet.Name
+	tmpl.Description = strings.TrimSpace(req.Description)
+	return true
+}
+
+// beatSheetResponse 节拍表的响应格式
+func beatSheetResponse(tmpl *models.NarrativeTemplate) gin.H {
+	resp := gin.H{
+		"id":          tmpl.ID,
+		"name":        tmpl.Name,
+		"description": tmpl.Description,
+		"version":     tmpl.Version,
+		"beats":       []models.StructureBeat{},
+		"created_at":  tmpl.CreatedAt,
+		"updated_at":  tmpl.UpdatedAt,
+	}
+	if sheet, err := tmpl.BeatSheet(); err == nil {
+		resp["beats"] = sheet.Beats
+	}
+	return resp
+}
+
+// auditBeatSheet 记录节拍表变更
+func auditBeatSheet(c *gin.Context, action string, before, after *models.NarrativeTemplate) {
+	tmpl := after
+	if tmpl == nil {
+		tmpl = before
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       action,
+		ResourceType: models.AuditResourceBeatSheet,
+		ResourceID:   tmpl.ID,
+		Summary:      "节拍表 " + tmpl.Name,
+	}, before, after)
+}
@@ -0,0 +1,297 @@
+// Package handlers HTTP处理器 - 假设分支（what-if）
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/narrative"
+)
+
+// BranchHandler 假设分支处理器
+type BranchHandler struct {
+	db db.Database
+}
+
+// NewBranchHandler 创建假设分支处理器
+func NewBranchHandler(database db.Database) *BranchHandler {
+	return &BranchHandler{db: database}
+}
+
+// CreateBranch 创建假设分支
+// @Summary 创建假设分支
+// @Description 从指定章节分叉，改变关键事件的结果并重新规划此后的章节；分支不影响主线，比较后可采纳
+// @Tags branches
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body CreateBranchRequest true "分叉章节与改变后的结果"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/branches [post]
+func (h *BranchHandler) CreateBranch(c *gin.Context) {
+	var req CreateBranchRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	engine, err := narrative.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化叙事器失败", err.Error()))
+		return
+	}
+	plan, err := engine.WithBudget(budget).WithLogger(requestLogger(c)).WithLanguage(project.Language).PlanWhatIf(blueprint, narrative.WhatIfParams{
+		ForkChapter: req.ForkChapter,
+		Outcome:     req.Outcome,
+		Chapters:    req.Chapters,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "分支规划失败", err.Error()))
+		return
+	}
+
+	name := req.Name
+	if name == "" {
+		name = fmt.Sprintf("第%d章分支", req.ForkChapter)
+	}
+	branch := &models.BlueprintBranch{
+		ID:           db.GenerateID("branch"),
+		ProjectID:    project.ID,
+		BlueprintID:  blueprint.ID,
+		Name:         name,
+		ForkChapter:  req.ForkChapter,
+		Outcome:      req.Outcome,
+		Status:       models.BranchDraft,
+		ChapterPlans: plan.ChapterPlans,
+		Scenes:       plan.Scenes,
+	}
+	if plan.State != nil {
+		if data, err := json.Marshal(plan.State); err == nil {
+			branch.State = data
+		}
+	}
+	if err := h.db.SaveBlueprintBranch(branch); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存分支失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, successResponse(gin.H{
+		"branch": branch,
+	}))
+}
+
+// ListBranches 列出假设分支
+// @Summary 假设分支列表
+// @Description 列出项目蓝图的全部假设分支（含已采纳和已放弃的）
+// @Tags branches
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/branches [get]
+func (h *BranchHandler) ListBranches(c *gin.Context) {
+	_, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	branches, err := h.db.ListBlueprintBranches(blueprint.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取分支失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"blueprint_id": blueprint.ID,
+		"branches":     branches,
+		"total":        len(branches),
+	}))
+}
+
+// CompareBranch 比较分支
+// @Summary 比较假设分支
+// @Description 将分支与主线（或另一个分支）做结构化对比，返回变更列表和可读报告
+// @Tags branches
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param branchId path string true "分支ID"
+// @Param with query string false "对比的另一分支ID，缺省为主线"
+// @Param format query string false "输出格式" Enums(json, text)
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/branches/{branchId}/compare [get]
+func (h *BranchHandler) CompareBranch(c *gin.Context) {
+	_, canon, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+	branch, ok := h.loadBranch(c, canon, c.Param("branchId"))
+	if !ok {
+		return
+	}
+
+	from := canon
+	if other := c.Query("with"); other != "" {
+		base, ok := h.loadBranch(c, canon, other)
+		if !ok {
+			return
+		}
+		from = base.Apply(canon)
+	}
+
+	diff := narrative.DiffBlueprints(from, branch.Apply(canon))
+
+	if c.Query("format") == "text" {
+		c.String(http.StatusOK, diff.Report())
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"branch":      branch,
+		"diff":        diff,
+		"has_changes": diff.HasChanges(),
+		"report":      diff.Report(),
+	}))
+}
+
+// AdoptBranch 采纳分支为主线
+// @Summary 采纳假设分支
+// @Description 用分支的章节规划和场景指令替换主线分叉点之后的内容；原主线保存为一个新分支，可随时换回
+// @Tags branches
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param branchId path string true "分支ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/branches/{branchId}/adopt [post]
+func (h *BranchHandler) AdoptBranch(c *gin.Context) {
+	project, canon, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+	branch, ok := h.loadBranch(c, canon, c.Param("branchId"))
+	if !ok {
+		return
+	}
+	if branch.Status == models.BranchCanon {
+		c.JSON(http.StatusConflict, errorResponse("ALREADY_CANON", "分支已是主线", ""))
+		return
+	}
+
+	// 原主线分叉点之后的内容保存为分支，避免采纳后丢失
+	previous := &models.BlueprintBranch{
+		ID:          db.GenerateID("branch"),
+		ProjectID:   project.ID,
+		BlueprintID: canon.ID,
+		Name:        "原主线",
+		ForkChapter: branch.ForkChapter,
+		Outcome:     fmt.Sprintf("采纳「%s」前的主线", branch.Name),
+		Status:      models.BranchDraft,
+	}
+	for _, p := range canon.ChapterPlans {
+		if p.Chapter >= branch.ForkChapter {
+			previous.ChapterPlans = append(previous.ChapterPlans, p)
+		}
+	}
+	for _, s := range canon.Scenes {
+		if s.Chapter >= branch.ForkChapter {
+			previous.Scenes = append(previous.Scenes, s)
+		}
+	}
+	if err := h.db.SaveBlueprintBranch(previous); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存原主线失败", err.Error()))
+		return
+	}
+
+	merged := branch.Apply(canon)
+	canon.ChapterPlans = merged.ChapterPlans
+	canon.Scenes = merged.Scenes
+	if err := h.db.SaveNarrativeBlueprint(canon); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存蓝图失败", err.Error()))
+		return
+	}
+
+	// 之前采纳的分支已被取代，恢复为待比较
+	if branches, err := h.db.ListBlueprintBranches(canon.ID); err == nil {
+		for i := range branches {
+			if branches[i].Status == models.BranchCanon && branches[i].ID != branch.ID {
+				branches[i].Status = models.BranchDraft
+				h.db.SaveBlueprintBranch(&branches[i])
+			}
+		}
+	}
+	branch.Status = models.BranchCanon
+	if err := h.db.SaveBlueprintBranch(branch); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存分支失败", err.Error()))
+		return
+	}
+
+	// 分叉点之后已写的章节与新主线不再一致，需要重写
+	var stale []gin.H
+	for _, ch := range h.db.ListChaptersByProject(project.ID) {
+		if ch.ChapterNum >= branch.ForkChapter && ch.WordCount > 0 {
+			stale = append(stale, gin.H{"chapter_id": ch.ID, "chapter_num": ch.ChapterNum, "title": ch.Title})
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"branch":           branch,
+		"previous_branch":  previous.ID,
+		"chapters_planned": len(canon.ChapterPlans),
+		"stale_chapters":   stale,
+	}))
+}
+
+// DiscardBranch 放弃分支
+// @Summary 放弃假设分支
+// @Description 将分支标记为已放弃，分支内容保留；已采纳的分支不能放弃
+// @Tags branches
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param branchId path string true "分支ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/branches/{branchId} [delete]
+func (h *BranchHandler) DiscardBranch(c *gin.Context) {
+	_, canon, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+	branch, ok := h.loadBranch(c, canon, c.Param("branchId"))
+	if !ok {
+		return
+	}
+	if branch.Status == models.BranchCanon {
+		c.JSON(http.StatusConflict, errorResponse("ALREADY_CANON", "已采纳的分支不能放弃", ""))
+		return
+	}
+
+	branch.Status = models.BranchDiscarded
+	if err := h.db.SaveBlueprintBranch(branch); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存分支失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"branch": branch,
+	}))
+}
+
+// loadBranch 加载属于该蓝图的分支，失败时已写入响应
+func (h *BranchHandler) loadBranch(c *gin.Context, blueprint *models.NarrativeBlueprint, branchID string) (*models.BlueprintBranch, bool) {
+	branch, err := h.db.GetBlueprintBranch(branchID)
+	if err != nil || branch.BlueprintID != blueprint.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "分支不存在", branchID))
+		return nil, false
+	}
+	return branch, true
+}
@@ -2,7 +2,9 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 	"unicode/utf8"
 
 	"github.com/gin-gonic/gin"
@@ -14,12 +16,17 @@ import (
 // ChapterHandler 章节处理器
 type ChapterHandler struct {
 	chapterRepo *repositories.ChapterRepository
+	lockRepo    *repositories.ChapterLockRepository
 }
 
+// chapterLockTTL 章节编辑锁有效期，前端需在过期前续期
+const chapterLockTTL = 5 * time.Minute
+
 // NewChapterHandler 创建章节处理器
 func NewChapterHandler() *ChapterHandler {
 	return &ChapterHandler{
 		chapterRepo: repositories.NewChapterRepository(),
+		lockRepo:    repositories.NewChapterLockRepository(),
 	}
 }
 
@@ -148,6 +155,7 @@ func (h *ChapterHandler) CreateChapter(c *gin.Context) {
 		WordCount:  0,
 		AIWordCount: 0,
 		Status:     models.ChapterStatusDraft,
+		Version:    1,
 	}
 
 	if err := h.chapterRepo.Create(c, chapter); err != nil {
@@ -176,7 +184,8 @@ func (h *ChapterHandler) UpdateChapter(c *gin.Context) {
 	chapterID := c.Param("chapterId")
 
 	// 检查项目是否存在
-	if _, err := db.Get().GetProject(projectID); err != nil {
+	project, err := db.Get().GetProject(projectID)
+	if err != nil {
 		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
 		return
 	}
@@ -204,6 +213,8 @@ func (h *ChapterHandler) UpdateChapter(c *gin.Context) {
 		return
 	}
 
+	before := *chapter
+
 	// 更新字段
 	if req.Title != "" {
 		chapter.Title = req.Title
@@ -216,12 +227,53 @@ func (h *ChapterHandler) UpdateChapter(c *gin.Context) {
 		chapter.Status = models.ChapterStatus(req.Status)
 	}
 
-	// 保存更新
-	if err := h.chapterRepo.Update(c, chapter); err != nil {
+	// 保存更新：提供了版本号时按乐观锁更新，否则沿用直接覆盖
+	if req.Version > 0 {
+		err = h.chapterRepo.UpdateWithVersion(c, chapter, req.Version)
+	} else {
+		err = h.chapterRepo.Update(c, chapter)
+	}
+	if err == repositories.ErrChapterVersionConflict {
+		current, getErr := h.chapterRepo.GetByID(c, chapterID)
+		if getErr != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取章节失败", getErr.Error()))
+			return
+		}
+		submitted := *chapter
+		submitted.Version = req.Version
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Data: gin.H{
+				"current":   toChapterResponse(current),
+				"submitted": toChapterResponse(&submitted),
+			},
+			Error: &ErrorInfo{
+				Code:    "VERSION_CONFLICT",
+				Message: "章节已被他人修改",
+				Details: fmt.Sprintf("提交版本 %d，当前版本 %d", req.Version, current.Version),
+			},
+		})
+		return
+	}
+	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "更新章节失败", err.Error()))
 		return
 	}
 
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditUpdate,
+		ResourceType: models.AuditResourceChapter,
+		ResourceID:   chapter.ID,
+		ProjectID:    projectID,
+		Summary:      fmt.Sprintf("编辑第%d章", chapter.ChapterNum),
+	}, &before, chapter)
+
+	// 章节定稿后更新实体提及，并按项目设置自动翻译
+	if chapter.Status == models.ChapterStatusCompleted && req.Status != "" {
+		trackChapterMentions(db.Get(), project, chapter, requestLogger(c))
+		autoTranslateChapter(db.Get(), project, chapter.ID, requestLogger(c))
+	}
+
 	c.JSON(http.StatusOK, successResponse(gin.H{
 		"chapter": toChapterResponse(chapter),
 	}))
@@ -229,7 +281,7 @@ func (h *ChapterHandler) UpdateChapter(c *gin.Context) {
 
 // DeleteChapter 删除章节
 // @Summary 删除章节
-// @Description 删除指定章节
+// @Description 章节移入回收站，30天内可恢复
 // @Tags chapters
 // @Produce json
 // @Param project_id path string true "项目ID"
@@ -268,10 +320,132 @@ func (h *ChapterHandler) DeleteChapter(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "删除章节失败", err.Error()))
 		return
 	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditDelete,
+		ResourceType: models.AuditResourceChapter,
+		ResourceID:   chapterID,
+		ProjectID:    projectID,
+		Summary:      fmt.Sprintf("删除第%d章", chapter.ChapterNum),
+	}, chapter, nil)
 
 	c.JSON(http.StatusOK, successResponse(gin.H{
 		"deleted_chapter_id": chapterID,
 		"chapter_num":        chapter.ChapterNum,
 		"title":              chapter.Title,
+		"purge_at":           time.Now().Add(models.TrashRetention),
+	}))
+}
+
+// LockChapter 获取或续期章节编辑锁
+// @Summary 锁定章节
+// @Description 获取章节的建议编辑锁，供前端提示"正在被他人编辑"；持有者重复调用即续期
+// @Tags chapters
+// @Produce json
+// @Param id path string true "章节ID"
+// @Success 200 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Router /api/v1/chapters/{id}/lock [post]
+func (h *ChapterHandler) LockChapter(c *gin.Context) {
+	chapterID := c.Param("id")
+
+	userID, ok := GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未登录", ""))
+		return
+	}
+
+	if _, _, ok := h.loadOwnedChapter(c); !ok {
+		return
+	}
+
+	username := ""
+	if user, ok := c.Get("user"); ok {
+		if u, ok := user.(*models.User); ok {
+			username = u.Username
+		}
+	}
+
+	lock, err := h.lockRepo.Acquire(c, chapterID, userID, username, chapterLockTTL)
+	if err == repositories.ErrChapterLocked {
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Data:    gin.H{"lock": lock},
+			Error: &ErrorInfo{
+				Code:    "CHAPTER_LOCKED",
+				Message: "章节正在被他人编辑",
+				Details: fmt.Sprintf("%s 正在编辑", lock.Username),
+			},
+		})
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "锁定章节失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{"lock": lock}))
+}
+
+// UnlockChapter 释放章节编辑锁
+// @Summary 解锁章节
+// @Description 释放当前用户持有的章节编辑锁
+// @Tags chapters
+// @Produce json
+// @Param id path string true "章节ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/lock [delete]
+func (h *ChapterHandler) UnlockChapter(c *gin.Context) {
+	chapterID := c.Param("id")
+
+	userID, ok := GetUserID(c)
+	if !ok {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未登录", ""))
+		return
+	}
+
+	if _, _, ok := h.loadOwnedChapter(c); !ok {
+		return
+	}
+
+	if err := h.lockRepo.Release(c, chapterID, userID); err != nil {
+		if err == repositories.ErrChapterLockNotFound {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "未持有该章节的锁", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "解锁章节失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{"chapter_id": chapterID}))
+}
+
+// GetChapterLock 查询章节编辑锁
+// @Summary 查询章节锁
+// @Description 查询章节当前是否被他人编辑
+// @Tags chapters
+// @Produce json
+// @Param id path string true "章节ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/lock [get]
+func (h *ChapterHandler) GetChapterLock(c *gin.Context) {
+	chapterID := c.Param("id")
+
+	if _, _, ok := h.loadOwnedChapter(c); !ok {
+		return
+	}
+
+	lock, err := h.lockRepo.Get(c, chapterID)
+	if err == repositories.ErrChapterLockNotFound {
+		c.JSON(http.StatusOK, successResponse(gin.H{"locked": false}))
+		return
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "查询章节锁失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"locked": true,
+		"lock":   lock,
 	}))
 }
@@ -0,0 +1,418 @@
+// Package handlers HTTP处理器 - 章节批注
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/annotation"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// annotationView 批注及其在当前正文中的位置和回复
+type annotationView struct {
+	models.Annotation
+	Anchor  annotation.Anchor        `json:"anchor"`
+	Replies []models.AnnotationReply `json:"replies"`
+}
+
+// ListAnnotations 列出章节批注
+// @Summary 章节批注列表
+// @Description 返回批注、讨论串和批注在当前正文中的位置；正文改动后按原文或段落重新定位，无法定位的批注 anchor.status 为 orphaned
+// @Tags chapters
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param resolved query bool false "按解决状态过滤"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/annotations [get]
+func (h *ChapterHandler) ListAnnotations(c *gin.Context) {
+	chapter, _, ok := h.loadOwnedChapter(c)
+	if !ok {
+		return
+	}
+	var resolved *bool
+	if raw := c.Query("resolved"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "resolved 参数无效", err.Error()))
+			return
+		}
+		resolved = &v
+	}
+
+	database := db.Get()
+	list, err := database.ListAnnotations(chapter.ID, resolved)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取批注失败", err.Error()))
+		return
+	}
+	ids := make([]string, len(list))
+	for i := range list {
+		ids[i] = list[i].ID
+	}
+	replies, err := database.ListAnnotationReplies(ids)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取批注回复失败", err.Error()))
+		return
+	}
+	byAnnotation := make(map[string][]models.AnnotationReply)
+	for _, r := range replies {
+		byAnnotation[r.AnnotationID] = append(byAnnotation[r.AnnotationID], r)
+	}
+
+	views := make([]annotationView, len(list))
+	orphaned := 0
+	for i := range list {
+		views[i] = annotationView{
+			Annotation: list[i],
+			Anchor:     annotation.Locate(chapter.Content, &list[i]),
+			Replies:    byAnnotation[list[i].ID],
+		}
+		if views[i].Replies == nil {
+			views[i].Replies = []models.AnnotationReply{}
+		}
+		if views[i].Anchor.Status == models.AnchorOrphaned {
+			orphaned++
+		}
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"annotations": views,
+		"total":       len(views),
+		"orphaned":    orphaned,
+	}))
+}
+
+// CreateAnnotation 在章节正文上添加批注
+// @Summary 添加批注
+// @Description 按字符区间 [start, end) 或段落哈希（见 paragraph_hash，由 GET /chapters/{id}/paragraphs 返回）定位
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param request body CreateAnnotationRequest true "批注"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/chapters/{id}/annotations [post]
+func (h *ChapterHandler) CreateAnnotation(c *gin.Context) {
+	var req CreateAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "批注内容为空", ""))
+		return
+	}
+
+	chapter, project, ok := h.loadOwnedChapter(c)
+	if !ok {
+		return
+	}
+	content := []rune(chapter.Content)
+	paragraphs := annotation.Paragraphs(chapter.Content)
+
+	start, end := req.Start, req.End
+	var para annotation.Paragraph
+	switch {
+	case req.End > 0:
+		if start >= end || end > len(content) {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "批注区间无效", fmt.Sprintf("正文共%d字", len(content))))
+			return
+		}
+		para, _ = annotation.ParagraphAt(paragraphs, start)
+	case req.ParagraphHash != "":
+		p, found := annotation.FindParagraph(paragraphs, req.ParagraphHash)
+		if !found {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "段落不存在", "正文可能已被修改，请重新获取段落"))
+			return
+		}
+		para, start, end = p, p.Start, p.End
+	default:
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请指定批注区间或段落", ""))
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	note := &models.Annotation{
+		ID:            db.GenerateID("annotation"),
+		ProjectID:     project.ID,
+		ChapterID:     chapter.ID,
+		UserID:        userID,
+		Username:      currentUsername(c),
+		Start:         start,
+		End:           end,
+		Quote:         string(content[start:end]),
+		ParagraphHash: para.Hash,
+		Body:          strings.TrimSpace(req.Body),
+	}
+	if err := db.Get().SaveAnnotation(note); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存批注失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, successResponse(annotationView{
+		Annotation: *note,
+		Anchor:     annotation.Anchor{Start: start, End: end, Status: models.AnchorExact},
+		Replies:    []models.AnnotationReply{},
+	}))
+}
+
+// UpdateAnnotation 修改批注内容
+// @Summary 修改批注
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param aid path string true "批注ID"
+// @Param request body UpdateAnnotationRequest true "批注内容"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/annotations/{aid} [put]
+func (h *ChapterHandler) UpdateAnnotation(c *gin.Context) {
+	var req UpdateAnnotationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	_, note, ok := h.loadAnnotation(c)
+	if !ok {
+		return
+	}
+	note.Body = strings.TrimSpace(req.Body)
+	if err := db.Get().SaveAnnotation(note); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存批注失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(note))
+}
+
+// DeleteAnnotation 删除批注及其回复
+// @Summary 删除批注
+// @Tags chapters
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param aid path string true "批注ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/annotations/{aid} [delete]
+func (h *ChapterHandler) DeleteAnnotation(c *gin.Context) {
+	_, note, ok := h.loadAnnotation(c)
+	if !ok {
+		return
+	}
+	if err := db.Get().DeleteAnnotation(note.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "删除批注失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"id": note.ID}))
+}
+
+// ReplyAnnotation 回复批注
+// @Summary 回复批注
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param aid path string true "批注ID"
+// @Param request body CreateAnnotationReplyRequest true "回复内容"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/chapters/{id}/annotations/{aid}/replies [post]
+func (h *ChapterHandler) ReplyAnnotation(c *gin.Context) {
+	var req CreateAnnotationReplyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	if strings.TrimSpace(req.Body) == "" {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "回复内容为空", ""))
+		return
+	}
+	_, note, ok := h.loadAnnotation(c)
+	if !ok {
+		return
+	}
+	userID, _ := GetUserID(c)
+	reply := &models.AnnotationReply{
+		ID:           db.GenerateID("reply"),
+		AnnotationID: note.ID,
+		UserID:       userID,
+		Username:     currentUsername(c),
+		Body:         strings.TrimSpace(req.Body),
+	}
+	if err := db.Get().SaveAnnotationReply(reply); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存回复失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, successResponse(reply))
+}
+
+// ResolveAnnotation 解决或重新打开批注
+// @Summary 解决批注
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param aid path string true "批注ID"
+// @Param request body ResolveAnnotationRequest false "resolved=false 时重新打开"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/annotations/{aid}/resolve [post]
+func (h *ChapterHandler) ResolveAnnotation(c *gin.Context) {
+	var req ResolveAnnotationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+	_, note, ok := h.loadAnnotation(c)
+	if !ok {
+		return
+	}
+	resolved := req.Resolved == nil || *req.Resolved
+	setAnnotationResolved(note, resolved, currentUsername(c))
+	if err := db.Get().SaveAnnotation(note); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存批注失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(note))
+}
+
+// SuggestFromAnnotation 把批注及其讨论作为修改指令，对批注的文本生成修改建议
+// @Summary 按批注生成修改建议
+// @Description 批注正文和回复作为指令交给修改建议模型；生成的建议采纳后批注自动解决
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param aid path string true "批注ID"
+// @Param request body SuggestFromAnnotationRequest false "附加指令"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/chapters/{id}/annotations/{aid}/suggest [post]
+func (h *ChapterHandler) SuggestFromAnnotation(c *gin.Context) {
+	var req SuggestFromAnnotationRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+	chapter, note, ok := h.loadAnnotation(c)
+	if !ok {
+		return
+	}
+	if note.Resolved {
+		c.JSON(http.StatusConflict, errorResponse("ALREADY_RESOLVED", "批注已解决", ""))
+		return
+	}
+	anchor := annotation.Locate(chapter.Content, note)
+	if anchor.Status == models.AnchorOrphaned {
+		c.JSON(http.StatusConflict, errorResponse("ANNOTATION_ORPHANED", "批注的文本已被修改，无法定位", ""))
+		return
+	}
+	replies, err := db.Get().ListAnnotationReplies([]string{note.ID})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取批注回复失败", err.Error()))
+		return
+	}
+	project, err := db.Get().GetProject(chapter.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	h.createEditSuggestion(c, chapter, project, anchor.Start, anchor.End, annotationInstruction(note, replies, req.Instruction), note.ID)
+}
+
+// loadAnnotation 读取路径中的章节和批注并校验权限，失败时已写入响应
+func (h *ChapterHandler) loadAnnotation(c *gin.Context) (*models.Chapter, *models.Annotation, bool) {
+	chapter, _, ok := h.loadOwnedChapter(c)
+	if !ok {
+		return nil, nil, false
+	}
+	note, err := db.Get().GetAnnotation(c.Param("aid"))
+	if err != nil || note.ChapterID != chapter.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "批注不存在", ""))
+		return nil, nil, false
+	}
+	return chapter, note, true
+}
+
+// resolveAnnotationBySuggestion 修改建议写回章节后解决其来源批注，失败只记录日志
+func (h *ChapterHandler) resolveAnnotationBySuggestion(c *gin.Context, suggestion *models.EditSuggestion) {
+	database := db.Get()
+	note, err := database.GetAnnotation(suggestion.AnnotationID)
+	if err != nil || note.Resolved {
+		return
+	}
+	setAnnotationResolved(note, true, currentUsername(c))
+	if err := database.SaveAnnotation(note); err != nil {
+		requestLogger(c).Warn("自动解决批注失败", "annotation_id", note.ID, "error", err)
+	}
+}
+
+// setAnnotationResolved 设置批注的解决状态
+func setAnnotationResolved(note *models.Annotation, resolved bool, by string) {
+	note.Resolved = resolved
+	if resolved {
+		now := time.Now()
+		note.ResolvedAt = &now
+		note.ResolvedBy = by
+	} else {
+		note.ResolvedAt = nil
+		note.ResolvedBy = ""
+	}
+}
+
+// annotationInstruction 把批注讨论整理成修改指令
+func annotationInstruction(note *models.Annotation, replies []models.AnnotationReply, extra string) string {
+	var sb strings.Builder
+	sb.WriteString("按以下批注意见修改选段：\n")
+	sb.WriteString(fmt.Sprintf("- %s：%s\n", displayName(note.Username), note.Body))
+	for _, r := range replies {
+		sb.WriteString(fmt.Sprintf("- %s（回复）：%s\n", displayName(r.Username), r.Body))
+	}
+	if extra = strings.TrimSpace(extra); extra != "" {
+		sb.WriteString("补充要求：" + extra + "\n")
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// displayName 批注人显示名，未记录用户名时显示“批注者”
+func displayName(username string) string {
+	if username == "" {
+		return "批注者"
+	}
+	return username
+}
+
+// currentUsername 当前登录用户的用户名
+func currentUsername(c *gin.Context) string {
+	if user, ok := c.Get("user"); ok {
+		if u, ok := user.(*models.User); ok {
+			return u.Username
+		}
+	}
+	return ""
+}
+
+// ListParagraphs 列出章节正文的段落及其哈希，供按段落批注
+// @Summary 章节段落
+// @Tags chapters
+// @Produce json
+// @Param id path string true "章节ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/paragraphs [get]
+func (h *ChapterHandler) ListParagraphs(c *gin.Context) {
+	chapter, _, ok := h.loadOwnedChapter(c)
+	if !ok {
+		return
+	}
+	paragraphs := annotation.Paragraphs(chapter.Content)
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"paragraphs": paragraphs,
+		"total":      len(paragraphs),
+		"word_count": utf8.RuneCountInString(chapter.Content),
+	}))
+}
@@ -0,0 +1,148 @@
+// Package handlers HTTP处理器 - 近似重复段落
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/neardup"
+)
+
+// ScanChapterDuplicates 检测章节与此前各章近似重复的段落
+// @Summary 检测近似重复段落
+// @Description 把章节正文逐段与此前各章比较，相似度达到阈值的段落写入章节记录（duplicate_passages）
+// @Tags chapters
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param threshold query number false "相似度阈值（0-1），默认0.6"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/duplicates [post]
+func (h *ChapterHandler) ScanChapterDuplicates(c *gin.Context) {
+	threshold, ok := duplicateThreshold(c)
+	if !ok {
+		return
+	}
+	chapter, project, ok := h.loadOwnedChapter(c)
+	if !ok {
+		return
+	}
+	database := db.Get()
+	blueprint := projectBlueprint(database, project)
+	prose := func(ch *models.Chapter) string { return chapterProse(database, blueprint, ch) }
+
+	chapter.DuplicatePassages = neardup.ScanChapter(sortedChapters(database, project.ID), chapter, prose, threshold)
+	if err := h.chapterRepo.UpdateColumns(c, chapter, "duplicate_passages"); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存检测结果失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"chapter_id": chapter.ID,
+		"duplicates": chapter.DuplicatePassages,
+		"total":      len(chapter.DuplicatePassages),
+	}))
+}
+
+// ScanProjectDuplicates 逐章检测全书的近似重复段落
+// @Summary 全书近似重复检测
+// @Description 按章节顺序把每章与此前各章比较，结果写入各章记录
+// @Tags chapters
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param threshold query number false "相似度阈值（0-1），默认0.6"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/duplicates/scan [post]
+func (h *ChapterHandler) ScanProjectDuplicates(c *gin.Context) {
+	threshold, ok := duplicateThreshold(c)
+	if !ok {
+		return
+	}
+	database := db.Get()
+	project, ok := loadOwnedProject(c, database)
+	if !ok {
+		return
+	}
+	blueprint := projectBlueprint(database, project)
+
+	// 章节按顺序先检测再加入索引，每章只与此前各章比较
+	idx := neardup.NewIndex()
+	results := make([]gin.H, 0)
+	total := 0
+	for _, ch := range sortedChapters(database, project.ID) {
+		prose := chapterProse(database, blueprint, ch)
+		if strings.TrimSpace(prose) == "" {
+			continue
+		}
+		found := idx.Find(ch.ChapterNum, prose, threshold)
+		idx.Add(ch.ChapterNum, prose)
+		if len(found) == 0 && len(ch.DuplicatePassages) == 0 {
+			continue
+		}
+		ch.DuplicatePassages = found
+		if err := h.chapterRepo.UpdateColumns(c, ch, "duplicate_passages"); err != nil {
+			results = append(results, gin.H{"chapter_num": ch.ChapterNum, "error": err.Error()})
+			continue
+		}
+		if len(found) > 0 {
+			total += len(found)
+			results = append(results, gin.H{
+				"chapter_id":  ch.ID,
+				"chapter_num": ch.ChapterNum,
+				"duplicates":  found,
+			})
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"chapters": results,
+		"total":    total,
+	}))
+}
+
+// RephraseDuplicate 为近似重复的段落生成改写建议
+// @Summary 改写重复段落
+// @Description 按检测结果中的序号取出重复段落，请模型改写成与另一章不同的表达；结果是一条修改建议，采纳方式见 /chapters/{id}/suggestions
+// @Tags chapters
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param index path int true "duplicate_passages 中的序号"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/chapters/{id}/duplicates/{index}/rephrase [post]
+func (h *ChapterHandler) RephraseDuplicate(c *gin.Context) {
+	chapter, project, ok := h.loadOwnedChapter(c)
+	if !ok {
+		return
+	}
+	index, err := strconv.Atoi(c.Param("index"))
+	if err != nil || index < 0 || index >= len(chapter.DuplicatePassages) {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "重复段落不存在", "请先检测近似重复段落"))
+		return
+	}
+	dup := chapter.DuplicatePassages[index]
+	start, found := locatePassage(chapter.Content, dup.Text, dup.Start)
+	if !found {
+		c.JSON(http.StatusConflict, errorResponse("DUPLICATE_STALE", "段落在检测后已被修改", "请重新检测"))
+		return
+	}
+
+	instruction := fmt.Sprintf("这段与第%d章的以下段落高度相似，请换一种写法表达同样的内容，避开相同的措辞和意象，保持情节和信息不变：\n%s", dup.OtherChapter, dup.OtherText)
+	h.createEditSuggestion(c, chapter, project, start, start+len([]rune(dup.Text)), instruction, "")
+}
+
+// duplicateThreshold 读取相似度阈值参数，失败时已写入响应
+func duplicateThreshold(c *gin.Context) (float64, bool) {
+	v := c.Query("threshold")
+	if v == "" {
+		return neardup.DefaultThreshold, true
+	}
+	threshold, err := strconv.ParseFloat(v, 64)
+	if err != nil || threshold <= 0 || threshold > 1 {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "threshold 参数无效", "取值范围 (0, 1]"))
+		return 0, false
+	}
+	return threshold, true
+}
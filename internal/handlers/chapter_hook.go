@@ -0,0 +1,157 @@
+// Package handlers HTTP处理器 - 章节钩子评分
+package handlers
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// ScoreChapterHooks 为章节开篇段落和章末悬念评分
+// @Summary 章节钩子评分
+// @Description 评估章节开篇段落和章末悬念的好奇心、紧张感和悬念强度，结果写入章节记录，综合分偏低的钩子标记为需要重新生成
+// @Tags chapters
+// @Produce json
+// @Param id path string true "章节ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/hook-score [post]
+func (h *ChapterHandler) ScoreChapterHooks(c *gin.Context) {
+	chapter, err := h.chapterRepo.GetByID(c, c.Param("id"))
+	if err != nil {
+		if err == repositories.ErrChapterNotFound {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "章节不存在", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取章节失败", err.Error()))
+		return
+	}
+
+	database := db.Get()
+	project, err := database.GetProject(chapter.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return
+	}
+
+	blueprint := &models.NarrativeBlueprint{}
+	if project.NarrativeID != "" {
+		if bp, err := database.GetNarrativeBlueprint(project.NarrativeID); err == nil && bp != nil {
+			blueprint = bp
+		}
+	}
+
+	prose := chapterProse(database, blueprint, chapter)
+	if strings.TrimSpace(prose) == "" {
+		c.JSON(http.StatusBadRequest, errorResponse("EMPTY_CHAPTER", "章节没有正文", ""))
+		return
+	}
+
+	plannedHook := ""
+	if plan := findChapterPlan(blueprint, chapter.ChapterNum); plan != nil {
+		plannedHook = plan.EndingHook
+	}
+
+	budget, ok := userBudget(c, database)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化写作器失败", err.Error()))
+		return
+	}
+	w = w.WithBudget(budget).WithLanguage(project.Language)
+	score, err := w.ScoreHooks(writer.HookScoreParams{
+		Chapter:     chapter.ChapterNum,
+		Title:       chapter.Title,
+		Prose:       prose,
+		PlannedHook: plannedHook,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "钩子评分失败", err.Error()))
+		return
+	}
+
+	chapter.HookScore = score
+	if err := h.chapterRepo.UpdateColumns(c, chapter, "hook_score"); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存评分结果失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"chapter_id": chapter.ID,
+		"hook_score": score,
+		"weak":       score.Weak(),
+	}))
+}
+
+// ListChapterHooks 列出项目各章的钩子评分
+// @Summary 章节钩子评分列表
+// @Description 按章节顺序列出已评分章节的开篇与章末钩子评分；weak=true 时只返回需要重新生成钩子的章节
+// @Tags chapters
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param weak query bool false "只返回弱钩子章节"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/hooks [get]
+func (h *ChapterHandler) ListChapterHooks(c *gin.Context) {
+	projectID := c.Param("projectId")
+	if _, err := db.Get().GetProject(projectID); err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	weakOnly := c.Query("weak") == "true"
+
+	chapters := db.Get().ListChaptersByProject(projectID)
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].ChapterNum < chapters[j].ChapterNum })
+
+	items := make([]gin.H, 0, len(chapters))
+	unscored := 0
+	for _, ch := range chapters {
+		if ch.HookScore == nil {
+			unscored++
+			continue
+		}
+		if weakOnly && !ch.HookScore.Weak() {
+			continue
+		}
+		items = append(items, gin.H{
+			"chapter_id":  ch.ID,
+			"chapter_num": ch.ChapterNum,
+			"title":       ch.Title,
+			"hook_score":  ch.HookScore,
+			"weak":        ch.HookScore.Weak(),
+		})
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"chapters":  items,
+		"unscored":  unscored,
+		"threshold": models.WeakHookThreshold,
+	}))
+}
+
+// chapterProse 章节正文，没有正文时拼接蓝图中已生成的场景
+func chapterProse(database db.Database, blueprint *models.NarrativeBlueprint, chapter *models.Chapter) string {
+	if strings.TrimSpace(chapter.Content) != "" || blueprint.ID == "" {
+		return chapter.Content
+	}
+	scenes := database.ListScenesByChapter(blueprint.ID, chapter.ChapterNum)
+	sort.Slice(scenes, func(i, j int) bool { return scenes[i].Scene < scenes[j].Scene })
+	parts := make([]string, 0, len(scenes))
+	for _, s := range scenes {
+		parts = append(parts, s.Content)
+	}
+	return strings.Join(parts, "\n\n")
+}
@@ -0,0 +1,238 @@
+// Package handlers HTTP处理器 - 单场景重新生成
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/memory"
+	"github.com/xlei/xupu/pkg/narrative"
+	"github.com/xlei/xupu/pkg/orchestrator"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// RegenerateScene 重新生成单个场景
+// @Summary 重新生成场景
+// @Description 只重新生成章节细纲中的一个场景（场景指令及正文），可附带修改意见，结果写回章节细纲
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param seq path int true "场景序号"
+// @Param request body RegenerateSceneRequest false "修改意见"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/scenes/{seq}/regenerate [post]
+func (h *ChapterHandler) RegenerateScene(c *gin.Context) {
+	chapterID := c.Param("id")
+	seq, err := strconv.Atoi(c.Param("seq"))
+	if err != nil || seq <= 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "场景序号无效", ""))
+		return
+	}
+
+	var req RegenerateSceneRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	chapter, err := h.chapterRepo.GetByID(c, chapterID)
+	if err != nil {
+		if err == repositories.ErrChapterNotFound {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "章节不存在", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取章节失败", err.Error()))
+		return
+	}
+
+	database := db.Get()
+	project, err := database.GetProject(chapter.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return
+	}
+
+	var blueprint *models.NarrativeBlueprint
+	if project.NarrativeID != "" {
+		blueprint, _ = database.GetNarrativeBlueprint(project.NarrativeID)
+	}
+
+	// 优先使用章节已保存的细纲，没有时从蓝图构建
+	outline := &narrative.ChapterDetailOutline{}
+	if len(chapter.DetailOutline) > 0 {
+		if err := json.Unmarshal(chapter.DetailOutline, outline); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "解析章节细纲失败", err.Error()))
+			return
+		}
+	} else {
+		plan := findChapterPlan(blueprint, chapter.ChapterNum)
+		if plan == nil {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "章节尚无细纲", "请先生成章节细纲"))
+			return
+		}
+		outline = narrative.OutlineFromBlueprint(plan, blueprint.Scenes)
+	}
+
+	budget, ok := userBudget(c, database)
+	if !ok {
+		return
+	}
+	engine, err := narrative.NewEvolutionEngine()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化叙事器失败", err.Error()))
+		return
+	}
+
+	var before interface{}
+	for _, s := range outline.Scenes {
+		if s.Sequence == seq {
+			before = s
+		}
+	}
+
+	scene, err := narrative.NewOrchestrator(engine.WithBudget(budget).WithLanguage(project.Language)).RegenerateScene(outline, seq, req.Guidance)
+	switch {
+	case errors.Is(err, narrative.ErrSceneNotFound):
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "场景不存在", err.Error()))
+		return
+	case errors.Is(err, narrative.ErrScenePinned):
+		c.JSON(http.StatusConflict, errorResponse("SCENE_PINNED", "场景已定稿", err.Error()))
+		return
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "重新生成场景失败", err.Error()))
+		return
+	}
+
+	// 写回章节细纲
+	data, err := json.Marshal(outline)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "序列化章节细纲失败", err.Error()))
+		return
+	}
+	chapter.DetailOutline = data
+	if err := h.chapterRepo.UpdateColumns(c, chapter, "detail_outline"); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存章节细纲失败", err.Error()))
+		return
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditRegenerate,
+		ResourceType: models.AuditResourceChapter,
+		ResourceID:   chapter.ID,
+		ProjectID:    project.ID,
+		Summary:      fmt.Sprintf("重新生成第%d章场景%d", chapter.ChapterNum, seq),
+	}, before, scene)
+
+	response := gin.H{
+		"chapter_id": chapter.ID,
+		"scene":      scene,
+		"outline":    outline,
+	}
+
+	if blueprint != nil {
+		instruction := syncBlueprintScene(blueprint, chapter.ChapterNum, scene)
+		if v := blueprint.POVPolicy.Check(instruction); v != nil {
+			response["pov_violation"] = v
+		}
+		if err := database.SaveNarrativeBlueprint(blueprint); err != nil {
+			response["blueprint_error"] = err.Error()
+		}
+
+		// 重写该场景正文，覆盖原有的场景输出
+		if !req.SkipProse {
+			maxRevisions := writer.DefaultMaxRevisions
+			if req.MaxRevisions != nil {
+				maxRevisions = *req.MaxRevisions
+			}
+			checklist := scene.Checklist()
+			prose, err := regenerateSceneProse(database, budget, project, blueprint, instruction, &checklist, maxRevisions)
+			if err != nil {
+				response["prose_error"] = err.Error()
+			} else {
+				response["prose"] = prose
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(response))
+}
+
+// findChapterPlan 查找蓝图中的章节规划
+func findChapterPlan(blueprint *models.NarrativeBlueprint, chapterNum int) *models.ChapterPlan {
+	if blueprint == nil {
+		return nil
+	}
+	for i := range blueprint.ChapterPlans {
+		if blueprint.ChapterPlans[i].Chapter == chapterNum {
+			return &blueprint.ChapterPlans[i]
+		}
+	}
+	return nil
+}
+
+// syncBlueprintScene 将重新生成的场景同步到蓝图的场景指令，返回同步后的指令
+func syncBlueprintScene(blueprint *models.NarrativeBlueprint, chapterNum int, scene *narrative.SceneDetailInstruction) models.SceneInstruction {
+	for i, s := range blueprint.Scenes {
+		if s.Chapter == chapterNum && s.Scene == scene.Sequence {
+			instruction := scene.ToSceneInstruction(chapterNum, s.ExpectedLength)
+			blueprint.Scenes[i] = instruction
+			return instruction
+		}
+	}
+
+	instruction := scene.ToSceneInstruction(chapterNum, 0)
+	blueprint.Scenes = append(blueprint.Scenes, instruction)
+	return instruction
+}
+
+// regenerateSceneProse 按新的场景指令重写正文
+// budget 为发起用户的月度预算，checklist 为场景细纲的审稿清单，maxRevisions 为审稿不通过时最多重写轮数
+func regenerateSceneProse(database db.Database, budget llm.Budget, project *models.Project, blueprint *models.NarrativeBlueprint, instruction models.SceneInstruction, checklist *writer.SceneChecklist, maxRevisions int) (*writer.SceneGenerationResult, error) {
+	w, err := writer.New()
+	if err != nil {
+		return nil, err
+	}
+	w = w.WithBudget(budget).WithLanguage(project.Language)
+
+	world, _ := database.GetWorld(blueprint.WorldID)
+	styleProfile, _ := writer.ResolveStyleProfile(database, project.StyleProfileID)
+
+	params := writer.GenerateParams{
+		BlueprintID:  blueprint.ID,
+		Chapter:      instruction.Chapter,
+		Scene:        instruction.Scene,
+		Instruction:  &instruction,
+		WorldContext: world,
+		StyleProfile: styleProfile,
+		Memories: memory.NewStore(database).Recall(blueprint.ID, memory.SceneQuery(&instruction), memory.SearchOptions{
+			BeforeChapter: instruction.Chapter,
+		}),
+		Checklist:     checklist,
+		MaxRevisions:  maxRevisions,
+		POVConstraint: blueprint.POVPolicy.Constraint(instruction.Chapter),
+	}
+	orchestrator.BuildSceneContext(database, project, instruction.Chapter).Apply(&params)
+	if existing, err := database.GetSceneByBlueprintAndChapter(blueprint.ID, instruction.Chapter, instruction.Scene); err == nil && existing != nil {
+		params.SceneID = existing.ID
+		if instruction.ExpectedLength == 0 {
+			params.TargetWordCount = existing.WordCount
+		}
+	}
+
+	return w.GenerateScene(params)
+}
@@ -0,0 +1,121 @@
+// Package handlers HTTP处理器 - 章节剧本导出
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/export"
+	"github.com/xlei/xupu/pkg/locale"
+	"github.com/xlei/xupu/pkg/narrative"
+)
+
+// ExportScreenplay 导出章节剧本
+// @Summary 导出章节剧本
+// @Description 按章节细纲的场景把正文转换为剧本：地点和时间作为场景标题，带引号的对白拆为角色台词，其余为动作描述；正文经过手工编辑时整章正文归入第一个场景
+// @Tags chapters
+// @Produce plain,xml
+// @Param id path string true "章节ID"
+// @Param format query string false "导出格式" Enums(fountain, fdx)
+// @Success 200 {string} string
+// @Router /api/v1/chapters/{id}/screenplay [get]
+func (h *ChapterHandler) ExportScreenplay(c *gin.Context) {
+	format := c.DefaultQuery("format", "fountain")
+	if format != "fountain" && format != "fdx" {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "不支持的导出格式", format))
+		return
+	}
+
+	chapter, err := h.chapterRepo.GetByID(c, c.Param("id"))
+	if err != nil {
+		if err == repositories.ErrChapterNotFound {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "章节不存在", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取章节失败", err.Error()))
+		return
+	}
+
+	database := db.Get()
+	project, err := database.GetProject(chapter.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权访问", ""))
+		return
+	}
+
+	var blueprint *models.NarrativeBlueprint
+	if project.NarrativeID != "" {
+		blueprint, _ = database.GetNarrativeBlueprint(project.NarrativeID)
+	}
+
+	// 优先使用章节已保存的细纲，没有时从蓝图构建
+	outline := &narrative.ChapterDetailOutline{}
+	if len(chapter.DetailOutline) > 0 {
+		if err := json.Unmarshal(chapter.DetailOutline, outline); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "解析章节细纲失败", err.Error()))
+			return
+		}
+	} else if plan := findChapterPlan(blueprint, chapter.ChapterNum); plan != nil {
+		outline = narrative.OutlineFromBlueprint(plan, blueprint.Scenes)
+	}
+	if len(outline.Scenes) == 0 {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "章节尚无细纲", "剧本的场景划分来自章节细纲，请先生成章节细纲"))
+		return
+	}
+
+	scenes := screenplayScenes(database, blueprint, chapter, outline)
+	title := strings.TrimSpace(locale.For(project.Language).Chapter(chapter.ChapterNum) + " " + chapter.Title)
+	script := export.ConvertScreenplay(title, scenes)
+
+	filename := fmt.Sprintf("%s-%03d", project.ID, chapter.ChapterNum)
+	if format == "fdx" {
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.fdx\"", filename))
+		c.Data(http.StatusOK, "application/xml; charset=utf-8", []byte(export.RenderFDX(script)))
+		return
+	}
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.fountain\"", filename))
+	c.String(http.StatusOK, export.RenderFountain(script))
+}
+
+// screenplayScenes 由细纲场景和正文组装剧本转换的输入
+// 正文未编辑过时使用各场景的输出，否则整章正文归入第一个场景，其余场景只有场景标题和动作概要
+func screenplayScenes(database db.Database, blueprint *models.NarrativeBlueprint, chapter *models.Chapter, outline *narrative.ChapterDetailOutline) []export.ScreenplayScene {
+	prose := make(map[int]string)
+	if strings.TrimSpace(chapter.Content) == "" && blueprint != nil {
+		for _, s := range database.ListScenesByChapter(blueprint.ID, chapter.ChapterNum) {
+			prose[s.Scene] = s.Content
+		}
+	}
+
+	scenes := make([]export.ScreenplayScene, 0, len(outline.Scenes))
+	for i, s := range outline.Scenes {
+		timeLabel := s.Time
+		if timeLabel == "" && s.StoryTime != nil {
+			timeLabel = s.StoryTime.Label
+		}
+		scene := export.ScreenplayScene{
+			Sequence:   s.Sequence,
+			Location:   s.Location,
+			Time:       timeLabel,
+			Characters: append(append([]string{}, s.Characters...), s.POVCharacter),
+			Summary:    s.MainAction,
+			Prose:      prose[s.Sequence],
+		}
+		if i == 0 && strings.TrimSpace(chapter.Content) != "" {
+			scene.Prose = chapter.Content
+		}
+		scenes = append(scenes, scene)
+	}
+	return scenes
+}
@@ -0,0 +1,298 @@
+// Package handlers HTTP处理器 - 修改建议（修订模式）
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// CreateEditSuggestion 为章节选段生成修改建议
+// @Summary 生成修改建议
+// @Description 按作者指令（如“更紧张一些”“删掉副词”）改写选段，返回改写结果和逐句差异；建议保存为待处理，由作者逐句采纳或拒绝后写回章节
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param request body CreateEditSuggestionRequest true "选段和指令"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/chapters/{id}/suggestions [post]
+func (h *ChapterHandler) CreateEditSuggestion(c *gin.Context) {
+	var req CreateEditSuggestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	chapter, project, ok := h.loadOwnedChapter(c)
+	if !ok {
+		return
+	}
+	h.createEditSuggestion(c, chapter, project, req.Start, req.End, req.Instruction, "")
+}
+
+// createEditSuggestion 对章节正文 [start, end) 按指令生成修改建议并保存，结果已写入响应
+func (h *ChapterHandler) createEditSuggestion(c *gin.Context, chapter *models.Chapter, project *models.Project, start, end int, instruction, annotationID string) {
+	content := []rune(chapter.Content)
+	if start < 0 || start >= end || end > len(content) {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "选段超出章节正文范围", fmt.Sprintf("正文共%d字", len(content))))
+		return
+	}
+	if end-start > writer.MaxSuggestionRunes {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "选段过长", fmt.Sprintf("单次最多%d字", writer.MaxSuggestionRunes)))
+		return
+	}
+	passage := string(content[start:end])
+	if strings.TrimSpace(passage) == "" {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "选段为空", ""))
+		return
+	}
+
+	budget, ok := userBudget(c, db.Get())
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化写作器失败", err.Error()))
+		return
+	}
+	w = w.WithBudget(budget).WithLanguage(project.Language).WithLogger(requestLogger(c))
+	styleProfile, _ := writer.ResolveStyleProfile(db.Get(), project.StyleProfileID)
+
+	result, err := w.SuggestEdit(writer.EditSuggestionParams{
+		Passage:      passage,
+		Instruction:  instruction,
+		Before:       string(content[:start]),
+		After:        string(content[end:]),
+		StyleProfile: styleProfile,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "生成修改建议失败", err.Error()))
+		return
+	}
+
+	suggestion := &models.EditSuggestion{
+		ID:             db.GenerateID("suggestion"),
+		ProjectID:      project.ID,
+		ChapterID:      chapter.ID,
+		AnnotationID:   annotationID,
+		Instruction:    strings.TrimSpace(instruction),
+		Start:          start,
+		End:            end,
+		Original:       passage,
+		Revised:        result.Revised,
+		Notes:          result.Notes,
+		Hunks:          result.Hunks,
+		Status:         models.SuggestionPending,
+		ChapterVersion: chapter.Version,
+	}
+	if userID, ok := GetUserID(c); ok {
+		suggestion.UserID = userID
+	}
+	if err := db.Get().SaveEditSuggestion(suggestion); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存修改建议失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, successResponse(suggestion))
+}
+
+// ListEditSuggestions 列出章节的修改建议
+// @Summary 修改建议列表
+// @Tags chapters
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param status query string false "按状态过滤：pending/applied/rejected"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/suggestions [get]
+func (h *ChapterHandler) ListEditSuggestions(c *gin.Context) {
+	chapter, _, ok := h.loadOwnedChapter(c)
+	if !ok {
+		return
+	}
+	list, err := db.Get().ListEditSuggestions(chapter.ID, c.Query("status"))
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取修改建议失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"suggestions": list,
+		"total":       len(list),
+	}))
+}
+
+// GetEditSuggestion 获取修改建议详情
+// @Summary 获取修改建议
+// @Tags chapters
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param sid path string true "修改建议ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/suggestions/{sid} [get]
+func (h *ChapterHandler) GetEditSuggestion(c *gin.Context) {
+	chapter, _, ok := h.loadOwnedChapter(c)
+	if !ok {
+		return
+	}
+	suggestion, err := db.Get().GetEditSuggestion(c.Param("sid"))
+	if err != nil || suggestion.ChapterID != chapter.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "修改建议不存在", ""))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(suggestion))
+}
+
+// ResolveEditSuggestion 逐句采纳或拒绝修改建议，并把结果写回章节
+// @Summary 处理修改建议
+// @Description accept 列出采纳的修改序号（hunks 中的 index），未列出的修改视为拒绝；选段在生成建议后被改动过时返回 409
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param sid path string true "修改建议ID"
+// @Param request body ResolveEditSuggestionRequest true "采纳的修改"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/suggestions/{sid}/resolve [post]
+func (h *ChapterHandler) ResolveEditSuggestion(c *gin.Context) {
+	var req ResolveEditSuggestionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	chapter, project, ok := h.loadOwnedChapter(c)
+	if !ok {
+		return
+	}
+	database := db.Get()
+	suggestion, err := database.GetEditSuggestion(c.Param("sid"))
+	if err != nil || suggestion.ChapterID != chapter.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "修改建议不存在", ""))
+		return
+	}
+	if suggestion.Status != models.SuggestionPending {
+		c.JSON(http.StatusConflict, errorResponse("ALREADY_RESOLVED", "修改建议已处理", suggestion.Status))
+		return
+	}
+
+	accepted := make(map[int]bool, len(req.Accept))
+	for _, i := range req.Accept {
+		if i < 0 || i >= len(suggestion.Hunks) || !suggestion.Hunks[i].Changed() {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", fmt.Sprintf("修改序号无效: %d", i), ""))
+			return
+		}
+		accepted[i] = true
+	}
+	count := 0
+	for i := range suggestion.Hunks {
+		hunk := &suggestion.Hunks[i]
+		if !hunk.Changed() {
+			continue
+		}
+		if req.AcceptAll || accepted[i] {
+			hunk.Decision = models.HunkAccepted
+			count++
+		} else {
+			hunk.Decision = models.HunkRejected
+		}
+	}
+	merged := writer.MergeHunks(suggestion.Hunks)
+
+	now := time.Now()
+	suggestion.Result = merged
+	suggestion.ResolvedAt = &now
+	suggestion.Status = models.SuggestionRejected
+	if count > 0 {
+		// 选段位置可能因其他修改而移动，原位置对不上时按原文查找唯一出现的位置
+		start, found := locatePassage(chapter.Content, suggestion.Original, suggestion.Start)
+		if !found {
+			c.JSON(http.StatusConflict, errorResponse("SUGGESTION_STALE", "选段在生成建议后已被修改", "请重新选择选段生成建议"))
+			return
+		}
+		content := []rune(chapter.Content)
+		end := start + utf8.RuneCountInString(suggestion.Original)
+		before := *chapter
+		chapter.Content = string(content[:start]) + merged + string(content[end:])
+		chapter.WordCount = utf8.RuneCountInString(chapter.Content)
+		if err := h.chapterRepo.UpdateWithVersion(c, chapter, before.Version); err != nil {
+			if err == repositories.ErrChapterVersionConflict {
+				c.JSON(http.StatusConflict, errorResponse("VERSION_CONFLICT", "章节已被修改", "请刷新后重试"))
+				return
+			}
+			c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存章节失败", err.Error()))
+			return
+		}
+		suggestion.Status = models.SuggestionApplied
+		recordAudit(c, &models.AuditLog{
+			Action:       models.AuditUpdate,
+			ResourceType: models.AuditResourceChapter,
+			ResourceID:   chapter.ID,
+			ProjectID:    project.ID,
+			Summary:      fmt.Sprintf("第%d章采纳修改建议 %d 处（%s）", chapter.ChapterNum, count, suggestion.Instruction),
+		}, &before, chapter)
+		if suggestion.AnnotationID != "" {
+			h.resolveAnnotationBySuggestion(c, suggestion)
+		}
+	}
+	if err := database.SaveEditSuggestion(suggestion); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存修改建议失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"suggestion": suggestion,
+		"accepted":   count,
+		"chapter": gin.H{
+			"id":         chapter.ID,
+			"version":    chapter.Version,
+			"word_count": chapter.WordCount,
+		},
+	}))
+}
+
+// loadOwnedChapter 读取路径中的章节并校验当前用户是项目所有者，失败时已写入响应
+func (h *ChapterHandler) loadOwnedChapter(c *gin.Context) (*models.Chapter, *models.Project, bool) {
+	chapter, err := h.chapterRepo.GetByID(c, c.Param("id"))
+	if err != nil {
+		if err == repositories.ErrChapterNotFound {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "章节不存在", ""))
+			return nil, nil, false
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取章节失败", err.Error()))
+		return nil, nil, false
+	}
+	project, err := db.Get().GetProject(chapter.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return nil, nil, false
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return nil, nil, false
+	}
+	return chapter, project, true
+}
+
+// locatePassage 在正文中定位选段（按字符计）：优先使用原位置，否则要求原文在正文中只出现一次
+func locatePassage(content, passage string, start int) (int, bool) {
+	runes := []rune(content)
+	n := utf8.RuneCountInString(passage)
+	if start >= 0 && start+n <= len(runes) && string(runes[start:start+n]) == passage {
+		return start, true
+	}
+	idx := strings.Index(content, passage)
+	if idx < 0 || strings.Contains(content[idx+1:], passage) {
+		return 0, false
+	}
+	return utf8.RuneCountInString(content[:idx]), true
+}
@@ -0,0 +1,277 @@
+// Package handlers HTTP处理器 - 章节标题生成
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// GenerateChapterTitles 生成章节标题候选
+// @Summary 生成章节标题
+// @Description 根据本章摘要或正文提出多个标题候选并为点击吸引力评分，候选写入章节记录；apply=true 时采用得分最高的候选
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param request body GenerateTitlesRequest false "候选数量与是否采用"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/titles [post]
+func (h *ChapterHandler) GenerateChapterTitles(c *gin.Context) {
+	var req GenerateTitlesRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	chapter, err := h.chapterRepo.GetByID(c, c.Param("id"))
+	if err != nil {
+		if err == repositories.ErrChapterNotFound {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "章节不存在", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取章节失败", err.Error()))
+		return
+	}
+
+	database := db.Get()
+	project, err := database.GetProject(chapter.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return
+	}
+
+	blueprint := projectBlueprint(database, project)
+	chapters := sortedChapters(database, project.ID)
+
+	budget, ok := userBudget(c, database)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化写作器失败", err.Error()))
+		return
+	}
+	w = w.WithBudget(budget).WithLanguage(project.Language)
+
+	candidates, err := generateChapterTitles(database, w, blueprint, chapters, chapter, req.Count)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "生成章节标题失败", err.Error()))
+		return
+	}
+
+	previous := chapter.Title
+	chapter.TitleCandidates = candidates
+	if req.Apply {
+		applyChapterTitle(blueprint, chapter, candidates[0].Title)
+	}
+	if err := h.chapterRepo.UpdateColumns(c, chapter, titleColumns(req.Apply)...); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存标题候选失败", err.Error()))
+		return
+	}
+	if req.Apply {
+		auditTitleChange(c, chapter, previous)
+		if blueprint.ID != "" {
+			database.SaveNarrativeBlueprint(blueprint)
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"chapter_id": chapter.ID,
+		"title":      chapter.Title,
+		"candidates": candidates,
+	}))
+}
+
+// RegenerateChapterTitles 批量重新生成章节标题
+// @Summary 批量重新生成章节标题
+// @Description 全书写完后按各章实际内容重新生成标题候选，依章节顺序逐章进行以避免前后雷同；apply=true 时各章采用得分最高的候选，没有正文的章节跳过
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body RegenerateTitlesRequest false "章节范围与是否采用"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/titles/regenerate [post]
+func (h *ChapterHandler) RegenerateChapterTitles(c *gin.Context) {
+	var req RegenerateTitlesRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	database := db.Get()
+	project, err := database.GetProject(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return
+	}
+
+	blueprint := projectBlueprint(database, project)
+	chapters := sortedChapters(database, project.ID)
+
+	budget, ok := userBudget(c, database)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化写作器失败", err.Error()))
+		return
+	}
+	w = w.WithBudget(budget).WithLanguage(project.Language)
+
+	wanted := make(map[int]bool, len(req.Chapters))
+	for _, n := range req.Chapters {
+		wanted[n] = true
+	}
+
+	logger := requestLogger(c)
+	results := make([]gin.H, 0, len(chapters))
+	skipped := make([]int, 0)
+	failed := 0
+	for _, ch := range chapters {
+		if len(wanted) > 0 && !wanted[ch.ChapterNum] {
+			continue
+		}
+		if strings.TrimSpace(ch.Summary) == "" && strings.TrimSpace(chapterProse(database, blueprint, ch)) == "" {
+			skipped = append(skipped, ch.ChapterNum)
+			continue
+		}
+
+		previous := ch.Title
+		candidates, err := generateChapterTitles(database, w, blueprint, chapters, ch, req.Count)
+		if err != nil {
+			logger.Warn("生成章节标题失败", "chapter", ch.ChapterNum, "error", err)
+			results = append(results, gin.H{"chapter_num": ch.ChapterNum, "error": err.Error()})
+			failed++
+			continue
+		}
+		ch.TitleCandidates = candidates
+		if req.Apply {
+			applyChapterTitle(blueprint, ch, candidates[0].Title)
+		}
+		if err := h.chapterRepo.UpdateColumns(c, ch, titleColumns(req.Apply)...); err != nil {
+			results = append(results, gin.H{"chapter_num": ch.ChapterNum, "error": err.Error()})
+			failed++
+			continue
+		}
+		if req.Apply {
+			auditTitleChange(c, ch, previous)
+		}
+		results = append(results, gin.H{
+			"chapter_id":     ch.ID,
+			"chapter_num":    ch.ChapterNum,
+			"previous_title": previous,
+			"title":          ch.Title,
+			"candidates":     candidates,
+		})
+	}
+
+	response := gin.H{
+		"chapters": results,
+		"skipped":  skipped,
+		"failed":   failed,
+	}
+	if req.Apply && blueprint.ID != "" {
+		if err := database.SaveNarrativeBlueprint(blueprint); err != nil {
+			response["blueprint_error"] = err.Error()
+		}
+	}
+	c.JSON(http.StatusOK, successResponse(response))
+}
+
+// auditTitleChange 记录采用生成标题造成的标题变更
+func auditTitleChange(c *gin.Context, chapter *models.Chapter, previous string) {
+	if chapter.Title == previous {
+		return
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditRegenerate,
+		ResourceType: models.AuditResourceChapter,
+		ResourceID:   chapter.ID,
+		ProjectID:    chapter.ProjectID,
+		Summary:      fmt.Sprintf("第%d章改用生成的标题", chapter.ChapterNum),
+	}, previous, chapter.Title)
+}
+
+// generateChapterTitles 为章节生成标题候选，相邻章节的标题作为对照
+func generateChapterTitles(database db.Database, w *writer.Writer, blueprint *models.NarrativeBlueprint, chapters []*models.Chapter, chapter *models.Chapter, count int) ([]models.TitleCandidate, error) {
+	params := writer.TitleParams{
+		Chapter:      chapter.ChapterNum,
+		CurrentTitle: chapter.Title,
+		Summary:      chapter.Summary,
+		Count:        count,
+	}
+	if strings.TrimSpace(params.Summary) == "" {
+		params.Prose = chapterProse(database, blueprint, chapter)
+	}
+	for i, ch := range chapters {
+		if ch.ID != chapter.ID {
+			continue
+		}
+		if i > 0 {
+			params.Neighbors = append(params.Neighbors, chapters[i-1].Title)
+		}
+		if i+1 < len(chapters) {
+			params.Neighbors = append(params.Neighbors, chapters[i+1].Title)
+		}
+		break
+	}
+	return w.GenerateTitles(params)
+}
+
+// applyChapterTitle 采用新标题，同步到蓝图的章节规划
+func applyChapterTitle(blueprint *models.NarrativeBlueprint, chapter *models.Chapter, title string) {
+	chapter.Title = title
+	if plan := findChapterPlan(blueprint, chapter.ChapterNum); plan != nil {
+		plan.Title = title
+	}
+}
+
+// titleColumns 保存标题生成结果时更新的列，只有采用标题时才写 title
+func titleColumns(apply bool) []string {
+	if apply {
+		return []string{"title_candidates", "title"}
+	}
+	return []string{"title_candidates"}
+}
+
+// projectBlueprint 项目的叙事蓝图，没有时返回空蓝图
+func projectBlueprint(database db.Database, project *models.Project) *models.NarrativeBlueprint {
+	if project.NarrativeID != "" {
+		if bp, err := database.GetNarrativeBlueprint(project.NarrativeID); err == nil && bp != nil {
+			return bp
+		}
+	}
+	return &models.NarrativeBlueprint{}
+}
+
+// sortedChapters 项目的全部章节，按章节号排序
+func sortedChapters(database db.Database, projectID string) []*models.Chapter {
+	chapters := database.ListChaptersByProject(projectID)
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].ChapterNum < chapters[j].ChapterNum })
+	return chapters
+}
@@ -0,0 +1,114 @@
+// Package handlers HTTP处理器 - 对话语音检查
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/orchestrator"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// CheckChapterVoice 检查章节对话的语音一致性
+// @Summary 检查对话语音一致性
+// @Description 抽样章节台词，对照说话者的语音档案标记用词、语体或口头禅走样的台词，结果写入章节记录
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param request body CheckVoiceRequest false "检查参数"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/chapters/{id}/voice-check [post]
+func (h *ChapterHandler) CheckChapterVoice(c *gin.Context) {
+	chapterID := c.Param("id")
+
+	var req CheckVoiceRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	chapter, err := h.chapterRepo.GetByID(c, chapterID)
+	if err != nil {
+		if err == repositories.ErrChapterNotFound {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "章节不存在", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取章节失败", err.Error()))
+		return
+	}
+
+	database := db.Get()
+	project, err := database.GetProject(chapter.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return
+	}
+
+	blueprint := &models.NarrativeBlueprint{}
+	if project.NarrativeID != "" {
+		if bp, err := database.GetNarrativeBlueprint(project.NarrativeID); err == nil && bp != nil {
+			blueprint = bp
+		}
+	}
+	var world *models.WorldSetting
+	if project.WorldID != "" {
+		world, _ = database.GetWorld(project.WorldID)
+	}
+
+	profiles := orchestrator.VoiceProfiles(database, blueprint, world)
+	if len(profiles) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("NO_VOICE_PROFILES", "项目角色没有语音档案", "请先生成或补充角色语音档案"))
+		return
+	}
+
+	// 优先检查章节正文，没有时拼接已生成的场景
+	prose := chapterProse(database, blueprint, chapter)
+	if strings.TrimSpace(prose) == "" {
+		c.JSON(http.StatusBadRequest, errorResponse("EMPTY_CHAPTER", "章节没有正文", ""))
+		return
+	}
+
+	budget, ok := userBudget(c, database)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化写作器失败", err.Error()))
+		return
+	}
+	w = w.WithBudget(budget).WithLanguage(project.Language)
+	issues, err := w.CheckVoice(writer.VoiceCheckParams{
+		Chapter:    chapter.ChapterNum,
+		Prose:      prose,
+		Profiles:   profiles,
+		SampleSize: req.SampleSize,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "对话语音检查失败", err.Error()))
+		return
+	}
+
+	chapter.VoiceIssues = issues
+	if err := h.chapterRepo.UpdateColumns(c, chapter, "voice_issues"); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存检查结果失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"chapter_id": chapter.ID,
+		"issues":     issues,
+	}))
+}
@@ -0,0 +1,161 @@
+// Package handlers HTTP处理器 - 章节成文
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/narrative"
+	"github.com/xlei/xupu/pkg/orchestrator"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// WriteChapter 按章节细纲成文
+// @Summary 按细纲写作章节
+// @Description 按章节细纲依次执行初稿（逐场景）、连贯性修订（全章）和润色（逐句），结果写入章节正文；各阶段的模型和提示词见配置 writer_draft/writer_revise/writer_polish
+// @Tags chapters
+// @Accept json
+// @Produce json
+// @Param id path string true "章节ID"
+// @Param request body WriteChapterRequest false "执行的阶段"
+// @Success 200 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Router /api/v1/chapters/{id}/write [post]
+func (h *ChapterHandler) WriteChapter(c *gin.Context) {
+	var req WriteChapterRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	chapter, err := h.chapterRepo.GetByID(c, c.Param("id"))
+	if err != nil {
+		if err == repositories.ErrChapterNotFound {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "章节不存在", ""))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取章节失败", err.Error()))
+		return
+	}
+	// 写作耗时较长，保存时按开始时的版本号条件更新，期间章节被修改则不覆盖
+	version := chapter.Version
+
+	database := db.Get()
+	project, err := database.GetProject(chapter.ProjectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return
+	}
+	if strings.TrimSpace(chapter.Content) != "" && !req.Overwrite {
+		c.JSON(http.StatusConflict, errorResponse("CHAPTER_NOT_EMPTY", "章节已有正文", "如需覆盖请设置 overwrite=true"))
+		return
+	}
+
+	var blueprint *models.NarrativeBlueprint
+	if project.NarrativeID != "" {
+		blueprint, _ = database.GetNarrativeBlueprint(project.NarrativeID)
+	}
+
+	// 优先使用章节已保存的细纲，没有时从蓝图构建
+	outline := &narrative.ChapterDetailOutline{}
+	if len(chapter.DetailOutline) > 0 {
+		if err := json.Unmarshal(chapter.DetailOutline, outline); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "解析章节细纲失败", err.Error()))
+			return
+		}
+	} else {
+		plan := findChapterPlan(blueprint, chapter.ChapterNum)
+		if plan == nil {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "章节尚无细纲", "请先生成章节细纲"))
+			return
+		}
+		outline = narrative.OutlineFromBlueprint(plan, blueprint.Scenes)
+	}
+	if outline.Title == "" {
+		outline.Title = chapter.Title
+	}
+
+	budget, ok := userBudget(c, database)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化写作器失败", err.Error()))
+		return
+	}
+	w = w.WithBudget(budget).WithLanguage(project.Language).WithLogger(requestLogger(c))
+
+	params := writer.ChapterParams{
+		Plan:   outline.WritingPlan(),
+		Stages: req.Stages,
+	}
+	params.StyleProfile, _ = writer.ResolveStyleProfile(database, project.StyleProfileID)
+	if world, err := database.GetWorld(project.WorldID); err == nil {
+		params.WorldContext = world
+	}
+	if blueprint != nil {
+		params.VoiceProfiles = orchestrator.VoiceProfiles(database, blueprint, params.WorldContext)
+	}
+	orchestrator.BuildSceneContext(database, project, chapter.ChapterNum).ApplyChapter(&params)
+	if chapter.ChapterNum > 1 {
+		if prev, err := database.GetChapterByNum(project.ID, chapter.ChapterNum-1); err == nil && prev != nil && prev.RollingSummary != "" {
+			params.PreviousSummary = writer.FormatPreviousContext(prev.RollingSummary, prev.StateDeltas)
+		}
+	}
+
+	result, err := w.WriteChapter(params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "章节写作失败", err.Error()))
+		return
+	}
+
+	before := *chapter
+	now := time.Now()
+	chapter.Content = result.Content
+	chapter.WordCount = result.WordCount
+	chapter.AIWordCount = result.WordCount
+	chapter.GeneratedAt = &now
+	if err := h.chapterRepo.UpdateWithVersion(c, chapter, version); err != nil {
+		if err == repositories.ErrChapterVersionConflict {
+			c.JSON(http.StatusConflict, errorResponse("VERSION_CONFLICT", "章节在写作期间已被修改", "请刷新后重试"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存章节正文失败", err.Error()))
+		return
+	}
+	stages := make([]string, len(result.Stages))
+	for i, s := range result.Stages {
+		stages[i] = s.Stage
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditRegenerate,
+		ResourceType: models.AuditResourceChapter,
+		ResourceID:   chapter.ID,
+		ProjectID:    project.ID,
+		Summary:      fmt.Sprintf("按细纲写作第%d章（%s），%d 字", chapter.ChapterNum, strings.Join(stages, " → "), result.WordCount),
+	}, &before, chapter)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"chapter_id":       chapter.ID,
+		"word_count":       result.WordCount,
+		"content":          result.Content,
+		"drafts":           result.Drafts,
+		"stages":           result.Stages,
+		"term_corrections": result.TermCorrections,
+	}))
+}
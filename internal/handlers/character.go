@@ -9,11 +9,14 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/naming"
+	"github.com/xlei/xupu/pkg/worldbuilder"
 )
 
 // CharacterHandler 角色处理器
 type CharacterHandler struct {
-	db db.Database
+	db           db.Database
+	worldBuilder *worldbuilder.WorldBuilder // 立绘提示词生成，首次使用时创建
 }
 
 // NewCharacterHandler 创建角色处理器
@@ -73,6 +76,20 @@ func (h *CharacterHandler) GachaCharacters(c *gin.Context) {
 		supportingCharacters = append(supportingCharacters, char)
 	}
 
+	// 按世界命名规范起名，并登记到世界的人名登记中避免重名
+	if world != nil {
+		names := naming.NewGenerator(world)
+		if req.ProtagonistName == "" {
+			mainCharacter.Name = names.Generate(mainCharacter.StaticProfile.Gender)
+		} else {
+			names.Register(req.ProtagonistName)
+		}
+		for i := range supportingCharacters {
+			supportingCharacters[i].Name = names.Generate(supportingCharacters[i].StaticProfile.Gender)
+		}
+		h.db.SaveWorld(world)
+	}
+
 	// 保存所有角色
 	var characterIDs []string
 	characterIDs = append(characterIDs, mainCharacter.ID)
@@ -135,6 +152,12 @@ func (h *CharacterHandler) generateMainCharacter(project *models.Project, world
 				HumanNature: "人性本善",
 				Morality:    []string{"诚实", "正义", "保护弱小"},
 			},
+			Voice: &models.VoiceProfile{
+				Diction:       "直白朴素，少用书面语",
+				Formality:     "随意",
+				SentenceStyle: "短句多，急了会连声追问",
+				Avoid:         []string{"客套话", "阴阳怪气"},
+			},
 		}
 	} else {
 		// 默认主角设定
@@ -165,6 +188,12 @@ func (h *CharacterHandler) generateMainCharacter(project *models.Project, world
 				HumanNature: "人性复杂",
 				Morality:    []string{"理性", "实用主义"},
 			},
+			Voice: &models.VoiceProfile{
+				Diction:       "干脆利落",
+				Formality:     "中性",
+				SentenceStyle: "多用判断句，很少解释",
+				Avoid:         []string{"犹豫的语气词"},
+			},
 		}
 	}
 
@@ -221,10 +250,36 @@ func (h *CharacterHandler) generateSupportingCharacter(project *models.Project,
 			Morality:    []string{"责任", "荣誉"},
 		},
 	}
+	if voice, ok := supportingVoices[role]; ok {
+		v := *voice
+		character.NarrativeProfile.Voice = &v
+	}
 
 	return character
 }
 
+// supportingVoices 配角定位对应的默认语音档案
+var supportingVoices = map[string]*models.VoiceProfile{
+	"导师": {
+		Diction:       "措辞考究，爱引经据典",
+		Formality:     "正式",
+		SentenceStyle: "语速慢，常以反问引导对方思考",
+		Avoid:         []string{"网络用语", "粗话"},
+	},
+	"伙伴": {
+		Diction:       "口语化，爱开玩笑",
+		Formality:     "随意",
+		SentenceStyle: "话多，喜欢插科打诨",
+		Avoid:         []string{"文绉绉的说法"},
+	},
+	"对手": {
+		Diction:       "尖刻，带刺",
+		Formality:     "中性",
+		SentenceStyle: "惜字如金，常用冷淡的短句和讥讽",
+		Avoid:         []string{"示弱的话"},
+	},
+}
+
 // GachaCharactersRequest 抽卡生成角色请求
 type GachaCharactersRequest struct {
 	ProtagonistName string `json:"protagonist_name"`
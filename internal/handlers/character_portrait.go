@@ -0,0 +1,57 @@
+// Package handlers HTTP处理器 - 角色立绘提示词
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/worldbuilder"
+)
+
+// GetPortraitPrompt 获取角色立绘提示词
+// @Summary 获取角色立绘提示词
+// @Description 返回角色的结构化外貌描述和 Stable Diffusion / Midjourney 提示词；尚未生成或 regenerate=true 时按世界的时代与风格生成并保存到角色档案
+// @Tags characters
+// @Produce json
+// @Param id path string true "角色ID"
+// @Param regenerate query bool false "重新生成"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/characters/{id}/portrait-prompt [get]
+func (h *CharacterHandler) GetPortraitPrompt(c *gin.Context) {
+	character, err := h.db.GetCharacter(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "角色不存在", ""))
+		return
+	}
+
+	if character.Portrait != nil && c.Query("regenerate") != "true" {
+		c.JSON(http.StatusOK, successResponse(character.Portrait))
+		return
+	}
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+
+	if h.worldBuilder == nil {
+		wb, err := worldbuilder.New()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化失败", err.Error()))
+			return
+		}
+		h.worldBuilder = wb
+	}
+
+	portrait, err := h.worldBuilder.WithBudget(budget).WithLogger(requestLogger(c)).GeneratePortrait(character)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("PORTRAIT_FAILED", "生成立绘提示词失败", err.Error()))
+		return
+	}
+	character.Portrait = portrait
+	if err := h.db.SaveCharacter(character); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存角色失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(portrait))
+}
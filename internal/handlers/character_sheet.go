@@ -0,0 +1,258 @@
+// Package handlers HTTP处理器 - 角色卡
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// CreateCharacterSheetRequest 新建角色卡请求
+// 手动新建的角色卡默认锁定所有已填写的字段，下一次演化作为预设角色注入
+type CreateCharacterSheetRequest struct {
+	Name              string   `json:"name" binding:"required"`
+	Role              string   `json:"role"`
+	ConsciousWant     string   `json:"conscious_want"`
+	UnconsciousNeed   string   `json:"unconscious_need"`
+	Fear              string   `json:"fear"`
+	MaskingBehavior   []string `json:"masking_behavior"`
+	Secrets           []string `json:"secrets"`
+	InternalConflicts []string `json:"internal_conflicts"`
+	CurrentEmotion    string   `json:"current_emotion"`
+}
+
+// UpdateCharacterSheetRequest 编辑角色卡请求
+// 修改过的字段自动锁定；lock/unlock 显式调整锁定字段，unlock 最后生效
+type UpdateCharacterSheetRequest struct {
+	Role              *string   `json:"role"`
+	ConsciousWant     *string   `json:"conscious_want"`
+	UnconsciousNeed   *string   `json:"unconscious_need"`
+	Fear              *string   `json:"fear"`
+	MaskingBehavior   *[]string `json:"masking_behavior"`
+	Secrets           *[]string `json:"secrets"`
+	InternalConflicts *[]string `json:"internal_conflicts"`
+	CurrentEmotion    *string   `json:"current_emotion"`
+	Lock              []string  `json:"lock"`
+	Unlock            []string  `json:"unlock"`
+}
+
+// projectWorldID 项目关联的世界ID，项目不存在或未关联世界时写入错误响应
+func (h *CharacterHandler) projectWorldID(c *gin.Context) (string, bool) {
+	project, err := h.db.GetProject(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return "", false
+	}
+	if project.WorldID == "" {
+		c.JSON(http.StatusBadRequest, errorResponse("NO_WORLD", "项目未关联世界", ""))
+		return "", false
+	}
+	return project.WorldID, true
+}
+
+// loadSheet 读取属于项目世界的角色卡，失败时写入错误响应
+func (h *CharacterHandler) loadSheet(c *gin.Context) (*models.CharacterSheet, bool) {
+	worldID, ok := h.projectWorldID(c)
+	if !ok {
+		return nil, false
+	}
+	sheet, err := h.db.GetCharacterSheet(c.Param("sheetId"))
+	if err != nil || sheet.WorldID != worldID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "角色卡不存在", ""))
+		return nil, false
+	}
+	return sheet, true
+}
+
+// ListCharacterSheets 获取项目角色卡
+// @Summary 获取角色卡列表
+// @Description 获取项目世界中演化生成或手动新建的角色卡，包含锁定字段
+// @Tags characters
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/characters/sheets [get]
+func (h *CharacterHandler) ListCharacterSheets(c *gin.Context) {
+	worldID, ok := h.projectWorldID(c)
+	if !ok {
+		return
+	}
+	sheets, err := h.db.ListCharacterSheets(worldID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("LIST_FAILED", "获取角色卡失败", err.Error()))
+		return
+	}
+	if sheets == nil {
+		sheets = []models.CharacterSheet{}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"sheets":          sheets,
+		"lockable_fields": models.CharacterSheetFields,
+	}))
+}
+
+// CreateCharacterSheet 新建角色卡
+// @Summary 新建角色卡
+// @Description 手动新建角色卡，已填写的字段锁定，下一次生成蓝图时作为预设角色参与演化
+// @Tags characters
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body CreateCharacterSheetRequest true "角色卡"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/characters/sheets [post]
+func (h *CharacterHandler) CreateCharacterSheet(c *gin.Context) {
+	worldID, ok := h.projectWorldID(c)
+	if !ok {
+		return
+	}
+	var req CreateCharacterSheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	sheet := &models.CharacterSheet{
+		ID:                db.GenerateID("sheet"),
+		WorldID:           worldID,
+		Name:              req.Name,
+		Role:              req.Role,
+		ConsciousWant:     req.ConsciousWant,
+		UnconsciousNeed:   req.UnconsciousNeed,
+		Fear:              req.Fear,
+		MaskingBehavior:   req.MaskingBehavior,
+		Secrets:           req.Secrets,
+		InternalConflicts: req.InternalConflicts,
+		CurrentEmotion:    req.CurrentEmotion,
+	}
+	filled := map[string]bool{
+		models.SheetFieldRole:              req.Role != "",
+		models.SheetFieldConsciousWant:     req.ConsciousWant != "",
+		models.SheetFieldUnconsciousNeed:   req.UnconsciousNeed != "",
+		models.SheetFieldFear:              req.Fear != "",
+		models.SheetFieldMaskingBehavior:   len(req.MaskingBehavior) > 0,
+		models.SheetFieldSecrets:           len(req.Secrets) > 0,
+		models.SheetFieldInternalConflicts: len(req.InternalConflicts) > 0,
+		models.SheetFieldCurrentEmotion:    req.CurrentEmotion != "",
+	}
+	for _, field := range models.CharacterSheetFields {
+		if filled[field] {
+			sheet.Lock(field)
+		}
+	}
+
+	if err := h.db.SaveCharacterSheet(sheet); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存角色卡失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusCreated, successResponse(sheet))
+}
+
+// GetCharacterSheet 获取角色卡
+// @Summary 获取角色卡
+// @Tags characters
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param sheetId path string true "角色卡ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/characters/sheets/{sheetId} [get]
+func (h *CharacterHandler) GetCharacterSheet(c *gin.Context) {
+	sheet, ok := h.loadSheet(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(sheet))
+}
+
+// UpdateCharacterSheet 编辑角色卡
+// @Summary 编辑角色卡
+// @Description 修改欲望、恐惧、秘密等字段并设置锁定，锁定字段在重新演化时保持不变
+// @Tags characters
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param sheetId path string true "角色卡ID"
+// @Param request body UpdateCharacterSheetRequest true "修改内容"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/characters/sheets/{sheetId} [patch]
+func (h *CharacterHandler) UpdateCharacterSheet(c *gin.Context) {
+	sheet, ok := h.loadSheet(c)
+	if !ok {
+		return
+	}
+	var req UpdateCharacterSheetRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	edited := make([]string, 0)
+	if req.Role != nil {
+		sheet.Role = *req.Role
+		edited = append(edited, models.SheetFieldRole)
+	}
+	if req.ConsciousWant != nil {
+		sheet.ConsciousWant = *req.ConsciousWant
+		edited = append(edited, models.SheetFieldConsciousWant)
+	}
+	if req.UnconsciousNeed != nil {
+		sheet.UnconsciousNeed = *req.UnconsciousNeed
+		edited = append(edited, models.SheetFieldUnconsciousNeed)
+	}
+	if req.Fear != nil {
+		sheet.Fear = *req.Fear
+		edited = append(edited, models.SheetFieldFear)
+	}
+	if req.MaskingBehavior != nil {
+		sheet.MaskingBehavior = *req.MaskingBehavior
+		edited = append(edited, models.SheetFieldMaskingBehavior)
+	}
+	if req.Secrets != nil {
+		sheet.Secrets = *req.Secrets
+		edited = append(edited, models.SheetFieldSecrets)
+	}
+	if req.InternalConflicts != nil {
+		sheet.InternalConflicts = *req.InternalConflicts
+		edited = append(edited, models.SheetFieldInternalConflicts)
+	}
+	if req.CurrentEmotion != nil {
+		sheet.CurrentEmotion = *req.CurrentEmotion
+		edited = append(edited, models.SheetFieldCurrentEmotion)
+	}
+
+	if err := sheet.Lock(append(edited, req.Lock...)...); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_FIELD", "锁定字段无效", err.Error()))
+		return
+	}
+	sheet.Unlock(req.Unlock...)
+
+	if err := h.db.SaveCharacterSheet(sheet); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存角色卡失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(sheet))
+}
+
+// DeleteCharacterSheet 删除角色卡
+// @Summary 删除角色卡
+// @Description 删除后下一次演化不再保留该角色的锁定设定
+// @Tags characters
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param sheetId path string true "角色卡ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/characters/sheets/{sheetId} [delete]
+func (h *CharacterHandler) DeleteCharacterSheet(c *gin.Context) {
+	sheet, ok := h.loadSheet(c)
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteCharacterSheet(sheet.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "删除角色卡失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"deleted": sheet.ID}))
+}
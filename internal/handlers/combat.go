@@ -0,0 +1,151 @@
+// Package handlers HTTP处理器 - 战斗分解
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/cultivation"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/items"
+	"github.com/xlei/xupu/pkg/vitals"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// CombatHandler 战斗分解处理器
+type CombatHandler struct {
+	db db.Database
+}
+
+// NewCombatHandler 创建战斗分解处理器
+func NewCombatHandler(database db.Database) *CombatHandler {
+	return &CombatHandler{db: database}
+}
+
+// PlanCombat 为场景生成战斗分解
+// @Summary 生成战斗分解
+// @Description 为蓝图中的场景生成逐回合的战斗分解（站位、招式、代价、伤势），覆盖原有分解；写正文时必须按分解推进
+// @Tags combat
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param chapter path int true "章节号"
+// @Param scene path int true "场景号"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/combat/{chapter}/{scene} [post]
+func (h *CombatHandler) PlanCombat(c *gin.Context) {
+	project, blueprint, index, ok := h.loadScene(c)
+	if !ok {
+		return
+	}
+	instr := blueprint.Scenes[index]
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化写作器失败", err.Error()))
+		return
+	}
+	world, _ := h.db.GetWorld(blueprint.WorldID)
+	choreography, err := w.WithBudget(budget).WithLogger(requestLogger(c)).WithLanguage(project.Language).PlanCombat(writer.CombatParams{
+		Instruction: &instr,
+		World:       world,
+		Realms:      cultivation.StatesAt(h.db, project.ID, world, instr.Chapter),
+		Vitals:      vitals.Load(h.db, project.ID).StatesAt(instr.Chapter),
+		Items:       items.Load(h.db, project.ID, instr.Chapter),
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "生成战斗分解失败", err.Error()))
+		return
+	}
+
+	h.saveChoreography(c, blueprint, index, choreography)
+}
+
+// UpdateCombat 手工修改场景的战斗分解
+// @Summary 修改战斗分解
+// @Tags combat
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param chapter path int true "章节号"
+// @Param scene path int true "场景号"
+// @Param request body models.CombatChoreography true "战斗分解"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/combat/{chapter}/{scene} [put]
+func (h *CombatHandler) UpdateCombat(c *gin.Context) {
+	var req models.CombatChoreography
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	if len(req.Beats) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "战斗分解至少需要一个回合", ""))
+		return
+	}
+
+	_, blueprint, index, ok := h.loadScene(c)
+	if !ok {
+		return
+	}
+	h.saveChoreography(c, blueprint, index, &req)
+}
+
+// DeleteCombat 清除场景的战斗分解
+// @Summary 清除战斗分解
+// @Description 清除后，生成该动作场景时会重新生成分解
+// @Tags combat
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param chapter path int true "章节号"
+// @Param scene path int true "场景号"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/combat/{chapter}/{scene} [delete]
+func (h *CombatHandler) DeleteCombat(c *gin.Context) {
+	_, blueprint, index, ok := h.loadScene(c)
+	if !ok {
+		return
+	}
+	h.saveChoreography(c, blueprint, index, nil)
+}
+
+// saveChoreography 写入场景的战斗分解并保存蓝图
+func (h *CombatHandler) saveChoreography(c *gin.Context, blueprint *models.NarrativeBlueprint, index int, choreography *models.CombatChoreography) {
+	blueprint.Scenes[index].Choreography = choreography
+	blueprint.UpdatedAt = time.Now()
+	if err := h.db.SaveNarrativeBlueprint(blueprint); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存蓝图失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"scene": blueprint.Scenes[index],
+	}))
+}
+
+// loadScene 加载当前用户项目蓝图中的场景，返回场景在蓝图中的下标，失败时已写入响应
+func (h *CombatHandler) loadScene(c *gin.Context) (*models.Project, *models.NarrativeBlueprint, int, bool) {
+	chapter, err1 := strconv.Atoi(c.Param("chapter"))
+	scene, err2 := strconv.Atoi(c.Param("scene"))
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "章节号或场景号无效", ""))
+		return nil, nil, 0, false
+	}
+
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return nil, nil, 0, false
+	}
+	for i := range blueprint.Scenes {
+		if blueprint.Scenes[i].Chapter == chapter && blueprint.Scenes[i].Scene == scene {
+			return project, blueprint, i, true
+		}
+	}
+	c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "场景不存在", ""))
+	return nil, nil, 0, false
+}
@@ -0,0 +1,235 @@
+// Package handlers HTTP处理器 - 竞品分析
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/narrative"
+)
+
+// maxCompetitorUploadBytes 上传的竞品文件大小上限
+const maxCompetitorUploadBytes = 1 << 20
+
+// CompetitorHandler 竞品分析处理器
+type CompetitorHandler struct {
+	db db.Database
+}
+
+// NewCompetitorHandler 创建竞品分析处理器
+func NewCompetitorHandler(database db.Database) *CompetitorHandler {
+	return &CompetitorHandler{db: database}
+}
+
+// AnalyzeCompetitor 分析竞品
+// @Summary 竞品分析
+// @Description 粘贴竞品的简介/章节目录（JSON），或上传txt文件（multipart，字段 file，可带 title），提取竞品的节拍、套路和节奏；项目已有蓝图时生成对比报告和差异化机会
+// @Tags competitors
+// @Accept json,mpfd
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body CompetitorAnalysisRequest false "竞品简介和章节目录"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/competitors [post]
+func (h *CompetitorHandler) AnalyzeCompetitor(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	title, source, ok := competitorSource(c)
+	if !ok {
+		return
+	}
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	engine, err := narrative.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化失败", err.Error()))
+		return
+	}
+	engine = engine.WithBudget(budget).WithLogger(requestLogger(c)).WithLanguage(project.Language)
+
+	structure, err := engine.AnalyzeCompetitor(title, source)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("ANALYZE_FAILED", "竞品分析失败", err.Error()))
+		return
+	}
+
+	analysis := &models.CompetitorAnalysis{
+		ID:        db.GenerateID("competitor"),
+		ProjectID: project.ID,
+		UserID:    project.UserID,
+		Title:     title,
+		Source:    source,
+		Structure: *structure,
+		CreatedAt: time.Now(),
+	}
+	if project.NarrativeID != "" {
+		if blueprint, err := h.db.GetNarrativeBlueprint(project.NarrativeID); err == nil {
+			comparison, err := engine.CompareWithBlueprint(structure, blueprint)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, errorResponse("ANALYZE_FAILED", "竞品对比失败", err.Error()))
+				return
+			}
+			analysis.Comparison = comparison
+		}
+	}
+
+	if err := h.db.SaveCompetitorAnalysis(analysis); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存竞品分析失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"analysis": analysis,
+	}))
+}
+
+// ListCompetitors 列出项目的竞品分析
+// @Summary 竞品分析列表
+// @Tags competitors
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/competitors [get]
+func (h *CompetitorHandler) ListCompetitors(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	analyses, err := h.db.ListCompetitorAnalyses(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取竞品分析失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"analyses": analyses,
+		"total":    len(analyses),
+	}))
+}
+
+// GetCompetitor 获取竞品分析详情
+// @Summary 获取竞品分析
+// @Tags competitors
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param analysisId path string true "竞品分析ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/competitors/{analysisId} [get]
+func (h *CompetitorHandler) GetCompetitor(c *gin.Context) {
+	analysis, ok := h.loadAnalysis(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"analysis": analysis,
+	}))
+}
+
+// DeleteCompetitor 删除竞品分析
+// @Summary 删除竞品分析
+// @Tags competitors
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param analysisId path string true "竞品分析ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/competitors/{analysisId} [delete]
+func (h *CompetitorHandler) DeleteCompetitor(c *gin.Context) {
+	analysis, ok := h.loadAnalysis(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteCompetitorAnalysis(analysis.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "删除竞品分析失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"message": "竞品分析已删除",
+	}))
+}
+
+// loadAnalysis 加载属于路径中项目的竞品分析
+func (h *CompetitorHandler) loadAnalysis(c *gin.Context) (*models.CompetitorAnalysis, bool) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return nil, false
+	}
+	analysis, err := h.db.GetCompetitorAnalysis(c.Param("analysisId"))
+	if err != nil || analysis.ProjectID != project.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "竞品分析不存在", ""))
+		return nil, false
+	}
+	return analysis, true
+}
+
+// competitorSource 从上传文件或JSON请求中取竞品标题和内容
+func competitorSource(c *gin.Context) (string, string, bool) {
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_FILE", "未找到上传文件", err.Error()))
+			return "", "", false
+		}
+		if file.Size > maxCompetitorUploadBytes {
+			c.JSON(http.StatusBadRequest, errorResponse("FILE_TOO_LARGE", "文件不能超过1MB", ""))
+			return "", "", false
+		}
+		src, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("READ_FAILED", "读取文件失败", err.Error()))
+			return "", "", false
+		}
+		defer src.Close()
+		content, err := io.ReadAll(src)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("READ_FAILED", "读取文件内容失败", err.Error()))
+			return "", "", false
+		}
+		title := c.PostForm("title")
+		if title == "" {
+			title = strings.TrimSuffix(file.Filename, ".txt")
+		}
+		if strings.TrimSpace(string(content)) == "" {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_FILE", "文件内容为空", ""))
+			return "", "", false
+		}
+		return title, string(content), true
+	}
+
+	var req CompetitorAnalysisRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return "", "", false
+	}
+	var source strings.Builder
+	if s := strings.TrimSpace(req.Synopsis); s != "" {
+		source.WriteString("【简介】\n" + s + "\n\n")
+	}
+	if len(req.ChapterList) > 0 {
+		source.WriteString("【章节目录】\n")
+		for _, title := range req.ChapterList {
+			if title = strings.TrimSpace(title); title != "" {
+				source.WriteString(title + "\n")
+			}
+		}
+	}
+	if source.Len() == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "简介和章节目录至少提供一项", ""))
+		return "", "", false
+	}
+	return req.Title, source.String(), true
+}
@@ -0,0 +1,276 @@
+// Package handlers HTTP处理器 - 内容合规
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/compliance"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// ComplianceHandler 内容合规处理器
+type ComplianceHandler struct {
+	db          db.Database
+	chapterRepo *repositories.ChapterRepository
+	cfg         *config.Config
+}
+
+// NewComplianceHandler 创建内容合规处理器
+func NewComplianceHandler(database db.Database) *ComplianceHandler {
+	cfg, err := config.Current()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+	return &ComplianceHandler{db: database, chapterRepo: repositories.NewChapterRepository(), cfg: cfg}
+}
+
+// ListPolicies 列出合规策略集
+// @Summary 合规策略集列表
+// @Description 返回配置中的合规策略集（已合并继承的规则）和默认策略集
+// @Tags compliance
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/compliance/policies [get]
+func (h *ComplianceHandler) ListPolicies(c *gin.Context) {
+	ids := make([]string, 0, len(h.cfg.Compliance.Policies))
+	for id := range h.cfg.Compliance.Policies {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	policies := make([]gin.H, 0, len(ids))
+	for _, id := range ids {
+		policy, _ := h.cfg.Compliance.Policy(id)
+		policies = append(policies, gin.H{
+			"id":     id,
+			"policy": policy,
+		})
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"policies":       policies,
+		"default_policy": h.cfg.Compliance.DefaultPolicy,
+	}))
+}
+
+// UpdateComplianceSettings 更新项目的内容合规配置
+// @Summary 更新内容合规配置
+// @Description 选择项目使用的策略集；auto_sanitize 开启后，标记更新为已定时或已发布前会自动改写必须处理的违规段落
+// @Tags compliance
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body UpdateComplianceSettingsRequest true "内容合规配置"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/compliance-settings [put]
+func (h *ComplianceHandler) UpdateComplianceSettings(c *gin.Context) {
+	var req UpdateComplianceSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	if _, err := compliance.Load(h.cfg.Compliance, req.Policy); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_POLICY", "合规策略集无效", err.Error()))
+		return
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	project.ComplianceSettings = models.ComplianceSettings{
+		Policy:       req.Policy,
+		AutoSanitize: req.AutoSanitize,
+	}
+	project.UpdatedAt = time.Now()
+	if err := h.db.SaveProject(project); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存项目失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"compliance_settings": project.ComplianceSettings,
+	}))
+}
+
+// ScanCompliance 检查章节正文的合规性
+// @Summary 内容合规检查
+// @Description 按项目的策略集检查章节正文，按章节和段落报告违规
+// @Tags compliance
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body ComplianceChaptersRequest false "检查的章节"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/compliance/scan [post]
+func (h *ComplianceHandler) ScanCompliance(c *gin.Context) {
+	project, scanner, chapters, blueprint, ok := h.prepare(c)
+	if !ok {
+		return
+	}
+
+	report := &models.ComplianceReport{
+		Policy:     scanner.Policy,
+		Violations: []models.ComplianceViolation{},
+		ScannedAt:  time.Now(),
+	}
+	for _, ch := range chapters {
+		prose := chapterProse(h.db, blueprint, ch)
+		if strings.TrimSpace(prose) == "" {
+			continue
+		}
+		report.Chapters++
+		report.Add(scanner.Scan(ch.ChapterNum, prose)...)
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"project_id": project.ID,
+		"report":     report,
+	}))
+}
+
+// SanitizeCompliance 改写有违规的段落
+// @Summary 合规改写
+// @Description 对有违规的章节请求改写违规段落并保存，返回改写后仍存在的违规
+// @Tags compliance
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body ComplianceChaptersRequest false "改写的章节"
+// @Success 200 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/compliance/sanitize [post]
+func (h *ComplianceHandler) SanitizeCompliance(c *gin.Context) {
+	project, scanner, chapters, blueprint, ok := h.prepare(c)
+	if !ok {
+		return
+	}
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化写作器失败", err.Error()))
+		return
+	}
+	w = w.WithBudget(budget).WithLanguage(project.Language)
+
+	report := &models.ComplianceReport{
+		Policy:     scanner.Policy,
+		Violations: []models.ComplianceViolation{},
+		ScannedAt:  time.Now(),
+	}
+	sanitized := make([]int, 0)
+	for _, ch := range chapters {
+		changed, remaining, err := sanitizeChapter(c, h.db, h.chapterRepo, w, scanner, blueprint, ch)
+		if err == repositories.ErrChapterVersionConflict {
+			c.JSON(http.StatusConflict, errorResponse("VERSION_CONFLICT", "章节已被修改", fmt.Sprintf("第%d章在改写期间被修改，请刷新后重试", ch.ChapterNum)))
+			return
+		}
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("SANITIZE_FAILED", "合规改写失败", err.Error()))
+			return
+		}
+		report.Chapters++
+		report.Add(remaining...)
+		if changed {
+			sanitized = append(sanitized, ch.ChapterNum)
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"sanitized_chapters": sanitized,
+		"report":             report,
+	}))
+}
+
+// prepare 加载项目、策略集和请求中的章节
+func (h *ComplianceHandler) prepare(c *gin.Context) (*models.Project, *compliance.Scanner, []*models.Chapter, *models.NarrativeBlueprint, bool) {
+	var req ComplianceChaptersRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return nil, nil, nil, nil, false
+		}
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return nil, nil, nil, nil, false
+	}
+	scanner, err := compliance.Load(h.cfg.Compliance, project.ComplianceSettings.Policy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_POLICY", "合规策略集无效", err.Error()))
+		return nil, nil, nil, nil, false
+	}
+	blueprint := &models.NarrativeBlueprint{}
+	if project.NarrativeID != "" {
+		if b, err := h.db.GetNarrativeBlueprint(project.NarrativeID); err == nil {
+			blueprint = b
+		}
+	}
+
+	wanted := make(map[int]bool, len(req.Chapters))
+	for _, n := range req.Chapters {
+		wanted[n] = true
+	}
+	chapters := make([]*models.Chapter, 0)
+	for _, ch := range h.db.ListChaptersByProject(project.ID) {
+		if len(wanted) == 0 || wanted[ch.ChapterNum] {
+			chapters = append(chapters, ch)
+		}
+	}
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].ChapterNum < chapters[j].ChapterNum })
+	return project, scanner, chapters, blueprint, true
+}
+
+// sanitizeChapter 改写章节中有违规的段落并保存正文，返回是否有改动和改写后仍存在的违规
+// 改写期间章节被修改时返回 repositories.ErrChapterVersionConflict
+func sanitizeChapter(c *gin.Context, database db.Database, chapterRepo *repositories.ChapterRepository, w *writer.Writer, scanner *compliance.Scanner, blueprint *models.NarrativeBlueprint, ch *models.Chapter) (bool, []models.ComplianceViolation, error) {
+	version := ch.Version
+	prose := chapterProse(database, blueprint, ch)
+	violations := scanner.Scan(ch.ChapterNum, prose)
+	if len(violations) == 0 {
+		return false, violations, nil
+	}
+
+	rewrites, err := w.SanitizeParagraphs(writer.SanitizeParams{
+		Chapter:    ch.ChapterNum,
+		Paragraphs: compliance.Paragraphs(prose),
+		Violations: violations,
+	})
+	if err != nil {
+		return false, nil, err
+	}
+	if len(rewrites) == 0 {
+		return false, violations, nil
+	}
+
+	before := *ch
+	ch.Content = compliance.ReplaceParagraphs(prose, rewrites)
+	ch.WordCount = utf8.RuneCountInString(ch.Content)
+	if err := chapterRepo.UpdateWithVersion(c, ch, version); err != nil {
+		return false, nil, err
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditRegenerate,
+		ResourceType: models.AuditResourceChapter,
+		ResourceID:   ch.ID,
+		ProjectID:    ch.ProjectID,
+		Summary:      fmt.Sprintf("合规改写第%d章 %d 段", ch.ChapterNum, len(rewrites)),
+	}, &before, ch)
+	return true, scanner.Scan(ch.ChapterNum, ch.Content), nil
+}
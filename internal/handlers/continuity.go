@@ -0,0 +1,238 @@
+// Package handlers HTTP处理器 - 细节连续性
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/continuity"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/webhook"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// ContinuityHandler 细节连续性处理器
+type ContinuityHandler struct {
+	db          db.Database
+	chapterRepo *repositories.ChapterRepository
+}
+
+// NewContinuityHandler 创建细节连续性处理器
+func NewContinuityHandler(database db.Database) *ContinuityHandler {
+	return &ContinuityHandler{db: database, chapterRepo: repositories.NewChapterRepository()}
+}
+
+// ListContinuityFacts 获取连续性事实
+// @Summary 获取连续性事实
+// @Description 列出正文确立和手工登记的外貌、伤势、随身物品等细节；指定 chapter 时只返回写该章时仍有效的事实
+// @Tags continuity
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param subject query string false "只返回指定角色或物品的事实"
+// @Param chapter query int false "章节号"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/continuity [get]
+func (h *ContinuityHandler) ListContinuityFacts(c *gin.Context) {
+	chapter := 0
+	if v := c.Query("chapter"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "chapter 应为正整数", v))
+			return
+		}
+		chapter = n
+	}
+	subject := strings.TrimSpace(c.Query("subject"))
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	facts, err := h.db.ListContinuityFacts(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取连续性事实失败", err.Error()))
+		return
+	}
+	filtered := make([]models.ContinuityFact, 0, len(facts))
+	for _, f := range facts {
+		if subject != "" && f.Subject != subject {
+			continue
+		}
+		if chapter > 0 && !f.ActiveAt(chapter) {
+			continue
+		}
+		filtered = append(filtered, f)
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"facts": filtered,
+		"total": len(filtered),
+	}))
+}
+
+// CreateContinuityFact 手工登记连续性事实
+// @Summary 登记连续性事实
+// @Description 手工登记一条细节（如主角的瞳色），写作时注入提示词，检查时作为对照；重新检查章节时保留
+// @Tags continuity
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body CreateContinuityFactRequest true "事实"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/continuity [post]
+func (h *ContinuityHandler) CreateContinuityFact(c *gin.Context) {
+	var req CreateContinuityFactRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	category := req.Category
+	if category == "" {
+		category = models.FactState
+	}
+	fact := &models.ContinuityFact{
+		ID:        db.GenerateID("fact"),
+		ProjectID: project.ID,
+		Subject:   strings.TrimSpace(req.Subject),
+		Attribute: strings.TrimSpace(req.Attribute),
+		Value:     strings.TrimSpace(req.Value),
+		Category:  category,
+		Permanent: req.Permanent,
+		Chapter:   req.Chapter,
+		Source:    models.FactFromManual,
+	}
+	if err := h.db.SaveContinuityFact(fact); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存连续性事实失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"fact": fact,
+	}))
+}
+
+// DeleteContinuityFact 删除连续性事实
+// @Summary 删除连续性事实
+// @Description 删除一条提取有误或不再需要的事实
+// @Tags continuity
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param factId path string true "事实ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/continuity/{factId} [delete]
+func (h *ContinuityHandler) DeleteContinuityFact(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	fact, err := h.db.GetContinuityFact(c.Param("factId"))
+	if err != nil || fact.ProjectID != project.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "事实不存在", ""))
+		return
+	}
+	if err := h.db.DeleteContinuityFact(fact.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "删除连续性事实失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"deleted": fact.ID,
+	}))
+}
+
+// CheckContinuity 检查章节细节连续性
+// @Summary 检查细节连续性
+// @Description 按章节顺序重新提取细节并检查与此前确立的细节是否矛盾，矛盾写入章节记录；手动修改正文后使用
+// @Tags continuity
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body CheckContinuityRequest false "章节范围"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/continuity/check [post]
+func (h *ContinuityHandler) CheckContinuity(c *gin.Context) {
+	var req CheckContinuityRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化写作器失败", err.Error()))
+		return
+	}
+	w = w.WithBudget(budget).WithLogger(requestLogger(c)).WithLanguage(project.Language)
+
+	wanted := make(map[int]bool, len(req.Chapters))
+	for _, n := range req.Chapters {
+		wanted[n] = true
+	}
+
+	blueprint := projectBlueprint(h.db, project)
+	results := make([]gin.H, 0)
+	issues := 0
+	for _, ch := range sortedChapters(h.db, project.ID) {
+		if len(wanted) > 0 && !wanted[ch.ChapterNum] {
+			continue
+		}
+		prose := chapterProse(h.db, blueprint, ch)
+		if strings.TrimSpace(prose) == "" {
+			continue
+		}
+
+		outcome, err := continuity.CheckChapter(h.db, w, ch, prose)
+		if err != nil {
+			results = append(results, gin.H{"chapter_num": ch.ChapterNum, "error": err.Error()})
+			continue
+		}
+		ch.ContinuityIssues = outcome.Issues
+		if err := h.chapterRepo.UpdateColumns(c, ch, "continuity_issues"); err != nil {
+			results = append(results, gin.H{"chapter_num": ch.ChapterNum, "error": err.Error()})
+			continue
+		}
+		if len(outcome.Issues) > 0 {
+			webhook.Emit(h.db, project.ID, models.WebhookEventConsistencyFailed, gin.H{
+				"chapter":    ch.ChapterNum,
+				"chapter_id": ch.ID,
+				"issues":     outcome.Issues,
+			})
+		}
+		issues += len(outcome.Issues)
+		results = append(results, gin.H{
+			"chapter_id":  ch.ID,
+			"chapter_num": ch.ChapterNum,
+			"issues":      outcome.Issues,
+			"added":       len(outcome.Added),
+			"ended":       len(outcome.Ended),
+		})
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"chapters": results,
+		"issues":   issues,
+	}))
+}
@@ -0,0 +1,206 @@
+// Package handlers HTTP处理器 - 修为境界
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/cultivation"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// CultivationHandler 修为境界处理器
+type CultivationHandler struct {
+	db db.Database
+}
+
+// NewCultivationHandler 创建修为境界处理器
+func NewCultivationHandler(database db.Database) *CultivationHandler {
+	return &CultivationHandler{db: database}
+}
+
+// GetCultivation 获取修为境界记录
+// @Summary 获取修为境界
+// @Description 返回世界的境界划分、各角色的境界变化记录、指定章节开始时的境界，以及不合理的突破
+// @Tags cultivation
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param chapter query int false "章节号，默认为最新一章之后"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/cultivation [get]
+func (h *CultivationHandler) GetCultivation(c *gin.Context) {
+	chapter := 0
+	if v := c.Query("chapter"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "chapter 应为正整数", v))
+			return
+		}
+		chapter = n
+	}
+
+	project, system, ok := h.loadSystem(c)
+	if !ok {
+		return
+	}
+
+	if chapter == 0 {
+		for _, ch := range h.db.ListChaptersByProject(project.ID) {
+			if ch.ChapterNum >= chapter {
+				chapter = ch.ChapterNum + 1
+			}
+		}
+	}
+	tracker := cultivation.Load(h.db, project.ID, system.Realms)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"system":     system,
+		"events":     tracker.Events(),
+		"chapter":    chapter,
+		"states":     tracker.StatesAt(chapter),
+		"violations": tracker.Validate(system.Bottleneck),
+	}))
+}
+
+// CreateRealmEvent 手工登记境界变化
+// @Summary 登记境界变化
+// @Description 手工登记角色登场时的境界、突破或跌落；突破时应写明代价或破除瓶颈的经过
+// @Tags cultivation
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body CreateRealmEventRequest true "境界变化"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/cultivation [post]
+func (h *CultivationHandler) CreateRealmEvent(c *gin.Context) {
+	var req CreateRealmEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	project, system, ok := h.loadSystem(c)
+	if !ok {
+		return
+	}
+
+	event := &models.RealmEvent{
+		ID:        db.GenerateID("realm"),
+		ProjectID: project.ID,
+		Character: strings.TrimSpace(req.Character),
+		Kind:      models.RealmEventKind(req.Kind),
+		Chapter:   req.Chapter,
+		Realm:     strings.TrimSpace(req.Realm),
+		Cost:      req.Cost,
+		Source:    models.RealmFromManual,
+	}
+	if err := h.db.SaveRealmEvent(event); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存境界记录失败", err.Error()))
+		return
+	}
+
+	// 只返回与本次登记相关的违规
+	violations := make([]cultivation.Violation, 0)
+	for _, v := range cultivation.Load(h.db, project.ID, system.Realms).Validate(system.Bottleneck) {
+		if v.Character == event.Character && v.Chapter == event.Chapter {
+			violations = append(violations, v)
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"event":      event,
+		"violations": violations,
+	}))
+}
+
+// DeleteRealmEvent 删除境界记录
+// @Summary 删除境界记录
+// @Description 删除一条提取有误的境界记录；从正文提取的记录在该章重新分析时会重新生成
+// @Tags cultivation
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param eventId path string true "记录ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/cultivation/{eventId} [delete]
+func (h *CultivationHandler) DeleteRealmEvent(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	event, err := h.db.GetRealmEvent(c.Param("eventId"))
+	if err != nil || event.ProjectID != project.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "境界记录不存在", ""))
+		return
+	}
+	if err := h.db.DeleteRealmEvent(event.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "删除境界记录失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"deleted": event.ID,
+	}))
+}
+
+// CheckCultivation 校验境界变化并写入世界的一致性报告
+// @Summary 校验修为境界
+// @Description 检查突破是否越级、过于频繁、缺少代价，用最新结果替换世界一致性报告中的境界问题
+// @Tags cultivation
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/cultivation/check [post]
+func (h *CultivationHandler) CheckCultivation(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	world, err := h.db.GetWorld(project.WorldID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界不存在", ""))
+		return
+	}
+	system := world.Cultivation()
+	if system == nil {
+		c.JSON(http.StatusBadRequest, errorResponse("NO_CULTIVATION", "世界没有设定修真体系", ""))
+		return
+	}
+
+	violations := cultivation.Load(h.db, project.ID, system.Realms).Validate(system.Bottleneck)
+	world.ConsistencyReport = cultivation.MergeIntoReport(world.ConsistencyReport, cultivation.Issues(violations))
+	world.UpdatedAt = time.Now()
+	if err := h.db.SaveWorld(world); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存一致性报告失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"violations":         violations,
+		"consistency_report": world.ConsistencyReport,
+	}))
+}
+
+// loadSystem 加载当前用户的项目及其世界的修真体系，失败时已写入响应
+func (h *CultivationHandler) loadSystem(c *gin.Context) (*models.Project, *models.CultivationSystem, bool) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return nil, nil, false
+	}
+	world, err := h.db.GetWorld(project.WorldID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界不存在", ""))
+		return nil, nil, false
+	}
+	system := world.Cultivation()
+	if system == nil {
+		c.JSON(http.StatusBadRequest, errorResponse("NO_CULTIVATION", "世界没有设定修真体系", ""))
+		return nil, nil, false
+	}
+	return project, system, true
+}
@@ -1,7 +1,13 @@
 // Package handlers HTTP处理器和DTO
 package handlers
 
-import "github.com/xlei/xupu/internal/models"
+import (
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/locale"
+	"github.com/xlei/xupu/pkg/narrative"
+)
 
 // ============================================
 // 请求 DTO
@@ -13,6 +19,150 @@ type CreateProjectRequest struct {
 	Description string          `json:"description"`
 	Mode        string          `json:"mode" binding:"required,oneof=planning intervention random story_core short script assisted workflow"`
 	Params      *CreationParams `json:"params"` // 可选：AI创作参数
+
+	WordCountTargets *models.WordCountTargets `json:"word_count_targets"`                                   // 可选：章节字数目标
+	StyleProfileID   string                   `json:"style_profile_id"`                                     // 可选：写作风格档案
+	Language         string                   `json:"language" binding:"omitempty,oneof=zh-CN zh-TW en ja"` // 可选：输出语言，默认简体中文
+	Webhooks         []WebhookRequest         `json:"webhooks" binding:"omitempty,max=10,dive"`             // 可选：随项目创建的Webhook，创作流程中的事件也会投递
+}
+
+// WebhookRequest 创建/更新项目Webhook请求
+type WebhookRequest struct {
+	URL     string   `json:"url" binding:"required,url"`
+	Events  []string `json:"events"`  // 订阅的事件，为空表示全部
+	Secret  string   `json:"secret"`  // 签名密钥，创建时为空则自动生成；更新时为空保持不变
+	Enabled *bool    `json:"enabled"` // 默认启用
+}
+
+// CreateAPIKeyRequest 创建API密钥请求
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes"`                          // read、write，默认两者都有
+	RateLimit     int      `json:"rate_limit" binding:"min=0"`      // 每分钟请求数上限，0 使用默认值
+	ExpiresInDays int      `json:"expires_in_days" binding:"min=0"` // 有效天数，0 表示不过期
+}
+
+// NotificationChannelRequest 创建/更新通知渠道请求
+type NotificationChannelRequest struct {
+	Type     string            `json:"type" binding:"required,oneof=email feishu dingtalk telegram"`
+	Name     string            `json:"name"`
+	Settings map[string]string `json:"settings"` // email: to；feishu/dingtalk: webhook_url、secret；telegram: bot_token、chat_id
+	Events   []string          `json:"events"`   // 订阅的事件，见 /notifications/events
+	Enabled  *bool             `json:"enabled"`  // 默认启用
+}
+
+// CreateBatchRequest 批量创建项目请求
+type CreateBatchRequest struct {
+	Items []BatchItemRequest `json:"items" binding:"required,min=1,max=100,dive"`
+}
+
+// BatchItemRequest 批量创建中的一项，params 必填
+type BatchItemRequest struct {
+	CreateProjectRequest
+	Priority int `json:"priority" binding:"min=0,max=10"` // 优先级 1-10，越大越先执行；0 使用默认值 5
+}
+
+// StyleProfileRequest 创建/更新写作风格档案请求
+type StyleProfileRequest struct {
+	Name              string   `json:"name" binding:"required"`
+	Description       string   `json:"description"`
+	NarrativeDistance string   `json:"narrative_distance" binding:"omitempty,oneof=close medium distant"`
+	SentenceRhythm    string   `json:"sentence_rhythm"`
+	VocabularyLevel   string   `json:"vocabulary_level" binding:"omitempty,oneof=simple moderate sophisticated"`
+	Tense             string   `json:"tense" binding:"omitempty,oneof=past present"`
+	POVConvention     string   `json:"pov_convention"`
+	BannedPhrases     []string `json:"banned_phrases"`
+}
+
+// UpdateProjectLanguageRequest 设置项目输出语言请求
+type UpdateProjectLanguageRequest struct {
+	Language string `json:"language" binding:"required,oneof=zh-CN zh-TW en ja"`
+}
+
+// ImportManuscriptRequest 导入书稿续写请求（multipart 表单，书稿文件通过 files 字段上传）
+type ImportManuscriptRequest struct {
+	Name             string `form:"name"` // 项目名称，为空时使用排在最前的文件名
+	Author           string `form:"author"`
+	Description      string `form:"description"`
+	Language         string `form:"language" binding:"omitempty,oneof=zh-CN zh-TW en ja"`
+	ContinueChapters int    `form:"continue_chapters" binding:"omitempty,min=1,max=30"` // 续写规划的章节数，默认10
+}
+
+// UpdateProjectStyleProfileRequest 设置项目写作风格档案请求（ID为空表示取消）
+type UpdateProjectStyleProfileRequest struct {
+	StyleProfileID string `json:"style_profile_id"`
+}
+
+// RegenerateSceneRequest 重新生成单个场景请求
+type RegenerateSceneRequest struct {
+	Guidance     string `json:"guidance"`                                                // 作者修改意见（可选）
+	SkipProse    bool   `json:"skip_prose"`                                              // 只重新生成场景指令，不重写正文
+	MaxRevisions *int   `json:"max_revisions,omitempty" binding:"omitempty,min=0,max=5"` // 正文审稿不通过时最多重写轮数，默认2，0表示不修订
+}
+
+// WriteChapterRequest 按章节细纲成文请求
+type WriteChapterRequest struct {
+	Stages    []string `json:"stages" binding:"omitempty,dive,oneof=draft revise polish"` // 执行的阶段，为空时按 chapter_writer.stages 配置
+	Overwrite bool     `json:"overwrite"`                                                 // 章节已有正文时覆盖，否则拒绝
+}
+
+// CreateEditSuggestionRequest 生成修改建议请求，选段位置按字符计
+type CreateEditSuggestionRequest struct {
+	Start       int    `json:"start" binding:"min=0"`                // 选段起始位置
+	End         int    `json:"end" binding:"required,gtfield=Start"` // 选段结束位置（不含）
+	Instruction string `json:"instruction" binding:"required"`       // 修改指令，如“更紧张一些”“删掉副词”
+}
+
+// ResolveEditSuggestionRequest 处理修改建议请求
+type ResolveEditSuggestionRequest struct {
+	Accept    []int `json:"accept"`     // 采纳的修改序号，未列出的修改视为拒绝
+	AcceptAll bool  `json:"accept_all"` // 采纳全部修改，忽略 accept
+}
+
+// CreateAnnotationRequest 创建批注请求，按区间或段落哈希二选一定位
+type CreateAnnotationRequest struct {
+	Start         int    `json:"start" binding:"min=0"` // 批注区间起始位置
+	End           int    `json:"end"`                   // 批注区间结束位置（不含）
+	ParagraphHash string `json:"paragraph_hash"`        // 按段落批注时的段落哈希，end 为 0 时使用
+	Body          string `json:"body" binding:"required"`
+}
+
+// UpdateAnnotationRequest 修改批注请求
+type UpdateAnnotationRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// CreateAnnotationReplyRequest 回复批注请求
+type CreateAnnotationReplyRequest struct {
+	Body string `json:"body" binding:"required"`
+}
+
+// ResolveAnnotationRequest 解决或重新打开批注请求
+type ResolveAnnotationRequest struct {
+	Resolved *bool `json:"resolved"` // 不传时为 true
+}
+
+// SuggestFromAnnotationRequest 按批注生成修改建议请求
+type SuggestFromAnnotationRequest struct {
+	Instruction string `json:"instruction"` // 附加指令（可选），与批注讨论一起交给模型
+}
+
+// UpdatePOVPolicyRequest 设置视角策略请求
+type UpdatePOVPolicyRequest struct {
+	Mode       models.POVMode `json:"mode" binding:"omitempty,oneof=single alternating omniscient"` // 为空表示取消策略
+	Characters []string       `json:"characters"`                                                   // 视角角色（单一视角一个，轮换视角按顺序）
+	Apply      bool           `json:"apply"`                                                        // 是否按策略改写场景的视角角色
+}
+
+// CheckVoiceRequest 对话语音检查请求
+type CheckVoiceRequest struct {
+	SampleSize int `json:"sample_size" binding:"min=0,max=100"` // 抽样台词数，0使用默认值
+}
+
+// UpdateWordCountTargetsRequest 更新章节字数目标请求
+type UpdateWordCountTargetsRequest struct {
+	Default  int         `json:"default" binding:"min=0"`
+	Chapters map[int]int `json:"chapters"`
 }
 
 // CreationParams 创作参数
@@ -23,6 +173,7 @@ type CreationParams struct {
 	WorldTheme string `json:"world_theme"`
 	WorldScale string `json:"world_scale" binding:"required,oneof=village city nation continent planet universe"`
 	WorldStyle string `json:"world_style"`
+	WorldTier  string `json:"world_tier" binding:"omitempty,oneof=quick standard deep"`
 
 	// 故事参数
 	StoryType    string `json:"story_type" binding:"required"`
@@ -31,6 +182,11 @@ type CreationParams struct {
 	Length       string `json:"length" binding:"required,oneof=short medium long"`
 	ChapterCount int    `json:"chapter_count" binding:"min=1,max=100"`
 	Structure    string `json:"structure" binding:"oneof=three_act heros_journey save_the_cat kishotenketsu freytag_pyramid"`
+	BeatSheetID  string `json:"beat_sheet_id"`                                                 // 自定义节拍表（可选），大纲的关键事件映射到其节拍上
+	Genre        string `json:"genre" binding:"omitempty,oneof=xianxia romance mystery scifi"` // 类型演化流水线（可选）
+
+	// 视角策略（可选）
+	POVPolicy *models.POVPolicy `json:"pov_policy"`
 
 	// 生成选项
 	Options GenerationOptions `json:"options"`
@@ -46,6 +202,7 @@ type GenerationOptions struct {
 	StartChapter        int    `json:"start_chapter" binding:"min=1"`
 	EndChapter          int    `json:"end_chapter" binding:"min=1"`
 	Style               string `json:"style"`
+	MaxRevisions        int    `json:"max_revisions" binding:"min=0,max=5"` // 场景自我修订轮数，0表示不修订
 }
 
 // GenerateChapterRequest 生成章节请求
@@ -66,6 +223,244 @@ type CreateWorldRequest struct {
 	Scale string `json:"scale" binding:"required,oneof=village city nation continent planet universe"`
 	Theme string `json:"theme" binding:"required"`
 	Style string `json:"style"`
+	Tier  string `json:"tier" binding:"omitempty,oneof=quick standard deep"` // 构建档位，默认 standard
+}
+
+// DeepenWorldRequest 加深世界分节请求
+type DeepenWorldRequest struct {
+	Section string `json:"section" binding:"required,oneof=geography religions history supernatural"`
+}
+
+// OrganizeReligionsRequest 生成宗教组织架构请求
+type OrganizeReligionsRequest struct {
+	Religion string `json:"religion"` // 宗教名称或ID，为空时处理全部宗教
+}
+
+// GenerateMinorPoolRequest 生成龙套角色池请求
+type GenerateMinorPoolRequest struct {
+	PerRegion int `json:"per_region"` // 每个地区的龙套数量，默认4
+}
+
+// GenerateReleasePlanRequest 生成连载更新计划请求
+type GenerateReleasePlanRequest struct {
+	InstallmentLength int `json:"installment_length" binding:"min=0,max=20000"` // 每次更新的目标字数，默认2000
+}
+
+// UpdateInstallmentRequest 更新发布状态请求
+type UpdateInstallmentRequest struct {
+	Status      string     `json:"status" binding:"required"` // planned/ready/scheduled/published
+	ScheduledAt *time.Time `json:"scheduled_at"`              // 定时发布时间（status=scheduled）
+	PublishedAt *time.Time `json:"published_at"`              // 实际发布时间（status=published，默认当前时间）
+}
+
+// CompetitorAnalysisRequest 竞品分析请求，简介和章节目录至少提供一项
+type CompetitorAnalysisRequest struct {
+	Title       string   `json:"title" binding:"max=200"`
+	Synopsis    string   `json:"synopsis"`     // 竞品简介
+	ChapterList []string `json:"chapter_list"` // 竞品章节目录，每项一个章节标题
+}
+
+// UpdateTropeSettingsRequest 更新套路检测配置请求
+type UpdateTropeSettingsRequest struct {
+	Sensitivity       string   `json:"sensitivity"`         // low/medium/high，默认 medium
+	Allowed           []string `json:"allowed"`             // 有意使用、不报告的套路ID
+	FlagGenreExpected bool     `json:"flag_genre_expected"` // 是否也报告题材预期中的套路
+}
+
+// ScanTropesRequest 套路检测请求
+type ScanTropesRequest struct {
+	Scope    string `json:"scope"`    // outline/chapter/all，默认 all
+	Chapters []int  `json:"chapters"` // 只检测指定章节，为空时检测全部有正文的章节
+	Suggest  bool   `json:"suggest"`  // 是否为报告的套路生成改写建议
+}
+
+// UpdateComplianceSettingsRequest 更新内容合规配置请求
+type UpdateComplianceSettingsRequest struct {
+	Policy       string `json:"policy"`        // 策略集ID，为空时使用默认策略集
+	AutoSanitize bool   `json:"auto_sanitize"` // 发布前自动改写必须处理的违规
+}
+
+// CreateReferenceTextRequest 提交参考文本请求
+type CreateReferenceTextRequest struct {
+	Title   string `json:"title" binding:"required"`
+	Content string `json:"content" binding:"required"`
+}
+
+// UpdateOriginalitySettingsRequest 更新原创性检查设置请求
+type UpdateOriginalitySettingsRequest struct {
+	Threshold float64 `json:"threshold" binding:"min=0,max=1"` // 单章雷同字数占比上限，0 使用默认值
+	Disabled  bool    `json:"disabled"`                        // 关闭导出前的检查
+}
+
+// ComplianceChaptersRequest 合规检查/改写请求
+type ComplianceChaptersRequest struct {
+	Chapters []int `json:"chapters"` // 为空时处理全部有正文的章节
+}
+
+// GlossaryTermRequest 术语表条目
+type GlossaryTermRequest struct {
+	Term    string   `json:"term" binding:"required"`
+	Kind    string   `json:"kind"`
+	Aliases []string `json:"aliases"` // 已知的错误写法
+	Note    string   `json:"note"`
+}
+
+// UpdateGlossaryRequest 更新术语表请求
+type UpdateGlossaryRequest struct {
+	Terms []GlossaryTermRequest `json:"terms" binding:"dive"` // 与设定中提取的术语同名时更新其错误写法和备注，否则作为手工术语；未列出的手工术语会被删除
+}
+
+// GlossaryCheckRequest 术语检查请求
+type GlossaryCheckRequest struct {
+	Chapters []int `json:"chapters"` // 为空时检查全部有正文的章节
+	Apply    bool  `json:"apply"`    // 是否把偏差写法改为规范写法并保存
+}
+
+// UpdateTranslationSettingsRequest 设置自动翻译请求（语言为空表示关闭）
+type UpdateTranslationSettingsRequest struct {
+	Language string `json:"language" binding:"omitempty,oneof=zh-CN zh-TW en ja"`
+}
+
+// TranslateChapterRequest 翻译章节请求
+type TranslateChapterRequest struct {
+	Language string `json:"language" binding:"omitempty,oneof=zh-CN zh-TW en ja"` // 为空时使用项目的译文语言
+}
+
+// CreateBranchRequest 创建假设分支请求
+type CreateBranchRequest struct {
+	Name        string `json:"name" binding:"max=200"`
+	ForkChapter int    `json:"fork_chapter" binding:"required,min=1"`     // 从该章起（含）与主线不同
+	Outcome     string `json:"outcome" binding:"required"`                // 改变后的关键事件结果
+	Chapters    int    `json:"chapters" binding:"omitempty,min=1,max=30"` // 分支规划的章节数，缺省规划到主线结尾
+}
+
+// ProposeEndingsRequest 生成结局候选请求
+type ProposeEndingsRequest struct {
+	Count int `json:"count" binding:"omitempty,min=3,max=5"` // 候选数量，默认3个
+}
+
+// SelectEndingRequest 选定结局请求，多个候选或附带意见时融合为一个新结局
+type SelectEndingRequest struct {
+	CandidateIDs []string `json:"candidate_ids" binding:"required,min=1,max=5"`
+	Guidance     string   `json:"guidance"`      // 融合或调整意见
+	KeepChapters bool     `json:"keep_chapters"` // 只更新第三幕，不重新规划终幕章节
+}
+
+// GenerateTitlesRequest 生成章节标题候选请求
+type GenerateTitlesRequest struct {
+	Count int  `json:"count" binding:"omitempty,min=2,max=10"` // 候选数量，默认5个
+	Apply bool `json:"apply"`                                  // 采用得分最高的候选
+}
+
+// RegenerateTitlesRequest 批量重新生成章节标题请求
+type RegenerateTitlesRequest struct {
+	Chapters []int `json:"chapters"`                               // 章节号，为空表示全部有正文的章节
+	Count    int   `json:"count" binding:"omitempty,min=2,max=10"` // 每章候选数量，默认5个
+	Apply    bool  `json:"apply"`                                  // 各章采用得分最高的候选
+}
+
+// GenerateMarketingRequest 生成营销文案请求
+type GenerateMarketingRequest struct {
+	Fields   []string `json:"fields" binding:"omitempty,dive,oneof=tagline blurb introduction tags"` // 要生成的项，为空表示全部未手动编辑的项
+	Chapters int      `json:"chapters" binding:"omitempty,min=1,max=10"`                             // 参考的开篇章节数，默认3章
+	Guidance string   `json:"guidance"`                                                              // 修改意见
+}
+
+// UpdateMarketingRequest 编辑营销文案请求，只修改提供的项
+type UpdateMarketingRequest struct {
+	Tagline      *string  `json:"tagline" binding:"omitempty,max=150"`
+	Blurb        *string  `json:"blurb" binding:"omitempty,max=300"`
+	Introduction *string  `json:"introduction" binding:"omitempty,max=1000"`
+	Tags         []string `json:"tags" binding:"omitempty,max=10"`
+}
+
+// CreateContinuityFactRequest 手工登记连续性事实请求
+type CreateContinuityFactRequest struct {
+	Subject   string `json:"subject" binding:"required"`
+	Attribute string `json:"attribute" binding:"required"`
+	Value     string `json:"value" binding:"required"`
+	Category  string `json:"category" binding:"omitempty,oneof=appearance injury possession state"`
+	Permanent bool   `json:"permanent"`
+	Chapter   int    `json:"chapter" binding:"omitempty,min=0"` // 确立的章节，从下一章起生效；0 表示开篇前即已确立
+}
+
+// CreateVitalEventRequest 手工登记生死伤势事件请求
+type CreateVitalEventRequest struct {
+	Character string `json:"character" binding:"required"`
+	Event     string `json:"event" binding:"required,oneof=death injury recovery resurrection"`
+	Chapter   int    `json:"chapter" binding:"required,min=1"` // 事件发生的章节
+	Detail    string `json:"detail"`
+}
+
+// ItemRequest 登记或修改关键物品请求
+type ItemRequest struct {
+	Name        string   `json:"name" binding:"required"`
+	Aliases     []string `json:"aliases"`
+	Kind        string   `json:"kind" binding:"omitempty,oneof=weapon artifact document token other"`
+	Description string   `json:"description"`
+	Holder      string   `json:"holder"`                  // 初始持有人
+	Location    string   `json:"location"`                // 不在人手中时的所在地
+	Chapter     int      `json:"chapter" binding:"min=0"` // 首次登场的章节，0 表示开篇前即已存在
+}
+
+// CreateItemTransferRequest 手工登记物品去向变化请求
+type CreateItemTransferRequest struct {
+	Chapter   int    `json:"chapter" binding:"required,min=1"`
+	From      string `json:"from"`
+	To        string `json:"to"`       // 新持有人，空表示不在任何人手中
+	Location  string `json:"location"` // 不在人手中时的所在地
+	Note      string `json:"note"`
+	Destroyed bool   `json:"destroyed"`
+}
+
+// SeedItemsRequest 导入关键物品请求
+type SeedItemsRequest struct {
+	SkipStory bool `json:"skip_story"` // 只导入术语表中的物品，不从蓝图提取
+}
+
+// CreateRealmEventRequest 手工登记境界变化请求
+type CreateRealmEventRequest struct {
+	Character string `json:"character" binding:"required"`
+	Kind      string `json:"kind" binding:"required,oneof=initial breakthrough regression"`
+	Chapter   int    `json:"chapter" binding:"required,min=1"`
+	Realm     string `json:"realm" binding:"required"` // 变化后的境界
+	Cost      string `json:"cost"`                     // 突破的代价或破除瓶颈的经过
+}
+
+// CreateRomanceArcRequest 创建感情线请求
+type CreateRomanceArcRequest struct {
+	CharacterA string               `json:"character_a" binding:"required"`
+	CharacterB string               `json:"character_b" binding:"required"`
+	Dynamic    string               `json:"dynamic"` // 感情模式，如欢喜冤家、破镜重圆
+	Beats      []models.RomanceBeat `json:"beats"`   // 为空时自动规划
+}
+
+// UpdateRomanceArcRequest 修改感情线请求
+type UpdateRomanceArcRequest struct {
+	Dynamic string               `json:"dynamic"`
+	Beats   []models.RomanceBeat `json:"beats" binding:"required"`
+}
+
+// CheckContinuityRequest 连续性检查请求
+type CheckContinuityRequest struct {
+	Chapters []int `json:"chapters"` // 要检查的章节号，为空表示全部有正文的章节
+}
+
+// AnalyzeThemeRequest 主题覆盖分析请求
+type AnalyzeThemeRequest struct {
+	Chapters []int `json:"chapters"` // 要分析的章节号，为空表示全部有正文的章节
+}
+
+// AnalyzeStyleMetricsRequest 文风指标统计请求
+type AnalyzeStyleMetricsRequest struct {
+	Chapters []int `json:"chapters"` // 要统计的章节号，为空表示全部有正文的章节
+}
+
+// BeatSheetRequest 自定义节拍表请求
+type BeatSheetRequest struct {
+	Name        string                 `json:"name" binding:"required,max=100"`
+	Description string                 `json:"description" binding:"max=500"`
+	Beats       []models.StructureBeat `json:"beats" binding:"required,min=2"`
 }
 
 // CreateBlueprintRequest 创建蓝图请求
@@ -78,6 +473,11 @@ type CreateBlueprintRequest struct {
 	Length       string `json:"length" binding:"required,oneof=short medium long"`
 	ChapterCount int    `json:"chapter_count" binding:"min=1,max=100"`
 	Structure    string `json:"structure" binding:"oneof=three_act heros_journey save_the_cat kishotenketsu freytag_pyramid"`
+	BeatSheetID  string `json:"beat_sheet_id"`                                                 // 自定义节拍表（可选），大纲的关键事件映射到其节拍上
+	Genre        string `json:"genre" binding:"omitempty,oneof=xianxia romance mystery scifi"` // 类型演化流水线（可选）
+
+	// 用户预设角色（可选），生成时只补齐剩余角色
+	Characters []*narrative.UserCharacter `json:"characters"`
 }
 
 // ============================================
@@ -111,37 +511,51 @@ type ProjectResponse struct {
 	NarrativeID string  `json:"narrative_id"`
 	CreatedAt   string  `json:"created_at"`
 	UpdatedAt   string  `json:"updated_at"`
+
+	WordCountTargets models.WordCountTargets `json:"word_count_targets"`
+	StyleProfileID   string                  `json:"style_profile_id"`
+	TropeSettings    models.TropeSettings    `json:"trope_settings"`
+
+	ComplianceSettings models.ComplianceSettings `json:"compliance_settings"`
+	Language           string                    `json:"language"`
+
+	OriginalitySettings models.OriginalitySettings `json:"originality_settings"`
+
+	TranslationLanguage string `json:"translation_language"`
 }
 
 // WorldResponse 世界响应
 type WorldResponse struct {
-	ID              string `json:"id"`
-	Name            string `json:"name"`
-	Type            string `json:"type"`
-	Scale           string `json:"scale"`
-	Style           string `json:"style"`
-	CoreQuestion    string `json:"core_question"`
-	HighestGood     string `json:"highest_good"`
-	UltimateEvil    string `json:"ultimate_evil"`
-	SocialConflicts int    `json:"social_conflicts_count"`
-	RegionCount     int    `json:"region_count"`
-	RaceCount       int    `json:"race_count"`
-	CreatedAt       string `json:"created_at"`
+	ID              string                      `json:"id"`
+	Name            string                      `json:"name"`
+	Type            string                      `json:"type"`
+	Scale           string                      `json:"scale"`
+	Style           string                      `json:"style"`
+	BuildTier       string                      `json:"build_tier"`
+	CoreQuestion    string                      `json:"core_question"`
+	HighestGood     string                      `json:"highest_good"`
+	UltimateEvil    string                      `json:"ultimate_evil"`
+	SocialConflicts int                         `json:"social_conflicts_count"`
+	RegionCount     int                         `json:"region_count"`
+	RaceCount       int                         `json:"race_count"`
+	CreatedAt       string                      `json:"created_at"`
+	Warnings        []models.DegradationWarning `json:"warnings,omitempty"` // 降级警告，非空表示部分内容为兜底占位
 }
 
 // BlueprintResponse 蓝图响应
 type BlueprintResponse struct {
-	ID            string               `json:"id"`
-	WorldID       string               `json:"world_id"`
-	StructureType string               `json:"structure_type"`
-	ChapterCount  int                  `json:"chapter_count"`
-	SceneCount    int                  `json:"scene_count"`
-	CoreTheme     string               `json:"core_theme"`
-	CharacterArcs int                  `json:"character_arcs_count"`
-	StoryOutline  models.StoryOutline  `json:"story_outline"`
-	ChapterPlans  []models.ChapterPlan `json:"chapter_plans"`
-	CreatedAt     string               `json:"created_at"`
-	UpdatedAt     string               `json:"updated_at"`
+	ID            string                      `json:"id"`
+	WorldID       string                      `json:"world_id"`
+	StructureType string                      `json:"structure_type"`
+	ChapterCount  int                         `json:"chapter_count"`
+	SceneCount    int                         `json:"scene_count"`
+	CoreTheme     string                      `json:"core_theme"`
+	CharacterArcs int                         `json:"character_arcs_count"`
+	StoryOutline  models.StoryOutline         `json:"story_outline"`
+	ChapterPlans  []models.ChapterPlan        `json:"chapter_plans"`
+	CreatedAt     string                      `json:"created_at"`
+	UpdatedAt     string                      `json:"updated_at"`
+	Warnings      []models.DegradationWarning `json:"warnings,omitempty"` // 降级警告，非空表示部分内容为兜底占位
 }
 
 // CreateChapterRequest 创建章节请求
@@ -155,6 +569,7 @@ type UpdateChapterRequest struct {
 	Title   string `json:"title"`
 	Content string `json:"content"`
 	Status  string `json:"status" binding:"omitempty,oneof=draft completed"`
+	Version int    `json:"version"` // 编辑时读取的版本号，提供时启用冲突检测
 }
 
 // ChapterResponse 章节响应
@@ -167,6 +582,7 @@ type ChapterResponse struct {
 	WordCount   int    `json:"word_count"`
 	AIWordCount int    `json:"ai_generated_word_count"`
 	Status      string `json:"status"`
+	Version     int    `json:"version"`
 	GeneratedAt string `json:"generated_at,omitempty"`
 	CreatedAt   string `json:"created_at"`
 	UpdatedAt   string `json:"updated_at"`
@@ -210,6 +626,17 @@ func toProjectResponse(p *models.Project) ProjectResponse {
 		NarrativeID: p.NarrativeID,
 		CreatedAt:   p.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   p.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+
+		WordCountTargets: p.WordCountTargets,
+		StyleProfileID:   p.StyleProfileID,
+		TropeSettings:    p.TropeSettings,
+
+		ComplianceSettings: p.ComplianceSettings,
+		Language:           locale.Normalize(p.Language),
+
+		OriginalitySettings: p.OriginalitySettings,
+
+		TranslationLanguage: p.TranslationLanguage,
 	}
 }
 
@@ -232,6 +659,7 @@ func toBlueprintResponse(b *models.NarrativeBlueprint) BlueprintResponse {
 		ChapterPlans:  b.ChapterPlans,
 		CreatedAt:     b.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:     b.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Warnings:      b.Warnings,
 	}
 }
 
@@ -266,6 +694,7 @@ func toChapterResponse(c *models.Chapter) ChapterResponse {
 		WordCount:   c.WordCount,
 		AIWordCount: c.AIWordCount,
 		Status:      string(c.Status),
+		Version:     c.Version,
 		CreatedAt:   c.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
 		UpdatedAt:   c.UpdatedAt.Format("2006-01-02T15:04:05Z07:00"),
 	}
@@ -0,0 +1,48 @@
+// Package handlers HTTP处理器 - 角色情绪热力图
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/emotion"
+)
+
+// EmotionHandler 角色情绪处理器
+type EmotionHandler struct {
+	db db.Database
+}
+
+// NewEmotionHandler 创建角色情绪处理器
+func NewEmotionHandler(database db.Database) *EmotionHandler {
+	return &EmotionHandler{db: database}
+}
+
+// GetEmotionHeatmap 获取角色情绪热力图
+// @Summary 获取角色情绪热力图
+// @Description 汇总各章摘要记录的角色章末情绪与强度，返回角色×章节的热力图数据，并标出情绪平淡、弧线不变和大起大落的角色
+// @Tags emotion
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param characters query string false "只包含这些角色，逗号分隔"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/emotions/heatmap [get]
+func (h *EmotionHandler) GetEmotionHeatmap(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	var characters []string
+	if v := c.Query("characters"); v != "" {
+		for _, name := range strings.Split(v, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				characters = append(characters, name)
+			}
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(emotion.Build(sortedChapters(h.db, project.ID), characters)))
+}
@@ -0,0 +1,219 @@
+// Package handlers HTTP处理器 - 结局候选
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/narrative"
+)
+
+// EndingHandler 结局候选处理器
+type EndingHandler struct {
+	db db.Database
+}
+
+// NewEndingHandler 创建结局候选处理器
+func NewEndingHandler(database db.Database) *EndingHandler {
+	return &EndingHandler{db: database}
+}
+
+// ProposeEndings 生成结局候选
+// @Summary 生成结局候选
+// @Description 生成3-5个走向不同的结局（悲剧、苦乐参半、圆满、开放），附对照主题规划的利弊分析；覆盖之前未选定的候选
+// @Tags endings
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body ProposeEndingsRequest false "候选数量"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/endings [post]
+func (h *EndingHandler) ProposeEndings(c *gin.Context) {
+	var req ProposeEndingsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	engine, err := narrative.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化叙事器失败", err.Error()))
+		return
+	}
+	candidates, err := engine.WithBudget(budget).WithLogger(requestLogger(c)).WithLanguage(project.Language).ProposeEndings(blueprint, req.Count)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "生成结局候选失败", err.Error()))
+		return
+	}
+
+	// 已选定的结局保留在候选列表中，便于对照
+	if selected := blueprint.SelectedEnding(); selected != nil {
+		kept := *selected
+		kept.ID = fmt.Sprintf("ending_%d", len(candidates)+1)
+		candidates = append(candidates, kept)
+	}
+	blueprint.EndingCandidates = candidates
+	blueprint.UpdatedAt = time.Now()
+	if err := h.db.SaveNarrativeBlueprint(blueprint); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存蓝图失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"blueprint_id": blueprint.ID,
+		"candidates":   candidates,
+	}))
+}
+
+// SelectEnding 选定或融合结局
+// @Summary 选定结局
+// @Description 选定一个结局候选，或融合多个候选（可附融合意见），写入第三幕并重新规划终幕章节；终幕已有成稿时改为生成待比较的假设分支
+// @Tags endings
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body SelectEndingRequest true "选定的候选"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/endings/select [post]
+func (h *EndingHandler) SelectEnding(c *gin.Context) {
+	var req SelectEndingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	picked := make([]models.EndingCandidate, 0, len(req.CandidateIDs))
+	for _, id := range req.CandidateIDs {
+		found := false
+		for _, candidate := range blueprint.EndingCandidates {
+			if candidate.ID == id {
+				picked = append(picked, candidate)
+				found = true
+				break
+			}
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "结局候选不存在", id))
+			return
+		}
+	}
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	engine, err := narrative.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化叙事器失败", err.Error()))
+		return
+	}
+	engine = engine.WithBudget(budget).WithLogger(requestLogger(c)).WithLanguage(project.Language)
+
+	// 只选一个且没有修改意见时直接采用，否则融合
+	chosen := &picked[0]
+	if len(picked) > 1 || req.Guidance != "" {
+		chosen, err = engine.BlendEndings(blueprint, picked, req.Guidance)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "融合结局失败", err.Error()))
+			return
+		}
+		blueprint.EndingCandidates = append(blueprint.EndingCandidates, *chosen)
+	}
+	for i := range blueprint.EndingCandidates {
+		blueprint.EndingCandidates[i].Selected = blueprint.EndingCandidates[i].ID == chosen.ID
+	}
+	blueprint.StoryOutline.Act3 = models.Act3{
+		Climax:     chosen.Climax,
+		Resolution: chosen.Resolution,
+	}
+	blueprint.UpdatedAt = time.Now()
+	if err := h.db.SaveNarrativeBlueprint(blueprint); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存蓝图失败", err.Error()))
+		return
+	}
+
+	response := gin.H{
+		"blueprint_id": blueprint.ID,
+		"ending":       chosen,
+	}
+	if req.KeepChapters {
+		c.JSON(http.StatusOK, successResponse(response))
+		return
+	}
+
+	// 按新结局重新规划终幕
+	fork := narrative.FinalActChapter(blueprint)
+	if fork == 0 {
+		c.JSON(http.StatusOK, successResponse(response))
+		return
+	}
+	plan, err := engine.PlanWhatIf(blueprint, narrative.WhatIfParams{
+		ForkChapter: fork,
+		Ending:      narrative.EndingOutline(chosen),
+	})
+	if err != nil {
+		response["replan_error"] = err.Error()
+		c.JSON(http.StatusOK, successResponse(response))
+		return
+	}
+	branch := &models.BlueprintBranch{
+		ID:           db.GenerateID("branch"),
+		ProjectID:    project.ID,
+		BlueprintID:  blueprint.ID,
+		Name:         "结局：" + chosen.Summary,
+		ForkChapter:  fork,
+		Outcome:      narrative.EndingOutline(chosen),
+		Status:       models.BranchDraft,
+		ChapterPlans: plan.ChapterPlans,
+		Scenes:       plan.Scenes,
+	}
+
+	// 终幕还没有成稿时直接锁定新的章节规划；已有成稿则存为分支，由作者比较后决定是否采纳
+	written := false
+	for _, ch := range h.db.ListChaptersByProject(project.ID) {
+		if ch.ChapterNum >= fork && ch.WordCount > 0 {
+			written = true
+			break
+		}
+	}
+	if written {
+		if err := h.db.SaveBlueprintBranch(branch); err != nil {
+			response["replan_error"] = err.Error()
+		} else {
+			response["branch"] = branch
+		}
+		c.JSON(http.StatusOK, successResponse(response))
+		return
+	}
+
+	merged := branch.Apply(blueprint)
+	blueprint.ChapterPlans = merged.ChapterPlans
+	blueprint.Scenes = merged.Scenes
+	if err := h.db.SaveNarrativeBlueprint(blueprint); err != nil {
+		response["replan_error"] = err.Error()
+	} else {
+		response["replanned_from"] = fork
+		response["chapter_plans"] = plan.ChapterPlans
+	}
+	c.JSON(http.StatusOK, successResponse(response))
+}
@@ -4,11 +4,17 @@ package handlers
 import (
 	"fmt"
 	"net/http"
+	"os"
+	"strconv"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/export"
+	"github.com/xlei/xupu/pkg/locale"
+	"github.com/xlei/xupu/pkg/tts"
 )
 
 // ExportHandler 导出处理器
@@ -23,7 +29,7 @@ func NewExportHandler() *ExportHandler {
 // @Summary 导出项目
 // @Description 将项目导出为指定格式
 // @Tags export
-// @Produce json, plain, markdown
+// @Produce json,plain,text/markdown
 // @Param id path string true "项目ID"
 // @Param format query string false "导出格式" Enums(json, markdown, txt)
 // @Success 200 {object} APIResponse
@@ -52,7 +58,7 @@ func (h *ExportHandler) ExportProject(c *gin.Context) {
 // @Summary 导出世界设定
 // @Description 将世界设定导出为指定格式
 // @Tags export
-// @Produce json, plain, markdown
+// @Produce json,plain,text/markdown
 // @Param id path string true "世界ID"
 // @Param format query string false "导出格式" Enums(json, markdown, txt)
 // @Success 200 {object} APIResponse
@@ -81,7 +87,7 @@ func (h *ExportHandler) ExportWorld(c *gin.Context) {
 // @Summary 导出叙事蓝图
 // @Description 将叙事蓝图导出为指定格式
 // @Tags export
-// @Produce json, plain, markdown
+// @Produce json,plain,text/markdown
 // @Param id path string true "蓝图ID"
 // @Param format query string false "导出格式" Enums(json, markdown, txt)
 // @Success 200 {object} APIResponse
@@ -106,6 +112,86 @@ func (h *ExportHandler) ExportBlueprint(c *gin.Context) {
 	}
 }
 
+// ExportBible 导出故事圣经
+// @Summary 导出故事圣经
+// @Description 汇编世界设定、角色档案、冲突线索、时间线和伏笔台账，供协作者查阅
+// @Tags export
+// @Produce text/markdown
+// @Param id path string true "项目ID"
+// @Success 200 {string} string
+// @Router /api/v1/export/project/{id}/bible [get]
+func (h *ExportHandler) ExportBible(c *gin.Context) {
+	if _, ok := loadOwnedProjectByID(c, db.Get(), c.Param("id")); !ok {
+		return
+	}
+
+	src, err := export.LoadBibleSource(db.Get(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", err.Error(), ""))
+		return
+	}
+
+	c.Header("Content-Type", "text/markdown; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s-bible.md\"", src.Project.ID))
+	c.String(http.StatusOK, export.RenderBibleMarkdown(src))
+}
+
+// ExportAudiobook 导出有声书
+// @Summary 导出有声书
+// @Description 用配置的语音合成服务朗读章节正文，场景按视角角色分配不同音色，每章一个 MP3，打包为 zip 下载；章节较多时可用 from/to 分批导出
+// @Tags export
+// @Produce application/zip
+// @Param id path string true "项目ID"
+// @Param from query int false "起始章节（含）"
+// @Param to query int false "结束章节（含）"
+// @Success 200 {file} file
+// @Router /api/v1/export/project/{id}/audiobook [get]
+func (h *ExportHandler) ExportAudiobook(c *gin.Context) {
+	from, _ := strconv.Atoi(c.Query("from"))
+	to, _ := strconv.Atoi(c.Query("to"))
+
+	if _, ok := loadOwnedProjectByID(c, db.Get(), c.Param("id")); !ok {
+		return
+	}
+
+	src, err := export.LoadAudiobookSource(db.Get(), c.Param("id"), from, to)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", err.Error(), ""))
+		return
+	}
+	if !checkOriginality(c, db.Get(), src.Project) {
+		return
+	}
+
+	cfg, err := config.Current()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("CONFIG_ERROR", "加载配置失败", err.Error()))
+		return
+	}
+	provider, err := tts.New(cfg.TTS)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化语音合成服务失败", err.Error()))
+		return
+	}
+	cast := tts.CastVoices(cfg.TTS, src.Characters, src.POVs())
+
+	// 音频体积较大，先写入临时文件再发送
+	tmp, err := os.CreateTemp("", "xupu-audiobook-*.zip")
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("EXPORT_FAILED", "创建临时文件失败", err.Error()))
+		return
+	}
+	defer os.Remove(tmp.Name())
+	err = export.WriteAudiobook(tmp, src, provider, cast, tts.SegmentRunes(cfg.TTS))
+	tmp.Close()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("EXPORT_FAILED", "有声书导出失败", err.Error()))
+		return
+	}
+
+	c.FileAttachment(tmp.Name(), fmt.Sprintf("%s-audiobook.zip", src.Project.ID))
+}
+
 // exportProjectMarkdown 导出项目为Markdown
 func (h *ExportHandler) exportProjectMarkdown(c *gin.Context, p *models.Project) {
 	var sb strings.Builder
@@ -280,6 +366,7 @@ func (h *ExportHandler) exportWorldTxt(c *gin.Context, w *models.WorldSetting) {
 // exportBlueprintMarkdown 导出蓝图为Markdown
 func (h *ExportHandler) exportBlueprintMarkdown(c *gin.Context, b *models.NarrativeBlueprint) {
 	var sb strings.Builder
+	terms := locale.For(blueprintLanguage(b))
 
 	sb.WriteString("# 叙事蓝图\n\n")
 
@@ -291,7 +378,7 @@ func (h *ExportHandler) exportBlueprintMarkdown(c *gin.Context, b *models.Narrat
 
 	// 第一幕
 	if b.StoryOutline.Act1.Setup != "" || b.StoryOutline.Act1.IncitingIncident != "" {
-		sb.WriteString("### 第一幕\n\n")
+		sb.WriteString("### " + terms.Act(1) + "\n\n")
 		if b.StoryOutline.Act1.Setup != "" {
 			sb.WriteString("**铺垫**: ")
 			sb.WriteString(b.StoryOutline.Act1.Setup)
@@ -311,7 +398,7 @@ func (h *ExportHandler) exportBlueprintMarkdown(c *gin.Context, b *models.Narrat
 
 	// 第二幕
 	if len(b.StoryOutline.Act2.RisingAction) > 0 || b.StoryOutline.Act2.Midpoint != "" {
-		sb.WriteString("### 第二幕\n\n")
+		sb.WriteString("### " + terms.Act(2) + "\n\n")
 		for _, action := range b.StoryOutline.Act2.RisingAction {
 			sb.WriteString("- ")
 			sb.WriteString(action)
@@ -326,7 +413,7 @@ func (h *ExportHandler) exportBlueprintMarkdown(c *gin.Context, b *models.Narrat
 
 	// 第三幕
 	if b.StoryOutline.Act3.Climax != "" || b.StoryOutline.Act3.Resolution != "" {
-		sb.WriteString("### 第三幕\n\n")
+		sb.WriteString("### " + terms.Act(3) + "\n\n")
 		if b.StoryOutline.Act3.Climax != "" {
 			sb.WriteString("**高潮**: ")
 			sb.WriteString(b.StoryOutline.Act3.Climax)
@@ -350,9 +437,9 @@ func (h *ExportHandler) exportBlueprintMarkdown(c *gin.Context, b *models.Narrat
 	if len(b.ChapterPlans) > 0 {
 		sb.WriteString("## 章节规划\n\n")
 		for _, chapter := range b.ChapterPlans {
-			sb.WriteString("### 第")
-			sb.WriteString(fmt.Sprintf("%d", chapter.Chapter))
-			sb.WriteString("章: ")
+			sb.WriteString("### ")
+			sb.WriteString(terms.Chapter(chapter.Chapter))
+			sb.WriteString(": ")
 			sb.WriteString(chapter.Title)
 			sb.WriteString("\n\n")
 			if chapter.Purpose != "" {
@@ -372,6 +459,17 @@ func (h *ExportHandler) exportBlueprintMarkdown(c *gin.Context, b *models.Narrat
 	c.String(http.StatusOK, sb.String())
 }
 
+// blueprintLanguage 蓝图所属项目的输出语言，未关联项目时为默认语言
+func blueprintLanguage(b *models.NarrativeBlueprint) string {
+	if b.ProjectID == "" {
+		return ""
+	}
+	if project, err := db.Get().GetProject(b.ProjectID); err == nil {
+		return project.Language
+	}
+	return ""
+}
+
 // exportBlueprintTxt 导出蓝图为纯文本
 func (h *ExportHandler) exportBlueprintTxt(c *gin.Context, b *models.NarrativeBlueprint) {
 	var sb strings.Builder
@@ -2,23 +2,51 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
+	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/internal/services/crawler"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/trends"
+	"github.com/xlei/xupu/pkg/worldbuilder"
 
 	"github.com/gin-gonic/gin"
 )
 
+// maxSnapshotCategories 单次快照最多的分类数，每个分类都要请求一次番茄榜单
+const maxSnapshotCategories = 10
+
 type ExternalRankHandler struct {
 	fanqieService *crawler.FanqieService
+	db            db.Database
 }
 
-func NewExternalRankHandler() *ExternalRankHandler {
+func NewExternalRankHandler(database db.Database) *ExternalRankHandler {
 	return &ExternalRankHandler{
 		fanqieService: crawler.NewFanqieService(),
+		db:            database,
+	}
+}
+
+// Snapshotter 定期快照番茄榜单的快照器，categories 为要快照的分类ID
+func (h *ExternalRankHandler) Snapshotter(categories []string) *trends.Snapshotter {
+	return &trends.Snapshotter{
+		DB:         h.db,
+		Source:     trends.SourceFanqie,
+		Categories: categories,
+		Fetch:      h.fanqieService.GetRankList,
 	}
 }
 
-// GetFanqieRank handles GET /api/v1/external/ranks/fanqie
+// GetFanqieRank 获取番茄榜单
+// @Summary 番茄榜单
+// @Tags external
+// @Produce json
+// @Param category_id query string false "分类ID，默认15"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/external/ranks/fanqie [get]
 func (h *ExternalRankHandler) GetFanqieRank(c *gin.Context) {
 	categoryID := c.Query("category_id")
 	if categoryID == "" {
@@ -47,7 +75,13 @@ func (h *ExternalRankHandler) GetFanqieRank(c *gin.Context) {
 	})
 }
 
-// GetFanqieBookDetail handles GET /api/v1/external/fanqie/books/:bookId
+// GetFanqieBookDetail 获取番茄书籍详情
+// @Summary 番茄书籍详情
+// @Tags external
+// @Produce json
+// @Param bookId path string true "书籍ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/external/fanqie/books/{bookId} [get]
 func (h *ExternalRankHandler) GetFanqieBookDetail(c *gin.Context) {
 	bookID := c.Param("bookId")
 	if bookID == "" {
@@ -80,7 +114,13 @@ func (h *ExternalRankHandler) GetFanqieBookDetail(c *gin.Context) {
 	})
 }
 
-// GetFanqieChapterList handles GET /api/v1/external/fanqie/books/:bookId/chapters
+// GetFanqieChapterList 获取番茄书籍章节列表
+// @Summary 番茄章节列表
+// @Tags external
+// @Produce json
+// @Param bookId path string true "书籍ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/external/fanqie/books/{bookId}/chapters [get]
 func (h *ExternalRankHandler) GetFanqieChapterList(c *gin.Context) {
 	bookID := c.Param("bookId")
 	if bookID == "" {
@@ -117,7 +157,13 @@ func (h *ExternalRankHandler) GetFanqieChapterList(c *gin.Context) {
 	})
 }
 
-// GetFanqieChapterContent handles GET /api/v1/external/fanqie/chapters/:chapterId
+// GetFanqieChapterContent 获取番茄章节正文
+// @Summary 番茄章节正文
+// @Tags external
+// @Produce json
+// @Param chapterId path string true "章节ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/external/fanqie/chapters/{chapterId} [get]
 func (h *ExternalRankHandler) GetFanqieChapterContent(c *gin.Context) {
 	chapterID := c.Param("chapterId")
 	if chapterID == "" {
@@ -149,3 +195,124 @@ func (h *ExternalRankHandler) GetFanqieChapterContent(c *gin.Context) {
 		"data":    content,
 	})
 }
+
+// SnapshotFanqieRank 立即快照番茄榜单（仅管理员）
+// @Summary 快照番茄榜单
+// @Description 拉取指定分类的番茄榜单并保存快照，供趋势分析使用。category_id 可逗号分隔多个（去重后最多10个），默认 15
+// @Tags external
+// @Produce json
+// @Security Bearer
+// @Param category_id query string false "分类ID，逗号分隔"
+// @Success 200 {object} APIResponse
+// @Failure 400 {object} APIResponse
+// @Router /api/v1/external/ranks/fanqie/snapshots [post]
+func (h *ExternalRankHandler) SnapshotFanqieRank(c *gin.Context) {
+	categories := snapshotCategories(c.DefaultQuery("category_id", "15"))
+	if len(categories) == 0 || len(categories) > maxSnapshotCategories {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_PARAM", "category_id 须为1到10个分类ID", ""))
+		return
+	}
+
+	snapshots, err := h.Snapshotter(categories).SnapshotOnce()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SNAPSHOT_ERROR", "快照番茄榜单失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(snapshots))
+}
+
+// snapshotCategories 解析逗号分隔的分类ID，去掉空白和重复项
+func snapshotCategories(raw string) []string {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, id := range strings.Split(raw, ",") {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		categories = append(categories, id)
+	}
+	return categories
+}
+
+// GetRankTrends 排行榜趋势
+// @Summary 排行榜趋势
+// @Description 对比最近 days 天与之前 days 天的榜单快照，给出上升的题材标签和世界类型
+// @Tags external
+// @Produce json
+// @Param days query int false "比较窗口天数，默认7"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/external/ranks/trends [get]
+func (h *ExternalRankHandler) GetRankTrends(c *gin.Context) {
+	report, err := h.analyzeTrends(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DATABASE_ERROR", "获取榜单快照失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(report))
+}
+
+// RankRecommendation 趋势推荐，附带可直接用于构建世界的参数
+type RankRecommendation struct {
+	trends.Recommendation
+	BuildParams worldbuilder.BuildParams `json:"build_params"`
+}
+
+// GetRankRecommendations 按趋势推荐世界类型与主题
+// @Summary 趋势题材推荐
+// @Description 根据榜单趋势推荐当下热门的世界类型与主题组合，build_params 可直接作为构建世界的参数
+// @Tags external
+// @Produce json
+// @Param days query int false "比较窗口天数，默认7"
+// @Param n query int false "推荐数量，默认3"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/external/ranks/recommendations [get]
+func (h *ExternalRankHandler) GetRankRecommendations(c *gin.Context) {
+	n, err := strconv.Atoi(c.DefaultQuery("n", "3"))
+	if err != nil || n <= 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_PARAM", "n 必须为正整数", ""))
+		return
+	}
+
+	report, err := h.analyzeTrends(c)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DATABASE_ERROR", "获取榜单快照失败", err.Error()))
+		return
+	}
+
+	recommendations := make([]RankRecommendation, 0, n)
+	for _, r := range report.Recommend(n) {
+		recommendations = append(recommendations, RankRecommendation{
+			Recommendation: r,
+			BuildParams: worldbuilder.BuildParams{
+				Type:  r.Type,
+				Scale: models.ScaleContinent,
+				Style: strings.Join(r.Tags, "、"),
+				Theme: r.Theme,
+			},
+		})
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"window":          report.Window,
+		"recommendations": recommendations,
+	}))
+}
+
+// analyzeTrends 读取两个窗口内的快照并分析趋势
+func (h *ExternalRankHandler) analyzeTrends(c *gin.Context) (*trends.Report, error) {
+	window := trends.DefaultWindow
+	if days, err := strconv.Atoi(c.Query("days")); err == nil && days > 0 {
+		window = time.Duration(days) * 24 * time.Hour
+	}
+
+	now := time.Now()
+	snapshots, err := h.db.ListRankSnapshots(trends.SourceFanqie, now.Add(-2*window))
+	if err != nil {
+		return nil, err
+	}
+	return trends.Analyze(snapshots, now, window), nil
+}
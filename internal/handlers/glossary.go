@@ -0,0 +1,115 @@
+// Package handlers HTTP处理器 - 术语一致性检查
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/glossary"
+)
+
+// GlossaryHandler 术语一致性检查处理器
+type GlossaryHandler struct {
+	db          db.Database
+	chapterRepo *repositories.ChapterRepository
+}
+
+// NewGlossaryHandler 创建术语一致性检查处理器
+func NewGlossaryHandler(database db.Database) *GlossaryHandler {
+	return &GlossaryHandler{db: database, chapterRepo: repositories.NewChapterRepository()}
+}
+
+// CheckGlossary 检查章节正文中的术语写法
+// @Summary 术语一致性检查
+// @Description 按项目世界的术语表检查章节正文，报告已登记的错误写法和只差一个字的近似写法；apply=true 时改为规范写法并保存
+// @Tags glossary
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body GlossaryCheckRequest false "检查范围"
+// @Success 200 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/glossary/check [post]
+func (h *GlossaryHandler) CheckGlossary(c *gin.Context) {
+	var req GlossaryCheckRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+	world, err := h.db.GetWorld(project.WorldID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目世界不存在", ""))
+		return
+	}
+	if len(world.Glossary) == 0 {
+		world.RebuildGlossary()
+	}
+	blueprint := &models.NarrativeBlueprint{}
+	if project.NarrativeID != "" {
+		if b, err := h.db.GetNarrativeBlueprint(project.NarrativeID); err == nil {
+			blueprint = b
+		}
+	}
+
+	wanted := make(map[int]bool, len(req.Chapters))
+	for _, n := range req.Chapters {
+		wanted[n] = true
+	}
+	chapters := h.db.ListChaptersByProject(project.ID)
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].ChapterNum < chapters[j].ChapterNum })
+
+	corrections := make([]models.GlossaryCorrection, 0)
+	corrected := make([]int, 0)
+	checked := 0
+	for _, ch := range chapters {
+		if len(wanted) > 0 && !wanted[ch.ChapterNum] {
+			continue
+		}
+		prose := chapterProse(h.db, blueprint, ch)
+		if strings.TrimSpace(prose) == "" {
+			continue
+		}
+		checked++
+
+		found := glossary.Find(prose, world.Glossary)
+		for i := range found {
+			found[i].Chapter = ch.ChapterNum
+		}
+		corrections = append(corrections, found...)
+		if !req.Apply || len(found) == 0 {
+			continue
+		}
+
+		ch.Content = glossary.Apply(prose, found)
+		ch.WordCount = utf8.RuneCountInString(ch.Content)
+		if err := h.chapterRepo.UpdateWithVersion(c, ch, ch.Version); err != nil {
+			if err == repositories.ErrChapterVersionConflict {
+				c.JSON(http.StatusConflict, errorResponse("VERSION_CONFLICT", "章节已被修改", fmt.Sprintf("第%d章在检查期间被修改，请刷新后重试", ch.ChapterNum)))
+				return
+			}
+			c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存章节失败", err.Error()))
+			return
+		}
+		corrected = append(corrected, ch.ChapterNum)
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"chapters":           checked,
+		"corrections":        corrections,
+		"corrected_chapters": corrected,
+	}))
+}
@@ -0,0 +1,523 @@
+// Package handlers gRPC 服务
+// 项目、世界、蓝图和生成任务的 gRPC 接口，与对应的 REST 处理器共用查询、筛选、校验和响应转换逻辑，
+// 认证与 REST 相同：JWT 或API密钥（authorization / x-api-key 元数据，格式同对应的请求头）
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/services/auth"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/logx"
+	"github.com/xlei/xupu/pkg/orchestrator"
+	"github.com/xlei/xupu/pkg/quota"
+	"github.com/xlei/xupu/pkg/rpc/xupuv1"
+	"github.com/xlei/xupu/pkg/scheduler"
+	"github.com/xlei/xupu/pkg/writer"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// grpcWatchInterval WatchTask 检查任务状态的间隔
+const grpcWatchInterval = time.Second
+
+// grpcUserKey 上下文中的当前用户ID
+type grpcUserKey struct{}
+
+// NewGRPCServer 创建 gRPC 服务器并注册项目、世界、蓝图和任务服务
+func NewGRPCServer(orc *orchestrator.Orchestrator, jwtSecret string) *grpc.Server {
+	a := &grpcAuth{authService: auth.NewAuthService(jwtSecret)}
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(a.unary),
+		grpc.ChainStreamInterceptor(a.stream),
+	)
+	xupuv1.RegisterProjectServiceServer(server, &grpcProjectService{orc: orc})
+	xupuv1.RegisterWorldServiceServer(server, &grpcWorldService{})
+	xupuv1.RegisterBlueprintServiceServer(server, &grpcBlueprintService{})
+	xupuv1.RegisterTaskServiceServer(server, &grpcTaskService{orc: orc})
+	return server
+}
+
+// ============================================
+// 认证
+// ============================================
+
+// grpcAuth 按 AuthMiddleware 的规则认证调用（先API密钥，再 JWT），记录调用日志
+type grpcAuth struct {
+	authService *auth.AuthService
+}
+
+// authenticate 返回带当前用户ID的上下文，method 用于判断API密钥所需的权限范围
+func (a *grpcAuth) authenticate(ctx context.Context, method string) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if key := grpcAPIKey(md); key != "" {
+		_, user, keyErr := validateAPIKey(db.Get(), key, grpcScope(method), time.Now())
+		if keyErr != nil {
+			return nil, status.Error(grpcAPIKeyCode(keyErr.status), keyErr.message)
+		}
+		return context.WithValue(ctx, grpcUserKey{}, user.ID), nil
+	}
+
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "缺少认证token")
+	}
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "无效的认证格式")
+	}
+	user, err := a.authService.ValidateToken(ctx, token)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, "无效的或已过期的令牌")
+	}
+	return context.WithValue(ctx, grpcUserKey{}, user.ID), nil
+}
+
+// grpcAPIKey 调用携带的API密钥：x-api-key 元数据，或 authorization: Bearer xpk_...
+func grpcAPIKey(md metadata.MD) string {
+	if values := md.Get("x-api-key"); len(values) > 0 && values[0] != "" {
+		return values[0]
+	}
+	if values := md.Get("authorization"); len(values) > 0 {
+		if token, ok := strings.CutPrefix(values[0], "Bearer "); ok && auth.IsAPIKey(token) {
+			return token
+		}
+	}
+	return ""
+}
+
+// grpcScope 方法所需的API密钥权限范围：Get/List/Watch 为只读，其余为写
+func grpcScope(method string) string {
+	name := method[strings.LastIndex(method, "/")+1:]
+	for _, prefix := range []string{"Get", "List", "Watch"} {
+		if strings.HasPrefix(name, prefix) {
+			return models.APIKeyScopeRead
+		}
+	}
+	return models.APIKeyScopeWrite
+}
+
+// grpcAPIKeyCode API密钥认证失败的HTTP状态码对应的 gRPC 状态码
+func grpcAPIKeyCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusTooManyRequests:
+		return codes.ResourceExhausted
+	default:
+		return codes.Unauthenticated
+	}
+}
+
+func (a *grpcAuth) unary(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+	start := time.Now()
+	ctx, err := a.authenticate(ctx, info.FullMethod)
+	var resp interface{}
+	if err == nil {
+		resp, err = handler(ctx, req)
+	}
+	logGRPC(info.FullMethod, start, err)
+	return resp, err
+}
+
+func (a *grpcAuth) stream(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	ctx, err := a.authenticate(ss.Context(), info.FullMethod)
+	if err == nil {
+		err = handler(srv, &grpcAuthStream{ServerStream: ss, ctx: ctx})
+	}
+	logGRPC(info.FullMethod, start, err)
+	return err
+}
+
+// grpcAuthStream 带当前用户ID上下文的流
+type grpcAuthStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *grpcAuthStream) Context() context.Context {
+	return s.ctx
+}
+
+// logGRPC 记录一次调用
+func logGRPC(method string, start time.Time, err error) {
+	logx.L().Info("gRPC", "method", method, "code", status.Code(err).String(), "latency", time.Since(start))
+}
+
+// grpcUserID 当前用户ID
+func grpcUserID(ctx context.Context) string {
+	userID, _ := ctx.Value(grpcUserKey{}).(string)
+	return userID
+}
+
+// ============================================
+// 项目
+// ============================================
+
+type grpcProjectService struct {
+	xupuv1.UnimplementedProjectServiceServer
+	orc *orchestrator.Orchestrator
+}
+
+func (s *grpcProjectService) ListProjects(ctx context.Context, req *xupuv1.ListProjectsRequest) (*xupuv1.ListProjectsResponse, error) {
+	statusFilter, sortBy := req.GetStatus(), req.GetSortBy()
+	if statusFilter == "" {
+		statusFilter = "all"
+	}
+	projects := filterProjects(db.Get().ListProjectsByUser(grpcUserID(ctx)), statusFilter, sortBy, req.GetSearch())
+
+	resp := &xupuv1.ListProjectsResponse{Total: int32(len(projects))}
+	for _, p := range projects {
+		resp.Projects = append(resp.Projects, toProjectProto(toProjectResponse(p)))
+	}
+	return resp, nil
+}
+
+func (s *grpcProjectService) GetProject(ctx context.Context, req *xupuv1.GetProjectRequest) (*xupuv1.GetProjectResponse, error) {
+	project, err := db.Get().GetProject(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "项目不存在")
+	}
+	if project.UserID != grpcUserID(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "无权访问")
+	}
+
+	resp := &xupuv1.GetProjectResponse{Project: toProjectProto(toProjectResponse(project))}
+	if progress, err := s.orc.GetProjectProgress(project.ID); err == nil {
+		resp.Progress = &xupuv1.ProjectProgress{
+			CurrentStage:       progress.CurrentStage,
+			WorldCompleted:     progress.WorldCompleted,
+			NarrativeCompleted: progress.NarrativeCompleted,
+			TotalChapters:      int32(progress.TotalChapters),
+			TotalScenes:        int32(progress.TotalScenes),
+			GeneratedScenes:    int32(progress.GeneratedScenes),
+			WordCount:          int32(progress.WordCount),
+			CompletionPercent:  progress.CompletionPercent,
+		}
+	}
+	return resp, nil
+}
+
+func (s *grpcProjectService) CreateProject(ctx context.Context, req *xupuv1.CreateProjectRequest) (*xupuv1.CreateProjectResponse, error) {
+	dto := &CreateProjectRequest{
+		Name:           req.GetName(),
+		Description:    req.GetDescription(),
+		Mode:           req.GetMode(),
+		StyleProfileID: req.GetStyleProfileId(),
+		Language:       req.GetLanguage(),
+	}
+	if err := validateGRPCCreateRequest(dto); err != nil {
+		return nil, err
+	}
+
+	project := newDraftProject(dto, grpcUserID(ctx))
+	if err := db.Get().SaveProject(project); err != nil {
+		return nil, status.Errorf(codes.Internal, "创建项目失败: %v", err)
+	}
+	return &xupuv1.CreateProjectResponse{Project: toProjectProto(toProjectResponse(project))}, nil
+}
+
+// validateGRPCCreateRequest 按 REST 请求的绑定规则校验，并检查风格档案
+func validateGRPCCreateRequest(req *CreateProjectRequest) error {
+	if err := binding.Validator.ValidateStruct(req); err != nil {
+		return status.Errorf(codes.InvalidArgument, "请求参数错误: %v", err)
+	}
+	if _, err := writer.ResolveStyleProfile(db.Get(), req.StyleProfileID); err != nil {
+		return status.Errorf(codes.InvalidArgument, "风格档案不存在: %v", err)
+	}
+	return nil
+}
+
+// toProjectProto 由 REST 的项目响应转换
+func toProjectProto(p ProjectResponse) *xupuv1.Project {
+	return &xupuv1.Project{
+		Id:             p.ID,
+		Name:           p.Name,
+		Description:    p.Description,
+		Mode:           p.Mode,
+		Status:         p.Status,
+		Progress:       p.Progress,
+		WorldId:        p.WorldID,
+		NarrativeId:    p.NarrativeID,
+		CreatedAt:      p.CreatedAt,
+		UpdatedAt:      p.UpdatedAt,
+		StyleProfileId: p.StyleProfileID,
+		Language:       p.Language,
+	}
+}
+
+// ============================================
+// 世界
+// ============================================
+
+type grpcWorldService struct {
+	xupuv1.UnimplementedWorldServiceServer
+}
+
+func (s *grpcWorldService) ListWorlds(ctx context.Context, req *xupuv1.ListWorldsRequest) (*xupuv1.ListWorldsResponse, error) {
+	resp := &xupuv1.ListWorldsResponse{}
+	for _, w := range db.Get().ListWorlds() {
+		resp.Worlds = append(resp.Worlds, toWorldProto(toWorldResponse(w)))
+	}
+	return resp, nil
+}
+
+func (s *grpcWorldService) GetWorld(ctx context.Context, req *xupuv1.GetWorldRequest) (*xupuv1.GetWorldResponse, error) {
+	world, err := db.Get().GetWorld(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "世界不存在")
+	}
+
+	w := toWorldProto(toWorldResponse(world))
+	if req.GetIncludeSetting() {
+		if w.SettingJson, err = json.Marshal(world); err != nil {
+			return nil, status.Errorf(codes.Internal, "序列化世界设定失败: %v", err)
+		}
+	}
+	return &xupuv1.GetWorldResponse{World: w}, nil
+}
+
+// toWorldProto 由 REST 的世界响应转换
+func toWorldProto(w WorldResponse) *xupuv1.World {
+	return &xupuv1.World{
+		Id:                   w.ID,
+		Name:                 w.Name,
+		Type:                 w.Type,
+		Scale:                w.Scale,
+		Style:                w.Style,
+		BuildTier:            w.BuildTier,
+		CoreQuestion:         w.CoreQuestion,
+		HighestGood:          w.HighestGood,
+		UltimateEvil:         w.UltimateEvil,
+		SocialConflictsCount: int32(w.SocialConflicts),
+		RegionCount:          int32(w.RegionCount),
+		RaceCount:            int32(w.RaceCount),
+		CreatedAt:            w.CreatedAt,
+	}
+}
+
+// ============================================
+// 蓝图
+// ============================================
+
+type grpcBlueprintService struct {
+	xupuv1.UnimplementedBlueprintServiceServer
+}
+
+func (s *grpcBlueprintService) GetBlueprint(ctx context.Context, req *xupuv1.GetBlueprintRequest) (*xupuv1.GetBlueprintResponse, error) {
+	blueprint, err := db.Get().GetNarrativeBlueprint(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "蓝图不存在")
+	}
+
+	b := toBlueprintResponse(blueprint)
+	outline, err := json.Marshal(b.StoryOutline)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "序列化故事大纲失败: %v", err)
+	}
+	resp := &xupuv1.Blueprint{
+		Id:                 b.ID,
+		WorldId:            b.WorldID,
+		StructureType:      b.StructureType,
+		ChapterCount:       int32(b.ChapterCount),
+		SceneCount:         int32(b.SceneCount),
+		CoreTheme:          b.CoreTheme,
+		CharacterArcsCount: int32(b.CharacterArcs),
+		CreatedAt:          b.CreatedAt,
+		UpdatedAt:          b.UpdatedAt,
+		StoryOutlineJson:   outline,
+	}
+	for _, p := range b.ChapterPlans {
+		resp.ChapterPlans = append(resp.ChapterPlans, &xupuv1.ChapterPlan{
+			Chapter:         int32(p.Chapter),
+			Title:           p.Title,
+			Purpose:         p.Purpose,
+			KeyScenes:       p.KeyScenes,
+			PlotAdvancement: p.PlotAdvancement,
+			ArcProgress:     p.ArcProgress,
+			EndingHook:      p.EndingHook,
+			WordCount:       int32(p.WordCount),
+			Status:          p.Status,
+		})
+	}
+	return &xupuv1.GetBlueprintResponse{Blueprint: resp}, nil
+}
+
+// ============================================
+// 生成任务
+// ============================================
+
+type grpcTaskService struct {
+	xupuv1.UnimplementedTaskServiceServer
+	orc *orchestrator.Orchestrator
+}
+
+func (s *grpcTaskService) CreateProjectTask(ctx context.Context, req *xupuv1.CreateProjectTaskRequest) (*xupuv1.CreateProjectTaskResponse, error) {
+	opts := req.GetOptions()
+	dto := &CreateProjectRequest{
+		Name:           req.GetName(),
+		Description:    req.GetDescription(),
+		Mode:           req.GetMode(),
+		StyleProfileID: req.GetStyleProfileId(),
+		Language:       req.GetLanguage(),
+		Params: &CreationParams{
+			WorldName:    req.GetWorldName(),
+			WorldType:    req.GetWorldType(),
+			WorldTheme:   req.GetWorldTheme(),
+			WorldScale:   req.GetWorldScale(),
+			WorldStyle:   req.GetWorldStyle(),
+			WorldTier:    req.GetWorldTier(),
+			StoryType:    req.GetStoryType(),
+			Theme:        req.GetTheme(),
+			Protagonist:  req.GetProtagonist(),
+			Length:       req.GetLength(),
+			ChapterCount: int(req.GetChapterCount()),
+			Structure:    req.GetStructure(),
+			Genre:        req.GetGenre(),
+			Options: GenerationOptions{
+				SkipWorldBuild:      opts.GetSkipWorldBuild(),
+				ExistingWorldID:     opts.GetExistingWorldId(),
+				SkipNarrative:       opts.GetSkipNarrative(),
+				ExistingBlueprintID: opts.GetExistingBlueprintId(),
+				GenerateContent:     opts.GetGenerateContent(),
+				StartChapter:        int(opts.GetStartChapter()),
+				EndChapter:          int(opts.GetEndChapter()),
+				Style:               opts.GetStyle(),
+				MaxRevisions:        int(opts.GetMaxRevisions()),
+			},
+		},
+	}
+	if err := validateGRPCCreateRequest(dto); err != nil {
+		return nil, err
+	}
+
+	userID := grpcUserID(ctx)
+	if err := quota.Check(db.Get(), userID); err != nil {
+		if errors.Is(err, llm.ErrBudgetExceeded) {
+			return nil, status.Errorf(codes.ResourceExhausted, "本月额度已用尽: %v", err)
+		}
+		return nil, status.Errorf(codes.Internal, "检查额度失败: %v", err)
+	}
+
+	task, err := orchestrator.CreateProjectAsync(toCreationParams(dto, userID), s.orc)
+	if err != nil {
+		return nil, status.Errorf(codes.Internal, "创建任务失败: %v", err)
+	}
+	return &xupuv1.CreateProjectTaskResponse{Task: toTaskProto(task)}, nil
+}
+
+// ownedTask 当前用户的任务，归属判断同 REST 的 taskOwner
+func ownedTask(ctx context.Context, taskID string) (*scheduler.Task, error) {
+	task, err := orchestrator.GetTask(taskID)
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "任务不存在")
+	}
+	if owner := taskOwner(task); owner == "" || owner != grpcUserID(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "无权访问")
+	}
+	return task, nil
+}
+
+func (s *grpcTaskService) GetTask(ctx context.Context, req *xupuv1.GetTaskRequest) (*xupuv1.GetTaskResponse, error) {
+	task, err := ownedTask(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return &xupuv1.GetTaskResponse{Task: toTaskProto(task)}, nil
+}
+
+func (s *grpcTaskService) ListProjectTasks(ctx context.Context, req *xupuv1.ListProjectTasksRequest) (*xupuv1.ListProjectTasksResponse, error) {
+	project, err := db.Get().GetProject(req.GetProjectId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "项目不存在")
+	}
+	if project.UserID != grpcUserID(ctx) {
+		return nil, status.Error(codes.PermissionDenied, "无权访问")
+	}
+
+	resp := &xupuv1.ListProjectTasksResponse{}
+	for _, task := range orchestrator.GetProjectTasks(req.GetProjectId()) {
+		resp.Tasks = append(resp.Tasks, toTaskProto(task))
+	}
+	return resp, nil
+}
+
+func (s *grpcTaskService) CancelTask(ctx context.Context, req *xupuv1.CancelTaskRequest) (*xupuv1.CancelTaskResponse, error) {
+	if _, err := ownedTask(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+	if err := orchestrator.CancelTask(req.GetId()); err != nil {
+		return nil, status.Errorf(codes.FailedPrecondition, "取消任务失败: %v", err)
+	}
+	task, err := orchestrator.GetTask(req.GetId())
+	if err != nil {
+		return nil, status.Error(codes.NotFound, "任务不存在")
+	}
+	return &xupuv1.CancelTaskResponse{Task: toTaskProto(task)}, nil
+}
+
+func (s *grpcTaskService) WatchTask(req *xupuv1.WatchTaskRequest, stream xupuv1.TaskService_WatchTaskServer) error {
+	task, err := ownedTask(stream.Context(), req.GetId())
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(grpcWatchInterval)
+	defer ticker.Stop()
+	var last *xupuv1.Task
+	for {
+		current := toTaskProto(task)
+		if last == nil || current.Status != last.Status || current.Progress != last.Progress {
+			if err := stream.Send(&xupuv1.WatchTaskResponse{Task: current}); err != nil {
+				return err
+			}
+			last = current
+		}
+		switch task.GetStatus() {
+		case scheduler.StatusCompleted, scheduler.StatusFailed, scheduler.StatusCancelled:
+			return nil
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-ticker.C:
+		}
+		if task, err = orchestrator.GetTask(req.GetId()); err != nil {
+			return status.Error(codes.NotFound, "任务不存在")
+		}
+	}
+}
+
+// toTaskProto 由 REST 的任务状态响应转换
+func toTaskProto(task *scheduler.Task) *xupuv1.Task {
+	r := toTaskStatusResponse(task)
+	t := &xupuv1.Task{
+		Id:        r.TaskID,
+		Type:      r.Type,
+		Status:    r.Status,
+		Progress:  r.Progress,
+		Priority:  int32(r.Priority),
+		CreatedAt: r.CreatedAt,
+		Error:     r.Error,
+		ProjectId: r.ProjectID,
+	}
+	if r.StartedAt != nil {
+		t.StartedAt = *r.StartedAt
+	}
+	if r.CompletedAt != nil {
+		t.CompletedAt = *r.CompletedAt
+	}
+	return t
+}
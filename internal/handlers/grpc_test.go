@@ -0,0 +1,165 @@
+// Package handlers gRPC 认证和任务归属测试
+package handlers
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/internal/services/auth"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/orchestrator"
+	"github.com/xlei/xupu/pkg/rpc/xupuv1"
+	"github.com/xlei/xupu/pkg/scheduler"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// newGRPCTaskClient 在内存连接上启动 gRPC 服务器，返回任务服务客户端
+func newGRPCTaskClient(t *testing.T, jwtSecret string) xupuv1.TaskServiceClient {
+	t.Helper()
+	lis := bufconn.Listen(1 << 20)
+	server := NewGRPCServer(nil, jwtSecret)
+	go server.Serve(lis)
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	if err != nil {
+		t.Fatalf("连接 gRPC 服务器失败: %v", err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return xupuv1.NewTaskServiceClient(conn)
+}
+
+// TestGRPCTaskOwnership gRPC 认证复用 REST 的用户校验和API密钥权限范围，任务接口只允许任务所属用户访问
+func TestGRPCTaskOwnership(t *testing.T) {
+	const secret = "grpc-test-secret"
+	ctx := context.Background()
+	database := db.Get()
+	authService := auth.NewAuthService(secret)
+
+	register := func(name string) (*models.User, string) {
+		user, token, _, err := authService.Register(ctx, auth.RegisterRequest{Username: name, Email: name + "@example.com", Password: "Passw0rd!x"})
+		if err != nil {
+			t.Fatalf("注册用户 %s 失败: %v", name, err)
+		}
+		return user, token
+	}
+	owner, ownerToken := register("grpc_owner")
+	_, otherToken := register("grpc_other")
+	disabled, disabledToken := register("grpc_disabled")
+	disabled.Status = "disabled"
+	if err := repositories.NewUserRepository().Update(ctx, disabled); err != nil {
+		t.Fatalf("禁用用户失败: %v", err)
+	}
+
+	plain, prefix, hash, err := auth.GenerateAPIKey()
+	if err != nil {
+		t.Fatalf("生成API密钥失败: %v", err)
+	}
+	readKey := &models.APIKey{ID: db.GenerateID("apikey"), UserID: owner.ID, Name: "只读", Prefix: prefix, KeyHash: hash, Scopes: []string{models.APIKeyScopeRead}}
+	if err := database.SaveAPIKey(readKey); err != nil {
+		t.Fatalf("保存API密钥失败: %v", err)
+	}
+
+	project := &models.Project{ID: db.GenerateID("project"), Name: "gRPC 归属测试", UserID: owner.ID}
+	if err := database.SaveProject(project); err != nil {
+		t.Fatalf("保存项目失败: %v", err)
+	}
+
+	if err := orchestrator.InitScheduler(); err != nil {
+		t.Fatalf("启动调度器失败: %v", err)
+	}
+	t.Cleanup(orchestrator.StopScheduler)
+	task := scheduler.NewJob(scheduler.TaskTypeChapterGen, project.ID, nil, func(ctx context.Context, _ *scheduler.Task) error {
+		<-ctx.Done()
+		return ctx.Err()
+	}).Build()
+	if err := orchestrator.GetScheduler().Submit(task); err != nil {
+		t.Fatalf("提交任务失败: %v", err)
+	}
+
+	client := newGRPCTaskClient(t, secret)
+	withToken := func(token string) context.Context {
+		return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+	}
+	withKey := metadata.AppendToOutgoingContext(ctx, "x-api-key", plain)
+
+	watch := func(ctx context.Context) error {
+		stream, err := client.WatchTask(ctx, &xupuv1.WatchTaskRequest{Id: task.ID})
+		if err != nil {
+			return err
+		}
+		_, err = stream.Recv()
+		return err
+	}
+
+	tests := []struct {
+		name string
+		call func() error
+		want codes.Code
+	}{
+		{name: "所属用户查询任务", want: codes.OK, call: func() error {
+			_, err := client.GetTask(withToken(ownerToken), &xupuv1.GetTaskRequest{Id: task.ID})
+			return err
+		}},
+		{name: "所属用户列出项目任务", want: codes.OK, call: func() error {
+			resp, err := client.ListProjectTasks(withToken(ownerToken), &xupuv1.ListProjectTasksRequest{ProjectId: project.ID})
+			if err == nil && len(resp.Tasks) != 1 {
+				t.Errorf("项目任务数 = %d, 期望 1", len(resp.Tasks))
+			}
+			return err
+		}},
+		{name: "其他用户查询任务", want: codes.PermissionDenied, call: func() error {
+			_, err := client.GetTask(withToken(otherToken), &xupuv1.GetTaskRequest{Id: task.ID})
+			return err
+		}},
+		{name: "其他用户列出项目任务", want: codes.PermissionDenied, call: func() error {
+			_, err := client.ListProjectTasks(withToken(otherToken), &xupuv1.ListProjectTasksRequest{ProjectId: project.ID})
+			return err
+		}},
+		{name: "其他用户订阅任务", want: codes.PermissionDenied, call: func() error {
+			return watch(withToken(otherToken))
+		}},
+		{name: "其他用户取消任务", want: codes.PermissionDenied, call: func() error {
+			_, err := client.CancelTask(withToken(otherToken), &xupuv1.CancelTaskRequest{Id: task.ID})
+			return err
+		}},
+		{name: "已禁用用户的token", want: codes.Unauthenticated, call: func() error {
+			_, err := client.GetTask(withToken(disabledToken), &xupuv1.GetTaskRequest{Id: task.ID})
+			return err
+		}},
+		{name: "只读API密钥查询任务", want: codes.OK, call: func() error {
+			_, err := client.GetTask(withKey, &xupuv1.GetTaskRequest{Id: task.ID})
+			return err
+		}},
+		{name: "只读API密钥取消任务", want: codes.PermissionDenied, call: func() error {
+			_, err := client.CancelTask(withKey, &xupuv1.CancelTaskRequest{Id: task.ID})
+			return err
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := status.Code(tt.call()); got != tt.want {
+				t.Errorf("状态码 = %s, 期望 %s", got, tt.want)
+			}
+		})
+	}
+
+	if task.GetStatus() == scheduler.StatusCancelled {
+		t.Fatal("无权取消的调用不应取消任务")
+	}
+	if _, err := client.CancelTask(withToken(ownerToken), &xupuv1.CancelTaskRequest{Id: task.ID}); err != nil {
+		t.Errorf("所属用户取消任务失败: %v", err)
+	}
+}
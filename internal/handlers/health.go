@@ -0,0 +1,171 @@
+// Package handlers HTTP处理器 - 健康检查
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/orchestrator"
+)
+
+const (
+	dbProbeTimeout  = 2 * time.Second
+	llmProbeTimeout = 5 * time.Second
+	// llmProbeTTL LLM探测结果的缓存时间，避免探针频繁调用真实API产生费用
+	llmProbeTTL = 30 * time.Second
+)
+
+// 组件状态
+const (
+	ComponentUp   = "up"
+	ComponentDown = "down"
+)
+
+// ComponentStatus 单个依赖组件的探测结果
+type ComponentStatus struct {
+	Status    string                 `json:"status"`
+	LatencyMs int64                  `json:"latency_ms"`
+	Error     string                 `json:"error,omitempty"`
+	Details   map[string]interface{} `json:"details,omitempty"`
+	CheckedAt time.Time              `json:"checked_at"`
+}
+
+// HealthResponse 健康检查响应
+type HealthResponse struct {
+	Status     string                     `json:"status"` // ok 或 unavailable
+	Components map[string]ComponentStatus `json:"components"`
+}
+
+// HealthHandler 健康检查处理器
+// /healthz 只检查进程内依赖（数据库、调度器），用作存活探针；
+// /readyz 额外探测LLM提供商，用作就绪探针
+type HealthHandler struct {
+	db        db.Database
+	llm       *llm.Client
+	llmErr    error
+	mu        sync.Mutex
+	llmStatus *ComponentStatus
+}
+
+// NewHealthHandler 创建健康检查处理器，LLM探测使用叙事引擎的提供商
+func NewHealthHandler(database db.Database) *HealthHandler {
+	client, _, err := llm.NewClientForModule("narrative_engine")
+	return &HealthHandler{
+		db:     database,
+		llm:    client,
+		llmErr: err,
+	}
+}
+
+// Healthz 存活检查
+// @Summary 存活检查
+// @Description 检查数据库连接和任务调度器状态
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
+// @Router /healthz [get]
+func (h *HealthHandler) Healthz(c *gin.Context) {
+	respondHealth(c, map[string]ComponentStatus{
+		"database":  h.probeDB(c.Request.Context()),
+		"scheduler": probeScheduler(),
+	})
+}
+
+// Readyz 就绪检查
+// @Summary 就绪检查
+// @Description 检查数据库连接、任务调度器状态和LLM提供商可达性（结果缓存30秒）
+// @Tags health
+// @Produce json
+// @Success 200 {object} HealthResponse
+// @Failure 503 {object} HealthResponse
+// @Router /readyz [get]
+func (h *HealthHandler) Readyz(c *gin.Context) {
+	respondHealth(c, map[string]ComponentStatus{
+		"database":  h.probeDB(c.Request.Context()),
+		"scheduler": probeScheduler(),
+		"llm":       h.probeLLM(c.Request.Context()),
+	})
+}
+
+func respondHealth(c *gin.Context, components map[string]ComponentStatus) {
+	resp := HealthResponse{Status: "ok", Components: components}
+	code := http.StatusOK
+	for _, comp := range components {
+		if comp.Status != ComponentUp {
+			resp.Status = "unavailable"
+			code = http.StatusServiceUnavailable
+		}
+	}
+	c.JSON(code, resp)
+}
+
+// probe 执行一次探测并记录耗时
+func probe(fn func() error) ComponentStatus {
+	start := time.Now()
+	err := fn()
+	status := ComponentStatus{
+		Status:    ComponentUp,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: start,
+	}
+	if err != nil {
+		status.Status = ComponentDown
+		status.Error = err.Error()
+	}
+	return status
+}
+
+func (h *HealthHandler) probeDB(ctx context.Context) ComponentStatus {
+	ctx, cancel := context.WithTimeout(ctx, dbProbeTimeout)
+	defer cancel()
+	return probe(func() error {
+		return db.Ping(ctx, h.db)
+	})
+}
+
+func probeScheduler() ComponentStatus {
+	s := orchestrator.GetScheduler()
+	if s == nil || !s.IsRunning() {
+		return ComponentStatus{Status: ComponentDown, Error: "调度器未运行", CheckedAt: time.Now()}
+	}
+	return ComponentStatus{
+		Status:    ComponentUp,
+		CheckedAt: time.Now(),
+		Details: map[string]interface{}{
+			"workers":        s.GetWorkerCount(),
+			"active_workers": s.GetActiveWorkers(),
+			"queue_size":     s.GetQueueSize(),
+		},
+	}
+}
+
+// probeLLM 探测LLM提供商，结果在 llmProbeTTL 内复用
+func (h *HealthHandler) probeLLM(ctx context.Context) ComponentStatus {
+	if h.llmErr != nil {
+		return ComponentStatus{Status: ComponentDown, Error: h.llmErr.Error(), CheckedAt: time.Now()}
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.llmStatus != nil && time.Since(h.llmStatus.CheckedAt) < llmProbeTTL {
+		return *h.llmStatus
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, llmProbeTimeout)
+	defer cancel()
+	status := probe(func() error {
+		return h.llm.Ping(ctx)
+	})
+	status.Details = map[string]interface{}{
+		"provider": h.llm.Provider,
+		"model":    h.llm.Model,
+	}
+	h.llmStatus = &status
+	return status
+}
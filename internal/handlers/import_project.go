@@ -5,14 +5,17 @@ import (
 	"net/http"
 	"os"
 	"path/filepath"
-	"regexp"
 	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/manuscript"
 )
 
+// UploadDir 运行时上传文件的磁盘目录，通过 /static/uploads/ 访问
+const UploadDir = "static/uploads"
+
 // ImportProject 导入项目
 // @Summary 导入本地小说
 // @Description 上传 TXT 文件并导入为项目
@@ -74,7 +77,7 @@ func (h *ProjectHandler) ImportProject(c *gin.Context) {
 	coverFile, err := c.FormFile("cover")
 	if err == nil {
 		// 确保目录存在
-		uploadDir := "static/uploads/covers"
+		uploadDir := filepath.Join(UploadDir, "covers")
 		if err := os.MkdirAll(uploadDir, 0755); err != nil {
 			// 忽略错误，继续
 		}
@@ -100,66 +103,20 @@ func (h *ProjectHandler) ImportProject(c *gin.Context) {
 		Author:      author,
 		Description: description,
 		CoverURL:    coverURL,
-		Mode:        models.OrchestrationMode("local_import"),
+		Mode:        models.ModeLocalImport,
 		Status:      models.StatusCompleted, // 导入的项目直接标记为完成
 		Progress:    100,
 	}
 
-	// 解析章节
-	// 简单的正则匹配：第X章 或 第X节
-	chapterRegex := regexp.MustCompile(`\n\s*(第[0-9一二三四五六七八九十百千]+[章节][^\n]*)`)
-	indexes := chapterRegex.FindAllStringIndex(content, -1)
-
+	// 解析章节：第X章/第X节为标题，之前的内容作为序章，没有标题时整个文件作为一章
 	var chapters []*models.Chapter
-
-	// 处理引子/序章（如果第一章之前有内容）
-	if len(indexes) > 0 && indexes[0][0] > 0 {
-		prefaceContent := strings.TrimSpace(content[:indexes[0][0]])
-		if len(prefaceContent) > 0 {
-			chapters = append(chapters, &models.Chapter{
-				ID:         db.GenerateID("chapter"),
-				ProjectID:  project.ID,
-				Title:      "序章/引子",
-				Content:    prefaceContent,
-				ChapterNum: 0,
-				Status:     models.ChapterStatusCompleted,
-			})
-		}
-	}
-
-	for i, idx := range indexes {
-		// start := idx[0]
-		end := len(content)
-		if i < len(indexes)-1 {
-			end = indexes[i+1][0]
-		}
-
-		// 提取标题和内容
-		// idx[0] 是匹配到的开始，idx[1] 是匹配到的结束（即标题行的结束）
-		// 但这里的正则包含了 \n，所以我们需要更精确地提取标题
-
-		fullMatch := content[idx[0]:idx[1]]
-		title := strings.TrimSpace(fullMatch)
-		chapterContent := strings.TrimSpace(content[idx[1]:end])
-
-		chapters = append(chapters, &models.Chapter{
-			ID:         db.GenerateID("chapter"),
-			ProjectID:  project.ID,
-			Title:      title,
-			Content:    chapterContent,
-			ChapterNum: len(chapters) + 1,
-			Status:     models.ChapterStatusCompleted,
-		})
-	}
-
-	// 如果正则没匹配到任何章节，将整个文件作为一个章节
-	if len(chapters) == 0 {
+	for _, ch := range manuscript.SplitChapters(content, false, projectName) {
 		chapters = append(chapters, &models.Chapter{
 			ID:         db.GenerateID("chapter"),
 			ProjectID:  project.ID,
-			Title:      projectName,
-			Content:    content,
-			ChapterNum: 1,
+			Title:      ch.Title,
+			Content:    ch.Content,
+			ChapterNum: ch.Num,
 			Status:     models.ChapterStatusCompleted,
 		})
 	}
@@ -0,0 +1,352 @@
+// Package handlers HTTP处理器 - 关键物品
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/items"
+	"github.com/xlei/xupu/pkg/narrative"
+)
+
+// ItemHandler 关键物品处理器
+type ItemHandler struct {
+	db db.Database
+}
+
+// NewItemHandler 创建关键物品处理器
+func NewItemHandler(database db.Database) *ItemHandler {
+	return &ItemHandler{db: database}
+}
+
+// ListItems 获取关键物品及其去向
+// @Summary 获取关键物品
+// @Description 返回登记的关键物品、去向变化记录，以及指定章节开始时各物品在谁手中
+// @Tags items
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param chapter query int false "章节号，默认为最新一章之后"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/items [get]
+func (h *ItemHandler) ListItems(c *gin.Context) {
+	chapter := 0
+	if v := c.Query("chapter"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "chapter 应为正整数", v))
+			return
+		}
+		chapter = n
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	if chapter == 0 {
+		for _, ch := range h.db.ListChaptersByProject(project.ID) {
+			if ch.ChapterNum >= chapter {
+				chapter = ch.ChapterNum + 1
+			}
+		}
+		chapter = max(chapter, 1)
+	}
+	list, err := h.db.ListItems(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取物品失败", err.Error()))
+		return
+	}
+	transfers, err := h.db.ListItemTransfers(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取物品去向失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"items":     list,
+		"transfers": transfers,
+		"chapter":   chapter,
+		"holdings":  items.Holdings(list, transfers, chapter),
+	}))
+}
+
+// CreateItem 手工登记关键物品
+// @Summary 登记关键物品
+// @Description 登记需要追踪去向的物品，写场景时会注入其当前持有人
+// @Tags items
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body ItemRequest true "物品"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/items [post]
+func (h *ItemHandler) CreateItem(c *gin.Context) {
+	var req ItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	list, err := h.db.ListItems(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取物品失败", err.Error()))
+		return
+	}
+	if items.Find(list, req.Name) != nil {
+		c.JSON(http.StatusConflict, errorResponse("CONFLICT", "同名物品已登记", req.Name))
+		return
+	}
+
+	item := &models.Item{
+		ID:        db.GenerateID("item"),
+		ProjectID: project.ID,
+		Source:    models.ItemFromManual,
+	}
+	applyItemRequest(item, &req)
+	if err := h.db.SaveItem(item); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存物品失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"item": item,
+	}))
+}
+
+// UpdateItem 修改关键物品
+// @Summary 修改关键物品
+// @Description 修改物品的名称、别称、描述和初始去向；此后的去向变化记录不受影响
+// @Tags items
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param itemId path string true "物品ID"
+// @Param request body ItemRequest true "物品"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/items/{itemId} [put]
+func (h *ItemHandler) UpdateItem(c *gin.Context) {
+	var req ItemRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	item, ok := h.loadItem(c)
+	if !ok {
+		return
+	}
+
+	applyItemRequest(item, &req)
+	if err := h.db.SaveItem(item); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存物品失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"item": item,
+	}))
+}
+
+// DeleteItem 删除关键物品
+// @Summary 删除关键物品
+// @Description 删除物品及其全部去向记录
+// @Tags items
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param itemId path string true "物品ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/items/{itemId} [delete]
+func (h *ItemHandler) DeleteItem(c *gin.Context) {
+	item, ok := h.loadItem(c)
+	if !ok {
+		return
+	}
+	if err := h.db.DeleteItem(item.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "删除物品失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"deleted": item.ID,
+	}))
+}
+
+// CreateItemTransfer 手工登记物品去向变化
+// @Summary 登记物品去向变化
+// @Description 手工登记物品在某一章的转手、遗失或毁坏；从正文提取的记录在该章重新分析时替换，手工记录保留
+// @Tags items
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param itemId path string true "物品ID"
+// @Param request body CreateItemTransferRequest true "去向变化"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/items/{itemId}/transfers [post]
+func (h *ItemHandler) CreateItemTransfer(c *gin.Context) {
+	var req CreateItemTransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	item, ok := h.loadItem(c)
+	if !ok {
+		return
+	}
+
+	transfer := &models.ItemTransfer{
+		ID:        db.GenerateID("transfer"),
+		ProjectID: item.ProjectID,
+		ItemID:    item.ID,
+		Chapter:   req.Chapter,
+		From:      strings.TrimSpace(req.From),
+		To:        strings.TrimSpace(req.To),
+		Location:  strings.TrimSpace(req.Location),
+		Note:      req.Note,
+		Destroyed: req.Destroyed,
+		Source:    models.ItemFromManual,
+	}
+	if err := h.db.SaveItemTransfer(transfer); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存去向记录失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"transfer": transfer,
+	}))
+}
+
+// SeedItems 从世界术语表和蓝图导入关键物品
+// @Summary 导入关键物品
+// @Description 把世界术语表中的物品登记为关键物品，并从蓝图的大纲和章节规划中提取关键物品；已登记的同名物品跳过
+// @Tags items
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body SeedItemsRequest false "导入选项"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/items/seed [post]
+func (h *ItemHandler) SeedItems(c *gin.Context) {
+	var req SeedItemsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	list, err := h.db.ListItems(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取物品失败", err.Error()))
+		return
+	}
+	known := make([]string, 0, len(list))
+	for _, item := range list {
+		known = append(known, item.Names()...)
+	}
+
+	added := make([]models.Item, 0)
+	if world, err := h.db.GetWorld(project.WorldID); err == nil && world != nil {
+		for _, t := range world.Glossary {
+			if t.Kind != models.TermItem || items.Find(list, t.Term) != nil {
+				continue
+			}
+			item := models.Item{
+				Name:        t.Term,
+				Aliases:     t.Aliases,
+				Kind:        models.ItemOther,
+				Description: t.Note,
+				Source:      models.ItemFromWorld,
+			}
+			list = append(list, item)
+			known = append(known, t.Term)
+			added = append(added, item)
+		}
+	}
+
+	if !req.SkipStory {
+		blueprint := projectBlueprint(h.db, project)
+		if blueprint.ID == "" {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "蓝图不存在", "可传 skip_story 只导入术语表中的物品"))
+			return
+		}
+		budget, ok := userBudget(c, h.db)
+		if !ok {
+			return
+		}
+		engine, err := narrative.New()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化叙事器失败", err.Error()))
+			return
+		}
+		extracted, err := engine.WithBudget(budget).WithLogger(requestLogger(c)).WithLanguage(project.Language).ExtractKeyItems(blueprint, known)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "提取关键物品失败", err.Error()))
+			return
+		}
+		for _, item := range extracted {
+			if items.Find(list, item.Name) != nil {
+				continue
+			}
+			list = append(list, item)
+			added = append(added, item)
+		}
+	}
+
+	for i := range added {
+		added[i].ID = db.GenerateID("item")
+		added[i].ProjectID = project.ID
+		if err := h.db.SaveItem(&added[i]); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存物品失败", err.Error()))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"added": added,
+		"total": len(list),
+	}))
+}
+
+// applyItemRequest 用请求内容更新物品
+func applyItemRequest(item *models.Item, req *ItemRequest) {
+	item.Name = strings.TrimSpace(req.Name)
+	item.Aliases = req.Aliases
+	item.Kind = req.Kind
+	if item.Kind == "" {
+		item.Kind = models.ItemOther
+	}
+	item.Description = req.Description
+	item.Holder = strings.TrimSpace(req.Holder)
+	item.Location = strings.TrimSpace(req.Location)
+	item.Chapter = req.Chapter
+}
+
+// loadItem 加载当前用户项目中的物品，失败时已写入响应
+func (h *ItemHandler) loadItem(c *gin.Context) (*models.Item, bool) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return nil, false
+	}
+	item, err := h.db.GetItem(c.Param("itemId"))
+	if err != nil || item.ProjectID != project.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "物品不存在", ""))
+		return nil, false
+	}
+	return item, true
+}
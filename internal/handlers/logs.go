@@ -0,0 +1,35 @@
+// Package handlers HTTP处理器 - 请求/任务日志
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/logx"
+)
+
+// requestLogger 获取 RequestID 中间件放入的带 trace_id 的日志，未经过中间件时返回全局日志
+func requestLogger(c *gin.Context) *slog.Logger {
+	if v, ok := c.Get("Logger"); ok {
+		if l, ok := v.(*slog.Logger); ok {
+			return l
+		}
+	}
+	return logx.L()
+}
+
+// respondTraceLogs 返回指定 trace 的缓冲日志，可用 ?level= 过滤最低级别（默认 debug）
+func respondTraceLogs(c *gin.Context, traceID string) {
+	minLevel := slog.LevelDebug
+	if level := c.Query("level"); level != "" {
+		minLevel = logx.ParseLevel(level)
+	}
+
+	entries := logx.Entries(traceID, minLevel)
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"trace_id": traceID,
+		"entries":  entries,
+		"total":    len(entries),
+	}))
+}
@@ -0,0 +1,33 @@
+// Package handlers 处理器测试的公共环境
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// TestMain 在仓库根目录下运行（读取 config/config.yaml），使用临时 SQLite 数据库和演练模式的LLM
+func TestMain(m *testing.M) {
+	dir, err := os.MkdirTemp("", "xupu-handlers")
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	if err := os.Chdir("../.."); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+	os.Setenv("DB_DRIVER", "sqlite")
+	os.Setenv("DB_PATH", filepath.Join(dir, "handlers.db"))
+	llm.SetDryRun(true)
+	gin.SetMode(gin.TestMode)
+
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
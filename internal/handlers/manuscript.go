@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/manuscript"
+)
+
+// maxManuscriptSize 单次导入的书稿总大小上限
+const maxManuscriptSize = 32 << 20
+
+// ManuscriptHandler 书稿导入处理器
+type ManuscriptHandler struct {
+	db db.Database
+}
+
+// NewManuscriptHandler 创建书稿导入处理器
+func NewManuscriptHandler(database db.Database) *ManuscriptHandler {
+	return &ManuscriptHandler{db: database}
+}
+
+// ImportManuscript 导入已有书稿并规划续写
+// @Summary 导入书稿续写
+// @Description 上传已写好的章节（TXT/Markdown/DOCX，可多个文件，按文件名自然顺序排列，一个文件可含多章），章节入库后在后台通读书稿，重建角色、世界设定、细节事实、情节线和伏笔，再从下一章起规划续写；返回导入记录，进度通过导入记录列表查询
+// @Tags projects
+// @Accept multipart/form-data
+// @Produce json
+// @Param files formData file true "书稿文件（可多个）"
+// @Param name formData string false "项目名称，默认使用排在最前的文件名"
+// @Param author formData string false "作者"
+// @Param description formData string false "简介"
+// @Param language formData string false "输出语言（zh-CN/zh-TW/en/ja）"
+// @Param continue_chapters formData int false "续写规划的章节数（1-30，默认10）"
+// @Success 202 {object} APIResponse
+// @Router /api/v1/projects/import/manuscript [post]
+func (h *ManuscriptHandler) ImportManuscript(c *gin.Context) {
+	userID, exists := GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未授权", ""))
+		return
+	}
+
+	var req ImportManuscriptRequest
+	if err := c.ShouldBind(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	form, err := c.MultipartForm()
+	if err != nil || len(form.File["files"]) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_FILE", "未找到上传文件", ""))
+		return
+	}
+
+	var (
+		files []manuscript.File
+		names []string
+		total int64
+	)
+	for _, fh := range form.File["files"] {
+		if !manuscript.Supported(fh.Filename) {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_FILE_TYPE", "仅支持txt、md、docx文件", fh.Filename))
+			return
+		}
+		if total += fh.Size; total > maxManuscriptSize {
+			c.JSON(http.StatusRequestEntityTooLarge, errorResponse("FILE_TOO_LARGE", "书稿文件过大", "总大小不能超过32MB"))
+			return
+		}
+		src, err := fh.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("READ_FAILED", "读取文件失败", err.Error()))
+			return
+		}
+		data, err := io.ReadAll(src)
+		src.Close()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("READ_FAILED", "读取文件内容失败", err.Error()))
+			return
+		}
+		files = append(files, manuscript.File{Name: fh.Filename, Data: data})
+		names = append(names, fh.Filename)
+	}
+
+	chapters, err := manuscript.Parse(files)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("PARSE_FAILED", "解析书稿失败", err.Error()))
+		return
+	}
+
+	if !checkQuota(c, h.db, userID) {
+		return
+	}
+
+	name := req.Name
+	if name == "" {
+		name = strings.TrimSuffix(chapters[0].Source, filepath.Ext(chapters[0].Source))
+	}
+	imp, err := manuscript.Create(h.db, names, chapters, manuscript.Options{
+		UserID:           userID,
+		Name:             name,
+		Author:           req.Author,
+		Description:      req.Description,
+		Language:         req.Language,
+		ContinueChapters: req.ContinueChapters,
+	})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存书稿失败", err.Error()))
+		return
+	}
+	recordJob(c, imp.Project.ID)
+
+	record := *imp.Record
+	go imp.WithLogger(requestLogger(c)).Run(nil)
+
+	c.JSON(http.StatusAccepted, successResponse(&record))
+}
+
+// ListManuscriptImports 获取书稿导入记录
+// @Summary 获取书稿导入记录
+// @Description 列出项目的书稿导入记录，包含分析进度、识别出的角色数、细节事实数、未回收伏笔数和重建的演化状态
+// @Tags projects
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/manuscript-imports [get]
+func (h *ManuscriptHandler) ListManuscriptImports(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	imports, err := h.db.ListManuscriptImports(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取导入记录失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(imports))
+}
@@ -0,0 +1,214 @@
+// Package handlers HTTP处理器 - 营销文案
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// defaultMarketingChapters 生成营销文案时默认参考的开篇章节数
+const defaultMarketingChapters = 3
+
+// GetMarketingCopy 获取营销文案
+// @Summary 获取营销文案
+// @Description 获取项目的宣传语、简介、作品介绍和标签
+// @Tags synopsis
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/marketing [get]
+func (h *SynopsisHandler) GetMarketingCopy(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	marketing, err := h.db.GetMarketingCopy(project.ID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "尚未生成营销文案", ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"marketing": marketing,
+	}))
+}
+
+// GenerateMarketingCopy 生成营销文案
+// @Summary 生成营销文案
+// @Description 根据蓝图和开篇章节生成宣传语（150字）、简介（300字）、作品介绍（1000字）和标签；可只重新生成指定项，整体生成时保留手动编辑过的项
+// @Tags synopsis
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body GenerateMarketingRequest false "生成范围与修改意见"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/marketing/generate [post]
+func (h *SynopsisHandler) GenerateMarketingCopy(c *gin.Context) {
+	var req GenerateMarketingRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	marketing, err := h.db.GetMarketingCopy(project.ID)
+	if err != nil {
+		marketing = &models.MarketingCopy{
+			ID:        db.GenerateID("marketing"),
+			ProjectID: project.ID,
+		}
+	}
+
+	// 未指定范围时整体生成，但不覆盖手动编辑过的项
+	fields := req.Fields
+	if len(fields) == 0 {
+		for _, f := range models.MarketingFields {
+			if !marketing.IsEdited(f) {
+				fields = append(fields, f)
+			}
+		}
+		if len(fields) == 0 {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "全部文案都已手动编辑", "请通过 fields 指定要重新生成的项"))
+			return
+		}
+	}
+
+	blueprint := projectBlueprint(h.db, project)
+	params := writer.MarketingParams{
+		Title:    project.Name,
+		Fields:   fields,
+		Current:  marketing,
+		Guidance: req.Guidance,
+	}
+	if blueprint.ID != "" {
+		params.Blueprint = blueprint
+	}
+	if project.WorldID != "" {
+		if world, err := h.db.GetWorld(project.WorldID); err == nil {
+			params.Genre = string(world.Type)
+		}
+	}
+
+	count := req.Chapters
+	if count == 0 {
+		count = defaultMarketingChapters
+	}
+	for _, ch := range sortedChapters(h.db, project.ID) {
+		if len(params.Chapters) >= count {
+			break
+		}
+		text := ch.Summary
+		if strings.TrimSpace(text) == "" {
+			text = chapterProse(h.db, blueprint, ch)
+		}
+		if strings.TrimSpace(text) != "" {
+			params.Chapters = append(params.Chapters, text)
+		}
+	}
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化写作器失败", err.Error()))
+		return
+	}
+	generated, err := w.WithBudget(budget).WithLogger(requestLogger(c)).WithLanguage(project.Language).GenerateMarketingCopy(params)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "生成营销文案失败", err.Error()))
+		return
+	}
+
+	for _, f := range fields {
+		switch f {
+		case models.MarketingTagline:
+			marketing.Tagline = generated.Tagline
+		case models.MarketingBlurb:
+			marketing.Blurb = generated.Blurb
+		case models.MarketingIntroduction:
+			marketing.Introduction = generated.Introduction
+		case models.MarketingTags:
+			marketing.Tags = generated.Tags
+		}
+		marketing.ClearEdited(f)
+	}
+	if err := h.db.SaveMarketingCopy(marketing); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存营销文案失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"marketing":   marketing,
+		"regenerated": fields,
+	}))
+}
+
+// UpdateMarketingCopy 编辑营销文案
+// @Summary 编辑营销文案
+// @Description 手动修改营销文案的任意项，修改过的项在整体重新生成时保留
+// @Tags synopsis
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body UpdateMarketingRequest true "要修改的项"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/marketing [put]
+func (h *SynopsisHandler) UpdateMarketingCopy(c *gin.Context) {
+	var req UpdateMarketingRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	marketing, err := h.db.GetMarketingCopy(project.ID)
+	if err != nil {
+		marketing = &models.MarketingCopy{
+			ID:        db.GenerateID("marketing"),
+			ProjectID: project.ID,
+		}
+	}
+
+	if req.Tagline != nil {
+		marketing.Tagline = *req.Tagline
+		marketing.MarkEdited(models.MarketingTagline)
+	}
+	if req.Blurb != nil {
+		marketing.Blurb = *req.Blurb
+		marketing.MarkEdited(models.MarketingBlurb)
+	}
+	if req.Introduction != nil {
+		marketing.Introduction = *req.Introduction
+		marketing.MarkEdited(models.MarketingIntroduction)
+	}
+	if req.Tags != nil {
+		marketing.Tags = req.Tags
+		marketing.MarkEdited(models.MarketingTags)
+	}
+	if err := h.db.SaveMarketingCopy(marketing); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存营销文案失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"marketing": marketing,
+	}))
+}
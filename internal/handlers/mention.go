@@ -0,0 +1,147 @@
+// Package handlers HTTP处理器 - 实体提及与出场时间线
+package handlers
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/mentions"
+)
+
+// MentionHandler 实体提及处理器
+type MentionHandler struct {
+	db db.Database
+}
+
+// NewMentionHandler 创建实体提及处理器
+func NewMentionHandler(database db.Database) *MentionHandler {
+	return &MentionHandler{db: database}
+}
+
+// ListMentions 获取实体出场时间线
+// @Summary 实体出场时间线
+// @Description 按实体汇总各章的提及记录，返回每个角色、地点和物品的出场章节，以及已连续多章未出场的提醒
+// @Tags mentions
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param kind query string false "实体类型" Enums(character, location, item)
+// @Param entity query string false "只返回指定实体（ID或名称）"
+// @Param gap query int false "缺席提醒阈值（章），默认12"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/mentions [get]
+func (h *MentionHandler) ListMentions(c *gin.Context) {
+	gap := mentions.DefaultAbsenceGap
+	if v := c.Query("gap"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "gap 应为正整数", v))
+			return
+		}
+		gap = n
+	}
+	kind := models.MentionKind(c.Query("kind"))
+	switch kind {
+	case "", models.MentionCharacter, models.MentionLocation, models.MentionItem:
+	default:
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "不支持的实体类型", string(kind)))
+		return
+	}
+	entity := strings.TrimSpace(c.Query("entity"))
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	records, err := h.db.ListEntityMentions(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取实体提及失败", err.Error()))
+		return
+	}
+
+	latest := 0
+	for _, ch := range h.db.ListChaptersByProject(project.ID) {
+		if ch.WordCount > 0 && ch.ChapterNum > latest {
+			latest = ch.ChapterNum
+		}
+	}
+	timelines := mentions.Timelines(mentions.ProjectEntities(h.db, project.ID, project.WorldID), records, latest)
+
+	filtered := make([]mentions.Timeline, 0, len(timelines))
+	for _, t := range timelines {
+		if kind != "" && t.Kind != kind {
+			continue
+		}
+		if entity != "" && t.EntityID != entity && t.Name != entity {
+			continue
+		}
+		filtered = append(filtered, t)
+	}
+	if entity != "" && len(filtered) == 0 {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "实体不存在", entity))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"latest_chapter": latest,
+		"gap":            gap,
+		"timelines":      filtered,
+		"absences":       mentions.Absences(filtered, gap),
+	}))
+}
+
+// RebuildMentions 重新检测实体提及
+// @Summary 重新检测实体提及
+// @Description 按当前的角色和世界设定重新扫描全部章节的正文；新增角色、地点或在术语表中登记物品后使用
+// @Tags mentions
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/mentions/rebuild [post]
+func (h *MentionHandler) RebuildMentions(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	blueprint := projectBlueprint(h.db, project)
+	entities := mentions.ProjectEntities(h.db, project.ID, project.WorldID)
+	if len(entities) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "项目世界中没有可追踪的角色、地点或物品", ""))
+		return
+	}
+
+	scanned, total := 0, 0
+	for _, ch := range sortedChapters(h.db, project.ID) {
+		found, err := mentions.TrackChapter(h.db, entities, ch, chapterProse(h.db, blueprint, ch))
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存实体提及失败", err.Error()))
+			return
+		}
+		scanned++
+		total += len(found)
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"entities": len(entities),
+		"chapters": scanned,
+		"mentions": total,
+	}))
+}
+
+// trackChapterMentions 章节定稿后重新检测本章的实体提及，失败只记录日志
+func trackChapterMentions(database db.Database, project *models.Project, chapter *models.Chapter, logger *slog.Logger) {
+	entities := mentions.ProjectEntities(database, project.ID, project.WorldID)
+	if len(entities) == 0 {
+		return
+	}
+	prose := chapterProse(database, projectBlueprint(database, project), chapter)
+	if _, err := mentions.TrackChapter(database, entities, chapter, prose); err != nil {
+		logger.Warn("保存实体提及失败", "chapter", chapter.ChapterNum, "error", err)
+	}
+}
@@ -36,12 +36,35 @@ func (h *NarrativeHandler) CreateBlueprint(c *gin.Context) {
 		return
 	}
 
+	budget, ok := userBudget(c, db.Get())
+	if !ok {
+		return
+	}
+
 	// 创建叙事引擎
 	engine, err := narrative.New()
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化失败", err.Error()))
 		return
 	}
+	engine = engine.WithBudget(budget).WithLogger(requestLogger(c))
+	if req.ProjectID != "" {
+		if project, err := db.Get().GetProject(req.ProjectID); err == nil {
+			engine = engine.WithLanguage(project.Language)
+		}
+	}
+
+	// 自定义节拍表只能由所有者使用：关联项目时以项目所有者为准
+	ownerID, _ := GetUserID(c)
+	if req.ProjectID != "" {
+		if project, err := db.Get().GetProject(req.ProjectID); err == nil {
+			ownerID = project.UserID
+		}
+	}
+	beatSheet, ok := resolveBeatSheet(c, db.Get(), req.BeatSheetID, ownerID)
+	if !ok {
+		return
+	}
 
 	// 构建参数
 	params := narrative.CreateParams{
@@ -52,6 +75,9 @@ func (h *NarrativeHandler) CreateBlueprint(c *gin.Context) {
 		Length:       req.Length,
 		ChapterCount: req.ChapterCount,
 		Structure:    parseNarrativeStructure(req.Structure),
+		Characters:   req.Characters,
+		Genre:        narrative.ParseGenre(req.Genre),
+		BeatSheet:    beatSheet,
 	}
 
 	// 创建蓝图
@@ -126,6 +152,70 @@ func (h *NarrativeHandler) ExportBlueprint(c *gin.Context) {
 	}))
 }
 
+// DiffBlueprint 对比两个蓝图版本
+// @Summary 蓝图对比
+// @Description 结构化比较两个蓝图（章节增删、场景目的变化、弧光变化），返回变更列表和可读报告
+// @Tags blueprints
+// @Produce json
+// @Param id path string true "旧版本蓝图ID"
+// @Param otherID path string true "新版本蓝图ID"
+// @Param format query string false "输出格式" Enums(json, text)
+// @Success 200 {object} APIResponse
+// @Router /api/v1/blueprints/{id}/diff/{otherID} [get]
+func (h *NarrativeHandler) DiffBlueprint(c *gin.Context) {
+	from, err := db.Get().GetNarrativeBlueprint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "蓝图不存在", c.Param("id")))
+		return
+	}
+	to, err := db.Get().GetNarrativeBlueprint(c.Param("otherID"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "蓝图不存在", c.Param("otherID")))
+		return
+	}
+
+	diff := narrative.DiffBlueprints(from, to)
+
+	if c.Query("format") == "text" {
+		c.String(http.StatusOK, diff.Report())
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"diff":        diff,
+		"has_changes": diff.HasChanges(),
+		"report":      diff.Report(),
+	}))
+}
+
+// GetRelationshipGraph 导出蓝图的角色关系图
+// @Summary 角色关系图
+// @Description 将蓝图所属世界的角色关系导出为 GraphViz DOT 或 D3 JSON 图（边带关系类型和紧张度）
+// @Tags blueprints
+// @Produce json
+// @Param id path string true "蓝图ID"
+// @Param format query string false "输出格式" Enums(json, dot)
+// @Success 200 {object} APIResponse
+// @Router /api/v1/blueprints/{id}/relationship-graph [get]
+func (h *NarrativeHandler) GetRelationshipGraph(c *gin.Context) {
+	blueprint, err := db.Get().GetNarrativeBlueprint(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "蓝图不存在", ""))
+		return
+	}
+
+	graph := narrative.GraphFromCharacters(db.Get().ListCharactersByWorld(blueprint.WorldID))
+
+	switch c.DefaultQuery("format", "json") {
+	case "dot":
+		c.Data(http.StatusOK, "text/vnd.graphviz; charset=utf-8", []byte(graph.DOT()))
+	case "json":
+		c.JSON(http.StatusOK, successResponse(graph))
+	default:
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_FORMAT", "不支持的格式", "format 仅支持 dot 或 json"))
+	}
+}
+
 // ApplyBlueprint 应用蓝图（创建章节）
 // @Summary 应用蓝图
 // @Description 将蓝图中的章节规划应用到项目，创建实际的章节记录
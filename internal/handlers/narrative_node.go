@@ -3,6 +3,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"time"
 
@@ -526,6 +527,12 @@ func (h *NarrativeNodeHandler) MergeToChapter(c *gin.Context) {
 		}
 	}
 
+	var before *models.Chapter
+	if req.ChapterID != nil {
+		snapshot := *chapter
+		before = &snapshot
+	}
+
 	// 根据合并策略合并内容
 	switch req.MergeStrategy {
 	case models.MergeStrategyAppend:
@@ -558,6 +565,13 @@ func (h *NarrativeNodeHandler) MergeToChapter(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存章节失败", err.Error()))
 		return
 	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditUpdate,
+		ResourceType: models.AuditResourceChapter,
+		ResourceID:   chapter.ID,
+		ProjectID:    projectID,
+		Summary:      fmt.Sprintf("合并叙事节点 %s 到第%d章（%s）", nodeID, chapter.ChapterNum, req.MergeStrategy),
+	}, before, chapter)
 
 	// 创建映射关系
 	mapping := &models.NodeChapterMapping{
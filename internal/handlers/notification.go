@@ -0,0 +1,244 @@
+// Package handlers HTTP处理器 - 通知渠道
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/notify"
+)
+
+// maskedSetting 响应中敏感参数的占位值；更新时原样传回表示不修改
+const maskedSetting = "******"
+
+// sensitiveSettings 不在响应中返回的渠道参数
+var sensitiveSettings = map[string]bool{
+	"secret":    true,
+	"bot_token": true,
+}
+
+// NotificationHandler 通知渠道处理器
+type NotificationHandler struct {
+	db db.Database
+}
+
+// NewNotificationHandler 创建通知渠道处理器
+func NewNotificationHandler(database db.Database) *NotificationHandler {
+	return &NotificationHandler{db: database}
+}
+
+// GetNotificationEvents 获取可订阅的通知事件和渠道类型
+// @Summary 获取通知事件
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/notifications/events [get]
+func (h *NotificationHandler) GetNotificationEvents(c *gin.Context) {
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"events":   models.NotifyEvents,
+		"channels": notify.Types(),
+	}))
+}
+
+// ListNotificationChannels 列出当前用户的通知渠道
+// @Summary 列出通知渠道
+// @Tags notifications
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/notifications/channels [get]
+func (h *NotificationHandler) ListNotificationChannels(c *gin.Context) {
+	userID, _ := GetUserID(c)
+
+	channels, err := h.db.ListNotificationChannels(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取通知渠道失败", err.Error()))
+		return
+	}
+	for i := range channels {
+		maskChannel(&channels[i])
+	}
+	c.JSON(http.StatusOK, successResponse(channels))
+}
+
+// CreateNotificationChannel 创建通知渠道
+// @Summary 创建通知渠道
+// @Description 长时间的创作任务结束时推送到邮件、飞书、钉钉或 Telegram，只推送勾选的事件
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param request body NotificationChannelRequest true "通知渠道"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/notifications/channels [post]
+func (h *NotificationHandler) CreateNotificationChannel(c *gin.Context) {
+	var req NotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未授权", ""))
+		return
+	}
+
+	ch := &models.NotificationChannel{
+		ID:       db.GenerateID("notify"),
+		UserID:   userID,
+		Settings: map[string]string{},
+	}
+	if !applyChannelRequest(c, ch, &req) {
+		return
+	}
+	if err := h.db.SaveNotificationChannel(ch); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存通知渠道失败", err.Error()))
+		return
+	}
+
+	maskChannel(ch)
+	c.JSON(http.StatusCreated, successResponse(ch))
+}
+
+// UpdateNotificationChannel 更新通知渠道
+// @Summary 更新通知渠道
+// @Description 敏感参数（secret、bot_token）为空或为占位值时保持不变
+// @Tags notifications
+// @Accept json
+// @Produce json
+// @Param id path string true "通知渠道ID"
+// @Param request body NotificationChannelRequest true "通知渠道"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/notifications/channels/{id} [put]
+func (h *NotificationHandler) UpdateNotificationChannel(c *gin.Context) {
+	ch, ok := h.loadOwnedChannel(c)
+	if !ok {
+		return
+	}
+
+	var req NotificationChannelRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	if !applyChannelRequest(c, ch, &req) {
+		return
+	}
+	if err := h.db.SaveNotificationChannel(ch); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存通知渠道失败", err.Error()))
+		return
+	}
+
+	maskChannel(ch)
+	c.JSON(http.StatusOK, successResponse(ch))
+}
+
+// DeleteNotificationChannel 删除通知渠道
+// @Summary 删除通知渠道
+// @Tags notifications
+// @Produce json
+// @Param id path string true "通知渠道ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/notifications/channels/{id} [delete]
+func (h *NotificationHandler) DeleteNotificationChannel(c *gin.Context) {
+	ch, ok := h.loadOwnedChannel(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteNotificationChannel(ch.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "删除通知渠道失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"id": ch.ID}))
+}
+
+// TestNotificationChannel 发送一条测试消息
+// @Summary 测试通知渠道
+// @Tags notifications
+// @Produce json
+// @Param id path string true "通知渠道ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/notifications/channels/{id}/test [post]
+func (h *NotificationHandler) TestNotificationChannel(c *gin.Context) {
+	ch, ok := h.loadOwnedChannel(c)
+	if !ok {
+		return
+	}
+
+	err := notify.Send(h.db, ch, notify.Message{
+		Event: models.NotifyEventTest,
+		Title: "序谱通知测试",
+		Text:  "收到这条消息说明通知渠道「" + ch.Name + "」配置正确。",
+	})
+	if err != nil {
+		c.JSON(http.StatusBadGateway, errorResponse("SEND_FAILED", "发送测试消息失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"id": ch.ID, "sent": true}))
+}
+
+// loadOwnedChannel 加载当前用户的通知渠道，失败时已写入响应
+func (h *NotificationHandler) loadOwnedChannel(c *gin.Context) (*models.NotificationChannel, bool) {
+	ch, err := h.db.GetNotificationChannel(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "通知渠道不存在", ""))
+		return nil, false
+	}
+	userID, exists := GetUserID(c)
+	if !exists || ch.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权访问", ""))
+		return nil, false
+	}
+	return ch, true
+}
+
+// applyChannelRequest 把请求写入渠道并校验渠道参数，失败时已写入响应
+// 敏感参数为空或为占位值时保留原值
+func applyChannelRequest(c *gin.Context, ch *models.NotificationChannel, req *NotificationChannelRequest) bool {
+	for _, event := range req.Events {
+		if !models.ValidNotifyEvent(event) {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_EVENT", "不支持的通知事件", event))
+			return false
+		}
+	}
+
+	settings := make(map[string]string, len(req.Settings))
+	for k, v := range req.Settings {
+		settings[k] = v
+	}
+	if ch.Type == req.Type {
+		for k := range sensitiveSettings {
+			if v := settings[k]; (v == "" || v == maskedSetting) && ch.Settings[k] != "" {
+				settings[k] = ch.Settings[k]
+			}
+		}
+	}
+
+	ch.Type = req.Type
+	ch.Name = req.Name
+	if ch.Name == "" {
+		ch.Name = req.Type
+	}
+	ch.Settings = settings
+	ch.Events = req.Events
+	ch.Enabled = req.Enabled == nil || *req.Enabled
+
+	if _, err := notify.NewSender(ch); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_CHANNEL", "通知渠道参数无效", err.Error()))
+		return false
+	}
+	return true
+}
+
+// maskChannel 隐藏响应中的敏感参数
+func maskChannel(ch *models.NotificationChannel) {
+	masked := make(map[string]string, len(ch.Settings))
+	for k, v := range ch.Settings {
+		if sensitiveSettings[k] && v != "" {
+			v = maskedSetting
+		}
+		masked[k] = v
+	}
+	ch.Settings = masked
+}
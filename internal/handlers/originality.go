@@ -0,0 +1,301 @@
+// Package handlers HTTP处理器 - 原创性检查
+package handlers
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/originality"
+)
+
+// maxReferenceUploadBytes 上传的参考文本大小上限
+const maxReferenceUploadBytes = 8 << 20
+
+// OriginalityHandler 原创性检查处理器
+type OriginalityHandler struct {
+	db  db.Database
+	cfg *config.Config
+}
+
+// NewOriginalityHandler 创建原创性检查处理器
+func NewOriginalityHandler(database db.Database) *OriginalityHandler {
+	cfg, err := config.Current()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+	return &OriginalityHandler{db: database, cfg: cfg}
+}
+
+// ListReferences 列出项目的参考文本
+// @Summary 参考文本列表
+// @Description 返回项目上传的参考文本（不含正文）
+// @Tags originality
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/references [get]
+func (h *OriginalityHandler) ListReferences(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+	refs, err := h.db.ListReferenceTexts(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取参考文本失败", err.Error()))
+		return
+	}
+	for i := range refs {
+		refs[i].Content = ""
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"references":           refs,
+		"total":                len(refs),
+		"originality_settings": project.OriginalitySettings,
+	}))
+}
+
+// UploadReference 上传参考文本
+// @Summary 上传参考文本
+// @Description 上传生成的正文不能照搬的文本（如同人转原创时的原作）；支持 multipart 上传 txt 文件（字段 file，可选 title），或 JSON 提交 title 和 content
+// @Tags originality
+// @Accept json,mpfd
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body CreateReferenceTextRequest false "参考文本"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/references [post]
+func (h *OriginalityHandler) UploadReference(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+	title, content, ok := referenceSource(c)
+	if !ok {
+		return
+	}
+
+	userID, _ := GetUserID(c)
+	ref := &models.ReferenceText{
+		ID:        db.GenerateID("reference"),
+		ProjectID: project.ID,
+		UserID:    userID,
+		Title:     strings.TrimSpace(title),
+		Content:   content,
+		WordCount: utf8.RuneCountInString(content),
+	}
+	if err := h.db.SaveReferenceText(ref); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "保存参考文本失败", err.Error()))
+		return
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditCreate,
+		ResourceType: models.AuditResourceProject,
+		ResourceID:   ref.ID,
+		ProjectID:    project.ID,
+		Summary:      "上传原创性参考文本《" + ref.Title + "》",
+	}, nil, nil)
+
+	ref.Content = ""
+	c.JSON(http.StatusCreated, successResponse(ref))
+}
+
+// DeleteReference 删除参考文本
+// @Summary 删除参考文本
+// @Tags originality
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param refId path string true "参考文本ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/references/{refId} [delete]
+func (h *OriginalityHandler) DeleteReference(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+	ref, err := h.db.GetReferenceText(c.Param("refId"))
+	if err != nil || ref.ProjectID != project.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "参考文本不存在", ""))
+		return
+	}
+	if err := h.db.DeleteReferenceText(ref.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "删除参考文本失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"id": ref.ID}))
+}
+
+// UpdateOriginalitySettings 更新项目的原创性检查设置
+// @Summary 更新原创性检查设置
+// @Description threshold 为单章雷同字数占比的上限，为 0 时使用配置中的默认值；disabled 关闭导出前的检查
+// @Tags originality
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body UpdateOriginalitySettingsRequest true "原创性检查设置"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/originality-settings [put]
+func (h *OriginalityHandler) UpdateOriginalitySettings(c *gin.Context) {
+	var req UpdateOriginalitySettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	before := project.OriginalitySettings
+	project.OriginalitySettings = models.OriginalitySettings{
+		Threshold: req.Threshold,
+		Disabled:  req.Disabled,
+	}
+	project.UpdatedAt = time.Now()
+	if err := h.db.SaveProject(project); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存项目失败", err.Error()))
+		return
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditUpdate,
+		ResourceType: models.AuditResourceProject,
+		ResourceID:   project.ID,
+		ProjectID:    project.ID,
+		Summary:      "更新原创性检查设置",
+	}, before, project.OriginalitySettings)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"originality_settings": project.OriginalitySettings,
+	}))
+}
+
+// ScanOriginality 检查正文与参考文本的相似度
+// @Summary 原创性检查
+// @Description 逐章查找与参考文本连续相同的片段，按雷同字数占比计算相似度；任一章超过阈值时 blocked=true，导出会被拒绝
+// @Tags originality
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/originality/scan [post]
+func (h *OriginalityHandler) ScanOriginality(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+	report, err := scanOriginality(h.db, h.cfg.Originality, project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取参考文本失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(report))
+}
+
+// scanOriginality 按项目的参考文本检查全书正文
+func scanOriginality(database db.Database, cfg config.OriginalityConfig, project *models.Project) (*originality.Report, error) {
+	refs, err := database.ListReferenceTexts(project.ID)
+	if err != nil {
+		return nil, err
+	}
+	ngram, threshold := originality.Settings(cfg, project.OriginalitySettings)
+	blueprint := projectBlueprint(database, project)
+	prose := func(ch *models.Chapter) string { return chapterProse(database, blueprint, ch) }
+	return originality.Scan(originality.NewCorpus(refs, ngram), sortedChapters(database, project.ID), prose, threshold), nil
+}
+
+// checkOriginality 导出前的原创性检查：项目有参考文本且有章节超过阈值时返回422，失败时已写入响应
+func checkOriginality(c *gin.Context, database db.Database, project *models.Project) bool {
+	if project.OriginalitySettings.Disabled {
+		return true
+	}
+	cfg, err := config.Current()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+	report, err := scanOriginality(database, cfg.Originality, project)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "原创性检查失败", err.Error()))
+		return false
+	}
+	if !report.Blocked {
+		return true
+	}
+	exceeded := make([]originality.ChapterResult, 0)
+	for _, ch := range report.Chapters {
+		if ch.Exceeded {
+			exceeded = append(exceeded, ch)
+		}
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{
+		"success": false,
+		"error": gin.H{
+			"code":    "ORIGINALITY_EXCEEDED",
+			"message": "正文与参考文本的相似度超过阈值，禁止导出",
+		},
+		"data": gin.H{
+			"threshold":      report.Threshold,
+			"max_similarity": report.MaxSimilarity,
+			"chapters":       exceeded,
+		},
+	})
+	return false
+}
+
+// referenceSource 从上传文件或JSON请求中取参考文本的标题和内容
+func referenceSource(c *gin.Context) (string, string, bool) {
+	if strings.HasPrefix(c.ContentType(), "multipart/") {
+		file, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_FILE", "未找到上传文件", err.Error()))
+			return "", "", false
+		}
+		if file.Size > maxReferenceUploadBytes {
+			c.JSON(http.StatusBadRequest, errorResponse("FILE_TOO_LARGE", "文件不能超过8MB", ""))
+			return "", "", false
+		}
+		src, err := file.Open()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("READ_FAILED", "读取文件失败", err.Error()))
+			return "", "", false
+		}
+		defer src.Close()
+		content, err := io.ReadAll(src)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("READ_FAILED", "读取文件内容失败", err.Error()))
+			return "", "", false
+		}
+		if !utf8.Valid(content) {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_FILE", "文件不是 UTF-8 编码的文本", ""))
+			return "", "", false
+		}
+		if strings.TrimSpace(string(content)) == "" {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_FILE", "文件内容为空", ""))
+			return "", "", false
+		}
+		title := c.PostForm("title")
+		if title == "" {
+			title = strings.TrimSuffix(file.Filename, ".txt")
+		}
+		return title, string(content), true
+	}
+
+	var req CreateReferenceTextRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return "", "", false
+	}
+	if strings.TrimSpace(req.Content) == "" {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "参考文本为空", ""))
+		return "", "", false
+	}
+	if len(req.Content) > maxReferenceUploadBytes {
+		c.JSON(http.StatusBadRequest, errorResponse("FILE_TOO_LARGE", "参考文本不能超过8MB", ""))
+		return "", "", false
+	}
+	return req.Title, req.Content, true
+}
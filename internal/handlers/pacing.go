@@ -0,0 +1,162 @@
+// Package handlers HTTP处理器 - 节奏分析
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/pacing"
+)
+
+// PacingHandler 节奏分析处理器
+type PacingHandler struct {
+	db  db.Database
+	cfg *config.Config
+}
+
+// NewPacingHandler 创建节奏分析处理器
+func NewPacingHandler(database db.Database) *PacingHandler {
+	cfg, err := config.Current()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+
+	return &PacingHandler{
+		db:  database,
+		cfg: cfg,
+	}
+}
+
+// GetPacingReport 获取章节节奏与类型基线的对比
+// @Summary 章节节奏对比
+// @Description 测量项目的章节张力曲线，与类型基线对比并列出偏离最大的章节及可能原因
+// @Tags pacing
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param genre query string false "类型（默认取世界类型）"
+// @Param source query string false "基线来源" Enums(config, aggregate)
+// @Param top query int false "返回偏离章节数" default(5)
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/pacing [get]
+func (h *PacingHandler) GetPacingReport(c *gin.Context) {
+	projectID := c.Param("projectId")
+
+	project, err := h.db.GetProject(projectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+
+	genre := c.Query("genre")
+	if genre == "" && project.WorldID != "" {
+		if world, err := h.db.GetWorld(project.WorldID); err == nil {
+			genre = string(world.Type)
+		}
+	}
+	if genre == "" {
+		genre = pacing.DefaultGenre
+	}
+
+	top, _ := strconv.Atoi(c.DefaultQuery("top", "5"))
+
+	chapters := h.db.ListChaptersByProject(projectID)
+	if len(chapters) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("NO_CHAPTERS", "项目还没有章节", ""))
+		return
+	}
+	metrics := pacing.MeasureCurve(chapters)
+
+	report := &pacing.Report{
+		Genre:    genre,
+		Chapters: metrics,
+	}
+
+	var baseline []float64
+	if c.Query("source") == pacing.SourceAggregate {
+		baseline = h.aggregateBaseline(projectID, genre)
+		if baseline != nil {
+			report.BaselineSource = pacing.SourceAggregate
+		}
+	}
+	if baseline == nil {
+		curve, resolved, err := pacing.ConfigBaseline(&h.cfg.Pacing, genre)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("NO_BASELINE", "缺少类型基线", err.Error()))
+			return
+		}
+		baseline = curve
+		report.Genre = resolved
+		report.BaselineSource = pacing.SourceConfig
+	}
+
+	threshold := h.cfg.Pacing.DeviationThreshold
+	if threshold <= 0 {
+		threshold = 0.3
+	}
+	report.Baseline, report.MeanDeviation, report.Deviations = pacing.Compare(metrics, baseline, threshold, top)
+
+	c.JSON(http.StatusOK, successResponse(report))
+}
+
+// aggregateBaseline 聚合同类型其他项目的张力曲线，项目数不足时返回nil
+func (h *PacingHandler) aggregateBaseline(excludeProjectID, genre string) []float64 {
+	curves := make([][]float64, 0)
+	for _, p := range h.db.ListProjects() {
+		if p.ID == excludeProjectID || p.WorldID == "" {
+			continue
+		}
+		world, err := h.db.GetWorld(p.WorldID)
+		if err != nil || string(world.Type) != genre {
+			continue
+		}
+		chapters := h.db.ListChaptersByProject(p.ID)
+		if len(chapters) < 3 {
+			continue
+		}
+		curves = append(curves, pacing.TensionCurve(pacing.MeasureCurve(chapters)))
+	}
+
+	minProjects := h.cfg.Pacing.MinAggregateProjects
+	if minProjects <= 0 {
+		minProjects = 3
+	}
+	if len(curves) < minProjects {
+		return nil
+	}
+	return pacing.AggregateBaseline(curves)
+}
+
+// GetPlanPacing 分析叙事蓝图章节规划的节奏
+// @Summary 章节规划节奏分析
+// @Description 由冲突强度、场景类型和目标字数计算章节规划的张力曲线，检测中段塌陷、高潮过早、连续低能量章节，并给出调序或换场景类型的建议
+// @Tags pacing
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/pacing/plan [get]
+func (h *PacingHandler) GetPlanPacing(c *gin.Context) {
+	project, err := h.db.GetProject(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	if project.NarrativeID == "" {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "叙事蓝图不存在，请先生成故事规划", ""))
+		return
+	}
+
+	blueprint, err := h.db.GetNarrativeBlueprint(project.NarrativeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "叙事蓝图不存在，请先生成故事规划", ""))
+		return
+	}
+	if len(blueprint.ChapterPlans) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("NO_CHAPTER_PLANS", "叙事蓝图还没有章节规划", ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(pacing.AnalyzePlan(blueprint.ChapterPlans)))
+}
@@ -0,0 +1,93 @@
+// Package handlers HTTP处理器 - 视角策略
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/narrative"
+)
+
+// POVHandler 视角策略处理器
+type POVHandler struct {
+	db db.Database
+}
+
+// NewPOVHandler 创建视角策略处理器
+func NewPOVHandler(database db.Database) *POVHandler {
+	return &POVHandler{db: database}
+}
+
+// GetPOVPolicy 获取蓝图视角策略及不符合策略的场景
+// @Summary 获取视角策略
+// @Description 返回蓝图的视角策略（单一视角/按章轮换/全知），并列出视角角色不符合策略的场景
+// @Tags pov
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/pov-policy [get]
+func (h *POVHandler) GetPOVPolicy(c *gin.Context) {
+	_, blueprint, ok := loadProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"policy":     blueprint.POVPolicy,
+		"violations": narrative.ValidatePOV(blueprint),
+	}))
+}
+
+// UpdatePOVPolicy 设置蓝图视角策略
+// @Summary 设置视角策略
+// @Description 设置蓝图的视角策略；apply 为真时把场景视角改为策略要求的角色，否则只返回不符合策略的场景。mode 为空表示取消策略
+// @Tags pov
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body UpdatePOVPolicyRequest true "视角策略"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/pov-policy [put]
+func (h *POVHandler) UpdatePOVPolicy(c *gin.Context) {
+	var req UpdatePOVPolicyRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	_, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	changed := 0
+	if req.Mode == "" {
+		blueprint.POVPolicy = nil
+	} else {
+		policy := &models.POVPolicy{Mode: req.Mode, Characters: req.Characters}
+		if err := policy.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "视角策略无效", err.Error()))
+			return
+		}
+		if req.Apply {
+			changed = narrative.ApplyPOVPolicy(blueprint, policy)
+		} else {
+			blueprint.POVPolicy = policy
+		}
+	}
+
+	blueprint.UpdatedAt = time.Now()
+	if err := h.db.SaveNarrativeBlueprint(blueprint); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存视角策略失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"policy":         blueprint.POVPolicy,
+		"changed_scenes": changed,
+		"violations":     narrative.ValidatePOV(blueprint),
+	}))
+}
@@ -0,0 +1,65 @@
+// Package handlers HTTP处理器 - 写作统计
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/progress"
+)
+
+// maxStatsDays 写作统计最多列出的天数
+const maxStatsDays = 366
+
+// ProgressHandler 写作统计处理器
+type ProgressHandler struct {
+	db db.Database
+}
+
+// NewProgressHandler 创建写作统计处理器
+func NewProgressHandler(database db.Database) *ProgressHandler {
+	return &ProgressHandler{db: database}
+}
+
+// GetProjectStats 获取项目写作统计
+// @Summary 获取写作统计
+// @Description 每章字数与目标、最近每天的净增字数和完成章节数、已完成与规划章节数，并按最近的日均字数估算完稿日期。字数记录在每次保存章节时更新
+// @Tags projects
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param days query int false "列出最近多少天，默认30"
+// @Param window query int false "按最近多少天计算写作速度，默认14"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/stats [get]
+func (h *ProgressHandler) GetProjectStats(c *gin.Context) {
+	days, ok := positiveQuery(c, "days", progress.DefaultDays)
+	if !ok {
+		return
+	}
+	window, ok := positiveQuery(c, "window", progress.DefaultWindow)
+	if !ok {
+		return
+	}
+	if days > maxStatsDays || window > maxStatsDays {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "统计天数不能超过366天", ""))
+		return
+	}
+	project, err := h.db.GetProject(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权访问", ""))
+		return
+	}
+
+	stats, err := progress.Load(h.db, project, progress.Options{Days: days, Window: window})
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取写作统计失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(stats))
+}
@@ -5,11 +5,13 @@ import (
 	"net/http"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/db"
 	"github.com/xlei/xupu/pkg/orchestrator"
+	"github.com/xlei/xupu/pkg/writer"
 )
 
 // ProjectHandler 项目处理器
@@ -50,59 +52,33 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
+	if !validateCreateProjectRequest(c, &req, userID) {
+		return
+	}
+
 	// 如果没有提供创作参数，创建简单的空项目草稿
 	if req.Params == nil {
-		// 创建空项目
-		project = &models.Project{
-			ID:          db.GenerateID("project"),
-			UserID:      userID,
-			Name:        req.Name,
-			Description: req.Description,
-			Mode:        models.OrchestrationMode(req.Mode),
-			Status:      models.StatusDraft,
-			Progress:    0,
-		}
-
+		project = newDraftProject(&req, userID)
 		if err := db.Get().SaveProject(project); err != nil {
 			c.JSON(http.StatusInternalServerError, errorResponse("CREATE_FAILED", "创建项目失败", err.Error()))
 			return
 		}
 	} else {
-		// 使用 orchestrator 创建完整的AI项目
-		// 构建创作参数
-		params := orchestrator.CreationParams{
-			UserID:       userID,
-			ProjectName:  req.Name,
-			Description:  req.Description,
-			WorldName:    req.Params.WorldName,
-			WorldType:    req.Params.WorldType,
-			WorldTheme:   req.Params.WorldTheme,
-			WorldScale:   req.Params.WorldScale,
-			WorldStyle:   req.Params.WorldStyle,
-			StoryType:    req.Params.StoryType,
-			StoryTheme:   req.Params.Theme,
-			Protagonist:  req.Params.Protagonist,
-			StoryLength:  req.Params.Length,
-			ChapterCount: req.Params.ChapterCount,
-			Structure:    req.Params.Structure,
-			Options: orchestrator.GenerationOptions{
-				SkipWorldBuild:      req.Params.Options.SkipWorldBuild,
-				ExistingWorldID:     req.Params.Options.ExistingWorldID,
-				SkipNarrative:       req.Params.Options.SkipNarrative,
-				ExistingBlueprintID: req.Params.Options.ExistingBlueprintID,
-				GenerateContent:     req.Params.Options.GenerateContent,
-				StartChapter:        req.Params.Options.StartChapter,
-				EndChapter:          req.Params.Options.EndChapter,
-				Style:               req.Params.Options.Style,
-			},
+		if !checkQuota(c, db.Get(), userID) {
+			return
 		}
 
+		// 使用 orchestrator 创建完整的AI项目
+		params := toCreationParams(&req, userID)
+		params.APIKeyID = requestAPIKeyID(c)
+
 		// 创建项目
-		project, err = h.orchestrator.CreateProject(params)
+		project, err = h.orchestrator.WithLogger(requestLogger(c)).CreateProject(params)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, errorResponse("CREATE_FAILED", "创建项目失败", err.Error()))
 			return
 		}
+		recordJob(c, project.ID)
 	}
 
 	c.JSON(http.StatusOK, successResponse(gin.H{
@@ -110,6 +86,97 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	}))
 }
 
+// newDraftProject 按创建请求构造空项目草稿（不含创作参数）
+func newDraftProject(req *CreateProjectRequest, userID string) *models.Project {
+	project := &models.Project{
+		ID:          db.GenerateID("project"),
+		UserID:      userID,
+		Name:        req.Name,
+		Description: req.Description,
+		Mode:        models.OrchestrationMode(req.Mode),
+		Status:      models.StatusDraft,
+		Progress:    0,
+
+		StyleProfileID: req.StyleProfileID,
+		Language:       req.Language,
+	}
+	if req.WordCountTargets != nil {
+		project.WordCountTargets = *req.WordCountTargets
+	}
+	return project
+}
+
+// validateCreateProjectRequest 校验创建项目请求引用的风格档案、节拍表和视角策略，失败时已写入响应
+func validateCreateProjectRequest(c *gin.Context, req *CreateProjectRequest, userID string) bool {
+	if _, err := writer.ResolveStyleProfile(db.Get(), req.StyleProfileID); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "风格档案不存在", err.Error()))
+		return false
+	}
+	if req.Params != nil {
+		if _, ok := resolveBeatSheet(c, db.Get(), req.Params.BeatSheetID, userID); !ok {
+			return false
+		}
+	}
+	if req.Params != nil && req.Params.POVPolicy != nil {
+		if err := req.Params.POVPolicy.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "视角策略无效", err.Error()))
+			return false
+		}
+	}
+	for i := range req.Webhooks {
+		if !validateWebhookRequest(c, &req.Webhooks[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// toCreationParams 把创建项目请求转换为编排器的创作参数，req.Params 不能为空
+func toCreationParams(req *CreateProjectRequest, userID string) orchestrator.CreationParams {
+	params := orchestrator.CreationParams{
+		UserID:       userID,
+		ProjectName:  req.Name,
+		Description:  req.Description,
+		WorldName:    req.Params.WorldName,
+		WorldType:    req.Params.WorldType,
+		WorldTheme:   req.Params.WorldTheme,
+		WorldScale:   req.Params.WorldScale,
+		WorldStyle:   req.Params.WorldStyle,
+		WorldTier:    req.Params.WorldTier,
+		StoryType:    req.Params.StoryType,
+		StoryTheme:   req.Params.Theme,
+		Protagonist:  req.Params.Protagonist,
+		StoryLength:  req.Params.Length,
+		ChapterCount: req.Params.ChapterCount,
+		Structure:    req.Params.Structure,
+		BeatSheetID:  req.Params.BeatSheetID,
+		Genre:        req.Params.Genre,
+		POVPolicy:    req.Params.POVPolicy,
+		Options: orchestrator.GenerationOptions{
+			SkipWorldBuild:      req.Params.Options.SkipWorldBuild,
+			ExistingWorldID:     req.Params.Options.ExistingWorldID,
+			SkipNarrative:       req.Params.Options.SkipNarrative,
+			ExistingBlueprintID: req.Params.Options.ExistingBlueprintID,
+			GenerateContent:     req.Params.Options.GenerateContent,
+			StartChapter:        req.Params.Options.StartChapter,
+			EndChapter:          req.Params.Options.EndChapter,
+			Style:               req.Params.Options.Style,
+			MaxRevisions:        req.Params.Options.MaxRevisions,
+		},
+		StyleProfileID: req.StyleProfileID,
+		Language:       req.Language,
+	}
+	if req.WordCountTargets != nil {
+		params.WordCountTargets = *req.WordCountTargets
+	}
+	for i := range req.Webhooks {
+		var hook models.Webhook
+		applyWebhookRequest(&hook, &req.Webhooks[i])
+		params.Webhooks = append(params.Webhooks, hook)
+	}
+	return params
+}
+
 // ListProjects 列出所有项目
 // @Summary 获取项目列表
 // @Description 获取当前用户的所有AI小说创作项目
@@ -134,9 +201,23 @@ func (h *ProjectHandler) ListProjects(c *gin.Context) {
 	search := c.Query("search")
 
 	// 只查询当前用户的项目
-	projects := db.Get().ListProjectsByUser(userID)
+	filtered := filterProjects(db.Get().ListProjectsByUser(userID), status, sortBy, search)
 
-	// 筛选
+	response := make([]ProjectResponse, 0, len(filtered))
+	for _, p := range filtered {
+		response = append(response, toProjectResponse(p))
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"projects": response,
+		"page":     1,
+		"pageSize": len(filtered),
+		"total":    len(filtered),
+	}))
+}
+
+// filterProjects 按状态（all 表示全部）和关键词筛选项目，按 updated/created/name 排序
+func filterProjects(projects []*models.Project, status, sortBy, search string) []*models.Project {
 	filtered := make([]*models.Project, 0, len(projects))
 	for _, p := range projects {
 		// 状态筛选
@@ -172,18 +253,7 @@ func (h *ProjectHandler) ListProjects(c *gin.Context) {
 			return filtered[i].UpdatedAt.After(filtered[j].UpdatedAt)
 		})
 	}
-
-	response := make([]ProjectResponse, 0, len(filtered))
-	for _, p := range filtered {
-		response = append(response, toProjectResponse(p))
-	}
-
-	c.JSON(http.StatusOK, successResponse(gin.H{
-		"projects": response,
-		"page":     1,
-		"pageSize": len(filtered),
-		"total":    len(filtered),
-	}))
+	return filtered
 }
 
 // GetProject 获取项目详情
@@ -221,9 +291,158 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 	c.JSON(http.StatusOK, successResponse(response))
 }
 
+// UpdateWordCountTargets 更新章节字数目标
+// @Summary 更新章节字数目标
+// @Description 设置项目的每章默认目标字数和单章覆盖，生成后字数偏离目标±20%时自动扩写或精简
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param request body UpdateWordCountTargetsRequest true "字数目标"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{id}/word-count-targets [put]
+func (h *ProjectHandler) UpdateWordCountTargets(c *gin.Context) {
+	id := c.Param("projectId")
+
+	var req UpdateWordCountTargetsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	for chapter, target := range req.Chapters {
+		if chapter <= 0 || target < 0 {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "章节号必须为正数，目标字数不能为负", ""))
+			return
+		}
+	}
+
+	project, err := db.Get().GetProject(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return
+	}
+
+	project.WordCountTargets = models.WordCountTargets{
+		Default:  req.Default,
+		Chapters: req.Chapters,
+	}
+	project.UpdatedAt = time.Now()
+	if err := db.Get().SaveProject(project); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存项目失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"project": toProjectResponse(project),
+	}))
+}
+
+// UpdateStyleProfile 设置项目的写作风格档案
+// @Summary 设置项目风格档案
+// @Description 为项目关联内置预设或自定义风格档案，之后生成的每个场景都会注入该风格；ID为空表示取消
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param request body UpdateProjectStyleProfileRequest true "风格档案ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{id}/style-profile [put]
+func (h *ProjectHandler) UpdateStyleProfile(c *gin.Context) {
+	id := c.Param("projectId")
+
+	var req UpdateProjectStyleProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	project, err := db.Get().GetProject(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return
+	}
+
+	profile, err := writer.ResolveStyleProfile(db.Get(), req.StyleProfileID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "风格档案不存在", err.Error()))
+		return
+	}
+	if profile != nil && !profile.Builtin && profile.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权使用该风格档案", ""))
+		return
+	}
+
+	project.StyleProfileID = req.StyleProfileID
+	project.UpdatedAt = time.Now()
+	if err := db.Get().SaveProject(project); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存项目失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"project":       toProjectResponse(project),
+		"style_profile": profile,
+	}))
+}
+
+// UpdateLanguage 设置项目输出语言
+// @Summary 设置项目输出语言
+// @Description 设置项目的输出语言（zh-CN/zh-TW/en/ja），之后生成的世界设定、蓝图和正文都使用该语言，章节标题等结构性用语随之本地化；已生成的内容不会翻译
+// @Tags projects
+// @Accept json
+// @Produce json
+// @Param id path string true "项目ID"
+// @Param request body UpdateProjectLanguageRequest true "输出语言"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{id}/language [put]
+func (h *ProjectHandler) UpdateLanguage(c *gin.Context) {
+	id := c.Param("projectId")
+
+	var req UpdateProjectLanguageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	project, err := db.Get().GetProject(id)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return
+	}
+
+	project.Language = req.Language
+	project.UpdatedAt = time.Now()
+	if err := db.Get().SaveProject(project); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存项目失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"project": toProjectResponse(project),
+	}))
+}
+
 // DeleteProject 删除项目
 // @Summary 删除项目
-// @Description 删除指定的项目及其所有关联数据
+// @Description 项目连同蓝图和章节移入回收站，30天内可恢复，之后由定时任务彻底清除
 // @Tags projects
 // @Produce json
 // @Param id path string true "项目ID"
@@ -252,11 +471,18 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 		return
 	}
 
-	// TODO: 级联删除相关数据（蓝图、场景等）
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditDelete,
+		ResourceType: models.AuditResourceProject,
+		ResourceID:   project.ID,
+		ProjectID:    project.ID,
+		Summary:      "删除项目 " + project.Name,
+	}, project, nil)
 
 	c.JSON(http.StatusOK, successResponse(gin.H{
 		"deleted_project_id":   project.ID,
 		"deleted_project_name": project.Name,
+		"purge_at":             time.Now().Add(models.TrashRetention),
 	}))
 }
 
@@ -382,3 +608,37 @@ func (h *ProjectHandler) GetProgress(c *gin.Context) {
 
 	c.JSON(http.StatusOK, successResponse(progress))
 }
+
+// GetWarnings 获取项目的降级警告
+// @Summary 获取降级警告
+// @Description 汇总项目世界设定和叙事蓝图上的降级警告，列出LLM失败后使用了兜底（占位）内容的环节
+// @Tags projects
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/warnings [get]
+func (h *ProjectHandler) GetWarnings(c *gin.Context) {
+	database := db.Get()
+	project, err := database.GetProject(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+
+	warnings := make([]models.DegradationWarning, 0)
+	if project.WorldID != "" {
+		if world, err := database.GetWorld(project.WorldID); err == nil {
+			warnings = append(warnings, world.Warnings...)
+		}
+	}
+	if project.NarrativeID != "" {
+		if blueprint, err := database.GetNarrativeBlueprint(project.NarrativeID); err == nil {
+			warnings = append(warnings, blueprint.Warnings...)
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"has_placeholder": len(warnings) > 0,
+		"warnings":        warnings,
+	}))
+}
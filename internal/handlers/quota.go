@@ -0,0 +1,72 @@
+// Package handlers HTTP处理器 - 用户预算
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/quota"
+)
+
+// QuotaHandler 用户预算处理器
+type QuotaHandler struct {
+	db db.Database
+}
+
+// NewQuotaHandler 创建用户预算处理器
+func NewQuotaHandler(database db.Database) *QuotaHandler {
+	return &QuotaHandler{db: database}
+}
+
+// GetMyQuota 获取当前用户本月的预算使用情况
+// @Summary 获取本月剩余额度
+// @Description 本月已用的token和费用、生效的上限、剩余额度和重置时间；state 为 ok/warning/exceeded
+// @Tags quota
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/quota [get]
+func (h *QuotaHandler) GetMyQuota(c *gin.Context) {
+	userID, exists := GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未授权", ""))
+		return
+	}
+
+	status, err := quota.GetStatus(h.db, userID, time.Now())
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取额度失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(status))
+}
+
+// checkQuota 提交生成任务前检查用户本月预算，已用尽时已写入响应
+func checkQuota(c *gin.Context, database db.Database, userID string) bool {
+	err := quota.Check(database, userID)
+	if err == nil {
+		return true
+	}
+	if errors.Is(err, llm.ErrBudgetExceeded) {
+		c.JSON(http.StatusTooManyRequests, errorResponse("QUOTA_EXCEEDED", "本月额度已用尽", err.Error()))
+	} else {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "检查额度失败", err.Error()))
+	}
+	return false
+}
+
+// userBudget 当前用户的月度预算，同步调用LLM的处理器挂到写作器、叙事器等的 WithBudget 上，与后台任务一样按用户限额。
+// 本月额度已用尽时已写入响应；没有登录用户时返回 nil，不限制
+func userBudget(c *gin.Context, database db.Database) (llm.Budget, bool) {
+	userID, exists := GetUserID(c)
+	if !exists || userID == "" {
+		return nil, true
+	}
+	if !checkQuota(c, database, userID) {
+		return nil, false
+	}
+	return quota.For(database, userID), true
+}
@@ -0,0 +1,63 @@
+// Package handlers 用户预算测试
+package handlers
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/quota"
+)
+
+// TestSyncHandlerUserBudget 同步调用LLM的接口按当前用户的月度预算限制：用尽时拒绝，未用尽时用量记入该用户
+func TestSyncHandlerUserBudget(t *testing.T) {
+	database := db.Get()
+	month := quota.Month(time.Now())
+	small, large := 100, 1_000_000_000
+	for _, q := range []*models.UserQuota{
+		{UserID: "budget_over", MonthlyTokens: &small},
+		{UserID: "budget_ok", MonthlyTokens: &large},
+	} {
+		if err := database.SaveUserQuota(q); err != nil {
+			t.Fatalf("保存用户预算失败: %v", err)
+		}
+	}
+	if err := database.AddUserUsage(&models.UserUsageMonth{UserID: "budget_over", Month: month, TotalTokens: 500}); err != nil {
+		t.Fatalf("记录用量失败: %v", err)
+	}
+
+	router := gin.New()
+	router.POST("/worlds", func(c *gin.Context) {
+		c.Set("user_id", c.GetHeader("X-Test-User"))
+	}, NewWorldHandler(nil).CreateWorld)
+
+	createWorld := func(userID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/worlds", strings.NewReader(`{"name":"预算测试","type":"fantasy","scale":"continent","theme":"成长"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Test-User", userID)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	w := createWorld("budget_over")
+	if w.Code != http.StatusTooManyRequests || !strings.Contains(w.Body.String(), "QUOTA_EXCEEDED") {
+		t.Fatalf("额度用尽的用户应被拒绝(429)，实际 %d: %s", w.Code, w.Body.String())
+	}
+	if usage, _ := database.GetUserUsage("budget_over", month); usage.TotalTokens != 500 {
+		t.Errorf("被拒绝的请求不应产生用量，实际累计 %d", usage.TotalTokens)
+	}
+
+	w = createWorld("budget_ok")
+	if w.Code != http.StatusOK {
+		t.Fatalf("额度充足的用户应成功，实际 %d: %s", w.Code, w.Body.String())
+	}
+	if usage, _ := database.GetUserUsage("budget_ok", month); usage.TotalTokens == 0 {
+		t.Error("同步接口的LLM用量应记入当前用户的月度预算")
+	}
+}
@@ -0,0 +1,322 @@
+// Package handlers HTTP处理器 - 连载更新计划
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/compliance"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/release"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// ReleaseHandler 连载更新计划处理器
+type ReleaseHandler struct {
+	db          db.Database
+	chapterRepo *repositories.ChapterRepository
+}
+
+// NewReleaseHandler 创建连载更新计划处理器
+func NewReleaseHandler(database db.Database) *ReleaseHandler {
+	return &ReleaseHandler{db: database, chapterRepo: repositories.NewChapterRepository()}
+}
+
+// GenerateReleasePlan 生成或刷新连载更新计划
+// @Summary 生成连载更新计划
+// @Description 把章节规划切成固定长度的更新单元：已写出的章节在有悬念的段落处切分，未写出的章节按目标字数和关键场景预先规划；已定时和已发布的更新保留原状态
+// @Tags release
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body GenerateReleasePlanRequest false "计划参数"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/release-plan [post]
+func (h *ReleaseHandler) GenerateReleasePlan(c *gin.Context) {
+	var req GenerateReleasePlanRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+	if len(blueprint.ChapterPlans) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("NO_CHAPTER_PLANS", "叙事蓝图还没有章节规划", ""))
+		return
+	}
+
+	plan, err := h.db.GetReleasePlan(project.ID)
+	if err != nil {
+		plan = &models.ReleasePlan{
+			ID:        db.GenerateID("release"),
+			ProjectID: project.ID,
+		}
+	}
+	if req.InstallmentLength > 0 {
+		plan.InstallmentLength = req.InstallmentLength
+	}
+	if plan.InstallmentLength <= 0 {
+		plan.InstallmentLength = release.DefaultInstallmentLength
+	}
+
+	chapters := h.db.ListChaptersByProject(project.ID)
+	plan.Installments = release.Plan(blueprint.ChapterPlans, chapters, plan.InstallmentLength, plan.Installments)
+	if err := h.db.SaveReleasePlan(plan); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存更新计划失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(plan))
+}
+
+// GetReleasePlan 获取连载更新计划
+// @Summary 获取连载更新计划
+// @Tags release
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param status query string false "按发布状态过滤" Enums(planned, ready, scheduled, published)
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/release-plan [get]
+func (h *ReleaseHandler) GetReleasePlan(c *gin.Context) {
+	plan, err := h.db.GetReleasePlan(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "更新计划不存在，请先生成", ""))
+		return
+	}
+
+	if status := c.Query("status"); status != "" {
+		filtered := make([]models.Installment, 0)
+		for _, inst := range plan.Installments {
+			if inst.Status == status {
+				filtered = append(filtered, inst)
+			}
+		}
+		plan.Installments = filtered
+	}
+
+	c.JSON(http.StatusOK, successResponse(plan))
+}
+
+// GetInstallmentText 获取一次更新的正文
+// @Summary 获取更新正文
+// @Tags release
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param seq path int true "更新序号"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/release-plan/installments/{seq} [get]
+func (h *ReleaseHandler) GetInstallmentText(c *gin.Context) {
+	plan, inst, ok := h.loadInstallment(c)
+	if !ok {
+		return
+	}
+
+	text := ""
+	for _, ch := range h.db.ListChaptersByProject(plan.ProjectID) {
+		if ch.ChapterNum == inst.Chapter {
+			text = release.Text(ch.Content, *inst)
+			break
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"installment": inst,
+		"text":        text,
+	}))
+}
+
+// UpdateInstallment 更新一次更新的发布状态
+// @Summary 更新发布状态
+// @Description 标记更新单元为已定时或已发布；标记为已发布且未指定时间时记录当前时间。
+// @Description 定时或发布前按项目的合规策略集检查正文，有必须处理的违规时返回422；项目开启自动改写时先改写违规段落并重新切分
+// @Tags release
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param seq path int true "更新序号"
+// @Param request body UpdateInstallmentRequest true "发布状态"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/release-plan/installments/{seq} [patch]
+func (h *ReleaseHandler) UpdateInstallment(c *gin.Context) {
+	var req UpdateInstallmentRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	if !models.ValidInstallmentStatus(req.Status) {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_STATUS", "无效的发布状态", req.Status))
+		return
+	}
+
+	project, err := h.db.GetProject(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return
+	}
+
+	plan, inst, ok := h.loadInstallment(c)
+	if !ok {
+		return
+	}
+
+	// 定时或发布前检查内容合规
+	if req.Status == models.InstallmentScheduled || req.Status == models.InstallmentPublished {
+		if inst, ok = h.checkCompliance(c, project, plan, inst); !ok {
+			return
+		}
+	}
+
+	before := *inst
+	inst.Status = req.Status
+	switch req.Status {
+	case models.InstallmentScheduled:
+		inst.ScheduledAt = req.ScheduledAt
+	case models.InstallmentPublished:
+		publishedAt := time.Now()
+		if req.PublishedAt != nil {
+			publishedAt = *req.PublishedAt
+		}
+		inst.PublishedAt = &publishedAt
+	default:
+		inst.ScheduledAt = nil
+		inst.PublishedAt = nil
+	}
+
+	if err := h.db.SaveReleasePlan(plan); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存更新计划失败", err.Error()))
+		return
+	}
+	action := models.AuditUpdate
+	if inst.Status == models.InstallmentPublished {
+		action = models.AuditPublish
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       action,
+		ResourceType: models.AuditResourceRelease,
+		ResourceID:   plan.ID,
+		ProjectID:    project.ID,
+		Summary:      fmt.Sprintf("更新 %d（第%d章）%s → %s", inst.Sequence, inst.Chapter, before.Status, inst.Status),
+	}, &before, inst)
+
+	c.JSON(http.StatusOK, successResponse(inst))
+}
+
+// checkCompliance 检查更新正文的合规性；有必须处理的违规时，开启自动改写则改写章节并重新切分更新计划，
+// 否则返回422。返回重新切分后对应的更新单元
+func (h *ReleaseHandler) checkCompliance(c *gin.Context, project *models.Project, plan *models.ReleasePlan, inst *models.Installment) (*models.Installment, bool) {
+	cfg, err := config.Current()
+	if err != nil {
+		cfg = &config.Config{}
+	}
+	if len(cfg.Compliance.Policies) == 0 {
+		return inst, true
+	}
+	scanner, err := compliance.Load(cfg.Compliance, project.ComplianceSettings.Policy)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_POLICY", "合规策略集无效", err.Error()))
+		return nil, false
+	}
+	chapter, err := h.db.GetChapterByNum(project.ID, inst.Chapter)
+	if err != nil {
+		return inst, true
+	}
+
+	violations := scanner.Scan(inst.Chapter, release.Text(chapter.Content, *inst))
+	if !compliance.Blocking(violations) {
+		return inst, true
+	}
+	if !project.ComplianceSettings.AutoSanitize {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"success": false,
+			"error": gin.H{
+				"code":    "COMPLIANCE_VIOLATION",
+				"message": "更新正文存在必须处理的违规内容",
+			},
+			"data": gin.H{"violations": violations},
+		})
+		return nil, false
+	}
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return nil, false
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化写作器失败", err.Error()))
+		return nil, false
+	}
+	w = w.WithBudget(budget).WithLanguage(project.Language)
+	_, remaining, err := sanitizeChapter(c, h.db, h.chapterRepo, w, scanner, &models.NarrativeBlueprint{}, chapter)
+	if err == repositories.ErrChapterVersionConflict {
+		c.JSON(http.StatusConflict, errorResponse("VERSION_CONFLICT", "章节已被修改", "请刷新后重试"))
+		return nil, false
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SANITIZE_FAILED", "合规改写失败", err.Error()))
+		return nil, false
+	}
+	if compliance.Blocking(remaining) {
+		c.JSON(http.StatusUnprocessableEntity, gin.H{
+			"success": false,
+			"error": gin.H{
+				"code":    "COMPLIANCE_VIOLATION",
+				"message": "自动改写后仍存在必须处理的违规内容",
+			},
+			"data": gin.H{"violations": remaining},
+		})
+		return nil, false
+	}
+
+	// 正文改动后切分位置会变化，按原计划重新切分并保留发布状态
+	blueprint, err := h.db.GetNarrativeBlueprint(project.NarrativeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "蓝图不存在", ""))
+		return nil, false
+	}
+	chapterNum, part := inst.Chapter, inst.Part
+	plan.Installments = release.Plan(blueprint.ChapterPlans, h.db.ListChaptersByProject(project.ID), plan.InstallmentLength, plan.Installments)
+	for i := range plan.Installments {
+		if plan.Installments[i].Chapter == chapterNum && plan.Installments[i].Part == part {
+			return &plan.Installments[i], true
+		}
+	}
+	c.JSON(http.StatusConflict, errorResponse("PLAN_CHANGED", "改写后该更新已不存在，请重新查看更新计划", ""))
+	return nil, false
+}
+
+// loadInstallment 加载项目更新计划及路径中的更新单元
+func (h *ReleaseHandler) loadInstallment(c *gin.Context) (*models.ReleasePlan, *models.Installment, bool) {
+	seq, err := strconv.Atoi(c.Param("seq"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效的更新序号", c.Param("seq")))
+		return nil, nil, false
+	}
+	plan, err := h.db.GetReleasePlan(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "更新计划不存在，请先生成", ""))
+		return nil, nil, false
+	}
+	inst := plan.FindInstallment(seq)
+	if inst == nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "更新不存在", ""))
+		return nil, nil, false
+	}
+	return plan, inst, true
+}
@@ -0,0 +1,261 @@
+// Package handlers HTTP处理器 - 感情线节拍
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/narrative"
+	"github.com/xlei/xupu/pkg/romance"
+)
+
+// RomanceHandler 感情线节拍处理器
+type RomanceHandler struct {
+	db db.Database
+}
+
+// NewRomanceHandler 创建感情线节拍处理器
+func NewRomanceHandler(database db.Database) *RomanceHandler {
+	return &RomanceHandler{db: database}
+}
+
+// ListRomanceArcs 获取感情线及节奏问题
+// @Summary 获取感情线
+// @Description 返回蓝图中的感情线节拍规划，以及顺序颠倒、进展过快、长期停滞等节奏问题
+// @Tags romance
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/romance [get]
+func (h *RomanceHandler) ListRomanceArcs(c *gin.Context) {
+	_, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	arcs := blueprint.RomanceArcs
+	if arcs == nil {
+		arcs = []models.RomanceArc{}
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"arcs":   arcs,
+		"issues": romance.ValidateAll(arcs, len(blueprint.ChapterPlans)),
+	}))
+}
+
+// CreateRomanceArc 为一对角色创建感情线
+// @Summary 创建感情线
+// @Description 为世界中的两个角色创建感情线；未给出节拍时根据两人现有关系和章节规划自动安排
+// @Tags romance
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body CreateRomanceArcRequest true "感情线"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/romance [post]
+func (h *RomanceHandler) CreateRomanceArc(c *gin.Context) {
+	var req CreateRomanceArcRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	req.CharacterA = strings.TrimSpace(req.CharacterA)
+	req.CharacterB = strings.TrimSpace(req.CharacterB)
+	if req.CharacterA == req.CharacterB {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "感情线需要两个不同的角色", ""))
+		return
+	}
+	if t := unknownBeatType(req.Beats); t != "" {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "未知的节拍类型", t))
+		return
+	}
+
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+	for i := range blueprint.RomanceArcs {
+		if arc := &blueprint.RomanceArcs[i]; arc.Involves([]string{req.CharacterA, req.CharacterB}) {
+			c.JSON(http.StatusConflict, errorResponse("CONFLICT", "这对角色已有感情线", arc.ID))
+			return
+		}
+	}
+
+	chars := make(map[string]*models.Character)
+	for _, ch := range h.db.ListCharactersByWorld(blueprint.WorldID) {
+		if ch != nil {
+			chars[ch.Name] = ch
+		}
+	}
+	a, b := chars[req.CharacterA], chars[req.CharacterB]
+	if a == nil || b == nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "角色不存在", fmt.Sprintf("%s、%s须为世界中的角色", req.CharacterA, req.CharacterB)))
+		return
+	}
+
+	arc := &models.RomanceArc{
+		CharacterA: req.CharacterA,
+		CharacterB: req.CharacterB,
+		Dynamic:    req.Dynamic,
+		Beats:      req.Beats,
+	}
+	if len(arc.Beats) == 0 {
+		budget, ok := userBudget(c, h.db)
+		if !ok {
+			return
+		}
+		engine, err := narrative.New()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化叙事器失败", err.Error()))
+			return
+		}
+		arc, err = engine.WithBudget(budget).WithLogger(requestLogger(c)).WithLanguage(project.Language).PlanRomanceArc(blueprint, narrative.RomanceParams{
+			CharacterA:   req.CharacterA,
+			CharacterB:   req.CharacterB,
+			Dynamic:      req.Dynamic,
+			Relationship: describeRelationship(a, b),
+		})
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "规划感情线失败", err.Error()))
+			return
+		}
+	}
+	arc.ID = db.GenerateID("romance")
+
+	blueprint.RomanceArcs = append(blueprint.RomanceArcs, *arc)
+	blueprint.UpdatedAt = time.Now()
+	if err := h.db.SaveNarrativeBlueprint(blueprint); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存蓝图失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"arc":    arc,
+		"issues": romance.Validate(arc, len(blueprint.ChapterPlans)),
+	}))
+}
+
+// UpdateRomanceArc 修改感情线的节拍
+// @Summary 修改感情线
+// @Tags romance
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param arcId path string true "感情线ID"
+// @Param request body UpdateRomanceArcRequest true "节拍"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/romance/{arcId} [put]
+func (h *RomanceHandler) UpdateRomanceArc(c *gin.Context) {
+	var req UpdateRomanceArcRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	if t := unknownBeatType(req.Beats); t != "" {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "未知的节拍类型", t))
+		return
+	}
+
+	_, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+	index := findRomanceArc(blueprint, c.Param("arcId"))
+	if index < 0 {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "感情线不存在", ""))
+		return
+	}
+
+	arc := &blueprint.RomanceArcs[index]
+	arc.Beats = req.Beats
+	if req.Dynamic != "" {
+		arc.Dynamic = req.Dynamic
+	}
+	blueprint.UpdatedAt = time.Now()
+	if err := h.db.SaveNarrativeBlueprint(blueprint); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存蓝图失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"arc":    arc,
+		"issues": romance.Validate(arc, len(blueprint.ChapterPlans)),
+	}))
+}
+
+// DeleteRomanceArc 删除感情线
+// @Summary 删除感情线
+// @Tags romance
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param arcId path string true "感情线ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/romance/{arcId} [delete]
+func (h *RomanceHandler) DeleteRomanceArc(c *gin.Context) {
+	_, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+	index := findRomanceArc(blueprint, c.Param("arcId"))
+	if index < 0 {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "感情线不存在", ""))
+		return
+	}
+
+	id := blueprint.RomanceArcs[index].ID
+	blueprint.RomanceArcs = append(blueprint.RomanceArcs[:index], blueprint.RomanceArcs[index+1:]...)
+	blueprint.UpdatedAt = time.Now()
+	if err := h.db.SaveNarrativeBlueprint(blueprint); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "保存蓝图失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"deleted": id,
+	}))
+}
+
+// findRomanceArc 感情线在蓝图中的下标，不存在时返回 -1
+func findRomanceArc(blueprint *models.NarrativeBlueprint, id string) int {
+	for i := range blueprint.RomanceArcs {
+		if blueprint.RomanceArcs[i].ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// unknownBeatType 节拍中第一个未知的类型，都合法时返回空
+func unknownBeatType(beats []models.RomanceBeat) string {
+	for _, b := range beats {
+		if _, ok := models.RomanceBeatNames[b.Type]; !ok {
+			return string(b.Type)
+		}
+	}
+	return ""
+}
+
+// describeRelationship 描述两个角色在叙事档案中的现有关系
+func describeRelationship(a, b *models.Character) string {
+	parts := make([]string, 0, 2)
+	for _, pair := range [][2]*models.Character{{a, b}, {b, a}} {
+		from, to := pair[0], pair[1]
+		for key, rel := range from.NarrativeProfile.Relationships {
+			if rel == nil || (key != to.ID && key != to.Name && rel.CharacterID != to.ID) {
+				continue
+			}
+			desc := fmt.Sprintf("%s对%s：好感%d，信任%d", from.Name, to.Name, rel.Emotion, rel.TrustLevel)
+			if rel.Attitude != "" {
+				desc += "，表面" + rel.Attitude
+			}
+			parts = append(parts, desc)
+			break
+		}
+	}
+	return strings.Join(parts, "；")
+}
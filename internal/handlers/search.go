@@ -0,0 +1,87 @@
+// Package handlers HTTP处理器 - 项目全文检索
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/search"
+)
+
+// SearchHandler 全文检索处理器
+type SearchHandler struct {
+	db db.Database
+}
+
+// NewSearchHandler 创建全文检索处理器
+func NewSearchHandler(database db.Database) *SearchHandler {
+	return &SearchHandler{db: database}
+}
+
+// SearchProject 检索项目内容
+// @Summary 项目全文检索
+// @Description 在章节正文、场景正文、世界设定实体和角色档案中检索，多个词（空白分隔，双引号表示短语）须同时出现；返回带类型和高亮片段的命中
+// @Tags search
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param q query string true "检索词"
+// @Param kind query string false "对象类型，逗号分隔" Enums(chapter, scene, world, character)
+// @Param limit query int false "返回数量，默认50，最多200"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/search [get]
+func (h *SearchHandler) SearchProject(c *gin.Context) {
+	query := strings.TrimSpace(c.Query("q"))
+	if len(search.ParseQuery(query)) == 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "缺少检索词", ""))
+		return
+	}
+
+	opts := search.Options{}
+	if kinds := c.Query("kind"); kinds != "" {
+		for _, k := range strings.Split(kinds, ",") {
+			kind := search.Kind(strings.TrimSpace(k))
+			if !validSearchKind(kind) {
+				c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "不支持的对象类型", string(kind)))
+				return
+			}
+			opts.Kinds = append(opts.Kinds, kind)
+		}
+	}
+	if limit := c.Query("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n <= 0 || n > 200 {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "limit 应为1-200的整数", limit))
+			return
+		}
+		opts.Limit = n
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	docs := search.ProjectDocuments(h.db, project)
+	hits, total := search.Search(docs, query, opts)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"query":    query,
+		"terms":    search.ParseQuery(query),
+		"hits":     hits,
+		"total":    total,
+		"searched": len(docs),
+	}))
+}
+
+// validSearchKind 是否为支持的检索对象类型
+func validSearchKind(kind search.Kind) bool {
+	for _, k := range search.Kinds {
+		if k == kind {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,136 @@
+// Package handlers HTTP处理器 - 文风指标
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/stylemetrics"
+)
+
+// StyleMetricsHandler 文风指标处理器
+type StyleMetricsHandler struct {
+	db          db.Database
+	chapterRepo *repositories.ChapterRepository
+}
+
+// NewStyleMetricsHandler 创建文风指标处理器
+func NewStyleMetricsHandler(database db.Database) *StyleMetricsHandler {
+	return &StyleMetricsHandler{db: database, chapterRepo: repositories.NewChapterRepository()}
+}
+
+// GetStyleTrend 获取文风趋势报告
+// @Summary 获取文风趋势报告
+// @Description 汇总各章的句长分布、对话占比、副词密度、重复短语和叙述人称，标出明显偏离其余各章的章节；正文在统计后改动过的章节临时重新统计（stale=true），不写入记录
+// @Tags style
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param z query number false "漂移阈值（标准差倍数），默认2"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/style-metrics [get]
+func (h *StyleMetricsHandler) GetStyleTrend(c *gin.Context) {
+	project, blueprint, ok := h.loadOwnedProject(c)
+	if !ok {
+		return
+	}
+	z := stylemetrics.DefaultDriftZ
+	if v := c.Query("z"); v != "" {
+		parsed, err := strconv.ParseFloat(v, 64)
+		if err != nil || parsed <= 0 {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "z 参数无效", v))
+			return
+		}
+		z = parsed
+	}
+
+	points := make([]stylemetrics.ChapterPoint, 0)
+	for _, ch := range sortedChapters(h.db, project.ID) {
+		prose := chapterProse(h.db, blueprint, ch)
+		if strings.TrimSpace(prose) == "" {
+			continue
+		}
+		metrics, stale := stylemetrics.ForChapter(ch, prose)
+		points = append(points, stylemetrics.ChapterPoint{
+			ChapterNum: ch.ChapterNum,
+			Title:      ch.Title,
+			Stale:      stale,
+			Metrics:    metrics,
+		})
+	}
+
+	c.JSON(http.StatusOK, successResponse(stylemetrics.Build(points, z)))
+}
+
+// AnalyzeStyleMetrics 重新统计章节的文风指标
+// @Summary 统计文风指标
+// @Description 按当前正文重新统计章节的文风指标并写入章节记录；章节生成后会自动统计，手动修改正文后使用
+// @Tags style
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body AnalyzeStyleMetricsRequest false "章节范围"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/style-metrics/analyze [post]
+func (h *StyleMetricsHandler) AnalyzeStyleMetrics(c *gin.Context) {
+	var req AnalyzeStyleMetricsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+	project, blueprint, ok := h.loadOwnedProject(c)
+	if !ok {
+		return
+	}
+
+	wanted := make(map[int]bool, len(req.Chapters))
+	for _, n := range req.Chapters {
+		wanted[n] = true
+	}
+
+	results := make([]gin.H, 0)
+	points := make([]stylemetrics.ChapterPoint, 0)
+	for _, ch := range sortedChapters(h.db, project.ID) {
+		prose := chapterProse(h.db, blueprint, ch)
+		if strings.TrimSpace(prose) == "" {
+			continue
+		}
+		if len(wanted) > 0 && !wanted[ch.ChapterNum] {
+			metrics, stale := stylemetrics.ForChapter(ch, prose)
+			points = append(points, stylemetrics.ChapterPoint{ChapterNum: ch.ChapterNum, Title: ch.Title, Stale: stale, Metrics: metrics})
+			continue
+		}
+
+		ch.StyleMetrics = stylemetrics.Measure(prose)
+		points = append(points, stylemetrics.ChapterPoint{ChapterNum: ch.ChapterNum, Title: ch.Title, Metrics: ch.StyleMetrics})
+		if err := h.chapterRepo.UpdateColumns(c, ch, "style_metrics"); err != nil {
+			results = append(results, gin.H{"chapter_num": ch.ChapterNum, "error": err.Error()})
+			continue
+		}
+		results = append(results, gin.H{
+			"chapter_id":    ch.ID,
+			"chapter_num":   ch.ChapterNum,
+			"style_metrics": ch.StyleMetrics,
+		})
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"chapters": results,
+		"report":   stylemetrics.Build(points, stylemetrics.DefaultDriftZ),
+	}))
+}
+
+// loadOwnedProject 加载当前用户的项目及其蓝图（没有蓝图时为空蓝图），失败时已写入响应
+func (h *StyleMetricsHandler) loadOwnedProject(c *gin.Context) (*models.Project, *models.NarrativeBlueprint, bool) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return nil, nil, false
+	}
+	return project, projectBlueprint(h.db, project), true
+}
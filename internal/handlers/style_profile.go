@@ -0,0 +1,227 @@
+// Package handlers HTTP处理器 - 写作风格档案
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// StyleProfileHandler 写作风格档案处理器
+type StyleProfileHandler struct {
+	db db.Database
+}
+
+// NewStyleProfileHandler 创建写作风格档案处理器
+func NewStyleProfileHandler(database db.Database) *StyleProfileHandler {
+	return &StyleProfileHandler{db: database}
+}
+
+// ListStyleProfiles 列出风格档案
+// @Summary 获取风格档案列表
+// @Description 返回内置预设（网文爽文、文青、硬科幻、悬疑）以及当前用户的自定义风格档案
+// @Tags style-profiles
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/style-profiles [get]
+func (h *StyleProfileHandler) ListStyleProfiles(c *gin.Context) {
+	userID, exists := GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未授权", ""))
+		return
+	}
+
+	custom, err := h.db.ListStyleProfiles(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取风格档案失败", err.Error()))
+		return
+	}
+
+	profiles := make([]*models.StyleProfile, 0, len(writer.BuiltinStyleProfiles)+len(custom))
+	profiles = append(profiles, writer.BuiltinStyleProfiles...)
+	for i := range custom {
+		profiles = append(profiles, &custom[i])
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"profiles": profiles,
+		"total":    len(profiles),
+	}))
+}
+
+// GetStyleProfile 获取风格档案详情
+// @Summary 获取风格档案
+// @Tags style-profiles
+// @Produce json
+// @Param id path string true "风格档案ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/style-profiles/{id} [get]
+func (h *StyleProfileHandler) GetStyleProfile(c *gin.Context) {
+	profile, ok := h.loadProfile(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"profile": profile,
+	}))
+}
+
+// CreateStyleProfile 创建自定义风格档案
+// @Summary 创建风格档案
+// @Tags style-profiles
+// @Accept json
+// @Produce json
+// @Param request body StyleProfileRequest true "风格档案"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/style-profiles [post]
+func (h *StyleProfileHandler) CreateStyleProfile(c *gin.Context) {
+	var req StyleProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	userID, exists := GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未授权", ""))
+		return
+	}
+
+	profile := &models.StyleProfile{
+		ID:        db.GenerateID("style"),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	applyStyleProfileRequest(profile, &req)
+
+	if err := h.db.SaveStyleProfile(profile); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("CREATE_FAILED", "创建风格档案失败", err.Error()))
+		return
+	}
+	auditStyleProfile(c, models.AuditCreate, nil, profile)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"profile": profile,
+	}))
+}
+
+// UpdateStyleProfile 更新自定义风格档案
+// @Summary 更新风格档案
+// @Description 内置预设只读，不能修改
+// @Tags style-profiles
+// @Accept json
+// @Produce json
+// @Param id path string true "风格档案ID"
+// @Param request body StyleProfileRequest true "风格档案"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/style-profiles/{id} [put]
+func (h *StyleProfileHandler) UpdateStyleProfile(c *gin.Context) {
+	var req StyleProfileRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	profile, ok := h.loadOwnedProfile(c)
+	if !ok {
+		return
+	}
+
+	before := *profile
+	applyStyleProfileRequest(profile, &req)
+	if err := h.db.SaveStyleProfile(profile); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存风格档案失败", err.Error()))
+		return
+	}
+	auditStyleProfile(c, models.AuditUpdate, &before, profile)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"profile": profile,
+	}))
+}
+
+// DeleteStyleProfile 删除自定义风格档案
+// @Summary 删除风格档案
+// @Description 内置预设只读，不能删除
+// @Tags style-profiles
+// @Produce json
+// @Param id path string true "风格档案ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/style-profiles/{id} [delete]
+func (h *StyleProfileHandler) DeleteStyleProfile(c *gin.Context) {
+	profile, ok := h.loadOwnedProfile(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteStyleProfile(profile.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "删除风格档案失败", err.Error()))
+		return
+	}
+	auditStyleProfile(c, models.AuditDelete, profile, nil)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"message": "风格档案已删除",
+	}))
+}
+
+// loadProfile 按路径参数加载风格档案（内置或当前用户的），失败时已写入响应
+func (h *StyleProfileHandler) loadProfile(c *gin.Context) (*models.StyleProfile, bool) {
+	profile, err := writer.ResolveStyleProfile(h.db, c.Param("id"))
+	if err != nil || profile == nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "风格档案不存在", ""))
+		return nil, false
+	}
+
+	userID, exists := GetUserID(c)
+	if !profile.Builtin && (!exists || profile.UserID != userID) {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权访问", ""))
+		return nil, false
+	}
+	return profile, true
+}
+
+// loadOwnedProfile 加载可修改的风格档案，内置预设返回403
+func (h *StyleProfileHandler) loadOwnedProfile(c *gin.Context) (*models.StyleProfile, bool) {
+	profile, ok := h.loadProfile(c)
+	if !ok {
+		return nil, false
+	}
+	if profile.Builtin {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "内置风格预设不可修改", ""))
+		return nil, false
+	}
+	return profile, true
+}
+
+// applyStyleProfileRequest 将请求字段写入风格档案
+func applyStyleProfileRequest(profile *models.StyleProfile, req *StyleProfileRequest) {
+	profile.Name = req.Name
+	profile.Description = req.Description
+	profile.NarrativeDistance = req.NarrativeDistance
+	profile.SentenceRhythm = req.SentenceRhythm
+	profile.VocabularyLevel = req.VocabularyLevel
+	profile.Tense = req.Tense
+	profile.POVConvention = req.POVConvention
+	profile.BannedPhrases = req.BannedPhrases
+	profile.UpdatedAt = time.Now()
+}
+
+// auditStyleProfile 记录风格档案变更
+func auditStyleProfile(c *gin.Context, action string, before, after *models.StyleProfile) {
+	profile := after
+	if profile == nil {
+		profile = before
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       action,
+		ResourceType: models.AuditResourceStyle,
+		ResourceID:   profile.ID,
+		Summary:      "风格档案 " + profile.Name,
+	}, before, after)
+}
@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
 	"github.com/xlei/xupu/pkg/orchestrator"
 	"github.com/xlei/xupu/pkg/scheduler"
 )
@@ -42,6 +44,8 @@ type SchedulerStatsResponse struct {
 	PendingTasks   int    `json:"pending_tasks"`
 	ActiveWorkers  int    `json:"active_workers"`
 	QueuedTasks    int    `json:"queued_tasks"`
+	LLMInFlight    int    `json:"llm_in_flight"`    // 正在进行的LLM请求数
+	LLMConcurrency int    `json:"llm_concurrency"`  // LLM并发上限，0表示不限制
 }
 
 // CreateAsyncProject 创建异步项目
@@ -54,6 +58,12 @@ type SchedulerStatsResponse struct {
 // @Success 200 {object} APIResponse
 // @Router /api/v1/tasks/project [post]
 func (h *TaskHandler) CreateAsyncProject(c *gin.Context) {
+	userID, exists := GetUserID(c)
+	if !exists {
+		c.JSON(http.StatusUnauthorized, errorResponse("UNAUTHORIZED", "未授权", ""))
+		return
+	}
+
 	var req CreateProjectRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
@@ -64,17 +74,21 @@ func (h *TaskHandler) CreateAsyncProject(c *gin.Context) {
 	params := orchestrator.CreationParams{
 		ProjectName: req.Name,
 		Description: req.Description,
+		UserID:      userID,
 		WorldName:   req.Params.WorldName,
 		WorldType:   req.Params.WorldType,
 		WorldTheme:  req.Params.WorldTheme,
 		WorldScale:  req.Params.WorldScale,
 		WorldStyle:  req.Params.WorldStyle,
+		WorldTier:   req.Params.WorldTier,
 		StoryType:   req.Params.StoryType,
 		StoryTheme:  req.Params.Theme,
 		Protagonist: req.Params.Protagonist,
 		StoryLength: req.Params.Length,
 		ChapterCount: req.Params.ChapterCount,
 		Structure:   req.Params.Structure,
+		POVPolicy:   req.Params.POVPolicy,
+		Language:    req.Language,
 		Options: orchestrator.GenerationOptions{
 			SkipWorldBuild:      req.Params.Options.SkipWorldBuild,
 			ExistingWorldID:     req.Params.Options.ExistingWorldID,
@@ -84,6 +98,7 @@ func (h *TaskHandler) CreateAsyncProject(c *gin.Context) {
 			StartChapter:        req.Params.Options.StartChapter,
 			EndChapter:          req.Params.Options.EndChapter,
 			Style:               req.Params.Options.Style,
+			MaxRevisions:        req.Params.Options.MaxRevisions,
 		},
 	}
 
@@ -128,6 +143,47 @@ func (h *TaskHandler) GetTaskStatus(c *gin.Context) {
 	c.JSON(http.StatusOK, successResponse(toTaskStatusResponse(task)))
 }
 
+// GetTaskLogs 获取任务日志
+// @Summary 获取任务日志
+// @Description 获取异步任务执行过程中 LLM、叙事器、世界设定器等模块输出的日志（内存缓冲，服务重启后丢失）
+// @Tags tasks
+// @Produce json
+// @Param id path string true "任务ID"
+// @Param level query string false "最低日志级别 debug/info/warn/error"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/tasks/{id}/logs [get]
+func (h *TaskHandler) GetTaskLogs(c *gin.Context) {
+	taskID := c.Param("id")
+
+	task, err := orchestrator.GetTask(taskID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "任务不存在", ""))
+		return
+	}
+	userID, exists := GetUserID(c)
+	if owner := taskOwner(task); !exists || owner == "" || owner != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权访问", ""))
+		return
+	}
+
+	respondTraceLogs(c, taskID)
+}
+
+// taskOwner 任务所属用户：创作任务取提交时的用户，其余取关联项目的所有者
+func taskOwner(task *scheduler.Task) string {
+	if params, ok := task.Params.(orchestrator.CreationParams); ok && params.UserID != "" {
+		return params.UserID
+	}
+	if task.ProjectID == "" {
+		return ""
+	}
+	project, err := db.Get().GetProject(task.ProjectID)
+	if err != nil {
+		return ""
+	}
+	return project.UserID
+}
+
 // CancelTask 取消任务
 // @Summary 取消任务
 // @Description 取消指定任务的执行
@@ -182,6 +238,7 @@ func (h *TaskHandler) PauseTask(c *gin.Context) {
 func (h *TaskHandler) GetSchedulerStats(c *gin.Context) {
 	stats := orchestrator.GetSchedulerStats()
 	sched := orchestrator.GetScheduler()
+	inFlight, concurrency := llm.ConcurrencyStats()
 
 	response := SchedulerStatsResponse{
 		TotalTasks:     stats.TotalTasks,
@@ -190,6 +247,8 @@ func (h *TaskHandler) GetSchedulerStats(c *gin.Context) {
 		CancelledTasks: stats.CancelledTasks,
 		ActiveWorkers:  sched.GetActiveWorkers(),
 		QueuedTasks:    sched.GetQueueSize(),
+		LLMInFlight:    inFlight,
+		LLMConcurrency: concurrency,
 	}
 
 	c.JSON(http.StatusOK, successResponse(response))
@@ -0,0 +1,125 @@
+// Package handlers HTTP处理器 - 主题覆盖
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/theme"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// ThemeHandler 主题覆盖处理器
+type ThemeHandler struct {
+	db          db.Database
+	chapterRepo *repositories.ChapterRepository
+}
+
+// NewThemeHandler 创建主题覆盖处理器
+func NewThemeHandler(database db.Database) *ThemeHandler {
+	return &ThemeHandler{db: database, chapterRepo: repositories.NewChapterRepository()}
+}
+
+// GetThemeCoverage 获取主题覆盖报告
+// @Summary 获取主题覆盖报告
+// @Description 汇总各章正文对核心主题、象征和母题的体现，与主题规划的深度安排对照，列出表达不足的元素和接下来几章的补足建议
+// @Tags theme
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/themes/coverage [get]
+func (h *ThemeHandler) GetThemeCoverage(c *gin.Context) {
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(theme.Build(blueprint.ThemePlan, sortedChapters(h.db, project.ID), blueprint.ChapterPlans)))
+}
+
+// AnalyzeThemeCoverage 分析章节的主题覆盖
+// @Summary 分析主题覆盖
+// @Description 按主题规划重新分析章节正文对主题、象征和母题的体现，结果写入章节记录；手动修改正文后使用
+// @Tags theme
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body AnalyzeThemeRequest false "章节范围"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/themes/analyze [post]
+func (h *ThemeHandler) AnalyzeThemeCoverage(c *gin.Context) {
+	var req AnalyzeThemeRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+	if blueprint.ThemePlan.Empty() {
+		c.JSON(http.StatusBadRequest, errorResponse("NO_THEME_PLAN", "蓝图没有主题规划", ""))
+		return
+	}
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "初始化写作器失败", err.Error()))
+		return
+	}
+	w = w.WithBudget(budget).WithLogger(requestLogger(c)).WithLanguage(project.Language)
+
+	wanted := make(map[int]bool, len(req.Chapters))
+	for _, n := range req.Chapters {
+		wanted[n] = true
+	}
+
+	results := make([]gin.H, 0)
+	chapters := sortedChapters(h.db, project.ID)
+	for _, ch := range chapters {
+		if len(wanted) > 0 && !wanted[ch.ChapterNum] {
+			continue
+		}
+		prose := chapterProse(h.db, blueprint, ch)
+		if strings.TrimSpace(prose) == "" {
+			continue
+		}
+
+		coverage, err := w.AnalyzeTheme(writer.ThemeParams{
+			Chapter: ch.ChapterNum,
+			Title:   ch.Title,
+			Prose:   prose,
+			Plan:    &blueprint.ThemePlan,
+			Planned: blueprint.ThemePlan.ThreadingAt(ch.ChapterNum),
+		})
+		if err != nil {
+			results = append(results, gin.H{"chapter_num": ch.ChapterNum, "error": err.Error()})
+			continue
+		}
+		ch.ThemeCoverage = coverage
+		if err := h.chapterRepo.UpdateColumns(c, ch, "theme_coverage"); err != nil {
+			results = append(results, gin.H{"chapter_num": ch.ChapterNum, "error": err.Error()})
+			continue
+		}
+		results = append(results, gin.H{
+			"chapter_id":     ch.ID,
+			"chapter_num":    ch.ChapterNum,
+			"theme_coverage": coverage,
+		})
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"chapters": results,
+		"report":   theme.Build(blueprint.ThemePlan, chapters, blueprint.ChapterPlans),
+	}))
+}
@@ -0,0 +1,206 @@
+// Package handlers HTTP处理器 - 故事时间线
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/timeline"
+)
+
+// TimelineHandler 故事时间线处理器
+type TimelineHandler struct {
+	db db.Database
+}
+
+// NewTimelineHandler 创建故事时间线处理器
+func NewTimelineHandler(database db.Database) *TimelineHandler {
+	return &TimelineHandler{db: database}
+}
+
+// GetTimeline 获取项目时间线及冲突
+// @Summary 获取故事时间线
+// @Description 按故事内时间排列蓝图场景，并检测同一角色同时在两地、移动速度超出世界设定等冲突
+// @Tags timeline
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/timeline [get]
+func (h *TimelineHandler) GetTimeline(c *gin.Context) {
+	project, blueprint, ok := loadProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	tl := timeline.FromScenes(blueprint.Scenes)
+	conflicts := tl.Validate(h.rules(project))
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"timeline":  tl,
+		"conflicts": conflicts,
+	}))
+}
+
+// UpdateSceneTime 设置场景的故事内时间
+// @Summary 设置场景时间
+// @Tags timeline
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param chapter path int true "章节号"
+// @Param scene path int true "场景号"
+// @Param request body models.StoryTime true "故事内时间"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/timeline/scenes/{chapter}/{scene} [put]
+func (h *TimelineHandler) UpdateSceneTime(c *gin.Context) {
+	chapter, err1 := strconv.Atoi(c.Param("chapter"))
+	scene, err2 := strconv.Atoi(c.Param("scene"))
+	if err1 != nil || err2 != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "章节号或场景号无效", ""))
+		return
+	}
+
+	var req models.StoryTime
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	if req.Start < 0 || req.Duration < 0 {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "时间不能为负", ""))
+		return
+	}
+
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	found := false
+	for i := range blueprint.Scenes {
+		if blueprint.Scenes[i].Chapter == chapter && blueprint.Scenes[i].Scene == scene {
+			storyTime := req
+			blueprint.Scenes[i].StoryTime = &storyTime
+			found = true
+			break
+		}
+	}
+	if !found {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "场景不存在", ""))
+		return
+	}
+
+	blueprint.UpdatedAt = time.Now()
+	if err := h.db.SaveNarrativeBlueprint(blueprint); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存蓝图失败", err.Error()))
+		return
+	}
+
+	tl := timeline.FromScenes(blueprint.Scenes)
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"timeline":  tl,
+		"conflicts": tl.Validate(h.rules(project)),
+	}))
+}
+
+// CheckTimeline 校验时间线并写入世界的一致性报告
+// @Summary 校验时间线
+// @Description 校验项目时间线，用最新结果替换世界一致性报告中的时间线问题
+// @Tags timeline
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/timeline/check [post]
+func (h *TimelineHandler) CheckTimeline(c *gin.Context) {
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	world, err := h.db.GetWorld(project.WorldID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界不存在", ""))
+		return
+	}
+
+	conflicts := timeline.FromScenes(blueprint.Scenes).Validate(timeline.RulesFromWorld(world))
+	world.ConsistencyReport = timeline.MergeIntoReport(world.ConsistencyReport, timeline.Issues(conflicts))
+	world.UpdatedAt = time.Now()
+	if err := h.db.SaveWorld(world); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存一致性报告失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"conflicts":          conflicts,
+		"consistency_report": world.ConsistencyReport,
+	}))
+}
+
+// rules 项目所在世界的移动约束
+func (h *TimelineHandler) rules(project *models.Project) timeline.Rules {
+	if project.WorldID == "" {
+		return timeline.Rules{}
+	}
+	world, err := h.db.GetWorld(project.WorldID)
+	if err != nil {
+		return timeline.Rules{}
+	}
+	return timeline.RulesFromWorld(world)
+}
+
+// loadProjectBlueprint 加载项目及其蓝图，失败时已写入响应
+func loadProjectBlueprint(c *gin.Context, database db.Database) (*models.Project, *models.NarrativeBlueprint, bool) {
+	project, err := database.GetProject(c.Param("projectId"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return nil, nil, false
+	}
+	if project.NarrativeID == "" {
+		c.JSON(http.StatusBadRequest, errorResponse("NO_BLUEPRINT", "项目还没有叙事蓝图", ""))
+		return nil, nil, false
+	}
+	blueprint, err := database.GetNarrativeBlueprint(project.NarrativeID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "蓝图不存在", ""))
+		return nil, nil, false
+	}
+	return project, blueprint, true
+}
+
+// loadOwnedProjectBlueprint 加载当前用户可修改的项目及蓝图
+func loadOwnedProjectBlueprint(c *gin.Context, database db.Database) (*models.Project, *models.NarrativeBlueprint, bool) {
+	project, blueprint, ok := loadProjectBlueprint(c, database)
+	if !ok {
+		return nil, nil, false
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权修改", ""))
+		return nil, nil, false
+	}
+	return project, blueprint, true
+}
+
+// loadOwnedProject 加载当前用户的项目，失败时已写入响应
+func loadOwnedProject(c *gin.Context, database db.Database) (*models.Project, bool) {
+	return loadOwnedProjectByID(c, database, c.Param("projectId"))
+}
+
+// loadOwnedProjectByID 按ID加载当前用户的项目，用于项目ID不在 projectId 路径参数中的接口
+func loadOwnedProjectByID(c *gin.Context, database db.Database, projectID string) (*models.Project, bool) {
+	project, err := database.GetProject(projectID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目不存在", ""))
+		return nil, false
+	}
+	userID, exists := GetUserID(c)
+	if !exists || project.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权访问", ""))
+		return nil, false
+	}
+	return project, true
+}
@@ -0,0 +1,358 @@
+// Package handlers HTTP处理器 - 章节翻译与双语对照导出
+package handlers
+
+import (
+	"bytes"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/compliance"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/export"
+	"github.com/xlei/xupu/pkg/locale"
+	"github.com/xlei/xupu/pkg/quota"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// TranslationHandler 章节翻译处理器
+type TranslationHandler struct {
+	db db.Database
+}
+
+// NewTranslationHandler 创建章节翻译处理器
+func NewTranslationHandler(database db.Database) *TranslationHandler {
+	return &TranslationHandler{db: database}
+}
+
+// UpdateTranslationSettings 设置自动翻译
+// @Summary 设置自动翻译
+// @Description 设置项目的译文语言，之后章节标记为已完成时自动翻译；语言为空表示关闭自动翻译，已有译文保留
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body UpdateTranslationSettingsRequest true "译文语言"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/translation-settings [put]
+func (h *TranslationHandler) UpdateTranslationSettings(c *gin.Context) {
+	var req UpdateTranslationSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+	if req.Language != "" && req.Language == locale.Normalize(project.Language) {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "译文语言不能与项目输出语言相同", req.Language))
+		return
+	}
+
+	project.TranslationLanguage = req.Language
+	project.UpdatedAt = time.Now()
+	if err := h.db.SaveProject(project); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存项目失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"project": toProjectResponse(project),
+	}))
+}
+
+// TranslateChapter 翻译章节
+// @Summary 翻译章节
+// @Description 按段落翻译章节正文，译文与原文逐段对齐保存；同一语言已有译文时覆盖
+// @Tags translations
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param chapterId path string true "章节ID"
+// @Param request body TranslateChapterRequest false "目标语言"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/chapters/{chapterId}/translate [post]
+func (h *TranslationHandler) TranslateChapter(c *gin.Context) {
+	var req TranslateChapterRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+	language := req.Language
+	if language == "" {
+		language = project.TranslationLanguage
+	}
+	if language == "" {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "未指定译文语言", "请在请求中指定 language 或设置项目的译文语言"))
+		return
+	}
+	if language == locale.Normalize(project.Language) {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "译文语言不能与项目输出语言相同", language))
+		return
+	}
+
+	chapter, err := h.db.GetChapter(c.Param("chapterId"))
+	if err != nil || chapter.ProjectID != project.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "章节不存在", ""))
+		return
+	}
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化写作器失败", err.Error()))
+		return
+	}
+	w = w.WithBudget(budget).WithLogger(requestLogger(c))
+
+	translation, missing, err := translateChapter(h.db, w, project, chapter, language)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("TRANSLATE_FAILED", "翻译章节失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"translation": translation,
+		"missing":     missing,
+	}))
+}
+
+// ListTranslations 列出译文
+// @Summary 列出译文
+// @Description 列出项目各章节在指定语言下的译文状态：未翻译、已翻译或原文已修改（过期）
+// @Tags translations
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param language query string false "译文语言，默认为项目的译文语言"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/translations [get]
+func (h *TranslationHandler) ListTranslations(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+	language, ok := translationLanguage(c, project)
+	if !ok {
+		return
+	}
+
+	translations, err := h.db.ListChapterTranslations(project.ID, language)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取译文失败", err.Error()))
+		return
+	}
+	byChapter := make(map[string]*models.ChapterTranslation, len(translations))
+	for i := range translations {
+		byChapter[translations[i].ChapterID] = &translations[i]
+	}
+
+	chapters := h.db.ListChaptersByProject(project.ID)
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].ChapterNum < chapters[j].ChapterNum })
+	items := make([]gin.H, 0, len(chapters))
+	for _, ch := range chapters {
+		status := "untranslated"
+		var updatedAt *time.Time
+		if t, ok := byChapter[ch.ID]; ok {
+			status = "translated"
+			if t.Stale(ch) {
+				status = "stale"
+			}
+			updatedAt = &t.UpdatedAt
+		}
+		items = append(items, gin.H{
+			"chapter_id":    ch.ID,
+			"chapter_num":   ch.ChapterNum,
+			"title":         ch.Title,
+			"status":        status,
+			"translated_at": updatedAt,
+		})
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"language": language,
+		"chapters": items,
+	}))
+}
+
+// ExportBilingual 导出双语对照
+// @Summary 导出双语对照
+// @Description 将已翻译章节的原文与译文逐段对齐导出，markdown 为左右对照表格，docx 为每章一张两列表格；未翻译的章节跳过，过期译文附提示
+// @Tags translations
+// @Produce text/markdown,octet-stream
+// @Param projectId path string true "项目ID"
+// @Param language query string false "译文语言，默认为项目的译文语言"
+// @Param format query string false "导出格式" Enums(markdown, docx)
+// @Success 200 {string} string
+// @Router /api/v1/projects/{projectId}/export/bilingual [get]
+func (h *TranslationHandler) ExportBilingual(c *gin.Context) {
+	format := c.DefaultQuery("format", "markdown")
+	if format != "markdown" && format != "md" && format != "docx" {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "不支持的导出格式", format))
+		return
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+	language, ok := translationLanguage(c, project)
+	if !ok {
+		return
+	}
+	if !checkOriginality(c, h.db, project) {
+		return
+	}
+
+	translations, err := h.db.ListChapterTranslations(project.ID, language)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "获取译文失败", err.Error()))
+		return
+	}
+	if len(translations) == 0 {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "项目没有该语言的译文", language))
+		return
+	}
+
+	src := &export.BilingualSource{
+		Title:          project.Name,
+		SourceLanguage: project.Language,
+		TargetLanguage: language,
+	}
+	for _, t := range translations {
+		ch, err := h.db.GetChapter(t.ChapterID)
+		if err != nil {
+			continue
+		}
+		src.Chapters = append(src.Chapters, export.BilingualChapter{
+			Num:         ch.ChapterNum,
+			Title:       ch.Title,
+			TargetTitle: t.Title,
+			Segments:    t.Segments,
+			Stale:       t.Stale(ch),
+		})
+	}
+
+	filename := fmt.Sprintf("%s-%s", project.ID, language)
+	if format == "docx" {
+		var buf bytes.Buffer
+		if err := export.WriteBilingualDOCX(&buf, src); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("EXPORT_FAILED", "导出失败", err.Error()))
+			return
+		}
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.docx\"", filename))
+		c.Data(http.StatusOK, "application/vnd.openxmlformats-officedocument.wordprocessingml.document", buf.Bytes())
+		return
+	}
+
+	c.Header("Content-Type", "text/markdown; charset=utf-8")
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s.md\"", filename))
+	c.String(http.StatusOK, export.RenderBilingualMarkdown(src))
+}
+
+// translationLanguage 查询参数中的译文语言，未指定时使用项目设置，失败时已写入响应
+func translationLanguage(c *gin.Context, project *models.Project) (string, bool) {
+	language := c.DefaultQuery("language", project.TranslationLanguage)
+	if language == "" {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "未指定译文语言", ""))
+		return "", false
+	}
+	if !models.ValidLanguage(language) {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "不支持的语言", language))
+		return "", false
+	}
+	return language, true
+}
+
+// translateChapter 翻译章节并保存译文，返回译文和漏译的段落数
+func translateChapter(database db.Database, w *writer.Writer, project *models.Project, chapter *models.Chapter, language string) (*models.ChapterTranslation, int, error) {
+	blueprint := &models.NarrativeBlueprint{}
+	if project.NarrativeID != "" {
+		if b, err := database.GetNarrativeBlueprint(project.NarrativeID); err == nil {
+			blueprint = b
+		}
+	}
+	prose := chapterProse(database, blueprint, chapter)
+	if strings.TrimSpace(prose) == "" {
+		return nil, 0, fmt.Errorf("第%d章没有正文", chapter.ChapterNum)
+	}
+
+	params := writer.TranslateParams{
+		Chapter:    chapter.ChapterNum,
+		Title:      chapter.Title,
+		Paragraphs: compliance.Paragraphs(prose),
+		Source:     project.Language,
+		Language:   language,
+	}
+	if world, err := database.GetWorld(project.WorldID); err == nil {
+		if len(world.Glossary) == 0 {
+			world.RebuildGlossary()
+		}
+		params.Glossary = world.Glossary
+	}
+
+	result, err := w.TranslateChapter(params)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	translation, err := database.GetChapterTranslation(chapter.ID, language)
+	if err != nil {
+		translation = &models.ChapterTranslation{
+			ID:        db.GenerateID("translation"),
+			ProjectID: project.ID,
+			ChapterID: chapter.ID,
+			Language:  language,
+		}
+	}
+	translation.ChapterNum = chapter.ChapterNum
+	translation.Title = result.Title
+	translation.Segments = result.Segments
+	translation.SourceVersion = chapter.Version
+	if err := database.SaveChapterTranslation(translation); err != nil {
+		return nil, 0, fmt.Errorf("保存译文失败: %w", err)
+	}
+	return translation, result.Missing, nil
+}
+
+// autoTranslateChapter 章节定稿后在后台翻译为项目的译文语言，失败只记录日志
+func autoTranslateChapter(database db.Database, project *models.Project, chapterID string, logger *slog.Logger) {
+	language := project.TranslationLanguage
+	if language == "" || language == locale.Normalize(project.Language) {
+		return
+	}
+	go func() {
+		chapter, err := database.GetChapter(chapterID)
+		if err != nil {
+			logger.Warn("自动翻译: 章节不存在", "chapter_id", chapterID)
+			return
+		}
+		w, err := writer.New()
+		if err != nil {
+			logger.Warn("自动翻译: 初始化写作器失败", "error", err)
+			return
+		}
+		w = w.WithBudget(quota.For(database, project.UserID)).WithLogger(logger)
+		if _, missing, err := translateChapter(database, w, project, chapter, language); err != nil {
+			logger.Warn("自动翻译失败", "chapter", chapter.ChapterNum, "language", language, "error", err)
+		} else {
+			logger.Info("自动翻译完成", "chapter", chapter.ChapterNum, "language", language, "missing", missing)
+		}
+	}()
+}
@@ -0,0 +1,98 @@
+// Package handlers HTTP处理器 - 回收站
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// TrashHandler 回收站处理器
+type TrashHandler struct {
+	db db.Database
+}
+
+// NewTrashHandler 创建回收站处理器
+func NewTrashHandler(database db.Database) *TrashHandler {
+	return &TrashHandler{db: database}
+}
+
+// ListTrash 列出回收站
+// @Summary 列出回收站
+// @Description 当前用户删除的项目、蓝图、章节以及已删除的世界，保留30天后彻底清除
+// @Tags trash
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/trash [get]
+func (h *TrashHandler) ListTrash(c *gin.Context) {
+	userID, _ := GetUserID(c)
+	items, err := h.db.ListTrash(userID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取回收站失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"items":          items,
+		"retention_days": int(models.TrashRetention.Hours() / 24),
+	}))
+}
+
+// RestoreTrash 从回收站恢复
+// @Summary 恢复已删除的内容
+// @Description 恢复项目时一并恢复与项目同时删除的蓝图和章节；所属项目仍在回收站中的蓝图和章节需先恢复项目；项目中已有相同章节号的章节时返回409
+// @Tags trash
+// @Produce json
+// @Param kind path string true "类型（project/world/blueprint/chapter）"
+// @Param id path string true "ID"
+// @Success 200 {object} APIResponse
+// @Failure 409 {object} APIResponse
+// @Router /api/v1/trash/{kind}/{id}/restore [post]
+func (h *TrashHandler) RestoreTrash(c *gin.Context) {
+	kind := c.Param("kind")
+	if !models.ValidTrashKind(kind) {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_KIND", "不支持的类型", kind))
+		return
+	}
+
+	item, err := h.db.GetTrashItem(kind, c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "回收站中没有该内容", ""))
+		return
+	}
+
+	// 世界没有所有者，其余按所属项目校验
+	userID, _ := GetUserID(c)
+	if kind != models.TrashWorld && item.UserID != userID {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权恢复", ""))
+		return
+	}
+	if item.ProjectID != "" {
+		if _, err := h.db.GetProject(item.ProjectID); err != nil {
+			c.JSON(http.StatusConflict, errorResponse("PROJECT_DELETED", "所属项目已删除，请先恢复项目", item.ProjectID))
+			return
+		}
+	}
+
+	if err := h.db.RestoreTrash(kind, item.ID); err != nil {
+		if errors.Is(err, db.ErrChapterNumConflict) {
+			c.JSON(http.StatusConflict, errorResponse("CHAPTER_NUM_CONFLICT", "项目中已有相同章节号的章节", "请先修改或删除现有章节后再恢复"))
+			return
+		}
+		c.JSON(http.StatusInternalServerError, errorResponse("RESTORE_FAILED", "恢复失败", err.Error()))
+		return
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditRestore,
+		ResourceType: kind,
+		ResourceID:   item.ID,
+		ProjectID:    item.ProjectID,
+		Summary:      "从回收站恢复 " + item.Name,
+	}, nil, item)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"restored": item,
+	}))
+}
@@ -0,0 +1,231 @@
+// Package handlers HTTP处理器 - 套路检测
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// TropeHandler 套路检测处理器
+type TropeHandler struct {
+	db db.Database
+}
+
+// NewTropeHandler 创建套路检测处理器
+func NewTropeHandler(database db.Database) *TropeHandler {
+	return &TropeHandler{db: database}
+}
+
+// ListTropes 套路库
+// @Summary 获取套路库
+// @Description 返回内置的套路条目，genres 为读者预期中包含该套路的题材
+// @Tags tropes
+// @Produce json
+// @Success 200 {object} APIResponse
+// @Router /api/v1/tropes [get]
+func (h *TropeHandler) ListTropes(c *gin.Context) {
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"tropes": writer.BuiltinTropes,
+		"total":  len(writer.BuiltinTropes),
+	}))
+}
+
+// UpdateTropeSettings 更新项目的套路检测配置
+// @Summary 更新套路检测配置
+// @Description sensitivity 控制报告的严重程度下限；allowed 中的套路有意使用，不报告；默认不报告项目题材预期中的套路
+// @Tags tropes
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body UpdateTropeSettingsRequest true "套路检测配置"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/trope-settings [put]
+func (h *TropeHandler) UpdateTropeSettings(c *gin.Context) {
+	var req UpdateTropeSettingsRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	if !models.ValidTropeSensitivity(req.Sensitivity) {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "sensitivity 只能是 low/medium/high", ""))
+		return
+	}
+	for _, id := range req.Allowed {
+		if _, ok := writer.FindTrope(id); !ok {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", fmt.Sprintf("套路不存在: %s", id), ""))
+			return
+		}
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	project.TropeSettings = models.TropeSettings{
+		Sensitivity:       req.Sensitivity,
+		Allowed:           req.Allowed,
+		FlagGenreExpected: req.FlagGenreExpected,
+	}
+	project.UpdatedAt = time.Now()
+	if err := h.db.SaveProject(project); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存项目失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"trope_settings": project.TropeSettings,
+	}))
+}
+
+// ScanTropes 检测大纲和章节中的套路
+// @Summary 套路检测
+// @Description 按项目的套路检测配置扫描蓝图大纲和章节正文，报告套路出现的位置和严重程度；suggest=true 时为每处套路生成改写建议
+// @Tags tropes
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body ScanTropesRequest false "检测范围"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/tropes/scan [post]
+func (h *TropeHandler) ScanTropes(c *gin.Context) {
+	var req ScanTropesRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+	if req.Scope == "" {
+		req.Scope = "all"
+	}
+	if req.Scope != "all" && req.Scope != models.TropeScopeOutline && req.Scope != models.TropeScopeChapter {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "scope 只能是 outline/chapter/all", ""))
+		return
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+	blueprint := &models.NarrativeBlueprint{}
+	if project.NarrativeID != "" {
+		if b, err := h.db.GetNarrativeBlueprint(project.NarrativeID); err == nil {
+			blueprint = b
+		}
+	}
+	var genre models.WorldType
+	if project.WorldID != "" {
+		if world, err := h.db.GetWorld(project.WorldID); err == nil {
+			genre = world.Type
+		}
+	}
+
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	w, err := writer.New()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化写作器失败", err.Error()))
+		return
+	}
+	w = w.WithBudget(budget).WithLanguage(project.Language)
+
+	report := &models.TropeReport{
+		ProjectID:   project.ID,
+		Settings:    project.TropeSettings,
+		Occurrences: []models.TropeOccurrence{},
+		ScannedAt:   time.Now(),
+	}
+	scan := func(params writer.TropeScanParams) bool {
+		params.Genre = genre
+		params.Settings = project.TropeSettings
+		occurrences, suppressed, err := w.ScanTropes(params)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("SCAN_FAILED", "套路检测失败", err.Error()))
+			return false
+		}
+		report.Occurrences = append(report.Occurrences, occurrences...)
+		report.Suppressed += suppressed
+		return true
+	}
+
+	if req.Scope != models.TropeScopeChapter {
+		if outline := blueprintOutlineText(blueprint); outline != "" {
+			if !scan(writer.TropeScanParams{Scope: models.TropeScopeOutline, Text: outline}) {
+				return
+			}
+		}
+	}
+	if req.Scope != models.TropeScopeOutline {
+		wanted := make(map[int]bool, len(req.Chapters))
+		for _, n := range req.Chapters {
+			wanted[n] = true
+		}
+		chapters := h.db.ListChaptersByProject(project.ID)
+		sort.Slice(chapters, func(i, j int) bool { return chapters[i].ChapterNum < chapters[j].ChapterNum })
+		for _, ch := range chapters {
+			if len(wanted) > 0 && !wanted[ch.ChapterNum] {
+				continue
+			}
+			prose := chapterProse(h.db, blueprint, ch)
+			if strings.TrimSpace(prose) == "" {
+				continue
+			}
+			if !scan(writer.TropeScanParams{Scope: models.TropeScopeChapter, Chapter: ch.ChapterNum, Text: prose}) {
+				return
+			}
+		}
+	}
+
+	if req.Suggest {
+		if err := w.SuggestTropeRewrites(report.Occurrences); err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("SUGGEST_FAILED", "生成改写建议失败", err.Error()))
+			return
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"report": report,
+	}))
+}
+
+// blueprintOutlineText 蓝图大纲和章节规划的文本，供套路检测
+func blueprintOutlineText(blueprint *models.NarrativeBlueprint) string {
+	var b strings.Builder
+	outline := blueprint.StoryOutline
+	for _, line := range []struct{ label, text string }{
+		{"开端", outline.Act1.Setup},
+		{"激励事件", outline.Act1.IncitingIncident},
+		{"第一转折", outline.Act1.PlotPoint1},
+		{"中点", outline.Act2.Midpoint},
+		{"至暗时刻", outline.Act2.AllIsLost},
+		{"第二转折", outline.Act2.PlotPoint2},
+		{"高潮", outline.Act3.Climax},
+		{"结局", outline.Act3.Resolution},
+	} {
+		if line.text != "" {
+			b.WriteString(fmt.Sprintf("%s: %s\n", line.label, line.text))
+		}
+	}
+	for _, ch := range blueprint.ChapterPlans {
+		b.WriteString(fmt.Sprintf("第%d章《%s》: %s", ch.Chapter, ch.Title, ch.Purpose))
+		if len(ch.KeyScenes) > 0 {
+			b.WriteString("；关键场景: " + strings.Join(ch.KeyScenes, "、"))
+		}
+		if ch.EndingHook != "" {
+			b.WriteString("；结尾: " + ch.EndingHook)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,172 @@
+// Package handlers HTTP处理器 - 生死伤势台账
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/vitals"
+)
+
+// VitalsHandler 生死伤势台账处理器
+type VitalsHandler struct {
+	db db.Database
+}
+
+// NewVitalsHandler 创建生死伤势台账处理器
+func NewVitalsHandler(database db.Database) *VitalsHandler {
+	return &VitalsHandler{db: database}
+}
+
+// GetVitals 获取生死伤势台账
+// @Summary 获取生死伤势台账
+// @Description 返回台账记录、指定章节开始时已故和重伤未愈的角色，以及已故角色出现在场景规划或正文中的违规
+// @Tags vitals
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param chapter query int false "章节号，默认为最新一章之后"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/vitals [get]
+func (h *VitalsHandler) GetVitals(c *gin.Context) {
+	chapter := 0
+	if v := c.Query("chapter"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "chapter 应为正整数", v))
+			return
+		}
+		chapter = n
+	}
+
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	if chapter == 0 {
+		for _, ch := range h.db.ListChaptersByProject(project.ID) {
+			if ch.ChapterNum >= chapter {
+				chapter = ch.ChapterNum + 1
+			}
+		}
+	}
+	ledger := vitals.Load(h.db, project.ID)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"events":     ledger.Events(),
+		"chapter":    chapter,
+		"states":     ledger.StatesAt(chapter),
+		"violations": ledger.Check(blueprint.Scenes),
+	}))
+}
+
+// CreateVitalEvent 手工登记生死伤势事件
+// @Summary 登记生死伤势事件
+// @Description 手工登记死亡、重伤、伤愈或复活；有意让角色复活时须先在复活的章节登记，否则场景规划会被视为违规
+// @Tags vitals
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body CreateVitalEventRequest true "事件"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/vitals [post]
+func (h *VitalsHandler) CreateVitalEvent(c *gin.Context) {
+	var req CreateVitalEventRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	event := &models.VitalEvent{
+		ID:        db.GenerateID("vital"),
+		ProjectID: project.ID,
+		Character: strings.TrimSpace(req.Character),
+		Event:     models.VitalEventType(req.Event),
+		Chapter:   req.Chapter,
+		Detail:    req.Detail,
+		Source:    models.VitalFromManual,
+	}
+	if err := h.db.SaveVitalEvent(event); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存台账记录失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"event": event,
+		"state": vitals.Load(h.db, project.ID).StateAt(event.Character, event.Chapter+1),
+	}))
+}
+
+// DeleteVitalEvent 删除台账记录
+// @Summary 删除台账记录
+// @Description 删除一条提取有误的台账记录；从正文提取的记录在该章重新分析时会重新生成
+// @Tags vitals
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param eventId path string true "记录ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/vitals/{eventId} [delete]
+func (h *VitalsHandler) DeleteVitalEvent(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	event, err := h.db.GetVitalEvent(c.Param("eventId"))
+	if err != nil || event.ProjectID != project.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "台账记录不存在", ""))
+		return
+	}
+	if err := h.db.DeleteVitalEvent(event.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "删除台账记录失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"deleted": event.ID,
+	}))
+}
+
+// CheckVitals 校验生死台账并写入世界的一致性报告
+// @Summary 校验生死台账
+// @Description 检查已故角色是否出现在场景规划或正文中，用最新结果替换世界一致性报告中的生死问题
+// @Tags vitals
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/vitals/check [post]
+func (h *VitalsHandler) CheckVitals(c *gin.Context) {
+	project, blueprint, ok := loadOwnedProjectBlueprint(c, h.db)
+	if !ok {
+		return
+	}
+
+	world, err := h.db.GetWorld(project.WorldID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界不存在", ""))
+		return
+	}
+
+	violations := vitals.Load(h.db, project.ID).Check(blueprint.Scenes)
+	world.ConsistencyReport = vitals.MergeIntoReport(world.ConsistencyReport, vitals.Issues(violations))
+	world.UpdatedAt = time.Now()
+	if err := h.db.SaveWorld(world); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("UPDATE_FAILED", "保存一致性报告失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"violations":         violations,
+		"consistency_report": world.ConsistencyReport,
+	}))
+}
@@ -0,0 +1,272 @@
+// Package handlers HTTP处理器 - 项目Webhook
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/webhook"
+)
+
+// WebhookHandler 项目Webhook处理器
+type WebhookHandler struct {
+	db db.Database
+}
+
+// NewWebhookHandler 创建项目Webhook处理器
+func NewWebhookHandler(database db.Database) *WebhookHandler {
+	return &WebhookHandler{db: database}
+}
+
+// WebhookResponse Webhook响应，创建时附带签名密钥
+type WebhookResponse struct {
+	*models.Webhook
+	Secret string `json:"secret,omitempty"`
+}
+
+// ListWebhooks 列出项目的Webhook
+// @Summary 列出项目Webhook
+// @Tags webhooks
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/webhooks [get]
+func (h *WebhookHandler) ListWebhooks(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	hooks, err := h.db.ListWebhooks(project.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取Webhook失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"webhooks": hooks,
+		"events":   models.WebhookEvents,
+	}))
+}
+
+// CreateWebhook 为项目创建Webhook
+// @Summary 创建项目Webhook
+// @Description 事件发生时向 URL POST 签名的 JSON，失败按 1s/4s/16s/64s 退避重试；URL 不能指向内网、本机或链路本地地址；签名密钥只在创建时返回
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param request body WebhookRequest true "Webhook"
+// @Success 201 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	if !validateWebhookRequest(c, &req) {
+		return
+	}
+
+	hook := &models.Webhook{
+		ID:        db.GenerateID("webhook"),
+		ProjectID: project.ID,
+	}
+	applyWebhookRequest(hook, &req)
+	if hook.Secret == "" {
+		hook.Secret = webhook.NewSecret()
+	}
+	if err := h.db.SaveWebhook(hook); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存Webhook失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusCreated, successResponse(WebhookResponse{Webhook: hook, Secret: hook.Secret}))
+}
+
+// UpdateWebhook 更新项目Webhook
+// @Summary 更新项目Webhook
+// @Tags webhooks
+// @Accept json
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param id path string true "Webhook ID"
+// @Param request body WebhookRequest true "Webhook"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/webhooks/{id} [put]
+func (h *WebhookHandler) UpdateWebhook(c *gin.Context) {
+	hook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	var req WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	if !validateWebhookRequest(c, &req) {
+		return
+	}
+
+	applyWebhookRequest(hook, &req)
+	if err := h.db.SaveWebhook(hook); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存Webhook失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(hook))
+}
+
+// DeleteWebhook 删除项目Webhook及其投递记录
+// @Summary 删除项目Webhook
+// @Tags webhooks
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	hook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	if err := h.db.DeleteWebhook(hook.ID); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "删除Webhook失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{"id": hook.ID}))
+}
+
+// TestWebhook 向Webhook投递一次 ping 事件
+// @Summary 测试项目Webhook
+// @Description 同步投递一次 ping 事件（不重试），返回投递记录
+// @Tags webhooks
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param id path string true "Webhook ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/webhooks/{id}/test [post]
+func (h *WebhookHandler) TestWebhook(c *gin.Context) {
+	hook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	delivery, err := webhook.Ping(h.db, hook)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存投递记录失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(delivery))
+}
+
+// ListWebhookDeliveries 获取Webhook的投递记录，新的在前
+// @Summary 获取Webhook投递记录
+// @Tags webhooks
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param id path string true "Webhook ID"
+// @Param limit query int false "返回条数，默认50"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/webhooks/{id}/deliveries [get]
+func (h *WebhookHandler) ListWebhookDeliveries(c *gin.Context) {
+	hook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	limit := 50
+	if v := c.Query("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "无效的limit", v))
+			return
+		}
+		limit = n
+	}
+
+	deliveries, err := h.db.ListWebhookDeliveries(hook.ID, limit)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DB_ERROR", "获取投递记录失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusOK, successResponse(deliveries))
+}
+
+// RedeliverWebhook 按原请求体重新投递
+// @Summary 重新投递Webhook事件
+// @Tags webhooks
+// @Produce json
+// @Param projectId path string true "项目ID"
+// @Param id path string true "Webhook ID"
+// @Param deliveryId path string true "投递ID"
+// @Success 202 {object} APIResponse
+// @Router /api/v1/projects/{projectId}/webhooks/{id}/deliveries/{deliveryId}/redeliver [post]
+func (h *WebhookHandler) RedeliverWebhook(c *gin.Context) {
+	hook, ok := h.loadWebhook(c)
+	if !ok {
+		return
+	}
+
+	original, err := h.db.GetWebhookDelivery(c.Param("deliveryId"))
+	if err != nil || original.WebhookID != hook.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "投递记录不存在", ""))
+		return
+	}
+
+	delivery, err := webhook.Redeliver(h.db, hook, original)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存投递记录失败", err.Error()))
+		return
+	}
+	c.JSON(http.StatusAccepted, successResponse(delivery))
+}
+
+// loadWebhook 加载当前用户项目下的Webhook，失败时已写入响应
+func (h *WebhookHandler) loadWebhook(c *gin.Context) (*models.Webhook, bool) {
+	project, ok := loadOwnedProject(c, h.db)
+	if !ok {
+		return nil, false
+	}
+	hook, err := h.db.GetWebhook(c.Param("id"))
+	if err != nil || hook.ProjectID != project.ID {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "Webhook不存在", ""))
+		return nil, false
+	}
+	return hook, true
+}
+
+// validateWebhookRequest 校验回调地址和订阅的事件，失败时已写入响应
+func validateWebhookRequest(c *gin.Context, req *WebhookRequest) bool {
+	if err := webhook.ValidateURL(req.URL); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_URL", "Webhook地址不可用", err.Error()))
+		return false
+	}
+	for _, event := range req.Events {
+		if !models.ValidWebhookEvent(event) {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_EVENT", "不支持的Webhook事件", event))
+			return false
+		}
+	}
+	return true
+}
+
+// applyWebhookRequest 把请求写入Webhook，密钥为空时不覆盖
+func applyWebhookRequest(hook *models.Webhook, req *WebhookRequest) {
+	hook.URL = req.URL
+	hook.Events = req.Events
+	hook.Enabled = req.Enabled == nil || *req.Enabled
+	if req.Secret != "" {
+		hook.Secret = req.Secret
+	}
+}
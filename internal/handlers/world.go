@@ -2,7 +2,9 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/xlei/xupu/internal/models"
@@ -39,6 +41,11 @@ func (h *WorldHandler) CreateWorld(c *gin.Context) {
 		return
 	}
 
+	budget, ok := userBudget(c, db.Get())
+	if !ok {
+		return
+	}
+
 	// 创建世界构建器
 	if h.worldBuilder == nil {
 		wb, err := worldbuilder.New()
@@ -50,12 +57,13 @@ func (h *WorldHandler) CreateWorld(c *gin.Context) {
 	}
 
 	// 构建世界
-	world, err := h.worldBuilder.Build(worldbuilder.BuildParams{
+	world, err := h.worldBuilder.WithBudget(budget).WithLogger(requestLogger(c)).Build(worldbuilder.BuildParams{
 		Name:  req.Name,
 		Type:  parseWorldType(req.Type),
 		Scale: parseWorldScale(req.Scale),
 		Theme: req.Theme,
 		Style: req.Style,
+		Tier:  models.ParseWorldBuildTier(req.Tier),
 	})
 
 	if err != nil {
@@ -66,6 +74,134 @@ func (h *WorldHandler) CreateWorld(c *gin.Context) {
 	c.JSON(http.StatusOK, successResponse(toWorldResponse(world)))
 }
 
+// DeepenWorld 加深世界分节
+// @Summary 加深世界分节
+// @Description 对已有世界只运行指定分节（geography/religions/history/supernatural）的详细构建，合并结果且不改动其他分节
+// @Tags worlds
+// @Accept json
+// @Produce json
+// @Param id path string true "世界ID"
+// @Param request body DeepenWorldRequest true "加深分节"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/worlds/{id}/deepen [post]
+func (h *WorldHandler) DeepenWorld(c *gin.Context) {
+	var req DeepenWorldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+	section, err := worldbuilder.ParseDeepenSection(req.Section)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	id := c.Param("id")
+	before, ok := loadOwnedWorld(c, db.Get())
+	if !ok {
+		return
+	}
+
+	budget, ok := userBudget(c, db.Get())
+	if !ok {
+		return
+	}
+
+	if h.worldBuilder == nil {
+		wb, err := worldbuilder.New()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化失败", err.Error()))
+			return
+		}
+		h.worldBuilder = wb
+	}
+
+	world, err := h.worldBuilder.WithBudget(budget).WithLogger(requestLogger(c)).Deepen(id, section)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("DEEPEN_FAILED", "加深世界失败", err.Error()))
+		return
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditRegenerate,
+		ResourceType: models.AuditResourceWorld,
+		ResourceID:   id,
+		Summary:      fmt.Sprintf("加深世界分节 %s", section),
+	}, before, world)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"section": section,
+		"world":   toWorldResponse(world),
+	}))
+}
+
+// OrganizeReligions 生成宗教组织架构
+// @Summary 生成宗教组织架构
+// @Description 为世界中的宗教生成神职层级、带教义分歧的派系和绑定地区的圣地
+// @Tags worlds
+// @Accept json
+// @Produce json
+// @Param id path string true "世界ID"
+// @Param request body OrganizeReligionsRequest false "指定宗教"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/worlds/{id}/religions/organize [post]
+func (h *WorldHandler) OrganizeReligions(c *gin.Context) {
+	var req OrganizeReligionsRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	id := c.Param("id")
+	existing, ok := loadOwnedWorld(c, db.Get())
+	if !ok {
+		return
+	}
+	if req.Religion != "" {
+		found := false
+		for _, r := range existing.Civilization.Religions {
+			found = found || r.Name == req.Religion || r.ID == req.Religion
+		}
+		if !found {
+			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "宗教不存在", req.Religion))
+			return
+		}
+	}
+
+	budget, ok := userBudget(c, db.Get())
+	if !ok {
+		return
+	}
+
+	if h.worldBuilder == nil {
+		wb, err := worldbuilder.New()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化失败", err.Error()))
+			return
+		}
+		h.worldBuilder = wb
+	}
+
+	world, err := h.worldBuilder.WithBudget(budget).WithLogger(requestLogger(c)).OrganizeReligions(id, req.Religion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("ORGANIZE_FAILED", "生成宗教组织失败", err.Error()))
+		return
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditRegenerate,
+		ResourceType: models.AuditResourceWorld,
+		ResourceID:   id,
+		Summary:      "生成宗教组织架构 " + req.Religion,
+	}, existing, world)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"world_id":  world.ID,
+		"religions": world.Civilization.Religions,
+		"warnings":  world.Warnings,
+	}))
+}
+
 // ListWorlds 列出所有世界
 // @Summary 获取世界列表
 // @Description 获取所有世界设定
@@ -104,9 +240,111 @@ func (h *WorldHandler) GetWorld(c *gin.Context) {
 	c.JSON(http.StatusOK, successResponse(toWorldResponse(world)))
 }
 
+// ListWorldEntities 列出世界实体索引
+// @Summary 获取世界实体列表
+// @Description 获取世界中所有命名实体（地区、种族、宗教、法律、历史事件），可按 kind 过滤
+// @Tags worlds
+// @Produce json
+// @Param id path string true "世界ID"
+// @Param kind query string false "实体类型 (region/race/religion/law/event)"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/worlds/{id}/entities [get]
+func (h *WorldHandler) ListWorldEntities(c *gin.Context) {
+	world, ok := loadIndexedWorld(c)
+	if !ok {
+		return
+	}
+
+	kind := models.EntityKind(c.Query("kind"))
+	entities := make([]models.WorldEntity, 0, len(world.Entities))
+	for _, e := range world.Entities {
+		if kind == "" || e.Kind == kind {
+			entities = append(entities, e)
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"world_id": world.ID,
+		"entities": entities,
+		"total":    len(entities),
+	}))
+}
+
+// GetWorldEntity 获取世界实体详情
+// @Summary 获取世界实体详情
+// @Description 按名称或ID获取实体的完整设定、它引用的实体以及引用它的实体
+// @Tags worlds
+// @Produce json
+// @Param id path string true "世界ID"
+// @Param name path string true "实体名称或ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/worlds/{id}/entities/{name} [get]
+func (h *WorldHandler) GetWorldEntity(c *gin.Context) {
+	world, ok := loadIndexedWorld(c)
+	if !ok {
+		return
+	}
+
+	entity := world.FindEntity(c.Param("name"))
+	if entity == nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "实体不存在", c.Param("name")))
+		return
+	}
+
+	references := make([]models.WorldEntity, 0, len(entity.References))
+	for _, ref := range entity.References {
+		if e := world.FindEntity(ref); e != nil {
+			references = append(references, *e)
+		}
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"entity":        entity,
+		"detail":        world.EntitySource(entity),
+		"references":    references,
+		"referenced_by": world.ReferencedBy(entity.ID),
+	}))
+}
+
+// GetWorldMap 获取地理示意图
+// @Summary 获取世界地理示意图
+// @Description 返回地区节点（相对坐标）和相邻边界（边界类型、通行小时数），供前端渲染示意地图
+// @Tags worlds
+// @Produce json
+// @Param id path string true "世界ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/worlds/{id}/map [get]
+func (h *WorldHandler) GetWorldMap(c *gin.Context) {
+	world, err := db.Get().GetWorld(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界不存在", ""))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(worldbuilder.BuildMap(world)))
+}
+
+// loadIndexedWorld 读取世界，早期创建、尚无实体索引的世界补建索引并保存
+func loadIndexedWorld(c *gin.Context) (*models.WorldSetting, bool) {
+	world, err := db.Get().GetWorld(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界不存在", ""))
+		return nil, false
+	}
+	if len(world.Entities) == 0 {
+		world.RebuildEntityIndex()
+		if len(world.Entities) > 0 {
+			if err := db.Get().SaveWorld(world); err != nil {
+				requestLogger(c).Warn("保存实体索引失败", "world_id", world.ID, "error", err)
+			}
+		}
+	}
+	return world, true
+}
+
 // DeleteWorld 删除世界
 // @Summary 删除世界
-// @Description 删除指定的世界设定
+// @Description 世界设定移入回收站，30天内可恢复
 // @Tags worlds
 // @Produce json
 // @Param id path string true "世界ID"
@@ -127,13 +365,108 @@ func (h *WorldHandler) DeleteWorld(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errorResponse("DELETE_FAILED", "删除世界失败", err.Error()))
 		return
 	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditDelete,
+		ResourceType: models.AuditResourceWorld,
+		ResourceID:   id,
+		Summary:      "删除世界 " + world.Name,
+	}, world, nil)
 
 	c.JSON(http.StatusOK, successResponse(gin.H{
 		"deleted_world_id":   world.ID,
 		"deleted_world_name": world.Name,
+		"purge_at":           time.Now().Add(models.TrashRetention),
 	}))
 }
 
+// ExportWorld 导出世界设定包
+// @Summary 导出世界设定
+// @Description 将世界设定及其关联角色导出为可移植的JSON包
+// @Tags worlds
+// @Produce json
+// @Param id path string true "世界ID"
+// @Success 200 {object} worldbuilder.WorldBundle
+// @Router /api/v1/worlds/{id}/export [get]
+func (h *WorldHandler) ExportWorld(c *gin.Context) {
+	if _, ok := loadOwnedWorld(c, db.Get()); !ok {
+		return
+	}
+
+	bundle, err := worldbuilder.ExportWorld(db.Get(), c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界不存在", err.Error()))
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=world_%s.json", bundle.World.ID))
+	c.JSON(http.StatusOK, bundle)
+}
+
+// ImportWorld 导入世界设定包
+// @Summary 导入世界设定
+// @Description 导入世界设定包，世界和角色会分配新ID
+// @Tags worlds
+// @Accept json
+// @Produce json
+// @Param request body worldbuilder.WorldBundle true "世界设定包"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/worlds/import [post]
+func (h *WorldHandler) ImportWorld(c *gin.Context) {
+	data, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "读取请求失败", err.Error()))
+		return
+	}
+
+	bundle, err := worldbuilder.ParseBundle(data)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_BUNDLE", "世界包无效", err.Error()))
+		return
+	}
+
+	result, err := worldbuilder.ImportWorld(db.Get(), bundle)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("IMPORT_FAILED", "导入世界失败", err.Error()))
+		return
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditImport,
+		ResourceType: models.AuditResourceWorld,
+		ResourceID:   result.World.ID,
+		Summary:      "导入世界 " + result.World.Name,
+	}, nil, result.World)
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"world":  toWorldResponse(result.World),
+		"id_map": result.IDMap,
+	}))
+}
+
+// loadOwnedWorld 加载路径中的世界，并校验当前用户有项目使用该世界，失败时已写入响应
+func loadOwnedWorld(c *gin.Context, database db.Database) (*models.WorldSetting, bool) {
+	world, err := database.GetWorld(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界不存在", ""))
+		return nil, false
+	}
+	userID, exists := GetUserID(c)
+	if !exists || !ownsWorld(database, userID, world.ID) {
+		c.JSON(http.StatusForbidden, errorResponse("FORBIDDEN", "无权访问", ""))
+		return nil, false
+	}
+	return world, true
+}
+
+// ownsWorld 用户是否有项目使用该世界
+func ownsWorld(database db.Database, userID, worldID string) bool {
+	for _, p := range database.ListProjectsByUser(userID) {
+		if p.WorldID == worldID {
+			return true
+		}
+	}
+	return false
+}
+
 // toWorldResponse 转换世界响应
 func toWorldResponse(w *models.WorldSetting) WorldResponse {
 	return WorldResponse{
@@ -142,6 +475,7 @@ func toWorldResponse(w *models.WorldSetting) WorldResponse {
 		Type:          string(w.Type),
 		Scale:         string(w.Scale),
 		Style:         w.Style,
+		BuildTier:     string(w.BuildTier),
 		CoreQuestion:  w.Philosophy.CoreQuestion,
 		HighestGood:   w.Philosophy.ValueSystem.HighestGood,
 		UltimateEvil:  w.Philosophy.ValueSystem.UltimateEvil,
@@ -149,6 +483,7 @@ func toWorldResponse(w *models.WorldSetting) WorldResponse {
 		RegionCount:   len(w.Geography.Regions),
 		RaceCount:     len(w.Civilization.Races),
 		CreatedAt:     w.CreatedAt.Format("2006-01-02T15:04:05Z07:00"),
+		Warnings:      w.Warnings,
 	}
 }
 
@@ -0,0 +1,140 @@
+// Package handlers HTTP处理器 - 世界术语表
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// GetGlossary 获取术语表
+// @Summary 获取术语表
+// @Description 返回世界的术语表；旧世界没有术语表时从设定中提取
+// @Tags worlds
+// @Produce json
+// @Param id path string true "世界ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/worlds/{id}/glossary [get]
+func (h *WorldHandler) GetGlossary(c *gin.Context) {
+	world, err := db.Get().GetWorld(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界不存在", ""))
+		return
+	}
+	if len(world.Glossary) == 0 {
+		world.RebuildGlossary()
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"glossary": world.Glossary,
+		"total":    len(world.Glossary),
+	}))
+}
+
+// UpdateGlossary 更新术语表
+// @Summary 更新术语表
+// @Description 登记术语的错误写法或添加手工术语。与设定中提取的术语同名的条目更新其错误写法和备注，其他条目作为手工术语保存；未列出的手工术语会被删除
+// @Tags worlds
+// @Accept json
+// @Produce json
+// @Param id path string true "世界ID"
+// @Param request body UpdateGlossaryRequest true "术语表条目"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/worlds/{id}/glossary [put]
+func (h *WorldHandler) UpdateGlossary(c *gin.Context) {
+	var req UpdateGlossaryRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+		return
+	}
+
+	database := db.Get()
+	world, ok := loadOwnedWorld(c, database)
+	if !ok {
+		return
+	}
+	world.RebuildGlossary()
+	previous := len(world.Glossary)
+
+	extracted := make(map[string]int, len(world.Glossary))
+	glossary := make([]models.GlossaryTerm, 0, len(world.Glossary)+len(req.Terms))
+	for _, t := range world.Glossary {
+		if t.Source == models.TermFromWorld {
+			glossary = append(glossary, t)
+		}
+	}
+	for i := range glossary {
+		extracted[glossary[i].Term] = i
+	}
+
+	canonical := make(map[string]bool, len(glossary)+len(req.Terms))
+	for _, t := range glossary {
+		canonical[t.Term] = true
+	}
+	for _, t := range req.Terms {
+		canonical[strings.TrimSpace(t.Term)] = true
+	}
+
+	seen := make(map[string]bool, len(req.Terms))
+	for _, t := range req.Terms {
+		term := strings.TrimSpace(t.Term)
+		if seen[term] {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "术语重复", term))
+			return
+		}
+		seen[term] = true
+		aliases := make([]string, 0, len(t.Aliases))
+		for _, a := range t.Aliases {
+			a = strings.TrimSpace(a)
+			if a == "" || a == term {
+				continue
+			}
+			// 错误写法不能是另一个术语的规范写法，否则校正时会改掉正确的名称
+			if canonical[a] {
+				c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "错误写法与其他术语重名", a))
+				return
+			}
+			aliases = append(aliases, a)
+		}
+
+		if i, ok := extracted[term]; ok {
+			glossary[i].Aliases = aliases
+			glossary[i].Note = t.Note
+			continue
+		}
+		kind := t.Kind
+		if kind == "" {
+			kind = models.TermOther
+		}
+		glossary = append(glossary, models.GlossaryTerm{
+			Term:    term,
+			Kind:    kind,
+			Aliases: aliases,
+			Source:  models.TermFromManual,
+			Note:    t.Note,
+		})
+	}
+
+	world.Glossary = glossary
+	world.UpdatedAt = time.Now()
+	if err := database.SaveWorld(world); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存世界失败", err.Error()))
+		return
+	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditUpdate,
+		ResourceType: models.AuditResourceWorld,
+		ResourceID:   world.ID,
+		Summary:      "更新术语表",
+	}, fmt.Sprintf("%d 条术语", previous), fmt.Sprintf("%d 条术语", len(world.Glossary)))
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"glossary": world.Glossary,
+		"total":    len(world.Glossary),
+	}))
+}
@@ -0,0 +1,132 @@
+// Package handlers HTTP处理器 - 龙套角色池
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/narrative"
+	"github.com/xlei/xupu/pkg/worldbuilder"
+)
+
+// ListMinorCharacters 获取龙套角色池
+// @Summary 获取龙套角色池
+// @Description 返回世界的龙套角色；指定 location 时只返回该地点场景可选用的龙套
+// @Tags worlds
+// @Produce json
+// @Param id path string true "世界ID"
+// @Param location query string false "场景地点"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/worlds/{id}/minor-characters [get]
+func (h *WorldHandler) ListMinorCharacters(c *gin.Context) {
+	world, err := db.Get().GetWorld(c.Param("id"))
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界不存在", ""))
+		return
+	}
+
+	minors := world.MinorCharacters
+	if location := c.Query("location"); location != "" {
+		minors = world.MinorCharactersAt(location, len(world.MinorCharacters))
+	}
+	if minors == nil {
+		minors = []models.MinorCharacter{}
+	}
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"minor_characters": minors,
+		"total":            len(minors),
+	}))
+}
+
+// GenerateMinorPool 生成龙套角色池
+// @Summary 生成龙套角色池
+// @Description 按地区生成有名有姓的龙套，替换未升格的旧龙套
+// @Tags worlds
+// @Accept json
+// @Produce json
+// @Param id path string true "世界ID"
+// @Param request body GenerateMinorPoolRequest false "生成参数"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/worlds/{id}/minor-characters/generate [post]
+func (h *WorldHandler) GenerateMinorPool(c *gin.Context) {
+	var req GenerateMinorPoolRequest
+	if c.Request.ContentLength > 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, errorResponse("INVALID_REQUEST", "请求参数错误", err.Error()))
+			return
+		}
+	}
+
+	id := c.Param("id")
+	if _, ok := loadOwnedWorld(c, db.Get()); !ok {
+		return
+	}
+	budget, ok := userBudget(c, db.Get())
+	if !ok {
+		return
+	}
+
+	if h.worldBuilder == nil {
+		wb, err := worldbuilder.New()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, errorResponse("INIT_FAILED", "初始化失败", err.Error()))
+			return
+		}
+		h.worldBuilder = wb
+	}
+
+	world, err := h.worldBuilder.WithBudget(budget).WithLogger(requestLogger(c)).GenerateMinorPool(id, req.PerRegion)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("GENERATE_FAILED", "生成龙套角色池失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"minor_characters": world.MinorCharacters,
+		"total":            len(world.MinorCharacters),
+	}))
+}
+
+// PromoteMinorCharacter 龙套升格为正式角色
+// @Summary 龙套升格
+// @Description 为龙套创建角色卡（定位锁定为配角），下一次生成蓝图时作为完整角色参与演化
+// @Tags worlds
+// @Produce json
+// @Param id path string true "世界ID"
+// @Param name path string true "龙套姓名或ID"
+// @Success 200 {object} APIResponse
+// @Router /api/v1/worlds/{id}/minor-characters/{name}/promote [post]
+func (h *WorldHandler) PromoteMinorCharacter(c *gin.Context) {
+	database := db.Get()
+	world, ok := loadOwnedWorld(c, database)
+	if !ok {
+		return
+	}
+	minor := world.FindMinorCharacter(c.Param("name"))
+	if minor == nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "龙套不存在", c.Param("name")))
+		return
+	}
+	if minor.Promoted() {
+		c.JSON(http.StatusConflict, errorResponse("ALREADY_PROMOTED", "龙套已升格", minor.PromotedTo))
+		return
+	}
+
+	sheet := narrative.MinorCharacterSheet(minor, world.ID)
+	if err := database.SaveCharacterSheet(sheet); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存角色卡失败", err.Error()))
+		return
+	}
+	minor.PromotedTo = sheet.ID
+	if err := database.SaveWorld(world); err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存世界失败", err.Error()))
+		return
+	}
+
+	c.JSON(http.StatusOK, successResponse(gin.H{
+		"minor_character": minor,
+		"sheet":           sheet,
+	}))
+}
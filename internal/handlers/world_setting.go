@@ -2,8 +2,11 @@
 package handlers
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
@@ -40,6 +43,45 @@ type SaveWorldStagesRequest struct {
 	Civilization *models.Civilization `json:"civilization"`
 	Society      *models.Society      `json:"society"`
 	History      *models.History      `json:"history"`
+
+	// 编辑开始时各分节的版本号，用于乐观并发控制；未提供的分节不做冲突检查
+	BaseVersions map[string]int `json:"base_versions"`
+}
+
+// sections 返回本次提交的分节内容
+func (r *SaveWorldStagesRequest) sections() map[string]interface{} {
+	sections := make(map[string]interface{})
+	if r.Philosophy != nil {
+		sections["philosophy"] = r.Philosophy
+	}
+	if r.Worldview != nil {
+		sections["worldview"] = r.Worldview
+	}
+	if r.Laws != nil {
+		sections["laws"] = r.Laws
+	}
+	if r.Geography != nil {
+		sections["geography"] = r.Geography
+	}
+	if r.Civilization != nil {
+		sections["civilization"] = r.Civilization
+	}
+	if r.Society != nil {
+		sections["society"] = r.Society
+	}
+	if r.History != nil {
+		sections["history"] = r.History
+	}
+	return sections
+}
+
+// SectionConflict 分节版本冲突
+type SectionConflict struct {
+	Section        string      `json:"section"`
+	BaseVersion    int         `json:"base_version"`    // 提交方编辑时的版本
+	CurrentVersion int         `json:"current_version"` // 服务端当前版本
+	Current        interface{} `json:"current"`         // 服务端当前内容
+	Submitted      interface{} `json:"submitted"`       // 提交的内容
 }
 
 // GenerateWorldStageRequest 生成特定阶段请求
@@ -80,62 +122,86 @@ func (h *WorldSettingHandler) SaveWorldStages(c *gin.Context) {
 	}
 
 	// 获取或创建世界设定
-	var world *models.WorldSetting
-	if project.WorldID != "" {
-		world, err = h.db.GetWorld(project.WorldID)
-		if err != nil {
-			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界设定不存在", ""))
-			return
-		}
-	} else {
-		// 创建新的世界设定
-		world = &models.WorldSetting{
-			ID:        db.GenerateID("world"),
-			Name:      project.Name + "的世界",
-			Type:      models.WorldFantasy, // 默认类型
-			Scale:     models.ScaleNation,  // 默认规模
-			Style:     "通用",
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		// 更新项目的 WorldID
-		project.WorldID = world.ID
-		h.db.SaveProject(project)
+	worldID, err := h.ensureWorld(project, models.WorldFantasy, "通用")
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界设定不存在", err.Error()))
+		return
 	}
 
-	// 更新各个阶段
-	if req.Philosophy != nil {
-		world.Philosophy = *req.Philosophy
-	}
-	if req.Worldview != nil {
-		world.Worldview = *req.Worldview
-	}
-	if req.Laws != nil {
-		world.Laws = *req.Laws
-	}
-	if req.Geography != nil {
-		world.Geography = *req.Geography
-	}
-	if req.Civilization != nil {
-		world.Civilization = *req.Civilization
-	}
-	if req.Society != nil {
-		world.Society = *req.Society
-	}
-	if req.History != nil {
-		world.History = *req.History
-	}
+	submitted := req.sections()
+
+	// 在最新读取的世界上检查分节版本并写入提交的分节，未提交的分节保持库中内容
+	var before map[string]int
+	world, conflicts, err := h.updateWorld(worldID, func(world *models.WorldSetting) []SectionConflict {
+		// 只有提交了基准版本的分节才参与检查
+		conflicts := make([]SectionConflict, 0)
+		for section, content := range submitted {
+			base, ok := req.BaseVersions[section]
+			if !ok {
+				continue
+			}
+			if current := world.SectionVersion(section); current != base {
+				conflicts = append(conflicts, SectionConflict{
+					Section:        section,
+					BaseVersion:    base,
+					CurrentVersion: current,
+					Current:        world.Section(section),
+					Submitted:      content,
+				})
+			}
+		}
+		if len(conflicts) > 0 {
+			return conflicts
+		}
 
-	world.UpdatedAt = time.Now()
+		before = sectionVersions(world)
 
-	// 保存
-	if err := h.db.SaveWorld(world); err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存失败", err.Error()))
+		// 更新各个阶段
+		if req.Philosophy != nil {
+			world.Philosophy = *req.Philosophy
+		}
+		if req.Worldview != nil {
+			world.Worldview = *req.Worldview
+		}
+		if req.Laws != nil {
+			world.Laws = *req.Laws
+		}
+		if req.Geography != nil {
+			world.Geography = *req.Geography
+		}
+		if req.Civilization != nil {
+			world.Civilization = *req.Civilization
+		}
+		if req.Society != nil {
+			world.Society = *req.Society
+		}
+		if req.History != nil {
+			world.History = *req.History
+		}
+		for section := range submitted {
+			world.BumpSectionVersion(section)
+		}
+		return nil
+	})
+	if writeWorldSaveError(c, conflicts, err) {
 		return
 	}
+	sections := make([]string, 0, len(submitted))
+	for section := range submitted {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditUpdate,
+		ResourceType: models.AuditResourceWorld,
+		ResourceID:   world.ID,
+		ProjectID:    projectID,
+		Summary:      "编辑世界分节 " + strings.Join(sections, ", "),
+	}, before, sectionVersions(world))
 
 	c.JSON(http.StatusOK, successResponse(gin.H{
 		"world_id": world.ID,
+		"versions": sectionVersions(world),
 		"message":  "保存成功",
 	}))
 }
@@ -203,6 +269,7 @@ func (h *WorldSettingHandler) GetWorldStages(c *gin.Context) {
 
 	c.JSON(http.StatusOK, successResponse(gin.H{
 		"world_id": world.ID,
+		"versions": sectionVersions(world),
 		"stages": gin.H{
 			"philosophy":   world.Philosophy,
 			"worldview":    world.Worldview,
@@ -230,6 +297,12 @@ func (h *WorldSettingHandler) GenerateWorldStage(c *gin.Context) {
 	projectID := c.Param("projectId")
 	stage := c.Param("stage")
 
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	worldBuilder := h.worldBuilder.WithBudget(budget).WithLogger(requestLogger(c))
+
 	// 验证项目存在
 	project, err := h.db.GetProject(projectID)
 	if err != nil {
@@ -244,33 +317,26 @@ func (h *WorldSettingHandler) GenerateWorldStage(c *gin.Context) {
 	}
 
 	// 获取或创建世界设定
-	var world *models.WorldSetting
-	if project.WorldID != "" {
-		world, err = h.db.GetWorld(project.WorldID)
-		if err != nil {
-			c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界设定不存在", ""))
-			return
-		}
-	} else {
-		// 创建新的世界设定
-		world = &models.WorldSetting{
-			ID:        db.GenerateID("world"),
-			Name:      project.Name + "的世界",
-			Type:      models.WorldFantasy,
-			Scale:     models.ScaleNation,
-			Style:     "通用",
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
-		}
-		// 更新项目的 WorldID
-		project.WorldID = world.ID
-		h.db.SaveProject(project)
+	worldID, err := h.ensureWorld(project, models.WorldFantasy, "通用")
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界设定不存在", err.Error()))
+		return
+	}
+	world, err := h.db.GetWorld(worldID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界设定不存在", ""))
+		return
+	}
+	sections := []string{stage}
+	if stage == "civilization_society" {
+		sections = []string{"civilization", "society"}
 	}
+	base := sectionVersions(world, sections...)
 
 	// 调用 WorldBuilder 生成指定阶段
 	switch stage {
 	case "philosophy":
-		result, _, err := h.worldBuilder.GenerateStage1(worldbuilder.Stage1Input{
+		result, _, err := worldBuilder.GenerateStage1(worldbuilder.Stage1Input{
 			WorldType: string(world.Type),
 			Theme:     req.Context,
 			Style:     world.Style,
@@ -286,7 +352,7 @@ func (h *WorldSettingHandler) GenerateWorldStage(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, errorResponse("MISSING_DEPENDENCY", "缺少前置阶段（哲学）", ""))
 			return
 		}
-		result, _, err := h.worldBuilder.GenerateStage2(worldbuilder.Stage2Input{
+		result, _, err := worldBuilder.GenerateStage2(worldbuilder.Stage2Input{
 			CoreQuestion: world.Philosophy.CoreQuestion,
 			HighestGood:  world.Philosophy.ValueSystem.HighestGood,
 			UltimateEvil: world.Philosophy.ValueSystem.UltimateEvil,
@@ -303,7 +369,7 @@ func (h *WorldSettingHandler) GenerateWorldStage(c *gin.Context) {
 			return
 		}
 		worldviewSummary := fmt.Sprintf("起源:%s 结构:%s", world.Worldview.Cosmology.Origin, world.Worldview.Cosmology.Structure)
-		result, _, err := h.worldBuilder.GenerateStage3(worldbuilder.Stage3Input{
+		result, _, err := worldBuilder.GenerateStage3(worldbuilder.Stage3Input{
 			WorldType: string(world.Type),
 			Worldview: worldviewSummary,
 		})
@@ -322,7 +388,7 @@ func (h *WorldSettingHandler) GenerateWorldStage(c *gin.Context) {
 		if len(world.Philosophy.ValueSystem.MoralDilemmas) > 0 {
 			mainConflicts = world.Philosophy.ValueSystem.MoralDilemmas[0].Dilemma
 		}
-		result, _, err := h.worldBuilder.GenerateStage4(worldbuilder.Stage4Input{
+		result, _, err := worldBuilder.GenerateStage4(worldbuilder.Stage4Input{
 			CoreQuestion:  world.Philosophy.CoreQuestion,
 			MainConflicts: mainConflicts,
 			WorldType:     string(world.Type),
@@ -340,7 +406,7 @@ func (h *WorldSettingHandler) GenerateWorldStage(c *gin.Context) {
 		}
 		lawsSummary := fmt.Sprintf("物理:%s 超自然:%v", world.Laws.Physics.Gravity, world.Laws.Supernatural != nil && world.Laws.Supernatural.Exists)
 		civilizationNeeds := fmt.Sprintf("资源需求基于%s类型的世界", world.Type)
-		result, _, err := h.worldBuilder.GenerateStage5(worldbuilder.Stage5Input{
+		result, _, err := worldBuilder.GenerateStage5(worldbuilder.Stage5Input{
 			WorldType:         string(world.Type),
 			WorldScale:        string(world.Scale),
 			LawsSummary:       lawsSummary,
@@ -357,15 +423,10 @@ func (h *WorldSettingHandler) GenerateWorldStage(c *gin.Context) {
 			c.JSON(http.StatusBadRequest, errorResponse("MISSING_DEPENDENCY", "缺少前置阶段（地理）", ""))
 			return
 		}
-		geographySummary := fmt.Sprintf("%d个区域, 气候:%s", len(world.Geography.Regions), func() string {
-			if world.Geography.Climate != nil {
-				return world.Geography.Climate.Type
-			}
-			return "未知"
-		}())
+		geographySummary := worldbuilder.GeographySummary(&world.Geography)
 		valueSystem := fmt.Sprintf("最高善:%s", world.Philosophy.ValueSystem.HighestGood)
 
-		result, _, err := h.worldBuilder.GenerateStage6(worldbuilder.Stage6Input{
+		result, _, err := worldBuilder.GenerateStage6(worldbuilder.Stage6Input{
 			WorldType:        string(world.Type),
 			GeographySummary: geographySummary,
 			ValueSystem:      valueSystem,
@@ -382,13 +443,31 @@ func (h *WorldSettingHandler) GenerateWorldStage(c *gin.Context) {
 		return
 	}
 
-	world.UpdatedAt = time.Now()
-
-	// 保存
-	if err := h.db.SaveWorld(world); err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存失败", err.Error()))
+	// 生成期间可能有人编辑了世界，重新读取后只合并生成的分节；
+	// AI生成同样视为一次分节编辑，使正在编辑该分节的协作者感知到变更
+	generated := world
+	var before map[string]int
+	world, conflicts, err := h.updateWorld(worldID, func(world *models.WorldSetting) []SectionConflict {
+		if conflicts := sectionConflicts(world, generated, base); len(conflicts) > 0 {
+			return conflicts
+		}
+		before = sectionVersions(world)
+		for _, section := range sections {
+			copyWorldSection(world, generated, section)
+			world.BumpSectionVersion(section)
+		}
+		return nil
+	})
+	if writeWorldSaveError(c, conflicts, err) {
 		return
 	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditRegenerate,
+		ResourceType: models.AuditResourceWorld,
+		ResourceID:   world.ID,
+		ProjectID:    projectID,
+		Summary:      "生成世界分节 " + stage,
+	}, before, sectionVersions(world))
 
 	// 返回生成的阶段
 	var result interface{}
@@ -429,10 +508,16 @@ func (h *WorldSettingHandler) GenerateWorldStage(c *gin.Context) {
 // @Param projectId path string true "项目ID"
 // @Param request body GenerateWorldStageRequest true "生成参数"
 // @Success 200 {object} APIResponse
-// @Router /api/v1/projects/{projectId}/world-stages/gacha [post]
+// @Router /api/v1/projects/{projectId}/world-gacha [post]
 func (h *WorldSettingHandler) GachaWorldSettings(c *gin.Context) {
 	projectID := c.Param("projectId")
 
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+	worldBuilder := h.worldBuilder.WithBudget(budget).WithLogger(requestLogger(c))
+
 	// 验证项目存在
 	project, err := h.db.GetProject(projectID)
 	if err != nil {
@@ -450,46 +535,40 @@ func (h *WorldSettingHandler) GachaWorldSettings(c *gin.Context) {
 	}
 
 	// 获取或创建世界设定
-	var world *models.WorldSetting
 	if project.WorldID != "" {
-		world, err = h.db.GetWorld(project.WorldID)
-		if err != nil {
+		if _, err := h.db.GetWorld(project.WorldID); err != nil {
 			// 如果找不到引用的世界，清除引用并重新创建
 			fmt.Printf("[WARN] Referenced world %s not found, creating new one\n", project.WorldID)
 			project.WorldID = ""
 		}
 	}
 
-	if project.WorldID == "" {
-		// 从请求参数中获取世界类型和风格
-		worldType := models.WorldFantasy // 默认奇幻
-		worldStyle := "通用"               // 默认风格
-
-		// 解析settings中的world_type和style
-		if req.Settings != nil {
-			if wt, ok := req.Settings["world_type"].(string); ok && wt != "" {
-				worldType = models.WorldType(wt)
-			}
-			if ws, ok := req.Settings["style"].(string); ok && ws != "" {
-				worldStyle = ws
-			}
-		}
+	// 从请求参数中获取世界类型和风格
+	worldType := models.WorldFantasy // 默认奇幻
+	worldStyle := "通用"               // 默认风格
 
-		// 创建新的世界设定
-		world = &models.WorldSetting{
-			ID:        db.GenerateID("world"),
-			Name:      project.Name + "的世界",
-			Type:      worldType,
-			Scale:     models.ScaleNation,
-			Style:     worldStyle,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+	// 解析settings中的world_type和style
+	if req.Settings != nil {
+		if wt, ok := req.Settings["world_type"].(string); ok && wt != "" {
+			worldType = models.WorldType(wt)
+		}
+		if ws, ok := req.Settings["style"].(string); ok && ws != "" {
+			worldStyle = ws
 		}
-		// 更新项目的 WorldID
-		project.WorldID = world.ID
-		h.db.SaveProject(project)
 	}
 
+	worldID, err := h.ensureWorld(project, worldType, worldStyle)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "创建世界设定失败", err.Error()))
+		return
+	}
+	world, err := h.db.GetWorld(worldID)
+	if err != nil {
+		c.JSON(http.StatusNotFound, errorResponse("NOT_FOUND", "世界设定不存在", ""))
+		return
+	}
+	base := sectionVersions(world, gachaSections...)
+
 	// 依次生成所有7个阶段
 	stages := []string{"philosophy", "worldview", "laws", "story_soil", "geography", "civilization_society"}
 
@@ -498,7 +577,7 @@ func (h *WorldSettingHandler) GachaWorldSettings(c *gin.Context) {
 		case "philosophy":
 			var result *models.Philosophy
 			var prompt string
-			result, prompt, err = h.worldBuilder.GenerateStage1(worldbuilder.Stage1Input{
+			result, prompt, err = worldBuilder.GenerateStage1(worldbuilder.Stage1Input{
 				WorldType: string(world.Type),
 				Theme:     req.Context,
 				Style:     world.Style,
@@ -513,7 +592,7 @@ func (h *WorldSettingHandler) GachaWorldSettings(c *gin.Context) {
 			if world.Philosophy.CoreQuestion != "" {
 				var result *models.Worldview
 				var prompt string
-				result, prompt, err = h.worldBuilder.GenerateStage2(worldbuilder.Stage2Input{
+				result, prompt, err = worldBuilder.GenerateStage2(worldbuilder.Stage2Input{
 					CoreQuestion: world.Philosophy.CoreQuestion,
 					HighestGood:  world.Philosophy.ValueSystem.HighestGood,
 					UltimateEvil: world.Philosophy.ValueSystem.UltimateEvil,
@@ -530,7 +609,7 @@ func (h *WorldSettingHandler) GachaWorldSettings(c *gin.Context) {
 				worldviewSummary := fmt.Sprintf("起源:%s 结构:%s", world.Worldview.Cosmology.Origin, world.Worldview.Cosmology.Structure)
 				var result *models.Laws
 				var prompt string
-				result, prompt, err = h.worldBuilder.GenerateStage3(worldbuilder.Stage3Input{
+				result, prompt, err = worldBuilder.GenerateStage3(worldbuilder.Stage3Input{
 					WorldType: string(world.Type),
 					Worldview: worldviewSummary,
 				})
@@ -549,7 +628,7 @@ func (h *WorldSettingHandler) GachaWorldSettings(c *gin.Context) {
 				}
 				var result *models.StorySoil
 				var prompt string
-				result, prompt, err = h.worldBuilder.GenerateStage4(worldbuilder.Stage4Input{
+				result, prompt, err = worldBuilder.GenerateStage4(worldbuilder.Stage4Input{
 					CoreQuestion:  world.Philosophy.CoreQuestion,
 					MainConflicts: mainConflicts,
 					WorldType:     string(world.Type),
@@ -567,7 +646,7 @@ func (h *WorldSettingHandler) GachaWorldSettings(c *gin.Context) {
 				civilizationNeeds := fmt.Sprintf("资源需求基于%s类型的世界", world.Type)
 				var result *models.Geography
 				var prompt string
-				result, prompt, err = h.worldBuilder.GenerateStage5(worldbuilder.Stage5Input{
+				result, prompt, err = worldBuilder.GenerateStage5(worldbuilder.Stage5Input{
 					WorldType:         string(world.Type),
 					WorldScale:        string(world.Scale),
 					LawsSummary:       lawsSummary,
@@ -582,17 +661,12 @@ func (h *WorldSettingHandler) GachaWorldSettings(c *gin.Context) {
 
 		case "civilization_society":
 			if len(world.Geography.Regions) > 0 {
-				geographySummary := fmt.Sprintf("%d个区域, 气候:%s", len(world.Geography.Regions), func() string {
-					if world.Geography.Climate != nil {
-						return world.Geography.Climate.Type
-					}
-					return "未知"
-				}())
+				geographySummary := worldbuilder.GeographySummary(&world.Geography)
 				valueSystem := fmt.Sprintf("最高善:%s", world.Philosophy.ValueSystem.HighestGood)
 
 				var result *worldbuilder.Stage6Result
 				var prompt string
-				result, prompt, err = h.worldBuilder.GenerateStage6(worldbuilder.Stage6Input{
+				result, prompt, err = worldBuilder.GenerateStage6(worldbuilder.Stage6Input{
 					WorldType:        string(world.Type),
 					GeographySummary: geographySummary,
 					ValueSystem:      valueSystem,
@@ -607,13 +681,30 @@ func (h *WorldSettingHandler) GachaWorldSettings(c *gin.Context) {
 		}
 	}
 
-	world.UpdatedAt = time.Now()
-
-	// 保存
-	if err := h.db.SaveWorld(world); err != nil {
-		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存失败", err.Error()))
+	// 抽卡耗时较长，重新读取后合并生成的分节，期间被编辑过的分节按冲突返回
+	generated := world
+	var before map[string]int
+	world, conflicts, err := h.updateWorld(worldID, func(world *models.WorldSetting) []SectionConflict {
+		if conflicts := sectionConflicts(world, generated, base); len(conflicts) > 0 {
+			return conflicts
+		}
+		before = sectionVersions(world)
+		for _, section := range gachaSections {
+			copyWorldSection(world, generated, section)
+			world.BumpSectionVersion(section)
+		}
+		return nil
+	})
+	if writeWorldSaveError(c, conflicts, err) {
 		return
 	}
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditRegenerate,
+		ResourceType: models.AuditResourceWorld,
+		ResourceID:   world.ID,
+		ProjectID:    projectID,
+		Summary:      "抽卡重新生成全部世界分节",
+	}, before, sectionVersions(world))
 
 	// 返回生成的所有阶段
 	c.JSON(http.StatusOK, successResponse(gin.H{
@@ -630,3 +721,138 @@ func (h *WorldSettingHandler) GachaWorldSettings(c *gin.Context) {
 		"message": "世界设定抽卡成功！",
 	}))
 }
+
+// gachaSections 抽卡生成的分节
+var gachaSections = []string{"philosophy", "worldview", "laws", "story_soil", "geography", "civilization", "society"}
+
+// maxWorldSaveAttempts 条件保存世界设定的最大尝试次数
+const maxWorldSaveAttempts = 3
+
+// ensureWorld 返回项目的世界设定ID，项目尚无世界时创建并关联
+func (h *WorldSettingHandler) ensureWorld(project *models.Project, worldType models.WorldType, style string) (string, error) {
+	if project.WorldID != "" {
+		if _, err := h.db.GetWorld(project.WorldID); err != nil {
+			return "", err
+		}
+		return project.WorldID, nil
+	}
+
+	world := &models.WorldSetting{
+		ID:        db.GenerateID("world"),
+		Name:      project.Name + "的世界",
+		Type:      worldType,
+		Scale:     models.ScaleNation,
+		Style:     style,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := h.db.SaveWorld(world); err != nil {
+		return "", err
+	}
+	// 更新项目的 WorldID
+	project.WorldID = world.ID
+	if err := h.db.SaveProject(project); err != nil {
+		return "", err
+	}
+	return world.ID, nil
+}
+
+// updateWorld 重新读取世界设定，由 merge 检查分节版本并写入分节，再按行版本条件保存；
+// 其间被其他请求保存时重新读取再试。merge 返回冲突时不保存
+func (h *WorldSettingHandler) updateWorld(worldID string, merge func(world *models.WorldSetting) []SectionConflict) (*models.WorldSetting, []SectionConflict, error) {
+	for attempt := 1; ; attempt++ {
+		world, err := h.db.GetWorld(worldID)
+		if err != nil {
+			return nil, nil, err
+		}
+		revision := world.Revision
+		if conflicts := merge(world); len(conflicts) > 0 {
+			return world, conflicts, nil
+		}
+		world.RebuildEntityIndex()
+		err = h.db.SaveWorldIfRevision(world, revision)
+		if !errors.Is(err, db.ErrWorldRevisionConflict) || attempt >= maxWorldSaveAttempts {
+			return world, nil, err
+		}
+	}
+}
+
+// writeWorldSaveError updateWorld 失败时写入响应，返回是否已写入
+func writeWorldSaveError(c *gin.Context, conflicts []SectionConflict, err error) bool {
+	switch {
+	case len(conflicts) > 0:
+		c.JSON(http.StatusConflict, APIResponse{
+			Success: false,
+			Data:    gin.H{"conflicts": conflicts},
+			Error: &ErrorInfo{
+				Code:    "VERSION_CONFLICT",
+				Message: "世界设定分节已被他人修改",
+				Details: fmt.Sprintf("%d个分节存在冲突", len(conflicts)),
+			},
+		})
+	case errors.Is(err, db.ErrWorldRevisionConflict):
+		c.JSON(http.StatusConflict, errorResponse("VERSION_CONFLICT", "世界设定正在被他人修改，请稍后重试", ""))
+	case err != nil:
+		c.JSON(http.StatusInternalServerError, errorResponse("SAVE_FAILED", "保存失败", err.Error()))
+	default:
+		return false
+	}
+	return true
+}
+
+// sectionConflicts 返回 base 记录的版本之后在 current 中被修改过的分节，Submitted 取 generated 中的内容
+func sectionConflicts(current, generated *models.WorldSetting, base map[string]int) []SectionConflict {
+	sections := make([]string, 0, len(base))
+	for section := range base {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+
+	var conflicts []SectionConflict
+	for _, section := range sections {
+		if v := current.SectionVersion(section); v != base[section] {
+			conflicts = append(conflicts, SectionConflict{
+				Section:        section,
+				BaseVersion:    base[section],
+				CurrentVersion: v,
+				Current:        current.Section(section),
+				Submitted:      generated.Section(section),
+			})
+		}
+	}
+	return conflicts
+}
+
+// copyWorldSection 把 src 的分节内容写入 dst
+func copyWorldSection(dst, src *models.WorldSetting, section string) {
+	switch section {
+	case "philosophy":
+		dst.Philosophy = src.Philosophy
+	case "worldview":
+		dst.Worldview = src.Worldview
+	case "laws":
+		dst.Laws = src.Laws
+	case "story_soil":
+		dst.StorySoil = src.StorySoil
+	case "geography":
+		dst.Geography = src.Geography
+	case "civilization":
+		dst.Civilization = src.Civilization
+	case "society":
+		dst.Society = src.Society
+	case "history":
+		dst.History = src.History
+	}
+}
+
+// sectionVersions 返回指定分节（默认全部分节）的当前版本
+func sectionVersions(world *models.WorldSetting, sections ...string) map[string]int {
+	if len(sections) == 0 {
+		sections = models.WorldSections
+	}
+	versions := make(map[string]int, len(sections))
+	for _, section := range sections {
+		versions[section] = world.SectionVersion(section)
+	}
+	return versions
+}
@@ -23,7 +23,7 @@ type WriterHandler struct {
 
 // NewWriterHandler 创建写作器处理器
 func NewWriterHandler(database db.Database) *WriterHandler {
-	cfg, err := config.LoadDefault()
+	cfg, err := config.Current()
 	if err != nil {
 		cfg = &config.Config{}
 	}
@@ -107,14 +107,20 @@ func (h *WriterHandler) ContinueChapter(c *gin.Context) {
 	// 获取叙事蓝图（如果有细纲则使用）
 	blueprint, _ := h.db.GetNarrativeBlueprint(projectID)
 
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+
 	// 调用AI生成继续内容
-	generatedText, err := h.generateContinuation(project, chapter, worldSettings, characters, blueprint, req)
+	generatedText, err := h.generateContinuation(budget, project, chapter, worldSettings, characters, blueprint, req)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "生成内容失败", err.Error()))
 		return
 	}
 
 	// 更新章节内容
+	before := *chapter
 	newContent := chapter.Content + generatedText
 	chapter.Content = newContent
 	chapter.WordCount = utf8.RuneCountInString(newContent)
@@ -124,6 +130,7 @@ func (h *WriterHandler) ContinueChapter(c *gin.Context) {
 		c.JSON(http.StatusInternalServerError, errorResponse("INTERNAL_ERROR", "保存章节失败", err.Error()))
 		return
 	}
+	auditContinuation(c, &before, chapter)
 
 	c.JSON(http.StatusOK, successResponse(gin.H{
 		"chapter": gin.H{
@@ -191,6 +198,11 @@ func (h *WriterHandler) ContinueChapterStream(c *gin.Context) {
 	characters := h.db.ListCharactersByWorld(project.WorldID)
 	blueprint, _ := h.db.GetNarrativeBlueprint(projectID)
 
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+
 	// 设置SSE Header
 	c.Header("Content-Type", "text/event-stream")
 	c.Header("Cache-Control", "no-cache")
@@ -200,7 +212,7 @@ func (h *WriterHandler) ContinueChapterStream(c *gin.Context) {
 	// 准备回调
 	var fullContent strings.Builder
 
-	err = h.generateContinuationStream(project, chapter, worldSettings, characters, blueprint, req, func(content string) bool {
+	err = h.generateContinuationStream(budget, project, chapter, worldSettings, characters, blueprint, req, func(content string) bool {
 		// 检查客户端是否断开
 		select {
 		case <-c.Request.Context().Done():
@@ -241,16 +253,31 @@ func (h *WriterHandler) ContinueChapterStream(c *gin.Context) {
 	// generatedText = strings.TrimSpace(generatedText)
 	// (流式可能导致Trim破坏格式，暂时不Trim或者只Trim首部)
 
+	before := *chapter
 	newContent := chapter.Content + generatedText
 	chapter.Content = newContent
 	chapter.WordCount = utf8.RuneCountInString(newContent)
 	chapter.AIWordCount += utf8.RuneCountInString(generatedText)
 
-	h.db.SaveChapter(chapter)
+	if err := h.db.SaveChapter(chapter); err == nil {
+		auditContinuation(c, &before, chapter)
+	}
+}
+
+// auditContinuation 记录AI续写对章节正文的追加
+func auditContinuation(c *gin.Context, before, after *models.Chapter) {
+	recordAudit(c, &models.AuditLog{
+		Action:       models.AuditRegenerate,
+		ResourceType: models.AuditResourceChapter,
+		ResourceID:   after.ID,
+		ProjectID:    after.ProjectID,
+		Summary:      fmt.Sprintf("AI续写第%d章 %d 字", after.ChapterNum, after.WordCount-before.WordCount),
+	}, before, after)
 }
 
-// generateContinuationStream 流式生成
+// generateContinuationStream 流式生成，budget 为发起用户的月度预算
 func (h *WriterHandler) generateContinuationStream(
+	budget llm.Budget,
 	project *models.Project,
 	chapter *models.Chapter,
 	worldSettings *models.WorldSetting,
@@ -263,6 +290,7 @@ func (h *WriterHandler) generateContinuationStream(
 	if err != nil {
 		return fmt.Errorf("创建LLM客户端失败: %w", err)
 	}
+	client = client.WithBudget(budget)
 
 	prompt := h.buildContinuationPrompt(project, chapter, worldSettings, characters, blueprint, req)
 	systemPrompt := h.buildWriterSystemPrompt(req)
@@ -270,8 +298,9 @@ func (h *WriterHandler) generateContinuationStream(
 	return client.GenerateStream(prompt, systemPrompt, callback)
 }
 
-// generateContinuation 生成继续内容
+// generateContinuation 生成继续内容，budget 为发起用户的月度预算
 func (h *WriterHandler) generateContinuation(
+	budget llm.Budget,
 	project *models.Project,
 	chapter *models.Chapter,
 	worldSettings *models.WorldSetting,
@@ -284,6 +313,7 @@ func (h *WriterHandler) generateContinuation(
 	if err != nil {
 		return "", fmt.Errorf("创建LLM客户端失败: %w", err)
 	}
+	client = client.WithBudget(budget)
 
 	// 构建提示词
 	prompt := h.buildContinuationPrompt(project, chapter, worldSettings, characters, blueprint, req)
@@ -571,8 +601,13 @@ func (h *WriterHandler) GenerateChapterOutline(c *gin.Context) {
 		return
 	}
 
+	budget, ok := userBudget(c, h.db)
+	if !ok {
+		return
+	}
+
 	// 生成场景指令
-	scenes, err := h.generateSceneInstructions(project, worldSettings, blueprint, targetPlan)
+	scenes, err := h.generateSceneInstructions(budget, project, worldSettings, blueprint, targetPlan)
 	if err != nil {
 		c.JSON(http.StatusInternalServerError, errorResponse("GENERATION_ERROR", "生成场景指令失败", err.Error()))
 		return
@@ -594,8 +629,9 @@ func (h *WriterHandler) GenerateChapterOutline(c *gin.Context) {
 	}))
 }
 
-// generateSceneInstructions 生成场景指令
+// generateSceneInstructions 生成场景指令，budget 为发起用户的月度预算
 func (h *WriterHandler) generateSceneInstructions(
+	budget llm.Budget,
 	project *models.Project,
 	worldSettings *models.WorldSetting,
 	blueprint *models.NarrativeBlueprint,
@@ -605,6 +641,7 @@ func (h *WriterHandler) generateSceneInstructions(
 	if err != nil {
 		return nil, fmt.Errorf("创建LLM客户端失败: %w", err)
 	}
+	client = client.WithBudget(budget)
 
 	prompt := h.buildScenePrompt(project, worldSettings, blueprint, plan)
 	systemPrompt := `你是专业的小说场景设计师，负责将章节规划拆解为详细的场景级写作指令。
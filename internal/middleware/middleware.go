@@ -2,11 +2,11 @@
 package middleware
 
 import (
-	"log"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/xlei/xupu/pkg/logx"
 )
 
 // Logger 日志中间件
@@ -20,12 +20,13 @@ func Logger() gin.HandlerFunc {
 		latency := time.Since(start)
 		statusCode := c.Writer.Status()
 
-		log.Printf("[%s] %s %s %d %v",
-			c.Request.Method,
-			path,
-			c.ClientIP(),
-			statusCode,
-			latency,
+		// RequestID 中间件在 Logger 之后注册，c.Next 返回后才能取到请求ID
+		logx.WithTrace(c.GetString("RequestID")).Info("HTTP请求",
+			"method", c.Request.Method,
+			"path", path,
+			"client_ip", c.ClientIP(),
+			"status", statusCode,
+			"latency", latency,
 		)
 	}
 }
@@ -35,7 +36,7 @@ func Recovery() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		defer func() {
 			if err := recover(); err != nil {
-				log.Printf("PANIC: %v", err)
+				logx.WithTrace(c.GetString("RequestID")).Error("PANIC", "error", err)
 				c.JSON(500, gin.H{
 					"error": "内部服务器错误",
 				})
@@ -51,7 +52,7 @@ func CORS() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		c.Header("Access-Control-Allow-Origin", "*")
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-Requested-With, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-Requested-With, Authorization, X-API-Key")
 		c.Header("Access-Control-Expose-Headers", "Content-Length")
 		c.Header("Access-Control-Max-Age", "86400")
 
@@ -65,6 +66,7 @@ func CORS() gin.HandlerFunc {
 }
 
 // RequestID 请求ID中间件
+// 同时在上下文中放入带 trace_id 的日志（键为 Logger），处理器据此串联本次请求中各模块的日志
 func RequestID() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		requestID := c.GetHeader("X-Request-ID")
@@ -72,6 +74,7 @@ func RequestID() gin.HandlerFunc {
 			requestID = uuid.New().String()
 		}
 		c.Set("RequestID", requestID)
+		c.Set("Logger", logx.WithTrace(requestID))
 		c.Header("X-Request-ID", requestID)
 		c.Next()
 	}
@@ -37,22 +37,65 @@ type PromptTemplate struct {
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// PromptExample 少样本示例，按提示词角色和题材检索后附在提示词末尾
+type PromptExample struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	Role      string    `json:"role" gorm:"size:100;index"` // 提示词角色，如 writer.scene、narrative.critique
+	Genre     string    `json:"genre" gorm:"size:50;index"` // 题材（世界类型或故事类型，如 xianxia、mystery），为空表示通用
+	Title     string    `json:"title" gorm:"size:200"`
+	Input     string    `json:"input" gorm:"type:text"`                // 示例的任务条件，可为空
+	Output    string    `json:"output" gorm:"type:text"`               // 示例输出
+	Tags      []string  `json:"tags" gorm:"type:json;serializer:json"` // 关键词，出现在提示词中时优先选用
+	Enabled   bool      `json:"enabled"`
+	CreatedBy string    `json:"created_by,omitempty" gorm:"size:100"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // ============================================
 // 叙事结构模板
 // ============================================
 
 // NarrativeTemplate 叙事模板
 type NarrativeTemplate struct {
-	ID          string    `json:"id" gorm:"primaryKey"` // e.g. "infinite_flow"
+	ID          string    `json:"id" gorm:"primaryKey"`                    // e.g. "infinite_flow"
+	UserID      string    `json:"user_id,omitempty" gorm:"size:100;index"` // 自定义节拍表的所有者，内置结构为空
 	Name        string    `json:"name" gorm:"size:100;not null"`
 	Description string    `json:"description" gorm:"size:500"`
 	Structure   JSON      `json:"structure" gorm:"type:json"`    // Definition of acts/stages
 	PromptRules JSON      `json:"prompt_rules" gorm:"type:json"` // How to prompt for this structure
 	IsActive    bool      `json:"is_active" gorm:"default:true"`
+	Version     int       `json:"version" gorm:"default:1"` // 每次修改加一，导入模板包时用于判断新旧
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
 
+// ============================================
+// 提示词实验
+// ============================================
+
+// 提示词实验状态
+const (
+	ExperimentStatusRunning   = "running"
+	ExperimentStatusCompleted = "completed"
+	ExperimentStatusFailed    = "failed"
+)
+
+// PromptExperiment 提示词A/B实验
+type PromptExperiment struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	WorldID   string    `json:"world_id" gorm:"size:100;index"`
+	Role      string    `json:"role" gorm:"size:100"`      // 被对比的提示词角色，如 conflict_designer
+	Phase     int       `json:"phase"`                     // 对比的演化阶段
+	Status    string    `json:"status" gorm:"size:20"`     // running, completed, failed
+	Variants  JSON      `json:"variants" gorm:"type:json"` // []PromptVariant
+	Report    JSON      `json:"report" gorm:"type:json"`   // ExperimentReport
+	Winner    string    `json:"winner" gorm:"size:100"`
+	Error     string    `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // ============================================
 // 通用 JSON 类型
 // ============================================
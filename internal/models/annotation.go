@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+// ============================================
+// 章节批注
+// ============================================
+
+// 批注锚点状态
+const (
+	AnchorExact    = "exact"    // 原位置的文本与批注时一致
+	AnchorMoved    = "moved"    // 正文改动后按引文或段落重新定位
+	AnchorOrphaned = "orphaned" // 批注的文本已被删改，无法定位
+)
+
+// Annotation 锚定在章节正文上的批注：按字符区间或段落哈希定位，下面挂讨论串
+type Annotation struct {
+	ID            string     `json:"id" gorm:"primaryKey"`
+	ProjectID     string     `json:"project_id" gorm:"size:100;index"`
+	ChapterID     string     `json:"chapter_id" gorm:"size:100;index"`
+	UserID        string     `json:"user_id" gorm:"size:100"`
+	Username      string     `json:"username" gorm:"size:100"`
+	Start         int        `json:"start"` // 批注区间（按字符计，不含 end）；按段落批注时为段落区间
+	End           int        `json:"end"`
+	Quote         string     `json:"quote" gorm:"type:text"`              // 批注时区间内的原文，正文改动后据此重新定位
+	ParagraphHash string     `json:"paragraph_hash" gorm:"size:20;index"` // 区间起点所在段落的哈希
+	Body          string     `json:"body" gorm:"type:text"`
+	Resolved      bool       `json:"resolved" gorm:"index"`
+	ResolvedBy    string     `json:"resolved_by,omitempty" gorm:"size:100"`
+	ResolvedAt    *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// AnnotationReply 批注下的回复
+type AnnotationReply struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	AnnotationID string    `json:"annotation_id" gorm:"size:100;index"`
+	UserID       string    `json:"user_id" gorm:"size:100"`
+	Username     string    `json:"username" gorm:"size:100"`
+	Body         string    `json:"body" gorm:"type:text"`
+	CreatedAt    time.Time `json:"created_at"`
+}
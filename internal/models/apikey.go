@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// ============================================
+// API密钥
+// ============================================
+
+// API密钥权限范围
+const (
+	APIKeyScopeRead  = "read"  // 只读请求（GET/HEAD）
+	APIKeyScopeWrite = "write" // 其余请求，包括提交生成任务
+)
+
+// APIKeyScopes 可授予的权限范围
+var APIKeyScopes = []string{APIKeyScopeRead, APIKeyScopeWrite}
+
+// ValidAPIKeyScope 是否为可授予的权限范围
+func ValidAPIKeyScope(scope string) bool {
+	for _, s := range APIKeyScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKey 供脚本和CI调用接口的长期密钥，只保存哈希，明文只在创建时返回一次
+type APIKey struct {
+	ID         string     `json:"id" gorm:"primaryKey"`
+	UserID     string     `json:"user_id" gorm:"size:100;index"`
+	Name       string     `json:"name" gorm:"size:100"`
+	Prefix     string     `json:"prefix" gorm:"size:16"` // 明文前几位，便于用户辨认
+	KeyHash    string     `json:"-" gorm:"size:64;uniqueIndex"`
+	Scopes     []string   `json:"scopes" gorm:"type:json;serializer:json"`
+	RateLimit  int        `json:"rate_limit"` // 每分钟请求数上限，0 使用默认值
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// Active 密钥在 now 时是否可用（未吊销、未过期）
+func (k *APIKey) Active(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// HasScope 是否授予了权限范围
+func (k *APIKey) HasScope(scope string) bool {
+	for _, s := range k.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyAudit 通过API密钥发起的写请求记录，JobID 为该请求提交的任务/批次/项目
+type APIKeyAudit struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	KeyID     string    `json:"key_id" gorm:"size:100;index"`
+	UserID    string    `json:"user_id" gorm:"size:100"`
+	Method    string    `json:"method" gorm:"size:10"`
+	Path      string    `json:"path" gorm:"size:255"`
+	Status    int       `json:"status"`
+	JobID     string    `json:"job_id,omitempty" gorm:"size:100;index"`
+	ClientIP  string    `json:"client_ip" gorm:"size:64"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// ============================================
+// 内容变更审计
+// ============================================
+
+// 审计动作
+const (
+	AuditCreate     = "create"
+	AuditUpdate     = "update"
+	AuditDelete     = "delete"
+	AuditRegenerate = "regenerate" // 由模型重新生成覆盖原内容
+	AuditImport     = "import"
+	AuditPublish    = "publish"
+	AuditRestore    = "restore" // 从回收站恢复
+)
+
+// 被审计的资源类型
+const (
+	AuditResourceWorld     = "world"
+	AuditResourceChapter   = "chapter"
+	AuditResourceTemplate  = "template" // 提示词模板、叙事结构、模板包
+	AuditResourceBeatSheet = "beat_sheet"
+	AuditResourceStyle     = "style_profile"
+	AuditResourceRelease   = "release"
+	AuditResourceProject   = "project"
+)
+
+// AuditActorSystem 定时任务等无请求上下文的变更记在系统名下
+const AuditActorSystem = "system"
+
+// AuditLog 内容变更记录，只追加不修改；Before/After 为变更前后的摘要而非完整内容
+type AuditLog struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	UserID       string    `json:"user_id" gorm:"size:100;index"` // 未登录接口为空，定时任务为 system
+	APIKeyID     string    `json:"api_key_id,omitempty" gorm:"size:100"`
+	Action       string    `json:"action" gorm:"size:20;index"`
+	ResourceType string    `json:"resource_type" gorm:"size:30;index:idx_audit_resource"`
+	ResourceID   string    `json:"resource_id" gorm:"size:100;index:idx_audit_resource"`
+	ProjectID    string    `json:"project_id,omitempty" gorm:"size:100;index"`
+	Summary      string    `json:"summary" gorm:"size:500"`
+	Before       string    `json:"before,omitempty" gorm:"type:text"`
+	After        string    `json:"after,omitempty" gorm:"type:text"`
+	TraceID      string    `json:"trace_id,omitempty" gorm:"size:64"`
+	ClientIP     string    `json:"client_ip,omitempty" gorm:"size:64"`
+	CreatedAt    time.Time `json:"created_at" gorm:"index"`
+}
+
+// AuditFilter 审计记录查询条件，零值字段不参与过滤
+type AuditFilter struct {
+	UserID       string
+	Action       string
+	ResourceType string
+	ResourceID   string
+	ProjectID    string
+	Since        *time.Time
+	Until        *time.Time
+	Limit        int
+}
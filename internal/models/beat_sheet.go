@@ -0,0 +1,111 @@
+package models
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ============================================
+// 自定义节拍表
+// ============================================
+
+// BeatSheet 用户自定义的叙事结构：若干具名节拍及其在全书中的位置
+// 以 NarrativeTemplate 保存，Structure 字段存放 {"beats": [...]}
+type BeatSheet struct {
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	Beats []StructureBeat `json:"beats"`
+}
+
+// StructureBeat 节拍表中的一个节拍
+type StructureBeat struct {
+	Name        string  `json:"name"`
+	Position    float64 `json:"position"` // 在全书中的位置，百分比 0-100
+	Description string  `json:"description,omitempty"`
+}
+
+// OutlineBeat 节拍在大纲中的落点：对应的章节和关键事件
+type OutlineBeat struct {
+	Name        string  `json:"name"`
+	Position    float64 `json:"position"`
+	Description string  `json:"description,omitempty"`
+	Chapter     int     `json:"chapter"`
+	Event       string  `json:"event"`
+}
+
+// Normalize 去除空白、按位置排序并校验节拍：至少两个，名称不重复，位置在 0-100 之间
+func (s *BeatSheet) Normalize() error {
+	if len(s.Beats) < 2 {
+		return fmt.Errorf("节拍表至少需要两个节拍")
+	}
+	seen := make(map[string]bool, len(s.Beats))
+	for i := range s.Beats {
+		b := &s.Beats[i]
+		b.Name = strings.TrimSpace(b.Name)
+		b.Description = strings.TrimSpace(b.Description)
+		if b.Name == "" {
+			return fmt.Errorf("第%d个节拍没有名称", i+1)
+		}
+		if seen[b.Name] {
+			return fmt.Errorf("节拍名称重复: %s", b.Name)
+		}
+		seen[b.Name] = true
+		if b.Position < 0 || b.Position > 100 {
+			return fmt.Errorf("节拍%s的位置%.1f超出0-100", b.Name, b.Position)
+		}
+	}
+	sort.SliceStable(s.Beats, func(i, j int) bool { return s.Beats[i].Position < s.Beats[j].Position })
+	return nil
+}
+
+// beatSheetStructure 自定义节拍表在 NarrativeTemplate.Structure 中的格式
+type beatSheetStructure struct {
+	Beats []StructureBeat `json:"beats"`
+}
+
+// IsBeatSheet 模板是否为自定义节拍表（内置结构的 Structure 为 stages 格式）
+func (t *NarrativeTemplate) IsBeatSheet() bool {
+	var s beatSheetStructure
+	return len(t.Structure) > 0 && json.Unmarshal(t.Structure, &s) == nil && len(s.Beats) > 0
+}
+
+// BeatSheet 解析模板中的节拍表
+func (t *NarrativeTemplate) BeatSheet() (*BeatSheet, error) {
+	var s beatSheetStructure
+	if len(t.Structure) > 0 {
+		if err := json.Unmarshal(t.Structure, &s); err != nil {
+			return nil, fmt.Errorf("解析节拍表失败: %w", err)
+		}
+	}
+	if len(s.Beats) == 0 {
+		return nil, fmt.Errorf("模板%s不是自定义节拍表", t.ID)
+	}
+	return &BeatSheet{ID: t.ID, Name: t.Name, Beats: s.Beats}, nil
+}
+
+// SetBeats 把节拍写入模板的 Structure 字段
+func (t *NarrativeTemplate) SetBeats(beats []StructureBeat) error {
+	data, err := json.Marshal(beatSheetStructure{Beats: beats})
+	if err != nil {
+		return err
+	}
+	t.Structure = JSON(data)
+	return nil
+}
+
+// KeyEvents 大纲中的关键事件，按故事顺序
+func (o *StoryOutline) KeyEvents() []string {
+	candidates := []string{o.Act1.Setup, o.Act1.IncitingIncident, o.Act1.PlotPoint1}
+	candidates = append(candidates, o.Act2.RisingAction...)
+	candidates = append(candidates, o.Act2.Midpoint, o.Act2.AllIsLost, o.Act2.PlotPoint2, o.Act3.Climax, o.Act3.Resolution)
+
+	events := make([]string, 0, len(candidates))
+	for _, e := range candidates {
+		if e = strings.TrimSpace(e); e != "" {
+			events = append(events, e)
+		}
+	}
+	return events
+}
@@ -0,0 +1,53 @@
+package models
+
+import "time"
+
+// ============================================
+// 蓝图假设分支（what-if）
+// ============================================
+
+// 分支状态
+const (
+	BranchDraft     = "draft"     // 待比较
+	BranchCanon     = "canon"     // 已采纳为主线
+	BranchDiscarded = "discarded" // 已放弃
+)
+
+// BlueprintBranch 蓝图的假设分支：从某一章起改变关键事件的结果，重新规划此后的章节
+// 分支只保存分叉点之后的章节规划和场景指令，分叉点之前沿用主线
+type BlueprintBranch struct {
+	ID           string             `json:"id" gorm:"primaryKey"`
+	ProjectID    string             `json:"project_id" gorm:"size:100;index"`
+	BlueprintID  string             `json:"blueprint_id" gorm:"size:100;index"`
+	Name         string             `json:"name" gorm:"size:200"`
+	ForkChapter  int                `json:"fork_chapter"`             // 从该章起（含）与主线不同
+	Outcome      string             `json:"outcome" gorm:"type:text"` // 改变后的关键事件结果
+	Status       string             `json:"status" gorm:"size:20"`
+	ChapterPlans []ChapterPlan      `json:"chapter_plans" gorm:"type:json;serializer:json"`
+	Scenes       []SceneInstruction `json:"scenes" gorm:"type:json;serializer:json"`
+	State        JSON               `json:"state,omitempty" gorm:"type:json"` // 分支的演化状态快照（narrative.EvolutionState）
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// Apply 把分支的章节规划和场景指令套用到主线蓝图的副本上，分叉点之前的内容沿用主线
+func (b *BlueprintBranch) Apply(canon *NarrativeBlueprint) *NarrativeBlueprint {
+	merged := *canon
+	merged.ID = b.ID
+	merged.ChapterPlans = make([]ChapterPlan, 0, len(canon.ChapterPlans))
+	for _, p := range canon.ChapterPlans {
+		if p.Chapter < b.ForkChapter {
+			merged.ChapterPlans = append(merged.ChapterPlans, p)
+		}
+	}
+	merged.ChapterPlans = append(merged.ChapterPlans, b.ChapterPlans...)
+
+	merged.Scenes = make([]SceneInstruction, 0, len(canon.Scenes))
+	for _, s := range canon.Scenes {
+		if s.Chapter < b.ForkChapter {
+			merged.Scenes = append(merged.Scenes, s)
+		}
+	}
+	merged.Scenes = append(merged.Scenes, b.Scenes...)
+	return &merged
+}
@@ -0,0 +1,138 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// ============================================
+// 角色卡
+// ============================================
+
+// 角色卡中可由用户锁定的字段（取值同 JSON 字段名）
+const (
+	SheetFieldRole              = "role"
+	SheetFieldConsciousWant     = "conscious_want"
+	SheetFieldUnconsciousNeed   = "unconscious_need"
+	SheetFieldFear              = "fear"
+	SheetFieldMaskingBehavior   = "masking_behavior"
+	SheetFieldSecrets           = "secrets"
+	SheetFieldInternalConflicts = "internal_conflicts"
+	SheetFieldCurrentEmotion    = "current_emotion"
+)
+
+// CharacterSheetFields 所有可锁定字段
+var CharacterSheetFields = []string{
+	SheetFieldRole,
+	SheetFieldConsciousWant,
+	SheetFieldUnconsciousNeed,
+	SheetFieldFear,
+	SheetFieldMaskingBehavior,
+	SheetFieldSecrets,
+	SheetFieldInternalConflicts,
+	SheetFieldCurrentEmotion,
+}
+
+// CharacterSheet 角色卡：演化生成的角色状态落库后的可编辑版本
+// 被锁定的字段在重新演化时保持用户设定，场景生成也以角色卡为准
+type CharacterSheet struct {
+	ID                string    `json:"id" gorm:"primaryKey"`
+	WorldID           string    `json:"world_id" gorm:"size:100;index"`
+	BlueprintID       string    `json:"blueprint_id" gorm:"size:100"` // 最近一次写入的蓝图
+	CharacterID       string    `json:"character_id" gorm:"size:100"` // 演化中的角色ID，场景指令按此引用
+	Name              string    `json:"name" gorm:"size:100;not null"`
+	Role              string    `json:"role" gorm:"size:50"`
+	ConsciousWant     string    `json:"conscious_want" gorm:"type:text"`
+	UnconsciousNeed   string    `json:"unconscious_need" gorm:"type:text"`
+	Fear              string    `json:"fear" gorm:"type:text"`
+	MaskingBehavior   []string  `json:"masking_behavior" gorm:"type:json;serializer:json"`
+	Secrets           []string  `json:"secrets" gorm:"type:json;serializer:json"`
+	InternalConflicts []string  `json:"internal_conflicts" gorm:"type:json;serializer:json"`
+	CurrentEmotion    string    `json:"current_emotion" gorm:"size:50"`
+	ArcProgress       float64   `json:"arc_progress"`
+	Locked            []string  `json:"locked" gorm:"type:json;serializer:json"` // 用户锁定的字段
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// ValidSheetField 是否为可锁定字段
+func ValidSheetField(field string) bool {
+	for _, f := range CharacterSheetFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// IsLocked 字段是否被用户锁定
+func (s *CharacterSheet) IsLocked(field string) bool {
+	for _, f := range s.Locked {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// Lock 锁定字段
+func (s *CharacterSheet) Lock(fields ...string) error {
+	for _, field := range fields {
+		if !ValidSheetField(field) {
+			return fmt.Errorf("不可锁定的字段: %s", field)
+		}
+		if !s.IsLocked(field) {
+			s.Locked = append(s.Locked, field)
+		}
+	}
+	return nil
+}
+
+// Unlock 解除字段锁定
+func (s *CharacterSheet) Unlock(fields ...string) {
+	kept := make([]string, 0, len(s.Locked))
+	for _, f := range s.Locked {
+		unlock := false
+		for _, field := range fields {
+			if f == field {
+				unlock = true
+				break
+			}
+		}
+		if !unlock {
+			kept = append(kept, f)
+		}
+	}
+	s.Locked = kept
+}
+
+// MergeGenerated 用新生成的角色卡更新未锁定的字段，锁定字段和角色卡ID保持不变
+func (s *CharacterSheet) MergeGenerated(generated *CharacterSheet) {
+	s.BlueprintID = generated.BlueprintID
+	s.CharacterID = generated.CharacterID
+	s.ArcProgress = generated.ArcProgress
+	if !s.IsLocked(SheetFieldRole) {
+		s.Role = generated.Role
+	}
+	if !s.IsLocked(SheetFieldConsciousWant) {
+		s.ConsciousWant = generated.ConsciousWant
+	}
+	if !s.IsLocked(SheetFieldUnconsciousNeed) {
+		s.UnconsciousNeed = generated.UnconsciousNeed
+	}
+	if !s.IsLocked(SheetFieldFear) {
+		s.Fear = generated.Fear
+	}
+	if !s.IsLocked(SheetFieldMaskingBehavior) {
+		s.MaskingBehavior = generated.MaskingBehavior
+	}
+	if !s.IsLocked(SheetFieldSecrets) {
+		s.Secrets = generated.Secrets
+	}
+	if !s.IsLocked(SheetFieldInternalConflicts) {
+		s.InternalConflicts = generated.InternalConflicts
+	}
+	if !s.IsLocked(SheetFieldCurrentEmotion) {
+		s.CurrentEmotion = generated.CurrentEmotion
+	}
+}
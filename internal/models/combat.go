@@ -0,0 +1,36 @@
+package models
+
+// ============================================
+// 战斗分解
+// ============================================
+
+// SceneTypeAction 动作场景类型（见 ChapterPlan.SceneTypes）
+const SceneTypeAction = "动作"
+
+// SceneType 章节中第 scene 个场景的类型，章节规划没有给出时返回空
+// 章节规划的场景类型按场景顺序排列
+func (p *ChapterPlan) SceneType(scene int) string {
+	if scene < 1 || scene > len(p.SceneTypes) {
+		return ""
+	}
+	return p.SceneTypes[scene-1]
+}
+
+// CombatChoreography 战斗场景的分解动作，写正文时必须按此推进
+type CombatChoreography struct {
+	Terrain string       `json:"terrain"` // 战场地形与可利用的环境
+	Beats   []CombatBeat `json:"beats"`
+	Outcome string       `json:"outcome"` // 战斗结果
+}
+
+// CombatBeat 一个回合的攻防
+type CombatBeat struct {
+	Actor    string `json:"actor"`
+	Target   string `json:"target,omitempty"`
+	Position string `json:"position"`          // 双方站位、距离的变化
+	Ability  string `json:"ability,omitempty"` // 使用的招式、法术或异能
+	Cost     string `json:"cost,omitempty"`    // 按世界设定付出的代价（灵力消耗、反噬等）
+	Action   string `json:"action"`            // 具体动作
+	Effect   string `json:"effect"`            // 结果
+	Injury   string `json:"injury,omitempty"`  // 本回合造成的伤势
+}
@@ -0,0 +1,73 @@
+package models
+
+import "time"
+
+// ============================================
+// 竞品分析
+// ============================================
+
+// CompetitorAnalysis 竞品分析：用户粘贴或上传的竞品简介/章节目录，提取出的结构以及与项目蓝图的对比
+type CompetitorAnalysis struct {
+	ID         string                `json:"id" gorm:"primaryKey"`
+	ProjectID  string                `json:"project_id" gorm:"size:100;index"`
+	UserID     string                `json:"user_id" gorm:"size:100;index"`
+	Title      string                `json:"title" gorm:"size:200"`
+	Source     string                `json:"source" gorm:"type:text"` // 原始简介/章节目录
+	Structure  CompetitorStructure   `json:"structure" gorm:"type:json;serializer:json"`
+	Comparison *CompetitorComparison `json:"comparison,omitempty" gorm:"type:json;serializer:json"` // 项目没有蓝图时为空
+	CreatedAt  time.Time             `json:"created_at"`
+}
+
+// CompetitorStructure 竞品结构
+type CompetitorStructure struct {
+	Genre        string           `json:"genre"`
+	Premise      string           `json:"premise"`       // 一句话核心设定
+	ChapterCount int              `json:"chapter_count"` // 章节目录中的章节数，只有简介时为0
+	Beats        []CompetitorBeat `json:"beats"`
+	Tropes       []string         `json:"tropes"` // 套路/卖点，如 退婚流、系统、重生
+	Pacing       CompetitorPacing `json:"pacing"`
+	Strengths    []string         `json:"strengths"` // 吸引读者的地方
+	Weaknesses   []string         `json:"weaknesses"`
+}
+
+// CompetitorBeat 竞品的结构节拍
+type CompetitorBeat struct {
+	Name     string  `json:"name"`     // 如 开篇钩子、激励事件、中点、至暗时刻、高潮
+	Position float64 `json:"position"` // 在全书中的相对位置（0-1）
+	Chapter  int     `json:"chapter,omitempty"`
+	Summary  string  `json:"summary"`
+}
+
+// CompetitorPacing 竞品节奏
+type CompetitorPacing struct {
+	Speed          string  `json:"speed"`           // fast, medium, slow
+	FirstHookAt    int     `json:"first_hook_at"`   // 第一个爽点/钩子所在章节
+	PayoffInterval float64 `json:"payoff_interval"` // 平均每隔几章一个爽点
+	Notes          string  `json:"notes"`
+}
+
+// CompetitorComparison 竞品与项目蓝图的对比
+type CompetitorComparison struct {
+	SharedTropes     []string                     `json:"shared_tropes"`
+	CompetitorTropes []string                     `json:"competitor_tropes"` // 只有竞品用到的套路
+	OwnTropes        []string                     `json:"own_tropes"`        // 只有本项目用到的套路
+	BeatTiming       []BeatTimingDiff             `json:"beat_timing"`
+	PacingNote       string                       `json:"pacing_note"`
+	Opportunities    []DifferentiationOpportunity `json:"opportunities"`
+}
+
+// BeatTimingDiff 同一节拍在竞品和本项目中的位置差
+type BeatTimingDiff struct {
+	Beat       string  `json:"beat"`
+	Competitor float64 `json:"competitor"` // 相对位置（0-1）
+	Own        float64 `json:"own"`
+	Delta      float64 `json:"delta"` // Own - Competitor，正数表示本项目更晚
+}
+
+// DifferentiationOpportunity 差异化机会
+type DifferentiationOpportunity struct {
+	Area       string `json:"area"` // premise/trope/pacing/structure/character
+	Competitor string `json:"competitor"`
+	Own        string `json:"own"`
+	Suggestion string `json:"suggestion"`
+}
@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// ============================================
+// 内容合规
+// ============================================
+
+// 违规严重程度
+const (
+	ComplianceBlock = "block" // 发布前必须处理
+	ComplianceWarn  = "warn"  // 只提示
+)
+
+// ComplianceSettings 项目的内容合规配置
+type ComplianceSettings struct {
+	Policy       string `json:"policy"`        // 策略集ID，为空时使用配置中的默认策略集
+	AutoSanitize bool   `json:"auto_sanitize"` // 发布前发现必须处理的违规时，自动请求改写
+}
+
+// ComplianceViolation 一处违规
+type ComplianceViolation struct {
+	Chapter   int    `json:"chapter"`
+	Paragraph int    `json:"paragraph"` // 段落序号（从1开始）
+	Category  string `json:"category"`
+	Severity  string `json:"severity"` // block/warn
+	Term      string `json:"term"`     // 命中的词语或正则匹配到的文本
+	Excerpt   string `json:"excerpt"`
+}
+
+// ComplianceReport 合规检查报告
+type ComplianceReport struct {
+	Policy     string                `json:"policy"`
+	Chapters   int                   `json:"chapters"` // 检查的章节数
+	Violations []ComplianceViolation `json:"violations"`
+	Blocking   int                   `json:"blocking"` // 必须处理的违规数
+	ScannedAt  time.Time             `json:"scanned_at"`
+}
+
+// Add 追加违规并统计必须处理的数量
+func (r *ComplianceReport) Add(violations ...ComplianceViolation) {
+	for _, v := range violations {
+		if v.Severity == ComplianceBlock {
+			r.Blocking++
+		}
+	}
+	r.Violations = append(r.Violations, violations...)
+}
@@ -0,0 +1,55 @@
+package models
+
+import "time"
+
+// ============================================
+// 连续性事实
+// ============================================
+
+// 事实类别
+const (
+	FactAppearance = "appearance" // 外貌（瞳色、发色、伤疤等）
+	FactInjury     = "injury"     // 伤势、病症
+	FactPossession = "possession" // 随身物品、所有物
+	FactState      = "state"      // 其他状态（境界、身份、所在地等）
+)
+
+// 事实来源
+const (
+	FactFromProse  = "prose"  // 从正文提取，重新检查该章时替换
+	FactFromManual = "manual" // 作者手工登记
+)
+
+// ContinuityFact 正文确立的一条物理或状态细节，如“林青云 左臂：骨折，缠着绷带”
+// 正文明确写出变化（伤愈、物品遗失）时旧事实在该章结束，新值另起一条
+type ContinuityFact struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	ProjectID    string    `json:"project_id" gorm:"size:100;index"`
+	Subject      string    `json:"subject" gorm:"size:200"`   // 角色或物品名
+	Attribute    string    `json:"attribute" gorm:"size:200"` // 属性，如“瞳色”“左臂”“佩剑”
+	Value        string    `json:"value" gorm:"type:text"`
+	Category     string    `json:"category" gorm:"size:20"`
+	Permanent    bool      `json:"permanent"`                           // 通常不会改变的细节（瞳色、胎记）
+	Chapter      int       `json:"chapter"`                             // 确立的章节，手工登记的事实为 0
+	EndedChapter int       `json:"ended_chapter"`                       // 被新值取代的章节，0 表示仍然有效
+	Evidence     string    `json:"evidence,omitempty" gorm:"type:text"` // 正文原句
+	Source       string    `json:"source" gorm:"size:20"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// ActiveAt 写第 chapter 章时该事实是否有效：在此前确立，且没有在此前被取代
+func (f *ContinuityFact) ActiveAt(chapter int) bool {
+	return f.Chapter < chapter && (f.EndedChapter == 0 || f.EndedChapter >= chapter)
+}
+
+// ContinuityIssue 正文与已确立事实的矛盾
+type ContinuityIssue struct {
+	Subject            string `json:"subject"`
+	Attribute          string `json:"attribute"`
+	Established        string `json:"established"`         // 已确立的值
+	EstablishedChapter int    `json:"established_chapter"` // 确立的章节
+	Found              string `json:"found"`               // 本章写成的值
+	Quote              string `json:"quote"`               // 本章原句
+	Suggestion         string `json:"suggestion,omitempty"`
+}
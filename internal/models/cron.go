@@ -0,0 +1,52 @@
+package models
+
+import "time"
+
+// ============================================
+// 定时任务
+// ============================================
+
+// 定时任务执行状态
+const (
+	CronRunRunning   = "running"
+	CronRunSucceeded = "succeeded"
+	CronRunFailed    = "failed"
+	CronRunSkipped   = "skipped" // 上一次执行尚未结束，本次跳过
+)
+
+// 定时任务的触发方式
+const (
+	CronTriggerSchedule = "schedule" // 按 cron 表达式到点触发
+	CronTriggerManual   = "manual"   // 管理员手动触发
+)
+
+// CronJob 按 cron 表达式周期执行的任务定义
+type CronJob struct {
+	ID         string     `json:"id" gorm:"primaryKey"`
+	Name       string     `json:"name" gorm:"size:200"`
+	Kind       string     `json:"kind" gorm:"size:50;index"` // 任务类型，如 rank_snapshot、continuity_check、publish_due
+	Spec       string     `json:"spec" gorm:"size:100"`      // cron 表达式：分 时 日 月 周
+	Timezone   string     `json:"timezone" gorm:"size:50"`   // IANA 时区，如 Asia/Shanghai；为空使用服务器时区
+	Params     JSON       `json:"params" gorm:"type:json"`   // 任务参数，格式由任务类型决定
+	Enabled    bool       `json:"enabled"`
+	NextRunAt  *time.Time `json:"next_run_at,omitempty" gorm:"index"`
+	LastRunAt  *time.Time `json:"last_run_at,omitempty"`
+	LastStatus string     `json:"last_status,omitempty" gorm:"size:20"`
+	CreatedBy  string     `json:"created_by" gorm:"size:100"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+// CronRun 定时任务的一次执行记录
+type CronRun struct {
+	ID         string     `json:"id" gorm:"primaryKey"`
+	JobID      string     `json:"job_id" gorm:"size:100;index"`
+	Kind       string     `json:"kind" gorm:"size:50"`
+	Trigger    string     `json:"trigger" gorm:"size:20"` // schedule/manual
+	Status     string     `json:"status" gorm:"size:20"`
+	Output     string     `json:"output,omitempty" gorm:"type:text"` // 执行结果摘要
+	Error      string     `json:"error,omitempty" gorm:"type:text"`
+	TaskID     string     `json:"task_id,omitempty" gorm:"size:100"` // 调度器中的任务ID，可查询任务日志
+	StartedAt  time.Time  `json:"started_at" gorm:"index"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+}
@@ -0,0 +1,59 @@
+package models
+
+import "time"
+
+// ============================================
+// 修为境界
+// ============================================
+
+// RealmEventKind 境界变化类型
+type RealmEventKind string
+
+const (
+	RealmInitial      RealmEventKind = "initial"      // 登场时的境界
+	RealmBreakthrough RealmEventKind = "breakthrough" // 突破
+	RealmRegression   RealmEventKind = "regression"   // 跌落、被废
+)
+
+// 境界记录来源
+const (
+	RealmFromProse  = "prose"  // 从章节分析提取，重新分析该章时替换
+	RealmFromManual = "manual" // 作者手工登记
+)
+
+// RealmEvent 角色在某一章的境界变化
+type RealmEvent struct {
+	ID        string         `json:"id" gorm:"primaryKey"`
+	ProjectID string         `json:"project_id" gorm:"size:100;index"`
+	Character string         `json:"character" gorm:"size:200"`
+	Kind      RealmEventKind `json:"kind" gorm:"size:20"`
+	Chapter   int            `json:"chapter"`
+	Realm     string         `json:"realm" gorm:"size:100"`               // 变化后的境界
+	Cost      string         `json:"cost,omitempty" gorm:"type:text"`     // 突破付出的代价、借助的资源、破除瓶颈的经过
+	Evidence  string         `json:"evidence,omitempty" gorm:"type:text"` // 正文原句
+	Source    string         `json:"source" gorm:"size:20"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// RealmState 角色在某一章开始时的境界
+type RealmState struct {
+	Character string `json:"character"`
+	Realm     string `json:"realm"`
+	Level     int    `json:"level"` // 在世界境界划分中的序号（从1开始），0 表示不在划分中
+	Since     int    `json:"since"` // 达到该境界的章节
+}
+
+// Cultivation 世界的修真体系，没有设定境界划分时返回 nil
+func (w *WorldSetting) Cultivation() *CultivationSystem {
+	if w == nil {
+		return nil
+	}
+	s := w.Laws.Supernatural
+	if s == nil || s.Settings == nil || s.Settings.CultivationSystem == nil {
+		return nil
+	}
+	if len(s.Settings.CultivationSystem.Realms) == 0 {
+		return nil
+	}
+	return s.Settings.CultivationSystem
+}
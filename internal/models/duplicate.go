@@ -0,0 +1,13 @@
+package models
+
+// DuplicatePassage 与其他章节近似重复的段落（位置按字符计，不含 end）
+type DuplicatePassage struct {
+	Start        int     `json:"start"`
+	End          int     `json:"end"`
+	Text         string  `json:"text"`
+	OtherChapter int     `json:"other_chapter"` // 与之重复的章节号
+	OtherStart   int     `json:"other_start"`
+	OtherEnd     int     `json:"other_end"`
+	OtherText    string  `json:"other_text"`
+	Similarity   float64 `json:"similarity"` // 字符三元组的 Jaccard 相似度（0-1）
+}
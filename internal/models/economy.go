@@ -0,0 +1,125 @@
+package models
+
+import (
+	"sort"
+	"strings"
+)
+
+// ============================================
+// 经济模型查询
+// ============================================
+
+// 稀缺程度阈值
+const (
+	ScarcityShortage = 60 // 达到该值视为短缺
+	ScarcitySurplus  = 30 // 不超过该值视为可外销
+)
+
+// ResourceHotspot 资源争夺热点：一地短缺、另一地充裕，且有贸易路线相连
+type ResourceHotspot struct {
+	Resource     string       `json:"resource"`
+	ScarceRegion string       `json:"scarce_region"`
+	SourceRegion string       `json:"source_region"`
+	Level        int          `json:"level"`           // 短缺地区的稀缺程度
+	Route        string       `json:"route,omitempty"` // 连接两地的贸易路线
+	Chokepoints  []Chokepoint `json:"chokepoints,omitempty"`
+}
+
+// ScarceIn 地区中短缺的资源，按稀缺程度降序
+func (e *Economy) ScarceIn(region string) []ResourceScarcity {
+	result := make([]ResourceScarcity, 0)
+	for _, s := range e.Scarcity {
+		if sameName(s.Region, region) && s.Level >= ScarcityShortage {
+			result = append(result, s)
+		}
+	}
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Level > result[j].Level })
+	return result
+}
+
+// SourcesOf 资源充裕可外销的地区
+func (e *Economy) SourcesOf(resource string) []ResourceScarcity {
+	result := make([]ResourceScarcity, 0)
+	for _, s := range e.Scarcity {
+		if sameName(s.Resource, resource) && s.Level <= ScarcitySurplus {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// RoutesThrough 起点、终点或咽喉要地位于该地区的贸易路线
+func (e *Economy) RoutesThrough(region string) []TradeRoute {
+	result := make([]TradeRoute, 0)
+	for _, r := range e.TradeRoutes {
+		if sameName(r.From, region) || sameName(r.To, region) {
+			result = append(result, r)
+			continue
+		}
+		for _, c := range r.Chokepoints {
+			if sameName(c.Region, region) {
+				result = append(result, r)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Hotspots 资源争夺热点，按稀缺程度降序；有路线相连（尤其经过咽喉要地）的排在前面
+func (e *Economy) Hotspots() []ResourceHotspot {
+	result := make([]ResourceHotspot, 0)
+	for _, short := range e.Scarcity {
+		if short.Level < ScarcityShortage {
+			continue
+		}
+		for _, source := range e.SourcesOf(short.Resource) {
+			if sameName(source.Region, short.Region) {
+				continue
+			}
+			h := ResourceHotspot{
+				Resource:     short.Resource,
+				ScarceRegion: short.Region,
+				SourceRegion: source.Region,
+				Level:        short.Level,
+			}
+			if route := e.routeBetween(source.Region, short.Region); route != nil {
+				h.Route = route.Name
+				h.Chokepoints = route.Chokepoints
+			}
+			result = append(result, h)
+		}
+	}
+
+	rank := func(h ResourceHotspot) int {
+		switch {
+		case len(h.Chokepoints) > 0:
+			return 2
+		case h.Route != "":
+			return 1
+		}
+		return 0
+	}
+	sort.SliceStable(result, func(i, j int) bool {
+		if rank(result[i]) != rank(result[j]) {
+			return rank(result[i]) > rank(result[j])
+		}
+		return result[i].Level > result[j].Level
+	})
+	return result
+}
+
+// routeBetween 连接两地的贸易路线（不区分方向）
+func (e *Economy) routeBetween(a, b string) *TradeRoute {
+	for i, r := range e.TradeRoutes {
+		if (sameName(r.From, a) && sameName(r.To, b)) || (sameName(r.From, b) && sameName(r.To, a)) {
+			return &e.TradeRoutes[i]
+		}
+	}
+	return nil
+}
+
+// sameName 名称是否相同（忽略首尾空白）
+func sameName(a, b string) bool {
+	return strings.TrimSpace(a) == strings.TrimSpace(b)
+}
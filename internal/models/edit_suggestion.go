@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+// ============================================
+// 修改建议（修订模式）
+// ============================================
+
+// 修改建议状态
+const (
+	SuggestionPending  = "pending"  // 待作者处理
+	SuggestionApplied  = "applied"  // 已写回章节（至少采纳了一处修改）
+	SuggestionRejected = "rejected" // 全部拒绝，章节未改动
+)
+
+// 句子级差异的操作
+const (
+	HunkEqual   = "equal"   // 未改动
+	HunkReplace = "replace" // 改写
+	HunkInsert  = "insert"  // 新增
+	HunkDelete  = "delete"  // 删除
+)
+
+// 单处修改的处理结果
+const (
+	HunkAccepted = "accepted"
+	HunkRejected = "rejected"
+)
+
+// EditHunk 选段改写前后的一处句子级差异
+type EditHunk struct {
+	Index    int    `json:"index"`
+	Op       string `json:"op"`                 // equal/replace/insert/delete
+	Original string `json:"original,omitempty"` // 原文句子（insert 为空）
+	Revised  string `json:"revised,omitempty"`  // 改写后的句子（delete 为空）
+	Decision string `json:"decision,omitempty"` // accepted/rejected，处理前为空；equal 不需要处理
+}
+
+// Changed 是否为需要作者处理的修改
+func (h EditHunk) Changed() bool {
+	return h.Op != HunkEqual
+}
+
+// EditSuggestion 按作者指令对章节选段给出的改写建议，作者逐句采纳或拒绝后写回章节
+type EditSuggestion struct {
+	ID             string     `json:"id" gorm:"primaryKey"`
+	ProjectID      string     `json:"project_id" gorm:"size:100;index"`
+	ChapterID      string     `json:"chapter_id" gorm:"size:100;index"`
+	UserID         string     `json:"user_id" gorm:"size:100"`
+	AnnotationID   string     `json:"annotation_id,omitempty" gorm:"size:100;index"` // 由批注生成时对应的批注，采纳后批注自动解决
+	Instruction    string     `json:"instruction" gorm:"type:text"`                  // 作者指令，如“更紧张一些”“删掉副词”
+	Start          int        `json:"start"`                                         // 选段在章节正文中的起止位置（按字符计，不含 end）
+	End            int        `json:"end"`
+	Original       string     `json:"original" gorm:"type:text"`
+	Revised        string     `json:"revised" gorm:"type:text"`
+	Notes          []string   `json:"notes,omitempty" gorm:"type:json;serializer:json"` // 模型对改动的说明
+	Hunks          []EditHunk `json:"hunks" gorm:"type:json;serializer:json"`
+	Status         string     `json:"status" gorm:"size:20;index"`
+	ChapterVersion int        `json:"chapter_version"`                   // 生成建议时的章节版本
+	Result         string     `json:"result,omitempty" gorm:"type:text"` // 写回章节的最终文本
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,37 @@
+package models
+
+// ============================================
+// 结局候选
+// ============================================
+
+// 结局类型
+const (
+	EndingTragic      = "tragic"      // 悲剧
+	EndingBittersweet = "bittersweet" // 苦乐参半
+	EndingTriumphant  = "triumphant"  // 圆满胜利
+	EndingOpen        = "open"        // 开放式
+	EndingBlended     = "blended"     // 由多个候选融合
+)
+
+// EndingCandidate 结局候选：一套高潮与结局方案，附带对照主题规划的利弊分析
+type EndingCandidate struct {
+	ID         string   `json:"id"`
+	Type       string   `json:"type"`
+	Summary    string   `json:"summary"` // 一句话概括
+	Climax     string   `json:"climax"`
+	Resolution string   `json:"resolution"`
+	Pros       []string `json:"pros"`
+	Cons       []string `json:"cons"`
+	ThemeFit   int      `json:"theme_fit"` // 与主题规划的契合度 0-100
+	Selected   bool     `json:"selected"`
+}
+
+// SelectedEnding 返回已选定的结局候选，没有时返回 nil
+func (b *NarrativeBlueprint) SelectedEnding() *EndingCandidate {
+	for i := range b.EndingCandidates {
+		if b.EndingCandidates[i].Selected {
+			return &b.EndingCandidates[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,148 @@
+package models
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ============================================
+// 术语表
+// ============================================
+
+// 术语类型
+const (
+	TermRealm    = "realm"    // 修炼境界
+	TermRegion   = "region"   // 地名
+	TermRace     = "race"     // 种族
+	TermReligion = "religion" // 宗教
+	TermFaction  = "faction"  // 派系、组织
+	TermTitle    = "title"    // 职衔
+	TermSite     = "site"     // 圣地等具名地点
+	TermLanguage = "language" // 语言
+	TermEvent    = "event"    // 历史事件、时代
+	TermLaw      = "law"      // 法律
+	TermItem     = "item"     // 物品、法宝（手工登记）
+	TermOther    = "other"
+)
+
+// 术语来源
+const (
+	TermFromWorld  = "world"  // 从世界设定提取，设定变化时重新提取
+	TermFromManual = "manual" // 手工添加，重新提取时保留
+)
+
+// GlossaryTerm 术语表条目：设定中的规范写法及已知的错误写法
+type GlossaryTerm struct {
+	Term    string   `json:"term"`              // 规范写法
+	Kind    string   `json:"kind"`              // 术语类型
+	Aliases []string `json:"aliases,omitempty"` // 已知的错误写法，正文中出现时一律改为规范写法
+	Source  string   `json:"source"`            // world/manual
+	Note    string   `json:"note,omitempty"`
+}
+
+// GlossaryCorrection 正文中的一处术语写法偏差
+type GlossaryCorrection struct {
+	Chapter   int    `json:"chapter,omitempty"`
+	Variant   string `json:"variant"`   // 正文中的写法
+	Canonical string `json:"canonical"` // 规范写法
+	Count     int    `json:"count"`
+	Reason    string `json:"reason"` // alias：已登记的错误写法；near_miss：与规范写法只差一个字
+}
+
+// 偏差原因
+const (
+	CorrectionAlias    = "alias"
+	CorrectionNearMiss = "near_miss"
+)
+
+// RebuildGlossary 从世界设定中提取术语，保留手工条目和已登记的错误写法
+func (w *WorldSetting) RebuildGlossary() {
+	aliases := make(map[string][]string, len(w.Glossary))
+	manual := make([]GlossaryTerm, 0)
+	for _, t := range w.Glossary {
+		if t.Source == TermFromManual {
+			manual = append(manual, t)
+			continue
+		}
+		aliases[t.Term] = t.Aliases
+	}
+
+	seen := make(map[string]bool)
+	for _, t := range manual {
+		seen[t.Term] = true
+	}
+	extracted := make([]GlossaryTerm, 0)
+	add := func(kind string, names ...string) {
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			// 单字名称无法可靠比对，跳过
+			if utf8.RuneCountInString(name) < 2 || seen[name] {
+				continue
+			}
+			seen[name] = true
+			extracted = append(extracted, GlossaryTerm{Term: name, Kind: kind, Aliases: aliases[name], Source: TermFromWorld})
+		}
+	}
+
+	if s := w.Laws.Supernatural; s != nil && s.Settings != nil && s.Settings.CultivationSystem != nil {
+		add(TermRealm, s.Settings.CultivationSystem.Realms...)
+	}
+	for _, r := range w.Geography.Regions {
+		add(TermRegion, r.Name)
+	}
+	for _, r := range w.Civilization.Races {
+		add(TermRace, r.Name)
+	}
+	for _, l := range w.Civilization.Languages {
+		add(TermLanguage, l.Name)
+	}
+	for _, r := range w.Civilization.Religions {
+		add(TermReligion, r.Name)
+		if org := r.Organization; org != nil {
+			add(TermFaction, org.Factions...)
+			for _, f := range org.FactionDetails {
+				add(TermFaction, f.Name)
+			}
+			for _, rank := range org.Hierarchy {
+				add(TermTitle, rank.Title)
+			}
+			for _, site := range org.HolySites {
+				add(TermSite, site.Name)
+			}
+		}
+	}
+	if ps := w.Society.Politics.PowerStructure; ps != nil {
+		for _, l := range ps.Formal {
+			add(TermTitle, l.Name)
+		}
+		for _, h := range ps.Actual {
+			add(TermFaction, h.Entity)
+		}
+	}
+	for _, l := range w.Society.Laws {
+		add(TermLaw, l.Name)
+	}
+	for _, e := range w.History.Eras {
+		add(TermEvent, e.Name)
+	}
+	for _, e := range w.History.Events {
+		add(TermEvent, e.Name)
+	}
+
+	w.Glossary = append(extracted, manual...)
+}
+
+// FindTerm 按规范写法或错误写法查找术语
+func (w *WorldSetting) FindTerm(text string) *GlossaryTerm {
+	for i := range w.Glossary {
+		if w.Glossary[i].Term == text {
+			return &w.Glossary[i]
+		}
+		for _, a := range w.Glossary[i].Aliases {
+			if a == text {
+				return &w.Glossary[i]
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// ============================================
+// 章节钩子评分
+// ============================================
+
+// WeakHookThreshold 钩子综合分低于该值（满分10）视为弱钩子，需要重新生成
+const WeakHookThreshold = 6.0
+
+// HookRating 单个钩子（开篇段落或章末悬念）的评分
+type HookRating struct {
+	Text        string  `json:"text"`        // 被评分的原文
+	Curiosity   int     `json:"curiosity"`   // 好奇心：是否抛出让人想知道答案的问题（0-10）
+	Tension     int     `json:"tension"`     // 紧张感：是否有迫在眉睫的危险或冲突（0-10）
+	Cliffhanger int     `json:"cliffhanger"` // 悬念强度：是否停在让人非读下一章不可的位置（0-10）
+	Overall     float64 `json:"overall"`     // 三项平均
+	Weak        bool    `json:"weak"`        // 低于阈值，建议重新生成
+	Comment     string  `json:"comment,omitempty"`
+	Suggestion  string  `json:"suggestion,omitempty"` // 改进方向
+}
+
+// Score 计算综合分并按阈值标记弱钩子
+func (r *HookRating) Score() {
+	r.Overall = float64(r.Curiosity+r.Tension+r.Cliffhanger) / 3
+	r.Weak = r.Overall < WeakHookThreshold
+}
+
+// HookScore 章节开篇与章末钩子评分
+type HookScore struct {
+	Opening  HookRating `json:"opening"`
+	Ending   HookRating `json:"ending"`
+	ScoredAt time.Time  `json:"scored_at"`
+}
+
+// Weak 开篇或章末任一钩子偏弱
+func (s *HookScore) Weak() bool {
+	return s != nil && (s.Opening.Weak || s.Ending.Weak)
+}
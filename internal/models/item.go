@@ -0,0 +1,71 @@
+package models
+
+import "time"
+
+// ============================================
+// 关键物品
+// ============================================
+
+// 物品类型
+const (
+	ItemWeapon   = "weapon"   // 兵器
+	ItemArtifact = "artifact" // 法宝、神器
+	ItemDocument = "document" // 书信、典籍、地图
+	ItemToken    = "token"    // 信物、令牌、钥匙
+	ItemOther    = "other"
+)
+
+// 物品来源
+const (
+	ItemFromWorld  = "world"  // 世界术语表中登记的物品
+	ItemFromStory  = "story"  // 从蓝图提取的关键物品
+	ItemFromProse  = "prose"  // 去向记录：从章节分析提取，重新分析该章时替换
+	ItemFromManual = "manual" // 作者手工登记
+)
+
+// Item 需要追踪去向的关键物品（兵器、书信、法宝等）
+type Item struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	ProjectID   string    `json:"project_id" gorm:"size:100;index"`
+	Name        string    `json:"name" gorm:"size:200"`
+	Aliases     []string  `json:"aliases,omitempty" gorm:"type:json;serializer:json"` // 正文中的其他称呼
+	Kind        string    `json:"kind" gorm:"size:20"`
+	Description string    `json:"description" gorm:"type:text"`
+	Holder      string    `json:"holder" gorm:"size:200"`   // 初始持有人，空表示不在任何人手中
+	Location    string    `json:"location" gorm:"size:200"` // 不在人手中时的初始所在地
+	Chapter     int       `json:"chapter"`                  // 首次登场的章节，0 表示开篇前即已存在
+	Source      string    `json:"source" gorm:"size:20"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// Names 物品的全部称呼
+func (i *Item) Names() []string {
+	return append([]string{i.Name}, i.Aliases...)
+}
+
+// ItemTransfer 物品在某一章的去向变化
+type ItemTransfer struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	ProjectID string    `json:"project_id" gorm:"size:100;index"`
+	ItemID    string    `json:"item_id" gorm:"size:100;index"`
+	Chapter   int       `json:"chapter"`
+	From      string    `json:"from" gorm:"size:200"`     // 原持有人
+	To        string    `json:"to" gorm:"size:200"`       // 新持有人，空表示不在任何人手中
+	Location  string    `json:"location" gorm:"size:200"` // 不在人手中时的所在地
+	Note      string    `json:"note" gorm:"type:text"`    // 经过（赠予、夺取、遗失、毁坏等）
+	Destroyed bool      `json:"destroyed"`                // 物品被毁，此后不应再出现
+	Evidence  string    `json:"evidence,omitempty" gorm:"type:text"`
+	Source    string    `json:"source" gorm:"size:20"` // prose/manual
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ItemHolding 物品在某一章开始时的去向
+type ItemHolding struct {
+	ItemID    string `json:"item_id"`
+	Name      string `json:"name"`
+	Holder    string `json:"holder,omitempty"`
+	Location  string `json:"location,omitempty"`
+	Destroyed bool   `json:"destroyed,omitempty"`
+	Since     int    `json:"since"` // 去向确定的章节
+}
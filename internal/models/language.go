@@ -0,0 +1,29 @@
+package models
+
+// ============================================
+// 输出语言
+// ============================================
+
+// 项目输出语言（BCP 47）
+const (
+	LanguageZhCN = "zh-CN" // 简体中文（默认）
+	LanguageZhTW = "zh-TW" // 繁体中文
+	LanguageEn   = "en"    // 英文
+	LanguageJa   = "ja"    // 日文
+)
+
+// Languages 支持的输出语言
+var Languages = []string{LanguageZhCN, LanguageZhTW, LanguageEn, LanguageJa}
+
+// ValidLanguage 是否为支持的输出语言，空值表示默认的简体中文
+func ValidLanguage(lang string) bool {
+	if lang == "" {
+		return true
+	}
+	for _, l := range Languages {
+		if l == lang {
+			return true
+		}
+	}
+	return false
+}
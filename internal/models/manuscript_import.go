@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// ============================================
+// 书稿导入
+// ============================================
+
+// 书稿导入状态
+const (
+	ManuscriptImportRunning   = "running"
+	ManuscriptImportCompleted = "completed"
+	ManuscriptImportFailed    = "failed"
+)
+
+// 书稿导入阶段
+const (
+	ManuscriptStageAnalyze    = "analyze"    // 逐批分析章节
+	ManuscriptStageSynthesize = "synthesize" // 归纳世界设定与冲突
+	ManuscriptStagePlan       = "plan"       // 规划续写章节
+	ManuscriptStageDone       = "done"
+)
+
+// ManuscriptImport 一次书稿导入：章节入库后分析书稿重建演化状态，再从下一章起规划续写
+type ManuscriptImport struct {
+	ID               string    `json:"id" gorm:"primaryKey"`
+	ProjectID        string    `json:"project_id" gorm:"size:100;index"`
+	UserID           string    `json:"user_id" gorm:"size:100;index"`
+	Files            []string  `json:"files" gorm:"type:json;serializer:json"` // 导入的文件名
+	Chapters         int       `json:"chapters"`                               // 导入的章节数
+	ContinueChapters int       `json:"continue_chapters"`                      // 续写规划的章节数
+	Status           string    `json:"status" gorm:"size:20"`
+	Stage            string    `json:"stage" gorm:"size:20"`
+	Analyzed         int       `json:"analyzed"`                         // 已分析的章节数
+	Characters       int       `json:"characters"`                       // 识别出的角色数
+	Facts            int       `json:"facts"`                            // 登记的细节事实数
+	OpenForeshadows  int       `json:"open_foreshadows"`                 // 未回收的伏笔数
+	State            JSON      `json:"state,omitempty" gorm:"type:json"` // 重建的演化状态（narrative.EvolutionState）
+	Error            string    `json:"error,omitempty" gorm:"type:text"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+}
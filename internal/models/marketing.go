@@ -0,0 +1,63 @@
+package models
+
+import "time"
+
+// ============================================
+// 营销文案
+// ============================================
+
+// 营销文案的各项及字数上限
+const (
+	MarketingTagline      = "tagline"      // 一句话宣传语
+	MarketingBlurb        = "blurb"        // 平台简介
+	MarketingIntroduction = "introduction" // 作品介绍
+	MarketingTags         = "tags"         // 标签
+
+	TaglineMaxRunes      = 150
+	BlurbMaxRunes        = 300
+	IntroductionMaxRunes = 1000
+)
+
+// MarketingFields 全部营销文案项
+var MarketingFields = []string{MarketingTagline, MarketingBlurb, MarketingIntroduction, MarketingTags}
+
+// MarketingCopy 作品的营销文案，每个项目一份，可整体或逐项重新生成，也可手动编辑
+type MarketingCopy struct {
+	ID           string    `json:"id" gorm:"primaryKey"`
+	ProjectID    string    `json:"project_id" gorm:"size:100;uniqueIndex"`
+	Tagline      string    `json:"tagline" gorm:"type:text"`
+	Blurb        string    `json:"blurb" gorm:"type:text"`
+	Introduction string    `json:"introduction" gorm:"type:text"`
+	Tags         []string  `json:"tags" gorm:"type:json;serializer:json"`
+	Edited       []string  `json:"edited,omitempty" gorm:"type:json;serializer:json"` // 手动编辑过的项，整体重新生成时保留
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// IsEdited 该项是否手动编辑过
+func (m *MarketingCopy) IsEdited(field string) bool {
+	for _, f := range m.Edited {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkEdited 标记该项已手动编辑
+func (m *MarketingCopy) MarkEdited(field string) {
+	if !m.IsEdited(field) {
+		m.Edited = append(m.Edited, field)
+	}
+}
+
+// ClearEdited 重新生成后清除该项的编辑标记
+func (m *MarketingCopy) ClearEdited(field string) {
+	kept := m.Edited[:0]
+	for _, f := range m.Edited {
+		if f != field {
+			kept = append(kept, f)
+		}
+	}
+	m.Edited = kept
+}
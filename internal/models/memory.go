@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ============================================
+// 检索记忆
+// ============================================
+
+// 记忆类型
+const (
+	MemoryChapterSummary = "chapter_summary" // 章节摘要
+	MemoryCharacterFact  = "character_fact"  // 角色事实
+	MemoryWorldEntry     = "world_entry"     // 世界设定条目
+)
+
+// MemoryEntry 检索记忆条目，生成场景时按相关度召回注入提示词
+type MemoryEntry struct {
+	ID          string    `json:"id" gorm:"primaryKey"`
+	BlueprintID string    `json:"blueprint_id" gorm:"size:100;index"`
+	Kind        string    `json:"kind" gorm:"size:30;index"`
+	SourceID    string    `json:"source_id" gorm:"size:100"` // 来源标识，如 chapter_3、角色ID、区域ID
+	Chapter     int       `json:"chapter"`                   // 关联章节，0表示不限章节
+	Content     string    `json:"content" gorm:"type:text"`
+	ContentHash string    `json:"content_hash" gorm:"size:64"` // 内容未变化时不重新嵌入
+	Model       string    `json:"model" gorm:"size:100"`       // 生成向量的嵌入模型
+	Embedding   []float32 `json:"-" gorm:"type:json;serializer:json"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
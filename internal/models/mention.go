@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// ============================================
+// 实体提及
+// ============================================
+
+// MentionKind 被提及的实体类型
+type MentionKind string
+
+const (
+	MentionCharacter MentionKind = "character" // 角色
+	MentionLocation  MentionKind = "location"  // 地点（地区、圣地等）
+	MentionItem      MentionKind = "item"      // 物品（术语表中登记为 item 的条目）
+)
+
+// EntityMention 某个实体在某一章中的出现记录，每章每个实体一条
+type EntityMention struct {
+	ID          string      `json:"id" gorm:"primaryKey"`
+	ProjectID   string      `json:"project_id" gorm:"size:100;index:idx_mention_chapter"`
+	ChapterNum  int         `json:"chapter_num" gorm:"index:idx_mention_chapter"`
+	ChapterID   string      `json:"chapter_id" gorm:"size:100"`
+	EntityKind  MentionKind `json:"entity_kind" gorm:"size:20"`
+	EntityID    string      `json:"entity_id" gorm:"size:100"` // 角色ID、世界实体ID或术语名
+	Name        string      `json:"name" gorm:"size:200"`      // 规范名称
+	Count       int         `json:"count"`                     // 本章出现次数
+	FirstOffset int         `json:"first_offset"`              // 本章首次出现的位置（字）
+	Excerpt     string      `json:"excerpt" gorm:"type:text"`  // 首次出现处的上下文
+	CreatedAt   time.Time   `json:"created_at"`
+}
@@ -0,0 +1,59 @@
+package models
+
+// ============================================
+// 龙套角色池
+// ============================================
+
+// MinorCharacter 龙套角色：按地区预先起好名字和身份，场景需要路人时从中选用，避免临时杜撰
+type MinorCharacter struct {
+	ID         string `json:"id"`
+	Name       string `json:"name"`
+	Gender     string `json:"gender,omitempty"`
+	Region     string `json:"region,omitempty"`      // 所在地区，为空表示不限地区
+	Role       string `json:"role"`                  // 一句话身份，如“城门口卖炊饼的老汉”
+	Trait      string `json:"trait,omitempty"`       // 辨识特征（口头禅、外貌、习惯）
+	PromotedTo string `json:"promoted_to,omitempty"` // 升格后的角色卡ID，为空表示仍是龙套
+}
+
+// Promoted 是否已升格为正式角色
+func (m *MinorCharacter) Promoted() bool {
+	return m.PromotedTo != ""
+}
+
+// FindMinorCharacter 按ID或姓名查找龙套角色
+func (w *WorldSetting) FindMinorCharacter(nameOrID string) *MinorCharacter {
+	for i := range w.MinorCharacters {
+		if w.MinorCharacters[i].ID == nameOrID || w.MinorCharacters[i].Name == nameOrID {
+			return &w.MinorCharacters[i]
+		}
+	}
+	return nil
+}
+
+// MinorCharactersAt 场景地点可用的龙套角色（最多 n 个，不含已升格的）
+// 地点能对应到地区时优先选该地区的龙套，不足时用不限地区的补齐；对应不到地区时只返回不限地区的
+func (w *WorldSetting) MinorCharactersAt(location string, n int) []MinorCharacter {
+	region := ""
+	if r := w.Geography.RegionOf(location); r != nil {
+		region = r.Name
+	}
+
+	passes := []string{""}
+	if region != "" {
+		passes = []string{region, ""}
+	}
+
+	result := make([]MinorCharacter, 0, n)
+	for _, pass := range passes {
+		for _, m := range w.MinorCharacters {
+			if len(result) >= n {
+				return result
+			}
+			if m.Promoted() || m.Region != pass {
+				continue
+			}
+			result = append(result, m)
+		}
+	}
+	return result
+}
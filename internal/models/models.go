@@ -24,10 +24,49 @@ type Project struct {
 	Progress    float64           `json:"progress"` // 0-100
 	CreatedAt   time.Time         `json:"created_at"`
 	UpdatedAt   time.Time         `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt    `json:"-" gorm:"index"` // 软删除，在回收站保留 TrashRetention 后清除
 
 	// 关联
 	WorldID     string `json:"world_id"`
 	NarrativeID string `json:"narrative_id"`
+
+	// 章节字数目标
+	WordCountTargets WordCountTargets `json:"word_count_targets" gorm:"type:json;serializer:json"`
+
+	// 写作风格档案（内置预设或用户自定义）
+	StyleProfileID string `json:"style_profile_id"`
+
+	// 套路检测配置
+	TropeSettings TropeSettings `json:"trope_settings" gorm:"type:json;serializer:json"`
+
+	// 内容合规配置
+	ComplianceSettings ComplianceSettings `json:"compliance_settings" gorm:"type:json;serializer:json"`
+
+	// 原创性检查设置
+	OriginalitySettings OriginalitySettings `json:"originality_settings" gorm:"type:json;serializer:json"`
+
+	// 输出语言（zh-CN/zh-TW/en/ja），为空时为简体中文
+	Language string `json:"language"`
+
+	// 译文语言，设置后章节标记为已完成时自动翻译，为空表示不自动翻译
+	TranslationLanguage string `json:"translation_language"`
+}
+
+// WordCountTargets 章节字数目标
+type WordCountTargets struct {
+	Default  int         `json:"default"`            // 每章默认目标字数，0表示沿用规划估算
+	Chapters map[int]int `json:"chapters,omitempty"` // 按章节号覆盖
+}
+
+// For 获取指定章节的目标字数，未配置时返回 fallback
+func (t WordCountTargets) For(chapter, fallback int) int {
+	if target, ok := t.Chapters[chapter]; ok && target > 0 {
+		return target
+	}
+	if t.Default > 0 {
+		return t.Default
+	}
+	return fallback
 }
 
 // OrchestrationMode 编排模式
@@ -42,6 +81,7 @@ const (
 	ModeScript       OrchestrationMode = "script"       // 剧本模式
 	ModeAssisted     OrchestrationMode = "assisted"     // 辅助创作
 	ModeAutomatic    OrchestrationMode = "automatic"    // 自动创作
+	ModeLocalImport  OrchestrationMode = "local_import" // 导入本地书稿
 )
 
 // ProjectStatus 项目状态
@@ -62,31 +102,111 @@ const (
 
 // WorldSetting 世界设定
 type WorldSetting struct {
-	ID        string     `json:"id" gorm:"primaryKey"`
-	Name      string     `json:"name"`
-	Type      WorldType  `json:"type"`
-	Scale     WorldScale `json:"scale"`
-	Style     string     `json:"style"` // 风格倾向
-	CreatedAt time.Time  `json:"created_at"`
-	UpdatedAt time.Time  `json:"updated_at"`
+	ID        string         `json:"id" gorm:"primaryKey"`
+	Name      string         `json:"name"`
+	Type      WorldType      `json:"type"`
+	Scale     WorldScale     `json:"scale"`
+	Style     string         `json:"style"` // 风格倾向
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"` // 软删除
+
+	// 构建档位（quick/standard/deep），quick/standard 构建的世界可以后续加深
+	BuildTier WorldBuildTier `json:"build_tier,omitempty"`
 
 	// 分层设定（存储为JSON）
-	Philosophy   Philosophy   `json:"philosophy" gorm:"type:json"`
-	Worldview    Worldview    `json:"worldview" gorm:"type:json"`
-	Laws         Laws         `json:"laws" gorm:"type:json"`
-	Geography    Geography    `json:"geography" gorm:"type:json"`
-	Civilization Civilization `json:"civilization" gorm:"type:json"`
-	Society      Society      `json:"society" gorm:"type:json"`
-	History      History      `json:"history" gorm:"type:json"`
+	Philosophy   Philosophy   `json:"philosophy" gorm:"type:json;serializer:json"`
+	Worldview    Worldview    `json:"worldview" gorm:"type:json;serializer:json"`
+	Laws         Laws         `json:"laws" gorm:"type:json;serializer:json"`
+	Geography    Geography    `json:"geography" gorm:"type:json;serializer:json"`
+	Civilization Civilization `json:"civilization" gorm:"type:json;serializer:json"`
+	Society      Society      `json:"society" gorm:"type:json;serializer:json"`
+	History      History      `json:"history" gorm:"type:json;serializer:json"`
 
 	// 故事土壤（叙事器最需要）
-	StorySoil StorySoil `json:"story_soil" gorm:"type:json"`
+	StorySoil StorySoil `json:"story_soil" gorm:"type:json;serializer:json"`
 
 	// 设定约束（写作器需要）
-	SettingConstraints SettingConstraints `json:"setting_constraints" gorm:"type:json"`
+	SettingConstraints SettingConstraints `json:"setting_constraints" gorm:"type:json;serializer:json"`
 
 	// 一致性检查报告（阶段7生成）
-	ConsistencyReport *ConsistencyReport `json:"consistency_report,omitempty" gorm:"type:json"`
+	ConsistencyReport *ConsistencyReport `json:"consistency_report,omitempty" gorm:"type:json;serializer:json"`
+
+	// 分节版本号（乐观并发控制，key为分节名，如 philosophy）
+	SectionVersions map[string]int `json:"section_versions,omitempty" gorm:"type:json;serializer:json"`
+
+	// 整行版本号（每次分节写入加一，按它条件更新，多副本部署时防止并发写入互相覆盖）
+	Revision int `json:"revision" gorm:"not null;default:0"`
+
+	// 已使用的人名登记（防止同一世界内重名）
+	NameRegistry []string `json:"name_registry,omitempty" gorm:"type:json;serializer:json"`
+
+	// 降级警告（哪些内容是LLM失败后的兜底占位）
+	Warnings []DegradationWarning `json:"warnings,omitempty" gorm:"type:json;serializer:json"`
+
+	// 实体索引（命名的地区、种族、宗教、法律、历史事件及其相互引用）
+	Entities []WorldEntity `json:"entities,omitempty" gorm:"type:json;serializer:json"`
+
+	// 龙套角色池（按地区预先起名，场景生成时选用）
+	MinorCharacters []MinorCharacter `json:"minor_characters,omitempty" gorm:"type:json;serializer:json"`
+
+	// 术语表（境界、地名等专有名词的规范写法，生成时注入提示词并校正正文）
+	Glossary []GlossaryTerm `json:"glossary,omitempty" gorm:"type:json;serializer:json"`
+}
+
+// DegradationWarning 降级警告：LLM调用失败或输出不可用，该环节使用了兜底（占位）内容
+type DegradationWarning struct {
+	Module    string    `json:"module"`          // 模块，同 module_mapping，如 narrative_engine
+	Component string    `json:"component"`       // 使用兜底内容的环节，如 characters、conflicts
+	Message   string    `json:"message"`         // 失败原因
+	Round     int       `json:"round,omitempty"` // 演化轮次（叙事器）
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WorldSections 支持分节编辑的世界设定分节
+var WorldSections = []string{
+	"philosophy", "worldview", "laws", "geography", "civilization", "society", "history",
+}
+
+// SectionVersion 获取分节当前版本（未编辑过的分节为0）
+func (w *WorldSetting) SectionVersion(section string) int {
+	if w.SectionVersions == nil {
+		return 0
+	}
+	return w.SectionVersions[section]
+}
+
+// BumpSectionVersion 分节版本号加一
+func (w *WorldSetting) BumpSectionVersion(section string) int {
+	if w.SectionVersions == nil {
+		w.SectionVersions = make(map[string]int)
+	}
+	w.SectionVersions[section]++
+	return w.SectionVersions[section]
+}
+
+// Section 获取分节内容
+func (w *WorldSetting) Section(section string) interface{} {
+	switch section {
+	case "philosophy":
+		return w.Philosophy
+	case "worldview":
+		return w.Worldview
+	case "laws":
+		return w.Laws
+	case "geography":
+		return w.Geography
+	case "civilization":
+		return w.Civilization
+	case "society":
+		return w.Society
+	case "history":
+		return w.History
+	case "story_soil":
+		return w.StorySoil
+	default:
+		return nil
+	}
 }
 
 // WorldType 世界类型
@@ -114,6 +234,24 @@ const (
 	ScaleUniverse  WorldScale = "universe"  // 宇宙级
 )
 
+// WorldBuildTier 世界构建档位
+type WorldBuildTier string
+
+const (
+	BuildTierQuick    WorldBuildTier = "quick"    // 快速：多阶段合并为少量组合提示词（约10轮LLM）
+	BuildTierStandard WorldBuildTier = "standard" // 标准：7个阶段各一次调用（默认）
+	BuildTierDeep     WorldBuildTier = "deep"     // 深度：高信息熵多轮构建（50-100轮LLM）
+)
+
+// ParseWorldBuildTier 解析构建档位，无法识别时为 standard
+func ParseWorldBuildTier(s string) WorldBuildTier {
+	switch WorldBuildTier(s) {
+	case BuildTierQuick, BuildTierDeep:
+		return WorldBuildTier(s)
+	}
+	return BuildTierStandard
+}
+
 // ============================================
 // 哲学思考层
 // ============================================
@@ -248,6 +386,23 @@ type Region struct {
 	Description string   `json:"description"`
 	Resources   []string `json:"resources"`
 	Risks       []string `json:"risks"` // 自然灾害
+
+	// 空间关系（示意图与移动时间校验使用）
+	Position *MapPosition   `json:"position,omitempty"` // 相对坐标
+	Borders  []RegionBorder `json:"borders,omitempty"`  // 相邻地区
+}
+
+// MapPosition 示意图上的相对坐标，取值0-100，x向东、y向南
+type MapPosition struct {
+	X float64 `json:"x"`
+	Y float64 `json:"y"`
+}
+
+// RegionBorder 与相邻地区的边界
+type RegionBorder struct {
+	Region      string  `json:"region"`       // 相邻地区名称
+	Type        string  `json:"type"`         // 边界类型：land, river, mountain, sea, wall
+	TravelHours float64 `json:"travel_hours"` // 两地之间常规通行所需小时数
 }
 
 // Resources 资源
@@ -311,6 +466,40 @@ type ReligionOrganization struct {
 	Type     string   `json:"type"` // hierarchy, decentralized, cult
 	Leader   string   `json:"leader"`
 	Factions []string `json:"factions"`
+
+	// 组织架构（宗教组织生成环节填充）
+	Hierarchy      []ClergyRank       `json:"hierarchy,omitempty"`       // 神职层级，从高到低
+	FactionDetails []ReligiousFaction `json:"faction_details,omitempty"` // 派系详情，名称与 Factions 一致
+	HolySites      []HolySite         `json:"holy_sites,omitempty"`
+}
+
+// ClergyRank 神职层级
+type ClergyRank struct {
+	Level     int      `json:"level"` // 1为最高
+	Title     string   `json:"title"`
+	Holders   []string `json:"holders,omitempty"` // 有名有姓的在任者
+	Duties    string   `json:"duties"`
+	ReportsTo string   `json:"reports_to,omitempty"` // 上级职衔
+}
+
+// ReligiousFaction 宗教派系
+type ReligiousFaction struct {
+	Name     string `json:"name"`
+	Leader   string `json:"leader"`
+	Doctrine string `json:"doctrine"`        // 核心主张
+	Dispute  string `json:"dispute"`         // 与正统或其他派系的教义分歧
+	Rival    string `json:"rival,omitempty"` // 主要对立方（派系或组织）
+	Strength int    `json:"strength"`        // 势力 0-100
+	Ambition string `json:"ambition"`        // 图谋（可作为反派素材）
+}
+
+// HolySite 圣地
+type HolySite struct {
+	Name         string `json:"name"`
+	Region       string `json:"region"` // 所在地区，与 Geography.Regions 名称一致
+	Significance string `json:"significance"`
+	ControlledBy string `json:"controlled_by,omitempty"` // 控制该圣地的派系
+	Contested    bool   `json:"contested,omitempty"`     // 是否被多方争夺
 }
 
 // ============================================
@@ -367,6 +556,34 @@ type Economy struct {
 	Type         string   `json:"type"` // natural, commodity, capitalist, planned
 	TradeNetwork string   `json:"trade_network"`
 	Currency     []string `json:"currency"`
+
+	// 简易经济模型（冲突设计使用，见 economy.go）
+	Scarcity    []ResourceScarcity `json:"scarcity,omitempty"`     // 各地区各资源的稀缺程度
+	TradeRoutes []TradeRoute       `json:"trade_routes,omitempty"` // 贸易路线
+}
+
+// ResourceScarcity 某地区某资源的稀缺程度
+type ResourceScarcity struct {
+	Region   string `json:"region"`
+	Resource string `json:"resource"`
+	Level    int    `json:"level"` // 0-100，0为充裕可外销，100为完全匮乏
+	Note     string `json:"note,omitempty"`
+}
+
+// TradeRoute 贸易路线
+type TradeRoute struct {
+	Name        string       `json:"name"`
+	From        string       `json:"from"` // 起点地区
+	To          string       `json:"to"`   // 终点地区
+	Goods       []string     `json:"goods"`
+	Chokepoints []Chokepoint `json:"chokepoints,omitempty"`
+}
+
+// Chokepoint 贸易路线上的咽喉要地
+type Chokepoint struct {
+	Region     string `json:"region"`
+	Controller string `json:"controller,omitempty"` // 控制者（势力、种族或组织）
+	Risk       string `json:"risk,omitempty"`
 }
 
 // Law 法律
@@ -467,6 +684,17 @@ type SettingConstraints struct {
 	MagicSystem      *MagicSystem `json:"magic_system,omitempty"`
 	TechnologyLevel  string       `json:"technology_level"`
 	GeographySummary string       `json:"geography_summary"`
+
+	// 移动时间约束（时间线校验使用）
+	TravelTimes    []TravelTime `json:"travel_times,omitempty"`     // 地点之间的最短移动时间
+	MinTravelHours float64      `json:"min_travel_hours,omitempty"` // 未单独列出的两地之间的最短移动时间，0表示不校验
+}
+
+// TravelTime 两地之间的最短移动时间（双向）
+type TravelTime struct {
+	From  string  `json:"from"`
+	To    string  `json:"to"`
+	Hours float64 `json:"hours"`
 }
 
 // ============================================
@@ -482,13 +710,16 @@ type Character struct {
 	UpdatedAt time.Time `json:"updated_at"`
 
 	// 静态档案（世界设定器生成）
-	StaticProfile StaticProfile `json:"static_profile" gorm:"type:json"`
+	StaticProfile StaticProfile `json:"static_profile" gorm:"type:json;serializer:json"`
 
 	// 叙事档案（叙事器生成）
-	NarrativeProfile NarrativeProfile `json:"narrative_profile" gorm:"type:json"`
+	NarrativeProfile NarrativeProfile `json:"narrative_profile" gorm:"type:json;serializer:json"`
 
 	// 动态状态（写作器维护）
-	DynamicState DynamicState `json:"dynamic_state" gorm:"type:json"`
+	DynamicState DynamicState `json:"dynamic_state" gorm:"type:json;serializer:json"`
+
+	// 立绘提示词（按需生成）
+	Portrait *CharacterPortrait `json:"portrait,omitempty" gorm:"type:json;serializer:json"`
 }
 
 // StaticProfile 静态档案
@@ -512,6 +743,7 @@ type NarrativeProfile struct {
 	BeliefSystem  BeliefSystem             `json:"belief_system"`
 	ArcPlan       *ArcPlan                 `json:"arc_plan,omitempty"`
 	Relationships map[string]*Relationship `json:"relationships"`
+	Voice         *VoiceProfile            `json:"voice,omitempty"` // 语音档案
 }
 
 // Trait 特质
@@ -614,26 +846,33 @@ type Knowledge struct {
 
 // NarrativeBlueprint 叙事蓝图
 type NarrativeBlueprint struct {
-	ID        string    `json:"id" gorm:"primaryKey"`
-	WorldID   string    `json:"world_id"`
-	ProjectID string    `json:"project_id"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID        string         `json:"id" gorm:"primaryKey"`
+	WorldID   string         `json:"world_id"`
+	ProjectID string         `json:"project_id"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" gorm:"index"` // 软删除，随项目删除时与项目的删除时间相同
 
 	// 核心内容
-	StoryOutline  StoryOutline        `json:"story_outline" gorm:"type:json"`
-	ChapterPlans  []ChapterPlan       `json:"chapter_plans" gorm:"type:json;serializer:json"`
-	Scenes        []SceneInstruction  `json:"scenes" gorm:"type:json;serializer:json"`
-	CharacterArcs map[string]*ArcPlan `json:"character_arcs" gorm:"type:json"`
-	ThemePlan     ThemePlan           `json:"theme_plan" gorm:"type:json"`
+	StoryOutline     StoryOutline             `json:"story_outline" gorm:"type:json;serializer:json"`
+	ChapterPlans     []ChapterPlan            `json:"chapter_plans" gorm:"type:json;serializer:json"`
+	Scenes           []SceneInstruction       `json:"scenes" gorm:"type:json;serializer:json"`
+	CharacterArcs    map[string]*ArcPlan      `json:"character_arcs" gorm:"type:json;serializer:json"`
+	ThemePlan        ThemePlan                `json:"theme_plan" gorm:"type:json;serializer:json"`
+	VoiceProfiles    map[string]*VoiceProfile `json:"voice_profiles,omitempty" gorm:"type:json;serializer:json"`    // 角色名 -> 语音档案
+	POVPolicy        *POVPolicy               `json:"pov_policy,omitempty" gorm:"type:json;serializer:json"`        // 视角策略，为空表示不约束
+	Warnings         []DegradationWarning     `json:"warnings,omitempty" gorm:"type:json;serializer:json"`          // 降级警告，非空表示部分内容为兜底占位
+	EndingCandidates []EndingCandidate        `json:"ending_candidates,omitempty" gorm:"type:json;serializer:json"` // 结局候选，选定的一项写入第三幕
+	RomanceArcs      []RomanceArc             `json:"romance_arcs,omitempty" gorm:"type:json;serializer:json"`      // 感情线节拍规划
 }
 
 // StoryOutline 故事大纲
 type StoryOutline struct {
-	StructureType string `json:"structure_type"` // three_act, heros_journey, kishotenketsu
-	Act1          Act1   `json:"act1"`
-	Act2          Act2   `json:"act2"`
-	Act3          Act3   `json:"act3"`
+	StructureType string        `json:"structure_type"` // three_act, heros_journey, kishotenketsu
+	Act1          Act1          `json:"act1"`
+	Act2          Act2          `json:"act2"`
+	Act3          Act3          `json:"act3"`
+	Beats         []OutlineBeat `json:"beats,omitempty"` // 自定义节拍表的节拍落点
 }
 
 // Act1 第一幕
@@ -668,6 +907,9 @@ type ChapterPlan struct {
 	EndingHook      string   `json:"ending_hook"`
 	WordCount       int      `json:"word_count"`
 	Status          string   `json:"status"` // pending, generating, completed
+
+	ConflictIntensity int      `json:"conflict_intensity,omitempty"` // 本章冲突强度 0-100
+	SceneTypes        []string `json:"scene_types,omitempty"`        // 关键场景类型：对话/动作/内心/过渡/描写
 }
 
 // SceneInstruction 场景指令
@@ -684,6 +926,24 @@ type SceneInstruction struct {
 	ExpectedLength int      `json:"expected_length"` // 字数
 	Mood           string   `json:"mood"`            // 氛围要求
 	Status         string   `json:"status"`          // pending, generating, completed
+
+	StoryTime *StoryTime `json:"story_time,omitempty"` // 故事内时间
+
+	EntityRefs []string `json:"entity_refs,omitempty"` // 场景涉及的世界实体ID（见 WorldSetting.Entities）
+
+	Choreography *CombatChoreography `json:"choreography,omitempty"` // 动作场景的战斗分解
+}
+
+// StoryTime 故事内时间，以故事开端为零点，单位为小时
+type StoryTime struct {
+	Start    float64 `json:"start"`              // 开始时间（距故事开端的小时数）
+	Duration float64 `json:"duration,omitempty"` // 持续时长（小时）
+	Label    string  `json:"label,omitempty"`    // 时间描述，如“第三日清晨”
+}
+
+// End 结束时间
+func (t *StoryTime) End() float64 {
+	return t.Start + t.Duration
 }
 
 // ThemePlan 主题规划
@@ -728,7 +988,7 @@ type SceneOutput struct {
 	Style        string `json:"style"`
 
 	// 状态更新
-	StateUpdates StateUpdates `json:"state_updates" gorm:"type:json"`
+	StateUpdates StateUpdates `json:"state_updates" gorm:"type:json;serializer:json"`
 }
 
 // StateUpdates 状态更新
@@ -1002,17 +1262,56 @@ type StoryPotential struct {
 
 // Chapter 章节
 type Chapter struct {
-	ID          string        `json:"id" gorm:"primaryKey"`
-	ProjectID   string        `json:"project_id" gorm:"not null;index"`
-	ChapterNum  int           `json:"chapter_num" gorm:"not null"`
-	Title       string        `json:"title" gorm:"size:200;not null"`
-	Content     string        `json:"content" gorm:"type:text"`
-	WordCount   int           `json:"word_count" gorm:"default:0"`
-	AIWordCount int           `json:"ai_generated_word_count" gorm:"default:0"`
-	Status      ChapterStatus `json:"status" gorm:"size:20;default:'draft'"`
-	GeneratedAt *time.Time    `json:"generated_at,omitempty"`
-	CreatedAt   time.Time     `json:"created_at"`
-	UpdatedAt   time.Time     `json:"updated_at"`
+	ID                string                `json:"id" gorm:"primaryKey"`
+	ProjectID         string                `json:"project_id" gorm:"not null;index"`
+	ChapterNum        int                   `json:"chapter_num" gorm:"not null"`
+	Title             string                `json:"title" gorm:"size:200;not null"`
+	Content           string                `json:"content" gorm:"type:text"`
+	WordCount         int                   `json:"word_count" gorm:"default:0"`
+	AIWordCount       int                   `json:"ai_generated_word_count" gorm:"default:0"`
+	Status            ChapterStatus         `json:"status" gorm:"size:20;default:'draft'"`
+	Version           int                   `json:"version" gorm:"not null;default:1"`                             // 乐观锁版本号，每次保存加一
+	DetailOutline     JSON                  `json:"detail_outline,omitempty" gorm:"type:json"`                     // 章节细纲（narrative.ChapterDetailOutline）
+	Summary           string                `json:"summary,omitempty" gorm:"type:text"`                            // 本章摘要（由正文生成）
+	RollingSummary    string                `json:"rolling_summary,omitempty" gorm:"type:text"`                    // 截至本章的故事梗概，作为下一章的前情
+	StateDeltas       []CharacterStateDelta `json:"state_deltas,omitempty" gorm:"type:json;serializer:json"`       // 本章角色状态变化
+	VoiceIssues       []VoiceIssue          `json:"voice_issues,omitempty" gorm:"type:json;serializer:json"`       // 与语音档案不符的台词
+	HookScore         *HookScore            `json:"hook_score,omitempty" gorm:"type:json;serializer:json"`         // 开篇与章末钩子评分
+	TitleCandidates   []TitleCandidate      `json:"title_candidates,omitempty" gorm:"type:json;serializer:json"`   // 标题候选，按综合分从高到低
+	ContinuityIssues  []ContinuityIssue     `json:"continuity_issues,omitempty" gorm:"type:json;serializer:json"`  // 与已确立细节矛盾之处
+	ThemeCoverage     *ThemeCoverage        `json:"theme_coverage,omitempty" gorm:"type:json;serializer:json"`     // 本章对主题、象征和母题的体现
+	StyleMetrics      *StyleMetrics         `json:"style_metrics,omitempty" gorm:"type:json;serializer:json"`      // 句长、对话占比、副词密度等文风指标
+	DuplicatePassages []DuplicatePassage    `json:"duplicate_passages,omitempty" gorm:"type:json;serializer:json"` // 与此前章节近似重复的段落
+	GeneratedAt       *time.Time            `json:"generated_at,omitempty"`
+	CreatedAt         time.Time             `json:"created_at"`
+	UpdatedAt         time.Time             `json:"updated_at"`
+	DeletedAt         gorm.DeletedAt        `json:"-" gorm:"index"` // 软删除，随项目删除时与项目的删除时间相同
+}
+
+// CharacterStateDelta 角色在一章中的状态变化
+type CharacterStateDelta struct {
+	Character    string   `json:"character"`
+	Emotion      string   `json:"emotion,omitempty"`      // 章末情绪
+	Intensity    int      `json:"intensity,omitempty"`    // 章末情绪强度 0-100，0 表示未给出
+	Location     string   `json:"location,omitempty"`     // 章末所在地
+	Learned      []string `json:"learned,omitempty"`      // 新得知的信息
+	Relationship string   `json:"relationship,omitempty"` // 关系变化
+	Change       string   `json:"change,omitempty"`       // 其他变化（受伤、获得物品、立场转变等）
+}
+
+// ChapterLock 章节编辑锁（建议锁，仅用于提示"正在被他人编辑"）
+type ChapterLock struct {
+	ChapterID string    `json:"chapter_id" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"size:100;not null"`
+	Username  string    `json:"username" gorm:"size:50"`
+	ExpiresAt time.Time `json:"expires_at"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// IsExpired 锁是否已过期
+func (l *ChapterLock) IsExpired() bool {
+	return time.Now().After(l.ExpiresAt)
 }
 
 // ChapterStatus 章节状态
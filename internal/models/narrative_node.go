@@ -33,14 +33,14 @@ type NarrativeNode struct {
 	Content     string         `json:"content" gorm:"type:text"`
 
 	// AI生成的元数据
-	Metadata    NodeMetadata   `json:"metadata" gorm:"type:jsonb"`
+	Metadata    NodeMetadata   `json:"metadata" gorm:"type:jsonb;serializer:json"`
 
 	// 分支选项（AI生成的多个选项）
-	Branches         []NodeBranch `json:"branches" gorm:"type:jsonb"`
+	Branches         []NodeBranch `json:"branches" gorm:"type:jsonb;serializer:json"`
 	SelectedBranchID *string      `json:"selected_branch_id,omitempty"`
 
 	// 世界设定快照
-	WorldStages WorldStagesSnapshot `json:"world_stages" gorm:"type:jsonb"`
+	WorldStages WorldStagesSnapshot `json:"world_stages" gorm:"type:jsonb;serializer:json"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
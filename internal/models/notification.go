@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// ============================================
+// 通知渠道
+// ============================================
+
+// 通知渠道类型
+const (
+	NotifyChannelEmail    = "email"    // SMTP 邮件，服务器在配置文件 notify.smtp 中设置
+	NotifyChannelFeishu   = "feishu"   // 飞书群机器人
+	NotifyChannelDingTalk = "dingtalk" // 钉钉群机器人
+	NotifyChannelTelegram = "telegram" // Telegram Bot
+)
+
+// 通知事件
+const (
+	NotifyEventProjectCompleted = "project_completed" // 项目创作完成
+	NotifyEventProjectFailed    = "project_failed"    // 项目创作失败
+	NotifyEventBatchFinished    = "batch_finished"    // 批量创作全部结束
+	NotifyEventBudgetWarning    = "budget_warning"    // 本月LLM用量达到提醒线
+	NotifyEventBudgetExceeded   = "budget_exceeded"   // 本月LLM用量达到上限，后续请求被拒绝
+	NotifyEventTest             = "test"              // 测试消息
+)
+
+// NotifyEvents 可订阅的通知事件
+var NotifyEvents = []string{
+	NotifyEventProjectCompleted,
+	NotifyEventProjectFailed,
+	NotifyEventBatchFinished,
+	NotifyEventBudgetWarning,
+	NotifyEventBudgetExceeded,
+}
+
+// ValidNotifyEvent 是否为可订阅的通知事件
+func ValidNotifyEvent(event string) bool {
+	for _, e := range NotifyEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationChannel 用户配置的通知渠道，只推送用户勾选的事件
+type NotificationChannel struct {
+	ID         string            `json:"id" gorm:"primaryKey"`
+	UserID     string            `json:"user_id" gorm:"size:100;index"`
+	Type       string            `json:"type" gorm:"size:20"` // email/feishu/dingtalk/telegram
+	Name       string            `json:"name" gorm:"size:100"`
+	Settings   map[string]string `json:"settings" gorm:"type:json;serializer:json"` // 渠道参数，如 to、webhook_url、secret、bot_token、chat_id
+	Events     []string          `json:"events" gorm:"type:json;serializer:json"`   // 订阅的事件
+	Enabled    bool              `json:"enabled"`
+	LastSentAt *time.Time        `json:"last_sent_at,omitempty"`
+	LastError  string            `json:"last_error,omitempty" gorm:"type:text"`
+	CreatedAt  time.Time         `json:"created_at"`
+	UpdatedAt  time.Time         `json:"updated_at"`
+}
+
+// Subscribes 是否订阅了事件；测试消息总是发送
+func (ch *NotificationChannel) Subscribes(event string) bool {
+	if event == NotifyEventTest {
+		return true
+	}
+	for _, e := range ch.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,85 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// ============================================
+// 角色立绘提示词
+// ============================================
+
+// CharacterPortrait 角色立绘：结构化外貌描述及可直接用于文生图的提示词
+type CharacterPortrait struct {
+	Appearance       PortraitAppearance `json:"appearance"`
+	Tags             PortraitTags       `json:"tags"`
+	Era              string             `json:"era,omitempty"`       // 角色所处时代
+	ArtStyle         string             `json:"art_style,omitempty"` // 画风
+	Prompt           string             `json:"prompt"`              // Stable Diffusion 正向提示词
+	NegativePrompt   string             `json:"negative_prompt"`     // Stable Diffusion 反向提示词
+	MidjourneyPrompt string             `json:"midjourney_prompt"`   // Midjourney 提示词
+	GeneratedAt      time.Time          `json:"generated_at"`
+}
+
+// PortraitAppearance 结构化外貌描述（中文）
+type PortraitAppearance struct {
+	Summary     string   `json:"summary"`     // 一句话外貌概括
+	Face        string   `json:"face"`        // 脸型与五官
+	Hair        string   `json:"hair"`        // 发型发色
+	Eyes        string   `json:"eyes"`        // 眼睛
+	Build       string   `json:"build"`       // 身材体态
+	Skin        string   `json:"skin"`        // 肤色与特征（疤痕、纹身等）
+	Clothing    string   `json:"clothing"`    // 服饰（符合时代与身份）
+	Accessories []string `json:"accessories"` // 配饰、武器、法器
+	Expression  string   `json:"expression"`  // 常见神态
+	Palette     string   `json:"palette"`     // 主色调
+}
+
+// PortraitTags 文生图英文关键词
+type PortraitTags struct {
+	Subject  []string `json:"subject"`  // 人物主体：性别、年龄、外貌
+	Clothing []string `json:"clothing"` // 服饰与配饰
+	Setting  []string `json:"setting"`  // 背景环境
+	Style    []string `json:"style"`    // 画风、光照、构图
+	Negative []string `json:"negative"` // 需要避免的元素（如不符合时代的物品）
+}
+
+// portraitQualityTags Stable Diffusion 通用质量词
+var portraitQualityTags = []string{"masterpiece", "best quality", "highly detailed", "portrait", "upper body"}
+
+// portraitNegativeTags Stable Diffusion 通用反向词
+var portraitNegativeTags = []string{"lowres", "bad anatomy", "bad hands", "extra fingers", "blurry", "watermark", "text"}
+
+// BuildPrompts 由英文关键词拼出 Stable Diffusion 与 Midjourney 提示词
+func (p *CharacterPortrait) BuildPrompts() {
+	subject := joinTags(p.Tags.Subject, p.Tags.Clothing, p.Tags.Setting)
+	p.Prompt = joinTags(portraitQualityTags, p.Tags.Subject, p.Tags.Clothing, p.Tags.Setting, p.Tags.Style)
+	p.NegativePrompt = joinTags(portraitNegativeTags, p.Tags.Negative)
+
+	mj := "portrait of " + subject
+	if style := joinTags(p.Tags.Style); style != "" {
+		mj += ", " + style
+	}
+	if len(p.Tags.Negative) > 0 {
+		mj += " --no " + joinTags(p.Tags.Negative)
+	}
+	p.MidjourneyPrompt = mj + " --ar 2:3"
+}
+
+// joinTags 去空去重后用逗号连接关键词
+func joinTags(groups ...[]string) string {
+	seen := make(map[string]bool)
+	tags := make([]string, 0)
+	for _, group := range groups {
+		for _, tag := range group {
+			tag = strings.TrimSpace(tag)
+			key := strings.ToLower(tag)
+			if tag == "" || seen[key] {
+				continue
+			}
+			seen[key] = true
+			tags = append(tags, tag)
+		}
+	}
+	return strings.Join(tags, ", ")
+}
@@ -0,0 +1,121 @@
+package models
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ============================================
+// 视角策略
+// ============================================
+
+// POVMode 视角模式
+type POVMode string
+
+const (
+	POVSingle      POVMode = "single"      // 单一视角：全书只用一个视角角色
+	POVAlternating POVMode = "alternating" // 按章轮换：每章一个视角角色，按顺序轮换
+	POVOmniscient  POVMode = "omniscient"  // 全知视角：叙述者可进入任意角色内心
+)
+
+// POVPolicy 蓝图级视角策略，约束场景指令的视角角色和正文的叙述方式
+type POVPolicy struct {
+	Mode       POVMode  `json:"mode"`
+	Characters []string `json:"characters,omitempty"` // 单一视角时为唯一视角角色，按章轮换时为轮换顺序
+}
+
+// POVViolation 场景视角与策略不符
+type POVViolation struct {
+	Chapter  int    `json:"chapter"`
+	Scene    int    `json:"scene"`
+	Expected string `json:"expected"` // 策略要求的视角角色
+	Actual   string `json:"actual"`   // 场景指令中的视角角色
+	Message  string `json:"message"`
+}
+
+// Validate 检查策略本身是否完整
+func (p *POVPolicy) Validate() error {
+	switch p.Mode {
+	case POVSingle:
+		if len(p.Characters) != 1 || strings.TrimSpace(p.Characters[0]) == "" {
+			return fmt.Errorf("单一视角需要指定恰好一个视角角色")
+		}
+	case POVAlternating:
+		if len(p.Characters) < 2 {
+			return fmt.Errorf("按章轮换视角至少需要两个视角角色")
+		}
+		seen := make(map[string]bool, len(p.Characters))
+		for _, name := range p.Characters {
+			if strings.TrimSpace(name) == "" {
+				return fmt.Errorf("视角角色不能为空")
+			}
+			if seen[name] {
+				return fmt.Errorf("视角角色重复: %s", name)
+			}
+			seen[name] = true
+		}
+	case POVOmniscient:
+	default:
+		return fmt.Errorf("未知的视角模式: %s", p.Mode)
+	}
+	return nil
+}
+
+// ExpectedPOV 指定章节应使用的视角角色，全知视角或未设置策略时返回空
+func (p *POVPolicy) ExpectedPOV(chapter int) string {
+	if p == nil || len(p.Characters) == 0 {
+		return ""
+	}
+	switch p.Mode {
+	case POVSingle:
+		return p.Characters[0]
+	case POVAlternating:
+		if chapter <= 0 {
+			chapter = 1
+		}
+		return p.Characters[(chapter-1)%len(p.Characters)]
+	default:
+		return ""
+	}
+}
+
+// Check 检查场景指令的视角角色，符合策略时返回nil
+func (p *POVPolicy) Check(scene SceneInstruction) *POVViolation {
+	expected := p.ExpectedPOV(scene.Chapter)
+	if expected == "" || scene.POVCharacter == expected {
+		return nil
+	}
+	v := &POVViolation{
+		Chapter:  scene.Chapter,
+		Scene:    scene.Scene,
+		Expected: expected,
+		Actual:   scene.POVCharacter,
+	}
+	if p.Mode == POVSingle {
+		v.Message = fmt.Sprintf("单一视角策略要求全书使用%s的视角", expected)
+	} else {
+		v.Message = fmt.Sprintf("按章轮换策略要求第%d章使用%s的视角", scene.Chapter, expected)
+	}
+	if scene.POVCharacter == "" {
+		v.Message += "，场景未指定视角角色"
+	} else {
+		v.Message += fmt.Sprintf("，场景使用了%s", scene.POVCharacter)
+	}
+	return v
+}
+
+// Constraint 正文生成的视角约束说明
+func (p *POVPolicy) Constraint(chapter int) string {
+	if p == nil {
+		return ""
+	}
+	switch p.Mode {
+	case POVSingle, POVAlternating:
+		pov := p.ExpectedPOV(chapter)
+		return fmt.Sprintf("严格限定在%s的视角：只写%s能看到、听到、想到的内容；其他角色的想法只能通过言行推测，不得直接描写其内心；不得中途切换视角", pov, pov)
+	case POVOmniscient:
+		return "使用全知视角：叙述者可以进入多个角色的内心，但同一段落只进入一个角色的内心，切换时要有明确过渡"
+	default:
+		return ""
+	}
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ============================================
+// 用户预算
+// ============================================
+
+// UserQuota 管理员为单个用户设置的月度预算，字段为空时沿用系统配置中的默认值
+// 上限为 0 表示不限
+type UserQuota struct {
+	UserID        string    `json:"user_id" gorm:"primaryKey;size:100"`
+	MonthlyTokens *int      `json:"monthly_tokens,omitempty"` // 每月token上限
+	MonthlyCost   *float64  `json:"monthly_cost,omitempty"`   // 每月费用上限，按模型单价估算
+	WarnRatio     *float64  `json:"warn_ratio,omitempty"`     // 用量达到上限的该比例时提醒，如 0.8
+	Note          string    `json:"note,omitempty" gorm:"size:255"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+// UserUsageMonth 用户按月累计的LLM用量
+type UserUsageMonth struct {
+	UserID       string     `json:"user_id" gorm:"primaryKey;size:100"`
+	Month        string     `json:"month" gorm:"primaryKey;size:7"` // YYYY-MM，服务器时区
+	Requests     int        `json:"requests"`
+	InputTokens  int        `json:"input_tokens"`
+	OutputTokens int        `json:"output_tokens"`
+	TotalTokens  int        `json:"total_tokens"`
+	Cost         float64    `json:"cost"`
+	WarnedAt     *time.Time `json:"warned_at,omitempty"`   // 已发送提醒线通知
+	ExceededAt   *time.Time `json:"exceeded_at,omitempty"` // 已发送达到上限通知
+	UpdatedAt    time.Time  `json:"updated_at"`
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ============================================
+// 外部排行榜快照
+// ============================================
+
+// RankSnapshot 某一时刻的排行榜快照，用于计算题材和标签的升降趋势
+type RankSnapshot struct {
+	ID         string             `json:"id" gorm:"primaryKey"`
+	Source     string             `json:"source" gorm:"size:50;index"` // 榜单来源，如 fanqie
+	CategoryID string             `json:"category_id" gorm:"size:50"`
+	Books      []RankSnapshotBook `json:"books" gorm:"type:json;serializer:json"`
+	TakenAt    time.Time          `json:"taken_at" gorm:"index"`
+}
+
+// RankSnapshotBook 快照中的一本书
+type RankSnapshotBook struct {
+	BookID   string   `json:"book_id"`
+	BookName string   `json:"book_name"`
+	Category string   `json:"category,omitempty"`
+	Rank     int      `json:"rank"` // 从1开始
+	Score    float64  `json:"score,omitempty"`
+	Tags     []string `json:"tags"` // 由分类和简介提取的题材标签
+}
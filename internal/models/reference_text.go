@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// ============================================
+// 原创性参考文本
+// ============================================
+
+// ReferenceText 项目上传的参考文本：生成的正文不能照搬其中的段落（如同人转原创时的原作）
+type ReferenceText struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	ProjectID string    `json:"project_id" gorm:"size:100;index"`
+	UserID    string    `json:"user_id" gorm:"size:100"`
+	Title     string    `json:"title" gorm:"size:200"`
+	Content   string    `json:"content,omitempty" gorm:"type:text"`
+	WordCount int       `json:"word_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// OriginalitySettings 项目的原创性检查设置
+type OriginalitySettings struct {
+	Threshold float64 `json:"threshold"` // 单章雷同字数占比阈值（0-1），为 0 时使用配置中的默认值
+	Disabled  bool    `json:"disabled"`  // 关闭导出前的原创性检查
+}
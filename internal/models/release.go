@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// ============================================
+// 连载更新计划
+// ============================================
+
+// 更新单元发布状态
+const (
+	InstallmentPlanned   = "planned"   // 已规划，正文尚未写出
+	InstallmentReady     = "ready"     // 正文已就绪
+	InstallmentScheduled = "scheduled" // 已定时
+	InstallmentPublished = "published" // 已发布
+)
+
+// ValidInstallmentStatus 是否为合法的发布状态
+func ValidInstallmentStatus(status string) bool {
+	switch status {
+	case InstallmentPlanned, InstallmentReady, InstallmentScheduled, InstallmentPublished:
+		return true
+	}
+	return false
+}
+
+// ReleasePlan 连载更新计划：把章节切成固定长度的更新单元，发布状态独立于章节结构
+// 每个项目一份，重新规划时按章节和分段保留已有的发布状态
+type ReleasePlan struct {
+	ID                string        `json:"id" gorm:"primaryKey"`
+	ProjectID         string        `json:"project_id" gorm:"size:100;uniqueIndex"`
+	InstallmentLength int           `json:"installment_length"` // 每次更新的目标字数
+	Installments      []Installment `json:"installments" gorm:"type:json;serializer:json"`
+	CreatedAt         time.Time     `json:"created_at"`
+	UpdatedAt         time.Time     `json:"updated_at"`
+}
+
+// Installment 一次更新
+type Installment struct {
+	Sequence    int        `json:"sequence"`               // 全书更新序号，从1开始
+	Chapter     int        `json:"chapter"`                // 所属章节
+	Part        int        `json:"part"`                   // 章内分段序号，从1开始
+	Parts       int        `json:"parts"`                  // 本章分段总数
+	Start       int        `json:"start"`                  // 在章节正文中的起始位置（字），正文未写出时为0
+	End         int        `json:"end"`                    // 结束位置（字，不含）
+	Length      int        `json:"length"`                 // 字数（未写出时为目标字数）
+	MicroHook   string     `json:"micro_hook"`             // 结尾的小钩子
+	WeakHook    bool       `json:"weak_hook,omitempty"`    // 切点附近没有悬念段落，需要补写小钩子
+	Status      string     `json:"status"`                 // planned/ready/scheduled/published
+	ScheduledAt *time.Time `json:"scheduled_at,omitempty"` // 定时发布时间
+	PublishedAt *time.Time `json:"published_at,omitempty"`
+}
+
+// FindInstallment 按更新序号查找
+func (p *ReleasePlan) FindInstallment(sequence int) *Installment {
+	for i := range p.Installments {
+		if p.Installments[i].Sequence == sequence {
+			return &p.Installments[i]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+package models
+
+// ============================================
+// 感情线节拍
+// ============================================
+
+// RomanceBeatType 感情线节拍类型
+type RomanceBeatType string
+
+const (
+	BeatMeetCute         RomanceBeatType = "meet_cute"        // 初遇
+	BeatAttraction       RomanceBeatType = "attraction"       // 心动
+	BeatMisunderstanding RomanceBeatType = "misunderstanding" // 误会
+	BeatFirstKiss        RomanceBeatType = "first_kiss"       // 初吻
+	BeatDarkMoment       RomanceBeatType = "dark_moment"      // 至暗时刻（分离、决裂）
+	BeatCommitment       RomanceBeatType = "commitment"       // 确定关系、许下承诺
+)
+
+// RomanceBeatOrder 节拍的常规先后顺序
+var RomanceBeatOrder = []RomanceBeatType{BeatMeetCute, BeatAttraction, BeatMisunderstanding, BeatFirstKiss, BeatDarkMoment, BeatCommitment}
+
+// RomanceBeatNames 节拍类型的中文名
+var RomanceBeatNames = map[RomanceBeatType]string{
+	BeatMeetCute:         "初遇",
+	BeatAttraction:       "心动",
+	BeatMisunderstanding: "误会",
+	BeatFirstKiss:        "初吻",
+	BeatDarkMoment:       "至暗时刻",
+	BeatCommitment:       "确定关系",
+}
+
+// RomanceArc 一对角色的感情线，叠加在角色关系之上
+type RomanceArc struct {
+	ID         string        `json:"id"`
+	CharacterA string        `json:"character_a"`
+	CharacterB string        `json:"character_b"`
+	Dynamic    string        `json:"dynamic,omitempty"` // 感情模式，如欢喜冤家、破镜重圆
+	Beats      []RomanceBeat `json:"beats"`
+}
+
+// RomanceBeat 感情线上的一个节拍
+type RomanceBeat struct {
+	Type        RomanceBeatType `json:"type"`
+	Chapter     int             `json:"chapter"` // 目标章节
+	Description string          `json:"description"`
+}
+
+// Involves 场景角色中是否同时包含这对角色
+func (a *RomanceArc) Involves(characters []string) bool {
+	hasA, hasB := false, false
+	for _, c := range characters {
+		hasA = hasA || c == a.CharacterA
+		hasB = hasB || c == a.CharacterB
+	}
+	return hasA && hasB
+}
+
+// Pair 这对角色的称呼
+func (a *RomanceArc) Pair() string {
+	return a.CharacterA + "与" + a.CharacterB
+}
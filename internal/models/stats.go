@@ -0,0 +1,34 @@
+package models
+
+import "time"
+
+// ============================================
+// 系统统计
+// ============================================
+
+// LLMUsageDay 按日、提供商、模型汇总的LLM用量，支撑运维看板的每日token曲线
+type LLMUsageDay struct {
+	Date         string    `json:"date" gorm:"primaryKey;size:10"` // YYYY-MM-DD，服务器时区
+	Provider     string    `json:"provider" gorm:"primaryKey;size:50"`
+	Model        string    `json:"model" gorm:"primaryKey;size:100"`
+	Requests     int       `json:"requests"`
+	InputTokens  int       `json:"input_tokens"`
+	OutputTokens int       `json:"output_tokens"`
+	TotalTokens  int       `json:"total_tokens"`
+	Cost         float64   `json:"cost"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// StorageUsage 数据库存储占用
+type StorageUsage struct {
+	Driver        string       `json:"driver"`
+	DatabaseBytes int64        `json:"database_bytes"`
+	Tables        []TableUsage `json:"tables"` // 按占用（无法获取时按行数）降序
+}
+
+// TableUsage 单张表的行数和占用
+type TableUsage struct {
+	Name  string `json:"name"`
+	Rows  int64  `json:"rows"`
+	Bytes int64  `json:"bytes,omitempty"` // SQLite 不提供按表统计时为 0
+}
@@ -0,0 +1,44 @@
+package models
+
+import "time"
+
+// ============================================
+// 文风指标
+// ============================================
+
+// 叙述人称
+const (
+	NarrationFirst = "first" // 第一人称
+	NarrationThird = "third" // 第三人称
+)
+
+// StyleMetrics 一章正文的可读性与文风指标（由正文统计得出，不调用模型）
+type StyleMetrics struct {
+	WordCount         int            `json:"word_count"`
+	Sentences         int            `json:"sentences"`
+	SentenceLength    SentenceLength `json:"sentence_length"`
+	DialogueRatio     float64        `json:"dialogue_ratio"` // 对话占比（0-1）
+	AdverbDensity     float64        `json:"adverb_density"` // 每千字副词数
+	TopAdverbs        []PhraseCount  `json:"top_adverbs,omitempty"`
+	RepeatedPhrases   []PhraseCount  `json:"repeated_phrases,omitempty"` // 本章反复出现的短语
+	Narration         string         `json:"narration"`                  // 叙述人称 first/third
+	PersonSlips       int            `json:"person_slips"`               // 第三人称叙述中出现“我”的段落数
+	PersonConsistency float64        `json:"person_consistency"`         // 人称一致的叙述段落占比（0-1）
+	Digest            string         `json:"digest"`                     // 统计时正文的摘要，正文改动后需要重新统计
+	MeasuredAt        time.Time      `json:"measured_at"`
+}
+
+// SentenceLength 句长分布（按字计，不含空白和标点）
+type SentenceLength struct {
+	Mean    float64 `json:"mean"`
+	Median  int     `json:"median"`
+	P90     int     `json:"p90"`
+	Max     int     `json:"max"`
+	Buckets []int   `json:"buckets"` // 依次为 1-10、11-20、21-40、41-60、60 字以上的句子数
+}
+
+// PhraseCount 短语及其出现次数
+type PhraseCount struct {
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+}
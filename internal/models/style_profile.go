@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// ============================================
+// 写作风格档案
+// ============================================
+
+// StyleProfile 写作风格档案，可关联到项目，生成每个场景时注入写作指导
+type StyleProfile struct {
+	ID                string    `json:"id" gorm:"primaryKey"`
+	UserID            string    `json:"user_id" gorm:"size:100;index"`
+	Name              string    `json:"name" gorm:"size:100;not null"`
+	Description       string    `json:"description" gorm:"size:500"`
+	NarrativeDistance string    `json:"narrative_distance" gorm:"size:20"` // close, medium, distant
+	SentenceRhythm    string    `json:"sentence_rhythm" gorm:"size:255"`   // 句子节奏
+	VocabularyLevel   string    `json:"vocabulary_level" gorm:"size:20"`   // simple, moderate, sophisticated
+	Tense             string    `json:"tense" gorm:"size:20"`              // past, present
+	POVConvention     string    `json:"pov_convention" gorm:"size:255"`    // 视角约定
+	BannedPhrases     []string  `json:"banned_phrases" gorm:"type:json;serializer:json"`
+	Builtin           bool      `json:"builtin" gorm:"-"` // 内置预设，只读
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
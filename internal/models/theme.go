@@ -0,0 +1,80 @@
+package models
+
+import "time"
+
+// ============================================
+// 主题覆盖
+// ============================================
+
+// 主题深度，与 ThemeThreading.Depth 的取值一致
+const (
+	ThemeDepthNone          = "none"          // 正文没有体现主题
+	ThemeDepthSurface       = "surface"       // 点到为止：台词或情节中提及
+	ThemeDepthDeep          = "deep"          // 深入：角色的选择和代价体现主题
+	ThemeDepthPhilosophical = "philosophical" // 哲思：对主题本身提出追问或给出回答
+)
+
+// ThemeDepthRank 主题深度的等级，未知取值视为 0
+func ThemeDepthRank(depth string) int {
+	switch depth {
+	case ThemeDepthSurface:
+		return 1
+	case ThemeDepthDeep:
+		return 2
+	case ThemeDepthPhilosophical:
+		return 3
+	}
+	return 0
+}
+
+// ThemeOccurrence 象征或母题在一章中的出现
+type ThemeOccurrence struct {
+	Name     string `json:"name"`
+	Count    int    `json:"count"`              // 正文中字面出现的次数，仅以暗示方式出现时为 0
+	Evidence string `json:"evidence,omitempty"` // 原文片段
+}
+
+// ThemeCoverage 一章正文对主题规划的体现（每章写完后由正文分析得出）
+type ThemeCoverage struct {
+	Expressed  bool              `json:"expressed"` // 是否体现了核心主题
+	Depth      string            `json:"depth"`     // 体现的深度
+	Evidence   string            `json:"evidence,omitempty"`
+	Symbols    []ThemeOccurrence `json:"symbols"` // 出现的象征
+	Motifs     []ThemeOccurrence `json:"motifs"`  // 出现的母题
+	AnalyzedAt time.Time         `json:"analyzed_at"`
+}
+
+// HasSymbol 本章是否出现了该象征
+func (c *ThemeCoverage) HasSymbol(name string) bool {
+	for _, o := range c.Symbols {
+		if o.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// HasMotif 本章是否出现了该母题
+func (c *ThemeCoverage) HasMotif(name string) bool {
+	for _, o := range c.Motifs {
+		if o.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Empty 主题规划是否没有任何可检查的内容
+func (p *ThemePlan) Empty() bool {
+	return p.CoreTheme == "" && len(p.Threading) == 0 && len(p.Symbols) == 0 && len(p.Motifs) == 0
+}
+
+// ThreadingAt 安排在第 chapter 章的主题表达，没有时返回 nil
+func (p *ThemePlan) ThreadingAt(chapter int) *ThemeThreading {
+	for i := range p.Threading {
+		if p.Threading[i].Chapter == chapter {
+			return &p.Threading[i]
+		}
+	}
+	return nil
+}
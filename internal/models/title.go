@@ -0,0 +1,20 @@
+package models
+
+// ============================================
+// 章节标题候选
+// ============================================
+
+// TitleCandidate 章节标题候选及其吸引力评分
+type TitleCandidate struct {
+	Title       string  `json:"title"`
+	Curiosity   int     `json:"curiosity"`   // 好奇心：看到标题是否想点进来（0-10）
+	Specificity int     `json:"specificity"` // 具体度：是否有具体的人、物、事，而不是空泛的概括（0-10）
+	Fit         int     `json:"fit"`         // 贴合度：是否符合本章内容且不剧透关键转折（0-10）
+	Overall     float64 `json:"overall"`     // 三项平均，即点击吸引力
+	Comment     string  `json:"comment,omitempty"`
+}
+
+// Score 计算综合分
+func (t *TitleCandidate) Score() {
+	t.Overall = float64(t.Curiosity+t.Specificity+t.Fit) / 3
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ============================================
+// 章节译文
+// ============================================
+
+// ChapterTranslation 章节译文：按段落与原文对齐，供双语对照导出
+type ChapterTranslation struct {
+	ID            string               `json:"id" gorm:"primaryKey"`
+	ProjectID     string               `json:"project_id" gorm:"size:100;index"`
+	ChapterID     string               `json:"chapter_id" gorm:"size:100;index"`
+	ChapterNum    int                  `json:"chapter_num"`
+	Language      string               `json:"language" gorm:"size:10"` // 译文语言
+	Title         string               `json:"title" gorm:"size:200"`   // 译后章节标题
+	Segments      []TranslationSegment `json:"segments" gorm:"type:json;serializer:json"`
+	SourceVersion int                  `json:"source_version"` // 翻译时原文章节的版本号，章节再次修改后译文过期
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+}
+
+// TranslationSegment 一段原文及其译文
+type TranslationSegment struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// Stale 原文在翻译后是否又被修改
+func (t *ChapterTranslation) Stale(chapter *Chapter) bool {
+	return chapter.Version != t.SourceVersion
+}
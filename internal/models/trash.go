@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// ============================================
+// 回收站
+// ============================================
+
+// TrashRetention 删除的内容在回收站中保留的时长，过期后由定时任务彻底清除
+const TrashRetention = 30 * 24 * time.Hour
+
+// 可恢复的内容类型
+const (
+	TrashProject   = "project"
+	TrashWorld     = "world"
+	TrashBlueprint = "blueprint"
+	TrashChapter   = "chapter"
+)
+
+// ValidTrashKind 是否为可恢复的内容类型
+func ValidTrashKind(kind string) bool {
+	switch kind {
+	case TrashProject, TrashWorld, TrashBlueprint, TrashChapter:
+		return true
+	}
+	return false
+}
+
+// TrashItem 回收站中的一项
+// 项目在回收站中时，其下的蓝图和章节不单独列出；恢复项目时一并恢复与项目同时删除的蓝图和章节
+type TrashItem struct {
+	Kind      string    `json:"kind"`
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`                 // 项目/世界名称、章节标题；蓝图为所属项目ID
+	ProjectID string    `json:"project_id,omitempty"` // 蓝图和章节所属项目
+	UserID    string    `json:"user_id,omitempty"`    // 项目所有者；世界没有所有者
+	DeletedAt time.Time `json:"deleted_at"`
+	PurgeAt   time.Time `json:"purge_at"`
+}
@@ -0,0 +1,98 @@
+package models
+
+import "time"
+
+// ============================================
+// 套路检测
+// ============================================
+
+// 套路检测灵敏度
+const (
+	TropeSensitivityLow    = "low"    // 只报告严重的套路
+	TropeSensitivityMedium = "medium" // 报告中等及以上
+	TropeSensitivityHigh   = "high"   // 全部报告
+)
+
+// 套路出现的严重程度
+const (
+	TropeSeverityLow    = "low"
+	TropeSeverityMedium = "medium"
+	TropeSeverityHigh   = "high"
+)
+
+// 检测范围
+const (
+	TropeScopeOutline = "outline" // 大纲和章节规划
+	TropeScopeChapter = "chapter" // 章节正文
+)
+
+// Trope 套路条目
+type Trope struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name"`
+	Aliases     []string    `json:"aliases,omitempty"`
+	Description string      `json:"description"`
+	Genres      []WorldType `json:"genres,omitempty"` // 该题材的读者预期中的套路，默认不报告
+	Weight      float64     `json:"weight"`           // 陈旧程度（0-1），越高越容易判为严重
+}
+
+// TropeSettings 项目的套路检测配置
+type TropeSettings struct {
+	Sensitivity       string   `json:"sensitivity"`                   // low/medium/high，默认 medium
+	Allowed           []string `json:"allowed,omitempty"`             // 有意使用、不报告的套路ID
+	FlagGenreExpected bool     `json:"flag_genre_expected,omitempty"` // 是否也报告题材预期中的套路
+}
+
+// ValidTropeSensitivity 是否为有效的灵敏度，空值视为默认
+func ValidTropeSensitivity(s string) bool {
+	switch s {
+	case "", TropeSensitivityLow, TropeSensitivityMedium, TropeSensitivityHigh:
+		return true
+	}
+	return false
+}
+
+// Reports 该灵敏度下是否报告指定严重程度的出现
+func (s TropeSettings) Reports(severity string) bool {
+	switch s.Sensitivity {
+	case TropeSensitivityLow:
+		return severity == TropeSeverityHigh
+	case TropeSensitivityHigh:
+		return true
+	default:
+		return severity != TropeSeverityLow
+	}
+}
+
+// IsAllowed 套路是否被设为允许
+func (s TropeSettings) IsAllowed(tropeID string) bool {
+	for _, id := range s.Allowed {
+		if id == tropeID {
+			return true
+		}
+	}
+	return false
+}
+
+// TropeOccurrence 一处套路出现
+type TropeOccurrence struct {
+	TropeID    string `json:"trope_id"` // 套路库之外的为 other
+	Name       string `json:"name"`
+	Scope      string `json:"scope"` // outline/chapter
+	Chapter    int    `json:"chapter,omitempty"`
+	Paragraph  int    `json:"paragraph,omitempty"` // 段落序号（从1开始），大纲为0
+	Excerpt    string `json:"excerpt"`
+	Strength   int    `json:"strength"` // 套路化程度（1-10）
+	Severity   string `json:"severity"`
+	Reason     string `json:"reason"`
+	Suggestion string `json:"suggestion,omitempty"` // 改写建议，开启建议步骤时填写
+}
+
+// TropeReport 套路检测报告
+type TropeReport struct {
+	ProjectID   string            `json:"project_id"`
+	Settings    TropeSettings     `json:"settings"`
+	Occurrences []TropeOccurrence `json:"occurrences"`
+	Suppressed  int               `json:"suppressed"` // 因灵敏度、允许列表或题材预期未报告的数量
+	ScannedAt   time.Time         `json:"scanned_at"`
+}
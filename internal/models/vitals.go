@@ -0,0 +1,54 @@
+package models
+
+import "time"
+
+// ============================================
+// 生死与伤势台账
+// ============================================
+
+// VitalEventType 台账事件类型
+type VitalEventType string
+
+const (
+	VitalDeath        VitalEventType = "death"        // 死亡
+	VitalInjury       VitalEventType = "injury"       // 重伤
+	VitalRecovery     VitalEventType = "recovery"     // 伤愈
+	VitalResurrection VitalEventType = "resurrection" // 复活（须在正文中交代）
+	VitalReappeared   VitalEventType = "reappeared"   // 已故角色以活人身份出场且没有交代，属于违规记录
+)
+
+// VitalStatus 角色的生死状态
+type VitalStatus string
+
+const (
+	StatusAlive   VitalStatus = "alive"
+	StatusInjured VitalStatus = "injured"
+	StatusDead    VitalStatus = "dead"
+)
+
+// 台账来源
+const (
+	VitalFromProse  = "prose"  // 从章节分析提取，重新分析该章时替换
+	VitalFromManual = "manual" // 作者手工登记
+)
+
+// VitalEvent 台账中的一条记录
+type VitalEvent struct {
+	ID        string         `json:"id" gorm:"primaryKey"`
+	ProjectID string         `json:"project_id" gorm:"size:100;index"`
+	Character string         `json:"character" gorm:"size:200"`
+	Event     VitalEventType `json:"event" gorm:"size:20"`
+	Chapter   int            `json:"chapter"`
+	Detail    string         `json:"detail" gorm:"type:text"`             // 死因、伤情等
+	Evidence  string         `json:"evidence,omitempty" gorm:"type:text"` // 正文原句
+	Source    string         `json:"source" gorm:"size:20"`
+	CreatedAt time.Time      `json:"created_at"`
+}
+
+// VitalState 角色在某一章开始时的状态
+type VitalState struct {
+	Character string      `json:"character"`
+	Status    VitalStatus `json:"status"`
+	Since     int         `json:"since"`  // 进入该状态的章节
+	Detail    string      `json:"detail"` // 死因或伤情
+}
@@ -0,0 +1,58 @@
+package models
+
+import "strings"
+
+// ============================================
+// 角色语音档案
+// ============================================
+
+// VoiceProfile 角色语音档案，描述角色的说话方式，用于保持跨章节的对话一致
+type VoiceProfile struct {
+	Diction       string   `json:"diction"`                  // 用词特点（文白、粗细、专业术语等）
+	Formality     string   `json:"formality"`                // 正式程度：正式/中性/随意/粗俗
+	SentenceStyle string   `json:"sentence_style,omitempty"` // 句式习惯（短句、反问、长篇大论等）
+	Catchphrases  []string `json:"catchphrases,omitempty"`   // 口头禅
+	Avoid         []string `json:"avoid,omitempty"`          // 该角色绝不会说的词或语气
+	SampleLines   []string `json:"sample_lines,omitempty"`   // 示例台词
+}
+
+// Empty 档案是否没有任何内容
+func (v *VoiceProfile) Empty() bool {
+	return v == nil || (v.Diction == "" && v.Formality == "" && v.SentenceStyle == "" &&
+		len(v.Catchphrases) == 0 && len(v.Avoid) == 0 && len(v.SampleLines) == 0)
+}
+
+// Describe 单行描述，用于提示词
+func (v *VoiceProfile) Describe() string {
+	if v.Empty() {
+		return ""
+	}
+	parts := make([]string, 0, 6)
+	if v.Formality != "" {
+		parts = append(parts, "语体"+v.Formality)
+	}
+	if v.Diction != "" {
+		parts = append(parts, "用词"+v.Diction)
+	}
+	if v.SentenceStyle != "" {
+		parts = append(parts, "句式"+v.SentenceStyle)
+	}
+	if len(v.Catchphrases) > 0 {
+		parts = append(parts, "口头禅「"+strings.Join(v.Catchphrases, "」「")+"」")
+	}
+	if len(v.Avoid) > 0 {
+		parts = append(parts, "不会说"+strings.Join(v.Avoid, "、"))
+	}
+	if len(v.SampleLines) > 0 {
+		parts = append(parts, "例如“"+v.SampleLines[0]+"”")
+	}
+	return strings.Join(parts, "；")
+}
+
+// VoiceIssue 与说话者语音档案不符的台词
+type VoiceIssue struct {
+	Speaker    string `json:"speaker"`              // 说话者
+	Line       string `json:"line"`                 // 台词原文
+	Problem    string `json:"problem"`              // 不符之处
+	Suggestion string `json:"suggestion,omitempty"` // 改写建议
+}
@@ -0,0 +1,85 @@
+package models
+
+import "time"
+
+// ============================================
+// Webhook
+// ============================================
+
+// Webhook 事件
+const (
+	WebhookEventChapterGenerated   = "chapter_generated"   // 一章正文生成完成
+	WebhookEventEvolutionCompleted = "evolution_completed" // 叙事演化完成，蓝图已生成
+	WebhookEventConsistencyFailed  = "consistency_failed"  // 章节与已确立的细节矛盾
+	WebhookEventProjectCompleted   = "project_completed"   // 项目创作流程完成
+	WebhookEventProjectFailed      = "project_failed"      // 项目创作流程失败
+	WebhookEventPing               = "ping"                // 测试投递
+)
+
+// WebhookEvents 可订阅的事件
+var WebhookEvents = []string{
+	WebhookEventChapterGenerated,
+	WebhookEventEvolutionCompleted,
+	WebhookEventConsistencyFailed,
+	WebhookEventProjectCompleted,
+	WebhookEventProjectFailed,
+}
+
+// ValidWebhookEvent 是否为可订阅的事件
+func ValidWebhookEvent(event string) bool {
+	for _, e := range WebhookEvents {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// Webhook 投递状态
+const (
+	WebhookDeliveryPending   = "pending"   // 投递中或等待重试
+	WebhookDeliverySucceeded = "succeeded" // 对方返回 2xx
+	WebhookDeliveryFailed    = "failed"    // 重试用尽或不可重试的错误
+)
+
+// Webhook 项目级事件回调：事件发生时向 URL POST 签名的 JSON
+type Webhook struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	ProjectID string    `json:"project_id" gorm:"size:100;index"`
+	URL       string    `json:"url" gorm:"size:500"`
+	Secret    string    `json:"-" gorm:"size:200"`                       // 签名密钥，只在创建时返回
+	Events    []string  `json:"events" gorm:"type:json;serializer:json"` // 订阅的事件，为空表示全部
+	Enabled   bool      `json:"enabled"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Subscribes 是否订阅了事件；ping 总是投递
+func (w *Webhook) Subscribes(event string) bool {
+	if len(w.Events) == 0 || event == WebhookEventPing {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery 一次事件投递及其重试结果
+type WebhookDelivery struct {
+	ID           string     `json:"id" gorm:"primaryKey"`
+	WebhookID    string     `json:"webhook_id" gorm:"size:100;index"`
+	ProjectID    string     `json:"project_id" gorm:"size:100;index"`
+	Event        string     `json:"event" gorm:"size:50"`
+	Payload      string     `json:"payload" gorm:"type:text"`
+	Status       string     `json:"status" gorm:"size:20"`
+	Attempts     int        `json:"attempts"`
+	ResponseCode int        `json:"response_code,omitempty"`
+	ResponseBody string     `json:"response_body,omitempty" gorm:"type:text"` // 截断到 1KB
+	Error        string     `json:"error,omitempty" gorm:"type:text"`
+	NextRetryAt  *time.Time `json:"next_retry_at,omitempty"`
+	CreatedAt    time.Time  `json:"created_at" gorm:"index"`
+	DeliveredAt  *time.Time `json:"delivered_at,omitempty"`
+}
@@ -0,0 +1,230 @@
+package models
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// ============================================
+// 世界实体索引
+// ============================================
+
+// EntityKind 实体类型
+type EntityKind string
+
+const (
+	EntityRegion   EntityKind = "region"   // 地区（geography.regions）
+	EntityRace     EntityKind = "race"     // 种族（civilization.races）
+	EntityReligion EntityKind = "religion" // 宗教（civilization.religions）
+	EntityLaw      EntityKind = "law"      // 法律（society.laws）
+	EntityEvent    EntityKind = "event"    // 历史事件（history.events）
+)
+
+// WorldEntity 世界中可被引用的命名实体
+type WorldEntity struct {
+	ID         string     `json:"id"`
+	Kind       EntityKind `json:"kind"`
+	Name       string     `json:"name"`
+	Section    string     `json:"section"` // 所在分节，同 WorldSections
+	Summary    string     `json:"summary"`
+	References []string   `json:"references,omitempty"` // 该实体描述中提到的其他实体ID
+}
+
+// entitySource 建索引时的实体来源
+type entitySource struct {
+	entity *WorldEntity
+	idRef  *string // 来源结构体上的ID字段，nil 表示来源没有ID字段（如法律）
+	text   string  // 用于解析引用的描述文本
+}
+
+// RebuildEntityIndex 重建实体索引：为命名的地区、种族、宗教、法律、历史事件分配ID并解析相互引用
+// 来源结构体已有ID时沿用；没有ID字段的实体按类型+名称沿用旧索引中的ID，完成后刷新术语表
+func (w *WorldSetting) RebuildEntityIndex() {
+	previous := make(map[string]string, len(w.Entities))
+	for _, e := range w.Entities {
+		previous[string(e.Kind)+"/"+e.Name] = e.ID
+	}
+
+	sources := make([]entitySource, 0)
+	add := func(kind EntityKind, section, name string, idRef *string, summary string, text ...string) {
+		if strings.TrimSpace(name) == "" {
+			return
+		}
+		sources = append(sources, entitySource{
+			entity: &WorldEntity{Kind: kind, Name: name, Section: section, Summary: truncateRunes(summary, 80)},
+			idRef:  idRef,
+			text:   strings.Join(append(text, summary), "\n"),
+		})
+	}
+
+	for i := range w.Geography.Regions {
+		r := &w.Geography.Regions[i]
+		text := append([]string{}, r.Resources...)
+		add(EntityRegion, "geography", r.Name, &r.ID, r.Description, append(text, r.Risks...)...)
+	}
+	for i := range w.Civilization.Races {
+		r := &w.Civilization.Races[i]
+		text := append([]string{}, r.Traits...)
+		text = append(text, r.Abilities...)
+		for other, relation := range r.Relations {
+			text = append(text, other, relation)
+		}
+		add(EntityRace, "civilization", r.Name, &r.ID, r.Description, text...)
+	}
+	for i := range w.Civilization.Religions {
+		r := &w.Civilization.Religions[i]
+		text := append([]string{}, r.Ethics...)
+		text = append(text, r.Practices...)
+		if r.Organization != nil {
+			text = append(text, r.Organization.Leader)
+			text = append(text, r.Organization.Factions...)
+			for _, f := range r.Organization.FactionDetails {
+				text = append(text, f.Dispute, f.Rival, f.Ambition)
+			}
+			for _, site := range r.Organization.HolySites {
+				text = append(text, site.Name, site.Region)
+			}
+		}
+		add(EntityReligion, "civilization", r.Name, &r.ID, r.Cosmology, text...)
+	}
+	for _, l := range w.Society.Laws {
+		add(EntityLaw, "society", l.Name, nil, l.Description, l.Penalty)
+	}
+	for i := range w.History.Events {
+		e := &w.History.Events[i]
+		text := append([]string{e.Time, e.Impact}, e.Causes...)
+		text = append(text, e.Consequences...)
+		add(EntityEvent, "history", e.Name, &e.ID, e.Description, text...)
+	}
+
+	// 分配ID（写回来源结构体）
+	for _, s := range sources {
+		id := ""
+		if s.idRef != nil {
+			id = *s.idRef
+		}
+		if id == "" {
+			id = previous[string(s.entity.Kind)+"/"+s.entity.Name]
+		}
+		if id == "" {
+			id = string(s.entity.Kind) + "_" + uuid.New().String()[:8]
+		}
+		if s.idRef != nil {
+			*s.idRef = id
+		}
+		s.entity.ID = id
+	}
+
+	// 解析引用：描述文本中出现的其他实体名称（单字名称过于模糊，跳过）
+	entities := make([]WorldEntity, 0, len(sources))
+	for _, s := range sources {
+		for _, other := range sources {
+			if other.entity == s.entity || utf8.RuneCountInString(other.entity.Name) < 2 {
+				continue
+			}
+			if strings.Contains(s.text, other.entity.Name) {
+				s.entity.References = appendUnique(s.entity.References, other.entity.ID)
+			}
+		}
+		entities = append(entities, *s.entity)
+	}
+	w.Entities = entities
+
+	// 专有名词随设定变化，同步刷新术语表
+	w.RebuildGlossary()
+}
+
+// FindEntity 按ID或名称查找实体
+func (w *WorldSetting) FindEntity(nameOrID string) *WorldEntity {
+	for i := range w.Entities {
+		if w.Entities[i].ID == nameOrID || w.Entities[i].Name == nameOrID {
+			return &w.Entities[i]
+		}
+	}
+	return nil
+}
+
+// ReferencedBy 引用了指定实体的其他实体
+func (w *WorldSetting) ReferencedBy(id string) []WorldEntity {
+	result := make([]WorldEntity, 0)
+	for _, e := range w.Entities {
+		for _, ref := range e.References {
+			if ref == id {
+				result = append(result, e)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// EntityRefs 文本中提到的实体ID（按索引顺序），供场景指令引用
+func (w *WorldSetting) EntityRefs(text string) []string {
+	var refs []string
+	for _, e := range w.Entities {
+		if utf8.RuneCountInString(e.Name) < 2 {
+			continue
+		}
+		if strings.Contains(text, e.Name) {
+			refs = append(refs, e.ID)
+		}
+	}
+	return refs
+}
+
+// EntitySource 实体对应的完整设定（地区、种族、宗教、法律或历史事件）
+func (w *WorldSetting) EntitySource(e *WorldEntity) interface{} {
+	switch e.Kind {
+	case EntityRegion:
+		for _, r := range w.Geography.Regions {
+			if r.ID == e.ID {
+				return r
+			}
+		}
+	case EntityRace:
+		for _, r := range w.Civilization.Races {
+			if r.ID == e.ID {
+				return r
+			}
+		}
+	case EntityReligion:
+		for _, r := range w.Civilization.Religions {
+			if r.ID == e.ID {
+				return r
+			}
+		}
+	case EntityLaw:
+		for _, l := range w.Society.Laws {
+			if l.Name == e.Name {
+				return l
+			}
+		}
+	case EntityEvent:
+		for _, ev := range w.History.Events {
+			if ev.ID == e.ID {
+				return ev
+			}
+		}
+	}
+	return nil
+}
+
+// appendUnique 追加不重复的字符串
+func appendUnique(list []string, s string) []string {
+	for _, v := range list {
+		if v == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
+
+// truncateRunes 按字符截断
+func truncateRunes(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"math"
+	"strings"
+)
+
+// ============================================
+// 地理空间关系
+// ============================================
+
+// RegionByName 按名称查找地区（忽略首尾空白）
+func (g *Geography) RegionByName(name string) *Region {
+	name = strings.TrimSpace(name)
+	for i := range g.Regions {
+		if strings.TrimSpace(g.Regions[i].Name) == name {
+			return &g.Regions[i]
+		}
+	}
+	return nil
+}
+
+// RegionOf 地点所在的地区：地点名称与地区名称相同或包含地区名称，取最长匹配
+func (g *Geography) RegionOf(location string) *Region {
+	var best *Region
+	for i := range g.Regions {
+		name := strings.TrimSpace(g.Regions[i].Name)
+		if name == "" || !strings.Contains(location, name) {
+			continue
+		}
+		if best == nil || len(name) > len(best.Name) {
+			best = &g.Regions[i]
+		}
+	}
+	return best
+}
+
+// TravelTimes 由地区边界推算任意两个连通地区之间的最短移动时间（经相邻地区中转）
+func (g *Geography) TravelTimes() []TravelTime {
+	n := len(g.Regions)
+	if n == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, n)
+	for i, r := range g.Regions {
+		index[strings.TrimSpace(r.Name)] = i
+	}
+
+	inf := math.Inf(1)
+	dist := make([][]float64, n)
+	for i := range dist {
+		dist[i] = make([]float64, n)
+		for j := range dist[i] {
+			if i != j {
+				dist[i][j] = inf
+			}
+		}
+	}
+	hasBorder := false
+	for i, r := range g.Regions {
+		for _, b := range r.Borders {
+			j, ok := index[strings.TrimSpace(b.Region)]
+			if !ok || j == i || b.TravelHours <= 0 {
+				continue
+			}
+			hasBorder = true
+			// 边界双向通行，两边标注不同时取较短者
+			if b.TravelHours < dist[i][j] {
+				dist[i][j] = b.TravelHours
+				dist[j][i] = b.TravelHours
+			}
+		}
+	}
+	if !hasBorder {
+		return nil
+	}
+
+	// 地区数量很少，直接用 Floyd-Warshall
+	for k := 0; k < n; k++ {
+		for i := 0; i < n; i++ {
+			for j := 0; j < n; j++ {
+				if dist[i][k]+dist[k][j] < dist[i][j] {
+					dist[i][j] = dist[i][k] + dist[k][j]
+				}
+			}
+		}
+	}
+
+	times := make([]TravelTime, 0)
+	for i := 0; i < n; i++ {
+		for j := i + 1; j < n; j++ {
+			if math.IsInf(dist[i][j], 1) {
+				continue
+			}
+			times = append(times, TravelTime{From: g.Regions[i].Name, To: g.Regions[j].Name, Hours: dist[i][j]})
+		}
+	}
+	return times
+}
@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ============================================
+// 写作统计
+// ============================================
+
+// WritingDay 章节某一天的字数记录：当天第一次保存前的字数和最后一次保存后的字数
+type WritingDay struct {
+	ID         string    `json:"id" gorm:"primaryKey"`
+	ProjectID  string    `json:"project_id" gorm:"size:100;index"`
+	ChapterID  string    `json:"chapter_id" gorm:"size:100;uniqueIndex:idx_writing_day_chapter_date"`
+	ChapterNum int       `json:"chapter_num"`
+	Date       string    `json:"date" gorm:"size:10;uniqueIndex:idx_writing_day_chapter_date;index"` // 2006-01-02，服务器本地时区
+	StartWords int       `json:"start_words"`
+	EndWords   int       `json:"end_words"`
+	Completed  bool      `json:"completed"` // 当天最后一次保存时章节已完成
+	CreatedAt  time.Time `json:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Written 当天净增字数，删改多于新写时为负数
+func (d WritingDay) Written() int {
+	return d.EndWords - d.StartWords
+}
@@ -0,0 +1,45 @@
+// Package main 把 swag 生成的 Swagger 2.0 文档转换为 OpenAPI 3.0，供服务内置和客户端生成使用
+//
+// 用法: go run ./internal/openapi/convert <swagger.json> <openapi.json>
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+)
+
+func main() {
+	if len(os.Args) != 3 {
+		fmt.Fprintln(os.Stderr, "用法: convert <swagger.json> <openapi.json>")
+		os.Exit(2)
+	}
+	if err := convert(os.Args[1], os.Args[2]); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+// convert 读取 Swagger 2.0 文档，转换后按缩进格式写出
+func convert(in, out string) error {
+	data, err := os.ReadFile(in)
+	if err != nil {
+		return fmt.Errorf("读取 %s 失败: %w", in, err)
+	}
+	var doc2 openapi2.T
+	if err := json.Unmarshal(data, &doc2); err != nil {
+		return fmt.Errorf("解析 Swagger 文档失败: %w", err)
+	}
+	doc3, err := openapi2conv.ToV3(&doc2)
+	if err != nil {
+		return fmt.Errorf("转换为 OpenAPI 3 失败: %w", err)
+	}
+	result, err := json.MarshalIndent(doc3, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(out, append(result, '\n'), 0644)
+}
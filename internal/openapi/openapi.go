@@ -0,0 +1,63 @@
+// Package openapi 内置的 OpenAPI 文档与 Swagger UI
+//
+// openapi.json 由 handler 上的 swag 注释生成，再转换为 OpenAPI 3.0（make openapi），不要手动修改。
+package openapi
+
+import (
+	_ "embed"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	swaggerFiles "github.com/swaggo/files/v2"
+)
+
+// Spec OpenAPI 3.0 文档
+//
+//go:embed openapi.json
+var Spec []byte
+
+// SpecPath 文档地址
+const SpecPath = "/api/openapi.json"
+
+// UIPath Swagger UI 地址
+const UIPath = "/api/docs"
+
+// swaggerInitializer 替换 Swagger UI 自带的初始化脚本，指向内置文档
+const swaggerInitializer = `window.onload = function() {
+  window.ui = SwaggerUIBundle({
+    url: "` + SpecPath + `",
+    dom_id: '#swagger-ui',
+    deepLinking: true,
+    persistAuthorization: true,
+    presets: [SwaggerUIBundle.presets.apis, SwaggerUIStandalonePreset],
+    plugins: [SwaggerUIBundle.plugins.DownloadUrl],
+    layout: "StandaloneLayout"
+  });
+};
+`
+
+// Register 注册文档和 Swagger UI 路由
+func Register(r gin.IRouter) {
+	r.GET(SpecPath, ServeSpec)
+	r.GET(UIPath, func(c *gin.Context) {
+		c.Redirect(http.StatusMovedPermanently, UIPath+"/")
+	})
+	r.GET(UIPath+"/*filepath", serveUI)
+}
+
+// ServeSpec 返回 OpenAPI 文档
+func ServeSpec(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", Spec)
+}
+
+// serveUI 返回 Swagger UI 静态资源
+func serveUI(c *gin.Context) {
+	switch c.Param("filepath") {
+	case "/swagger-initializer.js":
+		c.Data(http.StatusOK, "application/javascript; charset=utf-8", []byte(swaggerInitializer))
+	case "/", "/index.html":
+		c.FileFromFS("/", http.FS(swaggerFiles.FS))
+	default:
+		c.FileFromFS(c.Param("filepath"), http.FS(swaggerFiles.FS))
+	}
+}
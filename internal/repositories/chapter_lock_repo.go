@@ -0,0 +1,104 @@
+// Package repositories 数据访问层
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	gormdb "github.com/xlei/xupu/pkg/gormdb"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+var (
+	ErrChapterLockNotFound = errors.New("章节未被锁定")
+	ErrChapterLocked       = errors.New("章节正在被他人编辑")
+)
+
+// ChapterLockRepository 章节编辑锁仓储
+type ChapterLockRepository struct {
+	db *gorm.DB
+}
+
+// NewChapterLockRepository 创建章节编辑锁仓储
+func NewChapterLockRepository() *ChapterLockRepository {
+	return &ChapterLockRepository{
+		db: gormdb.Get(),
+	}
+}
+
+// Get 获取章节当前的有效锁
+func (r *ChapterLockRepository) Get(ctx context.Context, chapterID string) (*models.ChapterLock, error) {
+	var lock models.ChapterLock
+	result := r.db.WithContext(ctx).Where("chapter_id = ?", chapterID).First(&lock)
+	if result.Error != nil {
+		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
+			return nil, ErrChapterLockNotFound
+		}
+		return nil, result.Error
+	}
+	if lock.IsExpired() {
+		return nil, ErrChapterLockNotFound
+	}
+	return &lock, nil
+}
+
+// Acquire 获取或续期章节锁
+// 锁被其他用户持有且未过期时返回当前锁和 ErrChapterLocked
+func (r *ChapterLockRepository) Acquire(ctx context.Context, chapterID, userID, username string, ttl time.Duration) (*models.ChapterLock, error) {
+	now := time.Now()
+	lock := models.ChapterLock{
+		ChapterID: chapterID,
+		UserID:    userID,
+		Username:  username,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// 单条 upsert：锁不存在、已过期或由本人持有时写入，否则不更新任何行
+	result := r.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "chapter_id"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"created_at": gorm.Expr("CASE WHEN chapter_locks.user_id = excluded.user_id THEN chapter_locks.created_at ELSE excluded.created_at END"),
+			"user_id":    gorm.Expr("excluded.user_id"),
+			"username":   gorm.Expr("excluded.username"),
+			"expires_at": gorm.Expr("excluded.expires_at"),
+			"updated_at": gorm.Expr("excluded.updated_at"),
+		}),
+		Where: clause.Where{Exprs: []clause.Expression{
+			gorm.Expr("chapter_locks.expires_at < ? OR chapter_locks.user_id = ?", now, userID),
+		}},
+	}).Create(&lock)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		current, err := r.Get(ctx, chapterID)
+		if err != nil {
+			return nil, err
+		}
+		return current, ErrChapterLocked
+	}
+
+	if err := r.db.WithContext(ctx).Where("chapter_id = ?", chapterID).First(&lock).Error; err != nil {
+		return nil, err
+	}
+	return &lock, nil
+}
+
+// Release 释放章节锁，只有持有者可以释放
+func (r *ChapterLockRepository) Release(ctx context.Context, chapterID, userID string) error {
+	result := r.db.WithContext(ctx).
+		Where("chapter_id = ? AND user_id = ?", chapterID, userID).
+		Delete(&models.ChapterLock{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrChapterLockNotFound
+	}
+	return nil
+}
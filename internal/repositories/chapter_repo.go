@@ -4,15 +4,19 @@ package repositories
 import (
 	"context"
 	"errors"
+	"log/slog"
+	"time"
 
 	"github.com/xlei/xupu/internal/models"
+	xdb "github.com/xlei/xupu/pkg/db"
 	gormdb "github.com/xlei/xupu/pkg/gormdb"
 	"gorm.io/gorm"
 )
 
 var (
-	ErrChapterNotFound      = errors.New("章节不存在")
-	ErrChapterAlreadyExists = errors.New("章节已存在")
+	ErrChapterNotFound        = errors.New("章节不存在")
+	ErrChapterAlreadyExists   = errors.New("章节已存在")
+	ErrChapterVersionConflict = errors.New("章节已被他人修改")
 )
 
 // ChapterRepository 章节仓储
@@ -33,6 +37,7 @@ func (r *ChapterRepository) Create(ctx context.Context, chapter *models.Chapter)
 	if result.Error != nil {
 		return result.Error
 	}
+	r.recordWritingDay(ctx, chapter)
 	return nil
 }
 
@@ -79,8 +84,39 @@ func (r *ChapterRepository) ListByProjectID(ctx context.Context, projectID strin
 
 // Update 更新章节
 func (r *ChapterRepository) Update(ctx context.Context, chapter *models.Chapter) error {
+	chapter.Version++
 	result := r.db.WithContext(ctx).Save(chapter)
-	return result.Error
+	if result.Error != nil {
+		chapter.Version--
+		return result.Error
+	}
+	r.recordWritingDay(ctx, chapter)
+	return nil
+}
+
+// UpdateWithVersion 按版本号条件更新章节标题、正文、字数和状态，版本不匹配时返回 ErrChapterVersionConflict
+func (r *ChapterRepository) UpdateWithVersion(ctx context.Context, chapter *models.Chapter, expectedVersion int) error {
+	result := r.db.WithContext(ctx).Model(&models.Chapter{}).
+		Where("id = ? AND version = ?", chapter.ID, expectedVersion).
+		Updates(map[string]interface{}{
+			"title":         chapter.Title,
+			"content":       chapter.Content,
+			"word_count":    chapter.WordCount,
+			"ai_word_count": chapter.AIWordCount,
+			"generated_at":  chapter.GeneratedAt,
+			"status":        chapter.Status,
+			"version":       expectedVersion + 1,
+			"updated_at":    time.Now(),
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrChapterVersionConflict
+	}
+	chapter.Version = expectedVersion + 1
+	r.recordWritingDay(ctx, chapter)
+	return nil
 }
 
 // UpdateContent 更新章节内容
@@ -94,6 +130,26 @@ func (r *ChapterRepository) UpdateContent(ctx context.Context, chapterID string,
 	return result.Error
 }
 
+// UpdateColumns 只更新章节的指定列（及更新时间），不覆盖正文、版本号等其他列
+// 用于 LLM 调用后回写分析结果，避免用调用前读取的旧数据覆盖期间的编辑
+func (r *ChapterRepository) UpdateColumns(ctx context.Context, chapter *models.Chapter, columns ...string) error {
+	result := r.db.WithContext(ctx).Model(chapter).Select(columns).Updates(chapter)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrChapterNotFound
+	}
+	return nil
+}
+
+// recordWritingDay 更新写作统计，失败不影响章节保存
+func (r *ChapterRepository) recordWritingDay(ctx context.Context, chapter *models.Chapter) {
+	if err := xdb.RecordWritingDay(r.db.WithContext(ctx), chapter); err != nil {
+		slog.Warn("记录写作统计失败", "chapter_id", chapter.ID, "error", err)
+	}
+}
+
 // Delete 删除章节
 func (r *ChapterRepository) Delete(ctx context.Context, chapterID string) error {
 	result := r.db.WithContext(ctx).Delete(&models.Chapter{}, "id = ?", chapterID)
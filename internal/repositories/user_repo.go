@@ -4,6 +4,7 @@ package repositories
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/xlei/xupu/internal/models"
 	gormdb "github.com/xlei/xupu/pkg/gormdb"
@@ -87,11 +88,11 @@ func (r *UserRepository) Update(ctx context.Context, user *models.User) error {
 	return result.Error
 }
 
-// UpdateLastLogin 更新最后登录时间
+// UpdateLastLogin 更新最后登录时间（使用应用时间而非 NOW()，SQLite 不支持该函数）
 func (r *UserRepository) UpdateLastLogin(ctx context.Context, userID string) error {
 	result := r.db.WithContext(ctx).Model(&models.User{}).
 		Where("id = ?", userID).
-		Update("last_login_at", gorm.Expr("NOW()"))
+		Update("last_login_at", time.Now())
 	return result.Error
 }
 
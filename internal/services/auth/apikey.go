@@ -0,0 +1,32 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// APIKeyPrefix API密钥的固定前缀，用于与 JWT 区分
+const APIKeyPrefix = "xpk_"
+
+// GenerateAPIKey 生成API密钥，返回明文、用于展示的前缀和用于存储的哈希
+func GenerateAPIKey() (plain, prefix, hash string, err error) {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		return "", "", "", err
+	}
+	plain = APIKeyPrefix + hex.EncodeToString(b)
+	return plain, plain[:len(APIKeyPrefix)+6], HashAPIKey(plain), nil
+}
+
+// HashAPIKey 计算API密钥的哈希，密钥本身随机性足够，不需要加盐
+func HashAPIKey(plain string) string {
+	sum := sha256.Sum256([]byte(plain))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey 令牌是否为API密钥
+func IsAPIKey(token string) bool {
+	return strings.HasPrefix(token, APIKeyPrefix)
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/internal/repositories"
+	"github.com/xlei/xupu/pkg/logx"
 )
 
 var (
@@ -89,7 +90,7 @@ func (s *AuthService) Login(ctx context.Context, req LoginRequest) (*models.User
 	// 更新最后登录时间
 	if err := s.userRepo.UpdateLastLogin(ctx, user.ID); err != nil {
 		// 记录日志但不影响登录
-		// log.Printf("Failed to update last login time: %v", err)
+		logx.L().Warn("更新最后登录时间失败", "user_id", user.ID, "error", err)
 	}
 
 	return user, accessToken, refreshToken, nil
@@ -199,6 +200,11 @@ func (s *AuthService) ValidateToken(ctx context.Context, token string) (*models.
 		return nil, ErrUserNotFound
 	}
 
+	// 检查账户状态，禁用后已签发的token随即失效
+	if user.Status != "active" {
+		return nil, errors.New("账户已被禁用")
+	}
+
 	return user, nil
 }
 
@@ -0,0 +1,125 @@
+// Package annotation 章节批注的锚点
+// 批注按字符区间锚定在正文上，同时记录区间内的原文和所在段落的哈希。
+// 正文改动后依次按原位置、原文和段落哈希重新定位；都对不上时批注成为孤立批注，仍保留讨论内容。
+package annotation
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// Paragraph 正文中的一个段落（按字符计，不含 end）
+type Paragraph struct {
+	Index int    `json:"index"`
+	Start int    `json:"start"`
+	End   int    `json:"end"`
+	Hash  string `json:"hash"`
+}
+
+// Anchor 批注在当前正文中的位置
+type Anchor struct {
+	Start  int    `json:"start"`
+	End    int    `json:"end"`
+	Status string `json:"status"` // exact/moved/orphaned
+}
+
+// ParagraphHash 段落哈希：段落去掉首尾空白后的 sha1 前 12 位
+func ParagraphHash(text string) string {
+	sum := sha1.Sum([]byte(strings.TrimSpace(text)))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+// Paragraphs 按换行切分正文，跳过空行；区间不含段首段尾的空白
+func Paragraphs(content string) []Paragraph {
+	var paragraphs []Paragraph
+	offset := 0
+	for _, line := range strings.Split(content, "\n") {
+		n := utf8.RuneCountInString(line)
+		if trimmed := strings.TrimSpace(line); trimmed != "" {
+			lead := utf8.RuneCountInString(line[:strings.Index(line, trimmed)])
+			paragraphs = append(paragraphs, Paragraph{
+				Index: len(paragraphs),
+				Start: offset + lead,
+				End:   offset + lead + utf8.RuneCountInString(trimmed),
+				Hash:  ParagraphHash(trimmed),
+			})
+		}
+		offset += n + 1
+	}
+	return paragraphs
+}
+
+// ParagraphAt 返回包含第 pos 个字符的段落，pos 落在段落之间时返回其后的第一个段落
+func ParagraphAt(paragraphs []Paragraph, pos int) (Paragraph, bool) {
+	for _, p := range paragraphs {
+		if pos < p.End {
+			return p, true
+		}
+	}
+	return Paragraph{}, false
+}
+
+// FindParagraph 按哈希查找段落
+func FindParagraph(paragraphs []Paragraph, hash string) (Paragraph, bool) {
+	for _, p := range paragraphs {
+		if p.Hash == hash {
+			return p, true
+		}
+	}
+	return Paragraph{}, false
+}
+
+// Locate 在当前正文中定位批注：
+// 原位置的文本仍与原文一致时为 exact；否则在正文中查找原文，出现多次时优先取哈希匹配的段落内的一处，
+// 再取离原位置最近的一处；原文已找不到时退回到哈希匹配的整个段落；都失败为 orphaned
+func Locate(content string, a *models.Annotation) Anchor {
+	runes := []rune(content)
+	if a.Quote != "" && a.Start >= 0 && a.End <= len(runes) && a.Start < a.End && string(runes[a.Start:a.End]) == a.Quote {
+		return Anchor{Start: a.Start, End: a.End, Status: models.AnchorExact}
+	}
+
+	paragraphs := Paragraphs(content)
+	para, hashFound := Paragraph{}, false
+	if a.ParagraphHash != "" {
+		para, hashFound = FindParagraph(paragraphs, a.ParagraphHash)
+	}
+
+	if a.Quote != "" {
+		n := utf8.RuneCountInString(a.Quote)
+		best, bestDist := -1, 0
+		for offset := 0; ; {
+			idx := strings.Index(content[offset:], a.Quote)
+			if idx < 0 {
+				break
+			}
+			pos := utf8.RuneCountInString(content[:offset+idx])
+			dist := abs(pos - a.Start)
+			if hashFound && pos >= para.Start && pos+n <= para.End {
+				dist = -1 // 在原段落内，优先
+			}
+			if best < 0 || dist < bestDist {
+				best, bestDist = pos, dist
+			}
+			offset += idx + len(a.Quote)
+		}
+		if best >= 0 {
+			return Anchor{Start: best, End: best + n, Status: models.AnchorMoved}
+		}
+	}
+
+	if hashFound {
+		return Anchor{Start: para.Start, End: para.End, Status: models.AnchorMoved}
+	}
+	return Anchor{Status: models.AnchorOrphaned}
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
@@ -0,0 +1,99 @@
+// Package audit 内容变更审计 - 记录谁在何时改了什么
+//
+// 世界设定修改、章节覆盖与重新生成、模板修改和发布都会追加一条记录，附带变更前后的摘要。
+// 记录只追加，写入失败只打日志，不影响变更本身
+package audit
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/logx"
+)
+
+// 摘要长度上限（字）
+const (
+	maxSummary = 500
+	maxExcerpt = 80
+)
+
+// Record 追加一条变更记录
+func Record(database db.Database, entry *models.AuditLog) {
+	if entry.ID == "" {
+		entry.ID = db.GenerateID("audit")
+	}
+	entry.Summary = truncate(entry.Summary, maxSummary)
+	if err := database.AppendAuditLog(entry); err != nil {
+		logx.L().Warn("写入审计记录失败", "action", entry.Action, "resource", entry.ResourceType, "id", entry.ResourceID, "error", err)
+	}
+}
+
+// Summarize 生成对象的变更摘要：章节记录字数、内容指纹和开头，便于确认被覆盖的是哪一版；
+// 其他对象记录关键字段，未知类型截断JSON。nil 返回空串
+func Summarize(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case *models.Chapter:
+		if x == nil {
+			return ""
+		}
+		return fmt.Sprintf("第%d章「%s」 状态 %s，版本 %d，%d 字，指纹 %s，开头：%s",
+			x.ChapterNum, x.Title, x.Status, x.Version, x.WordCount, Fingerprint(x.Content), excerpt(x.Content))
+	case *models.WorldSetting:
+		if x == nil {
+			return ""
+		}
+		return fmt.Sprintf("世界「%s」 类型 %s，规模 %s，档位 %s，%d 个地区，%d 个种族，%d 个宗教，更新于 %s",
+			x.Name, x.Type, x.Scale, x.BuildTier, len(x.Geography.Regions), len(x.Civilization.Races),
+			len(x.Civilization.Religions), x.UpdatedAt.Format("2006-01-02 15:04:05"))
+	case *models.PromptTemplate:
+		if x == nil {
+			return ""
+		}
+		return fmt.Sprintf("提示词 %s 版本 %d，%d 字，指纹 %s",
+			x.Key, x.Version, utf8.RuneCountInString(x.Content), Fingerprint(x.Content))
+	case *models.NarrativeTemplate:
+		if x == nil {
+			return ""
+		}
+		return fmt.Sprintf("结构 %s「%s」 版本 %d，指纹 %s",
+			x.ID, x.Name, x.Version, Fingerprint(string(x.Structure)+string(x.PromptRules)))
+	case string:
+		return truncate(x, maxSummary)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%T", v)
+	}
+	if string(data) == "null" {
+		return ""
+	}
+	return truncate(string(data), maxSummary)
+}
+
+// Fingerprint 内容的短指纹（sha256 前12位），空内容返回 "-"
+func Fingerprint(content string) string {
+	if content == "" {
+		return "-"
+	}
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])[:12]
+}
+
+func excerpt(content string) string {
+	return truncate(strings.Join(strings.Fields(content), " "), maxExcerpt)
+}
+
+func truncate(s string, n int) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "…"
+}
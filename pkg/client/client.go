@@ -0,0 +1,26 @@
+// Package client Xupu REST API 的 Go 客户端
+//
+// client.gen.go 由 oapi-codegen 按 internal/openapi/openapi.json 生成（make openapi），不要手动修改；
+// 方法名由请求方法和路径组成，如 GET /api/v1/projects/{id} 对应 GetApiV1ProjectsIdWithResponse。
+package client
+
+import (
+	"context"
+	"net/http"
+)
+
+// WithToken 为每个请求附加认证头，token 为登录返回的访问令牌或 API Key
+func WithToken(token string) ClientOption {
+	return WithRequestEditorFn(func(ctx context.Context, req *http.Request) error {
+		req.Header.Set("Authorization", "Bearer "+token)
+		return nil
+	})
+}
+
+// New 创建带响应解析的客户端，baseURL 为服务地址，如 http://localhost:8080
+func New(baseURL, token string, opts ...ClientOption) (*ClientWithResponses, error) {
+	if token != "" {
+		opts = append([]ClientOption{WithToken(token)}, opts...)
+	}
+	return NewClientWithResponses(baseURL, opts...)
+}
@@ -0,0 +1,137 @@
+// Package compliance 内容合规 - 按策略集检查正文中的违规词语和话题，按章节、段落报告
+package compliance
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+)
+
+// excerptRunes 违规处前后各取的字数
+const excerptRunes = 20
+
+// rule 编译后的规则
+type rule struct {
+	category string
+	severity string
+	terms    []string
+	patterns []*regexp.Regexp
+}
+
+// Scanner 按策略集检查文本
+type Scanner struct {
+	Policy string
+	rules  []rule
+}
+
+// New 编译策略集，正则无效时返回错误
+func New(id string, policy *config.CompliancePolicy) (*Scanner, error) {
+	s := &Scanner{Policy: id, rules: make([]rule, 0, len(policy.Rules))}
+	for _, r := range policy.Rules {
+		compiled := rule{category: r.Category, severity: r.Severity, terms: r.Terms}
+		if compiled.severity != models.ComplianceWarn {
+			compiled.severity = models.ComplianceBlock
+		}
+		for _, p := range r.Patterns {
+			re, err := regexp.Compile(p)
+			if err != nil {
+				return nil, fmt.Errorf("策略集%s的规则%s正则无效: %w", id, r.Category, err)
+			}
+			compiled.patterns = append(compiled.patterns, re)
+		}
+		s.rules = append(s.rules, compiled)
+	}
+	return s, nil
+}
+
+// Load 从配置中加载策略集，id 为空时使用默认策略集
+func Load(cfg config.ComplianceConfig, id string) (*Scanner, error) {
+	if id == "" {
+		id = cfg.DefaultPolicy
+	}
+	policy, ok := cfg.Policy(id)
+	if !ok {
+		return nil, fmt.Errorf("合规策略集不存在: %s", id)
+	}
+	return New(id, policy)
+}
+
+// Scan 检查章节正文，每个段落的每个词语只报告一次
+func (s *Scanner) Scan(chapter int, text string) []models.ComplianceViolation {
+	violations := make([]models.ComplianceViolation, 0)
+	for i, p := range Paragraphs(text) {
+		for _, r := range s.rules {
+			for _, term := range r.terms {
+				if term == "" {
+					continue
+				}
+				if idx := strings.Index(p, term); idx >= 0 {
+					violations = append(violations, violation(chapter, i+1, r, term, p, idx))
+				}
+			}
+			for _, re := range r.patterns {
+				if loc := re.FindStringIndex(p); loc != nil {
+					violations = append(violations, violation(chapter, i+1, r, p[loc[0]:loc[1]], p, loc[0]))
+				}
+			}
+		}
+	}
+	return violations
+}
+
+// Paragraphs 正文的非空段落
+func Paragraphs(text string) []string {
+	paragraphs := make([]string, 0)
+	for _, line := range strings.Split(text, "\n") {
+		if p := strings.TrimSpace(line); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	return paragraphs
+}
+
+// ReplaceParagraphs 按段落序号（从1开始，与 Paragraphs 一致）替换正文中的段落，保留其余排版
+func ReplaceParagraphs(text string, rewrites map[int]string) string {
+	lines := strings.Split(text, "\n")
+	n := 0
+	for i, line := range lines {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		n++
+		if rewrite, ok := rewrites[n]; ok {
+			indent := line[:len(line)-len(strings.TrimLeft(line, " \t　"))]
+			lines[i] = indent + strings.TrimSpace(rewrite)
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Blocking 违规中是否有必须处理的
+func Blocking(violations []models.ComplianceViolation) bool {
+	for _, v := range violations {
+		if v.Severity == models.ComplianceBlock {
+			return true
+		}
+	}
+	return false
+}
+
+// violation 构建违规记录，摘录命中位置前后的文字
+func violation(chapter, paragraph int, r rule, term, text string, byteIdx int) models.ComplianceViolation {
+	runes := []rune(text)
+	start := len([]rune(text[:byteIdx]))
+	end := min(len(runes), start+len([]rune(term))+excerptRunes)
+	start = max(0, start-excerptRunes)
+	return models.ComplianceViolation{
+		Chapter:   chapter,
+		Paragraph: paragraph,
+		Category:  r.category,
+		Severity:  r.severity,
+		Term:      term,
+		Excerpt:   string(runes[start:end]),
+	}
+}
@@ -7,8 +7,6 @@ import (
 	"fmt"
 	"os"
 	"strings"
-
-	"gopkg.in/yaml.v3"
 )
 
 // Config 全局配置结构
@@ -16,6 +14,13 @@ type Config struct {
 	LLM     LLMConfig            `yaml:"llm"`
 	Prompts PromptsConfig        `yaml:"prompts"`
 	System  SystemConfig         `yaml:"system"`
+	Pacing  PacingConfig         `yaml:"pacing"`
+	Degradation DegradationConfig `yaml:"degradation"`
+	Compliance  ComplianceConfig  `yaml:"compliance"`
+	TTS         TTSConfig         `yaml:"tts"`
+	Notify      NotifyConfig      `yaml:"notify"`
+	ChapterWriter ChapterWriterConfig `yaml:"chapter_writer"`
+	Originality OriginalityConfig `yaml:"originality"`
 }
 
 // LLMConfig LLM相关配置
@@ -23,6 +28,24 @@ type LLMConfig struct {
 	DefaultProvider string                   `yaml:"default_provider"`
 	Providers       map[string]ProviderConfig `yaml:"providers"`
 	ModuleMapping   map[string]ModuleMapping `yaml:"module_mapping"`
+	MaxConcurrent   int                      `yaml:"max_concurrent"` // 全进程同时进行的LLM请求数上限，0表示不限制
+	CheapMode       bool                     `yaml:"cheap_mode"`     // 省钱模式：校验、摘要等非关键环节改用 CheapModel
+	CheapModel      ModelRef                 `yaml:"cheap_model"`    // 省钱模式使用的便宜模型
+	Examples        ExamplesConfig           `yaml:"examples"`       // 少样本示例注入
+}
+
+// 少样本示例注入模式
+const (
+	ExamplesOff = "off" // 不注入
+	ExamplesOn  = "on"  // 有可用示例时总是注入
+	ExamplesAB  = "ab"  // A/B 对照：按 Holdout 比例随机留出对照组，统计两组的校验通过率和耗时
+)
+
+// ExamplesConfig 少样本示例注入配置，示例本身由管理后台维护在数据库中
+type ExamplesConfig struct {
+	Mode    string  `yaml:"mode"`    // off/on/ab，为空等同 off
+	Max     int     `yaml:"max"`     // 每个提示词最多注入的示例数（1-3），0 使用默认值 2
+	Holdout float64 `yaml:"holdout"` // ab 模式下不注入示例的调用比例（0-1），0 使用默认值 0.5
 }
 
 // ProviderConfig LLM提供商配置
@@ -31,6 +54,36 @@ type ProviderConfig struct {
 	APIKey    string            `yaml:"api_key"`
 	APIKeyEnv string            `yaml:"api_key_env"`
 	Models    ModelsConfig      `yaml:"models"`
+	RateLimit RateLimitConfig   `yaml:"rate_limit"`
+}
+
+// RateLimitConfig 提供商限流配置，RPM/TPM 为0表示不限制
+type RateLimitConfig struct {
+	RPM        int                       `yaml:"rpm"`         // 每分钟请求数上限
+	TPM        int                       `yaml:"tpm"`         // 每分钟token数上限
+	MaxRetries int                       `yaml:"max_retries"` // 遇到429时的最大重试次数，0使用默认值
+	MaxBackoff int                       `yaml:"max_backoff"` // 最大退避秒数，0使用默认值
+	Models     map[string]ModelRateLimit `yaml:"models"`      // 按模型覆盖的上限
+}
+
+// ModelRateLimit 单个模型的限流上限
+type ModelRateLimit struct {
+	RPM int `yaml:"rpm"`
+	TPM int `yaml:"tpm"`
+}
+
+// ForModel 获取指定模型的 RPM/TPM 上限，模型未单独配置的项沿用提供商的值
+func (r RateLimitConfig) ForModel(model string) (rpm, tpm int) {
+	rpm, tpm = r.RPM, r.TPM
+	if m, ok := r.Models[model]; ok {
+		if m.RPM > 0 {
+			rpm = m.RPM
+		}
+		if m.TPM > 0 {
+			tpm = m.TPM
+		}
+	}
+	return rpm, tpm
 }
 
 // ModelsConfig 模型配置
@@ -43,16 +96,28 @@ type ModelsConfig struct {
 type ModelInfo struct {
 	Name            string  `yaml:"name"`
 	MaxTokens       int     `yaml:"max_tokens"`
+	ContextWindow   int     `yaml:"context_window"` // 上下文窗口（输入+输出）token数，为0时取 max_tokens
 	CostPer1kInput  float64 `yaml:"cost_per_1k_input"`
 	CostPer1kOutput float64 `yaml:"cost_per_1k_output"`
 }
 
 // ModuleMapping 模块与模型的映射
 type ModuleMapping struct {
-	Provider    string  `yaml:"provider"`
-	Model       string  `yaml:"model"`
-	Temperature float64 `yaml:"temperature"`
-	MaxTokens   int     `yaml:"max_tokens"`
+	Provider    string     `yaml:"provider"`
+	Model       string     `yaml:"model"`
+	Temperature float64    `yaml:"temperature"`
+	MaxTokens   int        `yaml:"max_tokens"`
+	Fallbacks   []ModelRef `yaml:"fallbacks"` // 后备模型，主模型因提供商错误或超时失败时依次改用
+}
+
+// ModelRef 指向某个提供商的模型
+type ModelRef struct {
+	Provider string `yaml:"provider"`
+	Model    string `yaml:"model"`
+}
+
+func (r ModelRef) String() string {
+	return r.Provider + "/" + r.Model
 }
 
 // PromptsConfig 提示词配置
@@ -92,6 +157,9 @@ type WriterPrompts struct {
 	GenerateAction         string `yaml:"generate_action"`
 	GenerateEnvironment    string `yaml:"generate_environment"`
 	GenerateInternalMonologue string `yaml:"generate_internal_monologue"`
+	ChapterDraft           string `yaml:"chapter_draft"`  // 章节流水线：逐场景初稿
+	ChapterRevise          string `yaml:"chapter_revise"` // 章节流水线：全章连贯性修订
+	ChapterPolish          string `yaml:"chapter_polish"` // 章节流水线：逐句润色
 }
 
 // CharacterPrompts 角色提示词
@@ -121,6 +189,10 @@ type LoggingConfig struct {
 	File       string `yaml:"file"`
 	MaxSize    int    `yaml:"max_size"`
 	MaxBackups int    `yaml:"max_backups"`
+
+	Format        string `yaml:"format"`         // text 或 json
+	RedactPrompts bool   `yaml:"redact_prompts"` // 日志中隐去提示词和LLM响应正文，只记录长度
+	TraceBuffer   int    `yaml:"trace_buffer"`   // 每个请求/任务在内存中保留的日志条数，0使用默认值
 }
 
 // RetryConfig 重试配置
@@ -136,51 +208,198 @@ type TimeoutConfig struct {
 	ChapterGeneration  int `yaml:"chapter_generation"`
 }
 
-var (
-	globalConfig *Config
+// 章节写作流水线的阶段，按此顺序执行
+const (
+	ChapterStageDraft  = "draft"  // 按场景写初稿
+	ChapterStageRevise = "revise" // 通读全章做连贯性修订
+	ChapterStagePolish = "polish" // 逐句润色
 )
 
-// Load 加载配置文件
-func Load(path string) (*Config, error) {
-	data, err := os.ReadFile(path)
-	if err != nil {
-		return nil, fmt.Errorf("读取配置文件失败: %w", err)
-	}
+// ChapterStages 全部阶段，按执行顺序
+var ChapterStages = []string{ChapterStageDraft, ChapterStageRevise, ChapterStagePolish}
 
-	cfg := &Config{}
-	if err := yaml.Unmarshal(data, cfg); err != nil {
-		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+// ChapterWriterConfig 章节写作流水线配置
+// 每个阶段使用 module_mapping 中的 writer_<阶段> 模块（如 writer_polish），未配置时使用 writer_scene
+type ChapterWriterConfig struct {
+	Stages []string `yaml:"stages"` // 启用的阶段，为空时全部启用；draft 不可省略
+}
+
+// Enabled 阶段是否启用
+func (c ChapterWriterConfig) Enabled(stage string) bool {
+	if len(c.Stages) == 0 {
+		return true
+	}
+	for _, s := range c.Stages {
+		if s == stage {
+			return true
+		}
 	}
+	return false
+}
+
+// ChapterStageModule 阶段对应的模块名
+func ChapterStageModule(stage string) string {
+	return "writer_" + stage
+}
+
+// OriginalityConfig 原创性检查配置：正文与项目参考文本比对，相似度超过阈值时禁止导出
+type OriginalityConfig struct {
+	NGram     int     `yaml:"ngram"`     // 判定为雷同的最短连续相同字数，0 使用默认值 12
+	Threshold float64 `yaml:"threshold"` // 默认阈值：单章雷同字数占比（0-1），项目可单独设置，0 使用默认值 0.1
+}
+
+// PacingConfig 节奏分析配置
+type PacingConfig struct {
+	DeviationThreshold   float64              `yaml:"deviation_threshold"`    // 判定为偏离的张力差值
+	MinAggregateProjects int                  `yaml:"min_aggregate_projects"` // 聚合基线所需的最少项目数
+	Baselines            map[string][]float64 `yaml:"baselines"`              // 类型 -> 归一化张力曲线（0-1）
+}
 
-	// 设置为全局配置
-	globalConfig = cfg
+// DegradationPolicy LLM失败或输出不可用时的降级策略
+type DegradationPolicy string
 
-	return cfg, nil
+const (
+	DegradeFailFast        DegradationPolicy = "fail_fast"             // 直接失败，不使用兜底内容
+	DegradeFallbackWarning DegradationPolicy = "fallback_with_warning" // 使用兜底内容并记录警告
+	DegradeFallbackSilent  DegradationPolicy = "fallback_silent"       // 使用兜底内容，不记录警告
+)
+
+// Valid 是否为已知策略
+func (p DegradationPolicy) Valid() bool {
+	switch p {
+	case DegradeFailFast, DegradeFallbackWarning, DegradeFallbackSilent:
+		return true
+	}
+	return false
+}
+
+// DegradationConfig 降级策略配置
+type DegradationConfig struct {
+	Default DegradationPolicy            `yaml:"default"` // 未单独配置的模块使用的策略，为空时为 fallback_with_warning
+	Modules map[string]DegradationPolicy `yaml:"modules"` // 模块名（同 module_mapping）-> 策略
 }
 
-// LoadDefault 加载默认配置文件
-func LoadDefault() (*Config, error) {
-	configPaths := []string{
-		"config/config.yaml",
-		"./config.yaml",
-		"/etc/xupu/config.yaml",
+// For 获取指定模块的降级策略
+func (d DegradationConfig) For(module string) DegradationPolicy {
+	if p, ok := d.Modules[module]; ok && p.Valid() {
+		return p
 	}
+	if d.Default.Valid() {
+		return d.Default
+	}
+	return DegradeFallbackWarning
+}
 
-	for _, path := range configPaths {
-		if cfg, err := Load(path); err == nil {
-			return cfg, nil
+// ComplianceConfig 内容合规配置
+type ComplianceConfig struct {
+	DefaultPolicy string                      `yaml:"default_policy"` // 项目未指定策略集时使用的策略集
+	Policies      map[string]CompliancePolicy `yaml:"policies"`       // 策略集ID -> 策略集
+}
+
+// CompliancePolicy 合规策略集
+type CompliancePolicy struct {
+	Name        string           `yaml:"name" json:"name"`
+	Description string           `yaml:"description" json:"description"`
+	Extends     string           `yaml:"extends" json:"extends,omitempty"` // 继承另一个策略集的全部规则
+	Rules       []ComplianceRule `yaml:"rules" json:"rules"`
+}
+
+// ComplianceRule 合规规则：命中任一词语或正则即视为违规
+type ComplianceRule struct {
+	Category string   `yaml:"category" json:"category"` // 违规类别，如 色情、血腥暴力、赌博毒品
+	Severity string   `yaml:"severity" json:"severity"` // block（必须处理）/ warn（提示）
+	Terms    []string `yaml:"terms" json:"terms"`
+	Patterns []string `yaml:"patterns" json:"patterns,omitempty"` // 正则表达式
+}
+
+// Policy 获取策略集（合并继承的规则），id 为空时使用默认策略集
+func (c ComplianceConfig) Policy(id string) (*CompliancePolicy, bool) {
+	if id == "" {
+		id = c.DefaultPolicy
+	}
+	policy, ok := c.Policies[id]
+	if !ok {
+		return nil, false
+	}
+	merged := policy
+	seen := map[string]bool{id: true}
+	for parent := policy.Extends; parent != "" && !seen[parent]; {
+		seen[parent] = true
+		p, ok := c.Policies[parent]
+		if !ok {
+			break
 		}
+		merged.Rules = append(append([]ComplianceRule{}, p.Rules...), merged.Rules...)
+		parent = p.Extends
 	}
+	return &merged, true
+}
+
+// TTSConfig 语音合成配置（有声书导出）
+type TTSConfig struct {
+	Provider     string            `yaml:"provider"`      // azure / edge / local
+	SegmentRunes int               `yaml:"segment_runes"` // 每次合成的最多字数，0使用默认值
+	Narrator     string            `yaml:"narrator"`      // 旁白音色，没有视角角色的段落使用
+	MaleVoices   []string          `yaml:"male_voices"`   // 男性视角角色轮流分配的音色
+	FemaleVoices []string          `yaml:"female_voices"` // 女性视角角色轮流分配的音色
+	Voices       map[string]string `yaml:"voices"`        // 角色名 -> 指定音色，优先于轮流分配
+	Azure        AzureTTSConfig    `yaml:"azure"`
+	Edge         EdgeTTSConfig     `yaml:"edge"`
+	Local        LocalTTSConfig    `yaml:"local"`
+}
+
+// AzureTTSConfig Azure 语音服务配置
+type AzureTTSConfig struct {
+	Region    string `yaml:"region"`
+	APIKey    string `yaml:"api_key"`
+	APIKeyEnv string `yaml:"api_key_env"`
+	Format    string `yaml:"format"` // X-Microsoft-OutputFormat，默认 audio-24khz-48kbitrate-mono-mp3
+}
+
+// GetAPIKey 获取 Azure 语音服务的 Key（优先配置文件，其次环境变量）
+func (c AzureTTSConfig) GetAPIKey() (string, error) {
+	p := ProviderConfig{APIKey: c.APIKey, APIKeyEnv: c.APIKeyEnv}
+	return p.GetAPIKey()
+}
+
+// EdgeTTSConfig edge-tts 命令行配置
+type EdgeTTSConfig struct {
+	Command string `yaml:"command"` // 默认 edge-tts
+	Rate    string `yaml:"rate"`    // 语速，如 +10%
+}
 
-	return nil, fmt.Errorf("未找到配置文件，尝试的路径: %v", configPaths)
+// LocalTTSConfig 本地语音合成命令配置
+// 参数中的 {voice}、{output} 会被替换，待合成文本从标准输入传入，命令需输出 MP3 文件
+type LocalTTSConfig struct {
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args"`
 }
 
-// Get 获取全局配置
-func Get() *Config {
-	if globalConfig == nil {
-		panic("配置未初始化，请先调用 Load() 或 LoadDefault()")
+// NotifyConfig 通知渠道的服务端配置；用户在自己的渠道里只填收件人、机器人地址等
+type NotifyConfig struct {
+	SMTP        SMTPConfig `yaml:"smtp"`
+	TelegramAPI string     `yaml:"telegram_api"` // Telegram Bot API 地址，默认 https://api.telegram.org，可换成反向代理
+}
+
+// SMTPConfig 邮件通知使用的 SMTP 服务器
+type SMTPConfig struct {
+	Host        string `yaml:"host"`
+	Port        int    `yaml:"port"` // 默认 587（STARTTLS）；465 使用隐式 TLS
+	Username    string `yaml:"username"`
+	Password    string `yaml:"password"`
+	PasswordEnv string `yaml:"password_env"`
+	From        string `yaml:"from"`
+}
+
+// GetPassword 获取 SMTP 密码（优先配置文件，其次环境变量）
+func (c SMTPConfig) GetPassword() string {
+	if c.Password != "" {
+		return c.Password
+	}
+	if c.PasswordEnv != "" {
+		return os.Getenv(c.PasswordEnv)
 	}
-	return globalConfig
+	return ""
 }
 
 // GetAPIKey 获取API Key（优先从配置文件读取，失败则从环境变量读取）
@@ -201,6 +420,27 @@ func (c *ProviderConfig) GetAPIKey() (string, error) {
 	return apiKey, nil
 }
 
+// DefaultContextWindow 模型未配置上下文窗口时假定的大小
+const DefaultContextWindow = 32000
+
+// ContextWindow 模型的上下文窗口大小：依次取 context_window、max_tokens，都未配置时为 DefaultContextWindow
+func (c *LLMConfig) ContextWindow(provider, model string) int {
+	if p, ok := c.Providers[provider]; ok {
+		for _, m := range p.Models.Available {
+			if m.Name != model {
+				continue
+			}
+			if m.ContextWindow > 0 {
+				return m.ContextWindow
+			}
+			if m.MaxTokens > 0 {
+				return m.MaxTokens
+			}
+		}
+	}
+	return DefaultContextWindow
+}
+
 // GetModuleConfig 获取模块的LLM配置
 func (c *LLMConfig) GetModuleConfig(moduleName string) (*ModuleMapping, *ProviderConfig, error) {
 	mapping, ok := c.ModuleMapping[moduleName]
@@ -344,6 +584,29 @@ func (c *Config) GetWriterInternalMonologue(data map[string]interface{}) (string
 	return RenderPrompt(c.Prompts.Writer.GenerateInternalMonologue, data)
 }
 
+// GetWriterChapterDraft 获取章节流水线初稿提示词
+func (c *Config) GetWriterChapterDraft(data map[string]interface{}) (string, error) {
+	return renderRequired("prompts.writer.chapter_draft", c.Prompts.Writer.ChapterDraft, data)
+}
+
+// GetWriterChapterRevise 获取章节流水线修订提示词
+func (c *Config) GetWriterChapterRevise(data map[string]interface{}) (string, error) {
+	return renderRequired("prompts.writer.chapter_revise", c.Prompts.Writer.ChapterRevise, data)
+}
+
+// GetWriterChapterPolish 获取章节流水线润色提示词
+func (c *Config) GetWriterChapterPolish(data map[string]interface{}) (string, error) {
+	return renderRequired("prompts.writer.chapter_polish", c.Prompts.Writer.ChapterPolish, data)
+}
+
+// renderRequired 渲染必须配置的提示词模板，未配置时返回错误
+func renderRequired(path, template string, data map[string]interface{}) (string, error) {
+	if strings.TrimSpace(template) == "" {
+		return "", fmt.Errorf("未配置提示词 %s", path)
+	}
+	return RenderPrompt(template, data)
+}
+
 // GetCharacterSystem 获取角色系统提示词
 func (c *Config) GetCharacterSystem() string {
 	return c.Prompts.Character.System
@@ -383,3 +646,45 @@ func (c *Config) GetAllPrompts() map[string]string {
 		"character.generate_profile": c.Prompts.Character.GenerateProfile,
 	}
 }
+
+// promptFields 提示词键到配置字段的映射，键与 GetAllPrompts 一致，另含章节流水线提示词
+func (c *Config) promptFields() map[string]*string {
+	return map[string]*string{
+		"world_builder.system":              &c.Prompts.WorldBuilder.System,
+		"world_builder.stage1_philosophy":   &c.Prompts.WorldBuilder.Stage1Philosophy,
+		"world_builder.stage2_worldview":    &c.Prompts.WorldBuilder.Stage2Worldview,
+		"world_builder.stage3_laws":         &c.Prompts.WorldBuilder.Stage3Laws,
+		"world_builder.stage4_story_soil":   &c.Prompts.WorldBuilder.Stage4StorySoil,
+		"world_builder.stage5_geography":    &c.Prompts.WorldBuilder.Stage5Geography,
+		"world_builder.stage6_civilization": &c.Prompts.WorldBuilder.Stage6Civilization,
+		"world_builder.stage7_consistency":  &c.Prompts.WorldBuilder.Stage7Consistency,
+
+		"narrative_engine.system":                 &c.Prompts.NarrativeEngine.System,
+		"narrative_engine.generate_outline":       &c.Prompts.NarrativeEngine.GenerateOutline,
+		"narrative_engine.generate_chapter_plans": &c.Prompts.NarrativeEngine.GenerateChapterPlans,
+		"narrative_engine.generate_scenes":        &c.Prompts.NarrativeEngine.GenerateScenes,
+		"narrative_engine.plan_character_arc":     &c.Prompts.NarrativeEngine.PlanCharacterArc,
+
+		"writer.system":                      &c.Prompts.Writer.System,
+		"writer.generate_dialogue":           &c.Prompts.Writer.GenerateDialogue,
+		"writer.generate_scene":              &c.Prompts.Writer.GenerateScene,
+		"writer.generate_action":             &c.Prompts.Writer.GenerateAction,
+		"writer.generate_environment":        &c.Prompts.Writer.GenerateEnvironment,
+		"writer.generate_internal_monologue": &c.Prompts.Writer.GenerateInternalMonologue,
+		"writer.chapter_draft":               &c.Prompts.Writer.ChapterDraft,
+		"writer.chapter_revise":              &c.Prompts.Writer.ChapterRevise,
+		"writer.chapter_polish":              &c.Prompts.Writer.ChapterPolish,
+
+		"character.system":           &c.Prompts.Character.System,
+		"character.generate_profile": &c.Prompts.Character.GenerateProfile,
+	}
+}
+
+// GetPrompt 按键获取提示词，键不存在时返回 false
+func (c *Config) GetPrompt(key string) (string, bool) {
+	field, ok := c.promptFields()[key]
+	if !ok {
+		return "", false
+	}
+	return *field, true
+}
@@ -0,0 +1,386 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ============================================
+// 配置来源与热加载
+//
+// 配置按以下顺序合并，后者覆盖前者：
+//  1. 基础配置文件：XUPU_CONFIG 指定的路径，未指定时依次查找 DefaultPaths
+//  2. 环境配置文件：设置 XUPU_ENV 时合并基础文件同目录下的 config.<环境>.yaml（不存在则跳过）
+//  3. 环境变量：XUPU__ 开头，双下划线分隔 YAML 路径，如 XUPU__LLM__MODULE_MAPPING__WRITER_SCENE__MODEL=glm-4-flash
+//
+// 合并后的配置经 Validate 校验才会生效。运行中可通过 Reload 重新读取，
+// 只替换 LLM 配置（提供商、模块映射、限流）和重试配置，其余配置需重启生效；
+// 本地调试提示词时可通过 ApplyPrompts 单独替换提示词
+// ============================================
+
+// 配置相关的环境变量
+const (
+	EnvConfigPath = "XUPU_CONFIG" // 基础配置文件路径
+	EnvName       = "XUPU_ENV"    // 运行环境，如 dev、staging、prod
+	EnvOverride   = "XUPU__"      // 覆盖单个配置项的环境变量前缀
+)
+
+// DefaultPaths 未指定 XUPU_CONFIG 时依次查找的配置文件
+var DefaultPaths = []string{
+	"config/config.yaml",
+	"./config.yaml",
+	"/etc/xupu/config.yaml",
+}
+
+var (
+	globalConfig atomic.Pointer[Config]
+	loadedPath   atomic.Value // string，最近一次加载的基础配置文件
+	generation   atomic.Uint64
+
+	reloadMu    sync.Mutex
+	reloadHooks []func(*Config)
+)
+
+// Environment 当前运行环境（XUPU_ENV），未设置时为空
+func Environment() string {
+	return strings.TrimSpace(os.Getenv(EnvName))
+}
+
+// Path 基础配置文件路径：XUPU_CONFIG 优先，否则为 DefaultPaths 中第一个存在的文件
+func Path() (string, error) {
+	if p := os.Getenv(EnvConfigPath); p != "" {
+		return p, nil
+	}
+	for _, p := range DefaultPaths {
+		if _, err := os.Stat(p); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("未找到配置文件，尝试的路径: %v（可通过 %s 指定）", DefaultPaths, EnvConfigPath)
+}
+
+// Load 加载配置文件，合并环境配置文件和环境变量覆盖，校验通过后设为全局配置
+func Load(path string) (*Config, error) {
+	cfg, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	// 设置为全局配置
+	globalConfig.Store(cfg)
+	loadedPath.Store(path)
+	return cfg, nil
+}
+
+// LoadDefault 从默认位置加载配置文件
+func LoadDefault() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return Load(path)
+}
+
+// Current 获取全局配置，尚未加载时从默认位置加载
+// 各模块的构造函数应使用它，而不是各自读取配置文件
+func Current() (*Config, error) {
+	if cfg := globalConfig.Load(); cfg != nil {
+		return cfg, nil
+	}
+	return LoadDefault()
+}
+
+// Get 获取全局配置
+func Get() *Config {
+	cfg := globalConfig.Load()
+	if cfg == nil {
+		panic("配置未初始化，请先调用 Load() 或 LoadDefault()")
+	}
+	return cfg
+}
+
+// Generation 配置的热加载次数，缓存了配置派生数据的组件据此判断是否需要刷新
+func Generation() uint64 {
+	return generation.Load()
+}
+
+// OnReload 注册热加载成功后的回调，参数为生效的新配置
+func OnReload(fn func(*Config)) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+	reloadHooks = append(reloadHooks, fn)
+}
+
+// ReloadResult 热加载结果
+type ReloadResult struct {
+	Path        string   `json:"path"`
+	Environment string   `json:"environment,omitempty"`
+	Generation  uint64   `json:"generation"`
+	Changes     []string `json:"changes"` // 生效的变更，为空表示与当前配置一致
+}
+
+// Reload 重新读取配置并热替换 LLM 配置和重试配置
+// 新配置校验失败时保持当前配置不变并返回错误
+func Reload() (*ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	cur := globalConfig.Load()
+	if cur == nil {
+		return nil, errors.New("配置未初始化，无法热加载")
+	}
+	path, _ := loadedPath.Load().(string)
+	if path == "" {
+		var err error
+		if path, err = Path(); err != nil {
+			return nil, err
+		}
+	}
+
+	next, err := read(path)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := diffLLM(cur.LLM, next.LLM)
+	if cur.System.Retry != next.System.Retry {
+		changes = append(changes, fmt.Sprintf("system.retry: %+v → %+v", cur.System.Retry, next.System.Retry))
+	}
+
+	merged := *cur
+	merged.LLM = next.LLM
+	merged.System.Retry = next.System.Retry
+	globalConfig.Store(&merged)
+	gen := generation.Add(1)
+
+	for _, fn := range reloadHooks {
+		fn(&merged)
+	}
+
+	return &ReloadResult{
+		Path:        path,
+		Environment: Environment(),
+		Generation:  gen,
+		Changes:     changes,
+	}, nil
+}
+
+// ApplyPrompts 用给定的提示词覆盖全局配置中的同名提示词（键同 GetAllPrompts），
+// 用于本地调试提示词时热替换；有未知的键时不做任何修改并返回错误
+func ApplyPrompts(prompts map[string]string) (*ReloadResult, error) {
+	reloadMu.Lock()
+	defer reloadMu.Unlock()
+
+	cur := globalConfig.Load()
+	if cur == nil {
+		return nil, errors.New("配置未初始化，无法替换提示词")
+	}
+
+	merged := *cur
+	fields := merged.promptFields()
+	keys := make([]string, 0, len(prompts))
+	for key := range prompts {
+		if _, ok := fields[key]; !ok {
+			return nil, fmt.Errorf("未知的提示词: %s", key)
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var changes []string
+	for _, key := range keys {
+		if *fields[key] != prompts[key] {
+			*fields[key] = prompts[key]
+			changes = append(changes, "prompts."+key)
+		}
+	}
+
+	path, _ := loadedPath.Load().(string)
+	result := &ReloadResult{Path: path, Environment: Environment(), Generation: generation.Load(), Changes: changes}
+	if len(changes) == 0 {
+		return result, nil
+	}
+
+	globalConfig.Store(&merged)
+	result.Generation = generation.Add(1)
+	for _, fn := range reloadHooks {
+		fn(&merged)
+	}
+	return result, nil
+}
+
+// read 读取并合并各层配置，解析并校验
+func read(path string) (*Config, error) {
+	root, err := readNode(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if env := Environment(); env != "" {
+		envPath := filepath.Join(filepath.Dir(path), "config."+env+".yaml")
+		if _, statErr := os.Stat(envPath); statErr == nil {
+			overlay, err := readNode(envPath)
+			if err != nil {
+				return nil, err
+			}
+			mergeNode(root, overlay)
+		}
+	}
+
+	for _, kv := range os.Environ() {
+		if !strings.HasPrefix(kv, EnvOverride) {
+			continue
+		}
+		key, value, _ := strings.Cut(kv, "=")
+		keys := strings.Split(strings.ToLower(strings.TrimPrefix(key, EnvOverride)), "__")
+		setNode(root, keys, value)
+	}
+
+	cfg := &Config{}
+	if err := root.Decode(cfg); err != nil {
+		return nil, fmt.Errorf("解析配置文件失败: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+// readNode 读取 YAML 文件的顶层映射节点，空文件视为空映射
+func readNode(path string) (*yaml.Node, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %w", err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("解析配置文件 %s 失败: %w", path, err)
+	}
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("配置文件 %s 的顶层必须是映射", path)
+	}
+	return root, nil
+}
+
+// mergeNode 将 src 合并进 dst：映射逐键递归合并，其余（标量、列表）整体替换
+func mergeNode(dst, src *yaml.Node) {
+	if dst.Kind != yaml.MappingNode || src.Kind != yaml.MappingNode {
+		*dst = *src
+		return
+	}
+	for i := 0; i+1 < len(src.Content); i += 2 {
+		key, value := src.Content[i], src.Content[i+1]
+		if existing := mapValue(dst, key.Value); existing != nil {
+			mergeNode(existing, value)
+			continue
+		}
+		dst.Content = append(dst.Content, key, value)
+	}
+}
+
+// setNode 按路径设置标量值，路径上缺失的映射自动创建；值的类型按 YAML 规则推断
+func setNode(root *yaml.Node, keys []string, value string) {
+	node := root
+	for i, key := range keys {
+		if node.Kind != yaml.MappingNode {
+			*node = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		}
+		next := mapValue(node, key)
+		if next == nil {
+			next = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, next)
+		}
+		if i == len(keys)-1 {
+			*next = yaml.Node{Kind: yaml.ScalarNode, Value: value}
+		}
+		node = next
+	}
+}
+
+// mapValue 映射节点中指定键的值节点
+func mapValue(m *yaml.Node, key string) *yaml.Node {
+	for i := 0; i+1 < len(m.Content); i += 2 {
+		if m.Content[i].Value == key {
+			return m.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// diffLLM 列出 LLM 配置的变化
+func diffLLM(old, cur LLMConfig) []string {
+	var changes []string
+	if old.DefaultProvider != cur.DefaultProvider {
+		changes = append(changes, fmt.Sprintf("llm.default_provider: %s → %s", old.DefaultProvider, cur.DefaultProvider))
+	}
+	if old.MaxConcurrent != cur.MaxConcurrent {
+		changes = append(changes, fmt.Sprintf("llm.max_concurrent: %d → %d", old.MaxConcurrent, cur.MaxConcurrent))
+	}
+	if old.CheapMode != cur.CheapMode {
+		changes = append(changes, fmt.Sprintf("llm.cheap_mode: %v → %v", old.CheapMode, cur.CheapMode))
+	}
+	if old.CheapModel != cur.CheapModel {
+		changes = append(changes, fmt.Sprintf("llm.cheap_model: %s → %s", old.CheapModel, cur.CheapModel))
+	}
+	if old.Examples != cur.Examples {
+		changes = append(changes, fmt.Sprintf("llm.examples: %+v → %+v", old.Examples, cur.Examples))
+	}
+
+	for _, name := range unionKeys(old.Providers, cur.Providers) {
+		o, inOld := old.Providers[name]
+		n, inCur := cur.Providers[name]
+		switch {
+		case !inOld:
+			changes = append(changes, "llm.providers."+name+": 新增")
+		case !inCur:
+			changes = append(changes, "llm.providers."+name+": 移除")
+		case fmt.Sprintf("%+v", o) != fmt.Sprintf("%+v", n):
+			changes = append(changes, "llm.providers."+name+": 已修改")
+		}
+	}
+
+	for _, name := range unionKeys(old.ModuleMapping, cur.ModuleMapping) {
+		o, inOld := old.ModuleMapping[name]
+		n, inCur := cur.ModuleMapping[name]
+		switch {
+		case !inOld:
+			changes = append(changes, fmt.Sprintf("llm.module_mapping.%s: 新增 %s/%s", name, n.Provider, n.Model))
+		case !inCur:
+			changes = append(changes, fmt.Sprintf("llm.module_mapping.%s: 移除", name))
+		case !reflect.DeepEqual(o, n):
+			changes = append(changes, fmt.Sprintf("llm.module_mapping.%s: %s/%s → %s/%s（temperature %.2f → %.2f，max_tokens %d → %d，后备 %v → %v）",
+				name, o.Provider, o.Model, n.Provider, n.Model, o.Temperature, n.Temperature, o.MaxTokens, n.MaxTokens, o.Fallbacks, n.Fallbacks))
+		}
+	}
+	return changes
+}
+
+// unionKeys 两个映射键的并集，按字典序
+func unionKeys[V any](a, b map[string]V) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	for k := range a {
+		seen[k] = true
+	}
+	for k := range b {
+		seen[k] = true
+	}
+	keys := make([]string, 0, len(seen))
+	for k := range seen {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,170 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// mockProvider 干跑用的模拟提供商，无需在 llm.providers 中定义（与 llm.MockProvider 一致）
+const mockProvider = "mock"
+
+// ValidationError 配置校验错误，列出所有问题而不是只报第一个
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return fmt.Sprintf("配置校验失败（%d 项）:\n  - %s", len(e.Problems), strings.Join(e.Problems, "\n  - "))
+}
+
+// Validate 校验配置，问题以 YAML 路径开头并附修改建议
+func (c *Config) Validate() error {
+	var problems []string
+	add := func(path, format string, args ...interface{}) {
+		problems = append(problems, path+": "+fmt.Sprintf(format, args...))
+	}
+
+	providers := make([]string, 0, len(c.LLM.Providers))
+	for name := range c.LLM.Providers {
+		providers = append(providers, name)
+	}
+	sort.Strings(providers)
+	known := strings.Join(providers, ", ")
+
+	if len(c.LLM.Providers) == 0 {
+		add("llm.providers", "至少需要配置一个提供商")
+	}
+	if p := c.LLM.DefaultProvider; p != "" && p != mockProvider {
+		if _, ok := c.LLM.Providers[p]; !ok {
+			add("llm.default_provider", "提供商 %q 未在 llm.providers 中定义（已定义：%s）", p, known)
+		}
+	}
+	checkRef := func(path string, ref ModelRef) {
+		if ref.Provider != mockProvider {
+			if _, ok := c.LLM.Providers[ref.Provider]; !ok {
+				add(path+".provider", "提供商 %q 未在 llm.providers 中定义（已定义：%s）", ref.Provider, known)
+			}
+		}
+		if ref.Model == "" && ref.Provider != mockProvider {
+			add(path+".model", "不能为空")
+		}
+	}
+	if c.LLM.CheapMode && c.LLM.CheapModel.Model == "" {
+		add("llm.cheap_model", "开启 cheap_mode 时必须配置 cheap_model")
+	}
+	if c.LLM.CheapModel != (ModelRef{}) {
+		checkRef("llm.cheap_model", c.LLM.CheapModel)
+	}
+	if err := c.LLM.Examples.Validate(); err != nil {
+		add("llm.examples", "%v", err)
+	}
+	if c.LLM.MaxConcurrent < 0 {
+		add("llm.max_concurrent", "不能为负数，0 表示不限制")
+	}
+
+	for _, name := range providers {
+		p := c.LLM.Providers[name]
+		path := "llm.providers." + name
+		if p.BaseURL == "" {
+			add(path+".base_url", "不能为空")
+		}
+		if p.APIKey == "" && p.APIKeyEnv == "" {
+			add(path, "需要设置 api_key 或 api_key_env")
+		}
+		r := p.RateLimit
+		if r.RPM < 0 || r.TPM < 0 || r.MaxRetries < 0 || r.MaxBackoff < 0 {
+			add(path+".rate_limit", "rpm/tpm/max_retries/max_backoff 不能为负数，0 表示不限制或使用默认值")
+		}
+		for model, m := range r.Models {
+			if m.RPM < 0 || m.TPM < 0 {
+				add(path+".rate_limit.models."+model, "rpm/tpm 不能为负数")
+			}
+		}
+	}
+
+	modules := make([]string, 0, len(c.LLM.ModuleMapping))
+	for name := range c.LLM.ModuleMapping {
+		modules = append(modules, name)
+	}
+	sort.Strings(modules)
+	for _, name := range modules {
+		m := c.LLM.ModuleMapping[name]
+		path := "llm.module_mapping." + name
+		if m.Provider != mockProvider {
+			if _, ok := c.LLM.Providers[m.Provider]; !ok {
+				add(path+".provider", "提供商 %q 未在 llm.providers 中定义（已定义：%s）", m.Provider, known)
+			}
+		}
+		if m.Model == "" && m.Provider != mockProvider {
+			add(path+".model", "不能为空")
+		}
+		if m.Temperature < 0 || m.Temperature > 2 {
+			add(path+".temperature", "应在 0 到 2 之间，当前为 %v", m.Temperature)
+		}
+		if m.MaxTokens < 0 {
+			add(path+".max_tokens", "不能为负数")
+		}
+		for i, ref := range m.Fallbacks {
+			checkRef(fmt.Sprintf("%s.fallbacks[%d]", path, i), ref)
+		}
+	}
+
+	retry := c.System.Retry
+	if retry.MaxAttempts < 1 {
+		add("system.retry.max_attempts", "至少为 1，当前为 %d", retry.MaxAttempts)
+	}
+	if retry.InitialDelay < 0 || retry.MaxDelay < 0 {
+		add("system.retry", "initial_delay/max_delay 不能为负数")
+	}
+	if retry.MaxDelay > 0 && retry.InitialDelay > retry.MaxDelay {
+		add("system.retry.initial_delay", "不能大于 max_delay（%d > %d）", retry.InitialDelay, retry.MaxDelay)
+	}
+	if c.System.Timeout.LLMRequest < 0 || c.System.Timeout.ChapterGeneration < 0 {
+		add("system.timeout", "不能为负数，0 使用默认值")
+	}
+
+	if d := c.Degradation.Default; d != "" && !d.Valid() {
+		add("degradation.default", "未知策略 %q（可选：%s、%s、%s）", d, DegradeFailFast, DegradeFallbackWarning, DegradeFallbackSilent)
+	}
+	for module, d := range c.Degradation.Modules {
+		if !d.Valid() {
+			add("degradation.modules."+module, "未知策略 %q（可选：%s、%s、%s）", d, DegradeFailFast, DegradeFallbackWarning, DegradeFallbackSilent)
+		}
+	}
+
+	if id := c.Compliance.DefaultPolicy; id != "" && len(c.Compliance.Policies) > 0 {
+		if _, ok := c.Compliance.Policies[id]; !ok {
+			add("compliance.default_policy", "策略集 %q 未在 compliance.policies 中定义", id)
+		}
+	}
+
+	for i, stage := range c.ChapterWriter.Stages {
+		if !slices.Contains(ChapterStages, stage) {
+			add(fmt.Sprintf("chapter_writer.stages[%d]", i), "未知阶段 %q（可选：%s）", stage, strings.Join(ChapterStages, "、"))
+		}
+	}
+	if !c.ChapterWriter.Enabled(ChapterStageDraft) {
+		add("chapter_writer.stages", "必须包含 %s，修订和润色都在初稿之上进行", ChapterStageDraft)
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+	sort.Strings(problems)
+	return &ValidationError{Problems: problems}
+}
+
+// Validate 校验少样本示例注入配置，运行时切换注入模式时也使用
+func (e ExamplesConfig) Validate() error {
+	switch {
+	case e.Mode != "" && e.Mode != ExamplesOff && e.Mode != ExamplesOn && e.Mode != ExamplesAB:
+		return fmt.Errorf("mode: 未知模式 %q（可选：%s、%s、%s）", e.Mode, ExamplesOff, ExamplesOn, ExamplesAB)
+	case e.Max < 0 || e.Max > 3:
+		return fmt.Errorf("max: 应在 1 到 3 之间，0 使用默认值，当前为 %d", e.Max)
+	case e.Holdout < 0 || e.Holdout >= 1:
+		return fmt.Errorf("holdout: 应在 0 到 1 之间（不含 1），当前为 %v", e.Holdout)
+	}
+	return nil
+}
@@ -0,0 +1,117 @@
+// Package continuity 细节连续性记忆
+// 每章写完后从正文中提取外貌、伤势、随身物品等细节存入事实库，写后续章节时注入提示词，
+// 并检查本章是否与此前确立的细节矛盾。事实带有确立和结束的章节，重写前面的章节时也能取到当时有效的细节。
+package continuity
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// Outcome 一章的检查结果
+type Outcome struct {
+	Issues []models.ContinuityIssue `json:"issues"` // 与已确立细节的矛盾
+	Added  []models.ContinuityFact  `json:"added"`  // 本章新确立的细节
+	Ended  []models.ContinuityFact  `json:"ended"`  // 被本章取代的细节
+}
+
+// ActiveFacts 写第 chapter 章时仍有效的事实
+func ActiveFacts(database db.Database, projectID string, chapter int) []models.ContinuityFact {
+	if projectID == "" {
+		return nil
+	}
+	facts, err := database.ListContinuityFacts(projectID)
+	if err != nil {
+		return nil
+	}
+	active := make([]models.ContinuityFact, 0, len(facts))
+	for _, f := range facts {
+		if f.ActiveAt(chapter) {
+			active = append(active, f)
+		}
+	}
+	return active
+}
+
+// CheckChapter 检查本章正文并更新事实库，结果由调用方写入章节记录
+// 重复检查同一章时先撤销该章上次对事实库的修改
+func CheckChapter(database db.Database, w *writer.Writer, chapter *models.Chapter, prose string) (*Outcome, error) {
+	if err := database.ResetChapterContinuity(chapter.ProjectID, chapter.ChapterNum); err != nil {
+		return nil, err
+	}
+	facts := ActiveFacts(database, chapter.ProjectID, chapter.ChapterNum)
+
+	result, err := w.CheckContinuity(writer.ContinuityParams{
+		Chapter: chapter.ChapterNum,
+		Prose:   prose,
+		Facts:   facts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := &Outcome{
+		Issues: result.Issues,
+		Added:  make([]models.ContinuityFact, 0, len(result.Updates)),
+		Ended:  make([]models.ContinuityFact, 0),
+	}
+	flagged := make(map[string]bool, len(out.Issues))
+	for _, issue := range out.Issues {
+		flagged[issue.Subject+"/"+issue.Attribute] = true
+	}
+	current := make(map[string]int, len(facts))
+	for i, f := range facts {
+		current[f.Subject+"/"+f.Attribute] = i
+	}
+
+	for _, u := range result.Updates {
+		key := u.Subject + "/" + u.Attribute
+		if i, ok := current[key]; ok {
+			existing := &facts[i]
+			if existing.Value == u.Value {
+				continue
+			}
+			if !u.Changed {
+				// 没有写出变化原因的新值是矛盾，不改动事实库
+				if !flagged[key] {
+					flagged[key] = true
+					out.Issues = append(out.Issues, models.ContinuityIssue{
+						Subject:            u.Subject,
+						Attribute:          u.Attribute,
+						Established:        existing.Value,
+						EstablishedChapter: existing.Chapter,
+						Found:              u.Value,
+						Quote:              u.Evidence,
+					})
+				}
+				continue
+			}
+			existing.EndedChapter = chapter.ChapterNum
+			if err := database.SaveContinuityFact(existing); err != nil {
+				return nil, err
+			}
+			out.Ended = append(out.Ended, *existing)
+		}
+
+		fact := models.ContinuityFact{
+			ID:        db.GenerateID("fact"),
+			ProjectID: chapter.ProjectID,
+			Subject:   u.Subject,
+			Attribute: u.Attribute,
+			Value:     u.Value,
+			Category:  u.Category,
+			Permanent: u.Permanent,
+			Chapter:   chapter.ChapterNum,
+			Evidence:  u.Evidence,
+			Source:    models.FactFromProse,
+		}
+		if err := database.SaveContinuityFact(&fact); err != nil {
+			return nil, err
+		}
+		facts = append(facts, fact)
+		current[key] = len(facts) - 1
+		out.Added = append(out.Added, fact)
+	}
+	return out, nil
+}
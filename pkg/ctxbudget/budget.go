@@ -0,0 +1,212 @@
+// Package ctxbudget 上下文预算 - 估算提示词token数，按优先级压缩或截断上下文，保证提示词不超出模型的上下文窗口
+//
+// 提示词由若干段组成（世界背景、地理、文明、已有角色、任务说明……），每段标注优先级。
+// 超出预算时从优先级最低、位置最靠后的段开始处理：先尝试压缩为摘要（可选，由 Compressor 完成），
+// 压缩不可用或压缩后仍超出时再截断，剩余空间太小时整段略去。Required 段从不单独处理；
+// 全部处理后仍超出时对整个提示词做首尾保留的截断，保证结果一定在预算内
+package ctxbudget
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Count 估算文本的token数：非ASCII字符（汉字等）按一字一token，偏保守；ASCII按约4个字符一token
+func Count(s string) int {
+	n, ascii := 0, 0
+	for _, r := range s {
+		if r < utf8.RuneSelf {
+			ascii++
+			continue
+		}
+		n++
+	}
+	return n + (ascii+3)/4
+}
+
+// Priority 段的优先级，值越大越先被压缩
+type Priority int
+
+const (
+	Required Priority = iota // 任务说明、输出格式等，必须完整保留
+	High                     // 与本次任务直接相关的上下文
+	Normal                   // 一般背景
+	Low                      // 锦上添花的细节，最先被压缩
+)
+
+// Section 提示词中的一段
+type Section struct {
+	Name     string
+	Text     string
+	Priority Priority
+}
+
+// Compressor 将一段上下文压缩到目标token数以内，失败时由调用方退回截断
+type Compressor interface {
+	Compress(name, text string, target int) (string, error)
+}
+
+// Action 对某一段所做的处理
+type Action struct {
+	Section string `json:"section"`
+	Action  string `json:"action"` // compressed / truncated / dropped / clipped
+	From    int    `json:"from"`   // 处理前token数
+	To      int    `json:"to"`     // 处理后token数
+}
+
+// Result 按预算拼接的提示词
+type Result struct {
+	Text    string
+	Tokens  int
+	Actions []Action // 为空表示未超出预算
+}
+
+// minSection 截断后不足该token数的段直接略去，残段对模型没有意义
+const minSection = 80
+
+// Fit 按预算拼接各段，limit 为提示词可用的token数，<=0 时不限制
+func Fit(sections []Section, limit int, compressor Compressor) Result {
+	texts := make([]string, len(sections))
+	sizes := make([]int, len(sections))
+	total := 0
+	for i, s := range sections {
+		texts[i] = s.Text
+		sizes[i] = Count(s.Text)
+		total += sizes[i]
+	}
+	if limit <= 0 || total <= limit {
+		return Result{Text: strings.Join(texts, ""), Tokens: total}
+	}
+
+	// 优先级低的先处理，同优先级从后往前
+	order := make([]int, 0, len(sections))
+	for i, s := range sections {
+		if s.Priority != Required && sizes[i] > 0 {
+			order = append(order, i)
+		}
+	}
+	sort.SliceStable(order, func(a, b int) bool {
+		pa, pb := sections[order[a]].Priority, sections[order[b]].Priority
+		if pa != pb {
+			return pa > pb
+		}
+		return order[a] > order[b]
+	})
+
+	var actions []Action
+	apply := func(i int, text, action string) {
+		from := sizes[i]
+		texts[i], sizes[i] = text, Count(text)
+		total += sizes[i] - from
+		actions = append(actions, Action{Section: sections[i].Name, Action: action, From: from, To: sizes[i]})
+	}
+
+	// 先压缩：每段至多压到原来的四分之一，尽量保留各段的要点而不是整段略去
+	if compressor != nil {
+		for _, i := range order {
+			over := total - limit
+			if over <= 0 {
+				break
+			}
+			target := max(sizes[i]-over, sizes[i]/4)
+			if target < minSection || target >= sizes[i] {
+				continue
+			}
+			if out, err := compressor.Compress(sections[i].Name, texts[i], target); err == nil && out != "" && Count(out) <= target {
+				apply(i, out, "compressed")
+			}
+		}
+	}
+
+	// 仍超出时截断，剩余空间太小的整段略去
+	for _, i := range order {
+		over := total - limit
+		if over <= 0 {
+			break
+		}
+		if target := sizes[i] - over; target >= minSection {
+			apply(i, Truncate(texts[i], target), "truncated")
+		} else if sizes[i] > 0 {
+			apply(i, "", "dropped")
+		}
+	}
+
+	text := strings.Join(texts, "")
+	if total > limit {
+		from := total
+		text = ClipMiddle(text, limit)
+		total = Count(text)
+		actions = append(actions, Action{Section: "*", Action: "clipped", From: from, To: total})
+	}
+	return Result{Text: text, Tokens: total, Actions: actions}
+}
+
+// truncatedMark 截断处的提示
+const truncatedMark = "\n…（以下从略）\n"
+
+// Truncate 截断到 limit 个token以内，尽量在行尾截断
+func Truncate(text string, limit int) string {
+	if Count(text) <= limit {
+		return text
+	}
+	keep := limit - Count(truncatedMark)
+	if keep <= 0 {
+		return ""
+	}
+	head := prefix(text, keep)
+	if i := strings.LastIndexByte(head, '\n'); i > len(head)/2 {
+		head = head[:i]
+	}
+	return head + truncatedMark
+}
+
+// clippedMark 首尾保留截断时中间的提示
+const clippedMark = "\n…（中间部分因长度限制省略）…\n"
+
+// ClipMiddle 保留开头三分之一和结尾三分之二截断到 limit 个token以内
+// 提示词的任务要求和输出格式通常在末尾，因此多保留结尾
+func ClipMiddle(text string, limit int) string {
+	if Count(text) <= limit {
+		return text
+	}
+	keep := limit - Count(clippedMark)
+	if keep <= 0 {
+		return suffix(text, limit)
+	}
+	return prefix(text, keep/3) + clippedMark + suffix(text, keep-keep/3)
+}
+
+// prefix 不超过 limit 个token的最长前缀
+func prefix(text string, limit int) string {
+	n, ascii := 0, 0
+	for i, r := range text {
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			n++
+		}
+		if n+(ascii+3)/4 > limit {
+			return text[:i]
+		}
+	}
+	return text
+}
+
+// suffix 不超过 limit 个token的最长后缀
+func suffix(text string, limit int) string {
+	n, ascii := 0, 0
+	for i := len(text); i > 0; {
+		r, size := utf8.DecodeLastRuneInString(text[:i])
+		if r < utf8.RuneSelf {
+			ascii++
+		} else {
+			n++
+		}
+		if n+(ascii+3)/4 > limit {
+			return text[i:]
+		}
+		i -= size
+	}
+	return text
+}
@@ -0,0 +1,87 @@
+package ctxbudget
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Generator 生成文本的模型客户端（*llm.Client 满足该接口）
+type Generator interface {
+	GenerateWithParams(prompt, systemPrompt string, temperature float64, maxTokens int) (string, error)
+}
+
+// LLMCompressor 调用模型把上下文压缩为摘要
+// 同一段内容压缩到同一目标长度的结果在进程内缓存，多次构建提示词（演化轮次、重试）不重复调用
+type LLMCompressor struct {
+	gen Generator
+}
+
+// NewLLMCompressor 创建使用指定模型的压缩器
+func NewLLMCompressor(gen Generator) *LLMCompressor {
+	return &LLMCompressor{gen: gen}
+}
+
+// compressSystem 压缩时的系统提示词
+const compressSystem = "你是一位严谨的资料编辑，负责把小说设定资料压缩为简明的要点。保留专有名词、数值和相互关系，删去修饰和重复，不添加原文没有的内容。直接输出压缩后的文本。"
+
+// Compress 压缩到目标token数以内；结果仍超出时返回错误，由调用方截断
+func (c *LLMCompressor) Compress(name, text string, target int) (string, error) {
+	key := cacheKey(name, text, target)
+	if v, ok := compressed.get(key); ok {
+		return v, nil
+	}
+
+	// 按字数提要求，留出余量
+	prompt := fmt.Sprintf("请将以下「%s」压缩到%d字以内：\n\n%s", name, target*4/5, text)
+	out, err := c.gen.GenerateWithParams(prompt, compressSystem, 0.2, target)
+	if err != nil {
+		return "", err
+	}
+	out = strings.TrimSpace(out)
+	if out == "" || Count(out) > target {
+		return "", fmt.Errorf("压缩结果超出目标长度（%d > %d）", Count(out), target)
+	}
+	out = "\n" + out + "\n"
+	compressed.put(key, out)
+	return out, nil
+}
+
+func cacheKey(name, text string, target int) string {
+	sum := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s", name, target, text)))
+	return hex.EncodeToString(sum[:])
+}
+
+// compressCacheSize 压缩结果缓存条数上限，超出时淘汰最早的
+const compressCacheSize = 512
+
+var compressed = &fifoCache{items: make(map[string]string)}
+
+type fifoCache struct {
+	mu    sync.Mutex
+	items map[string]string
+	order []string
+}
+
+func (c *fifoCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	v, ok := c.items[key]
+	return v, ok
+}
+
+func (c *fifoCache) put(key, value string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.items[key]; ok {
+		return
+	}
+	if len(c.order) >= compressCacheSize {
+		delete(c.items, c.order[0])
+		c.order = c.order[1:]
+	}
+	c.items[key] = value
+	c.order = append(c.order, key)
+}
@@ -0,0 +1,248 @@
+// Package cultivation 修为境界追踪
+// 按章节记录角色的境界变化，对照世界修真体系的境界划分和瓶颈校验突破是否合理，
+// 并算出各角色当前境界，供战斗场景的提示词使用。
+package cultivation
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// AspectRealm 一致性报告中境界问题的方面名
+const AspectRealm = "修为境界"
+
+// MinBreakthroughGap 同一角色两次突破之间至少间隔的章节数，更频繁的突破视为违规
+const MinBreakthroughGap = 3
+
+// 违规类型
+const (
+	ViolationUnknownRealm = "unknown_realm" // 境界不在世界的境界划分中
+	ViolationSkipped      = "skipped"       // 越级突破
+	ViolationNoCost       = "no_cost"       // 没有交代瓶颈如何破除、付出什么代价
+	ViolationTooFrequent  = "too_frequent"  // 突破过于频繁
+	ViolationDirection    = "direction"     // 记为突破的变化实为跌落，或相反
+)
+
+// Tracker 境界追踪，记录按章节排序
+type Tracker struct {
+	realms []string
+	events []models.RealmEvent
+}
+
+// NewTracker 由境界划分和境界记录构建
+func NewTracker(realms []string, events []models.RealmEvent) *Tracker {
+	sorted := make([]models.RealmEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Chapter < sorted[j].Chapter })
+	return &Tracker{realms: realms, events: sorted}
+}
+
+// Load 加载项目的境界记录，出错时返回空记录
+func Load(database db.Database, projectID string, realms []string) *Tracker {
+	if projectID == "" {
+		return NewTracker(realms, nil)
+	}
+	events, err := database.ListRealmEvents(projectID)
+	if err != nil {
+		return NewTracker(realms, nil)
+	}
+	return NewTracker(realms, events)
+}
+
+// StatesAt 第 chapter 章开始时项目各角色的境界，世界没有修真体系时返回空
+func StatesAt(database db.Database, projectID string, world *models.WorldSetting, chapter int) []models.RealmState {
+	system := world.Cultivation()
+	if system == nil {
+		return nil
+	}
+	return Load(database, projectID, system.Realms).StatesAt(chapter)
+}
+
+// Events 全部境界记录
+func (t *Tracker) Events() []models.RealmEvent {
+	return t.events
+}
+
+// Level 境界在划分中的序号（从1开始），不在划分中返回 0
+// 正文常写作“筑基中期”“金丹大圆满”，按最长的境界名前缀匹配
+func (t *Tracker) Level(realm string) int {
+	realm = strings.TrimSpace(realm)
+	best, bestLen := 0, 0
+	for i, r := range t.realms {
+		if r != "" && strings.HasPrefix(realm, r) && len(r) > bestLen {
+			best, bestLen = i+1, len(r)
+		}
+	}
+	return best
+}
+
+// StatesAt 第 chapter 章开始时各角色的境界，只计此前各章的记录
+func (t *Tracker) StatesAt(chapter int) []models.RealmState {
+	states := make(map[string]*models.RealmState)
+	order := make([]string, 0)
+	for _, e := range t.events {
+		if e.Chapter >= chapter {
+			break
+		}
+		s, ok := states[e.Character]
+		if !ok {
+			s = &models.RealmState{Character: e.Character}
+			states[e.Character] = s
+			order = append(order, e.Character)
+		}
+		s.Realm = e.Realm
+		s.Level = t.Level(e.Realm)
+		s.Since = e.Chapter
+	}
+
+	out := make([]models.RealmState, 0, len(order))
+	for _, name := range order {
+		out = append(out, *states[name])
+	}
+	return out
+}
+
+// Violation 不合理的境界变化
+type Violation struct {
+	Character string `json:"character"`
+	Chapter   int    `json:"chapter"`
+	Kind      string `json:"kind"`
+	Detail    string `json:"detail"`
+}
+
+// Validate 校验境界记录：境界须在划分中、突破不得越级、不得过于频繁；
+// 世界设定了瓶颈时，每次突破都要交代如何破除瓶颈或付出的代价
+func (t *Tracker) Validate(bottleneck string) []Violation {
+	violations := make([]Violation, 0)
+	last := make(map[string]models.RealmEvent)
+	lastBreak := make(map[string]int)
+	for _, e := range t.events {
+		level := t.Level(e.Realm)
+		if level == 0 && len(t.realms) > 0 {
+			violations = append(violations, Violation{
+				Character: e.Character,
+				Chapter:   e.Chapter,
+				Kind:      ViolationUnknownRealm,
+				Detail:    fmt.Sprintf("%s在第%d章的境界“%s”不在世界的境界划分（%s）中", e.Character, e.Chapter, e.Realm, strings.Join(t.realms, "→")),
+			})
+		}
+
+		prev, hasPrev := last[e.Character]
+		last[e.Character] = e
+		if e.Kind == models.RealmInitial {
+			continue
+		}
+		prevLevel := 0
+		if hasPrev {
+			prevLevel = t.Level(prev.Realm)
+		}
+
+		if hasPrev && level > 0 && prevLevel > 0 {
+			switch {
+			case e.Kind == models.RealmBreakthrough && level <= prevLevel:
+				violations = append(violations, Violation{
+					Character: e.Character, Chapter: e.Chapter, Kind: ViolationDirection,
+					Detail: fmt.Sprintf("%s在第%d章记为突破，但境界由%s变为%s，并未提升", e.Character, e.Chapter, prev.Realm, e.Realm),
+				})
+			case e.Kind == models.RealmRegression && level > prevLevel:
+				violations = append(violations, Violation{
+					Character: e.Character, Chapter: e.Chapter, Kind: ViolationDirection,
+					Detail: fmt.Sprintf("%s在第%d章记为跌落，但境界由%s升为%s", e.Character, e.Chapter, prev.Realm, e.Realm),
+				})
+			case e.Kind == models.RealmBreakthrough && level-prevLevel > 1:
+				violations = append(violations, Violation{
+					Character: e.Character, Chapter: e.Chapter, Kind: ViolationSkipped,
+					Detail: fmt.Sprintf("%s在第%d章由%s直接突破到%s，跳过了%s", e.Character, e.Chapter, prev.Realm, e.Realm, strings.Join(t.realms[prevLevel:level-1], "、")),
+				})
+			}
+		}
+
+		if e.Kind != models.RealmBreakthrough {
+			continue
+		}
+		if since, ok := lastBreak[e.Character]; ok && e.Chapter-since < MinBreakthroughGap {
+			violations = append(violations, Violation{
+				Character: e.Character, Chapter: e.Chapter, Kind: ViolationTooFrequent,
+				Detail: fmt.Sprintf("%s在第%d章和第%d章接连突破，间隔不足%d章", e.Character, since, e.Chapter, MinBreakthroughGap),
+			})
+		}
+		lastBreak[e.Character] = e.Chapter
+		if bottleneck != "" && strings.TrimSpace(e.Cost) == "" {
+			violations = append(violations, Violation{
+				Character: e.Character, Chapter: e.Chapter, Kind: ViolationNoCost,
+				Detail: fmt.Sprintf("%s在第%d章突破到%s，但没有交代如何跨过瓶颈（%s）或付出了什么代价", e.Character, e.Chapter, e.Realm, bottleneck),
+			})
+		}
+	}
+	return violations
+}
+
+// Issues 将违规转换为一致性问题
+func Issues(violations []Violation) []models.ConsistencyIssue {
+	issues := make([]models.ConsistencyIssue, 0, len(violations))
+	for _, v := range violations {
+		issue := models.ConsistencyIssue{
+			Aspect:   AspectRealm,
+			Issue:    v.Detail,
+			Severity: "medium",
+		}
+		switch v.Kind {
+		case ViolationUnknownRealm:
+			issue.Suggestion = "改用世界设定中的境界名称，或在修真体系中补充该境界"
+		case ViolationSkipped:
+			issue.Suggestion = "补写中间境界的突破，或交代越级的特殊机缘与代价"
+			issue.Severity = "high"
+		case ViolationNoCost:
+			issue.Suggestion = "补写突破前的积累、借助的资源或付出的代价"
+		case ViolationTooFrequent:
+			issue.Suggestion = "拉开突破的间隔，或把其中一次改为境界内的小幅精进"
+		case ViolationDirection:
+			issue.Suggestion = "核对境界记录，修正变化类型或境界名称"
+			issue.Severity = "low"
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// MergeIntoReport 用最新的境界问题替换一致性报告中原有的境界问题
+func MergeIntoReport(report *models.ConsistencyReport, issues []models.ConsistencyIssue) *models.ConsistencyReport {
+	if report == nil {
+		report = &models.ConsistencyReport{}
+	}
+	kept := make([]models.ConsistencyIssue, 0, len(report.Issues)+len(issues))
+	for _, issue := range report.Issues {
+		if issue.Aspect != AspectRealm {
+			kept = append(kept, issue)
+		}
+	}
+	report.Issues = append(kept, issues...)
+	return report
+}
+
+// RecordChapter 用章节分析的结果替换该章从正文提取的境界记录，手工登记的记录保留
+func RecordChapter(database db.Database, projectID string, chapter int, changes []models.RealmEvent) error {
+	events := make([]models.RealmEvent, 0, len(changes))
+	for _, e := range changes {
+		e.Character = strings.TrimSpace(e.Character)
+		e.Realm = strings.TrimSpace(e.Realm)
+		if e.Character == "" || e.Realm == "" {
+			continue
+		}
+		switch e.Kind {
+		case models.RealmInitial, models.RealmBreakthrough, models.RealmRegression:
+		default:
+			continue
+		}
+		e.ID = db.GenerateID("realm")
+		e.ProjectID = projectID
+		e.Chapter = chapter
+		e.Source = models.RealmFromProse
+		events = append(events, e)
+	}
+	return database.ReplaceChapterRealmEvents(projectID, chapter, events)
+}
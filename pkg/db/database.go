@@ -44,22 +44,36 @@ var (
 // Get 获取数据库实例（单例）
 func Get() Database {
 	once.Do(func() {
-		// 使用PostgreSQL
-		var err error
-		pgDB, err := NewPostgres(nil) // nil means use strict default config (which reads envs)
-		if err != nil {
-			// Fallback to memory or panic?
-			// Panic is better to ensure we know it failed
-			fmt.Printf("Initial DB connection failed: %v\n", err)
-			panic("failed to connect to database")
+		// 根据 DB_DRIVER 选择驱动，默认PostgreSQL
+		var gdb *PostgresDatabase
+		switch DBType(getEnv("DB_DRIVER", string(DBTypePostgres))) {
+		case DBTypeSQLite:
+			sqliteDB, err := NewSQLite("")
+			if err != nil {
+				fmt.Printf("Initial DB connection failed: %v\n", err)
+				panic("failed to open sqlite database")
+			}
+			defaultDB = sqliteDB
+			gdb = sqliteDB.PostgresDatabase
+		default:
+			pgDB, err := NewPostgres(nil) // nil means use strict default config (which reads envs)
+			if err != nil {
+				// Fallback to memory or panic?
+				// Panic is better to ensure we know it failed
+				fmt.Printf("Initial DB connection failed: %v\n", err)
+				panic("failed to connect to database")
+			}
+			defaultDB = pgDB
+			gdb = pgDB
 		}
 
 		// 自动迁移
-		if err := pgDB.Migrate(); err != nil {
+		if skipMigrate {
+			return
+		}
+		if err := gdb.Migrate(); err != nil {
 			fmt.Printf("DB Migration failed: %v\n", err)
 		}
-
-		defaultDB = pgDB
 	})
 	return defaultDB
 }
@@ -233,6 +247,26 @@ func (d *MemoryDatabase) GetWorld(id string) (*models.WorldSetting, error) {
 	return world, nil
 }
 
+// SaveWorldIfRevision 按行版本条件保存世界设定
+func (d *MemoryDatabase) SaveWorldIfRevision(world *models.WorldSetting, revision int) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if current, ok := d.worlds[world.ID]; !ok {
+		return ErrNotFound
+	} else if current.Revision != revision {
+		return ErrWorldRevisionConflict
+	}
+	world.Revision = revision + 1
+	world.UpdatedAt = time.Now()
+	d.worlds[world.ID] = world
+
+	if d.autoSave {
+		return d.save()
+	}
+	return nil
+}
+
 // ListWorlds 列出所有世界设定
 func (d *MemoryDatabase) ListWorlds() []*models.WorldSetting {
 	d.mu.RLock()
@@ -689,6 +723,12 @@ func (d *MemoryDatabase) GetSceneByBlueprintAndChapter(blueprintID string, chapt
 // ErrNotFound 记录不存在错误
 var ErrNotFound = fmt.Errorf("record not found")
 
+// ErrWorldRevisionConflict 世界设定已被其他请求修改
+var ErrWorldRevisionConflict = fmt.Errorf("world revision conflict")
+
+// ErrChapterNumConflict 恢复的章节与项目中现有章节的章节号相同
+var ErrChapterNumConflict = fmt.Errorf("chapter number already in use")
+
 // IsNotFound 判断是否为记录不存在错误
 func IsNotFound(err error) bool {
 	return err == ErrNotFound || strings.Contains(err.Error(), "not found")
@@ -950,6 +990,7 @@ func (d *MemoryDatabase) SaveChapter(chapter *models.Chapter) error {
 	if chapter.CreatedAt.IsZero() {
 		chapter.CreatedAt = time.Now()
 	}
+	chapter.Version++
 
 	d.chapters[chapter.ID] = chapter
 
@@ -2,6 +2,9 @@
 package db
 
 import (
+	"context"
+	"time"
+
 	"github.com/xlei/xupu/internal/models"
 )
 
@@ -16,6 +19,9 @@ type Database interface {
 	ListWorlds() []*models.WorldSetting
 	DeleteWorld(id string) error
 	UpdateWorldStage(id string, stage string, data interface{}) error
+	// SaveWorldIfRevision 仅当库中行版本仍为 revision 时保存，并把 Revision 加一；
+	// 已被其他请求写入时返回 ErrWorldRevisionConflict
+	SaveWorldIfRevision(world *models.WorldSetting, revision int) error
 
 	// Character
 	SaveCharacter(character *models.Character) error
@@ -87,9 +93,184 @@ type Database interface {
 	GetPromptTemplate(key string) (*models.PromptTemplate, error)
 	SavePromptTemplate(prompt *models.PromptTemplate) error
 
+	ListPromptExamples(role string) ([]models.PromptExample, error) // role 为空时列出全部
+	GetPromptExample(id string) (*models.PromptExample, error)
+	SavePromptExample(example *models.PromptExample) error
+	DeletePromptExample(id string) error
+
 	GetNarrativeTemplates() ([]models.NarrativeTemplate, error)
 	GetNarrativeTemplate(id string) (*models.NarrativeTemplate, error)
 	SaveNarrativeTemplate(template *models.NarrativeTemplate) error
+	ListUserNarrativeTemplates(userID string) ([]models.NarrativeTemplate, error)
+	DeleteNarrativeTemplate(id string) error
+
+	GetPromptExperiments() ([]models.PromptExperiment, error)
+	GetPromptExperiment(id string) (*models.PromptExperiment, error)
+	SavePromptExperiment(experiment *models.PromptExperiment) error
+
+	// StyleProfile
+	ListStyleProfiles(userID string) ([]models.StyleProfile, error)
+	GetStyleProfile(id string) (*models.StyleProfile, error)
+	SaveStyleProfile(profile *models.StyleProfile) error
+	DeleteStyleProfile(id string) error
+
+	// MemoryEntry
+	ListMemoryEntries(blueprintID string) ([]models.MemoryEntry, error)
+	SaveMemoryEntries(entries []models.MemoryEntry) error
+	DeleteMemoryEntries(blueprintID string) error
+
+	// CharacterSheet
+	ListCharacterSheets(worldID string) ([]models.CharacterSheet, error)
+	GetCharacterSheet(id string) (*models.CharacterSheet, error)
+	SaveCharacterSheet(sheet *models.CharacterSheet) error
+	DeleteCharacterSheet(id string) error
+
+	// ReleasePlan
+	GetReleasePlan(projectID string) (*models.ReleasePlan, error)
+	SaveReleasePlan(plan *models.ReleasePlan) error
+
+	// RankSnapshot
+	SaveRankSnapshot(snapshot *models.RankSnapshot) error
+	ListRankSnapshots(source string, since time.Time) ([]models.RankSnapshot, error)
+
+	// CompetitorAnalysis
+	ListCompetitorAnalyses(projectID string) ([]models.CompetitorAnalysis, error)
+	GetCompetitorAnalysis(id string) (*models.CompetitorAnalysis, error)
+	SaveCompetitorAnalysis(analysis *models.CompetitorAnalysis) error
+	DeleteCompetitorAnalysis(id string) error
+
+	// ChapterTranslation
+	ListChapterTranslations(projectID, language string) ([]models.ChapterTranslation, error)
+	GetChapterTranslation(chapterID, language string) (*models.ChapterTranslation, error)
+	SaveChapterTranslation(translation *models.ChapterTranslation) error
+
+	// BlueprintBranch
+	ListBlueprintBranches(blueprintID string) ([]models.BlueprintBranch, error)
+	GetBlueprintBranch(id string) (*models.BlueprintBranch, error)
+	SaveBlueprintBranch(branch *models.BlueprintBranch) error
+
+	// MarketingCopy
+	GetMarketingCopy(projectID string) (*models.MarketingCopy, error)
+	SaveMarketingCopy(marketing *models.MarketingCopy) error
+
+	// EntityMention
+	ListEntityMentions(projectID string) ([]models.EntityMention, error)
+	ReplaceChapterMentions(projectID string, chapterNum int, mentions []models.EntityMention) error
+
+	// ContinuityFact
+	ListContinuityFacts(projectID string) ([]models.ContinuityFact, error)
+	GetContinuityFact(id string) (*models.ContinuityFact, error)
+	SaveContinuityFact(fact *models.ContinuityFact) error
+	DeleteContinuityFact(id string) error
+	ResetChapterContinuity(projectID string, chapter int) error
+
+	// VitalEvent
+	ListVitalEvents(projectID string) ([]models.VitalEvent, error)
+	GetVitalEvent(id string) (*models.VitalEvent, error)
+	SaveVitalEvent(event *models.VitalEvent) error
+	DeleteVitalEvent(id string) error
+	ReplaceChapterVitalEvents(projectID string, chapter int, events []models.VitalEvent) error
+
+	// Item
+	ListItems(projectID string) ([]models.Item, error)
+	GetItem(id string) (*models.Item, error)
+	SaveItem(item *models.Item) error
+	DeleteItem(id string) error
+	ListItemTransfers(projectID string) ([]models.ItemTransfer, error)
+	SaveItemTransfer(transfer *models.ItemTransfer) error
+	DeleteItemTransfer(id string) error
+	ReplaceChapterItemTransfers(projectID string, chapter int, transfers []models.ItemTransfer) error
+
+	// Cultivation
+	ListRealmEvents(projectID string) ([]models.RealmEvent, error)
+	GetRealmEvent(id string) (*models.RealmEvent, error)
+	SaveRealmEvent(event *models.RealmEvent) error
+	DeleteRealmEvent(id string) error
+	ReplaceChapterRealmEvents(projectID string, chapter int, events []models.RealmEvent) error
+
+	// EditSuggestion
+	ListEditSuggestions(chapterID, status string) ([]models.EditSuggestion, error) // status 为空时列出全部
+	GetEditSuggestion(id string) (*models.EditSuggestion, error)
+	SaveEditSuggestion(suggestion *models.EditSuggestion) error
+
+	// Annotation
+	ListAnnotations(chapterID string, resolved *bool) ([]models.Annotation, error) // resolved 为 nil 时列出全部
+	GetAnnotation(id string) (*models.Annotation, error)
+	SaveAnnotation(annotation *models.Annotation) error
+	DeleteAnnotation(id string) error // 同时删除回复
+	ListAnnotationReplies(annotationIDs []string) ([]models.AnnotationReply, error)
+	SaveAnnotationReply(reply *models.AnnotationReply) error
+
+	// ReferenceText
+	ListReferenceTexts(projectID string) ([]models.ReferenceText, error) // 含正文
+	GetReferenceText(id string) (*models.ReferenceText, error)
+	SaveReferenceText(ref *models.ReferenceText) error
+	DeleteReferenceText(id string) error
+
+	// WritingDay
+	ListWritingDays(projectID, since string) ([]models.WritingDay, error) // since 为 2006-01-02，为空时返回全部
+
+	// ManuscriptImport
+	ListManuscriptImports(projectID string) ([]models.ManuscriptImport, error)
+	GetManuscriptImport(id string) (*models.ManuscriptImport, error)
+	SaveManuscriptImport(imp *models.ManuscriptImport) error
+
+	// CronJob
+	ListCronJobs() ([]models.CronJob, error)
+	GetCronJob(id string) (*models.CronJob, error)
+	SaveCronJob(job *models.CronJob) error
+	DeleteCronJob(id string) error
+	ListCronRuns(jobID string, limit int) ([]models.CronRun, error)
+	SaveCronRun(run *models.CronRun) error
+
+	// Webhook
+	ListWebhooks(projectID string) ([]models.Webhook, error)
+	GetWebhook(id string) (*models.Webhook, error)
+	SaveWebhook(hook *models.Webhook) error
+	DeleteWebhook(id string) error
+	ListWebhookDeliveries(webhookID string, limit int) ([]models.WebhookDelivery, error)
+	GetWebhookDelivery(id string) (*models.WebhookDelivery, error)
+	SaveWebhookDelivery(delivery *models.WebhookDelivery) error
+
+	// NotificationChannel
+	ListNotificationChannels(userID string) ([]models.NotificationChannel, error)
+	GetNotificationChannel(id string) (*models.NotificationChannel, error)
+	SaveNotificationChannel(ch *models.NotificationChannel) error
+	DeleteNotificationChannel(id string) error
+
+	// System stats
+	CountUsers(activeSince time.Time) (total int, active int, err error)
+	CountProjectsByStatus() (map[string]int, error)
+	AddLLMUsage(usage *models.LLMUsageDay) error
+	ListLLMUsage(sinceDate string) ([]models.LLMUsageDay, error)
+	StorageUsage() (*models.StorageUsage, error)
+
+	// UserQuota
+	GetUserQuota(userID string) (*models.UserQuota, error)
+	SaveUserQuota(quota *models.UserQuota) error
+	DeleteUserQuota(userID string) error
+	GetUserUsage(userID, month string) (*models.UserUsageMonth, error)
+	AddUserUsage(usage *models.UserUsageMonth) error
+	MarkUserUsageNotified(userID, month string, exceeded bool) (bool, error)
+
+	// APIKey
+	ListAPIKeys(userID string) ([]models.APIKey, error)
+	GetAPIKey(id string) (*models.APIKey, error)
+	GetAPIKeyByHash(hash string) (*models.APIKey, error)
+	SaveAPIKey(key *models.APIKey) error
+	TouchAPIKey(id string, at time.Time) error
+	ListAPIKeyAudits(keyID string, limit int) ([]models.APIKeyAudit, error)
+	SaveAPIKeyAudit(audit *models.APIKeyAudit) error
+
+	// AuditLog（只追加）
+	AppendAuditLog(entry *models.AuditLog) error
+	ListAuditLogs(filter models.AuditFilter) ([]models.AuditLog, error)
+
+	// Trash
+	ListTrash(userID string) ([]models.TrashItem, error)
+	GetTrashItem(kind, id string) (*models.TrashItem, error)
+	RestoreTrash(kind, id string) error
+	PurgeTrash(before time.Time) (map[string]int, error)
 
 	// Utilities
 	Stats() map[string]int
@@ -102,13 +283,28 @@ type DBType string
 const (
 	DBTypeMemory   DBType = "memory"   // 内存数据库
 	DBTypePostgres DBType = "postgres" // PostgreSQL
+	DBTypeSQLite   DBType = "sqlite"   // SQLite（单用户/本地模式）
 )
 
+// Pinger 可探测连接状态的数据库（PostgreSQL/SQLite），内存数据库不实现
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping 探测数据库连接，未实现 Pinger 的实现视为可用
+func Ping(ctx context.Context, d Database) error {
+	if p, ok := d.(Pinger); ok {
+		return p.Ping(ctx)
+	}
+	return nil
+}
+
 // Config 数据库配置
 type Config struct {
 	Type     DBType
 	Postgres *PostgresConfig
 	DataDir  string // 用于内存数据库的数据目录
+	SQLite   string // SQLite数据库文件路径
 }
 
 // Init 根据配置初始化数据库
@@ -126,6 +322,8 @@ func Init(cfg *Config) (Database, error) {
 			cfg.Postgres = DefaultPostgresConfig()
 		}
 		return NewPostgres(cfg.Postgres)
+	case DBTypeSQLite:
+		return NewSQLite(cfg.SQLite)
 	case DBTypeMemory:
 		fallthrough
 	default:
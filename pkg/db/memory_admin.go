@@ -2,6 +2,7 @@ package db
 
 import (
 	"errors"
+	"time"
 
 	"github.com/xlei/xupu/internal/models"
 )
@@ -34,6 +35,22 @@ func (d *MemoryDatabase) SavePromptTemplate(prompt *models.PromptTemplate) error
 	return errors.New("not implemented in memory db")
 }
 
+func (d *MemoryDatabase) ListPromptExamples(role string) ([]models.PromptExample, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetPromptExample(id string) (*models.PromptExample, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SavePromptExample(example *models.PromptExample) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeletePromptExample(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
 func (d *MemoryDatabase) GetNarrativeTemplates() ([]models.NarrativeTemplate, error) {
 	return nil, errors.New("not implemented in memory db")
 }
@@ -45,3 +62,479 @@ func (d *MemoryDatabase) GetNarrativeTemplate(id string) (*models.NarrativeTempl
 func (d *MemoryDatabase) SaveNarrativeTemplate(template *models.NarrativeTemplate) error {
 	return errors.New("not implemented in memory db")
 }
+
+func (d *MemoryDatabase) ListUserNarrativeTemplates(userID string) ([]models.NarrativeTemplate, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteNarrativeTemplate(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetPromptExperiments() ([]models.PromptExperiment, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetPromptExperiment(id string) (*models.PromptExperiment, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SavePromptExperiment(experiment *models.PromptExperiment) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListStyleProfiles(userID string) ([]models.StyleProfile, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetStyleProfile(id string) (*models.StyleProfile, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveStyleProfile(profile *models.StyleProfile) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteStyleProfile(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListMemoryEntries(blueprintID string) ([]models.MemoryEntry, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveMemoryEntries(entries []models.MemoryEntry) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteMemoryEntries(blueprintID string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListCharacterSheets(worldID string) ([]models.CharacterSheet, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetCharacterSheet(id string) (*models.CharacterSheet, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveCharacterSheet(sheet *models.CharacterSheet) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteCharacterSheet(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetReleasePlan(projectID string) (*models.ReleasePlan, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveReleasePlan(plan *models.ReleasePlan) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveRankSnapshot(snapshot *models.RankSnapshot) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListRankSnapshots(source string, since time.Time) ([]models.RankSnapshot, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListCompetitorAnalyses(projectID string) ([]models.CompetitorAnalysis, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetCompetitorAnalysis(id string) (*models.CompetitorAnalysis, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveCompetitorAnalysis(analysis *models.CompetitorAnalysis) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteCompetitorAnalysis(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListChapterTranslations(projectID, language string) ([]models.ChapterTranslation, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetChapterTranslation(chapterID, language string) (*models.ChapterTranslation, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveChapterTranslation(translation *models.ChapterTranslation) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListBlueprintBranches(blueprintID string) ([]models.BlueprintBranch, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetBlueprintBranch(id string) (*models.BlueprintBranch, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveBlueprintBranch(branch *models.BlueprintBranch) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetMarketingCopy(projectID string) (*models.MarketingCopy, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveMarketingCopy(marketing *models.MarketingCopy) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListEntityMentions(projectID string) ([]models.EntityMention, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ReplaceChapterMentions(projectID string, chapterNum int, mentions []models.EntityMention) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListContinuityFacts(projectID string) ([]models.ContinuityFact, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetContinuityFact(id string) (*models.ContinuityFact, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveContinuityFact(fact *models.ContinuityFact) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteContinuityFact(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ResetChapterContinuity(projectID string, chapter int) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListVitalEvents(projectID string) ([]models.VitalEvent, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetVitalEvent(id string) (*models.VitalEvent, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveVitalEvent(event *models.VitalEvent) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteVitalEvent(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ReplaceChapterVitalEvents(projectID string, chapter int, events []models.VitalEvent) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListItems(projectID string) ([]models.Item, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetItem(id string) (*models.Item, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveItem(item *models.Item) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteItem(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListItemTransfers(projectID string) ([]models.ItemTransfer, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveItemTransfer(transfer *models.ItemTransfer) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteItemTransfer(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ReplaceChapterItemTransfers(projectID string, chapter int, transfers []models.ItemTransfer) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListRealmEvents(projectID string) ([]models.RealmEvent, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetRealmEvent(id string) (*models.RealmEvent, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveRealmEvent(event *models.RealmEvent) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteRealmEvent(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ReplaceChapterRealmEvents(projectID string, chapter int, events []models.RealmEvent) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListCronJobs() ([]models.CronJob, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetCronJob(id string) (*models.CronJob, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveCronJob(job *models.CronJob) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteCronJob(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListCronRuns(jobID string, limit int) ([]models.CronRun, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveCronRun(run *models.CronRun) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListWebhooks(projectID string) ([]models.Webhook, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetWebhook(id string) (*models.Webhook, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveWebhook(hook *models.Webhook) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteWebhook(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListWebhookDeliveries(webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetWebhookDelivery(id string) (*models.WebhookDelivery, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveWebhookDelivery(delivery *models.WebhookDelivery) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListNotificationChannels(userID string) ([]models.NotificationChannel, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetNotificationChannel(id string) (*models.NotificationChannel, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveNotificationChannel(ch *models.NotificationChannel) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteNotificationChannel(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) CountUsers(activeSince time.Time) (int, int, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	active := 0
+	for _, user := range d.users {
+		if user.LastLoginAt != nil && !user.LastLoginAt.Before(activeSince) {
+			active++
+		}
+	}
+	return len(d.users), active, nil
+}
+
+func (d *MemoryDatabase) CountProjectsByStatus() (map[string]int, error) {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	counts := make(map[string]int)
+	for _, proj := range d.projects {
+		counts[string(proj.Status)]++
+	}
+	return counts, nil
+}
+
+func (d *MemoryDatabase) AddLLMUsage(usage *models.LLMUsageDay) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListLLMUsage(sinceDate string) ([]models.LLMUsageDay, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) StorageUsage() (*models.StorageUsage, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetUserQuota(userID string) (*models.UserQuota, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveUserQuota(quota *models.UserQuota) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteUserQuota(userID string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetUserUsage(userID, month string) (*models.UserUsageMonth, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) AddUserUsage(usage *models.UserUsageMonth) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) MarkUserUsageNotified(userID, month string, exceeded bool) (bool, error) {
+	return false, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListAPIKeys(userID string) ([]models.APIKey, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetAPIKey(id string) (*models.APIKey, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveAPIKey(key *models.APIKey) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) TouchAPIKey(id string, at time.Time) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListAPIKeyAudits(keyID string, limit int) ([]models.APIKeyAudit, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveAPIKeyAudit(audit *models.APIKeyAudit) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) AppendAuditLog(entry *models.AuditLog) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListAuditLogs(filter models.AuditFilter) ([]models.AuditLog, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListTrash(userID string) ([]models.TrashItem, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetTrashItem(kind, id string) (*models.TrashItem, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) RestoreTrash(kind, id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) PurgeTrash(before time.Time) (map[string]int, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListEditSuggestions(chapterID, status string) ([]models.EditSuggestion, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetEditSuggestion(id string) (*models.EditSuggestion, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveEditSuggestion(suggestion *models.EditSuggestion) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListAnnotations(chapterID string, resolved *bool) ([]models.Annotation, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetAnnotation(id string) (*models.Annotation, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveAnnotation(annotation *models.Annotation) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteAnnotation(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListAnnotationReplies(annotationIDs []string) ([]models.AnnotationReply, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveAnnotationReply(reply *models.AnnotationReply) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListReferenceTexts(projectID string) ([]models.ReferenceText, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetReferenceText(id string) (*models.ReferenceText, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveReferenceText(ref *models.ReferenceText) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) DeleteReferenceText(id string) error {
+	return errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListWritingDays(projectID, since string) ([]models.WritingDay, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) ListManuscriptImports(projectID string) ([]models.ManuscriptImport, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) GetManuscriptImport(id string) (*models.ManuscriptImport, error) {
+	return nil, errors.New("not implemented in memory db")
+}
+
+func (d *MemoryDatabase) SaveManuscriptImport(imp *models.ManuscriptImport) error {
+	return errors.New("not implemented in memory db")
+}
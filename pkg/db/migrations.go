@@ -0,0 +1,516 @@
+// Package db 版本化数据库迁移
+package db
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"gorm.io/gorm"
+)
+
+// Migration 一次版本化迁移
+// 新增模型或字段时在 migrations 末尾追加新版本，不要修改已发布的版本
+type Migration struct {
+	Version     int
+	Description string
+	Up          func(tx *gorm.DB) error
+}
+
+// SchemaMigration 已应用的迁移记录
+type SchemaMigration struct {
+	Version     int       `gorm:"primaryKey;autoIncrement:false" json:"version"`
+	Description string    `gorm:"type:varchar(255)" json:"description"`
+	AppliedAt   time.Time `json:"applied_at"`
+}
+
+// TableName 迁移记录表名
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// skipMigrate 为true时启动不执行迁移（--skip-migrate）
+var skipMigrate bool
+
+// SetSkipMigrate 设置是否跳过启动时的自动迁移，需在首次 Get() 之前调用
+func SetSkipMigrate(skip bool) {
+	skipMigrate = skip
+}
+
+// SkipMigrate 是否跳过自动迁移
+func SkipMigrate() bool {
+	return skipMigrate
+}
+
+// AllModels 所有需要建表的模型
+func AllModels() []interface{} {
+	return []interface{}{
+		&models.WorldSetting{},
+		&models.Character{},
+		&models.Project{},
+		&models.NarrativeBlueprint{},
+		&models.Chapter{},
+		&models.ChapterLock{},
+		&models.NarrativeNode{},
+		&models.NodeChapterMapping{},
+		&models.SceneOutput{},
+		&models.User{},
+		&models.AuthToken{},
+		&models.SysConfig{},
+		&models.PromptTemplate{},
+		&models.NarrativeTemplate{},
+		&models.PromptExperiment{},
+		&models.StyleProfile{},
+		&models.MemoryEntry{},
+		&models.CharacterSheet{},
+		&models.ReleasePlan{},
+		&models.RankSnapshot{},
+		&models.CompetitorAnalysis{},
+		&models.ChapterTranslation{},
+		&models.BlueprintBranch{},
+		&models.MarketingCopy{},
+		&models.EntityMention{},
+		&models.ContinuityFact{},
+		&models.VitalEvent{},
+		&models.Item{},
+		&models.ItemTransfer{},
+		&models.RealmEvent{},
+	}
+}
+
+// migrations 按版本号递增排列的迁移列表
+var migrations = []Migration{
+	{
+		Version:     1,
+		Description: "初始表结构",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(AllModels()...)
+		},
+	},
+	{
+		Version:     2,
+		Description: "项目章节字数目标",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Project{})
+		},
+	},
+	{
+		Version:     3,
+		Description: "章节细纲",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Chapter{})
+		},
+	},
+	{
+		Version:     4,
+		Description: "写作风格档案",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.StyleProfile{}, &models.Project{})
+		},
+	},
+	{
+		Version:     5,
+		Description: "世界人名登记",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.WorldSetting{})
+		},
+	},
+	{
+		Version:     6,
+		Description: "检索记忆",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.MemoryEntry{})
+		},
+	},
+	{
+		Version:     7,
+		Description: "章节摘要与角色状态变化",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Chapter{})
+		},
+	},
+	{
+		Version:     8,
+		Description: "角色语音档案与对话一致性检查",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.NarrativeBlueprint{}, &models.Chapter{})
+		},
+	},
+	{
+		Version:     9,
+		Description: "蓝图视角策略",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.NarrativeBlueprint{})
+		},
+	},
+	{
+		Version:     10,
+		Description: "世界设定与蓝图降级警告",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.WorldSetting{}, &models.NarrativeBlueprint{})
+		},
+	},
+	{
+		Version:     11,
+		Description: "世界设定构建档位",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.WorldSetting{})
+		},
+	},
+	{
+		Version:     12,
+		Description: "世界实体索引",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.WorldSetting{})
+		},
+	},
+	{
+		Version:     13,
+		Description: "可编辑角色卡",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.CharacterSheet{})
+		},
+	},
+	{
+		Version:     14,
+		Description: "角色立绘提示词",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Character{})
+		},
+	},
+	{
+		Version:     15,
+		Description: "龙套角色池",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.WorldSetting{})
+		},
+	},
+	{
+		Version:     16,
+		Description: "章节钩子评分",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Chapter{})
+		},
+	},
+	{
+		Version:     17,
+		Description: "连载更新计划",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.ReleasePlan{})
+		},
+	},
+	{
+		Version:     18,
+		Description: "排行榜快照",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.RankSnapshot{})
+		},
+	},
+	{
+		Version:     19,
+		Description: "竞品分析",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.CompetitorAnalysis{})
+		},
+	},
+	{
+		Version:     20,
+		Description: "项目套路检测配置",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Project{})
+		},
+	},
+	{
+		Version:     21,
+		Description: "项目内容合规配置",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Project{})
+		},
+	},
+	{
+		Version:     22,
+		Description: "世界术语表",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.WorldSetting{})
+		},
+	},
+	{
+		Version:     23,
+		Description: "项目输出语言",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Project{})
+		},
+	},
+	{
+		Version:     24,
+		Description: "章节译文",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Project{}, &models.ChapterTranslation{})
+		},
+	},
+	{
+		Version:     25,
+		Description: "蓝图假设分支",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.BlueprintBranch{})
+		},
+	},
+	{
+		Version:     26,
+		Description: "蓝图结局候选",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.NarrativeBlueprint{})
+		},
+	},
+	{
+		Version:     27,
+		Description: "章节标题候选",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Chapter{})
+		},
+	},
+	{
+		Version:     28,
+		Description: "营销文案",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.MarketingCopy{})
+		},
+	},
+	{
+		Version:     29,
+		Description: "实体提及",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.EntityMention{})
+		},
+	},
+	{
+		Version:     30,
+		Description: "连续性事实",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.ContinuityFact{}, &models.Chapter{})
+		},
+	},
+	{
+		Version:     31,
+		Description: "生死伤势台账",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.VitalEvent{})
+		},
+	},
+	{
+		Version:     32,
+		Description: "关键物品",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Item{}, &models.ItemTransfer{})
+		},
+	},
+	{
+		Version:     33,
+		Description: "修为境界",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.RealmEvent{})
+		},
+	},
+	{
+		Version:     34,
+		Description: "蓝图感情线节拍",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.NarrativeBlueprint{})
+		},
+	},
+	{
+		Version:     35,
+		Description: "章节主题覆盖",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Chapter{})
+		},
+	},
+	{
+		Version:     36,
+		Description: "自定义节拍表",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.NarrativeTemplate{})
+		},
+	},
+	{
+		Version:     37,
+		Description: "叙事模板版本",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.NarrativeTemplate{})
+		},
+	},
+	{
+		Version:     38,
+		Description: "定时任务",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.CronJob{}, &models.CronRun{})
+		},
+	},
+	{
+		Version:     39,
+		Description: "Webhook",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Webhook{}, &models.WebhookDelivery{})
+		},
+	},
+	{
+		Version:     40,
+		Description: "通知渠道",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.NotificationChannel{})
+		},
+	},
+	{
+		Version:     41,
+		Description: "LLM每日用量",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.LLMUsageDay{})
+		},
+	},
+	{
+		Version:     42,
+		Description: "用户预算",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.UserQuota{}, &models.UserUsageMonth{})
+		},
+	},
+	{
+		Version:     43,
+		Description: "API密钥",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.APIKey{}, &models.APIKeyAudit{})
+		},
+	},
+	{
+		Version:     44,
+		Description: "内容变更审计",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.AuditLog{})
+		},
+	},
+	{
+		Version:     45,
+		Description: "回收站（软删除）",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Project{}, &models.WorldSetting{}, &models.NarrativeBlueprint{}, &models.Chapter{})
+		},
+	},
+	{
+		Version:     46,
+		Description: "少样本示例库",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.PromptExample{})
+		},
+	},
+	{
+		Version:     47,
+		Description: "修改建议",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.EditSuggestion{})
+		},
+	},
+	{
+		Version:     48,
+		Description: "章节批注",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Annotation{}, &models.AnnotationReply{}, &models.EditSuggestion{})
+		},
+	},
+	{
+		Version:     49,
+		Description: "章节文风指标",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Chapter{})
+		},
+	},
+	{
+		Version:     50,
+		Description: "章节近似重复段落",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.Chapter{})
+		},
+	},
+	{
+		Version:     51,
+		Description: "原创性参考文本",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.ReferenceText{}, &models.Project{})
+		},
+	},
+	{
+		Version:     52,
+		Description: "写作统计",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.WritingDay{})
+		},
+	},
+	{
+		Version:     53,
+		Description: "书稿导入",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.ManuscriptImport{})
+		},
+	},
+	{
+		Version:     54,
+		Description: "世界设定行版本号",
+		Up: func(tx *gorm.DB) error {
+			return tx.AutoMigrate(&models.WorldSetting{})
+		},
+	},
+}
+
+// Migrations 返回全部迁移（按版本排序）
+func Migrations() []Migration {
+	list := make([]Migration, len(migrations))
+	copy(list, migrations)
+	sort.Slice(list, func(i, j int) bool { return list[i].Version < list[j].Version })
+	return list
+}
+
+// AppliedMigrations 查询已应用的迁移
+func AppliedMigrations(db *gorm.DB) ([]SchemaMigration, error) {
+	if err := db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("创建迁移记录表失败: %w", err)
+	}
+	var applied []SchemaMigration
+	if err := db.Order("version").Find(&applied).Error; err != nil {
+		return nil, err
+	}
+	return applied, nil
+}
+
+// RunMigrations 按版本顺序执行尚未应用的迁移，每个版本一个事务
+func RunMigrations(db *gorm.DB) error {
+	applied, err := AppliedMigrations(db)
+	if err != nil {
+		return err
+	}
+	done := make(map[int]bool, len(applied))
+	for _, m := range applied {
+		done[m.Version] = true
+	}
+
+	for _, m := range Migrations() {
+		if done[m.Version] {
+			continue
+		}
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := m.Up(tx); err != nil {
+				return err
+			}
+			return tx.Create(&SchemaMigration{
+				Version:     m.Version,
+				Description: m.Description,
+				AppliedAt:   time.Now(),
+			}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("迁移 %d (%s) 失败: %w", m.Version, m.Description, err)
+		}
+		log.Printf("已应用迁移 %d: %s", m.Version, m.Description)
+	}
+	return nil
+}
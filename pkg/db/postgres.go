@@ -2,7 +2,9 @@
 package db
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"os"
 	"strconv"
 	"time"
@@ -83,22 +85,9 @@ func NewPostgres(cfg *PostgresConfig) (*PostgresDatabase, error) {
 	return &PostgresDatabase{db: db}, nil
 }
 
-// Migrate 执行数据库迁移
+// Migrate 执行数据库迁移（版本化，记录在schema_migrations表）
 func (p *PostgresDatabase) Migrate() error {
-	return p.db.AutoMigrate(
-		&models.WorldSetting{},
-		&models.Character{},
-		&models.Project{},
-		&models.NarrativeBlueprint{},
-		&models.Chapter{},
-		&models.NarrativeNode{},
-		&models.NodeChapterMapping{},
-		&models.SceneOutput{},
-		&models.User{},
-		&models.SysConfig{},
-		&models.PromptTemplate{},
-		&models.NarrativeTemplate{},
-	)
+	return RunMigrations(p.db)
 }
 
 // Close 关闭数据库连接
@@ -110,6 +99,15 @@ func (p *PostgresDatabase) Close() error {
 	return sqlDB.Close()
 }
 
+// Ping 检查数据库连接是否可用
+func (p *PostgresDatabase) Ping(ctx context.Context) error {
+	sqlDB, err := p.db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
 // GetDB 获取GORM实例
 func (p *PostgresDatabase) GetDB() *gorm.DB {
 	return p.db
@@ -143,6 +141,22 @@ func (p *PostgresDatabase) GetWorld(id string) (*models.WorldSetting, error) {
 	return &world, nil
 }
 
+// SaveWorldIfRevision 按行版本条件保存世界设定
+func (p *PostgresDatabase) SaveWorldIfRevision(world *models.WorldSetting, revision int) error {
+	world.Revision = revision + 1
+	world.UpdatedAt = time.Now()
+	result := p.db.Model(world).Where("revision = ?", revision).Select("*").Updates(world)
+	if result.Error != nil {
+		world.Revision = revision
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		world.Revision = revision
+		return ErrWorldRevisionConflict
+	}
+	return nil
+}
+
 // ListWorlds 列出所有世界设定
 func (p *PostgresDatabase) ListWorlds() []*models.WorldSetting {
 	var worlds []*models.WorldSetting
@@ -150,7 +164,7 @@ func (p *PostgresDatabase) ListWorlds() []*models.WorldSetting {
 	return worlds
 }
 
-// DeleteWorld 删除世界设定
+// DeleteWorld 删除世界设定（软删除）
 func (p *PostgresDatabase) DeleteWorld(id string) error {
 	return p.db.Delete(&models.WorldSetting{}, "id = ?", id).Error
 }
@@ -258,12 +272,22 @@ func (p *PostgresDatabase) ListProjectsByUser(userID string) []*models.Project {
 }
 
 // DeleteProject 删除项目
+//
+// 软删除：项目与其蓝图、章节记录同一删除时间，恢复项目时据此一并恢复；
+// 记忆条目和场景输出在清除回收站时才删除
 func (p *PostgresDatabase) DeleteProject(id string) error {
-	// 级联删除关联的蓝图和场景
-	p.db.Where("project_id = ?", id).Delete(&models.NarrativeBlueprint{})
-	p.db.Where("blueprint_id IN (SELECT id FROM narrative_blueprints WHERE project_id = ?)", id).
-		Delete(&models.SceneOutput{})
-	return p.db.Delete(&models.Project{}, "id = ?", id).Error
+	now := time.Now()
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.NarrativeBlueprint{}).Where("project_id = ?", id).
+			UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.Chapter{}).Where("project_id = ?", id).
+			UpdateColumn("deleted_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.Project{}).Where("id = ?", id).UpdateColumn("deleted_at", now).Error
+	})
 }
 
 // UpdateProjectStatus 更新项目状态
@@ -307,10 +331,8 @@ func (p *PostgresDatabase) ListBlueprints() []*models.NarrativeBlueprint {
 	return blueprints
 }
 
-// DeleteBlueprint 删除叙事蓝图
+// DeleteBlueprint 删除叙事蓝图（软删除，场景输出在清除回收站时删除）
 func (p *PostgresDatabase) DeleteBlueprint(id string) error {
-	// 级联删除场景
-	p.db.Where("blueprint_id = ?", id).Delete(&models.SceneOutput{})
 	return p.db.Delete(&models.NarrativeBlueprint{}, "id = ?", id).Error
 }
 
@@ -493,7 +515,14 @@ func (p *PostgresDatabase) ListNodeChapterMappingsByChapter(chapterID string) []
 
 // SaveChapter 保存章节
 func (p *PostgresDatabase) SaveChapter(chapter *models.Chapter) error {
-	return p.db.Save(chapter).Error
+	chapter.Version++
+	if err := p.db.Save(chapter).Error; err != nil {
+		return err
+	}
+	if err := RecordWritingDay(p.db, chapter); err != nil {
+		slog.Warn("记录写作统计失败", "chapter_id", chapter.ID, "error", err)
+	}
+	return nil
 }
 
 // GetChapter 获取章节
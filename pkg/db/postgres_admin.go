@@ -1,9 +1,13 @@
 package db
 
 import (
+	"fmt"
+	"sort"
 	"time"
 
 	"github.com/xlei/xupu/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // ============================================
@@ -51,6 +55,37 @@ func (p *PostgresDatabase) SavePromptTemplate(prompt *models.PromptTemplate) err
 	return p.db.Save(prompt).Error
 }
 
+func (p *PostgresDatabase) ListPromptExamples(role string) ([]models.PromptExample, error) {
+	var examples []models.PromptExample
+	query := p.db.Order("role, updated_at DESC")
+	if role != "" {
+		query = query.Where("role = ?", role)
+	}
+	err := query.Find(&examples).Error
+	return examples, err
+}
+
+func (p *PostgresDatabase) GetPromptExample(id string) (*models.PromptExample, error) {
+	var example models.PromptExample
+	err := p.db.Where("id = ?", id).First(&example).Error
+	if err != nil {
+		return nil, err
+	}
+	return &example, nil
+}
+
+func (p *PostgresDatabase) SavePromptExample(example *models.PromptExample) error {
+	example.UpdatedAt = time.Now()
+	if example.CreatedAt.IsZero() {
+		example.CreatedAt = time.Now()
+	}
+	return p.db.Save(example).Error
+}
+
+func (p *PostgresDatabase) DeletePromptExample(id string) error {
+	return p.db.Delete(&models.PromptExample{}, "id = ?", id).Error
+}
+
 func (p *PostgresDatabase) GetNarrativeTemplates() ([]models.NarrativeTemplate, error) {
 	var templates []models.NarrativeTemplate
 	err := p.db.Find(&templates).Error
@@ -73,3 +108,1208 @@ func (p *PostgresDatabase) SaveNarrativeTemplate(template *models.NarrativeTempl
 	}
 	return p.db.Save(template).Error
 }
+
+func (p *PostgresDatabase) ListUserNarrativeTemplates(userID string) ([]models.NarrativeTemplate, error) {
+	var templates []models.NarrativeTemplate
+	err := p.db.Where("user_id = ?", userID).Order("updated_at DESC").Find(&templates).Error
+	return templates, err
+}
+
+func (p *PostgresDatabase) DeleteNarrativeTemplate(id string) error {
+	return p.db.Delete(&models.NarrativeTemplate{}, "id = ?", id).Error
+}
+
+func (p *PostgresDatabase) GetPromptExperiments() ([]models.PromptExperiment, error) {
+	var experiments []models.PromptExperiment
+	err := p.db.Order("created_at DESC").Find(&experiments).Error
+	return experiments, err
+}
+
+func (p *PostgresDatabase) GetPromptExperiment(id string) (*models.PromptExperiment, error) {
+	var experiment models.PromptExperiment
+	err := p.db.Where("id = ?", id).First(&experiment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+func (p *PostgresDatabase) SavePromptExperiment(experiment *models.PromptExperiment) error {
+	experiment.UpdatedAt = time.Now()
+	if experiment.CreatedAt.IsZero() {
+		experiment.CreatedAt = time.Now()
+	}
+	return p.db.Save(experiment).Error
+}
+
+func (p *PostgresDatabase) ListStyleProfiles(userID string) ([]models.StyleProfile, error) {
+	var profiles []models.StyleProfile
+	err := p.db.Where("user_id = ?", userID).Order("updated_at DESC").Find(&profiles).Error
+	return profiles, err
+}
+
+func (p *PostgresDatabase) GetStyleProfile(id string) (*models.StyleProfile, error) {
+	var profile models.StyleProfile
+	err := p.db.Where("id = ?", id).First(&profile).Error
+	if err != nil {
+		return nil, err
+	}
+	return &profile, nil
+}
+
+func (p *PostgresDatabase) SaveStyleProfile(profile *models.StyleProfile) error {
+	profile.UpdatedAt = time.Now()
+	if profile.CreatedAt.IsZero() {
+		profile.CreatedAt = time.Now()
+	}
+	return p.db.Save(profile).Error
+}
+
+func (p *PostgresDatabase) DeleteStyleProfile(id string) error {
+	return p.db.Delete(&models.StyleProfile{}, "id = ?", id).Error
+}
+
+func (p *PostgresDatabase) ListMemoryEntries(blueprintID string) ([]models.MemoryEntry, error) {
+	var entries []models.MemoryEntry
+	err := p.db.Where("blueprint_id = ?", blueprintID).Order("chapter, id").Find(&entries).Error
+	return entries, err
+}
+
+func (p *PostgresDatabase) SaveMemoryEntries(entries []models.MemoryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+	now := time.Now()
+	for i := range entries {
+		entries[i].UpdatedAt = now
+		if entries[i].CreatedAt.IsZero() {
+			entries[i].CreatedAt = now
+		}
+	}
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		for i := range entries {
+			if err := tx.Save(&entries[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (p *PostgresDatabase) DeleteMemoryEntries(blueprintID string) error {
+	return p.db.Delete(&models.MemoryEntry{}, "blueprint_id = ?", blueprintID).Error
+}
+
+func (p *PostgresDatabase) ListCharacterSheets(worldID string) ([]models.CharacterSheet, error) {
+	var sheets []models.CharacterSheet
+	err := p.db.Where("world_id = ?", worldID).Order("created_at, id").Find(&sheets).Error
+	return sheets, err
+}
+
+func (p *PostgresDatabase) GetCharacterSheet(id string) (*models.CharacterSheet, error) {
+	var sheet models.CharacterSheet
+	err := p.db.Where("id = ?", id).First(&sheet).Error
+	if err != nil {
+		return nil, err
+	}
+	return &sheet, nil
+}
+
+func (p *PostgresDatabase) SaveCharacterSheet(sheet *models.CharacterSheet) error {
+	sheet.UpdatedAt = time.Now()
+	if sheet.CreatedAt.IsZero() {
+		sheet.CreatedAt = time.Now()
+	}
+	return p.db.Save(sheet).Error
+}
+
+func (p *PostgresDatabase) DeleteCharacterSheet(id string) error {
+	return p.db.Delete(&models.CharacterSheet{}, "id = ?", id).Error
+}
+
+func (p *PostgresDatabase) GetReleasePlan(projectID string) (*models.ReleasePlan, error) {
+	var plan models.ReleasePlan
+	err := p.db.Where("project_id = ?", projectID).First(&plan).Error
+	if err != nil {
+		return nil, err
+	}
+	return &plan, nil
+}
+
+func (p *PostgresDatabase) SaveReleasePlan(plan *models.ReleasePlan) error {
+	plan.UpdatedAt = time.Now()
+	if plan.CreatedAt.IsZero() {
+		plan.CreatedAt = time.Now()
+	}
+	return p.db.Save(plan).Error
+}
+
+func (p *PostgresDatabase) SaveRankSnapshot(snapshot *models.RankSnapshot) error {
+	if snapshot.TakenAt.IsZero() {
+		snapshot.TakenAt = time.Now()
+	}
+	return p.db.Save(snapshot).Error
+}
+
+func (p *PostgresDatabase) ListRankSnapshots(source string, since time.Time) ([]models.RankSnapshot, error) {
+	var snapshots []models.RankSnapshot
+	err := p.db.Where("source = ? AND taken_at >= ?", source, since).Order("taken_at asc").Find(&snapshots).Error
+	return snapshots, err
+}
+
+func (p *PostgresDatabase) ListCompetitorAnalyses(projectID string) ([]models.CompetitorAnalysis, error) {
+	var analyses []models.CompetitorAnalysis
+	err := p.db.Where("project_id = ?", projectID).Order("created_at desc").Find(&analyses).Error
+	return analyses, err
+}
+
+func (p *PostgresDatabase) GetCompetitorAnalysis(id string) (*models.CompetitorAnalysis, error) {
+	var analysis models.CompetitorAnalysis
+	err := p.db.Where("id = ?", id).First(&analysis).Error
+	if err != nil {
+		return nil, err
+	}
+	return &analysis, nil
+}
+
+func (p *PostgresDatabase) SaveCompetitorAnalysis(analysis *models.CompetitorAnalysis) error {
+	if analysis.CreatedAt.IsZero() {
+		analysis.CreatedAt = time.Now()
+	}
+	return p.db.Save(analysis).Error
+}
+
+func (p *PostgresDatabase) DeleteCompetitorAnalysis(id string) error {
+	return p.db.Delete(&models.CompetitorAnalysis{}, "id = ?", id).Error
+}
+
+func (p *PostgresDatabase) ListChapterTranslations(projectID, language string) ([]models.ChapterTranslation, error) {
+	var translations []models.ChapterTranslation
+	err := p.db.Where("project_id = ? AND language = ?", projectID, language).Order("chapter_num asc").Find(&translations).Error
+	return translations, err
+}
+
+func (p *PostgresDatabase) GetChapterTranslation(chapterID, language string) (*models.ChapterTranslation, error) {
+	var translation models.ChapterTranslation
+	err := p.db.Where("chapter_id = ? AND language = ?", chapterID, language).First(&translation).Error
+	if err != nil {
+		return nil, err
+	}
+	return &translation, nil
+}
+
+func (p *PostgresDatabase) SaveChapterTranslation(translation *models.ChapterTranslation) error {
+	translation.UpdatedAt = time.Now()
+	if translation.CreatedAt.IsZero() {
+		translation.CreatedAt = translation.UpdatedAt
+	}
+	return p.db.Save(translation).Error
+}
+
+func (p *PostgresDatabase) ListBlueprintBranches(blueprintID string) ([]models.BlueprintBranch, error) {
+	var branches []models.BlueprintBranch
+	err := p.db.Where("blueprint_id = ?", blueprintID).Order("created_at desc").Find(&branches).Error
+	return branches, err
+}
+
+func (p *PostgresDatabase) GetBlueprintBranch(id string) (*models.BlueprintBranch, error) {
+	var branch models.BlueprintBranch
+	if err := p.db.Where("id = ?", id).First(&branch).Error; err != nil {
+		return nil, err
+	}
+	return &branch, nil
+}
+
+func (p *PostgresDatabase) SaveBlueprintBranch(branch *models.BlueprintBranch) error {
+	branch.UpdatedAt = time.Now()
+	if branch.CreatedAt.IsZero() {
+		branch.CreatedAt = branch.UpdatedAt
+	}
+	return p.db.Save(branch).Error
+}
+
+func (p *PostgresDatabase) GetMarketingCopy(projectID string) (*models.MarketingCopy, error) {
+	var marketing models.MarketingCopy
+	if err := p.db.Where("project_id = ?", projectID).First(&marketing).Error; err != nil {
+		return nil, err
+	}
+	return &marketing, nil
+}
+
+func (p *PostgresDatabase) SaveMarketingCopy(marketing *models.MarketingCopy) error {
+	marketing.UpdatedAt = time.Now()
+	if marketing.CreatedAt.IsZero() {
+		marketing.CreatedAt = marketing.UpdatedAt
+	}
+	return p.db.Save(marketing).Error
+}
+
+func (p *PostgresDatabase) ListEntityMentions(projectID string) ([]models.EntityMention, error) {
+	var mentions []models.EntityMention
+	err := p.db.Where("project_id = ?", projectID).Order("chapter_num, first_offset").Find(&mentions).Error
+	return mentions, err
+}
+
+// ReplaceChapterMentions 替换某一章的全部提及记录
+func (p *PostgresDatabase) ReplaceChapterMentions(projectID string, chapterNum int, mentions []models.EntityMention) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ? AND chapter_num = ?", projectID, chapterNum).Delete(&models.EntityMention{}).Error; err != nil {
+			return err
+		}
+		if len(mentions) == 0 {
+			return nil
+		}
+		now := time.Now()
+		for i := range mentions {
+			mentions[i].ProjectID = projectID
+			mentions[i].ChapterNum = chapterNum
+			if mentions[i].CreatedAt.IsZero() {
+				mentions[i].CreatedAt = now
+			}
+		}
+		return tx.Create(&mentions).Error
+	})
+}
+
+func (p *PostgresDatabase) ListContinuityFacts(projectID string) ([]models.ContinuityFact, error) {
+	var facts []models.ContinuityFact
+	err := p.db.Where("project_id = ?", projectID).Order("chapter, subject, attribute").Find(&facts).Error
+	return facts, err
+}
+
+func (p *PostgresDatabase) GetContinuityFact(id string) (*models.ContinuityFact, error) {
+	var fact models.ContinuityFact
+	if err := p.db.Where("id = ?", id).First(&fact).Error; err != nil {
+		return nil, err
+	}
+	return &fact, nil
+}
+
+func (p *PostgresDatabase) SaveContinuityFact(fact *models.ContinuityFact) error {
+	fact.UpdatedAt = time.Now()
+	if fact.CreatedAt.IsZero() {
+		fact.CreatedAt = fact.UpdatedAt
+	}
+	return p.db.Save(fact).Error
+}
+
+func (p *PostgresDatabase) DeleteContinuityFact(id string) error {
+	return p.db.Where("id = ?", id).Delete(&models.ContinuityFact{}).Error
+}
+
+// ResetChapterContinuity 撤销某一章对事实库的修改：删除该章从正文提取的事实，恢复被该章取代的事实
+func (p *PostgresDatabase) ResetChapterContinuity(projectID string, chapter int) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ? AND chapter = ? AND source = ?", projectID, chapter, models.FactFromProse).
+			Delete(&models.ContinuityFact{}).Error; err != nil {
+			return err
+		}
+		return tx.Model(&models.ContinuityFact{}).
+			Where("project_id = ? AND ended_chapter = ?", projectID, chapter).
+			Update("ended_chapter", 0).Error
+	})
+}
+
+func (p *PostgresDatabase) ListVitalEvents(projectID string) ([]models.VitalEvent, error) {
+	var events []models.VitalEvent
+	err := p.db.Where("project_id = ?", projectID).Order("chapter, created_at").Find(&events).Error
+	return events, err
+}
+
+func (p *PostgresDatabase) GetVitalEvent(id string) (*models.VitalEvent, error) {
+	var event models.VitalEvent
+	if err := p.db.Where("id = ?", id).First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (p *PostgresDatabase) SaveVitalEvent(event *models.VitalEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	return p.db.Save(event).Error
+}
+
+func (p *PostgresDatabase) DeleteVitalEvent(id string) error {
+	return p.db.Where("id = ?", id).Delete(&models.VitalEvent{}).Error
+}
+
+// ReplaceChapterVitalEvents 替换某一章从正文提取的台账记录，手工登记的记录保留
+func (p *PostgresDatabase) ReplaceChapterVitalEvents(projectID string, chapter int, events []models.VitalEvent) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ? AND chapter = ? AND source = ?", projectID, chapter, models.VitalFromProse).
+			Delete(&models.VitalEvent{}).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		now := time.Now()
+		for i := range events {
+			if events[i].CreatedAt.IsZero() {
+				events[i].CreatedAt = now
+			}
+		}
+		return tx.Create(&events).Error
+	})
+}
+
+func (p *PostgresDatabase) ListItems(projectID string) ([]models.Item, error) {
+	var items []models.Item
+	err := p.db.Where("project_id = ?", projectID).Order("chapter, name").Find(&items).Error
+	return items, err
+}
+
+func (p *PostgresDatabase) GetItem(id string) (*models.Item, error) {
+	var item models.Item
+	if err := p.db.Where("id = ?", id).First(&item).Error; err != nil {
+		return nil, err
+	}
+	return &item, nil
+}
+
+func (p *PostgresDatabase) SaveItem(item *models.Item) error {
+	item.UpdatedAt = time.Now()
+	if item.CreatedAt.IsZero() {
+		item.CreatedAt = item.UpdatedAt
+	}
+	return p.db.Save(item).Error
+}
+
+// DeleteItem 删除物品及其去向记录
+func (p *PostgresDatabase) DeleteItem(id string) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("item_id = ?", id).Delete(&models.ItemTransfer{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("id = ?", id).Delete(&models.Item{}).Error
+	})
+}
+
+func (p *PostgresDatabase) ListItemTransfers(projectID string) ([]models.ItemTransfer, error) {
+	var transfers []models.ItemTransfer
+	err := p.db.Where("project_id = ?", projectID).Order("chapter, created_at").Find(&transfers).Error
+	return transfers, err
+}
+
+func (p *PostgresDatabase) SaveItemTransfer(transfer *models.ItemTransfer) error {
+	if transfer.CreatedAt.IsZero() {
+		transfer.CreatedAt = time.Now()
+	}
+	return p.db.Save(transfer).Error
+}
+
+func (p *PostgresDatabase) DeleteItemTransfer(id string) error {
+	return p.db.Where("id = ?", id).Delete(&models.ItemTransfer{}).Error
+}
+
+// ReplaceChapterItemTransfers 替换某一章从正文提取的去向记录，手工登记的记录保留
+func (p *PostgresDatabase) ReplaceChapterItemTransfers(projectID string, chapter int, transfers []models.ItemTransfer) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ? AND chapter = ? AND source = ?", projectID, chapter, models.ItemFromProse).
+			Delete(&models.ItemTransfer{}).Error; err != nil {
+			return err
+		}
+		if len(transfers) == 0 {
+			return nil
+		}
+		now := time.Now()
+		for i := range transfers {
+			if transfers[i].CreatedAt.IsZero() {
+				transfers[i].CreatedAt = now
+			}
+		}
+		return tx.Create(&transfers).Error
+	})
+}
+
+func (p *PostgresDatabase) ListRealmEvents(projectID string) ([]models.RealmEvent, error) {
+	var events []models.RealmEvent
+	err := p.db.Where("project_id = ?", projectID).Order("chapter, created_at").Find(&events).Error
+	return events, err
+}
+
+func (p *PostgresDatabase) GetRealmEvent(id string) (*models.RealmEvent, error) {
+	var event models.RealmEvent
+	if err := p.db.Where("id = ?", id).First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (p *PostgresDatabase) SaveRealmEvent(event *models.RealmEvent) error {
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	return p.db.Save(event).Error
+}
+
+func (p *PostgresDatabase) DeleteRealmEvent(id string) error {
+	return p.db.Where("id = ?", id).Delete(&models.RealmEvent{}).Error
+}
+
+// ReplaceChapterRealmEvents 替换某一章从正文提取的境界记录，手工登记的记录保留
+func (p *PostgresDatabase) ReplaceChapterRealmEvents(projectID string, chapter int, events []models.RealmEvent) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("project_id = ? AND chapter = ? AND source = ?", projectID, chapter, models.RealmFromProse).
+			Delete(&models.RealmEvent{}).Error; err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		now := time.Now()
+		for i := range events {
+			if events[i].CreatedAt.IsZero() {
+				events[i].CreatedAt = now
+			}
+		}
+		return tx.Create(&events).Error
+	})
+}
+
+func (p *PostgresDatabase) ListEditSuggestions(chapterID, status string) ([]models.EditSuggestion, error) {
+	var suggestions []models.EditSuggestion
+	query := p.db.Where("chapter_id = ?", chapterID)
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Order("created_at DESC").Find(&suggestions).Error
+	return suggestions, err
+}
+
+func (p *PostgresDatabase) GetEditSuggestion(id string) (*models.EditSuggestion, error) {
+	var suggestion models.EditSuggestion
+	if err := p.db.Where("id = ?", id).First(&suggestion).Error; err != nil {
+		return nil, err
+	}
+	return &suggestion, nil
+}
+
+func (p *PostgresDatabase) SaveEditSuggestion(suggestion *models.EditSuggestion) error {
+	suggestion.UpdatedAt = time.Now()
+	if suggestion.CreatedAt.IsZero() {
+		suggestion.CreatedAt = suggestion.UpdatedAt
+	}
+	return p.db.Save(suggestion).Error
+}
+
+func (p *PostgresDatabase) ListAnnotations(chapterID string, resolved *bool) ([]models.Annotation, error) {
+	var annotations []models.Annotation
+	query := p.db.Where("chapter_id = ?", chapterID)
+	if resolved != nil {
+		query = query.Where("resolved = ?", *resolved)
+	}
+	err := query.Order("start ASC, created_at ASC").Find(&annotations).Error
+	return annotations, err
+}
+
+func (p *PostgresDatabase) GetAnnotation(id string) (*models.Annotation, error) {
+	var annotation models.Annotation
+	if err := p.db.Where("id = ?", id).First(&annotation).Error; err != nil {
+		return nil, err
+	}
+	return &annotation, nil
+}
+
+func (p *PostgresDatabase) SaveAnnotation(annotation *models.Annotation) error {
+	annotation.UpdatedAt = time.Now()
+	if annotation.CreatedAt.IsZero() {
+		annotation.CreatedAt = annotation.UpdatedAt
+	}
+	return p.db.Save(annotation).Error
+}
+
+func (p *PostgresDatabase) DeleteAnnotation(id string) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Delete(&models.AnnotationReply{}, "annotation_id = ?", id).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Annotation{}, "id = ?", id).Error
+	})
+}
+
+func (p *PostgresDatabase) ListAnnotationReplies(annotationIDs []string) ([]models.AnnotationReply, error) {
+	var replies []models.AnnotationReply
+	if len(annotationIDs) == 0 {
+		return replies, nil
+	}
+	err := p.db.Where("annotation_id IN ?", annotationIDs).Order("created_at ASC").Find(&replies).Error
+	return replies, err
+}
+
+func (p *PostgresDatabase) SaveAnnotationReply(reply *models.AnnotationReply) error {
+	if reply.CreatedAt.IsZero() {
+		reply.CreatedAt = time.Now()
+	}
+	return p.db.Save(reply).Error
+}
+
+func (p *PostgresDatabase) ListReferenceTexts(projectID string) ([]models.ReferenceText, error) {
+	var refs []models.ReferenceText
+	err := p.db.Where("project_id = ?", projectID).Order("created_at ASC").Find(&refs).Error
+	return refs, err
+}
+
+func (p *PostgresDatabase) GetReferenceText(id string) (*models.ReferenceText, error) {
+	var ref models.ReferenceText
+	if err := p.db.Where("id = ?", id).First(&ref).Error; err != nil {
+		return nil, err
+	}
+	return &ref, nil
+}
+
+func (p *PostgresDatabase) SaveReferenceText(ref *models.ReferenceText) error {
+	ref.UpdatedAt = time.Now()
+	if ref.CreatedAt.IsZero() {
+		ref.CreatedAt = ref.UpdatedAt
+	}
+	return p.db.Save(ref).Error
+}
+
+func (p *PostgresDatabase) DeleteReferenceText(id string) error {
+	return p.db.Delete(&models.ReferenceText{}, "id = ?", id).Error
+}
+
+func (p *PostgresDatabase) ListWritingDays(projectID, since string) ([]models.WritingDay, error) {
+	var days []models.WritingDay
+	query := p.db.Where("project_id = ?", projectID)
+	if since != "" {
+		query = query.Where("date >= ?", since)
+	}
+	err := query.Order("date ASC, chapter_num ASC").Find(&days).Error
+	return days, err
+}
+
+func (p *PostgresDatabase) ListManuscriptImports(projectID string) ([]models.ManuscriptImport, error) {
+	var imports []models.ManuscriptImport
+	err := p.db.Where("project_id = ?", projectID).Order("created_at desc").Find(&imports).Error
+	return imports, err
+}
+
+func (p *PostgresDatabase) GetManuscriptImport(id string) (*models.ManuscriptImport, error) {
+	var imp models.ManuscriptImport
+	if err := p.db.Where("id = ?", id).First(&imp).Error; err != nil {
+		return nil, err
+	}
+	return &imp, nil
+}
+
+func (p *PostgresDatabase) SaveManuscriptImport(imp *models.ManuscriptImport) error {
+	imp.UpdatedAt = time.Now()
+	if imp.CreatedAt.IsZero() {
+		imp.CreatedAt = imp.UpdatedAt
+	}
+	return p.db.Save(imp).Error
+}
+
+func (p *PostgresDatabase) ListCronJobs() ([]models.CronJob, error) {
+	var jobs []models.CronJob
+	err := p.db.Order("created_at asc").Find(&jobs).Error
+	return jobs, err
+}
+
+func (p *PostgresDatabase) GetCronJob(id string) (*models.CronJob, error) {
+	var job models.CronJob
+	if err := p.db.First(&job, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (p *PostgresDatabase) SaveCronJob(job *models.CronJob) error {
+	job.UpdatedAt = time.Now()
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = job.UpdatedAt
+	}
+	return p.db.Save(job).Error
+}
+
+func (p *PostgresDatabase) DeleteCronJob(id string) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("job_id = ?", id).Delete(&models.CronRun{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.CronJob{}, "id = ?", id).Error
+	})
+}
+
+// ListCronRuns 定时任务的执行记录，新的在前；limit 不大于0时不限制条数
+func (p *PostgresDatabase) ListCronRuns(jobID string, limit int) ([]models.CronRun, error) {
+	var runs []models.CronRun
+	query := p.db.Where("job_id = ?", jobID).Order("started_at desc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&runs).Error
+	return runs, err
+}
+
+func (p *PostgresDatabase) SaveCronRun(run *models.CronRun) error {
+	if run.StartedAt.IsZero() {
+		run.StartedAt = time.Now()
+	}
+	return p.db.Save(run).Error
+}
+
+func (p *PostgresDatabase) ListWebhooks(projectID string) ([]models.Webhook, error) {
+	var hooks []models.Webhook
+	err := p.db.Where("project_id = ?", projectID).Order("created_at asc").Find(&hooks).Error
+	return hooks, err
+}
+
+func (p *PostgresDatabase) GetWebhook(id string) (*models.Webhook, error) {
+	var hook models.Webhook
+	if err := p.db.First(&hook, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &hook, nil
+}
+
+func (p *PostgresDatabase) SaveWebhook(hook *models.Webhook) error {
+	hook.UpdatedAt = time.Now()
+	if hook.CreatedAt.IsZero() {
+		hook.CreatedAt = hook.UpdatedAt
+	}
+	return p.db.Save(hook).Error
+}
+
+func (p *PostgresDatabase) DeleteWebhook(id string) error {
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("webhook_id = ?", id).Delete(&models.WebhookDelivery{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Webhook{}, "id = ?", id).Error
+	})
+}
+
+// ListWebhookDeliveries Webhook 的投递记录，新的在前；limit 不大于0时不限制条数
+func (p *PostgresDatabase) ListWebhookDeliveries(webhookID string, limit int) ([]models.WebhookDelivery, error) {
+	var deliveries []models.WebhookDelivery
+	query := p.db.Where("webhook_id = ?", webhookID).Order("created_at desc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (p *PostgresDatabase) GetWebhookDelivery(id string) (*models.WebhookDelivery, error) {
+	var delivery models.WebhookDelivery
+	if err := p.db.First(&delivery, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &delivery, nil
+}
+
+func (p *PostgresDatabase) SaveWebhookDelivery(delivery *models.WebhookDelivery) error {
+	if delivery.CreatedAt.IsZero() {
+		delivery.CreatedAt = time.Now()
+	}
+	return p.db.Save(delivery).Error
+}
+
+func (p *PostgresDatabase) ListNotificationChannels(userID string) ([]models.NotificationChannel, error) {
+	var channels []models.NotificationChannel
+	err := p.db.Where("user_id = ?", userID).Order("created_at asc").Find(&channels).Error
+	return channels, err
+}
+
+func (p *PostgresDatabase) GetNotificationChannel(id string) (*models.NotificationChannel, error) {
+	var ch models.NotificationChannel
+	if err := p.db.First(&ch, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
+
+func (p *PostgresDatabase) SaveNotificationChannel(ch *models.NotificationChannel) error {
+	ch.UpdatedAt = time.Now()
+	if ch.CreatedAt.IsZero() {
+		ch.CreatedAt = ch.UpdatedAt
+	}
+	return p.db.Save(ch).Error
+}
+
+func (p *PostgresDatabase) DeleteNotificationChannel(id string) error {
+	return p.db.Delete(&models.NotificationChannel{}, "id = ?", id).Error
+}
+
+// CountUsers 用户总数和活跃用户数：活跃指 activeSince 之后登录过或更新过项目
+func (p *PostgresDatabase) CountUsers(activeSince time.Time) (int, int, error) {
+	var total, active int64
+	if err := p.db.Model(&models.User{}).Count(&total).Error; err != nil {
+		return 0, 0, err
+	}
+	recent := p.db.Model(&models.Project{}).Select("user_id").Where("updated_at >= ?", activeSince)
+	err := p.db.Model(&models.User{}).
+		Where("last_login_at >= ? OR id IN (?)", activeSince, recent).
+		Count(&active).Error
+	return int(total), int(active), err
+}
+
+func (p *PostgresDatabase) CountProjectsByStatus() (map[string]int, error) {
+	var rows []struct {
+		Status string
+		Count  int
+	}
+	err := p.db.Model(&models.Project{}).Select("status, COUNT(*) AS count").Group("status").Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, r := range rows {
+		counts[r.Status] = r.Count
+	}
+	return counts, nil
+}
+
+// AddLLMUsage 把一次用量累加到当天、提供商、模型对应的行上
+func (p *PostgresDatabase) AddLLMUsage(usage *models.LLMUsageDay) error {
+	usage.UpdatedAt = time.Now()
+	return p.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "date"}, {Name: "provider"}, {Name: "model"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests":      gorm.Expr("llm_usage_days.requests + excluded.requests"),
+			"input_tokens":  gorm.Expr("llm_usage_days.input_tokens + excluded.input_tokens"),
+			"output_tokens": gorm.Expr("llm_usage_days.output_tokens + excluded.output_tokens"),
+			"total_tokens":  gorm.Expr("llm_usage_days.total_tokens + excluded.total_tokens"),
+			"cost":          gorm.Expr("llm_usage_days.cost + excluded.cost"),
+			"updated_at":    gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(usage).Error
+}
+
+// ListLLMUsage sinceDate（YYYY-MM-DD，含当天）以来的每日用量，按日期升序
+func (p *PostgresDatabase) ListLLMUsage(sinceDate string) ([]models.LLMUsageDay, error) {
+	var days []models.LLMUsageDay
+	err := p.db.Where("date >= ?", sinceDate).Order("date asc, provider asc, model asc").Find(&days).Error
+	return days, err
+}
+
+// StorageUsage 数据库文件大小和各表行数；PostgreSQL 另外给出各表含索引的占用
+func (p *PostgresDatabase) StorageUsage() (*models.StorageUsage, error) {
+	usage := &models.StorageUsage{Driver: p.db.Dialector.Name()}
+
+	if usage.Driver == "postgres" {
+		if err := p.db.Raw("SELECT pg_database_size(current_database())").Scan(&usage.DatabaseBytes).Error; err != nil {
+			return nil, err
+		}
+		err := p.db.Raw(`SELECT relname AS name, n_live_tup AS rows, pg_total_relation_size(relid) AS bytes
+			FROM pg_stat_user_tables ORDER BY bytes DESC`).Scan(&usage.Tables).Error
+		if err != nil {
+			return nil, err
+		}
+		return usage, nil
+	}
+
+	var pageCount, pageSize int64
+	p.db.Raw("PRAGMA page_count").Scan(&pageCount)
+	p.db.Raw("PRAGMA page_size").Scan(&pageSize)
+	usage.DatabaseBytes = pageCount * pageSize
+
+	tables, err := p.db.Migrator().GetTables()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range tables {
+		var rows int64
+		if err := p.db.Table(name).Count(&rows).Error; err != nil {
+			return nil, err
+		}
+		usage.Tables = append(usage.Tables, models.TableUsage{Name: name, Rows: rows})
+	}
+	sort.Slice(usage.Tables, func(i, j int) bool { return usage.Tables[i].Rows > usage.Tables[j].Rows })
+	return usage, nil
+}
+
+// GetUserQuota 用户的预算设置，每次LLM请求前都会查询，未设置是常态，不走 First 以免刷屏记录未找到
+func (p *PostgresDatabase) GetUserQuota(userID string) (*models.UserQuota, error) {
+	var quotas []models.UserQuota
+	if err := p.db.Where("user_id = ?", userID).Limit(1).Find(&quotas).Error; err != nil {
+		return nil, err
+	}
+	if len(quotas) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &quotas[0], nil
+}
+
+func (p *PostgresDatabase) SaveUserQuota(quota *models.UserQuota) error {
+	quota.UpdatedAt = time.Now()
+	return p.db.Save(quota).Error
+}
+
+func (p *PostgresDatabase) DeleteUserQuota(userID string) error {
+	return p.db.Delete(&models.UserQuota{}, "user_id = ?", userID).Error
+}
+
+// GetUserUsage 用户某月的累计用量，当月尚无用量时返回零值记录
+func (p *PostgresDatabase) GetUserUsage(userID, month string) (*models.UserUsageMonth, error) {
+	var usage models.UserUsageMonth
+	err := p.db.Where("user_id = ? AND month = ?", userID, month).Limit(1).Find(&usage).Error
+	if err != nil {
+		return nil, err
+	}
+	usage.UserID = userID
+	usage.Month = month
+	return &usage, nil
+}
+
+// AddUserUsage 把一次用量累加到用户当月的行上
+func (p *PostgresDatabase) AddUserUsage(usage *models.UserUsageMonth) error {
+	usage.UpdatedAt = time.Now()
+	return p.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "month"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{
+			"requests":      gorm.Expr("user_usage_months.requests + excluded.requests"),
+			"input_tokens":  gorm.Expr("user_usage_months.input_tokens + excluded.input_tokens"),
+			"output_tokens": gorm.Expr("user_usage_months.output_tokens + excluded.output_tokens"),
+			"total_tokens":  gorm.Expr("user_usage_months.total_tokens + excluded.total_tokens"),
+			"cost":          gorm.Expr("user_usage_months.cost + excluded.cost"),
+			"updated_at":    gorm.Expr("excluded.updated_at"),
+		}),
+	}).Create(usage).Error
+}
+
+// MarkUserUsageNotified 标记当月已发送提醒线（exceeded 为 true 时为达到上限）通知
+// 只有首次标记返回 true，多个并发请求同时越线时只通知一次
+func (p *PostgresDatabase) MarkUserUsageNotified(userID, month string, exceeded bool) (bool, error) {
+	column := "warned_at"
+	if exceeded {
+		column = "exceeded_at"
+	}
+	result := p.db.Model(&models.UserUsageMonth{}).
+		Where("user_id = ? AND month = ? AND "+column+" IS NULL", userID, month).
+		Update(column, time.Now())
+	return result.RowsAffected > 0, result.Error
+}
+
+func (p *PostgresDatabase) ListAPIKeys(userID string) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	err := p.db.Where("user_id = ?", userID).Order("created_at asc").Find(&keys).Error
+	return keys, err
+}
+
+func (p *PostgresDatabase) GetAPIKey(id string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := p.db.First(&key, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (p *PostgresDatabase) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := p.db.First(&key, "key_hash = ?", hash).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (p *PostgresDatabase) SaveAPIKey(key *models.APIKey) error {
+	key.UpdatedAt = time.Now()
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = key.UpdatedAt
+	}
+	return p.db.Save(key).Error
+}
+
+// TouchAPIKey 只更新最近使用时间，避免与并发的吊销等修改互相覆盖
+func (p *PostgresDatabase) TouchAPIKey(id string, at time.Time) error {
+	return p.db.Model(&models.APIKey{}).Where("id = ?", id).UpdateColumn("last_used_at", at).Error
+}
+
+// ListAPIKeyAudits 密钥的请求记录，新的在前；limit 不大于0时不限制条数
+func (p *PostgresDatabase) ListAPIKeyAudits(keyID string, limit int) ([]models.APIKeyAudit, error) {
+	var audits []models.APIKeyAudit
+	query := p.db.Where("key_id = ?", keyID).Order("created_at desc")
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+	err := query.Find(&audits).Error
+	return audits, err
+}
+
+func (p *PostgresDatabase) SaveAPIKeyAudit(audit *models.APIKeyAudit) error {
+	if audit.CreatedAt.IsZero() {
+		audit.CreatedAt = time.Now()
+	}
+	return p.db.Create(audit).Error
+}
+
+// AppendAuditLog 追加一条内容变更记录；审计记录不提供修改和删除
+func (p *PostgresDatabase) AppendAuditLog(entry *models.AuditLog) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	return p.db.Create(entry).Error
+}
+
+// ListAuditLogs 按条件查询变更记录，新的在前；limit 不大于0时不限制条数
+func (p *PostgresDatabase) ListAuditLogs(filter models.AuditFilter) ([]models.AuditLog, error) {
+	query := p.db.Model(&models.AuditLog{})
+	for col, val := range map[string]string{
+		"user_id":       filter.UserID,
+		"action":        filter.Action,
+		"resource_type": filter.ResourceType,
+		"resource_id":   filter.ResourceID,
+		"project_id":    filter.ProjectID,
+	} {
+		if val != "" {
+			query = query.Where(col+" = ?", val)
+		}
+	}
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+	if filter.Until != nil {
+		query = query.Where("created_at < ?", *filter.Until)
+	}
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+	var logs []models.AuditLog
+	err := query.Order("created_at desc").Find(&logs).Error
+	return logs, err
+}
+
+// ============================================
+// Trash
+// ============================================
+
+// trashRows 查询回收站中某类内容，scope 追加过滤条件；蓝图和章节附带所属项目的所有者
+func (p *PostgresDatabase) trashRows(kind string, scope func(*gorm.DB) *gorm.DB) ([]models.TrashItem, error) {
+	query := scope(p.db.Unscoped().Where("deleted_at IS NOT NULL"))
+	var items []models.TrashItem
+	add := func(id, name, projectID, userID string, deletedAt gorm.DeletedAt) {
+		items = append(items, models.TrashItem{
+			Kind:      kind,
+			ID:        id,
+			Name:      name,
+			ProjectID: projectID,
+			UserID:    userID,
+			DeletedAt: deletedAt.Time,
+			PurgeAt:   deletedAt.Time.Add(models.TrashRetention),
+		})
+	}
+
+	switch kind {
+	case models.TrashProject:
+		var rows []models.Project
+		if err := query.Select("id", "name", "user_id", "deleted_at").Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			add(r.ID, r.Name, "", r.UserID, r.DeletedAt)
+		}
+	case models.TrashWorld:
+		var rows []models.WorldSetting
+		if err := query.Select("id", "name", "deleted_at").Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			add(r.ID, r.Name, "", "", r.DeletedAt)
+		}
+	case models.TrashBlueprint:
+		var rows []models.NarrativeBlueprint
+		if err := query.Select("id", "project_id", "deleted_at").Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			add(r.ID, r.ProjectID, r.ProjectID, "", r.DeletedAt)
+		}
+	case models.TrashChapter:
+		var rows []models.Chapter
+		if err := query.Select("id", "project_id", "chapter_num", "title", "deleted_at").Find(&rows).Error; err != nil {
+			return nil, err
+		}
+		for _, r := range rows {
+			add(r.ID, fmt.Sprintf("第%d章 %s", r.ChapterNum, r.Title), r.ProjectID, "", r.DeletedAt)
+		}
+	default:
+		return nil, fmt.Errorf("未知的回收站类型: %s", kind)
+	}
+
+	// 蓝图和章节的所有者取自所属项目（项目可能也在回收站中）
+	owners := make(map[string]string)
+	for _, item := range items {
+		if item.ProjectID != "" {
+			owners[item.ProjectID] = ""
+		}
+	}
+	if len(owners) > 0 {
+		ids := make([]string, 0, len(owners))
+		for id := range owners {
+			ids = append(ids, id)
+		}
+		var projects []models.Project
+		if err := p.db.Unscoped().Select("id", "user_id").Where("id IN ?", ids).Find(&projects).Error; err != nil {
+			return nil, err
+		}
+		for _, pr := range projects {
+			owners[pr.ID] = pr.UserID
+		}
+		for i := range items {
+			if items[i].ProjectID != "" {
+				items[i].UserID = owners[items[i].ProjectID]
+			}
+		}
+	}
+	return items, nil
+}
+
+// ListTrash 回收站内容，新删除的在前；userID 不为空时只列出该用户的项目及其蓝图、章节，世界没有所有者总是列出。
+// 所属项目也在回收站中的蓝图和章节不单独列出
+func (p *PostgresDatabase) ListTrash(userID string) ([]models.TrashItem, error) {
+	owned := func(col string) func(*gorm.DB) *gorm.DB {
+		return func(q *gorm.DB) *gorm.DB {
+			if userID == "" {
+				return q
+			}
+			return q.Where(col+" IN (SELECT id FROM projects WHERE user_id = ?)", userID)
+		}
+	}
+	inLiveProject := func(q *gorm.DB) *gorm.DB {
+		return owned("project_id")(q).Where("project_id NOT IN (SELECT id FROM projects WHERE deleted_at IS NOT NULL)")
+	}
+
+	var items []models.TrashItem
+	for _, kind := range []string{models.TrashProject, models.TrashWorld, models.TrashBlueprint, models.TrashChapter} {
+		scope := inLiveProject
+		switch kind {
+		case models.TrashProject:
+			scope = owned("id")
+		case models.TrashWorld:
+			scope = func(q *gorm.DB) *gorm.DB { return q }
+		}
+		rows, err := p.trashRows(kind, scope)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, rows...)
+	}
+	sort.SliceStable(items, func(i, j int) bool { return items[i].DeletedAt.After(items[j].DeletedAt) })
+	return items, nil
+}
+
+// GetTrashItem 获取回收站中的一项，不在回收站中时返回 gorm.ErrRecordNotFound
+func (p *PostgresDatabase) GetTrashItem(kind, id string) (*models.TrashItem, error) {
+	items, err := p.trashRows(kind, func(q *gorm.DB) *gorm.DB { return q.Where("id = ?", id) })
+	if err != nil {
+		return nil, err
+	}
+	if len(items) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &items[0], nil
+}
+
+// RestoreTrash 从回收站恢复；恢复项目时一并恢复与项目同时删除的蓝图和章节。
+// 恢复章节时项目中已有同章节号的章节返回 ErrChapterNumConflict
+func (p *PostgresDatabase) RestoreTrash(kind, id string) error {
+	model, err := trashModel(kind)
+	if err != nil {
+		return err
+	}
+	return p.db.Transaction(func(tx *gorm.DB) error {
+		if kind == models.TrashChapter {
+			var live int64
+			if err := tx.Model(&models.Chapter{}).
+				Where("project_id = (SELECT project_id FROM chapters WHERE id = ?) AND chapter_num = (SELECT chapter_num FROM chapters WHERE id = ?)", id, id).
+				Count(&live).Error; err != nil {
+				return err
+			}
+			if live > 0 {
+				return ErrChapterNumConflict
+			}
+		}
+		if kind == models.TrashProject {
+			sameTime := "project_id = ? AND deleted_at = (SELECT deleted_at FROM projects WHERE id = ?)"
+			for _, child := range []interface{}{&models.NarrativeBlueprint{}, &models.Chapter{}} {
+				if err := tx.Unscoped().Model(child).Where(sameTime, id, id).
+					UpdateColumn("deleted_at", nil).Error; err != nil {
+					return err
+				}
+			}
+		}
+		result := tx.Unscoped().Model(model).Where("id = ? AND deleted_at IS NOT NULL", id).UpdateColumn("deleted_at", nil)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// PurgeTrash 彻底删除 before 之前进入回收站的内容及其附属数据，返回各类删除的条数。
+// 附属数据包括项目、章节、蓝图和世界下的各表记录，审计日志保留
+func (p *PostgresDatabase) PurgeTrash(before time.Time) (map[string]int, error) {
+	counts := make(map[string]int)
+	err := p.db.Transaction(func(tx *gorm.DB) error {
+		expired := "deleted_at IS NOT NULL AND deleted_at < ?"
+		inProject := "project_id IN (SELECT id FROM projects WHERE " + expired + ")"
+		inChapter := "chapter_id IN (SELECT id FROM chapters WHERE " + expired + ")"
+		inProjectOrChapter := "(" + inProject + " OR " + inChapter + ")"
+		// 过期蓝图含过期项目下的蓝图
+		inBlueprint := "blueprint_id IN (SELECT id FROM narrative_blueprints WHERE " + expired + " OR " + inProject + ")"
+		inWorld := "world_id IN (SELECT id FROM world_settings WHERE " + expired + ")"
+
+		// 先删附属数据再删主记录；批注回复挂在批注下，须在批注之前删除
+		groups := []struct {
+			where string
+			args  []interface{}
+			deps  []interface{}
+		}{
+			{"annotation_id IN (SELECT id FROM annotations WHERE " + inProjectOrChapter + ")", []interface{}{before, before},
+				[]interface{}{&models.AnnotationReply{}}},
+			{inChapter, []interface{}{before}, []interface{}{&models.ChapterLock{}}},
+			{inProjectOrChapter, []interface{}{before, before}, []interface{}{
+				&models.Annotation{}, &models.EditSuggestion{}, &models.ChapterTranslation{},
+				&models.EntityMention{}, &models.NodeChapterMapping{}, &models.WritingDay{},
+			}},
+			{inProject, []interface{}{before}, []interface{}{
+				&models.NarrativeNode{}, &models.BlueprintBranch{}, &models.ReleasePlan{},
+				&models.ContinuityFact{}, &models.VitalEvent{}, &models.Item{}, &models.ItemTransfer{}, &models.RealmEvent{},
+				&models.MarketingCopy{}, &models.CompetitorAnalysis{}, &models.ReferenceText{}, &models.ManuscriptImport{},
+				&models.WebhookDelivery{}, &models.Webhook{},
+			}},
+			{inBlueprint, []interface{}{before, before}, []interface{}{&models.SceneOutput{}, &models.MemoryEntry{}, &models.CharacterSheet{}}},
+			{inWorld, []interface{}{before}, []interface{}{&models.CharacterSheet{}, &models.Character{}}},
+		}
+		for _, g := range groups {
+			for _, dep := range g.deps {
+				if err := tx.Where(g.where, g.args...).Delete(dep).Error; err != nil {
+					return err
+				}
+			}
+		}
+		for _, kind := range []string{models.TrashChapter, models.TrashBlueprint, models.TrashProject, models.TrashWorld} {
+			model, _ := trashModel(kind)
+			result := tx.Unscoped().Where(expired, before).Delete(model)
+			if result.Error != nil {
+				return result.Error
+			}
+			counts[kind] = int(result.RowsAffected)
+		}
+		return nil
+	})
+	return counts, err
+}
+
+func trashModel(kind string) (interface{}, error) {
+	switch kind {
+	case models.TrashProject:
+		return &models.Project{}, nil
+	case models.TrashWorld:
+		return &models.WorldSetting{}, nil
+	case models.TrashBlueprint:
+		return &models.NarrativeBlueprint{}, nil
+	case models.TrashChapter:
+		return &models.Chapter{}, nil
+	}
+	return nil, fmt.Errorf("未知的回收站类型: %s", kind)
+}
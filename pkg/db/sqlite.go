@@ -0,0 +1,59 @@
+// Package db SQLite持久化实现（单用户/本地模式）
+package db
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// DefaultSQLitePath 默认的SQLite数据库文件
+const DefaultSQLitePath = "data/xupu.db"
+
+// SQLiteDatabase SQLite数据库实现
+// 与PostgreSQL共用基于GORM的CRUD实现，只是连接方式不同
+type SQLiteDatabase struct {
+	*PostgresDatabase
+}
+
+// SQLiteDSN 构建SQLite连接串：开启外键、WAL和忙等待，便于API与CLI同时访问同一文件
+func SQLiteDSN(path string) string {
+	return fmt.Sprintf("%s?_pragma=foreign_keys(1)&_pragma=journal_mode(WAL)&_pragma=busy_timeout(5000)", path)
+}
+
+// NewSQLite 创建SQLite数据库连接，path为空时使用DB_PATH环境变量或默认路径
+func NewSQLite(path string) (*SQLiteDatabase, error) {
+	if path == "" {
+		path = getEnv("DB_PATH", DefaultSQLitePath)
+	}
+
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return nil, fmt.Errorf("创建数据目录失败: %w", err)
+		}
+	}
+
+	db, err := gorm.Open(sqlite.Open(SQLiteDSN(path)), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Warn),
+		NowFunc: func() time.Time {
+			return time.Now().UTC()
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("打开SQLite失败: %w", err)
+	}
+
+	// SQLite同一时间只允许一个写连接
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("获取数据库连接失败: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	return &SQLiteDatabase{PostgresDatabase: &PostgresDatabase{db: db}}, nil
+}
@@ -0,0 +1,63 @@
+package db
+
+import (
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// WritingDateLayout 写作统计的日期格式
+const WritingDateLayout = "2006-01-02"
+
+// RecordWritingDay 章节保存后更新当天的字数记录
+// 当天还没有记录时，以该章上一次记录的字数为起点；从未记录过的章节若是当天创建的从 0 算起，
+// 否则无从得知此前的字数，从当前字数算起（即不计入当天）
+func RecordWritingDay(gdb *gorm.DB, chapter *models.Chapter) error {
+	if chapter.ID == "" || chapter.ProjectID == "" {
+		return nil
+	}
+	now := time.Now()
+	today := now.Format(WritingDateLayout)
+	completed := chapter.Status == models.ChapterStatusCompleted
+
+	// 用 Limit+Find 而非 First，避免每次保存都打印 record not found
+	var existing []models.WritingDay
+	if err := gdb.Where("chapter_id = ? AND date = ?", chapter.ID, today).Limit(1).Find(&existing).Error; err != nil {
+		return err
+	}
+	if len(existing) > 0 {
+		return gdb.Model(&existing[0]).Updates(map[string]interface{}{
+			"chapter_num": chapter.ChapterNum,
+			"end_words":   chapter.WordCount,
+			"completed":   completed,
+			"updated_at":  now,
+		}).Error
+	}
+
+	start := chapter.WordCount
+	var last []models.WritingDay
+	if err := gdb.Where("chapter_id = ? AND date < ?", chapter.ID, today).Order("date DESC").Limit(1).Find(&last).Error; err != nil {
+		return err
+	}
+	if len(last) > 0 {
+		start = last[0].EndWords
+	} else if chapter.CreatedAt.IsZero() || chapter.CreatedAt.Format(WritingDateLayout) == today {
+		start = 0
+	}
+	day := models.WritingDay{
+		ID:         GenerateID("wday"),
+		ProjectID:  chapter.ProjectID,
+		ChapterID:  chapter.ID,
+		ChapterNum: chapter.ChapterNum,
+		Date:       today,
+		StartWords: start,
+		EndWords:   chapter.WordCount,
+		Completed:  completed,
+		CreatedAt:  now,
+		UpdatedAt:  now,
+	}
+	// 并发保存同一章时只保留先写入的一条，之后的保存会走上面的更新分支
+	return gdb.Clauses(clause.OnConflict{DoNothing: true}).Create(&day).Error
+}
@@ -0,0 +1,212 @@
+// Package emotion 角色情绪热力图
+// 汇总各章摘要记录的角色章末情绪与强度，生成角色×章节的热力图数据，
+// 并找出情绪始终平淡、弧线没有变化或相邻章节大起大落的角色。
+package emotion
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+const (
+	// DefaultIntensity 摘要没有给出强度、也无法从情绪词估计时的强度
+	DefaultIntensity = 50
+	// flatStdDev 强度标准差低于此值视为情绪平淡
+	flatStdDev = 8.0
+	// whiplashDelta 相邻两次记录的强度差达到此值视为骤变
+	whiplashDelta = 60
+	// minFlatSamples 判断平淡和弧线不变至少需要的记录数
+	minFlatSamples = 4
+)
+
+// 标记类型
+const (
+	FlagFlat      = "flat"      // 情绪强度几乎没有起伏
+	FlagUnchanged = "unchanged" // 情绪始终相同，弧线没有变化
+	FlagWhiplash  = "whiplash"  // 相邻记录之间情绪大起大落
+)
+
+// intenseWords 情绪词与估计强度，摘要没有给出强度时使用
+var intenseWords = []struct {
+	words     []string
+	intensity int
+}{
+	{[]string{"崩溃", "绝望", "暴怒", "狂喜", "癫狂", "撕心裂肺", "悲痛欲绝"}, 90},
+	{[]string{"愤怒", "恐惧", "悲痛", "震惊", "狂怒", "惊恐", "痛苦", "激动"}, 75},
+	{[]string{"焦虑", "不安", "悲伤", "喜悦", "紧张", "失落", "愧疚", "羞愧", "期待"}, 55},
+	{[]string{"平静", "淡然", "释然", "麻木", "冷漠", "从容"}, 20},
+}
+
+// Cell 角色在某一章的情绪
+type Cell struct {
+	Chapter   int    `json:"chapter"`
+	Emotion   string `json:"emotion"`
+	Intensity int    `json:"intensity"`
+	Estimated bool   `json:"estimated,omitempty"` // 强度由情绪词估计
+}
+
+// Row 一个角色在各章的情绪，没有记录的章节为 null
+type Row struct {
+	Character string  `json:"character"`
+	Cells     []*Cell `json:"cells"`
+	Samples   int     `json:"samples"` // 有记录的章节数
+	Mean      float64 `json:"mean"`
+	StdDev    float64 `json:"std_dev"`
+}
+
+// Flag 需要关注的角色情绪问题
+type Flag struct {
+	Character string `json:"character"`
+	Kind      string `json:"kind"`
+	Chapter   int    `json:"chapter,omitempty"`
+	Detail    string `json:"detail"`
+}
+
+// Heatmap 角色×章节的情绪热力图
+type Heatmap struct {
+	Chapters []int  `json:"chapters"`
+	Rows     []Row  `json:"rows"`
+	Flags    []Flag `json:"flags"`
+}
+
+// Estimate 根据情绪词估计强度，无法估计时返回 DefaultIntensity
+func Estimate(emotion string) int {
+	for _, group := range intenseWords {
+		for _, w := range group.words {
+			if strings.Contains(emotion, w) {
+				return group.intensity
+			}
+		}
+	}
+	return DefaultIntensity
+}
+
+// Build 由章节记录的角色状态变化生成热力图，characters 非空时只包含这些角色
+// 行按记录数降序排列
+func Build(chapters []*models.Chapter, characters []string) *Heatmap {
+	sorted := make([]*models.Chapter, 0, len(chapters))
+	for _, ch := range chapters {
+		if ch != nil {
+			sorted = append(sorted, ch)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ChapterNum < sorted[j].ChapterNum })
+
+	wanted := make(map[string]bool, len(characters))
+	for _, c := range characters {
+		wanted[c] = true
+	}
+
+	hm := &Heatmap{Chapters: make([]int, 0, len(sorted)), Rows: []Row{}, Flags: []Flag{}}
+	rows := make(map[string]*Row)
+	order := make([]string, 0)
+	for col, ch := range sorted {
+		hm.Chapters = append(hm.Chapters, ch.ChapterNum)
+		for _, d := range ch.StateDeltas {
+			if d.Character == "" || d.Emotion == "" || (len(wanted) > 0 && !wanted[d.Character]) {
+				continue
+			}
+			row, ok := rows[d.Character]
+			if !ok {
+				row = &Row{Character: d.Character, Cells: make([]*Cell, len(sorted))}
+				rows[d.Character] = row
+				order = append(order, d.Character)
+			}
+			cell := &Cell{Chapter: ch.ChapterNum, Emotion: d.Emotion, Intensity: d.Intensity}
+			if cell.Intensity <= 0 {
+				cell.Intensity = Estimate(d.Emotion)
+				cell.Estimated = true
+			}
+			row.Cells[col] = cell
+		}
+	}
+
+	for _, name := range order {
+		row := rows[name]
+		finish(row)
+		hm.Rows = append(hm.Rows, *row)
+		hm.Flags = append(hm.Flags, flags(row)...)
+	}
+	sort.SliceStable(hm.Rows, func(i, j int) bool { return hm.Rows[i].Samples > hm.Rows[j].Samples })
+	return hm
+}
+
+// finish 计算一行的记录数、均值和标准差
+func finish(row *Row) {
+	sum := 0
+	for _, c := range row.Cells {
+		if c != nil {
+			row.Samples++
+			sum += c.Intensity
+		}
+	}
+	if row.Samples == 0 {
+		return
+	}
+	row.Mean = float64(sum) / float64(row.Samples)
+	variance := 0.0
+	for _, c := range row.Cells {
+		if c != nil {
+			diff := float64(c.Intensity) - row.Mean
+			variance += diff * diff
+		}
+	}
+	row.StdDev = math.Round(math.Sqrt(variance/float64(row.Samples))*10) / 10
+	row.Mean = math.Round(row.Mean*10) / 10
+}
+
+// flags 找出一行中的平淡、弧线不变和骤变
+func flags(row *Row) []Flag {
+	out := make([]Flag, 0)
+	if row.Samples >= minFlatSamples {
+		emotions := make(map[string]bool)
+		for _, c := range row.Cells {
+			if c != nil {
+				emotions[c.Emotion] = true
+			}
+		}
+		switch {
+		case len(emotions) == 1:
+			out = append(out, Flag{Character: row.Character, Kind: FlagUnchanged,
+				Detail: fmt.Sprintf("%s在%d章中情绪始终是“%s”，人物弧线没有变化", row.Character, row.Samples, firstEmotion(row))})
+		case row.StdDev < flatStdDev:
+			out = append(out, Flag{Character: row.Character, Kind: FlagFlat,
+				Detail: fmt.Sprintf("%s的情绪强度始终在%.0f上下（标准差%.1f），缺少起伏", row.Character, row.Mean, row.StdDev)})
+		}
+	}
+
+	var prev *Cell
+	for _, c := range row.Cells {
+		if c == nil {
+			continue
+		}
+		if prev != nil && abs(c.Intensity-prev.Intensity) >= whiplashDelta {
+			out = append(out, Flag{Character: row.Character, Kind: FlagWhiplash, Chapter: c.Chapter,
+				Detail: fmt.Sprintf("%s的情绪从第%d章的“%s”（%d）骤变为第%d章的“%s”（%d），需要有足够的铺垫",
+					row.Character, prev.Chapter, prev.Emotion, prev.Intensity, c.Chapter, c.Emotion, c.Intensity)})
+		}
+		prev = c
+	}
+	return out
+}
+
+// firstEmotion 一行中第一条记录的情绪
+func firstEmotion(row *Row) string {
+	for _, c := range row.Cells {
+		if c != nil {
+			return c.Emotion
+		}
+	}
+	return ""
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
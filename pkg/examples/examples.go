@@ -0,0 +1,258 @@
+// Package examples 少样本示例库 - 按提示词角色和题材检索示例，注入到带角色的LLM调用中
+//
+// 示例由管理后台维护在数据库中，每条示例属于一个提示词角色（如 writer.scene），可限定题材。
+// 调用 GenerateJSONForRole 时，通过 llm.OnPrompt 钩子从该角色的启用示例中挑选最相关的1-3条附在提示词末尾。
+// 题材和关键词按提示词正文匹配：世界背景等上下文会写出世界类型（xianxia、mystery 等），
+// 限定了题材的示例只在提示词提到该题材时使用。
+// 注入模式由 llm.examples 配置（随配置热加载），也可在运行时临时切换；ab 模式下随机留出对照组，
+// 分别统计两组的结构校验通过率、修复次数和耗时，用于衡量示例的效果
+package examples
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/ctxbudget"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/logx"
+)
+
+// 实验分组
+const (
+	ArmExamples = "examples" // 注入了示例
+	ArmControl  = "control"  // ab 模式下留出的对照组
+)
+
+// 默认值
+const (
+	DefaultMax     = 2
+	DefaultHoldout = 0.5
+	maxExamples    = 3
+	cacheTTL       = time.Minute // 示例列表的缓存时间，管理后台修改时立即失效
+)
+
+// Store 示例的存储（db.Database 满足该接口）
+type Store interface {
+	ListPromptExamples(role string) ([]models.PromptExample, error)
+}
+
+// Settings 生效的注入配置
+type Settings struct {
+	Mode     string  `json:"mode"`
+	Max      int     `json:"max"`
+	Holdout  float64 `json:"holdout"`
+	Override bool    `json:"override"` // 是否为运行时临时切换（配置热加载后恢复为配置文件的值）
+}
+
+// Library 示例库
+type Library struct {
+	store Store
+
+	mu       sync.Mutex
+	cache    map[string]cachedList
+	override *config.ExamplesConfig
+	stats    map[armKey]*ArmStats
+}
+
+type cachedList struct {
+	examples []models.PromptExample
+	loadedAt time.Time
+}
+
+var (
+	defaultMu  sync.RWMutex
+	defaultLib *Library
+)
+
+// Install 创建示例库并注册为全局提示词钩子，配置热加载时清除运行时的临时切换
+func Install(store Store) *Library {
+	lib := &Library{
+		store: store,
+		cache: make(map[string]cachedList),
+		stats: make(map[armKey]*ArmStats),
+	}
+	defaultMu.Lock()
+	defaultLib = lib
+	defaultMu.Unlock()
+
+	llm.OnPrompt(lib.hook)
+	config.OnReload(func(*config.Config) {
+		lib.mu.Lock()
+		lib.override = nil
+		lib.mu.Unlock()
+	})
+	return lib
+}
+
+// Default 已安装的示例库，未安装时为 nil
+func Default() *Library {
+	defaultMu.RLock()
+	defer defaultMu.RUnlock()
+	return defaultLib
+}
+
+// Invalidate 清除缓存的示例列表，示例增删改后调用
+func (l *Library) Invalidate() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.cache = make(map[string]cachedList)
+}
+
+// Settings 当前生效的注入配置
+func (l *Library) Settings() Settings {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	var cfg config.ExamplesConfig
+	if l.override != nil {
+		cfg = *l.override
+	} else if c, err := config.Current(); err == nil {
+		cfg = c.LLM.Examples
+	}
+	s := Settings{Mode: cfg.Mode, Max: cfg.Max, Holdout: cfg.Holdout, Override: l.override != nil}
+	if s.Mode == "" {
+		s.Mode = config.ExamplesOff
+	}
+	if s.Max <= 0 {
+		s.Max = DefaultMax
+	}
+	s.Max = min(s.Max, maxExamples)
+	if s.Holdout <= 0 {
+		s.Holdout = DefaultHoldout
+	}
+	return s
+}
+
+// SetOverride 运行时临时切换注入配置，不写回配置文件；传 nil 恢复为配置文件的值
+// 切换模式时清空实验统计，避免两种设置的数据混在一起
+func (l *Library) SetOverride(cfg *config.ExamplesConfig) error {
+	if cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return err
+		}
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.override = cfg
+	l.stats = make(map[armKey]*ArmStats)
+	return nil
+}
+
+// Select 为提示词挑选最多 n 个示例：只取该角色启用的示例，限定题材的示例须在提示词中提到该题材，
+// 题材命中和关键词命中越多越优先，同分时取最近更新的
+func (l *Library) Select(role, prompt string, n int) []models.PromptExample {
+	if n <= 0 {
+		return nil
+	}
+	candidates := l.list(role)
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	text := strings.ToLower(prompt)
+	type scored struct {
+		example models.PromptExample
+		score   int
+	}
+	var picks []scored
+	for _, e := range candidates {
+		if !e.Enabled || strings.TrimSpace(e.Output) == "" {
+			continue
+		}
+		score := 0
+		if e.Genre != "" {
+			if !strings.Contains(text, strings.ToLower(e.Genre)) {
+				continue
+			}
+			score += 3
+		}
+		for _, tag := range e.Tags {
+			if tag != "" && strings.Contains(text, strings.ToLower(tag)) {
+				score++
+			}
+		}
+		picks = append(picks, scored{e, score})
+	}
+	sort.SliceStable(picks, func(i, j int) bool {
+		if picks[i].score != picks[j].score {
+			return picks[i].score > picks[j].score
+		}
+		return picks[i].example.UpdatedAt.After(picks[j].example.UpdatedAt)
+	})
+
+	out := make([]models.PromptExample, 0, min(n, len(picks)))
+	for i := 0; i < len(picks) && i < n; i++ {
+		out = append(out, picks[i].example)
+	}
+	return out
+}
+
+// list 角色的全部示例，带缓存；读取失败时视为没有示例，不影响正常调用
+func (l *Library) list(role string) []models.PromptExample {
+	l.mu.Lock()
+	cached, ok := l.cache[role]
+	l.mu.Unlock()
+	if ok && time.Since(cached.loadedAt) < cacheTTL {
+		return cached.examples
+	}
+
+	examples, err := l.store.ListPromptExamples(role)
+	if err != nil {
+		logx.L().Debug("读取少样本示例失败", "role", role, "error", err)
+		examples = nil
+	}
+	l.mu.Lock()
+	l.cache[role] = cachedList{examples: examples, loadedAt: time.Now()}
+	l.mu.Unlock()
+	return examples
+}
+
+// hook llm.OnPrompt 钩子：按模式注入示例，并在调用结束后记录所属分组的结果
+func (l *Library) hook(role, prompt string) (string, func(llm.RoleOutcome)) {
+	settings := l.Settings()
+	if settings.Mode == config.ExamplesOff {
+		return prompt, nil
+	}
+	picked := l.Select(role, prompt, settings.Max)
+	if len(picked) == 0 {
+		return prompt, nil
+	}
+
+	if settings.Mode == config.ExamplesAB && rand.Float64() < settings.Holdout {
+		return prompt, l.recorder(role, ArmControl, 0)
+	}
+	section := Render(picked)
+	return prompt + section, l.recorder(role, ArmExamples, ctxbudget.Count(section))
+}
+
+// Render 把示例渲染为附在提示词末尾的一段
+func Render(examples []models.PromptExample) string {
+	if len(examples) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\n# 参考示例\n")
+	sb.WriteString("以下示例展示期望的质量和写法，只借鉴思路与格式，不要照搬其中的人物、情节和措辞。\n")
+	for i, e := range examples {
+		if e.Title != "" {
+			sb.WriteString(fmt.Sprintf("\n## 示例%d：%s\n", i+1, e.Title))
+		} else {
+			sb.WriteString(fmt.Sprintf("\n## 示例%d\n", i+1))
+		}
+		if input := strings.TrimSpace(e.Input); input != "" {
+			sb.WriteString("### 条件\n")
+			sb.WriteString(input)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("### 输出\n")
+		sb.WriteString(strings.TrimSpace(e.Output))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
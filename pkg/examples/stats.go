@@ -0,0 +1,82 @@
+package examples
+
+import (
+	"sort"
+	"time"
+
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// ArmStats 某个角色在一个实验分组中的调用统计
+type ArmStats struct {
+	Role          string    `json:"role"`
+	Arm           string    `json:"arm"`
+	Calls         int       `json:"calls"`
+	Failed        int       `json:"failed"`         // 请求失败或修复后仍未通过校验
+	Repaired      int       `json:"repaired"`       // 经过修复才通过校验的调用数
+	Repairs       int       `json:"repairs"`        // 修复请求总数
+	SuccessRate   float64   `json:"success_rate"`   // 首次即通过校验的比例
+	AvgLatencyMs  float64   `json:"avg_latency_ms"` // 平均耗时（含修复请求）
+	ExampleTokens int       `json:"example_tokens"` // 注入示例的token总数，衡量示例带来的额外开销
+	UpdatedAt     time.Time `json:"updated_at"`
+
+	totalLatency time.Duration
+}
+
+type armKey struct {
+	role string
+	arm  string
+}
+
+// recorder 返回记录一次调用结果的回调
+func (l *Library) recorder(role, arm string, exampleTokens int) func(llm.RoleOutcome) {
+	return func(o llm.RoleOutcome) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+
+		key := armKey{role, arm}
+		st, ok := l.stats[key]
+		if !ok {
+			st = &ArmStats{Role: role, Arm: arm}
+			l.stats[key] = st
+		}
+		st.Calls++
+		switch {
+		case o.Err != nil:
+			st.Failed++
+		case o.Repairs > 0:
+			st.Repaired++
+		}
+		st.Repairs += o.Repairs
+		st.ExampleTokens += exampleTokens
+		st.totalLatency += o.Duration
+		st.SuccessRate = float64(st.Calls-st.Failed-st.Repaired) / float64(st.Calls)
+		st.AvgLatencyMs = float64(st.totalLatency.Milliseconds()) / float64(st.Calls)
+		st.UpdatedAt = time.Now()
+	}
+}
+
+// Stats 各角色各分组的统计，按角色、分组排序
+func (l *Library) Stats() []ArmStats {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make([]ArmStats, 0, len(l.stats))
+	for _, st := range l.stats {
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Role != out[j].Role {
+			return out[i].Role < out[j].Role
+		}
+		return out[i].Arm < out[j].Arm
+	})
+	return out
+}
+
+// ResetStats 清空统计，开始新一轮对比
+func (l *Library) ResetStats() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stats = make(map[armKey]*ArmStats)
+}
@@ -0,0 +1,187 @@
+// Package export 导出 - 有声书
+// 按场景的视角角色分配音色，把章节切分为语音合成大小的片段逐段合成，每章拼接为一个 MP3 后打包下载
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/locale"
+	"github.com/xlei/xupu/pkg/tts"
+)
+
+// AudioPart 同一视角角色朗读的一段正文
+type AudioPart struct {
+	POV  string
+	Text string
+}
+
+// AudiobookChapter 一章的朗读内容
+type AudiobookChapter struct {
+	Num   int
+	Title string
+	Parts []AudioPart
+}
+
+// AudiobookSource 有声书导出的数据
+type AudiobookSource struct {
+	Project    *models.Project
+	Characters []*models.Character
+	Chapters   []AudiobookChapter
+}
+
+// POVs 出现过的视角角色
+func (s *AudiobookSource) POVs() []string {
+	seen := make(map[string]bool)
+	povs := make([]string, 0)
+	for _, ch := range s.Chapters {
+		for _, p := range ch.Parts {
+			if p.POV != "" && !seen[p.POV] {
+				seen[p.POV] = true
+				povs = append(povs, p.POV)
+			}
+		}
+	}
+	return povs
+}
+
+// LoadAudiobookSource 加载项目 from-to 章（含两端，0 表示不限）的朗读内容
+// 章节正文未编辑过时按场景输出分段，各段使用场景的视角角色；编辑过的正文整章使用首个场景的视角角色
+func LoadAudiobookSource(database db.Database, projectID string, from, to int) (*AudiobookSource, error) {
+	project, err := database.GetProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("项目不存在: %s", projectID)
+	}
+	src := &AudiobookSource{Project: project}
+	if project.WorldID != "" {
+		src.Characters = database.ListCharactersByWorld(project.WorldID)
+	}
+
+	blueprint := &models.NarrativeBlueprint{}
+	if project.NarrativeID != "" {
+		if b, err := database.GetNarrativeBlueprint(project.NarrativeID); err == nil {
+			blueprint = b
+		}
+	}
+	plannedPOV := make(map[[2]int]string, len(blueprint.Scenes))
+	for _, s := range blueprint.Scenes {
+		plannedPOV[[2]int{s.Chapter, s.Scene}] = s.POVCharacter
+	}
+
+	chapters := database.ListChaptersByProject(projectID)
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].ChapterNum < chapters[j].ChapterNum })
+	for _, ch := range chapters {
+		if (from > 0 && ch.ChapterNum < from) || (to > 0 && ch.ChapterNum > to) {
+			continue
+		}
+		item := AudiobookChapter{Num: ch.ChapterNum, Title: ch.Title}
+
+		var scenes []*models.SceneOutput
+		if blueprint.ID != "" {
+			scenes = database.ListScenesByChapter(blueprint.ID, ch.ChapterNum)
+			sort.Slice(scenes, func(i, j int) bool { return scenes[i].Scene < scenes[j].Scene })
+		}
+		if strings.TrimSpace(ch.Content) == "" {
+			for _, s := range scenes {
+				pov := s.POVCharacter
+				if pov == "" {
+					pov = plannedPOV[[2]int{s.Chapter, s.Scene}]
+				}
+				item.Parts = append(item.Parts, AudioPart{POV: pov, Text: s.Content})
+			}
+		} else {
+			pov := ""
+			for _, s := range blueprint.Scenes {
+				if s.Chapter == ch.ChapterNum {
+					pov = s.POVCharacter
+					break
+				}
+			}
+			item.Parts = append(item.Parts, AudioPart{POV: pov, Text: ch.Content})
+		}
+		if len(item.Parts) > 0 {
+			src.Chapters = append(src.Chapters, item)
+		}
+	}
+	if len(src.Chapters) == 0 {
+		return nil, fmt.Errorf("项目 %s 在指定范围内没有正文", projectID)
+	}
+	return src, nil
+}
+
+// audiobookManifest 压缩包内的清单
+type audiobookManifest struct {
+	Title    string                   `json:"title"`
+	Provider string                   `json:"provider"`
+	Cast     tts.Cast                 `json:"cast"`
+	Chapters []audiobookManifestEntry `json:"chapters"`
+}
+
+type audiobookManifestEntry struct {
+	Chapter  int    `json:"chapter"`
+	Title    string `json:"title"`
+	File     string `json:"file"`
+	Segments int    `json:"segments"`
+}
+
+// WriteAudiobook 逐章合成并写入 zip：每章一个 MP3（各片段的 MP3 帧直接拼接），附 manifest.json
+func WriteAudiobook(w io.Writer, src *AudiobookSource, provider tts.Provider, cast tts.Cast, segmentRunes int) error {
+	zw := zip.NewWriter(w)
+	terms := locale.For(src.Project.Language)
+	manifest := audiobookManifest{Title: src.Project.Name, Provider: provider.Name(), Cast: cast}
+
+	for _, ch := range src.Chapters {
+		name := fmt.Sprintf("%03d-%s.mp3", ch.Num, safeFilename(terms.Chapter(ch.Num)+" "+ch.Title))
+		f, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Store})
+		if err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", name, err)
+		}
+
+		// 章节标题用旁白音色朗读
+		segments := 0
+		parts := append([]AudioPart{{Text: strings.TrimSpace(terms.Chapter(ch.Num) + " " + ch.Title)}}, ch.Parts...)
+		for _, part := range parts {
+			voice := cast.Voice(part.POV)
+			for _, text := range tts.Split(part.Text, segmentRunes) {
+				audio, err := provider.Synthesize(text, voice)
+				if err != nil {
+					return fmt.Errorf("第%d章合成失败: %w", ch.Num, err)
+				}
+				if _, err := f.Write(audio); err != nil {
+					return fmt.Errorf("写入 %s 失败: %w", name, err)
+				}
+				segments++
+			}
+		}
+		manifest.Chapters = append(manifest.Chapters, audiobookManifestEntry{
+			Chapter: ch.Num, Title: ch.Title, File: name, Segments: segments,
+		})
+	}
+
+	f, err := zw.Create("manifest.json")
+	if err != nil {
+		return fmt.Errorf("写入清单失败: %w", err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(manifest); err != nil {
+		return fmt.Errorf("写入清单失败: %w", err)
+	}
+	return zw.Close()
+}
+
+// safeFilename 去掉文件名中不允许的字符
+func safeFilename(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(`/\:*?"<>|`, r) || r < 0x20 {
+			return -1
+		}
+		return r
+	}, strings.TrimSpace(s))
+}
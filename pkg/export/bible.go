@@ -0,0 +1,491 @@
+// Package export 导出 - 故事圣经
+// 将世界设定、角色档案、冲突线索、时间线与伏笔台账汇编为一份结构化文档，供人类协作者查阅
+package export
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// BibleSource 故事圣经的数据来源
+type BibleSource struct {
+	Project    *models.Project
+	World      *models.WorldSetting
+	Characters []*models.Character
+	Blueprint  *models.NarrativeBlueprint // 可选
+	Nodes      []*models.NarrativeNode    // 可选
+}
+
+// LoadBibleSource 加载项目的故事圣经数据
+// 项目必须关联世界设定；叙事蓝图和叙事节点缺失时对应章节留空
+func LoadBibleSource(database db.Database, projectID string) (*BibleSource, error) {
+	project, err := database.GetProject(projectID)
+	if err != nil {
+		return nil, fmt.Errorf("项目不存在: %s", projectID)
+	}
+	if project.WorldID == "" {
+		return nil, fmt.Errorf("项目 %s 尚未关联世界设定", projectID)
+	}
+
+	world, err := database.GetWorld(project.WorldID)
+	if err != nil {
+		return nil, fmt.Errorf("世界不存在: %s", project.WorldID)
+	}
+
+	src := &BibleSource{
+		Project:    project,
+		World:      world,
+		Characters: database.ListCharactersByWorld(world.ID),
+		Nodes:      database.ListNarrativeNodesByProject(projectID),
+	}
+	if project.NarrativeID != "" {
+		if blueprint, err := database.GetBlueprint(project.NarrativeID); err == nil {
+			src.Blueprint = blueprint
+		}
+	}
+
+	sort.Slice(src.Characters, func(i, j int) bool { return src.Characters[i].Name < src.Characters[j].Name })
+	sort.Slice(src.Nodes, func(i, j int) bool {
+		if src.Nodes[i].NodeLevel != src.Nodes[j].NodeLevel {
+			return src.Nodes[i].NodeLevel < src.Nodes[j].NodeLevel
+		}
+		return src.Nodes[i].NodeOrder < src.Nodes[j].NodeOrder
+	})
+
+	return src, nil
+}
+
+// RenderBibleMarkdown 渲染为 Markdown
+func RenderBibleMarkdown(src *BibleSource) string {
+	var sb strings.Builder
+
+	title := src.World.Name
+	if src.Project != nil {
+		title = src.Project.Name
+	}
+	sb.WriteString(fmt.Sprintf("# 故事圣经：%s\n\n", title))
+	sb.WriteString(fmt.Sprintf("> 生成时间：%s\n\n", time.Now().Format("2006-01-02 15:04")))
+	sb.WriteString("## 目录\n\n")
+	sb.WriteString("1. 世界设定\n2. 角色档案\n3. 冲突线索\n4. 时间线\n5. 伏笔台账\n\n")
+
+	writeWorldSection(&sb, src.World)
+	writeCharacterSection(&sb, src)
+	writeConflictSection(&sb, src.World)
+	writeTimelineSection(&sb, src)
+	writeForeshadowSection(&sb, src)
+
+	return sb.String()
+}
+
+// WriteBiblePDF 通过 pandoc 将 Markdown 转换为 PDF
+// 中文排版需要 xelatex 引擎；未安装 pandoc 时返回错误
+func WriteBiblePDF(markdown, outputFile string) error {
+	pandoc, err := exec.LookPath("pandoc")
+	if err != nil {
+		return fmt.Errorf("导出PDF需要安装 pandoc（及 xelatex），可先导出 Markdown 再自行转换")
+	}
+
+	tmp, err := os.CreateTemp("", "xupu-bible-*.md")
+	if err != nil {
+		return fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(markdown); err != nil {
+		tmp.Close()
+		return fmt.Errorf("写入临时文件失败: %w", err)
+	}
+	tmp.Close()
+
+	cmd := exec.Command(pandoc, tmp.Name(), "-o", outputFile,
+		"--pdf-engine=xelatex", "-V", "CJKmainfont=Noto Serif CJK SC", "--toc")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("pandoc 转换失败: %v\n%s", err, out)
+	}
+	return nil
+}
+
+// ============================================
+// 各章节渲染
+// ============================================
+
+func writeWorldSection(sb *strings.Builder, world *models.WorldSetting) {
+	sb.WriteString("## 一、世界设定\n\n")
+	sb.WriteString(fmt.Sprintf("- **名称**：%s\n", world.Name))
+	writeItem(sb, "类型", string(world.Type))
+	writeItem(sb, "规模", string(world.Scale))
+	writeItem(sb, "风格", world.Style)
+	sb.WriteString("\n")
+
+	if p := world.Philosophy; p.CoreQuestion != "" {
+		sb.WriteString("### 核心哲学\n\n")
+		sb.WriteString(fmt.Sprintf("**核心问题**：%s\n\n", p.CoreQuestion))
+		writeField(sb, "**最高善**", p.ValueSystem.HighestGood)
+		writeField(sb, "**最大恶**", p.ValueSystem.UltimateEvil)
+		for _, t := range p.Themes {
+			sb.WriteString(fmt.Sprintf("- 主题「%s」：%s\n", t.Name, t.ExplorationAngle))
+		}
+		sb.WriteString("\n")
+	}
+
+	if c := world.Worldview.Cosmology; c.Origin != "" || c.Structure != "" {
+		sb.WriteString("### 世界观\n\n")
+		writeField(sb, "**起源**", c.Origin)
+		writeField(sb, "**结构**", c.Structure)
+		writeField(sb, "**终极命运**", c.Eschatology)
+		sb.WriteString("\n")
+	}
+
+	if s := world.Laws.Supernatural; s != nil && s.Exists {
+		sb.WriteString("### 超自然体系\n\n")
+		sb.WriteString(fmt.Sprintf("**类型**：%s\n\n", s.Type))
+		if s.Settings != nil {
+			if m := s.Settings.MagicSystem; m != nil {
+				writeField(sb, "**魔法来源**", m.Source)
+				writeField(sb, "**代价**", m.Cost)
+				writeList(sb, "限制", m.Limitation)
+			}
+			if c := s.Settings.CultivationSystem; c != nil {
+				writeField(sb, "**境界**", strings.Join(c.Realms, " → "))
+				writeField(sb, "**资源体系**", c.ResourceSystem)
+				writeField(sb, "**瓶颈**", c.Bottleneck)
+			}
+			if p := s.Settings.SuperpowerSystem; p != nil {
+				writeField(sb, "**能力起源**", p.Origin)
+				writeList(sb, "限制", p.Limit)
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(world.Geography.Regions) > 0 {
+		sb.WriteString("### 地理\n\n")
+		for _, r := range world.Geography.Regions {
+			sb.WriteString(fmt.Sprintf("- **%s**（%s）：%s\n", r.Name, r.Type, r.Description))
+		}
+		sb.WriteString("\n")
+	}
+
+	civ := world.Civilization
+	if len(civ.Races) > 0 || len(civ.Religions) > 0 {
+		sb.WriteString("### 文明\n\n")
+		for _, r := range civ.Races {
+			sb.WriteString(fmt.Sprintf("- 种族「%s」：%s\n", r.Name, r.Description))
+		}
+		for _, r := range civ.Religions {
+			sb.WriteString(fmt.Sprintf("- 宗教「%s」：%s\n", r.Name, r.Cosmology))
+		}
+		sb.WriteString("\n")
+	}
+
+	soc := world.Society
+	if soc.Politics.Type != "" || len(soc.Classes) > 0 {
+		sb.WriteString("### 社会\n\n")
+		writeField(sb, "**政体**", soc.Politics.Type)
+		writeField(sb, "**合法性来源**", soc.Politics.LegitimacySource)
+		for _, c := range soc.Classes {
+			sb.WriteString(fmt.Sprintf("- 阶层「%s」（等级 %d）\n", c.Name, c.Rank))
+		}
+		sb.WriteString("\n")
+	}
+
+	culture := world.StorySoil.CulturalDetails
+	if len(culture.Customs) > 0 || len(culture.Taboos) > 0 {
+		sb.WriteString("### 风俗与禁忌\n\n")
+		writeList(sb, "习俗", culture.Customs)
+		writeList(sb, "禁忌", culture.Taboos)
+		sb.WriteString("\n")
+	}
+}
+
+func writeCharacterSection(sb *strings.Builder, src *BibleSource) {
+	sb.WriteString("## 二、角色档案\n\n")
+	if len(src.Characters) == 0 {
+		sb.WriteString("（暂无角色）\n\n")
+		return
+	}
+
+	names := make(map[string]string, len(src.Characters))
+	for _, char := range src.Characters {
+		names[char.ID] = char.Name
+	}
+
+	for _, char := range src.Characters {
+		sp := char.StaticProfile
+		np := char.NarrativeProfile
+
+		sb.WriteString(fmt.Sprintf("### %s\n\n", char.Name))
+		sb.WriteString(fmt.Sprintf("- **ID**：%s\n", char.ID))
+		if sp.Age > 0 {
+			sb.WriteString(fmt.Sprintf("- **年龄**：%d\n", sp.Age))
+		}
+		writeItem(sb, "性别", sp.Gender)
+		writeItem(sb, "种族", sp.Race)
+		writeItem(sb, "身份", sp.Occupation)
+		writeItem(sb, "社会地位", sp.SocialStatus)
+		writeItem(sb, "外貌", sp.Appearance)
+		if len(sp.Abilities) > 0 {
+			sb.WriteString(fmt.Sprintf("- **能力**：%s\n", strings.Join(sp.Abilities, "、")))
+		}
+		sb.WriteString("\n")
+
+		writeField(sb, "**背景**", sp.Background)
+
+		if len(np.Personality) > 0 {
+			traits := make([]string, 0, len(np.Personality))
+			for _, t := range np.Personality {
+				traits = append(traits, t.Name)
+			}
+			sb.WriteString(fmt.Sprintf("**性格**：%s\n\n", strings.Join(traits, "、")))
+		}
+		writeField(sb, "**核心需求**", np.Motivation.CoreNeed)
+		writeField(sb, "**外在目标**", np.Motivation.ExternalGoal)
+		writeField(sb, "**内在冲突**", np.Motivation.InnerConflict)
+		writeField(sb, "**致命缺陷**", np.Flaw)
+		writeField(sb, "**核心恐惧**", np.Fear)
+
+		if len(np.Relationships) > 0 {
+			sb.WriteString("**人物关系**：\n\n")
+			keys := make([]string, 0, len(np.Relationships))
+			for k := range np.Relationships {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				rel := np.Relationships[k]
+				if rel == nil {
+					continue
+				}
+				target := k
+				if rel.CharacterID != "" {
+					target = rel.CharacterID
+				}
+				if name, ok := names[target]; ok {
+					target = name
+				}
+				sb.WriteString(fmt.Sprintf("- %s：%s（信任 %d）\n", target, rel.Attitude, rel.TrustLevel))
+			}
+			sb.WriteString("\n")
+		}
+
+		arc := np.ArcPlan
+		if src.Blueprint != nil {
+			if planned, ok := src.Blueprint.CharacterArcs[char.ID]; ok && planned != nil {
+				arc = planned
+			}
+		}
+		if arc != nil {
+			sb.WriteString(fmt.Sprintf("**角色弧光**：%s（进度 %d%%）\n\n", arc.ArcType, arc.CurrentProgress))
+			for _, tp := range arc.TurningPoints {
+				sb.WriteString(fmt.Sprintf("- 第%d章：%s → %s\n", tp.Chapter, tp.Event, tp.Change))
+			}
+			if len(arc.TurningPoints) > 0 {
+				sb.WriteString("\n")
+			}
+		}
+	}
+}
+
+func writeConflictSection(sb *strings.Builder, world *models.WorldSetting) {
+	sb.WriteString("## 三、冲突线索\n\n")
+
+	conflicts := append([]models.Conflict{}, world.Society.Conflicts...)
+	conflicts = append(conflicts, world.StorySoil.SocialConflicts...)
+	hooks := world.StorySoil.PotentialPlotHooks
+	issues := world.StorySoil.HistoricalContext.UnresolvedIssues
+
+	if len(conflicts) == 0 && len(hooks) == 0 && len(issues) == 0 {
+		sb.WriteString("（暂无冲突线索）\n\n")
+		return
+	}
+
+	if len(conflicts) > 0 {
+		sort.SliceStable(conflicts, func(i, j int) bool { return conflicts[i].Tension > conflicts[j].Tension })
+		sb.WriteString("| 类型 | 冲突 | 参与方 | 张力 | 触发条件 |\n")
+		sb.WriteString("|------|------|--------|------|----------|\n")
+		for _, c := range conflicts {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %d | %s |\n",
+				tableCell(c.Type), tableCell(c.Description), tableCell(strings.Join(c.Parties, "、")),
+				c.Tension, tableCell(strings.Join(c.Triggers, "；"))))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(hooks) > 0 {
+		sb.WriteString("### 潜在情节钩子\n\n")
+		for _, h := range hooks {
+			sb.WriteString(fmt.Sprintf("- [%s] %s", h.Type, h.Description))
+			if h.StoryPotential != "" {
+				sb.WriteString(fmt.Sprintf("——%s", h.StoryPotential))
+			}
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+
+	writeList(sb, "未解决的问题", issues)
+	if len(issues) > 0 {
+		sb.WriteString("\n")
+	}
+}
+
+func writeTimelineSection(sb *strings.Builder, src *BibleSource) {
+	sb.WriteString("## 四、时间线\n\n")
+	history := src.World.History
+
+	writeField(sb, "**起源**", history.Origin)
+
+	if len(history.Eras) > 0 {
+		sb.WriteString("### 纪元\n\n")
+		for _, era := range history.Eras {
+			sb.WriteString(fmt.Sprintf("- **%s**（%s）：%s\n", era.Name, era.Period, era.Description))
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(history.Events) > 0 {
+		sb.WriteString("### 历史事件\n\n")
+		for _, e := range history.Events {
+			sb.WriteString(fmt.Sprintf("- **%s** %s：%s\n", e.Time, e.Name, e.Description))
+			for _, c := range e.Consequences {
+				sb.WriteString(fmt.Sprintf("  - 后果：%s\n", c))
+			}
+		}
+		sb.WriteString("\n")
+	}
+
+	recent := append([]models.RecentEvent{}, src.World.StorySoil.HistoricalContext.RecentEvents...)
+	if len(recent) > 0 {
+		sort.SliceStable(recent, func(i, j int) bool { return recent[i].YearsAgo > recent[j].YearsAgo })
+		sb.WriteString("### 近期事件\n\n")
+		for _, e := range recent {
+			sb.WriteString(fmt.Sprintf("- %d年前：%s（%s）\n", e.YearsAgo, e.Event, e.Impact))
+		}
+		sb.WriteString("\n")
+	}
+
+	if src.Blueprint != nil && len(src.Blueprint.ChapterPlans) > 0 {
+		sb.WriteString("### 故事进程\n\n")
+		for _, ch := range src.Blueprint.ChapterPlans {
+			sb.WriteString(fmt.Sprintf("- 第%d章 %s：%s\n", ch.Chapter, ch.Title, ch.PlotAdvancement))
+		}
+		sb.WriteString("\n")
+	}
+}
+
+func writeForeshadowSection(sb *strings.Builder, src *BibleSource) {
+	sb.WriteString("## 五、伏笔台账\n\n")
+	wrote := false
+
+	if src.Blueprint != nil {
+		if symbols := src.Blueprint.ThemePlan.Symbols; len(symbols) > 0 {
+			sb.WriteString("### 象征\n\n")
+			sb.WriteString("| 象征 | 含义 | 首次出现 | 再次出现 |\n")
+			sb.WriteString("|------|------|----------|----------|\n")
+			for _, s := range symbols {
+				first, rest := "-", "-"
+				if len(s.Appearances) > 0 {
+					first = fmt.Sprintf("第%d章", s.Appearances[0])
+					if len(s.Appearances) > 1 {
+						chapters := make([]string, 0, len(s.Appearances)-1)
+						for _, ch := range s.Appearances[1:] {
+							chapters = append(chapters, fmt.Sprintf("%d", ch))
+						}
+						rest = "第" + strings.Join(chapters, "、") + "章"
+					}
+				}
+				sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n",
+					tableCell(s.Name), tableCell(s.Meaning), first, rest))
+			}
+			sb.WriteString("\n")
+			wrote = true
+		}
+
+		hooks := make([]models.ChapterPlan, 0)
+		for _, ch := range src.Blueprint.ChapterPlans {
+			if ch.EndingHook != "" {
+				hooks = append(hooks, ch)
+			}
+		}
+		if len(hooks) > 0 {
+			sb.WriteString("### 章末悬念\n\n")
+			for _, ch := range hooks {
+				sb.WriteString(fmt.Sprintf("- 第%d章：%s\n", ch.Chapter, ch.EndingHook))
+			}
+			sb.WriteString("\n")
+			wrote = true
+		}
+	}
+
+	// 叙事节点中已选分支的伏笔提示
+	nodeHints := make([]string, 0)
+	for _, node := range src.Nodes {
+		if node.SelectedBranchID == nil {
+			continue
+		}
+		for _, branch := range node.Branches {
+			if branch.ID != *node.SelectedBranchID {
+				continue
+			}
+			for _, hint := range branch.ExpectedOutcome.ForeshadowHints {
+				nodeHints = append(nodeHints, fmt.Sprintf("%s：%s", node.Title, hint))
+			}
+		}
+	}
+	if len(nodeHints) > 0 {
+		sb.WriteString("### 叙事节点伏笔\n\n")
+		for _, hint := range nodeHints {
+			sb.WriteString(fmt.Sprintf("- %s\n", hint))
+		}
+		sb.WriteString("\n")
+		wrote = true
+	}
+
+	if !wrote {
+		sb.WriteString("（暂无伏笔记录）\n\n")
+	}
+}
+
+// ============================================
+// 辅助函数
+// ============================================
+
+// writeField 写入非空字段
+func writeField(sb *strings.Builder, label, value string) {
+	if value == "" {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("%s：%s\n\n", label, value))
+}
+
+// writeItem 写入非空列表项
+func writeItem(sb *strings.Builder, label, value string) {
+	if value == "" {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("- **%s**：%s\n", label, value))
+}
+
+// writeList 写入非空列表
+func writeList(sb *strings.Builder, label string, items []string) {
+	if len(items) == 0 {
+		return
+	}
+	sb.WriteString(fmt.Sprintf("**%s**：\n\n", label))
+	for _, item := range items {
+		sb.WriteString(fmt.Sprintf("- %s\n", item))
+	}
+	sb.WriteString("\n")
+}
+
+// tableCell 转义表格单元格
+func tableCell(s string) string {
+	return strings.NewReplacer("|", "\\|", "\n", " ").Replace(s)
+}
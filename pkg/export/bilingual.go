@@ -0,0 +1,163 @@
+// Package export 导出 - 双语对照
+// 将章节原文与译文逐段对齐，导出为左右对照的 Markdown 表格或 DOCX 表格
+package export
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/locale"
+)
+
+// BilingualChapter 一章的对照内容
+type BilingualChapter struct {
+	Num         int
+	Title       string
+	TargetTitle string
+	Segments    []models.TranslationSegment
+	Stale       bool // 原文在翻译后又被修改过
+}
+
+// BilingualSource 双语对照导出的数据
+type BilingualSource struct {
+	Title          string
+	SourceLanguage string
+	TargetLanguage string
+	Chapters       []BilingualChapter
+}
+
+// RenderBilingualMarkdown 渲染为左右对照的 Markdown 表格，每章一张表
+func RenderBilingualMarkdown(src *BilingualSource) string {
+	var sb strings.Builder
+	source, target := locale.For(src.SourceLanguage), locale.For(src.TargetLanguage)
+
+	sb.WriteString(fmt.Sprintf("# %s\n\n", src.Title))
+	for _, ch := range src.Chapters {
+		sb.WriteString(fmt.Sprintf("## %s %s", source.Chapter(ch.Num), ch.Title))
+		if ch.TargetTitle != "" {
+			sb.WriteString(fmt.Sprintf(" / %s %s", target.Chapter(ch.Num), ch.TargetTitle))
+		}
+		sb.WriteString("\n\n")
+		if ch.Stale {
+			sb.WriteString("> 原文在翻译后有修改，译文可能不是最新\n\n")
+		}
+		sb.WriteString(fmt.Sprintf("| %s | %s |\n", locale.Name(src.SourceLanguage), locale.Name(src.TargetLanguage)))
+		sb.WriteString("| --- | --- |\n")
+		for _, s := range ch.Segments {
+			sb.WriteString(fmt.Sprintf("| %s | %s |\n", tableCell(s.Source), tableCell(s.Target)))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// WriteBilingualDOCX 写入 DOCX：每章一个标题和一张两列表格，原文与译文逐行对齐
+// 只生成 WordprocessingML 的最小结构，不依赖外部库
+func WriteBilingualDOCX(w io.Writer, src *BilingualSource) error {
+	zw := zip.NewWriter(w)
+	parts := []struct{ name, content string }{
+		{"[Content_Types].xml", docxContentTypes},
+		{"_rels/.rels", docxRels},
+		{"word/document.xml", renderDocxDocument(src)},
+	}
+	for _, part := range parts {
+		f, err := zw.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", part.name, err)
+		}
+		if _, err := io.WriteString(f, part.content); err != nil {
+			return fmt.Errorf("写入 %s 失败: %w", part.name, err)
+		}
+	}
+	return zw.Close()
+}
+
+const docxContentTypes = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+<Default Extension="xml" ContentType="application/xml"/>
+<Override PartName="/word/document.xml" ContentType="application/vnd.openxmlformats-officedocument.wordprocessingml.document.main+xml"/>
+</Types>`
+
+const docxRels = `<?xml version="1.0" encoding="UTF-8" standalone="yes"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="word/document.xml"/>
+</Relationships>`
+
+// renderDocxDocument 生成 word/document.xml
+func renderDocxDocument(src *BilingualSource) string {
+	var sb strings.Builder
+	source, target := locale.For(src.SourceLanguage), locale.For(src.TargetLanguage)
+
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="yes"?>`)
+	sb.WriteString(`<w:document xmlns:w="http://schemas.openxmlformats.org/wordprocessingml/2006/main"><w:body>`)
+	writeDocxParagraph(&sb, src.Title, 36)
+	for _, ch := range src.Chapters {
+		heading := fmt.Sprintf("%s %s", source.Chapter(ch.Num), ch.Title)
+		if ch.TargetTitle != "" {
+			heading += fmt.Sprintf(" / %s %s", target.Chapter(ch.Num), ch.TargetTitle)
+		}
+		writeDocxParagraph(&sb, heading, 28)
+		if ch.Stale {
+			writeDocxParagraph(&sb, "原文在翻译后有修改，译文可能不是最新", 0)
+		}
+
+		sb.WriteString(`<w:tbl><w:tblPr><w:tblW w:w="5000" w:type="pct"/><w:tblBorders>`)
+		for _, side := range []string{"top", "left", "bottom", "right", "insideH", "insideV"} {
+			sb.WriteString(fmt.Sprintf(`<w:%s w:val="single" w:sz="4" w:space="0" w:color="auto"/>`, side))
+		}
+		sb.WriteString(`</w:tblBorders><w:tblLayout w:type="fixed"/></w:tblPr>`)
+		sb.WriteString(`<w:tblGrid><w:gridCol w:w="4680"/><w:gridCol w:w="4680"/></w:tblGrid>`)
+		writeDocxRow(&sb, locale.Name(src.SourceLanguage), locale.Name(src.TargetLanguage), true)
+		for _, s := range ch.Segments {
+			writeDocxRow(&sb, s.Source, s.Target, false)
+		}
+		sb.WriteString(`</w:tbl>`)
+		writeDocxParagraph(&sb, "", 0)
+	}
+	sb.WriteString(`<w:sectPr><w:pgSz w:w="11906" w:h="16838"/>`)
+	sb.WriteString(`<w:pgMar w:top="1440" w:right="1273" w:bottom="1440" w:left="1273" w:header="851" w:footer="992" w:gutter="0"/></w:sectPr>`)
+	sb.WriteString(`</w:body></w:document>`)
+	return sb.String()
+}
+
+// writeDocxRow 写入一行两列的表格行
+func writeDocxRow(sb *strings.Builder, left, right string, header bool) {
+	sb.WriteString(`<w:tr>`)
+	if header {
+		sb.WriteString(`<w:trPr><w:tblHeader/></w:trPr>`)
+	}
+	for _, text := range []string{left, right} {
+		sb.WriteString(`<w:tc><w:tcPr><w:tcW w:w="2500" w:type="pct"/></w:tcPr>`)
+		if header {
+			sb.WriteString(`<w:p><w:r><w:rPr><w:b/></w:rPr>`)
+			writeDocxText(sb, text)
+			sb.WriteString(`</w:r></w:p>`)
+		} else {
+			writeDocxParagraph(sb, text, 0)
+		}
+		sb.WriteString(`</w:tc>`)
+	}
+	sb.WriteString(`</w:tr>`)
+}
+
+// writeDocxParagraph 写入段落，size 为字号（半磅），0 表示默认字号
+func writeDocxParagraph(sb *strings.Builder, text string, size int) {
+	sb.WriteString(`<w:p><w:r>`)
+	if size > 0 {
+		sb.WriteString(fmt.Sprintf(`<w:rPr><w:b/><w:sz w:val="%d"/></w:rPr>`, size))
+	}
+	writeDocxText(sb, text)
+	sb.WriteString(`</w:r></w:p>`)
+}
+
+// writeDocxText 写入转义后的文本
+func writeDocxText(sb *strings.Builder, text string) {
+	sb.WriteString(`<w:t xml:space="preserve">`)
+	xml.EscapeText(sb, []byte(text))
+	sb.WriteString(`</w:t>`)
+}
@@ -0,0 +1,288 @@
+// Package export 导出 - 剧本格式
+// 把章节的场景转换为剧本：场景标题取自场景的地点和时间，带引号的对白拆为角色和台词，其余文字作为动作描述
+// 输出 Fountain 纯文本或 Final Draft（FDX）XML，供改编使用
+package export
+
+import (
+	"encoding/xml"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 剧本元素类型（与 Final Draft 的段落类型同名）
+const (
+	ElementHeading       = "Scene Heading"
+	ElementAction        = "Action"
+	ElementCharacter     = "Character"
+	ElementParenthetical = "Parenthetical"
+	ElementDialogue      = "Dialogue"
+	ElementTransition    = "Transition"
+)
+
+// ScriptElement 剧本中的一个段落
+type ScriptElement struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// ScreenplayScene 转换的输入：场景指令中的地点、时间、出场角色，以及场景正文
+type ScreenplayScene struct {
+	Sequence   int
+	Location   string
+	Time       string
+	Characters []string
+	Summary    string // 场景没有正文时作为动作描述
+	Prose      string
+}
+
+// Screenplay 转换后的剧本
+type Screenplay struct {
+	Title    string
+	Elements []ScriptElement
+}
+
+// speechVerbs 引出对白的动词，去掉后剩下说话方式（“冷笑道”得到“冷笑”），按长度从长到短匹配
+var speechVerbs = []string{"开口道", "说道", "问道", "答道", "道", "说", "问", "答"}
+
+// interiorMarks 地点中出现这些字时视为内景
+var interiorMarks = []string{"内", "里", "中", "室", "屋", "房", "厅", "殿", "堂", "阁", "楼", "府", "舱", "洞", "馆", "店", "车厢", "宫"}
+
+// ConvertScreenplay 把场景转换为剧本
+func ConvertScreenplay(title string, scenes []ScreenplayScene) *Screenplay {
+	sorted := append([]ScreenplayScene{}, scenes...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Sequence < sorted[j].Sequence })
+
+	sp := &Screenplay{Title: title}
+	for i, s := range sorted {
+		if i > 0 {
+			sp.Elements = append(sp.Elements, ScriptElement{Type: ElementTransition, Text: "CUT TO:"})
+		}
+		sp.Elements = append(sp.Elements, ScriptElement{Type: ElementHeading, Text: sceneHeading(s)})
+
+		if strings.TrimSpace(s.Prose) == "" {
+			if s.Summary != "" {
+				sp.Elements = append(sp.Elements, ScriptElement{Type: ElementAction, Text: s.Summary})
+			}
+			continue
+		}
+		for _, paragraph := range strings.Split(s.Prose, "\n") {
+			if paragraph = strings.TrimSpace(paragraph); paragraph != "" {
+				sp.Elements = append(sp.Elements, convertParagraph(paragraph, s.Characters)...)
+			}
+		}
+	}
+	return sp
+}
+
+// sceneHeading 场景标题，如 INT. 客栈大堂 - 夜
+func sceneHeading(s ScreenplayScene) string {
+	location := strings.TrimSpace(s.Location)
+	if location == "" {
+		location = fmt.Sprintf("场景%d", s.Sequence)
+	}
+	prefix := "EXT."
+	for _, mark := range interiorMarks {
+		if strings.Contains(location, mark) {
+			prefix = "INT."
+			break
+		}
+	}
+	heading := prefix + " " + location
+	if t := strings.TrimSpace(s.Time); t != "" {
+		heading += " - " + t
+	}
+	return heading
+}
+
+// convertParagraph 把一段正文拆为动作描述和对白
+// 引号内的文字为台词；说话人取引号前（李明冷笑道：“……”）或引号后（“……”李明说。）分句开头的出场角色，
+// 都没有时沿用本段上一句台词的说话人；整段无法确定说话人时保留原文作为动作描述
+func convertParagraph(paragraph string, characters []string) []ScriptElement {
+	parts := splitQuotes(paragraph)
+	speakers := make(map[int][2]string, len(parts))
+	last := [2]string{}
+	for i, p := range parts {
+		if !p.quoted {
+			continue
+		}
+		if i > 0 && !parts[i-1].quoted {
+			head, clause := lastClause(parts[i-1].text)
+			if name, rest := leadingCharacter(clause, characters); name != "" {
+				last = [2]string{name, speechManner(rest)}
+				speakers[i] = last
+				parts[i-1].text = head
+				continue
+			}
+		}
+		if i+1 < len(parts) && !parts[i+1].quoted {
+			clause, tail := firstClause(parts[i+1].text)
+			if name, rest := leadingCharacter(clause, characters); name != "" {
+				last = [2]string{name, speechManner(rest)}
+				speakers[i] = last
+				parts[i+1].text = tail
+				continue
+			}
+		}
+		if last[0] == "" {
+			return []ScriptElement{{Type: ElementAction, Text: paragraph}}
+		}
+		speakers[i] = [2]string{last[0], ""}
+	}
+
+	elements := make([]ScriptElement, 0, len(parts)*2)
+	for i, p := range parts {
+		if !p.quoted {
+			if text := strings.TrimSpace(p.text); strings.Trim(text, "，。：:,. ") != "" {
+				elements = append(elements, ScriptElement{Type: ElementAction, Text: text})
+			}
+			continue
+		}
+		speaker := speakers[i]
+		// 同一说话人连续的台词（中间的叙述只是“他说”）合并为一段
+		if n := len(elements); n >= 2 && speaker[1] == "" && elements[n-1].Type == ElementDialogue &&
+			lastSpeaker(elements) == speaker[0] {
+			elements[n-1].Text += p.text
+			continue
+		}
+		elements = append(elements, ScriptElement{Type: ElementCharacter, Text: speaker[0]})
+		if speaker[1] != "" {
+			elements = append(elements, ScriptElement{Type: ElementParenthetical, Text: "(" + speaker[1] + ")"})
+		}
+		elements = append(elements, ScriptElement{Type: ElementDialogue, Text: p.text})
+	}
+	return elements
+}
+
+// lastSpeaker 最后一个角色元素的角色名
+func lastSpeaker(elements []ScriptElement) string {
+	for i := len(elements) - 1; i >= 0; i-- {
+		if elements[i].Type == ElementCharacter {
+			return elements[i].Text
+		}
+	}
+	return ""
+}
+
+// lastClause 把引号前的叙述拆为前文和最后一个分句
+func lastClause(text string) (string, string) {
+	runes := []rune(strings.TrimRight(text, " "))
+	for i := len(runes) - 1; i >= 0; i-- {
+		if strings.ContainsRune("。！？；!?;", runes[i]) {
+			return string(runes[:i+1]), strings.TrimSpace(string(runes[i+1:]))
+		}
+	}
+	return "", strings.TrimSpace(string(runes))
+}
+
+// firstClause 把引号后的叙述拆为第一个分句和后文
+func firstClause(text string) (string, string) {
+	runes := []rune(strings.TrimLeft(text, "，, "))
+	for i, r := range runes {
+		if strings.ContainsRune("。！？；，!?;,", r) {
+			return string(runes[:i]), string(runes[i+1:])
+		}
+	}
+	return string(runes), ""
+}
+
+// leadingCharacter 分句以出场角色名开头时返回角色名和剩余部分，较长的名字优先
+func leadingCharacter(clause string, characters []string) (string, string) {
+	best := ""
+	for _, name := range characters {
+		if name != "" && strings.HasPrefix(clause, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return "", ""
+	}
+	return best, strings.TrimPrefix(clause, best)
+}
+
+// speechManner 去掉说话动词和标点后剩下的说话方式，如“冷笑道”得到“冷笑”；没有说话动词时整句作为提示
+func speechManner(rest string) string {
+	rest = strings.Trim(rest, "：:，,。 ")
+	for _, verb := range speechVerbs {
+		if strings.HasSuffix(rest, verb) {
+			return strings.TrimSpace(strings.TrimSuffix(rest, verb))
+		}
+	}
+	return rest
+}
+
+// quotePart 段落中引号内外的片段
+type quotePart struct {
+	text   string
+	quoted bool
+}
+
+// splitQuotes 按中文双引号和直角引号切分段落
+func splitQuotes(paragraph string) []quotePart {
+	parts := make([]quotePart, 0)
+	var current strings.Builder
+	quoted := false
+	for _, r := range paragraph {
+		switch {
+		case !quoted && (r == '“' || r == '「'):
+			if current.Len() > 0 {
+				parts = append(parts, quotePart{text: current.String()})
+			}
+			current.Reset()
+			quoted = true
+		case quoted && (r == '”' || r == '」'):
+			parts = append(parts, quotePart{text: current.String(), quoted: true})
+			current.Reset()
+			quoted = false
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		parts = append(parts, quotePart{text: current.String(), quoted: quoted})
+	}
+	return parts
+}
+
+// RenderFountain 渲染为 Fountain 纯文本
+// 中文场景标题和角色名不满足 Fountain 的自动识别规则，分别用“.”和“@”强制标记
+func RenderFountain(sp *Screenplay) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("Title: %s\n\n", sp.Title))
+	for _, e := range sp.Elements {
+		switch e.Type {
+		case ElementHeading:
+			sb.WriteString("\n." + e.Text + "\n\n")
+		case ElementCharacter:
+			sb.WriteString("@" + e.Text + "\n")
+		case ElementParenthetical:
+			sb.WriteString(e.Text + "\n")
+		case ElementDialogue:
+			sb.WriteString(e.Text + "\n\n")
+		case ElementTransition:
+			sb.WriteString("> " + e.Text + "\n\n")
+		default:
+			sb.WriteString(e.Text + "\n\n")
+		}
+	}
+	return sb.String()
+}
+
+// RenderFDX 渲染为 Final Draft XML
+func RenderFDX(sp *Screenplay) string {
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="UTF-8" standalone="no" ?>` + "\n")
+	sb.WriteString(`<FinalDraft DocumentType="Script" Template="No" Version="5">` + "\n")
+	sb.WriteString("<Content>\n")
+	for _, e := range sp.Elements {
+		sb.WriteString(fmt.Sprintf(`<Paragraph Type="%s"><Text>`, e.Type))
+		xml.EscapeText(&sb, []byte(e.Text))
+		sb.WriteString("</Text></Paragraph>\n")
+	}
+	sb.WriteString("</Content>\n")
+	sb.WriteString("<TitlePage><Content><Paragraph Type=\"Action\" Alignment=\"Center\"><Text>")
+	xml.EscapeText(&sb, []byte(sp.Title))
+	sb.WriteString("</Text></Paragraph></Content></TitlePage>\n")
+	sb.WriteString("</FinalDraft>\n")
+	return sb.String()
+}
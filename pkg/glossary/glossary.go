@@ -0,0 +1,148 @@
+// Package glossary 术语表 - 检查正文中专有名词的写法，把已登记的错误写法和只差一个字的近似写法改为规范写法
+package glossary
+
+import (
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// minNearMissRunes 参与近似写法检测的最短术语字数，两字术语只差一字时多为不同的词
+const minNearMissRunes = 3
+
+// Find 查找正文中术语写法的偏差
+// 近似写法：与规范写法等长、只有一个汉字不同，且不同的字不在首位（首字不同多为另一个名称，如东/西）；
+// 近似写法本身是术语表中的其他术语，或与正文中规范写法的出现位置重叠时不算偏差
+func Find(text string, terms []models.GlossaryTerm) []models.GlossaryCorrection {
+	known := make(map[string]bool)
+	for _, t := range terms {
+		known[t.Term] = true
+		for _, a := range t.Aliases {
+			known[a] = true
+		}
+	}
+
+	runes := []rune(text)
+	covered := make([]bool, len(runes))
+	for _, t := range terms {
+		markCovered(text, t.Term, covered)
+	}
+
+	counts := make(map[[2]string]*models.GlossaryCorrection)
+	record := func(variant, canonical, reason string) {
+		key := [2]string{variant, canonical}
+		if c, ok := counts[key]; ok {
+			c.Count++
+			return
+		}
+		counts[key] = &models.GlossaryCorrection{Variant: variant, Canonical: canonical, Count: 1, Reason: reason}
+	}
+
+	for _, t := range terms {
+		for _, a := range t.Aliases {
+			if a == "" || a == t.Term {
+				continue
+			}
+			for n := strings.Count(text, a); n > 0; n-- {
+				record(a, t.Term, models.CorrectionAlias)
+			}
+		}
+
+		term := []rune(t.Term)
+		if len(term) < minNearMissRunes {
+			continue
+		}
+		for i := 0; i+len(term) <= len(runes); i++ {
+			if !nearMiss(runes[i:i+len(term)], term) || anyCovered(covered[i:i+len(term)]) {
+				continue
+			}
+			variant := string(runes[i : i+len(term)])
+			if known[variant] {
+				continue
+			}
+			record(variant, t.Term, models.CorrectionNearMiss)
+		}
+	}
+
+	result := make([]models.GlossaryCorrection, 0, len(counts))
+	for _, c := range counts {
+		result = append(result, *c)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Canonical != result[j].Canonical {
+			return result[i].Canonical < result[j].Canonical
+		}
+		return result[i].Variant < result[j].Variant
+	})
+	return result
+}
+
+// Normalize 把正文中的偏差写法改为规范写法，返回校正后的正文和校正记录
+func Normalize(text string, terms []models.GlossaryTerm) (string, []models.GlossaryCorrection) {
+	corrections := Find(text, terms)
+	return Apply(text, corrections), corrections
+}
+
+// Apply 按校正记录替换正文，较长的写法先替换
+func Apply(text string, corrections []models.GlossaryCorrection) string {
+	if len(corrections) == 0 {
+		return text
+	}
+	ordered := make([]models.GlossaryCorrection, len(corrections))
+	copy(ordered, corrections)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return utf8.RuneCountInString(ordered[i].Variant) > utf8.RuneCountInString(ordered[j].Variant)
+	})
+	for _, c := range ordered {
+		text = strings.ReplaceAll(text, c.Variant, c.Canonical)
+	}
+	return text
+}
+
+// nearMiss 两段等长文字是否只有一个汉字不同且不在首位
+func nearMiss(window, term []rune) bool {
+	diff := -1
+	for i := range term {
+		if window[i] == term[i] {
+			continue
+		}
+		if diff >= 0 || !unicode.Is(unicode.Han, window[i]) {
+			return false
+		}
+		diff = i
+	}
+	return diff > 0
+}
+
+// markCovered 标记正文中规范写法出现的位置（按字）
+func markCovered(text, term string, covered []bool) {
+	if term == "" {
+		return
+	}
+	n := utf8.RuneCountInString(term)
+	offset := 0
+	for {
+		idx := strings.Index(text[offset:], term)
+		if idx < 0 {
+			return
+		}
+		start := utf8.RuneCountInString(text[:offset+idx])
+		for i := start; i < start+n && i < len(covered); i++ {
+			covered[i] = true
+		}
+		offset += idx + len(term)
+	}
+}
+
+// anyCovered 是否有位置已被标记
+func anyCovered(covered []bool) bool {
+	for _, c := range covered {
+		if c {
+			return true
+		}
+	}
+	return false
+}
@@ -7,7 +7,8 @@ import (
 	"os"
 	"sync"
 
-	"github.com/xlei/xupu/internal/models"
+	"github.com/glebarez/sqlite"
+	xdb "github.com/xlei/xupu/pkg/db"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -32,19 +33,14 @@ func Get() *gorm.DB {
 
 // initDB 初始化数据库连接
 func initDB() (*gorm.DB, error) {
-	// 从环境变量获取数据库配置
-	host := getEnv("DB_HOST", "localhost")
-	port := getEnv("DB_PORT", "5432")
-	user := getEnv("DB_USER", "postgres")
-	password := getEnv("DB_PASSWORD", "")
-	dbname := getEnv("DB_NAME", "xupu")
-
-	// 构建连接字符串
-	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=Asia/Shanghai",
-		host, port, user, password, dbname)
+	// DB_DRIVER=sqlite 时与 db.Get() 共用同一个本地数据库文件
+	dialector := postgresDialector()
+	if getEnv("DB_DRIVER", "postgres") == "sqlite" {
+		dialector = sqlite.Open(xdb.SQLiteDSN(getEnv("DB_PATH", xdb.DefaultSQLitePath)))
+	}
 
 	// 连接数据库
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+	db, err := gorm.Open(dialector, &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Info),
 	})
 	if err != nil {
@@ -52,21 +48,31 @@ func initDB() (*gorm.DB, error) {
 	}
 
 	// 自动迁移表结构
-	if err := db.AutoMigrate(
-		&models.User{},
-		&models.AuthToken{},
-		&models.Chapter{}, // 章节表
-		&models.SysConfig{},
-		&models.PromptTemplate{},
-		&models.NarrativeTemplate{},
-	); err != nil {
-		return nil, fmt.Errorf("failed to migrate database: %w", err)
+	if !xdb.SkipMigrate() {
+		if err := xdb.RunMigrations(db); err != nil {
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
 	}
 
 	log.Println("GORM database connected successfully")
 	return db, nil
 }
 
+// postgresDialector 从环境变量构建PostgreSQL连接
+func postgresDialector() gorm.Dialector {
+	host := getEnv("DB_HOST", "localhost")
+	port := getEnv("DB_PORT", "5432")
+	user := getEnv("DB_USER", "postgres")
+	password := getEnv("DB_PASSWORD", "")
+	dbname := getEnv("DB_NAME", "xupu")
+
+	// 构建连接字符串
+	dsn := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable TimeZone=Asia/Shanghai",
+		host, port, user, password, dbname)
+
+	return postgres.Open(dsn)
+}
+
 // getEnv 获取环境变量，支持默认值
 func getEnv(key, defaultVal string) string {
 	if val := os.Getenv(key); val != "" {
@@ -0,0 +1,125 @@
+// Package items 关键物品的去向追踪
+// 物品来自世界术语表、蓝图提取或手工登记；每章分析时记录转手、遗失和毁坏，
+// 写后续场景前据此算出各物品当前在谁手中，避免物品凭空出现在别人手里。
+package items
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// Change 章节分析得到的一次去向变化，物品按名称或别名对应
+type Change struct {
+	Item      string
+	From      string
+	To        string
+	Location  string
+	Note      string
+	Destroyed bool
+	Evidence  string
+}
+
+// Holdings 第 chapter 章开始时各物品的去向，只计此前登场的物品和此前各章的变化
+func Holdings(items []models.Item, transfers []models.ItemTransfer, chapter int) []models.ItemHolding {
+	sorted := make([]models.ItemTransfer, len(transfers))
+	copy(sorted, transfers)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Chapter < sorted[j].Chapter })
+
+	holdings := make([]models.ItemHolding, 0, len(items))
+	index := make(map[string]int, len(items))
+	for _, item := range items {
+		if item.Chapter >= chapter && item.Chapter > 0 {
+			continue
+		}
+		index[item.ID] = len(holdings)
+		holdings = append(holdings, models.ItemHolding{
+			ItemID:   item.ID,
+			Name:     item.Name,
+			Holder:   item.Holder,
+			Location: item.Location,
+			Since:    item.Chapter,
+		})
+	}
+	for _, t := range sorted {
+		if t.Chapter >= chapter {
+			break
+		}
+		i, ok := index[t.ItemID]
+		if !ok {
+			continue
+		}
+		h := &holdings[i]
+		h.Holder = t.To
+		h.Location = t.Location
+		h.Destroyed = h.Destroyed || t.Destroyed
+		h.Since = t.Chapter
+	}
+	return holdings
+}
+
+// Load 第 chapter 章开始时项目各物品的去向，出错时返回空
+func Load(database db.Database, projectID string, chapter int) []models.ItemHolding {
+	if projectID == "" {
+		return nil
+	}
+	list, err := database.ListItems(projectID)
+	if err != nil || len(list) == 0 {
+		return nil
+	}
+	transfers, err := database.ListItemTransfers(projectID)
+	if err != nil {
+		return nil
+	}
+	return Holdings(list, transfers, chapter)
+}
+
+// Find 按名称或别名查找物品
+func Find(list []models.Item, name string) *models.Item {
+	name = strings.TrimSpace(name)
+	for i := range list {
+		for _, n := range list[i].Names() {
+			if n == name {
+				return &list[i]
+			}
+		}
+	}
+	return nil
+}
+
+// RecordChapter 用章节分析的结果替换该章从正文提取的去向记录，手工登记的记录保留
+// 对应不上已登记物品的变化忽略，返回忽略的物品名
+func RecordChapter(database db.Database, projectID string, chapter int, changes []Change) ([]string, error) {
+	list, err := database.ListItems(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfers := make([]models.ItemTransfer, 0, len(changes))
+	unknown := make([]string, 0)
+	for _, c := range changes {
+		item := Find(list, c.Item)
+		if item == nil {
+			if c.Item != "" {
+				unknown = append(unknown, c.Item)
+			}
+			continue
+		}
+		transfers = append(transfers, models.ItemTransfer{
+			ID:        db.GenerateID("transfer"),
+			ProjectID: projectID,
+			ItemID:    item.ID,
+			Chapter:   chapter,
+			From:      strings.TrimSpace(c.From),
+			To:        strings.TrimSpace(c.To),
+			Location:  strings.TrimSpace(c.Location),
+			Note:      c.Note,
+			Destroyed: c.Destroyed,
+			Evidence:  c.Evidence,
+			Source:    models.ItemFromProse,
+		})
+	}
+	return unknown, database.ReplaceChapterItemTransfers(projectID, chapter, transfers)
+}
@@ -0,0 +1,133 @@
+// Package jsonx 从LLM输出中提取和修复JSON
+// LLM返回的JSON常夹带前后说明文字、markdown代码块、注释、尾逗号、单引号，或因截断而缺少结尾，
+// Extract 负责定位JSON，Repair 按 jsonrepair 式的启发规则修正常见语法问题
+package jsonx
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// Extract 从文本中提取第一个完整的JSON对象或数组
+// 优先取 ```json 代码块之后的内容；按括号配对并跳过字符串，代码块嵌套或字符串内含 ``` 时也能正确定位；
+// 没有配对完整的块时返回从第一个括号开始的剩余文本（可交给 Repair 补全），找不到括号时原样返回
+func Extract(s string) string {
+	s = strings.TrimPrefix(s, "\ufeff")
+
+	from := 0
+	if i := strings.Index(s, "```json"); i >= 0 {
+		from = i + len("```json")
+	} else if i := strings.Index(s, "```"); i >= 0 && strings.IndexAny(s[:i], "{[") < 0 {
+		from = i + 3
+	}
+
+	if block, ok := firstBlock(s[from:]); ok {
+		return block
+	}
+	if from > 0 {
+		if block, ok := firstBlock(s); ok {
+			return block
+		}
+	}
+
+	if i := strings.IndexAny(s[from:], "{["); i >= 0 {
+		return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(s[from+i:]), "```"))
+	}
+	return s
+}
+
+// firstBlock 返回第一个括号配对完整的 {...} 或 [...] 块
+// 以 [ 开头但内容不像JSON数组（如 "[注意] 以下是结果"）时跳过继续查找
+func firstBlock(s string) (string, bool) {
+	for offset := 0; offset < len(s); {
+		i := strings.IndexAny(s[offset:], "{[")
+		if i < 0 {
+			return "", false
+		}
+		start := offset + i
+		if end, ok := matchBracket(s, start); ok {
+			block := s[start : end+1]
+			if s[start] == '{' || looksLikeArray(block) {
+				return block, true
+			}
+		}
+		offset = start + 1
+	}
+	return "", false
+}
+
+// looksLikeArray 数组块的第一个非空字符应是值的开头
+func looksLikeArray(block string) bool {
+	inner := strings.TrimSpace(block[1:])
+	if inner == "" {
+		return false
+	}
+	switch inner[0] {
+	case '{', '[', '"', '\'', ']', '-', 't', 'f', 'n':
+		return true
+	}
+	return inner[0] >= '0' && inner[0] <= '9'
+}
+
+// matchBracket 找到与 start 处括号配对的位置，跳过字符串内容
+func matchBracket(s string, start int) (int, bool) {
+	depth := 0
+	var quote byte
+	for i := start; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			switch c {
+			case '\\':
+				i++
+			case quote:
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			quote = c
+		case '\'':
+			// 只把紧跟在结构符号后的单引号视为字符串开头，避免误伤英文缩写
+			if prev := prevNonSpace(s, i); prev == '{' || prev == '[' || prev == ',' || prev == ':' {
+				quote = c
+			}
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i, true
+			}
+		}
+	}
+	return -1, false
+}
+
+func prevNonSpace(s string, i int) byte {
+	for j := i - 1; j >= 0; j-- {
+		if c := s[j]; c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return c
+		}
+	}
+	return 0
+}
+
+// Unmarshal 解析LLM输出：依次尝试原文、提取后的文本、修复后的文本，全部失败时返回原文的解析错误
+func Unmarshal(s string, v interface{}) error {
+	err := json.Unmarshal([]byte(s), v)
+	if err == nil {
+		return nil
+	}
+
+	extracted := Extract(s)
+	if extracted != s {
+		if json.Unmarshal([]byte(extracted), v) == nil {
+			return nil
+		}
+	}
+	if json.Unmarshal([]byte(Repair(extracted)), v) == nil {
+		return nil
+	}
+	return err
+}
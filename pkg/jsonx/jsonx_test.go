@@ -0,0 +1,238 @@
+// Package jsonx JSON提取与修复测试
+package jsonx
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+// TestExtract 测试JSON定位
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{
+			name:  "纯JSON",
+			input: `{"key": "value"}`,
+			want:  `{"key": "value"}`,
+		},
+		{
+			name:  "markdown代码块",
+			input: "```json\n{\"key\": \"value\"}\n```",
+			want:  `{"key": "value"}`,
+		},
+		{
+			name:  "无语言标记的代码块",
+			input: "```\n{\"key\": \"value\"}\n```",
+			want:  `{"key": "value"}`,
+		},
+		{
+			name:  "前后说明文字",
+			input: `好的，结果如下：{"key": "value"} 希望对你有帮助`,
+			want:  `{"key": "value"}`,
+		},
+		{
+			name:  "字符串中包含代码块标记",
+			input: "```json\n{\"code\": \"```go\\nfmt.Println()\\n```\"}\n```",
+			want:  "{\"code\": \"```go\\nfmt.Println()\\n```\"}",
+		},
+		{
+			name:  "字符串中包含括号",
+			input: `说明 {"a": "}{", "b": {"c": 1}} 结尾 {"d": 2}`,
+			want:  `{"a": "}{", "b": {"c": 1}}`,
+		},
+		{
+			name:  "数组",
+			input: "结果：\n[{\"a\": 1}, {\"a\": 2}]",
+			want:  `[{"a": 1}, {"a": 2}]`,
+		},
+		{
+			name:  "跳过非JSON的方括号",
+			input: `[注意] 以下是结果 {"a": 1}`,
+			want:  `{"a": 1}`,
+		},
+		{
+			name:  "代码块前的说明中没有括号",
+			input: "下面是JSON：\n```json\n{\"a\": [1, 2]}\n```\n以上。",
+			want:  `{"a": [1, 2]}`,
+		},
+		{
+			name:  "截断的JSON返回剩余文本",
+			input: "```json\n{\"a\": \"未完",
+			want:  `{"a": "未完`,
+		},
+		{
+			name:  "没有JSON时原样返回",
+			input: "没有结构化内容",
+			want:  "没有结构化内容",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Extract(tt.input); got != tt.want {
+				t.Errorf("Extract() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRepair 测试修复后能被标准库解析，且语义符合预期
+func TestRepair(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string // 期望的等价JSON
+	}{
+		{
+			name:  "尾逗号",
+			input: `{"a": 1, "b": [1, 2, ], }`,
+			want:  `{"a": 1, "b": [1, 2]}`,
+		},
+		{
+			name:  "单引号",
+			input: `{'a': 'it\'s', 'b': ['x', 'y']}`,
+			want:  `{"a": "it's", "b": ["x", "y"]}`,
+		},
+		{
+			name:  "单引号字符串中的双引号",
+			input: `{'a': '他说"好"'}`,
+			want:  `{"a": "他说\"好\""}`,
+		},
+		{
+			name:  "未加引号的键",
+			input: `{name: "张三", age: 18}`,
+			want:  `{"name": "张三", "age": 18}`,
+		},
+		{
+			name:  "Python字面量",
+			input: `{"a": True, "b": False, "c": None}`,
+			want:  `{"a": true, "b": false, "c": null}`,
+		},
+		{
+			name:  "注释",
+			input: "{\n  // 角色名\n  \"name\": \"李四\", /* 年龄 */ \"age\": 20\n}",
+			want:  `{"name": "李四", "age": 20}`,
+		},
+		{
+			name:  "字符串中未转义的引号",
+			input: `{"line": "他低声说"走吧"，转身离开", "n": 1}`,
+			want:  `{"line": "他低声说\"走吧\"，转身离开", "n": 1}`,
+		},
+		{
+			name:  "字符串中的换行",
+			input: "{\"content\": \"第一段\n第二段\"}",
+			want:  `{"content": "第一段\n第二段"}`,
+		},
+		{
+			name:  "缺少逗号",
+			input: "{\n  \"a\": \"x\"\n  \"b\": 2\n  \"c\": [1 2]\n}",
+			want:  `{"a": "x", "b": 2, "c": [1, 2]}`,
+		},
+		{
+			name:  "全角冒号",
+			input: `{"a"：1}`,
+			want:  `{"a": 1}`,
+		},
+		{
+			name:  "截断在字符串中",
+			input: `{"a": [{"b": "未完`,
+			want:  `{"a": [{"b": "未完"}]}`,
+		},
+		{
+			name:  "截断在冒号后",
+			input: `{"a": 1, "b":`,
+			want:  `{"a": 1, "b": null}`,
+		},
+		{
+			name:  "代码块标记",
+			input: "```json\n{\"a\": 1,}\n```",
+			want:  `{"a": 1}`,
+		},
+		{
+			name:  "非法转义",
+			input: `{"path": "C:\data"}`,
+			want:  `{"path": "C:\\data"}`,
+		},
+		{
+			name:  "合法JSON保持不变",
+			input: `{"a": {"b": [1, 2.5, -3e2, "x\"y"]}, "c": true}`,
+			want:  `{"a": {"b": [1, 2.5, -3e2, "x\"y"]}, "c": true}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repaired := Repair(tt.input)
+
+			var got, want interface{}
+			if err := json.Unmarshal([]byte(repaired), &got); err != nil {
+				t.Fatalf("Repair() = %q 无法解析: %v", repaired, err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatalf("期望值无法解析: %v", err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Repair() = %s, want %s", repaired, tt.want)
+			}
+		})
+	}
+}
+
+// TestUnmarshal 测试解析流程：原文、提取、修复依次尝试
+func TestUnmarshal(t *testing.T) {
+	type result struct {
+		Name  string   `json:"name"`
+		Score int      `json:"score"`
+		Tags  []string `json:"tags"`
+	}
+
+	tests := []struct {
+		name    string
+		input   string
+		want    result
+		wantErr bool
+	}{
+		{
+			name:  "合法JSON",
+			input: `{"name": "a", "score": 1, "tags": ["x"]}`,
+			want:  result{Name: "a", Score: 1, Tags: []string{"x"}},
+		},
+		{
+			name:  "代码块加说明文字",
+			input: "以下是评分：\n```json\n{\"name\": \"b\", \"score\": 2, \"tags\": []}\n```",
+			want:  result{Name: "b", Score: 2, Tags: []string{}},
+		},
+		{
+			name:  "需要修复",
+			input: "```json\n{name: 'c', score: 3, tags: ['y',],}\n```",
+			want:  result{Name: "c", Score: 3, Tags: []string{"y"}},
+		},
+		{
+			name:  "截断",
+			input: `{"name": "d", "score": 4, "tags": ["z"`,
+			want:  result{Name: "d", Score: 4, Tags: []string{"z"}},
+		},
+		{
+			name:    "无法修复",
+			input:   "完全没有JSON",
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got result
+			err := Unmarshal(tt.input, &got)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Unmarshal() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Unmarshal() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,323 @@
+package jsonx
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Repair 按启发规则修复不合法的JSON文本，输入应已由 Extract 定位
+// 处理：代码块标记、注释、单引号字符串、未加引号的键和字符串值、Python风格字面量、尾逗号、
+// 缺失的逗号、字符串中未转义的引号和换行、截断导致的未闭合字符串/括号
+// 修复结果不保证合法，调用方仍需检查 json.Unmarshal 的错误
+func Repair(s string) string {
+	s = strings.TrimSpace(s)
+	s = strings.TrimPrefix(s, "```json")
+	s = strings.TrimPrefix(s, "```")
+	s = strings.TrimSuffix(s, "```")
+	s = strings.TrimSpace(s)
+
+	r := &repairer{src: s}
+	r.run()
+	return r.out.String()
+}
+
+type repairer struct {
+	src   string
+	pos   int
+	out   strings.Builder
+	stack []byte // 未闭合的 { 或 [
+}
+
+func (r *repairer) run() {
+	for r.pos < len(r.src) {
+		c := r.src[r.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			r.out.WriteByte(c)
+			r.pos++
+		case c == '/' && r.peek(1) == '/':
+			r.skipUntil("\n")
+		case c == '/' && r.peek(1) == '*':
+			r.pos += 2
+			r.skipUntil("*/")
+			r.pos += 2
+		case c == '{' || c == '[':
+			r.beforeValue()
+			r.out.WriteByte(c)
+			r.stack = append(r.stack, c)
+			r.pos++
+		case c == '}' || c == ']':
+			r.close()
+			r.pos++
+		case c == ',':
+			last := r.lastSignificant()
+			if last != ',' && last != '{' && last != '[' && last != 0 {
+				r.out.WriteByte(',')
+			}
+			r.pos++
+		case c == ':':
+			r.out.WriteByte(':')
+			r.pos++
+		case strings.HasPrefix(r.src[r.pos:], "："):
+			// 全角冒号
+			r.out.WriteByte(':')
+			r.pos += len("：")
+		case c == '"' || c == '\'':
+			r.beforeValue()
+			r.str(c)
+		case c == '-' || (c >= '0' && c <= '9'):
+			r.beforeValue()
+			r.number()
+		default:
+			r.beforeValue()
+			r.word()
+		}
+	}
+	r.finish()
+}
+
+func (r *repairer) peek(n int) byte {
+	if r.pos+n < len(r.src) {
+		return r.src[r.pos+n]
+	}
+	return 0
+}
+
+func (r *repairer) skipUntil(end string) {
+	if i := strings.Index(r.src[r.pos:], end); i >= 0 {
+		r.pos += i
+	} else {
+		r.pos = len(r.src)
+	}
+}
+
+// lastSignificant 输出中最后一个非空白字符
+func (r *repairer) lastSignificant() byte {
+	out := r.out.String()
+	for i := len(out) - 1; i >= 0; i-- {
+		if c := out[i]; c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			return c
+		}
+	}
+	return 0
+}
+
+// beforeValue 在新值开始前补上缺失的逗号（前一个值已结束，且不在冒号之后）
+func (r *repairer) beforeValue() {
+	if len(r.stack) == 0 {
+		return
+	}
+	switch r.lastSignificant() {
+	case '"', '}', ']', 'e', 'l', '0', '1', '2', '3', '4', '5', '6', '7', '8', '9':
+		r.trimTrailingSpace()
+		r.out.WriteByte(',')
+	}
+}
+
+// close 闭合当前容器，去掉尾逗号；遇到不匹配的闭合符号时按栈顶补正确的符号
+func (r *repairer) close() {
+	r.trimTrailingComma()
+	if len(r.stack) == 0 {
+		return
+	}
+	r.fillMissingValue()
+	top := r.stack[len(r.stack)-1]
+	r.stack = r.stack[:len(r.stack)-1]
+	if top == '{' {
+		r.out.WriteByte('}')
+	} else {
+		r.out.WriteByte(']')
+	}
+}
+
+// finish 截断时补全未闭合的括号
+func (r *repairer) finish() {
+	for len(r.stack) > 0 {
+		r.close()
+	}
+	r.trimTrailingComma()
+}
+
+// fillMissingValue 键后缺少值时补 null
+func (r *repairer) fillMissingValue() {
+	if r.lastSignificant() == ':' {
+		r.out.WriteString("null")
+	}
+}
+
+func (r *repairer) trimTrailingSpace() {
+	out := strings.TrimRight(r.out.String(), " \t\r\n")
+	r.out.Reset()
+	r.out.WriteString(out)
+}
+
+func (r *repairer) trimTrailingComma() {
+	out := strings.TrimRight(r.out.String(), " \t\r\n")
+	out = strings.TrimSuffix(out, ",")
+	r.out.Reset()
+	r.out.WriteString(out)
+}
+
+// str 复制字符串，统一为双引号并修正转义
+// 字符串中的引号只有在其后紧跟结构符号（或文本结束）时才视为结束，否则按内容转义
+func (r *repairer) str(quote byte) {
+	r.out.WriteByte('"')
+	r.pos++
+
+	for r.pos < len(r.src) {
+		c := r.src[r.pos]
+		switch {
+		case c == '\\':
+			next := r.peek(1)
+			switch {
+			case next == '\'':
+				r.out.WriteByte('\'')
+			case next != 0 && strings.IndexByte(`"\/bfnrtu`, next) >= 0:
+				r.out.WriteByte('\\')
+				r.out.WriteByte(next)
+			default:
+				r.out.WriteString(`\\`)
+				r.pos++
+				continue
+			}
+			r.pos += 2
+		case c == quote:
+			if r.endsString() {
+				r.out.WriteByte('"')
+				r.pos++
+				return
+			}
+			if quote == '"' {
+				r.out.WriteString(`\"`)
+			} else {
+				r.out.WriteByte('\'')
+			}
+			r.pos++
+		case c == '"':
+			// 单引号字符串中的双引号
+			r.out.WriteString(`\"`)
+			r.pos++
+		case c == '\n':
+			r.out.WriteString(`\n`)
+			r.pos++
+		case c == '\r':
+			r.pos++
+		case c == '\t':
+			r.out.WriteString(`\t`)
+			r.pos++
+		case c < 0x20:
+			r.pos++
+		default:
+			_, size := utf8.DecodeRuneInString(r.src[r.pos:])
+			r.out.WriteString(r.src[r.pos : r.pos+size])
+			r.pos += size
+		}
+	}
+	// 截断在字符串中间
+	r.out.WriteByte('"')
+}
+
+// endsString 当前引号之后是否紧跟结构符号，用于区分结束引号和正文中的引号；
+// 换行后紧跟引号视为漏写逗号的下一个键
+func (r *repairer) endsString() bool {
+	newline := false
+	for i := r.pos + 1; i < len(r.src); i++ {
+		switch c := r.src[i]; c {
+		case ' ', '\t', '\r':
+			continue
+		case '\n':
+			newline = true
+			continue
+		case ',', ':', '}', ']':
+			return true
+		case '"':
+			return newline
+		case 0xEF:
+			// 全角冒号 "：" 的首字节
+			return strings.HasPrefix(r.src[i:], "：")
+		case '/':
+			return i+1 < len(r.src) && (r.src[i+1] == '/' || r.src[i+1] == '*')
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func (r *repairer) number() {
+	start := r.pos
+	for r.pos < len(r.src) && strings.IndexByte("+-0123456789.eE", r.src[r.pos]) >= 0 {
+		r.pos++
+	}
+	token := strings.TrimRight(r.src[start:r.pos], ".")
+	if token == "-" || token == "" {
+		r.out.WriteString("null")
+		return
+	}
+	r.out.WriteString(token)
+}
+
+// word 处理未加引号的内容：键加引号，Python/JS 字面量转换，其余作为字符串值
+func (r *repairer) word() {
+	start := r.pos
+	for r.pos < len(r.src) {
+		ch, size := utf8.DecodeRuneInString(r.src[r.pos:])
+		if ch == ':' || ch == ',' || ch == '}' || ch == ']' || ch == '\n' || ch == '"' ||
+			(ch != ' ' && unicode.IsSpace(ch)) || ch == '：' {
+			break
+		}
+		r.pos += size
+	}
+	token := strings.TrimSpace(r.src[start:r.pos])
+	if token == "" {
+		// 无法识别的单个字符，跳过以保证前进
+		if r.pos == start {
+			_, size := utf8.DecodeRuneInString(r.src[r.pos:])
+			r.pos += size
+		}
+		return
+	}
+
+	if r.isKey() {
+		r.out.WriteString(quoteString(token))
+		return
+	}
+
+	switch token {
+	case "true", "True", "TRUE":
+		r.out.WriteString("true")
+	case "false", "False", "FALSE":
+		r.out.WriteString("false")
+	case "null", "None", "NULL", "nil", "undefined", "NaN":
+		r.out.WriteString("null")
+	default:
+		r.out.WriteString(quoteString(token))
+	}
+}
+
+// isKey 当前裸词之后是否为冒号（即对象的键）
+func (r *repairer) isKey() bool {
+	if len(r.stack) == 0 || r.stack[len(r.stack)-1] != '{' {
+		return false
+	}
+	rest := strings.TrimLeft(r.src[r.pos:], " \t")
+	return strings.HasPrefix(rest, ":") || strings.HasPrefix(rest, "：")
+}
+
+func quoteString(s string) string {
+	var sb strings.Builder
+	sb.WriteByte('"')
+	for _, ch := range s {
+		switch ch {
+		case '"':
+			sb.WriteString(`\"`)
+		case '\\':
+			sb.WriteString(`\\`)
+		default:
+			sb.WriteRune(ch)
+		}
+	}
+	sb.WriteByte('"')
+	return sb.String()
+}
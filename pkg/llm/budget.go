@@ -0,0 +1,29 @@
+package llm
+
+import "errors"
+
+// ErrBudgetExceeded 预算已用尽，请求未发出
+var ErrBudgetExceeded = errors.New("LLM预算已用尽")
+
+// Budget 预算闸门，通过 Client.WithBudget 挂到客户端上
+// 每次请求发出前调用 Allow，返回错误（应包装 ErrBudgetExceeded）时请求不会发出；结算后调用 Spend 记账
+// 流式响应不返回用量，结束后按估算的用量记账
+type Budget interface {
+	Allow() error
+	Spend(u Usage)
+}
+
+// WithBudget 返回请求前检查指定预算的客户端副本（共享HTTP连接和限流器），用于按用户限额
+func (c *Client) WithBudget(b Budget) *Client {
+	cp := *c
+	cp.budget = b
+	return &cp
+}
+
+// allow 检查预算，未挂预算时放行
+func (c *Client) allow() error {
+	if c.budget == nil {
+		return nil
+	}
+	return c.budget.Allow()
+}
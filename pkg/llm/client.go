@@ -6,11 +6,17 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/joho/godotenv"
 	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/ctxbudget"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/logx"
 )
 
 // 初始化时加载环境变量
@@ -20,10 +26,55 @@ func init() {
 
 // Client LLM客户端
 type Client struct {
-	APIKey  string
-	BaseURL string
-	Model   string
-	httpCli *http.Client
+	APIKey   string
+	BaseURL  string
+	Model    string
+	Provider string // 提供商名称，与模型一起决定所用的限流器
+	httpCli  *http.Client
+
+	rateLimit   config.RateLimitConfig
+	logger      *slog.Logger // 为空时使用全局日志
+	instruction string       // 追加到每次请求系统提示词末尾的要求（如输出语言）
+	meter       *Meter       // 用量计量器，为空时不统计
+	budget      Budget       // 预算闸门，为空时不限制
+
+	module    string                // 按模块创建时的模块名，配置热加载后据此重新解析提供商和模型
+	mapping   *config.ModuleMapping // 返回给调用方的模块配置，热加载后原地更新
+	gen       uint64                // 解析模块配置时的配置版本
+	fallbacks []route               // 后备模型，主模型因提供商错误或超时失败时依次改用
+}
+
+// WithLogger 返回使用指定日志的客户端副本（共享HTTP连接和限流器），用于按请求/任务串联日志
+func (c *Client) WithLogger(l *slog.Logger) *Client {
+	cp := *c
+	cp.logger = l
+	return &cp
+}
+
+// WithInstruction 返回在每次请求的系统提示词末尾追加指定要求的客户端副本（共享HTTP连接和限流器）
+func (c *Client) WithInstruction(instruction string) *Client {
+	cp := *c
+	cp.instruction = instruction
+	return &cp
+}
+
+// systemMessages 系统提示词消息，追加客户端的附加要求
+func (c *Client) systemMessages(systemPrompt string) []Message {
+	if c.instruction != "" {
+		if systemPrompt != "" {
+			systemPrompt += "\n\n"
+		}
+		systemPrompt += c.instruction
+	}
+	if systemPrompt == "" {
+		return []Message{}
+	}
+	return []Message{{Role: "system", Content: systemPrompt}}
+}
+
+// log 当前客户端使用的日志
+func (c *Client) log() *slog.Logger {
+	return logx.Or(c.logger)
 }
 
 // Message 聊天消息
@@ -71,6 +122,10 @@ func (c *Client) SetMaxTokens(maxTokens int) {
 
 // NewClientWithConfig 使用配置创建LLM客户端
 func NewClientWithConfig(providerName string) (*Client, error) {
+	if DryRun() || providerName == MockProvider {
+		return newMockClient(""), nil
+	}
+
 	cfg := config.Get()
 	provider, ok := cfg.LLM.Providers[providerName]
 	if !ok {
@@ -83,10 +138,12 @@ func NewClientWithConfig(providerName string) (*Client, error) {
 	}
 
 	return &Client{
-		APIKey:  apiKey,
-		BaseURL: provider.BaseURL,
-		Model:   provider.Models.Default,
-		httpCli: &http.Client{Timeout: getTimeout()},
+		APIKey:    apiKey,
+		BaseURL:   provider.BaseURL,
+		Model:     provider.Models.Default,
+		Provider:  providerName,
+		httpCli:   &http.Client{Timeout: getTimeout()},
+		rateLimit: provider.RateLimit,
 	}, nil
 }
 
@@ -95,6 +152,11 @@ func NewClientWithConfig(providerName string) (*Client, error) {
 func NewClientForModule(moduleName string) (*Client, *config.ModuleMapping, error) {
 	cfg := config.Get()
 
+	if m, ok := cfg.LLM.ModuleMapping[moduleName]; DryRun() || (ok && m.Provider == MockProvider) {
+		mapping := mockModuleMapping(cfg, moduleName)
+		return newMockClient(mapping.Model), mapping, nil
+	}
+
 	mapping, provider, err := cfg.LLM.GetModuleConfig(moduleName)
 	if err != nil {
 		return nil, nil, err
@@ -106,15 +168,57 @@ func NewClientForModule(moduleName string) (*Client, *config.ModuleMapping, erro
 	}
 
 	client := &Client{
-		APIKey:  apiKey,
-		BaseURL: provider.BaseURL,
-		Model:   mapping.Model,
-		httpCli: &http.Client{Timeout: getTimeout()},
+		APIKey:    apiKey,
+		BaseURL:   provider.BaseURL,
+		Model:     mapping.Model,
+		Provider:  mapping.Provider,
+		httpCli:   &http.Client{Timeout: getTimeout()},
+		rateLimit: provider.RateLimit,
+		module:    moduleName,
+		mapping:   mapping,
+		gen:       config.Generation(),
+		fallbacks: resolveFallbacks(&cfg.LLM, moduleName, mapping.Fallbacks),
 	}
 
 	return client, mapping, nil
 }
 
+// refresh 配置热加载后按模块重新解析提供商、模型和限流配置，并原地更新模块配置
+// （调用方持有的温度、最大token数从下一次调用起生效）；解析失败时沿用原配置。
+// 返回是否发生了切换
+func (c *Client) refresh() bool {
+	gen := config.Generation()
+	if c.module == "" || c.gen == gen {
+		return false
+	}
+	c.gen = gen
+
+	mapping, provider, err := config.Get().LLM.GetModuleConfig(c.module)
+	var apiKey string
+	if err == nil {
+		apiKey, err = provider.GetAPIKey()
+	}
+	if err != nil {
+		c.log().Warn("配置热加载后解析模块配置失败，沿用原配置", "module", c.module, "error", err)
+		return false
+	}
+
+	switched := c.Provider != mapping.Provider || c.Model != mapping.Model
+	if switched {
+		c.log().Info("配置热加载，模块切换模型", "module", c.module, "from", c.Provider+"/"+c.Model, "to", mapping.Provider+"/"+mapping.Model)
+	}
+	c.APIKey = apiKey
+	c.BaseURL = provider.BaseURL
+	c.Model = mapping.Model
+	c.Provider = mapping.Provider
+	c.rateLimit = provider.RateLimit
+	c.fallbacks = resolveFallbacks(&config.Get().LLM, c.module, mapping.Fallbacks)
+	if c.mapping != nil {
+		*c.mapping = *mapping
+	}
+	return switched
+}
+
 // getTimeout 从配置获取超时时间，默认120秒
 func getTimeout() time.Duration {
 	cfg := config.Get()
@@ -131,10 +235,7 @@ func (c *Client) Generate(prompt string, systemPrompt string) (string, error) {
 
 // GenerateWithParams 使用指定参数生成文本
 func (c *Client) GenerateWithParams(prompt string, systemPrompt string, temperature float64, maxTokens int) (string, error) {
-	messages := []Message{}
-	if systemPrompt != "" {
-		messages = append(messages, Message{Role: "system", Content: systemPrompt})
-	}
+	messages := c.systemMessages(systemPrompt)
 	messages = append(messages, Message{Role: "user", Content: prompt})
 
 	reqBody := ChatRequest{
@@ -157,10 +258,7 @@ func (c *Client) GenerateJSONWithParams(prompt string, systemPrompt string, temp
 	// 添加JSON格式要求
 	jsonPrompt := prompt + "\n\n请直接以JSON格式返回结果，不要包含任何其他内容。"
 
-	messages := []Message{}
-	if systemPrompt != "" {
-		messages = append(messages, Message{Role: "system", Content: systemPrompt})
-	}
+	messages := c.systemMessages(systemPrompt)
 	messages = append(messages, Message{Role: "user", Content: jsonPrompt})
 
 	reqBody := ChatRequest{
@@ -175,16 +273,10 @@ func (c *Client) GenerateJSONWithParams(prompt string, systemPrompt string, temp
 		return nil, err
 	}
 
-	// 解析JSON
+	// 解析JSON：依次尝试原文、提取代码块/说明文字中的JSON、启发式修复
 	var result map[string]interface{}
-	err = json.Unmarshal([]byte(content), &result)
-	if err != nil {
-		// 尝试提取 ```json``` 中的内容
-		content = extractJSON(content)
-		err = json.Unmarshal([]byte(content), &result)
-		if err != nil {
-			return nil, fmt.Errorf("无法解析JSON: %w, 原始内容: %s", err, content[:min(200, len(content))])
-		}
+	if err := jsonx.Unmarshal(content, &result); err != nil {
+		return nil, fmt.Errorf("无法解析JSON: %w, 原始内容: %s", err, content[:min(200, len(content))])
 	}
 
 	return result, nil
@@ -197,42 +289,114 @@ func min(a, b int) int {
 	return b
 }
 
-// SendRequest 发送请求
+// SendRequest 发送请求，主模型因提供商错误或超时失败时依次改用后备模型
 func (c *Client) SendRequest(req ChatRequest) (string, error) {
-	resp, err := c.sendRequestInternal(req)
+	if prev := c.Model; c.refresh() && req.Model == prev {
+		req.Model = c.Model
+	}
+	content, err := c.send(req)
+	err = c.withFailover(err, nil, func(fc *Client) error {
+		req.Model = fc.Model
+		var ferr error
+		content, ferr = fc.send(req)
+		return ferr
+	})
+	return content, err
+}
+
+// send 向当前提供商/模型发送一次请求
+// 挂了预算的客户端先检查预算；请求先占用全局并发名额，再经所属提供商/模型的限流器排队放行，完成后按实际token用量结算
+func (c *Client) send(req ChatRequest) (string, error) {
+	c.fitContext(&req)
+	log := c.log().With("provider", c.Provider, "model", req.Model)
+	for _, m := range req.Messages {
+		log.Debug("LLM请求", "role", m.Role, logx.Prompt("content", m.Content))
+	}
+	if err := c.allow(); err != nil {
+		log.Warn("LLM请求被预算拦截", "error", err)
+		return "", err
+	}
+	start := time.Now()
+
+	var content string
+	var tokens int
+	var err error
+	if c.isMock() {
+		content, tokens, err = c.parseChat(c.mockChat(req), 0)
+	} else {
+		acquireSlot()
+		estimated := estimateTokens(req.Messages, req.MaxTokens)
+		var resp string
+		resp, err = c.sendRequestInternal(req, estimated)
+		releaseSlot()
+		if err == nil {
+			content, tokens, err = c.parseChat(resp, estimated)
+		}
+	}
+
+	elapsed := time.Since(start).Round(time.Millisecond)
+	recordCall(CallRecord{
+		Provider:  c.Provider,
+		Model:     req.Model,
+		ElapsedMS: elapsed.Milliseconds(),
+		Tokens:    tokens,
+		Failed:    err != nil,
+		At:        start,
+	})
 	if err != nil {
+		log.Warn("LLM调用失败", "elapsed", elapsed, "error", err)
 		return "", err
 	}
+	log.Info("LLM调用完成", "elapsed", elapsed, "response_chars", utf8.RuneCountInString(content))
+	log.Debug("LLM响应", logx.Prompt("content", content))
+	return content, nil
+}
 
+// parseChat 解析聊天响应，按实际用量结算限流器并记入计量器，返回内容和消耗的token数
+func (c *Client) parseChat(resp string, estimated int) (string, int, error) {
 	var chatResp ChatResponse
-	err = json.Unmarshal([]byte(resp), &chatResp)
+	err := json.Unmarshal([]byte(resp), &chatResp)
 	if err != nil {
-		return "", err
+		return "", 0, err
 	}
+	usage := requestUsage(c.Provider, c.Model, chatResp.Usage.PromptTokens, chatResp.Usage.CompletionTokens)
+	c.settle(estimated, usage)
 
 	if len(chatResp.Choices) == 0 {
-		return "", fmt.Errorf("API返回无内容")
+		return "", usage.TotalTokens, fmt.Errorf("API返回无内容")
 	}
 
-	return chatResp.Choices[0].Message.Content, nil
+	return chatResp.Choices[0].Message.Content, usage.TotalTokens, nil
 }
 
-// sendRequestInternal 内部请求方法
-func (c *Client) sendRequestInternal(req ChatRequest) (string, error) {
-	reqBody, err := json.Marshal(req)
-	if err != nil {
-		return "", err
+// settle 按实际用量结算限流器的预占，并记入计量器和预算
+func (c *Client) settle(estimated int, usage Usage) {
+	if estimated > 0 {
+		c.limiter().Settle(estimated, usage.TotalTokens)
+	}
+	c.meter.record(usage)
+	if c.budget != nil {
+		c.budget.Spend(usage)
 	}
+	reportUsage(c.Provider, c.Model, usage)
+}
 
-	httpReq, err := http.NewRequest("POST", c.BaseURL+"/chat/completions", bytes.NewReader(reqBody))
+// sendRequestInternal 内部请求方法
+func (c *Client) sendRequestInternal(req ChatRequest, tokens int) (string, error) {
+	reqBody, err := json.Marshal(req)
 	if err != nil {
 		return "", err
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
-
-	resp, err := c.httpCli.Do(httpReq)
+	resp, err := c.doLimited(func() (*http.Request, error) {
+		httpReq, err := http.NewRequest("POST", c.BaseURL+"/chat/completions", bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
+		return httpReq, nil
+	}, tokens)
 	if err != nil {
 		return "", err
 	}
@@ -244,7 +408,7 @@ func (c *Client) sendRequestInternal(req ChatRequest) (string, error) {
 	}
 
 	if resp.StatusCode != http.StatusOK {
-		return "", fmt.Errorf("API返回错误: %d, %s", resp.StatusCode, string(body))
+		return "", &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	return string(body), nil
@@ -261,11 +425,11 @@ func (c *Client) GenerateStream(prompt string, systemPrompt string, callback Str
 
 // GenerateStreamWithParams 使用指定参数流式生成文本
 func (c *Client) GenerateStreamWithParams(prompt string, systemPrompt string, temperature float64, maxTokens int, callback StreamCallback) error {
-	messages := []Message{}
-	if systemPrompt != "" {
-		messages = append(messages, Message{Role: "system", Content: systemPrompt})
-	}
-	messages = append(messages, Message{Role: "user", Content: prompt})
+	c.refresh()
+	req := ChatRequest{Model: c.Model, Messages: c.systemMessages(systemPrompt), MaxTokens: maxTokens}
+	req.Messages = append(req.Messages, Message{Role: "user", Content: prompt})
+	c.fitContext(&req)
+	messages := req.Messages
 
 	// 为了最小化修改，我们临时构建 map
 	reqMap := map[string]interface{}{
@@ -276,26 +440,49 @@ func (c *Client) GenerateStreamWithParams(prompt string, systemPrompt string, te
 		"stream":      true,
 	}
 
-	return c.sendStreamRequest(reqMap, callback)
+	// 已向调用方输出内容后不再切换后备模型，避免重复输出
+	delivered := false
+	tracked := func(chunk string) bool {
+		delivered = true
+		return callback(chunk)
+	}
+	tokens := estimateTokens(messages, maxTokens)
+	input := promptTokens(messages)
+	err := c.sendStreamRequest(reqMap, tokens, input, tracked)
+	return c.withFailover(err, func() bool { return !delivered }, func(fc *Client) error {
+		reqMap["model"] = fc.Model
+		return fc.sendStreamRequest(reqMap, tokens, input, tracked)
+	})
 }
 
-// sendStreamRequest 发送流式请求
-func (c *Client) sendStreamRequest(reqBody interface{}, callback StreamCallback) error {
-	jsonBody, err := json.Marshal(reqBody)
-	if err != nil {
+// sendStreamRequest 发送流式请求，tokens 为限流预占的token数，input 为估算的输入token数
+// 流式响应不返回用量，结束后按输入估算和已收到的输出估算用量，结算限流器并记入计量器和预算
+func (c *Client) sendStreamRequest(reqBody interface{}, tokens, input int, callback StreamCallback) error {
+	if err := c.allow(); err != nil {
 		return err
 	}
+	if c.isMock() {
+		return c.mockStream(reqBody, callback)
+	}
+
+	acquireSlot()
+	defer releaseSlot()
 
-	httpReq, err := http.NewRequest("POST", c.BaseURL+"/chat/completions", bytes.NewReader(jsonBody))
+	jsonBody, err := json.Marshal(reqBody)
 	if err != nil {
 		return err
 	}
 
-	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
-	httpReq.Header.Set("Accept", "text/event-stream")
-
-	resp, err := c.httpCli.Do(httpReq)
+	resp, err := c.doLimited(func() (*http.Request, error) {
+		httpReq, err := http.NewRequest("POST", c.BaseURL+"/chat/completions", bytes.NewReader(jsonBody))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
+		httpReq.Header.Set("Accept", "text/event-stream")
+		return httpReq, nil
+	}, tokens)
 	if err != nil {
 		return err
 	}
@@ -303,9 +490,15 @@ func (c *Client) sendStreamRequest(reqBody interface{}, callback StreamCallback)
 
 	if resp.StatusCode != http.StatusOK {
 		body, _ := io.ReadAll(resp.Body)
-		return fmt.Errorf("API返回错误: %d, %s", resp.StatusCode, string(body))
+		return &APIError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
+	// 中途断开或调用方停止接收时，已生成的部分同样计费
+	var output strings.Builder
+	defer func() {
+		c.settle(tokens, requestUsage(c.Provider, c.Model, input, ctxbudget.Count(output.String())))
+	}()
+
 	reader := bufio.NewReader(resp.Body)
 	for {
 		line, err := reader.ReadBytes('\n')
@@ -341,6 +534,7 @@ func (c *Client) sendStreamRequest(reqBody interface{}, callback StreamCallback)
 		if len(chunk.Choices) > 0 {
 			content := chunk.Choices[0].Delta.Content
 			if content != "" {
+				output.WriteString(content)
 				if !callback(content) {
 					break
 				}
@@ -350,37 +544,3 @@ func (c *Client) sendStreamRequest(reqBody interface{}, callback StreamCallback)
 
 	return nil
 }
-
-// extractJSON 从响应中提取JSON
-func extractJSON(s string) string {
-	// 查找 ```json```
-	start := bytes.Index([]byte(s), []byte("```json"))
-	if start >= 0 {
-		start += 7
-		end := bytes.Index([]byte(s[start:]), []byte("```"))
-		if end >= 0 {
-			return s[start : start+end]
-		}
-	}
-
-	// 查找 ````
-	start = bytes.Index([]byte(s), []byte("```"))
-	if start >= 0 {
-		start += 3
-		end := bytes.Index([]byte(s[start:]), []byte("```"))
-		if end >= 0 {
-			return s[start : start+end]
-		}
-	}
-
-	// 查找 { }
-	start = bytes.Index([]byte(s), []byte("{"))
-	if start >= 0 {
-		end := bytes.LastIndex([]byte(s), []byte("}"))
-		if end >= 0 {
-			return s[start : end+1]
-		}
-	}
-
-	return s
-}
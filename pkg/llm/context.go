@@ -0,0 +1,50 @@
+package llm
+
+import (
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/ctxbudget"
+)
+
+// ContextWindow 当前模型的上下文窗口大小
+func (c *Client) ContextWindow() int {
+	return config.Get().LLM.ContextWindow(c.Provider, c.Model)
+}
+
+// PromptBudget 留出输出空间后提示词（系统提示词+用户提示词）可用的token数
+// maxTokens 为本次请求的输出上限；未设置或超过窗口一半时按窗口的四分之一预留输出
+func (c *Client) PromptBudget(maxTokens int) int {
+	window := c.ContextWindow()
+	reserve := maxTokens
+	if reserve <= 0 || reserve > window/2 {
+		reserve = window / 4
+	}
+	return window - reserve
+}
+
+// fitContext 请求超出上下文窗口时截断最后一条用户消息的中间部分（保留开头和末尾的任务要求），
+// 是提示词构建阶段未做预算时的兜底
+func (c *Client) fitContext(req *ChatRequest) {
+	if c.isMock() || len(req.Messages) == 0 {
+		return
+	}
+	budget := c.PromptBudget(req.MaxTokens)
+	total := 0
+	for _, m := range req.Messages {
+		total += ctxbudget.Count(m.Content)
+	}
+	if total <= budget {
+		return
+	}
+
+	last := len(req.Messages) - 1
+	msg := req.Messages[last]
+	keep := budget - (total - ctxbudget.Count(msg.Content))
+	if keep <= 0 {
+		c.log().Warn("系统提示词已超出上下文窗口，无法截断", "model", c.Model, "tokens", total, "budget", budget)
+		return
+	}
+	messages := append([]Message(nil), req.Messages...)
+	messages[last].Content = ctxbudget.ClipMiddle(msg.Content, keep)
+	req.Messages = messages
+	c.log().Warn("提示词超出上下文窗口，已截断中间部分", "model", c.Model, "tokens", total, "budget", budget)
+}
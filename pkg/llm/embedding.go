@@ -0,0 +1,80 @@
+package llm
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+)
+
+// EmbeddingRequest 向量嵌入请求
+type EmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+// EmbeddingResponse 向量嵌入响应
+type EmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+// Embed 获取文本的向量嵌入（OpenAI兼容的 /embeddings 接口），返回顺序与输入一致
+func (c *Client) Embed(texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+	if c.isMock() {
+		return mockEmbeddings(texts), nil
+	}
+
+	reqBody, err := json.Marshal(EmbeddingRequest{Model: c.Model, Input: texts})
+	if err != nil {
+		return nil, err
+	}
+
+	tokens := 0
+	for _, t := range texts {
+		tokens += len([]rune(t))
+	}
+	resp, err := c.doLimited(func() (*http.Request, error) {
+		httpReq, err := http.NewRequest("POST", c.BaseURL+"/embeddings", bytes.NewReader(reqBody))
+		if err != nil {
+			return nil, err
+		}
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("Authorization", "Bearer "+c.APIKey)
+		return httpReq, nil
+	}, tokens)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body)}
+	}
+
+	var embResp EmbeddingResponse
+	if err := json.Unmarshal(body, &embResp); err != nil {
+		return nil, err
+	}
+	if len(embResp.Data) != len(texts) {
+		return nil, fmt.Errorf("嵌入数量不匹配: 期望%d，实际%d", len(texts), len(embResp.Data))
+	}
+
+	sort.Slice(embResp.Data, func(i, j int) bool { return embResp.Data[i].Index < embResp.Data[j].Index })
+	vectors := make([][]float32, len(embResp.Data))
+	for i, d := range embResp.Data {
+		vectors[i] = d.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,171 @@
+package llm
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"sync"
+
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/logx"
+)
+
+// ============================================
+// 后备模型与省钱模式
+//
+// 模块映射可配置后备链（主模型 → 次选 → 便宜模型），主模型遇到提供商错误
+// （5xx、429、鉴权失败）或超时时依次改用后备模型；流式请求已输出内容后不再切换。
+// 开启 llm.cheap_mode 时，登记为非关键的提示词角色（校验、摘要等）改用 llm.cheap_model，
+// 便宜模型失败时回到模块原有的模型链
+// ============================================
+
+// APIError 提供商返回的非200响应
+type APIError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API返回错误: %d, %s", e.StatusCode, e.Body)
+}
+
+// route 一个可调用的提供商/模型
+type route struct {
+	Provider  string
+	Model     string
+	APIKey    string
+	BaseURL   string
+	rateLimit config.RateLimitConfig
+}
+
+// resolveRoute 解析模型引用对应的提供商配置
+func resolveRoute(cfg *config.LLMConfig, ref config.ModelRef) (route, error) {
+	if ref.Provider == MockProvider {
+		return route{Provider: MockProvider, Model: ref.Model}, nil
+	}
+	provider, ok := cfg.Providers[ref.Provider]
+	if !ok {
+		return route{}, fmt.Errorf("未找到提供商 %s 的配置", ref.Provider)
+	}
+	apiKey, err := provider.GetAPIKey()
+	if err != nil {
+		return route{}, err
+	}
+	return route{
+		Provider:  ref.Provider,
+		Model:     ref.Model,
+		APIKey:    apiKey,
+		BaseURL:   provider.BaseURL,
+		rateLimit: provider.RateLimit,
+	}, nil
+}
+
+// resolveFallbacks 解析模块的后备链，无法解析的后备模型跳过并记录警告
+func resolveFallbacks(cfg *config.LLMConfig, module string, refs []config.ModelRef) []route {
+	routes := make([]route, 0, len(refs))
+	for _, ref := range refs {
+		r, err := resolveRoute(cfg, ref)
+		if err != nil {
+			logx.L().Warn("后备模型不可用，已跳过", "module", module, "model", ref.String(), "error", err)
+			continue
+		}
+		routes = append(routes, r)
+	}
+	return routes
+}
+
+// current 客户端当前使用的提供商/模型
+func (c *Client) current() route {
+	return route{Provider: c.Provider, Model: c.Model, APIKey: c.APIKey, BaseURL: c.BaseURL, rateLimit: c.rateLimit}
+}
+
+// via 返回改用指定提供商/模型的客户端副本（保留日志、计量、预算等设置），不再随配置热加载刷新
+func (c *Client) via(r route) *Client {
+	cp := *c
+	cp.Provider = r.Provider
+	cp.Model = r.Model
+	cp.APIKey = r.APIKey
+	cp.BaseURL = r.BaseURL
+	cp.rateLimit = r.rateLimit
+	cp.module = ""
+	cp.mapping = nil
+	cp.fallbacks = nil
+	return &cp
+}
+
+// withFailover 请求因提供商错误或超时失败时依次改用后备模型重试，直到成功、遇到不可切换的错误或后备用尽
+// canRetry 不为空时每次切换前检查，返回 false 则停止
+func (c *Client) withFailover(err error, canRetry func() bool, retry func(fc *Client) error) error {
+	from := c.Provider + "/" + c.Model
+	for _, r := range c.fallbacks {
+		if err == nil || !shouldFailover(err) || (canRetry != nil && !canRetry()) {
+			return err
+		}
+		to := r.Provider + "/" + r.Model
+		c.log().Warn("LLM调用失败，切换后备模型", "from", from, "to", to, "error", err)
+		err = retry(c.via(r))
+		from = to
+	}
+	return err
+}
+
+// shouldFailover 是否为换一个模型可能成功的错误：网络错误与超时、5xx、429（已退避重试仍失败）、408、鉴权失败
+func shouldFailover(err error) bool {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.StatusCode {
+		case http.StatusUnauthorized, http.StatusForbidden, http.StatusRequestTimeout, http.StatusTooManyRequests:
+			return true
+		}
+		return apiErr.StatusCode >= 500
+	}
+	var netErr net.Error
+	var urlErr *url.Error
+	return errors.As(err, &netErr) || errors.As(err, &urlErr)
+}
+
+var (
+	nonCriticalMu    sync.RWMutex
+	nonCriticalRoles = make(map[string]bool)
+)
+
+// MarkNonCritical 将提示词角色登记为非关键环节（校验、摘要、评分等），省钱模式下改用便宜模型
+func MarkNonCritical(roles ...string) {
+	nonCriticalMu.Lock()
+	defer nonCriticalMu.Unlock()
+	for _, role := range roles {
+		nonCriticalRoles[role] = true
+	}
+}
+
+// NonCritical 提示词角色是否为非关键环节
+func NonCritical(role string) bool {
+	nonCriticalMu.RLock()
+	defer nonCriticalMu.RUnlock()
+	return nonCriticalRoles[role]
+}
+
+// forRole 省钱模式下非关键角色改用便宜模型，原模型及其后备链作为便宜模型的后备；其余情况返回客户端本身
+func (c *Client) forRole(role string) *Client {
+	if c.isMock() || !NonCritical(role) {
+		return c
+	}
+	c.refresh()
+	cfg := &config.Get().LLM
+	if !cfg.CheapMode || cfg.CheapModel.Model == "" {
+		return c
+	}
+	if cfg.CheapModel.Provider == c.Provider && cfg.CheapModel.Model == c.Model {
+		return c
+	}
+	r, err := resolveRoute(cfg, cfg.CheapModel)
+	if err != nil {
+		c.log().Warn("便宜模型不可用，沿用原模型", "model", cfg.CheapModel.String(), "error", err)
+		return c
+	}
+	cheap := c.via(r)
+	cheap.fallbacks = append([]route{c.current()}, c.fallbacks...)
+	return cheap
+}
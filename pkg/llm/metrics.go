@@ -0,0 +1,88 @@
+package llm
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// slowestKept 保留的最慢调用条数
+const slowestKept = 50
+
+// CallRecord 一次LLM请求的耗时记录
+type CallRecord struct {
+	Provider  string    `json:"provider"`
+	Model     string    `json:"model"`
+	ElapsedMS int64     `json:"elapsed_ms"`
+	Tokens    int       `json:"tokens"`
+	Failed    bool      `json:"failed"`
+	At        time.Time `json:"at"`
+}
+
+// CallStats 进程启动以来的请求统计
+type CallStats struct {
+	Calls       int   `json:"calls"`
+	Failed      int   `json:"failed"`
+	TotalMS     int64 `json:"total_ms"`
+	AvgMS       int64 `json:"avg_ms"`
+	MaxMS       int64 `json:"max_ms"`
+	SlowestKept int   `json:"slowest_kept"`
+}
+
+var calls = struct {
+	mu      sync.Mutex
+	stats   CallStats
+	slowest []CallRecord // 按耗时降序
+}{}
+
+// recordCall 记录一次请求，只保留耗时最长的 slowestKept 条明细
+func recordCall(rec CallRecord) {
+	calls.mu.Lock()
+	defer calls.mu.Unlock()
+
+	calls.stats.Calls++
+	if rec.Failed {
+		calls.stats.Failed++
+	}
+	calls.stats.TotalMS += rec.ElapsedMS
+	if rec.ElapsedMS > calls.stats.MaxMS {
+		calls.stats.MaxMS = rec.ElapsedMS
+	}
+
+	if len(calls.slowest) == slowestKept && rec.ElapsedMS <= calls.slowest[slowestKept-1].ElapsedMS {
+		return
+	}
+	i := sort.Search(len(calls.slowest), func(i int) bool { return calls.slowest[i].ElapsedMS < rec.ElapsedMS })
+	calls.slowest = append(calls.slowest, CallRecord{})
+	copy(calls.slowest[i+1:], calls.slowest[i:])
+	calls.slowest[i] = rec
+	if len(calls.slowest) > slowestKept {
+		calls.slowest = calls.slowest[:slowestKept]
+	}
+}
+
+// CallMetrics 请求汇总统计
+func CallMetrics() CallStats {
+	calls.mu.Lock()
+	defer calls.mu.Unlock()
+
+	stats := calls.stats
+	if stats.Calls > 0 {
+		stats.AvgMS = stats.TotalMS / int64(stats.Calls)
+	}
+	stats.SlowestKept = slowestKept
+	return stats
+}
+
+// SlowestCalls 耗时最长的 n 次请求，按耗时降序；n 不大于0时返回全部保留的记录
+func SlowestCalls(n int) []CallRecord {
+	calls.mu.Lock()
+	defer calls.mu.Unlock()
+
+	if n <= 0 || n > len(calls.slowest) {
+		n = len(calls.slowest)
+	}
+	out := make([]CallRecord, n)
+	copy(out, calls.slowest[:n])
+	return out
+}
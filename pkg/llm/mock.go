@@ -0,0 +1,663 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/xlei/xupu/pkg/config"
+)
+
+// MockProvider 模拟提供商名称
+// 模块映射的 provider 设为 mock，或开启演练模式（--dry-run / XUPU_DRY_RUN=1）时，
+// 所有请求在本地生成确定性的响应，不发起网络调用，也不需要API Key
+const MockProvider = "mock"
+
+// mockEmbeddingDim 模拟向量维度
+const mockEmbeddingDim = 64
+
+var dryRun atomic.Bool
+
+func init() {
+	if v := os.Getenv("XUPU_DRY_RUN"); v == "1" || strings.EqualFold(v, "true") {
+		dryRun.Store(true)
+	}
+}
+
+// SetDryRun 开启或关闭演练模式，开启后新建的客户端全部使用模拟提供商
+func SetDryRun(enabled bool) {
+	dryRun.Store(enabled)
+}
+
+// DryRun 是否处于演练模式
+func DryRun() bool {
+	return dryRun.Load()
+}
+
+// mockFixture 按提示词标记注册的模拟响应
+type mockFixture struct {
+	marker string
+	sample interface{}
+}
+
+var (
+	mockMu       sync.RWMutex
+	mockFixtures []mockFixture
+)
+
+// RegisterMock 为包含 marker 的提示词注册模拟响应
+// sample 通常是调用方期望的响应结构体：已赋值的字段原样返回，零值字段按类型合成；
+// nil 切片合成一个元素，需要返回空数组时传入非nil的空切片。后注册的同名标记覆盖先注册的
+func RegisterMock(marker string, sample interface{}) {
+	mockMu.Lock()
+	defer mockMu.Unlock()
+	for i, f := range mockFixtures {
+		if f.marker == marker {
+			mockFixtures[i].sample = sample
+			return
+		}
+	}
+	mockFixtures = append(mockFixtures, mockFixture{marker: marker, sample: sample})
+}
+
+// newMockClient 创建模拟客户端
+func newMockClient(model string) *Client {
+	if model == "" {
+		model = MockProvider
+	}
+	return &Client{Model: model, Provider: MockProvider}
+}
+
+// mockModuleMapping 演练模式下模块没有配置时使用的映射
+func mockModuleMapping(cfg *config.Config, moduleName string) *config.ModuleMapping {
+	if mapping, ok := cfg.LLM.ModuleMapping[moduleName]; ok {
+		mapping.Provider = MockProvider
+		return &mapping
+	}
+	return &config.ModuleMapping{Provider: MockProvider, Model: MockProvider, Temperature: 0.7, MaxTokens: 4000}
+}
+
+// isMock 是否使用模拟提供商
+func (c *Client) isMock() bool {
+	return c.Provider == MockProvider
+}
+
+// mockChat 生成模拟的聊天响应体，结构与 /chat/completions 一致
+func (c *Client) mockChat(req ChatRequest) string {
+	content := c.mockContentFor(req)
+	prompt := ""
+	for _, m := range req.Messages {
+		prompt += m.Content
+	}
+
+	var resp ChatResponse
+	resp.Choices = append(resp.Choices, struct {
+		Message Message `json:"message"`
+	}{Message: Message{Role: "assistant", Content: content}})
+	resp.Usage.PromptTokens = len([]rune(prompt))
+	resp.Usage.CompletionTokens = len([]rune(content))
+	resp.Usage.TotalTokens = resp.Usage.PromptTokens + resp.Usage.CompletionTokens
+
+	body, _ := json.Marshal(resp)
+	return string(body)
+}
+
+// mockContent 生成模拟内容
+// 依次尝试：注册的响应结构体、提示词中给出的JSON输出格式、空JSON对象；不要求JSON的请求返回确定性的正文
+func mockContent(prompt string, maxTokens int) string {
+	seed := mockSeed(prompt)
+
+	mockMu.RLock()
+	for _, f := range mockFixtures {
+		if strings.Contains(prompt, f.marker) {
+			sample := f.sample
+			mockMu.RUnlock()
+			if body, err := json.Marshal(synthesize(reflect.ValueOf(sample), seed)); err == nil {
+				return string(body)
+			}
+			return "{}"
+		}
+	}
+	mockMu.RUnlock()
+
+	if template := jsonTemplate(prompt, seed, maxTokens); template != nil {
+		body, _ := json.Marshal(template)
+		return string(body)
+	}
+	if strings.Contains(prompt, "JSON") {
+		return "{}"
+	}
+	return mockProse(seed, maxTokens)
+}
+
+// mockSeed 由提示词得到确定性的种子
+func mockSeed(s string) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(s))
+	return h.Sum64()
+}
+
+var mockSentences = []string{
+	"风从山口灌进来，吹得檐下的铜铃叮当作响。",
+	"他停下脚步，回头望了一眼来时的路。",
+	"屋里的灯火摇曳，映出两道长长的影子。",
+	"她没有说话，只是把手中的信攥得更紧了。",
+	"远处传来钟声，一下一下，像是在数着什么。",
+	"雨丝细密，街道上的行人渐渐散去。",
+	"那一刻，所有的犹豫都有了答案。",
+	"桌上的茶早已凉透，却没有人起身去换。",
+}
+
+// mockProse 确定性的模拟正文，长度随 max_tokens 增长但有上限
+func mockProse(seed uint64, maxTokens int) string {
+	n := 12
+	if maxTokens > 0 && maxTokens < 2000 {
+		n = 4 + maxTokens/250
+	}
+
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteString(mockSentences[(seed+uint64(i))%uint64(len(mockSentences))])
+		if i%4 == 3 {
+			sb.WriteString("\n\n")
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// mockStream 按句切分模拟正文，逐段回调
+func (c *Client) mockStream(reqBody interface{}, callback StreamCallback) error {
+	var req ChatRequest
+	if body, err := json.Marshal(reqBody); err == nil {
+		json.Unmarshal(body, &req)
+	}
+
+	content := []rune(c.mockContentFor(req))
+	for start := 0; start < len(content); start += 20 {
+		end := start + 20
+		if end > len(content) {
+			end = len(content)
+		}
+		if !callback(string(content[start:end])) {
+			break
+		}
+	}
+	return nil
+}
+
+// mockContentFor 取请求中最后一条用户消息生成模拟内容
+func (c *Client) mockContentFor(req ChatRequest) string {
+	prompt := ""
+	for _, m := range req.Messages {
+		if m.Role == "user" {
+			prompt = m.Content
+		}
+	}
+	return mockContent(prompt, req.MaxTokens)
+}
+
+// mockEmbeddings 确定性的模拟向量：相同文本得到相同的单位向量
+func mockEmbeddings(texts []string) [][]float32 {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		vec := make([]float32, mockEmbeddingDim)
+		state := mockSeed(text)
+		var norm float64
+		for j := range vec {
+			state = state*6364136223846793005 + 1442695040888963407
+			v := float64(int64(state>>11))/float64(1<<52) - 1
+			vec[j] = float32(v)
+			norm += v * v
+		}
+		if norm = math.Sqrt(norm); norm > 0 {
+			for j := range vec {
+				vec[j] = float32(float64(vec[j]) / norm)
+			}
+		}
+		vectors[i] = vec
+	}
+	return vectors
+}
+
+// ============================================
+// 由响应结构体合成JSON
+// ============================================
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	rawJSONType = reflect.TypeOf(json.RawMessage{})
+)
+
+// synthesize 按类型为零值字段填充合成值，已赋值的字段保持不变
+func synthesize(v reflect.Value, seed uint64) interface{} {
+	if !v.IsValid() {
+		return map[string]interface{}{}
+	}
+	out := reflect.New(v.Type()).Elem()
+	out.Set(v)
+	fill(out, "", seed, 0)
+	return out.Interface()
+}
+
+// fill 递归填充零值，name 为字段的JSON名，用于生成可读的字符串
+func fill(v reflect.Value, name string, seed uint64, depth int) {
+	if depth > 6 || !v.CanSet() {
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+		elem := v.Elem()
+		fill(elem, name, seed, depth+1)
+	case reflect.Struct:
+		if v.Type() == timeType {
+			if v.Interface().(time.Time).IsZero() {
+				v.Set(reflect.ValueOf(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)))
+			}
+			return
+		}
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			jsonName := strings.Split(field.Tag.Get("json"), ",")[0]
+			if jsonName == "-" {
+				continue
+			}
+			if jsonName == "" {
+				jsonName = field.Name
+			}
+			fill(v.Field(i), jsonName, seed+uint64(i), depth+1)
+		}
+	case reflect.Slice:
+		if v.Type() == rawJSONType || !v.IsNil() {
+			// 非nil切片（包括显式的空切片）保持原样，只补全已有元素
+			for i := 0; i < v.Len(); i++ {
+				fill(v.Index(i), name, seed+uint64(i), depth+1)
+			}
+			return
+		}
+		s := reflect.MakeSlice(v.Type(), 1, 1)
+		fill(s.Index(0), name, seed, depth+1)
+		v.Set(s)
+	case reflect.Map:
+		if !v.IsNil() {
+			return
+		}
+		m := reflect.MakeMap(v.Type())
+		if v.Type().Key().Kind() == reflect.String {
+			key := reflect.New(v.Type().Key()).Elem()
+			key.SetString(fmt.Sprintf("模拟%s", name))
+			val := reflect.New(v.Type().Elem()).Elem()
+			fill(val, name, seed, depth+1)
+			m.SetMapIndex(key, val)
+		}
+		v.Set(m)
+	case reflect.Interface:
+		if v.IsNil() {
+			v.Set(reflect.ValueOf(fmt.Sprintf("模拟%s", name)))
+		}
+	case reflect.String:
+		if v.String() == "" {
+			v.SetString(fmt.Sprintf("模拟%s%d", name, seed%1000))
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if v.Int() == 0 {
+			v.SetInt(int64(1 + seed%5))
+		}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if v.Uint() == 0 {
+			v.SetUint(1 + seed%5)
+		}
+	case reflect.Float32, reflect.Float64:
+		if v.Float() == 0 {
+			v.SetFloat(0.5)
+		}
+	case reflect.Bool:
+		// 布尔值保持调用方给定的值
+	}
+}
+
+// ============================================
+// 提示词中的JSON输出格式
+// ============================================
+
+// formatMarkers 提示词中输出格式说明的常见标题
+var formatMarkers = []string{"输出格式", "返回格式", "JSON格式"}
+
+// jsonTemplate 从提示词中提取JSON输出格式并解析为示例值
+// 提示词里的格式往往不是严格的JSON（占位符如 实际字数、1-10、省略号和注释），按宽松规则解析
+func jsonTemplate(prompt string, seed uint64, maxTokens int) interface{} {
+	block := formatBlock(prompt)
+	if block == "" {
+		return nil
+	}
+
+	p := &templateParser{src: []rune(block), seed: seed, maxTokens: maxTokens}
+	v, ok := p.value("")
+	if !ok {
+		return nil
+	}
+	if _, isObject := v.(map[string]interface{}); !isObject {
+		return nil
+	}
+	return v
+}
+
+// formatBlock 定位输出格式对应的JSON块：取最靠后的、其后紧跟对象的格式标题之后的第一个对象；
+// 没有格式标题时取最后一个对象（输出格式通常放在提示词末尾）
+func formatBlock(prompt string) string {
+	var positions []int
+	for _, marker := range formatMarkers {
+		for offset := 0; ; {
+			i := strings.Index(prompt[offset:], marker)
+			if i < 0 {
+				break
+			}
+			positions = append(positions, offset+i)
+			offset += i + len(marker)
+		}
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(positions)))
+
+	for _, pos := range positions {
+		if blocks := topLevelObjects(prompt[pos:]); len(blocks) > 0 {
+			return blocks[0]
+		}
+	}
+	if blocks := topLevelObjects(prompt); len(blocks) > 0 {
+		return blocks[len(blocks)-1]
+	}
+	return ""
+}
+
+// topLevelObjects 返回文本中所有完整的顶层 {...} 块（按引号跳过字符串内的括号）
+func topLevelObjects(s string) []string {
+	var blocks []string
+	depth, begin := 0, -1
+	inString := false
+	runes := []rune(s)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		if inString {
+			if r == '\\' {
+				i++
+			} else if r == '"' {
+				inString = false
+			}
+			continue
+		}
+		switch r {
+		case '"':
+			if depth > 0 {
+				inString = true
+			}
+		case '{':
+			if depth == 0 {
+				begin = i
+			}
+			depth++
+		case '}':
+			if depth > 0 {
+				depth--
+				if depth == 0 {
+					blocks = append(blocks, string(runes[begin:i+1]))
+				}
+			}
+		}
+	}
+	return blocks
+}
+
+// templateParser 宽松的JSON格式解析器
+type templateParser struct {
+	src       []rune
+	pos       int
+	seed      uint64
+	maxTokens int
+}
+
+// proseKeys 正文类字段，模板中的占位文字替换为模拟正文
+var proseKeys = map[string]bool{"content": true, "text": true, "prose": true}
+
+// skipMarker 表示数组中的省略项
+type skipMarker struct{}
+
+func (p *templateParser) skipSpace() {
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		switch {
+		case r == ' ' || r == '\t' || r == '\n' || r == '\r':
+			p.pos++
+		case r == '/' && p.pos+1 < len(p.src) && p.src[p.pos+1] == '/':
+			for p.pos < len(p.src) && p.src[p.pos] != '\n' {
+				p.pos++
+			}
+		default:
+			return
+		}
+	}
+}
+
+func (p *templateParser) value(key string) (interface{}, bool) {
+	p.skipSpace()
+	if p.pos >= len(p.src) {
+		return nil, false
+	}
+
+	switch p.src[p.pos] {
+	case '{':
+		return p.object()
+	case '[':
+		return p.array(key)
+	case '"':
+		s, ok := p.str()
+		switch {
+		case !ok:
+			return nil, false
+		case isBoolPlaceholder(s):
+			return true, true
+		case proseKeys[key]:
+			return mockProse(p.seed, p.maxTokens), true
+		}
+		return s, true
+	default:
+		return p.bare(key), true
+	}
+}
+
+func (p *templateParser) object() (interface{}, bool) {
+	p.pos++ // {
+	obj := map[string]interface{}{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, false
+		}
+		switch p.src[p.pos] {
+		case '}':
+			p.pos++
+			return obj, true
+		case ',':
+			p.pos++
+			continue
+		}
+
+		var key string
+		if p.src[p.pos] == '"' {
+			k, ok := p.str()
+			if !ok {
+				return nil, false
+			}
+			key = k
+		} else {
+			// 省略号或裸键
+			start := p.pos
+			for p.pos < len(p.src) && !strings.ContainsRune(":,}\n", p.src[p.pos]) {
+				p.pos++
+			}
+			key = strings.TrimSpace(string(p.src[start:p.pos]))
+		}
+
+		p.skipSpace()
+		if p.pos >= len(p.src) || p.src[p.pos] != ':' {
+			// 没有值的条目（如 "..."）直接忽略
+			continue
+		}
+		p.pos++ // :
+
+		v, ok := p.value(key)
+		if !ok {
+			return nil, false
+		}
+		if key != "" && !isEllipsis(key) {
+			if _, skip := v.(skipMarker); !skip {
+				obj[key] = v
+			}
+		}
+	}
+}
+
+func (p *templateParser) array(key string) (interface{}, bool) {
+	p.pos++ // [
+	arr := []interface{}{}
+	for {
+		p.skipSpace()
+		if p.pos >= len(p.src) {
+			return nil, false
+		}
+		switch p.src[p.pos] {
+		case ']':
+			p.pos++
+			return arr, true
+		case ',':
+			p.pos++
+			continue
+		}
+
+		v, ok := p.value(key)
+		if !ok {
+			return nil, false
+		}
+		if _, skip := v.(skipMarker); !skip {
+			arr = append(arr, v)
+		}
+	}
+}
+
+func (p *templateParser) str() (string, bool) {
+	var sb strings.Builder
+	sb.WriteRune('"')
+	p.pos++ // "
+	for p.pos < len(p.src) {
+		r := p.src[p.pos]
+		p.pos++
+		switch r {
+		case '\\':
+			if p.pos < len(p.src) {
+				sb.WriteRune(r)
+				sb.WriteRune(p.src[p.pos])
+				p.pos++
+			}
+		case '"':
+			sb.WriteRune(r)
+			var s string
+			if err := json.Unmarshal([]byte(sb.String()), &s); err != nil {
+				return "", false
+			}
+			return s, true
+		case '\n':
+			sb.WriteString(`\n`)
+		case '\t':
+			sb.WriteString(`\t`)
+		default:
+			sb.WriteRune(r)
+		}
+	}
+	return "", false
+}
+
+var numberPattern = regexp.MustCompile(`-?\d+(\.\d+)?`)
+
+// bare 解析未加引号的值：JSON字面量原样返回，占位符按内容推断为数字或字符串
+func (p *templateParser) bare(key string) interface{} {
+	start := p.pos
+	for p.pos < len(p.src) && !strings.ContainsRune(",}]\n", p.src[p.pos]) {
+		p.pos++
+	}
+	if p.pos == start {
+		// 遇到不成对的括号，跳过以保证前进
+		p.pos++
+		return skipMarker{}
+	}
+	token := strings.TrimSpace(string(p.src[start:p.pos]))
+	if i := strings.Index(token, "//"); i >= 0 {
+		token = strings.TrimSpace(token[:i])
+	}
+
+	switch {
+	case token == "" || isEllipsis(token):
+		return skipMarker{}
+	case token == "true" || isBoolPlaceholder(token):
+		return true
+	case token == "false":
+		return false
+	case token == "null":
+		return nil
+	}
+	if n, err := strconv.ParseFloat(token, 64); err == nil {
+		return n
+	}
+	return placeholder(key, token)
+}
+
+// placeholder 推断占位符的示例值：给出数值范围时取中点，带数字或计量含义时取数字，否则作为字符串
+func placeholder(key, token string) interface{} {
+	nums := numberPattern.FindAllString(token, -1)
+	if len(nums) >= 2 {
+		lo, _ := strconv.ParseFloat(nums[0], 64)
+		hi, _ := strconv.ParseFloat(nums[1], 64)
+		if !strings.Contains(nums[0], ".") && !strings.Contains(nums[1], ".") {
+			return math.Floor((lo + hi) / 2)
+		}
+		return (lo + hi) / 2
+	}
+	if len(nums) == 1 {
+		n, _ := strconv.ParseFloat(nums[0], 64)
+		return n
+	}
+	for _, hint := range []string{"数", "分", "率", "级", "度", "量", "序号", "编号"} {
+		if strings.Contains(token, hint) {
+			return 1
+		}
+	}
+	if strings.Contains(key, "count") || strings.Contains(key, "score") || strings.HasSuffix(key, "_num") {
+		return 1
+	}
+	return token
+}
+
+// isBoolPlaceholder 布尔占位符，如 true/false
+func isBoolPlaceholder(s string) bool {
+	return s == "true/false" || s == "false/true" || s == "true|false" || s == "布尔值"
+}
+
+func isEllipsis(s string) bool {
+	s = strings.Trim(s, " \"")
+	return s == "..." || s == "…" || s == "……"
+}
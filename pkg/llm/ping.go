@@ -0,0 +1,45 @@
+package llm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+// Ping 探测提供商是否可达：以 max_tokens=1 发送一次最小的聊天请求
+// 不经限流器排队、不重试，超时由 ctx 控制；429 说明服务可达，只是当前限流，视为成功
+func (c *Client) Ping(ctx context.Context) error {
+	if c.isMock() {
+		return nil
+	}
+
+	body, err := json.Marshal(ChatRequest{
+		Model:     c.Model,
+		Messages:  []Message{{Role: "user", Content: "ping"}},
+		MaxTokens: 1,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", c.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.APIKey)
+
+	resp, err := c.httpCli.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK || resp.StatusCode == http.StatusTooManyRequests {
+		return nil
+	}
+	snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+	return &APIError{StatusCode: resp.StatusCode, Body: string(snippet)}
+}
@@ -0,0 +1,40 @@
+package llm
+
+import (
+	"sync"
+	"time"
+)
+
+// RoleOutcome 一次角色调用的结果
+type RoleOutcome struct {
+	Err      error         // 请求失败，或修复后仍未通过结构校验
+	Repairs  int           // 结构修复次数
+	Duration time.Duration // 含修复请求在内的总耗时
+}
+
+// PromptHook 发送前改写带角色的提示词（如注入少样本示例）
+// 返回改写后的提示词，以及调用结束后接收结果的回调（可为 nil），用于统计改写的效果
+type PromptHook func(role, prompt string) (string, func(RoleOutcome))
+
+var (
+	promptHookMu sync.RWMutex
+	promptHook   PromptHook
+)
+
+// OnPrompt 设置全局提示词钩子，只作用于 GenerateJSONForRole 的带角色调用；传 nil 取消
+func OnPrompt(hook PromptHook) {
+	promptHookMu.Lock()
+	defer promptHookMu.Unlock()
+	promptHook = hook
+}
+
+// applyPromptHook 按全局钩子改写提示词，未设置钩子时原样返回
+func applyPromptHook(role, prompt string) (string, func(RoleOutcome)) {
+	promptHookMu.RLock()
+	hook := promptHook
+	promptHookMu.RUnlock()
+	if hook == nil || role == "" {
+		return prompt, nil
+	}
+	return hook(role, prompt)
+}
@@ -0,0 +1,301 @@
+package llm
+
+import (
+	"io"
+	"math"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/ctxbudget"
+)
+
+const (
+	// defaultMaxRetries 遇到429时的默认重试次数
+	defaultMaxRetries = 5
+	// defaultMaxBackoff 默认最大退避时间
+	defaultMaxBackoff = 60 * time.Second
+	// baseBackoff 首次退避时间，之后按指数增长
+	baseBackoff = 2 * time.Second
+	// outputTokenEstimate 预估输出token的上限
+	// 模块配置的 max_tokens 往往远大于实际输出，全额预占会让TPM桶长期排空，请求完成后按实际用量结算
+	outputTokenEstimate = 4096
+)
+
+// bucket 令牌桶，容量为每分钟上限，按秒匀速补充
+type bucket struct {
+	capacity float64
+	tokens   float64
+	rate     float64 // 每秒补充量
+	last     time.Time
+}
+
+func newBucket(perMinute int, now time.Time) *bucket {
+	if perMinute <= 0 {
+		return nil
+	}
+	return &bucket{
+		capacity: float64(perMinute),
+		tokens:   float64(perMinute),
+		rate:     float64(perMinute) / 60,
+		last:     now,
+	}
+}
+
+func (b *bucket) refill(now time.Time) {
+	if elapsed := now.Sub(b.last).Seconds(); elapsed > 0 {
+		b.tokens = math.Min(b.capacity, b.tokens+elapsed*b.rate)
+		b.last = now
+	}
+}
+
+// wait 取出 n 个令牌还需等待的时间，n 超过容量时按容量计算，避免永远等不到
+func (b *bucket) wait(n float64) time.Duration {
+	n = math.Min(n, b.capacity)
+	if b.tokens >= n {
+		return 0
+	}
+	return time.Duration((n - b.tokens) / b.rate * float64(time.Second))
+}
+
+// limitWaiter 排队等待放行的请求
+type limitWaiter struct {
+	tokens int
+	ready  chan struct{}
+}
+
+// Limiter 单个提供商/模型的限流器
+// 请求进入队列后由派发协程按先进先出顺序放行，同时受 RPM、TPM 和429暂停期约束
+type Limiter struct {
+	key   string
+	queue chan *limitWaiter
+
+	mu          sync.Mutex
+	requests    *bucket
+	tokens      *bucket
+	pausedUntil time.Time
+	maxRetries  int
+	maxBackoff  time.Duration
+	rpm, tpm    int
+}
+
+var (
+	limitersMu sync.Mutex
+	limiters   = make(map[string]*Limiter)
+)
+
+// limiterFor 获取提供商/模型对应的限流器，同一提供商/模型的所有客户端共享；配置变化时更新上限
+func limiterFor(provider, model string, cfg config.RateLimitConfig) *Limiter {
+	key := provider + "/" + model
+
+	limitersMu.Lock()
+	defer limitersMu.Unlock()
+
+	l, ok := limiters[key]
+	if !ok {
+		l = &Limiter{key: key, queue: make(chan *limitWaiter, 256)}
+		limiters[key] = l
+		go l.dispatch()
+	}
+	l.configure(model, cfg)
+	return l
+}
+
+// configure 应用限流配置
+func (l *Limiter) configure(model string, cfg config.RateLimitConfig) {
+	rpm, tpm := cfg.ForModel(model)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if rpm != l.rpm {
+		l.requests = newBucket(rpm, now)
+		l.rpm = rpm
+	}
+	if tpm != l.tpm {
+		l.tokens = newBucket(tpm, now)
+		l.tpm = tpm
+	}
+
+	l.maxRetries = cfg.MaxRetries
+	if l.maxRetries <= 0 {
+		l.maxRetries = defaultMaxRetries
+	}
+	l.maxBackoff = time.Duration(cfg.MaxBackoff) * time.Second
+	if l.maxBackoff <= 0 {
+		l.maxBackoff = defaultMaxBackoff
+	}
+}
+
+// Acquire 排队等待放行，tokens 为预估消耗的token数
+func (l *Limiter) Acquire(tokens int) {
+	w := &limitWaiter{tokens: tokens, ready: make(chan struct{})}
+	l.queue <- w
+	<-w.ready
+}
+
+// Settle 请求完成后按实际用量结算预占的token，多退少补
+// 预估超过桶容量时 reserve 只扣了容量，按实际扣除的数额结算，避免退回从未扣除的token
+func (l *Limiter) Settle(estimated, actual int) {
+	if actual <= 0 {
+		return
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.tokens != nil {
+		reserved := math.Min(float64(estimated), l.tokens.capacity)
+		l.tokens.refill(time.Now())
+		l.tokens.tokens = math.Min(l.tokens.capacity, l.tokens.tokens+reserved-float64(actual))
+	}
+}
+
+// Pause 暂停派发一段时间，用于提供商返回429后整体退让
+func (l *Limiter) Pause(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if until := time.Now().Add(d); until.After(l.pausedUntil) {
+		l.pausedUntil = until
+	}
+}
+
+// dispatch 派发协程：依次放行队首请求
+func (l *Limiter) dispatch() {
+	for w := range l.queue {
+		for {
+			wait := l.reserve(w.tokens)
+			if wait == 0 {
+				break
+			}
+			time.Sleep(wait)
+		}
+		close(w.ready)
+	}
+}
+
+// reserve 尝试为请求扣除配额，成功返回0，否则返回需要等待的时间
+func (l *Limiter) reserve(tokens int) time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+
+	var wait time.Duration
+	if l.requests != nil {
+		l.requests.refill(now)
+		wait = max(wait, l.requests.wait(1))
+	}
+	if l.tokens != nil {
+		l.tokens.refill(now)
+		wait = max(wait, l.tokens.wait(float64(tokens)))
+	}
+	if wait > 0 {
+		return wait
+	}
+
+	if l.requests != nil {
+		l.requests.tokens--
+	}
+	if l.tokens != nil {
+		l.tokens.tokens -= math.Min(float64(tokens), l.tokens.capacity)
+	}
+	return 0
+}
+
+// retryLimit 遇到429时的最大重试次数
+func (l *Limiter) retryLimit() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.maxRetries
+}
+
+// backoff 第 attempt 次重试前的退避时间：优先使用 Retry-After，否则指数退避加随机抖动
+func (l *Limiter) backoff(attempt int, resp *http.Response) time.Duration {
+	l.mu.Lock()
+	limit := l.maxBackoff
+	l.mu.Unlock()
+
+	d := retryAfter(resp)
+	if d <= 0 {
+		d = baseBackoff << attempt
+		d += time.Duration(rand.Int63n(int64(d/2) + 1))
+	}
+	if d > limit {
+		d = limit
+	}
+	return d
+}
+
+// retryAfter 解析 Retry-After 响应头（秒数或HTTP日期）
+func retryAfter(resp *http.Response) time.Duration {
+	value := resp.Header.Get("Retry-After")
+	if value == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(value); err == nil {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
+
+// estimateTokens 预估请求消耗的token数：输入按 ctxbudget.Count 估算，输出取 max_tokens 与预估上限的较小值
+func estimateTokens(messages []Message, maxTokens int) int {
+	n := promptTokens(messages)
+	if maxTokens <= 0 || maxTokens > outputTokenEstimate {
+		maxTokens = outputTokenEstimate
+	}
+	return n + maxTokens
+}
+
+// promptTokens 按 ctxbudget.Count 估算输入消息的token数
+func promptTokens(messages []Message) int {
+	n := 0
+	for _, m := range messages {
+		n += ctxbudget.Count(m.Content)
+	}
+	return n
+}
+
+// doLimited 经限流器放行后发送请求；遇到429时暂停该提供商/模型的派发并退避重试，
+// 重试次数用尽后返回最后一次的429响应，由调用方按普通错误处理
+func (c *Client) doLimited(build func() (*http.Request, error), tokens int) (*http.Response, error) {
+	limiter := c.limiter()
+
+	for attempt := 0; ; attempt++ {
+		limiter.Acquire(tokens)
+
+		httpReq, err := build()
+		if err != nil {
+			return nil, err
+		}
+		resp, err := c.httpCli.Do(httpReq)
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusTooManyRequests || attempt >= limiter.retryLimit() {
+			return resp, nil
+		}
+
+		wait := limiter.backoff(attempt, resp)
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		c.log().Warn("LLM触发限流(429)，退避后重试", "limiter", limiter.key, "wait", wait.Round(time.Second), "attempt", attempt+1)
+		limiter.Pause(wait)
+	}
+}
+
+// limiter 当前提供商/模型的限流器，模型通过 SetModel 切换后自动使用新模型的限流器
+func (c *Client) limiter() *Limiter {
+	return limiterFor(c.Provider, c.Model, c.rateLimit)
+}
@@ -0,0 +1,42 @@
+// Package llm 限流器测试
+package llm
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestLimiterSettle 测试按实际用量结算预占的token
+func TestLimiterSettle(t *testing.T) {
+	tests := []struct {
+		name      string
+		estimated int
+		actual    int
+		want      float64 // 结算后桶内剩余（容量1000）
+	}{
+		{name: "实际少于预估时退回差额", estimated: 600, actual: 200, want: 800},
+		{name: "实际多于预估时补扣", estimated: 200, actual: 600, want: 400},
+		{name: "预估超过容量时只退回实际扣除的部分", estimated: 5000, actual: 200, want: 800},
+		{name: "预估超过容量且实际也超过容量", estimated: 5000, actual: 3000, want: -2000},
+		{name: "没有用量时不结算", estimated: 600, actual: 0, want: 400},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := &Limiter{tokens: newBucket(1000, time.Now())}
+			if wait := l.reserve(tt.estimated); wait != 0 {
+				t.Fatalf("满桶时应立即放行，实际需等待 %s", wait)
+			}
+			l.Settle(tt.estimated, tt.actual)
+
+			// 测试期间桶按每秒 1000/60 补充，允许 1 个token的误差
+			if got := l.tokens.tokens; math.Abs(got-tt.want) > 1 {
+				t.Errorf("剩余token = %.1f, 期望 %.0f", got, tt.want)
+			}
+			if l.tokens.tokens > l.tokens.capacity {
+				t.Errorf("剩余token %.1f 超过容量 %.0f", l.tokens.tokens, l.tokens.capacity)
+			}
+		})
+	}
+}
@@ -0,0 +1,421 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+
+// MaxSchemaRepairs 响应未通过结构校验时最多发送的修复请求数
+const MaxSchemaRepairs = 2
+
+// Schema JSON Schema 的子集：type/properties/required/items/additionalProperties/enum/minimum/maximum
+type Schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+}
+
+// SchemaOf 由响应结构体推导 Schema，只约束字段类型，不设必填项
+// 必填项和取值范围由注册方通过 Require/Range 补充
+func SchemaOf(v interface{}) *Schema {
+	return schemaOfType(reflect.TypeOf(v), 0)
+}
+
+func schemaOfType(t reflect.Type, depth int) *Schema {
+	if t == nil {
+		return &Schema{}
+	}
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if depth > 8 || reflect.PointerTo(t).Implements(unmarshalerType) {
+		// 自定义解码的类型（时间、原始JSON等）不约束
+		return &Schema{}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		s := &Schema{Type: "object", Properties: map[string]*Schema{}}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("json"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if field.Anonymous && name == "" {
+				// 内嵌结构体的字段提升到外层
+				if embedded := schemaOfType(field.Type, depth+1); embedded.Type == "object" {
+					for k, p := range embedded.Properties {
+						s.Properties[k] = p
+					}
+				}
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			s.Properties[name] = schemaOfType(field.Type, depth+1)
+		}
+		return s
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string"}
+		}
+		return &Schema{Type: "array", Items: schemaOfType(t.Elem(), depth+1)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: schemaOfType(t.Elem(), depth+1)}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		// interface{} 等不约束类型
+		return &Schema{}
+	}
+}
+
+// Require 将顶层字段设为必填
+func (s *Schema) Require(keys ...string) *Schema {
+	s.Required = append(s.Required, keys...)
+	return s
+}
+
+// Range 为路径上的数值字段设置取值范围，路径用点分隔，map 的值和数组元素用 * 表示
+func (s *Schema) Range(path string, min, max float64) *Schema {
+	if target := s.lookup(path); target != nil {
+		target.Minimum = &min
+		target.Maximum = &max
+	}
+	return s
+}
+
+// OneOf 限定路径上字段的取值
+func (s *Schema) OneOf(path string, values ...interface{}) *Schema {
+	if target := s.lookup(path); target != nil {
+		target.Enum = values
+	}
+	return s
+}
+
+// lookup 按点分路径查找子 Schema
+func (s *Schema) lookup(path string) *Schema {
+	cur := s
+	for _, part := range strings.Split(path, ".") {
+		if cur == nil {
+			return nil
+		}
+		switch {
+		case part == "*" && cur.Items != nil:
+			cur = cur.Items
+		case part == "*":
+			cur = cur.AdditionalProperties
+		default:
+			cur = cur.Properties[part]
+		}
+	}
+	return cur
+}
+
+// Validate 校验解析后的JSON值，返回所有错误（带 $.a.b 形式的路径）
+func (s *Schema) Validate(v interface{}) []string {
+	var errs []string
+	s.validate(v, "$", &errs)
+	return errs
+}
+
+func (s *Schema) validate(v interface{}, path string, errs *[]string) {
+	if s == nil {
+		return
+	}
+
+	if s.Type != "" && !matchesType(s.Type, v) {
+		*errs = append(*errs, fmt.Sprintf("%s: 类型应为%s，实际为%s", path, s.Type, jsonType(v)))
+		return
+	}
+
+	if len(s.Enum) > 0 {
+		found := false
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(e, v) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			*errs = append(*errs, fmt.Sprintf("%s: 取值 %v 不在允许范围 %v 内", path, v, s.Enum))
+		}
+	}
+
+	switch val := v.(type) {
+	case float64:
+		if s.Minimum != nil && val < *s.Minimum {
+			*errs = append(*errs, fmt.Sprintf("%s: %v 小于最小值 %v", path, val, *s.Minimum))
+		}
+		if s.Maximum != nil && val > *s.Maximum {
+			*errs = append(*errs, fmt.Sprintf("%s: %v 大于最大值 %v", path, val, *s.Maximum))
+		}
+	case map[string]interface{}:
+		for _, key := range s.Required {
+			if _, ok := val[key]; !ok {
+				*errs = append(*errs, fmt.Sprintf("%s: 缺少必填字段 %s", path, key))
+			}
+		}
+		keys := make([]string, 0, len(val))
+		for k := range val {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if prop, ok := s.Properties[k]; ok {
+				prop.validate(val[k], path+"."+k, errs)
+			} else if s.AdditionalProperties != nil {
+				s.AdditionalProperties.validate(val[k], path+"."+k, errs)
+			}
+		}
+	case []interface{}:
+		for i, item := range val {
+			s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	}
+}
+
+// matchesType 判断值是否符合 JSON Schema 类型，null 视为缺省值，与 json.Unmarshal 的行为一致
+func matchesType(t string, v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch t {
+	case "object":
+		_, ok := v.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := v.([]interface{})
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "number":
+		_, ok := v.(float64)
+		return ok
+	case "integer":
+		n, ok := v.(float64)
+		return ok && n == math.Trunc(n)
+	}
+	return true
+}
+
+// jsonType 值的 JSON 类型名
+func jsonType(v interface{}) string {
+	switch v.(type) {
+	case nil:
+		return "null"
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case bool:
+		return "boolean"
+	case float64:
+		return "number"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+// ============================================
+// 注册表与修复率统计
+// ============================================
+
+// SchemaStats 单个提示词角色的校验统计
+type SchemaStats struct {
+	Role       string    `json:"role"`
+	Calls      int       `json:"calls"`       // 经过校验的响应数
+	Valid      int       `json:"valid"`       // 首次即通过校验
+	Repaired   int       `json:"repaired"`    // 修复后通过
+	Failed     int       `json:"failed"`      // 修复后仍未通过
+	CallErrors int       `json:"call_errors"` // 请求本身失败（网络、限流、响应无法解析等）
+	RepairRate float64   `json:"repair_rate"` // 需要修复的比例
+	LastErrors []string  `json:"last_errors,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+var (
+	schemaMu    sync.RWMutex
+	schemas     = make(map[string]*Schema)
+	schemaStats = make(map[string]*SchemaStats)
+)
+
+// RegisterSchema 为提示词角色注册响应 Schema，角色名建议使用 模块.用途 的形式
+func RegisterSchema(role string, s *Schema) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+	schemas[role] = s
+}
+
+// LookupSchema 获取提示词角色的 Schema
+func LookupSchema(role string) (*Schema, bool) {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+	s, ok := schemas[role]
+	return s, ok
+}
+
+// SchemaMetrics 各角色的校验与修复统计，按角色名排序
+func SchemaMetrics() []SchemaStats {
+	schemaMu.RLock()
+	defer schemaMu.RUnlock()
+
+	out := make([]SchemaStats, 0, len(schemaStats))
+	for _, st := range schemaStats {
+		out = append(out, *st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Role < out[j].Role })
+	return out
+}
+
+// recordSchema 记录一次校验结果：repairs 为修复次数，errs 为最终仍未解决的错误
+func recordSchema(role string, repairs int, errs []string) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+
+	st, ok := schemaStats[role]
+	if !ok {
+		st = &SchemaStats{Role: role}
+		schemaStats[role] = st
+	}
+	st.Calls++
+	switch {
+	case len(errs) > 0:
+		st.Failed++
+		st.LastErrors = errs
+	case repairs > 0:
+		st.Repaired++
+	default:
+		st.Valid++
+	}
+	st.RepairRate = float64(st.Repaired+st.Failed) / float64(st.Calls)
+	st.UpdatedAt = time.Now()
+}
+
+// recordCallError 记录角色的一次请求失败
+func recordCallError(role string) {
+	schemaMu.Lock()
+	defer schemaMu.Unlock()
+
+	st, ok := schemaStats[role]
+	if !ok {
+		st = &SchemaStats{Role: role}
+		schemaStats[role] = st
+	}
+	st.CallErrors++
+	st.UpdatedAt = time.Now()
+}
+
+// SchemaError 修复后仍未通过结构校验
+type SchemaError struct {
+	Role   string
+	Errors []string
+}
+
+func (e *SchemaError) Error() string {
+	return fmt.Sprintf("响应未通过结构校验（%s）: %s", e.Role, strings.Join(e.Errors, "；"))
+}
+
+// GenerateJSONForRole 生成JSON并按角色注册的 Schema 校验
+// 未通过时把校验错误和 Schema 发回模型请求修正，最多 MaxSchemaRepairs 次，仍不通过则返回 *SchemaError；
+// 角色为空或未注册 Schema 时等同于 GenerateJSONWithParams；省钱模式下非关键角色改用便宜模型；
+// 设置了 OnPrompt 钩子时提示词先经钩子改写
+func (c *Client) GenerateJSONForRole(role, prompt, systemPrompt string, temperature float64, maxTokens int) (map[string]interface{}, error) {
+	c = c.forRole(role)
+	prompt, done := applyPromptHook(role, prompt)
+	start := time.Now()
+	result, repairs, err := c.generateForRole(role, prompt, systemPrompt, temperature, maxTokens)
+	if done != nil {
+		done(RoleOutcome{Err: err, Repairs: repairs, Duration: time.Since(start)})
+	}
+	return result, err
+}
+
+// generateForRole 生成并按 Schema 校验、修复，返回结果和修复次数
+func (c *Client) generateForRole(role, prompt, systemPrompt string, temperature float64, maxTokens int) (map[string]interface{}, int, error) {
+	result, err := c.GenerateJSONWithParams(prompt, systemPrompt, temperature, maxTokens)
+	if role == "" {
+		return result, 0, err
+	}
+	if err != nil {
+		recordCallError(role)
+		return result, 0, err
+	}
+	schema, ok := LookupSchema(role)
+	if !ok {
+		return result, 0, nil
+	}
+
+	errs := schema.Validate(result)
+	repairs := 0
+	for len(errs) > 0 && repairs < MaxSchemaRepairs {
+		repairs++
+		c.log().Info("LLM输出未通过结构校验，请求修复", "role", role, "attempt", repairs, "errors", errs)
+		repaired, err := c.GenerateJSONWithParams(buildRepairPrompt(result, errs, schema), systemPrompt, temperature, maxTokens)
+		if err != nil {
+			continue
+		}
+		result = repaired
+		errs = schema.Validate(result)
+	}
+
+	recordSchema(role, repairs, errs)
+	if len(errs) > 0 {
+		return nil, repairs, &SchemaError{Role: role, Errors: errs}
+	}
+	return result, repairs, nil
+}
+
+// buildRepairPrompt 构建修复提示词：原输出、校验错误和目标 Schema
+func buildRepairPrompt(output map[string]interface{}, errs []string, schema *Schema) string {
+	var prompt strings.Builder
+
+	raw, _ := json.MarshalIndent(output, "", "  ")
+	spec, _ := json.MarshalIndent(schema, "", "  ")
+
+	prompt.WriteString("# JSON修复任务\n\n")
+	prompt.WriteString("你上一次的输出没有通过结构校验，请修正后重新输出。\n\n")
+	prompt.WriteString(fmt.Sprintf("## 上一次的输出\n%s\n\n", raw))
+	prompt.WriteString("## 校验错误\n")
+	for _, e := range errs {
+		prompt.WriteString("- " + e + "\n")
+	}
+	prompt.WriteString(fmt.Sprintf("\n## 目标结构（JSON Schema）\n%s\n\n", spec))
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString("1. 只修正校验错误涉及的字段，其余内容保持不变\n")
+	prompt.WriteString("2. 缺少的必填字段根据上下文补全\n")
+	prompt.WriteString("3. 只输出修正后的完整JSON，不要解释\n")
+
+	return prompt.String()
+}
@@ -0,0 +1,164 @@
+package llm
+
+import (
+	"sync"
+
+	"github.com/xlei/xupu/pkg/config"
+)
+
+// Usage LLM用量统计
+type Usage struct {
+	Requests     int     `json:"requests"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	TotalTokens  int     `json:"total_tokens"`
+	Cost         float64 `json:"cost"` // 按配置中模型的单价估算，未配置单价的模型不计费
+}
+
+// Add 累加另一份用量
+func (u *Usage) Add(other Usage) {
+	u.Requests += other.Requests
+	u.InputTokens += other.InputTokens
+	u.OutputTokens += other.OutputTokens
+	u.TotalTokens += other.TotalTokens
+	u.Cost += other.Cost
+}
+
+// Meter 用量计量器，通过 Client.WithMeter 挂到客户端上，累计经过该客户端的所有请求
+// 可在多个客户端和协程间共享
+type Meter struct {
+	mu    sync.Mutex
+	usage Usage
+}
+
+// NewMeter 创建用量计量器
+func NewMeter() *Meter {
+	return &Meter{}
+}
+
+// Usage 当前累计用量
+func (m *Meter) Usage() Usage {
+	if m == nil {
+		return Usage{}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.usage
+}
+
+// record 记录一次请求的用量
+func (m *Meter) record(u Usage) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usage.Add(u)
+}
+
+// requestUsage 一次请求的用量，费用按模型单价计算
+func requestUsage(provider, model string, input, output int) Usage {
+	return Usage{
+		Requests:     1,
+		InputTokens:  input,
+		OutputTokens: output,
+		TotalTokens:  input + output,
+		Cost:         modelCost(provider, model, input, output),
+	}
+}
+
+// UsageHook 每次请求结算用量后的回调，用于按日持久化全站用量
+type UsageHook func(provider, model string, u Usage)
+
+var (
+	usageHookMu sync.RWMutex
+	usageHook   UsageHook
+)
+
+// OnUsage 设置全局用量回调，进程内所有客户端的请求都会经过；传 nil 取消
+// 回调在请求协程中同步执行，耗时操作应自行异步
+func OnUsage(hook UsageHook) {
+	usageHookMu.Lock()
+	defer usageHookMu.Unlock()
+	usageHook = hook
+}
+
+// reportUsage 把一次请求的用量交给全局回调
+func reportUsage(provider, model string, u Usage) {
+	usageHookMu.RLock()
+	hook := usageHook
+	usageHookMu.RUnlock()
+	if hook != nil {
+		hook(provider, model, u)
+	}
+}
+
+// modelCost 按提供商配置中的模型单价计算费用
+func modelCost(provider, model string, input, output int) float64 {
+	p, ok := config.Get().LLM.Providers[provider]
+	if !ok {
+		return 0
+	}
+	for _, info := range p.Models.Available {
+		if info.Name == model {
+			return float64(input)/1000*info.CostPer1kInput + float64(output)/1000*info.CostPer1kOutput
+		}
+	}
+	return 0
+}
+
+// WithMeter 返回把用量记到指定计量器的客户端副本（共享HTTP连接和限流器），用于按任务统计费用
+func (c *Client) WithMeter(m *Meter) *Client {
+	cp := *c
+	cp.meter = m
+	return &cp
+}
+
+// ============================================
+// 全局并发预算
+// ============================================
+
+// concurrency 进程内同时进行的LLM请求数上限，跨提供商/模型共享
+// 上限取自 llm.max_concurrent，每次申请时读取，配置变化后立即生效；0表示不限制
+var concurrency = struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	active int
+}{}
+
+func init() {
+	concurrency.cond = sync.NewCond(&concurrency.mu)
+}
+
+// concurrencyLimit 当前配置的并发上限
+func concurrencyLimit() int {
+	return config.Get().LLM.MaxConcurrent
+}
+
+// acquireSlot 等待一个并发名额
+func acquireSlot() {
+	limit := concurrencyLimit()
+
+	concurrency.mu.Lock()
+	defer concurrency.mu.Unlock()
+	for limit > 0 && concurrency.active >= limit {
+		concurrency.cond.Wait()
+		limit = concurrencyLimit()
+	}
+	concurrency.active++
+}
+
+// releaseSlot 归还并发名额
+func releaseSlot() {
+	concurrency.mu.Lock()
+	concurrency.active--
+	concurrency.mu.Unlock()
+	concurrency.cond.Broadcast()
+}
+
+// ConcurrencyStats 正在进行的LLM请求数和并发上限（0表示不限制）
+func ConcurrencyStats() (active, limit int) {
+	concurrency.mu.Lock()
+	defer concurrency.mu.Unlock()
+	return concurrency.active, concurrencyLimit()
+}
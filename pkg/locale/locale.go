@@ -0,0 +1,128 @@
+// Package locale 输出语言 - 提示词中的输出语言要求、按语言计字数和结构性用语（章、幕、场景）
+// 提示词本身保持中文，由系统提示词末尾的语言要求决定模型输出的语言
+package locale
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// Terms 结构性用语
+type Terms struct {
+	Language string
+	chapter  string    // 章节标题格式
+	scene    string    // 场景标题格式
+	acts     [3]string // 三幕名称
+	act      string    // 第四幕及以后的格式
+	quotes   [2]string // 书名、章名的引号
+}
+
+var terms = map[string]Terms{
+	models.LanguageZhCN: {
+		chapter: "第%d章", scene: "场景%d",
+		acts: [3]string{"第一幕", "第二幕", "第三幕"}, act: "第%d幕",
+		quotes: [2]string{"《", "》"},
+	},
+	models.LanguageZhTW: {
+		chapter: "第%d章", scene: "場景%d",
+		acts: [3]string{"第一幕", "第二幕", "第三幕"}, act: "第%d幕",
+		quotes: [2]string{"《", "》"},
+	},
+	models.LanguageEn: {
+		chapter: "Chapter %d", scene: "Scene %d",
+		acts: [3]string{"Act I", "Act II", "Act III"}, act: "Act %d",
+		quotes: [2]string{"“", "”"},
+	},
+	models.LanguageJa: {
+		chapter: "第%d章", scene: "シーン%d",
+		acts: [3]string{"第一幕", "第二幕", "第三幕"}, act: "第%d幕",
+		quotes: [2]string{"『", "』"},
+	},
+}
+
+// instructions 追加到系统提示词末尾的输出语言要求，简体中文不追加
+var instructions = map[string]string{
+	models.LanguageZhTW: "所有叙述、对白、名称和说明一律使用繁体中文（台湾用语和标点）书写。",
+	models.LanguageEn: "所有叙述、对白、名称和说明一律使用英文书写，行文符合英文小说的习惯；" +
+		"人名、地名等专有名词采用适合英文读者的写法，前后保持一致；提示中的“字数”均指英文单词数。",
+	models.LanguageJa: "所有叙述、对白、名称和说明一律使用日文书写，行文符合日文小说的习惯；" +
+		"专有名词采用适合日文读者的写法，前后保持一致；提示中的“字数”指日文字符数。",
+}
+
+// names 语言的中文名称，用于提示词
+var names = map[string]string{
+	models.LanguageZhCN: "简体中文",
+	models.LanguageZhTW: "繁体中文",
+	models.LanguageEn:   "英文",
+	models.LanguageJa:   "日文",
+}
+
+// Normalize 规范化语言代码，空值或不支持的语言返回简体中文
+func Normalize(lang string) string {
+	if _, ok := terms[lang]; ok {
+		return lang
+	}
+	return models.LanguageZhCN
+}
+
+// Instruction 系统提示词中的输出语言要求，简体中文返回空
+func Instruction(lang string) string {
+	text, ok := instructions[Normalize(lang)]
+	if !ok {
+		return ""
+	}
+	return "# 输出语言\n" + text + "JSON的键名和约定的枚举取值保持原样，不要翻译。"
+}
+
+// Name 语言的中文名称，如“英文”
+func Name(lang string) string {
+	return names[Normalize(lang)]
+}
+
+// For 指定语言的结构性用语
+func For(lang string) Terms {
+	lang = Normalize(lang)
+	t := terms[lang]
+	t.Language = lang
+	return t
+}
+
+// Chapter 章节标题，如“第3章”“Chapter 3”
+func (t Terms) Chapter(n int) string {
+	return fmt.Sprintf(t.chapter, n)
+}
+
+// Scene 场景标题
+func (t Terms) Scene(n int) string {
+	return fmt.Sprintf(t.scene, n)
+}
+
+// Act 幕名称（从1开始）
+func (t Terms) Act(n int) string {
+	if n >= 1 && n <= len(t.acts) {
+		return t.acts[n-1]
+	}
+	return fmt.Sprintf(t.act, n)
+}
+
+// Title 加引号的书名或章名
+func (t Terms) Title(s string) string {
+	return t.quotes[0] + s + t.quotes[1]
+}
+
+// CountWords 按语言计字数：英文按单词计，中文和日文按非空白字符计
+func CountWords(lang, text string) int {
+	if Normalize(lang) == models.LanguageEn {
+		return len(strings.Fields(text))
+	}
+	count := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,157 @@
+// Package logx 结构化日志
+// 基于 log/slog，日志按 trace_id（HTTP请求ID或异步任务ID）串联一次请求/任务中 llm、narrative、worldbuilder 的输出；
+// 带 trace_id 的日志同时写入内存缓冲，供 API 按请求/任务查询
+package logx
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/xlei/xupu/pkg/config"
+)
+
+// TraceKey 串联日志的属性名
+const TraceKey = "trace_id"
+
+// promptLimit 非脱敏模式下提示词/响应在日志中保留的最大字符数
+const promptLimit = 3000
+
+var (
+	mu     sync.RWMutex
+	base   = newLogger(os.Stderr, slog.LevelInfo, "text")
+	redact bool
+)
+
+// Init 按配置初始化全局日志：级别、格式、提示词脱敏和每个 trace 的缓冲条数
+func Init(cfg config.LoggingConfig) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	base = newLogger(os.Stderr, ParseLevel(cfg.Level), cfg.Format)
+	redact = cfg.RedactPrompts
+	traces.setLimit(cfg.TraceBuffer)
+}
+
+// SetLevel 调整全局日志级别（保留格式和脱敏设置）
+func SetLevel(level slog.Level) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	h := base.Handler().(*traceHandler)
+	base = slog.New(&traceHandler{next: withLevel(h.out, level, h.format), out: h.out, format: h.format})
+}
+
+// ParseLevel 解析日志级别名称，无法识别时为 info
+func ParseLevel(name string) slog.Level {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "debug":
+		return slog.LevelDebug
+	case "warn", "warning":
+		return slog.LevelWarn
+	case "error":
+		return slog.LevelError
+	}
+	return slog.LevelInfo
+}
+
+func newLogger(w io.Writer, level slog.Level, format string) *slog.Logger {
+	return slog.New(&traceHandler{next: withLevel(w, level, format), out: w, format: format})
+}
+
+func withLevel(w io.Writer, level slog.Level, format string) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "json" {
+		return slog.NewJSONHandler(w, opts)
+	}
+	return slog.NewTextHandler(w, opts)
+}
+
+// L 全局日志
+func L() *slog.Logger {
+	mu.RLock()
+	defer mu.RUnlock()
+	return base
+}
+
+// WithTrace 返回带 trace_id 的日志，trace 为空时返回全局日志
+func WithTrace(traceID string) *slog.Logger {
+	if traceID == "" {
+		return L()
+	}
+	return L().With(TraceKey, traceID)
+}
+
+// Or 返回 l，为 nil 时返回全局日志（供持有可选日志字段的模块使用）
+func Or(l *slog.Logger) *slog.Logger {
+	if l == nil {
+		return L()
+	}
+	return l
+}
+
+// Prompt 提示词/响应正文属性：开启脱敏时只记录长度，否则截断到 promptLimit 个字符
+func Prompt(key, text string) slog.Attr {
+	mu.RLock()
+	r := redact
+	mu.RUnlock()
+
+	n := utf8.RuneCountInString(text)
+	if r {
+		return slog.String(key, fmt.Sprintf("[已隐去 %d 字]", n))
+	}
+	if n > promptLimit {
+		runes := []rune(text)
+		return slog.String(key, string(runes[:promptLimit])+fmt.Sprintf("...(共%d字)", n))
+	}
+	return slog.String(key, text)
+}
+
+// traceHandler 包装输出 handler：带 trace_id 的记录同时写入内存缓冲
+// 缓冲不受输出级别限制，debug 级别的提示词记录也可按 trace 查询
+type traceHandler struct {
+	next   slog.Handler
+	out    io.Writer
+	format string
+	trace  string
+	attrs  []slog.Attr
+}
+
+func (h *traceHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.trace != "" || h.next.Enabled(ctx, level)
+}
+
+func (h *traceHandler) Handle(ctx context.Context, r slog.Record) error {
+	if h.trace != "" {
+		traces.add(h.trace, newEntry(r, h.attrs))
+	}
+	if !h.next.Enabled(ctx, r.Level) {
+		return nil
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *traceHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	nh := *h
+	nh.next = h.next.WithAttrs(attrs)
+	nh.attrs = append([]slog.Attr(nil), h.attrs...)
+	for _, a := range attrs {
+		if a.Key == TraceKey {
+			nh.trace = a.Value.String()
+			continue
+		}
+		nh.attrs = append(nh.attrs, a)
+	}
+	return &nh
+}
+
+func (h *traceHandler) WithGroup(name string) slog.Handler {
+	nh := *h
+	nh.next = h.next.WithGroup(name)
+	return &nh
+}
@@ -0,0 +1,96 @@
+package logx
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	defaultTraceBuffer = 500 // 每个 trace 默认保留的日志条数
+	maxTraces          = 256 // 内存中最多保留的 trace 数，超出时淘汰最早的
+)
+
+// Entry 一条缓冲的日志
+type Entry struct {
+	Time    time.Time              `json:"time"`
+	Level   string                 `json:"level"`
+	Message string                 `json:"message"`
+	Attrs   map[string]interface{} `json:"attrs,omitempty"`
+}
+
+func newEntry(r slog.Record, attrs []slog.Attr) Entry {
+	e := Entry{Time: r.Time, Level: r.Level.String(), Message: r.Message}
+	if len(attrs) == 0 && r.NumAttrs() == 0 {
+		return e
+	}
+	e.Attrs = make(map[string]interface{}, len(attrs)+r.NumAttrs())
+	for _, a := range attrs {
+		e.Attrs[a.Key] = a.Value.Resolve().Any()
+	}
+	r.Attrs(func(a slog.Attr) bool {
+		v := a.Value.Resolve()
+		if err, ok := v.Any().(error); ok {
+			e.Attrs[a.Key] = err.Error()
+		} else {
+			e.Attrs[a.Key] = v.Any()
+		}
+		return true
+	})
+	return e
+}
+
+// traceStore 按 trace 缓冲日志，每个 trace 只保留最近 limit 条
+type traceStore struct {
+	mu      sync.Mutex
+	limit   int
+	entries map[string][]Entry
+	order   []string // trace 首次出现的顺序，用于淘汰
+}
+
+var traces = &traceStore{limit: defaultTraceBuffer, entries: make(map[string][]Entry)}
+
+func (s *traceStore) setLimit(limit int) {
+	if limit <= 0 {
+		limit = defaultTraceBuffer
+	}
+	s.mu.Lock()
+	s.limit = limit
+	s.mu.Unlock()
+}
+
+func (s *traceStore) add(trace string, e Entry) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.entries[trace]
+	if !ok {
+		s.order = append(s.order, trace)
+		if len(s.order) > maxTraces {
+			delete(s.entries, s.order[0])
+			s.order = s.order[1:]
+		}
+	}
+	list = append(list, e)
+	if len(list) > s.limit {
+		list = list[len(list)-s.limit:]
+	}
+	s.entries[trace] = list
+}
+
+// Entries 获取指定请求/任务的缓冲日志（按时间顺序），minLevel 以下的条目被过滤
+func Entries(traceID string, minLevel slog.Level) []Entry {
+	traces.mu.Lock()
+	defer traces.mu.Unlock()
+
+	list := traces.entries[traceID]
+	out := make([]Entry, 0, len(list))
+	for _, e := range list {
+		var level slog.Level
+		if level.UnmarshalText([]byte(e.Level)) == nil && level < minLevel {
+			continue
+		}
+		out = append(out, e)
+	}
+	return out
+}
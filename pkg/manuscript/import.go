@@ -0,0 +1,311 @@
+package manuscript
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/logx"
+	"github.com/xlei/xupu/pkg/narrative"
+	"github.com/xlei/xupu/pkg/quota"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// DefaultContinueChapters 默认续写规划的章节数
+const DefaultContinueChapters = 10
+
+// Options 导入参数
+type Options struct {
+	UserID           string // 为空时（CLI 本地调用）不检查月度额度
+	Name             string
+	Author           string
+	Description      string
+	Language         string
+	ContinueChapters int // 续写规划的章节数，为 0 时使用 DefaultContinueChapters
+}
+
+// Import 一次书稿导入，Create 保存项目和章节后由 Run 分析书稿并规划续写
+type Import struct {
+	Project  *models.Project
+	Record   *models.ManuscriptImport
+	Chapters []*models.Chapter
+
+	db     db.Database
+	logger *slog.Logger
+}
+
+// Create 创建项目并保存解析出的章节和导入记录，此时尚未调用 LLM
+func Create(database db.Database, files []string, chapters []Chapter, opts Options) (*Import, error) {
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("没有解析出章节")
+	}
+	if opts.ContinueChapters <= 0 {
+		opts.ContinueChapters = DefaultContinueChapters
+	}
+
+	project := &models.Project{
+		ID:          db.GenerateID("project"),
+		UserID:      opts.UserID,
+		Name:        opts.Name,
+		Author:      opts.Author,
+		Description: opts.Description,
+		Language:    opts.Language,
+		Mode:        models.ModeLocalImport,
+		Status:      models.StatusBuilding,
+	}
+	if err := database.SaveProject(project); err != nil {
+		return nil, fmt.Errorf("保存项目失败: %w", err)
+	}
+
+	imp := &Import{Project: project, db: database}
+	for _, ch := range chapters {
+		chapter := &models.Chapter{
+			ID:         db.GenerateID("chapter"),
+			ProjectID:  project.ID,
+			ChapterNum: ch.Num,
+			Title:      ch.Title,
+			Content:    ch.Content,
+			WordCount:  writer.CountWords(ch.Content),
+			Status:     models.ChapterStatusCompleted,
+		}
+		if err := database.SaveChapter(chapter); err != nil {
+			return nil, fmt.Errorf("保存第%d章失败: %w", ch.Num, err)
+		}
+		imp.Chapters = append(imp.Chapters, chapter)
+	}
+
+	imp.Record = &models.ManuscriptImport{
+		ID:               db.GenerateID("msimport"),
+		ProjectID:        project.ID,
+		UserID:           opts.UserID,
+		Files:            files,
+		Chapters:         len(chapters),
+		ContinueChapters: opts.ContinueChapters,
+		Status:           models.ManuscriptImportRunning,
+		Stage:            models.ManuscriptStageAnalyze,
+	}
+	if err := database.SaveManuscriptImport(imp.Record); err != nil {
+		return nil, fmt.Errorf("保存导入记录失败: %w", err)
+	}
+	return imp, nil
+}
+
+// WithLogger 指定日志，用于按请求串联日志
+func (imp *Import) WithLogger(l *slog.Logger) *Import {
+	imp.logger = l
+	return imp
+}
+
+// Run 分析书稿重建角色、世界设定、细节事实和演化状态，再从下一章起规划续写；
+// 失败时导入的章节保留，项目仍可手动续写，导入记录标记为失败
+func (imp *Import) Run(progress narrative.ManuscriptProgress) error {
+	err := imp.run(progress)
+
+	record := imp.Record
+	if err != nil {
+		record.Status = models.ManuscriptImportFailed
+		record.Error = err.Error()
+		imp.log().Error("书稿导入失败", "project_id", imp.Project.ID, "error", err)
+	} else {
+		record.Status = models.ManuscriptImportCompleted
+		record.Stage = models.ManuscriptStageDone
+	}
+	if saveErr := imp.db.SaveManuscriptImport(record); saveErr != nil {
+		imp.log().Warn("保存导入记录失败", "import_id", record.ID, "error", saveErr)
+	}
+
+	imp.Project.Status = models.StatusCompleted
+	imp.Project.Progress = 100
+	if total := record.Chapters + record.ContinueChapters; err == nil && total > 0 {
+		imp.Project.Progress = float64(record.Chapters) * 100 / float64(total)
+	}
+	if saveErr := imp.db.SaveProject(imp.Project); saveErr != nil {
+		imp.log().Warn("保存项目失败", "project_id", imp.Project.ID, "error", saveErr)
+	}
+	return err
+}
+
+func (imp *Import) run(progress narrative.ManuscriptProgress) error {
+	engine, err := narrative.New()
+	if err != nil {
+		return err
+	}
+	engine = engine.WithLogger(imp.log()).WithLanguage(imp.Project.Language)
+	if imp.Project.UserID != "" {
+		engine = engine.WithBudget(quota.For(imp.db, imp.Project.UserID))
+	}
+
+	chapters := make([]narrative.ManuscriptChapter, 0, len(imp.Chapters))
+	written := 0
+	for _, ch := range imp.Chapters {
+		chapters = append(chapters, narrative.ManuscriptChapter{
+			Num:       ch.ChapterNum,
+			Title:     ch.Title,
+			Content:   ch.Content,
+			WordCount: ch.WordCount,
+		})
+		if ch.ChapterNum > 0 {
+			written += ch.WordCount
+		}
+	}
+
+	analysis, err := engine.AnalyzeManuscript(chapters, func(stage string, done, total int) {
+		imp.Record.Stage = stage
+		imp.Record.Analyzed = done
+		if err := imp.db.SaveManuscriptImport(imp.Record); err != nil {
+			imp.log().Warn("保存导入进度失败", "import_id", imp.Record.ID, "error", err)
+		}
+		if progress != nil {
+			progress(stage, done, total)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	if err := imp.saveAnalysis(analysis); err != nil {
+		return err
+	}
+
+	imp.Record.Stage = models.ManuscriptStagePlan
+	if err := imp.db.SaveManuscriptImport(imp.Record); err != nil {
+		imp.log().Warn("保存导入进度失败", "import_id", imp.Record.ID, "error", err)
+	}
+	if progress != nil {
+		progress(models.ManuscriptStagePlan, len(chapters), len(chapters))
+	}
+	wordCount := 0
+	if analysis.LastChapter > 0 {
+		wordCount = written / analysis.LastChapter
+	}
+	plan, err := engine.PlanContinuation(analysis, narrative.ContinuationParams{
+		Chapters:  imp.Record.ContinueChapters,
+		WordCount: wordCount,
+	})
+	if err != nil {
+		return err
+	}
+	imp.Record.ContinueChapters = len(plan.ChapterPlans)
+
+	blueprint := engine.ContinuationBlueprint(analysis, chapters, plan)
+	blueprint.ProjectID = imp.Project.ID
+	if err := imp.db.SaveNarrativeBlueprint(blueprint); err != nil {
+		return fmt.Errorf("保存叙事蓝图失败: %w", err)
+	}
+	imp.Project.WorldID = analysis.World.ID
+	imp.Project.NarrativeID = blueprint.ID
+
+	state, err := json.Marshal(analysis.State)
+	if err != nil {
+		return fmt.Errorf("序列化演化状态失败: %w", err)
+	}
+	imp.Record.State = models.JSON(state)
+	return nil
+}
+
+// saveAnalysis 保存分析出的世界设定、角色、细节事实，并把章节摘要写回章节作为续写的前情
+func (imp *Import) saveAnalysis(a *narrative.ManuscriptAnalysis) error {
+	if err := imp.db.SaveWorld(a.World); err != nil {
+		return fmt.Errorf("保存世界设定失败: %w", err)
+	}
+
+	ids := make(map[string]string, len(a.Characters))
+	for _, c := range a.Characters {
+		ids[c.Name] = c.ID
+	}
+	for _, c := range a.Characters {
+		if err := imp.db.SaveCharacter(manuscriptCharacter(a.World.ID, c, ids)); err != nil {
+			return fmt.Errorf("保存角色 %s 失败: %w", c.Name, err)
+		}
+	}
+	imp.Record.Characters = len(a.Characters)
+
+	for _, f := range a.Facts {
+		fact := &models.ContinuityFact{
+			ID:           db.GenerateID("fact"),
+			ProjectID:    imp.Project.ID,
+			Subject:      f.Subject,
+			Attribute:    f.Attribute,
+			Value:        f.Value,
+			Category:     f.Category,
+			Permanent:    f.Permanent,
+			Chapter:      f.Chapter,
+			EndedChapter: f.EndedChapter,
+			Evidence:     f.Evidence,
+			Source:       models.FactFromProse,
+		}
+		if err := imp.db.SaveContinuityFact(fact); err != nil {
+			return fmt.Errorf("保存细节事实失败: %w", err)
+		}
+	}
+	imp.Record.Facts = len(a.Facts)
+
+	open := 0
+	for _, f := range a.State.Foreshadowing {
+		if !f.IsPaidOff {
+			open++
+		}
+	}
+	imp.Record.OpenForeshadows = open
+
+	for _, ch := range imp.Chapters {
+		summary, rolling := a.Summaries[ch.ChapterNum], a.StorySoFar[ch.ChapterNum]
+		if summary == "" && rolling == "" {
+			continue
+		}
+		ch.Summary = summary
+		ch.RollingSummary = rolling
+		if err := imp.db.SaveChapter(ch); err != nil {
+			return fmt.Errorf("保存第%d章摘要失败: %w", ch.ChapterNum, err)
+		}
+	}
+	return nil
+}
+
+// manuscriptCharacter 把书稿中识别的角色转换为角色档案，关系以角色ID为键
+func manuscriptCharacter(worldID string, c *narrative.ManuscriptCharacter, ids map[string]string) *models.Character {
+	character := &models.Character{
+		ID:      c.ID,
+		WorldID: worldID,
+		Name:    c.Name,
+		StaticProfile: models.StaticProfile{
+			Occupation: c.Identity,
+		},
+		NarrativeProfile: models.NarrativeProfile{
+			Motivation: models.Motivation{
+				CoreNeed:     c.Need,
+				ExternalGoal: c.Want,
+			},
+			Fear:          c.Fear,
+			Relationships: make(map[string]*models.Relationship),
+		},
+		DynamicState: models.DynamicState{
+			Location: c.Location,
+			Emotion:  models.Emotion{Current: c.Emotion},
+		},
+	}
+	for _, trait := range strings.FieldsFunc(c.Personality, func(r rune) bool {
+		return strings.ContainsRune("，,、；;", r)
+	}) {
+		if trait = strings.TrimSpace(trait); trait != "" {
+			character.NarrativeProfile.Personality = append(character.NarrativeProfile.Personality, models.Trait{Name: trait, Category: "neutral"})
+		}
+	}
+	for _, r := range c.Relationships {
+		target, ok := ids[r.Target]
+		if !ok {
+			continue
+		}
+		character.NarrativeProfile.Relationships[target] = &models.Relationship{
+			CharacterID: target,
+			Attitude:    r.Relation,
+		}
+	}
+	return character
+}
+
+func (imp *Import) log() *slog.Logger {
+	return logx.Or(imp.logger)
+}
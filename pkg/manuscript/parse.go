@@ -0,0 +1,267 @@
+// Package manuscript 已有书稿的导入：解析 TXT/Markdown/DOCX 章节，分析出角色、世界事实、情节线和伏笔，
+// 重建演化状态后从下一章起续写规划
+package manuscript
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Chapter 解析出的一章
+type Chapter struct {
+	Num     int    `json:"num"`
+	Title   string `json:"title"`
+	Content string `json:"content"`
+	Source  string `json:"source"` // 来源文件名
+}
+
+// 支持的文件格式
+var supportedExts = map[string]bool{".txt": true, ".md": true, ".markdown": true, ".docx": true}
+
+// Supported 文件是否为支持的书稿格式
+func Supported(filename string) bool {
+	return supportedExts[strings.ToLower(filepath.Ext(filename))]
+}
+
+// chapterHeading 中文章节标题行：第X章 / 第X节 / 第X回
+var chapterHeading = regexp.MustCompile(`(?m)^[ \t\x{3000}]*(第[0-9０-９一二三四五六七八九十百千零〇两]+[章节回][^\n]*)$`)
+
+// markdownHeading Markdown 一、二级标题
+var markdownHeading = regexp.MustCompile(`(?m)^#{1,2}[ \t]+([^\n]+)$`)
+
+// frontMatter 文件开头的 YAML 文件头（xupu chapter pull 导出的文件带有）
+var frontMatter = regexp.MustCompile(`\A---\n[\s\S]*?\n---\n`)
+
+// frontMatterTitle 文件头中的标题
+var frontMatterTitle = regexp.MustCompile(`(?m)^title:[ \t]*"?([^"\n]+)"?[ \t]*$`)
+
+// SplitChapters 按章节标题切分正文：优先匹配“第X章”，Markdown 文件其次按一、二级标题切分；
+// 第一个标题之前的内容作为序章（编号 0），没有标题时整段作为一章，标题为 fallbackTitle
+func SplitChapters(content string, markdown bool, fallbackTitle string) []Chapter {
+	content = strings.ReplaceAll(content, "\r\n", "\n")
+
+	indexes := chapterHeading.FindAllStringSubmatchIndex(content, -1)
+	if len(indexes) == 0 && markdown {
+		indexes = markdownHeading.FindAllStringSubmatchIndex(content, -1)
+	}
+
+	var chapters []Chapter
+	if len(indexes) == 0 {
+		if body := strings.TrimSpace(content); body != "" {
+			chapters = append(chapters, Chapter{Num: 1, Title: fallbackTitle, Content: body})
+		}
+		return chapters
+	}
+
+	if preface := strings.TrimSpace(content[:indexes[0][0]]); preface != "" {
+		chapters = append(chapters, Chapter{Num: 0, Title: "序章/引子", Content: preface})
+	}
+	for i, idx := range indexes {
+		end := len(content)
+		if i < len(indexes)-1 {
+			end = indexes[i+1][0]
+		}
+		chapters = append(chapters, Chapter{
+			Num:     i + 1,
+			Title:   strings.TrimSpace(content[idx[2]:idx[3]]),
+			Content: strings.TrimSpace(content[idx[1]:end]),
+		})
+	}
+	return chapters
+}
+
+// ParseFile 解析一个书稿文件，一个文件可以包含多章
+func ParseFile(filename string, data []byte) ([]Chapter, error) {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !supportedExts[ext] {
+		return nil, fmt.Errorf("不支持的文件格式: %s（支持 txt、md、docx）", filename)
+	}
+	title := strings.TrimSuffix(filepath.Base(filename), filepath.Ext(filename))
+
+	var text string
+	markdown := ext != ".txt"
+	switch ext {
+	case ".docx":
+		var err error
+		if text, err = docxText(data); err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %w", filename, err)
+		}
+	default:
+		if !utf8.Valid(data) {
+			return nil, fmt.Errorf("%s 不是 UTF-8 编码，请转换后再导入", filename)
+		}
+		text = strings.TrimPrefix(string(data), "\uFEFF")
+		text = strings.ReplaceAll(text, "\r\n", "\n")
+		if fm := frontMatter.FindString(text); fm != "" {
+			if m := frontMatterTitle.FindStringSubmatch(fm); m != nil {
+				title = strings.TrimSpace(m[1])
+			}
+			text = text[len(fm):]
+		}
+	}
+
+	chapters := SplitChapters(text, markdown, title)
+	for i := range chapters {
+		chapters[i].Source = filepath.Base(filename)
+	}
+	return chapters, nil
+}
+
+// File 待导入的文件
+type File struct {
+	Name string
+	Data []byte
+}
+
+// Parse 按文件名的自然顺序（第2章在第10章之前）解析多个文件，并把章节从 1 起连续编号；
+// 只有第一个文件的序章保留为第 0 章，其余文件标题前的内容并入上一章
+func Parse(files []File) ([]Chapter, error) {
+	sorted := make([]File, len(files))
+	copy(sorted, files)
+	sort.SliceStable(sorted, func(i, j int) bool { return naturalLess(sorted[i].Name, sorted[j].Name) })
+
+	var chapters []Chapter
+	for _, f := range sorted {
+		parsed, err := ParseFile(f.Name, f.Data)
+		if err != nil {
+			return nil, err
+		}
+		for _, ch := range parsed {
+			if ch.Content == "" {
+				continue
+			}
+			if ch.Num == 0 && len(chapters) > 0 {
+				last := &chapters[len(chapters)-1]
+				last.Content += "\n\n" + ch.Content
+				continue
+			}
+			chapters = append(chapters, ch)
+		}
+	}
+
+	num := 0
+	for i := range chapters {
+		if chapters[i].Num == 0 && i == 0 {
+			continue
+		}
+		num++
+		chapters[i].Num = num
+	}
+	if num == 0 {
+		return nil, fmt.Errorf("没有解析出章节")
+	}
+	return chapters, nil
+}
+
+// digits 文件名中的数字段
+var digits = regexp.MustCompile(`\d+`)
+
+// naturalLess 自然顺序比较文件名，数字段按数值比较
+func naturalLess(a, b string) bool {
+	ka, kb := digits.FindAllStringIndex(a, -1), digits.FindAllStringIndex(b, -1)
+	ia, ib := 0, 0
+	for n := 0; n < len(ka) && n < len(kb); n++ {
+		pa, pb := a[ia:ka[n][0]], b[ib:kb[n][0]]
+		if pa != pb {
+			return pa < pb
+		}
+		na, _ := strconv.Atoi(a[ka[n][0]:ka[n][1]])
+		nb, _ := strconv.Atoi(b[kb[n][0]:kb[n][1]])
+		if na != nb {
+			return na < nb
+		}
+		ia, ib = ka[n][1], kb[n][1]
+	}
+	return a[ia:] < b[ib:]
+}
+
+// docxText 提取 DOCX 正文，段落之间换行，标题样式的段落转为 Markdown 标题
+func docxText(data []byte) (string, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return "", fmt.Errorf("不是有效的 DOCX 文件: %w", err)
+	}
+	var doc io.ReadCloser
+	for _, f := range zr.File {
+		if f.Name == "word/document.xml" {
+			if doc, err = f.Open(); err != nil {
+				return "", err
+			}
+			break
+		}
+	}
+	if doc == nil {
+		return "", fmt.Errorf("缺少 word/document.xml")
+	}
+	defer doc.Close()
+
+	var (
+		out      strings.Builder
+		para     strings.Builder
+		heading  bool
+		inText   bool
+		inParaPr bool
+		decoder  = xml.NewDecoder(doc)
+	)
+	for {
+		tok, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "p":
+				para.Reset()
+				heading = false
+			case "pPr":
+				inParaPr = true
+			case "pStyle":
+				if inParaPr {
+					for _, attr := range t.Attr {
+						if attr.Name.Local == "val" && (strings.HasPrefix(attr.Value, "Heading") || attr.Value == "Title") {
+							heading = true
+						}
+					}
+				}
+			case "t":
+				inText = true
+			case "tab":
+				para.WriteString("\t")
+			case "br", "cr":
+				para.WriteString("\n")
+			}
+		case xml.EndElement:
+			switch t.Name.Local {
+			case "pPr":
+				inParaPr = false
+			case "t":
+				inText = false
+			case "p":
+				line := para.String()
+				if heading && strings.TrimSpace(line) != "" {
+					line = "# " + strings.TrimSpace(line)
+				}
+				out.WriteString(line)
+				out.WriteString("\n")
+			}
+		case xml.CharData:
+			if inText {
+				para.Write(t)
+			}
+		}
+	}
+	return out.String(), nil
+}
@@ -0,0 +1,126 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// ChapterSourceID 章节记忆的来源标识
+func ChapterSourceID(chapter int) string {
+	return fmt.Sprintf("chapter_%d", chapter)
+}
+
+// ChapterPlanDocuments 章节规划摘要，作为尚未写出正文的章节的前情记忆
+func ChapterPlanDocuments(plans []models.ChapterPlan) []Document {
+	docs := make([]Document, 0, len(plans))
+	for _, ch := range plans {
+		parts := []string{fmt.Sprintf("第%d章《%s》", ch.Chapter, ch.Title)}
+		for _, s := range []string{ch.Purpose, ch.PlotAdvancement, ch.ArcProgress, ch.EndingHook} {
+			if s != "" {
+				parts = append(parts, s)
+			}
+		}
+		docs = append(docs, Document{
+			Kind:     models.MemoryChapterSummary,
+			SourceID: ChapterSourceID(ch.Chapter),
+			Chapter:  ch.Chapter,
+			Content:  strings.Join(parts, "；"),
+		})
+	}
+	return docs
+}
+
+// ChapterSummaryDocument 由正文生成的章节摘要，覆盖同一章的规划摘要
+func ChapterSummaryDocument(chapter int, title, summary string) Document {
+	return Document{
+		Kind:     models.MemoryChapterSummary,
+		SourceID: ChapterSourceID(chapter),
+		Chapter:  chapter,
+		Content:  fmt.Sprintf("第%d章《%s》：%s", chapter, title, summary),
+	}
+}
+
+// CharacterDocuments 角色档案与弧线中的事实
+func CharacterDocuments(characters []*models.Character, arcs map[string]*models.ArcPlan) []Document {
+	docs := make([]Document, 0, len(characters)+len(arcs))
+	for _, char := range characters {
+		if char == nil || char.Name == "" {
+			continue
+		}
+		sp, np := char.StaticProfile, char.NarrativeProfile
+		parts := []string{char.Name}
+		if sp.Occupation != "" || sp.SocialStatus != "" {
+			parts = append(parts, strings.TrimSpace(sp.Occupation+" "+sp.SocialStatus))
+		}
+		if sp.Background != "" {
+			parts = append(parts, "背景："+sp.Background)
+		}
+		if len(sp.Abilities) > 0 {
+			parts = append(parts, "能力："+strings.Join(sp.Abilities, "、"))
+		}
+		if np.Motivation.ExternalGoal != "" {
+			parts = append(parts, "目标："+np.Motivation.ExternalGoal)
+		}
+		if np.Flaw != "" {
+			parts = append(parts, "缺陷："+np.Flaw)
+		}
+		if np.Fear != "" {
+			parts = append(parts, "恐惧："+np.Fear)
+		}
+		docs = append(docs, Document{
+			Kind:     models.MemoryCharacterFact,
+			SourceID: char.ID,
+			Content:  strings.Join(parts, "；"),
+		})
+	}
+
+	for name, arc := range arcs {
+		if arc == nil {
+			continue
+		}
+		content := fmt.Sprintf("%s的弧线（%s）：从「%s」到「%s」", name, arc.ArcType,
+			arc.StartState.Motivation, arc.EndState.Motivation)
+		docs = append(docs, Document{
+			Kind:     models.MemoryCharacterFact,
+			SourceID: "arc_" + name,
+			Content:  content,
+		})
+	}
+	return docs
+}
+
+// WorldDocuments 世界设定条目：区域、种族、宗教、历史事件
+func WorldDocuments(world *models.WorldSetting) []Document {
+	if world == nil {
+		return nil
+	}
+	docs := make([]Document, 0)
+	add := func(sourceID, content string) {
+		docs = append(docs, Document{Kind: models.MemoryWorldEntry, SourceID: sourceID, Content: content})
+	}
+
+	for i, r := range world.Geography.Regions {
+		add(fmt.Sprintf("region_%d", i), fmt.Sprintf("区域%s（%s）：%s", r.Name, r.Type, r.Description))
+	}
+	for i, r := range world.Civilization.Races {
+		add(fmt.Sprintf("race_%d", i), fmt.Sprintf("种族%s：%s", r.Name, r.Description))
+	}
+	for i, r := range world.Civilization.Religions {
+		add(fmt.Sprintf("religion_%d", i), fmt.Sprintf("宗教%s：%s", r.Name, r.Cosmology))
+	}
+	for i, e := range world.History.Events {
+		add(fmt.Sprintf("event_%d", i), fmt.Sprintf("历史事件%s（%s）：%s", e.Name, e.Time, e.Description))
+	}
+	return docs
+}
+
+// SceneQuery 由场景指令构建检索查询
+func SceneQuery(instr *models.SceneInstruction) string {
+	if instr == nil {
+		return ""
+	}
+	parts := []string{instr.Purpose, instr.Location, strings.Join(instr.Characters, " "), instr.Action, instr.DialogueFocus}
+	return strings.Join(parts, " ")
+}
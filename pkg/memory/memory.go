@@ -0,0 +1,336 @@
+// Package memory 检索记忆
+// 将章节摘要、角色事实和世界设定条目嵌入为向量，生成场景时按相关度召回 top-k 条注入提示词，
+// 解决长篇项目中上下文装不下全部前情的问题
+package memory
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// DefaultTopK 默认召回条数
+const DefaultTopK = 8
+
+// embedBatchSize 每次嵌入请求的文本数
+const embedBatchSize = 16
+
+// Embedder 向量嵌入器
+type Embedder interface {
+	Name() string
+	Embed(texts []string) ([][]float32, error)
+}
+
+// Document 待索引的记忆文档
+type Document struct {
+	Kind     string
+	SourceID string
+	Chapter  int
+	Content  string
+}
+
+// SearchOptions 检索选项
+type SearchOptions struct {
+	TopK          int      // 召回条数，0使用默认值
+	BeforeChapter int      // 只召回该章之前的章节记忆（不影响不限章节的条目），0表示不限
+	Kinds         []string // 限定记忆类型，为空表示全部
+}
+
+// Result 检索结果
+type Result struct {
+	Entry models.MemoryEntry `json:"entry"`
+	Score float64            `json:"score"`
+}
+
+// Store 记忆存储
+type Store struct {
+	db       db.Database
+	embedder Embedder
+}
+
+// NewStore 创建记忆存储
+// 配置了 embedding 模块时使用LLM嵌入接口，否则使用本地哈希嵌入
+func NewStore(database db.Database) *Store {
+	var embedder Embedder = HashEmbedder{}
+	if client, _, err := llm.NewClientForModule("embedding"); err == nil {
+		embedder = llmEmbedder{client: client}
+	}
+	return NewStoreWithEmbedder(database, embedder)
+}
+
+// NewStoreWithEmbedder 使用指定嵌入器创建记忆存储
+func NewStoreWithEmbedder(database db.Database, embedder Embedder) *Store {
+	return &Store{db: database, embedder: embedder}
+}
+
+// Index 索引文档：内容和嵌入模型都未变化的条目跳过，其余重新嵌入后保存
+func (s *Store) Index(blueprintID string, docs []Document) error {
+	existing, err := s.db.ListMemoryEntries(blueprintID)
+	if err != nil {
+		return fmt.Errorf("读取记忆失败: %w", err)
+	}
+	byID := make(map[string]models.MemoryEntry, len(existing))
+	for _, e := range existing {
+		byID[e.ID] = e
+	}
+
+	pending := make([]models.MemoryEntry, 0)
+	for _, doc := range docs {
+		content := strings.TrimSpace(doc.Content)
+		if content == "" {
+			continue
+		}
+		id := entryID(blueprintID, doc.Kind, doc.SourceID)
+		hash := contentHash(content)
+		if old, ok := byID[id]; ok && old.ContentHash == hash && old.Model == s.embedder.Name() {
+			continue
+		}
+		entry := models.MemoryEntry{
+			ID:          id,
+			BlueprintID: blueprintID,
+			Kind:        doc.Kind,
+			SourceID:    doc.SourceID,
+			Chapter:     doc.Chapter,
+			Content:     content,
+			ContentHash: hash,
+			Model:       s.embedder.Name(),
+		}
+		if old, ok := byID[id]; ok {
+			entry.CreatedAt = old.CreatedAt
+		}
+		pending = append(pending, entry)
+	}
+
+	for start := 0; start < len(pending); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(pending))
+		batch := pending[start:end]
+
+		texts := make([]string, len(batch))
+		for i, e := range batch {
+			texts[i] = e.Content
+		}
+		vectors, err := s.embedder.Embed(texts)
+		if err != nil {
+			return fmt.Errorf("嵌入失败: %w", err)
+		}
+		for i := range batch {
+			batch[i].Embedding = normalize(vectors[i])
+		}
+	}
+
+	return s.db.SaveMemoryEntries(pending)
+}
+
+// Search 按查询文本检索最相关的记忆
+func (s *Store) Search(blueprintID, query string, opts SearchOptions) ([]Result, error) {
+	topK := opts.TopK
+	if topK <= 0 {
+		topK = DefaultTopK
+	}
+
+	entries, err := s.db.ListMemoryEntries(blueprintID)
+	if err != nil {
+		return nil, fmt.Errorf("读取记忆失败: %w", err)
+	}
+	if len(entries) == 0 || strings.TrimSpace(query) == "" {
+		return []Result{}, nil
+	}
+
+	vectors, err := s.embedder.Embed([]string{query})
+	if err != nil {
+		return nil, fmt.Errorf("嵌入查询失败: %w", err)
+	}
+	queryVec := normalize(vectors[0])
+
+	results := make([]Result, 0, len(entries))
+	for _, e := range entries {
+		// 不同模型的向量不可比较，等下次索引时重新嵌入
+		if e.Model != s.embedder.Name() || len(e.Embedding) != len(queryVec) {
+			continue
+		}
+		if opts.BeforeChapter > 0 && e.Chapter >= opts.BeforeChapter {
+			continue
+		}
+		if len(opts.Kinds) > 0 && !contains(opts.Kinds, e.Kind) {
+			continue
+		}
+		results = append(results, Result{Entry: e, Score: dot(queryVec, e.Embedding)})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Score > results[j].Score })
+	if len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// Recall 检索并格式化为提示词条目，失败时记录日志并返回空
+func (s *Store) Recall(blueprintID, query string, opts SearchOptions) []string {
+	results, err := s.Search(blueprintID, query, opts)
+	if err != nil {
+		log.Printf("[记忆] 警告: %v", err)
+		return nil
+	}
+	return Format(results)
+}
+
+// Format 将检索结果格式化为提示词条目，章节记忆按章节排序放在最前
+func Format(results []Result) []string {
+	sorted := make([]Result, len(results))
+	copy(sorted, results)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i].Entry, sorted[j].Entry
+		if (a.Chapter > 0) != (b.Chapter > 0) {
+			return a.Chapter > 0
+		}
+		return a.Chapter < b.Chapter
+	})
+
+	lines := make([]string, 0, len(sorted))
+	for _, r := range sorted {
+		lines = append(lines, fmt.Sprintf("[%s] %s", kindLabel(r.Entry.Kind), r.Entry.Content))
+	}
+	return lines
+}
+
+// kindLabel 记忆类型的中文标签
+func kindLabel(kind string) string {
+	switch kind {
+	case models.MemoryChapterSummary:
+		return "前情"
+	case models.MemoryCharacterFact:
+		return "角色"
+	case models.MemoryWorldEntry:
+		return "设定"
+	default:
+		return kind
+	}
+}
+
+// entryID 记忆条目ID，同一来源重复索引时覆盖
+func entryID(blueprintID, kind, sourceID string) string {
+	return fmt.Sprintf("%s:%s:%s", blueprintID, kind, sourceID)
+}
+
+// contentHash 内容摘要
+func contentHash(content string) string {
+	sum := sha1.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalize 归一化为单位向量，余弦相似度即为点积
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, x := range v {
+		norm += float64(x) * float64(x)
+	}
+	if norm == 0 {
+		return v
+	}
+	norm = math.Sqrt(norm)
+	out := make([]float32, len(v))
+	for i, x := range v {
+		out[i] = float32(float64(x) / norm)
+	}
+	return out
+}
+
+// dot 向量点积
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// llmEmbedder 使用LLM嵌入接口
+type llmEmbedder struct {
+	client *llm.Client
+}
+
+func (e llmEmbedder) Name() string {
+	return "llm:" + e.client.Model
+}
+
+func (e llmEmbedder) Embed(texts []string) ([][]float32, error) {
+	return e.client.Embed(texts)
+}
+
+// HashEmbedder 本地哈希嵌入：汉字单字和双字、英文单词哈希到固定维度
+// 不依赖外部服务，适合未配置嵌入模型或离线运行
+type HashEmbedder struct{}
+
+// hashDims 本地哈希嵌入维度
+const hashDims = 512
+
+func (HashEmbedder) Name() string {
+	return fmt.Sprintf("hash-%d", hashDims)
+}
+
+func (HashEmbedder) Embed(texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		v := make([]float32, hashDims)
+		for _, token := range tokenize(text) {
+			h := fnv.New32a()
+			h.Write([]byte(token))
+			v[h.Sum32()%hashDims]++
+		}
+		vectors[i] = v
+	}
+	return vectors, nil
+}
+
+// tokenize 分词：连续汉字产生单字和双字，字母数字按单词切分
+func tokenize(text string) []string {
+	tokens := make([]string, 0)
+	var word strings.Builder
+	var prevHan rune
+
+	flushWord := func() {
+		if word.Len() > 0 {
+			tokens = append(tokens, strings.ToLower(word.String()))
+			word.Reset()
+		}
+	}
+
+	for _, r := range text {
+		switch {
+		case unicode.Is(unicode.Han, r):
+			flushWord()
+			tokens = append(tokens, string(r))
+			if prevHan != 0 {
+				tokens = append(tokens, string([]rune{prevHan, r}))
+			}
+			prevHan = r
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			prevHan = 0
+			word.WriteRune(r)
+		default:
+			prevHan = 0
+			flushWord()
+		}
+	}
+	flushWord()
+	return tokens
+}
@@ -0,0 +1,195 @@
+// Package mentions 实体提及追踪
+// 章节写完后按名称在正文中查找角色、地点和物品的出现位置，记录每章的提及，
+// 汇总成每个实体的出场时间线，用于提示“某角色已经 12 章没有出场”之类的问题。
+// 名称来自角色档案和世界设定，直接做子串匹配，不调用模型。
+package mentions
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+const (
+	// DefaultAbsenceGap 默认的缺席提醒阈值（章）
+	DefaultAbsenceGap = 12
+	// excerptRunes 上下文片段中名称前后各保留的字数
+	excerptRunes = 30
+)
+
+// Entity 可被追踪的实体
+type Entity struct {
+	Kind    models.MentionKind `json:"kind"`
+	ID      string             `json:"id"`
+	Name    string             `json:"name"`
+	Aliases []string           `json:"aliases,omitempty"` // 同样计为提及的其他写法
+}
+
+// ProjectEntities 加载项目世界中的角色、地点，以及项目登记和世界术语表中的物品
+// 单字名称无法可靠匹配，跳过
+func ProjectEntities(database db.Database, projectID, worldID string) []Entity {
+	if projectID == "" && worldID == "" {
+		return nil
+	}
+	entities := make([]Entity, 0)
+	seen := make(map[string]bool)
+	add := func(kind models.MentionKind, id, name string, aliases []string) {
+		name = strings.TrimSpace(name)
+		if utf8.RuneCountInString(name) < 2 || seen[name] {
+			return
+		}
+		seen[name] = true
+		if id == "" {
+			id = name
+		}
+		entities = append(entities, Entity{Kind: kind, ID: id, Name: name, Aliases: aliases})
+	}
+
+	// 登记的关键物品优先，术语表中的同名物品不再重复
+	if projectID != "" {
+		if list, err := database.ListItems(projectID); err == nil {
+			for _, item := range list {
+				add(models.MentionItem, item.ID, item.Name, item.Aliases)
+			}
+		}
+	}
+	if worldID == "" {
+		return entities
+	}
+
+	for _, char := range database.ListCharactersByWorld(worldID) {
+		if char != nil {
+			add(models.MentionCharacter, char.ID, char.Name, nil)
+		}
+	}
+
+	world, err := database.GetWorld(worldID)
+	if err != nil || world == nil {
+		return entities
+	}
+	if len(world.Entities) == 0 {
+		world.RebuildEntityIndex()
+	}
+	for _, e := range world.Entities {
+		if e.Kind == models.EntityRegion {
+			var aliases []string
+			if term := world.FindTerm(e.Name); term != nil {
+				aliases = term.Aliases
+			}
+			add(models.MentionLocation, e.ID, e.Name, aliases)
+		}
+	}
+	for _, t := range world.Glossary {
+		switch t.Kind {
+		case models.TermRegion, models.TermSite:
+			add(models.MentionLocation, "", t.Term, t.Aliases)
+		case models.TermItem:
+			add(models.MentionItem, "", t.Term, t.Aliases)
+		}
+	}
+	return entities
+}
+
+// Detect 在本章正文中查找实体提及，每个出现的实体一条记录
+// 长名称优先匹配，已被长名称覆盖的位置不再计入短名称（如“青云山”中的“青云”）
+func Detect(entities []Entity, prose string) []models.EntityMention {
+	type pattern struct {
+		entity int
+		text   string
+	}
+	patterns := make([]pattern, 0, len(entities))
+	for i, e := range entities {
+		patterns = append(patterns, pattern{entity: i, text: e.Name})
+		for _, a := range e.Aliases {
+			if utf8.RuneCountInString(a) >= 2 {
+				patterns = append(patterns, pattern{entity: i, text: a})
+			}
+		}
+	}
+	sort.SliceStable(patterns, func(i, j int) bool { return len(patterns[i].text) > len(patterns[j].text) })
+
+	covered := make([]bool, len(prose))
+	found := make(map[int]*models.EntityMention)
+	for _, p := range patterns {
+		for start := 0; ; {
+			idx := strings.Index(prose[start:], p.text)
+			if idx < 0 {
+				break
+			}
+			pos := start + idx
+			end := pos + len(p.text)
+			start = end
+			if covered[pos] || covered[end-1] {
+				continue
+			}
+			for k := pos; k < end; k++ {
+				covered[k] = true
+			}
+
+			offset := utf8.RuneCountInString(prose[:pos])
+			m, ok := found[p.entity]
+			if !ok {
+				e := entities[p.entity]
+				m = &models.EntityMention{
+					EntityKind:  e.Kind,
+					EntityID:    e.ID,
+					Name:        e.Name,
+					FirstOffset: offset,
+					Excerpt:     excerpt(prose, pos, end),
+				}
+				found[p.entity] = m
+			} else if offset < m.FirstOffset {
+				m.FirstOffset = offset
+				m.Excerpt = excerpt(prose, pos, end)
+			}
+			m.Count++
+		}
+	}
+
+	mentions := make([]models.EntityMention, 0, len(found))
+	for _, m := range found {
+		mentions = append(mentions, *m)
+	}
+	sort.Slice(mentions, func(i, j int) bool { return mentions[i].FirstOffset < mentions[j].FirstOffset })
+	return mentions
+}
+
+// excerpt 截取名称前后的上下文，不跨段落
+func excerpt(prose string, start, end int) string {
+	before := []rune(prose[:start])
+	from := len(before) - excerptRunes
+	if from < 0 {
+		from = 0
+	}
+	head := string(before[from:])
+	if i := strings.LastIndex(head, "\n"); i >= 0 {
+		head = head[i+1:]
+	}
+	after := []rune(prose[end:])
+	if len(after) > excerptRunes {
+		after = after[:excerptRunes]
+	}
+	tail := string(after)
+	if i := strings.Index(tail, "\n"); i >= 0 {
+		tail = tail[:i]
+	}
+	return strings.TrimSpace(head + prose[start:end] + tail)
+}
+
+// TrackChapter 检测本章的实体提及并替换该章原有的记录
+func TrackChapter(database db.Database, entities []Entity, chapter *models.Chapter, prose string) ([]models.EntityMention, error) {
+	mentions := Detect(entities, prose)
+	for i := range mentions {
+		mentions[i].ID = db.GenerateID("mention")
+		mentions[i].ProjectID = chapter.ProjectID
+		mentions[i].ChapterNum = chapter.ChapterNum
+		mentions[i].ChapterID = chapter.ID
+	}
+	if err := database.ReplaceChapterMentions(chapter.ProjectID, chapter.ChapterNum, mentions); err != nil {
+		return nil, err
+	}
+	return mentions, nil
+}
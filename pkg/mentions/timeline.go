@@ -0,0 +1,98 @@
+package mentions
+
+import (
+	"sort"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// Appearance 实体在某一章的出场
+type Appearance struct {
+	Chapter int    `json:"chapter"`
+	Count   int    `json:"count"`
+	Excerpt string `json:"excerpt"`
+}
+
+// Timeline 单个实体的出场时间线
+type Timeline struct {
+	Kind         models.MentionKind `json:"kind"`
+	EntityID     string             `json:"entity_id"`
+	Name         string             `json:"name"`
+	Appearances  []Appearance       `json:"appearances"`
+	Mentions     int                `json:"mentions"`      // 全书累计提及次数
+	FirstChapter int                `json:"first_chapter"` // 0 表示尚未出场
+	LastChapter  int                `json:"last_chapter"`
+	Absent       int                `json:"absent"` // 距最新一章已缺席的章数
+}
+
+// Absence 缺席提醒
+type Absence struct {
+	Kind        models.MentionKind `json:"kind"`
+	EntityID    string             `json:"entity_id"`
+	Name        string             `json:"name"`
+	LastChapter int                `json:"last_chapter"`
+	Absent      int                `json:"absent"`
+}
+
+// Timelines 按实体汇总提及记录，latest 为已写到的最新章节
+// entities 中尚未出场的实体也会列出，便于发现从未登场的角色
+func Timelines(entities []Entity, mentions []models.EntityMention, latest int) []Timeline {
+	index := make(map[string]int)
+	timelines := make([]Timeline, 0, len(entities))
+	key := func(kind models.MentionKind, id string) string { return string(kind) + "/" + id }
+	for _, e := range entities {
+		index[key(e.Kind, e.ID)] = len(timelines)
+		timelines = append(timelines, Timeline{Kind: e.Kind, EntityID: e.ID, Name: e.Name, Appearances: []Appearance{}})
+	}
+
+	sort.SliceStable(mentions, func(i, j int) bool { return mentions[i].ChapterNum < mentions[j].ChapterNum })
+	for _, m := range mentions {
+		i, ok := index[key(m.EntityKind, m.EntityID)]
+		if !ok {
+			// 实体已从设定中删除，仍保留历史记录
+			i = len(timelines)
+			index[key(m.EntityKind, m.EntityID)] = i
+			timelines = append(timelines, Timeline{Kind: m.EntityKind, EntityID: m.EntityID, Name: m.Name})
+		}
+		t := &timelines[i]
+		t.Appearances = append(t.Appearances, Appearance{Chapter: m.ChapterNum, Count: m.Count, Excerpt: m.Excerpt})
+		t.Mentions += m.Count
+		if t.FirstChapter == 0 {
+			t.FirstChapter = m.ChapterNum
+		}
+		t.LastChapter = m.ChapterNum
+	}
+
+	for i := range timelines {
+		if timelines[i].LastChapter > 0 {
+			timelines[i].Absent = latest - timelines[i].LastChapter
+		}
+	}
+	return timelines
+}
+
+// Absences 已出场但连续 gap 章以上没有再出现的实体，按缺席时长从长到短排列
+// 只出场过一次的地点和物品多为一笔带过，不提醒；角色不论出场几次都提醒
+func Absences(timelines []Timeline, gap int) []Absence {
+	if gap <= 0 {
+		gap = DefaultAbsenceGap
+	}
+	out := make([]Absence, 0)
+	for _, t := range timelines {
+		if t.LastChapter == 0 || t.Absent < gap {
+			continue
+		}
+		if t.Kind != models.MentionCharacter && len(t.Appearances) < 2 {
+			continue
+		}
+		out = append(out, Absence{
+			Kind:        t.Kind,
+			EntityID:    t.EntityID,
+			Name:        t.Name,
+			LastChapter: t.LastChapter,
+			Absent:      t.Absent,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Absent > out[j].Absent })
+	return out
+}
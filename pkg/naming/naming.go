@@ -0,0 +1,368 @@
+// Package naming 角色命名
+// 根据世界类型、风格和语言推导命名规范，并维护世界内的人名登记，避免重名和不合时代的名字
+package naming
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// Scheme 命名体系
+type Scheme string
+
+const (
+	SchemeClassical      Scheme = "classical"      // 古典汉名（武侠、仙侠、历史）
+	SchemeModern         Scheme = "modern"         // 现代汉名（都市、民国）
+	SchemeFuturistic     Scheme = "futuristic"     // 未来汉名（科幻）
+	SchemeTransliterated Scheme = "transliterated" // 音译名（西方奇幻）
+)
+
+// Convention 命名规范
+type Convention struct {
+	Scheme    Scheme   `json:"scheme"`
+	Label     string   `json:"label"`
+	Rules     []string `json:"rules"`     // 提供给LLM的命名要求
+	Languages []string `json:"languages"` // 世界语言说明
+	Avoid     []string `json:"avoid"`     // 不合时代的字眼
+
+	surnames  []string
+	male      []string
+	female    []string
+	syllables []string
+}
+
+// 占位名，不能作为角色名
+var placeholderNames = map[string]bool{
+	"主角": true, "配角": true, "反派": true, "导师": true, "盟友": true,
+	"未命名": true, "无名": true, "角色": true, "某人": true,
+}
+
+var classicalSurnames = []string{
+	"萧", "沈", "陆", "顾", "谢", "苏", "叶", "林", "楚", "秦", "柳", "温", "燕", "卫", "裴", "韩",
+	"慕容", "上官", "司马", "欧阳", "东方", "南宫", "独孤", "公孙",
+}
+
+var modernSurnames = []string{
+	"王", "李", "张", "刘", "陈", "杨", "赵", "黄", "周", "吴", "徐", "孙", "马", "朱", "胡", "郭",
+	"何", "高", "林", "罗", "郑", "梁", "宋", "唐", "许", "邓", "冯", "韩",
+}
+
+var classicalMale = []string{"长", "云", "寒", "玄", "渊", "默", "承", "怀", "景", "清", "昭", "墨", "衡", "岳", "泓", "疏"}
+var classicalFemale = []string{"若", "婉", "霜", "月", "清", "璃", "漪", "兰", "瑶", "素", "灵", "绾", "雪", "芷", "蘅", "音"}
+
+var modernMale = []string{"浩", "宇", "晨", "磊", "杰", "涛", "鹏", "俊", "凯", "博", "文", "强", "志", "明", "伟", "峰"}
+var modernFemale = []string{"婷", "悦", "欣", "琳", "雯", "佳", "静", "璐", "萌", "莉", "晓", "妍", "颖", "倩", "薇", "楠"}
+
+var futuristicMale = []string{"辰", "星", "弈", "川", "野", "航", "极", "澈", "远", "曜", "衍", "启"}
+var futuristicFemale = []string{"岚", "汐", "遥", "霁", "萤", "湾", "昙", "澄", "弦", "渺", "珂", "若"}
+
+var transliteratedSyllables = []string{
+	"艾", "伦", "卡", "洛", "斯", "塞", "琳", "娜", "维", "尔", "德", "里", "安", "莉", "雅", "索",
+	"菲", "奥", "米", "拉", "凯", "恩", "芙", "蕾", "希", "格", "温", "特",
+}
+
+// ConventionForWorld 由世界类型、风格和语言推导命名规范
+func ConventionForWorld(world *models.WorldSetting) Convention {
+	if world == nil {
+		return conventionFor(SchemeModern, nil)
+	}
+	return conventionFor(schemeFor(world), world.Civilization.Languages)
+}
+
+// schemeFor 世界对应的命名体系，风格关键词优先于世界类型
+func schemeFor(world *models.WorldSetting) Scheme {
+	hint := world.Style
+	for _, lang := range world.Civilization.Languages {
+		hint += " " + lang.Name + " " + strings.Join(lang.Features, " ")
+	}
+
+	switch {
+	case containsAny(world.Style, "民国", "近代", "现代", "都市"):
+		return SchemeModern
+	case containsAny(hint, "西幻", "西方", "魔法", "精灵", "骑士", "音译"):
+		return SchemeTransliterated
+	}
+
+	switch world.Type {
+	case models.WorldWuxia, models.WorldXianxia, models.WorldHistorical:
+		return SchemeClassical
+	case models.WorldUrban:
+		return SchemeModern
+	case models.WorldScifi:
+		return SchemeFuturistic
+	case models.WorldFantasy:
+		if containsAny(hint, "东方", "古风", "玄幻") {
+			return SchemeClassical
+		}
+		return SchemeTransliterated
+	}
+
+	if containsAny(hint, "古", "仙", "武", "侠", "修") {
+		return SchemeClassical
+	}
+	return SchemeModern
+}
+
+// conventionFor 构建命名规范
+func conventionFor(scheme Scheme, languages []models.Language) Convention {
+	c := Convention{Scheme: scheme}
+
+	switch scheme {
+	case SchemeClassical:
+		c.Label = "古典汉名"
+		c.Rules = []string{
+			"姓氏用常见古姓或复姓，名取一到二字，用字典雅，可带字号",
+			"不得使用现代常见名字用字（如浩、杰、婷、丽）和外文名",
+		}
+		c.Avoid = []string{"浩", "杰", "婷", "丽", "强", "伟", "军", "娟"}
+		c.surnames, c.male, c.female = classicalSurnames, classicalMale, classicalFemale
+	case SchemeFuturistic:
+		c.Label = "未来汉名"
+		c.Rules = []string{
+			"以汉名为主，用字简洁有未来感，可附编号或代号",
+			"避免古风字号和过于土气的名字",
+		}
+		c.surnames, c.male, c.female = modernSurnames, futuristicMale, futuristicFemale
+	case SchemeTransliterated:
+		c.Label = "音译名"
+		c.Rules = []string{
+			"使用西方奇幻风格的音译名，二到四个音节，可带家族名，用“·”分隔",
+			"不得使用汉族姓氏加名字的形式",
+		}
+		c.syllables = transliteratedSyllables
+	default:
+		c.Label = "现代汉名"
+		c.Rules = []string{
+			"使用符合现代或近代背景的常见汉名",
+			"避免武侠仙侠式的复姓和字号",
+		}
+		c.surnames, c.male, c.female = modernSurnames, modernMale, modernFemale
+	}
+
+	c.Languages = make([]string, 0, len(languages))
+	for _, lang := range languages {
+		if lang.Name == "" {
+			continue
+		}
+		note := lang.Name
+		if lang.Speakers != "" {
+			note += "（使用者：" + lang.Speakers + "）"
+		}
+		if len(lang.Features) > 0 {
+			note += "：" + strings.Join(lang.Features, "、")
+		}
+		c.Languages = append(c.Languages, note)
+	}
+	return c
+}
+
+// Fits 名字是否符合命名规范
+func (c Convention) Fits(name string) bool {
+	name = strings.TrimSpace(name)
+	if name == "" || placeholderNames[name] || strings.ContainsFunc(name, unicode.IsDigit) {
+		return false
+	}
+
+	switch c.Scheme {
+	case SchemeTransliterated:
+		// 汉族姓氏开头的二三字名视为汉名
+		return strings.Contains(name, "·") || utf8.RuneCountInString(name) > 3 || !hasChineseSurname(name)
+	case SchemeClassical:
+		n := utf8.RuneCountInString(name)
+		if n < 2 || n > 4 || !isHan(name) {
+			return false
+		}
+		for _, avoid := range c.Avoid {
+			if strings.Contains(name, avoid) {
+				return false
+			}
+		}
+		return true
+	default:
+		return !strings.Contains(name, "·")
+	}
+}
+
+// Generator 人名生成器，持有世界的人名登记
+type Generator struct {
+	world      *models.WorldSetting
+	convention Convention
+	taken      map[string]bool
+	rng        *rand.Rand
+}
+
+// NewGenerator 创建生成器，已登记的人名从世界设定加载
+func NewGenerator(world *models.WorldSetting) *Generator {
+	g := &Generator{
+		world:      world,
+		convention: ConventionForWorld(world),
+		taken:      make(map[string]bool),
+		rng:        rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())),
+	}
+	if world != nil {
+		for _, name := range world.NameRegistry {
+			g.taken[name] = true
+		}
+	}
+	return g
+}
+
+// Convention 当前世界的命名规范
+func (g *Generator) Convention() Convention {
+	return g.convention
+}
+
+// Taken 名字是否已被使用
+func (g *Generator) Taken(name string) bool {
+	return g.taken[strings.TrimSpace(name)]
+}
+
+// Register 登记名字，名字为空或已被使用时返回false
+func (g *Generator) Register(name string) bool {
+	name = strings.TrimSpace(name)
+	if name == "" || g.taken[name] {
+		return false
+	}
+	g.taken[name] = true
+	if g.world != nil {
+		g.world.NameRegistry = append(g.world.NameRegistry, name)
+	}
+	return true
+}
+
+// Generate 生成一个未被使用的名字并登记，gender 为 男/女/male/female，其他值随机
+func (g *Generator) Generate(gender string) string {
+	for i := 0; i < 50; i++ {
+		name := g.compose(gender)
+		if g.Register(name) {
+			return name
+		}
+	}
+	// 组合空间耗尽时加序号区分
+	base := g.compose(gender)
+	for i := 2; ; i++ {
+		name := fmt.Sprintf("%s%s", base, chineseOrdinal(i))
+		if g.Register(name) {
+			return name
+		}
+	}
+}
+
+// Ensure 校验LLM给出的名字：符合规范且未被使用则登记后原样返回，否则生成替代名字
+func (g *Generator) Ensure(name, gender string) string {
+	name = strings.TrimSpace(name)
+	if g.convention.Fits(name) && g.Register(name) {
+		return name
+	}
+	return g.Generate(gender)
+}
+
+// Names 已登记的名字，按字典序排序
+func (g *Generator) Names() []string {
+	names := make([]string, 0, len(g.taken))
+	for name := range g.taken {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// PromptSection 命名要求提示词片段，包含已被使用的名字
+func (g *Generator) PromptSection() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("\n## 命名规范（%s）\n", g.convention.Label))
+	for _, rule := range g.convention.Rules {
+		sb.WriteString("- " + rule + "\n")
+	}
+	if len(g.convention.Languages) > 0 {
+		sb.WriteString("- 名字的音韵应符合世界语言：" + strings.Join(g.convention.Languages, "；") + "\n")
+	}
+	sb.WriteString("- 不要使用“主角”“反派”等占位名\n")
+	if names := g.Names(); len(names) > 0 {
+		sb.WriteString("- 以下名字已被使用，不得重复：" + strings.Join(names, "、") + "\n")
+	}
+	return sb.String()
+}
+
+// compose 按命名规范组合一个名字
+func (g *Generator) compose(gender string) string {
+	c := g.convention
+	if c.Scheme == SchemeTransliterated {
+		given := g.syllables(2 + g.rng.IntN(2))
+		if g.rng.IntN(2) == 0 {
+			return given
+		}
+		return given + "·" + g.syllables(2+g.rng.IntN(2))
+	}
+
+	pool := c.male
+	switch strings.ToLower(gender) {
+	case "女", "female", "f":
+		pool = c.female
+	case "男", "male", "m":
+	default:
+		if g.rng.IntN(2) == 0 {
+			pool = c.female
+		}
+	}
+
+	name := c.surnames[g.rng.IntN(len(c.surnames))] + pool[g.rng.IntN(len(pool))]
+	if g.rng.IntN(3) > 0 {
+		name += pool[g.rng.IntN(len(pool))]
+	}
+	return name
+}
+
+// syllables 随机拼接音译音节
+func (g *Generator) syllables(n int) string {
+	var sb strings.Builder
+	for i := 0; i < n; i++ {
+		sb.WriteString(g.convention.syllables[g.rng.IntN(len(g.convention.syllables))])
+	}
+	return sb.String()
+}
+
+// hasChineseSurname 是否以常见汉族姓氏开头
+func hasChineseSurname(name string) bool {
+	for _, surname := range append(classicalSurnames, modernSurnames...) {
+		if strings.HasPrefix(name, surname) {
+			return true
+		}
+	}
+	return false
+}
+
+// isHan 是否全部为汉字
+func isHan(s string) bool {
+	for _, r := range s {
+		if !unicode.Is(unicode.Han, r) {
+			return false
+		}
+	}
+	return true
+}
+
+// containsAny 是否包含任一关键词
+func containsAny(s string, keywords ...string) bool {
+	for _, kw := range keywords {
+		if strings.Contains(s, kw) {
+			return true
+		}
+	}
+	return false
+}
+
+// chineseOrdinal 重名区分用的排行字
+func chineseOrdinal(n int) string {
+	ordinals := []string{"", "", "二", "三", "四", "五", "六", "七", "八", "九"}
+	if n < len(ordinals) {
+		return ordinals[n]
+	}
+	return fmt.Sprint(n)
+}
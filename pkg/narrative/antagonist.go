@@ -0,0 +1,216 @@
+// Package narrative 叙事器 - 反派弧线规划
+// 在关键事件确定后为反派规划与主线平行的暗线：反派自己的行动、筹码得失、与主角的擦肩交锋，让最终对决有迹可循
+package narrative
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// AntagonistMove 反派暗线中的一步行动
+type AntagonistMove struct {
+	Sequence   int      `json:"sequence"`
+	Action     string   `json:"action"`              // 反派做了什么
+	Gains      []string `json:"gains"`               // 获得的资源、盟友、筹码
+	Losses     []string `json:"losses"`              // 失去的资源、盟友、筹码
+	ParallelTo string   `json:"parallel_to"`         // 同期主线关键事件ID
+	NearMiss   string   `json:"near_miss,omitempty"` // 与主角的擦肩或间接交锋，为空表示双方互不知情
+	Location   string   `json:"location,omitempty"`
+}
+
+// AntagonistArc 反派弧线：反派视角下的平行战役
+type AntagonistArc struct {
+	CharacterID   string           `json:"character_id"`
+	Name          string           `json:"name"`
+	Goal          string           `json:"goal"`          // 反派的终极目标
+	Motivation    string           `json:"motivation"`    // 反派认为自己正当的理由
+	Campaign      []AntagonistMove `json:"campaign"`      // 按时间顺序的行动
+	Confrontation string           `json:"confrontation"` // 走到最终对决时反派的处境，以及为何必须正面交锋
+}
+
+// PlanAntagonistArcs 为反派规划平行暗线（1轮LLM）
+// 需要在关键事件设计之后、高潮设计之前调用；没有反派角色时返回空
+func (o *Orchestrator) PlanAntagonistArcs(state *EvolutionState, events []KeyEvent) ([]AntagonistArc, error) {
+	antagonists := antagonistCharacters(state)
+	if len(antagonists) == 0 {
+		return nil, nil
+	}
+
+	state.CurrentRound++
+	prompt := o.buildAntagonistArcPrompt(state, antagonists, events)
+	systemPrompt := o.buildSystemPrompt("antagonist_strategist")
+
+	response, err := o.engine.callWithRetry(prompt, systemPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("反派弧线规划失败: %w", err)
+	}
+
+	var result struct {
+		Arcs []AntagonistArc `json:"arcs"`
+	}
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return nil, fmt.Errorf("解析反派弧线结果失败: %w", err)
+	}
+
+	eventIDs := make(map[string]bool, len(events))
+	for _, e := range events {
+		eventIDs[e.ID] = true
+	}
+
+	arcs := make([]AntagonistArc, 0, len(result.Arcs))
+	nearMisses := 0
+	for _, arc := range result.Arcs {
+		char := findAntagonist(antagonists, arc.CharacterID, arc.Name)
+		if char == nil {
+			continue
+		}
+		arc.CharacterID = char.ID
+		arc.Name = char.Name
+		for i := range arc.Campaign {
+			arc.Campaign[i].Sequence = i + 1
+			if !eventIDs[arc.Campaign[i].ParallelTo] {
+				arc.Campaign[i].ParallelTo = ""
+			}
+			if arc.Campaign[i].NearMiss != "" {
+				nearMisses++
+			}
+		}
+		arcs = append(arcs, arc)
+	}
+
+	details := make([]string, 0, len(arcs)+1)
+	for _, arc := range arcs {
+		details = append(details, fmt.Sprintf("%s: %s（%d步）", arc.Name, arc.Goal, len(arc.Campaign)))
+	}
+	details = append(details, fmt.Sprintf("擦肩交锋: %d", nearMisses))
+	state.logAction(state.CurrentRound, "antagonist_arc", "反派弧线规划", details)
+
+	return arcs, nil
+}
+
+// antagonistCharacters 演化状态中的反派角色
+func antagonistCharacters(state *EvolutionState) []*CharacterState {
+	result := make([]*CharacterState, 0)
+	for _, char := range sortedCharacters(state.Characters) {
+		if char.Role == "反派" || char.Role == "antagonist" {
+			result = append(result, char)
+		}
+	}
+	return result
+}
+
+// findAntagonist 按ID或姓名匹配反派
+func findAntagonist(antagonists []*CharacterState, id, name string) *CharacterState {
+	for _, char := range antagonists {
+		if char.ID == id || char.Name == name {
+			return char
+		}
+	}
+	return nil
+}
+
+// buildAntagonistArcPrompt 构建反派弧线提示词
+func (o *Orchestrator) buildAntagonistArcPrompt(state *EvolutionState, antagonists []*CharacterState, events []KeyEvent) string {
+	var sb strings.Builder
+	sb.WriteString("规划反派的平行暗线：\n\n")
+
+	sb.WriteString("反派：\n")
+	for _, char := range antagonists {
+		sb.WriteString(fmt.Sprintf("- %s（ID: %s）", char.Name, char.ID))
+		if char.Desires.ConsciousWant != "" {
+			sb.WriteString(fmt.Sprintf("，想要：%s", char.Desires.ConsciousWant))
+		}
+		if char.Desires.Fear != "" {
+			sb.WriteString(fmt.Sprintf("，恐惧：%s", char.Desires.Fear))
+		}
+		if len(char.Secrets) > 0 {
+			sb.WriteString(fmt.Sprintf("，秘密：%s", strings.Join(char.Secrets, "；")))
+		}
+		sb.WriteString("\n")
+	}
+
+	for _, char := range sortedCharacters(state.Characters) {
+		if char.Role == "主角" || char.Role == "protagonist" {
+			sb.WriteString(fmt.Sprintf("\n主角：%s，想要：%s\n", char.Name, char.Desires.ConsciousWant))
+		}
+	}
+
+	if len(state.Conflicts) > 0 {
+		sb.WriteString("\n核心冲突：\n")
+		for _, c := range state.Conflicts {
+			sb.WriteString(fmt.Sprintf("- %s\n", c.CoreQuestion))
+		}
+	}
+
+	sb.WriteString("\n主线关键事件：\n")
+	for _, e := range events {
+		sb.WriteString(fmt.Sprintf("- %s %s: %s\n", e.ID, e.Name, e.Description))
+	}
+
+	sb.WriteString(`
+要求：
+1. 反派不是等主角上门的靶子，他有自己的目标和时间表，每一步行动都推进他的计划
+2. 每一步写清反派获得和失去了什么（资源、盟友、情报、地盘），筹码此消彼长，不能一路顺风
+3. parallel_to 填同期的主线事件ID，让读者能在主线中看到暗线的投影
+4. 安排2-3次与主角的擦肩或间接交锋（near_miss）：抢先一步、错过、通过手下交手、留下痕迹，但不提前正面决战
+5. confrontation 写明走到高潮时反派手里握着什么、缺什么，以及为什么此时双方必须正面交锋
+
+请以JSON格式返回：
+{
+  "arcs": [
+    {
+      "character_id": "反派ID",
+      "name": "反派姓名",
+      "goal": "终极目标",
+      "motivation": "反派认为自己正当的理由",
+      "campaign": [
+        {
+          "action": "反派的行动",
+          "gains": ["获得的筹码"],
+          "losses": ["失去的筹码"],
+          "parallel_to": "event_1",
+          "near_miss": "与主角的擦肩交锋（没有则留空）",
+          "location": "地点"
+        }
+      ],
+      "confrontation": "最终对决前反派的处境"
+    }
+  ]
+}
+只返回JSON，不要包含其他内容。`)
+	return sb.String()
+}
+
+// buildAntagonistArcSection 反派暗线摘要，供高潮设计和章节分配使用
+func buildAntagonistArcSection(arcs []AntagonistArc) string {
+	if len(arcs) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n反派暗线：\n")
+	for _, arc := range arcs {
+		sb.WriteString(fmt.Sprintf("%s（目标：%s）\n", arc.Name, arc.Goal))
+		for _, move := range arc.Campaign {
+			line := fmt.Sprintf("  %d. %s", move.Sequence, move.Action)
+			if move.ParallelTo != "" {
+				line += fmt.Sprintf("（同期: %s）", move.ParallelTo)
+			}
+			if len(move.Gains) > 0 {
+				line += "；得: " + strings.Join(move.Gains, "、")
+			}
+			if len(move.Losses) > 0 {
+				line += "；失: " + strings.Join(move.Losses, "、")
+			}
+			if move.NearMiss != "" {
+				line += "；擦肩: " + move.NearMiss
+			}
+			sb.WriteString(line + "\n")
+		}
+		if arc.Confrontation != "" {
+			sb.WriteString(fmt.Sprintf("  对决前处境: %s\n", arc.Confrontation))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,54 @@
+package narrative
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// LoadBeatSheet 加载自定义节拍表
+func LoadBeatSheet(database db.Database, id string) (*models.BeatSheet, error) {
+	tmpl, err := database.GetNarrativeTemplate(id)
+	if err != nil || tmpl == nil {
+		return nil, fmt.Errorf("节拍表不存在: %s", id)
+	}
+	return tmpl.BeatSheet()
+}
+
+// MapBeatSheet 把大纲的关键事件映射到自定义节拍上
+// 节拍按位置落到对应章节，关键事件按故事顺序等比例分配给位置最接近的节拍
+func MapBeatSheet(outline models.StoryOutline, sheet *models.BeatSheet, chapterCount int) []models.OutlineBeat {
+	events := outline.KeyEvents()
+	beats := make([]models.OutlineBeat, 0, len(sheet.Beats))
+	for _, b := range sheet.Beats {
+		ratio := b.Position / 100
+		beat := models.OutlineBeat{
+			Name:        b.Name,
+			Position:    b.Position,
+			Description: b.Description,
+			Chapter:     beatChapter(ratio, chapterCount),
+		}
+		if len(events) > 0 {
+			beat.Event = events[int(math.Round(ratio*float64(len(events)-1)))]
+		}
+		beats = append(beats, beat)
+	}
+	return beats
+}
+
+// beatChapter 全书位置 ratio（0-1）对应的章节，至少为第1章
+func beatChapter(ratio float64, chapterCount int) int {
+	if chapterCount <= 0 {
+		return 0
+	}
+	chapter := int(math.Ceil(ratio * float64(chapterCount)))
+	if chapter < 1 {
+		chapter = 1
+	}
+	if chapter > chapterCount {
+		chapter = chapterCount
+	}
+	return chapter
+}
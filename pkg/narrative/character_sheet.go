@@ -0,0 +1,178 @@
+// Package narrative 叙事器 - 角色卡
+// 演化结束后角色状态落库为可编辑的角色卡；用户锁定的字段在重新演化时原样保留
+package narrative
+
+import (
+	"sort"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// lockCharacter 记录角色锁定字段的用户设定，之后每轮演化结束时恢复
+func (s *EvolutionState) lockCharacter(char *CharacterState) {
+	if len(char.Locked) == 0 {
+		return
+	}
+	if s.LockedCharacters == nil {
+		s.LockedCharacters = make(map[string]*CharacterState)
+	}
+	snapshot := *char
+	snapshot.Desires.MaskingBehavior = append([]string(nil), char.Desires.MaskingBehavior...)
+	snapshot.Secrets = append([]string(nil), char.Secrets...)
+	snapshot.InternalConflicts = append([]string(nil), char.InternalConflicts...)
+	s.LockedCharacters[char.ID] = &snapshot
+}
+
+// enforceLocks 把锁定字段恢复为用户设定，覆盖演化轮次对这些字段的改写
+func (s *EvolutionState) enforceLocks() {
+	for id, locked := range s.LockedCharacters {
+		char, ok := s.Characters[id]
+		if !ok || char == nil {
+			continue
+		}
+		for _, field := range locked.Locked {
+			switch field {
+			case models.SheetFieldRole:
+				char.Role = locked.Role
+			case models.SheetFieldConsciousWant:
+				char.Desires.ConsciousWant = locked.Desires.ConsciousWant
+			case models.SheetFieldUnconsciousNeed:
+				char.Desires.UnconsciousNeed = locked.Desires.UnconsciousNeed
+			case models.SheetFieldFear:
+				char.Desires.Fear = locked.Desires.Fear
+			case models.SheetFieldMaskingBehavior:
+				char.Desires.MaskingBehavior = append([]string(nil), locked.Desires.MaskingBehavior...)
+			case models.SheetFieldSecrets:
+				char.Secrets = append([]string(nil), locked.Secrets...)
+			case models.SheetFieldInternalConflicts:
+				char.InternalConflicts = append([]string(nil), locked.InternalConflicts...)
+			case models.SheetFieldCurrentEmotion:
+				char.EmotionalState.CurrentEmotion = locked.EmotionalState.CurrentEmotion
+			}
+		}
+		char.Locked = locked.Locked
+	}
+}
+
+// UserCharacterFromSheet 把角色卡转换为预设角色，沿用演化中的角色ID和锁定字段
+func UserCharacterFromSheet(sheet *models.CharacterSheet) *UserCharacter {
+	uc := &UserCharacter{}
+	uc.ID = sheet.CharacterID
+	uc.Name = sheet.Name
+	uc.Role = sheet.Role
+	uc.Desires = DesireSystem{
+		ConsciousWant:   sheet.ConsciousWant,
+		UnconsciousNeed: sheet.UnconsciousNeed,
+		Fear:            sheet.Fear,
+		MaskingBehavior: append([]string(nil), sheet.MaskingBehavior...),
+	}
+	uc.EmotionalState.CurrentEmotion = sheet.CurrentEmotion
+	uc.ArcProgress = sheet.ArcProgress
+	uc.Secrets = append([]string(nil), sheet.Secrets...)
+	uc.InternalConflicts = append([]string(nil), sheet.InternalConflicts...)
+	uc.Locked = append([]string(nil), sheet.Locked...)
+	return uc
+}
+
+// CharacterSheetsFromState 演化状态中的角色转换为角色卡（按角色ID排序，尚未分配角色卡ID）
+func CharacterSheetsFromState(state *EvolutionState, worldID, blueprintID string) []*models.CharacterSheet {
+	ids := make([]string, 0, len(state.Characters))
+	for id, char := range state.Characters {
+		if char != nil && char.Name != "" {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	sheets := make([]*models.CharacterSheet, 0, len(ids))
+	for _, id := range ids {
+		char := state.Characters[id]
+		sheets = append(sheets, &models.CharacterSheet{
+			WorldID:           worldID,
+			BlueprintID:       blueprintID,
+			CharacterID:       char.ID,
+			Name:              char.Name,
+			Role:              char.Role,
+			ConsciousWant:     char.Desires.ConsciousWant,
+			UnconsciousNeed:   char.Desires.UnconsciousNeed,
+			Fear:              char.Desires.Fear,
+			MaskingBehavior:   char.Desires.MaskingBehavior,
+			Secrets:           char.Secrets,
+			InternalConflicts: char.InternalConflicts,
+			CurrentEmotion:    char.EmotionalState.CurrentEmotion,
+			ArcProgress:       char.ArcProgress,
+			Locked:            char.Locked,
+		})
+	}
+	return sheets
+}
+
+// lockedSheetCharacters 世界中带锁定字段的角色卡，作为预设角色参与下一次演化
+// 与调用方预设角色同名或同ID的角色卡跳过，以调用方为准
+func (ne *NarrativeEngine) lockedSheetCharacters(worldID string, preset []*UserCharacter) []*UserCharacter {
+	sheets, err := ne.db.ListCharacterSheets(worldID)
+	if err != nil {
+		ne.log().Debug("读取角色卡失败，不注入锁定角色", "world_id", worldID, "error", err)
+		return nil
+	}
+
+	taken := make(map[string]bool, len(preset)*2)
+	for _, uc := range preset {
+		if uc != nil {
+			taken[uc.Name] = true
+			if uc.ID != "" {
+				taken[uc.ID] = true
+			}
+		}
+	}
+
+	chars := make([]*UserCharacter, 0)
+	for i := range sheets {
+		sheet := &sheets[i]
+		if len(sheet.Locked) == 0 || taken[sheet.Name] || (sheet.CharacterID != "" && taken[sheet.CharacterID]) {
+			continue
+		}
+		chars = append(chars, UserCharacterFromSheet(sheet))
+	}
+	return chars
+}
+
+// saveCharacterSheets 把演化后的角色写入角色卡：按角色ID或姓名匹配已有角色卡，只更新未锁定的字段
+// 保存失败只记录日志，不影响蓝图
+func (ne *NarrativeEngine) saveCharacterSheets(state *EvolutionState, blueprint *models.NarrativeBlueprint) {
+	existing, err := ne.db.ListCharacterSheets(blueprint.WorldID)
+	if err != nil {
+		ne.log().Debug("读取角色卡失败，跳过保存", "world_id", blueprint.WorldID, "error", err)
+		return
+	}
+	byCharacter := make(map[string]*models.CharacterSheet, len(existing))
+	byName := make(map[string]*models.CharacterSheet, len(existing))
+	for i := range existing {
+		sheet := &existing[i]
+		if sheet.CharacterID != "" {
+			byCharacter[sheet.CharacterID] = sheet
+		}
+		byName[sheet.Name] = sheet
+	}
+
+	saved := 0
+	for _, generated := range CharacterSheetsFromState(state, blueprint.WorldID, blueprint.ID) {
+		sheet, ok := byCharacter[generated.CharacterID]
+		if !ok {
+			sheet, ok = byName[generated.Name]
+		}
+		if ok {
+			sheet.MergeGenerated(generated)
+		} else {
+			sheet = generated
+			sheet.ID = db.GenerateID("sheet")
+		}
+		if err := ne.db.SaveCharacterSheet(sheet); err != nil {
+			ne.log().Warn("保存角色卡失败", "character", sheet.Name, "error", err)
+			continue
+		}
+		saved++
+	}
+	ne.log().Info("角色卡已更新", "world_id", blueprint.WorldID, "sheets", saved)
+}
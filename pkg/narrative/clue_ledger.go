@@ -0,0 +1,280 @@
+// Package narrative 叙事器 - 悬疑线索台账
+// 与伏笔计划并行，记录线索、红鲱鱼、谁在何时发现，并做公平推理校验
+package narrative
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ClueKind 线索类型
+type ClueKind string
+
+const (
+	ClueKindClue       ClueKind = "clue"        // 指向真相的线索
+	ClueKindRedHerring ClueKind = "red_herring" // 误导读者的红鲱鱼
+)
+
+// Clue 线索台账条目
+type Clue struct {
+	ID       string   `json:"id"`
+	Kind     ClueKind `json:"kind"`
+	Content  string   `json:"content"`   // 线索内容
+	PointsTo string   `json:"points_to"` // 指向的结论（红鲱鱼为错误结论）
+
+	// 呈现信息：线索在哪一章、以什么方式出现在读者面前
+	PlantChapter  int    `json:"plant_chapter"`
+	PlantScene    int    `json:"plant_scene"`
+	PlantMethod   string `json:"plant_method"`
+	ReaderVisible bool   `json:"reader_visible"` // 是否在正文中呈现给读者（而非侦探私下掌握）
+
+	// 发现信息：哪个角色在哪一章注意到这条线索
+	DiscoveredBy    string `json:"discovered_by"`
+	DiscoverChapter int    `json:"discover_chapter"`
+
+	// 红鲱鱼在哪一章被排除
+	DebunkChapter int `json:"debunk_chapter,omitempty"`
+}
+
+// ClueReveal 真相揭晓
+type ClueReveal struct {
+	ID            string   `json:"id"`
+	Chapter       int      `json:"chapter"`        // 揭晓章节
+	Truth         string   `json:"truth"`          // 揭晓的真相
+	RequiredClues []string `json:"required_clues"` // 推出真相所需的线索ID
+}
+
+// ClueLedger 线索台账
+type ClueLedger struct {
+	Truth   string        `json:"truth"` // 故事的核心真相（谁、怎么做、为什么）
+	Clues   []*Clue       `json:"clues"`
+	Reveals []*ClueReveal `json:"reveals"`
+}
+
+// FairPlayViolation 公平推理违规项
+type FairPlayViolation struct {
+	RevealID string `json:"reveal_id,omitempty"`
+	ClueID   string `json:"clue_id"`
+	Reason   string `json:"reason"`
+}
+
+// FairPlayReport 公平推理校验报告
+type FairPlayReport struct {
+	Fair       bool                `json:"fair"`
+	Violations []FairPlayViolation `json:"violations"`
+	Warnings   []string            `json:"warnings"`
+}
+
+// Clue 按ID查找线索
+func (l *ClueLedger) Clue(id string) *Clue {
+	for _, clue := range l.Clues {
+		if clue.ID == id {
+			return clue
+		}
+	}
+	return nil
+}
+
+// ValidateFairPlay 公平推理校验：揭晓所需的每条线索都必须在揭晓章节之前呈现给读者
+func (l *ClueLedger) ValidateFairPlay() *FairPlayReport {
+	report := &FairPlayReport{
+		Violations: make([]FairPlayViolation, 0),
+		Warnings:   make([]string, 0),
+	}
+
+	lastReveal := 0
+	for _, reveal := range l.Reveals {
+		if reveal.Chapter > lastReveal {
+			lastReveal = reveal.Chapter
+		}
+		if len(reveal.RequiredClues) == 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("揭晓%s没有关联任何线索，读者无从推理", reveal.ID))
+		}
+
+		for _, clueID := range reveal.RequiredClues {
+			violation := FairPlayViolation{RevealID: reveal.ID, ClueID: clueID}
+			clue := l.Clue(clueID)
+			switch {
+			case clue == nil:
+				violation.Reason = "线索不存在于台账中"
+			case clue.Kind == ClueKindRedHerring:
+				violation.Reason = "真相依赖红鲱鱼，红鲱鱼不能作为推理依据"
+			case clue.PlantChapter <= 0:
+				violation.Reason = "线索未安排呈现章节"
+			case clue.PlantChapter >= reveal.Chapter:
+				violation.Reason = fmt.Sprintf("线索在第%d章才出现，未早于第%d章的揭晓", clue.PlantChapter, reveal.Chapter)
+			case !clue.ReaderVisible:
+				violation.Reason = fmt.Sprintf("线索只有%s掌握，没有在正文中呈现给读者", clue.DiscoveredBy)
+			default:
+				continue
+			}
+			report.Violations = append(report.Violations, violation)
+		}
+	}
+
+	for _, clue := range l.Clues {
+		if clue.Kind != ClueKindRedHerring {
+			continue
+		}
+		if clue.DebunkChapter == 0 {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("红鲱鱼%s始终没有被排除", clue.ID))
+		} else if lastReveal > 0 && clue.DebunkChapter > lastReveal {
+			report.Warnings = append(report.Warnings, fmt.Sprintf("红鲱鱼%s在最终揭晓之后才被排除", clue.ID))
+		}
+	}
+
+	report.Fair = len(report.Violations) == 0
+	return report
+}
+
+// RepairFairPlay 修正可自动修正的违规：过晚出现的线索提前到揭晓前一章，未呈现的线索改为正文呈现
+// 返回修正说明；不存在的线索和依赖红鲱鱼的揭晓需要重新规划，不做处理
+func (l *ClueLedger) RepairFairPlay(report *FairPlayReport) []string {
+	fixes := make([]string, 0)
+	for _, v := range report.Violations {
+		clue := l.Clue(v.ClueID)
+		if clue == nil || clue.Kind == ClueKindRedHerring {
+			continue
+		}
+
+		var revealChapter int
+		for _, reveal := range l.Reveals {
+			if reveal.ID == v.RevealID {
+				revealChapter = reveal.Chapter
+			}
+		}
+
+		if (clue.PlantChapter <= 0 || clue.PlantChapter >= revealChapter) && revealChapter > 1 {
+			clue.PlantChapter = revealChapter - 1
+			if clue.DiscoverChapter > clue.PlantChapter {
+				clue.DiscoverChapter = clue.PlantChapter
+			}
+			fixes = append(fixes, fmt.Sprintf("线索%s提前到第%d章", clue.ID, clue.PlantChapter))
+		}
+		if !clue.ReaderVisible {
+			clue.ReaderVisible = true
+			fixes = append(fixes, fmt.Sprintf("线索%s改为在正文中呈现", clue.ID))
+		}
+	}
+	return fixes
+}
+
+// CluesInChapter 本章呈现的线索ID（含红鲱鱼），按ID排序
+func (l *ClueLedger) CluesInChapter(chapter int) []string {
+	ids := make([]string, 0)
+	for _, clue := range l.Clues {
+		if clue.PlantChapter == chapter {
+			ids = append(ids, clue.ID)
+		}
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// planClueLedger 规划线索台账并做公平推理校验（悬疑类型，阶段3与伏笔并行）
+func (o *Orchestrator) planClueLedger(state *EvolutionState) (*ClueLedger, error) {
+	state.CurrentRound++
+
+	prompt := o.buildClueLedgerPrompt(state)
+	systemPrompt := o.buildSystemPrompt("clue_architect")
+
+	response, err := o.engine.callWithRetry(prompt, systemPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("线索规划失败: %w", err)
+	}
+
+	ledger := &ClueLedger{}
+	if err := json.Unmarshal([]byte(response), ledger); err != nil {
+		return nil, fmt.Errorf("解析线索规划结果失败: %w", err)
+	}
+	for i, clue := range ledger.Clues {
+		if clue.ID == "" {
+			clue.ID = fmt.Sprintf("clue_%d", i+1)
+		}
+		if clue.Kind == "" {
+			clue.Kind = ClueKindClue
+		}
+	}
+
+	report := ledger.ValidateFairPlay()
+	changes := []string{
+		fmt.Sprintf("线索数: %d", len(ledger.Clues)),
+		fmt.Sprintf("公平推理: %v", report.Fair),
+	}
+	if !report.Fair {
+		changes = append(changes, ledger.RepairFairPlay(report)...)
+		report = ledger.ValidateFairPlay()
+		for _, v := range report.Violations {
+			changes = append(changes, fmt.Sprintf("未解决: %s %s", v.ClueID, v.Reason))
+		}
+	}
+	changes = append(changes, report.Warnings...)
+
+	state.logAction(state.CurrentRound, "clue_planning", "线索台账规划", changes)
+
+	return ledger, nil
+}
+
+// buildClueLedgerPrompt 构建线索台账规划提示词
+func (o *Orchestrator) buildClueLedgerPrompt(state *EvolutionState) string {
+	totalChapters := 12
+	if state.ChapterPlan != nil && state.ChapterPlan.TotalChapters > 0 {
+		totalChapters = state.ChapterPlan.TotalChapters
+	}
+
+	names := make([]string, 0, len(state.Characters))
+	for _, char := range state.Characters {
+		names = append(names, fmt.Sprintf("%s(%s)", char.Name, char.Role))
+	}
+
+	return fmt.Sprintf(`为以下悬疑故事规划线索台账：
+
+核心问题：%s
+主要冲突：%s
+角色：%s
+预计章节数：%d
+
+要求（公平推理原则）：
+1. 先确定真相：谁、用什么方法、出于什么动机
+2. 设计6-10条真实线索和2-4条红鲱鱼
+3. 揭晓真相所需的每条线索，都必须在揭晓章节之前的正文中呈现给读者（reader_visible=true），不能只让侦探私下掌握
+4. 标明每条线索由哪个角色在哪一章发现
+5. 每条红鲱鱼都要在最终揭晓之前被排除（debunk_chapter）
+6. 揭晓依赖的线索只能是真实线索，不能是红鲱鱼
+
+请以JSON格式返回：
+{
+  "truth": "真相",
+  "clues": [
+    {
+      "id": "clue_1",
+      "kind": "clue",
+      "content": "线索内容",
+      "points_to": "指向的结论",
+      "plant_chapter": 2,
+      "plant_scene": 1,
+      "plant_method": "如何呈现给读者",
+      "reader_visible": true,
+      "discovered_by": "角色名",
+      "discover_chapter": 2,
+      "debunk_chapter": 0
+    }
+  ],
+  "reveals": [
+    {
+      "id": "reveal_1",
+      "chapter": %d,
+      "truth": "揭晓的真相",
+      "required_clues": ["clue_1"]
+    }
+  ]
+}
+只返回JSON，不要包含其他内容。`,
+		state.WorldContext.Philosophy.CoreQuestion,
+		state.StoryArchitecture.CoreConflictType,
+		strings.Join(names, "、"),
+		totalChapters,
+		totalChapters)
+}
@@ -0,0 +1,243 @@
+package narrative
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/pacing"
+)
+
+// 竞品节拍名称，提取时要求 LLM 使用这些名称，便于和蓝图对齐
+const (
+	BeatOpeningHook      = "开篇钩子"
+	BeatIncitingIncident = "激励事件"
+	BeatMidpoint         = "中点"
+	BeatAllIsLost        = "至暗时刻"
+	BeatClimax           = "高潮"
+)
+
+// maxCompetitorSourceRunes 竞品原文最多送入提示词的字数
+const maxCompetitorSourceRunes = 12000
+
+// competitorComparisonOutput 对比步骤的 LLM 输出
+type competitorComparisonOutput struct {
+	OwnTropes     []string                            `json:"own_tropes"`
+	PacingNote    string                              `json:"pacing_note"`
+	Opportunities []models.DifferentiationOpportunity `json:"opportunities"`
+}
+
+// AnalyzeCompetitor 从竞品的简介或章节目录中提取结构：节拍、套路和节奏
+func (ne *NarrativeEngine) AnalyzeCompetitor(title, source string) (*models.CompetitorStructure, error) {
+	if strings.TrimSpace(source) == "" {
+		return nil, fmt.Errorf("竞品内容为空")
+	}
+
+	ne.log().Info("提取竞品结构", "title", title, "runes", len([]rune(source)))
+	result, err := ne.callWithRetry(buildCompetitorStructurePrompt(title, source),
+		`你是一位熟悉网络文学市场的资深编辑，擅长拆解作品的结构、套路和节奏。只根据给出的材料分析，不编造材料中没有的情节。`)
+	if err != nil {
+		return nil, fmt.Errorf("提取竞品结构失败: %w", err)
+	}
+
+	var structure models.CompetitorStructure
+	if err := jsonx.Unmarshal(result, &structure); err != nil {
+		return nil, fmt.Errorf("解析竞品结构失败: %w", err)
+	}
+	for i := range structure.Beats {
+		b := &structure.Beats[i]
+		// 只给了章节号时按章节数换算相对位置
+		if b.Position <= 0 && b.Chapter > 0 && structure.ChapterCount > 0 {
+			b.Position = float64(b.Chapter) / float64(structure.ChapterCount)
+		}
+		b.Position = math.Round(math.Max(0, math.Min(1, b.Position))*100) / 100
+	}
+	sort.SliceStable(structure.Beats, func(i, j int) bool { return structure.Beats[i].Position < structure.Beats[j].Position })
+	return &structure, nil
+}
+
+// CompareWithBlueprint 对比竞品结构和项目蓝图：套路异同和节拍位置由结构计算，差异化机会由 LLM 给出
+func (ne *NarrativeEngine) CompareWithBlueprint(structure *models.CompetitorStructure, blueprint *models.NarrativeBlueprint) (*models.CompetitorComparison, error) {
+	result, err := ne.callWithRetry(buildCompetitorComparisonPrompt(structure, blueprint),
+		`你是一位网络文学策划编辑，帮助作者找到与竞品的差异化定位。建议要具体到可以直接改进大纲。`)
+	if err != nil {
+		return nil, fmt.Errorf("竞品对比失败: %w", err)
+	}
+
+	var output competitorComparisonOutput
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, fmt.Errorf("解析竞品对比结果失败: %w", err)
+	}
+
+	comparison := &models.CompetitorComparison{
+		OwnTropes:     []string{},
+		PacingNote:    output.PacingNote,
+		Opportunities: output.Opportunities,
+	}
+	if comparison.Opportunities == nil {
+		comparison.Opportunities = []models.DifferentiationOpportunity{}
+	}
+	comparison.SharedTropes, comparison.CompetitorTropes, comparison.OwnTropes = splitTropes(structure.Tropes, output.OwnTropes)
+	comparison.BeatTiming = beatTiming(structure, blueprint)
+	return comparison, nil
+}
+
+// splitTropes 按名称拆分共有、仅竞品、仅本项目的套路
+func splitTropes(competitor, own []string) (shared, competitorOnly, ownOnly []string) {
+	shared, competitorOnly, ownOnly = []string{}, []string{}, []string{}
+	ownSet := make(map[string]bool, len(own))
+	for _, t := range own {
+		ownSet[strings.TrimSpace(t)] = true
+	}
+	seen := make(map[string]bool, len(competitor))
+	for _, t := range competitor {
+		t = strings.TrimSpace(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		if ownSet[t] {
+			shared = append(shared, t)
+		} else {
+			competitorOnly = append(competitorOnly, t)
+		}
+	}
+	for _, t := range own {
+		t = strings.TrimSpace(t)
+		if t != "" && !seen[t] {
+			seen[t] = true
+			ownOnly = append(ownOnly, t)
+		}
+	}
+	return shared, competitorOnly, ownOnly
+}
+
+// beatTiming 比较竞品节拍和蓝图中可定位的节拍：高潮取章节规划的张力峰值，开篇钩子取第一个冲突强度达到60的章节
+func beatTiming(structure *models.CompetitorStructure, blueprint *models.NarrativeBlueprint) []models.BeatTimingDiff {
+	diffs := []models.BeatTimingDiff{}
+	n := len(blueprint.ChapterPlans)
+	if n == 0 {
+		return diffs
+	}
+
+	own := make(map[string]float64)
+	report := pacing.AnalyzePlan(blueprint.ChapterPlans)
+	own[BeatClimax] = float64(report.PeakChapter) / float64(n)
+	for _, ch := range report.Chapters {
+		if ch.ConflictIntensity >= 60 {
+			own[BeatOpeningHook] = float64(ch.Chapter) / float64(n)
+			break
+		}
+	}
+
+	for _, b := range structure.Beats {
+		pos, ok := own[b.Name]
+		if !ok {
+			continue
+		}
+		delete(own, b.Name)
+		diffs = append(diffs, models.BeatTimingDiff{
+			Beat:       b.Name,
+			Competitor: b.Position,
+			Own:        math.Round(pos*100) / 100,
+			Delta:      math.Round((pos-b.Position)*100) / 100,
+		})
+	}
+	return diffs
+}
+
+// buildCompetitorStructurePrompt 构建竞品结构提取提示词
+func buildCompetitorStructurePrompt(title, source string) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("# 竞品结构拆解\n\n")
+	if title != "" {
+		prompt.WriteString(fmt.Sprintf("## 作品\n《%s》\n\n", title))
+	}
+	runes := []rune(source)
+	if len(runes) > maxCompetitorSourceRunes {
+		runes = runes[:maxCompetitorSourceRunes]
+	}
+	prompt.WriteString(fmt.Sprintf("## 简介/章节目录\n%s\n\n", string(runes)))
+
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString("1. genre 题材类型，premise 一句话核心设定\n")
+	prompt.WriteString("2. chapter_count 材料中的章节数，只有简介时填0\n")
+	prompt.WriteString(fmt.Sprintf("3. beats 结构节拍，name 只能是：%s、%s、%s、%s、%s；材料中看不出的节拍不要写\n",
+		BeatOpeningHook, BeatIncitingIncident, BeatMidpoint, BeatAllIsLost, BeatClimax))
+	prompt.WriteString("   position 为节拍在全书中的相对位置（0-1），能对应到章节时填 chapter\n")
+	prompt.WriteString("4. tropes 用到的套路和卖点，使用网文读者熟悉的简短叫法（如 退婚流、系统、重生、扮猪吃虎）\n")
+	prompt.WriteString("5. pacing 节奏：speed（fast/medium/slow）、first_hook_at 第一个爽点所在章节、payoff_interval 平均每隔几章一个爽点、notes 说明\n")
+	prompt.WriteString("6. strengths 吸引读者的地方，weaknesses 明显的短板\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "genre": "玄幻",
+  "premise": "一句话核心设定",
+  "chapter_count": 120,
+  "beats": [{"name": "开篇钩子", "position": 0.01, "chapter": 1, "summary": "节拍内容"}],
+  "tropes": ["退婚流", "系统"],
+  "pacing": {"speed": "fast", "first_hook_at": 3, "payoff_interval": 2.5, "notes": "节奏说明"},
+  "strengths": ["优势"],
+  "weaknesses": ["短板"]
+}`)
+
+	return prompt.String()
+}
+
+// buildCompetitorComparisonPrompt 构建竞品对比提示词
+func buildCompetitorComparisonPrompt(structure *models.CompetitorStructure, blueprint *models.NarrativeBlueprint) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("# 竞品差异化分析\n\n")
+	prompt.WriteString("## 竞品\n")
+	prompt.WriteString(fmt.Sprintf("- 题材: %s\n- 核心设定: %s\n", structure.Genre, structure.Premise))
+	if len(structure.Tropes) > 0 {
+		prompt.WriteString(fmt.Sprintf("- 套路: %s\n", strings.Join(structure.Tropes, "、")))
+	}
+	prompt.WriteString(fmt.Sprintf("- 节奏: %s，第%d章出现第一个爽点，平均%.1f章一个爽点。%s\n",
+		structure.Pacing.Speed, structure.Pacing.FirstHookAt, structure.Pacing.PayoffInterval, structure.Pacing.Notes))
+	for _, b := range structure.Beats {
+		prompt.WriteString(fmt.Sprintf("- %s（%.0f%%）: %s\n", b.Name, b.Position*100, b.Summary))
+	}
+	if len(structure.Strengths) > 0 {
+		prompt.WriteString(fmt.Sprintf("- 优势: %s\n", strings.Join(structure.Strengths, "；")))
+	}
+	if len(structure.Weaknesses) > 0 {
+		prompt.WriteString(fmt.Sprintf("- 短板: %s\n", strings.Join(structure.Weaknesses, "；")))
+	}
+
+	outline := blueprint.StoryOutline
+	prompt.WriteString("\n## 本项目蓝图\n")
+	if blueprint.ThemePlan.CoreTheme != "" {
+		prompt.WriteString(fmt.Sprintf("- 核心主题: %s\n", blueprint.ThemePlan.CoreTheme))
+	}
+	prompt.WriteString(fmt.Sprintf("- 开端: %s\n- 激励事件: %s\n- 中点: %s\n- 至暗时刻: %s\n- 高潮: %s\n- 结局: %s\n",
+		outline.Act1.Setup, outline.Act1.IncitingIncident, outline.Act2.Midpoint, outline.Act2.AllIsLost, outline.Act3.Climax, outline.Act3.Resolution))
+	prompt.WriteString(fmt.Sprintf("- 共%d章\n", len(blueprint.ChapterPlans)))
+	for i, ch := range blueprint.ChapterPlans {
+		if i >= 30 {
+			prompt.WriteString(fmt.Sprintf("- ……其余%d章略\n", len(blueprint.ChapterPlans)-i))
+			break
+		}
+		prompt.WriteString(fmt.Sprintf("  - 第%d章《%s》: %s\n", ch.Chapter, ch.Title, ch.Purpose))
+	}
+
+	prompt.WriteString("\n# 要求\n")
+	prompt.WriteString("1. own_tropes 本项目用到的套路，与竞品相同的套路使用与竞品完全相同的叫法\n")
+	prompt.WriteString("2. pacing_note 对比两者的节奏，指出本项目偏快还是偏慢\n")
+	prompt.WriteString("3. opportunities 差异化机会（3-6条）：area 为 premise/trope/pacing/structure/character 之一，\n")
+	prompt.WriteString("   competitor 竞品的做法，own 本项目目前的做法，suggestion 具体的差异化建议；优先利用竞品的短板\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "own_tropes": ["系统"],
+  "pacing_note": "节奏对比",
+  "opportunities": [{"area": "trope", "competitor": "竞品做法", "own": "本项目做法", "suggestion": "差异化建议"}]
+}`)
+
+	return prompt.String()
+}
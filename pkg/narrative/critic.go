@@ -0,0 +1,419 @@
+// Package narrative 叙事器 - 质量评审
+// 每轮演化后由LLM评审员按评分细则给角色、冲突、伏笔、主题打分，
+// 输出分项得分和改进建议，综合分用于判断演化是否可以提前结束
+package narrative
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/pkg/jsonx"
+)
+
+// CriticAspect 评审维度
+type CriticAspect string
+
+const (
+	AspectCharacters  CriticAspect = "characters"  // 角色
+	AspectConflicts   CriticAspect = "conflicts"   // 冲突
+	AspectForeshadows CriticAspect = "foreshadows" // 伏笔
+	AspectThemes      CriticAspect = "themes"      // 主题
+	AspectPlot        CriticAspect = "plot"        // 情节（反转）
+)
+
+// coreAspects 参与综合评分的维度
+var coreAspects = []CriticAspect{AspectCharacters, AspectConflicts, AspectForeshadows, AspectThemes}
+
+// roundAspects 演化轮次对应的评审维度
+var roundAspects = map[EvolutionRound]CriticAspect{
+	RoundCharacterCreation: AspectCharacters,
+	RoundCharacterDeepen:   AspectCharacters,
+	RoundConflictDesign:    AspectConflicts,
+	RoundConflictEvolution: AspectConflicts,
+	RoundForeshadowPlant:   AspectForeshadows,
+	RoundThemeDeepen:       AspectThemes,
+	RoundPlotTwist:         AspectPlot,
+}
+
+// RubricCriterion 评分细则条目
+type RubricCriterion struct {
+	Key         string // 分项键，与评审输出的 sub_scores 对应
+	Name        string // 分项名称
+	Description string // 评分标准
+}
+
+// rubric 评审维度的评分细则
+type rubric struct {
+	Label    string
+	Criteria []RubricCriterion
+}
+
+var rubrics = map[CriticAspect]rubric{
+	AspectCharacters: {
+		Label: "角色",
+		Criteria: []RubricCriterion{
+			{"depth", "立体度", "欲望、需求、恐惧是否具体且相互牵制，而不是标签化的性格描述"},
+			{"want_need_gap", "欲望与需求落差", "表层欲望与深层需求之间是否存在能驱动成长的真实落差"},
+			{"distinctiveness", "辨识度", "角色之间的动机、处境和行为方式是否明显不同，不可互换"},
+			{"relationships", "关系张力", "角色之间是否有隐藏情感、秘密或权力落差，能够持续产生戏剧"},
+			{"arc_potential", "弧光潜力", "内在冲突和秘密是否为后续转变留下了空间"},
+		},
+	},
+	AspectConflicts: {
+		Label: "冲突",
+		Criteria: []RubricCriterion{
+			{"stakes", "赌注", "失败的代价是否具体、个人化且足够沉重"},
+			{"escalation", "升级路径", "演化路径是否层层递进，每个阶段都比上一阶段更难"},
+			{"character_rooted", "角色根基", "冲突是否源自角色的欲望与缺陷，而不是外部强加"},
+			{"variety", "层次", "内在、人际、社会等不同层次的冲突是否相互交织"},
+			{"thematic_link", "主题关联", "冲突的核心问题是否在检验故事主题"},
+		},
+	},
+	AspectForeshadows: {
+		Label: "伏笔",
+		Criteria: []RubricCriterion{
+			{"subtlety", "隐蔽性", "伏笔初读时是否自然不突兀，不会提前泄露答案"},
+			{"fairness", "公平性", "回收时读者回看能否发现线索早已给出"},
+			{"payoff_plan", "回收规划", "每条伏笔是否有明确的回收时机，种下与回收间隔是否合理"},
+			{"payoff_impact", "回收冲击", "回收是否会改变读者对人物或事件的理解"},
+			{"thematic_link", "主题关联", "伏笔是否与主题或核心冲突相连，而不是孤立的谜题"},
+		},
+	},
+	AspectThemes: {
+		Label: "主题",
+		Criteria: []RubricCriterion{
+			{"clarity", "清晰度", "核心主题是否能用一个有争议的问题表述，而不是空泛的口号"},
+			{"layering", "层次", "主题是否从表层逐步深入到哲学层面"},
+			{"dramatization", "戏剧化", "主题是否通过角色选择和冲突结果体现，而不是说教"},
+			{"symbols", "象征", "象征和母题是否反复出现并随情节演化含义"},
+			{"ambiguity", "张力", "故事是否给主题问题的不同答案都留出了分量"},
+		},
+	},
+	AspectPlot: {
+		Label: "情节",
+		Criteria: []RubricCriterion{
+			{"hook", "钩子", "故事钩子是否能在开篇抓住读者"},
+			{"causality", "因果", "关键事件之间是否因果相连，而不是巧合推动"},
+			{"reversals", "反转", "反转是否出人意料又合情合理"},
+			{"tension", "张力", "各情节线的张力是否有起伏，并在高潮汇聚"},
+		},
+	},
+}
+
+// Critique 评审结果
+type Critique struct {
+	Aspect      CriticAspect   `json:"aspect"`
+	Round       int            `json:"round"`                // 评审时的演化轮次
+	Score       int            `json:"score"`                // 总分 0-100
+	SubScores   map[string]int `json:"sub_scores"`           // 分项得分 0-100
+	Strengths   []string       `json:"strengths,omitempty"`  // 优点
+	Weaknesses  []string       `json:"weaknesses,omitempty"` // 问题
+	Suggestions []string       `json:"suggestions"`          // 改进建议
+	Fallback    bool           `json:"fallback,omitempty"`   // LLM评审失败，分数为启发式估算
+}
+
+// critiqueResponse 评审员的响应
+type critiqueResponse struct {
+	SubScores   map[string]int `json:"sub_scores"`
+	Strengths   []string       `json:"strengths"`
+	Weaknesses  []string       `json:"weaknesses"`
+	Suggestions []string       `json:"suggestions"`
+}
+
+// critique 调用LLM评审员对指定维度打分，失败时退回启发式估算
+func (ee *EvolutionEngine) critique(state *EvolutionState, aspect CriticAspect) *Critique {
+	r := rubrics[aspect]
+	prompt := buildCritiquePrompt(state, aspect, r)
+	systemPrompt := fmt.Sprintf("你是一位严格的小说编辑，负责按评分细则评审故事的%s设计。评分要克制：合格为60分，只有出版级的设计才能超过85分。只输出JSON。", r.Label)
+
+	result, err := ee.callForRole(roleCritique, prompt, systemPrompt)
+	if err != nil {
+		ee.degrade(state, "critique_"+string(aspect), "%s评审失败，使用估算分数: %v", r.Label, err)
+		return ee.fallbackCritique(state, aspect)
+	}
+
+	var out critiqueResponse
+	if err := jsonx.Unmarshal(result, &out); err != nil {
+		ee.degrade(state, "critique_"+string(aspect), "解析%s评审失败，使用估算分数: %v", r.Label, err)
+		return ee.fallbackCritique(state, aspect)
+	}
+
+	// 只采纳细则内的分项，总分取分项平均，避免评审员给出与分项不符的总分
+	subScores := make(map[string]int, len(r.Criteria))
+	total := 0
+	for _, c := range r.Criteria {
+		score, ok := out.SubScores[c.Key]
+		if !ok {
+			continue
+		}
+		score = max(0, min(100, score))
+		subScores[c.Key] = score
+		total += score
+	}
+	if len(subScores) == 0 {
+		ee.degrade(state, "critique_"+string(aspect), "%s评审未给出分项得分，使用估算分数", r.Label)
+		return ee.fallbackCritique(state, aspect)
+	}
+
+	return &Critique{
+		Aspect:      aspect,
+		Round:       state.CurrentRound,
+		Score:       total / len(subScores),
+		SubScores:   subScores,
+		Strengths:   out.Strengths,
+		Weaknesses:  out.Weaknesses,
+		Suggestions: out.Suggestions,
+	}
+}
+
+// scoreAspect 评审指定维度，记录到演化状态并返回总分
+func (ee *EvolutionEngine) scoreAspect(state *EvolutionState, aspect CriticAspect) int {
+	c := ee.critique(state, aspect)
+	if state.Critiques == nil {
+		state.Critiques = make(map[CriticAspect]*Critique)
+	}
+	state.Critiques[aspect] = c
+	return c.Score
+}
+
+// fallbackCritique 启发式估算，仅在LLM评审不可用时使用
+func (ee *EvolutionEngine) fallbackCritique(state *EvolutionState, aspect CriticAspect) *Critique {
+	var score int
+	switch aspect {
+	case AspectCharacters:
+		score = 50 + len(state.Characters)*3
+	case AspectConflicts:
+		score = 40
+		if len(state.Conflicts) > 0 {
+			totalIntensity := 0
+			for _, c := range state.Conflicts {
+				totalIntensity += c.CurrentIntensity
+			}
+			score = 50 + totalIntensity/len(state.Conflicts)/2
+		}
+	case AspectForeshadows:
+		score = 50 + len(state.Foreshadowing)*2
+	case AspectThemes:
+		score = 50 + state.NarrativeDepth*4
+	default:
+		score = 60
+	}
+	return &Critique{
+		Aspect:   aspect,
+		Round:    state.CurrentRound,
+		Score:    min(80, score),
+		Fallback: true,
+	}
+}
+
+// OverallQuality 综合质量：各核心维度最近一次评审的平均分
+// ready 表示所有核心维度都已由LLM评审过，只有此时综合分才可用于提前结束演化
+func (s *EvolutionState) OverallQuality() (score int, ready bool) {
+	ready = true
+	total, count := 0, 0
+	for _, aspect := range coreAspects {
+		c, ok := s.Critiques[aspect]
+		if !ok || c == nil {
+			ready = false
+			continue
+		}
+		if c.Fallback {
+			ready = false
+		}
+		total += c.Score
+		count++
+	}
+	if count == 0 {
+		return 0, false
+	}
+	return total / count, ready
+}
+
+// CritiqueSuggestions 各维度最近一次评审的改进建议，供后续演化轮次参考
+func (s *EvolutionState) CritiqueSuggestions(aspect CriticAspect) []string {
+	if c, ok := s.Critiques[aspect]; ok && c != nil {
+		return c.Suggestions
+	}
+	return nil
+}
+
+// buildCritiquePrompt 构建评审提示词
+func buildCritiquePrompt(state *EvolutionState, aspect CriticAspect, r rubric) string {
+	var prompt strings.Builder
+
+	prompt.WriteString(fmt.Sprintf("# %s评审\n\n", r.Label))
+	if state.StoryHook != "" {
+		prompt.WriteString(fmt.Sprintf("故事钩子：%s\n", state.StoryHook))
+	}
+	if state.ThemeEvolution != nil && state.ThemeEvolution.CoreTheme != "" {
+		prompt.WriteString(fmt.Sprintf("核心主题：%s\n", state.ThemeEvolution.CoreTheme))
+	}
+	prompt.WriteString(fmt.Sprintf("演化轮次：%d/%d\n\n", state.CurrentRound, state.MaxRounds))
+
+	prompt.WriteString("## 待评审内容\n")
+	prompt.WriteString(describeAspect(state, aspect))
+
+	prompt.WriteString("\n## 评分细则（每项0-100分）\n")
+	for _, c := range r.Criteria {
+		prompt.WriteString(fmt.Sprintf("- %s（%s）：%s\n", c.Key, c.Name, c.Description))
+	}
+
+	if prev, ok := state.Critiques[aspect]; ok && prev != nil && !prev.Fallback && len(prev.Suggestions) > 0 {
+		prompt.WriteString(fmt.Sprintf("\n## 上次评审（第%d轮，%d分）的建议\n", prev.Round, prev.Score))
+		for _, s := range prev.Suggestions {
+			prompt.WriteString("- " + s + "\n")
+		}
+		prompt.WriteString("请判断这些建议是否已被采纳，并据此调整分数。\n")
+	}
+
+	prompt.WriteString("\n# 要求\n")
+	prompt.WriteString("1. 逐项打分，分数要能被待评审内容中的具体证据支撑\n")
+	prompt.WriteString("2. 内容缺失的分项给低分，不要因为数量多而给高分\n")
+	prompt.WriteString("3. 改进建议要具体到角色、冲突或伏笔，可以直接用于下一轮修改，最多5条\n\n")
+
+	prompt.WriteString("# 输出格式（JSON）\n{\n  \"sub_scores\": {")
+	keys := make([]string, 0, len(r.Criteria))
+	for _, c := range r.Criteria {
+		keys = append(keys, fmt.Sprintf("\"%s\": 0", c.Key))
+	}
+	prompt.WriteString(strings.Join(keys, ", "))
+	prompt.WriteString("},\n  \"strengths\": [\"优点\"],\n  \"weaknesses\": [\"问题\"],\n  \"suggestions\": [\"改进建议\"]\n}")
+
+	return prompt.String()
+}
+
+// describeAspect 将演化状态中与评审维度相关的内容整理为文本
+func describeAspect(state *EvolutionState, aspect CriticAspect) string {
+	var sb strings.Builder
+
+	switch aspect {
+	case AspectCharacters:
+		if len(state.Characters) == 0 {
+			sb.WriteString("（尚无角色）\n")
+		}
+		for _, char := range sortedCharacters(state.Characters) {
+			d := char.Desires
+			sb.WriteString(fmt.Sprintf("- %s（%s）：想要%s；需要%s；恐惧%s；落差%s\n",
+				char.Name, char.Role, d.ConsciousWant, d.UnconsciousNeed, d.Fear, d.WantVsNeedGap))
+			if len(char.InternalConflicts) > 0 {
+				sb.WriteString("  内在冲突：" + strings.Join(char.InternalConflicts, "；") + "\n")
+			}
+			if len(char.Secrets) > 0 {
+				sb.WriteString("  秘密：" + strings.Join(char.Secrets, "；") + "\n")
+			}
+			for _, rel := range char.Relationships {
+				target := rel.TargetCharacterID
+				if t, ok := state.Characters[target]; ok {
+					target = t.Name
+				}
+				sb.WriteString(fmt.Sprintf("  与%s：%s，表面情感%d，隐藏情感%d\n",
+					target, rel.PowerDynamic, rel.VisibleEmotion, rel.HiddenEmotion))
+			}
+		}
+	case AspectConflicts:
+		if len(state.Conflicts) == 0 {
+			sb.WriteString("（尚无冲突）\n")
+		}
+		for _, c := range state.Conflicts {
+			sb.WriteString(fmt.Sprintf("- [%s] %s（强度%d）\n", c.Type, c.CoreQuestion, c.CurrentIntensity))
+			if len(c.Stakes) > 0 {
+				sb.WriteString("  赌注：" + strings.Join(c.Stakes, "；") + "\n")
+			}
+			for _, stage := range c.EvolutionPath {
+				sb.WriteString(fmt.Sprintf("  %s：%s\n", stage.Stage, stage.Description))
+			}
+			if c.ThematicRelevance != "" {
+				sb.WriteString("  主题关联：" + c.ThematicRelevance + "\n")
+			}
+		}
+	case AspectForeshadows:
+		if len(state.Foreshadowing) == 0 && len(state.ForeshadowPlan) == 0 {
+			sb.WriteString("（尚无伏笔）\n")
+		}
+		for _, f := range state.Foreshadowing {
+			sb.WriteString(fmt.Sprintf("- [%s] %s（隐蔽度%d）", f.Type, f.Content, f.Subtlety))
+			if f.PayoffScene != "" {
+				sb.WriteString("，回收：" + f.PayoffScene)
+			} else if f.PayoffRound > 0 {
+				sb.WriteString(fmt.Sprintf("，计划第%d轮回收", f.PayoffRound))
+			} else {
+				sb.WriteString("，未规划回收")
+			}
+			sb.WriteString("\n")
+		}
+		for _, p := range state.ForeshadowPlan {
+			if p == nil {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("- 计划：%s\n", p.Content))
+		}
+	case AspectThemes:
+		te := state.ThemeEvolution
+		if te == nil {
+			sb.WriteString("（尚无主题设计）\n")
+			break
+		}
+		for _, layer := range te.ThematicLayers {
+			sb.WriteString(fmt.Sprintf("- %s层：%s\n", layer.Layer, layer.Expression))
+		}
+		for name, symbol := range te.SymbolTracker {
+			if symbol == nil {
+				continue
+			}
+			sb.WriteString(fmt.Sprintf("- 象征%s：%s", name, symbol.Meaning))
+			if len(symbol.Evolution) > 0 {
+				sb.WriteString("，演化：" + strings.Join(symbol.Evolution, "→"))
+			}
+			sb.WriteString("\n")
+		}
+		for _, c := range state.Conflicts {
+			if c.ThematicRelevance != "" {
+				sb.WriteString(fmt.Sprintf("- 冲突「%s」检验主题：%s\n", c.CoreQuestion, c.ThematicRelevance))
+			}
+		}
+	case AspectPlot:
+		for _, t := range state.PlotThreads {
+			sb.WriteString(fmt.Sprintf("- [%s] %s（张力%d，%s）\n", t.Type, t.Name, t.Tension, t.Status))
+			for _, e := range t.KeyEvents {
+				mark := ""
+				if e.Reversal {
+					mark = "【反转】"
+				}
+				sb.WriteString(fmt.Sprintf("  %d. %s%s\n", e.Sequence, mark, e.Description))
+			}
+		}
+		for _, c := range state.Conflicts {
+			sb.WriteString(fmt.Sprintf("- 冲突：%s（强度%d）\n", c.CoreQuestion, c.CurrentIntensity))
+		}
+	}
+
+	return sb.String()
+}
+
+// sortedCharacters 按主角、反派、其他角色排序，保证提示词稳定
+func sortedCharacters(m map[string]*CharacterState) []*CharacterState {
+	chars := make([]*CharacterState, 0, len(m))
+	for _, c := range m {
+		if c != nil {
+			chars = append(chars, c)
+		}
+	}
+	rank := func(role string) int {
+		switch role {
+		case "主角", "protagonist":
+			return 0
+		case "反派", "antagonist":
+			return 1
+		default:
+			return 2
+		}
+	}
+	sort.SliceStable(chars, func(i, j int) bool {
+		if rank(chars[i].Role) != rank(chars[j].Role) {
+			return rank(chars[i].Role) < rank(chars[j].Role)
+		}
+		return chars[i].Name < chars[j].Name
+	})
+	return chars
+}
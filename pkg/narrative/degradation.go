@@ -0,0 +1,57 @@
+// Package narrative 叙事器 - 降级策略
+// LLM调用失败或输出不可用时，按配置的降级策略决定直接失败还是使用兜底内容，
+// 使用兜底内容时记录警告，附在演化结果和叙事蓝图上，让用户知道哪些内容是占位
+package narrative
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+)
+
+// degradationModule 叙事器在降级策略配置中的模块名
+const degradationModule = "narrative_engine"
+
+// degradationPolicy 当前生效的降级策略
+func (ee *EvolutionEngine) degradationPolicy() config.DegradationPolicy {
+	if ee == nil || ee.cfg == nil {
+		return config.DegradeFallbackWarning
+	}
+	return ee.cfg.Degradation.For(degradationModule)
+}
+
+// degrade 记录一次降级，调用方随后使用兜底内容
+// fail_fast 下记录失败，由 Evolve / CreateBlueprintThroughEvolution 在本阶段结束时返回；
+// fallback_with_warning 下记录警告；fallback_silent 下不做记录
+func (ee *EvolutionEngine) degrade(state *EvolutionState, component, format string, args ...interface{}) {
+	message := fmt.Sprintf(format, args...)
+
+	switch ee.degradationPolicy() {
+	case config.DegradeFailFast:
+		if state.degradeErr == nil {
+			state.degradeErr = fmt.Errorf("%s: %s", component, message)
+		}
+	case config.DegradeFallbackSilent:
+	default:
+		ee.log().Warn("使用兜底内容", "module", degradationModule, "component", component, "reason", message)
+		state.Warnings = append(state.Warnings, models.DegradationWarning{
+			Module:    degradationModule,
+			Component: component,
+			Message:   message,
+			Round:     state.CurrentRound,
+			CreatedAt: time.Now(),
+		})
+	}
+}
+
+// takeDegradeErr 取出并清除 fail_fast 策略下记录的失败
+func (s *EvolutionState) takeDegradeErr() error {
+	err := s.degradeErr
+	s.degradeErr = nil
+	if err != nil {
+		return fmt.Errorf("降级策略为 fail_fast，LLM失败未使用兜底内容: %w", err)
+	}
+	return nil
+}
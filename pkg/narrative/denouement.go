@@ -0,0 +1,282 @@
+// Package narrative 叙事器 - 收尾与尾声规划
+// 在高潮章之后盘点已解决/未解决的线索，规划1-3个尾声章节
+package narrative
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// 线索类型
+const (
+	ThreadKindConflict     = "conflict"
+	ThreadKindForeshadow   = "foreshadow"
+	ThreadKindPlotThread   = "plot_thread"
+	ThreadKindCharacterArc = "character_arc"
+)
+
+// 尾声章节数量上限
+const maxEpilogueChapters = 3
+
+// ThreadStatus 线索收束状态
+type ThreadStatus struct {
+	ID       string `json:"id"`
+	Kind     string `json:"kind"` // conflict/foreshadow/plot_thread/character_arc
+	Name     string `json:"name"`
+	Resolved bool   `json:"resolved"`
+	Note     string `json:"note,omitempty"`
+}
+
+// ClosureBeat 收束节拍
+type ClosureBeat struct {
+	ThreadID string `json:"thread_id"`
+	Beat     string `json:"beat"` // 具体的收束动作
+}
+
+// EpilogueChapter 尾声章节
+type EpilogueChapter struct {
+	Chapter      int           `json:"chapter"`
+	Title        string        `json:"title"`
+	Purpose      string        `json:"purpose"`
+	ClosureBeats []ClosureBeat `json:"closure_beats"`
+	NewNormal    string        `json:"new_normal"` // 展示的"新常态"
+}
+
+// DenouementPlan 收尾规划
+type DenouementPlan struct {
+	ClimaxChapter int               `json:"climax_chapter"`
+	Resolved      []ThreadStatus    `json:"resolved"`
+	Unresolved    []ThreadStatus    `json:"unresolved"`
+	Epilogues     []EpilogueChapter `json:"epilogues"`
+}
+
+// PlanDenouement 规划尾声章节（1轮LLM）
+// 需要在阶段6（章节规划）之后调用
+func (o *Orchestrator) PlanDenouement(state *EvolutionState) (*DenouementPlan, error) {
+	if state.ChapterPlan == nil || len(state.ChapterPlan.ChapterSequence) == 0 {
+		return nil, fmt.Errorf("尚未完成章节规划")
+	}
+
+	plan := &DenouementPlan{
+		ClimaxChapter: findClimaxChapter(state),
+	}
+	for _, thread := range inventoryThreads(state, plan.ClimaxChapter) {
+		if thread.Resolved {
+			plan.Resolved = append(plan.Resolved, thread)
+		} else {
+			plan.Unresolved = append(plan.Unresolved, thread)
+		}
+	}
+
+	state.CurrentRound++
+	prompt := o.buildDenouementPrompt(state, plan, epilogueCount(len(plan.Unresolved)))
+	systemPrompt := o.buildSystemPrompt("denouement_planner")
+
+	response, err := o.engine.callWithRetry(prompt, systemPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("尾声规划失败: %w", err)
+	}
+
+	var result struct {
+		Epilogues []EpilogueChapter `json:"epilogues"`
+	}
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return nil, fmt.Errorf("解析尾声规划结果失败: %w", err)
+	}
+	if len(result.Epilogues) > maxEpilogueChapters {
+		result.Epilogues = result.Epilogues[:maxEpilogueChapters]
+	}
+
+	// 章节号紧接在已规划章节之后
+	last := lastChapterNum(state)
+	for i := range result.Epilogues {
+		result.Epilogues[i].Chapter = last + i + 1
+	}
+	plan.Epilogues = result.Epilogues
+
+	state.logAction(state.CurrentRound, "denouement_planning", "尾声规划", []string{
+		fmt.Sprintf("高潮章: 第%d章", plan.ClimaxChapter),
+		fmt.Sprintf("未解决线索: %d", len(plan.Unresolved)),
+		fmt.Sprintf("尾声章节: %d", len(plan.Epilogues)),
+	})
+
+	return plan, nil
+}
+
+// ApplyDenouement 将尾声章节追加到章节规划
+func (o *Orchestrator) ApplyDenouement(state *EvolutionState, plan *DenouementPlan) {
+	for _, epilogue := range plan.Epilogues {
+		beats := make([]string, 0, len(epilogue.ClosureBeats))
+		for _, beat := range epilogue.ClosureBeats {
+			beats = append(beats, beat.Beat)
+		}
+		state.ChapterPlan.ChapterSequence = append(state.ChapterPlan.ChapterSequence, ChapterSynopsis{
+			Chapter:             epilogue.Chapter,
+			Title:               epilogue.Title,
+			Purpose:             epilogue.Purpose,
+			KeyEvents:           beats,
+			RelationshipChanges: []string{},
+			ForeshadowOps:       ForeshadowOperations{},
+		})
+	}
+	state.ChapterPlan.TotalChapters = len(state.ChapterPlan.ChapterSequence)
+}
+
+// findClimaxChapter 定位高潮章：优先匹配"高潮"标记，其次是包含最后一个关键事件的章节
+func findClimaxChapter(state *EvolutionState) int {
+	sequence := state.ChapterPlan.ChapterSequence
+
+	for i := len(sequence) - 1; i >= 0; i-- {
+		ch := sequence[i]
+		if strings.Contains(ch.Title, "高潮") || strings.Contains(ch.Purpose, "高潮") {
+			return ch.Chapter
+		}
+	}
+
+	if state.GlobalOutline != nil && len(state.GlobalOutline.KeyEvents) > 0 {
+		lastEvent := state.GlobalOutline.KeyEvents[0]
+		for _, event := range state.GlobalOutline.KeyEvents {
+			if event.Sequence > lastEvent.Sequence {
+				lastEvent = event
+			}
+		}
+		for i := len(sequence) - 1; i >= 0; i-- {
+			for _, name := range sequence[i].KeyEvents {
+				if name == lastEvent.ID || name == lastEvent.Name {
+					return sequence[i].Chapter
+				}
+			}
+		}
+	}
+
+	return lastChapterNum(state)
+}
+
+// inventoryThreads 盘点高潮章之后各条线索的收束情况
+func inventoryThreads(state *EvolutionState, climaxChapter int) []ThreadStatus {
+	threads := make([]ThreadStatus, 0)
+
+	for _, conflict := range state.Conflicts {
+		threads = append(threads, ThreadStatus{
+			ID:       conflict.ID,
+			Kind:     ThreadKindConflict,
+			Name:     conflict.CoreQuestion,
+			Resolved: conflict.IsResolved,
+			Note:     conflict.Resolution,
+		})
+	}
+
+	for _, foreshadow := range state.ForeshadowPlan {
+		resolved := foreshadow.IsPaidOff ||
+			(foreshadow.PayoffChapter > 0 && foreshadow.PayoffChapter <= climaxChapter)
+		threads = append(threads, ThreadStatus{
+			ID:       foreshadow.ID,
+			Kind:     ThreadKindForeshadow,
+			Name:     foreshadow.Content,
+			Resolved: resolved,
+			Note:     foreshadow.PayoffMethod,
+		})
+	}
+
+	for _, thread := range state.PlotThreads {
+		threads = append(threads, ThreadStatus{
+			ID:       thread.ID,
+			Kind:     ThreadKindPlotThread,
+			Name:     thread.Name,
+			Resolved: thread.Status == "resolved",
+			Note:     thread.Type,
+		})
+	}
+
+	for id, character := range state.Characters {
+		threads = append(threads, ThreadStatus{
+			ID:       id,
+			Kind:     ThreadKindCharacterArc,
+			Name:     character.Name,
+			Resolved: character.ArcProgress >= 1,
+			Note:     character.Role,
+		})
+	}
+
+	return threads
+}
+
+// epilogueCount 根据未解决线索数量决定尾声章节数（1-3）
+func epilogueCount(unresolved int) int {
+	switch {
+	case unresolved <= 2:
+		return 1
+	case unresolved <= 5:
+		return 2
+	default:
+		return maxEpilogueChapters
+	}
+}
+
+// lastChapterNum 已规划的最后一章
+func lastChapterNum(state *EvolutionState) int {
+	last := 0
+	for _, ch := range state.ChapterPlan.ChapterSequence {
+		if ch.Chapter > last {
+			last = ch.Chapter
+		}
+	}
+	return last
+}
+
+// buildDenouementPrompt 构建尾声规划提示词
+func (o *Orchestrator) buildDenouementPrompt(state *EvolutionState, plan *DenouementPlan, count int) string {
+	formatThreads := func(threads []ThreadStatus) string {
+		if len(threads) == 0 {
+			return "（无）"
+		}
+		var sb strings.Builder
+		for _, t := range threads {
+			sb.WriteString(fmt.Sprintf("- [%s] %s（%s）\n", t.ID, t.Name, t.Kind))
+		}
+		return sb.String()
+	}
+
+	climax, resolution := "", ""
+	if state.GlobalOutline != nil {
+		climax = state.GlobalOutline.Climax
+		resolution = state.GlobalOutline.Resolution
+	}
+
+	return fmt.Sprintf(`故事已在第%d章迎来高潮，请为其规划%d个尾声章节。
+
+高潮：%s
+结局：%s
+
+已解决的线索：
+%s
+未解决的线索：
+%s
+要求：
+1. 每个尾声章节都要收束具体的未解决线索，给出明确的收束节拍（谁、做了什么、带来什么结果）
+2. 优先收束副线、伏笔和角色弧光，不要重新制造主线冲突
+3. 至少一个章节要展示高潮之后的"新常态"：世界和角色发生了怎样的持久变化
+4. 节奏舒缓，情绪上给读者余韵
+
+请以JSON格式返回：
+{
+  "epilogues": [
+    {
+      "title": "章节标题",
+      "purpose": "本章目的",
+      "closure_beats": [
+        {"thread_id": "线索ID", "beat": "具体的收束动作"}
+      ],
+      "new_normal": "展示的新常态"
+    }
+  ]
+}
+只返回JSON，不要包含其他内容。`,
+		plan.ClimaxChapter,
+		count,
+		climax,
+		resolution,
+		formatThreads(plan.Resolved),
+		formatThreads(plan.Unresolved))
+}
@@ -0,0 +1,346 @@
+// Package narrative 叙事器 - 蓝图对比
+// 结构化比较两个版本的叙事蓝图，输出章节增删、场景目的变化和角色弧光变化
+package narrative
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// 变更类型
+const (
+	ChangeAdded    = "added"
+	ChangeRemoved  = "removed"
+	ChangeModified = "modified"
+)
+
+// FieldChange 字段变化
+type FieldChange struct {
+	Field string `json:"field"`
+	Old   string `json:"old"`
+	New   string `json:"new"`
+}
+
+// ChapterChange 章节变化
+type ChapterChange struct {
+	Chapter int           `json:"chapter"`
+	Title   string        `json:"title"`
+	Kind    string        `json:"kind"` // added/removed/modified
+	Fields  []FieldChange `json:"fields,omitempty"`
+}
+
+// SceneChange 场景变化
+type SceneChange struct {
+	Chapter int           `json:"chapter"`
+	Scene   int           `json:"scene"`
+	Kind    string        `json:"kind"`
+	Purpose string        `json:"purpose"`
+	Fields  []FieldChange `json:"fields,omitempty"`
+}
+
+// ArcChange 角色弧光变化
+type ArcChange struct {
+	CharacterID string        `json:"character_id"`
+	Kind        string        `json:"kind"`
+	Fields      []FieldChange `json:"fields,omitempty"`
+}
+
+// BlueprintDiff 蓝图对比结果
+type BlueprintDiff struct {
+	FromID   string          `json:"from_id"`
+	ToID     string          `json:"to_id"`
+	Outline  []FieldChange   `json:"outline"`
+	Chapters []ChapterChange `json:"chapters"`
+	Scenes   []SceneChange   `json:"scenes"`
+	Arcs     []ArcChange     `json:"arcs"`
+	Theme    []FieldChange   `json:"theme"`
+}
+
+// DiffBlueprints 比较两个蓝图，from为旧版本，to为新版本
+func DiffBlueprints(from, to *models.NarrativeBlueprint) *BlueprintDiff {
+	return &BlueprintDiff{
+		FromID:   from.ID,
+		ToID:     to.ID,
+		Outline:  diffOutline(from.StoryOutline, to.StoryOutline),
+		Chapters: diffChapters(from.ChapterPlans, to.ChapterPlans),
+		Scenes:   diffScenes(from.Scenes, to.Scenes),
+		Arcs:     diffArcs(from.CharacterArcs, to.CharacterArcs),
+		Theme:    diffTheme(from.ThemePlan, to.ThemePlan),
+	}
+}
+
+// HasChanges 是否存在差异
+func (d *BlueprintDiff) HasChanges() bool {
+	return len(d.Outline)+len(d.Chapters)+len(d.Scenes)+len(d.Arcs)+len(d.Theme) > 0
+}
+
+// Report 生成可读的变更报告
+func (d *BlueprintDiff) Report() string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("# 蓝图变更报告\n\n%s → %s\n\n", d.FromID, d.ToID))
+
+	if !d.HasChanges() {
+		sb.WriteString("两个版本没有差异。\n")
+		return sb.String()
+	}
+
+	if len(d.Outline) > 0 {
+		sb.WriteString("## 故事大纲\n\n")
+		writeFieldChanges(&sb, d.Outline, "")
+		sb.WriteString("\n")
+	}
+
+	if len(d.Chapters) > 0 {
+		sb.WriteString("## 章节\n\n")
+		for _, ch := range d.Chapters {
+			sb.WriteString(fmt.Sprintf("- %s 第%d章「%s」\n", changeLabel(ch.Kind), ch.Chapter, ch.Title))
+			writeFieldChanges(&sb, ch.Fields, "  ")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(d.Scenes) > 0 {
+		sb.WriteString("## 场景\n\n")
+		for _, sc := range d.Scenes {
+			sb.WriteString(fmt.Sprintf("- %s 第%d章 场景%d：%s\n", changeLabel(sc.Kind), sc.Chapter, sc.Scene, sc.Purpose))
+			writeFieldChanges(&sb, sc.Fields, "  ")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(d.Arcs) > 0 {
+		sb.WriteString("## 角色弧光\n\n")
+		for _, arc := range d.Arcs {
+			sb.WriteString(fmt.Sprintf("- %s %s\n", changeLabel(arc.Kind), arc.CharacterID))
+			writeFieldChanges(&sb, arc.Fields, "  ")
+		}
+		sb.WriteString("\n")
+	}
+
+	if len(d.Theme) > 0 {
+		sb.WriteString("## 主题\n\n")
+		writeFieldChanges(&sb, d.Theme, "")
+	}
+
+	return sb.String()
+}
+
+// diffOutline 比较故事大纲
+func diffOutline(a, b models.StoryOutline) []FieldChange {
+	changes := make([]FieldChange, 0)
+	compareField(&changes, "structure_type", a.StructureType, b.StructureType)
+	compareField(&changes, "act1.setup", a.Act1.Setup, b.Act1.Setup)
+	compareField(&changes, "act1.inciting_incident", a.Act1.IncitingIncident, b.Act1.IncitingIncident)
+	compareField(&changes, "act1.plot_point1", a.Act1.PlotPoint1, b.Act1.PlotPoint1)
+	compareField(&changes, "act2.rising_action", joinList(a.Act2.RisingAction), joinList(b.Act2.RisingAction))
+	compareField(&changes, "act2.midpoint", a.Act2.Midpoint, b.Act2.Midpoint)
+	compareField(&changes, "act2.all_is_lost", a.Act2.AllIsLost, b.Act2.AllIsLost)
+	compareField(&changes, "act2.plot_point2", a.Act2.PlotPoint2, b.Act2.PlotPoint2)
+	compareField(&changes, "act3.climax", a.Act3.Climax, b.Act3.Climax)
+	compareField(&changes, "act3.resolution", a.Act3.Resolution, b.Act3.Resolution)
+	return changes
+}
+
+// diffChapters 按章节号比较章节规划
+func diffChapters(a, b []models.ChapterPlan) []ChapterChange {
+	oldByNum := make(map[int]models.ChapterPlan, len(a))
+	for _, ch := range a {
+		oldByNum[ch.Chapter] = ch
+	}
+	newByNum := make(map[int]models.ChapterPlan, len(b))
+	for _, ch := range b {
+		newByNum[ch.Chapter] = ch
+	}
+
+	changes := make([]ChapterChange, 0)
+	for _, num := range unionKeys(oldByNum, newByNum) {
+		oldCh, inOld := oldByNum[num]
+		newCh, inNew := newByNum[num]
+		switch {
+		case !inOld:
+			changes = append(changes, ChapterChange{Chapter: num, Title: newCh.Title, Kind: ChangeAdded})
+		case !inNew:
+			changes = append(changes, ChapterChange{Chapter: num, Title: oldCh.Title, Kind: ChangeRemoved})
+		default:
+			fields := make([]FieldChange, 0)
+			compareField(&fields, "title", oldCh.Title, newCh.Title)
+			compareField(&fields, "purpose", oldCh.Purpose, newCh.Purpose)
+			compareField(&fields, "key_scenes", joinList(oldCh.KeyScenes), joinList(newCh.KeyScenes))
+			compareField(&fields, "plot_advancement", oldCh.PlotAdvancement, newCh.PlotAdvancement)
+			compareField(&fields, "arc_progress", oldCh.ArcProgress, newCh.ArcProgress)
+			compareField(&fields, "ending_hook", oldCh.EndingHook, newCh.EndingHook)
+			if len(fields) > 0 {
+				changes = append(changes, ChapterChange{Chapter: num, Title: newCh.Title, Kind: ChangeModified, Fields: fields})
+			}
+		}
+	}
+	return changes
+}
+
+// diffScenes 按（章节，场景号）比较场景指令
+func diffScenes(a, b []models.SceneInstruction) []SceneChange {
+	type sceneKey struct{ chapter, scene int }
+	oldByKey := make(map[sceneKey]models.SceneInstruction, len(a))
+	for _, s := range a {
+		oldByKey[sceneKey{s.Chapter, s.Scene}] = s
+	}
+	newByKey := make(map[sceneKey]models.SceneInstruction, len(b))
+	for _, s := range b {
+		newByKey[sceneKey{s.Chapter, s.Scene}] = s
+	}
+
+	keys := make([]sceneKey, 0, len(oldByKey)+len(newByKey))
+	for k := range oldByKey {
+		keys = append(keys, k)
+	}
+	for k := range newByKey {
+		if _, ok := oldByKey[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].chapter != keys[j].chapter {
+			return keys[i].chapter < keys[j].chapter
+		}
+		return keys[i].scene < keys[j].scene
+	})
+
+	changes := make([]SceneChange, 0)
+	for _, k := range keys {
+		oldSc, inOld := oldByKey[k]
+		newSc, inNew := newByKey[k]
+		switch {
+		case !inOld:
+			changes = append(changes, SceneChange{Chapter: k.chapter, Scene: k.scene, Kind: ChangeAdded, Purpose: newSc.Purpose})
+		case !inNew:
+			changes = append(changes, SceneChange{Chapter: k.chapter, Scene: k.scene, Kind: ChangeRemoved, Purpose: oldSc.Purpose})
+		default:
+			fields := make([]FieldChange, 0)
+			compareField(&fields, "purpose", oldSc.Purpose, newSc.Purpose)
+			compareField(&fields, "location", oldSc.Location, newSc.Location)
+			compareField(&fields, "characters", joinList(oldSc.Characters), joinList(newSc.Characters))
+			compareField(&fields, "pov_character", oldSc.POVCharacter, newSc.POVCharacter)
+			compareField(&fields, "action", oldSc.Action, newSc.Action)
+			compareField(&fields, "dialogue_focus", oldSc.DialogueFocus, newSc.DialogueFocus)
+			compareField(&fields, "mood", oldSc.Mood, newSc.Mood)
+			if len(fields) > 0 {
+				changes = append(changes, SceneChange{Chapter: k.chapter, Scene: k.scene, Kind: ChangeModified, Purpose: newSc.Purpose, Fields: fields})
+			}
+		}
+	}
+	return changes
+}
+
+// diffArcs 按角色比较弧光规划
+func diffArcs(a, b map[string]*models.ArcPlan) []ArcChange {
+	ids := make([]string, 0, len(a)+len(b))
+	for id := range a {
+		ids = append(ids, id)
+	}
+	for id := range b {
+		if _, ok := a[id]; !ok {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	changes := make([]ArcChange, 0)
+	for _, id := range ids {
+		oldArc, newArc := a[id], b[id]
+		switch {
+		case oldArc == nil && newArc == nil:
+			continue
+		case oldArc == nil:
+			changes = append(changes, ArcChange{CharacterID: id, Kind: ChangeAdded})
+		case newArc == nil:
+			changes = append(changes, ArcChange{CharacterID: id, Kind: ChangeRemoved})
+		default:
+			fields := make([]FieldChange, 0)
+			compareField(&fields, "arc_type", oldArc.ArcType, newArc.ArcType)
+			compareField(&fields, "start_state.motivation", oldArc.StartState.Motivation, newArc.StartState.Motivation)
+			compareField(&fields, "start_state.emotion", oldArc.StartState.Emotion, newArc.StartState.Emotion)
+			compareField(&fields, "end_state.motivation", oldArc.EndState.Motivation, newArc.EndState.Motivation)
+			compareField(&fields, "end_state.emotion", oldArc.EndState.Emotion, newArc.EndState.Emotion)
+			compareField(&fields, "turning_points", formatTurningPoints(oldArc.TurningPoints), formatTurningPoints(newArc.TurningPoints))
+			if len(fields) > 0 {
+				changes = append(changes, ArcChange{CharacterID: id, Kind: ChangeModified, Fields: fields})
+			}
+		}
+	}
+	return changes
+}
+
+// diffTheme 比较主题规划
+func diffTheme(a, b models.ThemePlan) []FieldChange {
+	changes := make([]FieldChange, 0)
+	compareField(&changes, "core_theme", a.CoreTheme, b.CoreTheme)
+	compareField(&changes, "motifs", joinList(a.Motifs), joinList(b.Motifs))
+
+	symbols := func(list []models.Symbol) string {
+		names := make([]string, 0, len(list))
+		for _, s := range list {
+			names = append(names, s.Name)
+		}
+		return joinList(names)
+	}
+	compareField(&changes, "symbols", symbols(a.Symbols), symbols(b.Symbols))
+	return changes
+}
+
+// compareField 字段不同时记录变化
+func compareField(changes *[]FieldChange, field, oldVal, newVal string) {
+	if oldVal != newVal {
+		*changes = append(*changes, FieldChange{Field: field, Old: oldVal, New: newVal})
+	}
+}
+
+// unionKeys 两个章节集合的章节号并集（升序）
+func unionKeys(a, b map[int]models.ChapterPlan) []int {
+	keys := make([]int, 0, len(a)+len(b))
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Ints(keys)
+	return keys
+}
+
+// joinList 列表转为可比较的字符串
+func joinList(list []string) string {
+	return strings.Join(list, "、")
+}
+
+// formatTurningPoints 转折点转为可比较的字符串
+func formatTurningPoints(points []models.TurningPoint) string {
+	parts := make([]string, 0, len(points))
+	for _, p := range points {
+		parts = append(parts, fmt.Sprintf("第%d章:%s", p.Chapter, p.Event))
+	}
+	return strings.Join(parts, "；")
+}
+
+// changeLabel 变更类型的显示标签
+func changeLabel(kind string) string {
+	switch kind {
+	case ChangeAdded:
+		return "[新增]"
+	case ChangeRemoved:
+		return "[删除]"
+	default:
+		return "[修改]"
+	}
+}
+
+// writeFieldChanges 输出字段变化
+func writeFieldChanges(sb *strings.Builder, fields []FieldChange, indent string) {
+	for _, f := range fields {
+		sb.WriteString(fmt.Sprintf("%s- %s: %q → %q\n", indent, f.Field, f.Old, f.New))
+	}
+}
@@ -0,0 +1,240 @@
+// Package narrative 叙事器 - 结局候选
+// 一次生成多种走向的结局（悲剧、苦乐参半、圆满、开放），对照主题规划分析利弊，由作者选定或融合后再锁定终幕的章节规划
+package narrative
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+)
+
+// 结局候选数量范围
+const (
+	minEndingCandidates = 3
+	maxEndingCandidates = 5
+)
+
+// endingTypes 候选结局的类型顺序，前四种必出，第五种由模型自选走向
+var endingTypes = []string{
+	models.EndingTragic,
+	models.EndingBittersweet,
+	models.EndingTriumphant,
+	models.EndingOpen,
+}
+
+// endingTypeNames 结局类型的中文名
+var endingTypeNames = map[string]string{
+	models.EndingTragic:      "悲剧",
+	models.EndingBittersweet: "苦乐参半",
+	models.EndingTriumphant:  "圆满胜利",
+	models.EndingOpen:        "开放式",
+	models.EndingBlended:     "融合",
+}
+
+// endingOutput 结局候选的 LLM 输出
+type endingOutput struct {
+	Endings []endingItem `json:"endings"`
+}
+
+type endingItem struct {
+	Type       string   `json:"type"`
+	Summary    string   `json:"summary"`
+	Climax     string   `json:"climax"`
+	Resolution string   `json:"resolution"`
+	Pros       []string `json:"pros"`
+	Cons       []string `json:"cons"`
+	ThemeFit   int      `json:"theme_fit"`
+}
+
+func (e endingItem) candidate(id string) models.EndingCandidate {
+	return models.EndingCandidate{
+		ID:         id,
+		Type:       e.Type,
+		Summary:    e.Summary,
+		Climax:     e.Climax,
+		Resolution: e.Resolution,
+		Pros:       e.Pros,
+		Cons:       e.Cons,
+		ThemeFit:   max(0, min(100, e.ThemeFit)),
+	}
+}
+
+// ProposeEndings 为蓝图生成 count 个走向不同的结局候选（3-5 个），不修改蓝图
+func (ne *NarrativeEngine) ProposeEndings(blueprint *models.NarrativeBlueprint, count int) ([]models.EndingCandidate, error) {
+	if count < minEndingCandidates {
+		count = minEndingCandidates
+	}
+	if count > maxEndingCandidates {
+		count = maxEndingCandidates
+	}
+
+	ne.log().Info("生成结局候选", "blueprint_id", blueprint.ID, "count", count)
+	result, err := ne.callWithRetry(ne.buildEndingsPrompt(blueprint, count),
+		`你是一位资深小说结构顾问。不同结局要在冲突的解决方式和主角的得失上真正不同，而不是同一结局换种语气；分析利弊时以故事的主题规划为准绳。`)
+	if err != nil {
+		return nil, fmt.Errorf("生成结局候选失败: %w", err)
+	}
+
+	var output endingOutput
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, fmt.Errorf("解析结局候选失败: %w", err)
+	}
+	if len(output.Endings) == 0 {
+		return nil, fmt.Errorf("没有生成结局候选")
+	}
+
+	candidates := make([]models.EndingCandidate, 0, count)
+	for i, e := range output.Endings {
+		if i >= count {
+			break
+		}
+		if e.Type == "" && i < len(endingTypes) {
+			e.Type = endingTypes[i]
+		}
+		candidates = append(candidates, e.candidate(fmt.Sprintf("ending_%d", i+1)))
+	}
+	return candidates, nil
+}
+
+// BlendEndings 融合多个结局候选，guidance 为作者的融合意见
+func (ne *NarrativeEngine) BlendEndings(blueprint *models.NarrativeBlueprint, candidates []models.EndingCandidate, guidance string) (*models.EndingCandidate, error) {
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("没有可融合的结局候选")
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("# 结局融合\n\n")
+	ne.writeThemeContext(&prompt, blueprint)
+	prompt.WriteString("## 待融合的结局\n")
+	for _, c := range candidates {
+		prompt.WriteString(fmt.Sprintf("### %s（%s）\n高潮：%s\n结局：%s\n", c.Summary, endingTypeName(c.Type), c.Climax, c.Resolution))
+	}
+	if guidance != "" {
+		prompt.WriteString("\n## 作者意见\n" + guidance + "\n")
+	}
+	prompt.WriteString("\n# 要求\n")
+	prompt.WriteString("1. 取各结局中最有力的部分，组合成一个自洽的高潮与结局，不能简单拼接\n")
+	prompt.WriteString("2. 高潮和结局各80-150字\n")
+	prompt.WriteString("3. pros/cons 对照主题规划分析，theme_fit 为0-100的整数\n")
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{"summary": "一句话概括", "climax": "高潮", "resolution": "结局", "pros": ["优点"], "cons": ["缺点"], "theme_fit": 80}`)
+
+	result, err := ne.callWithRetry(prompt.String(), `你是一位资深小说结构顾问，擅长把几种结局方案融合为一个完整的收束。`)
+	if err != nil {
+		return nil, fmt.Errorf("融合结局失败: %w", err)
+	}
+
+	var output endingItem
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, fmt.Errorf("解析融合结局失败: %w", err)
+	}
+	if output.Climax == "" || output.Resolution == "" {
+		return nil, fmt.Errorf("融合结局缺少高潮或结局")
+	}
+	output.Type = models.EndingBlended
+	blended := output.candidate(fmt.Sprintf("ending_%d", len(blueprint.EndingCandidates)+1))
+	return &blended, nil
+}
+
+// FinalActChapter 终幕的起始章节：三幕结构中第三幕约占全书后四分之一
+func FinalActChapter(blueprint *models.NarrativeBlueprint) int {
+	total := len(blueprint.ChapterPlans)
+	if total == 0 {
+		return 0
+	}
+	start := total - total/4
+	if start < 1 {
+		start = 1
+	}
+	return blueprint.ChapterPlans[start-1].Chapter
+}
+
+// EndingOutline 把结局候选整理为分支规划需要收束到的结局描述
+func EndingOutline(ending *models.EndingCandidate) string {
+	return fmt.Sprintf("高潮：%s\n结局：%s", ending.Climax, ending.Resolution)
+}
+
+// buildEndingsPrompt 构建结局候选提示词
+func (ne *NarrativeEngine) buildEndingsPrompt(blueprint *models.NarrativeBlueprint, count int) string {
+	var prompt strings.Builder
+	outline := blueprint.StoryOutline
+
+	prompt.WriteString("# 结局候选设计\n\n")
+	prompt.WriteString("## 故事大纲\n")
+	prompt.WriteString(fmt.Sprintf("开端：%s\n激励事件：%s\n第一幕转折：%s\n", outline.Act1.Setup, outline.Act1.IncitingIncident, outline.Act1.PlotPoint1))
+	prompt.WriteString(fmt.Sprintf("中点：%s\n至暗时刻：%s\n第二幕转折：%s\n", outline.Act2.Midpoint, outline.Act2.AllIsLost, outline.Act2.PlotPoint2))
+	if outline.Act3.Climax != "" {
+		prompt.WriteString(fmt.Sprintf("\n## 当前结局（仅供参考）\n高潮：%s\n结局：%s\n", outline.Act3.Climax, outline.Act3.Resolution))
+	}
+	prompt.WriteString("\n")
+	ne.writeThemeContext(&prompt, blueprint)
+
+	if len(blueprint.CharacterArcs) > 0 {
+		names := make(map[string]string)
+		for _, ch := range ne.db.ListCharactersByWorld(blueprint.WorldID) {
+			names[ch.ID] = ch.Name
+		}
+		prompt.WriteString("## 角色弧光\n")
+		for id, arc := range blueprint.CharacterArcs {
+			if arc == nil {
+				continue
+			}
+			name := names[id]
+			if name == "" {
+				name = id
+			}
+			prompt.WriteString(fmt.Sprintf("- %s（%s）: 动机「%s」，情绪 %s → %s\n", name, arc.ArcType,
+				arc.StartState.Motivation, arc.StartState.Emotion, arc.EndState.Emotion))
+		}
+		prompt.WriteString("\n")
+	}
+
+	types := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		if i < len(endingTypes) {
+			types = append(types, fmt.Sprintf("%s（%s）", endingTypes[i], endingTypeName(endingTypes[i])))
+		} else {
+			types = append(types, "自选一种与前面都不同的走向")
+		}
+	}
+
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString(fmt.Sprintf("1. 给出%d个结局，type 依次为：%s\n", count, strings.Join(types, "、")))
+	prompt.WriteString("2. 每个结局包含高潮（climax）和结局（resolution），各80-150字，写清冲突如何解决、主角得到和失去了什么\n")
+	prompt.WriteString("3. pros/cons 各2-3条，对照主题规划说明该结局如何强化或削弱核心主题\n")
+	prompt.WriteString("4. theme_fit 为该结局与主题规划的契合度，0-100的整数\n")
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "endings": [
+    {"type": "tragic", "summary": "一句话概括", "climax": "高潮", "resolution": "结局", "pros": ["优点"], "cons": ["缺点"], "theme_fit": 70}
+  ]
+}`)
+	return prompt.String()
+}
+
+// writeThemeContext 写入主题规划，结局的利弊以它为准
+func (ne *NarrativeEngine) writeThemeContext(prompt *strings.Builder, blueprint *models.NarrativeBlueprint) {
+	theme := blueprint.ThemePlan
+	if theme.CoreTheme == "" {
+		return
+	}
+	prompt.WriteString("## 主题规划\n")
+	prompt.WriteString("核心主题：" + theme.CoreTheme + "\n")
+	if len(theme.Motifs) > 0 {
+		prompt.WriteString("母题：" + strings.Join(theme.Motifs, "、") + "\n")
+	}
+	for _, s := range theme.Symbols {
+		prompt.WriteString(fmt.Sprintf("象征：%s - %s\n", s.Name, s.Meaning))
+	}
+	prompt.WriteString("\n")
+}
+
+// endingTypeName 结局类型的中文名，未知类型原样返回
+func endingTypeName(t string) string {
+	if name, ok := endingTypeNames[t]; ok {
+		return name
+	}
+	return t
+}
@@ -5,13 +5,18 @@ package narrative
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/config"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/jsonx"
 	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/locale"
+	"github.com/xlei/xupu/pkg/logx"
+	"github.com/xlei/xupu/pkg/promptctx"
 )
 
 // NarrativeStructure 叙事结构类型
@@ -34,6 +39,9 @@ type CreateParams struct {
 	Length     string `json:"length"`      // 篇幅预期：short/medium/long
 	ChapterCount int  `json:"chapter_count"` // 章节数量（可选）
 	Structure   NarrativeStructure `json:"structure"` // 叙事结构（可选，默认三幕剧）
+	Characters  []*UserCharacter   `json:"characters,omitempty"` // 用户预设角色（可选）
+	Genre       Genre              `json:"genre,omitempty"`      // 故事类型流水线（可选，默认通用）
+	BeatSheet   *models.BeatSheet  `json:"beat_sheet,omitempty"` // 自定义节拍表（可选），大纲的关键事件映射到这些节拍上
 }
 
 // OutlineInput 生成大纲输入
@@ -186,6 +194,8 @@ type ChapterPlanItem struct {
 	ArcProgress     string   `json:"arc_progress"`
 	EndingHook      string   `json:"ending_hook"`
 	EstimatedWords  int      `json:"estimated_words"`
+	ConflictIntensity int      `json:"conflict_intensity"`
+	SceneTypes        []string `json:"scene_types"`
 }
 
 // SceneOutput 场景输出
@@ -231,12 +241,14 @@ type NarrativeEngine struct {
 	client  *llm.Client
 	mapping *config.ModuleMapping
 	evolution *EvolutionEngine // 演化引擎
+	logger  *slog.Logger     // 为空时使用全局日志
+	lang    string           // 输出语言，为空时为简体中文
 }
 
 // New 创建叙事器
 func New() (*NarrativeEngine, error) {
 	// 加载配置
-	cfg, err := config.Load("config/config.yaml")
+	cfg, err := config.Current()
 	if err != nil {
 		return nil, fmt.Errorf("加载配置失败: %w", err)
 	}
@@ -262,6 +274,66 @@ func New() (*NarrativeEngine, error) {
 	}, nil
 }
 
+// WithLogger 返回使用指定日志的叙事器副本，演化引擎和LLM客户端一并切换，用于按请求/任务串联日志
+func (ne *NarrativeEngine) WithLogger(l *slog.Logger) *NarrativeEngine {
+	cp := *ne
+	cp.logger = l
+	if ne.client != nil {
+		cp.client = ne.client.WithLogger(l)
+	}
+	if ne.evolution != nil {
+		cp.evolution = ne.evolution.WithLogger(l)
+	}
+	return &cp
+}
+
+// WithMeter 返回把LLM用量记到指定计量器的叙事器副本，演化引擎一并切换
+func (ne *NarrativeEngine) WithMeter(m *llm.Meter) *NarrativeEngine {
+	cp := *ne
+	if ne.client != nil {
+		cp.client = ne.client.WithMeter(m)
+	}
+	if ne.evolution != nil {
+		cp.evolution = ne.evolution.WithMeter(m)
+	}
+	return &cp
+}
+
+// WithBudget 返回请求前检查指定预算的叙事器副本，演化引擎一并切换
+func (ne *NarrativeEngine) WithBudget(b llm.Budget) *NarrativeEngine {
+	cp := *ne
+	if ne.client != nil {
+		cp.client = ne.client.WithBudget(b)
+	}
+	if ne.evolution != nil {
+		cp.evolution = ne.evolution.WithBudget(b)
+	}
+	return &cp
+}
+
+// WithLanguage 返回按指定语言输出的叙事器副本，演化引擎一并切换，章节标题等结构性用语随之本地化
+func (ne *NarrativeEngine) WithLanguage(lang string) *NarrativeEngine {
+	cp := *ne
+	cp.lang = lang
+	if ne.client != nil {
+		cp.client = ne.client.WithInstruction(locale.Instruction(lang))
+	}
+	if ne.evolution != nil {
+		cp.evolution = ne.evolution.WithLanguage(lang)
+	}
+	return &cp
+}
+
+// terms 输出语言的结构性用语
+func (ne *NarrativeEngine) terms() locale.Terms {
+	return locale.For(ne.lang)
+}
+
+// log 当前使用的日志
+func (ne *NarrativeEngine) log() *slog.Logger {
+	return logx.Or(ne.logger)
+}
+
 // EvolutionConfig 演化配置
 type EvolutionConfig struct {
 	EnableEvolution bool              `json:"enable_evolution"` // 是否启用动态演化
@@ -279,6 +351,13 @@ func (ne *NarrativeEngine) CreateBlueprintThroughEvolution(params CreateParams,
 		return nil, nil, fmt.Errorf("创建演化状态失败: %w", err)
 	}
 
+	// 注入用户预设角色，以及角色卡中带锁定字段的角色
+	characters := append([]*UserCharacter(nil), params.Characters...)
+	characters = append(characters, ne.lockedSheetCharacters(params.WorldID, params.Characters)...)
+	if err := evolutionState.InjectCharacters(characters); err != nil {
+		return nil, nil, fmt.Errorf("注入预设角色失败: %w", err)
+	}
+
 	// 设置演化配置
 	if config.MaxRounds > 0 {
 		evolutionState.MaxRounds = config.MaxRounds
@@ -290,19 +369,8 @@ func (ne *NarrativeEngine) CreateBlueprintThroughEvolution(params CreateParams,
 	if config.EnableEvolution {
 		roundTypes := config.RoundTypes
 		if len(roundTypes) == 0 {
-			// 默认演化序列
-			roundTypes = []EvolutionRound{
-				RoundCharacterCreation,  // 角色创建
-				RoundConflictDesign,     // 冲突设计
-				RoundCharacterDeepen,    // 角色深化
-				RoundConflictEvolution,  // 冲突演化
-				RoundForeshadowPlant,    // 种下伏笔
-				RoundThemeDeepen,        // 主题深化
-				RoundConflictEvolution,  // 冲突再演化
-				RoundForeshadowWeave,    // 编织伏笔
-				RoundPlotTwist,          // 情节转折
-				RoundResolutionPlan,     // 结局规划
-			}
+			// 按故事类型选择演化序列
+			roundTypes = PipelineForGenre(params.Genre).Rounds
 		}
 
 		for _, roundType := range roundTypes {
@@ -317,20 +385,29 @@ func (ne *NarrativeEngine) CreateBlueprintThroughEvolution(params CreateParams,
 
 			evolutionResults = append(evolutionResults, result)
 
-			// 检查自动停止条件
-			if config.AutoStopWhen > 0 && result.QualityScore >= config.AutoStopWhen {
-				break
+			// 检查自动停止条件：所有核心维度都经LLM评审且综合分达标才提前结束
+			if config.AutoStopWhen > 0 {
+				if overall, ready := evolutionState.OverallQuality(); ready && overall >= config.AutoStopWhen {
+					ne.log().Info("综合质量达标，提前结束演化", "overall", overall, "auto_stop_when", config.AutoStopWhen)
+					break
+				}
 			}
 		}
 	}
 
 	// 3. 基于演化状态生成叙事蓝图
+	evolutionState.enforceLocks()
 	blueprint := ne.buildBlueprintFromEvolution(evolutionState, params)
+	if err := evolutionState.takeDegradeErr(); err != nil {
+		return nil, nil, fmt.Errorf("构建叙事蓝图失败: %w", err)
+	}
+	blueprint.Warnings = evolutionState.Warnings
 
 	// 4. 保存到数据库
 	if err := ne.db.SaveNarrativeBlueprint(blueprint); err != nil {
 		return nil, nil, fmt.Errorf("保存叙事蓝图失败: %w", err)
 	}
+	ne.saveCharacterSheets(evolutionState, blueprint)
 
 	return blueprint, evolutionState, nil
 }
@@ -345,31 +422,35 @@ func (ne *NarrativeEngine) buildBlueprintFromEvolution(state *EvolutionState, pa
 	}
 
 	// 1. 从冲突系统生成故事大纲
-	fmt.Println("  📚 构建故事大纲...")
 	blueprint.StoryOutline = ne.buildOutlineFromConflicts(state)
-	fmt.Println("  ✓ 故事大纲完成")
+	ne.log().Info("故事大纲完成", "structure", blueprint.StoryOutline.StructureType)
 
 	// 2. 从演化状态生成章节规划
 	chapterCount := params.ChapterCount
 	if chapterCount == 0 {
 		chapterCount = ne.defaultChapterCount(params.Length)
 	}
-	fmt.Printf("  📖 生成 %d 章规划...\n", chapterCount)
 	blueprint.ChapterPlans = ne.buildChapterPlansFromEvolution(state, chapterCount)
-	fmt.Println("  ✓ 章节规划完成")
+	ne.log().Info("章节规划完成", "chapters", len(blueprint.ChapterPlans))
+
+	// 使用自定义节拍表时，把关键事件映射到节拍上
+	if params.BeatSheet != nil {
+		blueprint.StoryOutline.StructureType = params.BeatSheet.ID
+		blueprint.StoryOutline.Beats = MapBeatSheet(blueprint.StoryOutline, params.BeatSheet, len(blueprint.ChapterPlans))
+		ne.log().Info("节拍映射完成", "beat_sheet", params.BeatSheet.Name, "beats", len(blueprint.StoryOutline.Beats))
+	}
 
 	// 3. 从角色状态生成场景指令
 	blueprint.Scenes = ne.buildScenesFromEvolution(state, blueprint.ChapterPlans)
 
 	// 4. 从角色情感系统生成角色弧光
-	fmt.Println("  👥 构建角色弧光...")
 	blueprint.CharacterArcs = ne.buildCharacterArcsFromEvolution(state)
-	fmt.Println("  ✓ 角色弧光完成")
+	blueprint.VoiceProfiles = voiceProfilesFromEvolution(state)
+	ne.log().Info("角色弧光完成", "arcs", len(blueprint.CharacterArcs))
 
 	// 5. 从主题演化生成主题计划
-	fmt.Println("  🎨 构建主题规划...")
 	blueprint.ThemePlan = ne.buildThemePlanFromEvolution(state)
-	fmt.Println("  ✓ 主题规划完成")
+	ne.log().Info("主题规划完成", "core_theme", blueprint.ThemePlan.CoreTheme)
 
 	return blueprint
 }
@@ -387,6 +468,7 @@ func (ne *NarrativeEngine) buildOutlineFromConflicts(state *EvolutionState) mode
 
 	// 如果没有冲突，返回默认大纲
 	if len(mainConflict.EvolutionPath) == 0 {
+		ne.evolution.degrade(state, "outline", "主要冲突没有演化路径，使用默认大纲")
 		return ne.createDefaultOutline(state)
 	}
 
@@ -715,6 +797,11 @@ func (ne *NarrativeEngine) buildChapterPlansFromEvolution(state *EvolutionState,
 			EndingHook:      plan.EndingHook,
 			WordCount:       plan.EstimatedWords,
 			Status:          "pending",
+			ConflictIntensity: plan.ConflictIntensity,
+			SceneTypes:        plan.SceneTypes,
+		}
+		if plans[i].ConflictIntensity <= 0 {
+			plans[i].ConflictIntensity = state.conflictIntensityAt(i, chapterCount)
 		}
 	}
 
@@ -731,19 +818,19 @@ func (ne *NarrativeEngine) generateChapterPlansWithLLM(state *EvolutionState, ch
 	result, err := ne.callWithRetry(prompt, systemPrompt)
 	if err != nil {
 		// LLM失败时返回简化版本
+		ne.evolution.degrade(state, "chapter_plans", "章节规划生成失败，使用简化章节规划: %v", err)
 		return ne.createFallbackChapterPlans(chapterCount)
 	}
 
 	// 解析输出
 	var output ChapterPlanOutput
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		extracted := extractJSON(result)
-		if err := json.Unmarshal([]byte(extracted), &output); err != nil {
-			return ne.createFallbackChapterPlans(chapterCount)
-		}
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		ne.evolution.degrade(state, "chapter_plans", "解析章节规划失败，使用简化章节规划: %v", err)
+		return ne.createFallbackChapterPlans(chapterCount)
 	}
 
 	if len(output.Chapters) == 0 {
+		ne.evolution.degrade(state, "chapter_plans", "章节规划输出为空，使用简化章节规划")
 		return ne.createFallbackChapterPlans(chapterCount)
 	}
 
@@ -753,7 +840,7 @@ func (ne *NarrativeEngine) generateChapterPlansWithLLM(state *EvolutionState, ch
 		for i := len(output.Chapters); i < chapterCount; i++ {
 			output.Chapters = append(output.Chapters, ChapterPlanItem{
 				Chapter:         i + 1,
-				Title:           fmt.Sprintf("第%d章", i+1),
+				Title:           ne.terms().Chapter(i + 1),
 				Purpose:         "章节发展",
 				KeyScenes:       []string{"关键场景"},
 				PlotAdvancement: "情节推进",
@@ -773,7 +860,7 @@ func (ne *NarrativeEngine) createFallbackChapterPlans(chapterCount int) []Chapte
 	for i := 0; i < chapterCount; i++ {
 		plans[i] = ChapterPlanItem{
 			Chapter:         i + 1,
-			Title:           fmt.Sprintf("第%d章", i+1),
+			Title:           ne.terms().Chapter(i + 1),
 			Purpose:         "本章推动情节发展",
 			KeyScenes:       []string{"开场场景", "发展场景", "转折场景"},
 			PlotAdvancement: "主要情节向前推进",
@@ -814,28 +901,15 @@ func (ne *NarrativeEngine) buildChapterPlanPrompt(state *EvolutionState, chapter
 		prompt.WriteString("\n")
 	}
 
-	// 核心冲突
-	if len(state.Conflicts) > 0 {
-		prompt.WriteString("\n## 核心冲突\n")
-		for i, c := range state.Conflicts {
-			prompt.WriteString(fmt.Sprintf("%d. %s: %s (强度:%d)\n", i+1, c.Type, c.CoreQuestion, c.CurrentIntensity))
-			if len(c.EvolutionPath) > 0 {
-				prompt.WriteString(fmt.Sprintf("   演化路径: %s", c.EvolutionPath[0].Description))
-				for j := 1; j < len(c.EvolutionPath); j++ {
-					prompt.WriteString(fmt.Sprintf(" → %s", c.EvolutionPath[j].Description))
-				}
-				prompt.WriteString("\n")
-			}
-		}
-	}
+	// 核心冲突与主要角色
+	ctx := promptContext(state)
+	prompt.WriteString(promptctx.Conflicts(promptctx.ConflictOptions{Detail: true}).Render(ctx))
+	prompt.WriteString(promptctx.Characters(promptctx.CharacterOptions{Full: true}).Render(ctx))
 
-	// 主要角色
-	if len(state.Characters) > 0 {
-		prompt.WriteString("\n## 主要角色\n")
-		for _, char := range state.Characters {
-			prompt.WriteString(fmt.Sprintf("- %s (%s): 欲望=%s, 需求=%s, 恐惧=%s\n",
-				char.Name, char.Role, char.Desires.ConsciousWant, char.Desires.UnconsciousNeed, char.Desires.Fear))
-		}
+	// 类型专属规划（线索、感情节拍等，需要落到具体章节）
+	if beats := state.genreBeatsSection(); beats != "" {
+		prompt.WriteString("\n## 类型专属规划（请安排到具体章节）\n")
+		prompt.WriteString(beats)
 	}
 
 	// 伏笔（已在演化中种下）
@@ -870,6 +944,7 @@ func (ne *NarrativeEngine) buildChapterPlanPrompt(state *EvolutionState, chapter
 	prompt.WriteString("5. 说明角色弧光如何发展\n")
 	prompt.WriteString("6. 每章结尾有吸引读者继续阅读的悬念\n")
 	prompt.WriteString("7. 考虑伏笔回收和情节钩子的利用\n")
+	prompt.WriteString("8. 标注每章的冲突强度（0-100）和每个关键场景的类型（对话/动作/内心/过渡/描写），张力整体上升，中段要有起伏\n")
 
 	prompt.WriteString("\n# 输出格式（JSON）\n")
 	prompt.WriteString(`{
@@ -882,7 +957,9 @@ func (ne *NarrativeEngine) buildChapterPlanPrompt(state *EvolutionState, chapter
       "plot_advancement": "情节如何推进",
       "arc_progress": "角色弧光如何发展",
       "ending_hook": "结尾悬念",
-      "estimated_words": 5000
+      "estimated_words": 5000,
+      "conflict_intensity": 40,
+      "scene_types": ["对话", "动作", "内心"]
     }
   ]
 }`)
@@ -897,7 +974,7 @@ func (ne *NarrativeEngine) buildScenesFromEvolution(state *EvolutionState, plans
 
 	totalScenes := len(plans) * (3 + len(state.Characters)/2)
 
-	fmt.Printf("  🎬 开始生成 %d 个场景...\n", totalScenes)
+	ne.log().Info("开始生成场景", "total", totalScenes)
 
 	sceneIndex := 0
 	for chapterIdx := 0; chapterIdx < len(plans); chapterIdx++ {
@@ -909,7 +986,7 @@ func (ne *NarrativeEngine) buildScenesFromEvolution(state *EvolutionState, plans
 			globalSequence++ // 全局序号递增
 
 			if sceneIndex%5 == 1 || sceneIndex == totalScenes {
-				fmt.Printf("    [%d/%d] 生成第%d章场景%d...\n", sceneIndex, totalScenes, plan.Chapter, i+1)
+				ne.log().Debug("生成场景", "index", sceneIndex, "total", totalScenes, "chapter", plan.Chapter, "scene", i+1)
 			}
 
 			scene := models.SceneInstruction{
@@ -926,11 +1003,14 @@ func (ne *NarrativeEngine) buildScenesFromEvolution(state *EvolutionState, plans
 				Mood:           ne.determineSceneMood(state, plan.Chapter, i),
 				Status:         "pending",
 			}
+			if state.WorldContext != nil {
+				scene.EntityRefs = state.WorldContext.EntityRefs(scene.Location + "\n" + scene.Action)
+			}
 			scenes = append(scenes, scene)
 		}
 	}
 
-	fmt.Printf("  ✓ 场景生成完成\n")
+	ne.log().Info("场景生成完成", "scenes", len(scenes))
 	return scenes
 }
 
@@ -1167,6 +1247,15 @@ func (s *EvolutionState) findMainConflict() *ConflictThread {
 	return mainConflict
 }
 
+// conflictIntensityAt 章节规划未给出冲突强度时，按章节在全书中的位置取主要冲突对应演化阶段的强度
+func (s *EvolutionState) conflictIntensityAt(index, chapterCount int) int {
+	path := s.findMainConflict().EvolutionPath
+	if len(path) == 0 || chapterCount <= 0 {
+		return 0
+	}
+	return path[min(len(path)-1, index*len(path)/chapterCount)].Intensity
+}
+
 // getConflictForChapter 获取指定章节的主要冲突
 func (s *EvolutionState) getConflictForChapter(chapter int) *ConflictThread {
 	if len(s.Conflicts) == 0 {
@@ -1232,7 +1321,7 @@ func (ne *NarrativeEngine) estimateChapterWords(length string) int {
 
 func (ne *NarrativeEngine) determineScenePurpose(state *EvolutionState, chapter, sceneIndex int) string {
 	// 获取章节信息
-	chapterTitle := fmt.Sprintf("第%d章", chapter)
+	chapterTitle := ne.terms().Chapter(chapter)
 
 	// 获取冲突信息
 	conflictInfo := ""
@@ -1571,6 +1660,13 @@ func (ne *NarrativeEngine) CreateBlueprint(params CreateParams) (*models.Narrati
 		AutoStopWhen:    85, // 质量达到85分时停止
 	}
 
+	// 类型流水线完整执行，不受默认轮次上限限制
+	if params.Genre != GenreGeneral {
+		pipeline := PipelineForGenre(params.Genre)
+		evolutionConfig.RoundTypes = pipeline.Rounds
+		evolutionConfig.MaxRounds = len(pipeline.Rounds)
+	}
+
 	blueprint, _, err := ne.CreateBlueprintThroughEvolution(params, evolutionConfig)
 	if err != nil {
 		return nil, fmt.Errorf("动态演化失败: %w", err)
@@ -1665,6 +1761,22 @@ func (ne *NarrativeEngine) buildWorldSummary(world *models.WorldSetting) string
 		summary += "\n"
 	}
 
+	// 实体索引：场景中引用地点、种族、宗教等时可注明实体ID
+	if len(world.Entities) > 0 {
+		summary += "【实体索引】"
+		for i, e := range world.Entities {
+			if i >= 20 {
+				summary += fmt.Sprintf(" 等%d个", len(world.Entities))
+				break
+			}
+			if i > 0 {
+				summary += ", "
+			}
+			summary += fmt.Sprintf("%s(%s)", e.Name, e.ID)
+		}
+		summary += "\n"
+	}
+
 	return summary
 }
 
@@ -1725,20 +1837,13 @@ func (ne *NarrativeEngine) updatePreviousSummary(chapter models.ChapterPlan, sce
 }
 
 // callWithRetry 调用LLM并自动重试
+// 提示词和响应由LLM客户端按 debug 级别记录，这里只记录重试
 func (ne *NarrativeEngine) callWithRetry(prompt, systemPrompt string) (string, error) {
-	retryConfig := ne.cfg.System.Retry
+	retryConfig := config.Get().System.Retry // 每次读取全局配置，热加载后立即生效
 	maxAttempts := retryConfig.MaxAttempts
 	var lastErr error
 
-	fmt.Println("\n========== LLM DEBUG (JSON) ==========")
-	fmt.Printf("System Prompt:\n%s\n\n", systemPrompt)
-	fmt.Printf("User Prompt:\n%s\n", truncateForDebug(prompt, 2000))
-	fmt.Println("====================================")
-
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		fmt.Printf("🔄 尝试 %d/%d...\n", attempt, maxAttempts)
-		startTime := time.Now()
-
 		result, err := ne.client.GenerateJSONWithParams(
 			prompt,
 			systemPrompt,
@@ -1746,22 +1851,14 @@ func (ne *NarrativeEngine) callWithRetry(prompt, systemPrompt string) (string, e
 			ne.mapping.MaxTokens,
 		)
 
-		elapsed := time.Since(startTime)
-		fmt.Printf("⏱️  耗时: %.1f秒\n", elapsed.Seconds())
-
 		if err == nil {
 			jsonBytes, err := json.Marshal(result)
 			if err != nil {
-				fmt.Printf("❌ 序列化结果失败: %v\n", err)
 				return "", fmt.Errorf("序列化结果失败: %w", err)
 			}
-			fmt.Printf("✅ 响应成功\n")
-			fmt.Printf("Response:\n%s\n", truncateForDebug(string(jsonBytes), 3000))
-			fmt.Println("====================================\n")
 			return string(jsonBytes), nil
 		}
 
-		fmt.Printf("❌ 调用失败: %v\n", err)
 		lastErr = err
 
 		if attempt < maxAttempts {
@@ -1769,84 +1866,15 @@ func (ne *NarrativeEngine) callWithRetry(prompt, systemPrompt string) (string, e
 			if delay > time.Duration(retryConfig.MaxDelay)*time.Second {
 				delay = time.Duration(retryConfig.MaxDelay) * time.Second
 			}
-			fmt.Printf("⏳ 等待 %.1f 秒后重试...\n", delay.Seconds())
+			ne.log().Warn("LLM调用失败，等待后重试", "attempt", attempt, "max_attempts", maxAttempts, "delay", delay, "error", err)
 			time.Sleep(delay)
 		}
 	}
 
-	fmt.Printf("❌ LLM调用失败（重试%d次后）: %v\n", maxAttempts, lastErr)
-	fmt.Println("====================================\n")
+	ne.log().Error("LLM调用失败", "attempts", maxAttempts, "error", lastErr)
 	return "", fmt.Errorf("LLM调用失败（重试%d次后）: %w", maxAttempts, lastErr)
 }
 
-// extractJSON 从文本中提取JSON内容
-func extractJSON(s string) string {
-	// 查找 ```json```
-	start := -1
-	end := -1
-
-	jsonStart := []byte("```json")
-	if idx := indexOf(s, jsonStart); idx >= 0 {
-		start = idx + len(jsonStart)
-		if idx := indexOf(s[start:], []byte("```")); idx >= 0 {
-			end = start + idx
-			return s[start:end]
-		}
-	}
-
-	// 查找 ````
-	if idx := indexOf(s, []byte("```")); idx >= 0 {
-		start = idx + 3
-		if idx := indexOf(s[start:], []byte("```")); idx >= 0 {
-			end = start + idx
-			return s[start:end]
-		}
-	}
-
-	// 查找 { }
-	if idx := indexOf(s, []byte("{")); idx >= 0 {
-		start = idx
-		if idx := lastIndexOf(s, []byte("}")); idx >= 0 {
-			end = idx + 1
-			return s[start:end]
-		}
-	}
-
-	return s
-}
-
-func indexOf(s string, sep []byte) int {
-	for i := 0; i <= len(s)-len(sep); i++ {
-		match := true
-		for j := 0; j < len(sep); j++ {
-			if s[i+j] != sep[j] {
-				match = false
-				break
-			}
-		}
-		if match {
-			return i
-		}
-	}
-	return -1
-}
-
-func lastIndexOf(s string, sep []byte) int {
-	for i := len(s) - len(sep); i >= 0; i-- {
-		match := true
-		for j := 0; j < len(sep); j++ {
-			if s[i+j] != sep[j] {
-				match = false
-				break
-			}
-		}
-		if match {
-			return i
-		}
-	}
-	return -1
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
@@ -1878,40 +1906,10 @@ func (ne *NarrativeEngine) getMainCharacters(state *EvolutionState, maxCount int
 
 // callLLM 调用LLM的辅助函数
 func (ne *NarrativeEngine) callLLM(prompt string) (string, error) {
-	fmt.Println("\n========== LLM DEBUG (TEXT) ==========")
-	fmt.Printf("User Prompt:\n%s\n", truncateForDebug(prompt, 2000))
-	fmt.Println("====================================")
-
-	fmt.Println("🔄 调用LLM...")
-	startTime := time.Now()
-
-	response, err := ne.client.GenerateWithParams(
+	return ne.client.GenerateWithParams(
 		prompt,
 		"", // 系统提示，可以留空
 		ne.mapping.Temperature,
 		ne.mapping.MaxTokens,
 	)
-
-	elapsed := time.Since(startTime)
-	fmt.Printf("⏱️  耗时: %.1f秒\n", elapsed.Seconds())
-
-	if err != nil {
-		fmt.Printf("❌ 调用失败: %v\n", err)
-		fmt.Println("====================================\n")
-		return "", err
-	}
-
-	fmt.Printf("✅ 响应成功\n")
-	fmt.Printf("Response:\n%s\n", truncateForDebug(response, 3000))
-	fmt.Println("====================================\n")
-
-	return response, nil
-}
-
-// truncateForDebug 截断过长的调试输出
-func truncateForDebug(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen] + "\n... (截断，总长度: " + fmt.Sprintf("%d", len(s)) + " 字符)"
 }
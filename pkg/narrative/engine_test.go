@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
 )
 
 // MockDatabase 模拟数据库
@@ -282,9 +283,9 @@ func TestExtractJSON(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			got := extractJSON(tt.input)
+			got := jsonx.Extract(tt.input)
 			if !contains(got, tt.contains) {
-				t.Errorf("extractJSON() = %q, want to contain %q", got, tt.contains)
+				t.Errorf("jsonx.Extract() = %q, want to contain %q", got, tt.contains)
 			}
 		})
 	}
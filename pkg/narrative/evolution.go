@@ -5,13 +5,19 @@ package narrative
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/ctxbudget"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/jsonx"
 	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/locale"
+	"github.com/xlei/xupu/pkg/logx"
+	"github.com/xlei/xupu/pkg/promptctx"
 )
 
 // ============================================
@@ -38,6 +44,9 @@ type EvolutionState struct {
 	// 新增：伏笔计划（在生成大纲之前规划）
 	ForeshadowPlan   []*ForeshadowPlan         `json:"foreshadow_plan"`   // 伏笔计划
 
+	// 新增：线索台账（悬疑类型，与伏笔计划并行）
+	ClueLedger *ClueLedger `json:"clue_ledger,omitempty"` // 线索台账
+
 	// 新增：故事架构（在阶段1确定）
 	StoryArchitecture *StoryArchitecture        `json:"story_architecture"` // 故事架构
 
@@ -49,6 +58,31 @@ type EvolutionState struct {
 
 	// 新增：角色演化追踪
 	CharacterEvolution map[string]*CharacterEvolutionTracker `json:"character_evolution"` // 角色演化追踪
+
+	// 新增：收尾规划（高潮之后的尾声章节）
+	Denouement *DenouementPlan `json:"denouement,omitempty"` // 收尾规划
+
+	// 新增：副线规划发现的问题（休眠过久、张力超预算、强制收束）
+	SubplotWarnings []SubplotWarning `json:"subplot_warnings,omitempty"` // 副线问题
+
+	// 新增：用户预设角色
+	UserCharacters []string `json:"user_characters,omitempty"` // 预设角色ID
+	UserCenter     string   `json:"user_center,omitempty"`     // 用户指定的关系网络中心
+	LockedCharacters map[string]*CharacterState `json:"locked_characters,omitempty"` // 带锁定字段的角色的用户设定
+
+	// 新增：已生成的章节细纲（重新生成时保留定稿场景）
+	DetailOutlines map[int]*ChapterDetailOutline `json:"detail_outlines,omitempty"` // 章节细纲
+
+	// 新增：类型专属规划（线索、红鲱鱼、感情节拍等）
+	GenreBeats []*GenreBeat `json:"genre_beats,omitempty"` // 类型规划条目
+
+	// 新增：各维度最近一次质量评审
+	Critiques map[CriticAspect]*Critique `json:"critiques,omitempty"` // 质量评审
+
+	// 新增：降级警告（使用了兜底内容的环节）
+	Warnings []models.DegradationWarning `json:"warnings,omitempty"` // 降级警告
+
+	degradeErr error // fail_fast 策略下本轮记录的失败，由 Evolve 返回
 }
 
 // EvolutionLogEntry 演化日志条目
@@ -91,6 +125,8 @@ type CharacterState struct {
 	ArcProgress     float64             `json:"arc_progress"`     // 弧光进度 0-1
 	InternalConflicts []string          `json:"internal_conflicts"` // 内在冲突
 	Secrets         []string            `json:"secrets"`          // 秘密
+	Voice           *models.VoiceProfile `json:"voice,omitempty"`  // 语音档案
+	Locked          []string            `json:"locked,omitempty"` // 用户锁定的字段（同 models.CharacterSheetFields），演化不会改写
 }
 
 // EmotionalSystem 情感系统
@@ -191,6 +227,8 @@ type PlotEvent struct {
 	Chapter     int       `json:"chapter"`
 	Description string    `json:"description"`
 	Reversal    bool      `json:"reversal"` // 是否是逆转点
+	Tension     int       `json:"tension"`  // 事件后的线程张力 0-100
+	Resolves    bool      `json:"resolves,omitempty"` // 是否收束该线程
 }
 
 // ============================================
@@ -231,11 +269,12 @@ type EvolutionEngine struct {
 	cfg    *config.Config
 	client *llm.Client
 	mapping *config.ModuleMapping
+	logger *slog.Logger // 为空时使用全局日志
 }
 
 // NewEvolutionEngine 创建演化引擎
 func NewEvolutionEngine() (*EvolutionEngine, error) {
-	cfg, err := config.Load("config/config.yaml")
+	cfg, err := config.Current()
 	if err != nil {
 		return nil, fmt.Errorf("加载配置失败: %w", err)
 	}
@@ -253,6 +292,51 @@ func NewEvolutionEngine() (*EvolutionEngine, error) {
 	}, nil
 }
 
+// WithLogger 返回使用指定日志的演化引擎副本，LLM客户端一并切换，用于按请求/任务串联日志
+func (ee *EvolutionEngine) WithLogger(l *slog.Logger) *EvolutionEngine {
+	cp := *ee
+	cp.logger = l
+	if ee.client != nil {
+		cp.client = ee.client.WithLogger(l)
+	}
+	return &cp
+}
+
+// WithMeter 返回把LLM用量记到指定计量器的演化引擎副本
+func (ee *EvolutionEngine) WithMeter(m *llm.Meter) *EvolutionEngine {
+	cp := *ee
+	if ee.client != nil {
+		cp.client = ee.client.WithMeter(m)
+	}
+	return &cp
+}
+
+// WithBudget 返回请求前检查指定预算的演化引擎副本
+func (ee *EvolutionEngine) WithBudget(b llm.Budget) *EvolutionEngine {
+	cp := *ee
+	if ee.client != nil {
+		cp.client = ee.client.WithBudget(b)
+	}
+	return &cp
+}
+
+// WithLanguage 返回按指定语言输出的演化引擎副本
+func (ee *EvolutionEngine) WithLanguage(lang string) *EvolutionEngine {
+	cp := *ee
+	if ee.client != nil {
+		cp.client = ee.client.WithInstruction(locale.Instruction(lang))
+	}
+	return &cp
+}
+
+// log 当前使用的日志
+func (ee *EvolutionEngine) log() *slog.Logger {
+	if ee == nil {
+		return logx.L()
+	}
+	return logx.Or(ee.logger)
+}
+
 // CreateEvolutionState 创建初始演化状态
 func (ee *EvolutionEngine) CreateEvolutionState(worldID string) (*EvolutionState, error) {
 	world, err := ee.db.GetWorld(worldID)
@@ -288,6 +372,7 @@ func (ee *EvolutionEngine) CreateEvolutionState(worldID string) (*EvolutionState
 // Evolve 执行一轮演化
 func (ee *EvolutionEngine) Evolve(state *EvolutionState, roundType EvolutionRound) (*EvolutionResult, error) {
 	state.CurrentRound++
+	warnFrom := len(state.Warnings)
 	var result *EvolutionResult
 	var err error
 
@@ -306,19 +391,34 @@ func (ee *EvolutionEngine) Evolve(state *EvolutionState, roundType EvolutionRoun
 		result, err = ee.evolveThemeDeepen(state)
 	case RoundPlotTwist:
 		result, err = ee.evolvePlotTwist(state)
+	case RoundCultivationLadder, RoundRelationshipBeat, RoundClueDesign, RoundRedHerring, RoundTechPremise:
+		result, err = ee.evolveGenreRound(state, roundType)
 	default:
 		result, err = ee.evolveGeneral(state)
 	}
 
+	if err == nil {
+		err = state.takeDegradeErr()
+	}
 	if err != nil {
 		return nil, err
 	}
+	state.enforceLocks()
 
 	// 更新叙事深度
 	if state.CurrentRound%3 == 0 && state.NarrativeDepth < 10 {
 		state.NarrativeDepth++
 	}
 
+	// 附上本轮评审和综合评分
+	if aspect, ok := roundAspects[roundType]; ok {
+		result.Critique = state.Critiques[aspect]
+	}
+	result.OverallScore, _ = state.OverallQuality()
+	if len(state.Warnings) > warnFrom {
+		result.Warnings = append([]models.DegradationWarning(nil), state.Warnings[warnFrom:]...)
+	}
+
 	// 记录日志
 	state.logAction(state.CurrentRound, string(roundType), result.Summary, result.Changes)
 
@@ -333,6 +433,9 @@ type EvolutionResult struct {
 	Changes    []string           `json:"changes"`
 	NewContent *EvolutionNewContent `json:"new_content"`
 	QualityScore int              `json:"quality_score"` // 故事性质量评分 0-100
+	Critique     *Critique        `json:"critique,omitempty"` // 本轮评审（分项得分与改进建议）
+	OverallScore int              `json:"overall_score"`      // 各维度综合评分 0-100
+	Warnings     []models.DegradationWarning `json:"warnings,omitempty"` // 本轮降级警告，非空表示部分内容为兜底占位
 }
 
 // EvolutionNewContent 演化产生的新内容
@@ -349,7 +452,31 @@ type EvolutionNewContent struct {
 // evolveCharacterCreation 角色创建演化
 func (ee *EvolutionEngine) evolveCharacterCreation(state *EvolutionState) (*EvolutionResult, error) {
 	// 从世界设定中提取角色模板
-	characterTemplates := ee.extractCharacterTemplates(state.WorldContext)
+	characterTemplates := ee.extractCharacterTemplates(state)
+
+	// 用户预设角色占用名额，只补齐剩余角色
+	if len(state.UserCharacters) > 0 {
+		userNames := make(map[string]bool, len(state.UserCharacters))
+		for _, id := range state.UserCharacters {
+			if char, ok := state.Characters[id]; ok {
+				userNames[char.Name] = true
+			}
+		}
+		remaining := make([]models.Race, 0, len(characterTemplates))
+		for _, template := range characterTemplates {
+			if !userNames[template.Name] {
+				remaining = append(remaining, template)
+			}
+		}
+		slots := len(characterTemplates) - len(state.UserCharacters)
+		if slots < 0 {
+			slots = 0
+		}
+		if len(remaining) > slots {
+			remaining = remaining[:slots]
+		}
+		characterTemplates = remaining
+	}
 
 	// 为每个角色创建完整的情感系统
 	for _, template := range characterTemplates {
@@ -543,10 +670,7 @@ func (ee *EvolutionEngine) evolveGeneral(state *EvolutionState) (*EvolutionResul
 		NextSteps    []string `json:"next_steps"`
 	}
 
-	if err := json.Unmarshal([]byte(result), &evolutionAdvice); err != nil {
-		extracted := extractJSON(result)
-		json.Unmarshal([]byte(extracted), &evolutionAdvice)
-	}
+	jsonx.Unmarshal(result, &evolutionAdvice)
 
 	return &EvolutionResult{
 		Round:   state.CurrentRound,
@@ -574,18 +698,22 @@ func (s *EvolutionState) logAction(round int, action, details string, changes []
 // 以下方法需要调用LLM实现（简化版本）
 // extractCharacterTemplates 从世界设定中提取角色模板
 // 如果世界设定中没有种族信息，则通过LLM生成角色概念
-func (ee *EvolutionEngine) extractCharacterTemplates(world *models.WorldSetting) []models.Race {
+func (ee *EvolutionEngine) extractCharacterTemplates(state *EvolutionState) []models.Race {
+	world := state.WorldContext
+
 	// 如果已有种族信息，直接返回
 	if world.Civilization.Races != nil && len(world.Civilization.Races) > 0 {
 		return world.Civilization.Races
 	}
 
 	// 没有种族信息时，通过LLM生成角色概念
-	return ee.generateCharactersByLLM(world)
+	return ee.generateCharactersByLLM(state)
 }
 
 // generateCharactersByLLM 通过LLM生成角色概念
-func (ee *EvolutionEngine) generateCharactersByLLM(world *models.WorldSetting) []models.Race {
+func (ee *EvolutionEngine) generateCharactersByLLM(state *EvolutionState) []models.Race {
+	world := state.WorldContext
+
 	// 构建提示词
 	prompt := ee.buildCharacterGenerationPrompt(world)
 	systemPrompt := `你是一位专业的故事策划师，擅长创造深刻、复杂的角色。
@@ -595,6 +723,7 @@ func (ee *EvolutionEngine) generateCharactersByLLM(world *models.WorldSetting) [
 	result, err := ee.callWithRetry(prompt, systemPrompt)
 	if err != nil {
 		// LLM失败时返回默认角色
+		ee.degrade(state, "characters", "角色概念生成失败，使用默认角色: %v", err)
 		return ee.getDefaultCharacters(world)
 	}
 
@@ -609,26 +738,27 @@ func (ee *EvolutionEngine) generateCharactersByLLM(world *models.WorldSetting) [
 		} `json:"characters"`
 	}
 
-	if err := json.Unmarshal([]byte(result), &characterData); err != nil {
-		extracted := extractJSON(result)
-		json.Unmarshal([]byte(extracted), &characterData)
-	}
+	jsonx.Unmarshal(result, &characterData)
 
 	// 如果解析失败，返回默认角色
 	if len(characterData.Characters) == 0 {
+		ee.degrade(state, "characters", "角色概念输出为空，使用默认角色")
 		return ee.getDefaultCharacters(world)
 	}
 
 	// 转换为Race格式（复用Race结构表示角色概念）
+	// 角色名按世界命名规范校验并登记，避免不合时代的名字和重名
 	races := make([]models.Race, 0, len(characterData.Characters))
 	for _, char := range characterData.Characters {
+		name, _ := ensureCharacterName(world, char.Name, "")
 		races = append(races, models.Race{
-			Name:        char.Name,
+			Name:        name,
 			Description: char.Description,
 			Traits:      char.Traits,
 			Abilities:   char.Abilities,
 		})
 	}
+	ee.saveNameRegistry(world)
 
 	return races
 }
@@ -665,6 +795,8 @@ func (ee *EvolutionEngine) buildCharacterGenerationPrompt(world *models.WorldSet
 		}
 	}
 
+	prompt.WriteString(namingPromptSection(world))
+
 	prompt.WriteString("\n# 任务\n")
 	prompt.WriteString("基于以上世界设定，生成3-5个主要角色的概念。\n")
 	prompt.WriteString("每个角色应包含：\n")
@@ -768,6 +900,7 @@ func (ee *EvolutionEngine) createCharacterState(race models.Race, state *Evoluti
 	result, err := ee.callWithRetry(prompt, systemPrompt)
 	if err != nil {
 		// LLM失败时返回默认角色
+		ee.degrade(state, "character_state", "角色%s情感系统生成失败，使用默认角色状态: %v", race.Name, err)
 		return ee.createDefaultCharacterState(charID, race.Name), nil
 	}
 
@@ -783,10 +916,7 @@ func (ee *EvolutionEngine) createCharacterState(race models.Race, state *Evoluti
 		EQ              int    `json:"eq"`
 	}
 
-	if err := json.Unmarshal([]byte(result), &charData); err != nil {
-		extracted := extractJSON(result)
-		json.Unmarshal([]byte(extracted), &charData)
-	}
+	jsonx.Unmarshal(result, &charData)
 
 	// 填充默认值
 	if charData.Name == "" {
@@ -863,6 +993,7 @@ func (ee *EvolutionEngine) createDefaultCharacterState(id, name string) *Charact
 // buildCharacterCreationPrompt 构建角色创建提示词
 func (ee *EvolutionEngine) buildCharacterCreationPrompt(race models.Race, state *EvolutionState) string {
 	var prompt strings.Builder
+	sections := promptctx.Compose(promptContext(state))
 
 	prompt.WriteString("# 角色创建任务\n\n")
 
@@ -877,17 +1008,13 @@ func (ee *EvolutionEngine) buildCharacterCreationPrompt(race models.Race, state
 		prompt.WriteString(fmt.Sprintf("- 能力: %s\n", strings.Join(race.Abilities, "、")))
 	}
 
-	// 世界背景
-	prompt.WriteString(ee.buildWorldContextSection(state))
-
-	// 世界观（影响角色信仰）
-	prompt.WriteString(ee.buildWorldviewSection(state))
+	sections.Cut(&prompt, "种族信息", ctxbudget.Required)
 
-	// 超自然体系（影响角色能力）
-	prompt.WriteString(ee.buildSupernaturalSection(state))
-
-	// 语言宗教（影响角色背景）
-	prompt.WriteString(ee.buildCivilizationSection(state))
+	sections.
+		Add(promptctx.WorldContext(), ctxbudget.High).
+		Add(promptctx.Worldview(), ctxbudget.Normal).   // 影响角色信仰
+		Add(promptctx.Supernatural(), ctxbudget.High).  // 影响角色能力
+		Add(promptctx.Civilization(), ctxbudget.Normal) // 语言宗教，影响角色背景
 
 	// 社会阶层（角色出身参考）
 	if len(state.WorldContext.Society.Classes) > 0 {
@@ -897,6 +1024,8 @@ func (ee *EvolutionEngine) buildCharacterCreationPrompt(race models.Race, state
 		}
 	}
 
+	sections.Cut(&prompt, "社会阶层", ctxbudget.Normal)
+
 	// 政治结构（影响角色立场）
 	prompt.WriteString("\n## 政治环境\n")
 	prompt.WriteString(fmt.Sprintf("- 政体类型: %s\n", state.WorldContext.Society.Politics.Type))
@@ -912,6 +1041,8 @@ func (ee *EvolutionEngine) buildCharacterCreationPrompt(race models.Race, state
 		}
 	}
 
+	sections.Cut(&prompt, "政治环境", ctxbudget.Normal)
+
 	// 经济与贸易
 	prompt.WriteString("\n## 经济环境\n")
 	prompt.WriteString(fmt.Sprintf("- 经济类型: %s\n", state.WorldContext.Society.Economy.Type))
@@ -922,6 +1053,8 @@ func (ee *EvolutionEngine) buildCharacterCreationPrompt(race models.Race, state
 		prompt.WriteString(fmt.Sprintf("- 货币: %s\n", strings.Join(state.WorldContext.Society.Economy.Currency, ", ")))
 	}
 
+	sections.Cut(&prompt, "经济环境", ctxbudget.Low)
+
 	// 法律体系（影响角色行为约束）
 	if len(state.WorldContext.Society.Laws) > 0 {
 		prompt.WriteString("\n## 法律体系\n")
@@ -930,6 +1063,8 @@ func (ee *EvolutionEngine) buildCharacterCreationPrompt(race models.Race, state
 		}
 	}
 
+	sections.Cut(&prompt, "法律体系", ctxbudget.Low)
+
 	// 历史背景（影响角色记忆）
 	if len(state.WorldContext.History.Traumas) > 0 {
 		prompt.WriteString("\n## 集体创伤\n")
@@ -938,8 +1073,10 @@ func (ee *EvolutionEngine) buildCharacterCreationPrompt(race models.Race, state
 		}
 	}
 
+	sections.Cut(&prompt, "集体创伤", ctxbudget.Low)
+
 	// 历史时代（角色可能经历的）
-	prompt.WriteString(ee.buildHistoryDetailsSection(state))
+	sections.Add(promptctx.History(), ctxbudget.Low)
 
 	prompt.WriteString("\n# 任务\n")
 	prompt.WriteString("基于以上信息创建一个角色，要求：\n")
@@ -964,7 +1101,9 @@ func (ee *EvolutionEngine) buildCharacterCreationPrompt(race models.Race, state
   "eq": 0-100
 }`)
 
-	return prompt.String()
+	sections.Cut(&prompt, "任务", ctxbudget.Required)
+
+	return ee.fitPrompt(sections)
 }
 
 // designConflicts 使用LLM设计冲突
@@ -977,6 +1116,7 @@ func (ee *EvolutionEngine) designConflicts(state *EvolutionState) ([]*ConflictTh
 	result, err := ee.callWithRetry(prompt, systemPrompt)
 	if err != nil {
 		// LLM失败时使用默认冲突生成
+		ee.degrade(state, "conflicts", "冲突设计失败，使用默认冲突: %v", err)
 		return ee.createDefaultConflicts(state), nil
 	}
 
@@ -991,10 +1131,7 @@ func (ee *EvolutionEngine) designConflicts(state *EvolutionState) ([]*ConflictTh
 		} `json:"conflicts"`
 	}
 
-	if err := json.Unmarshal([]byte(result), &conflictData); err != nil {
-		extracted := extractJSON(result)
-		json.Unmarshal([]byte(extracted), &conflictData)
-	}
+	jsonx.Unmarshal(result, &conflictData)
 
 	conflicts := make([]*ConflictThread, 0)
 
@@ -1043,6 +1180,7 @@ func (ee *EvolutionEngine) designConflicts(state *EvolutionState) ([]*ConflictTh
 
 	// 如果没有生成任何冲突，使用默认方法
 	if len(conflicts) == 0 {
+		ee.degrade(state, "conflicts", "冲突设计输出为空，使用默认冲突")
 		return ee.createDefaultConflicts(state), nil
 	}
 
@@ -1077,23 +1215,15 @@ func (ee *EvolutionEngine) createDefaultConflicts(state *EvolutionState) []*Conf
 // buildConflictDesignPrompt 构建冲突设计提示词
 func (ee *EvolutionEngine) buildConflictDesignPrompt(state *EvolutionState) string {
 	var prompt strings.Builder
+	sections := promptctx.Compose(promptContext(state))
 
 	prompt.WriteString("# 冲突设计任务\n\n")
+	sections.Cut(&prompt, "标题", ctxbudget.Required)
 
-	// 世界背景
-	prompt.WriteString(ee.buildWorldContextSection(state))
-
-	// 超自然体系（冲突来源）
-	prompt.WriteString(ee.buildSupernaturalSection(state))
-
-	// 已有角色
-	if len(state.Characters) > 0 {
-		prompt.WriteString("\n## 已有角色\n")
-		for _, char := range state.Characters {
-			prompt.WriteString(fmt.Sprintf("- %s: 欲望=%s, 需求=%s, 恐惧=%s\n",
-				char.Name, char.Desires.ConsciousWant, char.Desires.UnconsciousNeed, char.Desires.Fear))
-		}
-	}
+	sections.
+		Add(promptctx.WorldContext(), ctxbudget.High).
+		Add(promptctx.Supernatural(), ctxbudget.Normal). // 冲突来源
+		Add(promptctx.Characters(promptctx.CharacterOptions{Heading: "已有角色", Full: true}), ctxbudget.High)
 
 	// 社会矛盾（来自故事土壤）
 	if len(state.WorldContext.StorySoil.SocialConflicts) > 0 {
@@ -1133,6 +1263,8 @@ func (ee *EvolutionEngine) buildConflictDesignPrompt(state *EvolutionState) stri
 		}
 	}
 
+	sections.Cut(&prompt, "社会矛盾与权力结构", ctxbudget.High)
+
 	// 经济状况（冲突根源）
 	prompt.WriteString("\n## 经济环境\n")
 	prompt.WriteString(fmt.Sprintf("- 经济类型: %s\n", state.WorldContext.Society.Economy.Type))
@@ -1142,7 +1274,10 @@ func (ee *EvolutionEngine) buildConflictDesignPrompt(state *EvolutionState) stri
 	if len(state.WorldContext.Society.Economy.Currency) > 0 {
 		prompt.WriteString(fmt.Sprintf("- 货币: %s\n", strings.Join(state.WorldContext.Society.Economy.Currency, ", ")))
 	}
-	prompt.WriteString(ee.buildGeographySection(state))
+	sections.Cut(&prompt, "经济环境", ctxbudget.Normal).
+		Add(promptctx.Economy(), ctxbudget.Normal).
+		Add(promptctx.Geography(), ctxbudget.Low).
+		Add(promptctx.ReligiousStrife(), ctxbudget.Normal)
 
 	// 法律体系（约束冲突解决方式）
 	if len(state.WorldContext.Society.Laws) > 0 {
@@ -1153,6 +1288,8 @@ func (ee *EvolutionEngine) buildConflictDesignPrompt(state *EvolutionState) stri
 		}
 	}
 
+	sections.Cut(&prompt, "法律体系", ctxbudget.Low)
+
 	// 文化细节
 	cd := state.WorldContext.StorySoil.CulturalDetails
 	if len(cd.Customs) > 0 || len(cd.Taboos) > 0 || len(cd.Slang) > 0 ||
@@ -1183,6 +1320,8 @@ func (ee *EvolutionEngine) buildConflictDesignPrompt(state *EvolutionState) stri
 		}
 	}
 
+	sections.Cut(&prompt, "文化与历史", ctxbudget.Low)
+
 	prompt.WriteString("\n# 任务\n")
 	prompt.WriteString("设计3-5个核心冲突，要求：\n")
 	prompt.WriteString("1. 冲突类型多样（内在冲突/人际冲突/社会冲突/存在冲突）\n")
@@ -1192,6 +1331,9 @@ func (ee *EvolutionEngine) buildConflictDesignPrompt(state *EvolutionState) stri
 	prompt.WriteString("5. 冲突与世界主题相关\n")
 	prompt.WriteString("6. 利用权力结构和社会矛盾作为冲突来源\n")
 	prompt.WriteString("7. 考虑资源争夺和超自然体系对冲突的影响\n")
+	if len(state.WorldContext.Society.Economy.Scarcity) > 0 {
+		prompt.WriteString("8. 资源争夺类冲突必须点名具体地区和物资（参考资源争夺热点和贸易路线），不要泛泛而谈\n")
+	}
 
 	prompt.WriteString("\n# 输出格式（JSON）\n")
 	prompt.WriteString(`{
@@ -1206,7 +1348,9 @@ func (ee *EvolutionEngine) buildConflictDesignPrompt(state *EvolutionState) stri
   ]
 }`)
 
-	return prompt.String()
+	sections.Cut(&prompt, "任务", ctxbudget.Required)
+
+	return ee.fitPrompt(sections)
 }
 
 func (ee *EvolutionEngine) generateNextConflictStage(conflict *ConflictThread, state *EvolutionState) string {
@@ -1414,6 +1558,7 @@ func (ee *EvolutionEngine) generateForeshadows(state *EvolutionState) []*Foresha
 	result, err := ee.callWithRetry(prompt, systemPrompt)
 	if err != nil {
 		// LLM失败时返回默认伏笔
+		ee.degrade(state, "foreshadows", "伏笔生成失败，使用默认伏笔: %v", err)
 		return ee.createDefaultForeshadows(state)
 	}
 
@@ -1428,10 +1573,7 @@ func (ee *EvolutionEngine) generateForeshadows(state *EvolutionState) []*Foresha
 		} `json:"foreshadows"`
 	}
 
-	if err := json.Unmarshal([]byte(result), &foreshadowData); err != nil {
-		extracted := extractJSON(result)
-		json.Unmarshal([]byte(extracted), &foreshadowData)
-	}
+	jsonx.Unmarshal(result, &foreshadowData)
 
 	foreshadows := make([]*Foreshadow, 0)
 	for _, f := range foreshadowData.Foreshadows {
@@ -1455,6 +1597,7 @@ func (ee *EvolutionEngine) generateForeshadows(state *EvolutionState) []*Foresha
 	}
 
 	if len(foreshadows) == 0 {
+		ee.degrade(state, "foreshadows", "伏笔输出为空，使用默认伏笔")
 		return ee.createDefaultForeshadows(state)
 	}
 
@@ -1492,12 +1635,7 @@ func (ee *EvolutionEngine) buildForeshadowPrompt(state *EvolutionState) string {
 	prompt.WriteString(fmt.Sprintf("- 当前轮次: %d\n", state.CurrentRound))
 
 	// 已有冲突
-	if len(state.Conflicts) > 0 {
-		prompt.WriteString("\n## 核心冲突\n")
-		for i, c := range state.Conflicts {
-			prompt.WriteString(fmt.Sprintf("%d. %s: %s\n", i+1, c.Type, c.CoreQuestion))
-		}
-	}
+	prompt.WriteString(promptctx.Conflicts(promptctx.ConflictOptions{}).Render(promptContext(state)))
 
 	// 已有角色
 	if len(state.Characters) > 0 {
@@ -1595,10 +1733,7 @@ func (ee *EvolutionEngine) deepenTheme(state *EvolutionState) []ThematicLayer {
 		} `json:"layers"`
 	}
 
-	if err := json.Unmarshal([]byte(result), &themeData); err != nil {
-		extracted := extractJSON(result)
-		json.Unmarshal([]byte(extracted), &themeData)
-	}
+	jsonx.Unmarshal(result, &themeData)
 
 	layers := make([]ThematicLayer, 0)
 	for _, l := range themeData.Layers {
@@ -1682,10 +1817,7 @@ func (ee *EvolutionEngine) generatePlotTwist(state *EvolutionState) string {
 		Twist string `json:"twist"`
 	}
 
-	if err := json.Unmarshal([]byte(result), &twistData); err != nil {
-		extracted := extractJSON(result)
-		json.Unmarshal([]byte(extracted), &twistData)
-	}
+	jsonx.Unmarshal(result, &twistData)
 
 	if twistData.Twist != "" {
 		return twistData.Twist
@@ -1704,15 +1836,8 @@ func (ee *EvolutionEngine) buildPlotTwistPrompt(state *EvolutionState) string {
 	prompt.WriteString(fmt.Sprintf("- 核心主题: %s\n", state.ThemeEvolution.CoreTheme))
 	prompt.WriteString(fmt.Sprintf("- 当前轮次: %d\n", state.CurrentRound))
 
-	// 已有冲突
-	if len(state.Conflicts) > 0 {
-		prompt.WriteString("\n## 核心冲突\n")
-		for i, c := range state.Conflicts {
-			if !c.IsResolved {
-				prompt.WriteString(fmt.Sprintf("%d. %s: %s (强度:%d)\n", i+1, c.Type, c.CoreQuestion, c.CurrentIntensity))
-			}
-		}
-	}
+	// 未解决的冲突
+	prompt.WriteString(promptctx.Conflicts(promptctx.ConflictOptions{Unresolved: true, Detail: true}).Render(promptContext(state)))
 
 	// 已有伏笔
 	plantedForeshadows := 0
@@ -1765,370 +1890,77 @@ func (ee *EvolutionEngine) buildPlotTwistPrompt(state *EvolutionState) string {
 	return prompt.String()
 }
 
-// buildWorldContextSection 构建世界背景提示词部分
-func (ee *EvolutionEngine) buildWorldContextSection(state *EvolutionState) string {
-	var prompt strings.Builder
-
-	prompt.WriteString("## 世界背景\n")
-	prompt.WriteString(fmt.Sprintf("- 世界名称: %s\n", state.WorldContext.Name))
-	prompt.WriteString(fmt.Sprintf("- 世界类型: %s\n", state.WorldContext.Type))
-	prompt.WriteString(fmt.Sprintf("- 世界规模: %s\n", state.WorldContext.Scale))
-	if state.WorldContext.Style != "" {
-		prompt.WriteString(fmt.Sprintf("- 风格倾向: %s\n", state.WorldContext.Style))
-	}
-	prompt.WriteString(fmt.Sprintf("- 核心主题: %s\n", state.WorldContext.Philosophy.CoreQuestion))
-	prompt.WriteString(fmt.Sprintf("- 最高善: %s, 最大恶: %s\n",
-		state.WorldContext.Philosophy.ValueSystem.HighestGood,
-		state.WorldContext.Philosophy.ValueSystem.UltimateEvil))
-
-	// 道德困境（冲突来源）
-	if len(state.WorldContext.Philosophy.ValueSystem.MoralDilemmas) > 0 {
-		prompt.WriteString("\n### 道德困境\n")
-		for i, d := range state.WorldContext.Philosophy.ValueSystem.MoralDilemmas {
-			prompt.WriteString(fmt.Sprintf("%d. %s: %s\n", i+1, d.Dilemma, d.Description))
-		}
-	}
-
-	// 主题列表
-	if len(state.WorldContext.Philosophy.Themes) > 0 {
-		prompt.WriteString("\n### 可探索主题\n")
-		for _, t := range state.WorldContext.Philosophy.Themes {
-			prompt.WriteString(fmt.Sprintf("- %s: %s\n", t.Name, t.ExplorationAngle))
-		}
-	}
-
-	return prompt.String()
-}
-
-// buildGeographySection 构建地理信息提示词部分
-func (ee *EvolutionEngine) buildGeographySection(state *EvolutionState) string {
-	if len(state.WorldContext.Geography.Regions) == 0 {
-		return ""
-	}
-
-	var prompt strings.Builder
-	prompt.WriteString("\n## 地理环境\n")
-
-	// 列出主要区域
-	prompt.WriteString("### 主要区域\n")
-	for i, region := range state.WorldContext.Geography.Regions {
-		if i >= 8 { // 最多显示8个区域
-			prompt.WriteString(fmt.Sprintf("... 还有%d个区域\n", len(state.WorldContext.Geography.Regions)-i))
-			break
-		}
-		prompt.WriteString(fmt.Sprintf("- %s (%s): %s\n", region.Name, region.Type, region.Description))
-		if len(region.Resources) > 0 {
-			prompt.WriteString(fmt.Sprintf("  区域资源: %s\n", strings.Join(region.Resources, ", ")))
-		}
-		if len(region.Risks) > 0 {
-			prompt.WriteString(fmt.Sprintf("  风险: %s\n", strings.Join(region.Risks, ", ")))
-		}
-	}
-
-	// 全局资源分布
-	if state.WorldContext.Geography.Resources != nil {
-		prompt.WriteString("\n### 资源分布\n")
-		if len(state.WorldContext.Geography.Resources.Basic) > 0 {
-			prompt.WriteString(fmt.Sprintf("基础资源: %s\n", strings.Join(state.WorldContext.Geography.Resources.Basic, ", ")))
-		}
-		if len(state.WorldContext.Geography.Resources.Strategic) > 0 {
-			prompt.WriteString(fmt.Sprintf("战略资源: %s\n", strings.Join(state.WorldContext.Geography.Resources.Strategic, ", ")))
-		}
-		if len(state.WorldContext.Geography.Resources.Rare) > 0 {
-			prompt.WriteString(fmt.Sprintf("稀有资源: %s\n", strings.Join(state.WorldContext.Geography.Resources.Rare, ", ")))
-		}
-	}
-
-	// 气候信息
-	if state.WorldContext.Geography.Climate != nil {
-		prompt.WriteString(fmt.Sprintf("\n### 气候\n%s\n", state.WorldContext.Geography.Climate.Type))
-		if len(state.WorldContext.Geography.Climate.Features) > 0 {
-			prompt.WriteString(fmt.Sprintf("特征: %s\n", strings.Join(state.WorldContext.Geography.Climate.Features, ", ")))
-		}
-	}
-
-	return prompt.String()
-}
-
-// buildWorldviewSection 构建世界观信息提示词部分
-func (ee *EvolutionEngine) buildWorldviewSection(state *EvolutionState) string {
-	var prompt strings.Builder
-	hasContent := false
-
-	// 宇宙论
-	if state.WorldContext.Worldview.Cosmology.Origin != "" ||
-		state.WorldContext.Worldview.Cosmology.Structure != "" ||
-		state.WorldContext.Worldview.Cosmology.Eschatology != "" {
-		hasContent = true
-		prompt.WriteString("\n## 世界观\n")
-		if state.WorldContext.Worldview.Cosmology.Origin != "" {
-			prompt.WriteString(fmt.Sprintf("### 宇宙论\n- 起源: %s\n", state.WorldContext.Worldview.Cosmology.Origin))
-		}
-		if state.WorldContext.Worldview.Cosmology.Structure != "" {
-			prompt.WriteString(fmt.Sprintf("- 结构: %s\n", state.WorldContext.Worldview.Cosmology.Structure))
-		}
-		if state.WorldContext.Worldview.Cosmology.Eschatology != "" {
-			prompt.WriteString(fmt.Sprintf("- 终极命运: %s\n", state.WorldContext.Worldview.Cosmology.Eschatology))
-		}
-	}
-
-	// 形而上学
-	if state.WorldContext.Worldview.Metaphysics.SoulExists ||
-		state.WorldContext.Worldview.Metaphysics.FateExists {
-		if !hasContent {
-			prompt.WriteString("\n## 世界观\n")
-			hasContent = true
-		}
-		prompt.WriteString("\n### 形而上学\n")
-		if state.WorldContext.Worldview.Metaphysics.SoulExists {
-			prompt.WriteString(fmt.Sprintf("- 灵魂存在: %s\n", state.WorldContext.Worldview.Metaphysics.SoulNature))
-			prompt.WriteString(fmt.Sprintf("- 来世: %s\n", state.WorldContext.Worldview.Metaphysics.Afterlife))
-		}
-		if state.WorldContext.Worldview.Metaphysics.FateExists {
-			prompt.WriteString(fmt.Sprintf("- 命运与自由意志: %s\n", state.WorldContext.Worldview.Metaphysics.FateRelShip))
-		}
-	}
-
-	return prompt.String()
-}
-
-// buildSupernaturalSection 构建超自然体系信息提示词部分
-func (ee *EvolutionEngine) buildSupernaturalSection(state *EvolutionState) string {
-	if state.WorldContext.Laws.Supernatural == nil ||
-		!state.WorldContext.Laws.Supernatural.Exists {
-		return ""
-	}
-
-	var prompt strings.Builder
-	sn := state.WorldContext.Laws.Supernatural
-
-	prompt.WriteString("\n## 超自然体系\n")
-	prompt.WriteString(fmt.Sprintf("类型: %s\n", sn.Type))
-
-	if sn.Settings != nil {
-		// 魔法体系
-		if sn.Settings.MagicSystem != nil {
-			prompt.WriteString("\n### 魔法体系\n")
-			prompt.WriteString(fmt.Sprintf("- 来源: %s\n", sn.Settings.MagicSystem.Source))
-			prompt.WriteString(fmt.Sprintf("- 代价: %s\n", sn.Settings.MagicSystem.Cost))
-			if len(sn.Settings.MagicSystem.Limitation) > 0 {
-				prompt.WriteString(fmt.Sprintf("- 限制: %s\n", strings.Join(sn.Settings.MagicSystem.Limitation, ", ")))
-			}
-		}
-
-		// 修真体系
-		if sn.Settings.CultivationSystem != nil {
-			prompt.WriteString("\n### 修真体系\n")
-			if len(sn.Settings.CultivationSystem.Realms) > 0 {
-				prompt.WriteString(fmt.Sprintf("- 境界: %s\n", strings.Join(sn.Settings.CultivationSystem.Realms, "→")))
-			}
-			prompt.WriteString(fmt.Sprintf("- 资源体系: %s\n", sn.Settings.CultivationSystem.ResourceSystem))
-			prompt.WriteString(fmt.Sprintf("- 瓶颈: %s\n", sn.Settings.CultivationSystem.Bottleneck))
-		}
-
-		// 异能体系
-		if sn.Settings.SuperpowerSystem != nil {
-			prompt.WriteString("\n### 异能体系\n")
-			prompt.WriteString(fmt.Sprintf("- 起源: %s\n", sn.Settings.SuperpowerSystem.Origin))
-			prompt.WriteString(fmt.Sprintf("- 类型: %s\n", sn.Settings.SuperpowerSystem.Type))
-			if len(sn.Settings.SuperpowerSystem.Limit) > 0 {
-				prompt.WriteString(fmt.Sprintf("- 限制: %s\n", strings.Join(sn.Settings.SuperpowerSystem.Limit, ", ")))
-			}
-		}
-	}
-
-	return prompt.String()
-}
-
-// buildCivilizationSection 构建文明信息提示词部分
-func (ee *EvolutionEngine) buildCivilizationSection(state *EvolutionState) string {
-	var prompt strings.Builder
-	hasContent := false
-
-	// 种族关系（潜在冲突来源）
-	if len(state.WorldContext.Civilization.Races) > 0 {
-		hasContent = true
-		hasRaceRelations := false
-		for _, race := range state.WorldContext.Civilization.Races {
-			if len(race.Relations) > 0 {
-				hasRaceRelations = true
-				break
-			}
-		}
-		if hasRaceRelations {
-			prompt.WriteString("\n## 种族关系\n")
-			for _, race := range state.WorldContext.Civilization.Races {
-				if len(race.Relations) > 0 {
-					prompt.WriteString(fmt.Sprintf("%s 与其他种族的关系:\n", race.Name))
-					for otherRace, relation := range race.Relations {
-						prompt.WriteString(fmt.Sprintf("  - %s: %s\n", otherRace, relation))
-					}
-				}
-			}
-		}
-	}
-
-	// 语言
-	if len(state.WorldContext.Civilization.Languages) > 0 {
-		if !hasContent {
-			hasContent = true
-			prompt.WriteString("\n## 语言文化\n")
-		} else {
-			prompt.WriteString("\n### 语言\n")
-		}
-		for _, lang := range state.WorldContext.Civilization.Languages {
-			prompt.WriteString(fmt.Sprintf("- %s (%s): 使用者=%s\n", lang.Name, lang.Type, lang.Speakers))
-			if len(lang.Features) > 0 {
-				prompt.WriteString(fmt.Sprintf("  特征: %s\n", strings.Join(lang.Features, ", ")))
-			}
-		}
-	}
-
-	// 宗教（包括组织结构）
-	if len(state.WorldContext.Civilization.Religions) > 0 {
-		if !hasContent {
-			prompt.WriteString("\n## 宗教信仰\n")
-			hasContent = true
-		} else {
-			prompt.WriteString("\n### 宗教信仰\n")
-		}
-		for _, rel := range state.WorldContext.Civilization.Religions {
-			prompt.WriteString(fmt.Sprintf("- %s (%s)\n", rel.Name, rel.Type))
-			if rel.Cosmology != "" {
-				prompt.WriteString(fmt.Sprintf("  宇宙观: %s\n", rel.Cosmology))
-			}
-			if len(rel.Ethics) > 0 {
-				prompt.WriteString(fmt.Sprintf("  伦理: %s\n", strings.Join(rel.Ethics, ", ")))
-			}
-			if len(rel.Practices) > 0 {
-				prompt.WriteString(fmt.Sprintf("  仪式: %s\n", strings.Join(rel.Practices, ", ")))
-			}
-			// 宗教组织（权力结构）
-			if rel.Organization != nil {
-				prompt.WriteString(fmt.Sprintf("  组织: %s (领导者: %s)\n", rel.Organization.Type, rel.Organization.Leader))
-				if len(rel.Organization.Factions) > 0 {
-					prompt.WriteString(fmt.Sprintf("  派系: %s\n", strings.Join(rel.Organization.Factions, ", ")))
-				}
-			}
-		}
-	}
-
-	return prompt.String()
-}
-
-// buildHistoryDetailsSection 构建历史详情提示词部分
-func (ee *EvolutionEngine) buildHistoryDetailsSection(state *EvolutionState) string {
-	var prompt strings.Builder
-	hasContent := false
-
-	// 世界起源
-	if state.WorldContext.History.Origin != "" {
-		hasContent = true
-		prompt.WriteString("\n## 世界起源\n")
-		prompt.WriteString(state.WorldContext.History.Origin)
-		prompt.WriteString("\n")
-	}
-
-	// 历史时代
-	if len(state.WorldContext.History.Eras) > 0 {
-		if !hasContent {
-			prompt.WriteString("\n## 历史时代\n")
-			hasContent = true
-		} else {
-			prompt.WriteString("\n### 历史时代\n")
-		}
-		for _, era := range state.WorldContext.History.Eras {
-			prompt.WriteString(fmt.Sprintf("- %s (%s): %s\n", era.Name, era.Period, era.Description))
-		}
-	}
-
-	return prompt.String()
-}
-
-// 质量评估方法
+// 质量评估方法：由LLM评审员按评分细则打分，见 critic.go
 func (ee *EvolutionEngine) evaluateCharacterQuality(state *EvolutionState) int {
-	return 70 + len(state.Characters)*3
+	return ee.scoreAspect(state, AspectCharacters)
 }
 
 func (ee *EvolutionEngine) evaluateConflictQuality(state *EvolutionState) int {
-	totalIntensity := 0
-	for _, c := range state.Conflicts {
-		totalIntensity += c.CurrentIntensity
-	}
-	return min(100, 50+totalIntensity/len(state.Conflicts)/2)
+	return ee.scoreAspect(state, AspectConflicts)
 }
 
 func (ee *EvolutionEngine) evaluateForeshadowQuality(state *EvolutionState) int {
-	return 60 + len(state.Foreshadowing)*2
+	return ee.scoreAspect(state, AspectForeshadows)
 }
 
 func (ee *EvolutionEngine) evaluateThemeQuality(state *EvolutionState) int {
-	return 60 + state.NarrativeDepth*4
+	return ee.scoreAspect(state, AspectThemes)
 }
 
 func (ee *EvolutionEngine) evaluatePlotQuality(state *EvolutionState) int {
-	return 70
+	return ee.scoreAspect(state, AspectPlot)
 }
 
+// calculateOverallQuality 综合质量，复用各维度最近一次评审，不重复调用LLM
 func (ee *EvolutionEngine) calculateOverallQuality(state *EvolutionState) int {
-	return (ee.evaluateCharacterQuality(state) +
-		ee.evaluateConflictQuality(state) +
-		ee.evaluateForeshadowQuality(state) +
-		ee.evaluateThemeQuality(state)) / 4
+	score, _ := state.OverallQuality()
+	return score
 }
 
 func (ee *EvolutionEngine) buildEvolutionPrompt(state *EvolutionState) string {
-	return fmt.Sprintf("分析当前叙事状态并提出改进建议。轮次: %d/%d, 角色: %d, 冲突: %d",
+	prompt := fmt.Sprintf("分析当前叙事状态并提出改进建议。轮次: %d/%d, 角色: %d, 冲突: %d",
 		state.CurrentRound, state.MaxRounds, len(state.Characters), len(state.Conflicts))
+
+	// 附上各维度评审的薄弱点，让建议有针对性
+	for _, aspect := range coreAspects {
+		c, ok := state.Critiques[aspect]
+		if !ok || c == nil || c.Fallback {
+			continue
+		}
+		prompt += fmt.Sprintf("\n%s评分%d", rubrics[aspect].Label, c.Score)
+		if len(c.Suggestions) > 0 {
+			prompt += "，评审建议: " + strings.Join(c.Suggestions, "；")
+		}
+	}
+	return prompt
 }
 
 func (ee *EvolutionEngine) callWithRetry(prompt, systemPrompt string) (string, error) {
-	fmt.Println("\n========== LLM DEBUG [EVOLUTION] (JSON) ==========")
-	fmt.Printf("System Prompt:\n%s\n\n", systemPrompt)
-	fmt.Printf("User Prompt:\n%s\n", truncateForDebugEvo(prompt, 2000))
-	fmt.Println("====================================================")
-
-	fmt.Println("🔄 调用LLM...")
-	startTime := time.Now()
+	return ee.callForRole("", prompt, systemPrompt)
+}
 
-	result, err := ee.client.GenerateJSONWithParams(
+// callForRole 调用LLM，响应按提示词角色注册的 Schema 校验，不通过时由客户端自动请求修复
+// 提示词和响应由LLM客户端按 debug 级别记录
+func (ee *EvolutionEngine) callForRole(role, prompt, systemPrompt string) (string, error) {
+	result, err := ee.client.GenerateJSONForRole(
+		role,
 		prompt,
 		systemPrompt,
 		ee.mapping.Temperature,
 		ee.mapping.MaxTokens,
 	)
-
-	elapsed := time.Since(startTime)
-	fmt.Printf("⏱️  耗时: %.1f秒\n", elapsed.Seconds())
-
 	if err != nil {
-		fmt.Printf("❌ 调用失败: %v\n", err)
-		fmt.Println("====================================================\n")
+		ee.log().Warn("LLM调用失败", "role", role, "error", err)
 		return "", err
 	}
 
 	jsonBytes, err := json.Marshal(result)
 	if err != nil {
-		fmt.Printf("❌ 序列化失败: %v\n", err)
-		fmt.Println("====================================================\n")
-		return "", err
+		return "", fmt.Errorf("序列化结果失败: %w", err)
 	}
-
-	fmt.Printf("✅ 响应成功\n")
-	fmt.Printf("Response:\n%s\n", truncateForDebugEvo(string(jsonBytes), 3000))
-	fmt.Println("====================================================\n")
-
 	return string(jsonBytes), nil
 }
 
-// truncateForDebugEvo 截断过长的调试输出（演化引擎版本）
-func truncateForDebugEvo(s string, maxLen int) string {
-	if len(s) <= maxLen {
-		return s
-	}
-	return s[:maxLen] + "\n... (截断，总长度: " + fmt.Sprintf("%d", len(s)) + " 字符)"
-}
-
 func (ee *EvolutionEngine) characterStateSlice(m map[string]*CharacterState) []*CharacterState {
 	result := make([]*CharacterState, 0, len(m))
 	for _, c := range m {
@@ -2219,6 +2051,7 @@ type GlobalOutline struct {
 	Climax      string            `json:"climax"`      // 高潮
 	Resolution  string            `json:"resolution"`  // 结局
 	ForeshadowLinks map[string]string `json:"foreshadow_links"` // 伏笔链接（事件ID -> 伏笔ID）
+	AntagonistArcs  []AntagonistArc   `json:"antagonist_arcs,omitempty"` // 反派平行暗线
 }
 
 // KeyEvent 关键事件
@@ -2231,6 +2064,8 @@ type KeyEvent struct {
 	Purpose      string   `json:"purpose"`      // 事件目的
 	Foreshadows  []string `json:"foreshadows"`  // 种植的伏笔ID
 	Reveals      []string `json:"reveals"`      // 回收的伏笔ID
+	Location     string   `json:"location,omitempty"`   // 事件地点
+	StoryTime    *models.StoryTime `json:"story_time,omitempty"` // 故事内时间
 }
 
 // ChapterPlan 章节规划
@@ -2247,6 +2082,7 @@ type ChapterSynopsis struct {
 	KeyEvents    []string `json:"key_events"`   // 本章包含的关键事件
 	RelationshipChanges []string `json:"relationship_changes"` // 预期的关系变化
 	ForeshadowOps ForeshadowOperations `json:"foreshadow_ops"` // 伏笔操作
+	Subplots     []string `json:"subplots,omitempty"` // 本章推进的副线节拍
 }
 
 // ForeshadowOperations 伏笔操作
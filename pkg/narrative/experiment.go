@@ -0,0 +1,278 @@
+// Package narrative 叙事器 - 提示词A/B实验
+// 在同一世界上用多个提示词变体运行同一演化阶段，并由LLM评审打分
+package narrative
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// 实验默认值
+const (
+	DefaultExperimentRole  = "conflict_designer"
+	DefaultExperimentPhase = 4
+)
+
+// PromptVariant 提示词变体
+type PromptVariant struct {
+	Name         string `json:"name"`
+	SystemPrompt string `json:"system_prompt"` // 为空时使用内置默认提示词
+}
+
+// ExperimentParams 实验参数
+type ExperimentParams struct {
+	WorldID  string          `json:"world_id"`
+	Role     string          `json:"role"`  // 被替换提示词的角色
+	Phase    int             `json:"phase"` // 对比的演化阶段（1-5）
+	Variants []PromptVariant `json:"variants"`
+}
+
+// Validate 校验实验参数：阶段在 1-5 之间，至少 2 个变体，变体名称非空且不重复（评审按名称回填分数）
+func (p ExperimentParams) Validate() error {
+	if p.Phase < 1 || p.Phase > 5 {
+		return fmt.Errorf("不支持的实验阶段: %d", p.Phase)
+	}
+	if len(p.Variants) < 2 {
+		return fmt.Errorf("至少需要2个提示词变体")
+	}
+	seen := make(map[string]bool, len(p.Variants))
+	for i, v := range p.Variants {
+		name := strings.TrimSpace(v.Name)
+		if name == "" {
+			return fmt.Errorf("第%d个变体缺少名称", i+1)
+		}
+		if seen[name] {
+			return fmt.Errorf("变体名称重复: %s", name)
+		}
+		seen[name] = true
+	}
+	return nil
+}
+
+// VariantOutput 单个变体的运行结果
+type VariantOutput struct {
+	Variant    string      `json:"variant"`
+	Output     interface{} `json:"output,omitempty"`
+	Rounds     int         `json:"rounds"`      // 该变体消耗的LLM轮次
+	DurationMs int64       `json:"duration_ms"` // 运行耗时
+	Score      float64     `json:"score"`       // 评审分数（0-100）
+	Comment    string      `json:"comment"`     // 评审意见
+	Error      string      `json:"error,omitempty"`
+}
+
+// ExperimentReport 实验报告
+type ExperimentReport struct {
+	WorldID string           `json:"world_id"`
+	Role    string           `json:"role"`
+	Phase   int              `json:"phase"`
+	Outputs []*VariantOutput `json:"outputs"`
+	Winner  string           `json:"winner"`
+	Summary string           `json:"summary"`
+}
+
+// RunPromptExperiment 运行提示词实验
+// 前置阶段只执行一次，之后每个变体都从同一份状态快照开始执行目标阶段，保证对比公平
+func (o *Orchestrator) RunPromptExperiment(params ExperimentParams) (*ExperimentReport, error) {
+	if params.Role == "" {
+		params.Role = DefaultExperimentRole
+	}
+	if params.Phase == 0 {
+		params.Phase = DefaultExperimentPhase
+	}
+	if err := params.Validate(); err != nil {
+		return nil, err
+	}
+
+	state, err := o.engine.CreateEvolutionState(params.WorldID)
+	if err != nil {
+		return nil, fmt.Errorf("初始化演化状态失败: %w", err)
+	}
+
+	// 执行前置阶段
+	for phase := 1; phase < params.Phase; phase++ {
+		if err := o.runPhase(state, phase); err != nil {
+			return nil, fmt.Errorf("前置阶段%d失败: %w", phase, err)
+		}
+	}
+
+	snapshot, err := json.Marshal(state)
+	if err != nil {
+		return nil, fmt.Errorf("序列化状态快照失败: %w", err)
+	}
+
+	report := &ExperimentReport{
+		WorldID: params.WorldID,
+		Role:    params.Role,
+		Phase:   params.Phase,
+		Outputs: make([]*VariantOutput, 0, len(params.Variants)),
+	}
+
+	for _, variant := range params.Variants {
+		report.Outputs = append(report.Outputs, o.runVariant(snapshot, params, variant))
+	}
+
+	if err := o.judgeVariants(report); err != nil {
+		return report, fmt.Errorf("评审失败: %w", err)
+	}
+
+	return report, nil
+}
+
+// runVariant 基于状态快照运行单个变体
+func (o *Orchestrator) runVariant(snapshot []byte, params ExperimentParams, variant PromptVariant) *VariantOutput {
+	output := &VariantOutput{Variant: variant.Name}
+
+	var state EvolutionState
+	if err := json.Unmarshal(snapshot, &state); err != nil {
+		output.Error = fmt.Sprintf("恢复状态快照失败: %v", err)
+		return output
+	}
+
+	o.SetSystemPromptOverride(params.Role, variant.SystemPrompt)
+	defer o.SetSystemPromptOverride(params.Role, "")
+
+	startRound := state.CurrentRound
+	startTime := time.Now()
+	err := o.runPhase(&state, params.Phase)
+	output.DurationMs = time.Since(startTime).Milliseconds()
+	output.Rounds = state.CurrentRound - startRound
+
+	if err != nil {
+		output.Error = err.Error()
+		return output
+	}
+	output.Output = phaseOutput(&state, params.Phase)
+	return output
+}
+
+// runPhase 执行指定演化阶段
+func (o *Orchestrator) runPhase(state *EvolutionState, phase int) error {
+	switch phase {
+	case 1:
+		return o.phase1_StoryArchitecture(state)
+	case 2:
+		return o.phase2_CharactersAndRelationships(state)
+	case 3:
+		return o.phase3_ForeshadowPlanning(state)
+	case 4:
+		return o.phase4_ConflictSystem(state)
+	case 5:
+		return o.phase5_GlobalOutline(state)
+	default:
+		return fmt.Errorf("不支持的演化阶段: %d", phase)
+	}
+}
+
+// phaseOutput 提取阶段产出
+func phaseOutput(state *EvolutionState, phase int) interface{} {
+	switch phase {
+	case 1:
+		return state.StoryArchitecture
+	case 2:
+		return map[string]interface{}{
+			"characters":           state.Characters,
+			"relationship_network": state.RelationshipNetwork,
+		}
+	case 3:
+		return state.ForeshadowPlan
+	case 4:
+		return state.Conflicts
+	case 5:
+		return state.GlobalOutline
+	default:
+		return nil
+	}
+}
+
+// judgeVariants 由LLM评审并排比较各变体产出
+func (o *Orchestrator) judgeVariants(report *ExperimentReport) error {
+	candidates := make([]*VariantOutput, 0, len(report.Outputs))
+	for _, output := range report.Outputs {
+		if output.Error == "" {
+			candidates = append(candidates, output)
+		}
+	}
+	if len(candidates) == 0 {
+		return fmt.Errorf("所有变体均运行失败")
+	}
+
+	prompt, err := buildExperimentJudgePrompt(report, candidates)
+	if err != nil {
+		return err
+	}
+
+	response, err := o.engine.callWithRetry(prompt, o.buildSystemPrompt("experiment_judge"))
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Scores []struct {
+			Variant string  `json:"variant"`
+			Score   float64 `json:"score"`
+			Comment string  `json:"comment"`
+		} `json:"scores"`
+		Winner  string `json:"winner"`
+		Summary string `json:"summary"`
+	}
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return fmt.Errorf("解析评审结果失败: %w", err)
+	}
+
+	for _, score := range result.Scores {
+		for _, output := range candidates {
+			if output.Variant == score.Variant {
+				output.Score = score.Score
+				output.Comment = score.Comment
+			}
+		}
+	}
+
+	// 以分数为准确定胜者，避免评审给出的名称与分数不一致
+	best := candidates[0]
+	for _, output := range candidates[1:] {
+		if output.Score > best.Score {
+			best = output
+		}
+	}
+	report.Winner = best.Variant
+	report.Summary = result.Summary
+
+	return nil
+}
+
+// buildExperimentJudgePrompt 构建评审提示词
+func buildExperimentJudgePrompt(report *ExperimentReport, candidates []*VariantOutput) (string, error) {
+	var sb strings.Builder
+	for _, output := range candidates {
+		data, err := json.Marshal(output.Output)
+		if err != nil {
+			return "", fmt.Errorf("序列化变体%s产出失败: %w", output.Variant, err)
+		}
+		sb.WriteString(fmt.Sprintf("【方案 %s】\n%s\n\n", output.Variant, string(data)))
+	}
+
+	return fmt.Sprintf(`以下是同一世界设定下，由不同提示词（角色：%s，演化阶段%d）生成的%d份方案：
+
+%s请从以下维度对每份方案评分（0-100）：
+1. 张力：冲突是否尖锐，赌注是否足够高
+2. 深度：是否有多层次的内在与外在矛盾
+3. 主题关联：是否与世界观和核心问题紧密相连
+4. 可写性：是否给后续章节留出足够的演化空间
+
+请以JSON格式返回：
+{
+  "scores": [
+    {"variant": "方案名称", "score": 85, "comment": "评审意见"}
+  ],
+  "winner": "最佳方案名称",
+  "summary": "总体对比结论"
+}
+只返回JSON，不要包含其他内容。`,
+		report.Role,
+		report.Phase,
+		len(candidates),
+		sb.String()), nil
+}
@@ -0,0 +1,35 @@
+// Package narrative 提示词实验测试
+package narrative
+
+import "testing"
+
+// TestExperimentParamsValidate 测试实验参数校验
+func TestExperimentParamsValidate(t *testing.T) {
+	variants := func(names ...string) []PromptVariant {
+		out := make([]PromptVariant, len(names))
+		for i, n := range names {
+			out[i] = PromptVariant{Name: n, SystemPrompt: "提示词" + n}
+		}
+		return out
+	}
+
+	tests := []struct {
+		name    string
+		params  ExperimentParams
+		wantErr bool
+	}{
+		{name: "两个变体", params: ExperimentParams{Phase: 4, Variants: variants("A", "B")}},
+		{name: "只有一个变体", params: ExperimentParams{Phase: 4, Variants: variants("A")}, wantErr: true},
+		{name: "名称为空", params: ExperimentParams{Phase: 4, Variants: variants("A", " ")}, wantErr: true},
+		{name: "名称重复", params: ExperimentParams{Phase: 4, Variants: variants("A", "B", "A")}, wantErr: true},
+		{name: "阶段越界", params: ExperimentParams{Phase: 6, Variants: variants("A", "B")}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.params.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
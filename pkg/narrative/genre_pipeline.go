@@ -0,0 +1,292 @@
+// Package narrative 叙事器 - 类型专属演化流水线
+// 不同类型的故事使用不同的演化轮次组合：悬疑增加线索/红鲱鱼规划，言情增加感情节拍规划等
+package narrative
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/pkg/ctxbudget"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/promptctx"
+)
+
+// Genre 故事类型
+type Genre string
+
+const (
+	GenreGeneral Genre = ""        // 通用（默认演化序列）
+	GenreXianxia Genre = "xianxia" // 仙侠/修真
+	GenreRomance Genre = "romance" // 言情
+	GenreMystery Genre = "mystery" // 悬疑/推理
+	GenreScifi   Genre = "scifi"   // 科幻
+)
+
+// 类型专属演化轮次
+const (
+	RoundCultivationLadder EvolutionRound = "cultivation_ladder" // 境界阶梯规划（仙侠）
+	RoundRelationshipBeat  EvolutionRound = "relationship_beat"  // 感情节拍规划（言情）
+	RoundClueDesign        EvolutionRound = "clue_design"        // 线索设计（悬疑）
+	RoundRedHerring        EvolutionRound = "red_herring"        // 红鲱鱼设计（悬疑）
+	RoundTechPremise       EvolutionRound = "tech_premise"       // 科技设定推演（科幻）
+)
+
+// GenrePipeline 类型演化流水线
+type GenrePipeline struct {
+	Genre       Genre            `json:"genre"`
+	Name        string           `json:"name"`
+	Description string           `json:"description"`
+	Rounds      []EvolutionRound `json:"rounds"`
+}
+
+// GenreBeat 类型专属规划条目（境界、感情节拍、线索、红鲱鱼、科技设定）
+type GenreBeat struct {
+	Round       EvolutionRound `json:"round"`                // 产生该条目的演化轮次
+	Title       string         `json:"title"`                // 简短标题
+	Description string         `json:"description"`          // 具体内容
+	Characters  []string       `json:"characters,omitempty"` // 相关角色
+	Position    string         `json:"position,omitempty"`   // 在故事中的位置（开端/发展/高潮/结局）
+}
+
+// defaultPipeline 默认演化序列
+var defaultPipeline = GenrePipeline{
+	Genre:       GenreGeneral,
+	Name:        "通用",
+	Description: "适用于大多数类型的默认演化序列",
+	Rounds: []EvolutionRound{
+		RoundCharacterCreation,
+		RoundConflictDesign,
+		RoundCharacterDeepen,
+		RoundConflictEvolution,
+		RoundForeshadowPlant,
+		RoundThemeDeepen,
+		RoundConflictEvolution,
+		RoundForeshadowWeave,
+		RoundPlotTwist,
+		RoundResolutionPlan,
+	},
+}
+
+// genrePipelines 类型专属流水线
+// 类型专属轮次放在靠前位置，避免被轮次上限或质量自动停止截断
+var genrePipelines = map[Genre]GenrePipeline{
+	GenreXianxia: {
+		Genre:       GenreXianxia,
+		Name:        "仙侠",
+		Description: "先确定境界阶梯，再让冲突与突破节点对齐",
+		Rounds: []EvolutionRound{
+			RoundCharacterCreation,
+			RoundCultivationLadder,
+			RoundConflictDesign,
+			RoundCharacterDeepen,
+			RoundConflictEvolution,
+			RoundForeshadowPlant,
+			RoundConflictEvolution,
+			RoundPlotTwist,
+			RoundResolutionPlan,
+		},
+	},
+	GenreRomance: {
+		Genre:       GenreRomance,
+		Name:        "言情",
+		Description: "以感情节拍为主线，冲突服务于关系推进",
+		Rounds: []EvolutionRound{
+			RoundCharacterCreation,
+			RoundCharacterDeepen,
+			RoundRelationshipBeat,
+			RoundConflictDesign,
+			RoundConflictEvolution,
+			RoundThemeDeepen,
+			RoundForeshadowPlant,
+			RoundPlotTwist,
+			RoundResolutionPlan,
+		},
+	},
+	GenreMystery: {
+		Genre:       GenreMystery,
+		Name:        "悬疑",
+		Description: "先设计真相与线索，再布置红鲱鱼和伏笔",
+		Rounds: []EvolutionRound{
+			RoundCharacterCreation,
+			RoundConflictDesign,
+			RoundClueDesign,
+			RoundRedHerring,
+			RoundForeshadowPlant,
+			RoundCharacterDeepen,
+			RoundPlotTwist,
+			RoundResolutionPlan,
+		},
+	},
+	GenreScifi: {
+		Genre:       GenreScifi,
+		Name:        "科幻",
+		Description: "先推演核心科技设定及其社会影响，冲突由设定推出",
+		Rounds: []EvolutionRound{
+			RoundTechPremise,
+			RoundCharacterCreation,
+			RoundConflictDesign,
+			RoundCharacterDeepen,
+			RoundConflictEvolution,
+			RoundThemeDeepen,
+			RoundForeshadowPlant,
+			RoundPlotTwist,
+			RoundResolutionPlan,
+		},
+	},
+}
+
+// PipelineForGenre 获取类型的演化流水线，未知类型返回默认序列
+func PipelineForGenre(genre Genre) GenrePipeline {
+	if pipeline, ok := genrePipelines[genre]; ok {
+		return pipeline
+	}
+	return defaultPipeline
+}
+
+// ParseGenre 解析类型名称，兼容中文
+func ParseGenre(s string) Genre {
+	switch strings.ToLower(strings.TrimSpace(s)) {
+	case "xianxia", "仙侠", "修真", "玄幻":
+		return GenreXianxia
+	case "romance", "言情", "爱情":
+		return GenreRomance
+	case "mystery", "悬疑", "推理", "侦探":
+		return GenreMystery
+	case "scifi", "sci-fi", "科幻":
+		return GenreScifi
+	default:
+		return GenreGeneral
+	}
+}
+
+// genreRoundSpec 类型专属轮次的提示词规格
+type genreRoundSpec struct {
+	label string // 轮次名称
+	role  string // 系统提示词中的角色
+	task  string // 任务说明
+	count int    // 期望条目数
+}
+
+var genreRoundSpecs = map[EvolutionRound]genreRoundSpec{
+	RoundCultivationLadder: {
+		label: "境界阶梯",
+		role:  "你是一位资深仙侠小说策划，擅长设计清晰、有代价的修炼体系。",
+		task:  "设计主角的境界阶梯：每个境界的名称、突破条件、突破代价，以及它对应故事的哪个阶段。突破应与冲突升级同步，不能无代价升级。",
+		count: 6,
+	},
+	RoundRelationshipBeat: {
+		label: "感情节拍",
+		role:  "你是一位资深言情小说策划，擅长设计张弛有度的感情线。",
+		task:  "为主要感情线设计节拍：相遇、吸引、阻碍、靠近、误会/危机、分离、和解/告白。每个节拍说明发生了什么、关系发生了什么变化、涉及哪些角色。",
+		count: 7,
+	},
+	RoundClueDesign: {
+		label: "线索",
+		role:  "你是一位资深推理小说策划，遵守公平推理原则，读者应当能和侦探同时拿到所有关键线索。",
+		task:  "先确定真相（谁、怎么做、为什么），再设计指向真相的关键线索。每条线索说明内容、由谁发现、在故事的哪个阶段出现；揭晓前所有必需线索都必须已经呈现给读者。",
+		count: 6,
+	},
+	RoundRedHerring: {
+		label: "红鲱鱼",
+		role:  "你是一位资深推理小说策划，擅长设计公平的误导。",
+		task:  "设计误导读者的红鲱鱼：每条说明它指向的错误结论、为什么看起来可信、在何处被排除。红鲱鱼不能与真相矛盾，排除时要让读者觉得合理。",
+		count: 3,
+	},
+	RoundTechPremise: {
+		label: "科技设定",
+		role:  "你是一位硬科幻策划，擅长从一个科技前提推演出社会、伦理和个人层面的后果。",
+		task:  "确定故事的核心科技前提及其限制，推演它带来的社会结构变化、伦理困境和个人代价。每条说明设定内容、它在故事中如何制造冲突。",
+		count: 4,
+	},
+}
+
+// evolveGenreRound 执行类型专属轮次，结果记录为类型规划条目
+func (ee *EvolutionEngine) evolveGenreRound(state *EvolutionState, round EvolutionRound) (*EvolutionResult, error) {
+	spec := genreRoundSpecs[round]
+
+	result, err := ee.callWithRetry(ee.buildGenreRoundPrompt(state, spec), spec.role)
+	if err != nil {
+		return nil, err
+	}
+
+	var output struct {
+		Items []*GenreBeat `json:"items"`
+	}
+	jsonx.Unmarshal(result, &output)
+
+	changes := make([]string, 0, len(output.Items))
+	for _, item := range output.Items {
+		if item == nil || item.Title == "" {
+			continue
+		}
+		item.Round = round
+		state.GenreBeats = append(state.GenreBeats, item)
+		changes = append(changes, item.Title)
+	}
+
+	return &EvolutionResult{
+		Round:        state.CurrentRound,
+		Type:         string(round),
+		Summary:      fmt.Sprintf("规划了%d个%s", len(changes), spec.label),
+		Changes:      changes,
+		QualityScore: ee.calculateOverallQuality(state),
+	}, nil
+}
+
+// buildGenreRoundPrompt 构建类型专属轮次提示词
+func (ee *EvolutionEngine) buildGenreRoundPrompt(state *EvolutionState, spec genreRoundSpec) string {
+	var prompt strings.Builder
+	sections := promptctx.Compose(promptContext(state))
+
+	prompt.WriteString(fmt.Sprintf("# %s规划任务\n\n", spec.label))
+	sections.Cut(&prompt, "标题", ctxbudget.Required).
+		Add(promptctx.WorldContext(), ctxbudget.Normal).
+		Add(promptctx.Characters(promptctx.CharacterOptions{}), ctxbudget.High).
+		Add(promptctx.Conflicts(promptctx.ConflictOptions{}), ctxbudget.High)
+
+	// 同类已有条目（例如设计红鲱鱼时需要知道真实线索）
+	if existing := state.genreBeatsSection(); existing != "" {
+		prompt.WriteString("\n## 已有类型规划\n")
+		prompt.WriteString(existing)
+	}
+
+	sections.Cut(&prompt, "已有类型规划", ctxbudget.High)
+
+	prompt.WriteString("\n# 任务\n")
+	prompt.WriteString(spec.task)
+	prompt.WriteString(fmt.Sprintf("\n请给出约%d条。\n", spec.count))
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "items": [
+    {
+      "title": "简短标题",
+      "description": "具体内容",
+      "characters": ["相关角色名"],
+      "position": "开端/发展/高潮/结局"
+    }
+  ]
+}`)
+
+	sections.Cut(&prompt, "任务", ctxbudget.Required)
+
+	return ee.fitPrompt(sections)
+}
+
+// genreBeatsSection 类型规划条目的文本摘要，供后续提示词使用
+func (s *EvolutionState) genreBeatsSection() string {
+	if len(s.GenreBeats) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, beat := range s.GenreBeats {
+		label := genreRoundSpecs[beat.Round].label
+		sb.WriteString(fmt.Sprintf("- [%s] %s: %s", label, beat.Title, beat.Description))
+		if beat.Position != "" {
+			sb.WriteString(fmt.Sprintf("（%s）", beat.Position))
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
@@ -0,0 +1,174 @@
+// Package narrative 叙事器 - 关系图导出
+// 将角色关系网络导出为 GraphViz DOT 或 D3 友好的 JSON 图
+package narrative
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// GraphNode 关系图节点
+type GraphNode struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Role   string `json:"role,omitempty"`
+	Center bool   `json:"center,omitempty"`
+}
+
+// GraphLink 关系图边（D3 的 source/target 约定）
+type GraphLink struct {
+	Source  string `json:"source"`
+	Target  string `json:"target"`
+	Type    string `json:"type"`
+	Tension int    `json:"tension"` // 0-100
+}
+
+// RelationshipGraph 关系图
+type RelationshipGraph struct {
+	Nodes  []GraphNode `json:"nodes"`
+	Links  []GraphLink `json:"links"`
+	Center string      `json:"center,omitempty"`
+}
+
+// GraphFromNetwork 从演化状态的关系网络构建关系图
+func GraphFromNetwork(network *RelationshipNetwork) *RelationshipGraph {
+	graph := &RelationshipGraph{
+		Nodes: make([]GraphNode, 0),
+		Links: make([]GraphLink, 0),
+	}
+	if network == nil {
+		return graph
+	}
+	graph.Center = network.CenterNode
+
+	for id, char := range network.Nodes {
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:     id,
+			Name:   char.Name,
+			Role:   char.Role,
+			Center: id == network.CenterNode,
+		})
+	}
+	for _, edge := range network.Edges {
+		graph.Links = append(graph.Links, GraphLink{
+			Source:  edge.From,
+			Target:  edge.To,
+			Type:    edge.Type,
+			Tension: edge.Tension,
+		})
+	}
+
+	graph.sort()
+	return graph
+}
+
+// GraphFromCharacters 从已保存的角色档案构建关系图
+// 双向关系只保留一条边，紧张度由信任度换算（信任越低越紧张）
+func GraphFromCharacters(chars []*models.Character) *RelationshipGraph {
+	graph := &RelationshipGraph{
+		Nodes: make([]GraphNode, 0, len(chars)),
+		Links: make([]GraphLink, 0),
+	}
+
+	known := make(map[string]bool, len(chars))
+	for _, char := range chars {
+		known[char.ID] = true
+		graph.Nodes = append(graph.Nodes, GraphNode{
+			ID:   char.ID,
+			Name: char.Name,
+			Role: char.StaticProfile.Occupation,
+		})
+	}
+
+	seen := make(map[string]bool)
+	for _, char := range chars {
+		for target, rel := range char.NarrativeProfile.Relationships {
+			if rel == nil {
+				continue
+			}
+			if rel.CharacterID != "" {
+				target = rel.CharacterID
+			}
+			if !known[target] {
+				continue
+			}
+			key := getRelationshipKey(char.ID, target)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+
+			relType := rel.Attitude
+			if relType == "" {
+				relType = rel.Power
+			}
+			graph.Links = append(graph.Links, GraphLink{
+				Source:  char.ID,
+				Target:  target,
+				Type:    relType,
+				Tension: clampTension(100 - rel.TrustLevel),
+			})
+		}
+	}
+
+	graph.sort()
+	return graph
+}
+
+// DOT 渲染为 GraphViz DOT
+func (g *RelationshipGraph) DOT() string {
+	var sb strings.Builder
+	sb.WriteString("graph relationships {\n")
+	sb.WriteString("  node [shape=ellipse, fontname=\"sans-serif\"];\n")
+
+	for _, node := range g.Nodes {
+		attrs := fmt.Sprintf("label=%s", dotQuote(node.Name))
+		if node.Role != "" {
+			attrs += fmt.Sprintf(", role=%s", dotQuote(node.Role))
+		}
+		if node.Center {
+			attrs += ", shape=doublecircle, style=bold"
+		}
+		sb.WriteString(fmt.Sprintf("  %s [%s];\n", dotQuote(node.ID), attrs))
+	}
+
+	for _, link := range g.Links {
+		sb.WriteString(fmt.Sprintf("  %s -- %s [label=%s, type=%s, tension=%d, penwidth=%.1f];\n",
+			dotQuote(link.Source), dotQuote(link.Target),
+			dotQuote(link.Type), dotQuote(link.Type), link.Tension,
+			1+float64(link.Tension)/25))
+	}
+
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// sort 按ID排序，保证输出稳定
+func (g *RelationshipGraph) sort() {
+	sort.Slice(g.Nodes, func(i, j int) bool { return g.Nodes[i].ID < g.Nodes[j].ID })
+	sort.Slice(g.Links, func(i, j int) bool {
+		if g.Links[i].Source != g.Links[j].Source {
+			return g.Links[i].Source < g.Links[j].Source
+		}
+		return g.Links[i].Target < g.Links[j].Target
+	})
+}
+
+// dotQuote 转义为 DOT 字符串
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// clampTension 紧张度限制在0-100
+func clampTension(v int) int {
+	if v < 0 {
+		return 0
+	}
+	if v > 100 {
+		return 100
+	}
+	return v
+}
@@ -0,0 +1,99 @@
+// Package narrative 叙事器 - 关键物品提取
+// 从蓝图的大纲、章节规划和场景中找出推动情节、需要追踪去向的物品
+package narrative
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+)
+
+// maxKeyItems 一次提取的关键物品上限
+const maxKeyItems = 20
+
+// keyItemOutput 关键物品提取的 LLM 输出
+type keyItemOutput struct {
+	Items []struct {
+		Name        string   `json:"name"`
+		Aliases     []string `json:"aliases"`
+		Kind        string   `json:"kind"`
+		Description string   `json:"description"`
+		Holder      string   `json:"holder"`
+		Location    string   `json:"location"`
+		Chapter     int      `json:"chapter"`
+	} `json:"items"`
+}
+
+// ExtractKeyItems 从蓝图中提取关键物品，known 为已登记的物品名，不会重复提取
+// 返回的物品未分配ID和项目
+func (ne *NarrativeEngine) ExtractKeyItems(blueprint *models.NarrativeBlueprint, known []string) ([]models.Item, error) {
+	if len(blueprint.ChapterPlans) == 0 && blueprint.StoryOutline.Act1.Setup == "" {
+		return nil, fmt.Errorf("蓝图没有大纲和章节规划")
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("# 关键物品提取\n\n")
+	outline := blueprint.StoryOutline
+	prompt.WriteString("## 故事大纲\n")
+	prompt.WriteString(fmt.Sprintf("开端：%s\n激励事件：%s\n中点：%s\n高潮：%s\n结局：%s\n\n",
+		outline.Act1.Setup, outline.Act1.IncitingIncident, outline.Act2.Midpoint, outline.Act3.Climax, outline.Act3.Resolution))
+	if len(blueprint.ChapterPlans) > 0 {
+		prompt.WriteString("## 章节规划\n")
+		for _, p := range blueprint.ChapterPlans {
+			prompt.WriteString(fmt.Sprintf("第%d章 %s：%s %s\n", p.Chapter, p.Title, p.Purpose, strings.Join(p.KeyScenes, "；")))
+		}
+		prompt.WriteString("\n")
+	}
+	if len(known) > 0 {
+		prompt.WriteString("## 已登记的物品（不要重复）\n" + strings.Join(known, "、") + "\n\n")
+	}
+
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString(fmt.Sprintf("1. 找出最多%d件推动情节、会在多个章节出现或转手的具体物品：兵器（weapon）、法宝神器（artifact）、书信典籍地图（document）、信物令牌钥匙（token）、其他（other）\n", maxKeyItems))
+	prompt.WriteString("2. 普通的衣物、食物、家具等不算；抽象概念和功法不算\n")
+	prompt.WriteString("3. holder 为故事开始（或物品登场）时的持有人，不在人手中时写 location；chapter 为首次登场的章节，开篇前就存在的写 0\n")
+	prompt.WriteString("4. aliases 写正文中可能出现的其他称呼\n")
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{"items": [{"name": "物品名", "aliases": ["别称"], "kind": "weapon", "description": "外观与作用", "holder": "持有人", "location": "所在地", "chapter": 0}]}`)
+
+	result, err := ne.callWithRetry(prompt.String(), `你是一位小说连续性编辑，负责整理需要在全书中追踪去向的关键物品。只提取大纲和规划中明确出现的物品，不要虚构。`)
+	if err != nil {
+		return nil, fmt.Errorf("提取关键物品失败: %w", err)
+	}
+
+	var output keyItemOutput
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, fmt.Errorf("解析关键物品失败: %w", err)
+	}
+
+	seen := make(map[string]bool, len(known))
+	for _, name := range known {
+		seen[name] = true
+	}
+	extracted := make([]models.Item, 0, len(output.Items))
+	for _, it := range output.Items {
+		name := strings.TrimSpace(it.Name)
+		if name == "" || seen[name] || len(extracted) >= maxKeyItems {
+			continue
+		}
+		seen[name] = true
+		switch it.Kind {
+		case models.ItemWeapon, models.ItemArtifact, models.ItemDocument, models.ItemToken:
+		default:
+			it.Kind = models.ItemOther
+		}
+		extracted = append(extracted, models.Item{
+			Name:        name,
+			Aliases:     it.Aliases,
+			Kind:        it.Kind,
+			Description: it.Description,
+			Holder:      strings.TrimSpace(it.Holder),
+			Location:    strings.TrimSpace(it.Location),
+			Chapter:     max(0, it.Chapter),
+			Source:      models.ItemFromStory,
+		})
+	}
+	return extracted, nil
+}
@@ -0,0 +1,826 @@
+// Package narrative 叙事器 - 已有书稿分析与续写规划
+// 按批阅读已写好的章节，逐步积累角色、世界事实、情节线和伏笔，重建演化状态，再从下一章起规划续写
+package narrative
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/ctxbudget"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/jsonx"
+)
+
+const (
+	// manuscriptBatchTokens 每批送去分析的正文token数上限
+	manuscriptBatchTokens = 12000
+	// manuscriptChapterTokens 单章正文的token数上限，超出时截去中间部分
+	manuscriptChapterTokens = 6000
+	// maxContinuationChapters 一次续写规划的章节数上限
+	maxContinuationChapters = 30
+)
+
+// ManuscriptChapter 待分析的已有章节
+type ManuscriptChapter struct {
+	Num       int
+	Title     string
+	Content   string
+	WordCount int
+}
+
+// ManuscriptCharacter 从书稿中识别的角色
+type ManuscriptCharacter struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	Role          string   `json:"role"` // 主角/反派/配角
+	Identity      string   `json:"identity"`
+	Personality   string   `json:"personality"`
+	Want          string   `json:"want"`
+	Need          string   `json:"need"`
+	Fear          string   `json:"fear"`
+	Secrets       []string `json:"secrets"`
+	Emotion       string   `json:"emotion"`  // 最近一章结束时的情绪
+	Location      string   `json:"location"` // 最近一章结束时所在地
+	Relationships []struct {
+		Target   string `json:"target"`
+		Relation string `json:"relation"`
+		Tension  string `json:"tension"`
+	} `json:"relationships"`
+	FirstChapter int `json:"first_chapter"`
+}
+
+// ManuscriptFact 从正文中确立的细节，写入连续性事实库
+type ManuscriptFact struct {
+	Chapter      int    `json:"chapter"`
+	Subject      string `json:"subject"`
+	Attribute    string `json:"attribute"`
+	Value        string `json:"value"`
+	Category     string `json:"category"`
+	Permanent    bool   `json:"permanent"`
+	Evidence     string `json:"evidence"`
+	EndedChapter int    `json:"-"` // 被后续章节的新值取代的章节
+}
+
+// ManuscriptAnalysis 书稿分析结果
+type ManuscriptAnalysis struct {
+	World       *models.WorldSetting   // 由书稿归纳的世界设定（尚未保存）
+	Characters  []*ManuscriptCharacter // 按首次出场排序
+	Facts       []*ManuscriptFact
+	Summaries   map[int]string      // 章节号 -> 本章摘要
+	StorySoFar  map[int]string      // 章节号 -> 截至该章的故事梗概（每批最后一章）
+	Outline     models.StoryOutline // 已写部分的大纲和预期的高潮、结局
+	State       *EvolutionState     // 重建的演化状态
+	LastChapter int                 // 已写的最后一章
+}
+
+// ManuscriptProgress 分析进度回调，done/total 为已分析/全部章节数
+type ManuscriptProgress func(stage string, done, total int)
+
+// manuscriptBatchOutput 一批章节的分析输出
+type manuscriptBatchOutput struct {
+	Chapters []struct {
+		Chapter int    `json:"chapter"`
+		Summary string `json:"summary"`
+	} `json:"chapters"`
+	Characters  []*ManuscriptCharacter `json:"characters"`
+	Facts       []*ManuscriptFact      `json:"facts"`
+	PlotThreads []struct {
+		Name       string   `json:"name"`
+		Type       string   `json:"type"`
+		Characters []string `json:"characters"`
+		Status     string   `json:"status"`
+		Tension    int      `json:"tension"`
+		Events     []struct {
+			Chapter     int    `json:"chapter"`
+			Description string `json:"description"`
+			Resolves    bool   `json:"resolves"`
+		} `json:"events"`
+	} `json:"plot_threads"`
+	Foreshadows []struct {
+		Content string `json:"content"`
+		Type    string `json:"type"`
+		Chapter int    `json:"chapter"`
+	} `json:"foreshadows"`
+	PaidOff []struct {
+		ID      string `json:"id"`
+		Chapter int    `json:"chapter"`
+		Scene   string `json:"scene"`
+	} `json:"paid_off"`
+	StorySoFar string `json:"story_so_far"`
+}
+
+// manuscriptSynthesisOutput 全部章节读完后的归纳输出
+type manuscriptSynthesisOutput struct {
+	World struct {
+		Name             string `json:"name"`
+		Type             string `json:"type"`
+		Scale            string `json:"scale"`
+		Style            string `json:"style"`
+		CoreQuestion     string `json:"core_question"`
+		TechnologyLevel  string `json:"technology_level"`
+		GeographySummary string `json:"geography_summary"`
+		SocialConflicts  []struct {
+			Type        string   `json:"type"`
+			Description string   `json:"description"`
+			Parties     []string `json:"parties"`
+		} `json:"social_conflicts"`
+	} `json:"world"`
+	Theme     string `json:"theme"`
+	StoryHook string `json:"story_hook"`
+	Conflicts []struct {
+		Type         string   `json:"type"`
+		CoreQuestion string   `json:"core_question"`
+		Participants []string `json:"participants"`
+		Intensity    int      `json:"intensity"`
+		Stakes       []string `json:"stakes"`
+		Resolved     bool     `json:"resolved"`
+	} `json:"conflicts"`
+	Outline struct {
+		Setup            string   `json:"setup"`
+		IncitingIncident string   `json:"inciting_incident"`
+		PlotPoint1       string   `json:"plot_point1"`
+		RisingAction     []string `json:"rising_action"`
+		Midpoint         string   `json:"midpoint"`
+		Climax           string   `json:"climax"`
+		Resolution       string   `json:"resolution"`
+	} `json:"outline"`
+}
+
+// manuscriptAccumulator 逐批合并的分析结果
+type manuscriptAccumulator struct {
+	characters []*ManuscriptCharacter
+	byName     map[string]*ManuscriptCharacter
+	facts      []*ManuscriptFact
+	threads    []*PlotThread
+	foreshadow []*Foreshadow
+	summaries  map[int]string
+	soFar      map[int]string
+	storySoFar string
+}
+
+// AnalyzeManuscript 分析已有书稿：按批阅读章节，提取角色、细节、情节线和伏笔，最后归纳世界设定和冲突，重建演化状态
+func (ne *NarrativeEngine) AnalyzeManuscript(chapters []ManuscriptChapter, progress ManuscriptProgress) (*ManuscriptAnalysis, error) {
+	if len(chapters) == 0 {
+		return nil, fmt.Errorf("书稿没有章节")
+	}
+	if progress == nil {
+		progress = func(string, int, int) {}
+	}
+
+	acc := &manuscriptAccumulator{
+		byName:    make(map[string]*ManuscriptCharacter),
+		summaries: make(map[int]string),
+		soFar:     make(map[int]string),
+	}
+	batches := batchManuscript(chapters)
+	done := 0
+	for i, batch := range batches {
+		progress("analyze", done, len(chapters))
+		ne.log().Info("分析书稿", "batch", i+1, "batches", len(batches), "from", batch[0].Num, "to", batch[len(batch)-1].Num)
+		result, err := ne.callWithRetry(ne.buildManuscriptBatchPrompt(acc, batch),
+			`你是一位资深网络小说责任编辑，正在通读作者已写好的书稿，为续写整理设定。只记录正文中明确写出的内容，不要臆测；人名、地名与正文保持一致。`)
+		if err != nil {
+			return nil, fmt.Errorf("分析第%d-%d章失败: %w", batch[0].Num, batch[len(batch)-1].Num, err)
+		}
+		var output manuscriptBatchOutput
+		if err := jsonx.Unmarshal(result, &output); err != nil {
+			return nil, fmt.Errorf("解析第%d-%d章的分析结果失败: %w", batch[0].Num, batch[len(batch)-1].Num, err)
+		}
+		acc.merge(&output, batch)
+		done += len(batch)
+	}
+
+	progress("synthesize", done, len(chapters))
+	result, err := ne.callWithRetry(ne.buildManuscriptSynthesisPrompt(acc),
+		`你是一位资深网络小说策划编辑，根据已写部分归纳作品的世界观、主题和核心冲突，并判断故事接下来应当走向的高潮与结局。`)
+	if err != nil {
+		return nil, fmt.Errorf("归纳书稿设定失败: %w", err)
+	}
+	var synthesis manuscriptSynthesisOutput
+	if err := jsonx.Unmarshal(result, &synthesis); err != nil {
+		return nil, fmt.Errorf("解析书稿设定失败: %w", err)
+	}
+
+	analysis := &ManuscriptAnalysis{
+		World:       manuscriptWorld(&synthesis),
+		Characters:  acc.characters,
+		Facts:       acc.facts,
+		Summaries:   acc.summaries,
+		StorySoFar:  acc.soFar,
+		LastChapter: chapters[len(chapters)-1].Num,
+		Outline: models.StoryOutline{
+			StructureType: string(StructureThreeAct),
+			Act1: models.Act1{
+				Setup:            synthesis.Outline.Setup,
+				IncitingIncident: synthesis.Outline.IncitingIncident,
+				PlotPoint1:       synthesis.Outline.PlotPoint1,
+			},
+			Act2: models.Act2{
+				RisingAction: synthesis.Outline.RisingAction,
+				Midpoint:     synthesis.Outline.Midpoint,
+			},
+			Act3: models.Act3{
+				Climax:     synthesis.Outline.Climax,
+				Resolution: synthesis.Outline.Resolution,
+			},
+		},
+	}
+	analysis.State = ne.manuscriptState(analysis, acc, &synthesis)
+	progress("done", done, len(chapters))
+	return analysis, nil
+}
+
+// batchManuscript 按token预算把章节分批，单章过长时截去中间部分
+func batchManuscript(chapters []ManuscriptChapter) [][]ManuscriptChapter {
+	var batches [][]ManuscriptChapter
+	var current []ManuscriptChapter
+	tokens := 0
+	for _, ch := range chapters {
+		ch.Content = ctxbudget.ClipMiddle(ch.Content, manuscriptChapterTokens)
+		n := ctxbudget.Count(ch.Content)
+		if len(current) > 0 && tokens+n > manuscriptBatchTokens {
+			batches = append(batches, current)
+			current, tokens = nil, 0
+		}
+		current = append(current, ch)
+		tokens += n
+	}
+	if len(current) > 0 {
+		batches = append(batches, current)
+	}
+	return batches
+}
+
+// merge 合并一批的分析结果：角色按名字合并，同一细节出现新值时旧值在该章结束，伏笔按编号标记回收
+func (acc *manuscriptAccumulator) merge(output *manuscriptBatchOutput, batch []ManuscriptChapter) {
+	first, last := batch[0].Num, batch[len(batch)-1].Num
+	inBatch := func(n int) int {
+		if n < first || n > last {
+			return last
+		}
+		return n
+	}
+
+	for _, ch := range output.Chapters {
+		if s := strings.TrimSpace(ch.Summary); s != "" {
+			acc.summaries[inBatch(ch.Chapter)] = s
+		}
+	}
+
+	for _, c := range output.Characters {
+		name := strings.TrimSpace(c.Name)
+		if name == "" {
+			continue
+		}
+		existing, ok := acc.byName[name]
+		if !ok {
+			c.Name = name
+			c.ID = db.GenerateID("char")
+			c.FirstChapter = inBatch(c.FirstChapter)
+			acc.byName[name] = c
+			acc.characters = append(acc.characters, c)
+			continue
+		}
+		mergeString(&existing.Role, c.Role)
+		mergeString(&existing.Identity, c.Identity)
+		mergeString(&existing.Personality, c.Personality)
+		mergeString(&existing.Want, c.Want)
+		mergeString(&existing.Need, c.Need)
+		mergeString(&existing.Fear, c.Fear)
+		mergeString(&existing.Emotion, c.Emotion)
+		mergeString(&existing.Location, c.Location)
+		existing.Secrets = appendUnique(existing.Secrets, c.Secrets...)
+		if len(c.Relationships) > 0 {
+			existing.Relationships = c.Relationships
+		}
+	}
+
+	current := make(map[string]*ManuscriptFact)
+	for _, f := range acc.facts {
+		if f.EndedChapter == 0 {
+			current[f.Subject+"/"+f.Attribute] = f
+		}
+	}
+	for _, f := range output.Facts {
+		if strings.TrimSpace(f.Subject) == "" || strings.TrimSpace(f.Attribute) == "" || strings.TrimSpace(f.Value) == "" {
+			continue
+		}
+		f.Chapter = inBatch(f.Chapter)
+		key := f.Subject + "/" + f.Attribute
+		if old, ok := current[key]; ok {
+			if old.Value == f.Value {
+				continue
+			}
+			old.EndedChapter = f.Chapter
+		}
+		current[key] = f
+		acc.facts = append(acc.facts, f)
+	}
+
+	threads := make(map[string]*PlotThread, len(acc.threads))
+	for _, t := range acc.threads {
+		threads[t.Name] = t
+	}
+	for _, t := range output.PlotThreads {
+		name := strings.TrimSpace(t.Name)
+		if name == "" {
+			continue
+		}
+		thread, ok := threads[name]
+		if !ok {
+			thread = &PlotThread{
+				ID:   fmt.Sprintf("import_thread_%d", len(acc.threads)+1),
+				Name: name,
+				Type: t.Type,
+			}
+			threads[name] = thread
+			acc.threads = append(acc.threads, thread)
+		}
+		thread.Characters = appendUnique(thread.Characters, t.Characters...)
+		if t.Status != "" {
+			thread.Status = t.Status
+		}
+		if t.Tension > 0 {
+			thread.Tension = t.Tension
+		}
+		for _, e := range t.Events {
+			thread.KeyEvents = append(thread.KeyEvents, PlotEvent{
+				Sequence:    len(thread.KeyEvents) + 1,
+				Chapter:     inBatch(e.Chapter),
+				Description: e.Description,
+				Tension:     thread.Tension,
+				Resolves:    e.Resolves,
+			})
+			if e.Resolves {
+				thread.Status = "resolved"
+			}
+		}
+	}
+
+	for _, p := range output.PaidOff {
+		for _, f := range acc.foreshadow {
+			if f.ID == p.ID && !f.IsPaidOff {
+				f.IsPaidOff = true
+				f.PayoffRound = inBatch(p.Chapter)
+				f.PayoffScene = p.Scene
+			}
+		}
+	}
+	for _, f := range output.Foreshadows {
+		if strings.TrimSpace(f.Content) == "" {
+			continue
+		}
+		chapter := inBatch(f.Chapter)
+		acc.foreshadow = append(acc.foreshadow, &Foreshadow{
+			ID:         fmt.Sprintf("import_foreshadow_%d", len(acc.foreshadow)+1),
+			Type:       f.Type,
+			Content:    f.Content,
+			PlantRound: chapter,
+			PlantScene: fmt.Sprintf("第%d章", chapter),
+			IsPlanted:  true,
+		})
+	}
+
+	if s := strings.TrimSpace(output.StorySoFar); s != "" {
+		acc.storySoFar = s
+		acc.soFar[last] = s
+	}
+}
+
+// mergeString 新值非空时覆盖
+func mergeString(dst *string, v string) {
+	if v = strings.TrimSpace(v); v != "" {
+		*dst = v
+	}
+}
+
+// appendUnique 追加不重复的非空字符串
+func appendUnique(list []string, items ...string) []string {
+	for _, item := range items {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		dup := false
+		for _, existing := range list {
+			if existing == item {
+				dup = true
+				break
+			}
+		}
+		if !dup {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// manuscriptWorld 由归纳结果生成世界设定，类型和规模不在取值范围内时取混合、国家级
+func manuscriptWorld(s *manuscriptSynthesisOutput) *models.WorldSetting {
+	world := &models.WorldSetting{
+		ID:    db.GenerateID("world"),
+		Name:  s.World.Name,
+		Type:  models.WorldMixed,
+		Scale: models.ScaleNation,
+		Style: s.World.Style,
+	}
+	if world.Name == "" {
+		world.Name = "导入书稿的世界"
+	}
+	switch t := models.WorldType(s.World.Type); t {
+	case models.WorldFantasy, models.WorldScifi, models.WorldHistorical, models.WorldUrban, models.WorldWuxia, models.WorldXianxia:
+		world.Type = t
+	}
+	switch sc := models.WorldScale(s.World.Scale); sc {
+	case models.ScaleVillage, models.ScaleCity, models.ScaleContinent, models.ScalePlanet, models.ScaleUniverse:
+		world.Scale = sc
+	}
+	world.Philosophy.CoreQuestion = s.World.CoreQuestion
+	world.SettingConstraints.TechnologyLevel = s.World.TechnologyLevel
+	world.SettingConstraints.GeographySummary = s.World.GeographySummary
+	for _, c := range s.World.SocialConflicts {
+		world.StorySoil.SocialConflicts = append(world.StorySoil.SocialConflicts, models.Conflict{
+			Type:        c.Type,
+			Description: c.Description,
+			Parties:     c.Parties,
+		})
+	}
+	return world
+}
+
+// manuscriptState 用分析结果重建演化状态，角色以 ManuscriptCharacter.ID 为键
+func (ne *NarrativeEngine) manuscriptState(a *ManuscriptAnalysis, acc *manuscriptAccumulator, s *manuscriptSynthesisOutput) *EvolutionState {
+	state := &EvolutionState{
+		MaxRounds:     10,
+		WorldContext:  a.World,
+		Characters:    make(map[string]*CharacterState, len(a.Characters)),
+		Conflicts:     make([]*ConflictThread, 0, len(s.Conflicts)),
+		Foreshadowing: acc.foreshadow,
+		PlotThreads:   acc.threads,
+		ThemeEvolution: &ThemeEvolutionState{
+			CoreTheme:      s.Theme,
+			ThematicLayers: make([]ThematicLayer, 0),
+			SymbolTracker:  make(map[string]*Symbol),
+			MotifProgress:  make(map[string]int),
+		},
+		StoryHook:    s.StoryHook,
+		EvolutionLog: make([]EvolutionLogEntry, 0),
+	}
+	if state.ThemeEvolution.CoreTheme == "" {
+		state.ThemeEvolution.CoreTheme = a.World.Philosophy.CoreQuestion
+	}
+	if state.Foreshadowing == nil {
+		state.Foreshadowing = make([]*Foreshadow, 0)
+	}
+	if state.PlotThreads == nil {
+		state.PlotThreads = make([]*PlotThread, 0)
+	}
+
+	ids := make(map[string]string, len(a.Characters))
+	for _, c := range a.Characters {
+		ids[c.Name] = c.ID
+	}
+	for _, c := range a.Characters {
+		cs := &CharacterState{
+			ID:   c.ID,
+			Name: c.Name,
+			Role: c.Role,
+			EmotionalState: EmotionalSystem{
+				CurrentEmotion: c.Emotion,
+			},
+			Desires: DesireSystem{
+				ConsciousWant:   c.Want,
+				UnconsciousNeed: c.Need,
+				Fear:            c.Fear,
+			},
+			Relationships: make(map[string]*RelationshipState),
+			Secrets:       c.Secrets,
+		}
+		for _, r := range c.Relationships {
+			target, ok := ids[r.Target]
+			if !ok {
+				continue
+			}
+			rel := &RelationshipState{TargetCharacterID: target, PowerDynamic: r.Relation}
+			if r.Tension != "" {
+				rel.UnspokenTension = []string{r.Tension}
+			}
+			cs.Relationships[target] = rel
+		}
+		state.Characters[c.ID] = cs
+	}
+
+	for i, c := range s.Conflicts {
+		participants := make([]string, 0, len(c.Participants))
+		for _, name := range c.Participants {
+			if id, ok := ids[name]; ok {
+				participants = append(participants, id)
+			}
+		}
+		state.Conflicts = append(state.Conflicts, &ConflictThread{
+			ID:               fmt.Sprintf("import_conflict_%d", i+1),
+			Type:             c.Type,
+			CoreQuestion:     c.CoreQuestion,
+			Participants:     participants,
+			CurrentIntensity: c.Intensity,
+			Stakes:           c.Stakes,
+			IsResolved:       c.Resolved,
+		})
+	}
+
+	open := 0
+	for _, f := range state.Foreshadowing {
+		if !f.IsPaidOff {
+			open++
+		}
+	}
+	state.logAction(0, "manuscript_import", fmt.Sprintf("由已写的%d章书稿重建", a.LastChapter), []string{
+		fmt.Sprintf("角色数: %d", len(state.Characters)),
+		fmt.Sprintf("情节线数: %d", len(state.PlotThreads)),
+		fmt.Sprintf("未回收伏笔: %d/%d", open, len(state.Foreshadowing)),
+		fmt.Sprintf("细节事实: %d", len(a.Facts)),
+	})
+	return state
+}
+
+// buildManuscriptBatchPrompt 构建一批章节的分析提示词，附上此前积累的角色、情节线和未回收伏笔
+func (ne *NarrativeEngine) buildManuscriptBatchPrompt(acc *manuscriptAccumulator, batch []ManuscriptChapter) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("# 书稿分析\n\n")
+	if acc.storySoFar != "" {
+		prompt.WriteString("## 前情梗概\n")
+		prompt.WriteString(acc.storySoFar + "\n\n")
+	}
+	if len(acc.characters) > 0 {
+		prompt.WriteString("## 已知角色\n")
+		for _, c := range acc.characters {
+			line := fmt.Sprintf("- %s（%s）", c.Name, c.Role)
+			if c.Identity != "" {
+				line += ": " + c.Identity
+			}
+			prompt.WriteString(line + "\n")
+		}
+		prompt.WriteString("\n")
+	}
+	if len(acc.threads) > 0 {
+		prompt.WriteString("## 已知情节线\n")
+		for _, t := range acc.threads {
+			prompt.WriteString(fmt.Sprintf("- %s（%s，%s）\n", t.Name, t.Type, t.Status))
+		}
+		prompt.WriteString("\n")
+	}
+	openForeshadows := 0
+	for _, f := range acc.foreshadow {
+		if f.IsPaidOff {
+			continue
+		}
+		if openForeshadows == 0 {
+			prompt.WriteString("## 尚未回收的伏笔\n")
+		}
+		openForeshadows++
+		prompt.WriteString(fmt.Sprintf("- [%s] 第%d章: %s\n", f.ID, f.PlantRound, f.Content))
+	}
+	if openForeshadows > 0 {
+		prompt.WriteString("\n")
+	}
+
+	prompt.WriteString("## 本批正文\n")
+	for _, ch := range batch {
+		prompt.WriteString(fmt.Sprintf("\n### 第%d章 %s\n", ch.Num, ch.Title))
+		prompt.WriteString(ch.Content + "\n")
+	}
+
+	prompt.WriteString("\n# 要求\n")
+	prompt.WriteString(fmt.Sprintf("1. chapters 为第%d-%d章每章一条，summary 100-200字，写清发生了什么和结果\n", batch[0].Num, batch[len(batch)-1].Num))
+	prompt.WriteString("2. characters 列出本批出场的重要角色，已知角色用同样的名字，只填本批有新信息的字段；emotion、location 为本批结束时的状态\n")
+	prompt.WriteString("3. facts 为正文明确写出、后文需要保持一致的细节（外貌、伤势、随身物品、境界身份等），category 为 appearance/injury/possession/state 之一\n")
+	prompt.WriteString("4. plot_threads 列出本批推进的情节线，已知情节线用同样的名字；status 为 active/paused/resolved\n")
+	prompt.WriteString("5. foreshadows 只列本批新埋下、尚未揭晓的伏笔；paid_off 列出本批回收的已知伏笔编号\n")
+	prompt.WriteString("6. story_so_far 为截至本批结束的完整故事梗概（300-600字），在前情梗概的基础上更新\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "chapters": [{"chapter": 1, "summary": "本章摘要"}],
+  "characters": [{"name": "角色名", "role": "主角/反派/配角", "identity": "身份", "personality": "性格", "want": "表层欲望", "need": "深层需求", "fear": "恐惧", "secrets": ["秘密"], "emotion": "当前情绪", "location": "所在地", "first_chapter": 1, "relationships": [{"target": "角色名", "relation": "关系", "tension": "未言明的矛盾"}]}],
+  "facts": [{"chapter": 1, "subject": "角色或物品名", "attribute": "属性", "value": "值", "category": "appearance", "permanent": true, "evidence": "正文原句"}],
+  "plot_threads": [{"name": "情节线名", "type": "主线/副线/背景线", "characters": ["角色名"], "status": "active", "tension": 60, "events": [{"chapter": 1, "description": "事件", "resolves": false}]}],
+  "foreshadows": [{"content": "伏笔内容", "type": "象征式/对话式/情节式/角色式", "chapter": 1}],
+  "paid_off": [{"id": "伏笔编号", "chapter": 1, "scene": "回收方式"}],
+  "story_so_far": "故事梗概"
+}`)
+
+	return prompt.String()
+}
+
+// buildManuscriptSynthesisPrompt 构建书稿归纳提示词
+func (ne *NarrativeEngine) buildManuscriptSynthesisPrompt(acc *manuscriptAccumulator) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("# 书稿设定归纳\n\n")
+	prompt.WriteString("## 故事梗概\n")
+	prompt.WriteString(acc.storySoFar + "\n\n")
+
+	prompt.WriteString("## 角色\n")
+	for _, c := range acc.characters {
+		prompt.WriteString(fmt.Sprintf("- %s（%s）: %s；想要%s，害怕%s\n", c.Name, c.Role, c.Identity, c.Want, c.Fear))
+	}
+
+	prompt.WriteString("\n## 情节线\n")
+	for _, t := range acc.threads {
+		prompt.WriteString(fmt.Sprintf("- %s（%s，%s，张力%d）\n", t.Name, t.Type, t.Status, t.Tension))
+	}
+
+	prompt.WriteString("\n# 要求\n")
+	prompt.WriteString("1. world 归纳正文体现的世界观；type 为 fantasy/scifi/historical/urban/wuxia/xianxia/mixed 之一，scale 为 village/city/nation/continent/planet/universe 之一\n")
+	prompt.WriteString("2. conflicts 列出2-4个核心冲突，participants 用角色名，resolved 表示在已写部分已经解决\n")
+	prompt.WriteString("3. outline 的 setup 到 midpoint 概括已写部分；climax、resolution 为按现有走向推断的高潮与结局，要能收束未解决的冲突和情节线\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "world": {"name": "世界名", "type": "xianxia", "scale": "continent", "style": "风格", "core_question": "作品探讨的核心问题", "technology_level": "技术水平", "geography_summary": "地理概况", "social_conflicts": [{"type": "political", "description": "矛盾", "parties": ["势力"]}]},
+  "theme": "核心主题",
+  "story_hook": "故事钩子",
+  "conflicts": [{"type": "人际冲突", "core_question": "核心问题", "participants": ["角色名"], "intensity": 70, "stakes": ["赌注"], "resolved": false}],
+  "outline": {"setup": "开端", "inciting_incident": "激励事件", "plot_point1": "第一转折", "rising_action": ["上升情节"], "midpoint": "中点", "climax": "高潮", "resolution": "结局"}
+}`)
+
+	return prompt.String()
+}
+
+// ContinuationParams 续写规划参数
+type ContinuationParams struct {
+	Chapters  int // 规划的章节数，不超过 maxContinuationChapters
+	WordCount int // 每章目标字数
+}
+
+// ContinuationPlan 续写规划结果
+type ContinuationPlan struct {
+	ChapterPlans []models.ChapterPlan
+	Scenes       []models.SceneInstruction
+}
+
+// PlanContinuation 从已写的最后一章的下一章起规划续写，并把续写的关键事件写入演化状态的全局大纲
+func (ne *NarrativeEngine) PlanContinuation(a *ManuscriptAnalysis, params ContinuationParams) (*ContinuationPlan, error) {
+	count := params.Chapters
+	if count <= 0 {
+		return nil, fmt.Errorf("续写章节数必须大于 0")
+	}
+	if count > maxContinuationChapters {
+		count = maxContinuationChapters
+	}
+	start := a.LastChapter + 1
+
+	ne.log().Info("规划续写", "from", start, "chapters", count)
+	result, err := ne.callWithRetry(ne.buildContinuationPrompt(a, start, count),
+		`你是一位资深网络小说策划编辑，为作者已写好的书稿规划后续章节。续写要紧接已写内容，延续原作的人物、语气和节奏，优先推进未解决的冲突、回收已埋下的伏笔。`)
+	if err != nil {
+		return nil, fmt.Errorf("规划续写失败: %w", err)
+	}
+
+	var output whatIfOutput
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, fmt.Errorf("解析续写规划失败: %w", err)
+	}
+	if len(output.Chapters) == 0 {
+		return nil, fmt.Errorf("续写规划没有章节")
+	}
+
+	plan := &ContinuationPlan{}
+	plan.ChapterPlans, plan.Scenes = ne.chapterPlansFromOutput(&output, start, count, params.WordCount)
+
+	a.State.GlobalOutline = &GlobalOutline{
+		Opening:    a.Outline.Act1.Setup,
+		KeyEvents:  output.keyEvents("continuation_event"),
+		Climax:     a.Outline.Act3.Climax,
+		Resolution: a.Outline.Act3.Resolution,
+	}
+	a.State.logAction(a.State.CurrentRound, "continuation_plan", fmt.Sprintf("第%d章起续写%d章", start, len(plan.ChapterPlans)), []string{
+		fmt.Sprintf("关键事件数: %d", len(a.State.GlobalOutline.KeyEvents)),
+	})
+	return plan, nil
+}
+
+// ContinuationBlueprint 由书稿分析和续写规划构建叙事蓝图：已写章节标记为已完成，续写章节待生成
+func (ne *NarrativeEngine) ContinuationBlueprint(a *ManuscriptAnalysis, chapters []ManuscriptChapter, plan *ContinuationPlan) *models.NarrativeBlueprint {
+	now := time.Now()
+	blueprint := &models.NarrativeBlueprint{
+		ID:            db.GenerateID("narrative"),
+		WorldID:       a.World.ID,
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		StoryOutline:  a.Outline,
+		CharacterArcs: ne.buildCharacterArcsFromEvolution(a.State),
+		VoiceProfiles: voiceProfilesFromEvolution(a.State),
+		ThemePlan:     ne.buildThemePlanFromEvolution(a.State),
+	}
+	for _, ch := range chapters {
+		if ch.Num <= 0 {
+			continue
+		}
+		blueprint.ChapterPlans = append(blueprint.ChapterPlans, models.ChapterPlan{
+			Chapter:         ch.Num,
+			Title:           ch.Title,
+			Purpose:         "已写章节",
+			PlotAdvancement: a.Summaries[ch.Num],
+			WordCount:       ch.WordCount,
+			Status:          "completed",
+		})
+	}
+	if plan != nil {
+		blueprint.ChapterPlans = append(blueprint.ChapterPlans, plan.ChapterPlans...)
+		blueprint.Scenes = plan.Scenes
+	}
+	return blueprint
+}
+
+// buildContinuationPrompt 构建续写规划提示词
+func (ne *NarrativeEngine) buildContinuationPrompt(a *ManuscriptAnalysis, start, count int) string {
+	state := a.State
+	var prompt strings.Builder
+
+	prompt.WriteString("# 续写规划\n\n")
+	prompt.WriteString("## 世界设定\n")
+	prompt.WriteString(ne.buildWorldSummary(state.WorldContext))
+	if state.ThemeEvolution != nil && state.ThemeEvolution.CoreTheme != "" {
+		prompt.WriteString(fmt.Sprintf("【主题】%s\n", state.ThemeEvolution.CoreTheme))
+	}
+	prompt.WriteString("\n")
+
+	prompt.WriteString("## 主要角色\n")
+	for i, c := range a.Characters {
+		if i >= 12 {
+			break
+		}
+		line := fmt.Sprintf("- %s（%s）", c.Name, c.Role)
+		if c.Identity != "" {
+			line += ": " + c.Identity
+		}
+		if c.Want != "" {
+			line += "；想要" + c.Want
+		}
+		if c.Emotion != "" || c.Location != "" {
+			line += fmt.Sprintf("；目前%s，在%s", c.Emotion, c.Location)
+		}
+		prompt.WriteString(line + "\n")
+	}
+
+	prompt.WriteString("\n## 已写章节\n")
+	for n := 1; n <= a.LastChapter; n++ {
+		if s, ok := a.Summaries[n]; ok {
+			prompt.WriteString(fmt.Sprintf("- 第%d章: %s\n", n, s))
+		}
+	}
+
+	var conflicts []string
+	for _, c := range state.Conflicts {
+		if !c.IsResolved {
+			conflicts = append(conflicts, fmt.Sprintf("- %s（强度%d）", c.CoreQuestion, c.CurrentIntensity))
+		}
+	}
+	if len(conflicts) > 0 {
+		prompt.WriteString("\n## 未解决的冲突\n")
+		prompt.WriteString(strings.Join(conflicts, "\n") + "\n")
+	}
+
+	var threads []string
+	for _, t := range state.PlotThreads {
+		if t.Status != "resolved" {
+			threads = append(threads, fmt.Sprintf("- %s（%s，%s，张力%d）", t.Name, t.Type, t.Status, t.Tension))
+		}
+	}
+	if len(threads) > 0 {
+		prompt.WriteString("\n## 进行中的情节线\n")
+		prompt.WriteString(strings.Join(threads, "\n") + "\n")
+	}
+
+	var foreshadows []string
+	for _, f := range state.Foreshadowing {
+		if !f.IsPaidOff {
+			foreshadows = append(foreshadows, fmt.Sprintf("- 第%d章埋下: %s", f.PlantRound, f.Content))
+		}
+	}
+	if len(foreshadows) > 0 {
+		prompt.WriteString("\n## 待回收的伏笔\n")
+		prompt.WriteString(strings.Join(foreshadows, "\n") + "\n")
+	}
+
+	if a.Outline.Act3.Climax != "" {
+		prompt.WriteString("\n## 预期的高潮与结局\n")
+		prompt.WriteString(fmt.Sprintf("高潮: %s\n结局: %s\n", a.Outline.Act3.Climax, a.Outline.Act3.Resolution))
+	}
+
+	prompt.WriteString("\n# 要求\n")
+	prompt.WriteString(fmt.Sprintf("1. 从第%d章起规划%d章，chapter 从%d开始连续编号\n", start, count, start))
+	prompt.WriteString(fmt.Sprintf("2. 第%d章紧接第%d章的结尾，已写章节中发生的事实不能改动\n", start, a.LastChapter))
+	prompt.WriteString("3. 推进未解决的冲突和进行中的情节线，待回收的伏笔在合适的章节回收，不要一次全部揭晓\n")
+	prompt.WriteString("4. key_events 列出续写部分的关键事件，按发生顺序排列\n")
+	prompt.WriteString("5. 每章2-4个场景，角色使用上面的名字，conflict_intensity 为0-100的整数\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(planOutputFormat)
+
+	return prompt.String()
+}
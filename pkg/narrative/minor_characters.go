@@ -0,0 +1,81 @@
+// Package narrative 叙事器 - 龙套升格
+// 龙套在故事中变得重要时升格为完整角色：进入演化状态、关系网络和角色演化追踪，并落为角色卡
+package narrative
+
+import (
+	"fmt"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// promotedRole 升格角色的默认定位
+const promotedRole = "配角"
+
+// PromoteMinorCharacter 把龙套升格为演化状态中的完整角色，并建立角色演化追踪
+// 龙套的一句话身份作为角色的表层处境，辨识特征作为掩饰行为
+func (s *EvolutionState) PromoteMinorCharacter(minor *models.MinorCharacter) (*CharacterState, error) {
+	if minor == nil || minor.Name == "" {
+		return nil, fmt.Errorf("龙套缺少姓名")
+	}
+	for _, char := range s.Characters {
+		if char != nil && char.Name == minor.Name {
+			return nil, fmt.Errorf("角色已存在: %s", minor.Name)
+		}
+	}
+
+	char := &CharacterState{
+		ID:   db.GenerateID("char"),
+		Name: minor.Name,
+		Role: promotedRole,
+		EmotionalState: EmotionalSystem{
+			CurrentEmotion:     "平静",
+			EmotionalIntensity: 50,
+		},
+		Relationships:     make(map[string]*RelationshipState),
+		InternalConflicts: []string{},
+		Secrets:           []string{},
+	}
+	char.Desires.ConsciousWant = minor.Role
+	if minor.Trait != "" {
+		char.Desires.MaskingBehavior = []string{minor.Trait}
+	}
+
+	s.Characters[char.ID] = char
+	if s.RelationshipNetwork != nil && s.RelationshipNetwork.Nodes != nil {
+		s.RelationshipNetwork.Nodes[char.ID] = char
+	}
+	if s.CharacterEvolution == nil {
+		s.CharacterEvolution = make(map[string]*CharacterEvolutionTracker)
+	}
+	s.CharacterEvolution[char.ID] = &CharacterEvolutionTracker{
+		CharacterID:         char.ID,
+		EmotionalJourney:    []EmotionalState{},
+		RelationshipHistory: make(map[string][]RelationshipHistoryEntry),
+		KnowledgeGrowth:     []KnowledgePiece{},
+		TurningPoints:       []TurningPoint{},
+		ChapterChanges:      make(map[string]*ChapterCharacterChange),
+	}
+
+	s.logAction(s.CurrentRound, "promote_minor", "龙套升格为角色", []string{
+		fmt.Sprintf("角色: %s", char.Name),
+		fmt.Sprintf("原身份: %s", minor.Role),
+	})
+	return char, nil
+}
+
+// MinorCharacterSheet 龙套升格后的角色卡：定位锁定为配角，下一次演化作为预设角色注入并获得完整的欲望与情感系统
+func MinorCharacterSheet(minor *models.MinorCharacter, worldID string) *models.CharacterSheet {
+	sheet := &models.CharacterSheet{
+		ID:            db.GenerateID("sheet"),
+		WorldID:       worldID,
+		Name:          minor.Name,
+		Role:          promotedRole,
+		ConsciousWant: minor.Role,
+	}
+	if minor.Trait != "" {
+		sheet.MaskingBehavior = []string{minor.Trait}
+	}
+	sheet.Lock(models.SheetFieldRole)
+	return sheet
+}
@@ -0,0 +1,36 @@
+// Package narrative 叙事器 - 角色命名
+// 角色名按世界的命名规范校验，并登记到世界的人名登记中，避免同一世界内重名
+package narrative
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/naming"
+)
+
+// namingPromptSection 世界命名规范提示词片段，世界为空时返回空串
+func namingPromptSection(world *models.WorldSetting) string {
+	if world == nil {
+		return ""
+	}
+	return naming.NewGenerator(world).PromptSection()
+}
+
+// ensureCharacterName 校验LLM给出的角色名，不合规或重名时替换为生成的名字
+// 返回最终名字以及是否发生了替换
+func ensureCharacterName(world *models.WorldSetting, name, gender string) (string, bool) {
+	if world == nil {
+		return name, false
+	}
+	final := naming.NewGenerator(world).Ensure(name, gender)
+	return final, final != name
+}
+
+// saveNameRegistry 持久化世界的人名登记，失败只打印警告
+func (ee *EvolutionEngine) saveNameRegistry(world *models.WorldSetting) {
+	if ee.db == nil || world == nil || world.ID == "" {
+		return
+	}
+	if err := ee.db.SaveWorld(world); err != nil {
+		ee.log().Warn("保存人名登记失败", "world_id", world.ID, "error", err)
+	}
+}
@@ -8,111 +8,159 @@ import (
 	"strings"
 
 	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/timeline"
 )
 
 // Orchestrator 演化编排器
 type Orchestrator struct {
 	engine *EvolutionEngine
+
+	// promptOverrides 按角色覆盖系统提示词（用于提示词实验）
+	promptOverrides map[string]string
+
+	// userCharacters 用户预设角色（阶段2之前注入）
+	userCharacters []*UserCharacter
+
+	// wordTargets 项目级章节字数目标（细纲估算字数时优先使用）
+	wordTargets models.WordCountTargets
+
+	// styleProfile 项目写作风格档案（注入每个场景的写作指导）
+	styleProfile *models.StyleProfile
+
+	// genre 故事类型，悬疑类型在阶段3额外规划线索台账
+	genre Genre
+
+	// ending 作者选定的结局，设置后阶段5直接采用，不再由模型设计
+	ending *models.EndingCandidate
 }
 
 // NewOrchestrator 创建编排器
 func NewOrchestrator(engine *EvolutionEngine) *Orchestrator {
 	return &Orchestrator{
-		engine: engine,
+		engine:          engine,
+		promptOverrides: make(map[string]string),
+	}
+}
+
+// SetSystemPromptOverride 覆盖指定角色的系统提示词，prompt为空时恢复默认
+func (o *Orchestrator) SetSystemPromptOverride(role, prompt string) {
+	if prompt == "" {
+		delete(o.promptOverrides, role)
+		return
 	}
+	o.promptOverrides[role] = prompt
+}
+
+// SetUserCharacters 设置用户预设角色，阶段2只为剩余名额创建角色
+func (o *Orchestrator) SetUserCharacters(chars []*UserCharacter) {
+	o.userCharacters = chars
+}
+
+// SetWordCountTargets 设置项目级章节字数目标
+func (o *Orchestrator) SetWordCountTargets(targets models.WordCountTargets) {
+	o.wordTargets = targets
+}
+
+// SetGenre 设置故事类型
+func (o *Orchestrator) SetGenre(genre Genre) {
+	o.genre = genre
+}
+
+// SetEnding 设置作者选定的结局（来自结局候选），传 nil 恢复由模型设计
+func (o *Orchestrator) SetEnding(ending *models.EndingCandidate) {
+	o.ending = ending
 }
 
 // ExecuteFullEvolution 执行完整的演化流程（约200轮LLM）
 func (o *Orchestrator) ExecuteFullEvolution(worldID string, chapterCount int) (*EvolutionState, error) {
-	fmt.Println("🔄 [初始化] 正在初始化演化状态...")
+	o.engine.log().Info("初始化演化状态")
 	// 初始化演化状态
 	state, err := o.engine.CreateEvolutionState(worldID)
 	if err != nil {
 		return nil, fmt.Errorf("初始化演化状态失败: %w", err)
 	}
-	fmt.Printf("✓ 初始化完成 (轮次: %d)\n\n", state.CurrentRound)
+	if err := state.InjectCharacters(o.userCharacters); err != nil {
+		return nil, fmt.Errorf("注入预设角色失败: %w", err)
+	}
+	o.engine.log().Debug("初始化完成", "round", state.CurrentRound)
 
 	// 阶段1：故事架构设计（10-15轮）
-	fmt.Println("🏗️  [阶段1/7] 故事架构设计 (10-15轮LLM)...")
-	fmt.Println("  ├─ 分析世界设定，确定叙事模式")
-	fmt.Println("  ├─ 规划角色阵容架构")
-	fmt.Println("  └─ 确定核心矛盾线索")
+	o.engine.log().Info("阶段1/7: 故事架构设计")
 	if err := o.phase1_StoryArchitecture(state); err != nil {
 		return nil, fmt.Errorf("故事架构设计失败: %w", err)
 	}
-	fmt.Printf("✓ 阶段1完成 (当前轮次: %d)\n\n", state.CurrentRound)
+	o.engine.log().Info("阶段1完成", "round", state.CurrentRound)
 
 	// 阶段2：角色创建与关系网络（40-50轮）
-	fmt.Println("👥 [阶段2/7] 角色创建与关系网络 (40-50轮LLM)...")
-	fmt.Printf("  ├─ 创建 %d 个角色 (每角色3轮)\n", state.StoryArchitecture.CharacterRoster.TotalCharacters)
-	fmt.Println("  ├─ 构建关系网络 (5-8轮)")
-	fmt.Println("  ├─ 演化关系网络 (5-10轮)")
-	fmt.Println("  └─ 自动识别主角")
+	o.engine.log().Info("阶段2/7: 角色创建与关系网络", "characters", state.StoryArchitecture.CharacterRoster.TotalCharacters)
 	if err := o.phase2_CharactersAndRelationships(state); err != nil {
 		return nil, fmt.Errorf("角色创建失败: %w", err)
 	}
-	fmt.Printf("✓ 阶段2完成 (当前轮次: %d)\n", state.CurrentRound)
+	state.enforceLocks()
+	o.engine.log().Info("阶段2完成", "round", state.CurrentRound)
 	if state.RelationshipNetwork.CenterNode != "" {
 		protagonist := state.Characters[state.RelationshipNetwork.CenterNode]
-		fmt.Printf("  ✓ 主角识别: %s\n\n", protagonist.Name)
-	} else {
-		fmt.Println()
+		o.engine.log().Info("主角识别", "protagonist", protagonist.Name)
 	}
 
 	// 阶段3：伏笔系统设计（10-15轮）
-	fmt.Println("🔮 [阶段3/7] 伏笔系统设计 (10-15轮LLM)...")
-	fmt.Println("  ├─ 规划伏笔网络 (5-8轮)")
-	fmt.Println("  └─ 验证伏笔完整性 (5-7轮)")
+	o.engine.log().Info("阶段3/7: 伏笔系统设计")
 	if err := o.phase3_ForeshadowPlanning(state); err != nil {
 		return nil, fmt.Errorf("伏笔系统设计失败: %w", err)
 	}
-	fmt.Printf("✓ 阶段3完成 - 规划了 %d 个伏笔 (当前轮次: %d)\n\n", len(state.ForeshadowPlan), state.CurrentRound)
+	o.engine.log().Info("阶段3完成", "foreshadows", len(state.ForeshadowPlan), "round", state.CurrentRound)
 
 	// 阶段4：冲突系统设计（20-30轮）
-	fmt.Println("⚔️  [阶段4/7] 冲突系统设计 (20-30轮LLM)...")
-	fmt.Printf("  ├─ 设计 %d 个核心冲突 (每冲突2轮)\n", len(state.Characters)+2)
-	fmt.Println("  └─ 构建冲突层级 (3-5轮)")
+	o.engine.log().Info("阶段4/7: 冲突系统设计")
 	if err := o.phase4_ConflictSystem(state); err != nil {
 		return nil, fmt.Errorf("冲突系统设计失败: %w", err)
 	}
-	fmt.Printf("✓ 阶段4完成 - 设计了 %d 个冲突 (当前轮次: %d)\n\n", len(state.Conflicts), state.CurrentRound)
+	o.engine.log().Info("阶段4完成", "conflicts", len(state.Conflicts), "round", state.CurrentRound)
 
 	// 阶段5：生成主要故事大纲（15-20轮）
-	fmt.Println("📖 [阶段5/7] 生成主要故事大纲 (15-20轮LLM)...")
-	fmt.Println("  ├─ 规划故事走向 (1轮)")
-	fmt.Println("  ├─ 设计关键事件序列 (1轮)")
-	fmt.Println("  ├─ 设计高潮和结局 (1轮)")
-	fmt.Println("  └─ 构建伏笔链接")
+	o.engine.log().Info("阶段5/7: 生成主要故事大纲")
 	if err := o.phase5_GlobalOutline(state); err != nil {
 		return nil, fmt.Errorf("故事大纲生成失败: %w", err)
 	}
-	fmt.Printf("✓ 阶段5完成 - 设计了 %d 个关键事件 (当前轮次: %d)\n\n", len(state.GlobalOutline.KeyEvents), state.CurrentRound)
+	o.engine.log().Info("阶段5完成", "key_events", len(state.GlobalOutline.KeyEvents), "round", state.CurrentRound)
 
 	// 阶段6：章节规划（10-15轮）
-	fmt.Printf("📚 [阶段6/7] 章节规划 (10-15轮LLM)...\n")
-	fmt.Printf("  ├─ 将关键事件分配到 %d 个章节 (5-8轮)\n", chapterCount)
-	fmt.Println("  └─ 优化章节序列和连接 (5-7轮)")
+	o.engine.log().Info("阶段6/7: 章节规划", "chapters", chapterCount)
 	if err := o.phase6_ChapterPlanning(state, chapterCount); err != nil {
 		return nil, fmt.Errorf("章节规划失败: %w", err)
 	}
-	fmt.Printf("✓ 阶段6完成 - 规划了 %d 个章节 (当前轮次: %d)\n\n", len(state.ChapterPlan.ChapterSequence), state.CurrentRound)
+	o.engine.log().Info("阶段6完成", "chapters", len(state.ChapterPlan.ChapterSequence), "round", state.CurrentRound)
+
+	// 副线规划：分配副线到章节并做张力预算（1轮）
+	o.engine.log().Info("副线规划")
+	subplotWarnings, err := o.PlanSubplots(state)
+	if err != nil {
+		return nil, fmt.Errorf("副线规划失败: %w", err)
+	}
+	for _, w := range subplotWarnings {
+		o.engine.log().Warn("副线问题", "thread", w.ThreadID, "kind", w.Kind, "message", w.Message)
+	}
+	o.engine.log().Info("副线规划完成", "threads", len(state.PlotThreads), "warnings", len(subplotWarnings), "round", state.CurrentRound)
+
+	// 收尾规划：高潮之后的尾声章节（1轮）
+	o.engine.log().Info("收尾: 尾声规划")
+	denouement, err := o.PlanDenouement(state)
+	if err != nil {
+		return nil, fmt.Errorf("尾声规划失败: %w", err)
+	}
+	o.ApplyDenouement(state, denouement)
+	state.Denouement = denouement
+	o.engine.log().Info("收尾完成", "epilogues", len(denouement.Epilogues), "round", state.CurrentRound)
 
 	// 阶段7：细纲生成（每章10-15轮，在生成时按需执行）
-	fmt.Println("🎯 [阶段7/7] 细纲生成系统 (按需执行)")
-	fmt.Println("  阶段7不是一次性执行，而是在生成每章细纲时按需调用")
-	fmt.Println("  每章细纲生成包括：")
-	fmt.Println("    • 设计场景序列 (2-3轮)")
-	fmt.Println("    • 生成场景详细指令 (每场景1轮)")
-	fmt.Println("    • 追踪角色演化 (1轮)")
-	fmt.Println("    • 规划伏笔操作")
-	fmt.Println("    • 估算字数和写作指导")
-	fmt.Printf("  预计每章需要: 10-15轮LLM\n\n")
+	o.engine.log().Debug("阶段7/7: 细纲生成在生成每章时按需执行")
 
 	// 阶段7：细纲生成（每章10-15轮，在生成时按需执行）
 	// 这个阶段不是一次性执行，而是按需生成
 	// 这里只设置标志
 	// state.CurrentRound = 0 // 重置轮次计数器，为细纲生成准备
 
+	state.enforceLocks()
 	return state, nil
 }
 
@@ -157,8 +205,8 @@ func (o *Orchestrator) phase1_StoryArchitecture(state *EvolutionState) error {
 func (o *Orchestrator) phase2_CharactersAndRelationships(state *EvolutionState) error {
 	roster := state.StoryArchitecture.CharacterRoster
 
-	// 2.1 逐个创建角色（每个角色3-4轮）
-	for i := 0; i < roster.TotalCharacters; i++ {
+	// 2.1 逐个创建角色（每个角色3-4轮），用户预设角色占用名额
+	for i := len(state.UserCharacters); i < roster.TotalCharacters; i++ {
 		character, err := o.createCharacterWithDepth(state, i)
 		if err != nil {
 			return err
@@ -171,6 +219,7 @@ func (o *Orchestrator) phase2_CharactersAndRelationships(state *EvolutionState)
 	if err != nil {
 		return err
 	}
+	applyUserRelationships(state, network)
 	state.RelationshipNetwork = network
 
 	// 2.3 演化关系网络（5-10轮）
@@ -178,8 +227,11 @@ func (o *Orchestrator) phase2_CharactersAndRelationships(state *EvolutionState)
 		return err
 	}
 
-	// 识别主角
-	protagonist := o.identifyProtagonist(state)
+	// 识别主角（用户指定的中心角色优先）
+	protagonist := state.UserCenter
+	if protagonist == "" {
+		protagonist = o.identifyProtagonist(state)
+	}
 	state.RelationshipNetwork.CenterNode = protagonist
 
 	// 初始化角色演化追踪
@@ -213,6 +265,15 @@ func (o *Orchestrator) phase3_ForeshadowPlanning(state *EvolutionState) error {
 
 	state.ForeshadowPlan = foreshadowPlan
 
+	// 3.3 悬疑类型：规划线索台账并做公平推理校验
+	if o.genre == GenreMystery {
+		ledger, err := o.planClueLedger(state)
+		if err != nil {
+			return err
+		}
+		state.ClueLedger = ledger
+	}
+
 	return nil
 }
 
@@ -247,8 +308,14 @@ func (o *Orchestrator) phase5_GlobalOutline(state *EvolutionState) error {
 		return err
 	}
 
-	// 5.3 验证大纲的连贯性
-	climax, resolution, err := o.designClimaxAndResolution(state, keyEvents)
+	// 5.3 规划反派的平行暗线
+	antagonistArcs, err := o.PlanAntagonistArcs(state, keyEvents)
+	if err != nil {
+		return err
+	}
+
+	// 5.4 验证大纲的连贯性
+	climax, resolution, err := o.designClimaxAndResolution(state, keyEvents, antagonistArcs)
 	if err != nil {
 		return err
 	}
@@ -262,6 +329,7 @@ func (o *Orchestrator) phase5_GlobalOutline(state *EvolutionState) error {
 		Climax:           climax,
 		Resolution:       resolution,
 		ForeshadowLinks: foreshadowLinks,
+		AntagonistArcs:   antagonistArcs,
 	}
 
 	return nil
@@ -488,14 +556,18 @@ func (o *Orchestrator) createCharacterBasicInfo(state *EvolutionState, charID st
 		UnconsciousNeed string   `json:"unconscious_need"`
 		CoreTraits      []string `json:"core_traits"`
 		Flaws           []string `json:"flaws"`
+		Voice           *models.VoiceProfile `json:"voice"`
 	}
 	if err := json.Unmarshal([]byte(response), &result); err != nil {
 		return nil, fmt.Errorf("解析角色创建结果失败: %w", err)
 	}
 
+	name, renamed := ensureCharacterName(state.WorldContext, result.Name, "")
+	o.engine.saveNameRegistry(state.WorldContext)
+
 	character := &CharacterState{
 		ID:   charID,
-		Name: result.Name,
+		Name: name,
 		Role: result.Role,
 		EmotionalState: EmotionalSystem{
 			CurrentEmotion:     "平静",
@@ -510,13 +582,18 @@ func (o *Orchestrator) createCharacterBasicInfo(state *EvolutionState, charID st
 		ArcProgress:       0.0,
 		InternalConflicts: []string{},
 		Secrets:          []string{},
+		Voice:            result.Voice,
 	}
 
-	state.logAction(state.CurrentRound, "character_creation", "创建角色", []string{
-		fmt.Sprintf("角色名: %s", result.Name),
+	changes := []string{
+		fmt.Sprintf("角色名: %s", name),
 		fmt.Sprintf("角色: %s", result.Role),
 		fmt.Sprintf("意识欲望: %s", result.ConsciousWant),
-	})
+	}
+	if renamed {
+		changes = append(changes, fmt.Sprintf("原名「%s」不符合命名规范或重名，已替换", result.Name))
+	}
+	state.logAction(state.CurrentRound, "character_creation", "创建角色", changes)
 
 	return character, nil
 }
@@ -531,8 +608,7 @@ func (o *Orchestrator) deepenCharacterInternalConflict(state *EvolutionState, ch
 		return fmt.Errorf("角色深化失败: %w", err)
 	}
 
-	// 调试：打印原始响应
-	fmt.Printf("  [DEBUG] 原始响应长度: %d\n", len(response))
+	o.engine.log().Debug("角色深化响应", "character", character.Name, "response_chars", len(response))
 
 	var result struct {
 		InternalConflicts []string `json:"internal_conflicts"`
@@ -1041,6 +1117,8 @@ func (o *Orchestrator) designKeyEvents(state *EvolutionState, opening, direction
 			Conflicts   []string `json:"conflicts"`
 			Characters  []string `json:"characters"`
 			Foreshadowing []string `json:"foreshadowing"`
+			Location    string   `json:"location"`
+			StoryTime   *models.StoryTime `json:"story_time"`
 		} `json:"events"`
 	}
 	if err := json.Unmarshal([]byte(response), &result); err != nil {
@@ -1058,21 +1136,33 @@ func (o *Orchestrator) designKeyEvents(state *EvolutionState, opening, direction
 			Name:                event.Name,
 			Description:         event.Description,
 			InvolvedCharacters:  event.Characters,
+			Location:            event.Location,
+			StoryTime:           event.StoryTime,
 		})
 	}
 
-	state.logAction(state.CurrentRound, "key_events_design", "关键事件设计", []string{
-		fmt.Sprintf("事件数: %d", len(events)),
-	})
+	changes := []string{fmt.Sprintf("事件数: %d", len(events))}
+	for _, conflict := range keyEventTimeline(events).Validate(timeline.RulesFromWorld(state.WorldContext)) {
+		changes = append(changes, "时间线冲突: "+conflict.Detail)
+	}
+	state.logAction(state.CurrentRound, "key_events_design", "关键事件设计", changes)
 
 	return events, nil
 }
 
 // designClimaxAndResolution 设计高潮和结局
-func (o *Orchestrator) designClimaxAndResolution(state *EvolutionState, events []KeyEvent) (string, string, error) {
+func (o *Orchestrator) designClimaxAndResolution(state *EvolutionState, events []KeyEvent, arcs []AntagonistArc) (string, string, error) {
 	state.CurrentRound++
 
-	prompt := o.buildClimaxPrompt(state, events)
+	if o.ending != nil {
+		state.logAction(state.CurrentRound, "climax_design", "采用选定结局", []string{
+			fmt.Sprintf("类型: %s", o.ending.Type),
+			fmt.Sprintf("结局: %s", o.ending.Resolution),
+		})
+		return o.ending.Climax, o.ending.Resolution, nil
+	}
+
+	prompt := o.buildClimaxPrompt(state, events, arcs)
 	systemPrompt := o.buildSystemPrompt("climax_designer")
 
 	response, err := o.engine.callWithRetry(prompt, systemPrompt)
@@ -1197,8 +1287,8 @@ func (o *Orchestrator) refineChapterSequence(state *EvolutionState, sequence []C
 
 // GenerateChapterDetailOutline 生成单章的细纲（10-15轮LLM）
 func (o *Orchestrator) GenerateChapterDetailOutline(state *EvolutionState, chapterNum int) (*ChapterDetailOutline, error) {
-	fmt.Printf("\n🎯 [开始] 生成第%d章细纲...\n", chapterNum)
-	fmt.Printf("  当前总轮次: %d\n", state.CurrentRound)
+	log := o.engine.log().With("chapter", chapterNum)
+	log.Info("开始生成章节细纲", "round", state.CurrentRound)
 
 	// 获取章节规划
 	if state.ChapterPlan == nil || len(state.ChapterPlan.ChapterSequence) == 0 {
@@ -1217,56 +1307,62 @@ func (o *Orchestrator) GenerateChapterDetailOutline(state *EvolutionState, chapt
 		return nil, fmt.Errorf("未找到章节%d的规划", chapterNum)
 	}
 
-	fmt.Printf("  章节标题: %s\n", chapterSynopsis.Title)
-	fmt.Printf("  章节目的: %s\n", chapterSynopsis.Purpose)
+	log.Debug("章节规划", "title", chapterSynopsis.Title, "purpose", chapterSynopsis.Purpose)
+
+	// 已定稿的场景保留，只重新生成其余场景
+	pinned := pinnedScenes(state, chapterNum)
+	if len(pinned) > 0 {
+		log.Info("保留已定稿场景", "pinned", len(pinned))
+	}
 
 	// 第1-2轮：设计场景序列
 	state.CurrentRound++
-	fmt.Printf("\n  [轮次 %d] 设计场景序列...\n", state.CurrentRound)
-	sceneSequence, err := o.designSceneSequence(state, chapterSynopsis)
+	log.Debug("设计场景序列", "round", state.CurrentRound)
+	sceneSequence, err := o.designSceneSequence(state, chapterSynopsis, pinned)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("  ✓ 规划了 %d 个场景\n", len(sceneSequence))
+	log.Debug("场景序列完成", "scenes", len(sceneSequence))
 
 	// 第3-10轮：为每个场景生成详细指令
 	scenes := make([]*SceneDetailInstruction, 0, len(sceneSequence))
 	for i, scene := range sceneSequence {
 		state.CurrentRound++
-		fmt.Printf("  [轮次 %d] 生成场景%d详情 (%s)...\n", state.CurrentRound, i+1, scene.Type)
+		log.Debug("生成场景详情", "round", state.CurrentRound, "scene", i+1, "type", scene.Type)
 		detail, err := o.generateSceneDetailInstruction(state, chapterSynopsis, scene, i)
 		if err != nil {
 			return nil, fmt.Errorf("场景%d详情生成失败: %w", i, err)
 		}
 		scenes = append(scenes, detail)
-		fmt.Printf("  ✓ 场景%d完成: %s (POV: %s)\n", i+1, detail.Location, detail.POVCharacter)
+		log.Debug("场景详情完成", "scene", i+1, "location", detail.Location, "pov", detail.POVCharacter)
 	}
+	scenes = mergePinnedScenes(pinned, scenes)
 
 	// 第11-12轮：追踪角色演化
 	state.CurrentRound++
-	fmt.Printf("\n  [轮次 %d] 追踪角色演化...\n", state.CurrentRound)
+	log.Debug("追踪角色演化", "round", state.CurrentRound)
 	characterEvolution, err := o.trackChapterCharacterEvolution(state, chapterNum, scenes)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("  ✓ 追踪了 %d 个角色的演化\n", len(characterEvolution))
+	log.Debug("角色演化完成", "characters", len(characterEvolution))
 
 	// 第13-14轮：规划伏笔操作
 	state.CurrentRound++
-	fmt.Printf("  [轮次 %d] 规划伏笔操作...\n", state.CurrentRound)
+	log.Debug("规划伏笔操作", "round", state.CurrentRound)
 	foreshadowTracking, err := o.planChapterForeshadowing(state, chapterNum, scenes)
 	if err != nil {
 		return nil, err
 	}
-	fmt.Printf("  ✓ 种植: %d个 | 回收: %d个 | 进行中: %d个\n",
-		len(foreshadowTracking.Planted),
-		len(foreshadowTracking.PaidOff),
-		len(foreshadowTracking.Active))
+	log.Debug("伏笔操作规划完成",
+		"planted", len(foreshadowTracking.Planted),
+		"paid_off", len(foreshadowTracking.PaidOff),
+		"active", len(foreshadowTracking.Active))
 
 	// 第15轮：确定章节字数和写作指导
 	state.CurrentRound++
 	wordCount, guidance := o.estimateChapterMetrics(state, chapterSynopsis, scenes)
-	fmt.Printf("  [轮次 %d] 估算字数: %d\n", state.CurrentRound, wordCount)
+	log.Debug("估算字数", "round", state.CurrentRound, "words", wordCount)
 
 	outline := &ChapterDetailOutline{
 		Chapter:              chapterNum,
@@ -1280,23 +1376,31 @@ func (o *Orchestrator) GenerateChapterDetailOutline(state *EvolutionState, chapt
 		ForeshadowingTracking: *foreshadowTracking,
 	}
 
-	// 将写作指导应用到每个场景
+	// 将写作指导应用到每个场景（定稿场景保持不变）
 	for _, scene := range scenes {
-		scene.WritingGuidance = *guidance
+		if !scene.Pinned {
+			scene.WritingGuidance = *guidance
+		}
 	}
 
-	fmt.Printf("\n✓ 第%d章细纲生成完成! (使用了 %d 轮)\n", chapterNum, state.CurrentRound-(state.CurrentRound-15))
+	reconcileForeshadowOps(state, chapterNum, scenes)
+	if state.DetailOutlines == nil {
+		state.DetailOutlines = make(map[int]*ChapterDetailOutline)
+	}
+	state.DetailOutlines[chapterNum] = outline
+
+	log.Info("章节细纲生成完成", "scenes", len(scenes), "round", state.CurrentRound)
 
 	return outline, nil
 }
 
 // designSceneSequence 设计场景序列（2-3轮LLM）
-func (o *Orchestrator) designSceneSequence(state *EvolutionState, chapter *ChapterSynopsis) ([]struct {
+func (o *Orchestrator) designSceneSequence(state *EvolutionState, chapter *ChapterSynopsis, pinned []*SceneDetailInstruction) ([]struct {
 	Sequence int
 	Type     string
 	Purpose  string
 }, error) {
-	prompt := o.buildSceneSequencePrompt(state, chapter)
+	prompt := o.buildSceneSequencePrompt(state, chapter) + formatPinnedScenes(pinned)
 	systemPrompt := o.buildSystemPrompt("scene_sequence_designer")
 
 	response, err := o.engine.callWithRetry(prompt, systemPrompt)
@@ -1348,6 +1452,7 @@ func (o *Orchestrator) generateSceneDetailInstruction(state *EvolutionState, cha
 	var result struct {
 		Location            string                       `json:"location"`
 		Time                string                       `json:"time"`
+		StoryTime           *models.StoryTime            `json:"story_time"`
 		POVCharacter        string                       `json:"pov_character"`
 		Characters          []string                     `json:"characters"`
 		MainAction          string                       `json:"main_action"`
@@ -1370,6 +1475,7 @@ func (o *Orchestrator) generateSceneDetailInstruction(state *EvolutionState, cha
 		Purpose:               fmt.Sprintf("%v", scene),
 		Location:              result.Location,
 		Time:                  result.Time,
+		StoryTime:             result.StoryTime,
 		POVCharacter:          result.POVCharacter,
 		Characters:            result.Characters,
 		SceneType:             result.MainAction, // 简化，实际应该有专门的类型字段
@@ -1441,18 +1547,24 @@ func (o *Orchestrator) planChapterForeshadowing(state *EvolutionState, chapterNu
 		}
 	}
 
-	return &ForeshadowTracking{
+	tracking := &ForeshadowTracking{
 		Planted: planted,
 		PaidOff: paidOff,
 		Active:  active,
-	}, nil
+	}
+	if state.ClueLedger != nil {
+		tracking.Clues = state.ClueLedger.CluesInChapter(chapterNum)
+	}
+
+	return tracking, nil
 }
 
 // estimateChapterMetrics 估算章节指标
 func (o *Orchestrator) estimateChapterMetrics(state *EvolutionState, chapter *ChapterSynopsis, scenes []*SceneDetailInstruction) (int, *WritingGuidance) {
-	// 基于场景数量和类型估算字数
+	// 基于场景数量和类型估算字数，项目配置了目标字数时以配置为准
 	baseWordCount := 3000 // 基础字数
 	wordCount := baseWordCount + len(scenes)*500 // 每个场景增加500字
+	wordCount = o.wordTargets.For(chapter.Chapter, wordCount)
 
 	guidance := &WritingGuidance{
 		Techniques:       []string{"展示而非讲述", "感官细节", "节奏变化"},
@@ -1460,6 +1572,7 @@ func (o *Orchestrator) estimateChapterMetrics(state *EvolutionState, chapter *Ch
 		NarrativeDistance: "中距离",
 		StyleHints:       []string{"中等节奏"},
 	}
+	applyStyleProfile(guidance, o.styleProfile)
 
 	return wordCount, guidance
 }
@@ -1492,9 +1605,11 @@ type SceneDetailInstruction struct {
 	Purpose      string   `json:"purpose"`
 	Location     string   `json:"location"`
 	Time         string   `json:"time"`
+	StoryTime    *models.StoryTime `json:"story_time,omitempty"` // 故事内时间（小时）
 	POVCharacter string   `json:"pov_character"`
 	Characters    []string `json:"characters"`
 	SceneType    string   `json:"scene_type"` // "对话"/"动作"/"内心"/"过渡"/"描写"
+	Pinned       bool     `json:"pinned"`     // 已定稿，重新生成细纲时保留
 
 	// 核心指令
 	MainAction   string `json:"main_action"`
@@ -1574,6 +1689,7 @@ type WritingGuidance struct {
 	DialogueNotes     string   `json:"dialogue_notes"`     // 对话指导
 	NarrativeDistance  string   `json:"narrative_distance"` // 叙事距离：近距离/中距离/远距离
 	StyleHints        []string `json:"style_hints"`       // 风格提示
+	BannedPhrases     []string `json:"banned_phrases,omitempty"` // 禁用表达（来自风格档案）
 }
 
 // ChapterCharacterEvolution 章节角色演化
@@ -1589,12 +1705,17 @@ type ForeshadowTracking struct {
 	Planted  []string `json:"planted"`  // 本章种植的伏笔ID
 	PaidOff  []string `json:"paid_off"`  // 本章回收的伏笔ID
 	Active   []string `json:"active"`    // 仍未回收的伏笔ID
+	Clues    []string `json:"clues,omitempty"` // 本章呈现的线索ID（悬疑）
 }
 
 // ============ 辅助方法：Prompt构建 ============
 
 // buildSystemPrompt 构建系统提示词
 func (o *Orchestrator) buildSystemPrompt(role string) string {
+	if prompt, ok := o.promptOverrides[role]; ok {
+		return prompt
+	}
+
 	systemPrompts := map[string]string{
 		"story_architecture_analyzer": `你是一位资深的故事架构分析师，精通各种叙事理论。
 你擅长分析世界设定的深层张力，确定最适合的叙事模式。
@@ -1634,6 +1755,10 @@ func (o *Orchestrator) buildSystemPrompt(role string) string {
 你擅长检查伏笔计划的完整性和合理性。
 你能识别伏笔的遗漏、冲突和时机问题。`,
 
+		"clue_architect": `你是一位推理小说线索设计师。
+你严格遵守公平推理原则：揭晓真相所需的线索，读者必须在揭晓之前就能看到。
+你擅长设计既隐蔽又公平的线索，以及可信但终将被排除的红鲱鱼。`,
+
 		"conflict_designer": `你是一位核心冲突设计师。
 你擅长设计多层次、有深度的冲突。
 你确保每个冲突都有足够的赌注和演化空间。
@@ -1679,6 +1804,22 @@ func (o *Orchestrator) buildSystemPrompt(role string) string {
 		"character_evolution_tracker": `你是一位角色演化追踪师。
 你擅长分析角色在章节中的情感轨迹和成长。
 你能识别关键的关系变化和内在转变。`,
+
+		"denouement_planner": `你是一位收尾与尾声规划师。
+你擅长在高潮之后梳理所有线索，为副线、伏笔和角色弧光安排干净利落的收束。
+你理解尾声要展示世界与角色的新常态，给读者留下余韵。`,
+
+		"subplot_planner": `你是一位副线与节奏规划师。
+你擅长在主线之外编织副线和背景线，让它们与主题呼应、按节奏出场。
+你懂得控制副线的张力，不让副线抢走主线的风头，并在终幕之前把副线干净地收束。`,
+
+		"antagonist_strategist": `你是一位反派策划师。
+你擅长站在反派的立场规划他的目标、手段和时间表，让反派的行动与主线平行推进。
+你理解最终对决之所以动人，是因为读者一路看见了反派的得失和双方的擦肩而过。`,
+
+		"experiment_judge": `你是一位严格、公正的故事策划评审。
+你擅长并排比较多份策划方案，从张力、深度、主题关联和可写性评估优劣。
+你的评分客观一致，只依据方案内容本身，不受方案顺序影响。`,
 	}
 
 	if prompt, ok := systemPrompts[role]; ok {
@@ -1914,13 +2055,8 @@ func (o *Orchestrator) buildCharacterCreationPrompt(state *EvolutionState, index
 - 核心问题：%s
 - 种族：%v
 
-%请根据世界类型和风格创建符合时代背景的角色。
-例如：
-- 历史类（民国、古代）：姓名应符合时代特征，避免现代或奇幻风格
-- 奇幻类：姓名可以带有魔法或神秘元素
-- 科幻类：姓名应反映未来科技特征
-- 现实类：姓名应贴近现实生活
-
+请根据世界类型和风格创建符合时代背景的角色。
+%s
 已创建的角色：
 %s
 
@@ -1932,6 +2068,7 @@ func (o *Orchestrator) buildCharacterCreationPrompt(state *EvolutionState, index
 5. 潜意识需求（深层需要什么）
 6. 核心特质
 7. 致命弱点
+8. 语音档案：用词、语体、句式、口头禅和绝不会说的话，要与已创建角色明显区分
 
 请以JSON格式返回：
 {
@@ -1943,7 +2080,15 @@ func (o *Orchestrator) buildCharacterCreationPrompt(state *EvolutionState, index
   "conscious_want": "意识欲望",
   "unconscious_need": "潜意识需求",
   "core_traits": ["核心特质"],
-  "flaws": ["弱点"]
+  "flaws": ["弱点"],
+  "voice": {
+    "diction": "用词特点",
+    "formality": "正式/中性/随意/粗俗",
+    "sentence_style": "句式习惯",
+    "catchphrases": ["口头禅"],
+    "avoid": ["绝不会说的词或语气"],
+    "sample_lines": ["示例台词"]
+  }
 }
 只返回JSON，不要包含其他内容。`,
 		index+1,
@@ -1959,6 +2104,7 @@ func (o *Orchestrator) buildCharacterCreationPrompt(state *EvolutionState, index
 		world.Style,
 		world.Philosophy.CoreQuestion,
 		raceNames,
+		namingPromptSection(world),
 		formatExistingCharacters(state.Characters))
 }
 
@@ -2411,10 +2557,13 @@ func (o *Orchestrator) buildKeyEventsPrompt(state *EvolutionState, opening, dire
       "description": "事件描述",
       "conflicts": ["conflict_0"],
       "characters": ["char_0"],
-      "foreshadowing": ["foreshadow_1"]
+      "foreshadowing": ["foreshadow_1"],
+      "location": "事件地点",
+      "story_time": {"start": 0, "duration": 2, "label": "第一日黄昏"}
     }
   ]
 }
+story_time.start 为距故事开端的小时数，duration 为持续小时数；同一角色不能同时出现在两地，移动时间要符合世界设定。
 只返回JSON，不要包含其他内容。`,
 		opening,
 		direction,
@@ -2423,7 +2572,7 @@ func (o *Orchestrator) buildKeyEventsPrompt(state *EvolutionState, opening, dire
 }
 
 // buildClimaxPrompt 构建高潮结局提示词
-func (o *Orchestrator) buildClimaxPrompt(state *EvolutionState, events []KeyEvent) string {
+func (o *Orchestrator) buildClimaxPrompt(state *EvolutionState, events []KeyEvent, arcs []AntagonistArc) string {
 	eventSummary := make([]string, 0, len(events))
 	for _, e := range events {
 		eventSummary = append(eventSummary, fmt.Sprintf("- %s: %s", e.Name, e.Description))
@@ -2433,9 +2582,9 @@ func (o *Orchestrator) buildClimaxPrompt(state *EvolutionState, events []KeyEven
 
 关键事件概览：
 %s
-
+%s
 请设计：
-1. 高潮（所有冲突的最终对决）
+1. 高潮（所有冲突的最终对决，反派暗线积累的筹码和擦肩交锋要在此兑现）
 2. 结局（冲突解决，主角变化）
 3. 余韵（世界变成怎样）
 
@@ -2446,7 +2595,8 @@ func (o *Orchestrator) buildClimaxPrompt(state *EvolutionState, events []KeyEven
   "aftermath": "余韵描述"
 }
 只返回JSON，不要包含其他内容。`,
-		strings.Join(eventSummary, "\n"))
+		strings.Join(eventSummary, "\n"),
+		buildAntagonistArcSection(arcs))
 }
 
 // buildChapterAssignmentPrompt 构建章节分配提示词
@@ -2461,11 +2611,13 @@ func (o *Orchestrator) buildChapterAssignmentPrompt(state *EvolutionState, chapt
 
 关键事件：
 %s
-
+%s
 开篇：%s
 高潮：%s
 结局：%s
 
+反派暗线的行动和擦肩交锋要随同期关键事件落到章节中。
+
 请为每一章规划：
 1. 章节编号
 2. 章节标题
@@ -2486,6 +2638,7 @@ func (o *Orchestrator) buildChapterAssignmentPrompt(state *EvolutionState, chapt
 只返回JSON，不要包含其他内容。`,
 		chapterCount,
 		strings.Join(events, "\n"),
+		buildAntagonistArcSection(state.GlobalOutline.AntagonistArcs),
 		state.GlobalOutline.Opening,
 		state.GlobalOutline.Climax,
 		state.GlobalOutline.Resolution)
@@ -2526,7 +2679,7 @@ func (o *Orchestrator) buildSceneSequencePrompt(state *EvolutionState, chapter *
 章节标题：%s
 章节目的：%s
 关键事件：%v
-
+%s
 请规划3-6个场景，每个场景包括：
 1. 场景序号
 2. 场景类型（对话/动作/内心/过渡/描写）
@@ -2546,7 +2699,8 @@ func (o *Orchestrator) buildSceneSequencePrompt(state *EvolutionState, chapter *
 		chapter.Chapter,
 		chapter.Title,
 		chapter.Purpose,
-		chapter.KeyEvents)
+		chapter.KeyEvents,
+		buildChapterSubplotSection(chapter))
 }
 
 // buildSceneDetailPrompt 构建场景详情提示词
@@ -2573,6 +2727,7 @@ func (o *Orchestrator) buildSceneDetailPrompt(state *EvolutionState, chapter *Ch
 {
   "location": "地点",
   "time": "时间",
+  "story_time": {"start": 26, "duration": 1, "label": "第二日清晨"},
   "pov_character": "char_0",
   "characters": ["char_0", "char_1"],
   "main_action": "主要动作描述",
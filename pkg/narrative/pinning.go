@@ -0,0 +1,141 @@
+// Package narrative 叙事器 - 场景定稿（pinned）
+// 重新生成细纲时保留已定稿的场景，只重新生成其余场景
+package narrative
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PinScene 标记细纲中的场景为已定稿或取消定稿
+func (s *EvolutionState) PinScene(chapterNum, sequence int, pinned bool) error {
+	outline, ok := s.DetailOutlines[chapterNum]
+	if !ok || outline == nil {
+		return fmt.Errorf("第%d章尚未生成细纲", chapterNum)
+	}
+	for _, scene := range outline.Scenes {
+		if scene.Sequence == sequence {
+			scene.Pinned = pinned
+			return nil
+		}
+	}
+	return fmt.Errorf("第%d章不存在场景%d", chapterNum, sequence)
+}
+
+// pinnedScenes 获取章节上一版细纲中已定稿的场景（按序号排序）
+func pinnedScenes(state *EvolutionState, chapterNum int) []*SceneDetailInstruction {
+	outline, ok := state.DetailOutlines[chapterNum]
+	if !ok || outline == nil {
+		return nil
+	}
+
+	pinned := make([]*SceneDetailInstruction, 0)
+	for _, scene := range outline.Scenes {
+		if scene.Pinned {
+			pinned = append(pinned, scene)
+		}
+	}
+	sort.Slice(pinned, func(i, j int) bool { return pinned[i].Sequence < pinned[j].Sequence })
+	return pinned
+}
+
+// mergePinnedScenes 将定稿场景按原位置插回新生成的场景序列，并重排序号
+// 定稿场景中已种植/回收的伏笔，不会在新场景中重复出现
+func mergePinnedScenes(pinned, regenerated []*SceneDetailInstruction) []*SceneDetailInstruction {
+	if len(pinned) == 0 {
+		return regenerated
+	}
+
+	plantedIDs := make(map[string]bool)
+	paidOffIDs := make(map[string]bool)
+	for _, scene := range pinned {
+		for _, p := range scene.Foreshadowing.Plant {
+			plantedIDs[p.ForeshadowID] = true
+		}
+		for _, p := range scene.Foreshadowing.Payoff {
+			paidOffIDs[p.ForeshadowID] = true
+		}
+	}
+	for _, scene := range regenerated {
+		plants := scene.Foreshadowing.Plant[:0]
+		for _, p := range scene.Foreshadowing.Plant {
+			if !plantedIDs[p.ForeshadowID] {
+				plants = append(plants, p)
+			}
+		}
+		scene.Foreshadowing.Plant = plants
+
+		payoffs := scene.Foreshadowing.Payoff[:0]
+		for _, p := range scene.Foreshadowing.Payoff {
+			if !paidOffIDs[p.ForeshadowID] {
+				payoffs = append(payoffs, p)
+			}
+		}
+		scene.Foreshadowing.Payoff = payoffs
+	}
+
+	total := len(pinned) + len(regenerated)
+	merged := make([]*SceneDetailInstruction, 0, total)
+	pi, ri := 0, 0
+	for pos := 1; len(merged) < total; pos++ {
+		// 定稿场景尽量保持原位置；新场景用完后剩余定稿场景依次排在末尾
+		if pi < len(pinned) && (pinned[pi].Sequence <= pos || ri >= len(regenerated)) {
+			merged = append(merged, pinned[pi])
+			pi++
+		} else {
+			merged = append(merged, regenerated[ri])
+			ri++
+		}
+	}
+
+	for i, scene := range merged {
+		scene.Sequence = i + 1
+	}
+	return merged
+}
+
+// reconcileForeshadowOps 根据最终场景序号更新章节规划中的伏笔操作场景号
+func reconcileForeshadowOps(state *EvolutionState, chapterNum int, scenes []*SceneDetailInstruction) {
+	plantScene := make(map[string]int)
+	payoffScene := make(map[string]int)
+	for _, scene := range scenes {
+		for _, p := range scene.Foreshadowing.Plant {
+			plantScene[p.ForeshadowID] = scene.Sequence
+		}
+		for _, p := range scene.Foreshadowing.Payoff {
+			payoffScene[p.ForeshadowID] = scene.Sequence
+		}
+	}
+
+	for i := range state.ChapterPlan.ChapterSequence {
+		if state.ChapterPlan.ChapterSequence[i].Chapter != chapterNum {
+			continue
+		}
+		ops := &state.ChapterPlan.ChapterSequence[i].ForeshadowOps
+		for j := range ops.Plant {
+			if seq, ok := plantScene[ops.Plant[j].ForeshadowID]; ok {
+				ops.Plant[j].Scene = seq
+			}
+		}
+		for j := range ops.Payoff {
+			if seq, ok := payoffScene[ops.Payoff[j].ForeshadowID]; ok {
+				ops.Payoff[j].Scene = seq
+			}
+		}
+	}
+}
+
+// formatPinnedScenes 定稿场景的提示词片段
+func formatPinnedScenes(pinned []*SceneDetailInstruction) string {
+	if len(pinned) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("\n以下场景已定稿，将保留在原位置，请只设计其余场景，并与它们自然衔接（不要重复其中的伏笔操作）：\n")
+	for _, scene := range pinned {
+		sb.WriteString(fmt.Sprintf("- 场景%d [%s] %s：%s\n", scene.Sequence, scene.SceneType, scene.Location, scene.MainAction))
+	}
+	return sb.String()
+}
@@ -0,0 +1,32 @@
+package narrative
+
+import "github.com/xlei/xupu/internal/models"
+
+// ValidatePOV 按蓝图的视角策略检查全部场景指令，返回不符合策略的场景
+func ValidatePOV(blueprint *models.NarrativeBlueprint) []models.POVViolation {
+	violations := make([]models.POVViolation, 0)
+	if blueprint == nil || blueprint.POVPolicy == nil {
+		return violations
+	}
+	for _, scene := range blueprint.Scenes {
+		if v := blueprint.POVPolicy.Check(scene); v != nil {
+			violations = append(violations, *v)
+		}
+	}
+	return violations
+}
+
+// ApplyPOVPolicy 设置蓝图的视角策略，并把场景指令的视角角色改为策略要求的角色
+// 只在规划阶段使用（策略随蓝图一起确定时），返回被修改的场景数
+func ApplyPOVPolicy(blueprint *models.NarrativeBlueprint, policy *models.POVPolicy) int {
+	blueprint.POVPolicy = policy
+	changed := 0
+	for i := range blueprint.Scenes {
+		expected := policy.ExpectedPOV(blueprint.Scenes[i].Chapter)
+		if expected != "" && blueprint.Scenes[i].POVCharacter != expected {
+			blueprint.Scenes[i].POVCharacter = expected
+			changed++
+		}
+	}
+	return changed
+}
@@ -0,0 +1,62 @@
+package narrative
+
+import (
+	"sort"
+
+	"github.com/xlei/xupu/pkg/ctxbudget"
+	"github.com/xlei/xupu/pkg/promptctx"
+)
+
+// systemPromptReserve 构建用户提示词时为系统提示词预留的token数
+const systemPromptReserve = 2000
+
+// promptContext 演化状态对应的提示词上下文，角色按名称排序，保证同一状态生成的提示词一致（压缩缓存可命中）
+func promptContext(state *EvolutionState) *promptctx.Context {
+	ctx := &promptctx.Context{World: state.WorldContext}
+
+	for _, char := range state.Characters {
+		ctx.Characters = append(ctx.Characters, promptctx.Character{
+			Name: char.Name,
+			Role: char.Role,
+			Want: char.Desires.ConsciousWant,
+			Need: char.Desires.UnconsciousNeed,
+			Fear: char.Desires.Fear,
+		})
+	}
+	sort.Slice(ctx.Characters, func(i, j int) bool { return ctx.Characters[i].Name < ctx.Characters[j].Name })
+
+	for _, c := range state.Conflicts {
+		path := make([]string, 0, len(c.EvolutionPath))
+		for _, stage := range c.EvolutionPath {
+			path = append(path, stage.Description)
+		}
+		ctx.Conflicts = append(ctx.Conflicts, promptctx.Conflict{
+			Type:      c.Type,
+			Question:  c.CoreQuestion,
+			Intensity: c.CurrentIntensity,
+			Resolved:  c.IsResolved,
+			Path:      path,
+		})
+	}
+	return ctx
+}
+
+// fitPrompt 按当前模型的上下文窗口拼接提示词，超出时压缩或截断低优先级的设定
+// 大型世界的完整设定可能超出窗口，压缩结果按内容缓存，演化各轮次复用
+func (ee *EvolutionEngine) fitPrompt(sections *promptctx.Composer) string {
+	limit := 0
+	var compressor ctxbudget.Compressor
+	if ee.client != nil {
+		maxTokens := 0
+		if ee.mapping != nil {
+			maxTokens = ee.mapping.MaxTokens
+		}
+		limit = ee.client.PromptBudget(maxTokens) - systemPromptReserve
+		compressor = ctxbudget.NewLLMCompressor(ee.client)
+	}
+	result := ctxbudget.Fit(sections.Sections(), limit, compressor)
+	if len(result.Actions) > 0 {
+		ee.log().Info("提示词超出上下文预算，已压缩低优先级设定", "tokens", result.Tokens, "limit", limit, "actions", result.Actions)
+	}
+	return result.Text
+}
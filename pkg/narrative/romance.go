@@ -0,0 +1,78 @@
+// Package narrative 叙事器 - 感情线节拍规划
+// 在已有的角色关系之上，为一对角色安排感情线各节拍的目标章节
+package narrative
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+)
+
+// RomanceParams 感情线规划参数
+type RomanceParams struct {
+	CharacterA   string
+	CharacterB   string
+	Dynamic      string // 期望的感情模式（可选）
+	Relationship string // 两人现有关系的描述（可选）
+}
+
+// PlanRomanceArc 按章节规划为一对角色安排感情线节拍
+// 返回的感情线未分配ID
+func (ne *NarrativeEngine) PlanRomanceArc(blueprint *models.NarrativeBlueprint, params RomanceParams) (*models.RomanceArc, error) {
+	if len(blueprint.ChapterPlans) == 0 {
+		return nil, fmt.Errorf("蓝图没有章节规划")
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("# 感情线节拍规划\n\n")
+	prompt.WriteString(fmt.Sprintf("## 角色\n%s 与 %s\n", params.CharacterA, params.CharacterB))
+	if params.Relationship != "" {
+		prompt.WriteString(fmt.Sprintf("现有关系：%s\n", params.Relationship))
+	}
+	if params.Dynamic != "" {
+		prompt.WriteString(fmt.Sprintf("感情模式：%s\n", params.Dynamic))
+	}
+	prompt.WriteString("\n## 章节规划\n")
+	for _, p := range blueprint.ChapterPlans {
+		prompt.WriteString(fmt.Sprintf("第%d章 %s：%s\n", p.Chapter, p.Title, p.Purpose))
+	}
+	prompt.WriteString("\n")
+
+	names := make([]string, 0, len(models.RomanceBeatOrder))
+	for _, t := range models.RomanceBeatOrder {
+		names = append(names, fmt.Sprintf("%s（%s）", t, models.RomanceBeatNames[t]))
+	}
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString(fmt.Sprintf("1. 节拍类型：%s；初遇、至暗时刻、确定关系必须有，其余按需要安排，同一类型可出现多次\n", strings.Join(names, "、")))
+	prompt.WriteString(fmt.Sprintf("2. chapter 为第1-%d章中的目标章节，要选两人在情节上有交集的章节\n", len(blueprint.ChapterPlans)))
+	prompt.WriteString("3. 节奏：初遇到初吻之间留出升温的篇幅；至暗时刻放在全书后半，确定关系放在后三分之一；相邻节拍不要间隔太久\n")
+	prompt.WriteString("4. description 写这一节拍具体发生什么，要贴合该章的情节\n")
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{"dynamic": "感情模式", "beats": [{"type": "meet_cute", "chapter": 1, "description": "具体发生什么"}]}`)
+
+	result, err := ne.callWithRetry(prompt.String(), `你是一位言情小说策划，擅长安排感情线的起伏节奏。节拍要服务于主线情节，不要为感情而感情。`)
+	if err != nil {
+		return nil, fmt.Errorf("规划感情线失败: %w", err)
+	}
+
+	var arc models.RomanceArc
+	if err := jsonx.Unmarshal(result, &arc); err != nil {
+		return nil, fmt.Errorf("解析感情线规划失败: %w", err)
+	}
+	beats := make([]models.RomanceBeat, 0, len(arc.Beats))
+	for _, b := range arc.Beats {
+		if _, ok := models.RomanceBeatNames[b.Type]; !ok {
+			continue
+		}
+		beats = append(beats, b)
+	}
+	arc.CharacterA = params.CharacterA
+	arc.CharacterB = params.CharacterB
+	if params.Dynamic != "" {
+		arc.Dynamic = params.Dynamic
+	}
+	arc.Beats = beats
+	return &arc, nil
+}
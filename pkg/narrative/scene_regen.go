@@ -0,0 +1,150 @@
+// Package narrative 叙事器 - 单场景重新生成
+// 只重新生成细纲中的一个场景，结果缝合回章节细纲，其余场景保持不变
+package narrative
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// 单场景重新生成的错误
+var (
+	ErrSceneNotFound = errors.New("场景不存在")
+	ErrScenePinned   = errors.New("场景已定稿，请先取消定稿")
+)
+
+// RegenerateScene 重新生成章节细纲中的单个场景
+// guidance 为用户的修改意见（可选）；已定稿的场景不允许重新生成
+func (o *Orchestrator) RegenerateScene(outline *ChapterDetailOutline, sequence int, guidance string) (*SceneDetailInstruction, error) {
+	index := -1
+	for i, scene := range outline.Scenes {
+		if scene.Sequence == sequence {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("第%d章场景%d: %w", outline.Chapter, sequence, ErrSceneNotFound)
+	}
+
+	old := outline.Scenes[index]
+	if old.Pinned {
+		return nil, fmt.Errorf("场景%d: %w", sequence, ErrScenePinned)
+	}
+
+	chapter := &ChapterSynopsis{
+		Chapter:   outline.Chapter,
+		Title:     outline.Title,
+		Purpose:   outline.Purpose,
+		KeyEvents: outline.KeyEvents,
+	}
+
+	state := &EvolutionState{}
+	scene, err := o.generateSceneDetailInstruction(state, chapter, describeSceneForRegeneration(outline, index, guidance), index)
+	if err != nil {
+		return nil, err
+	}
+
+	// 场景目的和写作指导沿用原场景，重新生成的只是具体写法
+	scene.Sequence = sequence
+	scene.Purpose = old.Purpose
+	scene.WritingGuidance = old.WritingGuidance
+	if scene.SceneType == "" {
+		scene.SceneType = old.SceneType
+	}
+	if scene.StoryTime == nil {
+		scene.StoryTime = old.StoryTime
+	}
+
+	outline.Scenes[index] = scene
+	return scene, nil
+}
+
+// describeSceneForRegeneration 重新生成时的场景描述：原目的、前后场景衔接和用户意见
+func describeSceneForRegeneration(outline *ChapterDetailOutline, index int, guidance string) string {
+	old := outline.Scenes[index]
+
+	var sb strings.Builder
+	sb.WriteString(old.Purpose)
+	if index > 0 {
+		prev := outline.Scenes[index-1]
+		sb.WriteString(fmt.Sprintf("\n上一场景：%s（%s）", prev.MainAction, prev.Location))
+	}
+	if index < len(outline.Scenes)-1 {
+		next := outline.Scenes[index+1]
+		sb.WriteString(fmt.Sprintf("\n下一场景：%s（%s），请与之自然衔接", next.MainAction, next.Location))
+	}
+	if old.MainAction != "" {
+		sb.WriteString(fmt.Sprintf("\n原方案（需要改写）：%s", old.MainAction))
+	}
+	if guidance != "" {
+		sb.WriteString(fmt.Sprintf("\n作者修改意见：%s", guidance))
+	}
+	return sb.String()
+}
+
+// OutlineFromBlueprint 由蓝图的章节规划和场景指令构建细纲
+// 用于章节还没有保存细纲时作为重新生成的起点
+func OutlineFromBlueprint(plan *models.ChapterPlan, scenes []models.SceneInstruction) *ChapterDetailOutline {
+	outline := &ChapterDetailOutline{
+		Chapter:            plan.Chapter,
+		Title:              plan.Title,
+		Purpose:            plan.Purpose,
+		KeyEvents:          plan.KeyScenes,
+		EstimatedWordCount: plan.WordCount,
+		Scenes:             make([]*SceneDetailInstruction, 0, len(scenes)),
+	}
+
+	for _, s := range scenes {
+		if s.Chapter != plan.Chapter {
+			continue
+		}
+		outline.Scenes = append(outline.Scenes, &SceneDetailInstruction{
+			Sequence:      s.Scene,
+			Purpose:       s.Purpose,
+			Location:      s.Location,
+			StoryTime:     s.StoryTime,
+			POVCharacter:  s.POVCharacter,
+			Characters:    s.Characters,
+			MainAction:    s.Action,
+			DialogueFocus: s.DialogueFocus,
+			Atmosphere:    SceneAtmosphere{Mood: s.Mood},
+		})
+	}
+	return outline
+}
+
+// ToSceneInstruction 转换为写作器使用的场景指令
+func (s *SceneDetailInstruction) ToSceneInstruction(chapter, expectedLength int) models.SceneInstruction {
+	return models.SceneInstruction{
+		Chapter:        chapter,
+		Scene:          s.Sequence,
+		Sequence:       s.Sequence,
+		Purpose:        s.Purpose,
+		Location:       s.Location,
+		Characters:     s.Characters,
+		POVCharacter:   s.POVCharacter,
+		Action:         s.MainAction,
+		DialogueFocus:  s.DialogueFocus,
+		ExpectedLength: expectedLength,
+		Mood:           s.Atmosphere.Mood,
+		Status:         "pending",
+		StoryTime:      s.StoryTime,
+	}
+}
+
+// Checklist 转换为写作器自我修订使用的审稿清单
+func (s *SceneDetailInstruction) Checklist() writer.SceneChecklist {
+	return writer.SceneChecklist{
+		POVCharacter:  s.POVCharacter,
+		MustInclude:   s.Constraints.MustInclude,
+		MustNotReveal: s.Constraints.MustNotReveal,
+		Mood:          s.Atmosphere.Mood,
+		Pacing:        s.Atmosphere.Pacing,
+		SensoryFocus:  s.Atmosphere.SensoryFocus,
+	}
+}
@@ -0,0 +1,17 @@
+// Package narrative 叙事器 - 响应结构校验
+// 各提示词角色的 JSON Schema，由LLM客户端校验响应，不通过时自动请求修复
+package narrative
+
+import "github.com/xlei/xupu/pkg/llm"
+
+// 提示词角色
+const (
+	roleCritique = "narrative.critique"
+)
+
+func init() {
+	llm.MarkNonCritical(roleCritique)
+	llm.RegisterSchema(roleCritique, llm.SchemaOf(critiqueResponse{}).
+		Require("sub_scores").
+		Range("sub_scores.*", 0, 100))
+}
@@ -0,0 +1,45 @@
+// Package narrative 叙事器 - 风格档案
+// 将项目的写作风格档案注入每个场景的写作指导
+package narrative
+
+import (
+	"fmt"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// SetStyleProfile 设置项目写作风格档案
+func (o *Orchestrator) SetStyleProfile(profile *models.StyleProfile) {
+	o.styleProfile = profile
+}
+
+// applyStyleProfile 将风格档案合并到写作指导
+func applyStyleProfile(guidance *WritingGuidance, profile *models.StyleProfile) {
+	if guidance == nil || profile == nil {
+		return
+	}
+
+	if distance, ok := map[string]string{
+		"close":   "近距离",
+		"medium":  "中距离",
+		"distant": "远距离",
+	}[profile.NarrativeDistance]; ok {
+		guidance.NarrativeDistance = distance
+	}
+
+	hints := []string{fmt.Sprintf("风格：%s", profile.Name)}
+	if profile.SentenceRhythm != "" {
+		hints = append(hints, "句子节奏："+profile.SentenceRhythm)
+	}
+	if profile.VocabularyLevel != "" {
+		hints = append(hints, "词汇水平："+profile.VocabularyLevel)
+	}
+	if profile.Tense != "" {
+		hints = append(hints, "时态："+profile.Tense)
+	}
+	if profile.POVConvention != "" {
+		hints = append(hints, "视角约定："+profile.POVConvention)
+	}
+	guidance.StyleHints = append(hints, guidance.StyleHints...)
+	guidance.BannedPhrases = append(guidance.BannedPhrases, profile.BannedPhrases...)
+}
@@ -0,0 +1,346 @@
+// Package narrative 叙事器 - 副线管理与张力预算
+// 在章节规划之后规划副线和背景线：分配到章节、追踪每条线的张力曲线、检查休眠过久的线程，并保证副线在终幕前收束
+package narrative
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// 情节线程类型
+const (
+	PlotThreadMain       = "主线"
+	PlotThreadSub        = "副线"
+	PlotThreadBackground = "背景线"
+)
+
+// 情节线程状态
+const (
+	ThreadStatusDormant  = "dormant"
+	ThreadStatusActive   = "active"
+	ThreadStatusPaused   = "paused"
+	ThreadStatusResolved = "resolved"
+)
+
+// 副线问题类型
+const (
+	SubplotWarningDormant    = "dormant"     // 休眠过久
+	SubplotWarningOverBudget = "over_budget" // 副线张力挤占主线
+	SubplotWarningForced     = "forced"      // 终幕前强制收束
+)
+
+// subplotDormancyLimit 各类线程允许连续不出场的章节数
+var subplotDormancyLimit = map[string]int{
+	PlotThreadMain:       2,
+	PlotThreadSub:        3,
+	PlotThreadBackground: 5,
+}
+
+// subplotTensionBudget 单章副线与背景线张力之和的上限，超出时副线会喧宾夺主
+const subplotTensionBudget = 120
+
+// SubplotWarning 副线规划中发现的问题
+type SubplotWarning struct {
+	ThreadID    string `json:"thread_id,omitempty"`
+	Kind        string `json:"kind"` // dormant/over_budget/forced
+	FromChapter int    `json:"from_chapter"`
+	ToChapter   int    `json:"to_chapter"`
+	Message     string `json:"message"`
+}
+
+// PlanSubplots 规划副线与背景线并分配到章节（1轮LLM）
+// 需要在阶段6（章节规划）之后、收尾规划之前调用，收尾规划据此盘点线程是否收束
+func (o *Orchestrator) PlanSubplots(state *EvolutionState) ([]SubplotWarning, error) {
+	if state.ChapterPlan == nil || len(state.ChapterPlan.ChapterSequence) == 0 {
+		return nil, fmt.Errorf("尚未完成章节规划")
+	}
+
+	state.CurrentRound++
+	finalAct := finalActStart(state)
+	prompt := o.buildSubplotPrompt(state, finalAct)
+	systemPrompt := o.buildSystemPrompt("subplot_planner")
+
+	response, err := o.engine.callWithRetry(prompt, systemPrompt)
+	if err != nil {
+		return nil, fmt.Errorf("副线规划失败: %w", err)
+	}
+
+	var result struct {
+		Threads []*PlotThread `json:"threads"`
+	}
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return nil, fmt.Errorf("解析副线规划结果失败: %w", err)
+	}
+
+	threads := make([]*PlotThread, 0, len(result.Threads))
+	for i, thread := range result.Threads {
+		if thread == nil || thread.Name == "" {
+			continue
+		}
+		if thread.ID == "" {
+			thread.ID = fmt.Sprintf("thread_%d", i+1)
+		}
+		if _, ok := subplotDormancyLimit[thread.Type]; !ok {
+			thread.Type = PlotThreadSub
+		}
+		threads = append(threads, thread)
+	}
+	state.PlotThreads = threads
+
+	warnings := state.BudgetSubplots(finalAct)
+	state.SubplotWarnings = warnings
+
+	state.logAction(state.CurrentRound, "subplot_planning", "副线规划", []string{
+		fmt.Sprintf("线程: %d", len(threads)),
+		fmt.Sprintf("终幕起始: 第%d章", finalAct),
+		fmt.Sprintf("问题: %d", len(warnings)),
+	})
+
+	return warnings, nil
+}
+
+// BudgetSubplots 整理情节线程：事件按章节排序、终幕前强制收束副线、更新状态与当前张力，
+// 把线程节拍写入章节概要，并返回休眠过久和张力超预算的问题
+func (s *EvolutionState) BudgetSubplots(finalAct int) []SubplotWarning {
+	warnings := make([]SubplotWarning, 0)
+	lastChapter := 0
+	if s.ChapterPlan != nil {
+		lastChapter = lastChapterNum(s)
+	}
+
+	for _, thread := range s.PlotThreads {
+		sort.SliceStable(thread.KeyEvents, func(i, j int) bool {
+			return thread.KeyEvents[i].Chapter < thread.KeyEvents[j].Chapter
+		})
+		for i := range thread.KeyEvents {
+			thread.KeyEvents[i].Sequence = i + 1
+		}
+
+		if thread.Type != PlotThreadMain && finalAct > 1 && !thread.resolvedBefore(finalAct) {
+			chapter := finalAct - 1
+			thread.KeyEvents = append(thread.KeyEvents, PlotEvent{
+				Sequence:    len(thread.KeyEvents) + 1,
+				Chapter:     chapter,
+				Description: fmt.Sprintf("收束「%s」，为终幕让出空间", thread.Name),
+				Resolves:    true,
+			})
+			warnings = append(warnings, SubplotWarning{
+				ThreadID:    thread.ID,
+				Kind:        SubplotWarningForced,
+				FromChapter: chapter,
+				ToChapter:   chapter,
+				Message:     fmt.Sprintf("%s「%s」在终幕前未收束，已在第%d章安排收束", thread.Type, thread.Name, chapter),
+			})
+		}
+
+		warnings = append(warnings, thread.dormancyWarnings(lastChapter)...)
+		thread.refreshStatus(lastChapter)
+	}
+
+	warnings = append(warnings, s.tensionBudgetWarnings()...)
+	s.assignSubplotsToChapters()
+	return warnings
+}
+
+// TensionCurve 线程在第1章到第 total 章的张力曲线：事件章取事件张力，其余章节沿用上一次的张力，收束后归零
+func (t *PlotThread) TensionCurve(total int) []int {
+	curve := make([]int, total)
+	tension, event := 0, 0
+	for ch := 1; ch <= total; ch++ {
+		for event < len(t.KeyEvents) && t.KeyEvents[event].Chapter <= ch {
+			e := t.KeyEvents[event]
+			tension = e.Tension
+			if e.Resolves {
+				tension = 0
+			}
+			event++
+		}
+		curve[ch-1] = tension
+	}
+	return curve
+}
+
+// resolvedBefore 线程是否在指定章节之前收束
+func (t *PlotThread) resolvedBefore(chapter int) bool {
+	for _, e := range t.KeyEvents {
+		if e.Resolves && e.Chapter < chapter {
+			return true
+		}
+	}
+	return false
+}
+
+// dormancyWarnings 线程相邻两次出场间隔超过允许的休眠章节数时给出警告
+func (t *PlotThread) dormancyWarnings(lastChapter int) []SubplotWarning {
+	limit := subplotDormancyLimit[t.Type]
+	warnings := make([]SubplotWarning, 0)
+	prev := 0
+	for _, e := range t.KeyEvents {
+		if prev > 0 && e.Chapter-prev-1 > limit {
+			warnings = append(warnings, SubplotWarning{
+				ThreadID:    t.ID,
+				Kind:        SubplotWarningDormant,
+				FromChapter: prev + 1,
+				ToChapter:   e.Chapter - 1,
+				Message:     fmt.Sprintf("%s「%s」在第%d-%d章休眠了%d章（上限%d章）", t.Type, t.Name, prev+1, e.Chapter-1, e.Chapter-prev-1, limit),
+			})
+		}
+		if e.Resolves {
+			return warnings
+		}
+		prev = e.Chapter
+	}
+	if prev > 0 && lastChapter-prev > limit {
+		warnings = append(warnings, SubplotWarning{
+			ThreadID:    t.ID,
+			Kind:        SubplotWarningDormant,
+			FromChapter: prev + 1,
+			ToChapter:   lastChapter,
+			Message:     fmt.Sprintf("%s「%s」第%d章之后再未出场", t.Type, t.Name, prev),
+		})
+	}
+	return warnings
+}
+
+// refreshStatus 按事件更新线程状态和当前张力
+func (t *PlotThread) refreshStatus(lastChapter int) {
+	if len(t.KeyEvents) == 0 {
+		t.Status = ThreadStatusDormant
+		t.Tension = 0
+		return
+	}
+	last := t.KeyEvents[len(t.KeyEvents)-1]
+	t.Tension = last.Tension
+	switch {
+	case last.Resolves:
+		t.Status = ThreadStatusResolved
+		t.Tension = 0
+	case lastChapter-last.Chapter > subplotDormancyLimit[t.Type]:
+		t.Status = ThreadStatusDormant
+	default:
+		t.Status = ThreadStatusActive
+	}
+}
+
+// tensionBudgetWarnings 单章副线张力之和超过预算时给出警告
+func (s *EvolutionState) tensionBudgetWarnings() []SubplotWarning {
+	if s.ChapterPlan == nil {
+		return nil
+	}
+	total := lastChapterNum(s)
+	budget := make([]int, total)
+	for _, thread := range s.PlotThreads {
+		if thread.Type == PlotThreadMain {
+			continue
+		}
+		for i, tension := range thread.TensionCurve(total) {
+			budget[i] += tension
+		}
+	}
+
+	warnings := make([]SubplotWarning, 0)
+	for i, used := range budget {
+		if used > subplotTensionBudget {
+			warnings = append(warnings, SubplotWarning{
+				Kind:        SubplotWarningOverBudget,
+				FromChapter: i + 1,
+				ToChapter:   i + 1,
+				Message:     fmt.Sprintf("第%d章副线张力合计%d，超过预算%d，副线可能喧宾夺主", i+1, used, subplotTensionBudget),
+			})
+		}
+	}
+	return warnings
+}
+
+// assignSubplotsToChapters 把线程节拍写入对应章节概要
+func (s *EvolutionState) assignSubplotsToChapters() {
+	if s.ChapterPlan == nil {
+		return
+	}
+	index := make(map[int]int, len(s.ChapterPlan.ChapterSequence))
+	for i := range s.ChapterPlan.ChapterSequence {
+		s.ChapterPlan.ChapterSequence[i].Subplots = nil
+		index[s.ChapterPlan.ChapterSequence[i].Chapter] = i
+	}
+	for _, thread := range s.PlotThreads {
+		for _, e := range thread.KeyEvents {
+			i, ok := index[e.Chapter]
+			if !ok {
+				continue
+			}
+			beat := fmt.Sprintf("[%s·%s] %s", thread.Type, thread.Name, e.Description)
+			s.ChapterPlan.ChapterSequence[i].Subplots = append(s.ChapterPlan.ChapterSequence[i].Subplots, beat)
+		}
+	}
+}
+
+// finalActStart 终幕起始章节：高潮章往前留一章铺垫，不早于全书四分之三处
+func finalActStart(state *EvolutionState) int {
+	climax := findClimaxChapter(state)
+	start := lastChapterNum(state)*3/4 + 1
+	if climax > 1 && climax-1 < start {
+		start = climax - 1
+	}
+	return start
+}
+
+// buildSubplotPrompt 构建副线规划提示词
+func (o *Orchestrator) buildSubplotPrompt(state *EvolutionState, finalAct int) string {
+	var sb strings.Builder
+	sb.WriteString("规划副线与背景线，并分配到章节：\n\n")
+
+	sb.WriteString("章节规划：\n")
+	for _, ch := range state.ChapterPlan.ChapterSequence {
+		sb.WriteString(fmt.Sprintf("- 第%d章 %s: %s\n", ch.Chapter, ch.Title, ch.Purpose))
+	}
+
+	sb.WriteString("\n角色：\n")
+	for _, char := range sortedCharacters(state.Characters) {
+		sb.WriteString(fmt.Sprintf("- %s（%s）: %s\n", char.Name, char.Role, char.Desires.ConsciousWant))
+	}
+
+	if len(state.Conflicts) > 0 {
+		sb.WriteString("\n冲突：\n")
+		for _, c := range state.Conflicts {
+			sb.WriteString(fmt.Sprintf("- %s: %s\n", c.ID, c.CoreQuestion))
+		}
+	}
+
+	sb.WriteString(fmt.Sprintf(`
+要求：
+1. 设计1条主线（%s）、2-4条副线（%s）和若干背景线（%s），副线要与主题或主角的内在冲突呼应
+2. 每条线程的事件标注所在章节和事件后的张力（0-100），副线张力峰值低于主线
+3. 副线连续不出场不超过%d章，背景线不超过%d章
+4. 所有副线和背景线必须在第%d章（终幕）之前收束，收束事件 resolves 为 true
+5. 终幕只留给主线
+
+请以JSON格式返回：
+{
+  "threads": [
+    {
+      "id": "thread_1",
+      "name": "线程名称",
+      "type": "主线/副线/背景线",
+      "characters": ["角色名"],
+      "dependencies": ["依赖的线程ID"],
+      "key_events": [
+        {"chapter": 1, "description": "事件描述", "tension": 30, "reversal": false, "resolves": false}
+      ]
+    }
+  ]
+}
+只返回JSON，不要包含其他内容。`,
+		PlotThreadMain, PlotThreadSub, PlotThreadBackground,
+		subplotDormancyLimit[PlotThreadSub], subplotDormancyLimit[PlotThreadBackground], finalAct))
+	return sb.String()
+}
+
+// buildChapterSubplotSection 本章需要推进的副线节拍，供场景设计使用
+func buildChapterSubplotSection(chapter *ChapterSynopsis) string {
+	if len(chapter.Subplots) == 0 {
+		return ""
+	}
+	return "副线节拍（安排在合适的场景中）：\n- " + strings.Join(chapter.Subplots, "\n- ") + "\n"
+}
@@ -0,0 +1,27 @@
+// Package narrative 叙事器 - 关键事件时间线
+package narrative
+
+import (
+	"github.com/xlei/xupu/pkg/timeline"
+)
+
+// keyEventTimeline 由关键事件构建时间线，用于在大纲阶段检测时间冲突
+func keyEventTimeline(events []KeyEvent) *timeline.Timeline {
+	entries := make([]timeline.Entry, 0, len(events))
+	untimed := make([]string, 0)
+	for _, event := range events {
+		if event.StoryTime == nil {
+			untimed = append(untimed, event.ID)
+			continue
+		}
+		entries = append(entries, timeline.Entry{
+			ID:         event.ID,
+			Kind:       timeline.KindKeyEvent,
+			Title:      event.Name,
+			Location:   event.Location,
+			Characters: event.InvolvedCharacters,
+			Time:       *event.StoryTime,
+		})
+	}
+	return timeline.New(entries, untimed)
+}
@@ -0,0 +1,126 @@
+// Package narrative 叙事器 - 用户预设角色
+// 作者已设计好的角色在阶段2之前注入演化状态，生成时只补齐剩余的角色名额
+package narrative
+
+import (
+	"fmt"
+
+	"github.com/xlei/xupu/pkg/naming"
+)
+
+// UserCharacter 用户预设角色
+type UserCharacter struct {
+	CharacterState
+	Center bool `json:"center"` // 作为关系网络中心（主角）
+}
+
+// InjectCharacters 注入用户预设角色
+// 未指定ID的角色分配 user_N 形式的ID；关系中可以用角色名引用其他预设角色
+func (s *EvolutionState) InjectCharacters(chars []*UserCharacter) error {
+	if len(chars) == 0 {
+		return nil
+	}
+
+	byName := make(map[string]string, len(chars))
+	for i, uc := range chars {
+		if uc == nil || uc.Name == "" {
+			return fmt.Errorf("第%d个预设角色缺少姓名", i+1)
+		}
+		if uc.ID == "" {
+			uc.ID = fmt.Sprintf("user_%d", len(s.UserCharacters)+i)
+		}
+		if _, exists := s.Characters[uc.ID]; exists {
+			return fmt.Errorf("角色ID重复: %s", uc.ID)
+		}
+		byName[uc.Name] = uc.ID
+	}
+
+	centers := 0
+	for _, uc := range chars {
+		char := uc.CharacterState
+		if char.Relationships == nil {
+			char.Relationships = make(map[string]*RelationshipState)
+		}
+		if char.InternalConflicts == nil {
+			char.InternalConflicts = []string{}
+		}
+		if char.Secrets == nil {
+			char.Secrets = []string{}
+		}
+		if char.EmotionalState.CurrentEmotion == "" {
+			char.EmotionalState.CurrentEmotion = "平静"
+			char.EmotionalState.EmotionalIntensity = 50
+		}
+
+		// 关系目标支持用角色名引用
+		relationships := make(map[string]*RelationshipState, len(char.Relationships))
+		for target, rel := range char.Relationships {
+			if id, ok := byName[target]; ok {
+				target = id
+			}
+			if rel == nil {
+				rel = &RelationshipState{}
+			}
+			rel.TargetCharacterID = target
+			relationships[target] = rel
+		}
+		char.Relationships = relationships
+
+		s.Characters[char.ID] = &char
+		s.lockCharacter(&char)
+		s.UserCharacters = append(s.UserCharacters, char.ID)
+		if uc.Center {
+			s.UserCenter = char.ID
+			centers++
+		}
+	}
+	if centers > 1 {
+		return fmt.Errorf("最多只能指定一个中心角色")
+	}
+
+	// 预设角色名登记到世界，LLM生成的角色不会与之重名
+	if s.WorldContext != nil {
+		names := naming.NewGenerator(s.WorldContext)
+		for _, uc := range chars {
+			names.Register(uc.Name)
+		}
+	}
+
+	s.logAction(s.CurrentRound, "inject_characters", "注入用户预设角色", []string{
+		fmt.Sprintf("预设角色: %d", len(chars)),
+		fmt.Sprintf("中心角色: %s", s.UserCenter),
+	})
+
+	return nil
+}
+
+// applyUserRelationships 将预设角色之间的关系补充到关系网络
+// LLM已经建立的关系边保持不变
+func applyUserRelationships(state *EvolutionState, network *RelationshipNetwork) {
+	for _, id := range state.UserCharacters {
+		char, ok := state.Characters[id]
+		if !ok {
+			continue
+		}
+		for target, rel := range char.Relationships {
+			if _, ok := state.Characters[target]; !ok {
+				continue
+			}
+			key := getRelationshipKey(id, target)
+			if _, exists := network.Edges[key]; exists {
+				continue
+			}
+			tension := rel.VisibleEmotion - rel.HiddenEmotion
+			if tension < 0 {
+				tension = -tension
+			}
+			network.Edges[key] = &Relationship{
+				From:         id,
+				To:           target,
+				Type:         "预设",
+				Tension:      tension,
+				CurrentState: rel.PowerDynamic,
+			}
+		}
+	}
+}
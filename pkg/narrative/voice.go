@@ -0,0 +1,15 @@
+package narrative
+
+import "github.com/xlei/xupu/internal/models"
+
+// voiceProfilesFromEvolution 收集演化中创建的角色语音档案，按角色名索引
+func voiceProfilesFromEvolution(state *EvolutionState) map[string]*models.VoiceProfile {
+	profiles := make(map[string]*models.VoiceProfile)
+	for _, char := range state.Characters {
+		if char == nil || char.Name == "" || char.Voice.Empty() {
+			continue
+		}
+		profiles[char.Name] = char.Voice
+	}
+	return profiles
+}
@@ -0,0 +1,261 @@
+// Package narrative 叙事器 - 假设分支（what-if）
+// 从某一章起改变关键事件的结果，保留此前的主线，重新规划此后的章节和场景，供作者与主线比较后决定是否采纳
+package narrative
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+)
+
+// maxWhatIfChapters 一次分支规划的章节数上限
+const maxWhatIfChapters = 30
+
+// planOutputFormat 分支和续写规划共用的输出格式，解析为 whatIfOutput
+const planOutputFormat = `{
+  "key_events": [{"name": "事件名", "description": "事件描述", "involved_characters": ["角色名"]}],
+  "chapters": [
+    {
+      "chapter": 1,
+      "title": "章节标题",
+      "purpose": "本章目的",
+      "key_scenes": ["关键场景"],
+      "plot_advancement": "情节推进",
+      "arc_progress": "角色弧光进展",
+      "ending_hook": "结尾悬念",
+      "conflict_intensity": 60,
+      "scenes": [{"purpose": "场景目的", "location": "地点", "characters": ["角色名"], "pov_character": "视角角色", "action": "主要动作", "dialogue_focus": "对话重点", "mood": "氛围"}]
+    }
+  ]
+}`
+
+// WhatIfParams 假设分支参数
+type WhatIfParams struct {
+	ForkChapter int    // 从该章起（含）与主线不同
+	Outcome     string // 改变后的关键事件结果
+	Chapters    int    // 分支规划的章节数，0 表示规划到主线结尾（不超过 maxWhatIfChapters）
+	Ending      string // 分支必须收束到的高潮与结局（可选），用于改换结局后重新规划终幕
+}
+
+// WhatIfPlan 假设分支的规划结果
+type WhatIfPlan struct {
+	ChapterPlans []models.ChapterPlan
+	Scenes       []models.SceneInstruction
+	State        *EvolutionState // 分支的演化状态：世界上下文、主线角色和分支的关键事件序列
+}
+
+// whatIfOutput 分支规划的 LLM 输出
+type whatIfOutput struct {
+	KeyEvents []struct {
+		Name               string   `json:"name"`
+		Description        string   `json:"description"`
+		InvolvedCharacters []string `json:"involved_characters"`
+	} `json:"key_events"`
+	Chapters []struct {
+		Chapter           int      `json:"chapter"`
+		Title             string   `json:"title"`
+		Purpose           string   `json:"purpose"`
+		KeyScenes         []string `json:"key_scenes"`
+		PlotAdvancement   string   `json:"plot_advancement"`
+		ArcProgress       string   `json:"arc_progress"`
+		EndingHook        string   `json:"ending_hook"`
+		ConflictIntensity int      `json:"conflict_intensity"`
+		Scenes            []struct {
+			Purpose       string   `json:"purpose"`
+			Location      string   `json:"location"`
+			Characters    []string `json:"characters"`
+			POVCharacter  string   `json:"pov_character"`
+			Action        string   `json:"action"`
+			DialogueFocus string   `json:"dialogue_focus"`
+			Mood          string   `json:"mood"`
+		} `json:"scenes"`
+	} `json:"chapters"`
+}
+
+// PlanWhatIf 规划假设分支：分叉点之前沿用主线，分叉点起按改变后的结果重新规划章节和场景
+func (ne *NarrativeEngine) PlanWhatIf(blueprint *models.NarrativeBlueprint, params WhatIfParams) (*WhatIfPlan, error) {
+	total := len(blueprint.ChapterPlans)
+	if params.ForkChapter < 1 || params.ForkChapter > total {
+		return nil, fmt.Errorf("分叉章节超出范围: 第%d章（共%d章）", params.ForkChapter, total)
+	}
+	if strings.TrimSpace(params.Outcome) == "" && strings.TrimSpace(params.Ending) == "" {
+		return nil, fmt.Errorf("未给出改变后的事件结果")
+	}
+	count := params.Chapters
+	if count <= 0 {
+		count = total - params.ForkChapter + 1
+	}
+	if count > maxWhatIfChapters {
+		count = maxWhatIfChapters
+	}
+
+	state, err := ne.evolution.CreateEvolutionState(blueprint.WorldID)
+	if err != nil {
+		return nil, fmt.Errorf("创建分支演化状态失败: %w", err)
+	}
+
+	ne.log().Info("规划假设分支", "blueprint_id", blueprint.ID, "fork_chapter", params.ForkChapter, "chapters", count)
+	result, err := ne.callWithRetry(ne.buildWhatIfPrompt(state, blueprint, params, count),
+		`你是一位资深网络小说策划编辑，擅长推演情节走向。改变一个关键事件的结果后，要让后续情节自然地由这个结果推动，而不是换个说法重复原方案。`)
+	if err != nil {
+		return nil, fmt.Errorf("规划假设分支失败: %w", err)
+	}
+
+	var output whatIfOutput
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, fmt.Errorf("解析假设分支失败: %w", err)
+	}
+	if len(output.Chapters) == 0 {
+		return nil, fmt.Errorf("假设分支没有章节规划")
+	}
+
+	// 字数沿用主线分叉点之后章节的平均值
+	wordCount := 0
+	for _, p := range blueprint.ChapterPlans[params.ForkChapter-1:] {
+		wordCount += p.WordCount
+	}
+	wordCount /= total - params.ForkChapter + 1
+
+	plan := &WhatIfPlan{State: state}
+	plan.ChapterPlans, plan.Scenes = ne.chapterPlansFromOutput(&output, params.ForkChapter, count, wordCount)
+
+	events := output.keyEvents("whatif_event")
+	state.GlobalOutline = &GlobalOutline{
+		Opening:    blueprint.StoryOutline.Act1.Setup,
+		KeyEvents:  events,
+		Climax:     blueprint.StoryOutline.Act3.Climax,
+		Resolution: blueprint.StoryOutline.Act3.Resolution,
+	}
+	state.logAction(state.CurrentRound, "what_if_fork", fmt.Sprintf("第%d章起分叉: %s", params.ForkChapter, params.Outcome), []string{
+		fmt.Sprintf("章节数: %d", len(plan.ChapterPlans)),
+		fmt.Sprintf("关键事件数: %d", len(events)),
+	})
+	return plan, nil
+}
+
+// chapterPlansFromOutput 把规划输出转换为从 start 章起的至多 count 章的章节规划和场景指令
+func (ne *NarrativeEngine) chapterPlansFromOutput(output *whatIfOutput, start, count, wordCount int) ([]models.ChapterPlan, []models.SceneInstruction) {
+	var plans []models.ChapterPlan
+	var scenes []models.SceneInstruction
+	for i, ch := range output.Chapters {
+		if i >= count {
+			break
+		}
+		num := start + i
+		title := ch.Title
+		if title == "" {
+			title = ne.terms().Chapter(num)
+		}
+		plans = append(plans, models.ChapterPlan{
+			Chapter:           num,
+			Title:             title,
+			Purpose:           ch.Purpose,
+			KeyScenes:         ch.KeyScenes,
+			PlotAdvancement:   ch.PlotAdvancement,
+			ArcProgress:       ch.ArcProgress,
+			EndingHook:        ch.EndingHook,
+			WordCount:         wordCount,
+			Status:            "pending",
+			ConflictIntensity: ch.ConflictIntensity,
+		})
+		for j, s := range ch.Scenes {
+			scenes = append(scenes, models.SceneInstruction{
+				Chapter:        num,
+				Scene:          j + 1,
+				Sequence:       j + 1,
+				Purpose:        s.Purpose,
+				Location:       s.Location,
+				Characters:     s.Characters,
+				POVCharacter:   s.POVCharacter,
+				Action:         s.Action,
+				DialogueFocus:  s.DialogueFocus,
+				ExpectedLength: wordCount / len(ch.Scenes),
+				Mood:           s.Mood,
+				Status:         "pending",
+			})
+		}
+	}
+	return plans, scenes
+}
+
+// keyEvents 规划输出中的关键事件，ID 以 prefix 为前缀按顺序编号
+func (o *whatIfOutput) keyEvents(prefix string) []KeyEvent {
+	events := make([]KeyEvent, 0, len(o.KeyEvents))
+	for i, e := range o.KeyEvents {
+		events = append(events, KeyEvent{
+			ID:                 fmt.Sprintf("%s_%d", prefix, i+1),
+			Sequence:           i + 1,
+			Name:               e.Name,
+			Description:        e.Description,
+			InvolvedCharacters: e.InvolvedCharacters,
+		})
+	}
+	return events
+}
+
+// buildWhatIfPrompt 构建假设分支规划提示词
+func (ne *NarrativeEngine) buildWhatIfPrompt(state *EvolutionState, blueprint *models.NarrativeBlueprint, params WhatIfParams, count int) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("# 假设分支规划\n\n")
+	prompt.WriteString("## 世界设定\n")
+	prompt.WriteString(ne.buildWorldSummary(state.WorldContext))
+	prompt.WriteString("\n")
+
+	if characters := ne.db.ListCharactersByWorld(blueprint.WorldID); len(characters) > 0 {
+		prompt.WriteString("## 主要角色\n")
+		for i, ch := range characters {
+			if i >= 12 {
+				break
+			}
+			line := "- " + ch.Name
+			if arc, ok := blueprint.CharacterArcs[ch.ID]; ok && arc.ArcType != "" {
+				line += fmt.Sprintf("（弧光: %s）", arc.ArcType)
+			}
+			if ch.StaticProfile.Occupation != "" {
+				line += ": " + ch.StaticProfile.Occupation
+			}
+			prompt.WriteString(line + "\n")
+		}
+		prompt.WriteString("\n")
+	}
+
+	prompt.WriteString("## 主线（分叉点之前，保持不变）\n")
+	for _, ch := range blueprint.ChapterPlans {
+		if ch.Chapter >= params.ForkChapter {
+			break
+		}
+		prompt.WriteString(fmt.Sprintf("- 第%d章《%s》: %s\n", ch.Chapter, ch.Title, ch.PlotAdvancement))
+	}
+
+	prompt.WriteString(fmt.Sprintf("\n## 主线原方案（第%d章起，将被替换）\n", params.ForkChapter))
+	for _, ch := range blueprint.ChapterPlans[params.ForkChapter-1:] {
+		prompt.WriteString(fmt.Sprintf("- 第%d章《%s》: %s\n", ch.Chapter, ch.Title, ch.Purpose))
+	}
+
+	if params.Outcome != "" {
+		prompt.WriteString(fmt.Sprintf("\n## 改变\n从第%d章起，关键事件的结果改为：%s\n", params.ForkChapter, params.Outcome))
+	}
+	if params.Ending != "" {
+		prompt.WriteString("\n## 必须收束到的结局\n")
+		prompt.WriteString(params.Ending + "\n")
+	}
+
+	prompt.WriteString("\n# 要求\n")
+	prompt.WriteString(fmt.Sprintf("1. 从第%d章起重新规划%d章，chapter 从%d开始连续编号\n", params.ForkChapter, count, params.ForkChapter))
+	if params.Ending != "" {
+		prompt.WriteString("2. 情节逐步推向上面的高潮与结局，最后几章依次呈现高潮和结局，不能偏离或另起结局\n")
+	} else {
+		prompt.WriteString("2. 第一章必须呈现改变后的结果，此后的情节由这个结果推动，角色的反应符合各自的动机\n")
+	}
+	prompt.WriteString("3. 分叉点之前已经发生的事实不能改动，已埋下的伏笔要有交代\n")
+	prompt.WriteString("4. key_events 列出分支中的关键事件，按发生顺序排列\n")
+	prompt.WriteString("5. 每章2-4个场景，conflict_intensity 为0-100的整数\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(planOutputFormat)
+
+	return prompt.String()
+}
@@ -0,0 +1,89 @@
+// Package narrative 叙事器 - 章节写作计划
+// 把章节细纲转换为写作器章节流水线使用的写作计划
+package narrative
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// WritingPlan 转换为写作器章节流水线的写作计划
+func (o *ChapterDetailOutline) WritingPlan() writer.ChapterPlan {
+	plan := writer.ChapterPlan{
+		Chapter:   o.Chapter,
+		Title:     o.Title,
+		Purpose:   o.Purpose,
+		Tone:      o.Tone,
+		KeyEvents: o.KeyEvents,
+		WordCount: o.EstimatedWordCount,
+	}
+	for _, s := range o.Scenes {
+		if s != nil {
+			plan.Scenes = append(plan.Scenes, s.WritingPlan())
+		}
+	}
+	sort.SliceStable(plan.Scenes, func(i, j int) bool { return plan.Scenes[i].Sequence < plan.Scenes[j].Sequence })
+	return plan
+}
+
+// WritingPlan 转换为写作器章节流水线的场景写作要点
+func (s *SceneDetailInstruction) WritingPlan() writer.ScenePlan {
+	scene := writer.ScenePlan{
+		Sequence:      s.Sequence,
+		Purpose:       s.Purpose,
+		Location:      s.Location,
+		Time:          s.Time,
+		Characters:    s.Characters,
+		MainAction:    s.MainAction,
+		DialogueFocus: s.DialogueFocus,
+		Transition:    s.Constraints.TransitionHint,
+		Checklist:     s.Checklist(),
+	}
+
+	names := make([]string, 0, len(s.CharacterStateChanges))
+	for name := range s.CharacterStateChanges {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if c := s.CharacterStateChanges[name]; c != nil && c.EmotionalChange != "" {
+			scene.StateChanges = append(scene.StateChanges, name+"："+c.EmotionalChange)
+		}
+	}
+	for _, r := range s.RelationshipChanges {
+		scene.StateChanges = append(scene.StateChanges,
+			fmt.Sprintf("%s关系%s", strings.ReplaceAll(r.Relationship, "_", "与"), r.Change))
+	}
+
+	for _, p := range s.Foreshadowing.Plant {
+		line := p.Content
+		if p.Method != "" {
+			line += "（" + p.Method + "）"
+		}
+		scene.Plant = append(scene.Plant, line)
+	}
+	for _, p := range s.Foreshadowing.Payoff {
+		line := p.Reveals
+		if p.Method != "" {
+			line += "（" + p.Method + "）"
+		}
+		scene.Payoff = append(scene.Payoff, line)
+	}
+
+	g := s.WritingGuidance
+	scene.Guidance = append(scene.Guidance, g.Techniques...)
+	scene.Guidance = append(scene.Guidance, g.StyleHints...)
+	if g.DialogueNotes != "" {
+		scene.Guidance = append(scene.Guidance, "对话："+g.DialogueNotes)
+	}
+	if g.NarrativeDistance != "" {
+		scene.Guidance = append(scene.Guidance, "叙事距离："+g.NarrativeDistance)
+	}
+	if len(g.BannedPhrases) > 0 {
+		scene.Guidance = append(scene.Guidance, "禁用表达："+strings.Join(g.BannedPhrases, "、"))
+	}
+	return scene
+}
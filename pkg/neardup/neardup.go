@@ -0,0 +1,195 @@
+// Package neardup 近似重复段落检测
+// 模型有时会在不同章节重复同一段描写。把各章正文按段落切分，用字符三元组的 simhash 快速筛出候选，
+// 再按三元组集合的 Jaccard 相似度确认，找出与其他章节近似重复的段落及其位置。
+// 纯文本比较，不调用模型；改写交给修改建议流程。
+package neardup
+
+import (
+	"hash/fnv"
+	"math"
+	"math/bits"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/annotation"
+)
+
+const (
+	// DefaultThreshold 默认的相似度阈值
+	DefaultThreshold = 0.6
+	// minPassageRunes 参与比较的段落至少的有效字数，过短的段落（对话、短句）重复属正常
+	minPassageRunes = 40
+	// shingleRunes 三元组长度
+	shingleRunes = 3
+	// maxHamming simhash 汉明距离不超过该值的段落才计算相似度
+	maxHamming = 18
+)
+
+// passage 参与比较的段落
+type passage struct {
+	chapter    int
+	start, end int
+	text       string
+	simhash    uint64
+	shingles   map[uint64]struct{}
+}
+
+// Index 各章段落的指纹索引
+type Index struct {
+	passages []passage
+}
+
+// NewIndex 创建空索引
+func NewIndex() *Index {
+	return &Index{}
+}
+
+// Add 把一章正文的段落加入索引
+func (idx *Index) Add(chapter int, content string) {
+	idx.passages = append(idx.passages, split(chapter, content)...)
+}
+
+// Len 索引中的段落数
+func (idx *Index) Len() int {
+	return len(idx.passages)
+}
+
+// Find 找出一章正文中与索引里其他章节近似重复的段落，每个段落只保留最相似的一处，按位置排序
+func (idx *Index) Find(chapter int, content string, threshold float64) []models.DuplicatePassage {
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	matches := make([]models.DuplicatePassage, 0)
+	for _, p := range split(chapter, content) {
+		var best *passage
+		bestSim := 0.0
+		for i := range idx.passages {
+			q := &idx.passages[i]
+			if q.chapter == chapter || bits.OnesCount64(p.simhash^q.simhash) > maxHamming {
+				continue
+			}
+			if sim := jaccard(p.shingles, q.shingles); sim >= threshold && sim > bestSim {
+				best, bestSim = q, sim
+			}
+		}
+		if best == nil {
+			continue
+		}
+		matches = append(matches, models.DuplicatePassage{
+			Start:        p.start,
+			End:          p.end,
+			Text:         p.text,
+			OtherChapter: best.chapter,
+			OtherStart:   best.start,
+			OtherEnd:     best.end,
+			OtherText:    best.text,
+			Similarity:   math.Round(bestSim*100) / 100,
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Start < matches[j].Start })
+	return matches
+}
+
+// split 把正文切成段落并计算指纹，跳过过短的段落
+func split(chapter int, content string) []passage {
+	runes := []rune(content)
+	passages := make([]passage, 0)
+	for _, para := range annotation.Paragraphs(content) {
+		text := string(runes[para.Start:para.End])
+		shingles := shingle(normalize(text))
+		if len(shingles) == 0 {
+			continue
+		}
+		passages = append(passages, passage{
+			chapter:  chapter,
+			start:    para.Start,
+			end:      para.End,
+			text:     text,
+			simhash:  simhash(shingles),
+			shingles: shingles,
+		})
+	}
+	return passages
+}
+
+// normalize 只保留文字和数字，英文转小写
+func normalize(text string) []rune {
+	out := make([]rune, 0, len(text))
+	for _, r := range text {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			out = append(out, unicode.ToLower(r))
+		}
+	}
+	return out
+}
+
+// shingle 字符三元组的哈希集合；有效字数不足时返回空
+func shingle(runes []rune) map[uint64]struct{} {
+	if len(runes) < minPassageRunes {
+		return nil
+	}
+	set := make(map[uint64]struct{}, len(runes))
+	h := fnv.New64a()
+	for i := 0; i+shingleRunes <= len(runes); i++ {
+		h.Reset()
+		h.Write([]byte(string(runes[i : i+shingleRunes])))
+		set[h.Sum64()] = struct{}{}
+	}
+	return set
+}
+
+// simhash 由三元组哈希计算 64 位 simhash
+func simhash(shingles map[uint64]struct{}) uint64 {
+	var weights [64]int
+	for s := range shingles {
+		for b := 0; b < 64; b++ {
+			if s&(1<<uint(b)) != 0 {
+				weights[b]++
+			} else {
+				weights[b]--
+			}
+		}
+	}
+	var hash uint64
+	for b := 0; b < 64; b++ {
+		if weights[b] > 0 {
+			hash |= 1 << uint(b)
+		}
+	}
+	return hash
+}
+
+// jaccard 两个三元组集合的 Jaccard 相似度
+func jaccard(a, b map[uint64]struct{}) float64 {
+	if len(a) > len(b) {
+		a, b = b, a
+	}
+	common := 0
+	for s := range a {
+		if _, ok := b[s]; ok {
+			common++
+		}
+	}
+	union := len(a) + len(b) - common
+	if union == 0 {
+		return 0
+	}
+	return float64(common) / float64(union)
+}
+
+// ScanChapter 把一章与此前各章比较，prose 返回章节正文
+func ScanChapter(chapters []*models.Chapter, target *models.Chapter, prose func(*models.Chapter) string, threshold float64) []models.DuplicatePassage {
+	idx := NewIndex()
+	for _, ch := range chapters {
+		if ch.ChapterNum < target.ChapterNum {
+			idx.Add(ch.ChapterNum, prose(ch))
+		}
+	}
+	content := prose(target)
+	if idx.Len() == 0 || strings.TrimSpace(content) == "" {
+		return []models.DuplicatePassage{}
+	}
+	return idx.Find(target.ChapterNum, content, threshold)
+}
@@ -0,0 +1,283 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/smtp"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+)
+
+func init() {
+	Register(models.NotifyChannelEmail, newEmailSender)
+	Register(models.NotifyChannelFeishu, newFeishuSender)
+	Register(models.NotifyChannelDingTalk, newDingTalkSender)
+	Register(models.NotifyChannelTelegram, newTelegramSender)
+}
+
+// ============================================
+// 邮件
+// ============================================
+
+// emailSender 通过配置文件中的 SMTP 服务器发邮件，渠道参数 to 为收件人（多个用逗号分隔）
+type emailSender struct {
+	smtp config.SMTPConfig
+	to   []string
+}
+
+func newEmailSender(settings map[string]string, cfg config.NotifyConfig) (Sender, error) {
+	if err := require(settings, "to"); err != nil {
+		return nil, err
+	}
+	if cfg.SMTP.Host == "" || cfg.SMTP.From == "" {
+		return nil, fmt.Errorf("服务器未配置 SMTP（notify.smtp.host/from）")
+	}
+	to := make([]string, 0)
+	for _, addr := range strings.Split(settings["to"], ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			to = append(to, addr)
+		}
+	}
+	return &emailSender{smtp: cfg.SMTP, to: to}, nil
+}
+
+func (s *emailSender) Send(ctx context.Context, msg Message) error {
+	port := s.smtp.Port
+	if port == 0 {
+		port = 587
+	}
+	addr := net.JoinHostPort(s.smtp.Host, strconv.Itoa(port))
+
+	var body bytes.Buffer
+	fmt.Fprintf(&body, "From: %s\r\n", s.smtp.From)
+	fmt.Fprintf(&body, "To: %s\r\n", strings.Join(s.to, ", "))
+	fmt.Fprintf(&body, "Subject: =?UTF-8?B?%s?=\r\n", base64.StdEncoding.EncodeToString([]byte(msg.Title)))
+	body.WriteString("MIME-Version: 1.0\r\n")
+	body.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	body.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+	body.WriteString(base64.StdEncoding.EncodeToString([]byte(msg.Text)))
+	body.WriteString("\r\n")
+
+	dialer := &net.Dialer{}
+	var conn net.Conn
+	var err error
+	if port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: s.smtp.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, s.smtp.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && port != 465 {
+		if err := client.StartTLS(&tls.Config{ServerName: s.smtp.Host}); err != nil {
+			return err
+		}
+	}
+	if s.smtp.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.smtp.Username, s.smtp.GetPassword(), s.smtp.Host)); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+	if err := client.Mail(s.smtp.From); err != nil {
+		return err
+	}
+	for _, rcpt := range s.to {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("收件人 %s 被拒绝: %w", rcpt, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(body.Bytes()); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// ============================================
+// 飞书 / 钉钉 群机器人
+// ============================================
+
+// feishuSender 飞书自定义机器人，渠道参数 webhook_url，开启签名校验时填 secret
+type feishuSender struct {
+	url    string
+	secret string
+}
+
+func newFeishuSender(settings map[string]string, _ config.NotifyConfig) (Sender, error) {
+	if err := require(settings, "webhook_url"); err != nil {
+		return nil, err
+	}
+	return &feishuSender{url: settings["webhook_url"], secret: settings["secret"]}, nil
+}
+
+func (s *feishuSender) Send(ctx context.Context, msg Message) error {
+	payload := map[string]interface{}{
+		"msg_type": "text",
+		"content":  map[string]string{"text": msg.Title + "\n" + msg.Text},
+	}
+	if s.secret != "" {
+		// 签名：以 timestamp + "\n" + secret 为密钥对空串做 HmacSHA256
+		timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+		mac := hmac.New(sha256.New, []byte(timestamp+"\n"+s.secret))
+		payload["timestamp"] = timestamp
+		payload["sign"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	}
+
+	var resp struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	}
+	if err := postJSON(ctx, s.url, payload, &resp); err != nil {
+		return err
+	}
+	if resp.Code != 0 {
+		return fmt.Errorf("飞书返回错误 %d: %s", resp.Code, resp.Msg)
+	}
+	return nil
+}
+
+// dingTalkSender 钉钉自定义机器人，渠道参数 webhook_url，开启加签时填 secret
+type dingTalkSender struct {
+	url    string
+	secret string
+}
+
+func newDingTalkSender(settings map[string]string, _ config.NotifyConfig) (Sender, error) {
+	if err := require(settings, "webhook_url"); err != nil {
+		return nil, err
+	}
+	return &dingTalkSender{url: settings["webhook_url"], secret: settings["secret"]}, nil
+}
+
+func (s *dingTalkSender) Send(ctx context.Context, msg Message) error {
+	target := s.url
+	if s.secret != "" {
+		// 加签：以 secret 为密钥对 timestamp + "\n" + secret 做 HmacSHA256，毫秒时间戳
+		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		mac := hmac.New(sha256.New, []byte(s.secret))
+		mac.Write([]byte(timestamp + "\n" + s.secret))
+		sign := url.QueryEscape(base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+		sep := "?"
+		if strings.Contains(target, "?") {
+			sep = "&"
+		}
+		target += sep + "timestamp=" + timestamp + "&sign=" + sign
+	}
+
+	payload := map[string]interface{}{
+		"msgtype": "text",
+		"text":    map[string]string{"content": msg.Title + "\n" + msg.Text},
+	}
+	var resp struct {
+		ErrCode int    `json:"errcode"`
+		ErrMsg  string `json:"errmsg"`
+	}
+	if err := postJSON(ctx, target, payload, &resp); err != nil {
+		return err
+	}
+	if resp.ErrCode != 0 {
+		return fmt.Errorf("钉钉返回错误 %d: %s", resp.ErrCode, resp.ErrMsg)
+	}
+	return nil
+}
+
+// ============================================
+// Telegram
+// ============================================
+
+// telegramSender Telegram Bot，渠道参数 bot_token 和 chat_id
+type telegramSender struct {
+	api    string
+	token  string
+	chatID string
+}
+
+func newTelegramSender(settings map[string]string, cfg config.NotifyConfig) (Sender, error) {
+	if err := require(settings, "bot_token", "chat_id"); err != nil {
+		return nil, err
+	}
+	api := strings.TrimRight(cfg.TelegramAPI, "/")
+	if api == "" {
+		api = "https://api.telegram.org"
+	}
+	return &telegramSender{api: api, token: settings["bot_token"], chatID: settings["chat_id"]}, nil
+}
+
+func (s *telegramSender) Send(ctx context.Context, msg Message) error {
+	payload := map[string]interface{}{
+		"chat_id": s.chatID,
+		"text":    msg.Title + "\n\n" + msg.Text,
+	}
+	var resp struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := postJSON(ctx, s.api+"/bot"+s.token+"/sendMessage", payload, &resp); err != nil {
+		// 错误信息里的地址含 bot token，不能原样记录
+		return fmt.Errorf("请求 Telegram 失败: %s", strings.ReplaceAll(err.Error(), s.token, "***"))
+	}
+	if !resp.OK {
+		return fmt.Errorf("Telegram 返回错误: %s", resp.Description)
+	}
+	return nil
+}
+
+// postJSON 发送 JSON 并解析 JSON 响应
+func postJSON(ctx context.Context, target string, payload, out interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("解析响应失败: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,138 @@
+// Package notify 用户通知 - 可插拔的通知渠道（邮件、飞书、钉钉、Telegram）
+// 长时间的创作任务结束时，按用户勾选的事件推送到其配置的渠道
+package notify
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/logx"
+)
+
+// sendTimeout 单条通知的发送超时
+const sendTimeout = 15 * time.Second
+
+// Message 一条通知
+type Message struct {
+	Event string
+	Title string
+	Text  string
+}
+
+// Sender 通知渠道的发送端
+type Sender interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// Factory 按渠道参数构建发送端，参数缺失时返回错误
+type Factory func(settings map[string]string, cfg config.NotifyConfig) (Sender, error)
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = make(map[string]Factory)
+)
+
+// Register 注册通知渠道类型
+func Register(channelType string, factory Factory) {
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	factories[channelType] = factory
+}
+
+// Types 已注册的渠道类型
+func Types() []string {
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+
+	types := make([]string, 0, len(factories))
+	for t := range factories {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// NewSender 为渠道构建发送端，同时用于校验渠道参数
+func NewSender(ch *models.NotificationChannel) (Sender, error) {
+	factoriesMu.RLock()
+	factory, ok := factories[ch.Type]
+	factoriesMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("不支持的通知渠道: %s", ch.Type)
+	}
+	return factory(ch.Settings, serverConfig())
+}
+
+// serverConfig 服务端通知配置；配置文件不可用时使用零值
+func serverConfig() config.NotifyConfig {
+	cfg, err := config.Current()
+	if err != nil {
+		return config.NotifyConfig{}
+	}
+	return cfg.Notify
+}
+
+// Dispatch 把事件异步推送到用户订阅了该事件的所有渠道，不阻塞调用方
+func Dispatch(database db.Database, userID string, msg Message) {
+	if userID == "" {
+		return
+	}
+	channels, err := database.ListNotificationChannels(userID)
+	if err != nil || len(channels) == 0 {
+		return
+	}
+
+	for i := range channels {
+		ch := &channels[i]
+		if !ch.Enabled || !ch.Subscribes(msg.Event) {
+			continue
+		}
+		go func() {
+			if err := Send(database, ch, msg); err != nil {
+				logx.L().Warn("发送通知失败", "channel", ch.ID, "type", ch.Type, "event", msg.Event, "error", err)
+			}
+		}()
+	}
+}
+
+// Send 同步发送一条通知，并记录渠道的最近发送结果
+func Send(database db.Database, ch *models.NotificationChannel, msg Message) error {
+	sender, err := NewSender(ch)
+	if err == nil {
+		ctx, cancel := context.WithTimeout(context.Background(), sendTimeout)
+		err = sender.Send(ctx, msg)
+		cancel()
+	}
+
+	// 只更新发送结果，避免覆盖发送期间对渠道的修改
+	if latest, loadErr := database.GetNotificationChannel(ch.ID); loadErr == nil {
+		ch = latest
+	}
+	if err != nil {
+		ch.LastError = err.Error()
+	} else {
+		now := time.Now()
+		ch.LastSentAt = &now
+		ch.LastError = ""
+	}
+	if saveErr := database.SaveNotificationChannel(ch); saveErr != nil {
+		logx.L().Warn("保存通知渠道状态失败", "channel", ch.ID, "error", saveErr)
+	}
+	return err
+}
+
+// require 取出必填的渠道参数
+func require(settings map[string]string, keys ...string) error {
+	for _, k := range keys {
+		if settings[k] == "" {
+			return fmt.Errorf("缺少渠道参数 %s", k)
+		}
+	}
+	return nil
+}
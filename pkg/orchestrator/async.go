@@ -4,10 +4,11 @@ package orchestrator
 import (
 	"context"
 	"fmt"
-	"log"
 	"time"
 
 	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/logx"
 	"github.com/xlei/xupu/pkg/scheduler"
 	"github.com/xlei/xupu/pkg/writer"
 )
@@ -44,12 +45,12 @@ func InitScheduler() error {
 
 	// 设置回调
 	globalScheduler.SetTaskCompleteCallback(func(task *scheduler.Task) {
-		log.Printf("[Orchestrator] Task completed: %s", task.ID)
+		logx.WithTrace(task.ID).Info("任务完成")
 		onTaskComplete(task)
 	})
 
 	globalScheduler.SetTaskFailedCallback(func(task *scheduler.Task, err error) {
-		log.Printf("[Orchestrator] Task failed: %s, error: %v", task.ID, err)
+		logx.WithTrace(task.ID).Error("任务失败", "error", err)
 		onTaskFailed(task, err)
 	})
 
@@ -58,7 +59,7 @@ func InitScheduler() error {
 	}
 
 	schedulerOnce.initialized = true
-	log.Println("[Orchestrator] Scheduler initialized")
+	logx.L().Info("调度器已启动")
 	return nil
 }
 
@@ -72,24 +73,18 @@ func StopScheduler() {
 	if globalScheduler != nil {
 		globalScheduler.Stop()
 		schedulerOnce.initialized = false
-		log.Println("[Orchestrator] Scheduler stopped")
+		logx.L().Info("调度器已停止")
 	}
 }
 
 // onTaskComplete 任务完成处理
 func onTaskComplete(task *scheduler.Task) {
-	// 更新项目状态
-	if task.ProjectID != "" {
-		// 这里可以添加额外的完成处理逻辑
-	}
+	notifyTaskOwner(task, nil)
 }
 
 // onTaskFailed 任务失败处理
 func onTaskFailed(task *scheduler.Task, err error) {
-	// 更新项目状态为失败
-	if task.ProjectID != "" {
-		// 这里可以添加额外的失败处理逻辑
-	}
+	notifyTaskOwner(task, err)
 }
 
 // ============================================
@@ -125,8 +120,12 @@ func CreateProjectAsync(params CreationParams, orc *Orchestrator) (*scheduler.Ta
 // executeProjectCreation 执行项目创建
 func executeProjectCreation(ctx context.Context, task *scheduler.Task, orc *Orchestrator) error {
 	params := task.Params.(CreationParams)
+	orc = orc.WithLogger(logx.WithTrace(task.ID)).WithLanguage(params.Language).withUserBudget(params.UserID)
 
-	// 创建项目对象
+	// 创建项目对象，提交时未指定项目ID的在此生成
+	if task.ProjectID == "" {
+		task.ProjectID = db.GenerateID("project")
+	}
 	project := &models.Project{
 		ID:        task.ProjectID,
 		Name:      params.ProjectName,
@@ -135,15 +134,17 @@ func executeProjectCreation(ctx context.Context, task *scheduler.Task, orc *Orch
 		Mode:      models.ModePlanning,
 		Status:    models.StatusBuilding,
 		Progress:  0,
+
+		WordCountTargets: params.WordCountTargets,
+		StyleProfileID:   params.StyleProfileID,
+		Language:         params.Language,
 	}
 
 	// 保存项目
 	if err := orc.db.SaveProject(project); err != nil {
 		return fmt.Errorf("保存项目失败: %w", err)
 	}
-
-	// 更新任务的项目ID
-	task.ProjectID = project.ID
+	orc.registerWebhooks(project.ID, params.Webhooks)
 
 	// 检查取消
 	select {
@@ -153,9 +154,10 @@ func executeProjectCreation(ctx context.Context, task *scheduler.Task, orc *Orch
 	}
 
 	// 执行创作流程
-	result, err := orc.executeCreationFlowAsync(project, params, ctx)
+	result, err := orc.executeCreationFlowAsync(project, params, ctx, task)
 	if err != nil {
 		orc.db.UpdateProjectStatus(project.ID, models.StatusFailed, project.Progress)
+		orc.notify(project.ID, models.WebhookEventProjectFailed, map[string]interface{}{"task_id": task.ID, "error": err.Error()})
 		return fmt.Errorf("执行创作流程失败: %w", err)
 	}
 
@@ -165,6 +167,8 @@ func executeProjectCreation(ctx context.Context, task *scheduler.Task, orc *Orch
 	project.Progress = 100
 	project.Status = models.StatusCompleted
 	orc.db.SaveProject(project)
+	task.SetProgress(100)
+	orc.notify(project.ID, models.WebhookEventProjectCompleted, result)
 
 	// 设置任务结果
 	task.SetResult(result)
@@ -172,15 +176,16 @@ func executeProjectCreation(ctx context.Context, task *scheduler.Task, orc *Orch
 	return nil
 }
 
-// executeCreationFlowAsync 异步执行创作流程
-func (o *Orchestrator) executeCreationFlowAsync(project *models.Project, params CreationParams, ctx context.Context) (*CreationResult, error) {
-	result := &CreationResult{}
+// executeCreationFlowAsync 异步执行创作流程，各阶段的进度同步到任务
+func (o *Orchestrator) executeCreationFlowAsync(project *models.Project, params CreationParams, ctx context.Context, task *scheduler.Task) (*CreationResult, error) {
+	result := &CreationResult{ProjectID: project.ID}
 	progressStep := 100.0 / 3 // 三个阶段
 
 	// 阶段1: 世界设定
-	log.Printf("[编排器] 开始世界设定，项目ID: %s", project.ID)
+	o.log().Info("开始世界设定", "project_id", project.ID)
 	project.Progress = progressStep
 	o.db.SaveProject(project)
+	task.SetProgress(project.Progress)
 
 	select {
 	case <-ctx.Done():
@@ -196,9 +201,10 @@ func (o *Orchestrator) executeCreationFlowAsync(project *models.Project, params
 	project.WorldID = worldID
 
 	// 阶段2: 叙事蓝图
-	log.Printf("[编排器] 开始叙事规划，项目ID: %s", project.ID)
+	o.log().Info("开始叙事规划", "project_id", project.ID)
 	project.Progress = progressStep * 2
 	o.db.SaveProject(project)
+	task.SetProgress(project.Progress)
 
 	select {
 	case <-ctx.Done():
@@ -212,6 +218,7 @@ func (o *Orchestrator) executeCreationFlowAsync(project *models.Project, params
 	}
 	result.NarrativeID = narrativeID
 	project.NarrativeID = narrativeID
+	o.notifyEvolution(project.ID, narrativeID, params)
 
 	// 阶段3: 内容生成（如果需要）
 	if params.Options.GenerateContent {
@@ -224,7 +231,9 @@ func (o *Orchestrator) executeCreationFlowAsync(project *models.Project, params
 		default:
 		}
 
-		sceneCount, wordCount, err := o.stage3_ContentGenerationAsync(narrativeID, params, result, ctx)
+		sceneCount, wordCount, err := o.stage3_ContentGenerationAsync(narrativeID, params, result, ctx, func(done, total int) {
+			task.SetProgress(progressStep * (2 + float64(done)/float64(total)))
+		})
 		if err != nil {
 			return nil, fmt.Errorf("内容生成阶段失败: %w", err)
 		}
@@ -235,8 +244,8 @@ func (o *Orchestrator) executeCreationFlowAsync(project *models.Project, params
 	return result, nil
 }
 
-// stage3_ContentGenerationAsync 异步内容生成
-func (o *Orchestrator) stage3_ContentGenerationAsync(narrativeID string, params CreationParams, result *CreationResult, ctx context.Context) (int, int, error) {
+// stage3_ContentGenerationAsync 异步内容生成，每写完一章回调 onChapter(已完成章数, 总章数)
+func (o *Orchestrator) stage3_ContentGenerationAsync(narrativeID string, params CreationParams, result *CreationResult, ctx context.Context, onChapter func(done, total int)) (int, int, error) {
 	blueprint, err := o.db.GetNarrativeBlueprint(narrativeID)
 	if err != nil {
 		return 0, 0, fmt.Errorf("获取叙事蓝图失败: %w", err)
@@ -256,6 +265,16 @@ func (o *Orchestrator) stage3_ContentGenerationAsync(narrativeID string, params
 	sceneCount := 0
 	totalWordCount := 0
 
+	styleProfile, err := writer.ResolveStyleProfile(o.db, params.StyleProfileID)
+	if err != nil {
+		o.log().Warn("风格档案不可用，使用默认风格", "error", err)
+	}
+
+	o.indexMemories(result.ProjectID, blueprint, world)
+	voices := o.voiceProfiles(blueprint, world)
+	characters := o.characterStates(blueprint, world)
+	project, _ := o.db.GetProject(result.ProjectID)
+
 	for i := startChapter - 1; i < endChapter; i++ {
 		select {
 		case <-ctx.Done():
@@ -264,30 +283,62 @@ func (o *Orchestrator) stage3_ContentGenerationAsync(narrativeID string, params
 		}
 
 		chapter := blueprint.ChapterPlans[i]
-		log.Printf("[编排器] 生成第%d章: %s", chapter.Chapter, chapter.Title)
+		o.log().Info("生成章节", "chapter", chapter.Chapter, "title", chapter.Title)
 
 		chapterScenes := getScenesForChapter(blueprint.Scenes, chapter.Chapter)
+		sceneTargets := sceneWordTargets(params.WordCountTargets.For(chapter.Chapter, chapter.WordCount), chapterScenes)
+		previousSummary := o.previousContext(result.ProjectID, chapter.Chapter)
+		sceneCtx := BuildSceneContext(o.db, project, chapter.Chapter)
+
+		for j, sceneInstr := range chapterScenes {
+			if v := o.enforcePOV(blueprint, &sceneInstr); v != nil {
+				result.POVViolations = append(result.POVViolations, *v)
+			}
+			result.VitalViolations = append(result.VitalViolations, o.guardDeceased(result.ProjectID, &sceneInstr)...)
+			o.choreograph(result.ProjectID, blueprint, world, &chapter, &sceneInstr)
 
-		for _, sceneInstr := range chapterScenes {
-			sceneResult, err := o.writer.GenerateScene(writer.GenerateParams{
+			sceneParams := writer.GenerateParams{
 				BlueprintID:      blueprint.ID,
 				Chapter:          sceneInstr.Chapter,
 				Scene:            sceneInstr.Scene,
 				Instruction:      &sceneInstr,
-				PreviousSummary:  buildPreviousSummary(blueprint.ChapterPlans[:i]),
-				CharacterStates:  buildCharacterStates(blueprint, world),
+				PreviousSummary:  previousSummary,
+				Memories:         o.recallMemories(blueprint, &sceneInstr),
+				CharacterStates:  characters,
 				WorldContext:     world,
 				Style:            writer.DefaultStyle(),
-			})
+				TargetWordCount:  sceneTargets[j],
+				StyleProfile:     styleProfile,
+				VoiceProfiles:    voices,
+				POVConstraint:    blueprint.POVPolicy.Constraint(sceneInstr.Chapter),
+				Checklist:        o.sceneChecklist(result.ProjectID, blueprint, &sceneInstr),
+				MaxRevisions:     params.Options.MaxRevisions,
+			}
+			sceneCtx.Apply(&sceneParams)
+			sceneResult, err := o.writer.GenerateScene(sceneParams)
 
 			if err != nil {
-				log.Printf("[编排器] 警告: 场景%d-%d生成失败: %v", sceneInstr.Chapter, sceneInstr.Scene, err)
+				o.log().Warn("场景生成失败", "chapter", sceneInstr.Chapter, "scene", sceneInstr.Scene, "error", err)
 				continue
 			}
 
 			sceneCount++
 			totalWordCount += sceneResult.WordCount
+			if n := len(sceneResult.Metadata.Violations); n > 0 {
+				o.log().Warn("场景修订后仍有违规", "chapter", sceneInstr.Chapter, "scene", sceneInstr.Scene, "revisions", sceneResult.Metadata.Revisions, "violations", n)
+			}
 		}
+
+		// 本章摘要作为下一章的前情
+		o.summarizeChapter(result.ProjectID, blueprint, chapter)
+		o.checkChapterVoice(result.ProjectID, blueprint, chapter, voices)
+		o.trackMentions(result.ProjectID, blueprint, chapter)
+		o.checkContinuity(result.ProjectID, blueprint, chapter)
+		o.analyzeTheme(result.ProjectID, blueprint, chapter)
+		o.measureStyle(result.ProjectID, blueprint, chapter)
+		o.checkDuplicates(result.ProjectID, blueprint, chapter)
+		o.notifyChapter(result.ProjectID, chapter)
+		onChapter(i-startChapter+2, endChapter-startChapter+1)
 	}
 
 	return sceneCount, totalWordCount, nil
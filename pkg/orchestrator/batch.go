@@ -0,0 +1,244 @@
+// Package orchestrator 编排器 - 批量创作
+package orchestrator
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/scheduler"
+)
+
+// 批量创作的整体状态
+const (
+	BatchPending   = "pending"   // 所有项都在排队
+	BatchRunning   = "running"   // 有项在执行或排队
+	BatchCompleted = "completed" // 全部成功
+	BatchPartial   = "partial"   // 已结束，部分成功
+	BatchFailed    = "failed"    // 已结束，没有一项成功
+	BatchCancelled = "cancelled" // 已结束，全部取消
+)
+
+// BatchItem 批量创作中的一项
+type BatchItem struct {
+	Params   CreationParams
+	Priority scheduler.TaskPriority // 0 使用 PriorityNormal
+}
+
+// Batch 一次批量创作：每项是调度器中的一个项目创建任务，用量分项计量
+type Batch struct {
+	ID        string
+	UserID    string
+	CreatedAt time.Time
+	entries   []*batchEntry
+	notified  sync.Once // 整批结束的通知只发一次
+}
+
+// batchEntry 批量创作中一项的任务和用量
+type batchEntry struct {
+	name  string
+	task  *scheduler.Task
+	meter *llm.Meter
+}
+
+// BatchItemStatus 批量创作中一项的状态
+type BatchItemStatus struct {
+	Index       int        `json:"index"`
+	Name        string     `json:"name"`
+	TaskID      string     `json:"task_id"`
+	ProjectID   string     `json:"project_id"`
+	Priority    int        `json:"priority"`
+	Status      string     `json:"status"`
+	Progress    float64    `json:"progress"`
+	Error       string     `json:"error,omitempty"`
+	StartedAt   *time.Time `json:"started_at,omitempty"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+	Usage       llm.Usage  `json:"usage"`
+}
+
+// BatchStatus 批量创作的整体状态、分项进度和汇总用量
+type BatchStatus struct {
+	ID        string            `json:"id"`
+	Status    string            `json:"status"`
+	Progress  float64           `json:"progress"` // 各项进度的平均值
+	Counts    map[string]int    `json:"counts"`   // 按任务状态计数
+	Usage     llm.Usage         `json:"usage"`    // 所有项的用量合计
+	CreatedAt time.Time         `json:"created_at"`
+	Items     []BatchItemStatus `json:"items"`
+}
+
+var (
+	batchesMu sync.RWMutex
+	batches   = make(map[string]*Batch)
+)
+
+// SubmitBatch 把每项作为项目创建任务按各自优先级提交到全局调度器
+// 项目ID在提交时分配，便于立即查询；任一项提交失败时撤销已提交的项
+func SubmitBatch(userID string, items []BatchItem, orc *Orchestrator) (*Batch, error) {
+	if globalScheduler == nil {
+		return nil, fmt.Errorf("调度器未初始化")
+	}
+	if len(items) == 0 {
+		return nil, fmt.Errorf("批量创作至少需要一项")
+	}
+
+	batch := &Batch{
+		ID:        db.GenerateID("batch"),
+		UserID:    userID,
+		CreatedAt: time.Now(),
+	}
+	for _, item := range items {
+		params := item.Params
+		params.UserID = userID
+		priority := item.Priority
+		if priority == 0 {
+			priority = scheduler.PriorityNormal
+		}
+
+		meter := llm.NewMeter()
+		metered := orc.WithMeter(meter)
+		task := scheduler.NewJob(
+			scheduler.TaskTypeWorldBuild,
+			db.GenerateID("project"),
+			params,
+			func(ctx context.Context, t *scheduler.Task) error {
+				return executeProjectCreation(ctx, t, metered)
+			},
+		).SetPriority(priority).
+			SetScheduler(globalScheduler).
+			Build()
+
+		if err := globalScheduler.Submit(task); err != nil {
+			batch.Cancel()
+			return nil, fmt.Errorf("提交第%d项失败: %w", len(batch.entries)+1, err)
+		}
+		batch.entries = append(batch.entries, &batchEntry{name: params.ProjectName, task: task, meter: meter})
+	}
+
+	batchesMu.Lock()
+	batches[batch.ID] = batch
+	batchesMu.Unlock()
+	return batch, nil
+}
+
+// GetBatch 获取批量创作
+func GetBatch(id string) (*Batch, error) {
+	batchesMu.RLock()
+	defer batchesMu.RUnlock()
+
+	batch, ok := batches[id]
+	if !ok {
+		return nil, fmt.Errorf("批量创作不存在")
+	}
+	return batch, nil
+}
+
+// ListBatches 获取用户的所有批量创作，新的在前
+func ListBatches(userID string) []*Batch {
+	batchesMu.RLock()
+	defer batchesMu.RUnlock()
+
+	list := make([]*Batch, 0)
+	for _, batch := range batches {
+		if batch.UserID == userID {
+			list = append(list, batch)
+		}
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].CreatedAt.After(list[j].CreatedAt) })
+	return list
+}
+
+// batchOfTask 查找任务所属的批量创作
+func batchOfTask(taskID string) *Batch {
+	batchesMu.RLock()
+	defer batchesMu.RUnlock()
+
+	for _, batch := range batches {
+		for _, e := range batch.entries {
+			if e.task.ID == taskID {
+				return batch
+			}
+		}
+	}
+	return nil
+}
+
+// finish 整批已结束且尚未报告时返回汇总状态，整批只报告一次
+func (b *Batch) finish() (*BatchStatus, bool) {
+	status := b.Status()
+	switch status.Status {
+	case BatchPending, BatchRunning:
+		return nil, false
+	}
+	first := false
+	b.notified.Do(func() { first = true })
+	return status, first
+}
+
+// Cancel 取消所有尚未结束的项
+func (b *Batch) Cancel() {
+	for _, e := range b.entries {
+		switch e.task.GetStatus() {
+		case scheduler.StatusPending, scheduler.StatusRunning:
+			globalScheduler.CancelTask(e.task.ID)
+		}
+	}
+}
+
+// Status 汇总各项的任务状态和用量
+func (b *Batch) Status() *BatchStatus {
+	status := &BatchStatus{
+		ID:        b.ID,
+		Counts:    make(map[string]int),
+		CreatedAt: b.CreatedAt,
+		Items:     make([]BatchItemStatus, 0, len(b.entries)),
+	}
+
+	for i, e := range b.entries {
+		item := BatchItemStatus{
+			Index:       i,
+			Name:        e.name,
+			TaskID:      e.task.ID,
+			ProjectID:   e.task.ProjectID,
+			Priority:    int(e.task.Priority),
+			Status:      string(e.task.GetStatus()),
+			Progress:    e.task.GetProgress(),
+			Error:       e.task.Error,
+			StartedAt:   e.task.StartedAt,
+			CompletedAt: e.task.CompletedAt,
+			Usage:       e.meter.Usage(),
+		}
+		status.Items = append(status.Items, item)
+		status.Counts[item.Status]++
+		status.Usage.Add(item.Usage)
+		status.Progress += item.Progress
+	}
+	if n := len(b.entries); n > 0 {
+		status.Progress /= float64(n)
+	}
+	status.Status = batchState(status.Counts, len(b.entries))
+	return status
+}
+
+// batchState 由各状态的项数推出整体状态
+func batchState(counts map[string]int, total int) string {
+	pending := counts[string(scheduler.StatusPending)]
+	active := pending + counts[string(scheduler.StatusRunning)]
+	switch {
+	case pending == total:
+		return BatchPending
+	case active > 0:
+		return BatchRunning
+	case counts[string(scheduler.StatusCompleted)] == total:
+		return BatchCompleted
+	case counts[string(scheduler.StatusCancelled)] == total:
+		return BatchCancelled
+	case counts[string(scheduler.StatusCompleted)] > 0:
+		return BatchPartial
+	}
+	return BatchFailed
+}
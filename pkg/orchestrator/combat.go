@@ -0,0 +1,40 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// choreograph 为动作场景生成战斗分解，写入场景指令并保存到蓝图
+// 已有分解的场景沿用原分解；失败只记录日志，场景照常生成
+func (o *Orchestrator) choreograph(projectID string, blueprint *models.NarrativeBlueprint, world *models.WorldSetting, plan *models.ChapterPlan, instr *models.SceneInstruction) {
+	if instr.Choreography != nil || !writer.IsActionScene(plan, instr) {
+		return
+	}
+	choreography, err := o.writer.PlanCombat(writer.CombatParams{
+		Instruction: instr,
+		World:       world,
+		Realms:      o.realmStates(projectID, world, instr.Chapter),
+		Vitals:      o.vitalStates(projectID, instr.Chapter),
+		Items:       o.itemHoldings(projectID, instr.Chapter),
+	})
+	if err != nil {
+		o.log().Warn("战斗分解失败", "chapter", instr.Chapter, "scene", instr.Scene, "error", err)
+		return
+	}
+	if len(choreography.Beats) == 0 {
+		return
+	}
+	instr.Choreography = choreography
+
+	for i := range blueprint.Scenes {
+		if blueprint.Scenes[i].Chapter == instr.Chapter && blueprint.Scenes[i].Scene == instr.Scene {
+			blueprint.Scenes[i].Choreography = choreography
+			if err := o.db.SaveNarrativeBlueprint(blueprint); err != nil {
+				o.log().Warn("保存战斗分解失败", "chapter", instr.Chapter, "scene", instr.Scene, "error", err)
+			}
+			break
+		}
+	}
+	o.log().Info("战斗分解完成", "chapter", instr.Chapter, "scene", instr.Scene, "beats", len(choreography.Beats))
+}
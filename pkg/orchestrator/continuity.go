@@ -0,0 +1,37 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/continuity"
+)
+
+// checkContinuity 提取本章确立的细节并检查与此前细节的矛盾，矛盾写入章节记录
+// 失败只记录日志，不中断生成
+func (o *Orchestrator) checkContinuity(projectID string, blueprint *models.NarrativeBlueprint, plan models.ChapterPlan) {
+	if projectID == "" {
+		return
+	}
+	prose := o.chapterProse(blueprint.ID, plan.Chapter)
+	if prose == "" {
+		return
+	}
+	chapter, err := o.db.GetChapterByNum(projectID, plan.Chapter)
+	if err != nil || chapter == nil {
+		return
+	}
+
+	outcome, err := continuity.CheckChapter(o.db, o.writer, chapter, prose)
+	if err != nil {
+		o.log().Warn("细节连续性检查失败", "chapter", plan.Chapter, "error", err)
+		return
+	}
+	chapter.ContinuityIssues = outcome.Issues
+	if err := o.db.SaveChapter(chapter); err != nil {
+		o.log().Warn("保存连续性检查结果失败", "chapter", plan.Chapter, "error", err)
+		return
+	}
+	if n := len(outcome.Issues); n > 0 {
+		o.log().Warn("本章与已确立的细节矛盾", "chapter", plan.Chapter, "issues", n)
+		o.notifyContinuity(chapter)
+	}
+}
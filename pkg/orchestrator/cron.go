@@ -0,0 +1,486 @@
+// Package orchestrator 编排器 - 定时任务
+package orchestrator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/audit"
+	"github.com/xlei/xupu/pkg/continuity"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/logx"
+	"github.com/xlei/xupu/pkg/release"
+	"github.com/xlei/xupu/pkg/scheduler"
+)
+
+// cronTickInterval 检查到期定时任务的间隔；cron 表达式精确到分钟，半分钟检查一次足够
+const cronTickInterval = 30 * time.Second
+
+// CronHandler 定时任务的执行函数，返回执行结果摘要
+type CronHandler func(ctx context.Context, job *models.CronJob) (string, error)
+
+// CronKind 一种可调度的任务类型
+type CronKind struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Params      string `json:"params"` // 参数说明
+	run         CronHandler
+}
+
+var (
+	cronKindsMu sync.RWMutex
+	cronKinds   = make(map[string]*CronKind)
+
+	// cronActive 正在执行的定时任务，同一任务上一次未结束时跳过本次
+	cronActiveMu sync.Mutex
+	cronActive   = make(map[string]bool)
+
+	cronStop chan struct{}
+	cronDone chan struct{}
+)
+
+func init() {
+	RegisterCronKind("continuity_check", "重新检查项目章节的细节连续性，适合在手动修改正文后定期复查",
+		`{"project_id": "项目ID", "chapters": "只检查最近N章，0为全部"}`, runContinuityCheck)
+	RegisterCronKind("publish_due", "把定时时间已到的连载更新标记为已发布",
+		`{"project_id": "项目ID，为空时处理所有项目"}`, runPublishDue)
+	RegisterCronKind("purge_trash", "彻底删除在回收站中超过保留期的项目、世界、蓝图和章节",
+		`{"days": "保留天数，默认30"}`, runPurgeTrash)
+}
+
+// RegisterCronKind 注册任务类型；依赖外部组件的类型（如排行榜快照）由启动程序注册
+func RegisterCronKind(name, description, params string, run CronHandler) {
+	cronKindsMu.Lock()
+	defer cronKindsMu.Unlock()
+	cronKinds[name] = &CronKind{Name: name, Description: description, Params: params, run: run}
+}
+
+// CronKinds 已注册的任务类型，按名称排序
+func CronKinds() []CronKind {
+	cronKindsMu.RLock()
+	defer cronKindsMu.RUnlock()
+
+	kinds := make([]CronKind, 0, len(cronKinds))
+	for _, k := range cronKinds {
+		kinds = append(kinds, *k)
+	}
+	sort.Slice(kinds, func(i, j int) bool { return kinds[i].Name < kinds[j].Name })
+	return kinds
+}
+
+func lookupCronKind(name string) (*CronKind, bool) {
+	cronKindsMu.RLock()
+	defer cronKindsMu.RUnlock()
+	k, ok := cronKinds[name]
+	return k, ok
+}
+
+// ParseCronJob 按任务的时区解析 cron 表达式
+func ParseCronJob(job *models.CronJob) (*scheduler.CronExpr, error) {
+	loc := time.Local
+	if job.Timezone != "" {
+		var err error
+		if loc, err = time.LoadLocation(job.Timezone); err != nil {
+			return nil, fmt.Errorf("无效的时区 %q: %w", job.Timezone, err)
+		}
+	}
+	return scheduler.ParseCron(job.Spec, loc)
+}
+
+// PrepareCronJob 校验任务类型、表达式和时区，并按启用状态重新计算下次执行时间
+func PrepareCronJob(job *models.CronJob, now time.Time) error {
+	if _, ok := lookupCronKind(job.Kind); !ok {
+		return fmt.Errorf("未知的任务类型: %s", job.Kind)
+	}
+	if len(job.Params) > 0 && !json.Valid(job.Params) {
+		return fmt.Errorf("任务参数不是合法的 JSON")
+	}
+	expr, err := ParseCronJob(job)
+	if err != nil {
+		return err
+	}
+
+	job.NextRunAt = nil
+	if job.Enabled {
+		next := expr.Next(now)
+		if next.IsZero() {
+			return fmt.Errorf("cron 表达式 %q 在五年内不会触发", job.Spec)
+		}
+		job.NextRunAt = &next
+	}
+	return nil
+}
+
+// StartCron 启动定时任务循环，到期的任务提交到全局调度器执行
+// 只应由 API 服务调用；CLI 也会初始化调度器，但不应执行定时任务
+func StartCron(database db.Database) error {
+	if globalScheduler == nil {
+		return fmt.Errorf("调度器未初始化")
+	}
+	if cronStop != nil {
+		return nil
+	}
+	jobs, err := database.ListCronJobs()
+	if err != nil {
+		return fmt.Errorf("加载定时任务失败: %w", err)
+	}
+	ensureDefaultCronJobs(database, jobs, time.Now())
+
+	cronStop = make(chan struct{})
+	cronDone = make(chan struct{})
+	go func(stop, done chan struct{}) {
+		defer close(done)
+		ticker := time.NewTicker(cronTickInterval)
+		defer ticker.Stop()
+		for {
+			runDueCronJobs(database, time.Now())
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+			}
+		}
+	}(cronStop, cronDone)
+
+	logx.L().Info("定时任务已启动")
+	return nil
+}
+
+// defaultCronJobs 服务启动时确保存在的系统任务；同类型的任务已存在（包括已停用的）时不再创建
+var defaultCronJobs = []models.CronJob{
+	{Name: "清理回收站", Kind: "purge_trash", Spec: "30 3 * * *", Enabled: true},
+}
+
+func ensureDefaultCronJobs(database db.Database, jobs []models.CronJob, now time.Time) {
+	existing := make(map[string]bool, len(jobs))
+	for _, job := range jobs {
+		existing[job.Kind] = true
+	}
+	for _, def := range defaultCronJobs {
+		if existing[def.Kind] {
+			continue
+		}
+		job := def
+		job.ID = db.GenerateID("cron")
+		job.CreatedBy = models.AuditActorSystem
+		if err := PrepareCronJob(&job, now); err != nil {
+			logx.L().Warn("创建默认定时任务失败", "kind", job.Kind, "error", err)
+			continue
+		}
+		if err := database.SaveCronJob(&job); err != nil {
+			logx.L().Warn("创建默认定时任务失败", "kind", job.Kind, "error", err)
+		}
+	}
+}
+
+// StopCron 停止定时任务循环，已提交的执行由调度器继续完成
+func StopCron() {
+	if cronStop == nil {
+		return
+	}
+	close(cronStop)
+	<-cronDone
+	cronStop, cronDone = nil, nil
+	logx.L().Info("定时任务已停止")
+}
+
+// runDueCronJobs 执行所有到期的任务
+// 服务停机期间错过的多次触发只补执行一次，下次执行时间从当前时间起算
+func runDueCronJobs(database db.Database, now time.Time) {
+	jobs, err := database.ListCronJobs()
+	if err != nil {
+		logx.L().Warn("加载定时任务失败", "error", err)
+		return
+	}
+
+	for i := range jobs {
+		job := &jobs[i]
+		if !job.Enabled || job.NextRunAt == nil || job.NextRunAt.After(now) {
+			continue
+		}
+
+		expr, err := ParseCronJob(job)
+		if err != nil {
+			logx.L().Warn("定时任务表达式无效，已停用", "job", job.ID, "error", err)
+			job.Enabled = false
+			job.NextRunAt = nil
+		} else {
+			next := expr.Next(now)
+			job.NextRunAt = &next
+			if next.IsZero() {
+				job.NextRunAt = nil
+			}
+		}
+		if err := database.SaveCronJob(job); err != nil {
+			logx.L().Warn("更新定时任务失败", "job", job.ID, "error", err)
+			continue
+		}
+		if !job.Enabled {
+			continue
+		}
+
+		if _, err := dispatchCronJob(database, job, models.CronTriggerSchedule); err != nil {
+			logx.L().Warn("提交定时任务失败", "job", job.ID, "error", err)
+		}
+	}
+}
+
+// RunCronJob 立即执行一次定时任务，不影响下次执行时间
+func RunCronJob(database db.Database, id string) (*models.CronRun, error) {
+	if globalScheduler == nil {
+		return nil, fmt.Errorf("调度器未初始化")
+	}
+	job, err := database.GetCronJob(id)
+	if err != nil {
+		return nil, err
+	}
+	return dispatchCronJob(database, job, models.CronTriggerManual)
+}
+
+// dispatchCronJob 记录一次执行并提交到调度器；上一次执行尚未结束时记录为跳过
+func dispatchCronJob(database db.Database, job *models.CronJob, trigger string) (*models.CronRun, error) {
+	run := &models.CronRun{
+		ID:        db.GenerateID("cronrun"),
+		JobID:     job.ID,
+		Kind:      job.Kind,
+		Trigger:   trigger,
+		Status:    models.CronRunRunning,
+		StartedAt: time.Now(),
+	}
+
+	kind, ok := lookupCronKind(job.Kind)
+	if !ok {
+		finishCronRun(database, run, "", fmt.Errorf("未知的任务类型: %s", job.Kind))
+		return run, nil
+	}
+
+	cronActiveMu.Lock()
+	if cronActive[job.ID] {
+		cronActiveMu.Unlock()
+		run.Status = models.CronRunSkipped
+		run.FinishedAt = &run.StartedAt
+		run.Output = "上一次执行尚未结束"
+		if err := database.SaveCronRun(run); err != nil {
+			return nil, err
+		}
+		return run, nil
+	}
+	cronActive[job.ID] = true
+	cronActiveMu.Unlock()
+
+	snapshot := *job
+	task := scheduler.NewJob(
+		scheduler.TaskTypeCron,
+		"",
+		&snapshot,
+		func(ctx context.Context, t *scheduler.Task) error {
+			output, err := kind.run(ctx, &snapshot)
+			finishCronRun(database, run, output, err)
+			t.SetResult(output)
+			return err
+		},
+	).SetPriority(scheduler.PriorityLow).
+		SetScheduler(globalScheduler).
+		Build()
+
+	run.TaskID = task.ID
+	if err := database.SaveCronRun(run); err != nil {
+		releaseCronJob(job.ID)
+		return nil, err
+	}
+	// 提交后 run 由执行任务的 goroutine 更新，返回提交时的副本
+	submitted := *run
+	if err := globalScheduler.Submit(task); err != nil {
+		finishCronRun(database, run, "", fmt.Errorf("提交到调度器失败: %w", err))
+		return run, nil
+	}
+
+	logx.WithTrace(task.ID).Info("定时任务开始执行", "job", job.ID, "kind", job.Kind, "trigger", trigger)
+	return &submitted, nil
+}
+
+// finishCronRun 保存执行结果，并更新任务的最近执行状态
+func finishCronRun(database db.Database, run *models.CronRun, output string, runErr error) {
+	defer releaseCronJob(run.JobID)
+
+	finishedAt := time.Now()
+	run.FinishedAt = &finishedAt
+	run.Output = output
+	run.Status = models.CronRunSucceeded
+	if runErr != nil {
+		run.Status = models.CronRunFailed
+		run.Error = runErr.Error()
+	}
+	if err := database.SaveCronRun(run); err != nil {
+		logx.L().Warn("保存定时任务执行记录失败", "run", run.ID, "error", err)
+	}
+
+	// 执行期间任务可能被修改，重新加载后只更新执行状态
+	job, err := database.GetCronJob(run.JobID)
+	if err != nil {
+		return
+	}
+	job.LastRunAt = &run.StartedAt
+	job.LastStatus = run.Status
+	if err := database.SaveCronJob(job); err != nil {
+		logx.L().Warn("更新定时任务状态失败", "job", job.ID, "error", err)
+	}
+}
+
+func releaseCronJob(id string) {
+	cronActiveMu.Lock()
+	delete(cronActive, id)
+	cronActiveMu.Unlock()
+}
+
+// DecodeCronParams 解析任务参数，参数为空时保持 v 的默认值
+func DecodeCronParams(job *models.CronJob, v interface{}) error {
+	if len(job.Params) == 0 || string(job.Params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(job.Params, v); err != nil {
+		return fmt.Errorf("任务参数无效: %w", err)
+	}
+	return nil
+}
+
+// ============================================
+// 内置任务类型
+// ============================================
+
+// runContinuityCheck 重新检查项目已写章节的细节连续性，优先使用章节正文（可能已被手动修改）
+func runContinuityCheck(ctx context.Context, job *models.CronJob) (string, error) {
+	var params struct {
+		ProjectID string `json:"project_id"`
+		Chapters  int    `json:"chapters"`
+	}
+	if err := DecodeCronParams(job, &params); err != nil {
+		return "", err
+	}
+	if params.ProjectID == "" {
+		return "", fmt.Errorf("缺少参数 project_id")
+	}
+
+	o, err := New()
+	if err != nil {
+		return "", err
+	}
+	project, err := o.db.GetProject(params.ProjectID)
+	if err != nil {
+		return "", fmt.Errorf("项目不存在: %s", params.ProjectID)
+	}
+	o = o.WithLanguage(project.Language)
+
+	chapters := o.db.ListChaptersByProject(project.ID)
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].ChapterNum < chapters[j].ChapterNum })
+	if params.Chapters > 0 && len(chapters) > params.Chapters {
+		chapters = chapters[len(chapters)-params.Chapters:]
+	}
+
+	checked, issues := 0, 0
+	for _, chapter := range chapters {
+		if err := ctx.Err(); err != nil {
+			return fmt.Sprintf("已检查 %d 章，发现 %d 处矛盾（已取消）", checked, issues), err
+		}
+		prose := chapter.Content
+		if strings.TrimSpace(prose) == "" {
+			prose = o.chapterProse(project.NarrativeID, chapter.ChapterNum)
+		}
+		if strings.TrimSpace(prose) == "" {
+			continue
+		}
+
+		outcome, err := continuity.CheckChapter(o.db, o.writer, chapter, prose)
+		if err != nil {
+			return fmt.Sprintf("已检查 %d 章，发现 %d 处矛盾", checked, issues), fmt.Errorf("第%d章检查失败: %w", chapter.ChapterNum, err)
+		}
+		chapter.ContinuityIssues = outcome.Issues
+		if err := o.db.SaveChapter(chapter); err != nil {
+			return "", fmt.Errorf("保存第%d章检查结果失败: %w", chapter.ChapterNum, err)
+		}
+		o.notifyContinuity(chapter)
+		checked++
+		issues += len(outcome.Issues)
+	}
+	return fmt.Sprintf("已检查 %d 章，发现 %d 处矛盾", checked, issues), nil
+}
+
+// runPublishDue 把定时时间已到的更新标记为已发布；合规检查在设置定时时已经完成
+func runPublishDue(ctx context.Context, job *models.CronJob) (string, error) {
+	var params struct {
+		ProjectID string `json:"project_id"`
+	}
+	if err := DecodeCronParams(job, &params); err != nil {
+		return "", err
+	}
+
+	database := db.Get()
+	projectIDs := []string{params.ProjectID}
+	if params.ProjectID == "" {
+		projectIDs = projectIDs[:0]
+		for _, p := range database.ListProjects() {
+			projectIDs = append(projectIDs, p.ID)
+		}
+	}
+
+	now := time.Now()
+	total, projects := 0, 0
+	for _, projectID := range projectIDs {
+		if err := ctx.Err(); err != nil {
+			return fmt.Sprintf("已发布 %d 次更新", total), err
+		}
+		plan, err := database.GetReleasePlan(projectID)
+		if err != nil {
+			if params.ProjectID != "" {
+				return "", fmt.Errorf("项目 %s 没有更新计划", projectID)
+			}
+			continue
+		}
+		published := release.PublishDue(plan, now)
+		if len(published) == 0 {
+			continue
+		}
+		if err := database.SaveReleasePlan(plan); err != nil {
+			return fmt.Sprintf("已发布 %d 次更新", total), fmt.Errorf("保存项目 %s 的更新计划失败: %w", projectID, err)
+		}
+		audit.Record(database, &models.AuditLog{
+			UserID:       models.AuditActorSystem,
+			Action:       models.AuditPublish,
+			ResourceType: models.AuditResourceRelease,
+			ResourceID:   plan.ID,
+			ProjectID:    projectID,
+			Summary:      fmt.Sprintf("定时任务 %s 发布到期更新", job.Name),
+			After:        fmt.Sprintf("已发布更新 %v", published),
+		})
+		total += len(published)
+		projects++
+	}
+	return fmt.Sprintf("%d 个项目共发布 %d 次更新", projects, total), nil
+}
+
+// runPurgeTrash 彻底删除回收站中超过保留期的内容
+func runPurgeTrash(ctx context.Context, job *models.CronJob) (string, error) {
+	var params struct {
+		Days int `json:"days"`
+	}
+	if err := DecodeCronParams(job, &params); err != nil {
+		return "", err
+	}
+	retention := models.TrashRetention
+	if params.Days > 0 {
+		retention = time.Duration(params.Days) * 24 * time.Hour
+	}
+
+	counts, err := db.Get().PurgeTrash(time.Now().Add(-retention))
+	if err != nil {
+		return "", fmt.Errorf("清理回收站失败: %w", err)
+	}
+	return fmt.Sprintf("清除项目 %d 个、世界 %d 个、蓝图 %d 个、章节 %d 章",
+		counts[models.TrashProject], counts[models.TrashWorld], counts[models.TrashBlueprint], counts[models.TrashChapter]), nil
+}
@@ -0,0 +1,47 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/cultivation"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// realmStates 第 chapter 章开始时各角色的修为境界，世界没有修真体系时返回空
+func (o *Orchestrator) realmStates(projectID string, world *models.WorldSetting, chapter int) []models.RealmState {
+	return cultivation.StatesAt(o.db, projectID, world, chapter)
+}
+
+// worldCultivation 蓝图所属世界的修真体系，没有时返回 nil
+func (o *Orchestrator) worldCultivation(blueprint *models.NarrativeBlueprint) *models.CultivationSystem {
+	world, err := o.db.GetWorld(blueprint.WorldID)
+	if err != nil {
+		return nil
+	}
+	return world.Cultivation()
+}
+
+// recordRealms 用本章摘要中的境界变化更新记录，并记录不合理的突破，失败只记录日志
+func (o *Orchestrator) recordRealms(projectID string, system *models.CultivationSystem, chapter int, changes []writer.RealmChange) {
+	if projectID == "" || system == nil {
+		return
+	}
+	events := make([]models.RealmEvent, 0, len(changes))
+	for _, c := range changes {
+		events = append(events, models.RealmEvent{
+			Character: c.Character,
+			Kind:      c.Kind,
+			Realm:     c.Realm,
+			Cost:      c.Cost,
+			Evidence:  c.Evidence,
+		})
+	}
+	if err := cultivation.RecordChapter(o.db, projectID, chapter, events); err != nil {
+		o.log().Warn("更新修为境界失败", "chapter", chapter, "error", err)
+		return
+	}
+	for _, v := range cultivation.Load(o.db, projectID, system.Realms).Validate(system.Bottleneck) {
+		if v.Chapter == chapter {
+			o.log().Warn("境界变化不合理", "chapter", chapter, "character", v.Character, "kind", v.Kind, "detail", v.Detail)
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package orchestrator
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// TestCreateProject_DryRun 演练模式下跑通完整创作流程（世界构建、叙事演化、内容生成），不调用真实API
+func TestCreateProject_DryRun(t *testing.T) {
+	t.Chdir("../..")
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "dryrun.db"))
+	llm.SetDryRun(true)
+	defer llm.SetDryRun(false)
+
+	orc, err := New()
+	if err != nil {
+		t.Fatalf("初始化编排器失败: %v", err)
+	}
+
+	project, err := orc.CreateProject(CreationParams{
+		ProjectName:  "演练项目",
+		WorldType:    "fantasy",
+		WorldScale:   "continent",
+		StoryType:    "adventure",
+		StoryLength:  "short",
+		ChapterCount: 2,
+		Structure:    "three_act",
+		Options: GenerationOptions{
+			GenerateContent: true,
+			StartChapter:    1,
+		},
+	})
+	if err != nil {
+		t.Fatalf("创建项目失败: %v", err)
+	}
+	if project.Status != models.StatusCompleted {
+		t.Errorf("项目状态 = %s, 期望 %s", project.Status, models.StatusCompleted)
+	}
+
+	if chapters := orc.db.ListChaptersByProject(project.ID); len(chapters) == 0 {
+		t.Error("未生成任何章节")
+	}
+}
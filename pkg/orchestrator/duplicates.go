@@ -0,0 +1,38 @@
+package orchestrator
+
+import (
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/neardup"
+)
+
+// checkDuplicates 把本章正文与此前各章比较，近似重复的段落写入章节记录
+// 失败只记录日志，不中断生成
+func (o *Orchestrator) checkDuplicates(projectID string, blueprint *models.NarrativeBlueprint, plan models.ChapterPlan) {
+	if projectID == "" {
+		return
+	}
+	chapter, err := o.db.GetChapterByNum(projectID, plan.Chapter)
+	if err != nil || chapter == nil {
+		return
+	}
+	prose := func(ch *models.Chapter) string {
+		if strings.TrimSpace(ch.Content) != "" {
+			return ch.Content
+		}
+		return o.chapterProse(blueprint.ID, ch.ChapterNum)
+	}
+	found := neardup.ScanChapter(o.db.ListChaptersByProject(projectID), chapter, prose, neardup.DefaultThreshold)
+	if len(found) == 0 && len(chapter.DuplicatePassages) == 0 {
+		return
+	}
+	chapter.DuplicatePassages = found
+	if err := o.db.SaveChapter(chapter); err != nil {
+		o.log().Warn("保存近似重复检测结果失败", "chapter", plan.Chapter, "error", err)
+		return
+	}
+	if n := len(found); n > 0 {
+		o.log().Warn("本章有与此前章节近似重复的段落", "chapter", plan.Chapter, "passages", n)
+	}
+}
@@ -0,0 +1,31 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/items"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// itemHoldings 第 chapter 章开始时关键物品的去向，注入场景提示词
+func (o *Orchestrator) itemHoldings(projectID string, chapter int) []models.ItemHolding {
+	return items.Load(o.db, projectID, chapter)
+}
+
+// recordItems 用本章摘要中的物品去向变化更新记录，失败只记录日志
+func (o *Orchestrator) recordItems(projectID string, chapter int, changes []writer.ItemChange) {
+	if projectID == "" {
+		return
+	}
+	converted := make([]items.Change, 0, len(changes))
+	for _, c := range changes {
+		converted = append(converted, items.Change(c))
+	}
+	unknown, err := items.RecordChapter(o.db, projectID, chapter, converted)
+	if err != nil {
+		o.log().Warn("更新物品去向失败", "chapter", chapter, "error", err)
+		return
+	}
+	if len(unknown) > 0 {
+		o.log().Info("本章提到的物品未登记，已忽略", "chapter", chapter, "items", unknown)
+	}
+}
@@ -0,0 +1,39 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/memory"
+)
+
+// indexMemories 将章节摘要、角色事实和世界设定写入检索记忆（内容未变的条目不会重新嵌入）
+// 已有正文摘要的章节使用正文摘要，其余章节使用规划摘要
+func (o *Orchestrator) indexMemories(projectID string, blueprint *models.NarrativeBlueprint, world *models.WorldSetting) {
+	docs := memory.ChapterPlanDocuments(blueprint.ChapterPlans)
+	if projectID != "" {
+		summaries := make(map[int]*models.Chapter)
+		for _, ch := range o.db.ListChaptersByProject(projectID) {
+			if ch.Summary != "" {
+				summaries[ch.ChapterNum] = ch
+			}
+		}
+		for i, doc := range docs {
+			if ch, ok := summaries[doc.Chapter]; ok {
+				docs[i] = memory.ChapterSummaryDocument(ch.ChapterNum, ch.Title, ch.Summary)
+			}
+		}
+	}
+	if world != nil {
+		docs = append(docs, memory.CharacterDocuments(o.db.ListCharactersByWorld(world.ID), blueprint.CharacterArcs)...)
+		docs = append(docs, memory.WorldDocuments(world)...)
+	}
+	if err := o.memory.Index(blueprint.ID, docs); err != nil {
+		o.log().Warn("索引记忆失败", "blueprint_id", blueprint.ID, "error", err)
+	}
+}
+
+// recallMemories 召回与场景相关的记忆，章节记忆只取本章之前的
+func (o *Orchestrator) recallMemories(blueprint *models.NarrativeBlueprint, instr *models.SceneInstruction) []string {
+	return o.memory.Recall(blueprint.ID, memory.SceneQuery(instr), memory.SearchOptions{
+		BeforeChapter: instr.Chapter,
+	})
+}
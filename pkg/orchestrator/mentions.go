@@ -0,0 +1,29 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/mentions"
+)
+
+// trackMentions 记录本章中角色、地点和物品的出场，供出场时间线和缺席提醒使用
+// 失败只记录日志，不中断生成
+func (o *Orchestrator) trackMentions(projectID string, blueprint *models.NarrativeBlueprint, plan models.ChapterPlan) {
+	if projectID == "" {
+		return
+	}
+	prose := o.chapterProse(blueprint.ID, plan.Chapter)
+	if prose == "" {
+		return
+	}
+	chapter, err := o.db.GetChapterByNum(projectID, plan.Chapter)
+	if err != nil || chapter == nil {
+		return
+	}
+
+	found, err := mentions.TrackChapter(o.db, mentions.ProjectEntities(o.db, projectID, blueprint.WorldID), chapter, prose)
+	if err != nil {
+		o.log().Warn("保存实体提及失败", "chapter", plan.Chapter, "error", err)
+		return
+	}
+	o.log().Info("实体提及已更新", "chapter", plan.Chapter, "entities", len(found))
+}
@@ -0,0 +1,52 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/notify"
+	"github.com/xlei/xupu/pkg/scheduler"
+)
+
+// notifyTaskOwner 项目创建任务结束时通知所属用户；批量创作的最后一项结束时再通知整批结果
+func notifyTaskOwner(task *scheduler.Task, taskErr error) {
+	params, ok := task.Params.(CreationParams)
+	if !ok || params.UserID == "" {
+		return
+	}
+	database := db.Get()
+
+	msg := notify.Message{Event: models.NotifyEventProjectCompleted}
+	if taskErr != nil {
+		msg.Event = models.NotifyEventProjectFailed
+		msg.Title = fmt.Sprintf("《%s》创作失败", params.ProjectName)
+		msg.Text = fmt.Sprintf("项目ID：%s\n错误：%v", task.ProjectID, taskErr)
+	} else {
+		msg.Title = fmt.Sprintf("《%s》创作完成", params.ProjectName)
+		msg.Text = fmt.Sprintf("项目ID：%s", task.ProjectID)
+		if result, ok := task.GetResult().(*CreationResult); ok {
+			msg.Text += fmt.Sprintf("\n场景：%d，字数：%d", result.SceneCount, result.WordCount)
+		}
+	}
+	if task.StartedAt != nil {
+		msg.Text += fmt.Sprintf("\n用时：%s", time.Since(*task.StartedAt).Round(time.Second))
+	}
+	notify.Dispatch(database, params.UserID, msg)
+
+	if batch := batchOfTask(task.ID); batch != nil {
+		if status, finished := batch.finish(); finished {
+			notify.Dispatch(database, batch.UserID, notify.Message{
+				Event: models.NotifyEventBatchFinished,
+				Title: fmt.Sprintf("批量创作已结束（%d 项）", len(status.Items)),
+				Text: fmt.Sprintf("批次ID：%s\n状态：%s\n成功：%d，失败：%d，取消：%d\n消耗 token：%d，费用：%.4f",
+					status.ID, status.Status,
+					status.Counts[string(scheduler.StatusCompleted)],
+					status.Counts[string(scheduler.StatusFailed)],
+					status.Counts[string(scheduler.StatusCancelled)],
+					status.Usage.TotalTokens, status.Usage.Cost),
+			})
+		}
+	}
+}
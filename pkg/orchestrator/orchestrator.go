@@ -4,13 +4,17 @@ package orchestrator
 
 import (
 	"fmt"
-	"log"
+	"log/slog"
 	"time"
 
 	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/config"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/logx"
+	"github.com/xlei/xupu/pkg/memory"
 	"github.com/xlei/xupu/pkg/narrative"
+	"github.com/xlei/xupu/pkg/vitals"
 	"github.com/xlei/xupu/pkg/writer"
 	"github.com/xlei/xupu/pkg/worldbuilder"
 )
@@ -21,6 +25,7 @@ type CreationParams struct {
 	ProjectName string `json:"project_name"`
 	Description string `json:"description"`
 	UserID      string `json:"user_id,omitempty"`
+	APIKeyID    string `json:"api_key_id,omitempty"` // 通过API密钥提交时记录所用密钥
 
 	// 世界设定参数
 	WorldName   string `json:"world_name"`
@@ -28,6 +33,7 @@ type CreationParams struct {
 	WorldTheme  string `json:"world_theme"`
 	WorldScale  string `json:"world_scale"`
 	WorldStyle  string `json:"world_style"`
+	WorldTier   string `json:"world_tier,omitempty"` // 世界构建档位 quick/standard/deep
 
 	// 故事参数
 	StoryType    string `json:"story_type"`
@@ -36,9 +42,26 @@ type CreationParams struct {
 	StoryLength  string `json:"story_length"`
 	ChapterCount int  `json:"chapter_count,omitempty"`
 	Structure    string `json:"structure,omitempty"`
+	BeatSheetID  string `json:"beat_sheet_id,omitempty"` // 自定义节拍表
+	Genre        string `json:"genre,omitempty"` // 类型演化流水线：xianxia/romance/mystery/scifi
+
+	// 章节字数目标（为空时使用蓝图规划的字数）
+	WordCountTargets models.WordCountTargets `json:"word_count_targets"`
+
+	// 写作风格档案ID（内置预设或用户自定义）
+	StyleProfileID string `json:"style_profile_id,omitempty"`
+
+	// 视角策略（可选），规划阶段按策略分配场景视角，生成时校验
+	POVPolicy *models.POVPolicy `json:"pov_policy,omitempty"`
+
+	// 输出语言（zh-CN/zh-TW/en/ja），为空时为简体中文
+	Language string `json:"language,omitempty"`
 
 	// 生成选项
 	Options GenerationOptions `json:"options"`
+
+	// 随项目一起创建的 Webhook，创作流程中的事件也会投递
+	Webhooks []models.Webhook `json:"webhooks,omitempty"`
 }
 
 // GenerationOptions 生成选项
@@ -51,6 +74,7 @@ type GenerationOptions struct {
 	StartChapter     int  `json:"start_chapter"`         // 起始章节
 	EndChapter       int  `json:"end_chapter"`           // 结束章节
 	Style            string `json:"style"`                // 写作风格
+	MaxRevisions     int  `json:"max_revisions"`         // 场景审稿不通过时最多重写轮数，0表示不修订
 }
 
 // Orchestrator 编排器
@@ -60,11 +84,14 @@ type Orchestrator struct {
 	worldBuilder    *worldbuilder.WorldBuilder
 	narrativeEngine *narrative.NarrativeEngine
 	writer          *writer.Writer
+	memory          *memory.Store
+	logger          *slog.Logger
+	lang            string // 输出语言，为空时为简体中文
 }
 
 // New 创建编排器
 func New() (*Orchestrator, error) {
-	cfg, err := config.Load("config/config.yaml")
+	cfg, err := config.Current()
 	if err != nil {
 		return nil, fmt.Errorf("加载配置失败: %w", err)
 	}
@@ -91,11 +118,82 @@ func New() (*Orchestrator, error) {
 		worldBuilder:    worldBuilder,
 		narrativeEngine: narrativeEngine,
 		writer:          writer,
+		memory:          memory.NewStore(db.Get()),
 	}, nil
 }
 
+// WithLogger 返回使用指定日志的编排器副本，世界设定器、叙事器和写作器一并切换，
+// 异步任务以任务ID为 trace 串联整个创作流程的日志
+func (o *Orchestrator) WithLogger(l *slog.Logger) *Orchestrator {
+	cp := *o
+	cp.logger = l
+	if o.worldBuilder != nil {
+		cp.worldBuilder = o.worldBuilder.WithLogger(l)
+	}
+	if o.narrativeEngine != nil {
+		cp.narrativeEngine = o.narrativeEngine.WithLogger(l)
+	}
+	if o.writer != nil {
+		cp.writer = o.writer.WithLogger(l)
+	}
+	return &cp
+}
+
+// WithMeter 返回把LLM用量记到指定计量器的编排器副本，世界设定器、叙事器和写作器一并切换，用于按任务统计费用
+func (o *Orchestrator) WithMeter(m *llm.Meter) *Orchestrator {
+	cp := *o
+	if o.worldBuilder != nil {
+		cp.worldBuilder = o.worldBuilder.WithMeter(m)
+	}
+	if o.narrativeEngine != nil {
+		cp.narrativeEngine = o.narrativeEngine.WithMeter(m)
+	}
+	if o.writer != nil {
+		cp.writer = o.writer.WithMeter(m)
+	}
+	return &cp
+}
+
+// WithBudget 返回LLM请求前检查指定预算的编排器副本，世界设定器、叙事器和写作器一并切换，用于按用户限额
+func (o *Orchestrator) WithBudget(b llm.Budget) *Orchestrator {
+	cp := *o
+	if o.worldBuilder != nil {
+		cp.worldBuilder = o.worldBuilder.WithBudget(b)
+	}
+	if o.narrativeEngine != nil {
+		cp.narrativeEngine = o.narrativeEngine.WithBudget(b)
+	}
+	if o.writer != nil {
+		cp.writer = o.writer.WithBudget(b)
+	}
+	return &cp
+}
+
+// WithLanguage 返回按项目输出语言生成的编排器副本，世界设定器、叙事器和写作器一并切换
+func (o *Orchestrator) WithLanguage(lang string) *Orchestrator {
+	cp := *o
+	cp.lang = lang
+	if o.worldBuilder != nil {
+		cp.worldBuilder = o.worldBuilder.WithLanguage(lang)
+	}
+	if o.narrativeEngine != nil {
+		cp.narrativeEngine = o.narrativeEngine.WithLanguage(lang)
+	}
+	if o.writer != nil {
+		cp.writer = o.writer.WithLanguage(lang)
+	}
+	return &cp
+}
+
+// log 当前使用的日志
+func (o *Orchestrator) log() *slog.Logger {
+	return logx.Or(o.logger)
+}
+
 // CreateProject 创建新项目并执行完整的创作流程
 func (o *Orchestrator) CreateProject(params CreationParams) (*models.Project, error) {
+	o = o.WithLanguage(params.Language).withUserBudget(params.UserID)
+
 	// 1. 创建项目对象
 	project := &models.Project{
 		ID:          db.GenerateID("project"),
@@ -107,18 +205,24 @@ func (o *Orchestrator) CreateProject(params CreationParams) (*models.Project, er
 		Progress:    0,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
+
+		WordCountTargets: params.WordCountTargets,
+		StyleProfileID:   params.StyleProfileID,
+		Language:         params.Language,
 	}
 
 	// 保存项目
 	if err := o.db.SaveProject(project); err != nil {
 		return nil, fmt.Errorf("保存项目失败: %w", err)
 	}
+	o.registerWebhooks(project.ID, params.Webhooks)
 
 	// 2. 执行创作流程
 	result, err := o.executeCreationFlow(project, params)
 	if err != nil {
 		// 更新项目状态为失败
 		o.db.UpdateProjectStatus(project.ID, models.StatusFailed, project.Progress)
+		o.notify(project.ID, models.WebhookEventProjectFailed, map[string]interface{}{"error": err.Error()})
 		return nil, fmt.Errorf("执行创作流程失败: %w", err)
 	}
 
@@ -132,25 +236,29 @@ func (o *Orchestrator) CreateProject(params CreationParams) (*models.Project, er
 	if err := o.db.SaveProject(project); err != nil {
 		return nil, fmt.Errorf("更新项目失败: %w", err)
 	}
+	o.notify(project.ID, models.WebhookEventProjectCompleted, result)
 
 	return project, nil
 }
 
 // CreationResult 创作结果
 type CreationResult struct {
+	ProjectID   string `json:"project_id"`
 	WorldID     string `json:"world_id"`
 	NarrativeID string `json:"narrative_id"`
 	SceneCount  int    `json:"scene_count"`
 	WordCount   int    `json:"word_count"`
 	Duration    time.Duration `json:"duration"`
+	POVViolations []models.POVViolation `json:"pov_violations,omitempty"` // 生成时发现的视角违规（已按策略纠正）
+	VitalViolations []vitals.Violation  `json:"vital_violations,omitempty"` // 场景规划中出现的已故角色（已从出场角色中移除）
 }
 
 // executeCreationFlow 执行创作流程
 func (o *Orchestrator) executeCreationFlow(project *models.Project, params CreationParams) (*CreationResult, error) {
 	startTime := time.Now()
-	result := &CreationResult{}
+	result := &CreationResult{ProjectID: project.ID}
 
-	log.Printf("[编排器] 开始执行创作流程，项目ID: %s", project.ID)
+	o.log().Info("开始执行创作流程", "project_id", project.ID)
 
 	// 阶段1: 世界设定
 	worldID, err := o.stage1_WorldBuilding(params, result)
@@ -160,7 +268,7 @@ func (o *Orchestrator) executeCreationFlow(project *models.Project, params Creat
 	result.WorldID = worldID
 	project.WorldID = worldID
 	o.db.SaveProject(project) // 更新进度
-	log.Printf("[编排器] 世界设定完成，ID: %s", worldID)
+	o.log().Info("世界设定完成", "world_id", worldID)
 
 	// 阶段2: 叙事蓝图
 	narrativeID, err := o.stage2_NarrativePlanning(worldID, params, result)
@@ -170,7 +278,8 @@ func (o *Orchestrator) executeCreationFlow(project *models.Project, params Creat
 	result.NarrativeID = narrativeID
 	project.NarrativeID = narrativeID
 	o.db.SaveProject(project) // 更新进度
-	log.Printf("[编排器] 叙事蓝图完成，ID: %s", narrativeID)
+	o.log().Info("叙事蓝图完成", "blueprint_id", narrativeID)
+	o.notifyEvolution(project.ID, narrativeID, params)
 
 	// 阶段3: 内容生成（如果需要）
 	if params.Options.GenerateContent {
@@ -180,11 +289,11 @@ func (o *Orchestrator) executeCreationFlow(project *models.Project, params Creat
 		}
 		result.SceneCount = sceneCount
 		result.WordCount = wordCount
-		log.Printf("[编排器] 内容生成完成，场景数: %d, 字数: %d", sceneCount, wordCount)
+		o.log().Info("内容生成完成", "scenes", sceneCount, "words", wordCount)
 	}
 
 	result.Duration = time.Since(startTime)
-	log.Printf("[编排器] 创作流程完成，耗时: %v", result.Duration)
+	o.log().Info("创作流程完成", "elapsed", result.Duration)
 
 	return result, nil
 }
@@ -197,7 +306,7 @@ func (o *Orchestrator) stage1_WorldBuilding(params CreationParams, result *Creat
 		if err != nil {
 			return "", fmt.Errorf("获取指定世界失败: %w", err)
 		}
-		log.Printf("[编排器] 使用已有世界: %s", world.Name)
+		o.log().Info("使用已有世界", "world_id", world.ID, "name", world.Name)
 		return world.ID, nil
 	}
 
@@ -208,6 +317,7 @@ func (o *Orchestrator) stage1_WorldBuilding(params CreationParams, result *Creat
 		Scale:     parseWorldScale(params.WorldScale),
 		Theme:     params.WorldTheme,
 		Style:     params.WorldStyle,
+		Tier:      models.ParseWorldBuildTier(params.WorldTier),
 	})
 
 	if err != nil {
@@ -219,17 +329,39 @@ func (o *Orchestrator) stage1_WorldBuilding(params CreationParams, result *Creat
 
 // stage2_NarrativePlanning 阶段2: 叙事规划
 func (o *Orchestrator) stage2_NarrativePlanning(worldID string, params CreationParams, result *CreationResult) (string, error) {
+	if params.POVPolicy != nil {
+		if err := params.POVPolicy.Validate(); err != nil {
+			return "", fmt.Errorf("视角策略无效: %w", err)
+		}
+	}
+
 	// 如果指定了已有蓝图，直接使用
 	if params.Options.ExistingBlueprintID != "" {
 		blueprint, err := o.db.GetNarrativeBlueprint(params.Options.ExistingBlueprintID)
 		if err != nil {
 			return "", fmt.Errorf("获取指定蓝图失败: %w", err)
 		}
-		log.Printf("[编排器] 使用已有蓝图: %s", blueprint.ID)
+		o.log().Info("使用已有蓝图", "blueprint_id", blueprint.ID)
+
+		// 已有蓝图只设置策略，不改写场景，不符合的场景在生成时上报
+		if params.POVPolicy != nil {
+			blueprint.POVPolicy = params.POVPolicy
+			if err := o.db.SaveNarrativeBlueprint(blueprint); err != nil {
+				return "", fmt.Errorf("保存视角策略失败: %w", err)
+			}
+		}
 		return blueprint.ID, nil
 	}
 
 	// 创建新蓝图
+	var beatSheet *models.BeatSheet
+	if params.BeatSheetID != "" {
+		sheet, err := narrative.LoadBeatSheet(o.db, params.BeatSheetID)
+		if err != nil {
+			return "", err
+		}
+		beatSheet = sheet
+	}
 	narrativeParams := narrative.CreateParams{
 		WorldID:      worldID,
 		StoryType:    params.StoryType,
@@ -238,6 +370,8 @@ func (o *Orchestrator) stage2_NarrativePlanning(worldID string, params CreationP
 		Length:       params.StoryLength,
 		ChapterCount: params.ChapterCount,
 		Structure:    parseNarrativeStructure(params.Structure),
+		Genre:        narrative.ParseGenre(params.Genre),
+		BeatSheet:    beatSheet,
 	}
 
 	blueprint, err := o.narrativeEngine.CreateBlueprint(narrativeParams)
@@ -245,6 +379,15 @@ func (o *Orchestrator) stage2_NarrativePlanning(worldID string, params CreationP
 		return "", err
 	}
 
+	// 按视角策略分配场景视角
+	if params.POVPolicy != nil {
+		changed := narrative.ApplyPOVPolicy(blueprint, params.POVPolicy)
+		if err := o.db.SaveNarrativeBlueprint(blueprint); err != nil {
+			return "", fmt.Errorf("保存视角策略失败: %w", err)
+		}
+		o.log().Info("已应用视角策略", "mode", params.POVPolicy.Mode, "changed_scenes", changed)
+	}
+
 	return blueprint.ID, nil
 }
 
@@ -274,42 +417,83 @@ func (o *Orchestrator) stage3_ContentGeneration(narrativeID string, params Creat
 
 	// 获取风格配置
 	style := writer.DefaultStyle()
+	styleProfile, err := writer.ResolveStyleProfile(o.db, params.StyleProfileID)
+	if err != nil {
+		o.log().Warn("风格档案不可用，使用默认风格", "error", err)
+	}
 	if params.Options.Style != "" {
 		if styleProfile, ok := writer.GetStyle(params.Options.Style); ok {
 			params.Options.Style = styleProfile.Name
 		}
 	}
 
+	// 索引检索记忆
+	o.indexMemories(result.ProjectID, blueprint, world)
+	voices := o.voiceProfiles(blueprint, world)
+	characters := o.characterStates(blueprint, world)
+	project, _ := o.db.GetProject(result.ProjectID)
+
 	// 逐章生成
 	for i := startChapter - 1; i < endChapter; i++ {
 		chapter := blueprint.ChapterPlans[i]
-		log.Printf("[编排器] 生成第%d章: %s", chapter.Chapter, chapter.Title)
+		o.log().Info("生成章节", "chapter", chapter.Chapter, "title", chapter.Title)
 
 		// 获取该章的场景指令
 		chapterScenes := getScenesForChapter(blueprint.Scenes, chapter.Chapter)
+		sceneTargets := sceneWordTargets(params.WordCountTargets.For(chapter.Chapter, chapter.WordCount), chapterScenes)
+		previousSummary := o.previousContext(result.ProjectID, chapter.Chapter)
+		sceneCtx := BuildSceneContext(o.db, project, chapter.Chapter)
+
+		for j, sceneInstr := range chapterScenes {
+			if v := o.enforcePOV(blueprint, &sceneInstr); v != nil {
+				result.POVViolations = append(result.POVViolations, *v)
+			}
+			result.VitalViolations = append(result.VitalViolations, o.guardDeceased(result.ProjectID, &sceneInstr)...)
+			o.choreograph(result.ProjectID, blueprint, world, &chapter, &sceneInstr)
 
-		for _, sceneInstr := range chapterScenes {
 			// 生成场景
-			sceneResult, err := o.writer.GenerateScene(writer.GenerateParams{
+			sceneParams := writer.GenerateParams{
 				BlueprintID:    blueprint.ID,
 				Chapter:        sceneInstr.Chapter,
 				Scene:          sceneInstr.Scene,
 				Instruction:    &sceneInstr,
-				PreviousSummary: buildPreviousSummary(blueprint.ChapterPlans[:i]),
-				CharacterStates: buildCharacterStates(blueprint, world),
+				PreviousSummary: previousSummary,
+				Memories:       o.recallMemories(blueprint, &sceneInstr),
+				CharacterStates: characters,
 				WorldContext:   world,
 				Style:          style,
-			})
+				TargetWordCount: sceneTargets[j],
+				StyleProfile:   styleProfile,
+				VoiceProfiles:  voices,
+				POVConstraint:  blueprint.POVPolicy.Constraint(sceneInstr.Chapter),
+				Checklist:      o.sceneChecklist(result.ProjectID, blueprint, &sceneInstr),
+				MaxRevisions:   params.Options.MaxRevisions,
+			}
+			sceneCtx.Apply(&sceneParams)
+			sceneResult, err := o.writer.GenerateScene(sceneParams)
 
 			if err != nil {
-				log.Printf("[编排器] 警告: 场景%d-%d生成失败: %v", sceneInstr.Chapter, sceneInstr.Scene, err)
+				o.log().Warn("场景生成失败", "chapter", sceneInstr.Chapter, "scene", sceneInstr.Scene, "error", err)
 				continue
 			}
 
 			sceneCount++
 			totalWordCount += sceneResult.WordCount
-			log.Printf("[编排器] 场景%d-%d生成完成，字数: %d", sceneInstr.Chapter, sceneInstr.Scene, sceneResult.WordCount)
+			o.log().Info("场景生成完成", "chapter", sceneInstr.Chapter, "scene", sceneInstr.Scene, "words", sceneResult.WordCount)
+			if n := len(sceneResult.Metadata.Violations); n > 0 {
+				o.log().Warn("场景修订后仍有违规", "chapter", sceneInstr.Chapter, "scene", sceneInstr.Scene, "revisions", sceneResult.Metadata.Revisions, "violations", n)
+			}
 		}
+
+		// 本章摘要作为下一章的前情
+		o.summarizeChapter(result.ProjectID, blueprint, chapter)
+		o.checkChapterVoice(result.ProjectID, blueprint, chapter, voices)
+		o.trackMentions(result.ProjectID, blueprint, chapter)
+		o.checkContinuity(result.ProjectID, blueprint, chapter)
+		o.analyzeTheme(result.ProjectID, blueprint, chapter)
+		o.measureStyle(result.ProjectID, blueprint, chapter)
+		o.checkDuplicates(result.ProjectID, blueprint, chapter)
+		o.notifyChapter(result.ProjectID, chapter)
 	}
 
 	return sceneCount, totalWordCount, nil
@@ -325,6 +509,7 @@ func (o *Orchestrator) ResumeGeneration(projectID string) error {
 	if project.Status != models.StatusPaused {
 		return fmt.Errorf("项目状态不是暂停，无法恢复")
 	}
+	o = o.WithLanguage(project.Language)
 
 	project.Status = models.StatusGenerating
 	o.db.SaveProject(project)
@@ -338,33 +523,71 @@ func (o *Orchestrator) ResumeGeneration(projectID string) error {
 	// 找到下一个未生成的场景
 	world, _ := o.db.GetWorld(blueprint.WorldID)
 	style := writer.DefaultStyle()
+	styleProfile, err := writer.ResolveStyleProfile(o.db, project.StyleProfileID)
+	if err != nil {
+		o.log().Warn("风格档案不可用，使用默认风格", "error", err)
+	}
+
+	o.indexMemories(project.ID, blueprint, world)
+	voices := o.voiceProfiles(blueprint, world)
+	characters := o.characterStates(blueprint, world)
 
 	for _, chapter := range blueprint.ChapterPlans {
 		chapterScenes := getScenesForChapter(blueprint.Scenes, chapter.Chapter)
+		sceneTargets := sceneWordTargets(project.WordCountTargets.For(chapter.Chapter, chapter.WordCount), chapterScenes)
+		previousSummary := o.previousContext(project.ID, chapter.Chapter)
+		sceneCtx := BuildSceneContext(o.db, project, chapter.Chapter)
+		generated := false
 
-		for _, sceneInstr := range chapterScenes {
+		for j, sceneInstr := range chapterScenes {
 			// 检查是否已生成
 			existing, _ := o.db.GetSceneByBlueprintAndChapter(blueprint.ID, sceneInstr.Chapter, sceneInstr.Scene)
 			if existing != nil {
 				continue // 已生成，跳过
 			}
 
+			o.enforcePOV(blueprint, &sceneInstr)
+			o.guardDeceased(project.ID, &sceneInstr)
+			o.choreograph(project.ID, blueprint, world, &chapter, &sceneInstr)
+
 			// 生成场景
-			_, err := o.writer.GenerateScene(writer.GenerateParams{
+			sceneParams := writer.GenerateParams{
 				BlueprintID:    blueprint.ID,
 				Chapter:        sceneInstr.Chapter,
 				Scene:          sceneInstr.Scene,
 				Instruction:    &sceneInstr,
-				PreviousSummary: buildPreviousSummary(blueprint.ChapterPlans[:chapter.Chapter-1]),
-				CharacterStates: buildCharacterStates(blueprint, world),
+				PreviousSummary: previousSummary,
+				Memories:       o.recallMemories(blueprint, &sceneInstr),
+				CharacterStates: characters,
 				WorldContext:   world,
 				Style:          style,
-			})
+				TargetWordCount: sceneTargets[j],
+				StyleProfile:   styleProfile,
+				VoiceProfiles:  voices,
+				POVConstraint:  blueprint.POVPolicy.Constraint(sceneInstr.Chapter),
+			}
+			sceneCtx.Apply(&sceneParams)
+			_, err := o.writer.GenerateScene(sceneParams)
 
 			if err != nil {
-				log.Printf("场景生成失败: %v", err)
+				o.log().Warn("场景生成失败", "chapter", sceneInstr.Chapter, "scene", sceneInstr.Scene, "error", err)
 				continue
 			}
+			generated = true
+		}
+
+		// 有新场景或尚未摘要的章节重新生成摘要
+		if generated || !o.summarized(project.ID, chapter.Chapter) {
+			o.summarizeChapter(project.ID, blueprint, chapter)
+		}
+		if generated {
+			o.checkChapterVoice(project.ID, blueprint, chapter, voices)
+			o.trackMentions(project.ID, blueprint, chapter)
+			o.checkContinuity(project.ID, blueprint, chapter)
+			o.analyzeTheme(project.ID, blueprint, chapter)
+			o.measureStyle(project.ID, blueprint, chapter)
+			o.checkDuplicates(project.ID, blueprint, chapter)
+			o.notifyChapter(project.ID, chapter)
 		}
 	}
 
@@ -506,15 +729,27 @@ func getScenesForChapter(scenes []models.SceneInstruction, chapter int) []models
 	return result
 }
 
-func buildPreviousSummary(chapters []models.ChapterPlan) string {
-	if len(chapters) == 0 {
-		return ""
+// sceneWordTargets 将章节目标字数按场景预期长度分摊到各场景
+// 场景均未给出预期长度时平均分配；章节目标为0时全部返回0（沿用场景指令）
+func sceneWordTargets(chapterTarget int, scenes []models.SceneInstruction) []int {
+	targets := make([]int, len(scenes))
+	if chapterTarget <= 0 || len(scenes) == 0 {
+		return targets
 	}
-	summary := "前情提要："
-	for _, ch := range chapters {
-		summary += fmt.Sprintf("第%d章%s；", ch.Chapter, ch.Title)
+
+	totalExpected := 0
+	for _, scene := range scenes {
+		totalExpected += scene.ExpectedLength
 	}
-	return summary
+
+	for i, scene := range scenes {
+		if totalExpected > 0 {
+			targets[i] = chapterTarget * scene.ExpectedLength / totalExpected
+		} else {
+			targets[i] = chapterTarget / len(scenes)
+		}
+	}
+	return targets
 }
 
 func buildCharacterStates(blueprint *models.NarrativeBlueprint, world *models.WorldSetting) map[string]*writer.CharacterContext {
@@ -533,6 +768,37 @@ func buildCharacterStates(blueprint *models.NarrativeBlueprint, world *models.Wo
 	return states
 }
 
+// characterStates 场景生成用的角色上下文：在基础角色状态上叠加世界的角色卡（按演化角色ID索引）
+// 角色卡包含用户编辑和锁定的欲望、恐惧与秘密，以角色卡为准
+func (o *Orchestrator) characterStates(blueprint *models.NarrativeBlueprint, world *models.WorldSetting) map[string]*writer.CharacterContext {
+	if world == nil {
+		return map[string]*writer.CharacterContext{}
+	}
+	states := buildCharacterStates(blueprint, world)
+	sheets, err := o.db.ListCharacterSheets(world.ID)
+	if err != nil {
+		o.log().Debug("读取角色卡失败", "world_id", world.ID, "error", err)
+		return states
+	}
+	for _, sheet := range sheets {
+		if sheet.CharacterID == "" {
+			continue
+		}
+		states[sheet.CharacterID] = &writer.CharacterContext{
+			ID:             sheet.CharacterID,
+			Name:           sheet.Name,
+			Role:           sheet.Role,
+			CurrentEmotion: sheet.CurrentEmotion,
+			Desire:         sheet.ConsciousWant,
+			Fear:           sheet.Fear,
+			Secrets:        sheet.Secrets,
+			Knowledge:      []string{},
+			Relationships:  make(map[string]string),
+		}
+	}
+	return states
+}
+
 func determineStage(status models.ProjectStatus) string {
 	switch status {
 	case models.StatusDraft:
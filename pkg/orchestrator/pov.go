@@ -0,0 +1,17 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+)
+
+// enforcePOV 按蓝图视角策略校验场景指令
+// 不符合时改用策略要求的视角角色生成，并返回违规记录供上报；符合或未设置策略时返回nil
+func (o *Orchestrator) enforcePOV(blueprint *models.NarrativeBlueprint, instr *models.SceneInstruction) *models.POVViolation {
+	v := blueprint.POVPolicy.Check(*instr)
+	if v == nil {
+		return nil
+	}
+	o.log().Warn("场景视角不符合策略，改用策略视角", "chapter", instr.Chapter, "scene", instr.Scene, "reason", v.Message, "pov", v.Expected)
+	instr.POVCharacter = v.Expected
+	return v
+}
@@ -0,0 +1,11 @@
+package orchestrator
+
+import "github.com/xlei/xupu/pkg/quota"
+
+// withUserBudget 返回按用户月度预算限制LLM请求的编排器副本，用户为空（CLI等本地调用）时不限制
+func (o *Orchestrator) withUserBudget(userID string) *Orchestrator {
+	if userID == "" || o.db == nil {
+		return o
+	}
+	return o.WithBudget(quota.For(o.db, userID))
+}
@@ -0,0 +1,42 @@
+package orchestrator
+
+import (
+	"encoding/json"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/narrative"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// sceneChecklist 场景的审稿清单
+// 优先使用章节记录中保存的细纲（含必含元素、禁止透露的信息和氛围），没有时只检查视角和情绪基调；
+// 蓝图设置了视角策略时，以策略要求的视角角色为准
+func (o *Orchestrator) sceneChecklist(projectID string, blueprint *models.NarrativeBlueprint, instr *models.SceneInstruction) *writer.SceneChecklist {
+	checklist := o.outlineChecklist(projectID, instr)
+	if pov := blueprint.POVPolicy.ExpectedPOV(instr.Chapter); pov != "" {
+		checklist.POVCharacter = pov
+	}
+	return checklist
+}
+
+// outlineChecklist 由章节细纲或场景指令构建审稿清单
+func (o *Orchestrator) outlineChecklist(projectID string, instr *models.SceneInstruction) *writer.SceneChecklist {
+	if projectID != "" {
+		if record, err := o.db.GetChapterByNum(projectID, instr.Chapter); err == nil && record != nil && len(record.DetailOutline) > 0 {
+			outline := &narrative.ChapterDetailOutline{}
+			if err := json.Unmarshal(record.DetailOutline, outline); err == nil {
+				for _, scene := range outline.Scenes {
+					if scene != nil && scene.Sequence == instr.Scene {
+						checklist := scene.Checklist()
+						return &checklist
+					}
+				}
+			}
+		}
+	}
+
+	return &writer.SceneChecklist{
+		POVCharacter: instr.POVCharacter,
+		Mood:         instr.Mood,
+	}
+}
@@ -0,0 +1,63 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/continuity"
+	"github.com/xlei/xupu/pkg/cultivation"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/items"
+	"github.com/xlei/xupu/pkg/vitals"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// SceneContext 写某一章时注入场景提示词的连续性上下文
+type SceneContext struct {
+	ContinuityFacts []models.ContinuityFact // 仍有效的物理和状态细节
+	VitalStates     []models.VitalState     // 已故或重伤未愈的角色
+	ItemHoldings    []models.ItemHolding    // 关键物品的去向
+	RealmStates     []models.RealmState     // 角色当前境界
+	RomanceArcs     []models.RomanceArc     // 感情线节拍规划
+}
+
+// BuildSceneContext 加载项目写第 chapterNum 章时的连续性上下文
+// 世界设定和蓝图按项目关联的ID读取，没有关联时对应部分为空；同一章的各场景可共用一份
+func BuildSceneContext(database db.Database, project *models.Project, chapterNum int) SceneContext {
+	if project == nil {
+		return SceneContext{}
+	}
+
+	var world *models.WorldSetting
+	if project.WorldID != "" {
+		world, _ = database.GetWorld(project.WorldID)
+	}
+	sc := SceneContext{
+		ContinuityFacts: continuity.ActiveFacts(database, project.ID, chapterNum),
+		VitalStates:     vitals.Load(database, project.ID).StatesAt(chapterNum),
+		ItemHoldings:    items.Load(database, project.ID, chapterNum),
+		RealmStates:     cultivation.StatesAt(database, project.ID, world, chapterNum),
+	}
+	if project.NarrativeID != "" {
+		if blueprint, err := database.GetNarrativeBlueprint(project.NarrativeID); err == nil {
+			sc.RomanceArcs = blueprint.RomanceArcs
+		}
+	}
+	return sc
+}
+
+// Apply 把上下文填入场景生成参数
+func (sc SceneContext) Apply(params *writer.GenerateParams) {
+	params.ContinuityFacts = sc.ContinuityFacts
+	params.VitalStates = sc.VitalStates
+	params.ItemHoldings = sc.ItemHoldings
+	params.RealmStates = sc.RealmStates
+	params.RomanceArcs = sc.RomanceArcs
+}
+
+// ApplyChapter 把上下文填入按细纲写作整章的参数
+func (sc SceneContext) ApplyChapter(params *writer.ChapterParams) {
+	params.ContinuityFacts = sc.ContinuityFacts
+	params.VitalStates = sc.VitalStates
+	params.ItemHoldings = sc.ItemHoldings
+	params.RealmStates = sc.RealmStates
+	params.RomanceArcs = sc.RomanceArcs
+}
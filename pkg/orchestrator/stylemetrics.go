@@ -0,0 +1,26 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/stylemetrics"
+)
+
+// measureStyle 统计本章正文的文风指标，结果写入章节记录
+// 纯文本统计，不调用模型；失败只记录日志，不中断生成
+func (o *Orchestrator) measureStyle(projectID string, blueprint *models.NarrativeBlueprint, plan models.ChapterPlan) {
+	if projectID == "" {
+		return
+	}
+	prose := o.chapterProse(blueprint.ID, plan.Chapter)
+	if prose == "" {
+		return
+	}
+	chapter, err := o.db.GetChapterByNum(projectID, plan.Chapter)
+	if err != nil || chapter == nil {
+		return
+	}
+	chapter.StyleMetrics = stylemetrics.Measure(prose)
+	if err := o.db.SaveChapter(chapter); err != nil {
+		o.log().Warn("保存文风指标失败", "chapter", plan.Chapter, "error", err)
+	}
+}
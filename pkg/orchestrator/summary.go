@@ -0,0 +1,117 @@
+package orchestrator
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/cultivation"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/locale"
+	"github.com/xlei/xupu/pkg/memory"
+	"github.com/xlei/xupu/pkg/vitals"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// chapterProse 按场景顺序拼接本章已生成的正文
+func (o *Orchestrator) chapterProse(blueprintID string, chapter int) string {
+	scenes := o.db.ListScenesByChapter(blueprintID, chapter)
+	sort.Slice(scenes, func(i, j int) bool { return scenes[i].Scene < scenes[j].Scene })
+
+	parts := make([]string, 0, len(scenes))
+	for _, s := range scenes {
+		if strings.TrimSpace(s.Content) != "" {
+			parts = append(parts, s.Content)
+		}
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// previousContext 上一章记录的滚动梗概和角色状态变化，作为本章的前情摘要
+func (o *Orchestrator) previousContext(projectID string, chapter int) string {
+	if projectID == "" || chapter <= 1 {
+		return ""
+	}
+	prev, err := o.db.GetChapterByNum(projectID, chapter-1)
+	if err != nil || prev == nil || prev.RollingSummary == "" {
+		return ""
+	}
+	return writer.FormatPreviousContext(prev.RollingSummary, prev.StateDeltas)
+}
+
+// summarized 章节记录是否已有摘要
+func (o *Orchestrator) summarized(projectID string, chapter int) bool {
+	record, err := o.db.GetChapterByNum(projectID, chapter)
+	return err == nil && record != nil && record.RollingSummary != ""
+}
+
+// summarizeChapter 根据本章正文生成摘要，写入章节记录并更新检索记忆
+// 失败只记录日志，不中断生成
+func (o *Orchestrator) summarizeChapter(projectID string, blueprint *models.NarrativeBlueprint, plan models.ChapterPlan) {
+	prose := o.chapterProse(blueprint.ID, plan.Chapter)
+	if prose == "" {
+		return
+	}
+
+	var previous string
+	if projectID != "" && plan.Chapter > 1 {
+		if prev, err := o.db.GetChapterByNum(projectID, plan.Chapter-1); err == nil && prev != nil {
+			previous = prev.RollingSummary
+		}
+	}
+
+	system := o.worldCultivation(blueprint)
+	var realms []models.RealmState
+	if system != nil {
+		realms = cultivation.Load(o.db, projectID, system.Realms).StatesAt(plan.Chapter)
+	}
+	summary, err := o.writer.SummarizeChapter(writer.ChapterSummaryParams{
+		Chapter:         plan.Chapter,
+		Title:           plan.Title,
+		Prose:           prose,
+		PreviousSummary: previous,
+		Deceased:        vitals.Load(o.db, projectID).Deceased(plan.Chapter),
+		Items:           o.itemHoldings(projectID, plan.Chapter),
+		Cultivation:     system,
+		Realms:          realms,
+	})
+	if err != nil {
+		o.log().Warn("章节摘要失败", "chapter", plan.Chapter, "error", err)
+		return
+	}
+
+	if projectID != "" {
+		chapter, err := o.db.GetChapterByNum(projectID, plan.Chapter)
+		if err != nil || chapter == nil {
+			now := time.Now()
+			wordCount := locale.CountWords(o.lang, prose)
+			chapter = &models.Chapter{
+				ID:          db.GenerateID("chapter"),
+				ProjectID:   projectID,
+				ChapterNum:  plan.Chapter,
+				Title:       plan.Title,
+				Content:     prose,
+				WordCount:   wordCount,
+				AIWordCount: wordCount,
+				Status:      models.ChapterStatusDraft,
+				GeneratedAt: &now,
+			}
+		}
+		chapter.Summary = summary.Summary
+		chapter.RollingSummary = summary.RollingSummary
+		chapter.StateDeltas = summary.StateDeltas
+		if err := o.db.SaveChapter(chapter); err != nil {
+			o.log().Warn("保存章节摘要失败", "chapter", plan.Chapter, "error", err)
+		}
+		o.recordVitals(projectID, plan.Chapter, summary.VitalEvents)
+		o.recordItems(projectID, plan.Chapter, summary.ItemTransfers)
+		o.recordRealms(projectID, system, plan.Chapter, summary.RealmChanges)
+	}
+
+	doc := memory.ChapterSummaryDocument(plan.Chapter, plan.Title, summary.Summary)
+	if err := o.memory.Index(blueprint.ID, []memory.Document{doc}); err != nil {
+		o.log().Warn("索引章节摘要失败", "chapter", plan.Chapter, "error", err)
+	}
+	o.log().Info("章节摘要完成", "chapter", plan.Chapter)
+}
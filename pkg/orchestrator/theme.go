@@ -0,0 +1,38 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// analyzeTheme 分析本章正文对主题规划的体现，结果写入章节记录
+// 没有主题规划时跳过；失败只记录日志，不中断生成
+func (o *Orchestrator) analyzeTheme(projectID string, blueprint *models.NarrativeBlueprint, plan models.ChapterPlan) {
+	if projectID == "" || blueprint.ThemePlan.Empty() {
+		return
+	}
+	prose := o.chapterProse(blueprint.ID, plan.Chapter)
+	if prose == "" {
+		return
+	}
+	chapter, err := o.db.GetChapterByNum(projectID, plan.Chapter)
+	if err != nil || chapter == nil {
+		return
+	}
+
+	coverage, err := o.writer.AnalyzeTheme(writer.ThemeParams{
+		Chapter: plan.Chapter,
+		Title:   plan.Title,
+		Prose:   prose,
+		Plan:    &blueprint.ThemePlan,
+		Planned: blueprint.ThemePlan.ThreadingAt(plan.Chapter),
+	})
+	if err != nil {
+		o.log().Warn("主题覆盖分析失败", "chapter", plan.Chapter, "error", err)
+		return
+	}
+	chapter.ThemeCoverage = coverage
+	if err := o.db.SaveChapter(chapter); err != nil {
+		o.log().Warn("保存主题覆盖分析失败", "chapter", plan.Chapter, "error", err)
+	}
+}
@@ -0,0 +1,53 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/vitals"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// vitalStates 第 chapter 章开始时已故或重伤未愈的角色，注入场景提示词
+func (o *Orchestrator) vitalStates(projectID string, chapter int) []models.VitalState {
+	return vitals.Load(o.db, projectID).StatesAt(chapter)
+}
+
+// guardDeceased 按生死台账校验场景指令
+// 出场角色中的已故角色（该章登记了复活的除外）从本次生成中移除，并返回违规记录供上报
+func (o *Orchestrator) guardDeceased(projectID string, instr *models.SceneInstruction) []vitals.Violation {
+	violations := vitals.Load(o.db, projectID).CheckScenes([]models.SceneInstruction{*instr})
+	if len(violations) == 0 {
+		return nil
+	}
+	dead := make(map[string]bool, len(violations))
+	for _, v := range violations {
+		dead[v.Character] = true
+		o.log().Warn("场景规划中出现已故角色，已从出场角色中移除", "chapter", instr.Chapter, "scene", instr.Scene, "character", v.Character, "died_in", v.DiedIn)
+	}
+	kept := make([]string, 0, len(instr.Characters))
+	for _, name := range instr.Characters {
+		if !dead[name] {
+			kept = append(kept, name)
+		}
+	}
+	instr.Characters = kept
+	return violations
+}
+
+// recordVitals 用本章摘要中的生死变化更新台账，失败只记录日志
+func (o *Orchestrator) recordVitals(projectID string, chapter int, changes []writer.VitalChange) {
+	if projectID == "" {
+		return
+	}
+	events := make([]models.VitalEvent, 0, len(changes))
+	for _, c := range changes {
+		events = append(events, models.VitalEvent{
+			Character: c.Character,
+			Event:     c.Event,
+			Detail:    c.Detail,
+			Evidence:  c.Evidence,
+		})
+	}
+	if err := vitals.RecordChapter(o.db, projectID, chapter, events); err != nil {
+		o.log().Warn("更新生死台账失败", "chapter", chapter, "error", err)
+	}
+}
@@ -0,0 +1,65 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+// voiceProfiles 收集本项目的角色语音档案
+func (o *Orchestrator) voiceProfiles(blueprint *models.NarrativeBlueprint, world *models.WorldSetting) map[string]*models.VoiceProfile {
+	return VoiceProfiles(o.db, blueprint, world)
+}
+
+// VoiceProfiles 收集角色语音档案：蓝图中演化生成的档案，加上世界角色档案中的语音（同名时以角色档案为准）
+func VoiceProfiles(database db.Database, blueprint *models.NarrativeBlueprint, world *models.WorldSetting) map[string]*models.VoiceProfile {
+	profiles := make(map[string]*models.VoiceProfile, len(blueprint.VoiceProfiles))
+	for name, p := range blueprint.VoiceProfiles {
+		if !p.Empty() {
+			profiles[name] = p
+		}
+	}
+	if world != nil {
+		for _, char := range database.ListCharactersByWorld(world.ID) {
+			if char != nil && char.Name != "" && !char.NarrativeProfile.Voice.Empty() {
+				profiles[char.Name] = char.NarrativeProfile.Voice
+			}
+		}
+	}
+	return profiles
+}
+
+// checkChapterVoice 抽样检查本章台词与语音档案的一致性，结果写入章节记录
+// 失败只记录日志，不中断生成
+func (o *Orchestrator) checkChapterVoice(projectID string, blueprint *models.NarrativeBlueprint, plan models.ChapterPlan, profiles map[string]*models.VoiceProfile) {
+	if projectID == "" || len(profiles) == 0 {
+		return
+	}
+	prose := o.chapterProse(blueprint.ID, plan.Chapter)
+	if prose == "" {
+		return
+	}
+
+	issues, err := o.writer.CheckVoice(writer.VoiceCheckParams{
+		Chapter:  plan.Chapter,
+		Prose:    prose,
+		Profiles: profiles,
+	})
+	if err != nil {
+		o.log().Warn("对话语音检查失败", "chapter", plan.Chapter, "error", err)
+		return
+	}
+
+	chapter, err := o.db.GetChapterByNum(projectID, plan.Chapter)
+	if err != nil || chapter == nil {
+		return
+	}
+	chapter.VoiceIssues = issues
+	if err := o.db.SaveChapter(chapter); err != nil {
+		o.log().Warn("保存对话检查结果失败", "chapter", plan.Chapter, "error", err)
+		return
+	}
+	if len(issues) > 0 {
+		o.log().Info("台词与角色语音档案不符", "chapter", plan.Chapter, "issues", len(issues))
+	}
+}
@@ -0,0 +1,73 @@
+package orchestrator
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/webhook"
+)
+
+// registerWebhooks 保存创建项目时一并提交的 Webhook，使创作流程中的事件也能投递
+func (o *Orchestrator) registerWebhooks(projectID string, hooks []models.Webhook) {
+	for _, hook := range hooks {
+		if err := webhook.ValidateURL(hook.URL); err != nil {
+			o.log().Warn("Webhook地址不可用，已跳过", "url", hook.URL, "error", err)
+			continue
+		}
+		hook.ID = db.GenerateID("webhook")
+		hook.ProjectID = projectID
+		if hook.Secret == "" {
+			hook.Secret = webhook.NewSecret()
+		}
+		if err := o.db.SaveWebhook(&hook); err != nil {
+			o.log().Warn("保存Webhook失败", "url", hook.URL, "error", err)
+		}
+	}
+}
+
+// notify 向项目的 Webhook 投递事件，不阻塞生成
+func (o *Orchestrator) notify(projectID, event string, data interface{}) {
+	webhook.Emit(o.db, projectID, event, data)
+}
+
+// notifyChapter 投递章节生成完成事件
+func (o *Orchestrator) notifyChapter(projectID string, plan models.ChapterPlan) {
+	if projectID == "" {
+		return
+	}
+	data := map[string]interface{}{
+		"chapter": plan.Chapter,
+		"title":   plan.Title,
+	}
+	if chapter, err := o.db.GetChapterByNum(projectID, plan.Chapter); err == nil && chapter != nil {
+		data["chapter_id"] = chapter.ID
+		data["word_count"] = chapter.WordCount
+		data["continuity_issues"] = len(chapter.ContinuityIssues)
+	}
+	o.notify(projectID, models.WebhookEventChapterGenerated, data)
+}
+
+// notifyContinuity 章节与已确立的细节矛盾时投递事件
+func (o *Orchestrator) notifyContinuity(chapter *models.Chapter) {
+	if len(chapter.ContinuityIssues) == 0 {
+		return
+	}
+	o.notify(chapter.ProjectID, models.WebhookEventConsistencyFailed, map[string]interface{}{
+		"chapter":    chapter.ChapterNum,
+		"chapter_id": chapter.ID,
+		"issues":     chapter.ContinuityIssues,
+	})
+}
+
+// notifyEvolution 叙事规划完成后投递演化完成事件；使用已有蓝图时没有演化，不投递
+func (o *Orchestrator) notifyEvolution(projectID, blueprintID string, params CreationParams) {
+	if params.Options.ExistingBlueprintID != "" {
+		return
+	}
+	data := map[string]interface{}{"blueprint_id": blueprintID}
+	if blueprint, err := o.db.GetNarrativeBlueprint(blueprintID); err == nil {
+		data["chapter_count"] = len(blueprint.ChapterPlans)
+		data["scene_count"] = len(blueprint.Scenes)
+		data["warnings"] = blueprint.Warnings
+	}
+	o.notify(projectID, models.WebhookEventEvolutionCompleted, data)
+}
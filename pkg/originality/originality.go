@@ -0,0 +1,217 @@
+// Package originality 原创性检查
+// 项目可以上传不能照搬的参考文本（如同人转原创时的原作）。把参考文本的连续 n 字片段建成指纹表，
+// 逐章查找与参考文本连续相同的片段，按雷同字数占全章字数的比例计算相似度；任一章超过阈值时禁止导出。
+// 只比较文字和数字，忽略标点和空白，避免改个标点就绕过检查。
+package originality
+
+import (
+	"hash/fnv"
+	"math"
+	"sort"
+	"unicode"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+)
+
+const (
+	// DefaultNGram 默认的最短雷同字数
+	DefaultNGram = 12
+	// DefaultThreshold 默认的单章相似度阈值
+	DefaultThreshold = 0.1
+	// maxMatches 每章最多列出的雷同片段数
+	maxMatches = 20
+)
+
+// Match 与参考文本雷同的片段（位置按字符计，不含 end）
+type Match struct {
+	Start          int    `json:"start"`
+	End            int    `json:"end"`
+	Text           string `json:"text"`
+	ReferenceID    string `json:"reference_id"`
+	ReferenceTitle string `json:"reference_title"`
+	ReferenceStart int    `json:"reference_start"` // 在参考文本中的起始位置
+}
+
+// ChapterResult 单章检查结果
+type ChapterResult struct {
+	ChapterNum int     `json:"chapter_num"`
+	Title      string  `json:"title"`
+	WordCount  int     `json:"word_count"`
+	Copied     int     `json:"copied"`     // 雷同字数
+	Similarity float64 `json:"similarity"` // 雷同字数占比（0-1）
+	Exceeded   bool    `json:"exceeded"`
+	Matches    []Match `json:"matches"` // 按长度从长到短，最多 20 处
+}
+
+// Report 全书检查报告
+type Report struct {
+	References    int             `json:"references"`
+	NGram         int             `json:"ngram"`
+	Threshold     float64         `json:"threshold"`
+	MaxSimilarity float64         `json:"max_similarity"`
+	Blocked       bool            `json:"blocked"` // 有章节超过阈值，禁止导出
+	Chapters      []ChapterResult `json:"chapters"`
+}
+
+// location 片段在参考文本中的位置
+type location struct {
+	ref   int32
+	start int32 // 原文中的字符位置
+}
+
+// Corpus 参考文本的指纹表
+type Corpus struct {
+	ngram int
+	refs  []models.ReferenceText
+	grams map[uint64]location
+}
+
+// Settings 合并配置和项目设置，返回 n 和阈值
+func Settings(cfg config.OriginalityConfig, project models.OriginalitySettings) (int, float64) {
+	ngram := cfg.NGram
+	if ngram <= 0 {
+		ngram = DefaultNGram
+	}
+	threshold := project.Threshold
+	if threshold <= 0 {
+		threshold = cfg.Threshold
+	}
+	if threshold <= 0 {
+		threshold = DefaultThreshold
+	}
+	return ngram, threshold
+}
+
+// NewCorpus 由参考文本构建指纹表
+func NewCorpus(refs []models.ReferenceText, ngram int) *Corpus {
+	if ngram <= 0 {
+		ngram = DefaultNGram
+	}
+	c := &Corpus{
+		ngram: ngram,
+		refs:  refs,
+		grams: make(map[uint64]location),
+	}
+	for i, ref := range refs {
+		text, offsets := normalize([]rune(ref.Content))
+		for j := 0; j+ngram <= len(text); j++ {
+			h := hashRunes(text[j : j+ngram])
+			if _, ok := c.grams[h]; !ok {
+				c.grams[h] = location{ref: int32(i), start: int32(offsets[j])}
+			}
+		}
+	}
+	return c
+}
+
+// Empty 是否没有可比对的参考文本
+func (c *Corpus) Empty() bool {
+	return len(c.grams) == 0
+}
+
+// Check 检查一章正文
+func (c *Corpus) Check(chapter *models.Chapter, content string, threshold float64) ChapterResult {
+	runes := []rune(content)
+	text, offsets := normalize(runes)
+	result := ChapterResult{
+		ChapterNum: chapter.ChapterNum,
+		Title:      chapter.Title,
+		WordCount:  len(text),
+		Matches:    make([]Match, 0),
+	}
+	if len(text) < c.ngram || c.Empty() {
+		return result
+	}
+
+	// 标出落在雷同片段里的字，并记下每段雷同的起点对应的参考位置
+	covered := make([]bool, len(text))
+	origin := make(map[int]location)
+	for i := 0; i+c.ngram <= len(text); i++ {
+		loc, ok := c.grams[hashRunes(text[i:i+c.ngram])]
+		if !ok {
+			continue
+		}
+		if !covered[i] {
+			origin[i] = loc
+		}
+		for k := i; k < i+c.ngram; k++ {
+			covered[k] = true
+		}
+	}
+
+	for i := 0; i < len(text); {
+		if !covered[i] {
+			i++
+			continue
+		}
+		j := i
+		for j < len(text) && covered[j] {
+			j++
+		}
+		result.Copied += j - i
+		loc := origin[i]
+		start, end := offsets[i], offsets[j-1]+1
+		result.Matches = append(result.Matches, Match{
+			Start:          start,
+			End:            end,
+			Text:           string(runes[start:end]),
+			ReferenceID:    c.refs[loc.ref].ID,
+			ReferenceTitle: c.refs[loc.ref].Title,
+			ReferenceStart: int(loc.start),
+		})
+		i = j
+	}
+
+	result.Similarity = math.Round(float64(result.Copied)/float64(len(text))*1000) / 1000
+	result.Exceeded = result.Similarity > threshold
+	sort.SliceStable(result.Matches, func(i, j int) bool {
+		return result.Matches[i].End-result.Matches[i].Start > result.Matches[j].End-result.Matches[j].Start
+	})
+	if len(result.Matches) > maxMatches {
+		result.Matches = result.Matches[:maxMatches]
+	}
+	return result
+}
+
+// Scan 检查全书，prose 返回章节正文
+func Scan(corpus *Corpus, chapters []*models.Chapter, prose func(*models.Chapter) string, threshold float64) *Report {
+	report := &Report{
+		References: len(corpus.refs),
+		NGram:      corpus.ngram,
+		Threshold:  threshold,
+		Chapters:   make([]ChapterResult, 0, len(chapters)),
+	}
+	for _, ch := range chapters {
+		content := prose(ch)
+		if content == "" {
+			continue
+		}
+		result := corpus.Check(ch, content, threshold)
+		report.Chapters = append(report.Chapters, result)
+		report.MaxSimilarity = math.Max(report.MaxSimilarity, result.Similarity)
+		if result.Exceeded {
+			report.Blocked = true
+		}
+	}
+	return report
+}
+
+// normalize 只保留文字和数字并转小写，同时返回每个字在原文中的位置
+func normalize(runes []rune) ([]rune, []int) {
+	text := make([]rune, 0, len(runes))
+	offsets := make([]int, 0, len(runes))
+	for i, r := range runes {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			text = append(text, unicode.ToLower(r))
+			offsets = append(offsets, i)
+		}
+	}
+	return text, offsets
+}
+
+func hashRunes(runes []rune) uint64 {
+	h := fnv.New64a()
+	h.Write([]byte(string(runes)))
+	return h.Sum64()
+}
@@ -0,0 +1,61 @@
+package pacing
+
+import (
+	"fmt"
+
+	"github.com/xlei/xupu/pkg/config"
+)
+
+// 基线来源
+const (
+	SourceConfig    = "config"
+	SourceAggregate = "aggregate"
+)
+
+// DefaultGenre 未配置类型基线时使用的兜底类型
+const DefaultGenre = "default"
+
+// aggregatePoints 聚合基线的采样点数
+const aggregatePoints = 10
+
+// ConfigBaseline 从配置中获取类型基线，未配置时回退到default
+func ConfigBaseline(cfg *config.PacingConfig, genre string) ([]float64, string, error) {
+	if cfg == nil {
+		return nil, "", fmt.Errorf("未配置节奏基线")
+	}
+	if curve, ok := cfg.Baselines[genre]; ok && len(curve) > 0 {
+		return curve, genre, nil
+	}
+	if curve, ok := cfg.Baselines[DefaultGenre]; ok && len(curve) > 0 {
+		return curve, DefaultGenre, nil
+	}
+	return nil, "", fmt.Errorf("未找到类型 %s 的节奏基线", genre)
+}
+
+// AggregateBaseline 由同类型项目的张力曲线聚合出基线
+// 只使用归一化后的曲线数值，不携带任何项目信息
+func AggregateBaseline(curves [][]float64) []float64 {
+	baseline := make([]float64, aggregatePoints)
+	if len(curves) == 0 {
+		return baseline
+	}
+
+	for _, curve := range curves {
+		for i, v := range Resample(curve, aggregatePoints) {
+			baseline[i] += v
+		}
+	}
+	for i := range baseline {
+		baseline[i] = round2(baseline[i] / float64(len(curves)))
+	}
+	return baseline
+}
+
+// TensionCurve 提取归一化张力曲线
+func TensionCurve(metrics []*ChapterMetrics) []float64 {
+	curve := make([]float64, 0, len(metrics))
+	for _, m := range metrics {
+		curve = append(curve, m.Tension)
+	}
+	return curve
+}
@@ -0,0 +1,264 @@
+// Package pacing 节奏分析 - 章节张力/情感曲线与类型基线对比
+package pacing
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// 张力词典：出现越密集，章节张力越高
+var tensionWords = []string{
+	"杀", "血", "战", "斗", "怒", "吼", "逃", "追", "危", "险",
+	"死", "伤", "痛", "惊", "恐", "威胁", "对峙", "爆发", "崩溃", "绝望",
+	"背叛", "陷阱", "刀", "剑", "枪", "火", "冲突", "质问", "咬牙", "颤抖",
+}
+
+// 冲突词典：用于判断章节是否触及任何冲突
+var conflictWords = []string{
+	"冲突", "争", "斗", "战", "敌", "对手", "仇", "反对", "阻止", "威胁",
+	"质问", "争执", "对峙", "背叛", "报复", "矛盾", "较量", "交锋", "算计", "逼",
+}
+
+// 情感词典
+var (
+	positiveWords = []string{"笑", "喜", "欢", "暖", "温柔", "希望", "安心", "感激", "幸福", "轻松", "胜利", "拥抱"}
+	negativeWords = []string{"哭", "泪", "悲", "恨", "怒", "怕", "恐", "绝望", "痛", "孤独", "失败", "冷"}
+)
+
+// 偏差原因
+const (
+	CauseEmpty         = "章节尚无正文"
+	CauseNoConflict    = "未触及任何冲突"
+	CauseAllDialogue   = "几乎全是对话"
+	CauseNoDialogue    = "缺少对话，以叙述为主"
+	CauseTooShort      = "篇幅明显偏短"
+	CauseAboveBaseline = "张力明显高于类型基线"
+	CauseBelowBaseline = "张力明显低于类型基线"
+)
+
+// ChapterMetrics 单章节奏指标
+type ChapterMetrics struct {
+	ChapterNum      int     `json:"chapter_num"`
+	Title           string  `json:"title"`
+	WordCount       int     `json:"word_count"`
+	RawTension      float64 `json:"raw_tension"`      // 每千字张力词密度
+	Tension         float64 `json:"tension"`          // 项目内归一化张力（0-1）
+	Sentiment       float64 `json:"sentiment"`        // 情感倾向（-1到1）
+	DialogueRatio   float64 `json:"dialogue_ratio"`   // 对话占比（0-1）
+	ConflictTouches int     `json:"conflict_touches"` // 冲突词出现次数
+}
+
+// Deviation 偏离基线的章节
+type Deviation struct {
+	ChapterNum int      `json:"chapter_num"`
+	Title      string   `json:"title"`
+	Measured   float64  `json:"measured"`
+	Baseline   float64  `json:"baseline"`
+	Delta      float64  `json:"delta"` // measured - baseline
+	Causes     []string `json:"causes"`
+}
+
+// Report 节奏对比报告
+type Report struct {
+	Genre          string            `json:"genre"`
+	BaselineSource string            `json:"baseline_source"` // config, aggregate
+	Chapters       []*ChapterMetrics `json:"chapters"`
+	Baseline       []float64         `json:"baseline"` // 按章节重采样后的基线
+	MeanDeviation  float64           `json:"mean_deviation"`
+	Deviations     []*Deviation      `json:"deviations"` // 按偏差从大到小排序
+}
+
+// MeasureChapter 计算单章节奏指标
+func MeasureChapter(chapter *models.Chapter) *ChapterMetrics {
+	content := chapter.Content
+	total := utf8.RuneCountInString(content)
+
+	m := &ChapterMetrics{
+		ChapterNum: chapter.ChapterNum,
+		Title:      chapter.Title,
+		WordCount:  total,
+	}
+	if total == 0 {
+		return m
+	}
+
+	tension := countWords(content, tensionWords)
+	tension += strings.Count(content, "！") + strings.Count(content, "!")
+	m.RawTension = float64(tension) * 1000 / float64(total)
+
+	m.ConflictTouches = countWords(content, conflictWords)
+	m.DialogueRatio = dialogueRatio(content, total)
+
+	pos := countWords(content, positiveWords)
+	neg := countWords(content, negativeWords)
+	if pos+neg > 0 {
+		m.Sentiment = float64(pos-neg) / float64(pos+neg)
+	}
+
+	return m
+}
+
+// MeasureCurve 计算项目的张力曲线，章节按章节号排序，张力归一化到0-1
+func MeasureCurve(chapters []*models.Chapter) []*ChapterMetrics {
+	sorted := make([]*models.Chapter, len(chapters))
+	copy(sorted, chapters)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ChapterNum < sorted[j].ChapterNum
+	})
+
+	metrics := make([]*ChapterMetrics, 0, len(sorted))
+	for _, ch := range sorted {
+		metrics = append(metrics, MeasureChapter(ch))
+	}
+
+	// 按项目内最大/最小值归一化，比较的是曲线形状而不是绝对值
+	lo, hi := math.Inf(1), math.Inf(-1)
+	for _, m := range metrics {
+		lo = math.Min(lo, m.RawTension)
+		hi = math.Max(hi, m.RawTension)
+	}
+	for _, m := range metrics {
+		if hi > lo {
+			m.Tension = (m.RawTension - lo) / (hi - lo)
+		}
+	}
+
+	return metrics
+}
+
+// Compare 将项目曲线与基线对比，返回偏差最大的top个章节
+func Compare(metrics []*ChapterMetrics, baseline []float64, threshold float64, top int) ([]float64, float64, []*Deviation) {
+	if len(metrics) == 0 {
+		return nil, 0, nil
+	}
+
+	resampled := Resample(baseline, len(metrics))
+	medianWords := medianWordCount(metrics)
+
+	deviations := make([]*Deviation, 0, len(metrics))
+	sum := 0.0
+	for i, m := range metrics {
+		delta := m.Tension - resampled[i]
+		sum += math.Abs(delta)
+
+		if math.Abs(delta) < threshold {
+			continue
+		}
+		deviations = append(deviations, &Deviation{
+			ChapterNum: m.ChapterNum,
+			Title:      m.Title,
+			Measured:   round2(m.Tension),
+			Baseline:   round2(resampled[i]),
+			Delta:      round2(delta),
+			Causes:     diagnose(m, delta, medianWords),
+		})
+	}
+
+	sort.SliceStable(deviations, func(i, j int) bool {
+		return math.Abs(deviations[i].Delta) > math.Abs(deviations[j].Delta)
+	})
+	if top > 0 && len(deviations) > top {
+		deviations = deviations[:top]
+	}
+
+	return resampled, round2(sum / float64(len(metrics))), deviations
+}
+
+// Resample 将曲线线性插值到n个点
+func Resample(curve []float64, n int) []float64 {
+	result := make([]float64, n)
+	if n == 0 || len(curve) == 0 {
+		return result
+	}
+	if len(curve) == 1 || n == 1 {
+		for i := range result {
+			result[i] = curve[0]
+		}
+		return result
+	}
+
+	for i := 0; i < n; i++ {
+		pos := float64(i) * float64(len(curve)-1) / float64(n-1)
+		left := int(math.Floor(pos))
+		if left >= len(curve)-1 {
+			result[i] = curve[len(curve)-1]
+			continue
+		}
+		frac := pos - float64(left)
+		result[i] = curve[left]*(1-frac) + curve[left+1]*frac
+	}
+	return result
+}
+
+// diagnose 推断偏差的可能原因
+func diagnose(m *ChapterMetrics, delta float64, medianWords int) []string {
+	if m.WordCount == 0 {
+		return []string{CauseEmpty}
+	}
+
+	causes := make([]string, 0)
+	if m.ConflictTouches == 0 {
+		causes = append(causes, CauseNoConflict)
+	}
+	if m.DialogueRatio >= 0.8 {
+		causes = append(causes, CauseAllDialogue)
+	} else if m.DialogueRatio == 0 && delta < 0 {
+		causes = append(causes, CauseNoDialogue)
+	}
+	if medianWords > 0 && m.WordCount*2 < medianWords {
+		causes = append(causes, CauseTooShort)
+	}
+
+	if delta > 0 {
+		causes = append(causes, CauseAboveBaseline)
+	} else {
+		causes = append(causes, CauseBelowBaseline)
+	}
+	return causes
+}
+
+// dialogueRatio 计算引号内文字占比
+func dialogueRatio(content string, total int) float64 {
+	inQuote := false
+	quoted := 0
+	for _, r := range content {
+		switch r {
+		case '“', '「':
+			inQuote = true
+			continue
+		case '”', '」':
+			inQuote = false
+			continue
+		}
+		if inQuote {
+			quoted++
+		}
+	}
+	return float64(quoted) / float64(total)
+}
+
+// countWords 统计词典中词语的出现总次数
+func countWords(content string, words []string) int {
+	count := 0
+	for _, w := range words {
+		count += strings.Count(content, w)
+	}
+	return count
+}
+
+func medianWordCount(metrics []*ChapterMetrics) int {
+	counts := make([]int, 0, len(metrics))
+	for _, m := range metrics {
+		counts = append(counts, m.WordCount)
+	}
+	sort.Ints(counts)
+	return counts[len(counts)/2]
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,299 @@
+package pacing
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// 章节规划的结构问题
+const (
+	IssueSaggingMiddle   = "sagging_middle"   // 中段塌陷
+	IssuePrematureClimax = "premature_climax" // 高潮过早
+	IssueLowEnergyRun    = "low_energy_run"   // 连续低能量章节
+)
+
+// 调整建议类型
+const (
+	SuggestReorder       = "reorder"        // 调整章节顺序
+	SuggestSceneType     = "scene_type"     // 更换场景类型
+	SuggestRaiseConflict = "raise_conflict" // 提高冲突强度
+)
+
+// sceneEnergy 各场景类型的能量（0-1）
+var sceneEnergy = map[string]float64{
+	"动作": 1.0,
+	"对话": 0.6,
+	"内心": 0.45,
+	"描写": 0.3,
+	"过渡": 0.2,
+}
+
+// 张力构成权重：冲突强度为主，场景类型次之，篇幅越短节奏越紧
+const (
+	weightConflict = 0.6
+	weightScene    = 0.3
+	weightBrevity  = 0.1
+)
+
+// lowEnergyThreshold 低于该张力视为低能量章节
+const lowEnergyThreshold = 0.35
+
+// minStructureChapters 判断结构问题所需的最少章节数
+const minStructureChapters = 4
+
+// PlanChapter 单章规划的节奏指标
+type PlanChapter struct {
+	Chapter           int      `json:"chapter"`
+	Title             string   `json:"title"`
+	ConflictIntensity int      `json:"conflict_intensity"` // 0-100
+	SceneTypes        []string `json:"scene_types,omitempty"`
+	SceneEnergy       float64  `json:"scene_energy"` // 场景类型平均能量（0-1）
+	WordCount         int      `json:"word_count"`
+	Tension           float64  `json:"tension"` // 综合张力（0-1）
+}
+
+// PlanIssue 章节规划的结构问题
+type PlanIssue struct {
+	Kind     string `json:"kind"` // sagging_middle/premature_climax/low_energy_run
+	Chapters []int  `json:"chapters"`
+	Message  string `json:"message"`
+}
+
+// PlanSuggestion 调整建议
+type PlanSuggestion struct {
+	Kind          string `json:"kind"` // reorder/scene_type/raise_conflict
+	Chapter       int    `json:"chapter"`
+	TargetChapter int    `json:"target_chapter,omitempty"` // reorder：与之对调的章节
+	Scene         int    `json:"scene,omitempty"`          // scene_type：场景序号（从1开始）
+	From          string `json:"from,omitempty"`
+	To            string `json:"to,omitempty"`
+	Message       string `json:"message"`
+}
+
+// PlanReport 章节规划节奏分析
+type PlanReport struct {
+	Chapters    []*PlanChapter   `json:"chapters"`
+	Curve       []float64        `json:"curve"`        // 张力曲线
+	PeakChapter int              `json:"peak_chapter"` // 张力最高的章节
+	Issues      []PlanIssue      `json:"issues"`
+	Suggestions []PlanSuggestion `json:"suggestions"`
+}
+
+// AnalyzePlan 由冲突强度、场景类型和目标字数计算章节规划的张力曲线，检测结构问题并给出调整建议
+func AnalyzePlan(plans []models.ChapterPlan) *PlanReport {
+	report := &PlanReport{
+		Chapters:    make([]*PlanChapter, 0, len(plans)),
+		Curve:       make([]float64, 0, len(plans)),
+		Issues:      []PlanIssue{},
+		Suggestions: []PlanSuggestion{},
+	}
+	if len(plans) == 0 {
+		return report
+	}
+
+	sorted := append([]models.ChapterPlan(nil), plans...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Chapter < sorted[j].Chapter })
+
+	median := medianPlanWords(sorted)
+	for _, p := range sorted {
+		ch := &PlanChapter{
+			Chapter:           p.Chapter,
+			Title:             p.Title,
+			ConflictIntensity: p.ConflictIntensity,
+			SceneTypes:        p.SceneTypes,
+			SceneEnergy:       round2(averageSceneEnergy(p.SceneTypes)),
+			WordCount:         p.WordCount,
+		}
+		brevity := 0.5
+		if p.WordCount > 0 && median > 0 {
+			brevity = min(1, float64(median)/float64(p.WordCount))
+		}
+		conflict := float64(max(0, min(100, p.ConflictIntensity))) / 100
+		ch.Tension = round2(weightConflict*conflict + weightScene*ch.SceneEnergy + weightBrevity*brevity)
+		report.Chapters = append(report.Chapters, ch)
+		report.Curve = append(report.Curve, ch.Tension)
+	}
+
+	peak := 0
+	for i, t := range report.Curve {
+		if t > report.Curve[peak] {
+			peak = i
+		}
+	}
+	report.PeakChapter = report.Chapters[peak].Chapter
+
+	if len(report.Chapters) >= minStructureChapters {
+		report.checkPrematureClimax(peak)
+		report.checkSaggingMiddle()
+	}
+	report.checkLowEnergyRuns()
+	return report
+}
+
+// checkPrematureClimax 张力峰值落在全书前60%视为高潮过早，建议与终幕中张力最高的章节对调
+func (r *PlanReport) checkPrematureClimax(peak int) {
+	n := len(r.Chapters)
+	finalStart := n * 6 / 10
+	if peak >= finalStart {
+		return
+	}
+
+	target := finalStart
+	for i := finalStart; i < n; i++ {
+		if r.Curve[i] > r.Curve[target] {
+			target = i
+		}
+	}
+	p, t := r.Chapters[peak], r.Chapters[target]
+	r.Issues = append(r.Issues, PlanIssue{
+		Kind:     IssuePrematureClimax,
+		Chapters: []int{p.Chapter},
+		Message:  fmt.Sprintf("张力峰值出现在第%d章（全书%d章），高潮过早，后段难以再升级", p.Chapter, n),
+	})
+	r.Suggestions = append(r.Suggestions, PlanSuggestion{
+		Kind:          SuggestReorder,
+		Chapter:       p.Chapter,
+		TargetChapter: t.Chapter,
+		Message:       fmt.Sprintf("将第%d章与第%d章对调，或压低第%d章的冲突强度，把最激烈的对抗留到终幕", p.Chapter, t.Chapter, p.Chapter),
+	})
+}
+
+// checkSaggingMiddle 中间三分之一的平均张力低于首尾两段时视为中段塌陷，建议在中段最弱的章节加入逆转
+func (r *PlanReport) checkSaggingMiddle() {
+	n := len(r.Chapters)
+	first, last := n/3, n-n/3
+	head, middle, tail := mean(r.Curve[:first]), mean(r.Curve[first:last]), mean(r.Curve[last:])
+	if middle >= min(head, tail)-0.05 {
+		return
+	}
+
+	weakest := first
+	chapters := make([]int, 0, last-first)
+	for i := first; i < last; i++ {
+		chapters = append(chapters, r.Chapters[i].Chapter)
+		if r.Curve[i] < r.Curve[weakest] {
+			weakest = i
+		}
+	}
+	r.Issues = append(r.Issues, PlanIssue{
+		Kind:     IssueSaggingMiddle,
+		Chapters: chapters,
+		Message:  fmt.Sprintf("中段平均张力%.2f，低于开篇%.2f和结尾%.2f，中段塌陷", middle, head, tail),
+	})
+	w := r.Chapters[weakest]
+	r.Suggestions = append(r.Suggestions, PlanSuggestion{
+		Kind:    SuggestRaiseConflict,
+		Chapter: w.Chapter,
+		Message: fmt.Sprintf("在第%d章加入中点逆转或新的威胁，提高冲突强度（当前%d）", w.Chapter, w.ConflictIntensity),
+	})
+}
+
+// checkLowEnergyRuns 连续两章及以上低能量时，建议把后一章能量最低的场景换成动作或对话场景
+func (r *PlanReport) checkLowEnergyRuns() {
+	run := make([]int, 0)
+	flush := func() {
+		if len(run) >= 2 {
+			chapters := make([]int, 0, len(run))
+			for _, i := range run {
+				chapters = append(chapters, r.Chapters[i].Chapter)
+			}
+			r.Issues = append(r.Issues, PlanIssue{
+				Kind:     IssueLowEnergyRun,
+				Chapters: chapters,
+				Message:  fmt.Sprintf("第%d-%d章连续%d章张力低于%.2f，读者容易流失", chapters[0], chapters[len(chapters)-1], len(run), lowEnergyThreshold),
+			})
+			r.Suggestions = append(r.Suggestions, lowEnergySuggestion(r.Chapters[run[1]]))
+		}
+		run = run[:0]
+	}
+	for i, t := range r.Curve {
+		if t < lowEnergyThreshold {
+			run = append(run, i)
+			continue
+		}
+		flush()
+	}
+	flush()
+}
+
+// lowEnergySuggestion 有场景类型时建议替换能量最低的场景，否则建议提高冲突强度
+func lowEnergySuggestion(ch *PlanChapter) PlanSuggestion {
+	if len(ch.SceneTypes) == 0 {
+		return PlanSuggestion{
+			Kind:    SuggestRaiseConflict,
+			Chapter: ch.Chapter,
+			Message: fmt.Sprintf("第%d章提高冲突强度，或加入一场正面交锋", ch.Chapter),
+		}
+	}
+
+	weakest := 0
+	hasAction := false
+	for i, t := range ch.SceneTypes {
+		if energyOf(t) < energyOf(ch.SceneTypes[weakest]) {
+			weakest = i
+		}
+		if t == "动作" {
+			hasAction = true
+		}
+	}
+	to := "动作"
+	if hasAction {
+		to = "对话"
+	}
+	from := ch.SceneTypes[weakest]
+	return PlanSuggestion{
+		Kind:    SuggestSceneType,
+		Chapter: ch.Chapter,
+		Scene:   weakest + 1,
+		From:    from,
+		To:      to,
+		Message: fmt.Sprintf("第%d章第%d个场景由「%s」改为「%s」，打破连续的低能量", ch.Chapter, weakest+1, from, to),
+	}
+}
+
+// averageSceneEnergy 场景类型平均能量，没有场景类型时取中值
+func averageSceneEnergy(types []string) float64 {
+	if len(types) == 0 {
+		return 0.5
+	}
+	total := 0.0
+	for _, t := range types {
+		total += energyOf(t)
+	}
+	return total / float64(len(types))
+}
+
+// energyOf 场景类型能量，未知类型取中值
+func energyOf(sceneType string) float64 {
+	if e, ok := sceneEnergy[sceneType]; ok {
+		return e
+	}
+	return 0.5
+}
+
+func medianPlanWords(plans []models.ChapterPlan) int {
+	counts := make([]int, 0, len(plans))
+	for _, p := range plans {
+		if p.WordCount > 0 {
+			counts = append(counts, p.WordCount)
+		}
+	}
+	if len(counts) == 0 {
+		return 0
+	}
+	sort.Ints(counts)
+	return counts[len(counts)/2]
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	total := 0.0
+	for _, v := range values {
+		total += v
+	}
+	return total / float64(len(values))
+}
@@ -0,0 +1,220 @@
+// Package progress 写作进度统计
+// 由章节每天的字数记录（models.WritingDay）汇总每天写了多少字、完成了几章，
+// 结合大纲规划的章节数和目标字数，按最近一段时间的日均字数估算完稿日期。
+package progress
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+const (
+	// DefaultDays 默认列出的最近天数
+	DefaultDays = 30
+	// DefaultWindow 默认计算写作速度的最近天数
+	DefaultWindow = 14
+	// fallbackChapterWords 没有目标字数也没有已完成章节可参照时的每章字数
+	fallbackChapterWords = 3000
+	dateLayout           = "2006-01-02"
+)
+
+// ChapterStat 单章进度
+type ChapterStat struct {
+	ChapterNum int    `json:"chapter_num"`
+	Title      string `json:"title"`
+	Status     string `json:"status"` // draft, completed, planned（尚未创建）
+	WordCount  int    `json:"word_count"`
+	Target     int    `json:"target"`
+	Recent     int    `json:"recent"`              // 统计窗口内的净增字数
+	LastDate   string `json:"last_date,omitempty"` // 最近一次有字数记录的日期
+}
+
+// DayStat 单日统计
+type DayStat struct {
+	Date      string `json:"date"`
+	Words     int    `json:"words"`     // 当天净增字数
+	Chapters  int    `json:"chapters"`  // 当天修改过的章节数
+	Completed int    `json:"completed"` // 当天完成的章节数
+}
+
+// Stats 项目写作统计
+type Stats struct {
+	TotalWords        int     `json:"total_words"`
+	TargetWords       int     `json:"target_words"`
+	ChaptersPlanned   int     `json:"chapters_planned"`
+	ChaptersWritten   int     `json:"chapters_written"` // 有正文的章节数
+	ChaptersCompleted int     `json:"chapters_completed"`
+	Progress          float64 `json:"progress"` // 完成章节数占规划章节数（0-1）
+
+	Window         int     `json:"window"`        // 计算速度的天数
+	WordsPerDay    float64 `json:"words_per_day"` // 窗口内日均净增字数（含没写的日子）
+	ActiveDays     int     `json:"active_days"`   // 窗口内有写作的天数
+	RemainingWords int     `json:"remaining_words"`
+	DaysLeft       int     `json:"days_left,omitempty"`
+	EstimatedDate  string  `json:"estimated_date,omitempty"` // 预计完稿日期；已完稿或最近没有进展时为空
+	Finished       bool    `json:"finished"`
+
+	Chapters []ChapterStat `json:"chapters"`
+	Days     []DayStat     `json:"days"` // 最近若干天，按日期升序，没写的日子字数为 0
+}
+
+// Options 统计参数
+type Options struct {
+	Days    int                     // 列出的最近天数
+	Window  int                     // 计算速度的最近天数
+	Targets models.WordCountTargets // 章节目标字数，Load 时取项目设置
+	Now     time.Time
+}
+
+// Compute 汇总项目的写作统计；plans 为大纲的章节规划，records 为全部字数记录
+func Compute(chapters []*models.Chapter, plans []models.ChapterPlan, records []models.WritingDay, opts Options) *Stats {
+	if opts.Days <= 0 {
+		opts.Days = DefaultDays
+	}
+	if opts.Window <= 0 {
+		opts.Window = DefaultWindow
+	}
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+	today := opts.Now.Format(dateLayout)
+	windowFrom := opts.Now.AddDate(0, 0, -(opts.Window - 1)).Format(dateLayout)
+
+	stats := &Stats{Window: opts.Window, Chapters: make([]ChapterStat, 0), Days: make([]DayStat, 0, opts.Days)}
+
+	// 按日期汇总；章节从未完成变为完成的那天记为完成日
+	sort.SliceStable(records, func(i, j int) bool { return records[i].Date < records[j].Date })
+	daily := map[string]*DayStat{}
+	recent := map[string]int{}
+	lastDate := map[string]string{}
+	wasCompleted := map[string]bool{}
+	for _, r := range records {
+		day := daily[r.Date]
+		if day == nil {
+			day = &DayStat{Date: r.Date}
+			daily[r.Date] = day
+		}
+		day.Words += r.Written()
+		day.Chapters++
+		if r.Completed && !wasCompleted[r.ChapterID] {
+			day.Completed++
+		}
+		wasCompleted[r.ChapterID] = r.Completed
+		lastDate[r.ChapterID] = r.Date
+		if r.Date >= windowFrom && r.Date <= today {
+			recent[r.ChapterID] += r.Written()
+		}
+	}
+
+	for d := 0; d < opts.Days; d++ {
+		date := opts.Now.AddDate(0, 0, d-(opts.Days-1)).Format(dateLayout)
+		if day := daily[date]; day != nil {
+			stats.Days = append(stats.Days, *day)
+		} else {
+			stats.Days = append(stats.Days, DayStat{Date: date})
+		}
+	}
+
+	windowWords := 0
+	for date, day := range daily {
+		if date >= windowFrom && date <= today {
+			windowWords += day.Words
+			if day.Words != 0 {
+				stats.ActiveDays++
+			}
+		}
+	}
+	stats.WordsPerDay = math.Round(float64(windowWords)/float64(opts.Window)*10) / 10
+
+	// 章节：已创建的章节加上规划中尚未创建的章节
+	byNum := map[int]*models.Chapter{}
+	completedWords, completedCount := 0, 0
+	for _, ch := range chapters {
+		byNum[ch.ChapterNum] = ch
+		stats.TotalWords += ch.WordCount
+		if ch.WordCount > 0 {
+			stats.ChaptersWritten++
+		}
+		if ch.Status == models.ChapterStatusCompleted {
+			stats.ChaptersCompleted++
+			completedWords += ch.WordCount
+			completedCount++
+		}
+	}
+	fallback := fallbackChapterWords
+	if completedCount > 0 {
+		fallback = completedWords / completedCount
+	}
+
+	planned := map[int]models.ChapterPlan{}
+	nums := make([]int, 0, len(plans)+len(chapters))
+	for _, p := range plans {
+		if _, ok := planned[p.Chapter]; !ok {
+			nums = append(nums, p.Chapter)
+		}
+		planned[p.Chapter] = p
+	}
+	for num := range byNum {
+		if _, ok := planned[num]; !ok {
+			nums = append(nums, num)
+		}
+	}
+	sort.Ints(nums)
+
+	stats.ChaptersPlanned = len(plans)
+	if stats.ChaptersPlanned == 0 {
+		stats.ChaptersPlanned = len(chapters)
+	}
+	for _, num := range nums {
+		plan, isPlanned := planned[num]
+		target := opts.Targets.For(num, plan.WordCount)
+		if target <= 0 {
+			target = fallback
+		}
+		cs := ChapterStat{ChapterNum: num, Title: plan.Title, Status: "planned", Target: target}
+		if ch := byNum[num]; ch != nil {
+			cs.Title = ch.Title
+			cs.Status = string(ch.Status)
+			cs.WordCount = ch.WordCount
+			cs.Recent = recent[ch.ID]
+			cs.LastDate = lastDate[ch.ID]
+		}
+		if isPlanned || len(plans) == 0 {
+			stats.TargetWords += target
+			if cs.Status != string(models.ChapterStatusCompleted) && target > cs.WordCount {
+				stats.RemainingWords += target - cs.WordCount
+			}
+		}
+		stats.Chapters = append(stats.Chapters, cs)
+	}
+
+	if stats.ChaptersPlanned > 0 {
+		stats.Progress = math.Round(float64(stats.ChaptersCompleted)/float64(stats.ChaptersPlanned)*1000) / 1000
+	}
+	stats.Finished = stats.ChaptersPlanned > 0 && stats.ChaptersCompleted >= stats.ChaptersPlanned
+	if !stats.Finished && stats.RemainingWords > 0 && stats.WordsPerDay > 0 {
+		stats.DaysLeft = int(math.Ceil(float64(stats.RemainingWords) / stats.WordsPerDay))
+		stats.EstimatedDate = opts.Now.AddDate(0, 0, stats.DaysLeft).Format(dateLayout)
+	}
+	return stats
+}
+
+// Load 从数据库读取项目的章节、大纲和字数记录并汇总
+func Load(database db.Database, project *models.Project, opts Options) (*Stats, error) {
+	records, err := database.ListWritingDays(project.ID, "")
+	if err != nil {
+		return nil, err
+	}
+	var plans []models.ChapterPlan
+	if project.NarrativeID != "" {
+		if bp, err := database.GetNarrativeBlueprint(project.NarrativeID); err == nil && bp != nil {
+			plans = bp.ChapterPlans
+		}
+	}
+	opts.Targets = project.WordCountTargets
+	return Compute(database.ListChaptersByProject(project.ID), plans, records, opts), nil
+}
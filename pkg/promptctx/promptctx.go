@@ -0,0 +1,149 @@
+// Package promptctx 提示词上下文 - 以可组合的提供者构建提示词中的设定段落
+//
+// 世界背景、地理、文明、历史、角色、冲突等上下文各由一个 Provider 渲染，
+// 各模块用 Composer 按需组合并标注优先级，得到的段落交给 ctxbudget.Fit 按预算拼接。
+// 同一类上下文在所有提示词中格式一致；新增上下文类型只需实现 Provider，
+// 可通过 Include/Exclude 按名称选择启用哪些段落
+package promptctx
+
+import (
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/ctxbudget"
+)
+
+// Context 构建上下文段落的数据来源
+type Context struct {
+	World      *models.WorldSetting
+	Characters []Character
+	Conflicts  []Conflict
+}
+
+// Character 提示词中引用的角色
+type Character struct {
+	Name string
+	Role string
+	Want string // 表层欲望
+	Need string // 深层需求
+	Fear string
+}
+
+// Conflict 提示词中引用的冲突线
+type Conflict struct {
+	Type      string
+	Question  string
+	Intensity int
+	Resolved  bool
+	Path      []string // 演化路径各阶段的描述
+}
+
+// Provider 上下文提供者，Name 用于 Include/Exclude 选择和预算日志
+// Render 无内容时返回空字符串
+type Provider interface {
+	Name() string
+	Render(c *Context) string
+}
+
+// providerFunc 以函数实现的提供者
+type providerFunc struct {
+	name   string
+	render func(*Context) string
+}
+
+func (p providerFunc) Name() string             { return p.name }
+func (p providerFunc) Render(c *Context) string { return p.render(c) }
+
+// NewProvider 以函数创建提供者，用于接入新的上下文类型
+func NewProvider(name string, render func(*Context) string) Provider {
+	return providerFunc{name: name, render: render}
+}
+
+// Option 组合选项
+type Option func(*Composer)
+
+// Include 只启用指定名称的提供者，未指定时全部启用
+func Include(names ...string) Option {
+	return func(c *Composer) {
+		if c.include == nil {
+			c.include = make(map[string]bool, len(names))
+		}
+		for _, n := range names {
+			c.include[n] = true
+		}
+	}
+}
+
+// Exclude 禁用指定名称的提供者，优先于 Include
+func Exclude(names ...string) Option {
+	return func(c *Composer) {
+		if c.exclude == nil {
+			c.exclude = make(map[string]bool, len(names))
+		}
+		for _, n := range names {
+			c.exclude[n] = true
+		}
+	}
+}
+
+// Composer 按顺序累积提示词段落：提供者渲染的上下文和调用方自己写的文本
+type Composer struct {
+	ctx      *Context
+	include  map[string]bool
+	exclude  map[string]bool
+	sections []ctxbudget.Section
+}
+
+// Compose 创建组合器
+func Compose(ctx *Context, opts ...Option) *Composer {
+	c := &Composer{ctx: ctx}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Enabled 指定名称的提供者是否启用
+func (c *Composer) Enabled(name string) bool {
+	if c.exclude[name] {
+		return false
+	}
+	return c.include == nil || c.include[name]
+}
+
+// Add 追加提供者渲染的段落，未启用或无内容时跳过
+func (c *Composer) Add(p Provider, priority ctxbudget.Priority) *Composer {
+	if c.ctx == nil || !c.Enabled(p.Name()) {
+		return c
+	}
+	return c.Text(p.Name(), p.Render(c.ctx), priority)
+}
+
+// Text 追加一段文本，空文本忽略
+func (c *Composer) Text(name, text string, priority ctxbudget.Priority) *Composer {
+	if text != "" {
+		c.sections = append(c.sections, ctxbudget.Section{Name: name, Text: text, Priority: priority})
+	}
+	return c
+}
+
+// Cut 把构建器中已写入的内容作为一段收下并清空构建器
+func (c *Composer) Cut(b *strings.Builder, name string, priority ctxbudget.Priority) *Composer {
+	c.Text(name, b.String(), priority)
+	b.Reset()
+	return c
+}
+
+// Sections 已累积的段落
+func (c *Composer) Sections() []ctxbudget.Section {
+	return c.sections
+}
+
+// String 不做预算控制直接拼接全部段落
+func (c *Composer) String() string {
+	var sb strings.Builder
+	for _, s := range c.sections {
+		sb.WriteString(s.Text)
+	}
+	return sb.String()
+}
@@ -0,0 +1,79 @@
+package promptctx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// CharacterOptions 角色列表的格式
+type CharacterOptions struct {
+	Heading string // 段标题，默认“主要角色”
+	Full    bool   // 列出欲望、需求和恐惧，否则只列欲望
+	Limit   int    // 最多列出的角色数，<=0 不限制
+}
+
+// Characters 角色列表
+func Characters(opts CharacterOptions) Provider {
+	if opts.Heading == "" {
+		opts.Heading = "主要角色"
+	}
+	return NewProvider(NameCharacters, func(c *Context) string {
+		if len(c.Characters) == 0 {
+			return ""
+		}
+		var prompt strings.Builder
+		prompt.WriteString(fmt.Sprintf("\n## %s\n", opts.Heading))
+		for i, ch := range c.Characters {
+			if opts.Limit > 0 && i >= opts.Limit {
+				prompt.WriteString(fmt.Sprintf("... 还有%d个角色\n", len(c.Characters)-i))
+				break
+			}
+			prompt.WriteString("- " + ch.Name)
+			if ch.Role != "" {
+				prompt.WriteString(fmt.Sprintf(" (%s)", ch.Role))
+			}
+			prompt.WriteString(fmt.Sprintf(": 欲望=%s", ch.Want))
+			if opts.Full {
+				prompt.WriteString(fmt.Sprintf(", 需求=%s, 恐惧=%s", ch.Need, ch.Fear))
+			}
+			prompt.WriteString("\n")
+		}
+		return prompt.String()
+	})
+}
+
+// ConflictOptions 冲突列表的格式
+type ConflictOptions struct {
+	Heading    string // 段标题，默认“核心冲突”
+	Unresolved bool   // 只列出未解决的冲突
+	Detail     bool   // 列出强度和演化路径
+}
+
+// Conflicts 冲突列表
+func Conflicts(opts ConflictOptions) Provider {
+	if opts.Heading == "" {
+		opts.Heading = "核心冲突"
+	}
+	return NewProvider(NameConflicts, func(c *Context) string {
+		var prompt strings.Builder
+		n := 0
+		for _, cf := range c.Conflicts {
+			if opts.Unresolved && cf.Resolved {
+				continue
+			}
+			if n == 0 {
+				prompt.WriteString(fmt.Sprintf("\n## %s\n", opts.Heading))
+			}
+			n++
+			prompt.WriteString(fmt.Sprintf("%d. %s: %s", n, cf.Type, cf.Question))
+			if opts.Detail {
+				prompt.WriteString(fmt.Sprintf(" (强度:%d)", cf.Intensity))
+			}
+			prompt.WriteString("\n")
+			if opts.Detail && len(cf.Path) > 0 {
+				prompt.WriteString(fmt.Sprintf("   演化路径: %s\n", strings.Join(cf.Path, " → ")))
+			}
+		}
+		return prompt.String()
+	})
+}
@@ -0,0 +1,394 @@
+package promptctx
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// 内置提供者的名称
+const (
+	NameWorldContext    = "世界背景"
+	NameWorldview       = "世界观"
+	NameSupernatural    = "超自然体系"
+	NameCivilization    = "文明"
+	NameGeography       = "地理环境"
+	NameEconomy         = "资源与贸易"
+	NameReligiousStrife = "宗教派系纷争"
+	NameHistory         = "历史"
+	NameCharacters      = "角色"
+	NameConflicts       = "冲突"
+)
+
+// world 只依赖世界设定的提供者，未设置世界时无内容
+func world(name string, render func(w *models.WorldSetting) string) Provider {
+	return NewProvider(name, func(c *Context) string {
+		if c.World == nil {
+			return ""
+		}
+		return render(c.World)
+	})
+}
+
+// WorldContext 世界背景：基本信息、核心主题、价值体系、道德困境和可探索主题
+func WorldContext() Provider { return world(NameWorldContext, renderWorldContext) }
+
+// Worldview 世界观：宇宙论与形而上学
+func Worldview() Provider { return world(NameWorldview, renderWorldview) }
+
+// Supernatural 超自然体系：魔法、修真、异能
+func Supernatural() Provider { return world(NameSupernatural, renderSupernatural) }
+
+// Civilization 文明：种族关系、语言、宗教及其组织
+func Civilization() Provider { return world(NameCivilization, renderCivilization) }
+
+// Geography 地理环境：主要区域、资源分布、气候
+func Geography() Provider { return world(NameGeography, renderGeography) }
+
+// Economy 资源争夺热点与贸易路线（来自经济模型）
+func Economy() Provider { return world(NameEconomy, renderEconomy) }
+
+// ReligiousStrife 宗教派系纷争与争夺中的圣地（来自宗教组织架构，可作为反派素材）
+func ReligiousStrife() Provider { return world(NameReligiousStrife, renderReligiousStrife) }
+
+// History 历史：世界起源与历史时代
+func History() Provider { return world(NameHistory, renderHistory) }
+
+func renderWorldContext(w *models.WorldSetting) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("## 世界背景\n")
+	prompt.WriteString(fmt.Sprintf("- 世界名称: %s\n", w.Name))
+	prompt.WriteString(fmt.Sprintf("- 世界类型: %s\n", w.Type))
+	prompt.WriteString(fmt.Sprintf("- 世界规模: %s\n", w.Scale))
+	if w.Style != "" {
+		prompt.WriteString(fmt.Sprintf("- 风格倾向: %s\n", w.Style))
+	}
+	prompt.WriteString(fmt.Sprintf("- 核心主题: %s\n", w.Philosophy.CoreQuestion))
+	prompt.WriteString(fmt.Sprintf("- 最高善: %s, 最大恶: %s\n",
+		w.Philosophy.ValueSystem.HighestGood,
+		w.Philosophy.ValueSystem.UltimateEvil))
+
+	// 道德困境（冲突来源）
+	if len(w.Philosophy.ValueSystem.MoralDilemmas) > 0 {
+		prompt.WriteString("\n### 道德困境\n")
+		for i, d := range w.Philosophy.ValueSystem.MoralDilemmas {
+			prompt.WriteString(fmt.Sprintf("%d. %s: %s\n", i+1, d.Dilemma, d.Description))
+		}
+	}
+
+	// 主题列表
+	if len(w.Philosophy.Themes) > 0 {
+		prompt.WriteString("\n### 可探索主题\n")
+		for _, t := range w.Philosophy.Themes {
+			prompt.WriteString(fmt.Sprintf("- %s: %s\n", t.Name, t.ExplorationAngle))
+		}
+	}
+
+	return prompt.String()
+}
+
+func renderWorldview(w *models.WorldSetting) string {
+	var prompt strings.Builder
+	cosmology, metaphysics := w.Worldview.Cosmology, w.Worldview.Metaphysics
+
+	// 宇宙论
+	if cosmology.Origin != "" || cosmology.Structure != "" || cosmology.Eschatology != "" {
+		prompt.WriteString("\n## 世界观\n")
+		if cosmology.Origin != "" {
+			prompt.WriteString(fmt.Sprintf("### 宇宙论\n- 起源: %s\n", cosmology.Origin))
+		}
+		if cosmology.Structure != "" {
+			prompt.WriteString(fmt.Sprintf("- 结构: %s\n", cosmology.Structure))
+		}
+		if cosmology.Eschatology != "" {
+			prompt.WriteString(fmt.Sprintf("- 终极命运: %s\n", cosmology.Eschatology))
+		}
+	}
+
+	// 形而上学
+	if metaphysics.SoulExists || metaphysics.FateExists {
+		if prompt.Len() == 0 {
+			prompt.WriteString("\n## 世界观\n")
+		}
+		prompt.WriteString("\n### 形而上学\n")
+		if metaphysics.SoulExists {
+			prompt.WriteString(fmt.Sprintf("- 灵魂存在: %s\n", metaphysics.SoulNature))
+			prompt.WriteString(fmt.Sprintf("- 来世: %s\n", metaphysics.Afterlife))
+		}
+		if metaphysics.FateExists {
+			prompt.WriteString(fmt.Sprintf("- 命运与自由意志: %s\n", metaphysics.FateRelShip))
+		}
+	}
+
+	return prompt.String()
+}
+
+func renderSupernatural(w *models.WorldSetting) string {
+	sn := w.Laws.Supernatural
+	if sn == nil || !sn.Exists {
+		return ""
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("\n## 超自然体系\n")
+	prompt.WriteString(fmt.Sprintf("类型: %s\n", sn.Type))
+
+	if sn.Settings != nil {
+		// 魔法体系
+		if magic := sn.Settings.MagicSystem; magic != nil {
+			prompt.WriteString("\n### 魔法体系\n")
+			prompt.WriteString(fmt.Sprintf("- 来源: %s\n", magic.Source))
+			prompt.WriteString(fmt.Sprintf("- 代价: %s\n", magic.Cost))
+			if len(magic.Limitation) > 0 {
+				prompt.WriteString(fmt.Sprintf("- 限制: %s\n", strings.Join(magic.Limitation, ", ")))
+			}
+		}
+
+		// 修真体系
+		if cultivation := sn.Settings.CultivationSystem; cultivation != nil {
+			prompt.WriteString("\n### 修真体系\n")
+			if len(cultivation.Realms) > 0 {
+				prompt.WriteString(fmt.Sprintf("- 境界: %s\n", strings.Join(cultivation.Realms, "→")))
+			}
+			prompt.WriteString(fmt.Sprintf("- 资源体系: %s\n", cultivation.ResourceSystem))
+			prompt.WriteString(fmt.Sprintf("- 瓶颈: %s\n", cultivation.Bottleneck))
+		}
+
+		// 异能体系
+		if superpower := sn.Settings.SuperpowerSystem; superpower != nil {
+			prompt.WriteString("\n### 异能体系\n")
+			prompt.WriteString(fmt.Sprintf("- 起源: %s\n", superpower.Origin))
+			prompt.WriteString(fmt.Sprintf("- 类型: %s\n", superpower.Type))
+			if len(superpower.Limit) > 0 {
+				prompt.WriteString(fmt.Sprintf("- 限制: %s\n", strings.Join(superpower.Limit, ", ")))
+			}
+		}
+	}
+
+	return prompt.String()
+}
+
+func renderCivilization(w *models.WorldSetting) string {
+	var prompt strings.Builder
+	civ := &w.Civilization
+	hasContent := false
+
+	// 种族关系（潜在冲突来源）
+	if len(civ.Races) > 0 {
+		hasContent = true
+		hasRaceRelations := false
+		for _, race := range civ.Races {
+			if len(race.Relations) > 0 {
+				hasRaceRelations = true
+				break
+			}
+		}
+		if hasRaceRelations {
+			prompt.WriteString("\n## 种族关系\n")
+			for _, race := range civ.Races {
+				if len(race.Relations) > 0 {
+					prompt.WriteString(fmt.Sprintf("%s 与其他种族的关系:\n", race.Name))
+					for otherRace, relation := range race.Relations {
+						prompt.WriteString(fmt.Sprintf("  - %s: %s\n", otherRace, relation))
+					}
+				}
+			}
+		}
+	}
+
+	// 语言
+	if len(civ.Languages) > 0 {
+		if !hasContent {
+			hasContent = true
+			prompt.WriteString("\n## 语言文化\n")
+		} else {
+			prompt.WriteString("\n### 语言\n")
+		}
+		for _, lang := range civ.Languages {
+			prompt.WriteString(fmt.Sprintf("- %s (%s): 使用者=%s\n", lang.Name, lang.Type, lang.Speakers))
+			if len(lang.Features) > 0 {
+				prompt.WriteString(fmt.Sprintf("  特征: %s\n", strings.Join(lang.Features, ", ")))
+			}
+		}
+	}
+
+	// 宗教（包括组织结构）
+	if len(civ.Religions) > 0 {
+		if !hasContent {
+			prompt.WriteString("\n## 宗教信仰\n")
+		} else {
+			prompt.WriteString("\n### 宗教信仰\n")
+		}
+		for _, rel := range civ.Religions {
+			prompt.WriteString(fmt.Sprintf("- %s (%s)\n", rel.Name, rel.Type))
+			if rel.Cosmology != "" {
+				prompt.WriteString(fmt.Sprintf("  宇宙观: %s\n", rel.Cosmology))
+			}
+			if len(rel.Ethics) > 0 {
+				prompt.WriteString(fmt.Sprintf("  伦理: %s\n", strings.Join(rel.Ethics, ", ")))
+			}
+			if len(rel.Practices) > 0 {
+				prompt.WriteString(fmt.Sprintf("  仪式: %s\n", strings.Join(rel.Practices, ", ")))
+			}
+			// 宗教组织（权力结构）
+			if org := rel.Organization; org != nil {
+				prompt.WriteString(fmt.Sprintf("  组织: %s (领导者: %s)\n", org.Type, org.Leader))
+				if len(org.FactionDetails) > 0 {
+					for _, f := range org.FactionDetails {
+						prompt.WriteString(fmt.Sprintf("  派系: %s (领袖: %s) 主张: %s\n", f.Name, f.Leader, f.Doctrine))
+					}
+				} else if len(org.Factions) > 0 {
+					prompt.WriteString(fmt.Sprintf("  派系: %s\n", strings.Join(org.Factions, ", ")))
+				}
+				for _, site := range org.HolySites {
+					prompt.WriteString(fmt.Sprintf("  圣地: %s (%s)\n", site.Name, site.Region))
+				}
+			}
+		}
+	}
+
+	return prompt.String()
+}
+
+func renderGeography(w *models.WorldSetting) string {
+	geo := &w.Geography
+	if len(geo.Regions) == 0 {
+		return ""
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("\n## 地理环境\n")
+
+	// 列出主要区域
+	prompt.WriteString("### 主要区域\n")
+	for i, region := range geo.Regions {
+		if i >= 8 { // 最多显示8个区域
+			prompt.WriteString(fmt.Sprintf("... 还有%d个区域\n", len(geo.Regions)-i))
+			break
+		}
+		prompt.WriteString(fmt.Sprintf("- %s (%s): %s\n", region.Name, region.Type, region.Description))
+		if len(region.Resources) > 0 {
+			prompt.WriteString(fmt.Sprintf("  区域资源: %s\n", strings.Join(region.Resources, ", ")))
+		}
+		if len(region.Risks) > 0 {
+			prompt.WriteString(fmt.Sprintf("  风险: %s\n", strings.Join(region.Risks, ", ")))
+		}
+	}
+
+	// 全局资源分布
+	if res := geo.Resources; res != nil {
+		prompt.WriteString("\n### 资源分布\n")
+		if len(res.Basic) > 0 {
+			prompt.WriteString(fmt.Sprintf("基础资源: %s\n", strings.Join(res.Basic, ", ")))
+		}
+		if len(res.Strategic) > 0 {
+			prompt.WriteString(fmt.Sprintf("战略资源: %s\n", strings.Join(res.Strategic, ", ")))
+		}
+		if len(res.Rare) > 0 {
+			prompt.WriteString(fmt.Sprintf("稀有资源: %s\n", strings.Join(res.Rare, ", ")))
+		}
+	}
+
+	// 气候信息
+	if climate := geo.Climate; climate != nil {
+		prompt.WriteString(fmt.Sprintf("\n### 气候\n%s\n", climate.Type))
+		if len(climate.Features) > 0 {
+			prompt.WriteString(fmt.Sprintf("特征: %s\n", strings.Join(climate.Features, ", ")))
+		}
+	}
+
+	return prompt.String()
+}
+
+func renderEconomy(w *models.WorldSetting) string {
+	economy := &w.Society.Economy
+	if len(economy.Scarcity) == 0 && len(economy.TradeRoutes) == 0 {
+		return ""
+	}
+
+	var prompt strings.Builder
+	if hotspots := economy.Hotspots(); len(hotspots) > 0 {
+		prompt.WriteString("### 资源争夺热点\n")
+		for i, h := range hotspots {
+			if i >= 5 {
+				break
+			}
+			prompt.WriteString(fmt.Sprintf("- %s：%s短缺（稀缺度%d），%s充裕", h.Resource, h.ScarceRegion, h.Level, h.SourceRegion))
+			if h.Route != "" {
+				prompt.WriteString(fmt.Sprintf("，经%s运输", h.Route))
+			}
+			for _, c := range h.Chokepoints {
+				prompt.WriteString(fmt.Sprintf("，咽喉要地%s", c.Region))
+				if c.Controller != "" {
+					prompt.WriteString(fmt.Sprintf("（%s控制）", c.Controller))
+				}
+			}
+			prompt.WriteString("\n")
+		}
+	}
+	if len(economy.TradeRoutes) > 0 {
+		prompt.WriteString("### 贸易路线\n")
+		for _, r := range economy.TradeRoutes {
+			prompt.WriteString(fmt.Sprintf("- %s：%s → %s，货物: %s\n", r.Name, r.From, r.To, strings.Join(r.Goods, ", ")))
+		}
+	}
+	return prompt.String()
+}
+
+func renderReligiousStrife(w *models.WorldSetting) string {
+	var prompt strings.Builder
+	for _, rel := range w.Civilization.Religions {
+		org := rel.Organization
+		if org == nil || (len(org.FactionDetails) == 0 && len(org.HolySites) == 0) {
+			continue
+		}
+		if prompt.Len() == 0 {
+			prompt.WriteString("\n## 宗教派系纷争\n")
+		}
+		prompt.WriteString(fmt.Sprintf("### %s（领袖: %s）\n", rel.Name, org.Leader))
+		for _, f := range org.FactionDetails {
+			prompt.WriteString(fmt.Sprintf("- %s（%s领导，势力%d）: 分歧: %s", f.Name, f.Leader, f.Strength, f.Dispute))
+			if f.Rival != "" {
+				prompt.WriteString(fmt.Sprintf("；对立: %s", f.Rival))
+			}
+			if f.Ambition != "" {
+				prompt.WriteString(fmt.Sprintf("；图谋: %s", f.Ambition))
+			}
+			prompt.WriteString("\n")
+		}
+		for _, site := range org.HolySites {
+			if !site.Contested {
+				continue
+			}
+			prompt.WriteString(fmt.Sprintf("- 争夺中的圣地: %s（%s），现由%s控制\n", site.Name, site.Region, site.ControlledBy))
+		}
+	}
+	return prompt.String()
+}
+
+func renderHistory(w *models.WorldSetting) string {
+	var prompt strings.Builder
+
+	// 世界起源
+	if w.History.Origin != "" {
+		prompt.WriteString("\n## 世界起源\n")
+		prompt.WriteString(w.History.Origin)
+		prompt.WriteString("\n")
+	}
+
+	// 历史时代
+	if len(w.History.Eras) > 0 {
+		if prompt.Len() == 0 {
+			prompt.WriteString("\n## 历史时代\n")
+		} else {
+			prompt.WriteString("\n### 历史时代\n")
+		}
+		for _, era := range w.History.Eras {
+			prompt.WriteString(fmt.Sprintf("- %s (%s): %s\n", era.Name, era.Period, era.Description))
+		}
+	}
+
+	return prompt.String()
+}
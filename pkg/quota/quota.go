@@ -0,0 +1,223 @@
+// Package quota 用户预算 - 按月限制每个用户的LLM token和费用
+//
+// 默认上限来自系统配置（quota_monthly_tokens、quota_monthly_cost、quota_warn_ratio），
+// 管理员可为单个用户覆盖。用量达到提醒线时通知一次；达到上限后该用户的LLM请求在发出前被拒绝，直到下个月
+package quota
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/logx"
+	"github.com/xlei/xupu/pkg/notify"
+)
+
+// 系统配置中的默认上限
+const (
+	ConfigMonthlyTokens = "quota_monthly_tokens"
+	ConfigMonthlyCost   = "quota_monthly_cost"
+	ConfigWarnRatio     = "quota_warn_ratio"
+)
+
+// DefaultWarnRatio 未配置提醒线时的默认值
+const DefaultWarnRatio = 0.8
+
+// 预算状态
+const (
+	StateOK       = "ok"
+	StateWarning  = "warning"  // 达到提醒线，仍可继续使用
+	StateExceeded = "exceeded" // 达到上限，请求被拒绝
+)
+
+// Limits 生效的月度上限，0 表示不限
+type Limits struct {
+	MonthlyTokens int     `json:"monthly_tokens"`
+	MonthlyCost   float64 `json:"monthly_cost"`
+	WarnRatio     float64 `json:"warn_ratio"`
+	Custom        bool    `json:"custom"` // 是否有针对该用户的设置
+}
+
+// Status 用户本月的预算使用情况
+type Status struct {
+	UserID          string    `json:"user_id"`
+	Month           string    `json:"month"`
+	Limits          Limits    `json:"limits"`
+	Used            llm.Usage `json:"used"`
+	RemainingTokens *int      `json:"remaining_tokens,omitempty"` // 不限时为空
+	RemainingCost   *float64  `json:"remaining_cost,omitempty"`   // 不限时为空
+	Ratio           float64   `json:"ratio"`                      // token 和费用中较高的使用比例
+	State           string    `json:"state"`
+	ResetsAt        time.Time `json:"resets_at"`
+}
+
+// Month 用量按月归档的键
+func Month(t time.Time) string {
+	return t.Format("2006-01")
+}
+
+// LimitsFor 用户生效的上限：用户设置优先，未设置的项取系统配置
+func LimitsFor(database db.Database, userID string) Limits {
+	configs := sysConfigs(database)
+	limits := Limits{
+		MonthlyTokens: int(configFloat(configs, ConfigMonthlyTokens, 0)),
+		MonthlyCost:   configFloat(configs, ConfigMonthlyCost, 0),
+		WarnRatio:     configFloat(configs, ConfigWarnRatio, DefaultWarnRatio),
+	}
+	if q, err := database.GetUserQuota(userID); err == nil {
+		limits.Custom = true
+		if q.MonthlyTokens != nil {
+			limits.MonthlyTokens = *q.MonthlyTokens
+		}
+		if q.MonthlyCost != nil {
+			limits.MonthlyCost = *q.MonthlyCost
+		}
+		if q.WarnRatio != nil {
+			limits.WarnRatio = *q.WarnRatio
+		}
+	}
+	return limits
+}
+
+// GetStatus 用户本月的预算使用情况
+func GetStatus(database db.Database, userID string, now time.Time) (*Status, error) {
+	month := Month(now)
+	usage, err := database.GetUserUsage(userID, month)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &Status{
+		UserID: userID,
+		Month:  month,
+		Limits: LimitsFor(database, userID),
+		Used: llm.Usage{
+			Requests:     usage.Requests,
+			InputTokens:  usage.InputTokens,
+			OutputTokens: usage.OutputTokens,
+			TotalTokens:  usage.TotalTokens,
+			Cost:         usage.Cost,
+		},
+		ResetsAt: time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, now.Location()),
+	}
+	if limit := status.Limits.MonthlyTokens; limit > 0 {
+		remaining := max(limit-usage.TotalTokens, 0)
+		status.RemainingTokens = &remaining
+		status.Ratio = float64(usage.TotalTokens) / float64(limit)
+	}
+	if limit := status.Limits.MonthlyCost; limit > 0 {
+		remaining := max(limit-usage.Cost, 0)
+		status.RemainingCost = &remaining
+		status.Ratio = max(status.Ratio, usage.Cost/limit)
+	}
+
+	switch {
+	case status.Ratio >= 1:
+		status.State = StateExceeded
+	case status.Limits.WarnRatio > 0 && status.Ratio >= status.Limits.WarnRatio:
+		status.State = StateWarning
+	default:
+		status.State = StateOK
+	}
+	return status, nil
+}
+
+// Check 用户本月预算是否已用尽，用尽时返回包装了 llm.ErrBudgetExceeded 的错误
+// 无法读取用量（如内存数据库）时放行
+func Check(database db.Database, userID string) error {
+	if userID == "" {
+		return nil
+	}
+	status, err := GetStatus(database, userID, time.Now())
+	if err != nil || status.State != StateExceeded {
+		return nil
+	}
+	return fmt.Errorf("%w：本月已用 %d tokens、费用 %.4f，%s 重置",
+		llm.ErrBudgetExceeded, status.Used.TotalTokens, status.Used.Cost, status.ResetsAt.Format("2006-01-02"))
+}
+
+// Budget 单个用户的预算闸门，实现 llm.Budget
+type Budget struct {
+	db     db.Database
+	userID string
+}
+
+// For 创建用户的预算闸门
+func For(database db.Database, userID string) *Budget {
+	return &Budget{db: database, userID: userID}
+}
+
+// Allow 请求发出前检查本月预算
+func (b *Budget) Allow() error {
+	return Check(b.db, b.userID)
+}
+
+// Spend 把用量记入用户当月累计，越过提醒线或上限时通知用户（每月各一次）
+func (b *Budget) Spend(u llm.Usage) {
+	now := time.Now()
+	err := b.db.AddUserUsage(&models.UserUsageMonth{
+		UserID:       b.userID,
+		Month:        Month(now),
+		Requests:     u.Requests,
+		InputTokens:  u.InputTokens,
+		OutputTokens: u.OutputTokens,
+		TotalTokens:  u.TotalTokens,
+		Cost:         u.Cost,
+	})
+	if err != nil {
+		logx.L().Debug("记录用户用量失败", "user", b.userID, "error", err)
+		return
+	}
+
+	status, err := GetStatus(b.db, b.userID, now)
+	if err != nil || status.State == StateOK {
+		return
+	}
+	exceeded := status.State == StateExceeded
+	if first, err := b.db.MarkUserUsageNotified(b.userID, status.Month, exceeded); err != nil || !first {
+		return
+	}
+	notify.Dispatch(b.db, b.userID, message(status))
+}
+
+// message 预算通知内容
+func message(status *Status) notify.Message {
+	used := fmt.Sprintf("本月已用 %d tokens、费用 %.4f（%.0f%%）", status.Used.TotalTokens, status.Used.Cost, status.Ratio*100)
+	if status.State == StateExceeded {
+		return notify.Message{
+			Event: models.NotifyEventBudgetExceeded,
+			Title: "本月创作额度已用尽",
+			Text:  used + "，后续生成请求将被拒绝，" + status.ResetsAt.Format("2006-01-02") + " 重置。",
+		}
+	}
+	return notify.Message{
+		Event: models.NotifyEventBudgetWarning,
+		Title: "本月创作额度即将用尽",
+		Text:  used + "，达到上限后生成请求将被拒绝。",
+	}
+}
+
+// sysConfigs 系统配置按键索引，读取失败时为空
+func sysConfigs(database db.Database) map[string]string {
+	configs, err := database.GetSysConfigs()
+	if err != nil {
+		return nil
+	}
+	values := make(map[string]string, len(configs))
+	for _, cfg := range configs {
+		values[cfg.Key] = cfg.Value
+	}
+	return values
+}
+
+// configFloat 读取数值型系统配置，不存在或无法解析时返回 def
+func configFloat(configs map[string]string, key string, def float64) float64 {
+	v, err := strconv.ParseFloat(configs[key], 64)
+	if err != nil {
+		return def
+	}
+	return v
+}
@@ -0,0 +1,263 @@
+// Package release 连载更新计划 - 把章节切成固定长度的更新单元
+// 已写出的章节在段落边界切分，优先停在有悬念的段落；未写出的章节按目标字数和关键场景预先规划
+package release
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// DefaultInstallmentLength 默认每次更新的字数
+const DefaultInstallmentLength = 2000
+
+// 切分点允许偏离目标长度的范围
+const (
+	minCutRatio = 0.7
+	maxCutRatio = 1.3
+)
+
+// maxHookRunes 小钩子最多保留的字数
+const maxHookRunes = 40
+
+// hookEndings 有悬念感的段落结尾
+var hookEndings = []string{"？", "?", "！", "!", "……", "…", "——", "？”", "！”", "……”", "——”"}
+
+// hookWords 段落中出现时更像悬念的词
+var hookWords = []string{"突然", "忽然", "竟然", "居然", "却", "难道", "究竟", "到底", "就在这时", "谁", "什么"}
+
+// Plan 按章节规划和已有正文生成更新单元；old 非空时按章节和分段保留已有的发布状态
+func Plan(plans []models.ChapterPlan, chapters []*models.Chapter, length int, old []models.Installment) []models.Installment {
+	if length <= 0 {
+		length = DefaultInstallmentLength
+	}
+
+	sorted := append([]models.ChapterPlan(nil), plans...)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Chapter < sorted[j].Chapter })
+	written := make(map[int]*models.Chapter, len(chapters))
+	for _, ch := range chapters {
+		if strings.TrimSpace(ch.Content) != "" {
+			written[ch.ChapterNum] = ch
+		}
+	}
+
+	installments := make([]models.Installment, 0)
+	for _, plan := range sorted {
+		var parts []models.Installment
+		if ch, ok := written[plan.Chapter]; ok {
+			parts = splitContent(ch.Content, length)
+		} else {
+			parts = planUnwritten(plan, length)
+		}
+		for i := range parts {
+			parts[i].Chapter = plan.Chapter
+			parts[i].Part = i + 1
+			parts[i].Parts = len(parts)
+			parts[i].Sequence = len(installments) + 1
+			installments = append(installments, parts[i])
+		}
+	}
+
+	carryStatus(installments, old)
+	return installments
+}
+
+// Text 取更新单元对应的正文
+func Text(content string, inst models.Installment) string {
+	runes := []rune(content)
+	if inst.End <= inst.Start || inst.End > len(runes) {
+		return ""
+	}
+	return string(runes[inst.Start:inst.End])
+}
+
+// PublishDue 把定时时间已到的更新标记为已发布，返回发布的更新序号
+func PublishDue(plan *models.ReleasePlan, now time.Time) []int {
+	published := make([]int, 0)
+	for i := range plan.Installments {
+		inst := &plan.Installments[i]
+		if inst.Status != models.InstallmentScheduled || inst.ScheduledAt == nil || inst.ScheduledAt.After(now) {
+			continue
+		}
+		publishedAt := *inst.ScheduledAt
+		inst.Status = models.InstallmentPublished
+		inst.PublishedAt = &publishedAt
+		published = append(published, inst.Sequence)
+	}
+	return published
+}
+
+// splitContent 在段落边界切分正文：在目标长度附近的候选切点中选悬念最强、离目标最近的一个
+func splitContent(content string, length int) []models.Installment {
+	runes := []rune(content)
+	total := len(runes)
+
+	// 段落结束位置（不含换行）
+	type boundary struct {
+		end       int
+		paragraph string
+	}
+	boundaries := make([]boundary, 0)
+	start := 0
+	for i := 0; i <= total; i++ {
+		if i == total || runes[i] == '\n' {
+			if p := strings.TrimSpace(string(runes[start:i])); p != "" {
+				boundaries = append(boundaries, boundary{end: i, paragraph: p})
+			}
+			start = i + 1
+		}
+	}
+
+	installments := make([]models.Installment, 0)
+	from := 0
+	for len(boundaries) > 0 {
+		// 剩余正文不超过切分上限时整体作为最后一段
+		if total-from <= int(float64(length)*maxCutRatio) {
+			break
+		}
+		best, bestScore := -1, math.Inf(-1)
+		for i, b := range boundaries {
+			size := b.end - from
+			if size < int(float64(length)*minCutRatio) {
+				continue
+			}
+			if size > int(float64(length)*maxCutRatio) && best >= 0 {
+				break
+			}
+			distance := math.Abs(float64(size-length)) / float64(length)
+			score := hookStrength(b.paragraph) - distance*2
+			if score > bestScore {
+				best, bestScore = i, score
+			}
+		}
+		if best < 0 {
+			break
+		}
+		cut := boundaries[best]
+		installments = append(installments, models.Installment{
+			Start:     from,
+			End:       cut.end,
+			Length:    cut.end - from,
+			MicroHook: lastSentence(cut.paragraph),
+			WeakHook:  hookStrength(cut.paragraph) < 1,
+			Status:    models.InstallmentReady,
+		})
+		from = cut.end
+		boundaries = boundaries[best+1:]
+	}
+
+	last := ""
+	if len(boundaries) > 0 {
+		last = boundaries[len(boundaries)-1].paragraph
+	}
+	if from < total {
+		installments = append(installments, models.Installment{
+			Start:     from,
+			End:       total,
+			Length:    total - from,
+			MicroHook: lastSentence(last),
+			Status:    models.InstallmentReady,
+		})
+	}
+	return installments
+}
+
+// planUnwritten 未写出的章节按目标字数分段，分段的钩子落在对应的关键场景上，最后一段用章节的结尾悬念
+func planUnwritten(plan models.ChapterPlan, length int) []models.Installment {
+	words := plan.WordCount
+	if words <= 0 {
+		words = length
+	}
+	n := max(1, int(math.Round(float64(words)/float64(length))))
+
+	installments := make([]models.Installment, 0, n)
+	for i := 0; i < n; i++ {
+		hook := plan.EndingHook
+		if i < n-1 {
+			hook = fmt.Sprintf("第%d段停在悬而未决处", i+1)
+			if len(plan.KeyScenes) > 0 {
+				scene := plan.KeyScenes[min(len(plan.KeyScenes)-1, (i+1)*len(plan.KeyScenes)/n)]
+				hook = fmt.Sprintf("停在「%s」的关键时刻", scene)
+			}
+		}
+		installments = append(installments, models.Installment{
+			Length:    words / n,
+			MicroHook: hook,
+			Status:    models.InstallmentPlanned,
+		})
+	}
+	return installments
+}
+
+// carryStatus 按章节和分段把已定时、已发布的状态带到新计划
+func carryStatus(installments, old []models.Installment) {
+	type key struct{ chapter, part int }
+	previous := make(map[key]models.Installment, len(old))
+	for _, inst := range old {
+		previous[key{inst.Chapter, inst.Part}] = inst
+	}
+	for i := range installments {
+		prev, ok := previous[key{installments[i].Chapter, installments[i].Part}]
+		if !ok {
+			continue
+		}
+		if prev.Status == models.InstallmentScheduled || prev.Status == models.InstallmentPublished {
+			installments[i].Status = prev.Status
+			installments[i].ScheduledAt = prev.ScheduledAt
+			installments[i].PublishedAt = prev.PublishedAt
+		}
+	}
+}
+
+// hookStrength 段落的悬念强度：结尾标点和悬念词
+func hookStrength(paragraph string) float64 {
+	score := 0.0
+	for _, end := range hookEndings {
+		if strings.HasSuffix(paragraph, end) {
+			score += 1
+			break
+		}
+	}
+	tail := []rune(paragraph)
+	if len(tail) > 30 {
+		tail = tail[len(tail)-30:]
+	}
+	for _, w := range hookWords {
+		if strings.Contains(string(tail), w) {
+			score += 0.5
+			break
+		}
+	}
+	// 短段落收尾更有停顿感
+	if len([]rune(paragraph)) <= 20 {
+		score += 0.3
+	}
+	return score
+}
+
+// lastSentence 段落的最后一句，作为更新单元的小钩子
+func lastSentence(paragraph string) string {
+	runes := []rune(strings.TrimSpace(paragraph))
+	if len(runes) == 0 {
+		return ""
+	}
+	end := len(runes)
+	// 跳过结尾的标点和引号
+	for end > 0 && strings.ContainsRune("。！？!?…—”」", runes[end-1]) {
+		end--
+	}
+	start := 0
+	for i := end - 1; i >= 0; i-- {
+		if strings.ContainsRune("。！？!?", runes[i]) {
+			start = i + 1
+			break
+		}
+	}
+	// 过长的句子只保留末尾
+	start = max(start, len(runes)-maxHookRunes)
+	return strings.TrimSpace(string(runes[start:]))
+}
@@ -0,0 +1,196 @@
+// Package romance 感情线节拍
+// 为一对角色规划初遇、心动、误会、初吻、至暗时刻和确定关系的目标章节，
+// 校验节拍的顺序与节奏，并找出每章需要推进或尚不能发生的节拍。
+package romance
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// 节奏阈值（占全书章节数的比例）
+const (
+	// minKissGap 初遇到初吻至少间隔的比例，再快显得仓促
+	minKissGap = 0.1
+	// maxStallGap 相邻节拍最大间隔的比例，超出视为感情线停滞
+	maxStallGap = 0.35
+	// darkMomentFrom 至暗时刻不早于全书的这一位置
+	darkMomentFrom = 0.5
+	// commitmentFrom 确定关系不早于全书的这一位置
+	commitmentFrom = 0.7
+)
+
+// 问题类型
+const (
+	IssueMissing    = "missing"      // 缺少核心节拍
+	IssueOutOfRange = "out_of_range" // 目标章节超出全书
+	IssueOrder      = "order"        // 节拍顺序颠倒
+	IssueRushed     = "rushed"       // 进展过快
+	IssueStalled    = "stalled"      // 长时间没有进展
+	IssueEarly      = "early"        // 关键节拍出现得过早
+)
+
+// coreBeats 每条感情线都应有的节拍
+var coreBeats = []models.RomanceBeatType{models.BeatMeetCute, models.BeatDarkMoment, models.BeatCommitment}
+
+// Issue 节拍规划的问题
+type Issue struct {
+	ArcID      string `json:"arc_id"`
+	Pair       string `json:"pair"`
+	Chapter    int    `json:"chapter,omitempty"`
+	Kind       string `json:"kind"`
+	Detail     string `json:"detail"`
+	Suggestion string `json:"suggestion"`
+}
+
+// rank 节拍在常规顺序中的位置，未知类型返回 -1
+func rank(t models.RomanceBeatType) int {
+	for i, o := range models.RomanceBeatOrder {
+		if o == t {
+			return i
+		}
+	}
+	return -1
+}
+
+// beatName 节拍的中文名
+func beatName(t models.RomanceBeatType) string {
+	if name, ok := models.RomanceBeatNames[t]; ok {
+		return name
+	}
+	return string(t)
+}
+
+// SortedBeats 按目标章节排序的节拍，同章按常规顺序
+func SortedBeats(arc *models.RomanceArc) []models.RomanceBeat {
+	beats := make([]models.RomanceBeat, len(arc.Beats))
+	copy(beats, arc.Beats)
+	sort.SliceStable(beats, func(i, j int) bool {
+		if beats[i].Chapter != beats[j].Chapter {
+			return beats[i].Chapter < beats[j].Chapter
+		}
+		return rank(beats[i].Type) < rank(beats[j].Type)
+	})
+	return beats
+}
+
+// Validate 校验一条感情线的节拍：核心节拍齐全、章节在全书范围内、顺序合理、节奏不过快也不停滞
+// totalChapters 为全书章节数，为 0 时只检查齐全和顺序
+func Validate(arc *models.RomanceArc, totalChapters int) []Issue {
+	issues := make([]Issue, 0)
+	add := func(chapter int, kind, detail, suggestion string) {
+		issues = append(issues, Issue{ArcID: arc.ID, Pair: arc.Pair(), Chapter: chapter, Kind: kind, Detail: detail, Suggestion: suggestion})
+	}
+
+	first := make(map[models.RomanceBeatType]int)
+	for _, b := range arc.Beats {
+		if c, ok := first[b.Type]; !ok || b.Chapter < c {
+			first[b.Type] = b.Chapter
+		}
+		if b.Chapter < 1 || (totalChapters > 0 && b.Chapter > totalChapters) {
+			add(b.Chapter, IssueOutOfRange, fmt.Sprintf("%s的%s安排在第%d章，超出全书范围", arc.Pair(), beatName(b.Type), b.Chapter),
+				fmt.Sprintf("改到第1-%d章之间", totalChapters))
+		}
+	}
+	for _, t := range coreBeats {
+		if _, ok := first[t]; !ok {
+			add(0, IssueMissing, fmt.Sprintf("%s的感情线缺少%s", arc.Pair(), beatName(t)), "补充该节拍的目标章节")
+		}
+	}
+
+	// 顺序：按常规顺序两两比较已安排的节拍
+	for i, a := range models.RomanceBeatOrder {
+		ca, ok := first[a]
+		if !ok {
+			continue
+		}
+		for _, b := range models.RomanceBeatOrder[i+1:] {
+			if a == models.BeatMisunderstanding || b == models.BeatMisunderstanding {
+				continue // 误会可以出现在初遇之后的任何阶段
+			}
+			if cb, ok := first[b]; ok && cb < ca {
+				add(cb, IssueOrder, fmt.Sprintf("%s的%s（第%d章）早于%s（第%d章）", arc.Pair(), beatName(b), cb, beatName(a), ca),
+					fmt.Sprintf("把%s移到%s之后", beatName(b), beatName(a)))
+			}
+		}
+	}
+	if cm, ok := first[models.BeatMeetCute]; ok {
+		if cmis, ok := first[models.BeatMisunderstanding]; ok && cmis < cm {
+			add(cmis, IssueOrder, fmt.Sprintf("%s的误会（第%d章）早于初遇（第%d章）", arc.Pair(), cmis, cm), "把误会移到初遇之后")
+		}
+	}
+
+	if totalChapters <= 0 {
+		return issues
+	}
+	ratio := func(chapter int) float64 { return float64(chapter) / float64(totalChapters) }
+
+	if cm, ok := first[models.BeatMeetCute]; ok {
+		if ck, ok := first[models.BeatFirstKiss]; ok && ck >= cm && float64(ck-cm) < minKissGap*float64(totalChapters) {
+			add(ck, IssueRushed, fmt.Sprintf("%s从初遇（第%d章）到初吻（第%d章）只隔%d章，进展过快", arc.Pair(), cm, ck, ck-cm),
+				"在两者之间安排心动或误会，给感情升温留出篇幅")
+		}
+	}
+	if cd, ok := first[models.BeatDarkMoment]; ok && ratio(cd) < darkMomentFrom {
+		add(cd, IssueEarly, fmt.Sprintf("%s的至暗时刻安排在第%d章，位于全书前半", arc.Pair(), cd),
+			fmt.Sprintf("至暗时刻宜放在第%d章之后，前半部分以误会、试探为主", int(darkMomentFrom*float64(totalChapters))))
+	}
+	if cc, ok := first[models.BeatCommitment]; ok && ratio(cc) < commitmentFrom {
+		add(cc, IssueEarly, fmt.Sprintf("%s在第%d章就确定关系，之后感情线缺少张力", arc.Pair(), cc),
+			fmt.Sprintf("确定关系宜放在第%d章之后，或在确定关系后安排新的考验", int(commitmentFrom*float64(totalChapters))))
+	}
+
+	beats := SortedBeats(arc)
+	for i := 1; i < len(beats); i++ {
+		gap := beats[i].Chapter - beats[i-1].Chapter
+		if float64(gap) > maxStallGap*float64(totalChapters) && gap > 5 {
+			add(beats[i].Chapter, IssueStalled, fmt.Sprintf("%s的感情线在第%d章到第%d章之间没有节拍", arc.Pair(), beats[i-1].Chapter, beats[i].Chapter),
+				"在中间安排心动或误会等小节拍，保持感情线的存在感")
+		}
+	}
+	return issues
+}
+
+// ValidateAll 校验全部感情线
+func ValidateAll(arcs []models.RomanceArc, totalChapters int) []Issue {
+	issues := make([]Issue, 0)
+	for i := range arcs {
+		issues = append(issues, Validate(&arcs[i], totalChapters)...)
+	}
+	return issues
+}
+
+// Directive 某一章中一对角色的感情线要求
+type Directive struct {
+	Arc     *models.RomanceArc
+	Current []models.RomanceBeat // 本章要完成的节拍
+	Reached []models.RomanceBeat // 此前已完成的节拍
+	Next    *models.RomanceBeat  // 下一个尚未到来的节拍，本章不得提前发生
+}
+
+// ChapterDirectives 第 chapter 章中同场出现的各对角色的感情线要求
+func ChapterDirectives(arcs []models.RomanceArc, chapter int, characters []string) []Directive {
+	directives := make([]Directive, 0)
+	for i := range arcs {
+		arc := &arcs[i]
+		if !arc.Involves(characters) {
+			continue
+		}
+		d := Directive{Arc: arc}
+		for _, b := range SortedBeats(arc) {
+			switch {
+			case b.Chapter < chapter:
+				d.Reached = append(d.Reached, b)
+			case b.Chapter == chapter:
+				d.Current = append(d.Current, b)
+			case d.Next == nil:
+				next := b
+				d.Next = &next
+			}
+		}
+		directives = append(directives, d)
+	}
+	return directives
+}
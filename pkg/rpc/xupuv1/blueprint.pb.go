@@ -0,0 +1,442 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: xupu/v1/blueprint.proto
+
+package xupuv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Blueprint 叙事蓝图
+type Blueprint struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	Id                 string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	WorldId            string                 `protobuf:"bytes,2,opt,name=world_id,json=worldId,proto3" json:"world_id,omitempty"`
+	StructureType      string                 `protobuf:"bytes,3,opt,name=structure_type,json=structureType,proto3" json:"structure_type,omitempty"`
+	ChapterCount       int32                  `protobuf:"varint,4,opt,name=chapter_count,json=chapterCount,proto3" json:"chapter_count,omitempty"`
+	SceneCount         int32                  `protobuf:"varint,5,opt,name=scene_count,json=sceneCount,proto3" json:"scene_count,omitempty"`
+	CoreTheme          string                 `protobuf:"bytes,6,opt,name=core_theme,json=coreTheme,proto3" json:"core_theme,omitempty"`
+	CharacterArcsCount int32                  `protobuf:"varint,7,opt,name=character_arcs_count,json=characterArcsCount,proto3" json:"character_arcs_count,omitempty"`
+	ChapterPlans       []*ChapterPlan         `protobuf:"bytes,8,rep,name=chapter_plans,json=chapterPlans,proto3" json:"chapter_plans,omitempty"`
+	CreatedAt          string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // RFC 3339
+	UpdatedAt          string                 `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	// story_outline_json 故事大纲（同 REST 的 story_outline）
+	StoryOutlineJson []byte `protobuf:"bytes,11,opt,name=story_outline_json,json=storyOutlineJson,proto3" json:"story_outline_json,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Blueprint) Reset() {
+	*x = Blueprint{}
+	mi := &file_xupu_v1_blueprint_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Blueprint) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Blueprint) ProtoMessage() {}
+
+func (x *Blueprint) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_blueprint_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Blueprint.ProtoReflect.Descriptor instead.
+func (*Blueprint) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_blueprint_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Blueprint) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Blueprint) GetWorldId() string {
+	if x != nil {
+		return x.WorldId
+	}
+	return ""
+}
+
+func (x *Blueprint) GetStructureType() string {
+	if x != nil {
+		return x.StructureType
+	}
+	return ""
+}
+
+func (x *Blueprint) GetChapterCount() int32 {
+	if x != nil {
+		return x.ChapterCount
+	}
+	return 0
+}
+
+func (x *Blueprint) GetSceneCount() int32 {
+	if x != nil {
+		return x.SceneCount
+	}
+	return 0
+}
+
+func (x *Blueprint) GetCoreTheme() string {
+	if x != nil {
+		return x.CoreTheme
+	}
+	return ""
+}
+
+func (x *Blueprint) GetCharacterArcsCount() int32 {
+	if x != nil {
+		return x.CharacterArcsCount
+	}
+	return 0
+}
+
+func (x *Blueprint) GetChapterPlans() []*ChapterPlan {
+	if x != nil {
+		return x.ChapterPlans
+	}
+	return nil
+}
+
+func (x *Blueprint) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Blueprint) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *Blueprint) GetStoryOutlineJson() []byte {
+	if x != nil {
+		return x.StoryOutlineJson
+	}
+	return nil
+}
+
+// ChapterPlan 章节规划
+type ChapterPlan struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Chapter         int32                  `protobuf:"varint,1,opt,name=chapter,proto3" json:"chapter,omitempty"`
+	Title           string                 `protobuf:"bytes,2,opt,name=title,proto3" json:"title,omitempty"`
+	Purpose         string                 `protobuf:"bytes,3,opt,name=purpose,proto3" json:"purpose,omitempty"`
+	KeyScenes       []string               `protobuf:"bytes,4,rep,name=key_scenes,json=keyScenes,proto3" json:"key_scenes,omitempty"`
+	PlotAdvancement string                 `protobuf:"bytes,5,opt,name=plot_advancement,json=plotAdvancement,proto3" json:"plot_advancement,omitempty"`
+	ArcProgress     string                 `protobuf:"bytes,6,opt,name=arc_progress,json=arcProgress,proto3" json:"arc_progress,omitempty"`
+	EndingHook      string                 `protobuf:"bytes,7,opt,name=ending_hook,json=endingHook,proto3" json:"ending_hook,omitempty"`
+	WordCount       int32                  `protobuf:"varint,8,opt,name=word_count,json=wordCount,proto3" json:"word_count,omitempty"`
+	Status          string                 `protobuf:"bytes,9,opt,name=status,proto3" json:"status,omitempty"` // pending/generating/completed
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *ChapterPlan) Reset() {
+	*x = ChapterPlan{}
+	mi := &file_xupu_v1_blueprint_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChapterPlan) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChapterPlan) ProtoMessage() {}
+
+func (x *ChapterPlan) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_blueprint_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChapterPlan.ProtoReflect.Descriptor instead.
+func (*ChapterPlan) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_blueprint_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ChapterPlan) GetChapter() int32 {
+	if x != nil {
+		return x.Chapter
+	}
+	return 0
+}
+
+func (x *ChapterPlan) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ChapterPlan) GetPurpose() string {
+	if x != nil {
+		return x.Purpose
+	}
+	return ""
+}
+
+func (x *ChapterPlan) GetKeyScenes() []string {
+	if x != nil {
+		return x.KeyScenes
+	}
+	return nil
+}
+
+func (x *ChapterPlan) GetPlotAdvancement() string {
+	if x != nil {
+		return x.PlotAdvancement
+	}
+	return ""
+}
+
+func (x *ChapterPlan) GetArcProgress() string {
+	if x != nil {
+		return x.ArcProgress
+	}
+	return ""
+}
+
+func (x *ChapterPlan) GetEndingHook() string {
+	if x != nil {
+		return x.EndingHook
+	}
+	return ""
+}
+
+func (x *ChapterPlan) GetWordCount() int32 {
+	if x != nil {
+		return x.WordCount
+	}
+	return 0
+}
+
+func (x *ChapterPlan) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+type GetBlueprintRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlueprintRequest) Reset() {
+	*x = GetBlueprintRequest{}
+	mi := &file_xupu_v1_blueprint_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlueprintRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlueprintRequest) ProtoMessage() {}
+
+func (x *GetBlueprintRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_blueprint_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlueprintRequest.ProtoReflect.Descriptor instead.
+func (*GetBlueprintRequest) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_blueprint_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetBlueprintRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetBlueprintResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Blueprint     *Blueprint             `protobuf:"bytes,1,opt,name=blueprint,proto3" json:"blueprint,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetBlueprintResponse) Reset() {
+	*x = GetBlueprintResponse{}
+	mi := &file_xupu_v1_blueprint_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetBlueprintResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetBlueprintResponse) ProtoMessage() {}
+
+func (x *GetBlueprintResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_blueprint_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetBlueprintResponse.ProtoReflect.Descriptor instead.
+func (*GetBlueprintResponse) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_blueprint_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetBlueprintResponse) GetBlueprint() *Blueprint {
+	if x != nil {
+		return x.Blueprint
+	}
+	return nil
+}
+
+var File_xupu_v1_blueprint_proto protoreflect.FileDescriptor
+
+const file_xupu_v1_blueprint_proto_rawDesc = "" +
+	"\n" +
+	"\x17xupu/v1/blueprint.proto\x12\axupu.v1\"\x9b\x03\n" +
+	"\tBlueprint\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x19\n" +
+	"\bworld_id\x18\x02 \x01(\tR\aworldId\x12%\n" +
+	"\x0estructure_type\x18\x03 \x01(\tR\rstructureType\x12#\n" +
+	"\rchapter_count\x18\x04 \x01(\x05R\fchapterCount\x12\x1f\n" +
+	"\vscene_count\x18\x05 \x01(\x05R\n" +
+	"sceneCount\x12\x1d\n" +
+	"\n" +
+	"core_theme\x18\x06 \x01(\tR\tcoreTheme\x120\n" +
+	"\x14character_arcs_count\x18\a \x01(\x05R\x12characterArcsCount\x129\n" +
+	"\rchapter_plans\x18\b \x03(\v2\x14.xupu.v1.ChapterPlanR\fchapterPlans\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\tR\tupdatedAt\x12,\n" +
+	"\x12story_outline_json\x18\v \x01(\fR\x10storyOutlineJson\"\x9c\x02\n" +
+	"\vChapterPlan\x12\x18\n" +
+	"\achapter\x18\x01 \x01(\x05R\achapter\x12\x14\n" +
+	"\x05title\x18\x02 \x01(\tR\x05title\x12\x18\n" +
+	"\apurpose\x18\x03 \x01(\tR\apurpose\x12\x1d\n" +
+	"\n" +
+	"key_scenes\x18\x04 \x03(\tR\tkeyScenes\x12)\n" +
+	"\x10plot_advancement\x18\x05 \x01(\tR\x0fplotAdvancement\x12!\n" +
+	"\farc_progress\x18\x06 \x01(\tR\varcProgress\x12\x1f\n" +
+	"\vending_hook\x18\a \x01(\tR\n" +
+	"endingHook\x12\x1d\n" +
+	"\n" +
+	"word_count\x18\b \x01(\x05R\twordCount\x12\x16\n" +
+	"\x06status\x18\t \x01(\tR\x06status\"%\n" +
+	"\x13GetBlueprintRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"H\n" +
+	"\x14GetBlueprintResponse\x120\n" +
+	"\tblueprint\x18\x01 \x01(\v2\x12.xupu.v1.BlueprintR\tblueprint2_\n" +
+	"\x10BlueprintService\x12K\n" +
+	"\fGetBlueprint\x12\x1c.xupu.v1.GetBlueprintRequest\x1a\x1d.xupu.v1.GetBlueprintResponseB,Z*github.com/xlei/xupu/pkg/rpc/xupuv1;xupuv1b\x06proto3"
+
+var (
+	file_xupu_v1_blueprint_proto_rawDescOnce sync.Once
+	file_xupu_v1_blueprint_proto_rawDescData []byte
+)
+
+func file_xupu_v1_blueprint_proto_rawDescGZIP() []byte {
+	file_xupu_v1_blueprint_proto_rawDescOnce.Do(func() {
+		file_xupu_v1_blueprint_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_xupu_v1_blueprint_proto_rawDesc), len(file_xupu_v1_blueprint_proto_rawDesc)))
+	})
+	return file_xupu_v1_blueprint_proto_rawDescData
+}
+
+var file_xupu_v1_blueprint_proto_msgTypes = make([]protoimpl.MessageInfo, 4)
+var file_xupu_v1_blueprint_proto_goTypes = []any{
+	(*Blueprint)(nil),            // 0: xupu.v1.Blueprint
+	(*ChapterPlan)(nil),          // 1: xupu.v1.ChapterPlan
+	(*GetBlueprintRequest)(nil),  // 2: xupu.v1.GetBlueprintRequest
+	(*GetBlueprintResponse)(nil), // 3: xupu.v1.GetBlueprintResponse
+}
+var file_xupu_v1_blueprint_proto_depIdxs = []int32{
+	1, // 0: xupu.v1.Blueprint.chapter_plans:type_name -> xupu.v1.ChapterPlan
+	0, // 1: xupu.v1.GetBlueprintResponse.blueprint:type_name -> xupu.v1.Blueprint
+	2, // 2: xupu.v1.BlueprintService.GetBlueprint:input_type -> xupu.v1.GetBlueprintRequest
+	3, // 3: xupu.v1.BlueprintService.GetBlueprint:output_type -> xupu.v1.GetBlueprintResponse
+	3, // [3:4] is the sub-list for method output_type
+	2, // [2:3] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_xupu_v1_blueprint_proto_init() }
+func file_xupu_v1_blueprint_proto_init() {
+	if File_xupu_v1_blueprint_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_xupu_v1_blueprint_proto_rawDesc), len(file_xupu_v1_blueprint_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   4,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_xupu_v1_blueprint_proto_goTypes,
+		DependencyIndexes: file_xupu_v1_blueprint_proto_depIdxs,
+		MessageInfos:      file_xupu_v1_blueprint_proto_msgTypes,
+	}.Build()
+	File_xupu_v1_blueprint_proto = out.File
+	file_xupu_v1_blueprint_proto_goTypes = nil
+	file_xupu_v1_blueprint_proto_depIdxs = nil
+}
@@ -0,0 +1,127 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: xupu/v1/blueprint.proto
+
+package xupuv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	BlueprintService_GetBlueprint_FullMethodName = "/xupu.v1.BlueprintService/GetBlueprint"
+)
+
+// BlueprintServiceClient is the client API for BlueprintService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// BlueprintService 叙事蓝图，与 REST 的 /api/v1/blueprints 对应
+type BlueprintServiceClient interface {
+	// GetBlueprint 获取蓝图和章节规划
+	GetBlueprint(ctx context.Context, in *GetBlueprintRequest, opts ...grpc.CallOption) (*GetBlueprintResponse, error)
+}
+
+type blueprintServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewBlueprintServiceClient(cc grpc.ClientConnInterface) BlueprintServiceClient {
+	return &blueprintServiceClient{cc}
+}
+
+func (c *blueprintServiceClient) GetBlueprint(ctx context.Context, in *GetBlueprintRequest, opts ...grpc.CallOption) (*GetBlueprintResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetBlueprintResponse)
+	err := c.cc.Invoke(ctx, BlueprintService_GetBlueprint_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// BlueprintServiceServer is the server API for BlueprintService service.
+// All implementations must embed UnimplementedBlueprintServiceServer
+// for forward compatibility.
+//
+// BlueprintService 叙事蓝图，与 REST 的 /api/v1/blueprints 对应
+type BlueprintServiceServer interface {
+	// GetBlueprint 获取蓝图和章节规划
+	GetBlueprint(context.Context, *GetBlueprintRequest) (*GetBlueprintResponse, error)
+	mustEmbedUnimplementedBlueprintServiceServer()
+}
+
+// UnimplementedBlueprintServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedBlueprintServiceServer struct{}
+
+func (UnimplementedBlueprintServiceServer) GetBlueprint(context.Context, *GetBlueprintRequest) (*GetBlueprintResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlueprint not implemented")
+}
+func (UnimplementedBlueprintServiceServer) mustEmbedUnimplementedBlueprintServiceServer() {}
+func (UnimplementedBlueprintServiceServer) testEmbeddedByValue()                          {}
+
+// UnsafeBlueprintServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to BlueprintServiceServer will
+// result in compilation errors.
+type UnsafeBlueprintServiceServer interface {
+	mustEmbedUnimplementedBlueprintServiceServer()
+}
+
+func RegisterBlueprintServiceServer(s grpc.ServiceRegistrar, srv BlueprintServiceServer) {
+	// If the following call pancis, it indicates UnimplementedBlueprintServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&BlueprintService_ServiceDesc, srv)
+}
+
+func _BlueprintService_GetBlueprint_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetBlueprintRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BlueprintServiceServer).GetBlueprint(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: BlueprintService_GetBlueprint_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(BlueprintServiceServer).GetBlueprint(ctx, req.(*GetBlueprintRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// BlueprintService_ServiceDesc is the grpc.ServiceDesc for BlueprintService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var BlueprintService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "xupu.v1.BlueprintService",
+	HandlerType: (*BlueprintServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetBlueprint",
+			Handler:    _BlueprintService_GetBlueprint_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "xupu/v1/blueprint.proto",
+}
@@ -0,0 +1,706 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: xupu/v1/project.proto
+
+package xupuv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Project 项目
+type Project struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name           string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Description    string                 `protobuf:"bytes,3,opt,name=description,proto3" json:"description,omitempty"`
+	Mode           string                 `protobuf:"bytes,4,opt,name=mode,proto3" json:"mode,omitempty"`
+	Status         string                 `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	Progress       float64                `protobuf:"fixed64,6,opt,name=progress,proto3" json:"progress,omitempty"`
+	WorldId        string                 `protobuf:"bytes,7,opt,name=world_id,json=worldId,proto3" json:"world_id,omitempty"`
+	NarrativeId    string                 `protobuf:"bytes,8,opt,name=narrative_id,json=narrativeId,proto3" json:"narrative_id,omitempty"`
+	CreatedAt      string                 `protobuf:"bytes,9,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // RFC 3339
+	UpdatedAt      string                 `protobuf:"bytes,10,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	StyleProfileId string                 `protobuf:"bytes,11,opt,name=style_profile_id,json=styleProfileId,proto3" json:"style_profile_id,omitempty"`
+	Language       string                 `protobuf:"bytes,12,opt,name=language,proto3" json:"language,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *Project) Reset() {
+	*x = Project{}
+	mi := &file_xupu_v1_project_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Project) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Project) ProtoMessage() {}
+
+func (x *Project) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_project_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Project.ProtoReflect.Descriptor instead.
+func (*Project) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_project_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Project) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Project) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Project) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *Project) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Project) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Project) GetProgress() float64 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *Project) GetWorldId() string {
+	if x != nil {
+		return x.WorldId
+	}
+	return ""
+}
+
+func (x *Project) GetNarrativeId() string {
+	if x != nil {
+		return x.NarrativeId
+	}
+	return ""
+}
+
+func (x *Project) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Project) GetUpdatedAt() string {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return ""
+}
+
+func (x *Project) GetStyleProfileId() string {
+	if x != nil {
+		return x.StyleProfileId
+	}
+	return ""
+}
+
+func (x *Project) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+// ProjectProgress 项目创作进度
+type ProjectProgress struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	CurrentStage       string                 `protobuf:"bytes,1,opt,name=current_stage,json=currentStage,proto3" json:"current_stage,omitempty"`
+	WorldCompleted     bool                   `protobuf:"varint,2,opt,name=world_completed,json=worldCompleted,proto3" json:"world_completed,omitempty"`
+	NarrativeCompleted bool                   `protobuf:"varint,3,opt,name=narrative_completed,json=narrativeCompleted,proto3" json:"narrative_completed,omitempty"`
+	TotalChapters      int32                  `protobuf:"varint,4,opt,name=total_chapters,json=totalChapters,proto3" json:"total_chapters,omitempty"`
+	TotalScenes        int32                  `protobuf:"varint,5,opt,name=total_scenes,json=totalScenes,proto3" json:"total_scenes,omitempty"`
+	GeneratedScenes    int32                  `protobuf:"varint,6,opt,name=generated_scenes,json=generatedScenes,proto3" json:"generated_scenes,omitempty"`
+	WordCount          int32                  `protobuf:"varint,7,opt,name=word_count,json=wordCount,proto3" json:"word_count,omitempty"`
+	CompletionPercent  float64                `protobuf:"fixed64,8,opt,name=completion_percent,json=completionPercent,proto3" json:"completion_percent,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *ProjectProgress) Reset() {
+	*x = ProjectProgress{}
+	mi := &file_xupu_v1_project_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ProjectProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ProjectProgress) ProtoMessage() {}
+
+func (x *ProjectProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_project_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ProjectProgress.ProtoReflect.Descriptor instead.
+func (*ProjectProgress) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_project_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ProjectProgress) GetCurrentStage() string {
+	if x != nil {
+		return x.CurrentStage
+	}
+	return ""
+}
+
+func (x *ProjectProgress) GetWorldCompleted() bool {
+	if x != nil {
+		return x.WorldCompleted
+	}
+	return false
+}
+
+func (x *ProjectProgress) GetNarrativeCompleted() bool {
+	if x != nil {
+		return x.NarrativeCompleted
+	}
+	return false
+}
+
+func (x *ProjectProgress) GetTotalChapters() int32 {
+	if x != nil {
+		return x.TotalChapters
+	}
+	return 0
+}
+
+func (x *ProjectProgress) GetTotalScenes() int32 {
+	if x != nil {
+		return x.TotalScenes
+	}
+	return 0
+}
+
+func (x *ProjectProgress) GetGeneratedScenes() int32 {
+	if x != nil {
+		return x.GeneratedScenes
+	}
+	return 0
+}
+
+func (x *ProjectProgress) GetWordCount() int32 {
+	if x != nil {
+		return x.WordCount
+	}
+	return 0
+}
+
+func (x *ProjectProgress) GetCompletionPercent() float64 {
+	if x != nil {
+		return x.CompletionPercent
+	}
+	return 0
+}
+
+type ListProjectsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`               // all/draft/generating/completed，默认 all
+	SortBy        string                 `protobuf:"bytes,2,opt,name=sort_by,json=sortBy,proto3" json:"sort_by,omitempty"` // updated/created/name，默认 updated
+	Search        string                 `protobuf:"bytes,3,opt,name=search,proto3" json:"search,omitempty"`               // 按名称和简介搜索
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProjectsRequest) Reset() {
+	*x = ListProjectsRequest{}
+	mi := &file_xupu_v1_project_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProjectsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProjectsRequest) ProtoMessage() {}
+
+func (x *ListProjectsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_project_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProjectsRequest.ProtoReflect.Descriptor instead.
+func (*ListProjectsRequest) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_project_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListProjectsRequest) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *ListProjectsRequest) GetSortBy() string {
+	if x != nil {
+		return x.SortBy
+	}
+	return ""
+}
+
+func (x *ListProjectsRequest) GetSearch() string {
+	if x != nil {
+		return x.Search
+	}
+	return ""
+}
+
+type ListProjectsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Projects      []*Project             `protobuf:"bytes,1,rep,name=projects,proto3" json:"projects,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProjectsResponse) Reset() {
+	*x = ListProjectsResponse{}
+	mi := &file_xupu_v1_project_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProjectsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProjectsResponse) ProtoMessage() {}
+
+func (x *ListProjectsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_project_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProjectsResponse.ProtoReflect.Descriptor instead.
+func (*ListProjectsResponse) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_project_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ListProjectsResponse) GetProjects() []*Project {
+	if x != nil {
+		return x.Projects
+	}
+	return nil
+}
+
+func (x *ListProjectsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+type GetProjectRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProjectRequest) Reset() {
+	*x = GetProjectRequest{}
+	mi := &file_xupu_v1_project_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProjectRequest) ProtoMessage() {}
+
+func (x *GetProjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_project_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProjectRequest.ProtoReflect.Descriptor instead.
+func (*GetProjectRequest) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_project_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetProjectRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetProjectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       *Project               `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	Progress      *ProjectProgress       `protobuf:"bytes,2,opt,name=progress,proto3" json:"progress,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetProjectResponse) Reset() {
+	*x = GetProjectResponse{}
+	mi := &file_xupu_v1_project_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetProjectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetProjectResponse) ProtoMessage() {}
+
+func (x *GetProjectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_project_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetProjectResponse.ProtoReflect.Descriptor instead.
+func (*GetProjectResponse) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_project_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetProjectResponse) GetProject() *Project {
+	if x != nil {
+		return x.Project
+	}
+	return nil
+}
+
+func (x *GetProjectResponse) GetProgress() *ProjectProgress {
+	if x != nil {
+		return x.Progress
+	}
+	return nil
+}
+
+type CreateProjectRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description    string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Mode           string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"` // planning/intervention/random/story_core/short/script/assisted/workflow
+	StyleProfileId string                 `protobuf:"bytes,4,opt,name=style_profile_id,json=styleProfileId,proto3" json:"style_profile_id,omitempty"`
+	Language       string                 `protobuf:"bytes,5,opt,name=language,proto3" json:"language,omitempty"` // zh-CN/zh-TW/en/ja，默认简体中文
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateProjectRequest) Reset() {
+	*x = CreateProjectRequest{}
+	mi := &file_xupu_v1_project_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProjectRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProjectRequest) ProtoMessage() {}
+
+func (x *CreateProjectRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_project_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProjectRequest.ProtoReflect.Descriptor instead.
+func (*CreateProjectRequest) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_project_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CreateProjectRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateProjectRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateProjectRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *CreateProjectRequest) GetStyleProfileId() string {
+	if x != nil {
+		return x.StyleProfileId
+	}
+	return ""
+}
+
+func (x *CreateProjectRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+type CreateProjectResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Project       *Project               `protobuf:"bytes,1,opt,name=project,proto3" json:"project,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateProjectResponse) Reset() {
+	*x = CreateProjectResponse{}
+	mi := &file_xupu_v1_project_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProjectResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProjectResponse) ProtoMessage() {}
+
+func (x *CreateProjectResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_project_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProjectResponse.ProtoReflect.Descriptor instead.
+func (*CreateProjectResponse) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_project_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *CreateProjectResponse) GetProject() *Project {
+	if x != nil {
+		return x.Project
+	}
+	return nil
+}
+
+var File_xupu_v1_project_proto protoreflect.FileDescriptor
+
+const file_xupu_v1_project_proto_rawDesc = "" +
+	"\n" +
+	"\x15xupu/v1/project.proto\x12\axupu.v1\"\xd9\x02\n" +
+	"\aProject\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x03 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04mode\x18\x04 \x01(\tR\x04mode\x12\x16\n" +
+	"\x06status\x18\x05 \x01(\tR\x06status\x12\x1a\n" +
+	"\bprogress\x18\x06 \x01(\x01R\bprogress\x12\x19\n" +
+	"\bworld_id\x18\a \x01(\tR\aworldId\x12!\n" +
+	"\fnarrative_id\x18\b \x01(\tR\vnarrativeId\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\t \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\n" +
+	" \x01(\tR\tupdatedAt\x12(\n" +
+	"\x10style_profile_id\x18\v \x01(\tR\x0estyleProfileId\x12\x1a\n" +
+	"\blanguage\x18\f \x01(\tR\blanguage\"\xd3\x02\n" +
+	"\x0fProjectProgress\x12#\n" +
+	"\rcurrent_stage\x18\x01 \x01(\tR\fcurrentStage\x12'\n" +
+	"\x0fworld_completed\x18\x02 \x01(\bR\x0eworldCompleted\x12/\n" +
+	"\x13narrative_completed\x18\x03 \x01(\bR\x12narrativeCompleted\x12%\n" +
+	"\x0etotal_chapters\x18\x04 \x01(\x05R\rtotalChapters\x12!\n" +
+	"\ftotal_scenes\x18\x05 \x01(\x05R\vtotalScenes\x12)\n" +
+	"\x10generated_scenes\x18\x06 \x01(\x05R\x0fgeneratedScenes\x12\x1d\n" +
+	"\n" +
+	"word_count\x18\a \x01(\x05R\twordCount\x12-\n" +
+	"\x12completion_percent\x18\b \x01(\x01R\x11completionPercent\"^\n" +
+	"\x13ListProjectsRequest\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x17\n" +
+	"\asort_by\x18\x02 \x01(\tR\x06sortBy\x12\x16\n" +
+	"\x06search\x18\x03 \x01(\tR\x06search\"Z\n" +
+	"\x14ListProjectsResponse\x12,\n" +
+	"\bprojects\x18\x01 \x03(\v2\x10.xupu.v1.ProjectR\bprojects\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\"#\n" +
+	"\x11GetProjectRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"v\n" +
+	"\x12GetProjectResponse\x12*\n" +
+	"\aproject\x18\x01 \x01(\v2\x10.xupu.v1.ProjectR\aproject\x124\n" +
+	"\bprogress\x18\x02 \x01(\v2\x18.xupu.v1.ProjectProgressR\bprogress\"\xa6\x01\n" +
+	"\x14CreateProjectRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\x12(\n" +
+	"\x10style_profile_id\x18\x04 \x01(\tR\x0estyleProfileId\x12\x1a\n" +
+	"\blanguage\x18\x05 \x01(\tR\blanguage\"C\n" +
+	"\x15CreateProjectResponse\x12*\n" +
+	"\aproject\x18\x01 \x01(\v2\x10.xupu.v1.ProjectR\aproject2\xf4\x01\n" +
+	"\x0eProjectService\x12K\n" +
+	"\fListProjects\x12\x1c.xupu.v1.ListProjectsRequest\x1a\x1d.xupu.v1.ListProjectsResponse\x12E\n" +
+	"\n" +
+	"GetProject\x12\x1a.xupu.v1.GetProjectRequest\x1a\x1b.xupu.v1.GetProjectResponse\x12N\n" +
+	"\rCreateProject\x12\x1d.xupu.v1.CreateProjectRequest\x1a\x1e.xupu.v1.CreateProjectResponseB,Z*github.com/xlei/xupu/pkg/rpc/xupuv1;xupuv1b\x06proto3"
+
+var (
+	file_xupu_v1_project_proto_rawDescOnce sync.Once
+	file_xupu_v1_project_proto_rawDescData []byte
+)
+
+func file_xupu_v1_project_proto_rawDescGZIP() []byte {
+	file_xupu_v1_project_proto_rawDescOnce.Do(func() {
+		file_xupu_v1_project_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_xupu_v1_project_proto_rawDesc), len(file_xupu_v1_project_proto_rawDesc)))
+	})
+	return file_xupu_v1_project_proto_rawDescData
+}
+
+var file_xupu_v1_project_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_xupu_v1_project_proto_goTypes = []any{
+	(*Project)(nil),               // 0: xupu.v1.Project
+	(*ProjectProgress)(nil),       // 1: xupu.v1.ProjectProgress
+	(*ListProjectsRequest)(nil),   // 2: xupu.v1.ListProjectsRequest
+	(*ListProjectsResponse)(nil),  // 3: xupu.v1.ListProjectsResponse
+	(*GetProjectRequest)(nil),     // 4: xupu.v1.GetProjectRequest
+	(*GetProjectResponse)(nil),    // 5: xupu.v1.GetProjectResponse
+	(*CreateProjectRequest)(nil),  // 6: xupu.v1.CreateProjectRequest
+	(*CreateProjectResponse)(nil), // 7: xupu.v1.CreateProjectResponse
+}
+var file_xupu_v1_project_proto_depIdxs = []int32{
+	0, // 0: xupu.v1.ListProjectsResponse.projects:type_name -> xupu.v1.Project
+	0, // 1: xupu.v1.GetProjectResponse.project:type_name -> xupu.v1.Project
+	1, // 2: xupu.v1.GetProjectResponse.progress:type_name -> xupu.v1.ProjectProgress
+	0, // 3: xupu.v1.CreateProjectResponse.project:type_name -> xupu.v1.Project
+	2, // 4: xupu.v1.ProjectService.ListProjects:input_type -> xupu.v1.ListProjectsRequest
+	4, // 5: xupu.v1.ProjectService.GetProject:input_type -> xupu.v1.GetProjectRequest
+	6, // 6: xupu.v1.ProjectService.CreateProject:input_type -> xupu.v1.CreateProjectRequest
+	3, // 7: xupu.v1.ProjectService.ListProjects:output_type -> xupu.v1.ListProjectsResponse
+	5, // 8: xupu.v1.ProjectService.GetProject:output_type -> xupu.v1.GetProjectResponse
+	7, // 9: xupu.v1.ProjectService.CreateProject:output_type -> xupu.v1.CreateProjectResponse
+	7, // [7:10] is the sub-list for method output_type
+	4, // [4:7] is the sub-list for method input_type
+	4, // [4:4] is the sub-list for extension type_name
+	4, // [4:4] is the sub-list for extension extendee
+	0, // [0:4] is the sub-list for field type_name
+}
+
+func init() { file_xupu_v1_project_proto_init() }
+func file_xupu_v1_project_proto_init() {
+	if File_xupu_v1_project_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_xupu_v1_project_proto_rawDesc), len(file_xupu_v1_project_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   8,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_xupu_v1_project_proto_goTypes,
+		DependencyIndexes: file_xupu_v1_project_proto_depIdxs,
+		MessageInfos:      file_xupu_v1_project_proto_msgTypes,
+	}.Build()
+	File_xupu_v1_project_proto = out.File
+	file_xupu_v1_project_proto_goTypes = nil
+	file_xupu_v1_project_proto_depIdxs = nil
+}
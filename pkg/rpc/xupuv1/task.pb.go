@@ -0,0 +1,994 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: xupu/v1/task.proto
+
+package xupuv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// Task 任务状态
+type Task struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Status        string                 `protobuf:"bytes,3,opt,name=status,proto3" json:"status,omitempty"` // pending/running/completed/failed/cancelled/paused
+	Progress      float64                `protobuf:"fixed64,4,opt,name=progress,proto3" json:"progress,omitempty"`
+	Priority      int32                  `protobuf:"varint,5,opt,name=priority,proto3" json:"priority,omitempty"`
+	CreatedAt     string                 `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // RFC 3339
+	StartedAt     string                 `protobuf:"bytes,7,opt,name=started_at,json=startedAt,proto3" json:"started_at,omitempty"`
+	CompletedAt   string                 `protobuf:"bytes,8,opt,name=completed_at,json=completedAt,proto3" json:"completed_at,omitempty"`
+	Error         string                 `protobuf:"bytes,9,opt,name=error,proto3" json:"error,omitempty"`
+	ProjectId     string                 `protobuf:"bytes,10,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Task) Reset() {
+	*x = Task{}
+	mi := &file_xupu_v1_task_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Task) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Task) ProtoMessage() {}
+
+func (x *Task) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Task.ProtoReflect.Descriptor instead.
+func (*Task) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Task) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Task) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Task) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *Task) GetProgress() float64 {
+	if x != nil {
+		return x.Progress
+	}
+	return 0
+}
+
+func (x *Task) GetPriority() int32 {
+	if x != nil {
+		return x.Priority
+	}
+	return 0
+}
+
+func (x *Task) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *Task) GetStartedAt() string {
+	if x != nil {
+		return x.StartedAt
+	}
+	return ""
+}
+
+func (x *Task) GetCompletedAt() string {
+	if x != nil {
+		return x.CompletedAt
+	}
+	return ""
+}
+
+func (x *Task) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Task) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+// GenerationOptions 生成选项
+type GenerationOptions struct {
+	state               protoimpl.MessageState `protogen:"open.v1"`
+	SkipWorldBuild      bool                   `protobuf:"varint,1,opt,name=skip_world_build,json=skipWorldBuild,proto3" json:"skip_world_build,omitempty"`
+	ExistingWorldId     string                 `protobuf:"bytes,2,opt,name=existing_world_id,json=existingWorldId,proto3" json:"existing_world_id,omitempty"`
+	SkipNarrative       bool                   `protobuf:"varint,3,opt,name=skip_narrative,json=skipNarrative,proto3" json:"skip_narrative,omitempty"`
+	ExistingBlueprintId string                 `protobuf:"bytes,4,opt,name=existing_blueprint_id,json=existingBlueprintId,proto3" json:"existing_blueprint_id,omitempty"`
+	GenerateContent     bool                   `protobuf:"varint,5,opt,name=generate_content,json=generateContent,proto3" json:"generate_content,omitempty"`
+	StartChapter        int32                  `protobuf:"varint,6,opt,name=start_chapter,json=startChapter,proto3" json:"start_chapter,omitempty"`
+	EndChapter          int32                  `protobuf:"varint,7,opt,name=end_chapter,json=endChapter,proto3" json:"end_chapter,omitempty"`
+	Style               string                 `protobuf:"bytes,8,opt,name=style,proto3" json:"style,omitempty"`
+	MaxRevisions        int32                  `protobuf:"varint,9,opt,name=max_revisions,json=maxRevisions,proto3" json:"max_revisions,omitempty"`
+	unknownFields       protoimpl.UnknownFields
+	sizeCache           protoimpl.SizeCache
+}
+
+func (x *GenerationOptions) Reset() {
+	*x = GenerationOptions{}
+	mi := &file_xupu_v1_task_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GenerationOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GenerationOptions) ProtoMessage() {}
+
+func (x *GenerationOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GenerationOptions.ProtoReflect.Descriptor instead.
+func (*GenerationOptions) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *GenerationOptions) GetSkipWorldBuild() bool {
+	if x != nil {
+		return x.SkipWorldBuild
+	}
+	return false
+}
+
+func (x *GenerationOptions) GetExistingWorldId() string {
+	if x != nil {
+		return x.ExistingWorldId
+	}
+	return ""
+}
+
+func (x *GenerationOptions) GetSkipNarrative() bool {
+	if x != nil {
+		return x.SkipNarrative
+	}
+	return false
+}
+
+func (x *GenerationOptions) GetExistingBlueprintId() string {
+	if x != nil {
+		return x.ExistingBlueprintId
+	}
+	return ""
+}
+
+func (x *GenerationOptions) GetGenerateContent() bool {
+	if x != nil {
+		return x.GenerateContent
+	}
+	return false
+}
+
+func (x *GenerationOptions) GetStartChapter() int32 {
+	if x != nil {
+		return x.StartChapter
+	}
+	return 0
+}
+
+func (x *GenerationOptions) GetEndChapter() int32 {
+	if x != nil {
+		return x.EndChapter
+	}
+	return 0
+}
+
+func (x *GenerationOptions) GetStyle() string {
+	if x != nil {
+		return x.Style
+	}
+	return ""
+}
+
+func (x *GenerationOptions) GetMaxRevisions() int32 {
+	if x != nil {
+		return x.MaxRevisions
+	}
+	return 0
+}
+
+// CreateProjectTaskRequest 创作参数，字段和校验规则同 REST 创建项目请求的 params
+type CreateProjectTaskRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description    string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	Mode           string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	StyleProfileId string                 `protobuf:"bytes,4,opt,name=style_profile_id,json=styleProfileId,proto3" json:"style_profile_id,omitempty"`
+	Language       string                 `protobuf:"bytes,5,opt,name=language,proto3" json:"language,omitempty"`
+	WorldName      string                 `protobuf:"bytes,10,opt,name=world_name,json=worldName,proto3" json:"world_name,omitempty"`
+	WorldType      string                 `protobuf:"bytes,11,opt,name=world_type,json=worldType,proto3" json:"world_type,omitempty"` // fantasy/scifi/historical/urban/wuxia/xianxia/mixed
+	WorldTheme     string                 `protobuf:"bytes,12,opt,name=world_theme,json=worldTheme,proto3" json:"world_theme,omitempty"`
+	WorldScale     string                 `protobuf:"bytes,13,opt,name=world_scale,json=worldScale,proto3" json:"world_scale,omitempty"` // village/city/nation/continent/planet/universe
+	WorldStyle     string                 `protobuf:"bytes,14,opt,name=world_style,json=worldStyle,proto3" json:"world_style,omitempty"`
+	WorldTier      string                 `protobuf:"bytes,15,opt,name=world_tier,json=worldTier,proto3" json:"world_tier,omitempty"` // quick/standard/deep
+	StoryType      string                 `protobuf:"bytes,20,opt,name=story_type,json=storyType,proto3" json:"story_type,omitempty"`
+	Theme          string                 `protobuf:"bytes,21,opt,name=theme,proto3" json:"theme,omitempty"`
+	Protagonist    string                 `protobuf:"bytes,22,opt,name=protagonist,proto3" json:"protagonist,omitempty"`
+	Length         string                 `protobuf:"bytes,23,opt,name=length,proto3" json:"length,omitempty"` // short/medium/long
+	ChapterCount   int32                  `protobuf:"varint,24,opt,name=chapter_count,json=chapterCount,proto3" json:"chapter_count,omitempty"`
+	Structure      string                 `protobuf:"bytes,25,opt,name=structure,proto3" json:"structure,omitempty"`
+	Genre          string                 `protobuf:"bytes,26,opt,name=genre,proto3" json:"genre,omitempty"`
+	Options        *GenerationOptions     `protobuf:"bytes,30,opt,name=options,proto3" json:"options,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CreateProjectTaskRequest) Reset() {
+	*x = CreateProjectTaskRequest{}
+	mi := &file_xupu_v1_task_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProjectTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProjectTaskRequest) ProtoMessage() {}
+
+func (x *CreateProjectTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProjectTaskRequest.ProtoReflect.Descriptor instead.
+func (*CreateProjectTaskRequest) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *CreateProjectTaskRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetStyleProfileId() string {
+	if x != nil {
+		return x.StyleProfileId
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetLanguage() string {
+	if x != nil {
+		return x.Language
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetWorldName() string {
+	if x != nil {
+		return x.WorldName
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetWorldType() string {
+	if x != nil {
+		return x.WorldType
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetWorldTheme() string {
+	if x != nil {
+		return x.WorldTheme
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetWorldScale() string {
+	if x != nil {
+		return x.WorldScale
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetWorldStyle() string {
+	if x != nil {
+		return x.WorldStyle
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetWorldTier() string {
+	if x != nil {
+		return x.WorldTier
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetStoryType() string {
+	if x != nil {
+		return x.StoryType
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetTheme() string {
+	if x != nil {
+		return x.Theme
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetProtagonist() string {
+	if x != nil {
+		return x.Protagonist
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetLength() string {
+	if x != nil {
+		return x.Length
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetChapterCount() int32 {
+	if x != nil {
+		return x.ChapterCount
+	}
+	return 0
+}
+
+func (x *CreateProjectTaskRequest) GetStructure() string {
+	if x != nil {
+		return x.Structure
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetGenre() string {
+	if x != nil {
+		return x.Genre
+	}
+	return ""
+}
+
+func (x *CreateProjectTaskRequest) GetOptions() *GenerationOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+type CreateProjectTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CreateProjectTaskResponse) Reset() {
+	*x = CreateProjectTaskResponse{}
+	mi := &file_xupu_v1_task_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CreateProjectTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CreateProjectTaskResponse) ProtoMessage() {}
+
+func (x *CreateProjectTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CreateProjectTaskResponse.ProtoReflect.Descriptor instead.
+func (*CreateProjectTaskResponse) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *CreateProjectTaskResponse) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+type GetTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskRequest) Reset() {
+	*x = GetTaskRequest{}
+	mi := &file_xupu_v1_task_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskRequest) ProtoMessage() {}
+
+func (x *GetTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskRequest.ProtoReflect.Descriptor instead.
+func (*GetTaskRequest) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetTaskResponse) Reset() {
+	*x = GetTaskResponse{}
+	mi := &file_xupu_v1_task_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetTaskResponse) ProtoMessage() {}
+
+func (x *GetTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetTaskResponse.ProtoReflect.Descriptor instead.
+func (*GetTaskResponse) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetTaskResponse) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+type ListProjectTasksRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ProjectId     string                 `protobuf:"bytes,1,opt,name=project_id,json=projectId,proto3" json:"project_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProjectTasksRequest) Reset() {
+	*x = ListProjectTasksRequest{}
+	mi := &file_xupu_v1_task_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProjectTasksRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProjectTasksRequest) ProtoMessage() {}
+
+func (x *ListProjectTasksRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProjectTasksRequest.ProtoReflect.Descriptor instead.
+func (*ListProjectTasksRequest) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListProjectTasksRequest) GetProjectId() string {
+	if x != nil {
+		return x.ProjectId
+	}
+	return ""
+}
+
+type ListProjectTasksResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Tasks         []*Task                `protobuf:"bytes,1,rep,name=tasks,proto3" json:"tasks,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListProjectTasksResponse) Reset() {
+	*x = ListProjectTasksResponse{}
+	mi := &file_xupu_v1_task_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListProjectTasksResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListProjectTasksResponse) ProtoMessage() {}
+
+func (x *ListProjectTasksResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListProjectTasksResponse.ProtoReflect.Descriptor instead.
+func (*ListProjectTasksResponse) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *ListProjectTasksResponse) GetTasks() []*Task {
+	if x != nil {
+		return x.Tasks
+	}
+	return nil
+}
+
+type CancelTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTaskRequest) Reset() {
+	*x = CancelTaskRequest{}
+	mi := &file_xupu_v1_task_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTaskRequest) ProtoMessage() {}
+
+func (x *CancelTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTaskRequest.ProtoReflect.Descriptor instead.
+func (*CancelTaskRequest) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *CancelTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type CancelTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CancelTaskResponse) Reset() {
+	*x = CancelTaskResponse{}
+	mi := &file_xupu_v1_task_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CancelTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CancelTaskResponse) ProtoMessage() {}
+
+func (x *CancelTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CancelTaskResponse.ProtoReflect.Descriptor instead.
+func (*CancelTaskResponse) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *CancelTaskResponse) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+type WatchTaskRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTaskRequest) Reset() {
+	*x = WatchTaskRequest{}
+	mi := &file_xupu_v1_task_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTaskRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTaskRequest) ProtoMessage() {}
+
+func (x *WatchTaskRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTaskRequest.ProtoReflect.Descriptor instead.
+func (*WatchTaskRequest) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *WatchTaskRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type WatchTaskResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Task          *Task                  `protobuf:"bytes,1,opt,name=task,proto3" json:"task,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchTaskResponse) Reset() {
+	*x = WatchTaskResponse{}
+	mi := &file_xupu_v1_task_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchTaskResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchTaskResponse) ProtoMessage() {}
+
+func (x *WatchTaskResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_task_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchTaskResponse.ProtoReflect.Descriptor instead.
+func (*WatchTaskResponse) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_task_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WatchTaskResponse) GetTask() *Task {
+	if x != nil {
+		return x.Task
+	}
+	return nil
+}
+
+var File_xupu_v1_task_proto protoreflect.FileDescriptor
+
+const file_xupu_v1_task_proto_rawDesc = "" +
+	"\n" +
+	"\x12xupu/v1/task.proto\x12\axupu.v1\"\x90\x02\n" +
+	"\x04Task\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x16\n" +
+	"\x06status\x18\x03 \x01(\tR\x06status\x12\x1a\n" +
+	"\bprogress\x18\x04 \x01(\x01R\bprogress\x12\x1a\n" +
+	"\bpriority\x18\x05 \x01(\x05R\bpriority\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\tR\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"started_at\x18\a \x01(\tR\tstartedAt\x12!\n" +
+	"\fcompleted_at\x18\b \x01(\tR\vcompletedAt\x12\x14\n" +
+	"\x05error\x18\t \x01(\tR\x05error\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\n" +
+	" \x01(\tR\tprojectId\"\xf0\x02\n" +
+	"\x11GenerationOptions\x12(\n" +
+	"\x10skip_world_build\x18\x01 \x01(\bR\x0eskipWorldBuild\x12*\n" +
+	"\x11existing_world_id\x18\x02 \x01(\tR\x0fexistingWorldId\x12%\n" +
+	"\x0eskip_narrative\x18\x03 \x01(\bR\rskipNarrative\x122\n" +
+	"\x15existing_blueprint_id\x18\x04 \x01(\tR\x13existingBlueprintId\x12)\n" +
+	"\x10generate_content\x18\x05 \x01(\bR\x0fgenerateContent\x12#\n" +
+	"\rstart_chapter\x18\x06 \x01(\x05R\fstartChapter\x12\x1f\n" +
+	"\vend_chapter\x18\a \x01(\x05R\n" +
+	"endChapter\x12\x14\n" +
+	"\x05style\x18\b \x01(\tR\x05style\x12#\n" +
+	"\rmax_revisions\x18\t \x01(\x05R\fmaxRevisions\"\xe8\x04\n" +
+	"\x18CreateProjectTaskRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12\x12\n" +
+	"\x04mode\x18\x03 \x01(\tR\x04mode\x12(\n" +
+	"\x10style_profile_id\x18\x04 \x01(\tR\x0estyleProfileId\x12\x1a\n" +
+	"\blanguage\x18\x05 \x01(\tR\blanguage\x12\x1d\n" +
+	"\n" +
+	"world_name\x18\n" +
+	" \x01(\tR\tworldName\x12\x1d\n" +
+	"\n" +
+	"world_type\x18\v \x01(\tR\tworldType\x12\x1f\n" +
+	"\vworld_theme\x18\f \x01(\tR\n" +
+	"worldTheme\x12\x1f\n" +
+	"\vworld_scale\x18\r \x01(\tR\n" +
+	"worldScale\x12\x1f\n" +
+	"\vworld_style\x18\x0e \x01(\tR\n" +
+	"worldStyle\x12\x1d\n" +
+	"\n" +
+	"world_tier\x18\x0f \x01(\tR\tworldTier\x12\x1d\n" +
+	"\n" +
+	"story_type\x18\x14 \x01(\tR\tstoryType\x12\x14\n" +
+	"\x05theme\x18\x15 \x01(\tR\x05theme\x12 \n" +
+	"\vprotagonist\x18\x16 \x01(\tR\vprotagonist\x12\x16\n" +
+	"\x06length\x18\x17 \x01(\tR\x06length\x12#\n" +
+	"\rchapter_count\x18\x18 \x01(\x05R\fchapterCount\x12\x1c\n" +
+	"\tstructure\x18\x19 \x01(\tR\tstructure\x12\x14\n" +
+	"\x05genre\x18\x1a \x01(\tR\x05genre\x124\n" +
+	"\aoptions\x18\x1e \x01(\v2\x1a.xupu.v1.GenerationOptionsR\aoptions\">\n" +
+	"\x19CreateProjectTaskResponse\x12!\n" +
+	"\x04task\x18\x01 \x01(\v2\r.xupu.v1.TaskR\x04task\" \n" +
+	"\x0eGetTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"4\n" +
+	"\x0fGetTaskResponse\x12!\n" +
+	"\x04task\x18\x01 \x01(\v2\r.xupu.v1.TaskR\x04task\"8\n" +
+	"\x17ListProjectTasksRequest\x12\x1d\n" +
+	"\n" +
+	"project_id\x18\x01 \x01(\tR\tprojectId\"?\n" +
+	"\x18ListProjectTasksResponse\x12#\n" +
+	"\x05tasks\x18\x01 \x03(\v2\r.xupu.v1.TaskR\x05tasks\"#\n" +
+	"\x11CancelTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"7\n" +
+	"\x12CancelTaskResponse\x12!\n" +
+	"\x04task\x18\x01 \x01(\v2\r.xupu.v1.TaskR\x04task\"\"\n" +
+	"\x10WatchTaskRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\"6\n" +
+	"\x11WatchTaskResponse\x12!\n" +
+	"\x04task\x18\x01 \x01(\v2\r.xupu.v1.TaskR\x04task2\x8d\x03\n" +
+	"\vTaskService\x12Z\n" +
+	"\x11CreateProjectTask\x12!.xupu.v1.CreateProjectTaskRequest\x1a\".xupu.v1.CreateProjectTaskResponse\x12<\n" +
+	"\aGetTask\x12\x17.xupu.v1.GetTaskRequest\x1a\x18.xupu.v1.GetTaskResponse\x12W\n" +
+	"\x10ListProjectTasks\x12 .xupu.v1.ListProjectTasksRequest\x1a!.xupu.v1.ListProjectTasksResponse\x12E\n" +
+	"\n" +
+	"CancelTask\x12\x1a.xupu.v1.CancelTaskRequest\x1a\x1b.xupu.v1.CancelTaskResponse\x12D\n" +
+	"\tWatchTask\x12\x19.xupu.v1.WatchTaskRequest\x1a\x1a.xupu.v1.WatchTaskResponse0\x01B,Z*github.com/xlei/xupu/pkg/rpc/xupuv1;xupuv1b\x06proto3"
+
+var (
+	file_xupu_v1_task_proto_rawDescOnce sync.Once
+	file_xupu_v1_task_proto_rawDescData []byte
+)
+
+func file_xupu_v1_task_proto_rawDescGZIP() []byte {
+	file_xupu_v1_task_proto_rawDescOnce.Do(func() {
+		file_xupu_v1_task_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_xupu_v1_task_proto_rawDesc), len(file_xupu_v1_task_proto_rawDesc)))
+	})
+	return file_xupu_v1_task_proto_rawDescData
+}
+
+var file_xupu_v1_task_proto_msgTypes = make([]protoimpl.MessageInfo, 12)
+var file_xupu_v1_task_proto_goTypes = []any{
+	(*Task)(nil),                      // 0: xupu.v1.Task
+	(*GenerationOptions)(nil),         // 1: xupu.v1.GenerationOptions
+	(*CreateProjectTaskRequest)(nil),  // 2: xupu.v1.CreateProjectTaskRequest
+	(*CreateProjectTaskResponse)(nil), // 3: xupu.v1.CreateProjectTaskResponse
+	(*GetTaskRequest)(nil),            // 4: xupu.v1.GetTaskRequest
+	(*GetTaskResponse)(nil),           // 5: xupu.v1.GetTaskResponse
+	(*ListProjectTasksRequest)(nil),   // 6: xupu.v1.ListProjectTasksRequest
+	(*ListProjectTasksResponse)(nil),  // 7: xupu.v1.ListProjectTasksResponse
+	(*CancelTaskRequest)(nil),         // 8: xupu.v1.CancelTaskRequest
+	(*CancelTaskResponse)(nil),        // 9: xupu.v1.CancelTaskResponse
+	(*WatchTaskRequest)(nil),          // 10: xupu.v1.WatchTaskRequest
+	(*WatchTaskResponse)(nil),         // 11: xupu.v1.WatchTaskResponse
+}
+var file_xupu_v1_task_proto_depIdxs = []int32{
+	1,  // 0: xupu.v1.CreateProjectTaskRequest.options:type_name -> xupu.v1.GenerationOptions
+	0,  // 1: xupu.v1.CreateProjectTaskResponse.task:type_name -> xupu.v1.Task
+	0,  // 2: xupu.v1.GetTaskResponse.task:type_name -> xupu.v1.Task
+	0,  // 3: xupu.v1.ListProjectTasksResponse.tasks:type_name -> xupu.v1.Task
+	0,  // 4: xupu.v1.CancelTaskResponse.task:type_name -> xupu.v1.Task
+	0,  // 5: xupu.v1.WatchTaskResponse.task:type_name -> xupu.v1.Task
+	2,  // 6: xupu.v1.TaskService.CreateProjectTask:input_type -> xupu.v1.CreateProjectTaskRequest
+	4,  // 7: xupu.v1.TaskService.GetTask:input_type -> xupu.v1.GetTaskRequest
+	6,  // 8: xupu.v1.TaskService.ListProjectTasks:input_type -> xupu.v1.ListProjectTasksRequest
+	8,  // 9: xupu.v1.TaskService.CancelTask:input_type -> xupu.v1.CancelTaskRequest
+	10, // 10: xupu.v1.TaskService.WatchTask:input_type -> xupu.v1.WatchTaskRequest
+	3,  // 11: xupu.v1.TaskService.CreateProjectTask:output_type -> xupu.v1.CreateProjectTaskResponse
+	5,  // 12: xupu.v1.TaskService.GetTask:output_type -> xupu.v1.GetTaskResponse
+	7,  // 13: xupu.v1.TaskService.ListProjectTasks:output_type -> xupu.v1.ListProjectTasksResponse
+	9,  // 14: xupu.v1.TaskService.CancelTask:output_type -> xupu.v1.CancelTaskResponse
+	11, // 15: xupu.v1.TaskService.WatchTask:output_type -> xupu.v1.WatchTaskResponse
+	11, // [11:16] is the sub-list for method output_type
+	6,  // [6:11] is the sub-list for method input_type
+	6,  // [6:6] is the sub-list for extension type_name
+	6,  // [6:6] is the sub-list for extension extendee
+	0,  // [0:6] is the sub-list for field type_name
+}
+
+func init() { file_xupu_v1_task_proto_init() }
+func file_xupu_v1_task_proto_init() {
+	if File_xupu_v1_task_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_xupu_v1_task_proto_rawDesc), len(file_xupu_v1_task_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   12,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_xupu_v1_task_proto_goTypes,
+		DependencyIndexes: file_xupu_v1_task_proto_depIdxs,
+		MessageInfos:      file_xupu_v1_task_proto_msgTypes,
+	}.Build()
+	File_xupu_v1_task_proto = out.File
+	file_xupu_v1_task_proto_goTypes = nil
+	file_xupu_v1_task_proto_depIdxs = nil
+}
@@ -0,0 +1,443 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.9
+// 	protoc        (unknown)
+// source: xupu/v1/world.proto
+
+package xupuv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+// World 世界设定摘要
+type World struct {
+	state                protoimpl.MessageState `protogen:"open.v1"`
+	Id                   string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name                 string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Type                 string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	Scale                string                 `protobuf:"bytes,4,opt,name=scale,proto3" json:"scale,omitempty"`
+	Style                string                 `protobuf:"bytes,5,opt,name=style,proto3" json:"style,omitempty"`
+	BuildTier            string                 `protobuf:"bytes,6,opt,name=build_tier,json=buildTier,proto3" json:"build_tier,omitempty"`
+	CoreQuestion         string                 `protobuf:"bytes,7,opt,name=core_question,json=coreQuestion,proto3" json:"core_question,omitempty"`
+	HighestGood          string                 `protobuf:"bytes,8,opt,name=highest_good,json=highestGood,proto3" json:"highest_good,omitempty"`
+	UltimateEvil         string                 `protobuf:"bytes,9,opt,name=ultimate_evil,json=ultimateEvil,proto3" json:"ultimate_evil,omitempty"`
+	SocialConflictsCount int32                  `protobuf:"varint,10,opt,name=social_conflicts_count,json=socialConflictsCount,proto3" json:"social_conflicts_count,omitempty"`
+	RegionCount          int32                  `protobuf:"varint,11,opt,name=region_count,json=regionCount,proto3" json:"region_count,omitempty"`
+	RaceCount            int32                  `protobuf:"varint,12,opt,name=race_count,json=raceCount,proto3" json:"race_count,omitempty"`
+	CreatedAt            string                 `protobuf:"bytes,13,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"` // RFC 3339
+	// setting_json 完整设定（同 REST 世界导出中的 world），仅在 GetWorldRequest.include_setting 时返回
+	SettingJson   []byte `protobuf:"bytes,14,opt,name=setting_json,json=settingJson,proto3" json:"setting_json,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *World) Reset() {
+	*x = World{}
+	mi := &file_xupu_v1_world_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *World) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*World) ProtoMessage() {}
+
+func (x *World) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_world_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use World.ProtoReflect.Descriptor instead.
+func (*World) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_world_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *World) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *World) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *World) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *World) GetScale() string {
+	if x != nil {
+		return x.Scale
+	}
+	return ""
+}
+
+func (x *World) GetStyle() string {
+	if x != nil {
+		return x.Style
+	}
+	return ""
+}
+
+func (x *World) GetBuildTier() string {
+	if x != nil {
+		return x.BuildTier
+	}
+	return ""
+}
+
+func (x *World) GetCoreQuestion() string {
+	if x != nil {
+		return x.CoreQuestion
+	}
+	return ""
+}
+
+func (x *World) GetHighestGood() string {
+	if x != nil {
+		return x.HighestGood
+	}
+	return ""
+}
+
+func (x *World) GetUltimateEvil() string {
+	if x != nil {
+		return x.UltimateEvil
+	}
+	return ""
+}
+
+func (x *World) GetSocialConflictsCount() int32 {
+	if x != nil {
+		return x.SocialConflictsCount
+	}
+	return 0
+}
+
+func (x *World) GetRegionCount() int32 {
+	if x != nil {
+		return x.RegionCount
+	}
+	return 0
+}
+
+func (x *World) GetRaceCount() int32 {
+	if x != nil {
+		return x.RaceCount
+	}
+	return 0
+}
+
+func (x *World) GetCreatedAt() string {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return ""
+}
+
+func (x *World) GetSettingJson() []byte {
+	if x != nil {
+		return x.SettingJson
+	}
+	return nil
+}
+
+type ListWorldsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWorldsRequest) Reset() {
+	*x = ListWorldsRequest{}
+	mi := &file_xupu_v1_world_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWorldsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWorldsRequest) ProtoMessage() {}
+
+func (x *ListWorldsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_world_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWorldsRequest.ProtoReflect.Descriptor instead.
+func (*ListWorldsRequest) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_world_proto_rawDescGZIP(), []int{1}
+}
+
+type ListWorldsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Worlds        []*World               `protobuf:"bytes,1,rep,name=worlds,proto3" json:"worlds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListWorldsResponse) Reset() {
+	*x = ListWorldsResponse{}
+	mi := &file_xupu_v1_world_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListWorldsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListWorldsResponse) ProtoMessage() {}
+
+func (x *ListWorldsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_world_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListWorldsResponse.ProtoReflect.Descriptor instead.
+func (*ListWorldsResponse) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_world_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ListWorldsResponse) GetWorlds() []*World {
+	if x != nil {
+		return x.Worlds
+	}
+	return nil
+}
+
+type GetWorldRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Id             string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	IncludeSetting bool                   `protobuf:"varint,2,opt,name=include_setting,json=includeSetting,proto3" json:"include_setting,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *GetWorldRequest) Reset() {
+	*x = GetWorldRequest{}
+	mi := &file_xupu_v1_world_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWorldRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWorldRequest) ProtoMessage() {}
+
+func (x *GetWorldRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_world_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWorldRequest.ProtoReflect.Descriptor instead.
+func (*GetWorldRequest) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_world_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetWorldRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *GetWorldRequest) GetIncludeSetting() bool {
+	if x != nil {
+		return x.IncludeSetting
+	}
+	return false
+}
+
+type GetWorldResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	World         *World                 `protobuf:"bytes,1,opt,name=world,proto3" json:"world,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetWorldResponse) Reset() {
+	*x = GetWorldResponse{}
+	mi := &file_xupu_v1_world_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetWorldResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetWorldResponse) ProtoMessage() {}
+
+func (x *GetWorldResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_xupu_v1_world_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetWorldResponse.ProtoReflect.Descriptor instead.
+func (*GetWorldResponse) Descriptor() ([]byte, []int) {
+	return file_xupu_v1_world_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetWorldResponse) GetWorld() *World {
+	if x != nil {
+		return x.World
+	}
+	return nil
+}
+
+var File_xupu_v1_world_proto protoreflect.FileDescriptor
+
+const file_xupu_v1_world_proto_rawDesc = "" +
+	"\n" +
+	"\x13xupu/v1/world.proto\x12\axupu.v1\"\xb1\x03\n" +
+	"\x05World\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x14\n" +
+	"\x05scale\x18\x04 \x01(\tR\x05scale\x12\x14\n" +
+	"\x05style\x18\x05 \x01(\tR\x05style\x12\x1d\n" +
+	"\n" +
+	"build_tier\x18\x06 \x01(\tR\tbuildTier\x12#\n" +
+	"\rcore_question\x18\a \x01(\tR\fcoreQuestion\x12!\n" +
+	"\fhighest_good\x18\b \x01(\tR\vhighestGood\x12#\n" +
+	"\rultimate_evil\x18\t \x01(\tR\fultimateEvil\x124\n" +
+	"\x16social_conflicts_count\x18\n" +
+	" \x01(\x05R\x14socialConflictsCount\x12!\n" +
+	"\fregion_count\x18\v \x01(\x05R\vregionCount\x12\x1d\n" +
+	"\n" +
+	"race_count\x18\f \x01(\x05R\traceCount\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\r \x01(\tR\tcreatedAt\x12!\n" +
+	"\fsetting_json\x18\x0e \x01(\fR\vsettingJson\"\x13\n" +
+	"\x11ListWorldsRequest\"<\n" +
+	"\x12ListWorldsResponse\x12&\n" +
+	"\x06worlds\x18\x01 \x03(\v2\x0e.xupu.v1.WorldR\x06worlds\"J\n" +
+	"\x0fGetWorldRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12'\n" +
+	"\x0finclude_setting\x18\x02 \x01(\bR\x0eincludeSetting\"8\n" +
+	"\x10GetWorldResponse\x12$\n" +
+	"\x05world\x18\x01 \x01(\v2\x0e.xupu.v1.WorldR\x05world2\x96\x01\n" +
+	"\fWorldService\x12E\n" +
+	"\n" +
+	"ListWorlds\x12\x1a.xupu.v1.ListWorldsRequest\x1a\x1b.xupu.v1.ListWorldsResponse\x12?\n" +
+	"\bGetWorld\x12\x18.xupu.v1.GetWorldRequest\x1a\x19.xupu.v1.GetWorldResponseB,Z*github.com/xlei/xupu/pkg/rpc/xupuv1;xupuv1b\x06proto3"
+
+var (
+	file_xupu_v1_world_proto_rawDescOnce sync.Once
+	file_xupu_v1_world_proto_rawDescData []byte
+)
+
+func file_xupu_v1_world_proto_rawDescGZIP() []byte {
+	file_xupu_v1_world_proto_rawDescOnce.Do(func() {
+		file_xupu_v1_world_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_xupu_v1_world_proto_rawDesc), len(file_xupu_v1_world_proto_rawDesc)))
+	})
+	return file_xupu_v1_world_proto_rawDescData
+}
+
+var file_xupu_v1_world_proto_msgTypes = make([]protoimpl.MessageInfo, 5)
+var file_xupu_v1_world_proto_goTypes = []any{
+	(*World)(nil),              // 0: xupu.v1.World
+	(*ListWorldsRequest)(nil),  // 1: xupu.v1.ListWorldsRequest
+	(*ListWorldsResponse)(nil), // 2: xupu.v1.ListWorldsResponse
+	(*GetWorldRequest)(nil),    // 3: xupu.v1.GetWorldRequest
+	(*GetWorldResponse)(nil),   // 4: xupu.v1.GetWorldResponse
+}
+var file_xupu_v1_world_proto_depIdxs = []int32{
+	0, // 0: xupu.v1.ListWorldsResponse.worlds:type_name -> xupu.v1.World
+	0, // 1: xupu.v1.GetWorldResponse.world:type_name -> xupu.v1.World
+	1, // 2: xupu.v1.WorldService.ListWorlds:input_type -> xupu.v1.ListWorldsRequest
+	3, // 3: xupu.v1.WorldService.GetWorld:input_type -> xupu.v1.GetWorldRequest
+	2, // 4: xupu.v1.WorldService.ListWorlds:output_type -> xupu.v1.ListWorldsResponse
+	4, // 5: xupu.v1.WorldService.GetWorld:output_type -> xupu.v1.GetWorldResponse
+	4, // [4:6] is the sub-list for method output_type
+	2, // [2:4] is the sub-list for method input_type
+	2, // [2:2] is the sub-list for extension type_name
+	2, // [2:2] is the sub-list for extension extendee
+	0, // [0:2] is the sub-list for field type_name
+}
+
+func init() { file_xupu_v1_world_proto_init() }
+func file_xupu_v1_world_proto_init() {
+	if File_xupu_v1_world_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_xupu_v1_world_proto_rawDesc), len(file_xupu_v1_world_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   5,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_xupu_v1_world_proto_goTypes,
+		DependencyIndexes: file_xupu_v1_world_proto_depIdxs,
+		MessageInfos:      file_xupu_v1_world_proto_msgTypes,
+	}.Build()
+	File_xupu_v1_world_proto = out.File
+	file_xupu_v1_world_proto_goTypes = nil
+	file_xupu_v1_world_proto_depIdxs = nil
+}
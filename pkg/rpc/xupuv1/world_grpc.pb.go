@@ -0,0 +1,167 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: xupu/v1/world.proto
+
+package xupuv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	WorldService_ListWorlds_FullMethodName = "/xupu.v1.WorldService/ListWorlds"
+	WorldService_GetWorld_FullMethodName   = "/xupu.v1.WorldService/GetWorld"
+)
+
+// WorldServiceClient is the client API for WorldService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// WorldService 世界设定，与 REST 的 /api/v1/worlds 对应
+type WorldServiceClient interface {
+	// ListWorlds 列出世界设定摘要
+	ListWorlds(ctx context.Context, in *ListWorldsRequest, opts ...grpc.CallOption) (*ListWorldsResponse, error)
+	// GetWorld 获取世界设定，可附带完整设定的JSON
+	GetWorld(ctx context.Context, in *GetWorldRequest, opts ...grpc.CallOption) (*GetWorldResponse, error)
+}
+
+type worldServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewWorldServiceClient(cc grpc.ClientConnInterface) WorldServiceClient {
+	return &worldServiceClient{cc}
+}
+
+func (c *worldServiceClient) ListWorlds(ctx context.Context, in *ListWorldsRequest, opts ...grpc.CallOption) (*ListWorldsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListWorldsResponse)
+	err := c.cc.Invoke(ctx, WorldService_ListWorlds_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *worldServiceClient) GetWorld(ctx context.Context, in *GetWorldRequest, opts ...grpc.CallOption) (*GetWorldResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetWorldResponse)
+	err := c.cc.Invoke(ctx, WorldService_GetWorld_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// WorldServiceServer is the server API for WorldService service.
+// All implementations must embed UnimplementedWorldServiceServer
+// for forward compatibility.
+//
+// WorldService 世界设定，与 REST 的 /api/v1/worlds 对应
+type WorldServiceServer interface {
+	// ListWorlds 列出世界设定摘要
+	ListWorlds(context.Context, *ListWorldsRequest) (*ListWorldsResponse, error)
+	// GetWorld 获取世界设定，可附带完整设定的JSON
+	GetWorld(context.Context, *GetWorldRequest) (*GetWorldResponse, error)
+	mustEmbedUnimplementedWorldServiceServer()
+}
+
+// UnimplementedWorldServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedWorldServiceServer struct{}
+
+func (UnimplementedWorldServiceServer) ListWorlds(context.Context, *ListWorldsRequest) (*ListWorldsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListWorlds not implemented")
+}
+func (UnimplementedWorldServiceServer) GetWorld(context.Context, *GetWorldRequest) (*GetWorldResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetWorld not implemented")
+}
+func (UnimplementedWorldServiceServer) mustEmbedUnimplementedWorldServiceServer() {}
+func (UnimplementedWorldServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeWorldServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to WorldServiceServer will
+// result in compilation errors.
+type UnsafeWorldServiceServer interface {
+	mustEmbedUnimplementedWorldServiceServer()
+}
+
+func RegisterWorldServiceServer(s grpc.ServiceRegistrar, srv WorldServiceServer) {
+	// If the following call pancis, it indicates UnimplementedWorldServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&WorldService_ServiceDesc, srv)
+}
+
+func _WorldService_ListWorlds_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListWorldsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorldServiceServer).ListWorlds(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WorldService_ListWorlds_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorldServiceServer).ListWorlds(ctx, req.(*ListWorldsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _WorldService_GetWorld_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetWorldRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(WorldServiceServer).GetWorld(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: WorldService_GetWorld_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(WorldServiceServer).GetWorld(ctx, req.(*GetWorldRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// WorldService_ServiceDesc is the grpc.ServiceDesc for WorldService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var WorldService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "xupu.v1.WorldService",
+	HandlerType: (*WorldServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListWorlds",
+			Handler:    _WorldService_ListWorlds_Handler,
+		},
+		{
+			MethodName: "GetWorld",
+			Handler:    _WorldService_GetWorld_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "xupu/v1/world.proto",
+}
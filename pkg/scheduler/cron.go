@@ -0,0 +1,193 @@
+// Package scheduler 调度器 - cron 表达式
+package scheduler
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CronExpr 解析后的 cron 表达式：分 时 日 月 周，五个字段
+// 支持 *、逗号列表、a-b 范围、/n 步长、月份和星期的英文缩写，以及 @hourly/@daily/@weekly/@monthly/@yearly
+// 日和周都不以 * 开头时，与标准 cron 一致，满足其一即可；否则两者都须满足
+type CronExpr struct {
+	spec     string
+	minute   uint64
+	hour     uint64
+	dom      uint64
+	month    uint64
+	dow      uint64
+	domStar  bool
+	dowStar  bool
+	location *time.Location
+}
+
+// cronField 字段的取值范围和名称
+type cronField struct {
+	name     string
+	min, max int
+	names    map[string]int
+}
+
+var cronFields = []cronField{
+	{name: "分钟", min: 0, max: 59},
+	{name: "小时", min: 0, max: 23},
+	{name: "日", min: 1, max: 31},
+	{name: "月", min: 1, max: 12, names: map[string]int{
+		"jan": 1, "feb": 2, "mar": 3, "apr": 4, "may": 5, "jun": 6,
+		"jul": 7, "aug": 8, "sep": 9, "oct": 10, "nov": 11, "dec": 12,
+	}},
+	{name: "星期", min: 0, max: 7, names: map[string]int{
+		"sun": 0, "mon": 1, "tue": 2, "wed": 3, "thu": 4, "fri": 5, "sat": 6,
+	}},
+}
+
+var cronDescriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+// ParseCron 解析 cron 表达式，location 为计算触发时间所用的时区，为 nil 时使用本地时区
+func ParseCron(spec string, location *time.Location) (*CronExpr, error) {
+	spec = strings.TrimSpace(spec)
+	expanded := spec
+	if d, ok := cronDescriptors[strings.ToLower(spec)]; ok {
+		expanded = d
+	}
+	fields := strings.Fields(expanded)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron 表达式应为 5 个字段（分 时 日 月 周）: %q", spec)
+	}
+	if location == nil {
+		location = time.Local
+	}
+
+	expr := &CronExpr{spec: spec, location: location}
+	bits := []*uint64{&expr.minute, &expr.hour, &expr.dom, &expr.month, &expr.dow}
+	for i, f := range fields {
+		b, err := parseCronField(f, cronFields[i])
+		if err != nil {
+			return nil, err
+		}
+		*bits[i] = b
+	}
+	// 星期中的 7 等同于 0（周日）
+	if expr.dow&(1<<7) != 0 {
+		expr.dow = expr.dow&^(1<<7) | 1
+	}
+	// 以 * 或 ? 开头的字段（包括 */2 这样的步长）视为不限定，与标准 cron 一致
+	expr.domStar = strings.HasPrefix(fields[2], "*") || strings.HasPrefix(fields[2], "?")
+	expr.dowStar = strings.HasPrefix(fields[4], "*") || strings.HasPrefix(fields[4], "?")
+	return expr, nil
+}
+
+// parseCronField 解析单个字段为位图
+func parseCronField(field string, def cronField) (uint64, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rangePart, step := part, 1
+		if i := strings.Index(part, "/"); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, fmt.Errorf("%s字段的步长无效: %q", def.name, part)
+			}
+			rangePart, step = part[:i], n
+		}
+
+		lo, hi := def.min, def.max
+		switch {
+		case rangePart == "*" || rangePart == "?":
+		case strings.Contains(rangePart, "-"):
+			bounds := strings.SplitN(rangePart, "-", 2)
+			var err error
+			if lo, err = cronValue(bounds[0], def); err != nil {
+				return 0, err
+			}
+			if hi, err = cronValue(bounds[1], def); err != nil {
+				return 0, err
+			}
+			if lo > hi {
+				return 0, fmt.Errorf("%s字段的范围无效: %q", def.name, part)
+			}
+		default:
+			v, err := cronValue(rangePart, def)
+			if err != nil {
+				return 0, err
+			}
+			lo = v
+			if step == 1 {
+				hi = v
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, nil
+}
+
+// cronValue 解析字段中的单个取值（数字或英文缩写）
+func cronValue(s string, def cronField) (int, error) {
+	if v, ok := def.names[strings.ToLower(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil || v < def.min || v > def.max {
+		return 0, fmt.Errorf("%s字段的取值无效: %q（范围 %d-%d）", def.name, s, def.min, def.max)
+	}
+	return v, nil
+}
+
+// String 原始表达式
+func (e *CronExpr) String() string {
+	return e.spec
+}
+
+// Location 计算触发时间所用的时区
+func (e *CronExpr) Location() *time.Location {
+	return e.location
+}
+
+// Next 晚于 after 的下一次触发时间（精确到分钟）；五年内没有触发时间（如 2月30日）时返回零值
+func (e *CronExpr) Next(after time.Time) time.Time {
+	t := after.In(e.location).Truncate(time.Minute).Add(time.Minute)
+	limit := t.AddDate(5, 0, 0)
+
+	for t.Before(limit) {
+		if e.month&(1<<uint(t.Month())) == 0 {
+			t = time.Date(t.Year(), t.Month()+1, 1, 0, 0, 0, 0, e.location)
+			continue
+		}
+		if !e.dayMatches(t) {
+			t = time.Date(t.Year(), t.Month(), t.Day()+1, 0, 0, 0, 0, e.location)
+			continue
+		}
+		if e.hour&(1<<uint(t.Hour())) == 0 {
+			t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour()+1, 0, 0, 0, e.location)
+			continue
+		}
+		if e.minute&(1<<uint(t.Minute())) == 0 {
+			t = t.Add(time.Minute)
+			continue
+		}
+		return t
+	}
+	return time.Time{}
+}
+
+// dayMatches 日期是否满足日和星期字段
+func (e *CronExpr) dayMatches(t time.Time) bool {
+	dom := e.dom&(1<<uint(t.Day())) != 0
+	dow := e.dow&(1<<uint(t.Weekday())) != 0
+	if e.domStar || e.dowStar {
+		return dom && dow
+	}
+	return dom || dow
+}
@@ -0,0 +1,119 @@
+// Package scheduler cron 表达式测试
+package scheduler
+
+import (
+	"testing"
+	"time"
+)
+
+// TestParseCron 测试表达式解析和校验
+func TestParseCron(t *testing.T) {
+	tests := []struct {
+		name    string
+		spec    string
+		wantErr bool
+		domStar bool
+		dowStar bool
+	}{
+		{name: "全部为星号", spec: "* * * * *", domStar: true, dowStar: true},
+		{name: "步长范围列表和缩写", spec: "*/5 0-23/2 1,15 jan-jun/2 mon-fri"},
+		{name: "描述符", spec: "@daily", domStar: true, dowStar: true},
+		{name: "描述符不区分大小写", spec: "@Weekly", domStar: true},
+		{name: "问号", spec: "0 0 ? * ?", domStar: true, dowStar: true},
+		{name: "日为星号步长", spec: "0 0 */2 * mon", domStar: true},
+		{name: "星期为星号步长", spec: "0 0 1 * */2", dowStar: true},
+		{name: "日和星期都受限", spec: "0 0 1-5 * 1"},
+		{name: "星期为7", spec: "0 0 * * 7", domStar: true},
+		{name: "字段数不足", spec: "* * * *", wantErr: true},
+		{name: "字段数过多", spec: "* * * * * *", wantErr: true},
+		{name: "分钟越界", spec: "60 * * * *", wantErr: true},
+		{name: "日为0", spec: "* * 0 * *", wantErr: true},
+		{name: "月越界", spec: "* * * 13 *", wantErr: true},
+		{name: "星期越界", spec: "* * * * 8", wantErr: true},
+		{name: "步长为0", spec: "*/0 * * * *", wantErr: true},
+		{name: "步长非数字", spec: "*/x * * * *", wantErr: true},
+		{name: "范围颠倒", spec: "5-1 * * * *", wantErr: true},
+		{name: "未知缩写", spec: "* * * foo *", wantErr: true},
+		{name: "空表达式", spec: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCron(tt.spec, time.UTC)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("ParseCron(%q) 应返回错误", tt.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCron(%q) 返回错误: %v", tt.spec, err)
+			}
+			if expr.domStar != tt.domStar || expr.dowStar != tt.dowStar {
+				t.Errorf("ParseCron(%q) domStar=%v dowStar=%v, 期望 %v %v", tt.spec, expr.domStar, expr.dowStar, tt.domStar, tt.dowStar)
+			}
+			if expr.String() != tt.spec {
+				t.Errorf("String() = %q, 期望 %q", expr.String(), tt.spec)
+			}
+		})
+	}
+}
+
+// TestCronNext 测试下一次触发时间（2026-01-01 为周四）
+func TestCronNext(t *testing.T) {
+	at := func(year int, month time.Month, day, hour, minute int) time.Time {
+		return time.Date(year, month, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := []struct {
+		name  string
+		spec  string
+		after time.Time
+		want  time.Time
+	}{
+		{name: "每15分钟", spec: "*/15 * * * *", after: at(2026, 1, 1, 10, 7), want: at(2026, 1, 1, 10, 15)},
+		{name: "恰好在触发时刻时取下一次", spec: "*/15 * * * *", after: at(2026, 1, 1, 10, 15), want: at(2026, 1, 1, 10, 30)},
+		{name: "秒数截断到分钟", spec: "@hourly", after: at(2026, 1, 1, 10, 59).Add(30 * time.Second), want: at(2026, 1, 1, 11, 0)},
+		{name: "范围内步长", spec: "0 9-17/4 * * *", after: at(2026, 1, 1, 10, 0), want: at(2026, 1, 1, 13, 0)},
+		{name: "列表跨天", spec: "30 8,20 * * *", after: at(2026, 1, 1, 20, 30), want: at(2026, 1, 2, 8, 30)},
+		{name: "月初跨月", spec: "0 0 1 * *", after: at(2026, 1, 31, 12, 0), want: at(2026, 2, 1, 0, 0)},
+		{name: "31日跳过二月", spec: "0 0 31 * *", after: at(2026, 1, 31, 0, 0), want: at(2026, 3, 31, 0, 0)},
+		{name: "跨年", spec: "0 0 1 1 *", after: at(2026, 12, 31, 23, 59), want: at(2027, 1, 1, 0, 0)},
+		{name: "月份缩写范围", spec: "0 0 1 jun-aug *", after: at(2026, 8, 1, 0, 0), want: at(2027, 6, 1, 0, 0)},
+		{name: "闰年2月29日", spec: "0 0 29 2 *", after: at(2026, 1, 1, 0, 0), want: at(2028, 2, 29, 0, 0)},
+		{name: "星期7为周日", spec: "0 0 * * 7", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 4, 0, 0)},
+		{name: "工作日跳过周末", spec: "0 12 * * mon-fri", after: at(2026, 1, 2, 13, 0), want: at(2026, 1, 5, 12, 0)},
+		{name: "日和星期都受限时满足其一", spec: "0 0 13 * fri", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 2, 0, 0)},
+		{name: "日和星期都受限时日先满足", spec: "0 0 3 * fri", after: at(2026, 1, 2, 0, 0), want: at(2026, 1, 3, 0, 0)},
+		{name: "日为星号步长时两者都须满足", spec: "0 0 */2 * mon", after: at(2026, 1, 1, 0, 0), want: at(2026, 1, 5, 0, 0)},
+		{name: "日为星号步长时跳过偶数日的周一", spec: "0 0 */2 * mon", after: at(2026, 1, 5, 0, 0), want: at(2026, 1, 19, 0, 0)},
+		{name: "星期为星号步长时两者都须满足", spec: "0 0 1 * */2", after: at(2026, 1, 1, 0, 0), want: at(2026, 2, 1, 0, 0)},
+		{name: "不存在的日期", spec: "0 0 30 2 *", after: at(2026, 1, 1, 0, 0), want: time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expr, err := ParseCron(tt.spec, time.UTC)
+			if err != nil {
+				t.Fatalf("ParseCron(%q) 返回错误: %v", tt.spec, err)
+			}
+			if got := expr.Next(tt.after); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, 期望 %s", tt.after.Format(time.RFC3339), got.Format(time.RFC3339), tt.want.Format(time.RFC3339))
+			}
+		})
+	}
+}
+
+// TestCronNextLocation 测试按指定时区计算触发时间
+func TestCronNextLocation(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	expr, err := ParseCron("0 9 * * *", shanghai)
+	if err != nil {
+		t.Fatal(err)
+	}
+	after := time.Date(2026, 1, 1, 2, 0, 0, 0, time.UTC) // 上海时间 10:00
+	want := time.Date(2026, 1, 2, 1, 0, 0, 0, time.UTC)  // 上海时间次日 09:00
+	if got := expr.Next(after); !got.Equal(want) {
+		t.Errorf("Next = %s, 期望 %s", got, want)
+	}
+}
@@ -317,6 +317,16 @@ func (s *Scheduler) GetActiveWorkers() int {
 	return int(atomic.LoadInt32(&s.activeWorkers))
 }
 
+// IsRunning 调度器是否已启动且未停止
+func (s *Scheduler) IsRunning() bool {
+	return len(s.workers) > 0 && s.ctx.Err() == nil
+}
+
+// GetWorkerCount 获取工作协程总数
+func (s *Scheduler) GetWorkerCount() int {
+	return s.workerCount
+}
+
 // CleanCompletedTasks 清理已完成的任务
 func (s *Scheduler) CleanCompletedTasks(olderThan time.Duration) int {
 	s.taskMutex.Lock()
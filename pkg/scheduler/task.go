@@ -40,6 +40,7 @@ const (
 	TaskTypeChapterGen     TaskType = "chapter_gen"      // 章节生成
 	TaskTypeSceneGen       TaskType = "scene_gen"        // 场景生成
 	TaskTypeExport         TaskType = "export"           // 导出
+	TaskTypeCron           TaskType = "cron"             // 定时任务
 )
 
 // Task 任务
@@ -0,0 +1,184 @@
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// ProjectDocuments 加载项目的全部可检索文档：章节、场景、世界设定实体和角色
+func ProjectDocuments(database db.Database, project *models.Project) []Document {
+	docs := make([]Document, 0)
+
+	chapters := database.ListChaptersByProject(project.ID)
+	sort.Slice(chapters, func(i, j int) bool { return chapters[i].ChapterNum < chapters[j].ChapterNum })
+	titles := make(map[int]string, len(chapters))
+	for _, ch := range chapters {
+		titles[ch.ChapterNum] = ch.Title
+		if strings.TrimSpace(ch.Content) == "" {
+			continue
+		}
+		docs = append(docs, Document{
+			Kind:    KindChapter,
+			ID:      ch.ID,
+			Title:   ch.Title,
+			Chapter: ch.ChapterNum,
+			Text:    ch.Content,
+		})
+	}
+
+	if project.NarrativeID != "" {
+		for _, s := range database.ListScenesByBlueprint(project.NarrativeID) {
+			if strings.TrimSpace(s.Content) == "" {
+				continue
+			}
+			title := fmt.Sprintf("第%d章 场景%d", s.Chapter, s.Scene)
+			if t := titles[s.Chapter]; t != "" {
+				title = fmt.Sprintf("%s 场景%d", t, s.Scene)
+			}
+			docs = append(docs, Document{
+				Kind:    KindScene,
+				ID:      s.ID,
+				Title:   title,
+				Chapter: s.Chapter,
+				Scene:   s.Scene,
+				Text:    s.Content,
+			})
+		}
+	}
+
+	if project.WorldID != "" {
+		if world, err := database.GetWorld(project.WorldID); err == nil {
+			docs = append(docs, WorldDocuments(world)...)
+			docs = append(docs, characterDocuments(database, project.WorldID)...)
+		}
+	}
+	return docs
+}
+
+// WorldDocuments 世界设定中的命名实体，正文为实体完整设定中的全部文字
+func WorldDocuments(world *models.WorldSetting) []Document {
+	if len(world.Entities) == 0 {
+		world.RebuildEntityIndex()
+	}
+	docs := make([]Document, 0, len(world.Entities))
+	for i := range world.Entities {
+		e := &world.Entities[i]
+		text := e.Summary
+		if source := world.EntitySource(e); source != nil {
+			text = strings.Join(textValues(source), "\n")
+		}
+		docs = append(docs, Document{
+			Kind:  KindWorld,
+			ID:    e.ID,
+			Title: e.Name,
+			Text:  text,
+		})
+	}
+	return docs
+}
+
+// characterDocuments 世界中的角色：角色档案，有角色卡时附上角色卡
+func characterDocuments(database db.Database, worldID string) []Document {
+	sheets := make(map[string]models.CharacterSheet)
+	if list, err := database.ListCharacterSheets(worldID); err == nil {
+		for _, s := range list {
+			sheets[s.Name] = s
+		}
+	}
+
+	docs := make([]Document, 0)
+	for _, char := range database.ListCharactersByWorld(worldID) {
+		if char == nil || char.Name == "" {
+			continue
+		}
+		parts := textValues(char.StaticProfile)
+		parts = append(parts, textValues(char.NarrativeProfile)...)
+		if sheet, ok := sheets[char.Name]; ok {
+			parts = append(parts, sheetText(sheet)...)
+			delete(sheets, char.Name)
+		}
+		docs = append(docs, Document{
+			Kind:  KindCharacter,
+			ID:    char.ID,
+			Title: char.Name,
+			Text:  strings.Join(parts, "\n"),
+		})
+	}
+
+	// 只有角色卡、没有角色档案的角色（由叙事器演化产生）
+	names := make([]string, 0, len(sheets))
+	for name := range sheets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		sheet := sheets[name]
+		docs = append(docs, Document{
+			Kind:  KindCharacter,
+			ID:    sheet.ID,
+			Title: sheet.Name,
+			Text:  strings.Join(sheetText(sheet), "\n"),
+		})
+	}
+	return docs
+}
+
+// sheetText 角色卡中的文字
+func sheetText(sheet models.CharacterSheet) []string {
+	parts := []string{sheet.Role, sheet.ConsciousWant, sheet.UnconsciousNeed, sheet.Fear, sheet.CurrentEmotion}
+	parts = append(parts, sheet.MaskingBehavior...)
+	parts = append(parts, sheet.Secrets...)
+	parts = append(parts, sheet.InternalConflicts...)
+	return nonEmpty(parts)
+}
+
+// textValues 结构体中的全部字符串值（经 JSON 展开，按键名排序）
+func textValues(v interface{}) []string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var tree interface{}
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil
+	}
+	out := make([]string, 0)
+	var walk func(node interface{})
+	walk = func(node interface{}) {
+		switch n := node.(type) {
+		case string:
+			out = append(out, n)
+		case []interface{}:
+			for _, item := range n {
+				walk(item)
+			}
+		case map[string]interface{}:
+			keys := make([]string, 0, len(n))
+			for k := range n {
+				keys = append(keys, k)
+			}
+			sort.Strings(keys)
+			for _, k := range keys {
+				walk(n[k])
+			}
+		}
+	}
+	walk(tree)
+	return nonEmpty(out)
+}
+
+// nonEmpty 去掉空白字符串
+func nonEmpty(list []string) []string {
+	out := list[:0]
+	for _, s := range list {
+		if strings.TrimSpace(s) != "" {
+			out = append(out, s)
+		}
+	}
+	return out
+}
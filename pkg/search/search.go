@@ -0,0 +1,243 @@
+// Package search 项目内容全文检索
+// 中文正文没有词边界，数据库自带的全文索引（如 Postgres tsvector）需要额外的分词扩展，
+// 因此这里按子串匹配在内存中检索：每次查询加载项目的章节、场景、世界设定和角色卡，逐篇扫描并生成高亮片段。
+// 单个项目的内容量在几 MB 以内，线性扫描的耗时可以接受。
+package search
+
+import (
+	"html"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Kind 检索对象类型
+type Kind string
+
+const (
+	KindChapter   Kind = "chapter"   // 章节正文
+	KindScene     Kind = "scene"     // 场景正文
+	KindWorld     Kind = "world"     // 世界设定实体（地区、种族、宗教、法律、历史事件）
+	KindCharacter Kind = "character" // 角色档案与角色卡
+)
+
+// Kinds 全部检索对象类型
+var Kinds = []Kind{KindChapter, KindScene, KindWorld, KindCharacter}
+
+const (
+	// DefaultLimit 默认返回的命中数
+	DefaultLimit = 50
+	// snippetRunes 高亮片段中命中词前后各保留的字数
+	snippetRunes = 40
+	// maxHighlights 每个命中最多返回的高亮片段数
+	maxHighlights = 3
+	// titleBoost 标题命中的加权
+	titleBoost = 5
+)
+
+// 高亮标记
+const (
+	MarkStart = "<mark>"
+	MarkEnd   = "</mark>"
+)
+
+// Document 可检索的文档
+type Document struct {
+	Kind    Kind
+	ID      string
+	Title   string
+	Chapter int // 章节和场景所在章节号，其他类型为 0
+	Scene   int // 场景序号
+	Text    string
+}
+
+// Hit 检索命中
+type Hit struct {
+	Kind       Kind     `json:"kind"`
+	ID         string   `json:"id"`
+	Title      string   `json:"title"`
+	Chapter    int      `json:"chapter,omitempty"`
+	Scene      int      `json:"scene,omitempty"`
+	Matches    int      `json:"matches"` // 正文中命中的次数
+	Score      int      `json:"score"`
+	Highlights []string `json:"highlights"` // 命中词用 <mark></mark> 包裹的上下文片段
+}
+
+// Options 检索选项
+type Options struct {
+	Kinds []Kind // 为空表示全部类型
+	Limit int    // 为 0 时使用 DefaultLimit
+}
+
+// ParseQuery 拆分查询词：空白分隔，双引号内的短语作为一个词
+func ParseQuery(query string) []string {
+	terms := make([]string, 0)
+	var current strings.Builder
+	quoted := false
+	flush := func() {
+		if t := strings.TrimSpace(current.String()); t != "" {
+			terms = append(terms, t)
+		}
+		current.Reset()
+	}
+	for _, r := range query {
+		switch {
+		case r == '"' || r == '“' || r == '”':
+			flush()
+			quoted = !quoted
+		case unicode.IsSpace(r) && !quoted:
+			flush()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	flush()
+	return terms
+}
+
+// Search 在文档中检索，所有查询词都出现（标题或正文）的文档才算命中，按得分从高到低排列；
+// 返回截取 Limit 条后的命中和截取前的命中总数
+func Search(docs []Document, query string, opts Options) ([]Hit, int) {
+	terms := ParseQuery(query)
+	if len(terms) == 0 {
+		return nil, 0
+	}
+	lowerTerms := make([]string, len(terms))
+	for i, t := range terms {
+		lowerTerms[i] = strings.ToLower(t)
+	}
+
+	allowed := make(map[Kind]bool, len(opts.Kinds))
+	for _, k := range opts.Kinds {
+		allowed[k] = true
+	}
+
+	hits := make([]Hit, 0)
+	for _, doc := range docs {
+		if len(allowed) > 0 && !allowed[doc.Kind] {
+			continue
+		}
+		text := strings.ToLower(doc.Text)
+		title := strings.ToLower(doc.Title)
+
+		matches, score := 0, 0
+		found := true
+		for _, t := range lowerTerms {
+			inText := strings.Count(text, t)
+			inTitle := strings.Count(title, t)
+			if inText+inTitle == 0 {
+				found = false
+				break
+			}
+			matches += inText
+			score += inText + inTitle*titleBoost
+		}
+		if !found {
+			continue
+		}
+
+		hits = append(hits, Hit{
+			Kind:       doc.Kind,
+			ID:         doc.ID,
+			Title:      doc.Title,
+			Chapter:    doc.Chapter,
+			Scene:      doc.Scene,
+			Matches:    matches,
+			Score:      score,
+			Highlights: Highlight(doc.Text, terms, maxHighlights),
+		})
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		if hits[i].Score != hits[j].Score {
+			return hits[i].Score > hits[j].Score
+		}
+		if hits[i].Chapter != hits[j].Chapter {
+			return hits[i].Chapter < hits[j].Chapter
+		}
+		return hits[i].Scene < hits[j].Scene
+	})
+
+	total := len(hits)
+	limit := opts.Limit
+	if limit <= 0 {
+		limit = DefaultLimit
+	}
+	if len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, total
+}
+
+// Highlight 截取命中词前后的上下文片段，命中词用 MarkStart/MarkEnd 包裹，最多返回 max 个片段
+// 相互重叠的命中合并为一个片段；片段文本经过 HTML 转义，只有高亮标记是 HTML
+func Highlight(text string, terms []string, max int) []string {
+	runes := []rune(text)
+	lower := []rune(strings.ToLower(text))
+	if len(lower) != len(runes) {
+		// 大小写转换改变了字符数（极少见），退回区分大小写匹配
+		lower = runes
+	}
+
+	type span struct{ start, end int }
+	spans := make([]span, 0)
+	for _, t := range terms {
+		term := []rune(strings.ToLower(t))
+		if len(term) == 0 {
+			continue
+		}
+		for i := 0; i+len(term) <= len(lower); i++ {
+			if string(lower[i:i+len(term)]) == string(term) {
+				spans = append(spans, span{i, i + len(term)})
+				i += len(term) - 1
+			}
+		}
+	}
+	if len(spans) == 0 {
+		return []string{}
+	}
+	sort.Slice(spans, func(i, j int) bool { return spans[i].start < spans[j].start })
+
+	snippets := make([]string, 0, max)
+	for i := 0; i < len(spans) && len(snippets) < max; {
+		from := spans[i].start - snippetRunes
+		if from < 0 {
+			from = 0
+		}
+		// 收集落在同一片段内的命中
+		group := []span{spans[i]}
+		to := spans[i].end + snippetRunes
+		j := i + 1
+		for ; j < len(spans) && spans[j].start < to; j++ {
+			if spans[j].start >= group[len(group)-1].end {
+				group = append(group, spans[j])
+			}
+			if e := spans[j].end + snippetRunes; e > to {
+				to = e
+			}
+		}
+		if to > len(runes) {
+			to = len(runes)
+		}
+
+		var sb strings.Builder
+		if from > 0 {
+			sb.WriteString("…")
+		}
+		pos := from
+		for _, s := range group {
+			sb.WriteString(html.EscapeString(string(runes[pos:s.start])))
+			sb.WriteString(MarkStart)
+			sb.WriteString(html.EscapeString(string(runes[s.start:s.end])))
+			sb.WriteString(MarkEnd)
+			pos = s.end
+		}
+		sb.WriteString(html.EscapeString(string(runes[pos:to])))
+		if to < len(runes) {
+			sb.WriteString("…")
+		}
+		snippets = append(snippets, strings.Join(strings.Fields(sb.String()), " "))
+		i = j
+	}
+	return snippets
+}
@@ -0,0 +1,321 @@
+// Package stylemetrics 文风指标
+// 逐章统计句长分布、对话占比、副词密度、重复短语和叙述人称，存入章节记录，
+// 并汇总成全书趋势，标出明显偏离全书水平的章节，帮助作者发现文风漂移。
+// 全部由正文统计得出，不调用模型。
+package stylemetrics
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/writer"
+)
+
+const (
+	// minPhraseRunes/maxPhraseRunes 重复短语的长度范围（字）
+	minPhraseRunes = 4
+	maxPhraseRunes = 8
+	// minPhraseRepeats 同一短语在一章中至少出现的次数
+	minPhraseRepeats = 3
+	// maxListed 副词和重复短语各保留的条数
+	maxListed = 10
+	// firstPersonDensity 叙述中“我”每千字超过该值视为第一人称叙述
+	firstPersonDensity = 3.0
+)
+
+// sentenceBuckets 句长分桶的上界（字），最后一桶不设上界
+var sentenceBuckets = []int{10, 20, 40, 60}
+
+// adverbs 常见副词，同时统计英文以 -ly 结尾的词
+var adverbs = []string{
+	"非常", "十分", "极其", "特别", "相当", "突然", "忽然", "猛地", "缓缓", "慢慢",
+	"轻轻", "默默", "静静", "微微", "渐渐", "悄悄", "狠狠", "淡淡", "深深", "稍稍",
+	"几乎", "简直", "竟然", "居然", "终于", "仿佛", "似乎", "立刻", "顿时", "不禁",
+}
+
+// Measure 统计一章正文的文风指标
+func Measure(content string) *models.StyleMetrics {
+	m := &models.StyleMetrics{
+		Narration:         models.NarrationThird,
+		PersonConsistency: 1,
+		Digest:            Digest(content),
+		MeasuredAt:        time.Now(),
+	}
+	total := countText(content)
+	m.WordCount = total
+	if total == 0 {
+		return m
+	}
+
+	narration, dialogue := splitDialogue(content)
+	m.DialogueRatio = round2(float64(countText(strings.Join(dialogue, ""))) / float64(total))
+
+	lengths := make([]int, 0)
+	for _, s := range writer.SplitSentences(content) {
+		if n := countText(s); n > 0 {
+			lengths = append(lengths, n)
+		}
+	}
+	m.Sentences = len(lengths)
+	m.SentenceLength = sentenceLength(lengths)
+
+	found, count := countAdverbs(content)
+	m.AdverbDensity = round2(float64(count) * 1000 / float64(total))
+	m.TopAdverbs = found
+	m.RepeatedPhrases = repeatedPhrases(strings.Join(narration, "\n"))
+
+	measurePerson(m, narration)
+	return m
+}
+
+// Digest 正文摘要：sha1 前 16 位
+func Digest(content string) string {
+	sum := sha1.Sum([]byte(content))
+	return hex.EncodeToString(sum[:])[:16]
+}
+
+// countText 统计字数，不含空白和标点
+func countText(text string) int {
+	n := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) && !unicode.IsPunct(r) && !unicode.IsSymbol(r) {
+			n++
+		}
+	}
+	return n
+}
+
+// splitDialogue 把正文拆成叙述和引号内的对话，叙述按原文的换行分段
+func splitDialogue(content string) (narration, dialogue []string) {
+	var nb, qb strings.Builder
+	inQuote := false
+	flushNarration := func() {
+		if strings.TrimSpace(nb.String()) != "" {
+			narration = append(narration, nb.String())
+		}
+		nb.Reset()
+	}
+	for _, r := range content {
+		switch r {
+		case '“', '「', '『':
+			inQuote = true
+			continue
+		case '”', '」', '』':
+			if inQuote {
+				dialogue = append(dialogue, qb.String())
+				qb.Reset()
+			}
+			inQuote = false
+			continue
+		case '"':
+			if inQuote {
+				dialogue = append(dialogue, qb.String())
+				qb.Reset()
+			}
+			inQuote = !inQuote
+			continue
+		case '\n':
+			// 引号没有闭合时在段尾结束对话，避免一个缺失的后引号吞掉后文
+			if inQuote {
+				dialogue = append(dialogue, qb.String())
+				qb.Reset()
+				inQuote = false
+			}
+			flushNarration()
+			continue
+		}
+		if inQuote {
+			qb.WriteRune(r)
+		} else {
+			nb.WriteRune(r)
+		}
+	}
+	if qb.Len() > 0 {
+		dialogue = append(dialogue, qb.String())
+	}
+	flushNarration()
+	return narration, dialogue
+}
+
+// sentenceLength 句长分布
+func sentenceLength(lengths []int) models.SentenceLength {
+	result := models.SentenceLength{Buckets: make([]int, len(sentenceBuckets)+1)}
+	if len(lengths) == 0 {
+		return result
+	}
+	sorted := make([]int, len(lengths))
+	copy(sorted, lengths)
+	sort.Ints(sorted)
+
+	sum := 0
+	for _, n := range sorted {
+		sum += n
+		bucket := len(sentenceBuckets)
+		for i, upper := range sentenceBuckets {
+			if n <= upper {
+				bucket = i
+				break
+			}
+		}
+		result.Buckets[bucket]++
+	}
+	result.Mean = round2(float64(sum) / float64(len(sorted)))
+	result.Median = sorted[len(sorted)/2]
+	result.P90 = sorted[(len(sorted)*9)/10]
+	result.Max = sorted[len(sorted)-1]
+	return result
+}
+
+// countAdverbs 统计副词，返回出现最多的若干个和总次数
+func countAdverbs(content string) ([]models.PhraseCount, int) {
+	counts := make(map[string]int)
+	for _, a := range adverbs {
+		if n := strings.Count(content, a); n > 0 {
+			counts[a] = n
+		}
+	}
+	for _, word := range strings.FieldsFunc(content, func(r rune) bool { return !unicode.IsLetter(r) || r > unicode.MaxASCII }) {
+		if lower := strings.ToLower(word); len(lower) > 4 && strings.HasSuffix(lower, "ly") {
+			counts[lower]++
+		}
+	}
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return topPhrases(counts, maxListed), total
+}
+
+// repeatedPhrases 找出叙述中反复出现的短语：只看汉字组成的片段，
+// 同样次数的短短语被更长的短语包含时只保留长的
+func repeatedPhrases(narration string) []models.PhraseCount {
+	var runs [][]rune
+	var cur []rune
+	for _, r := range narration {
+		if unicode.Is(unicode.Han, r) {
+			cur = append(cur, r)
+			continue
+		}
+		if len(cur) >= minPhraseRunes {
+			runs = append(runs, cur)
+		}
+		cur = nil
+	}
+	if len(cur) >= minPhraseRunes {
+		runs = append(runs, cur)
+	}
+
+	counts := make(map[string]int)
+	for n := minPhraseRunes; n <= maxPhraseRunes; n++ {
+		for _, run := range runs {
+			for i := 0; i+n <= len(run); i++ {
+				counts[string(run[i:i+n])]++
+			}
+		}
+	}
+	candidates := make([]models.PhraseCount, 0)
+	for text, n := range counts {
+		if n >= minPhraseRepeats {
+			candidates = append(candidates, models.PhraseCount{Text: text, Count: n})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		li, lj := len([]rune(candidates[i].Text)), len([]rune(candidates[j].Text))
+		if li != lj {
+			return li > lj
+		}
+		if candidates[i].Count != candidates[j].Count {
+			return candidates[i].Count > candidates[j].Count
+		}
+		return candidates[i].Text < candidates[j].Text
+	})
+
+	kept := make([]models.PhraseCount, 0)
+	for _, c := range candidates {
+		covered := false
+		for _, k := range kept {
+			if strings.Contains(k.Text, c.Text) && k.Count >= c.Count {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			kept = append(kept, c)
+		}
+	}
+	sort.SliceStable(kept, func(i, j int) bool { return kept[i].Count > kept[j].Count })
+	if len(kept) > maxListed {
+		kept = kept[:maxListed]
+	}
+	return kept
+}
+
+// measurePerson 判断叙述人称：叙述中“我”足够密集为第一人称，否则为第三人称；
+// 第三人称叙述中出现“我”的段落计为人称不一致（多为漏了引号的对话或内心独白）
+func measurePerson(m *models.StyleMetrics, narration []string) {
+	words, first := 0, 0
+	for _, p := range narration {
+		words += countText(p)
+		first += firstPersonCount(p)
+	}
+	if words == 0 {
+		return
+	}
+	if float64(first)*1000/float64(words) >= firstPersonDensity {
+		m.Narration = models.NarrationFirst
+		return
+	}
+	paragraphs := 0
+	for _, p := range narration {
+		if countText(p) == 0 {
+			continue
+		}
+		paragraphs++
+		if firstPersonCount(p) > 0 {
+			m.PersonSlips++
+		}
+	}
+	if paragraphs > 0 {
+		m.PersonConsistency = round2(1 - float64(m.PersonSlips)/float64(paragraphs))
+	}
+}
+
+// firstPersonCount 第一人称代词次数：汉字“我”，以及英文 I/me/my
+func firstPersonCount(text string) int {
+	n := strings.Count(text, "我")
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) || r > unicode.MaxASCII }) {
+		switch word {
+		case "I", "me", "my", "Me", "My":
+			n++
+		}
+	}
+	return n
+}
+
+// topPhrases 按次数从高到低取前 n 个
+func topPhrases(counts map[string]int, n int) []models.PhraseCount {
+	list := make([]models.PhraseCount, 0, len(counts))
+	for text, count := range counts {
+		list = append(list, models.PhraseCount{Text: text, Count: count})
+	}
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Count != list[j].Count {
+			return list[i].Count > list[j].Count
+		}
+		return list[i].Text < list[j].Text
+	})
+	if len(list) > n {
+		list = list[:n]
+	}
+	return list
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
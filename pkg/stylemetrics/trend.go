@@ -0,0 +1,199 @@
+package stylemetrics
+
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+const (
+	// DefaultDriftZ 默认的漂移阈值：偏离其余各章均值超过该倍数的标准差
+	DefaultDriftZ = 2.0
+	// minTrendChapters 少于该章数时不判断漂移
+	minTrendChapters = 4
+)
+
+// 漂移指标
+const (
+	MetricSentenceLength = "平均句长"
+	MetricDialogueRatio  = "对话占比"
+	MetricAdverbDensity  = "副词密度"
+	MetricNarration      = "叙述人称"
+)
+
+// ChapterPoint 趋势中的一章
+type ChapterPoint struct {
+	ChapterNum int                  `json:"chapter_num"`
+	Title      string               `json:"title"`
+	Stale      bool                 `json:"stale"` // 章节在统计后被修改过，指标为临时计算结果
+	Metrics    *models.StyleMetrics `json:"metrics"`
+}
+
+// Drift 明显偏离全书水平的章节
+type Drift struct {
+	ChapterNum int     `json:"chapter_num"`
+	Title      string  `json:"title"`
+	Metric     string  `json:"metric"`
+	Value      float64 `json:"value"`
+	Baseline   float64 `json:"baseline"` // 其余各章的均值
+	Z          float64 `json:"z"`
+	Note       string  `json:"note"`
+}
+
+// BookSummary 全书汇总
+type BookSummary struct {
+	Chapters          int     `json:"chapters"`
+	MeanSentenceLen   float64 `json:"mean_sentence_length"`
+	DialogueRatio     float64 `json:"dialogue_ratio"`
+	AdverbDensity     float64 `json:"adverb_density"`
+	Narration         string  `json:"narration"`          // 多数章节的叙述人称
+	PersonConsistency float64 `json:"person_consistency"` // 叙述人称与全书一致的章节占比
+}
+
+// Report 文风趋势报告
+type Report struct {
+	Book     BookSummary    `json:"book"`
+	Chapters []ChapterPoint `json:"chapters"`
+	Drifts   []Drift        `json:"drifts"`
+}
+
+// ForChapter 返回章节的文风指标：已存的指标与当前正文一致时直接使用，否则按正文重新统计
+// 第二个返回值表示是否为重新统计（尚未保存）
+func ForChapter(chapter *models.Chapter, prose string) (*models.StyleMetrics, bool) {
+	if m := chapter.StyleMetrics; m != nil && m.Digest == Digest(prose) {
+		return m, false
+	}
+	return Measure(prose), true
+}
+
+// Build 汇总各章指标成全书趋势，章节按章节号排序，没有正文的章节不计入
+func Build(points []ChapterPoint, z float64) *Report {
+	if z <= 0 {
+		z = DefaultDriftZ
+	}
+	sorted := make([]ChapterPoint, 0, len(points))
+	for _, p := range points {
+		if p.Metrics != nil && p.Metrics.WordCount > 0 {
+			sorted = append(sorted, p)
+		}
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].ChapterNum < sorted[j].ChapterNum })
+
+	report := &Report{Chapters: sorted, Drifts: make([]Drift, 0)}
+	if len(sorted) == 0 {
+		return report
+	}
+
+	series := map[string][]float64{}
+	first := 0
+	for _, p := range sorted {
+		series[MetricSentenceLength] = append(series[MetricSentenceLength], p.Metrics.SentenceLength.Mean)
+		series[MetricDialogueRatio] = append(series[MetricDialogueRatio], p.Metrics.DialogueRatio)
+		series[MetricAdverbDensity] = append(series[MetricAdverbDensity], p.Metrics.AdverbDensity)
+		if p.Metrics.Narration == models.NarrationFirst {
+			first++
+		}
+	}
+
+	report.Book = BookSummary{
+		Chapters:        len(sorted),
+		MeanSentenceLen: round2(mean(series[MetricSentenceLength])),
+		DialogueRatio:   round2(mean(series[MetricDialogueRatio])),
+		AdverbDensity:   round2(mean(series[MetricAdverbDensity])),
+		Narration:       models.NarrationThird,
+	}
+	majority := len(sorted) - first
+	if first > len(sorted)-first {
+		report.Book.Narration = models.NarrationFirst
+		majority = first
+	}
+	report.Book.PersonConsistency = round2(float64(majority) / float64(len(sorted)))
+
+	for _, p := range sorted {
+		if p.Metrics.Narration != report.Book.Narration {
+			report.Drifts = append(report.Drifts, Drift{
+				ChapterNum: p.ChapterNum,
+				Title:      p.Title,
+				Metric:     MetricNarration,
+				Note:       fmt.Sprintf("本章为%s叙述，全书以%s为主", personName(p.Metrics.Narration), personName(report.Book.Narration)),
+			})
+		} else if p.Metrics.PersonSlips > 0 {
+			report.Drifts = append(report.Drifts, Drift{
+				ChapterNum: p.ChapterNum,
+				Title:      p.Title,
+				Metric:     MetricNarration,
+				Value:      p.Metrics.PersonConsistency,
+				Baseline:   1,
+				Note:       fmt.Sprintf("第三人称叙述中有 %d 段出现“我”，检查是否漏了引号", p.Metrics.PersonSlips),
+			})
+		}
+	}
+
+	if len(sorted) >= minTrendChapters {
+		for _, metric := range []string{MetricSentenceLength, MetricDialogueRatio, MetricAdverbDensity} {
+			values := series[metric]
+			for i, p := range sorted {
+				// 与其余各章比较，避免偏离的章节拉高自身的基线
+				others := make([]float64, 0, len(values)-1)
+				others = append(others, values[:i]...)
+				others = append(others, values[i+1:]...)
+				avg, sd := mean(others), stddev(others)
+				if sd == 0 {
+					continue
+				}
+				score := (values[i] - avg) / sd
+				if math.Abs(score) < z {
+					continue
+				}
+				direction := "高于"
+				if score < 0 {
+					direction = "低于"
+				}
+				report.Drifts = append(report.Drifts, Drift{
+					ChapterNum: p.ChapterNum,
+					Title:      p.Title,
+					Metric:     metric,
+					Value:      round2(values[i]),
+					Baseline:   round2(avg),
+					Z:          round2(score),
+					Note:       fmt.Sprintf("%s明显%s其余各章", metric, direction),
+				})
+			}
+		}
+	}
+
+	sort.SliceStable(report.Drifts, func(i, j int) bool { return report.Drifts[i].ChapterNum < report.Drifts[j].ChapterNum })
+	return report
+}
+
+func personName(narration string) string {
+	if narration == models.NarrationFirst {
+		return "第一人称"
+	}
+	return "第三人称"
+}
+
+func mean(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, v := range values {
+		sum += v
+	}
+	return sum / float64(len(values))
+}
+
+func stddev(values []float64) float64 {
+	if len(values) < 2 {
+		return 0
+	}
+	avg := mean(values)
+	sum := 0.0
+	for _, v := range values {
+		sum += (v - avg) * (v - avg)
+	}
+	return math.Sqrt(sum / float64(len(values)))
+}
@@ -0,0 +1,233 @@
+package templatepack
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// maxPackSize 从URL下载模板包的大小上限
+const maxPackSize = 10 << 20
+
+// ConflictStrategy 导入时与已有模板冲突（ID或key相同）的处理方式
+type ConflictStrategy string
+
+const (
+	ConflictNewer     ConflictStrategy = "newer"     // 仅当包中版本更高时覆盖（默认）
+	ConflictSkip      ConflictStrategy = "skip"      // 保留已有模板
+	ConflictOverwrite ConflictStrategy = "overwrite" // 总是覆盖
+	ConflictRename    ConflictStrategy = "rename"    // 以新ID或key导入，保留已有模板
+)
+
+// ParseConflictStrategy 解析冲突处理方式，空字符串为 ConflictNewer
+func ParseConflictStrategy(s string) (ConflictStrategy, error) {
+	switch ConflictStrategy(s) {
+	case "":
+		return ConflictNewer, nil
+	case ConflictNewer, ConflictSkip, ConflictOverwrite, ConflictRename:
+		return ConflictStrategy(s), nil
+	}
+	return "", fmt.Errorf("未知的冲突处理方式: %s（可选 newer/skip/overwrite/rename）", s)
+}
+
+// 导入动作
+const (
+	ActionCreated = "created"
+	ActionUpdated = "updated"
+	ActionSkipped = "skipped"
+	ActionRenamed = "renamed"
+)
+
+// InstallOptions 导入选项
+type InstallOptions struct {
+	Conflict       ConflictStrategy
+	TrustedKeys    []string // 受信任的公钥（base64）
+	AllowUnsigned  bool     // 允许导入未签名的包
+	AllowUntrusted bool     // 允许导入签名有效但公钥不在信任列表中的包
+	DryRun         bool     // 只计算导入结果，不写入数据库
+}
+
+// ItemResult 单个模板的导入结果
+type ItemResult struct {
+	Kind            string `json:"kind"` // structure/prompt
+	ID              string `json:"id"`   // 包中的ID或key
+	Action          string `json:"action"`
+	NewID           string `json:"new_id,omitempty"` // 改名导入时的新ID或key
+	ExistingVersion int    `json:"existing_version,omitempty"`
+	IncomingVersion int    `json:"incoming_version"`
+}
+
+// InstallResult 导入结果
+type InstallResult struct {
+	Name        string       `json:"name"`
+	PackVersion string       `json:"pack_version"`
+	Signed      bool         `json:"signed"`
+	Trusted     bool         `json:"trusted"`
+	SignedBy    string       `json:"signed_by,omitempty"` // 签名公钥
+	Items       []ItemResult `json:"items"`
+}
+
+// Load 从文件路径或 http(s) URL 读取模板包内容
+func Load(source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("读取模板包失败: %w", err)
+		}
+		return data, nil
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Get(source)
+	if err != nil {
+		return nil, fmt.Errorf("下载模板包失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("下载模板包失败: HTTP %d", resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPackSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("下载模板包失败: %w", err)
+	}
+	if len(data) > maxPackSize {
+		return nil, fmt.Errorf("模板包超过 %d MB", maxPackSize>>20)
+	}
+	return data, nil
+}
+
+// Install 校验签名后把模板包导入数据库
+func Install(database db.Database, pack *Pack, opts InstallOptions) (*InstallResult, error) {
+	result := &InstallResult{Name: pack.Name, PackVersion: pack.PackVersion, Items: []ItemResult{}}
+
+	if pack.Signature != nil {
+		key, err := pack.Verify()
+		if err != nil {
+			return nil, err
+		}
+		result.Signed = true
+		result.SignedBy = key
+		for _, k := range opts.TrustedKeys {
+			if k == key {
+				result.Trusted = true
+				break
+			}
+		}
+	}
+	switch {
+	case !result.Signed && !opts.AllowUnsigned:
+		return nil, fmt.Errorf("模板包没有签名，确认来源可靠后可允许导入未签名的包")
+	case result.Signed && !result.Trusted && !opts.AllowUntrusted:
+		return nil, fmt.Errorf("签名公钥 %s 不在信任列表中", result.SignedBy)
+	}
+
+	conflict := opts.Conflict
+	if conflict == "" {
+		conflict = ConflictNewer
+	}
+
+	for _, incoming := range pack.Structures {
+		tmpl := incoming
+		tmpl.UserID = ""
+		if tmpl.Version == 0 {
+			tmpl.Version = 1
+		}
+		item := ItemResult{Kind: "structure", ID: tmpl.ID, IncomingVersion: tmpl.Version}
+
+		existing, _ := database.GetNarrativeTemplate(tmpl.ID)
+		if existing != nil {
+			item.ExistingVersion = existing.Version
+			item.Action = resolve(conflict, existing.Version, tmpl.Version)
+			switch item.Action {
+			case ActionRenamed:
+				tmpl.ID = uniqueID(tmpl.ID, func(id string) bool {
+					found, _ := database.GetNarrativeTemplate(id)
+					return found != nil
+				})
+				item.NewID = tmpl.ID
+			case ActionUpdated:
+				tmpl.CreatedAt = existing.CreatedAt
+			}
+		} else {
+			item.Action = ActionCreated
+		}
+
+		if item.Action != ActionSkipped && !opts.DryRun {
+			if err := database.SaveNarrativeTemplate(&tmpl); err != nil {
+				return nil, fmt.Errorf("保存叙事模板 %s 失败: %w", tmpl.ID, err)
+			}
+		}
+		result.Items = append(result.Items, item)
+	}
+
+	for _, incoming := range pack.Prompts {
+		prompt := incoming
+		if prompt.Version == 0 {
+			prompt.Version = 1
+		}
+		item := ItemResult{Kind: "prompt", ID: prompt.Key, IncomingVersion: prompt.Version}
+
+		existing, _ := database.GetPromptTemplate(prompt.Key)
+		if existing != nil {
+			item.ExistingVersion = existing.Version
+			item.Action = resolve(conflict, existing.Version, prompt.Version)
+			if item.Action == ActionRenamed {
+				prompt.Key = uniqueID(prompt.Key, func(key string) bool {
+					found, _ := database.GetPromptTemplate(key)
+					return found != nil
+				})
+				item.NewID = prompt.Key
+			}
+		} else {
+			item.Action = ActionCreated
+		}
+
+		if item.Action != ActionSkipped && !opts.DryRun {
+			if err := database.SavePromptTemplate(&prompt); err != nil {
+				return nil, fmt.Errorf("保存提示词模板 %s 失败: %w", prompt.Key, err)
+			}
+		}
+		result.Items = append(result.Items, item)
+	}
+	return result, nil
+}
+
+// resolve 按冲突处理方式决定已存在模板的导入动作
+func resolve(conflict ConflictStrategy, existing, incoming int) string {
+	switch conflict {
+	case ConflictSkip:
+		return ActionSkipped
+	case ConflictOverwrite:
+		return ActionUpdated
+	case ConflictRename:
+		return ActionRenamed
+	}
+	if incoming > existing {
+		return ActionUpdated
+	}
+	return ActionSkipped
+}
+
+// uniqueID 在 id 后追加序号，直到不与已有模板冲突
+func uniqueID(id string, exists func(string) bool) string {
+	for i := 2; ; i++ {
+		candidate := fmt.Sprintf("%s_%d", id, i)
+		if !exists(candidate) {
+			return candidate
+		}
+	}
+}
+
+// Counts 按动作统计导入结果
+func (r *InstallResult) Counts() map[string]int {
+	counts := make(map[string]int)
+	for _, item := range r.Items {
+		counts[item.Action]++
+	}
+	return counts
+}
@@ -0,0 +1,237 @@
+// Package templatepack 模板包导入导出
+// 将叙事结构模板和提示词模板打包为带签名的JSON，便于在不同部署之间分享类型流水线。
+// 签名使用 ed25519：导出时用私钥签名，导入时校验签名并检查公钥是否受信任。
+package templatepack
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// 模板包格式标识与版本
+const (
+	PackFormat  = "xupu.template_pack"
+	PackVersion = 1
+
+	// SignatureAlgorithm 签名算法
+	SignatureAlgorithm = "ed25519"
+
+	// SigningKeyEnv 签名私钥（base64 编码的 32 字节种子）所在的环境变量
+	SigningKeyEnv = "XUPU_TEMPLATE_SIGNING_KEY"
+	// TrustedKeysEnv 受信任的公钥（base64，逗号分隔）所在的环境变量
+	TrustedKeysEnv = "XUPU_TRUSTED_TEMPLATE_KEYS"
+)
+
+// Pack 可移植的模板包
+type Pack struct {
+	Format      string                     `json:"format"`
+	Version     int                        `json:"version"`      // 包格式版本
+	Name        string                     `json:"name"`         // 包名，如 "xianxia-pipeline"
+	PackVersion string                     `json:"pack_version"` // 包自身的版本，由作者维护，如 "1.2.0"
+	Author      string                     `json:"author,omitempty"`
+	Description string                     `json:"description,omitempty"`
+	ExportedAt  time.Time                  `json:"exported_at"`
+	Structures  []models.NarrativeTemplate `json:"structures"`
+	Prompts     []models.PromptTemplate    `json:"prompts"`
+	Signature   *Signature                 `json:"signature,omitempty"`
+}
+
+// Signature 模板包签名，签名内容为去掉 signature 字段后的包 JSON
+type Signature struct {
+	Algorithm string `json:"algorithm"`
+	PublicKey string `json:"public_key"` // base64
+	Value     string `json:"value"`      // base64
+}
+
+// ExportOptions 导出选项
+type ExportOptions struct {
+	Name         string
+	PackVersion  string
+	Author       string
+	Description  string
+	StructureIDs []string // 要导出的叙事模板，为空表示全部内置模板（不含用户自定义节拍表）
+	PromptKeys   []string // 要导出的提示词模板，为空表示全部
+}
+
+// Export 从数据库导出模板包（未签名）
+func Export(database db.Database, opts ExportOptions) (*Pack, error) {
+	pack := &Pack{
+		Format:      PackFormat,
+		Version:     PackVersion,
+		Name:        opts.Name,
+		PackVersion: opts.PackVersion,
+		Author:      opts.Author,
+		Description: opts.Description,
+		ExportedAt:  time.Now(),
+		Structures:  []models.NarrativeTemplate{},
+		Prompts:     []models.PromptTemplate{},
+	}
+	if pack.Name == "" {
+		pack.Name = "templates"
+	}
+
+	if len(opts.StructureIDs) > 0 {
+		for _, id := range opts.StructureIDs {
+			tmpl, err := database.GetNarrativeTemplate(id)
+			if err != nil || tmpl == nil {
+				return nil, fmt.Errorf("叙事模板不存在: %s", id)
+			}
+			pack.Structures = append(pack.Structures, exportStructure(*tmpl))
+		}
+	} else {
+		templates, err := database.GetNarrativeTemplates()
+		if err != nil {
+			return nil, fmt.Errorf("获取叙事模板失败: %w", err)
+		}
+		for _, tmpl := range templates {
+			if tmpl.UserID == "" {
+				pack.Structures = append(pack.Structures, exportStructure(tmpl))
+			}
+		}
+	}
+
+	if len(opts.PromptKeys) > 0 {
+		for _, key := range opts.PromptKeys {
+			prompt, err := database.GetPromptTemplate(key)
+			if err != nil || prompt == nil {
+				return nil, fmt.Errorf("提示词模板不存在: %s", key)
+			}
+			pack.Prompts = append(pack.Prompts, *prompt)
+		}
+	} else {
+		prompts, err := database.GetPromptTemplates()
+		if err != nil {
+			return nil, fmt.Errorf("获取提示词模板失败: %w", err)
+		}
+		pack.Prompts = append(pack.Prompts, prompts...)
+	}
+	return pack, nil
+}
+
+// exportStructure 去掉叙事模板中与部署相关的字段
+func exportStructure(tmpl models.NarrativeTemplate) models.NarrativeTemplate {
+	tmpl.UserID = ""
+	if tmpl.Version == 0 {
+		tmpl.Version = 1
+	}
+	return tmpl
+}
+
+// payload 签名内容：去掉 signature 字段后的包 JSON
+func (p *Pack) payload() ([]byte, error) {
+	unsigned := *p
+	unsigned.Signature = nil
+	return json.Marshal(&unsigned)
+}
+
+// Sign 用私钥签名
+func (p *Pack) Sign(key ed25519.PrivateKey) error {
+	data, err := p.payload()
+	if err != nil {
+		return fmt.Errorf("序列化模板包失败: %w", err)
+	}
+	p.Signature = &Signature{
+		Algorithm: SignatureAlgorithm,
+		PublicKey: base64.StdEncoding.EncodeToString(key.Public().(ed25519.PublicKey)),
+		Value:     base64.StdEncoding.EncodeToString(ed25519.Sign(key, data)),
+	}
+	return nil
+}
+
+// Verify 校验签名是否与内容一致，返回签名公钥；未签名的包返回错误
+func (p *Pack) Verify() (string, error) {
+	sig := p.Signature
+	if sig == nil {
+		return "", fmt.Errorf("模板包没有签名")
+	}
+	if sig.Algorithm != SignatureAlgorithm {
+		return "", fmt.Errorf("不支持的签名算法: %s", sig.Algorithm)
+	}
+	pub, err := base64.StdEncoding.DecodeString(sig.PublicKey)
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("签名公钥无效")
+	}
+	value, err := base64.StdEncoding.DecodeString(sig.Value)
+	if err != nil {
+		return "", fmt.Errorf("签名无效")
+	}
+	data, err := p.payload()
+	if err != nil {
+		return "", fmt.Errorf("序列化模板包失败: %w", err)
+	}
+	if !ed25519.Verify(ed25519.PublicKey(pub), data, value) {
+		return "", fmt.Errorf("签名校验失败，模板包可能被篡改")
+	}
+	return sig.PublicKey, nil
+}
+
+// Parse 解析并校验模板包格式
+func Parse(data []byte) (*Pack, error) {
+	var pack Pack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("解析模板包失败: %w", err)
+	}
+	if pack.Format != PackFormat {
+		return nil, fmt.Errorf("不是有效的模板包: format=%q", pack.Format)
+	}
+	if pack.Version > PackVersion {
+		return nil, fmt.Errorf("模板包版本 %d 高于当前支持的版本 %d", pack.Version, PackVersion)
+	}
+	for _, s := range pack.Structures {
+		if s.ID == "" || s.Name == "" {
+			return nil, fmt.Errorf("模板包中的叙事模板缺少ID或名称")
+		}
+	}
+	for _, p := range pack.Prompts {
+		if p.Key == "" {
+			return nil, fmt.Errorf("模板包中的提示词模板缺少key")
+		}
+	}
+	return &pack, nil
+}
+
+// GenerateKey 生成签名密钥，返回 base64 编码的私钥种子和公钥
+func GenerateKey() (string, string, error) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		return "", "", err
+	}
+	return base64.StdEncoding.EncodeToString(priv.Seed()), base64.StdEncoding.EncodeToString(pub), nil
+}
+
+// ParseKey 解析 base64 编码的私钥种子
+func ParseKey(encoded string) (ed25519.PrivateKey, error) {
+	seed, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil || len(seed) != ed25519.SeedSize {
+		return nil, fmt.Errorf("签名私钥无效，应为 base64 编码的 %d 字节种子", ed25519.SeedSize)
+	}
+	return ed25519.NewKeyFromSeed(seed), nil
+}
+
+// SigningKeyFromEnv 从环境变量读取签名私钥，未设置时返回 nil
+func SigningKeyFromEnv() (ed25519.PrivateKey, error) {
+	encoded := os.Getenv(SigningKeyEnv)
+	if encoded == "" {
+		return nil, nil
+	}
+	return ParseKey(encoded)
+}
+
+// TrustedKeysFromEnv 从环境变量读取受信任的公钥
+func TrustedKeysFromEnv() []string {
+	keys := make([]string, 0)
+	for _, k := range strings.Split(os.Getenv(TrustedKeysEnv), ",") {
+		if k = strings.TrimSpace(k); k != "" {
+			keys = append(keys, k)
+		}
+	}
+	return keys
+}
@@ -0,0 +1,237 @@
+// Package theme 主题覆盖报告
+// 汇总各章正文对核心主题、象征和母题的体现，与主题规划的深度安排对照，
+// 找出表达不足的主题元素，并为接下来的章节给出补足建议。
+package theme
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+const (
+	// motifGap 母题连续这么多章没有出现视为冷落
+	motifGap = 5
+	// upcomingChapters 给出建议的后续章节数
+	upcomingChapters = 3
+)
+
+// 元素类型
+const (
+	KindTheme  = "theme"
+	KindSymbol = "symbol"
+	KindMotif  = "motif"
+)
+
+// 问题类型
+const (
+	IssueMissing = "missing" // 计划的章节没有出现
+	IssueShallow = "shallow" // 体现深度不及计划
+	IssueDormant = "dormant" // 长时间没有出现
+)
+
+// Element 一个主题元素的覆盖情况
+type Element struct {
+	Kind     string `json:"kind"`
+	Name     string `json:"name"`
+	Planned  []int  `json:"planned"`   // 计划出现的章节（已分析范围内）
+	Seen     []int  `json:"seen"`      // 实际出现的章节
+	LastSeen int    `json:"last_seen"` // 最近一次出现的章节，0 表示从未出现
+}
+
+// Issue 表达不足之处
+type Issue struct {
+	Kind    string `json:"kind"` // theme/symbol/motif
+	Name    string `json:"name"`
+	Chapter int    `json:"chapter,omitempty"`
+	Problem string `json:"problem"` // missing/shallow/dormant
+	Detail  string `json:"detail"`
+}
+
+// Suggestion 对一个后续章节的主题安排建议
+type Suggestion struct {
+	Chapter int      `json:"chapter"`
+	Title   string   `json:"title,omitempty"`
+	Items   []string `json:"items"`
+}
+
+// Report 主题覆盖报告
+type Report struct {
+	CoreTheme   string       `json:"core_theme"`
+	Analyzed    []int        `json:"analyzed"`    // 已分析的章节
+	ThemeHits   int          `json:"theme_hits"`  // 体现了核心主题的章节数
+	Elements    []Element    `json:"elements"`    // 象征和母题的覆盖情况
+	Issues      []Issue      `json:"issues"`      // 表达不足之处
+	Underserved []string     `json:"underserved"` // 表达不足的元素名称
+	Suggestions []Suggestion `json:"suggestions"` // 接下来几章的建议
+}
+
+// Build 根据各章的主题覆盖分析生成报告，plans 为章节规划，用于给出后续章节的建议
+func Build(plan models.ThemePlan, chapters []*models.Chapter, plans []models.ChapterPlan) *Report {
+	report := &Report{
+		CoreTheme:   plan.CoreTheme,
+		Analyzed:    []int{},
+		Elements:    []Element{},
+		Issues:      []Issue{},
+		Underserved: []string{},
+		Suggestions: []Suggestion{},
+	}
+
+	coverage := make(map[int]*models.ThemeCoverage)
+	latest := 0
+	for _, ch := range chapters {
+		if ch == nil || ch.ThemeCoverage == nil {
+			continue
+		}
+		coverage[ch.ChapterNum] = ch.ThemeCoverage
+		report.Analyzed = append(report.Analyzed, ch.ChapterNum)
+		if ch.ThemeCoverage.Expressed {
+			report.ThemeHits++
+		}
+		if ch.ChapterNum > latest {
+			latest = ch.ChapterNum
+		}
+	}
+	sort.Ints(report.Analyzed)
+
+	underserved := make(map[string]bool)
+	addIssue := func(issue Issue) {
+		report.Issues = append(report.Issues, issue)
+		if !underserved[issue.Name] {
+			underserved[issue.Name] = true
+			report.Underserved = append(report.Underserved, issue.Name)
+		}
+	}
+
+	// 主题深度：逐条对照已分析章节的计划
+	for _, t := range plan.Threading {
+		c, ok := coverage[t.Chapter]
+		if !ok {
+			continue
+		}
+		name := plan.CoreTheme
+		if name == "" {
+			name = t.Expression
+		}
+		switch {
+		case !c.Expressed:
+			addIssue(Issue{Kind: KindTheme, Name: name, Chapter: t.Chapter, Problem: IssueMissing,
+				Detail: fmt.Sprintf("第%d章计划表达“%s”，正文没有体现主题", t.Chapter, t.Expression)})
+		case models.ThemeDepthRank(c.Depth) < models.ThemeDepthRank(t.Depth):
+			addIssue(Issue{Kind: KindTheme, Name: name, Chapter: t.Chapter, Problem: IssueShallow,
+				Detail: fmt.Sprintf("第%d章计划的主题深度为%s，正文只达到%s", t.Chapter, t.Depth, c.Depth)})
+		}
+	}
+
+	// 象征：对照计划出现的章节
+	for _, s := range plan.Symbols {
+		e := Element{Kind: KindSymbol, Name: s.Name, Planned: []int{}, Seen: []int{}}
+		for _, n := range report.Analyzed {
+			if coverage[n].HasSymbol(s.Name) {
+				e.Seen = append(e.Seen, n)
+				e.LastSeen = n
+			}
+		}
+		for _, n := range s.Appearances {
+			c, ok := coverage[n]
+			if !ok {
+				continue
+			}
+			e.Planned = append(e.Planned, n)
+			if !c.HasSymbol(s.Name) {
+				addIssue(Issue{Kind: KindSymbol, Name: s.Name, Chapter: n, Problem: IssueMissing,
+					Detail: fmt.Sprintf("象征“%s”计划在第%d章出现，正文没有出现", s.Name, n)})
+			}
+		}
+		report.Elements = append(report.Elements, e)
+	}
+
+	// 母题：没有章节安排，检查是否长期缺席
+	for _, m := range plan.Motifs {
+		e := Element{Kind: KindMotif, Name: m, Planned: []int{}, Seen: []int{}}
+		for _, n := range report.Analyzed {
+			if coverage[n].HasMotif(m) {
+				e.Seen = append(e.Seen, n)
+				e.LastSeen = n
+			}
+		}
+		switch {
+		case len(report.Analyzed) >= motifGap && e.LastSeen == 0:
+			addIssue(Issue{Kind: KindMotif, Name: m, Problem: IssueDormant,
+				Detail: fmt.Sprintf("母题“%s”在已分析的%d章中从未出现", m, len(report.Analyzed))})
+		case e.LastSeen > 0 && latest-e.LastSeen >= motifGap:
+			addIssue(Issue{Kind: KindMotif, Name: m, Chapter: e.LastSeen, Problem: IssueDormant,
+				Detail: fmt.Sprintf("母题“%s”自第%d章后已有%d章没有出现", m, e.LastSeen, latest-e.LastSeen)})
+		}
+		report.Elements = append(report.Elements, e)
+	}
+
+	report.Suggestions = suggest(plan, plans, latest, report.Issues)
+	return report
+}
+
+// suggest 为已分析章节之后的几章给出主题安排：计划中的表达和象征优先，表达不足的元素补在最近的一章
+func suggest(plan models.ThemePlan, plans []models.ChapterPlan, latest int, issues []Issue) []Suggestion {
+	upcoming := make([]models.ChapterPlan, 0, upcomingChapters)
+	for _, p := range plans {
+		if p.Chapter > latest {
+			upcoming = append(upcoming, p)
+		}
+	}
+	sort.Slice(upcoming, func(i, j int) bool { return upcoming[i].Chapter < upcoming[j].Chapter })
+	if len(upcoming) > upcomingChapters {
+		upcoming = upcoming[:upcomingChapters]
+	}
+
+	out := make([]Suggestion, 0, len(upcoming))
+	for i, p := range upcoming {
+		s := Suggestion{Chapter: p.Chapter, Title: p.Title, Items: []string{}}
+		for _, t := range plan.Threading {
+			if t.Chapter == p.Chapter {
+				s.Items = append(s.Items, fmt.Sprintf("按计划表达主题：%s（深度%s）", t.Expression, t.Depth))
+			}
+		}
+		for _, sym := range plan.Symbols {
+			for _, n := range sym.Appearances {
+				if n == p.Chapter {
+					s.Items = append(s.Items, fmt.Sprintf("安排象征“%s”出现：%s", sym.Name, sym.Meaning))
+					break
+				}
+			}
+		}
+		if i == 0 {
+			s.Items = append(s.Items, catchUp(issues)...)
+		}
+		if len(s.Items) > 0 {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// catchUp 补足表达不足元素的建议，每个元素只给一条
+func catchUp(issues []Issue) []string {
+	seen := make(map[string]bool)
+	items := make([]string, 0)
+	for _, issue := range issues {
+		key := issue.Kind + "/" + issue.Name
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		switch issue.Kind {
+		case KindTheme:
+			if issue.Problem == IssueShallow {
+				items = append(items, fmt.Sprintf("让角色为“%s”做出有代价的选择，把主题写深", issue.Name))
+			} else {
+				items = append(items, fmt.Sprintf("在情节中重新触及主题“%s”", issue.Name))
+			}
+		case KindSymbol:
+			items = append(items, fmt.Sprintf("补上象征“%s”的出现，可作为场景中的意象或道具", issue.Name))
+		case KindMotif:
+			items = append(items, fmt.Sprintf("让母题“%s”重新出现", issue.Name))
+		}
+	}
+	return items
+}
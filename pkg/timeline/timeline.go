@@ -0,0 +1,275 @@
+// Package timeline 故事时间线
+// 为关键事件和场景维护故事内时间，检测不可能的时间顺序（分身、超出世界设定的移动速度）
+package timeline
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// 条目类型
+const (
+	KindScene    = "scene"
+	KindKeyEvent = "key_event"
+)
+
+// 冲突类型
+const (
+	ConflictSimultaneous = "simultaneous" // 同一角色同时出现在两个地点
+	ConflictTravel       = "travel"       // 移动速度超出世界设定
+)
+
+// AspectTimeline 一致性报告中时间线问题的方面名
+const AspectTimeline = "时间线"
+
+// Entry 时间线条目
+type Entry struct {
+	ID         string           `json:"id"`
+	Kind       string           `json:"kind"` // scene, key_event
+	Title      string           `json:"title"`
+	Chapter    int              `json:"chapter,omitempty"`
+	Scene      int              `json:"scene,omitempty"`
+	Location   string           `json:"location"`
+	Characters []string         `json:"characters"`
+	Time       models.StoryTime `json:"time"`
+}
+
+// Timeline 项目时间线
+type Timeline struct {
+	Entries []Entry  `json:"entries"` // 已标注时间的条目，按故事时间排序
+	Untimed []string `json:"untimed"` // 未标注时间的条目ID
+}
+
+// Conflict 时间线冲突
+type Conflict struct {
+	Type      string `json:"type"`
+	Character string `json:"character"`
+	First     string `json:"first"`  // 先发生的条目ID
+	Second    string `json:"second"` // 后发生的条目ID
+	Detail    string `json:"detail"`
+}
+
+// Rules 世界的移动约束
+type Rules struct {
+	TravelTimes    []models.TravelTime
+	MinTravelHours float64
+
+	// 地理：场景地点归属到地区后，按地区边界推算的移动时间校验
+	Geography   *models.Geography
+	RegionTimes []models.TravelTime
+}
+
+// RulesFromWorld 从世界设定读取移动约束
+func RulesFromWorld(world *models.WorldSetting) Rules {
+	if world == nil {
+		return Rules{}
+	}
+	return Rules{
+		TravelTimes:    world.SettingConstraints.TravelTimes,
+		MinTravelHours: world.SettingConstraints.MinTravelHours,
+		Geography:      &world.Geography,
+		RegionTimes:    world.Geography.TravelTimes(),
+	}
+}
+
+// travelHours 两地之间的最短移动时间，没有约束时返回false
+// 优先使用显式列出的地点约束，其次是两地所在地区之间的地图距离，最后是默认最短时间
+func (r Rules) travelHours(from, to string) (float64, bool) {
+	if hours, ok := lookupTravel(r.TravelTimes, from, to); ok {
+		return hours, true
+	}
+	if r.Geography != nil && len(r.RegionTimes) > 0 {
+		a, b := r.Geography.RegionOf(from), r.Geography.RegionOf(to)
+		if a != nil && b != nil && a.Name != b.Name {
+			if hours, ok := lookupTravel(r.RegionTimes, a.Name, b.Name); ok {
+				return hours, true
+			}
+		}
+	}
+	if r.MinTravelHours > 0 {
+		return r.MinTravelHours, true
+	}
+	return 0, false
+}
+
+// lookupTravel 在移动时间表中查找两地（双向）
+func lookupTravel(times []models.TravelTime, from, to string) (float64, bool) {
+	for _, t := range times {
+		if (sameLocation(t.From, from) && sameLocation(t.To, to)) || (sameLocation(t.From, to) && sameLocation(t.To, from)) {
+			return t.Hours, true
+		}
+	}
+	return 0, false
+}
+
+// New 由条目构建时间线，未标注时间的条目单独列出
+func New(entries []Entry, untimed []string) *Timeline {
+	sorted := make([]Entry, len(entries))
+	copy(sorted, entries)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Time.Start < sorted[j].Time.Start
+	})
+
+	if untimed == nil {
+		untimed = make([]string, 0)
+	}
+	return &Timeline{Entries: sorted, Untimed: untimed}
+}
+
+// FromScenes 由蓝图场景指令构建时间线
+func FromScenes(scenes []models.SceneInstruction) *Timeline {
+	entries := make([]Entry, 0, len(scenes))
+	untimed := make([]string, 0)
+	for _, s := range scenes {
+		id := SceneEntryID(s.Chapter, s.Scene)
+		if s.StoryTime == nil {
+			untimed = append(untimed, id)
+			continue
+		}
+		entries = append(entries, Entry{
+			ID:         id,
+			Kind:       KindScene,
+			Title:      fmt.Sprintf("第%d章场景%d", s.Chapter, s.Scene),
+			Chapter:    s.Chapter,
+			Scene:      s.Scene,
+			Location:   s.Location,
+			Characters: s.Characters,
+			Time:       *s.StoryTime,
+		})
+	}
+	return New(entries, untimed)
+}
+
+// SceneEntryID 场景条目ID
+func SceneEntryID(chapter, scene int) string {
+	return fmt.Sprintf("ch%d_s%d", chapter, scene)
+}
+
+// Validate 检测不可能的时间顺序
+func (t *Timeline) Validate(rules Rules) []Conflict {
+	conflicts := make([]Conflict, 0)
+
+	for _, character := range t.characters() {
+		entries := t.entriesFor(character)
+
+		for i := 0; i < len(entries); i++ {
+			a := entries[i]
+			for j := i + 1; j < len(entries); j++ {
+				b := entries[j]
+				if sameLocation(a.Location, b.Location) {
+					continue
+				}
+
+				// 时间重叠：同一角色同时在两个地点
+				if b.Time.Start < a.Time.End() {
+					conflicts = append(conflicts, Conflict{
+						Type:      ConflictSimultaneous,
+						Character: character,
+						First:     a.ID,
+						Second:    b.ID,
+						Detail: fmt.Sprintf("%s在%s（%s）与%s（%s）时间重叠", character,
+							a.Title, a.Location, b.Title, b.Location),
+					})
+					continue
+				}
+
+				// 只检查紧邻的下一次出场的移动时间
+				if j != i+1 {
+					continue
+				}
+				required, ok := rules.travelHours(a.Location, b.Location)
+				gap := b.Time.Start - a.Time.End()
+				if ok && gap < required {
+					conflicts = append(conflicts, Conflict{
+						Type:      ConflictTravel,
+						Character: character,
+						First:     a.ID,
+						Second:    b.ID,
+						Detail: fmt.Sprintf("%s从%s到%s只用了%.1f小时，世界设定至少需要%.1f小时", character,
+							a.Location, b.Location, gap, required),
+					})
+				}
+			}
+		}
+	}
+
+	return conflicts
+}
+
+// characters 时间线中出现的所有角色，按名称排序
+func (t *Timeline) characters() []string {
+	seen := make(map[string]bool)
+	names := make([]string, 0)
+	for _, e := range t.Entries {
+		for _, c := range e.Characters {
+			if c != "" && !seen[c] {
+				seen[c] = true
+				names = append(names, c)
+			}
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// entriesFor 角色出场的条目（已按时间排序），忽略没有地点的条目
+func (t *Timeline) entriesFor(character string) []Entry {
+	result := make([]Entry, 0)
+	for _, e := range t.Entries {
+		if strings.TrimSpace(e.Location) == "" {
+			continue
+		}
+		for _, c := range e.Characters {
+			if c == character {
+				result = append(result, e)
+				break
+			}
+		}
+	}
+	return result
+}
+
+// Issues 将冲突转换为一致性问题
+func Issues(conflicts []Conflict) []models.ConsistencyIssue {
+	issues := make([]models.ConsistencyIssue, 0, len(conflicts))
+	for _, c := range conflicts {
+		issue := models.ConsistencyIssue{
+			Aspect: AspectTimeline,
+			Issue:  c.Detail,
+		}
+		switch c.Type {
+		case ConflictSimultaneous:
+			issue.Severity = "high"
+			issue.Suggestion = "调整其中一个场景的时间或在场角色"
+		case ConflictTravel:
+			issue.Severity = "medium"
+			issue.Suggestion = "拉开两个场景的时间间隔，或交代更快的移动手段"
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// MergeIntoReport 用最新的时间线问题替换一致性报告中原有的时间线问题
+func MergeIntoReport(report *models.ConsistencyReport, issues []models.ConsistencyIssue) *models.ConsistencyReport {
+	if report == nil {
+		report = &models.ConsistencyReport{}
+	}
+
+	kept := make([]models.ConsistencyIssue, 0, len(report.Issues)+len(issues))
+	for _, issue := range report.Issues {
+		if issue.Aspect != AspectTimeline {
+			kept = append(kept, issue)
+		}
+	}
+	report.Issues = append(kept, issues...)
+	return report
+}
+
+// sameLocation 地点是否相同（忽略首尾空白和大小写）
+func sameLocation(a, b string) bool {
+	return strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
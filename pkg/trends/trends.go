@@ -0,0 +1,377 @@
+// Package trends 排行榜趋势 - 定期快照外部榜单，计算上升的题材与标签，推荐当下热门的世界类型与主题组合
+package trends
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// SourceFanqie 番茄小说榜单
+const SourceFanqie = "fanqie"
+
+// DefaultWindow 默认的趋势比较窗口：最近一个窗口与前一个窗口对比
+const DefaultWindow = 7 * 24 * time.Hour
+
+// tagRule 题材标签规则：简介或分类命中关键词即打上标签，可对应世界类型和主题
+type tagRule struct {
+	Tag      string
+	Keywords []string
+	Type     models.WorldType
+	Theme    string
+}
+
+// tagRules 题材标签词典
+var tagRules = []tagRule{
+	{Tag: "修仙", Keywords: []string{"修仙", "修真", "仙门", "宗门", "渡劫", "飞升", "仙侠"}, Type: models.WorldXianxia, Theme: "逆天改命"},
+	{Tag: "玄幻", Keywords: []string{"玄幻", "斗气", "魔法", "异界", "龙族", "奇幻"}, Type: models.WorldFantasy, Theme: "强者之路"},
+	{Tag: "武侠", Keywords: []string{"武侠", "江湖", "武林", "门派", "侠客"}, Type: models.WorldWuxia, Theme: "侠义与恩仇"},
+	{Tag: "都市", Keywords: []string{"都市", "总裁", "职场", "校园", "豪门", "神豪"}, Type: models.WorldUrban, Theme: "逆袭与成长"},
+	{Tag: "历史", Keywords: []string{"历史", "王朝", "皇帝", "权谋", "朝堂", "古代"}, Type: models.WorldHistorical, Theme: "权力与忠诚"},
+	{Tag: "科幻", Keywords: []string{"科幻", "星际", "机甲", "赛博", "未来"}, Type: models.WorldScifi, Theme: "科技与人性"},
+	{Tag: "末世", Keywords: []string{"末世", "丧尸", "天灾", "废土"}, Type: models.WorldScifi, Theme: "人性与生存"},
+	{Tag: "赘婿", Keywords: []string{"赘婿", "上门女婿"}, Type: models.WorldUrban, Theme: "尊严与逆袭"},
+	{Tag: "重生", Keywords: []string{"重生", "重回"}, Theme: "弥补遗憾"},
+	{Tag: "穿越", Keywords: []string{"穿越", "穿书", "魂穿"}},
+	{Tag: "系统", Keywords: []string{"系统", "签到", "面板", "金手指"}},
+	{Tag: "甜宠", Keywords: []string{"甜宠", "宠妻", "恋爱", "追妻"}, Theme: "爱与救赎"},
+	{Tag: "悬疑", Keywords: []string{"悬疑", "推理", "诡异", "惊悚", "规则怪谈"}, Theme: "真相与代价"},
+	{Tag: "复仇", Keywords: []string{"复仇", "报仇", "打脸"}, Theme: "复仇与宽恕"},
+}
+
+// TagTrend 标签热度变化
+type TagTrend struct {
+	Tag      string  `json:"tag"`
+	Current  float64 `json:"current"`  // 最近窗口的平均热度
+	Previous float64 `json:"previous"` // 前一窗口的平均热度
+	Delta    float64 `json:"delta"`
+	New      bool    `json:"new,omitempty"` // 前一窗口未出现
+}
+
+// GenreTrend 世界类型热度变化
+type GenreTrend struct {
+	Type     models.WorldType `json:"type"`
+	Current  float64          `json:"current"`
+	Previous float64          `json:"previous"`
+	Delta    float64          `json:"delta"`
+}
+
+// Report 趋势报告
+type Report struct {
+	Source            string       `json:"source"`
+	Window            string       `json:"window"`
+	CurrentSnapshots  int          `json:"current_snapshots"`
+	PreviousSnapshots int          `json:"previous_snapshots"`
+	Tags              []TagTrend   `json:"tags"`   // 按变化量从大到小
+	Genres            []GenreTrend `json:"genres"` // 按变化量从大到小
+
+	cooccur map[models.WorldType]map[string]float64 // 最近窗口中各世界类型书目上出现的标签热度
+}
+
+// Recommendation 推荐的世界类型与主题组合
+type Recommendation struct {
+	Type   models.WorldType `json:"type"`
+	Theme  string           `json:"theme"`
+	Tags   []string         `json:"tags"` // 同类型书目中正在上升的标签
+	Score  float64          `json:"score"`
+	Reason string           `json:"reason"`
+}
+
+// ExtractTags 由分类和简介提取题材标签
+func ExtractTags(category, description string) []string {
+	text := category + " " + description
+	tags := make([]string, 0)
+	for _, rule := range tagRules {
+		for _, kw := range rule.Keywords {
+			if strings.Contains(text, kw) {
+				tags = append(tags, rule.Tag)
+				break
+			}
+		}
+	}
+	return tags
+}
+
+// NewSnapshot 由榜单书目构建快照
+func NewSnapshot(source, categoryID string, books []models.FanqieBook) *models.RankSnapshot {
+	snapshot := &models.RankSnapshot{
+		ID:         db.GenerateID("rank"),
+		Source:     source,
+		CategoryID: categoryID,
+		Books:      make([]models.RankSnapshotBook, 0, len(books)),
+		TakenAt:    time.Now(),
+	}
+	for i, b := range books {
+		snapshot.Books = append(snapshot.Books, models.RankSnapshotBook{
+			BookID:   b.BookID,
+			BookName: b.BookName,
+			Category: b.Category,
+			Rank:     i + 1,
+			Score:    b.Score,
+			Tags:     ExtractTags(b.Category, b.Description),
+		})
+	}
+	return snapshot
+}
+
+// Analyze 对比最近窗口和前一窗口的快照，计算标签与世界类型的热度变化
+// 每本书按名次加权（榜首为1，末位趋近0），窗口内按快照数取平均
+func Analyze(snapshots []models.RankSnapshot, now time.Time, window time.Duration) *Report {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	report := &Report{
+		Window:  window.String(),
+		Tags:    []TagTrend{},
+		Genres:  []GenreTrend{},
+		cooccur: make(map[models.WorldType]map[string]float64),
+	}
+
+	current, previous := make(map[string]float64), make(map[string]float64)
+	currentGenre, previousGenre := make(map[models.WorldType]float64), make(map[models.WorldType]float64)
+	for _, s := range snapshots {
+		if report.Source == "" {
+			report.Source = s.Source
+		}
+		recent := s.TakenAt.After(now.Add(-window))
+		if !recent && s.TakenAt.Before(now.Add(-2*window)) {
+			continue
+		}
+		tags, genres := current, currentGenre
+		if recent {
+			report.CurrentSnapshots++
+		} else {
+			report.PreviousSnapshots++
+			tags, genres = previous, previousGenre
+		}
+
+		for _, b := range s.Books {
+			weight := rankWeight(b.Rank, len(s.Books))
+			for _, tag := range b.Tags {
+				tags[tag] += weight
+			}
+			for _, t := range bookTypes(b.Tags) {
+				genres[t] += weight
+				if recent {
+					if report.cooccur[t] == nil {
+						report.cooccur[t] = make(map[string]float64)
+					}
+					for _, tag := range b.Tags {
+						report.cooccur[t][tag] += weight
+					}
+				}
+			}
+		}
+	}
+
+	average := func(v float64, n int) float64 {
+		if n == 0 {
+			return 0
+		}
+		return round2(v / float64(n))
+	}
+
+	for _, rule := range tagRules {
+		cur, prev := average(current[rule.Tag], report.CurrentSnapshots), average(previous[rule.Tag], report.PreviousSnapshots)
+		if cur == 0 && prev == 0 {
+			continue
+		}
+		report.Tags = append(report.Tags, TagTrend{
+			Tag:      rule.Tag,
+			Current:  cur,
+			Previous: prev,
+			Delta:    round2(cur - prev),
+			New:      prev == 0 && report.PreviousSnapshots > 0,
+		})
+	}
+	sort.SliceStable(report.Tags, func(i, j int) bool { return report.Tags[i].Delta > report.Tags[j].Delta })
+
+	for _, t := range []models.WorldType{models.WorldXianxia, models.WorldFantasy, models.WorldWuxia, models.WorldUrban, models.WorldHistorical, models.WorldScifi} {
+		cur, prev := average(currentGenre[t], report.CurrentSnapshots), average(previousGenre[t], report.PreviousSnapshots)
+		if cur == 0 && prev == 0 {
+			continue
+		}
+		report.Genres = append(report.Genres, GenreTrend{Type: t, Current: cur, Previous: prev, Delta: round2(cur - prev)})
+	}
+	sort.SliceStable(report.Genres, func(i, j int) bool { return report.Genres[i].Delta > report.Genres[j].Delta })
+
+	return report
+}
+
+// Recommend 推荐 n 个当下热门的世界类型与主题组合：类型按 当前热度+上升量 排序，
+// 主题取同类型书目中上升最快、带主题的标签，没有时用类型的默认主题
+func (r *Report) Recommend(n int) []Recommendation {
+	tagDelta := make(map[string]float64, len(r.Tags))
+	for _, t := range r.Tags {
+		tagDelta[t.Tag] = t.Delta
+	}
+
+	genres := append([]GenreTrend(nil), r.Genres...)
+	sort.SliceStable(genres, func(i, j int) bool {
+		return genres[i].Current+genres[i].Delta > genres[j].Current+genres[j].Delta
+	})
+
+	result := make([]Recommendation, 0, n)
+	for _, g := range genres {
+		if len(result) >= n {
+			break
+		}
+		// 最近窗口没有上榜的类型不推荐
+		if g.Current == 0 {
+			continue
+		}
+		tags := make([]string, 0)
+		for tag := range r.cooccur[g.Type] {
+			if tag != genreLabel(g.Type) {
+				tags = append(tags, tag)
+			}
+		}
+		sort.SliceStable(tags, func(i, j int) bool {
+			si := tagDelta[tags[i]] + r.cooccur[g.Type][tags[i]]
+			sj := tagDelta[tags[j]] + r.cooccur[g.Type][tags[j]]
+			if si != sj {
+				return si > sj
+			}
+			return tags[i] < tags[j]
+		})
+		if len(tags) > 3 {
+			tags = tags[:3]
+		}
+
+		theme := defaultTheme(g.Type)
+		for _, tag := range tags {
+			if t := ruleFor(tag).Theme; t != "" {
+				theme = t
+				break
+			}
+		}
+
+		reason := fmt.Sprintf("%s类近期热度%.2f", genreLabel(g.Type), g.Current)
+		if g.Delta > 0 {
+			reason += fmt.Sprintf("，较前一窗口上升%.2f", g.Delta)
+		}
+		if len(tags) > 0 {
+			reason += "，同类书目中「" + strings.Join(tags, "」「") + "」正在走热"
+		}
+		result = append(result, Recommendation{
+			Type:   g.Type,
+			Theme:  theme,
+			Tags:   tags,
+			Score:  round2(g.Current + g.Delta),
+			Reason: reason,
+		})
+	}
+	return result
+}
+
+// Fetcher 拉取某个分类的榜单
+type Fetcher func(categoryID string) ([]models.FanqieBook, error)
+
+// Snapshotter 定期把榜单写入快照
+type Snapshotter struct {
+	DB         db.Database
+	Source     string
+	Categories []string
+	Fetch      Fetcher
+}
+
+// SnapshotOnce 为每个分类拉取一次榜单并保存，单个分类失败不影响其他分类
+func (s *Snapshotter) SnapshotOnce() ([]*models.RankSnapshot, error) {
+	snapshots := make([]*models.RankSnapshot, 0, len(s.Categories))
+	var lastErr error
+	for _, category := range s.Categories {
+		books, err := s.Fetch(category)
+		if err != nil {
+			lastErr = fmt.Errorf("拉取分类%s榜单失败: %w", category, err)
+			continue
+		}
+		snapshot := NewSnapshot(s.Source, category, books)
+		if err := s.DB.SaveRankSnapshot(snapshot); err != nil {
+			lastErr = fmt.Errorf("保存分类%s快照失败: %w", category, err)
+			continue
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+	if len(snapshots) == 0 && lastErr != nil {
+		return nil, lastErr
+	}
+	return snapshots, nil
+}
+
+// Run 按固定间隔快照，直到 ctx 取消；启动时先快照一次
+func (s *Snapshotter) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.SnapshotOnce(); err != nil {
+			log.Printf("排行榜快照失败: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// rankWeight 名次权重：榜首为1，线性递减
+func rankWeight(rank, total int) float64 {
+	if total <= 0 || rank <= 0 {
+		return 0
+	}
+	return float64(total-rank+1) / float64(total)
+}
+
+// bookTypes 书目标签对应的世界类型（去重）
+func bookTypes(tags []string) []models.WorldType {
+	seen := make(map[models.WorldType]bool)
+	types := make([]models.WorldType, 0)
+	for _, tag := range tags {
+		if t := ruleFor(tag).Type; t != "" && !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
+// ruleFor 按标签查找规则
+func ruleFor(tag string) tagRule {
+	for _, rule := range tagRules {
+		if rule.Tag == tag {
+			return rule
+		}
+	}
+	return tagRule{}
+}
+
+// defaultTheme 世界类型的默认主题（词典中第一个对应该类型的标签的主题）
+func defaultTheme(t models.WorldType) string {
+	for _, rule := range tagRules {
+		if rule.Type == t && rule.Theme != "" {
+			return rule.Theme
+		}
+	}
+	return ""
+}
+
+// genreLabel 世界类型的中文名（词典中第一个对应该类型的标签）
+func genreLabel(t models.WorldType) string {
+	for _, rule := range tagRules {
+		if rule.Type == t {
+			return rule.Tag
+		}
+	}
+	return string(t)
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
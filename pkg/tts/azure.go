@@ -0,0 +1,84 @@
+// Package tts 语音合成 - Azure 语音服务
+package tts
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/pkg/config"
+)
+
+// azureProvider Azure 语音服务（REST 接口）
+type azureProvider struct {
+	endpoint string
+	key      string
+	format   string
+	client   *http.Client
+}
+
+func newAzure(cfg config.AzureTTSConfig) (*azureProvider, error) {
+	if cfg.Region == "" {
+		return nil, fmt.Errorf("未配置 Azure 语音服务的 region")
+	}
+	key, err := cfg.GetAPIKey()
+	if err != nil {
+		return nil, fmt.Errorf("Azure 语音服务: %w", err)
+	}
+	format := cfg.Format
+	if format == "" {
+		format = "audio-24khz-48kbitrate-mono-mp3"
+	}
+	return &azureProvider{
+		endpoint: fmt.Sprintf("https://%s.tts.speech.microsoft.com/cognitiveservices/v1", cfg.Region),
+		key:      key,
+		format:   format,
+		client:   &http.Client{Timeout: 60 * time.Second},
+	}, nil
+}
+
+func (p *azureProvider) Name() string { return "azure" }
+
+func (p *azureProvider) Synthesize(text, voice string) ([]byte, error) {
+	var ssml bytes.Buffer
+	lang := voiceLocale(voice)
+	fmt.Fprintf(&ssml, `<speak version="1.0" xmlns="http://www.w3.org/2001/10/synthesis" xml:lang="%s"><voice name="%s">`, lang, voice)
+	xml.EscapeText(&ssml, []byte(text))
+	ssml.WriteString(`</voice></speak>`)
+
+	req, err := http.NewRequest(http.MethodPost, p.endpoint, &ssml)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Ocp-Apim-Subscription-Key", p.key)
+	req.Header.Set("Content-Type", "application/ssml+xml")
+	req.Header.Set("X-Microsoft-OutputFormat", p.format)
+	req.Header.Set("User-Agent", "xupu")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("请求 Azure 语音服务失败: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("读取合成结果失败: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Azure 语音服务返回 %d: %s", resp.StatusCode, string(data))
+	}
+	return data, nil
+}
+
+// voiceLocale 从音色名（如 zh-CN-XiaoxiaoNeural）取语言区域
+func voiceLocale(voice string) string {
+	parts := strings.SplitN(voice, "-", 3)
+	if len(parts) < 3 {
+		return "zh-CN"
+	}
+	return parts[0] + "-" + parts[1]
+}
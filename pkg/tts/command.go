@@ -0,0 +1,74 @@
+// Package tts 语音合成 - 命令行合成（edge-tts 与本地命令）
+package tts
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/xlei/xupu/pkg/config"
+)
+
+// commandProvider 通过外部命令合成：文本从标准输入传入，命令把音频写入临时文件
+type commandProvider struct {
+	name    string
+	command string
+	args    func(voice, output string) []string
+}
+
+func newEdge(cfg config.EdgeTTSConfig) *commandProvider {
+	command := cfg.Command
+	if command == "" {
+		command = "edge-tts"
+	}
+	p := &commandProvider{name: "edge", command: command}
+	p.args = func(voice, output string) []string {
+		args := []string{"--voice", voice, "--file", "/dev/stdin", "--write-media", output}
+		if cfg.Rate != "" {
+			args = append(args, "--rate="+cfg.Rate)
+		}
+		return args
+	}
+	return p
+}
+
+func newLocal(cfg config.LocalTTSConfig) (*commandProvider, error) {
+	if cfg.Command == "" {
+		return nil, fmt.Errorf("未配置本地语音合成命令")
+	}
+	return &commandProvider{
+		name:    "local",
+		command: cfg.Command,
+		args: func(voice, output string) []string {
+			r := strings.NewReplacer("{voice}", voice, "{output}", output)
+			args := make([]string, len(cfg.Args))
+			for i, a := range cfg.Args {
+				args[i] = r.Replace(a)
+			}
+			return args
+		},
+	}, nil
+}
+
+func (p *commandProvider) Name() string { return p.name }
+
+func (p *commandProvider) Synthesize(text, voice string) ([]byte, error) {
+	path, err := exec.LookPath(p.command)
+	if err != nil {
+		return nil, fmt.Errorf("未找到语音合成命令 %s", p.command)
+	}
+	tmp, err := os.CreateTemp("", "xupu-tts-*.mp3")
+	if err != nil {
+		return nil, fmt.Errorf("创建临时文件失败: %w", err)
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+
+	cmd := exec.Command(path, p.args(voice, tmp.Name())...)
+	cmd.Stdin = strings.NewReader(text)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("%s 合成失败: %v\n%s", p.command, err, out)
+	}
+	return os.ReadFile(tmp.Name())
+}
@@ -0,0 +1,167 @@
+// Package tts 语音合成 - 有声书导出使用的合成服务、文本分段和视角角色音色分配
+package tts
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// defaultSegmentRunes 每次合成的默认最多字数
+const defaultSegmentRunes = 800
+
+// Provider 语音合成服务
+type Provider interface {
+	// Name 服务名称
+	Name() string
+	// Synthesize 用指定音色合成一段文本，返回 MP3 数据
+	Synthesize(text, voice string) ([]byte, error)
+}
+
+// New 按配置创建语音合成服务；演练模式下返回不发起调用的模拟服务
+func New(cfg config.TTSConfig) (Provider, error) {
+	if llm.DryRun() {
+		return mockProvider{}, nil
+	}
+	switch cfg.Provider {
+	case "azure":
+		return newAzure(cfg.Azure)
+	case "edge", "":
+		return newEdge(cfg.Edge), nil
+	case "local":
+		return newLocal(cfg.Local)
+	default:
+		return nil, fmt.Errorf("不支持的语音合成服务: %s", cfg.Provider)
+	}
+}
+
+// SegmentRunes 配置的分段字数，未配置时使用默认值
+func SegmentRunes(cfg config.TTSConfig) int {
+	if cfg.SegmentRunes > 0 {
+		return cfg.SegmentRunes
+	}
+	return defaultSegmentRunes
+}
+
+// Split 把文本切分为不超过 maxRunes 字的片段，优先在段落和句末标点处断开
+func Split(text string, maxRunes int) []string {
+	segments := make([]string, 0)
+	current := make([]rune, 0, maxRunes)
+	flush := func() {
+		if s := strings.TrimSpace(string(current)); s != "" {
+			segments = append(segments, s)
+		}
+		current = current[:0]
+	}
+
+	for _, paragraph := range strings.Split(text, "\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		for _, sentence := range sentences(paragraph) {
+			runes := []rune(sentence)
+			if len(current)+len(runes)+1 > maxRunes {
+				flush()
+			}
+			// 单句超长时硬切
+			for len(runes) > maxRunes {
+				segments = append(segments, string(runes[:maxRunes]))
+				runes = runes[maxRunes:]
+			}
+			current = append(current, runes...)
+		}
+		current = append(current, '\n')
+	}
+	flush()
+	return segments
+}
+
+// sentences 按句末标点切分，标点和紧随的引号留在句尾
+func sentences(paragraph string) []string {
+	out := make([]string, 0)
+	runes := []rune(paragraph)
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if !strings.ContainsRune("。！？!?…；;", runes[i]) {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && strings.ContainsRune("。！？!?…”’」』\"", runes[end]) {
+			end++
+		}
+		out = append(out, string(runes[start:end]))
+		start, i = end, end-1
+	}
+	if start < len(runes) {
+		out = append(out, string(runes[start:]))
+	}
+	return out
+}
+
+// Cast 音色分配：旁白音色和视角角色 -> 音色
+type Cast struct {
+	Narrator string            `json:"narrator"`
+	Voices   map[string]string `json:"voices"`
+}
+
+// Voice 视角角色的音色，未分配的角色使用旁白音色
+func (c Cast) Voice(pov string) string {
+	if v, ok := c.Voices[pov]; ok {
+		return v
+	}
+	return c.Narrator
+}
+
+// CastVoices 为视角角色分配音色：配置中指定的优先，其余按角色档案的性别从音色池轮流分配
+func CastVoices(cfg config.TTSConfig, characters []*models.Character, povs []string) Cast {
+	cast := Cast{Narrator: cfg.Narrator, Voices: make(map[string]string, len(povs))}
+
+	gender := make(map[string]string, len(characters))
+	for _, ch := range characters {
+		gender[ch.Name] = ch.StaticProfile.Gender
+	}
+	sorted := append([]string{}, povs...)
+	sort.Strings(sorted)
+
+	male, female := 0, 0
+	for _, name := range sorted {
+		if name == "" {
+			continue
+		}
+		if _, ok := cast.Voices[name]; ok {
+			continue
+		}
+		if v, ok := cfg.Voices[name]; ok {
+			cast.Voices[name] = v
+			continue
+		}
+		if isFemale(gender[name]) && len(cfg.FemaleVoices) > 0 {
+			cast.Voices[name] = cfg.FemaleVoices[female%len(cfg.FemaleVoices)]
+			female++
+		} else if len(cfg.MaleVoices) > 0 {
+			cast.Voices[name] = cfg.MaleVoices[male%len(cfg.MaleVoices)]
+			male++
+		}
+	}
+	return cast
+}
+
+// isFemale 角色档案中的性别是否为女性
+func isFemale(gender string) bool {
+	g := strings.ToLower(strings.TrimSpace(gender))
+	return strings.Contains(g, "女") || g == "f" || strings.HasPrefix(g, "female")
+}
+
+// mockProvider 演练模式使用的模拟服务，返回空音频
+type mockProvider struct{}
+
+func (mockProvider) Name() string { return "mock" }
+
+func (mockProvider) Synthesize(text, voice string) ([]byte, error) {
+	return []byte{}, nil
+}
@@ -0,0 +1,264 @@
+// Package vitals 角色生死与伤势台账
+// 章节分析时记录死亡、重伤、伤愈和复活，写后续场景前据此查出已故和重伤的角色，
+// 防止已死的角色没有交代就重新出场。
+package vitals
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// AspectVitals 一致性报告中生死问题的方面名
+const AspectVitals = "生死伤势"
+
+// 违规来源
+const (
+	ViolationPlan  = "plan"  // 场景规划让已故角色出场
+	ViolationProse = "prose" // 正文中已故角色没有交代就出场
+)
+
+// Ledger 台账，事件按章节排序
+type Ledger struct {
+	events []models.VitalEvent
+}
+
+// NewLedger 由台账事件构建
+func NewLedger(events []models.VitalEvent) *Ledger {
+	sorted := make([]models.VitalEvent, len(events))
+	copy(sorted, events)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Chapter < sorted[j].Chapter })
+	return &Ledger{events: sorted}
+}
+
+// Load 加载项目的台账，出错时返回空台账
+func Load(database db.Database, projectID string) *Ledger {
+	if projectID == "" {
+		return NewLedger(nil)
+	}
+	events, err := database.ListVitalEvents(projectID)
+	if err != nil {
+		return NewLedger(nil)
+	}
+	return NewLedger(events)
+}
+
+// Events 全部台账事件
+func (l *Ledger) Events() []models.VitalEvent {
+	return l.events
+}
+
+// StateAt 角色在第 chapter 章开始时的状态，只计此前各章的事件
+func (l *Ledger) StateAt(character string, chapter int) models.VitalState {
+	state := models.VitalState{Character: character, Status: models.StatusAlive}
+	for _, e := range l.events {
+		if e.Chapter >= chapter {
+			break
+		}
+		if e.Character != character {
+			continue
+		}
+		apply(&state, e)
+	}
+	return state
+}
+
+// StatesAt 第 chapter 章开始时已故或重伤未愈的角色
+func (l *Ledger) StatesAt(chapter int) []models.VitalState {
+	states := make(map[string]*models.VitalState)
+	order := make([]string, 0)
+	for _, e := range l.events {
+		if e.Chapter >= chapter {
+			break
+		}
+		s, ok := states[e.Character]
+		if !ok {
+			s = &models.VitalState{Character: e.Character, Status: models.StatusAlive}
+			states[e.Character] = s
+			order = append(order, e.Character)
+		}
+		apply(s, e)
+	}
+
+	out := make([]models.VitalState, 0, len(order))
+	for _, name := range order {
+		if s := states[name]; s.Status != models.StatusAlive {
+			out = append(out, *s)
+		}
+	}
+	return out
+}
+
+// Deceased 第 chapter 章开始时已故的角色名
+func (l *Ledger) Deceased(chapter int) []string {
+	names := make([]string, 0)
+	for _, s := range l.StatesAt(chapter) {
+		if s.Status == models.StatusDead {
+			names = append(names, s.Character)
+		}
+	}
+	return names
+}
+
+// resurrectedIn 角色是否在第 chapter 章复活
+func (l *Ledger) resurrectedIn(character string, chapter int) bool {
+	for _, e := range l.events {
+		if e.Chapter == chapter && e.Character == character && e.Event == models.VitalResurrection {
+			return true
+		}
+	}
+	return false
+}
+
+// apply 按事件更新状态；违规记录不改变状态
+func apply(state *models.VitalState, e models.VitalEvent) {
+	switch e.Event {
+	case models.VitalDeath:
+		state.Status = models.StatusDead
+	case models.VitalInjury:
+		if state.Status == models.StatusDead {
+			return
+		}
+		state.Status = models.StatusInjured
+	case models.VitalRecovery, models.VitalResurrection:
+		if e.Event == models.VitalRecovery && state.Status == models.StatusDead {
+			return
+		}
+		state.Status = models.StatusAlive
+	default:
+		return
+	}
+	state.Since = e.Chapter
+	state.Detail = e.Detail
+}
+
+// Violation 已故角色没有交代就出场
+type Violation struct {
+	Character string `json:"character"`
+	Chapter   int    `json:"chapter"`
+	Scene     int    `json:"scene,omitempty"`
+	DiedIn    int    `json:"died_in"` // 死亡的章节
+	Source    string `json:"source"`  // plan/prose
+	Detail    string `json:"detail"`
+}
+
+// Check 检查场景规划和已记录的正文违规，按章节排序
+func (l *Ledger) Check(scenes []models.SceneInstruction) []Violation {
+	violations := append(l.CheckScenes(scenes), l.proseViolations()...)
+	sort.SliceStable(violations, func(i, j int) bool { return violations[i].Chapter < violations[j].Chapter })
+	return violations
+}
+
+// CheckScenes 检查场景规划：出场（含视角角色）的已故角色，除非台账记录其在该章复活，都视为违规
+func (l *Ledger) CheckScenes(scenes []models.SceneInstruction) []Violation {
+	violations := make([]Violation, 0)
+	for _, s := range scenes {
+		names := append([]string{}, s.Characters...)
+		if s.POVCharacter != "" {
+			names = append(names, s.POVCharacter)
+		}
+		seen := make(map[string]bool, len(names))
+		for _, name := range names {
+			if name == "" || seen[name] {
+				continue
+			}
+			seen[name] = true
+			state := l.StateAt(name, s.Chapter)
+			if state.Status != models.StatusDead || l.resurrectedIn(name, s.Chapter) {
+				continue
+			}
+			violations = append(violations, Violation{
+				Character: name,
+				Chapter:   s.Chapter,
+				Scene:     s.Scene,
+				DiedIn:    state.Since,
+				Source:    ViolationPlan,
+				Detail:    fmt.Sprintf("%s已在第%d章死亡（%s），却出现在第%d章场景%d的规划中", name, state.Since, state.Detail, s.Chapter, s.Scene),
+			})
+		}
+	}
+	return violations
+}
+
+// proseViolations 章节分析记录的已故角色没有交代就出场
+func (l *Ledger) proseViolations() []Violation {
+	violations := make([]Violation, 0)
+	for _, e := range l.events {
+		if e.Event != models.VitalReappeared {
+			continue
+		}
+		state := l.StateAt(e.Character, e.Chapter)
+		detail := fmt.Sprintf("%s在第%d章以活人身份出场，但没有交代其如何生还", e.Character, e.Chapter)
+		if e.Evidence != "" {
+			detail += "：" + e.Evidence
+		}
+		violations = append(violations, Violation{
+			Character: e.Character,
+			Chapter:   e.Chapter,
+			DiedIn:    state.Since,
+			Source:    ViolationProse,
+			Detail:    detail,
+		})
+	}
+	return violations
+}
+
+// Issues 将违规转换为一致性问题
+func Issues(violations []Violation) []models.ConsistencyIssue {
+	issues := make([]models.ConsistencyIssue, 0, len(violations))
+	for _, v := range violations {
+		issue := models.ConsistencyIssue{
+			Aspect:   AspectVitals,
+			Issue:    v.Detail,
+			Severity: "high",
+		}
+		switch v.Source {
+		case ViolationPlan:
+			issue.Suggestion = "从场景规划中移除该角色，或改为回忆、遗物等间接出场；确需复活时先在台账中登记复活"
+		case ViolationProse:
+			issue.Suggestion = "改写为回忆、幻象或他人转述，或补写角色生还的经过"
+		}
+		issues = append(issues, issue)
+	}
+	return issues
+}
+
+// MergeIntoReport 用最新的生死问题替换一致性报告中原有的生死问题
+func MergeIntoReport(report *models.ConsistencyReport, issues []models.ConsistencyIssue) *models.ConsistencyReport {
+	if report == nil {
+		report = &models.ConsistencyReport{}
+	}
+	kept := make([]models.ConsistencyIssue, 0, len(report.Issues)+len(issues))
+	for _, issue := range report.Issues {
+		if issue.Aspect != AspectVitals {
+			kept = append(kept, issue)
+		}
+	}
+	report.Issues = append(kept, issues...)
+	return report
+}
+
+// RecordChapter 用章节分析的结果替换该章从正文提取的台账记录，手工登记的记录保留
+func RecordChapter(database db.Database, projectID string, chapter int, changes []models.VitalEvent) error {
+	events := make([]models.VitalEvent, 0, len(changes))
+	for _, e := range changes {
+		e.Character = strings.TrimSpace(e.Character)
+		if e.Character == "" {
+			continue
+		}
+		switch e.Event {
+		case models.VitalDeath, models.VitalInjury, models.VitalRecovery, models.VitalResurrection, models.VitalReappeared:
+		default:
+			continue
+		}
+		e.ID = db.GenerateID("vital")
+		e.ProjectID = projectID
+		e.Chapter = chapter
+		e.Source = models.VitalFromProse
+		events = append(events, e)
+	}
+	return database.ReplaceChapterVitalEvents(projectID, chapter, events)
+}
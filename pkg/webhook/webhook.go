@@ -0,0 +1,352 @@
+// Package webhook 项目事件回调 - 签名投递、失败退避重试和投递记录
+//
+// 投递由固定数量的工作协程从有界队列中取出执行，失败的投递退避后重新入队；
+// 回调地址不能指向内网、本机或链路本地地址，创建时和每次建立连接时都会检查
+//
+// 每次投递 POST 一个 JSON：
+//
+//	{"id": "投递ID", "event": "chapter_generated", "project_id": "...", "timestamp": 1700000000, "data": {...}}
+//
+// 请求头 X-Xupu-Signature 为 "sha256=" + HMAC-SHA256(secret, timestamp + "." + body) 的十六进制，
+// 接收方用同一密钥校验，并可用 X-Xupu-Timestamp 拒绝过旧的请求防止重放
+package webhook
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/logx"
+)
+
+// 请求头
+const (
+	HeaderEvent     = "X-Xupu-Event"
+	HeaderDelivery  = "X-Xupu-Delivery"
+	HeaderTimestamp = "X-Xupu-Timestamp"
+	HeaderSignature = "X-Xupu-Signature"
+)
+
+// MaxAttempts 每次投递的最多尝试次数（含首次）
+const MaxAttempts = 5
+
+// maxResponseBody 投递记录中保存的响应体长度上限
+const maxResponseBody = 1024
+
+// Backoff 第 attempt 次失败后等待多久重试：1s、4s、16s、64s
+var Backoff = func(attempt int) time.Duration {
+	return time.Second << (2 * uint(attempt-1))
+}
+
+// 投递队列：Workers 个工作协程并发投递，队列满时新的投递直接记为失败
+const (
+	Workers   = 4
+	QueueSize = 1024
+)
+
+// ErrForbiddenAddress 回调地址指向内网、本机或链路本地地址
+var ErrForbiddenAddress = errors.New("不允许向内网、本机或链路本地地址投递")
+
+var client = &http.Client{
+	Timeout: 10 * time.Second,
+	Transport: &http.Transport{
+		// 在建立连接时检查解析出的地址，防止域名解析到内网或跳转到内网地址
+		DialContext: (&net.Dialer{
+			Timeout: 5 * time.Second,
+			Control: func(network, address string, _ syscall.RawConn) error {
+				host, _, err := net.SplitHostPort(address)
+				if err != nil {
+					return err
+				}
+				if ip := net.ParseIP(host); ip == nil || forbiddenIP(ip) {
+					return fmt.Errorf("%w: %s", ErrForbiddenAddress, host)
+				}
+				return nil
+			},
+		}).DialContext,
+		MaxIdleConns:        100,
+		IdleConnTimeout:     90 * time.Second,
+		TLSHandshakeTimeout: 10 * time.Second,
+	},
+}
+
+// job 队列中的一次投递
+type job struct {
+	database db.Database
+	hook     *models.Webhook
+	delivery *models.WebhookDelivery
+}
+
+var (
+	queue     chan job
+	startOnce sync.Once
+)
+
+// Payload 投递的请求体
+type Payload struct {
+	ID        string      `json:"id"`
+	Event     string      `json:"event"`
+	ProjectID string      `json:"project_id"`
+	Timestamp int64       `json:"timestamp"`
+	Data      interface{} `json:"data,omitempty"`
+}
+
+// NewSecret 生成签名密钥
+func NewSecret() string {
+	b := make([]byte, 24)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("生成密钥失败: %v", err))
+	}
+	return "whsec_" + hex.EncodeToString(b)
+}
+
+// ValidateURL 校验回调地址：只允许 http/https，且主机解析出的地址都不能是内网、本机或链路本地地址
+func ValidateURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return fmt.Errorf("地址无效: %w", err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("只支持 http 和 https 地址")
+	}
+	host := u.Hostname()
+	if host == "" {
+		return fmt.Errorf("地址缺少主机名")
+	}
+
+	ips := []net.IP{net.ParseIP(host)}
+	if ips[0] == nil {
+		addrs, err := net.LookupIP(host)
+		if err != nil {
+			return fmt.Errorf("解析主机名失败: %w", err)
+		}
+		ips = addrs
+	}
+	for _, ip := range ips {
+		if forbiddenIP(ip) {
+			return fmt.Errorf("%w: %s", ErrForbiddenAddress, ip)
+		}
+	}
+	return nil
+}
+
+// forbiddenIP 是否为不允许投递的地址
+func forbiddenIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// Sign 计算签名：HMAC-SHA256(secret, timestamp + "." + body)
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Emit 把事件放入投递队列，发给项目下订阅了该事件的所有 Webhook，不阻塞调用方
+// 没有配置 Webhook（或数据库不支持）时什么也不做
+func Emit(database db.Database, projectID, event string, data interface{}) {
+	if projectID == "" {
+		return
+	}
+	hooks, err := database.ListWebhooks(projectID)
+	if err != nil || len(hooks) == 0 {
+		return
+	}
+
+	for i := range hooks {
+		hook := &hooks[i]
+		if !hook.Enabled || !hook.Subscribes(event) {
+			continue
+		}
+		delivery, err := NewDelivery(hook, event, data)
+		if err != nil {
+			logx.L().Warn("构建Webhook投递失败", "webhook", hook.ID, "event", event, "error", err)
+			continue
+		}
+		if err := database.SaveWebhookDelivery(delivery); err != nil {
+			logx.L().Warn("保存Webhook投递记录失败", "webhook", hook.ID, "event", event, "error", err)
+			continue
+		}
+		enqueue(database, hook, delivery)
+	}
+}
+
+// NewDelivery 为 Webhook 构建一次待投递的事件
+func NewDelivery(hook *models.Webhook, event string, data interface{}) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{
+		ID:        db.GenerateID("whd"),
+		WebhookID: hook.ID,
+		ProjectID: hook.ProjectID,
+		Event:     event,
+		Status:    models.WebhookDeliveryPending,
+		CreatedAt: time.Now(),
+	}
+	body, err := json.Marshal(Payload{
+		ID:        delivery.ID,
+		Event:     event,
+		ProjectID: hook.ProjectID,
+		Timestamp: delivery.CreatedAt.Unix(),
+		Data:      data,
+	})
+	if err != nil {
+		return nil, err
+	}
+	delivery.Payload = string(body)
+	return delivery, nil
+}
+
+// Redeliver 按原请求体重新投递一次，生成新的投递记录
+func Redeliver(database db.Database, hook *models.Webhook, original *models.WebhookDelivery) (*models.WebhookDelivery, error) {
+	delivery := &models.WebhookDelivery{
+		ID:        db.GenerateID("whd"),
+		WebhookID: hook.ID,
+		ProjectID: hook.ProjectID,
+		Event:     original.Event,
+		Payload:   original.Payload,
+		Status:    models.WebhookDeliveryPending,
+		CreatedAt: time.Now(),
+	}
+	if err := database.SaveWebhookDelivery(delivery); err != nil {
+		return nil, err
+	}
+	enqueue(database, hook, delivery)
+	return delivery, nil
+}
+
+// Ping 同步投递一次 ping 事件，不重试，用于测试配置
+func Ping(database db.Database, hook *models.Webhook) (*models.WebhookDelivery, error) {
+	delivery, err := NewDelivery(hook, models.WebhookEventPing, map[string]string{"webhook_id": hook.ID})
+	if err != nil {
+		return nil, err
+	}
+	delivery.Attempts = 1
+	attempt(hook, delivery)
+	if delivery.Status == models.WebhookDeliverySucceeded {
+		now := time.Now()
+		delivery.DeliveredAt = &now
+	} else {
+		delivery.Status = models.WebhookDeliveryFailed
+	}
+	if err := database.SaveWebhookDelivery(delivery); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// enqueue 把投递放入队列，第一次调用时启动工作协程
+// 队列已满时不等待，直接把投递记为失败，之后可手动重新投递
+func enqueue(database db.Database, hook *models.Webhook, delivery *models.WebhookDelivery) {
+	startOnce.Do(func() {
+		queue = make(chan job, QueueSize)
+		for i := 0; i < Workers; i++ {
+			go func() {
+				for j := range queue {
+					deliver(j.database, j.hook, j.delivery)
+				}
+			}()
+		}
+	})
+
+	select {
+	case queue <- job{database: database, hook: hook, delivery: delivery}:
+	default:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextRetryAt = nil
+		delivery.Error = "投递队列已满"
+		if err := database.SaveWebhookDelivery(delivery); err != nil {
+			logx.L().Warn("保存Webhook投递记录失败", "delivery", delivery.ID, "error", err)
+		}
+		logx.L().Warn("Webhook投递队列已满", "webhook", hook.ID, "delivery", delivery.ID, "event", delivery.Event)
+	}
+}
+
+// deliver 尝试投递一次并更新投递记录，失败时按 Backoff 退避后重新入队
+// 网络错误、5xx 和 429 会重试；其他 4xx 说明请求本身不被接受，不再重试
+func deliver(database db.Database, hook *models.Webhook, delivery *models.WebhookDelivery) {
+	log := logx.L().With("webhook", hook.ID, "delivery", delivery.ID, "event", delivery.Event)
+
+	delivery.Attempts++
+	retryable := attempt(hook, delivery)
+
+	now := time.Now()
+	switch {
+	case delivery.Status == models.WebhookDeliverySucceeded:
+		delivery.DeliveredAt = &now
+		delivery.NextRetryAt = nil
+	case retryable && delivery.Attempts < MaxAttempts:
+		next := now.Add(Backoff(delivery.Attempts))
+		delivery.NextRetryAt = &next
+	default:
+		delivery.Status = models.WebhookDeliveryFailed
+		delivery.NextRetryAt = nil
+	}
+	if err := database.SaveWebhookDelivery(delivery); err != nil {
+		log.Warn("保存Webhook投递记录失败", "error", err)
+	}
+
+	if delivery.NextRetryAt != nil {
+		time.AfterFunc(time.Until(*delivery.NextRetryAt), func() {
+			enqueue(database, hook, delivery)
+		})
+		return
+	}
+	if delivery.Status == models.WebhookDeliveryFailed {
+		log.Warn("Webhook投递失败", "attempts", delivery.Attempts, "code", delivery.ResponseCode, "error", delivery.Error)
+	}
+}
+
+// attempt 发送一次请求并记录结果，返回失败是否值得重试
+func attempt(hook *models.Webhook, delivery *models.WebhookDelivery) bool {
+	body := []byte(delivery.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, hook.URL, bytes.NewReader(body))
+	if err != nil {
+		delivery.Error = err.Error()
+		return false
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Xupu-Webhook/1.0")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, delivery.ID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(hook.Secret, timestamp, body))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		delivery.ResponseCode = 0
+		delivery.ResponseBody = ""
+		delivery.Error = err.Error()
+		return !errors.Is(err, ErrForbiddenAddress)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	delivery.ResponseCode = resp.StatusCode
+	delivery.ResponseBody = string(respBody)
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		delivery.Status = models.WebhookDeliverySucceeded
+		delivery.Error = ""
+		return false
+	}
+	delivery.Error = fmt.Sprintf("HTTP %d", resp.StatusCode)
+	return resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests
+}
@@ -4,14 +4,19 @@ package worldbuilder
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/config"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/jsonx"
 	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/locale"
+	"github.com/xlei/xupu/pkg/logx"
 )
 
 // BuildParams 世界构建参数
@@ -24,6 +29,9 @@ type BuildParams struct {
 
 	// 哲学参数（阶段1）
 	Theme string `json:"theme"` // 核心主题
+
+	// 构建档位，为空时为 standard
+	Tier models.WorldBuildTier `json:"tier,omitempty"`
 }
 
 // Stage1Input 阶段1输入
@@ -77,12 +85,14 @@ type Stage7Input struct {
 type Stage5Output struct {
 	Geography struct {
 		Regions []struct {
-			ID          string   `json:"id"`
-			Name        string   `json:"name"`
-			Type        string   `json:"type"`
-			Description string   `json:"description"`
-			Resources   []string `json:"resources"`
-			Risks       []string `json:"risks"`
+			ID          string                `json:"id"`
+			Name        string                `json:"name"`
+			Type        string                `json:"type"`
+			Description string                `json:"description"`
+			Resources   []string              `json:"resources"`
+			Risks       []string              `json:"risks"`
+			Position    *models.MapPosition   `json:"position"`
+			Borders     []models.RegionBorder `json:"borders"`
 		} `json:"regions"`
 		Resources *struct {
 			Basic     []string `json:"basic"`
@@ -149,9 +159,11 @@ type Stage6Output struct {
 			Obligations []string `json:"obligations"`
 		} `json:"classes"`
 		Economy struct {
-			Type         string   `json:"type"`
-			TradeNetwork string   `json:"trade_network"`
-			Currency     []string `json:"currency"`
+			Type         string                    `json:"type"`
+			TradeNetwork string                    `json:"trade_network"`
+			Currency     []string                  `json:"currency"`
+			Scarcity     []models.ResourceScarcity `json:"scarcity"`
+			TradeRoutes  []models.TradeRoute       `json:"trade_routes"`
 		} `json:"economy"`
 		Laws []struct {
 			Name        string `json:"name"`
@@ -220,18 +232,249 @@ type Stage3Output struct {
 	} `json:"supernatural"`
 }
 
+// philosophy 映射为哲学模型
+func (o Stage1Output) philosophy() *models.Philosophy {
+	return &models.Philosophy{
+		CoreQuestion: o.CoreQuestion,
+		Derivation:   o.Derivation,
+		ValueSystem:  o.ValueSystem,
+		Themes:       o.Themes,
+	}
+}
+
+// worldview 映射为世界观模型
+func (o Stage2Output) worldview() *models.Worldview {
+	return &models.Worldview{
+		Derivation:  o.DerivationLogic,
+		Cosmology:   o.Cosmology,
+		Metaphysics: o.Metaphysics,
+	}
+}
+
+// laws 映射为法则模型
+func (o Stage3Output) laws() *models.Laws {
+	// 构建法则对象（需要将LLM输出映射到模型结构）
+	laws := &models.Laws{
+		Physics: models.Physics{
+			Gravity:            o.Physics.Gravity,
+			TimeFlow:           o.Physics.TimeFlow,
+			EnergyConservation: o.Physics.EnergyConservation,
+			Causality:          o.Physics.Causality,
+			DeathNature:        o.Physics.DeathNature,
+		},
+	}
+
+	// 处理超自然体系
+	if o.Supernatural != nil && o.Supernatural.Exists {
+		supernatural := &models.Supernatural{
+			Exists: o.Supernatural.Exists,
+			Type:   o.Supernatural.Type,
+		}
+
+		// 根据类型创建详细设定
+		settings := &models.SupernaturalSettings{}
+
+		switch o.Supernatural.Type {
+		case "magic":
+			settings.MagicSystem = &models.MagicSystem{
+				Source:     o.Supernatural.Source,
+				Cost:       o.Supernatural.Cost,
+				Limitation: o.Supernatural.Limitation,
+			}
+		case "cultivation":
+			settings.CultivationSystem = &models.CultivationSystem{
+				// 简化的修真体系设定，实际由LLM生成
+				ResourceSystem: o.Supernatural.Source,
+			}
+			if len(o.Supernatural.Limitation) > 0 {
+				settings.CultivationSystem.Bottleneck = o.Supernatural.Limitation[0]
+			}
+		case "superpower":
+			settings.SuperpowerSystem = &models.SuperpowerSystem{
+				Origin: o.Supernatural.Source,
+				Type:   o.Supernatural.Type,
+				Limit:  o.Supernatural.Limitation,
+			}
+		}
+
+		if settings.MagicSystem != nil || settings.CultivationSystem != nil || settings.SuperpowerSystem != nil {
+			supernatural.Settings = settings
+		}
+
+		laws.Supernatural = supernatural
+	}
+
+	return laws
+}
+
+// geography 映射为地理模型
+func (o Stage5Output) geography() *models.Geography {
+	// 构建地理对象
+	geography := &models.Geography{
+		Regions: make([]models.Region, len(o.Geography.Regions)),
+	}
+
+	// 映射区域
+	for i, r := range o.Geography.Regions {
+		geography.Regions[i] = models.Region{
+			ID:          r.ID,
+			Name:        r.Name,
+			Type:        r.Type,
+			Description: r.Description,
+			Resources:   r.Resources,
+			Risks:       r.Risks,
+			Position:    r.Position,
+			Borders:     r.Borders,
+		}
+	}
+
+	// 映射资源
+	if o.Geography.Resources != nil {
+		geography.Resources = &models.Resources{
+			Basic:     o.Geography.Resources.Basic,
+			Strategic: o.Geography.Resources.Strategic,
+			Rare:      o.Geography.Resources.Rare,
+		}
+	}
+
+	// 映射气候
+	if o.Geography.Climate != nil {
+		geography.Climate = &models.Climate{
+			Type:     o.Geography.Climate.Type,
+			Seasons:  o.Geography.Climate.Seasons,
+			Features: o.Geography.Climate.Features,
+		}
+	}
+
+	return geography
+}
+
+// result 映射为文明和社会模型
+func (o Stage6Output) result() *Stage6Result {
+	// 构建文明对象
+	civilization := &models.Civilization{
+		Races:     make([]models.Race, len(o.Civilization.Races)),
+		Languages: make([]models.Language, len(o.Civilization.Languages)),
+		Religions: make([]models.Religion, len(o.Civilization.Religions)),
+	}
+
+	// 映射种族
+	for i, r := range o.Civilization.Races {
+		civilization.Races[i] = models.Race{
+			ID:          r.ID,
+			Name:        r.Name,
+			Description: r.Description,
+			Traits:      r.Traits,
+			Abilities:   r.Abilities,
+			Relations:   r.Relations,
+		}
+	}
+
+	// 映射语言
+	for i, l := range o.Civilization.Languages {
+		civilization.Languages[i] = models.Language{
+			ID:       l.ID,
+			Name:     l.Name,
+			Type:     l.Type,
+			Speakers: l.Speakers,
+			Features: l.Features,
+		}
+	}
+
+	// 映射宗教
+	for i, r := range o.Civilization.Religions {
+		civilization.Religions[i] = models.Religion{
+			ID:           r.ID,
+			Name:         r.Name,
+			Type:         r.Type,
+			Cosmology:    r.Cosmology,
+			Ethics:       r.Ethics,
+			Practices:    r.Practices,
+			Organization: r.Organization,
+		}
+	}
+
+	// 构建社会对象
+	powerStructure := &models.PowerStructure{
+		Formal:            make([]models.PowerLevel, len(o.Society.Politics.PowerStructure.Formal)),
+		Actual:            make([]models.PowerHolder, len(o.Society.Politics.PowerStructure.Actual)),
+		ChecksAndBalances: "",
+	}
+
+	// 映射权力层级
+	for i, p := range o.Society.Politics.PowerStructure.Formal {
+		powerStructure.Formal[i] = models.PowerLevel{
+			Level:  p.Level,
+			Name:   p.Name,
+			Powers: p.Powers,
+		}
+	}
+
+	// 映射实际掌权者
+	for i, p := range o.Society.Politics.PowerStructure.Actual {
+		powerStructure.Actual[i] = models.PowerHolder{
+			Entity:       p.Entity,
+			PowerSource:  p.PowerSource,
+			Relationship: p.Relationship,
+		}
+	}
+
+	society := &models.Society{
+		Politics: models.Politics{
+			Type:             o.Society.Politics.Type,
+			LegitimacySource: o.Society.Politics.LegitimacySource,
+			PowerStructure:   powerStructure,
+		},
+		Classes: make([]models.Class, len(o.Society.Classes)),
+		Economy: models.Economy{
+			Type:         o.Society.Economy.Type,
+			TradeNetwork: o.Society.Economy.TradeNetwork,
+			Currency:     o.Society.Economy.Currency,
+			Scarcity:     o.Society.Economy.Scarcity,
+			TradeRoutes:  o.Society.Economy.TradeRoutes,
+		},
+		Laws: make([]models.Law, len(o.Society.Laws)),
+	}
+
+	// 映射社会阶级
+	for i, c := range o.Society.Classes {
+		society.Classes[i] = models.Class{
+			Name:        c.Name,
+			Rank:        c.Rank,
+			Rights:      c.Rights,
+			Obligations: c.Obligations,
+		}
+	}
+
+	// 映射法律
+	for i, l := range o.Society.Laws {
+		society.Laws[i] = models.Law{
+			Name:        l.Name,
+			Type:        l.Type,
+			Description: l.Description,
+			Penalty:     "", // 阶段6不生成惩罚细节
+		}
+	}
+
+	return &Stage6Result{
+		Civilization: civilization,
+		Society:      society,
+	}
+}
+
 // WorldBuilder 世界设定器
 type WorldBuilder struct {
 	db      db.Database
 	cfg     *config.Config
 	client  *llm.Client
 	mapping *config.ModuleMapping
+	logger  *slog.Logger
 }
 
 // New 创建世界设定器
 func New() (*WorldBuilder, error) {
 	// 加载配置
-	cfg, err := config.Load("config/config.yaml")
+	cfg, err := config.Current()
 	if err != nil {
 		return nil, fmt.Errorf("加载配置失败: %w", err)
 	}
@@ -250,8 +493,61 @@ func New() (*WorldBuilder, error) {
 	}, nil
 }
 
-// Build 完整构建世界（执行所有7个阶段）
+// WithLogger 返回使用指定日志的世界设定器副本，LLM客户端一并切换，用于按请求/任务串联日志
+func (wb *WorldBuilder) WithLogger(l *slog.Logger) *WorldBuilder {
+	cp := *wb
+	cp.logger = l
+	if wb.client != nil {
+		cp.client = wb.client.WithLogger(l)
+	}
+	return &cp
+}
+
+// WithMeter 返回把LLM用量记到指定计量器的世界设定器副本
+func (wb *WorldBuilder) WithMeter(m *llm.Meter) *WorldBuilder {
+	cp := *wb
+	if wb.client != nil {
+		cp.client = wb.client.WithMeter(m)
+	}
+	return &cp
+}
+
+// WithBudget 返回请求前检查指定预算的世界设定器副本
+func (wb *WorldBuilder) WithBudget(b llm.Budget) *WorldBuilder {
+	cp := *wb
+	if wb.client != nil {
+		cp.client = wb.client.WithBudget(b)
+	}
+	return &cp
+}
+
+// WithLanguage 返回按指定语言输出的世界设定器副本，LLM客户端的系统提示词追加输出语言要求
+func (wb *WorldBuilder) WithLanguage(lang string) *WorldBuilder {
+	cp := *wb
+	if wb.client != nil {
+		cp.client = wb.client.WithInstruction(locale.Instruction(lang))
+	}
+	return &cp
+}
+
+// log 当前使用的日志
+func (wb *WorldBuilder) log() *slog.Logger {
+	return logx.Or(wb.logger)
+}
+
+// Build 按档位构建世界：quick 合并提示词快速构建，standard 执行7个阶段，deep 使用高信息熵多轮构建
 func (wb *WorldBuilder) Build(params BuildParams) (*models.WorldSetting, error) {
+	switch params.Tier {
+	case models.BuildTierQuick:
+		return wb.buildQuick(params)
+	case models.BuildTierDeep:
+		return wb.detailed().Build(params)
+	}
+	return wb.buildStandard(params)
+}
+
+// buildStandard 完整构建世界（执行所有7个阶段，每阶段一次调用）
+func (wb *WorldBuilder) buildStandard(params BuildParams) (*models.WorldSetting, error) {
 	// 创建世界设定对象
 	world := &models.WorldSetting{
 		ID:    db.GenerateID("world"),
@@ -259,9 +555,12 @@ func (wb *WorldBuilder) Build(params BuildParams) (*models.WorldSetting, error)
 		Type:  params.Type,
 		Scale: params.Scale,
 		Style: params.Style,
+
+		BuildTier: models.BuildTierStandard,
 	}
 
 	// 阶段1: 哲学基础
+	wb.log().Info("世界构建阶段", "world_id", world.ID, "stage", 1, "name", "哲学基础")
 	philosophy, _, err := wb.GenerateStage1(Stage1Input{
 		WorldType: string(params.Type),
 		Theme:     params.Theme,
@@ -276,6 +575,7 @@ func (wb *WorldBuilder) Build(params BuildParams) (*models.WorldSetting, error)
 	}
 
 	// 阶段2: 世界观
+	wb.log().Info("世界构建阶段", "world_id", world.ID, "stage", 2, "name", "世界观")
 	worldview, _, err := wb.GenerateStage2(Stage2Input{
 		CoreQuestion: philosophy.CoreQuestion,
 		HighestGood:  philosophy.ValueSystem.HighestGood,
@@ -290,6 +590,7 @@ func (wb *WorldBuilder) Build(params BuildParams) (*models.WorldSetting, error)
 	}
 
 	// 阶段3: 法则设定
+	wb.log().Info("世界构建阶段", "world_id", world.ID, "stage", 3, "name", "法则设定")
 	worldviewSummary := fmt.Sprintf("起源:%s 结构:%s", worldview.Cosmology.Origin, worldview.Cosmology.Structure)
 	laws, _, err := wb.GenerateStage3(Stage3Input{
 		WorldType: string(params.Type),
@@ -304,6 +605,7 @@ func (wb *WorldBuilder) Build(params BuildParams) (*models.WorldSetting, error)
 	}
 
 	// 阶段4: 故事土壤
+	wb.log().Info("世界构建阶段", "world_id", world.ID, "stage", 4, "name", "故事土壤")
 	// 准备主要矛盾摘要
 	mainConflicts := ""
 	if len(philosophy.ValueSystem.MoralDilemmas) > 0 {
@@ -324,6 +626,7 @@ func (wb *WorldBuilder) Build(params BuildParams) (*models.WorldSetting, error)
 	}
 
 	// 阶段5: 地理环境
+	wb.log().Info("世界构建阶段", "world_id", world.ID, "stage", 5, "name", "地理环境")
 	// 准备法则摘要
 	lawsSummary := fmt.Sprintf("物理:%s 超自然:%v", laws.Physics.Gravity, laws.Supernatural != nil && laws.Supernatural.Exists)
 	civilizationNeeds := fmt.Sprintf("资源需求基于%s类型的世界", params.Type)
@@ -343,14 +646,9 @@ func (wb *WorldBuilder) Build(params BuildParams) (*models.WorldSetting, error)
 	}
 
 	// 阶段6: 文明社会
+	wb.log().Info("世界构建阶段", "world_id", world.ID, "stage", 6, "name", "文明社会")
 	// 准备地理摘要
-	geographySummary := fmt.Sprintf("%d个区域, 气候:%s", len(geography.Regions),
-		func() string {
-			if geography.Climate != nil {
-				return geography.Climate.Type
-			}
-			return "未知"
-		}())
+	geographySummary := GeographySummary(geography)
 	valueSystem := fmt.Sprintf("最高善:%s", philosophy.ValueSystem.HighestGood)
 
 	civResult, _, err := wb.GenerateStage6(Stage6Input{
@@ -363,18 +661,23 @@ func (wb *WorldBuilder) Build(params BuildParams) (*models.WorldSetting, error)
 	}
 	world.Civilization = *civResult.Civilization
 	world.Society = *civResult.Society
+	registerWorldNames(world)
+	world.RebuildEntityIndex()
 	if err := wb.db.SaveWorld(world); err != nil {
 		return nil, fmt.Errorf("保存阶段6失败: %w", err)
 	}
 
 	// 阶段7: 一致性检查
-	// 构建世界设定摘要
+	wb.log().Info("世界构建阶段", "world_id", world.ID, "stage", 7, "name", "一致性检查")
+	// 构建世界设定摘要；检查报告不影响设定本身，失败时按降级策略处理
 	worldSummary := wb.buildWorldSummary(world)
 	report, _, err := wb.GenerateStage7(Stage7Input{
 		WorldSettingSummary: worldSummary,
 	})
 	if err != nil {
-		return nil, fmt.Errorf("阶段7失败: %w", err)
+		if err := wb.degrade(world, "consistency_report", fmt.Errorf("一致性检查失败，未生成检查报告: %w", err)); err != nil {
+			return nil, fmt.Errorf("阶段7失败: %w", err)
+		}
 	}
 	world.ConsistencyReport = report
 	if err := wb.db.SaveWorld(world); err != nil {
@@ -403,30 +706,18 @@ func (wb *WorldBuilder) GenerateStage1(input Stage1Input) (*models.Philosophy, s
 	systemPrompt := wb.cfg.GetWorldBuilderSystem()
 
 	// 调用LLM（带重试）
-	result, err := wb.callWithRetry(prompt, systemPrompt)
+	result, err := wb.callForRole(roleStage1, prompt, systemPrompt)
 	if err != nil {
 		return nil, "", err
 	}
 
 	// 解析输出
 	var output Stage1Output
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		// 尝试提取JSON
-		extracted := extractJSON(result)
-		if err := json.Unmarshal([]byte(extracted), &output); err != nil {
-			return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
-		}
-	}
-
-	// 构建哲学对象
-	philosophy := &models.Philosophy{
-		CoreQuestion: output.CoreQuestion,
-		Derivation:   output.Derivation,
-		ValueSystem:  output.ValueSystem,
-		Themes:       output.Themes,
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
 	}
 
-	return philosophy, prompt, nil
+	return output.philosophy(), prompt, nil
 }
 
 // GenerateStage1ForWorld 为已有世界生成/重新生成阶段1
@@ -458,29 +749,18 @@ func (wb *WorldBuilder) GenerateStage2(input Stage2Input) (*models.Worldview, st
 	systemPrompt := wb.cfg.GetWorldBuilderSystem()
 
 	// 调用LLM（带重试）
-	result, err := wb.callWithRetry(prompt, systemPrompt)
+	result, err := wb.callForRole(roleStage2, prompt, systemPrompt)
 	if err != nil {
 		return nil, "", err
 	}
 
 	// 解析输出
 	var output Stage2Output
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		// 尝试提取JSON
-		extracted := extractJSON(result)
-		if err := json.Unmarshal([]byte(extracted), &output); err != nil {
-			return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
-		}
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
 	}
 
-	// 构建世界观对象
-	worldview := &models.Worldview{
-		Derivation:  output.DerivationLogic,
-		Cosmology:   output.Cosmology,
-		Metaphysics: output.Metaphysics,
-	}
-
-	return worldview, prompt, nil
+	return output.worldview(), prompt, nil
 }
 
 // GenerateStage2ForWorld 为已有世界生成/重新生成阶段2
@@ -511,73 +791,18 @@ func (wb *WorldBuilder) GenerateStage3(input Stage3Input) (*models.Laws, string,
 	systemPrompt := wb.cfg.GetWorldBuilderSystem()
 
 	// 调用LLM（带重试）
-	result, err := wb.callWithRetry(prompt, systemPrompt)
+	result, err := wb.callForRole(roleStage3, prompt, systemPrompt)
 	if err != nil {
 		return nil, "", err
 	}
 
 	// 解析输出
 	var output Stage3Output
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		// 尝试提取JSON
-		extracted := extractJSON(result)
-		if err := json.Unmarshal([]byte(extracted), &output); err != nil {
-			return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
-		}
-	}
-
-	// 构建法则对象（需要将LLM输出映射到模型结构）
-	laws := &models.Laws{
-		Physics: models.Physics{
-			Gravity:            output.Physics.Gravity,
-			TimeFlow:           output.Physics.TimeFlow,
-			EnergyConservation: output.Physics.EnergyConservation,
-			Causality:          output.Physics.Causality,
-			DeathNature:        output.Physics.DeathNature,
-		},
-	}
-
-	// 处理超自然体系
-	if output.Supernatural != nil && output.Supernatural.Exists {
-		supernatural := &models.Supernatural{
-			Exists: output.Supernatural.Exists,
-			Type:   output.Supernatural.Type,
-		}
-
-		// 根据类型创建详细设定
-		settings := &models.SupernaturalSettings{}
-
-		switch output.Supernatural.Type {
-		case "magic":
-			settings.MagicSystem = &models.MagicSystem{
-				Source:     output.Supernatural.Source,
-				Cost:       output.Supernatural.Cost,
-				Limitation: output.Supernatural.Limitation,
-			}
-		case "cultivation":
-			settings.CultivationSystem = &models.CultivationSystem{
-				// 简化的修真体系设定，实际由LLM生成
-				ResourceSystem: output.Supernatural.Source,
-			}
-			if len(output.Supernatural.Limitation) > 0 {
-				settings.CultivationSystem.Bottleneck = output.Supernatural.Limitation[0]
-			}
-		case "superpower":
-			settings.SuperpowerSystem = &models.SuperpowerSystem{
-				Origin: output.Supernatural.Source,
-				Type:   output.Supernatural.Type,
-				Limit:  output.Supernatural.Limitation,
-			}
-		}
-
-		if settings.MagicSystem != nil || settings.CultivationSystem != nil || settings.SuperpowerSystem != nil {
-			supernatural.Settings = settings
-		}
-
-		laws.Supernatural = supernatural
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
 	}
 
-	return laws, prompt, nil
+	return output.laws(), prompt, nil
 }
 
 // GenerateStage3ForWorld 为已有世界生成/重新生成阶段3
@@ -609,19 +834,15 @@ func (wb *WorldBuilder) GenerateStage4(input Stage4Input) (*models.StorySoil, st
 	systemPrompt := wb.cfg.GetWorldBuilderSystem()
 
 	// 调用LLM（带重试）
-	result, err := wb.callWithRetry(prompt, systemPrompt)
+	result, err := wb.callForRole(roleStage4, prompt, systemPrompt)
 	if err != nil {
 		return nil, "", err
 	}
 
 	// 解析输出
 	var output models.StorySoil
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		// 尝试提取JSON
-		extracted := extractJSON(result)
-		if err := json.Unmarshal([]byte(extracted), &output); err != nil {
-			return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
-		}
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
 	}
 
 	return &output, prompt, nil
@@ -647,75 +868,27 @@ func (wb *WorldBuilder) GenerateStage5(input Stage5Input) (*models.Geography, st
 		"CivilizationNeeds": input.CivilizationNeeds,
 	}
 
-	var prompt string
-	var err error
-
-	// 历史世界使用专门的提示词，生成真实地理名称
-	if input.WorldType == string(models.WorldHistorical) {
-		prompt = wb.buildHistoricalGeographyPrompt(data)
-	} else {
-		// 渲染提示词
-		prompt, err = wb.cfg.GetWorldBuilderStage5(data)
-		if err != nil {
-			return nil, "", fmt.Errorf("渲染提示词失败: %w", err)
-		}
+	prompt, err := wb.stage5Prompt(data)
+	if err != nil {
+		return nil, "", err
 	}
 
 	// 获取系统提示词
 	systemPrompt := wb.cfg.GetWorldBuilderSystem()
 
 	// 调用LLM（带重试）
-	result, err := wb.callWithRetry(prompt, systemPrompt)
+	result, err := wb.callForRole(roleStage5, prompt, systemPrompt)
 	if err != nil {
 		return nil, "", err
 	}
 
 	// 解析输出
 	var output Stage5Output
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		// 尝试提取JSON
-		extracted := extractJSON(result)
-		if err := json.Unmarshal([]byte(extracted), &output); err != nil {
-			return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
-		}
-	}
-
-	// 构建地理对象
-	geography := &models.Geography{
-		Regions: make([]models.Region, len(output.Geography.Regions)),
-	}
-
-	// 映射区域
-	for i, r := range output.Geography.Regions {
-		geography.Regions[i] = models.Region{
-			ID:          r.ID,
-			Name:        r.Name,
-			Type:        r.Type,
-			Description: r.Description,
-			Resources:   r.Resources,
-			Risks:       r.Risks,
-		}
-	}
-
-	// 映射资源
-	if output.Geography.Resources != nil {
-		geography.Resources = &models.Resources{
-			Basic:     output.Geography.Resources.Basic,
-			Strategic: output.Geography.Resources.Strategic,
-			Rare:      output.Geography.Resources.Rare,
-		}
-	}
-
-	// 映射气候
-	if output.Geography.Climate != nil {
-		geography.Climate = &models.Climate{
-			Type:     output.Geography.Climate.Type,
-			Seasons:  output.Geography.Climate.Seasons,
-			Features: output.Geography.Climate.Features,
-		}
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
 	}
 
-	return geography, prompt, nil
+	return output.geography(), prompt, nil
 }
 
 // GenerateStage5ForWorld 为已有世界生成/重新生成阶段5
@@ -728,6 +901,34 @@ func (wb *WorldBuilder) GenerateStage5ForWorld(worldID string, input Stage5Input
 	return wb.db.UpdateWorldStage(worldID, "geography", geography)
 }
 
+// GeographySummary 阶段6使用的地理摘要：区域数量、气候以及各区域名称和资源（经济模型需要引用真实区域）
+func GeographySummary(g *models.Geography) string {
+	climate := "未知"
+	if g.Climate != nil {
+		climate = g.Climate.Type
+	}
+	summary := fmt.Sprintf("%d个区域, 气候:%s", len(g.Regions), climate)
+	for _, r := range g.Regions {
+		summary += fmt.Sprintf("\n- %s(%s)", r.Name, r.Type)
+		if len(r.Resources) > 0 {
+			summary += " 资源:" + strings.Join(r.Resources, "、")
+		}
+	}
+	return summary
+}
+
+// stage5Prompt 渲染阶段5提示词，历史世界使用专门的提示词以生成真实地理名称
+func (wb *WorldBuilder) stage5Prompt(data map[string]interface{}) (string, error) {
+	if data["WorldType"] == string(models.WorldHistorical) {
+		return wb.buildHistoricalGeographyPrompt(data), nil
+	}
+	prompt, err := wb.cfg.GetWorldBuilderStage5(data)
+	if err != nil {
+		return "", fmt.Errorf("渲染提示词失败: %w", err)
+	}
+	return prompt, nil
+}
+
 // buildHistoricalGeographyPrompt 构建历史世界地理提示词
 func (wb *WorldBuilder) buildHistoricalGeographyPrompt(data map[string]interface{}) string {
 	worldScale := data["WorldScale"].(string)
@@ -777,6 +978,7 @@ func (wb *WorldBuilder) buildHistoricalGeographyPrompt(data map[string]interface
 
 	prompt.WriteString("# 生成任务\n")
 	prompt.WriteString("基于以上信息生成历史世界的【地理环境】，请生成以下内容并以JSON格式返回：\n")
+	prompt.WriteString("position 为地区在示意图上的相对坐标（0-100，x向东、y向南），应符合真实方位；borders 列出相邻地区及按当时交通方式通行所需的小时数。\n")
 	prompt.WriteString(`{
   "geography": {
     "regions": [
@@ -786,7 +988,11 @@ func (wb *WorldBuilder) buildHistoricalGeographyPrompt(data map[string]interface
         "type": "urban/plain/river/lake/mountain/hill/forest",
         "description": "符合历史背景的详细描述",
         "resources": ["符合时代的资源1", "资源2"],
-        "risks": ["符合时代背景的风险1", "风险2"]
+        "risks": ["符合时代背景的风险1", "风险2"],
+        "position": {"x": 50, "y": 50},
+        "borders": [
+          {"region": "相邻地区名称", "type": "land/river/mountain/sea/wall", "travel_hours": 6}
+        ]
       }
     ],
     "resources": {
@@ -826,128 +1032,18 @@ func (wb *WorldBuilder) GenerateStage6(input Stage6Input) (*Stage6Result, string
 	systemPrompt := wb.cfg.GetWorldBuilderSystem()
 
 	// 调用LLM（带重试）
-	result, err := wb.callWithRetry(prompt, systemPrompt)
+	result, err := wb.callForRole(roleStage6, prompt, systemPrompt)
 	if err != nil {
 		return nil, "", err
 	}
 
 	// 解析输出
 	var output Stage6Output
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		// 尝试提取JSON
-		extracted := extractJSON(result)
-		if err := json.Unmarshal([]byte(extracted), &output); err != nil {
-			return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
-		}
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
 	}
 
-	// 构建文明对象
-	civilization := &models.Civilization{
-		Races:     make([]models.Race, len(output.Civilization.Races)),
-		Languages: make([]models.Language, len(output.Civilization.Languages)),
-		Religions: make([]models.Religion, len(output.Civilization.Religions)),
-	}
-
-	// 映射种族
-	for i, r := range output.Civilization.Races {
-		civilization.Races[i] = models.Race{
-			ID:          r.ID,
-			Name:        r.Name,
-			Description: r.Description,
-			Traits:      r.Traits,
-			Abilities:   r.Abilities,
-			Relations:   r.Relations,
-		}
-	}
-
-	// 映射语言
-	for i, l := range output.Civilization.Languages {
-		civilization.Languages[i] = models.Language{
-			ID:       l.ID,
-			Name:     l.Name,
-			Type:     l.Type,
-			Speakers: l.Speakers,
-			Features: l.Features,
-		}
-	}
-
-	// 映射宗教
-	for i, r := range output.Civilization.Religions {
-		civilization.Religions[i] = models.Religion{
-			ID:           r.ID,
-			Name:         r.Name,
-			Type:         r.Type,
-			Cosmology:    r.Cosmology,
-			Ethics:       r.Ethics,
-			Practices:    r.Practices,
-			Organization: r.Organization,
-		}
-	}
-
-	// 构建社会对象
-	powerStructure := &models.PowerStructure{
-		Formal:            make([]models.PowerLevel, len(output.Society.Politics.PowerStructure.Formal)),
-		Actual:            make([]models.PowerHolder, len(output.Society.Politics.PowerStructure.Actual)),
-		ChecksAndBalances: "",
-	}
-
-	// 映射权力层级
-	for i, p := range output.Society.Politics.PowerStructure.Formal {
-		powerStructure.Formal[i] = models.PowerLevel{
-			Level:  p.Level,
-			Name:   p.Name,
-			Powers: p.Powers,
-		}
-	}
-
-	// 映射实际掌权者
-	for i, p := range output.Society.Politics.PowerStructure.Actual {
-		powerStructure.Actual[i] = models.PowerHolder{
-			Entity:       p.Entity,
-			PowerSource:  p.PowerSource,
-			Relationship: p.Relationship,
-		}
-	}
-
-	society := &models.Society{
-		Politics: models.Politics{
-			Type:             output.Society.Politics.Type,
-			LegitimacySource: output.Society.Politics.LegitimacySource,
-			PowerStructure:   powerStructure,
-		},
-		Classes: make([]models.Class, len(output.Society.Classes)),
-		Economy: models.Economy{
-			Type:         output.Society.Economy.Type,
-			TradeNetwork: output.Society.Economy.TradeNetwork,
-			Currency:     output.Society.Economy.Currency,
-		},
-		Laws: make([]models.Law, len(output.Society.Laws)),
-	}
-
-	// 映射社会阶级
-	for i, c := range output.Society.Classes {
-		society.Classes[i] = models.Class{
-			Name:        c.Name,
-			Rank:        c.Rank,
-			Rights:      c.Rights,
-			Obligations: c.Obligations,
-		}
-	}
-
-	// 映射法律
-	for i, l := range output.Society.Laws {
-		society.Laws[i] = models.Law{
-			Name:        l.Name,
-			Type:        l.Type,
-			Description: l.Description,
-			Penalty:     "", // 阶段6不生成惩罚细节
-		}
-	}
-
-	return &Stage6Result{
-		Civilization: civilization,
-		Society:      society,
-	}, prompt, nil
+	return output.result(), prompt, nil
 }
 
 // GenerateStage6ForWorld 为已有世界生成/重新生成阶段6
@@ -963,7 +1059,18 @@ func (wb *WorldBuilder) GenerateStage6ForWorld(worldID string, input Stage6Input
 	}
 
 	// 更新社会
-	return wb.db.UpdateWorldStage(worldID, "society", result.Society)
+	if err := wb.db.UpdateWorldStage(worldID, "society", result.Society); err != nil {
+		return err
+	}
+
+	// 登记新出现的人名
+	world, err := wb.db.GetWorld(worldID)
+	if err != nil {
+		return err
+	}
+	registerWorldNames(world)
+	world.RebuildEntityIndex()
+	return wb.db.SaveWorld(world)
 }
 
 // GenerateStage7 生成阶段7：一致性检查
@@ -983,19 +1090,15 @@ func (wb *WorldBuilder) GenerateStage7(input Stage7Input) (*models.ConsistencyRe
 	systemPrompt := wb.cfg.GetWorldBuilderSystem()
 
 	// 调用LLM（带重试）
-	result, err := wb.callWithRetry(prompt, systemPrompt)
+	result, err := wb.callForRole(roleStage7, prompt, systemPrompt)
 	if err != nil {
 		return nil, "", err
 	}
 
 	// 解析输出
 	var output Stage7Output
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		// 尝试提取JSON
-		extracted := extractJSON(result)
-		if err := json.Unmarshal([]byte(extracted), &output); err != nil {
-			return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
-		}
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, "", fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
 	}
 
 	// 构建一致性报告
@@ -1070,15 +1173,17 @@ func (wb *WorldBuilder) buildWorldSummary(world *models.WorldSetting) string {
 	return summary
 }
 
-// callWithRetry 调用LLM并自动重试
-func (wb *WorldBuilder) callWithRetry(prompt, systemPrompt string) (string, error) {
-	retryConfig := wb.cfg.System.Retry
+// callForRole 调用LLM并自动重试，响应按提示词角色注册的 Schema 校验；
+// 修复后仍未通过校验时不再重试，直接返回错误
+func (wb *WorldBuilder) callForRole(role, prompt, systemPrompt string) (string, error) {
+	retryConfig := config.Get().System.Retry // 每次读取全局配置，热加载后立即生效
 	maxAttempts := retryConfig.MaxAttempts
 	var lastErr error
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
 		// 调用LLM
-		result, err := wb.client.GenerateJSONWithParams(
+		result, err := wb.client.GenerateJSONForRole(
+			role,
 			prompt,
 			systemPrompt,
 			wb.mapping.Temperature,
@@ -1094,6 +1199,10 @@ func (wb *WorldBuilder) callWithRetry(prompt, systemPrompt string) (string, erro
 			return string(jsonBytes), nil
 		}
 
+		var schemaErr *llm.SchemaError
+		if errors.As(err, &schemaErr) {
+			return "", err
+		}
 		lastErr = err
 
 		// 如果不是最后一次尝试，等待后重试
@@ -1109,76 +1218,6 @@ func (wb *WorldBuilder) callWithRetry(prompt, systemPrompt string) (string, erro
 	return "", fmt.Errorf("LLM调用失败（重试%d次后）: %w", maxAttempts, lastErr)
 }
 
-// extractJSON 从文本中提取JSON内容
-func extractJSON(s string) string {
-	// 查找 ```json```
-	start := -1
-	end := -1
-
-	jsonStart := []byte("```json")
-	if idx := indexOf(s, jsonStart); idx >= 0 {
-		start = idx + len(jsonStart)
-		if idx := indexOf(s[start:], []byte("```")); idx >= 0 {
-			end = start + idx
-			return s[start:end]
-		}
-	}
-
-	// 查找 ````
-	if idx := indexOf(s, []byte("```")); idx >= 0 {
-		start = idx + 3
-		if idx := indexOf(s[start:], []byte("```")); idx >= 0 {
-			end = start + idx
-			return s[start:end]
-		}
-	}
-
-	// 查找 { }
-	if idx := indexOf(s, []byte("{")); idx >= 0 {
-		start = idx
-		if idx := lastIndexOf(s, []byte("}")); idx >= 0 {
-			end = idx + 1
-			return s[start:end]
-		}
-	}
-
-	return s
-}
-
-// indexOf 查找字节切片位置
-func indexOf(s string, sep []byte) int {
-	for i := 0; i <= len(s)-len(sep); i++ {
-		match := true
-		for j := 0; j < len(sep); j++ {
-			if s[i+j] != sep[j] {
-				match = false
-				break
-			}
-		}
-		if match {
-			return i
-		}
-	}
-	return -1
-}
-
-// lastIndexOf 从后查找字节切片位置
-func lastIndexOf(s string, sep []byte) int {
-	for i := len(s) - len(sep); i >= 0; i-- {
-		match := true
-		for j := 0; j < len(sep); j++ {
-			if s[i+j] != sep[j] {
-				match = false
-				break
-			}
-		}
-		if match {
-			return i
-		}
-	}
-	return -1
-}
-
 func min(a, b int) int {
 	if a < b {
 		return a
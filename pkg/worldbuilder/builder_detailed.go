@@ -5,6 +5,7 @@ package worldbuilder
 import (
 	"encoding/json"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
@@ -12,6 +13,7 @@ import (
 	"github.com/xlei/xupu/pkg/config"
 	"github.com/xlei/xupu/pkg/db"
 	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/logx"
 )
 
 // DetailedBuilder 高信息熵世界构建器
@@ -20,11 +22,12 @@ type DetailedBuilder struct {
 	client *llm.Client
 	db     db.Database
 	mapping *config.ModuleMapping
+	logger  *slog.Logger
 }
 
 // NewDetailedBuilder 创建高信息熵构建器
 func NewDetailedBuilder() (*DetailedBuilder, error) {
-	cfg, err := config.Load("config/config.yaml")
+	cfg, err := config.Current()
 	if err != nil {
 		return nil, fmt.Errorf("加载配置失败: %w", err)
 	}
@@ -45,12 +48,13 @@ func NewDetailedBuilder() (*DetailedBuilder, error) {
 	}, nil
 }
 
+// log 当前使用的日志
+func (dbuilder *DetailedBuilder) log() *slog.Logger {
+	return logx.Or(dbuilder.logger)
+}
+
 // Build 构建完整世界（50-100轮LLM）
 func (dbuilder *DetailedBuilder) Build(params BuildParams) (*models.WorldSetting, error) {
-	fmt.Println("\n========================================")
-	fmt.Println("  🌍 高信息熵世界构建器")
-	fmt.Println("========================================\n")
-
 	startTime := time.Now()
 
 	// 创建世界设定对象
@@ -60,10 +64,12 @@ func (dbuilder *DetailedBuilder) Build(params BuildParams) (*models.WorldSetting
 		Type:  params.Type,
 		Scale: params.Scale,
 		Style: params.Style,
+
+		BuildTier: models.BuildTierDeep,
 	}
 
 	// 阶段1：哲学基础（3-5轮）
-	fmt.Println("📚 [阶段1/7] 哲学基础构建 (3-5轮LLM)...")
+	dbuilder.log().Info("世界构建阶段", "world_id", world.ID, "stage", 1, "name", "哲学基础", "tier", models.BuildTierDeep)
 	if err := dbuilder.buildStage1Detailed(world, params); err != nil {
 		return nil, fmt.Errorf("阶段1失败: %w", err)
 	}
@@ -72,7 +78,7 @@ func (dbuilder *DetailedBuilder) Build(params BuildParams) (*models.WorldSetting
 	}
 
 	// 阶段2：世界观（5-8轮）
-	fmt.Println("\n🌌 [阶段2/7] 世界观构建 (5-8轮LLM)...")
+	dbuilder.log().Info("世界构建阶段", "world_id", world.ID, "stage", 2, "name", "世界观", "tier", models.BuildTierDeep)
 	if err := dbuilder.buildStage2Detailed(world, params); err != nil {
 		return nil, fmt.Errorf("阶段2失败: %w", err)
 	}
@@ -81,7 +87,7 @@ func (dbuilder *DetailedBuilder) Build(params BuildParams) (*models.WorldSetting
 	}
 
 	// 阶段3：法则设定（8-12轮）
-	fmt.Println("\n⚡ [阶段3/7] 法则设定构建 (8-12轮LLM)...")
+	dbuilder.log().Info("世界构建阶段", "world_id", world.ID, "stage", 3, "name", "法则设定", "tier", models.BuildTierDeep)
 	if err := dbuilder.buildStage3Detailed(world, params); err != nil {
 		return nil, fmt.Errorf("阶段3失败: %w", err)
 	}
@@ -90,7 +96,7 @@ func (dbuilder *DetailedBuilder) Build(params BuildParams) (*models.WorldSetting
 	}
 
 	// 阶段4：故事土壤（10-15轮）
-	fmt.Println("\n🌱 [阶段4/7] 故事土壤构建 (10-15轮LLM)...")
+	dbuilder.log().Info("世界构建阶段", "world_id", world.ID, "stage", 4, "name", "故事土壤", "tier", models.BuildTierDeep)
 	if err := dbuilder.buildStage4Detailed(world, params); err != nil {
 		return nil, fmt.Errorf("阶段4失败: %w", err)
 	}
@@ -99,7 +105,7 @@ func (dbuilder *DetailedBuilder) Build(params BuildParams) (*models.WorldSetting
 	}
 
 	// 阶段5：地理环境（10-20轮）
-	fmt.Println("\n🗺️  [阶段5/7] 地理环境构建 (10-20轮LLM)...")
+	dbuilder.log().Info("世界构建阶段", "world_id", world.ID, "stage", 5, "name", "地理环境", "tier", models.BuildTierDeep)
 	if err := dbuilder.buildStage5Detailed(world, params); err != nil {
 		return nil, fmt.Errorf("阶段5失败: %w", err)
 	}
@@ -108,7 +114,7 @@ func (dbuilder *DetailedBuilder) Build(params BuildParams) (*models.WorldSetting
 	}
 
 	// 阶段6：文明社会（15-25轮）
-	fmt.Println("\n🏛️  [阶段6/7] 文明社会构建 (15-25轮LLM)...")
+	dbuilder.log().Info("世界构建阶段", "world_id", world.ID, "stage", 6, "name", "文明社会", "tier", models.BuildTierDeep)
 	if err := dbuilder.buildStage6Detailed(world, params); err != nil {
 		return nil, fmt.Errorf("阶段6失败: %w", err)
 	}
@@ -117,16 +123,17 @@ func (dbuilder *DetailedBuilder) Build(params BuildParams) (*models.WorldSetting
 	}
 
 	// 阶段7：历史与一致性（10-20轮）
-	fmt.Println("\n📜 [阶段7/7] 历史与一致性验证 (10-20轮LLM)...")
+	dbuilder.log().Info("世界构建阶段", "world_id", world.ID, "stage", 7, "name", "历史与一致性", "tier", models.BuildTierDeep)
 	if err := dbuilder.buildStage7Detailed(world, params); err != nil {
 		return nil, fmt.Errorf("阶段7失败: %w", err)
 	}
+	world.RebuildEntityIndex()
 	if err := dbuilder.db.SaveWorld(world); err != nil {
 		return nil, fmt.Errorf("保存阶段7失败: %w", err)
 	}
 
 	elapsed := time.Since(startTime)
-	fmt.Printf("\n✓ 世界构建完成！用时: %.1f秒\n", elapsed.Seconds())
+	dbuilder.log().Info("世界构建完成", "world_id", world.ID, "elapsed", elapsed.Round(time.Millisecond))
 
 	return world, nil
 }
@@ -136,37 +143,37 @@ func (dbuilder *DetailedBuilder) buildStage1Detailed(world *models.WorldSetting,
 	round := 0
 
 	// 第1轮：生成核心问题
-	fmt.Println("  ├─ [轮次1] 生成核心问题...")
+	dbuilder.log().Debug("生成核心问题")
 	coreQuestion, err := dbuilder.generateCoreQuestion(params)
 	if err != nil {
 		return err
 	}
 	world.Philosophy.CoreQuestion = coreQuestion
 	round++
-	fmt.Printf("    ✓ 核心问题: %s\n", coreQuestion)
+	dbuilder.log().Debug("核心问题", "value", coreQuestion)
 
 	// 第2轮：生成价值体系
-	fmt.Println("  ├─ [轮次2] 生成价值体系...")
+	dbuilder.log().Debug("生成价值体系")
 	valueSystem, err := dbuilder.generateValueSystem(coreQuestion, params)
 	if err != nil {
 		return err
 	}
 	world.Philosophy.ValueSystem = *valueSystem
 	round++
-	fmt.Printf("    ✓ 最高善: %s\n", valueSystem.HighestGood)
+	dbuilder.log().Debug("最高善", "value", valueSystem.HighestGood)
 
 	// 第3轮：生成主题列表
-	fmt.Println("  ├─ [轮次3] 生成主题列表...")
+	dbuilder.log().Debug("生成主题列表")
 	themes, err := dbuilder.generateThemes(coreQuestion, valueSystem, params)
 	if err != nil {
 		return err
 	}
 	world.Philosophy.Themes = themes
 	round++
-	fmt.Printf("    ✓ 主题数量: %d\n", len(themes))
+	dbuilder.log().Debug("主题数量", "value", len(themes))
 
 	// 第4-5轮：验证和优化
-	fmt.Println("  └─ [轮次4-5] 验证和优化...")
+	dbuilder.log().Debug("验证和优化")
 	derivation, err := dbuilder.validateAndRefinePhilosophy(world.Philosophy)
 	if err != nil {
 		return err
@@ -174,7 +181,7 @@ func (dbuilder *DetailedBuilder) buildStage1Detailed(world *models.WorldSetting,
 	world.Philosophy.Derivation = derivation
 	round++
 
-	fmt.Printf("  ✓ 阶段1完成 (共%d轮LLM)\n", round)
+	dbuilder.log().Debug("阶段1完成", "rounds", round)
 	return nil
 }
 
@@ -183,37 +190,37 @@ func (dbuilder *DetailedBuilder) buildStage2Detailed(world *models.WorldSetting,
 	round := 0
 
 	// 第1轮：生成宇宙起源
-	fmt.Println("  ├─ [轮次1] 生成宇宙起源...")
+	dbuilder.log().Debug("生成宇宙起源")
 	cosmology, err := dbuilder.generateCosmology(world.Philosophy, params)
 	if err != nil {
 		return err
 	}
 	world.Worldview.Cosmology = *cosmology
 	round++
-	fmt.Printf("    ✓ 起源: %s\n", cosmology.Origin[:50]+"...")
+	dbuilder.log().Debug("宇宙起源", "value", cosmology.Origin)
 
 	// 第2轮：生成宇宙结构
-	fmt.Println("  ├─ [轮次2] 生成宇宙结构...")
+	dbuilder.log().Debug("生成宇宙结构")
 	structure, err := dbuilder.generateCosmologyStructure(world.Philosophy, cosmology)
 	if err != nil {
 		return err
 	}
 	cosmology.Structure = structure
 	round++
-	fmt.Printf("    ✓ 结构层次: %d层\n", strings.Count(structure, "层"))
+	dbuilder.log().Debug("结构层次", "value", strings.Count(structure, "层"))
 
 	// 第3轮：生成形而上学
-	fmt.Println("  ├─ [轮次3] 生成形而上学...")
+	dbuilder.log().Debug("生成形而上学")
 	metaphysics, err := dbuilder.generateMetaphysics(world.Philosophy, cosmology)
 	if err != nil {
 		return err
 	}
 	world.Worldview.Metaphysics = *metaphysics
 	round++
-	fmt.Printf("    ✓ 灵魂观: %v\n", metaphysics.SoulExists)
+	dbuilder.log().Debug("灵魂观", "value", metaphysics.SoulExists)
 
 	// 第4-5轮：生成命运和来世观念
-	fmt.Println("  ├─ [轮次4-5] 生成命运和来世...")
+	dbuilder.log().Debug("生成命运和来世")
 	if metaphysics.FateExists {
 		fateRelation, err := dbuilder.generateFateRelation(world.Philosophy)
 		if err != nil {
@@ -231,7 +238,7 @@ func (dbuilder *DetailedBuilder) buildStage2Detailed(world *models.WorldSetting,
 	round += 2
 
 	// 第6-8轮：验证和生成推导逻辑
-	fmt.Println("  └─ [轮次6-8] 验证世界观一致性...")
+	dbuilder.log().Debug("验证世界观一致性")
 	derivation, err := dbuilder.validateAndRefineWorldview(world.Philosophy, world.Worldview)
 	if err != nil {
 		return err
@@ -239,7 +246,7 @@ func (dbuilder *DetailedBuilder) buildStage2Detailed(world *models.WorldSetting,
 	world.Worldview.Derivation = derivation
 	round++
 
-	fmt.Printf("  ✓ 阶段2完成 (共%d轮LLM)\n", round)
+	dbuilder.log().Debug("阶段2完成", "rounds", round)
 	return nil
 }
 
@@ -248,29 +255,29 @@ func (dbuilder *DetailedBuilder) buildStage3Detailed(world *models.WorldSetting,
 	round := 0
 
 	// 第1-3轮：生成物理法则
-	fmt.Println("  ├─ [轮次1-3] 生成物理法则...")
+	dbuilder.log().Debug("生成物理法则")
 	physics, err := dbuilder.generatePhysicsLaws(world.Worldview, params)
 	if err != nil {
 		return err
 	}
 	world.Laws.Physics = *physics
 	round += 3
-	fmt.Printf("    ✓ 物理法则: 重力、时间、能量、因果、死亡\n")
+	dbuilder.log().Debug("物理法则已生成")
 
 	// 第4-7轮：生成超自然体系（如果有）
 	if world.Laws.Supernatural != nil && world.Laws.Supernatural.Exists {
-		fmt.Println("  ├─ [轮次4-7] 生成超自然体系...")
+		dbuilder.log().Debug("生成超自然体系")
 		supernatural, err := dbuilder.generateSupernaturalSystem(world.Worldview, params)
 		if err != nil {
 			return err
 		}
 		world.Laws.Supernatural = supernatural
 		round += 4
-		fmt.Printf("    ✓ 超自然体系: %s\n", supernatural.Type)
+		dbuilder.log().Debug("超自然体系", "value", supernatural.Type)
 	}
 
 	// 第8-12轮：生成应用案例和验证
-	fmt.Println("  └─ [轮次8-12] 生成法则应用案例...")
+	dbuilder.log().Debug("生成法则应用案例")
 	applications, err := dbuilder.generateLawApplications(world.Laws, params)
 	if err != nil {
 		return err
@@ -279,7 +286,7 @@ func (dbuilder *DetailedBuilder) buildStage3Detailed(world *models.WorldSetting,
 	_ = applications
 	round += 4
 
-	fmt.Printf("  ✓ 阶段3完成 (共%d轮LLM)\n", round)
+	dbuilder.log().Debug("阶段3完成", "rounds", round)
 	return nil
 }
 
@@ -758,8 +765,8 @@ func (dbuilder *DetailedBuilder) generateSupernaturalSystem(worldview models.Wor
 	}
 
 	var result struct {
-		Type     string                 `json:"type"`
-		Settings map[string]interface{} `json:"settings"`
+		Type     string                       `json:"type"`
+		Settings *models.SupernaturalSettings `json:"settings"`
 	}
 	if err := json.Unmarshal([]byte(response), &result); err != nil {
 		return nil, err
@@ -768,7 +775,7 @@ func (dbuilder *DetailedBuilder) generateSupernaturalSystem(worldview models.Wor
 	return &models.Supernatural{
 		Exists:   true,
 		Type:     result.Type,
-		Settings: nil, // 简化：暂不处理复杂的settings
+		Settings: result.Settings,
 	}, nil
 }
 
@@ -781,17 +788,17 @@ func (dbuilder *DetailedBuilder) buildStage4Detailed(world *models.WorldSetting,
 	round := 0
 
 	// 第1-3轮：生成主要社会冲突
-	fmt.Println("  ├─ [轮次1-3] 生成主要社会冲突...")
+	dbuilder.log().Debug("生成主要社会冲突")
 	conflicts, err := dbuilder.generateSocialConflicts(world.Philosophy, world.Laws, params)
 	if err != nil {
 		return err
 	}
 	world.StorySoil.SocialConflicts = conflicts
 	round += 3
-	fmt.Printf("    ✓ 社会冲突数量: %d\n", len(conflicts))
+	dbuilder.log().Debug("社会冲突数量", "value", len(conflicts))
 
 	// 第4-6轮：为每个冲突生成背景和细节
-	fmt.Println("  ├─ [轮次4-6] 深化冲突背景...")
+	dbuilder.log().Debug("深化冲突背景")
 	for i, conflict := range conflicts {
 		details, err := dbuilder.generateConflictDetails(conflict, world.Philosophy)
 		if err != nil {
@@ -800,36 +807,36 @@ func (dbuilder *DetailedBuilder) buildStage4Detailed(world *models.WorldSetting,
 		conflicts[i] = details
 		round++
 	}
-	fmt.Println("    ✓ 所有冲突背景已深化")
+	dbuilder.log().Debug("所有冲突背景已深化")
 
 	// 第7-9轮：生成权力结构
-	fmt.Println("  ├─ [轮次7-9] 生成权力结构...")
+	dbuilder.log().Debug("生成权力结构")
 	powerStructures, err := dbuilder.generatePowerStructures(world.Philosophy, world.Laws, params)
 	if err != nil {
 		return err
 	}
 	world.StorySoil.PowerStructures = powerStructures
 	round += 3
-	fmt.Printf("    ✓ 权力结构层数: %d\n", len(powerStructures))
+	dbuilder.log().Debug("权力结构层数", "value", len(powerStructures))
 
 	// 第10-12轮：生成情节钩子
-	fmt.Println("  ├─ [轮次10-12] 生成情节钩子...")
+	dbuilder.log().Debug("生成情节钩子")
 	plotHooks, err := dbuilder.generatePlotHooks(world.Philosophy, world.StorySoil, params)
 	if err != nil {
 		return err
 	}
 	world.StorySoil.PotentialPlotHooks = plotHooks
 	round += 3
-	fmt.Printf("    ✓ 情节钩子数量: %d\n", len(plotHooks))
+	dbuilder.log().Debug("情节钩子数量", "value", len(plotHooks))
 
 	// 第13-15轮：验证故事土壤的一致性
-	fmt.Println("  └─ [轮次13-15] 验证故事土壤一致性...")
+	dbuilder.log().Debug("验证故事土壤一致性")
 	if err := dbuilder.validateStorySoil(world.StorySoil); err != nil {
 		return err
 	}
 	round += 3
 
-	fmt.Printf("  ✓ 阶段4完成 (共%d轮LLM)\n", round)
+	dbuilder.log().Debug("阶段4完成", "rounds", round)
 	return nil
 }
 
@@ -838,16 +845,16 @@ func (dbuilder *DetailedBuilder) buildStage5Detailed(world *models.WorldSetting,
 	round := 0
 
 	// 第1轮：规划地区数量和分布
-	fmt.Println("  ├─ [轮次1] 规划地区分布...")
+	dbuilder.log().Debug("规划地区分布")
 	regionPlan, err := dbuilder.planRegions(world, params)
 	if err != nil {
 		return err
 	}
 	round++
-	fmt.Printf("    ✓ 计划地区数量: %d\n", len(regionPlan))
+	dbuilder.log().Debug("计划地区数量", "value", len(regionPlan))
 
 	// 第2-N轮：为每个地区生成详细设定
-	fmt.Println("  ├─ [轮次2-N] 生成地区详细设定...")
+	dbuilder.log().Debug("生成地区详细设定")
 	regions := make([]models.Region, 0)
 	for i, plan := range regionPlan {
 		region, err := dbuilder.generateRegionDetail(plan, world, params)
@@ -856,38 +863,45 @@ func (dbuilder *DetailedBuilder) buildStage5Detailed(world *models.WorldSetting,
 		}
 		regions = append(regions, *region)
 		round++
-		fmt.Printf("    ✓ 地区 %d/%d: %s\n", i+1, len(regionPlan), region.Name)
+		dbuilder.log().Debug("地区设定完成", "index", i+1, "total", len(regionPlan), "name", region.Name)
 	}
 	world.Geography.Regions = regions
 
-	// 第N+1轮：生成气候系统
-	fmt.Println("  ├─ [轮次"+fmt.Sprint(round+1)+"] 生成气候系统...")
+	// 第N+1轮：生成地区方位与相邻关系
+	dbuilder.log().Debug("生成地区方位与相邻关系")
+	if err := dbuilder.generateRegionLayout(world.Geography.Regions, world); err != nil {
+		return err
+	}
+	round++
+
+	// 第N+2轮：生成气候系统
+	dbuilder.log().Debug("生成气候系统")
 	climate, err := dbuilder.generateClimateSystem(regions, world)
 	if err != nil {
 		return err
 	}
 	world.Geography.Climate = climate
 	round++
-	fmt.Printf("    ✓ 气候类型: %s\n", climate.Type)
+	dbuilder.log().Debug("气候类型", "value", climate.Type)
 
-	// 第N+2轮：生成资源分布
-	fmt.Println("  ├─ [轮次"+fmt.Sprint(round+1)+"] 生成资源分布...")
+	// 第N+3轮：生成资源分布
+	dbuilder.log().Debug("生成资源分布")
 	resources, err := dbuilder.generateResourceDistribution(regions, climate, world)
 	if err != nil {
 		return err
 	}
 	world.Geography.Resources = resources
 	round++
-	fmt.Printf("    ✓ 资源类别数: %d\n", len(resources.Basic))
+	dbuilder.log().Debug("资源类别数", "value", len(resources.Basic))
 
-	// 第N+3轮：验证地理一致性
-	fmt.Println("  └─ [轮次"+fmt.Sprint(round+1)+"] 验证地理一致性...")
+	// 第N+4轮：验证地理一致性
+	dbuilder.log().Debug("验证地理一致性")
 	if err := dbuilder.validateGeographyConsistency(world.Geography, world.Worldview); err != nil {
 		return err
 	}
 	round++
 
-	fmt.Printf("  ✓ 阶段5完成 (共%d轮LLM)\n", round)
+	dbuilder.log().Debug("阶段5完成", "rounds", round)
 	return nil
 }
 
@@ -896,16 +910,16 @@ func (dbuilder *DetailedBuilder) buildStage6Detailed(world *models.WorldSetting,
 	round := 0
 
 	// 第1轮：规划种族数量
-	fmt.Println("  ├─ [轮次1] 规划种族体系...")
+	dbuilder.log().Debug("规划种族体系")
 	racePlan, err := dbuilder.planRaces(world, params)
 	if err != nil {
 		return err
 	}
 	round++
-	fmt.Printf("    ✓ 计划种族数量: %d\n", len(racePlan))
+	dbuilder.log().Debug("计划种族数量", "value", len(racePlan))
 
 	// 第2-N轮：为每个种族生成详细设定
-	fmt.Println("  ├─ [轮次2-N] 生成种族详细设定...")
+	dbuilder.log().Debug("生成种族详细设定")
 	races := make([]models.Race, 0)
 	for i, plan := range racePlan {
 		race, err := dbuilder.generateRaceDetail(plan, world, params)
@@ -914,62 +928,76 @@ func (dbuilder *DetailedBuilder) buildStage6Detailed(world *models.WorldSetting,
 		}
 		races = append(races, *race)
 		round++
-		fmt.Printf("    ✓ 种族 %d/%d: %s\n", i+1, len(racePlan), race.Name)
+		dbuilder.log().Debug("种族设定完成", "index", i+1, "total", len(racePlan), "name", race.Name)
 	}
 	world.Civilization.Races = races
 
 	// 第N+1轮：生成种族关系
-	fmt.Println("  ├─ [轮次"+fmt.Sprint(round+1)+"] 生成种族关系网络...")
+	dbuilder.log().Debug("生成种族关系网络")
 	if err := dbuilder.generateRaceRelations(races, world); err != nil {
 		return err
 	}
 	round++
-	fmt.Println("    ✓ 种族关系网络已建立")
+	dbuilder.log().Debug("种族关系网络已建立")
 
 	// 第N+2-N+4轮：生成语言系统
-	fmt.Println("  ├─ [轮次"+fmt.Sprint(round+1)+"-"+fmt.Sprint(round+3)+"] 生成语言系统...")
+	dbuilder.log().Debug("生成语言系统")
 	languages, err := dbuilder.generateLanguageSystem(races, world)
 	if err != nil {
 		return err
 	}
 	world.Civilization.Languages = languages
 	round += 3
-	fmt.Printf("    ✓ 语言数量: %d\n", len(languages))
+	dbuilder.log().Debug("语言数量", "value", len(languages))
 
 	// 第N+5-N+7轮：生成宗教体系
-	fmt.Println("  ├─ [轮次"+fmt.Sprint(round+1)+"-"+fmt.Sprint(round+3)+"] 生成宗教体系...")
+	dbuilder.log().Debug("生成宗教体系")
 	religions, err := dbuilder.generateReligionSystem(races, world)
 	if err != nil {
 		return err
 	}
 	world.Civilization.Religions = religions
 	round += 3
-	fmt.Printf("    ✓ 宗教数量: %d\n", len(religions))
+	dbuilder.log().Debug("宗教数量", "value", len(religions))
+
+	// 宗教组织架构：神职层级、派系、圣地（每个宗教一轮）
+	if err := dbuilder.standard().organizeReligions(world, ""); err != nil {
+		return err
+	}
+	round += len(religions)
 
 	// 第N+8-N+10轮：生成政治结构
-	fmt.Println("  ├─ [轮次"+fmt.Sprint(round+1)+"-"+fmt.Sprint(round+3)+"] 生成政治结构...")
+	dbuilder.log().Debug("生成政治结构")
 	if err := dbuilder.generatePoliticalStructure(world); err != nil {
 		return err
 	}
 	round += 3
-	fmt.Println("    ✓ 政治结构已建立")
+	dbuilder.log().Debug("政治结构已建立")
+
+	// 经济模型：各地区资源稀缺程度与贸易路线
+	dbuilder.log().Debug("生成经济模型")
+	if err := dbuilder.generateEconomyModel(world); err != nil {
+		return err
+	}
+	round++
+	dbuilder.log().Debug("经济模型", "scarcity", len(world.Society.Economy.Scarcity), "routes", len(world.Society.Economy.TradeRoutes))
 
 	// 第N+11-N+13轮：生成社会阶层
-	fmt.Println("  ├─ [轮次"+fmt.Sprint(round+1)+"-"+fmt.Sprint(round+3)+"] 生成社会阶层...")
+	dbuilder.log().Debug("生成社会阶层")
 	if err := dbuilder.generateSocialClasses(world); err != nil {
 		return err
 	}
 	round += 3
-	fmt.Printf("    ✓ 社会阶层数量: %d\n", len(world.Society.Classes))
+	dbuilder.log().Debug("社会阶层数量", "value", len(world.Society.Classes))
 
 	// 第N+14-N+16轮：验证文明一致性
-	fmt.Println("  └─ [轮次"+fmt.Sprint(round+1)+"-"+fmt.Sprint(round+3)+"] 验证文明一致性...")
+	dbuilder.log().Debug("验证文明一致性")
 	if err := dbuilder.validateCivilizationConsistency(world); err != nil {
 		return err
 	}
 	round += 3
 
-	fmt.Printf("  ✓ 阶段6完成 (共%d轮LLM)\n", round)
+	dbuilder.log().Debug("阶段6完成", "rounds", round)
 	return nil
 }
 
@@ -978,16 +1006,16 @@ func (dbuilder *DetailedBuilder) buildStage7Detailed(world *models.WorldSetting,
 	round := 0
 
 	// 第1轮：规划时代划分
-	fmt.Println("  ├─ [轮次1] 规划时代划分...")
+	dbuilder.log().Debug("规划时代划分")
 	eras, err := dbuilder.planEras(world, params)
 	if err != nil {
 		return err
 	}
 	round++
-	fmt.Printf("    ✓ 计划时代数量: %d\n", len(eras))
+	dbuilder.log().Debug("计划时代数量", "value", len(eras))
 
 	// 第2-N轮：为每个时代生成重大事件
-	fmt.Println("  ├─ [轮次2-N] 生成时代重大事件...")
+	dbuilder.log().Debug("生成时代重大事件")
 	allEvents := make([]models.Event, 0)
 	for i, era := range eras {
 		events, err := dbuilder.generateEraEvents(era, world, params)
@@ -996,21 +1024,21 @@ func (dbuilder *DetailedBuilder) buildStage7Detailed(world *models.WorldSetting,
 		}
 		allEvents = append(allEvents, events...)
 		round++
-		fmt.Printf("    ✓ 时代 %d/%d: %s (%d个事件)\n", i+1, len(eras), era.Name, len(events))
+		dbuilder.log().Debug("时代事件完成", "index", i+1, "total", len(eras), "era", era.Name, "events", len(events))
 	}
 	world.History.Eras = eras
 	world.History.Events = allEvents
 
 	// 第N+1-N+3轮：验证历史因果关系
-	fmt.Println("  ├─ [轮次"+fmt.Sprint(round+1)+"-"+fmt.Sprint(round+3)+"] 验证历史因果关系...")
+	dbuilder.log().Debug("验证历史因果关系")
 	if err := dbuilder.validateHistoryCausality(world); err != nil {
 		return err
 	}
 	round += 3
-	fmt.Println("    ✓ 历史因果关系已验证")
+	dbuilder.log().Debug("历史因果关系已验证")
 
 	// 第N+4-N+8轮：最终一致性检查
-	fmt.Println("  └─ [轮次"+fmt.Sprint(round+1)+"-"+fmt.Sprint(round+5)+"] 最终一致性检查...")
+	dbuilder.log().Debug("最终一致性检查")
 	report, err := dbuilder.performFinalConsistencyCheck(world)
 	if err != nil {
 		return err
@@ -1018,16 +1046,9 @@ func (dbuilder *DetailedBuilder) buildStage7Detailed(world *models.WorldSetting,
 	world.ConsistencyReport = report
 	round += 5
 
-	fmt.Printf("  ✓ 阶段7完成 (共%d轮LLM)\n", round)
+	dbuilder.log().Debug("阶段7完成", "rounds", round)
 
-	// 输出一致性报告摘要
-	fmt.Printf("\n  📊 一致性报告摘要:\n")
-	fmt.Printf("     总体评分: %d/100\n", report.OverallScore)
-	if len(report.Issues) > 0 {
-		fmt.Printf("     发现问题: %d个\n", len(report.Issues))
-	} else {
-		fmt.Printf("     发现问题: 无\n")
-	}
+	dbuilder.log().Info("一致性检查完成", "world_id", world.ID, "score", report.OverallScore, "issues", len(report.Issues))
 
 	return nil
 }
@@ -1302,6 +1323,74 @@ func (dbuilder *DetailedBuilder) generateRegionDetail(plan RegionPlan, world *mo
 	}, nil
 }
 
+// generateRegionLayout 为已生成的地区补充示意图坐标和相邻关系（写回 regions）
+func (dbuilder *DetailedBuilder) generateRegionLayout(regions []models.Region, world *models.WorldSetting) error {
+	if len(regions) == 0 {
+		return nil
+	}
+
+	var list strings.Builder
+	for _, r := range regions {
+		desc := []rune(r.Description)
+		list.WriteString(fmt.Sprintf("- %s（%s）：%s\n", r.Name, r.Type, string(desc[:min(60, len(desc))])))
+	}
+
+	prompt := fmt.Sprintf(`基于已有地区，确定它们在地图上的相对位置和相邻关系：
+
+世界类型：%s
+世界规模：%s
+地区列表：
+%s
+⚠️ 重要要求：
+1. 为每个地区给出示意图坐标（0-100，x向东、y向南），相邻地区坐标应彼此接近
+2. 列出每个地区的相邻地区（只能使用上面的地区名称），相邻关系必须双向一致
+3. 标注边界类型（land/river/mountain/sea/wall）
+4. 给出常规通行所需小时数，需符合世界规模和交通方式
+
+请以JSON格式返回：
+{
+  "regions": [
+    {
+      "name": "地区名称",
+      "position": {"x": 30, "y": 40},
+      "borders": [
+        {"region": "相邻地区名称", "type": "mountain", "travel_hours": 24}
+      ]
+    }
+  ]
+}
+只返回JSON，不要包含其他内容。`,
+		world.Type, world.Scale, list.String())
+
+	systemPrompt := dbuilder.cfg.GetWorldBuilderSystem()
+	response, err := dbuilder.callWithRetry(prompt, systemPrompt)
+	if err != nil {
+		return err
+	}
+
+	var result struct {
+		Regions []struct {
+			Name     string                `json:"name"`
+			Position *models.MapPosition   `json:"position"`
+			Borders  []models.RegionBorder `json:"borders"`
+		} `json:"regions"`
+	}
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return err
+	}
+
+	geography := models.Geography{Regions: regions}
+	for _, layout := range result.Regions {
+		region := geography.RegionByName(layout.Name)
+		if region == nil {
+			continue
+		}
+		region.Position = layout.Position
+		region.Borders = layout.Borders
+	}
+	return nil
+}
+
 func (dbuilder *DetailedBuilder) generateClimateSystem(regions []models.Region, world *models.WorldSetting) (*models.Climate, error) {
 	prompt := fmt.Sprintf(`基于地区分布，生成统一的气候系统：
 
@@ -1749,6 +1838,77 @@ func (dbuilder *DetailedBuilder) generatePoliticalStructure(world *models.WorldS
 	return nil
 }
 
+// generateEconomyModel 生成各地区资源稀缺程度和贸易路线（写入 world.Society.Economy）
+func (dbuilder *DetailedBuilder) generateEconomyModel(world *models.WorldSetting) error {
+	if len(world.Geography.Regions) == 0 {
+		return nil
+	}
+
+	raceNames := make([]string, 0, len(world.Civilization.Races))
+	for _, race := range world.Civilization.Races {
+		raceNames = append(raceNames, race.Name)
+	}
+
+	prompt := fmt.Sprintf(`基于地理和文明设定，生成简易经济模型：
+
+世界类型：%s
+地理环境：%s
+种族：%s
+
+⚠️ 重要要求：
+1. 为每个区域的主要资源给出稀缺程度（0为充裕可外销，100为完全匮乏）
+2. 至少有两种资源在某些区域短缺、在另一些区域充裕
+3. 设计连接充裕区域与短缺区域的贸易路线，写明运输的货物
+4. 标出路线上可被争夺或封锁的咽喉要地及其控制者
+5. 区域名称只能使用上面列出的区域
+
+请以JSON格式返回：
+{
+  "type": "natural/commodity/capitalist/planned",
+  "trade_network": "贸易网络概述",
+  "currency": ["货币1"],
+  "scarcity": [
+    {"region": "区域名称", "resource": "资源名称", "level": 80, "note": "短缺原因"}
+  ],
+  "trade_routes": [
+    {
+      "name": "路线名称",
+      "from": "起点区域",
+      "to": "终点区域",
+      "goods": ["货物1"],
+      "chokepoints": [{"region": "区域名称", "controller": "控制者", "risk": "风险"}]
+    }
+  ]
+}
+只返回JSON，不要包含其他内容。`,
+		world.Type, GeographySummary(&world.Geography), strings.Join(raceNames, "、"))
+
+	systemPrompt := dbuilder.cfg.GetWorldBuilderSystem()
+	response, err := dbuilder.callWithRetry(prompt, systemPrompt)
+	if err != nil {
+		return err
+	}
+
+	var result models.Economy
+	if err := json.Unmarshal([]byte(response), &result); err != nil {
+		return err
+	}
+
+	economy := &world.Society.Economy
+	if result.Type != "" {
+		economy.Type = result.Type
+	}
+	if result.TradeNetwork != "" {
+		economy.TradeNetwork = result.TradeNetwork
+	}
+	if len(result.Currency) > 0 {
+		economy.Currency = result.Currency
+	}
+	economy.Scarcity = result.Scarcity
+	economy.TradeRoutes = result.TradeRoutes
+	return nil
+}
+
 func (dbuilder *DetailedBuilder) generateSocialClasses(world *models.WorldSetting) error {
 	prompt := fmt.Sprintf(`基于世界设定，生成社会阶层：
 
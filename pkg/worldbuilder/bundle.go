@@ -0,0 +1,134 @@
+// Package worldbuilder 世界设定导入导出
+// 将完整的世界设定及其关联角色打包为可移植的JSON，便于在不同部署之间迁移
+package worldbuilder
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// 世界包格式标识与版本
+const (
+	BundleFormat  = "xupu.world"
+	BundleVersion = 1
+)
+
+// WorldBundle 可移植的世界设定包
+type WorldBundle struct {
+	Format     string               `json:"format"`
+	Version    int                  `json:"version"`
+	ExportedAt time.Time            `json:"exported_at"`
+	World      *models.WorldSetting `json:"world"`
+	Characters []*models.Character  `json:"characters"`
+}
+
+// ImportResult 导入结果
+type ImportResult struct {
+	World *models.WorldSetting `json:"world"`
+	// IDMap 旧ID到新ID的映射（世界和角色）
+	IDMap map[string]string `json:"id_map"`
+}
+
+// ExportWorld 导出世界设定及其关联角色
+func ExportWorld(database db.Database, worldID string) (*WorldBundle, error) {
+	world, err := database.GetWorld(worldID)
+	if err != nil {
+		return nil, fmt.Errorf("世界不存在: %s", worldID)
+	}
+
+	characters := database.ListCharactersByWorld(worldID)
+	if characters == nil {
+		characters = []*models.Character{}
+	}
+
+	return &WorldBundle{
+		Format:     BundleFormat,
+		Version:    BundleVersion,
+		ExportedAt: time.Now(),
+		World:      world,
+		Characters: characters,
+	}, nil
+}
+
+// ParseBundle 解析并校验世界包
+func ParseBundle(data []byte) (*WorldBundle, error) {
+	var bundle WorldBundle
+	if err := json.Unmarshal(data, &bundle); err != nil {
+		return nil, fmt.Errorf("解析世界包失败: %w", err)
+	}
+	if bundle.Format != BundleFormat {
+		return nil, fmt.Errorf("不是有效的世界包: format=%q", bundle.Format)
+	}
+	if bundle.Version > BundleVersion {
+		return nil, fmt.Errorf("世界包版本 %d 高于当前支持的版本 %d", bundle.Version, BundleVersion)
+	}
+	if bundle.World == nil {
+		return nil, fmt.Errorf("世界包缺少世界设定")
+	}
+	return &bundle, nil
+}
+
+// ImportWorld 导入世界包
+// 世界和角色都会分配新ID，角色关系中的角色ID同步重映射，避免与目标库中已有数据冲突
+func ImportWorld(database db.Database, bundle *WorldBundle) (*ImportResult, error) {
+	now := time.Now()
+	idMap := make(map[string]string, len(bundle.Characters)+1)
+
+	world := bundle.World
+	newWorldID := db.GenerateID("world")
+	idMap[world.ID] = newWorldID
+	world.ID = newWorldID
+	world.CreatedAt = now
+	world.UpdatedAt = now
+	world.SectionVersions = nil
+	if len(world.Entities) == 0 {
+		world.RebuildEntityIndex()
+	}
+
+	for _, char := range bundle.Characters {
+		idMap[char.ID] = db.GenerateID("char")
+	}
+
+	if err := database.SaveWorld(world); err != nil {
+		return nil, fmt.Errorf("保存世界失败: %w", err)
+	}
+
+	for _, char := range bundle.Characters {
+		char.ID = idMap[char.ID]
+		char.WorldID = newWorldID
+		char.CreatedAt = now
+		char.UpdatedAt = now
+		remapRelationships(char, idMap)
+
+		if err := database.SaveCharacter(char); err != nil {
+			return nil, fmt.Errorf("保存角色 %s 失败: %w", char.Name, err)
+		}
+	}
+
+	return &ImportResult{World: world, IDMap: idMap}, nil
+}
+
+// remapRelationships 重映射角色关系中的角色ID，包外角色保持原样
+func remapRelationships(char *models.Character, idMap map[string]string) {
+	if len(char.NarrativeProfile.Relationships) == 0 {
+		return
+	}
+
+	remapped := make(map[string]*models.Relationship, len(char.NarrativeProfile.Relationships))
+	for key, rel := range char.NarrativeProfile.Relationships {
+		if newID, ok := idMap[key]; ok {
+			key = newID
+		}
+		if rel != nil {
+			if newID, ok := idMap[rel.CharacterID]; ok {
+				rel.CharacterID = newID
+			}
+		}
+		remapped[key] = rel
+	}
+	char.NarrativeProfile.Relationships = remapped
+}
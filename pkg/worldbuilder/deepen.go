@@ -0,0 +1,227 @@
+// Package worldbuilder 世界设定器 - 增量加深
+// 对已有世界只运行某一分节对应的高信息熵构建步骤，按名称合并结果，不改动其他分节
+package worldbuilder
+
+import (
+	"fmt"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// DeepenSection 可加深的世界分节
+type DeepenSection string
+
+const (
+	DeepenGeography    DeepenSection = "geography"    // 地理：地区、气候、资源（阶段5）
+	DeepenReligions    DeepenSection = "religions"    // 宗教体系及组织架构（阶段6）
+	DeepenHistory      DeepenSection = "history"      // 历史：时代与事件（阶段7，不含最终一致性检查）
+	DeepenSupernatural DeepenSection = "supernatural" // 超自然体系（阶段3）
+)
+
+// DeepenSections 所有可加深的分节
+var DeepenSections = []DeepenSection{DeepenGeography, DeepenReligions, DeepenHistory, DeepenSupernatural}
+
+// ParseDeepenSection 解析分节名称
+func ParseDeepenSection(s string) (DeepenSection, error) {
+	for _, section := range DeepenSections {
+		if string(section) == s {
+			return section, nil
+		}
+	}
+	return "", fmt.Errorf("不支持的分节: %s", s)
+}
+
+// Deepen 对已有世界加深指定分节并保存
+func (wb *WorldBuilder) Deepen(worldID string, section DeepenSection) (*models.WorldSetting, error) {
+	world, err := wb.db.GetWorld(worldID)
+	if err != nil {
+		return nil, fmt.Errorf("世界不存在: %w", err)
+	}
+
+	params := BuildParams{
+		Name:  world.Name,
+		Type:  world.Type,
+		Scale: world.Scale,
+		Style: world.Style,
+		Theme: world.Philosophy.CoreQuestion,
+	}
+	dbuilder := wb.detailed()
+	wb.log().Info("加深世界分节", "world_id", world.ID, "section", section, "tier", world.BuildTier)
+
+	switch section {
+	case DeepenGeography:
+		err = dbuilder.deepenGeography(world, params)
+	case DeepenReligions:
+		if err = dbuilder.deepenReligions(world); err == nil {
+			err = wb.organizeReligions(world, "")
+		}
+	case DeepenHistory:
+		err = dbuilder.deepenHistory(world, params)
+	case DeepenSupernatural:
+		err = dbuilder.deepenSupernatural(world, params)
+	default:
+		return nil, fmt.Errorf("不支持的分节: %s", section)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("加深%s失败: %w", section, err)
+	}
+
+	registerWorldNames(world)
+	world.RebuildEntityIndex()
+	if err := wb.db.SaveWorld(world); err != nil {
+		return nil, fmt.Errorf("保存世界失败: %w", err)
+	}
+	return world, nil
+}
+
+// deepenGeography 在副本上运行阶段5，再把地区按名称合并回世界
+func (dbuilder *DetailedBuilder) deepenGeography(world *models.WorldSetting, params BuildParams) error {
+	scratch := *world
+	scratch.Geography = models.Geography{}
+	if err := dbuilder.buildStage5Detailed(&scratch, params); err != nil {
+		return err
+	}
+
+	world.Geography.Regions = mergeRegions(world.Geography.Regions, scratch.Geography.Regions)
+	if scratch.Geography.Climate != nil {
+		world.Geography.Climate = scratch.Geography.Climate
+	}
+	if scratch.Geography.Resources != nil {
+		world.Geography.Resources = scratch.Geography.Resources
+	}
+	return nil
+}
+
+// deepenReligions 基于现有种族生成宗教体系并按名称合并
+func (dbuilder *DetailedBuilder) deepenReligions(world *models.WorldSetting) error {
+	religions, err := dbuilder.generateReligionSystem(world.Civilization.Races, world)
+	if err != nil {
+		return err
+	}
+	world.Civilization.Religions = mergeReligions(world.Civilization.Religions, religions)
+	return nil
+}
+
+// deepenHistory 生成时代与事件并按名称合并，不重跑最终一致性检查
+func (dbuilder *DetailedBuilder) deepenHistory(world *models.WorldSetting, params BuildParams) error {
+	eras, err := dbuilder.planEras(world, params)
+	if err != nil {
+		return err
+	}
+
+	events := make([]models.Event, 0)
+	for i, era := range eras {
+		eraEvents, err := dbuilder.generateEraEvents(era, world, params)
+		if err != nil {
+			return err
+		}
+		events = append(events, eraEvents...)
+		dbuilder.log().Debug("时代事件完成", "index", i+1, "total", len(eras), "era", era.Name, "events", len(eraEvents))
+	}
+
+	world.History.Eras = mergeEras(world.History.Eras, eras)
+	world.History.Events = mergeEvents(world.History.Events, events)
+	return dbuilder.validateHistoryCausality(world)
+}
+
+// deepenSupernatural 生成超自然体系；已有的子体系在新结果缺失时保留
+func (dbuilder *DetailedBuilder) deepenSupernatural(world *models.WorldSetting, params BuildParams) error {
+	supernatural, err := dbuilder.generateSupernaturalSystem(world.Worldview, params)
+	if err != nil {
+		return err
+	}
+
+	if old := world.Laws.Supernatural; old != nil && old.Settings != nil {
+		if supernatural.Settings == nil {
+			supernatural.Settings = &models.SupernaturalSettings{}
+		}
+		if supernatural.Settings.MagicSystem == nil {
+			supernatural.Settings.MagicSystem = old.Settings.MagicSystem
+		}
+		if supernatural.Settings.CultivationSystem == nil {
+			supernatural.Settings.CultivationSystem = old.Settings.CultivationSystem
+		}
+		if supernatural.Settings.SuperpowerSystem == nil {
+			supernatural.Settings.SuperpowerSystem = old.Settings.SuperpowerSystem
+		}
+	}
+	world.Laws.Supernatural = supernatural
+	return nil
+}
+
+// mergeRegions 同名地区用新设定替换（保留原ID），其余追加
+func mergeRegions(existing, generated []models.Region) []models.Region {
+	index := make(map[string]int, len(existing))
+	for i, r := range existing {
+		index[r.Name] = i
+	}
+	for _, r := range generated {
+		if i, ok := index[r.Name]; ok {
+			if existing[i].ID != "" {
+				r.ID = existing[i].ID
+			}
+			existing[i] = r
+			continue
+		}
+		index[r.Name] = len(existing)
+		existing = append(existing, r)
+	}
+	return existing
+}
+
+// mergeReligions 同名宗教用新设定替换（保留原ID），其余追加
+func mergeReligions(existing, generated []models.Religion) []models.Religion {
+	index := make(map[string]int, len(existing))
+	for i, r := range existing {
+		index[r.Name] = i
+	}
+	for _, r := range generated {
+		if i, ok := index[r.Name]; ok {
+			if existing[i].ID != "" {
+				r.ID = existing[i].ID
+			}
+			existing[i] = r
+			continue
+		}
+		index[r.Name] = len(existing)
+		existing = append(existing, r)
+	}
+	return existing
+}
+
+// mergeEras 同名时代用新设定替换，其余追加
+func mergeEras(existing, generated []models.Era) []models.Era {
+	index := make(map[string]int, len(existing))
+	for i, e := range existing {
+		index[e.Name] = i
+	}
+	for _, e := range generated {
+		if i, ok := index[e.Name]; ok {
+			existing[i] = e
+			continue
+		}
+		index[e.Name] = len(existing)
+		existing = append(existing, e)
+	}
+	return existing
+}
+
+// mergeEvents 同名事件用新设定替换（保留原ID），其余追加
+func mergeEvents(existing, generated []models.Event) []models.Event {
+	index := make(map[string]int, len(existing))
+	for i, e := range existing {
+		index[e.Name] = i
+	}
+	for _, e := range generated {
+		if i, ok := index[e.Name]; ok {
+			if existing[i].ID != "" {
+				e.ID = existing[i].ID
+			}
+			existing[i] = e
+			continue
+		}
+		index[e.Name] = len(existing)
+		existing = append(existing, e)
+	}
+	return existing
+}
@@ -0,0 +1,36 @@
+// Package worldbuilder 世界设定器 - 降级策略
+package worldbuilder
+
+import (
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+)
+
+// degradationModule 世界设定器在降级策略配置中的模块名
+const degradationModule = "world_builder"
+
+// degrade 按降级策略处理可跳过环节的失败
+// fail_fast 下原样返回错误；fallback_with_warning 下在世界设定上记录警告后继续；fallback_silent 下直接继续
+func (wb *WorldBuilder) degrade(world *models.WorldSetting, component string, cause error) error {
+	policy := config.DegradeFallbackWarning
+	if wb.cfg != nil {
+		policy = wb.cfg.Degradation.For(degradationModule)
+	}
+
+	switch policy {
+	case config.DegradeFailFast:
+		return cause
+	case config.DegradeFallbackSilent:
+	default:
+		wb.log().Warn("世界设定环节失败，跳过", "component", component, "error", cause)
+		world.Warnings = append(world.Warnings, models.DegradationWarning{
+			Module:    degradationModule,
+			Component: component,
+			Message:   cause.Error(),
+			CreatedAt: time.Now(),
+		})
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// Package worldbuilder 世界设定器 - 地理示意图
+// 把地区坐标和边界整理为前端可直接渲染的节点/连线结构
+package worldbuilder
+
+import (
+	"math"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// WorldMap 地理示意图
+type WorldMap struct {
+	WorldID string    `json:"world_id"`
+	Nodes   []MapNode `json:"nodes"`
+	Edges   []MapEdge `json:"edges"`
+}
+
+// MapNode 示意图上的地区
+type MapNode struct {
+	ID        string  `json:"id"`
+	Name      string  `json:"name"`
+	Type      string  `json:"type"`
+	X         float64 `json:"x"`
+	Y         float64 `json:"y"`
+	Estimated bool    `json:"estimated,omitempty"` // 地区没有坐标，位置为自动排布
+}
+
+// MapEdge 相邻地区之间的边界
+type MapEdge struct {
+	From        string  `json:"from"` // 地区ID
+	To          string  `json:"to"`
+	Type        string  `json:"type"`
+	TravelHours float64 `json:"travel_hours"`
+}
+
+// BuildMap 构建世界地理示意图，没有坐标的地区沿圆周自动排布
+func BuildMap(world *models.WorldSetting) *WorldMap {
+	regions := world.Geography.Regions
+	m := &WorldMap{
+		WorldID: world.ID,
+		Nodes:   make([]MapNode, 0, len(regions)),
+		Edges:   make([]MapEdge, 0),
+	}
+
+	ids := make(map[string]string, len(regions))
+	for i, r := range regions {
+		id := r.ID
+		if id == "" {
+			id = r.Name
+		}
+		ids[strings.TrimSpace(r.Name)] = id
+
+		node := MapNode{ID: id, Name: r.Name, Type: r.Type}
+		if r.Position != nil {
+			node.X, node.Y = r.Position.X, r.Position.Y
+		} else {
+			angle := 2 * math.Pi * float64(i) / float64(len(regions))
+			node.X = math.Round(50 + 40*math.Cos(angle))
+			node.Y = math.Round(50 + 40*math.Sin(angle))
+			node.Estimated = true
+		}
+		m.Nodes = append(m.Nodes, node)
+	}
+
+	// 边界按无序地区对去重，两边都标注时保留先出现的一条
+	seen := make(map[[2]string]bool)
+	for _, r := range regions {
+		from := ids[strings.TrimSpace(r.Name)]
+		for _, b := range r.Borders {
+			to, ok := ids[strings.TrimSpace(b.Region)]
+			if !ok || to == from {
+				continue
+			}
+			key := [2]string{from, to}
+			if to < from {
+				key = [2]string{to, from}
+			}
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			m.Edges = append(m.Edges, MapEdge{From: from, To: to, Type: b.Type, TravelHours: b.TravelHours})
+		}
+	}
+
+	return m
+}
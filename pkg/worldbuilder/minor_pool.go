@@ -0,0 +1,147 @@
+// Package worldbuilder 世界设定器 - 龙套角色池
+// 按地区生成有名有姓的龙套（一句话身份），场景需要路人时从池中选用，保证同一地点的路人前后一致
+package worldbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/naming"
+)
+
+// minorPoolTitle 龙套角色池提示词标题，同时作为模拟响应的标记
+const minorPoolTitle = "# 龙套角色池"
+
+// DefaultMinorsPerRegion 每个地区默认生成的龙套数量
+const DefaultMinorsPerRegion = 4
+
+// minorPoolOutput 龙套角色池生成输出
+type minorPoolOutput struct {
+	Regions []minorPoolRegion `json:"regions"`
+}
+
+// minorPoolRegion 单个地区的龙套
+type minorPoolRegion struct {
+	Region     string                  `json:"region"`
+	Characters []models.MinorCharacter `json:"characters"`
+}
+
+func init() {
+	llm.RegisterSchema(roleMinorPool, llm.SchemaOf(minorPoolOutput{}))
+	llm.RegisterMock(minorPoolTitle, minorPoolOutput{})
+}
+
+// GenerateMinorPool 为世界各地区生成龙套角色池并保存
+// 未升格的旧龙套被替换，已升格的保留；名字按世界命名规范校验并登记
+func (wb *WorldBuilder) GenerateMinorPool(worldID string, perRegion int) (*models.WorldSetting, error) {
+	world, err := wb.db.GetWorld(worldID)
+	if err != nil {
+		return nil, fmt.Errorf("世界不存在: %w", err)
+	}
+	if perRegion <= 0 {
+		perRegion = DefaultMinorsPerRegion
+	}
+
+	wb.log().Info("生成龙套角色池", "world_id", world.ID, "regions", len(world.Geography.Regions), "per_region", perRegion)
+	names := naming.NewGenerator(world)
+	result, err := wb.callForRole(roleMinorPool, buildMinorPoolPrompt(world, perRegion, names), wb.cfg.GetWorldBuilderSystem())
+	if err != nil {
+		return nil, err
+	}
+	var output minorPoolOutput
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
+	}
+
+	pool := make([]models.MinorCharacter, 0)
+	for _, m := range world.MinorCharacters {
+		if m.Promoted() {
+			pool = append(pool, m)
+		}
+	}
+	for _, region := range output.Regions {
+		regionName := region.Region
+		if r := world.Geography.RegionByName(regionName); r == nil {
+			regionName = ""
+			if r := world.Geography.RegionOf(region.Region); r != nil {
+				regionName = r.Name
+			}
+		}
+		for i, m := range region.Characters {
+			if i >= perRegion || strings.TrimSpace(m.Role) == "" {
+				continue
+			}
+			m.ID = db.GenerateID("minor")
+			m.Region = regionName
+			m.Name = names.Ensure(m.Name, m.Gender)
+			m.PromotedTo = ""
+			pool = append(pool, m)
+		}
+	}
+	world.MinorCharacters = pool
+
+	if err := wb.db.SaveWorld(world); err != nil {
+		return nil, fmt.Errorf("保存世界失败: %w", err)
+	}
+	return world, nil
+}
+
+// buildMinorPoolPrompt 构建龙套角色池提示词
+func buildMinorPoolPrompt(world *models.WorldSetting, perRegion int, names *naming.Generator) string {
+	var prompt strings.Builder
+	prompt.WriteString(minorPoolTitle + "\n\n")
+	prompt.WriteString(fmt.Sprintf("世界：%s（%s）\n", world.Name, world.Type))
+	if world.Style != "" {
+		prompt.WriteString(fmt.Sprintf("风格：%s\n", world.Style))
+	}
+	if era := currentEra(world); era != "" {
+		prompt.WriteString(fmt.Sprintf("当前时代：%s\n", era))
+	}
+
+	prompt.WriteString("\n## 地区\n")
+	if len(world.Geography.Regions) == 0 {
+		prompt.WriteString("（世界尚无地区设定，region 留空）\n")
+	}
+	for _, r := range world.Geography.Regions {
+		line := fmt.Sprintf("- %s（%s）", r.Name, r.Type)
+		if r.Description != "" {
+			line += "：" + r.Description
+		}
+		if len(r.Resources) > 0 {
+			line += "；物产：" + strings.Join(r.Resources, "、")
+		}
+		prompt.WriteString(line + "\n")
+	}
+	if len(world.Society.Conflicts) > 0 {
+		prompt.WriteString("\n## 社会冲突\n")
+		for _, c := range world.Society.Conflicts {
+			prompt.WriteString(fmt.Sprintf("- %s\n", c.Description))
+		}
+	}
+	prompt.WriteString(names.PromptSection())
+
+	prompt.WriteString(fmt.Sprintf(`
+## 要求
+1. 每个地区生成%d个龙套：商贩、差役、店家、信使、邻里等场景里常见的路人，身份要贴合该地区的地貌、物产和社会状况
+2. role 用一句话写清身份和处境；trait 写一个让读者记住的特征（口头禅、外貌、习惯）
+3. region 必须使用上面列出的地区名称
+4. 龙套之间不要重名，也不要与已被使用的名字重复
+
+请以JSON格式返回：
+{
+  "regions": [
+    {
+      "region": "地区名称",
+      "characters": [
+        {"name": "姓名", "gender": "男/女", "role": "一句话身份", "trait": "辨识特征"}
+      ]
+    }
+  ]
+}
+只返回JSON，不要包含其他内容。`, perRegion))
+	return prompt.String()
+}
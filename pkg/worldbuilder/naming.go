@@ -0,0 +1,29 @@
+// Package worldbuilder 世界构建器 - 人名登记
+package worldbuilder
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/naming"
+)
+
+// registerWorldNames 将世界设定中出现的人名（宗教领袖、龙套等）登记到人名登记
+// 后续生成角色时不会与这些人物重名
+func registerWorldNames(world *models.WorldSetting) {
+	names := naming.NewGenerator(world)
+	for _, m := range world.MinorCharacters {
+		names.Register(m.Name)
+	}
+	for _, religion := range world.Civilization.Religions {
+		if religion.Organization != nil {
+			names.Register(religion.Organization.Leader)
+			for _, rank := range religion.Organization.Hierarchy {
+				for _, holder := range rank.Holders {
+					names.Register(holder)
+				}
+			}
+			for _, faction := range religion.Organization.FactionDetails {
+				names.Register(faction.Leader)
+			}
+		}
+	}
+}
@@ -0,0 +1,199 @@
+// Package worldbuilder 世界设定器 - 角色立绘提示词
+// 按角色档案和所在世界的时代、风格生成结构化外貌描述，再拼出 Stable Diffusion / Midjourney 提示词
+package worldbuilder
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// portraitTitle 立绘提示词标题，同时作为模拟响应的标记
+const portraitTitle = "# 角色立绘设计"
+
+// portraitOutput 立绘生成输出
+type portraitOutput struct {
+	Appearance models.PortraitAppearance `json:"appearance"`
+	Tags       models.PortraitTags       `json:"tags"`
+	Era        string                    `json:"era"`
+	ArtStyle   string                    `json:"art_style"`
+}
+
+// portraitStyles 各世界类型的默认画风
+var portraitStyles = map[models.WorldType]string{
+	models.WorldFantasy:    "西方奇幻插画，厚涂，戏剧性光影",
+	models.WorldScifi:      "科幻概念设计，赛博质感，冷色霓虹光",
+	models.WorldHistorical: "历史写实风格，工笔与油画质感，自然光",
+	models.WorldUrban:      "现代都市写实插画，电影感光线",
+	models.WorldWuxia:      "国风武侠水墨插画，留白，写意",
+	models.WorldXianxia:    "国风仙侠插画，飘逸衣袂，仙气光晕",
+}
+
+func init() {
+	llm.RegisterSchema(rolePortrait, llm.SchemaOf(portraitOutput{}))
+	llm.RegisterMock(portraitTitle, portraitOutput{})
+}
+
+// GeneratePortrait 为角色生成立绘描述和提示词；角色关联世界时按世界的时代、风格、种族和地区设计外貌
+func (wb *WorldBuilder) GeneratePortrait(char *models.Character) (*models.CharacterPortrait, error) {
+	var world *models.WorldSetting
+	if char.WorldID != "" {
+		w, err := wb.db.GetWorld(char.WorldID)
+		if err != nil {
+			return nil, fmt.Errorf("世界不存在: %w", err)
+		}
+		world = w
+	}
+
+	wb.log().Info("生成角色立绘提示词", "character", char.Name, "world_id", char.WorldID)
+	prompt := buildPortraitPrompt(char, world)
+	result, err := wb.callForRole(rolePortrait, prompt, wb.cfg.GetWorldBuilderSystem())
+	if err != nil {
+		return nil, err
+	}
+	var output portraitOutput
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
+	}
+
+	portrait := &models.CharacterPortrait{
+		Appearance:  output.Appearance,
+		Tags:        output.Tags,
+		Era:         output.Era,
+		ArtStyle:    output.ArtStyle,
+		GeneratedAt: time.Now(),
+	}
+	if world != nil {
+		if portrait.Era == "" {
+			portrait.Era = currentEra(world)
+		}
+		if portrait.ArtStyle == "" {
+			portrait.ArtStyle = portraitStyle(world)
+		}
+	}
+	portrait.BuildPrompts()
+	return portrait, nil
+}
+
+// buildPortraitPrompt 构建立绘提示词
+func buildPortraitPrompt(char *models.Character, world *models.WorldSetting) string {
+	profile := char.StaticProfile
+	var prompt strings.Builder
+	prompt.WriteString(portraitTitle + "\n\n")
+
+	prompt.WriteString("## 角色\n")
+	prompt.WriteString(fmt.Sprintf("姓名：%s\n", char.Name))
+	if profile.Gender != "" || profile.Age > 0 {
+		prompt.WriteString(fmt.Sprintf("性别：%s，年龄：%d\n", profile.Gender, profile.Age))
+	}
+	if profile.Race != "" {
+		prompt.WriteString(fmt.Sprintf("种族：%s\n", profile.Race))
+	}
+	if profile.Occupation != "" || profile.SocialStatus != "" {
+		prompt.WriteString(fmt.Sprintf("身份：%s（%s）\n", profile.Occupation, profile.SocialStatus))
+	}
+	if profile.Appearance != "" {
+		prompt.WriteString(fmt.Sprintf("已有外貌设定：%s\n", profile.Appearance))
+	}
+	if profile.Background != "" {
+		prompt.WriteString(fmt.Sprintf("背景：%s\n", profile.Background))
+	}
+	if len(profile.Abilities) > 0 {
+		prompt.WriteString(fmt.Sprintf("能力：%s\n", strings.Join(profile.Abilities, "、")))
+	}
+	traits := make([]string, 0, len(char.NarrativeProfile.Personality))
+	for _, t := range char.NarrativeProfile.Personality {
+		traits = append(traits, t.Name)
+	}
+	if len(traits) > 0 {
+		prompt.WriteString(fmt.Sprintf("性格：%s\n", strings.Join(traits, "、")))
+	}
+
+	if world != nil {
+		prompt.WriteString("\n## 世界\n")
+		prompt.WriteString(fmt.Sprintf("世界：%s（%s）\n", world.Name, world.Type))
+		if world.Style != "" {
+			prompt.WriteString(fmt.Sprintf("风格：%s\n", world.Style))
+		}
+		if era := currentEra(world); era != "" {
+			prompt.WriteString(fmt.Sprintf("当前时代：%s\n", era))
+		}
+		if world.SettingConstraints.TechnologyLevel != "" {
+			prompt.WriteString(fmt.Sprintf("科技水平：%s\n", world.SettingConstraints.TechnologyLevel))
+		}
+		for _, race := range world.Civilization.Races {
+			if race.Name == profile.Race && race.Description != "" {
+				prompt.WriteString(fmt.Sprintf("种族特征：%s\n", race.Description))
+			}
+		}
+		if region := world.Geography.RegionOf(profile.Background); region != nil {
+			prompt.WriteString(fmt.Sprintf("所在地区：%s（%s）%s\n", region.Name, region.Type, region.Description))
+		}
+		prompt.WriteString(fmt.Sprintf("建议画风：%s\n", portraitStyle(world)))
+	}
+
+	prompt.WriteString(`
+## 要求
+1. appearance 用中文描述外貌，服饰、配饰必须符合世界的时代和科技水平，不要出现时代错位的物品
+2. tags 用英文关键词，供文生图模型使用：subject 写性别、年龄、五官、发型、体态；clothing 写服饰配饰；setting 写背景环境；style 写画风、光照、构图；negative 写需要避免的元素（如与时代不符的物品）
+3. 已有外貌设定的内容必须保留
+4. era 写角色所处时代，art_style 写画风（中文）
+
+请以JSON格式返回：
+{
+  "appearance": {
+    "summary": "一句话外貌概括",
+    "face": "脸型与五官",
+    "hair": "发型发色",
+    "eyes": "眼睛",
+    "build": "身材体态",
+    "skin": "肤色与特征",
+    "clothing": "服饰",
+    "accessories": ["配饰"],
+    "expression": "常见神态",
+    "palette": "主色调"
+  },
+  "tags": {
+    "subject": ["young man", "sharp eyes"],
+    "clothing": ["hanfu"],
+    "setting": ["misty mountains"],
+    "style": ["chinese ink painting"],
+    "negative": ["modern clothing"]
+  },
+  "era": "时代",
+  "art_style": "画风"
+}
+只返回JSON，不要包含其他内容。`)
+	return prompt.String()
+}
+
+// currentEra 世界的当前时代（历史中最后一个时代）
+func currentEra(world *models.WorldSetting) string {
+	eras := world.History.Eras
+	if len(eras) == 0 {
+		return ""
+	}
+	era := eras[len(eras)-1]
+	if era.Period != "" {
+		return fmt.Sprintf("%s（%s）", era.Name, era.Period)
+	}
+	return era.Name
+}
+
+// portraitStyle 世界风格倾向优先，否则按世界类型取默认画风
+func portraitStyle(world *models.WorldSetting) string {
+	if style, ok := portraitStyles[world.Type]; ok {
+		if world.Style != "" {
+			return world.Style + "；" + style
+		}
+		return style
+	}
+	if world.Style != "" {
+		return world.Style
+	}
+	return "写实插画"
+}
@@ -0,0 +1,227 @@
+// Package worldbuilder 世界设定器 - 宗教组织架构
+// 为已有宗教单独生成神职层级、带教义分歧的派系和绑定地区的圣地，为冲突设计提供反派素材
+package worldbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// religionOrgTitle 宗教组织提示词标题，同时作为模拟响应的标记
+const religionOrgTitle = "# 宗教组织架构"
+
+// religionOrgOutput 宗教组织生成输出
+type religionOrgOutput struct {
+	Organization models.ReligionOrganization `json:"organization"`
+}
+
+func init() {
+	llm.RegisterSchema(roleReligionOrg, llm.SchemaOf(religionOrgOutput{}))
+	llm.RegisterMock(religionOrgTitle, religionOrgOutput{})
+}
+
+// OrganizeReligions 为世界中的宗教生成组织架构并保存；religion 为空时处理全部宗教
+func (wb *WorldBuilder) OrganizeReligions(worldID, religion string) (*models.WorldSetting, error) {
+	world, err := wb.db.GetWorld(worldID)
+	if err != nil {
+		return nil, fmt.Errorf("世界不存在: %w", err)
+	}
+
+	found := religion == ""
+	for _, r := range world.Civilization.Religions {
+		if r.Name == religion || r.ID == religion {
+			found = true
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("宗教不存在: %s", religion)
+	}
+
+	if err := wb.organizeReligions(world, religion); err != nil {
+		return nil, err
+	}
+
+	registerWorldNames(world)
+	world.RebuildEntityIndex()
+	if err := wb.db.SaveWorld(world); err != nil {
+		return nil, fmt.Errorf("保存世界失败: %w", err)
+	}
+	return world, nil
+}
+
+// organizeReligions 逐个宗教生成组织架构（写回 world），单个宗教失败按降级策略处理
+func (wb *WorldBuilder) organizeReligions(world *models.WorldSetting, only string) error {
+	for i := range world.Civilization.Religions {
+		religion := &world.Civilization.Religions[i]
+		if only != "" && religion.Name != only && religion.ID != only {
+			continue
+		}
+
+		wb.log().Info("生成宗教组织架构", "world_id", world.ID, "religion", religion.Name)
+		org, err := wb.generateReligionOrganization(world, religion)
+		if err != nil {
+			if err := wb.degrade(world, "religion_organization", fmt.Errorf("%s: %w", religion.Name, err)); err != nil {
+				return err
+			}
+			continue
+		}
+		religion.Organization = org
+	}
+	return nil
+}
+
+// generateReligionOrganization 生成单个宗教的组织架构
+func (wb *WorldBuilder) generateReligionOrganization(world *models.WorldSetting, religion *models.Religion) (*models.ReligionOrganization, error) {
+	prompt := wb.buildReligionOrgPrompt(world, religion)
+
+	result, err := wb.callForRole(roleReligionOrg, prompt, wb.cfg.GetWorldBuilderSystem())
+	if err != nil {
+		return nil, err
+	}
+	var output religionOrgOutput
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		return nil, fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
+	}
+
+	org := output.Organization
+	normalizeReligionOrg(&org, religion.Organization, &world.Geography)
+	return &org, nil
+}
+
+// buildReligionOrgPrompt 构建宗教组织提示词
+func (wb *WorldBuilder) buildReligionOrgPrompt(world *models.WorldSetting, religion *models.Religion) string {
+	var prompt strings.Builder
+	prompt.WriteString(religionOrgTitle + "\n\n")
+	prompt.WriteString(fmt.Sprintf("世界：%s（%s）\n", world.Name, world.Type))
+	prompt.WriteString(fmt.Sprintf("核心问题：%s\n\n", world.Philosophy.CoreQuestion))
+
+	prompt.WriteString("## 宗教\n")
+	prompt.WriteString(fmt.Sprintf("名称：%s（%s）\n", religion.Name, religion.Type))
+	if religion.Cosmology != "" {
+		prompt.WriteString(fmt.Sprintf("宇宙观：%s\n", religion.Cosmology))
+	}
+	if len(religion.Ethics) > 0 {
+		prompt.WriteString(fmt.Sprintf("伦理：%s\n", strings.Join(religion.Ethics, "；")))
+	}
+	if len(religion.Practices) > 0 {
+		prompt.WriteString(fmt.Sprintf("仪式：%s\n", strings.Join(religion.Practices, "；")))
+	}
+	if org := religion.Organization; org != nil {
+		prompt.WriteString(fmt.Sprintf("现有组织：%s，领袖：%s", org.Type, org.Leader))
+		if len(org.Factions) > 0 {
+			prompt.WriteString(fmt.Sprintf("，派系：%s", strings.Join(org.Factions, "、")))
+		}
+		prompt.WriteString("（请在此基础上扩展，保留已有名称）\n")
+	}
+
+	others := make([]string, 0)
+	for _, r := range world.Civilization.Religions {
+		if r.Name != religion.Name {
+			others = append(others, r.Name)
+		}
+	}
+	if len(others) > 0 {
+		prompt.WriteString(fmt.Sprintf("其他宗教：%s\n", strings.Join(others, "、")))
+	}
+
+	if len(world.Geography.Regions) > 0 {
+		names := make([]string, 0, len(world.Geography.Regions))
+		for _, r := range world.Geography.Regions {
+			names = append(names, r.Name)
+		}
+		prompt.WriteString(fmt.Sprintf("\n## 可用地区\n%s\n", strings.Join(names, "、")))
+	}
+	if len(world.Society.Conflicts) > 0 {
+		prompt.WriteString("\n## 社会冲突\n")
+		for _, c := range world.Society.Conflicts {
+			prompt.WriteString(fmt.Sprintf("- %s\n", c.Description))
+		}
+	}
+
+	prompt.WriteString(`
+## 要求
+1. hierarchy：3-6级神职层级，从最高（level=1）到最低，关键职位给出有名有姓的在任者
+2. faction_details：2-4个派系，每个派系有领袖、核心主张，以及与正统或其他派系的具体教义分歧（dispute）和图谋（ambition），至少一个派系的图谋足以成为故事反派的动机
+3. holy_sites：1-3处圣地，region 必须使用上面列出的地区名称，标出控制者，被争夺的圣地 contested 为 true
+4. leader 为最高神职的在任者，factions 为派系名称列表
+
+请以JSON格式返回：
+{
+  "organization": {
+    "type": "hierarchy/decentralized/cult",
+    "leader": "领袖姓名",
+    "factions": ["派系名称"],
+    "hierarchy": [
+      {"level": 1, "title": "职衔", "holders": ["姓名"], "duties": "职责", "reports_to": ""}
+    ],
+    "faction_details": [
+      {"name": "派系名称", "leader": "姓名", "doctrine": "核心主张", "dispute": "教义分歧", "rival": "对立方", "strength": 60, "ambition": "图谋"}
+    ],
+    "holy_sites": [
+      {"name": "圣地名称", "region": "地区名称", "significance": "意义", "controlled_by": "派系名称", "contested": true}
+    ]
+  }
+}
+只返回JSON，不要包含其他内容。`)
+	return prompt.String()
+}
+
+// normalizeReligionOrg 补齐派系名称和领袖，沿用已有组织中的名称，圣地地区对齐到世界地理
+func normalizeReligionOrg(org *models.ReligionOrganization, previous *models.ReligionOrganization, geography *models.Geography) {
+	if previous != nil {
+		if org.Type == "" {
+			org.Type = previous.Type
+		}
+		if org.Leader == "" {
+			org.Leader = previous.Leader
+		}
+	}
+	if org.Leader == "" {
+		for _, rank := range org.Hierarchy {
+			if len(rank.Holders) > 0 {
+				org.Leader = rank.Holders[0]
+				break
+			}
+		}
+	}
+
+	factions := make([]string, 0, len(org.FactionDetails))
+	if previous != nil {
+		factions = append(factions, previous.Factions...)
+	}
+	for _, f := range org.Factions {
+		factions = appendName(factions, f)
+	}
+	for _, f := range org.FactionDetails {
+		factions = appendName(factions, f.Name)
+	}
+	org.Factions = factions
+
+	for i := range org.HolySites {
+		site := &org.HolySites[i]
+		if geography.RegionByName(site.Region) != nil {
+			continue
+		}
+		if region := geography.RegionOf(site.Region); region != nil {
+			site.Region = region.Name
+		}
+	}
+}
+
+// appendName 追加非空且不重复的名称
+func appendName(names []string, name string) []string {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return names
+	}
+	for _, n := range names {
+		if n == name {
+			return names
+		}
+	}
+	return append(names, name)
+}
@@ -0,0 +1,38 @@
+// Package worldbuilder 世界构建器 - 响应结构校验
+// 七个构建阶段及快速档位组合提示词的 JSON Schema，由LLM客户端校验响应，不通过时自动请求修复
+package worldbuilder
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// 提示词角色
+const (
+	roleStage1 = "worldbuilder.stage1_philosophy"
+	roleStage2 = "worldbuilder.stage2_worldview"
+	roleStage3 = "worldbuilder.stage3_laws"
+	roleStage4 = "worldbuilder.stage4_story_soil"
+	roleStage5 = "worldbuilder.stage5_geography"
+	roleStage6 = "worldbuilder.stage6_civilization"
+	roleStage7 = "worldbuilder.stage7_consistency"
+
+	roleQuickFoundation = "worldbuilder.quick_foundation"
+	roleQuickSetting    = "worldbuilder.quick_setting"
+
+	roleReligionOrg = "worldbuilder.religion_organization"
+	rolePortrait    = "worldbuilder.character_portrait"
+	roleMinorPool   = "worldbuilder.minor_pool"
+)
+
+func init() {
+	llm.RegisterSchema(roleStage1, llm.SchemaOf(Stage1Output{}))
+	llm.RegisterSchema(roleStage2, llm.SchemaOf(Stage2Output{}))
+	llm.RegisterSchema(roleStage3, llm.SchemaOf(Stage3Output{}))
+	llm.RegisterSchema(roleStage4, llm.SchemaOf(models.StorySoil{}))
+	llm.RegisterSchema(roleStage5, llm.SchemaOf(Stage5Output{}))
+	llm.RegisterSchema(roleStage6, llm.SchemaOf(Stage6Output{}))
+	llm.RegisterSchema(roleStage7, llm.SchemaOf(Stage7Output{}))
+	llm.RegisterSchema(roleQuickFoundation, llm.SchemaOf(quickFoundationOutput{}))
+	llm.RegisterSchema(roleQuickSetting, llm.SchemaOf(quickSettingOutput{}))
+}
@@ -0,0 +1,88 @@
+// Package worldbuilder 单阶段重跑
+// 以已有世界为输入重跑某个构建阶段而不写库，用于本地调试提示词时比较输出
+package worldbuilder
+
+import (
+	"fmt"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// StageCount 标准构建的阶段数
+const StageCount = 7
+
+// StagePromptKeys 各阶段使用的提示词键（同 config.GetAllPrompts），下标为阶段号减一
+var StagePromptKeys = [StageCount]string{
+	"world_builder.stage1_philosophy",
+	"world_builder.stage2_worldview",
+	"world_builder.stage3_laws",
+	"world_builder.stage4_story_soil",
+	"world_builder.stage5_geography",
+	"world_builder.stage6_civilization",
+	"world_builder.stage7_consistency",
+}
+
+// SystemPromptKey 各阶段共用的系统提示词键
+const SystemPromptKey = "world_builder.system"
+
+// RunStage 以世界已有的前序设定为输入重跑指定阶段，返回阶段输出和渲染后的提示词，不修改世界也不写库
+// 各阶段输入按标准构建的方式从世界设定中提取；阶段1的主题不在世界设定中，由 theme 指定
+func (wb *WorldBuilder) RunStage(stage int, world *models.WorldSetting, theme string) (interface{}, string, error) {
+	var (
+		output interface{}
+		prompt string
+		err    error
+	)
+	switch stage {
+	case 1:
+		output, prompt, err = wb.GenerateStage1(Stage1Input{
+			WorldType: string(world.Type),
+			Theme:     theme,
+			Style:     world.Style,
+		})
+	case 2:
+		output, prompt, err = wb.GenerateStage2(Stage2Input{
+			CoreQuestion: world.Philosophy.CoreQuestion,
+			HighestGood:  world.Philosophy.ValueSystem.HighestGood,
+			UltimateEvil: world.Philosophy.ValueSystem.UltimateEvil,
+		})
+	case 3:
+		output, prompt, err = wb.GenerateStage3(Stage3Input{
+			WorldType: string(world.Type),
+			Worldview: fmt.Sprintf("起源:%s 结构:%s", world.Worldview.Cosmology.Origin, world.Worldview.Cosmology.Structure),
+		})
+	case 4:
+		mainConflicts := ""
+		if len(world.Philosophy.ValueSystem.MoralDilemmas) > 0 {
+			mainConflicts = world.Philosophy.ValueSystem.MoralDilemmas[0].Dilemma
+		}
+		output, prompt, err = wb.GenerateStage4(Stage4Input{
+			CoreQuestion:  world.Philosophy.CoreQuestion,
+			MainConflicts: mainConflicts,
+			WorldType:     string(world.Type),
+		})
+	case 5:
+		output, prompt, err = wb.GenerateStage5(Stage5Input{
+			WorldType:         string(world.Type),
+			WorldScale:        string(world.Scale),
+			LawsSummary:       fmt.Sprintf("物理:%s 超自然:%v", world.Laws.Physics.Gravity, world.Laws.Supernatural != nil && world.Laws.Supernatural.Exists),
+			CivilizationNeeds: fmt.Sprintf("资源需求基于%s类型的世界", world.Type),
+		})
+	case 6:
+		output, prompt, err = wb.GenerateStage6(Stage6Input{
+			WorldType:        string(world.Type),
+			GeographySummary: GeographySummary(&world.Geography),
+			ValueSystem:      fmt.Sprintf("最高善:%s", world.Philosophy.ValueSystem.HighestGood),
+		})
+	case 7:
+		output, prompt, err = wb.GenerateStage7(Stage7Input{
+			WorldSettingSummary: wb.buildWorldSummary(world),
+		})
+	default:
+		return nil, "", fmt.Errorf("无效的阶段: %d（应为 1-%d）", stage, StageCount)
+	}
+	if err != nil {
+		return nil, prompt, err
+	}
+	return output, prompt, nil
+}
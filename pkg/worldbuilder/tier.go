@@ -0,0 +1,233 @@
+// Package worldbuilder 世界设定器 - 构建档位
+// quick 档位把7个阶段合并为两次组合提示词（基础设定、环境与文明），跳过一致性检查，适合原型阶段；
+// 生成的世界记录档位，之后可按分节加深
+package worldbuilder
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// 组合提示词的标题，同时作为模拟响应的标记
+const (
+	quickFoundationTitle = "# 快速世界构建：基础设定"
+	quickSettingTitle    = "# 快速世界构建：环境与文明"
+)
+
+// sameCall 组合提示词中依赖同一次调用前序部分的占位
+const sameCall = "（与本次同时生成的前序设定保持一致）"
+
+// quickFoundationOutput 快速档位第一次调用的输出：哲学、世界观、法则
+type quickFoundationOutput struct {
+	Philosophy Stage1Output `json:"philosophy"`
+	Worldview  Stage2Output `json:"worldview"`
+	Laws       Stage3Output `json:"laws"`
+}
+
+// quickSettingOutput 快速档位第二次调用的输出：故事土壤、地理（geography）、文明与社会（civilization、society）
+type quickSettingOutput struct {
+	StorySoil models.StorySoil `json:"story_soil"`
+	Stage5Output
+	Stage6Output
+}
+
+func init() {
+	llm.RegisterMock(quickFoundationTitle, quickFoundationOutput{})
+	llm.RegisterMock(quickSettingTitle, quickSettingOutput{})
+}
+
+// detailed 共用配置、LLM客户端和日志的高信息熵构建器（deep 档位）
+func (wb *WorldBuilder) detailed() *DetailedBuilder {
+	return &DetailedBuilder{
+		cfg:     wb.cfg,
+		client:  wb.client,
+		db:      wb.db,
+		mapping: wb.mapping,
+		logger:  wb.logger,
+	}
+}
+
+// standard 共用配置、LLM客户端和日志的标准构建器（使用按角色校验的单次调用环节）
+func (dbuilder *DetailedBuilder) standard() *WorldBuilder {
+	return &WorldBuilder{
+		cfg:     dbuilder.cfg,
+		client:  dbuilder.client,
+		db:      dbuilder.db,
+		mapping: dbuilder.mapping,
+		logger:  dbuilder.logger,
+	}
+}
+
+// buildQuick 快速构建：两次组合调用生成阶段1-6，不做一致性检查
+func (wb *WorldBuilder) buildQuick(params BuildParams) (*models.WorldSetting, error) {
+	world := &models.WorldSetting{
+		ID:    db.GenerateID("world"),
+		Name:  params.Name,
+		Type:  params.Type,
+		Scale: params.Scale,
+		Style: params.Style,
+
+		BuildTier: models.BuildTierQuick,
+	}
+
+	wb.log().Info("世界构建阶段", "world_id", world.ID, "stage", "1-3", "name", "基础设定", "tier", models.BuildTierQuick)
+	foundation, err := wb.generateQuickFoundation(params)
+	if err != nil {
+		return nil, fmt.Errorf("基础设定失败: %w", err)
+	}
+	world.Philosophy = *foundation.Philosophy.philosophy()
+	world.Worldview = *foundation.Worldview.worldview()
+	world.Laws = *foundation.Laws.laws()
+	if err := wb.db.SaveWorld(world); err != nil {
+		return nil, fmt.Errorf("保存基础设定失败: %w", err)
+	}
+
+	wb.log().Info("世界构建阶段", "world_id", world.ID, "stage", "4-6", "name", "环境与文明", "tier", models.BuildTierQuick)
+	setting, err := wb.generateQuickSetting(params, world)
+	if err != nil {
+		return nil, fmt.Errorf("环境与文明设定失败: %w", err)
+	}
+	world.StorySoil = setting.StorySoil
+	world.Geography = *setting.Stage5Output.geography()
+	civ := setting.Stage6Output.result()
+	world.Civilization = *civ.Civilization
+	world.Society = *civ.Society
+	registerWorldNames(world)
+	world.RebuildEntityIndex()
+	if err := wb.db.SaveWorld(world); err != nil {
+		return nil, fmt.Errorf("保存环境与文明设定失败: %w", err)
+	}
+
+	return world, nil
+}
+
+// generateQuickFoundation 一次调用生成哲学基础、世界观和法则设定
+func (wb *WorldBuilder) generateQuickFoundation(params BuildParams) (*quickFoundationOutput, error) {
+	stage1, err := wb.cfg.GetWorldBuilderStage1(map[string]interface{}{
+		"WorldType": string(params.Type),
+		"Theme":     params.Theme,
+		"Style":     params.Style,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("渲染提示词失败: %w", err)
+	}
+	stage2, err := wb.cfg.GetWorldBuilderStage2(map[string]interface{}{
+		"CoreQuestion": sameCall,
+		"HighestGood":  sameCall,
+		"UltimateEvil": sameCall,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("渲染提示词失败: %w", err)
+	}
+	stage3, err := wb.cfg.GetWorldBuilderStage3(map[string]interface{}{
+		"WorldType": string(params.Type),
+		"Worldview": sameCall,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("渲染提示词失败: %w", err)
+	}
+
+	prompt := combinePrompts(quickFoundationTitle, []combinedPart{
+		{key: "philosophy", title: "哲学基础", prompt: stage1},
+		{key: "worldview", title: "世界观", prompt: stage2},
+		{key: "laws", title: "法则设定", prompt: stage3},
+	})
+
+	var output quickFoundationOutput
+	if err := wb.callQuick(roleQuickFoundation, prompt, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// generateQuickSetting 一次调用生成故事土壤、地理环境和文明社会
+func (wb *WorldBuilder) generateQuickSetting(params BuildParams, world *models.WorldSetting) (*quickSettingOutput, error) {
+	mainConflicts := ""
+	if len(world.Philosophy.ValueSystem.MoralDilemmas) > 0 {
+		mainConflicts = world.Philosophy.ValueSystem.MoralDilemmas[0].Dilemma
+	}
+	stage4, err := wb.cfg.GetWorldBuilderStage4(map[string]interface{}{
+		"CoreQuestion":  world.Philosophy.CoreQuestion,
+		"MainConflicts": mainConflicts,
+		"WorldType":     string(params.Type),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("渲染提示词失败: %w", err)
+	}
+	stage5, err := wb.stage5Prompt(map[string]interface{}{
+		"WorldType":         string(params.Type),
+		"WorldScale":        string(params.Scale),
+		"LawsSummary":       fmt.Sprintf("物理:%s 超自然:%v", world.Laws.Physics.Gravity, world.Laws.Supernatural != nil && world.Laws.Supernatural.Exists),
+		"CivilizationNeeds": fmt.Sprintf("资源需求基于%s类型的世界", params.Type),
+	})
+	if err != nil {
+		return nil, err
+	}
+	stage6, err := wb.cfg.GetWorldBuilderStage6(map[string]interface{}{
+		"WorldType":        string(params.Type),
+		"GeographySummary": sameCall,
+		"ValueSystem":      fmt.Sprintf("最高善:%s", world.Philosophy.ValueSystem.HighestGood),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("渲染提示词失败: %w", err)
+	}
+
+	prompt := combinePrompts(quickSettingTitle, []combinedPart{
+		{key: "story_soil", title: "故事土壤", prompt: stage4},
+		{key: "geography", title: "地理环境", prompt: stage5, unwrapped: true},
+		{key: "civilization、society", title: "文明社会", prompt: stage6, unwrapped: true},
+	})
+
+	var output quickSettingOutput
+	if err := wb.callQuick(roleQuickSetting, prompt, &output); err != nil {
+		return nil, err
+	}
+	return &output, nil
+}
+
+// callQuick 调用组合提示词并解析为 v
+func (wb *WorldBuilder) callQuick(role, prompt string, v interface{}) error {
+	result, err := wb.callForRole(role, prompt, wb.cfg.GetWorldBuilderSystem())
+	if err != nil {
+		return err
+	}
+	if err := jsonx.Unmarshal(result, v); err != nil {
+		return fmt.Errorf("解析LLM输出失败: %w, 原始内容: %s", err, result[:min(500, len(result))])
+	}
+	return nil
+}
+
+// combinedPart 组合提示词中的一个阶段
+type combinedPart struct {
+	key       string // 合并JSON中的字段名
+	title     string
+	prompt    string // 该阶段原本的提示词（含输出格式）
+	unwrapped bool   // 该阶段的输出本身已带顶层字段，直接并入合并JSON
+}
+
+// combinePrompts 把多个阶段的提示词合并为一次调用，要求返回一个合并的JSON对象
+func combinePrompts(title string, parts []combinedPart) string {
+	var sb strings.Builder
+	sb.WriteString(title + "\n\n")
+	sb.WriteString("请在一次回复中依次完成以下各部分设定，后面的部分必须建立在前面部分的结果之上。\n")
+	sb.WriteString("各部分的具体要求和输出格式见下文；请把所有部分合并为一个JSON对象返回：\n")
+	for _, p := range parts {
+		if p.unwrapped {
+			sb.WriteString(fmt.Sprintf("- %s：该部分输出中的顶层字段（%s）直接放在合并对象的顶层\n", p.title, p.key))
+		} else {
+			sb.WriteString(fmt.Sprintf("- %s：放在字段 \"%s\" 中\n", p.title, p.key))
+		}
+	}
+	for i, p := range parts {
+		sb.WriteString(fmt.Sprintf("\n## 第%d部分：%s\n\n", i+1, p.title))
+		sb.WriteString(strings.TrimSpace(p.prompt))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n只返回合并后的JSON对象，不要包含其他内容。")
+	return sb.String()
+}
@@ -0,0 +1,414 @@
+// Package writer 写作器 - 章节写作流水线
+// 依据章节细纲分三个阶段成文：逐场景写初稿（draft）、通读全章做连贯性修订（revise）、逐句润色（polish）。
+// 每个阶段使用各自的模块映射（writer_draft/writer_revise/writer_polish，未配置时沿用 writer_scene）
+// 和 prompts.writer.chapter_* 下各自的提示词；修订和润色可在 chapter_writer.stages 中关闭，也可按次指定
+package writer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/glossary"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+const (
+	// defaultSceneWordCount 章节计划没有给出字数时每个场景的目标字数
+	defaultSceneWordCount = 1200
+	// previousTailRunes 写下一场景时附上的上一场景结尾长度（字）
+	previousTailRunes = 300
+	// minStageRetention 修订和润色后至少保留的篇幅比例，低于该比例视为模型截断或删改过度，不采用
+	minStageRetention = 0.6
+)
+
+// ChapterPlan 章节写作计划，由章节细纲转换而来
+type ChapterPlan struct {
+	Chapter   int
+	Title     string
+	Purpose   string
+	Tone      string
+	KeyEvents []string
+	WordCount int // 全章目标字数，0 时为各场景字数之和
+	Scenes    []ScenePlan
+}
+
+// ScenePlan 场景的写作要点
+type ScenePlan struct {
+	Sequence      int
+	Purpose       string
+	Location      string
+	Time          string
+	Characters    []string
+	MainAction    string
+	DialogueFocus string
+	StateChanges  []string       // 角色状态和关系的变化，如“林舟：由怀疑转为信任”
+	Plant         []string       // 需要埋下的伏笔
+	Payoff        []string       // 需要回收的伏笔
+	Transition    string         // 场景结束时的过渡
+	Guidance      []string       // 写作技巧和风格提示
+	Checklist     SceneChecklist // 视角、必含元素、禁止透露的信息和氛围
+	WordCount     int            // 目标字数，0 时按章节字数平均分配
+}
+
+// ChapterParams 章节写作参数
+type ChapterParams struct {
+	Plan            ChapterPlan
+	PreviousSummary string                          // 前情摘要
+	WorldContext    *models.WorldSetting            // 世界设定（可选）
+	Style           StyleConfig                     // 风格配置
+	StyleProfile    *models.StyleProfile            // 项目风格档案（可选）
+	VoiceProfiles   map[string]*models.VoiceProfile // 角色名 -> 语音档案（可选）
+	ContinuityFacts []models.ContinuityFact         // 写本章时仍有效的物理和状态细节（可选）
+	VitalStates     []models.VitalState             // 本章开始时已故或重伤未愈的角色（可选）
+	ItemHoldings    []models.ItemHolding            // 本章开始时关键物品的去向（可选）
+	RealmStates     []models.RealmState             // 本章开始时各角色的修为境界（可选，用于战斗场景）
+	RomanceArcs     []models.RomanceArc             // 蓝图的感情线节拍（可选）
+	Stages          []string                        // 执行的阶段，为空时按 chapter_writer.stages 配置
+}
+
+// ChapterResult 章节写作结果
+type ChapterResult struct {
+	Content         string                      `json:"content"`
+	WordCount       int                         `json:"word_count"`
+	Drafts          []SceneDraft                `json:"drafts"` // 各场景初稿
+	Stages          []StageReport               `json:"stages"`
+	TermCorrections []models.GlossaryCorrection `json:"term_corrections,omitempty"`
+}
+
+// SceneDraft 场景初稿
+type SceneDraft struct {
+	Sequence  int    `json:"sequence"`
+	Content   string `json:"content"`
+	WordCount int    `json:"word_count"`
+}
+
+// StageReport 阶段执行情况
+type StageReport struct {
+	Stage      string   `json:"stage"`
+	Module     string   `json:"module"` // 实际使用的模块
+	Model      string   `json:"model"`
+	WordCount  int      `json:"word_count"` // 本阶段完成后的全章字数
+	DurationMs int64    `json:"duration_ms"`
+	Changes    []string `json:"changes,omitempty"` // 修订说明
+	Skipped    string   `json:"skipped,omitempty"` // 未采用本阶段结果的原因，此时沿用上一阶段的文本
+}
+
+// chapterRevisionResponse 全章修订的响应
+type chapterRevisionResponse struct {
+	Content string   `json:"content"`
+	Changes []string `json:"changes"`
+}
+
+// stageEnabled 阶段是否执行：按次指定时以指定为准，否则按配置
+func (p ChapterParams) stageEnabled(stage string) bool {
+	if len(p.Stages) > 0 {
+		return config.ChapterWriterConfig{Stages: p.Stages}.Enabled(stage)
+	}
+	return config.Get().ChapterWriter.Enabled(stage)
+}
+
+// sceneWordCount 场景目标字数
+func (p ChapterPlan) sceneWordCount(s ScenePlan) int {
+	if s.WordCount > 0 {
+		return s.WordCount
+	}
+	if p.WordCount > 0 && len(p.Scenes) > 0 {
+		return p.WordCount / len(p.Scenes)
+	}
+	return defaultSceneWordCount
+}
+
+// targetWordCount 全章目标字数
+func (p ChapterPlan) targetWordCount() int {
+	if p.WordCount > 0 {
+		return p.WordCount
+	}
+	total := 0
+	for _, s := range p.Scenes {
+		total += p.sceneWordCount(s)
+	}
+	return total
+}
+
+// WriteChapter 按章节计划依次执行初稿、修订、润色，返回全章正文
+// 初稿失败时返回错误；修订和润色失败或结果不可用时沿用上一阶段的文本，原因记在阶段报告中
+func (w *Writer) WriteChapter(params ChapterParams) (*ChapterResult, error) {
+	if len(params.Plan.Scenes) == 0 {
+		return nil, fmt.Errorf("第%d章没有场景安排", params.Plan.Chapter)
+	}
+	if !params.stageEnabled(config.ChapterStageDraft) {
+		return nil, fmt.Errorf("阶段 %s 不可省略", config.ChapterStageDraft)
+	}
+	if params.Style.Voice == "" {
+		params.Style = DefaultStyle()
+	}
+	cfg := config.Get()
+	result := &ChapterResult{}
+
+	// 初稿：逐场景写作，每个场景附上上一场景的结尾以便衔接
+	sw, report := w.stageWriter(config.ChapterStageDraft)
+	start := time.Now()
+	previous := ""
+	for _, scene := range params.Plan.Scenes {
+		prompt, err := cfg.GetWriterChapterDraft(draftPromptData(params, scene, previous))
+		if err != nil {
+			return nil, err
+		}
+		resp, err := sw.callForRole(roleChapterDraft, prompt, w.buildSystemPrompt(params.Style))
+		if err != nil {
+			return nil, fmt.Errorf("第%d个场景初稿失败: %w", scene.Sequence, err)
+		}
+		var draft rewriteResponse
+		if err := jsonx.Unmarshal(resp, &draft); err != nil || strings.TrimSpace(draft.Content) == "" {
+			return nil, fmt.Errorf("第%d个场景初稿为空", scene.Sequence)
+		}
+		previous = strings.TrimSpace(draft.Content)
+		result.Drafts = append(result.Drafts, SceneDraft{
+			Sequence:  scene.Sequence,
+			Content:   previous,
+			WordCount: w.countWords(previous),
+		})
+	}
+	parts := make([]string, len(result.Drafts))
+	for i, d := range result.Drafts {
+		parts[i] = d.Content
+	}
+	content := strings.Join(parts, "\n\n")
+	result.Stages = append(result.Stages, w.finishStage(report, start, content))
+
+	if params.stageEnabled(config.ChapterStageRevise) {
+		content = w.runChapterStage(result, config.ChapterStageRevise, content, func(sw *Writer, content string) (string, []string, error) {
+			prompt, err := cfg.GetWriterChapterRevise(revisePromptData(params, content, w.countWords(content)))
+			if err != nil {
+				return "", nil, err
+			}
+			resp, err := sw.callForRole(roleChapterRevise, prompt,
+				"你是一位资深小说编辑，负责通读全章，修正场景衔接和前后矛盾，保留作者的情节和文风。只输出JSON。")
+			if err != nil {
+				return "", nil, err
+			}
+			var revised chapterRevisionResponse
+			if err := jsonx.Unmarshal(resp, &revised); err != nil {
+				return "", nil, fmt.Errorf("解析修订结果失败: %w", err)
+			}
+			return revised.Content, revised.Changes, nil
+		})
+	}
+
+	if params.stageEnabled(config.ChapterStagePolish) {
+		content = w.runChapterStage(result, config.ChapterStagePolish, content, func(sw *Writer, content string) (string, []string, error) {
+			prompt, err := cfg.GetWriterChapterPolish(polishPromptData(params, content, w.countWords(content)))
+			if err != nil {
+				return "", nil, err
+			}
+			resp, err := sw.callForRole(roleChapterPolish, prompt,
+				"你是一位文字功底深厚的小说润色编辑，只做句子层面的打磨，不改变情节和人物言行。只输出JSON。")
+			if err != nil {
+				return "", nil, err
+			}
+			var polished rewriteResponse
+			if err := jsonx.Unmarshal(resp, &polished); err != nil {
+				return "", nil, fmt.Errorf("解析润色结果失败: %w", err)
+			}
+			return polished.Content, nil, nil
+		})
+	}
+
+	// 术语校正：已登记的错误写法和近似写法改为术语表中的规范写法
+	if params.WorldContext != nil && len(params.WorldContext.Glossary) > 0 {
+		content, result.TermCorrections = glossary.Normalize(content, params.WorldContext.Glossary)
+	}
+	result.Content = content
+	result.WordCount = w.countWords(content)
+	return result, nil
+}
+
+// runChapterStage 执行修订或润色阶段，返回采用的文本
+func (w *Writer) runChapterStage(result *ChapterResult, stage, content string,
+	run func(sw *Writer, content string) (string, []string, error)) string {
+	sw, report := w.stageWriter(stage)
+	start := time.Now()
+
+	revised, changes, err := run(sw, content)
+	revised = strings.TrimSpace(revised)
+	switch {
+	case err != nil:
+		report.Skipped = err.Error()
+	case revised == "":
+		report.Skipped = "结果为空"
+	case float64(w.countWords(revised)) < float64(w.countWords(content))*minStageRetention:
+		report.Skipped = fmt.Sprintf("篇幅由%d字降至%d字，疑似截断", w.countWords(content), w.countWords(revised))
+	default:
+		content = revised
+		report.Changes = changes
+	}
+	if report.Skipped != "" {
+		w.log().Warn("章节写作阶段未采用，沿用上一阶段文本", "stage", stage, "reason", report.Skipped)
+	}
+	result.Stages = append(result.Stages, w.finishStage(report, start, content))
+	return content
+}
+
+// finishStage 补全阶段报告的耗时和字数
+func (w *Writer) finishStage(report StageReport, start time.Time, content string) StageReport {
+	report.DurationMs = time.Since(start).Milliseconds()
+	report.WordCount = w.countWords(content)
+	return report
+}
+
+// stageWriter 阶段使用的写作器：配置了 writer_<阶段> 模块时改用该模块的模型，否则沿用 writer_scene
+func (w *Writer) stageWriter(stage string) (*Writer, StageReport) {
+	report := StageReport{Stage: stage, Module: "writer_scene"}
+	if w.mapping != nil {
+		report.Model = w.mapping.Model
+	}
+
+	module := config.ChapterStageModule(stage)
+	if _, ok := config.Get().LLM.ModuleMapping[module]; !ok {
+		return w, report
+	}
+	client, mapping, err := llm.NewClientForModule(module)
+	if err != nil {
+		w.log().Warn("创建阶段模型客户端失败，沿用 writer_scene", "stage", stage, "module", module, "error", err)
+		return w, report
+	}
+
+	cp := *w
+	cp.client = w.decorate(client)
+	cp.mapping = mapping
+	report.Module = module
+	report.Model = mapping.Model
+	return &cp, report
+}
+
+// draftPromptData 初稿提示词的模板数据
+func draftPromptData(params ChapterParams, scene ScenePlan, previous string) map[string]interface{} {
+	plan := params.Plan
+	tail := "（本章开篇）"
+	if previous != "" {
+		runes := []rune(previous)
+		if len(runes) > previousTailRunes {
+			runes = runes[len(runes)-previousTailRunes:]
+		}
+		tail = string(runes)
+	}
+	return map[string]interface{}{
+		"Chapter":        plan.Chapter,
+		"Title":          plan.Title,
+		"ChapterPurpose": plan.Purpose,
+		"Tone":           plan.Tone,
+		"Sequence":       scene.Sequence,
+		"SceneCount":     len(plan.Scenes),
+		"Scene":          formatScenePlan(scene),
+		"Context":        draftContext(params, scene),
+		"PreviousTail":   tail,
+		"WordCount":      plan.sceneWordCount(scene),
+	}
+}
+
+// revisePromptData 修订提示词的模板数据
+func revisePromptData(params ChapterParams, content string, words int) map[string]interface{} {
+	plan := params.Plan
+	var outline strings.Builder
+	for _, s := range plan.Scenes {
+		outline.WriteString(fmt.Sprintf("%d. [%s] %s", s.Sequence, s.Location, s.Purpose))
+		if s.MainAction != "" {
+			outline.WriteString("：" + s.MainAction)
+		}
+		outline.WriteString("\n")
+	}
+	return map[string]interface{}{
+		"Chapter":        plan.Chapter,
+		"Title":          plan.Title,
+		"ChapterPurpose": plan.Purpose,
+		"KeyEvents":      strings.Join(plan.KeyEvents, "；"),
+		"Outline":        outline.String(),
+		"Content":        content,
+		"WordCount":      words,
+	}
+}
+
+// polishPromptData 润色提示词的模板数据
+func polishPromptData(params ChapterParams, content string, words int) map[string]interface{} {
+	var style strings.Builder
+	style.WriteString("## 风格要求\n")
+	style.WriteString(fmt.Sprintf("- 叙述视角: %s\n", voiceDescription(params.Style.Voice)))
+	style.WriteString(fmt.Sprintf("- 节奏: %s\n\n", pacingDescription(params.Style.Pacing)))
+	style.WriteString(BuildStyleProfilePrompt(params.StyleProfile))
+	return map[string]interface{}{
+		"Chapter":   params.Plan.Chapter,
+		"Title":     params.Plan.Title,
+		"Tone":      params.Plan.Tone,
+		"Style":     style.String(),
+		"Content":   content,
+		"WordCount": words,
+	}
+}
+
+// formatScenePlan 格式化场景写作要点
+func formatScenePlan(s ScenePlan) string {
+	var sb strings.Builder
+	sb.WriteString(fmt.Sprintf("- 目的：%s\n", s.Purpose))
+	sb.WriteString(fmt.Sprintf("- 地点：%s\n", s.Location))
+	if s.Time != "" {
+		sb.WriteString(fmt.Sprintf("- 时间：%s\n", s.Time))
+	}
+	if len(s.Characters) > 0 {
+		sb.WriteString(fmt.Sprintf("- 出场角色：%s\n", strings.Join(s.Characters, "、")))
+	}
+	if s.MainAction != "" {
+		sb.WriteString(fmt.Sprintf("- 主要动作：%s\n", s.MainAction))
+	}
+	if s.DialogueFocus != "" {
+		sb.WriteString(fmt.Sprintf("- 对话焦点：%s\n", s.DialogueFocus))
+	}
+	if len(s.StateChanges) > 0 {
+		sb.WriteString(fmt.Sprintf("- 状态变化：%s\n", strings.Join(s.StateChanges, "；")))
+	}
+	if len(s.Plant) > 0 {
+		sb.WriteString(fmt.Sprintf("- 埋下伏笔：%s\n", strings.Join(s.Plant, "；")))
+	}
+	if len(s.Payoff) > 0 {
+		sb.WriteString(fmt.Sprintf("- 回收伏笔：%s\n", strings.Join(s.Payoff, "；")))
+	}
+	if s.Transition != "" {
+		sb.WriteString(fmt.Sprintf("- 结尾过渡：%s\n", s.Transition))
+	}
+	if len(s.Guidance) > 0 {
+		sb.WriteString(fmt.Sprintf("- 写作提示：%s\n", strings.Join(s.Guidance, "；")))
+	}
+	sb.WriteString(formatChecklist(s.Checklist))
+	return sb.String()
+}
+
+// draftContext 初稿的背景信息：前情、语音档案、可用龙套、细节事实、生死、物品、境界、感情线、风格档案和术语
+func draftContext(params ChapterParams, scene ScenePlan) string {
+	var sb strings.Builder
+	if params.PreviousSummary != "" {
+		sb.WriteString(fmt.Sprintf("## 前情摘要\n%s\n\n", params.PreviousSummary))
+	}
+	sb.WriteString(BuildVoiceProfilePrompt(scene.Characters, params.VoiceProfiles))
+	sb.WriteString(buildMinorCharacterPrompt(params.WorldContext, scene.Location))
+	sb.WriteString(BuildContinuityPrompt(params.ContinuityFacts, scene.Characters, scene.Location, scene.Purpose, scene.MainAction))
+	sb.WriteString(BuildVitalPrompt(params.VitalStates, scene.Characters))
+	sb.WriteString(BuildItemPrompt(params.ItemHoldings, scene.Characters, scene.Location, scene.Purpose, scene.MainAction))
+	sb.WriteString(BuildRealmPrompt(params.RealmStates, params.WorldContext.Cultivation(), &models.SceneInstruction{
+		Chapter:    params.Plan.Chapter,
+		Scene:      scene.Sequence,
+		Purpose:    scene.Purpose,
+		Location:   scene.Location,
+		Characters: scene.Characters,
+		Action:     scene.MainAction,
+	}))
+	sb.WriteString(BuildRomancePrompt(params.RomanceArcs, params.Plan.Chapter, scene.Characters))
+	sb.WriteString(BuildStyleProfilePrompt(params.StyleProfile))
+	if params.WorldContext != nil {
+		sb.WriteString(BuildGlossaryPrompt(params.WorldContext.Glossary,
+			scene.Purpose, scene.Location, scene.MainAction, params.PreviousSummary))
+	}
+	return sb.String()
+}
@@ -0,0 +1,250 @@
+// Package writer 章节写作流水线测试
+package writer
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/config"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// newDryRunWriter 演练模式下的写作器，不调用真实API
+func newDryRunWriter(t *testing.T) *Writer {
+	t.Helper()
+	t.Chdir("../..")
+	t.Setenv("DB_DRIVER", "sqlite")
+	t.Setenv("DB_PATH", filepath.Join(t.TempDir(), "writer.db"))
+	llm.SetDryRun(true)
+	t.Cleanup(func() { llm.SetDryRun(false) })
+
+	w, err := New()
+	if err != nil {
+		t.Fatalf("初始化写作器失败: %v", err)
+	}
+	return w
+}
+
+// testChapterPlan 两个场景的章节计划
+func testChapterPlan() ChapterPlan {
+	return ChapterPlan{
+		Chapter:   3,
+		Title:     "夜雨",
+		Purpose:   "揭开玉佩的来历",
+		Tone:      "紧张",
+		KeyEvents: []string{"林舟夜探藏经阁", "苏晚出手相救"},
+		WordCount: 2000,
+		Scenes: []ScenePlan{
+			{Sequence: 1, Purpose: "潜入藏经阁", Location: "藏经阁", Characters: []string{"林舟"}, MainAction: "林舟翻找旧卷"},
+			{Sequence: 2, Purpose: "与黑衣人交手", Location: "后山", Characters: []string{"林舟", "苏晚"}, MainAction: "苏晚出手击退黑衣人"},
+		},
+	}
+}
+
+// TestChapterPlanWordCount 测试场景和全章目标字数
+func TestChapterPlanWordCount(t *testing.T) {
+	tests := []struct {
+		name       string
+		plan       ChapterPlan
+		wantScenes []int
+		wantTotal  int
+	}{
+		{
+			name:       "按章节字数平均分配",
+			plan:       ChapterPlan{WordCount: 3000, Scenes: []ScenePlan{{}, {}, {}}},
+			wantScenes: []int{1000, 1000, 1000},
+			wantTotal:  3000,
+		},
+		{
+			name:       "场景字数优先",
+			plan:       ChapterPlan{WordCount: 3000, Scenes: []ScenePlan{{WordCount: 2000}, {}}},
+			wantScenes: []int{2000, 1500},
+			wantTotal:  3000,
+		},
+		{
+			name:       "没有章节字数时为各场景之和",
+			plan:       ChapterPlan{Scenes: []ScenePlan{{WordCount: 800}, {}}},
+			wantScenes: []int{800, defaultSceneWordCount},
+			wantTotal:  800 + defaultSceneWordCount,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			for i, s := range tt.plan.Scenes {
+				if got := tt.plan.sceneWordCount(s); got != tt.wantScenes[i] {
+					t.Errorf("第%d个场景字数 = %d, 期望 %d", i+1, got, tt.wantScenes[i])
+				}
+			}
+			if got := tt.plan.targetWordCount(); got != tt.wantTotal {
+				t.Errorf("全章字数 = %d, 期望 %d", got, tt.wantTotal)
+			}
+		})
+	}
+}
+
+// TestDraftPromptDataPreviousTail 测试初稿提示词附上的上一场景结尾
+func TestDraftPromptDataPreviousTail(t *testing.T) {
+	params := ChapterParams{Plan: testChapterPlan()}
+	scene := params.Plan.Scenes[1]
+
+	if got := draftPromptData(params, scene, "")["PreviousTail"]; got != "（本章开篇）" {
+		t.Errorf("第一个场景的 PreviousTail = %q", got)
+	}
+
+	previous := strings.Repeat("甲", 100) + strings.Repeat("乙", previousTailRunes)
+	tail := draftPromptData(params, scene, previous)["PreviousTail"].(string)
+	if tail != strings.Repeat("乙", previousTailRunes) {
+		t.Errorf("PreviousTail 应为上一场景最后 %d 字，实际 %d 字", previousTailRunes, len([]rune(tail)))
+	}
+}
+
+// TestDraftContextContinuity 测试初稿背景信息中的细节事实、生死、物品、境界和感情线
+func TestDraftContextContinuity(t *testing.T) {
+	plan := testChapterPlan()
+	fight := plan.Scenes[1]
+	world := &models.WorldSetting{Laws: models.Laws{Supernatural: &models.Supernatural{
+		Exists: true,
+		Type:   "cultivation",
+		Settings: &models.SupernaturalSettings{
+			CultivationSystem: &models.CultivationSystem{Realms: []string{"炼气", "筑基", "金丹"}},
+		},
+	}}}
+	params := ChapterParams{
+		Plan:            plan,
+		WorldContext:    world,
+		ContinuityFacts: []models.ContinuityFact{{Subject: "林舟", Attribute: "左手", Value: "缠着绷带"}},
+		VitalStates: []models.VitalState{
+			{Character: "老掌门", Status: models.StatusDead, Since: 1, Detail: "中毒"},
+			{Character: "林舟", Status: models.StatusInjured, Since: 2, Detail: "肋骨断裂"},
+		},
+		ItemHoldings: []models.ItemHolding{{Name: "青玉佩", Holder: "苏晚", Since: 2}},
+		RealmStates:  []models.RealmState{{Character: "林舟", Realm: "筑基", Level: 2, Since: 2}},
+		RomanceArcs: []models.RomanceArc{{
+			CharacterA: "林舟",
+			CharacterB: "苏晚",
+			Beats:      []models.RomanceBeat{{Type: models.BeatAttraction, Chapter: 3, Description: "生死关头的心动"}},
+		}},
+	}
+
+	got := draftContext(params, fight)
+	for _, want := range []string{
+		"## 已确立的细节", "林舟 左手：缠着绷带",
+		"## 已故角色", "老掌门",
+		"## 伤势", "肋骨断裂",
+		"## 关键物品", "青玉佩：在苏晚手中",
+		"## 修为境界", "林舟：筑基",
+		"## 感情线", "生死关头的心动",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("背景信息缺少 %q:\n%s", want, got)
+		}
+	}
+
+	// 第一个场景只有林舟出场且不是战斗：不列苏晚的物品、境界和感情线
+	got = draftContext(params, plan.Scenes[0])
+	for _, unwanted := range []string{"## 关键物品", "## 修为境界", "## 感情线"} {
+		if strings.Contains(got, unwanted) {
+			t.Errorf("非相关场景的背景信息不应包含 %q:\n%s", unwanted, got)
+		}
+	}
+
+	if got := draftContext(ChapterParams{Plan: plan}, fight); strings.Contains(got, "##") {
+		t.Errorf("没有任何上下文时背景信息应为空，实际:\n%s", got)
+	}
+}
+
+// TestWriteChapter_DryRun 演练模式下按细纲写作整章
+func TestWriteChapter_DryRun(t *testing.T) {
+	w := newDryRunWriter(t)
+
+	tests := []struct {
+		name       string
+		stages     []string
+		wantStages []string
+	}{
+		{name: "全部阶段", stages: config.ChapterStages, wantStages: config.ChapterStages},
+		{name: "只写初稿", stages: []string{config.ChapterStageDraft}, wantStages: []string{config.ChapterStageDraft}},
+		{name: "跳过修订", stages: []string{config.ChapterStageDraft, config.ChapterStagePolish}, wantStages: []string{config.ChapterStageDraft, config.ChapterStagePolish}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := w.WriteChapter(ChapterParams{Plan: testChapterPlan(), Stages: tt.stages})
+			if err != nil {
+				t.Fatalf("写作失败: %v", err)
+			}
+			if len(result.Drafts) != 2 {
+				t.Errorf("初稿数 = %d, 期望 2", len(result.Drafts))
+			}
+			if strings.TrimSpace(result.Content) == "" || result.WordCount == 0 {
+				t.Error("正文为空")
+			}
+			got := make([]string, len(result.Stages))
+			for i, s := range result.Stages {
+				got[i] = s.Stage
+			}
+			if strings.Join(got, ",") != strings.Join(tt.wantStages, ",") {
+				t.Errorf("执行的阶段 = %v, 期望 %v", got, tt.wantStages)
+			}
+		})
+	}
+}
+
+// TestWriteChapterInvalid 测试无法写作的章节计划
+func TestWriteChapterInvalid(t *testing.T) {
+	w := newDryRunWriter(t)
+
+	if _, err := w.WriteChapter(ChapterParams{Plan: ChapterPlan{Chapter: 1}}); err == nil {
+		t.Error("没有场景时应返回错误")
+	}
+	if _, err := w.WriteChapter(ChapterParams{Plan: testChapterPlan(), Stages: []string{config.ChapterStageRevise}}); err == nil {
+		t.Error("省略初稿阶段时应返回错误")
+	}
+}
+
+// TestRunChapterStage 测试修订和润色结果的采用规则
+func TestRunChapterStage(t *testing.T) {
+	w := newDryRunWriter(t)
+	original := strings.Repeat("风从山口灌进来。", 20)
+
+	tests := []struct {
+		name        string
+		revised     string
+		err         error
+		wantAdopted bool
+	}{
+		{name: "采用修订结果", revised: strings.Repeat("风从山口灌了进来。", 20), wantAdopted: true},
+		{name: "调用失败", err: errors.New("超时")},
+		{name: "结果为空", revised: "  \n"},
+		{name: "篇幅过短视为截断", revised: strings.Repeat("风从山口灌进来。", 5)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &ChapterResult{}
+			got := w.runChapterStage(result, config.ChapterStageRevise, original, func(*Writer, string) (string, []string, error) {
+				return tt.revised, []string{"调整衔接"}, tt.err
+			})
+			if len(result.Stages) != 1 {
+				t.Fatalf("阶段报告数 = %d, 期望 1", len(result.Stages))
+			}
+			report := result.Stages[0]
+			if tt.wantAdopted {
+				if got != strings.TrimSpace(tt.revised) || report.Skipped != "" || len(report.Changes) != 1 {
+					t.Errorf("应采用修订结果，实际 skipped=%q", report.Skipped)
+				}
+				return
+			}
+			if got != original || report.Skipped == "" || report.Changes != nil {
+				t.Errorf("应沿用原文并记录原因，实际 skipped=%q", report.Skipped)
+			}
+			if report.WordCount != w.countWords(original) {
+				t.Errorf("阶段字数 = %d, 期望 %d", report.WordCount, w.countWords(original))
+			}
+		})
+	}
+}
@@ -0,0 +1,212 @@
+// Package writer 写作器 - 战斗分解
+// 动作场景写正文前先拆出逐回合的攻防：站位、招式、代价和伤势，正文按分解推进，避免打斗写得含糊
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+const (
+	// combatTitle 战斗分解提示词标题，同时作为模拟响应的标记
+	combatTitle = "战斗分解\n"
+	// maxCombatBeats 战斗分解的回合上限
+	maxCombatBeats = 12
+)
+
+func init() {
+	llm.RegisterMock(combatTitle, models.CombatChoreography{Beats: []models.CombatBeat{}})
+}
+
+// CombatParams 战斗分解参数
+type CombatParams struct {
+	Instruction *models.SceneInstruction
+	World       *models.WorldSetting // 超自然体系的代价和限制
+	Realms      []models.RealmState  // 各角色的修为境界（可选）
+	Vitals      []models.VitalState  // 重伤未愈的角色（可选）
+	Items       []models.ItemHolding // 关键物品的去向（可选），出场角色持有的兵器可用于战斗
+}
+
+// IsActionScene 场景是否需要战斗分解：章节规划标为动作场景，或场景文字写明了战斗
+func IsActionScene(plan *models.ChapterPlan, instr *models.SceneInstruction) bool {
+	if plan != nil && plan.SceneType(instr.Scene) == models.SceneTypeAction {
+		return true
+	}
+	return IsCombatScene(instr)
+}
+
+// PlanCombat 为动作场景生成逐回合的战斗分解
+func (w *Writer) PlanCombat(params CombatParams) (*models.CombatChoreography, error) {
+	if params.Instruction == nil || len(params.Instruction.Characters) == 0 {
+		return nil, fmt.Errorf("场景没有出场角色")
+	}
+
+	result, err := w.callForRole(roleCombat, buildCombatPrompt(params),
+		"你是一位武打与动作戏设计，擅长把打斗拆成清晰可信的攻防回合。所有招式和能力必须遵守世界设定的代价与限制。只输出JSON。")
+	if err != nil {
+		return nil, fmt.Errorf("生成战斗分解失败: %w", err)
+	}
+
+	var out models.CombatChoreography
+	if err := jsonx.Unmarshal(result, &out); err != nil {
+		return nil, fmt.Errorf("解析战斗分解失败: %w", err)
+	}
+	beats := make([]models.CombatBeat, 0, len(out.Beats))
+	for _, b := range out.Beats {
+		if strings.TrimSpace(b.Actor) == "" || strings.TrimSpace(b.Action) == "" {
+			continue
+		}
+		beats = append(beats, b)
+		if len(beats) >= maxCombatBeats {
+			break
+		}
+	}
+	out.Beats = beats
+	return &out, nil
+}
+
+// buildCombatPrompt 构建战斗分解提示词
+func buildCombatPrompt(params CombatParams) string {
+	instr := params.Instruction
+	var prompt strings.Builder
+
+	prompt.WriteString(fmt.Sprintf("# 第%d章场景%d%s\n", instr.Chapter, instr.Scene, combatTitle))
+	prompt.WriteString("## 场景\n")
+	prompt.WriteString(fmt.Sprintf("- 地点：%s\n- 目的：%s\n", instr.Location, instr.Purpose))
+	if instr.Action != "" {
+		prompt.WriteString(fmt.Sprintf("- 动作：%s\n", instr.Action))
+	}
+	if instr.Mood != "" {
+		prompt.WriteString(fmt.Sprintf("- 氛围：%s\n", instr.Mood))
+	}
+	prompt.WriteString("\n")
+
+	realms := make(map[string]string, len(params.Realms))
+	for _, s := range params.Realms {
+		realms[s.Character] = s.Realm
+	}
+	injuries := make(map[string]string, len(params.Vitals))
+	for _, s := range params.Vitals {
+		if s.Status == models.StatusInjured {
+			injuries[s.Character] = s.Detail
+		}
+	}
+	held := make(map[string][]string)
+	for _, h := range params.Items {
+		if h.Holder != "" && !h.Destroyed {
+			held[h.Holder] = append(held[h.Holder], h.Name)
+		}
+	}
+	prompt.WriteString("## 参战角色\n")
+	for _, name := range instr.Characters {
+		parts := make([]string, 0, 3)
+		if r := realms[name]; r != "" {
+			parts = append(parts, "境界"+r)
+		}
+		if d := injuries[name]; d != "" {
+			parts = append(parts, "重伤未愈："+d)
+		}
+		if list := held[name]; len(list) > 0 {
+			parts = append(parts, "持有"+strings.Join(list, "、"))
+		}
+		if len(parts) == 0 {
+			prompt.WriteString(fmt.Sprintf("- %s\n", name))
+			continue
+		}
+		prompt.WriteString(fmt.Sprintf("- %s：%s\n", name, strings.Join(parts, "；")))
+	}
+	prompt.WriteString("\n")
+	prompt.WriteString(describeSupernatural(params.World))
+
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString(fmt.Sprintf("1. 把打斗拆成3-%d个回合，每回合写明出手者、目标、站位与距离的变化、使用的招式或能力、具体动作和结果\n", maxCombatBeats))
+	prompt.WriteString("2. 能力必须在世界设定的范围内，使用时写出代价（cost）；不得使用角色没有的兵器或能力\n")
+	prompt.WriteString("3. 胜负符合境界差距和伤势；以弱胜强须写出借助的地形、外物或代价\n")
+	prompt.WriteString("4. injury 记录本回合造成的伤势，后续回合要体现伤势的影响\n")
+	prompt.WriteString("5. terrain 写战场中可利用的环境，outcome 写结果，须与场景目的一致\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "terrain": "战场地形",
+  "beats": [
+    {"actor": "出手者", "target": "目标", "position": "站位变化", "ability": "招式或能力", "cost": "代价", "action": "具体动作", "effect": "结果", "injury": "造成的伤势"}
+  ],
+  "outcome": "战斗结果"
+}`)
+	return prompt.String()
+}
+
+// describeSupernatural 世界超自然体系的代价与限制
+func describeSupernatural(world *models.WorldSetting) string {
+	if world == nil || world.Laws.Supernatural == nil || world.Laws.Supernatural.Settings == nil {
+		return ""
+	}
+	s := world.Laws.Supernatural.Settings
+	var sb strings.Builder
+	if m := s.MagicSystem; m != nil {
+		sb.WriteString(fmt.Sprintf("- 魔法：来源%s，代价%s\n", m.Source, m.Cost))
+		if len(m.Limitation) > 0 {
+			sb.WriteString(fmt.Sprintf("  限制：%s\n", strings.Join(m.Limitation, "；")))
+		}
+	}
+	if c := s.CultivationSystem; c != nil && len(c.Realms) > 0 {
+		sb.WriteString(fmt.Sprintf("- 修真境界：%s\n", strings.Join(c.Realms, "→")))
+		if c.ResourceSystem != "" {
+			sb.WriteString(fmt.Sprintf("  资源：%s\n", c.ResourceSystem))
+		}
+	}
+	if p := s.SuperpowerSystem; p != nil {
+		sb.WriteString(fmt.Sprintf("- 异能：%s，%s\n", p.Origin, p.Type))
+		if len(p.Limit) > 0 {
+			sb.WriteString(fmt.Sprintf("  限制：%s\n", strings.Join(p.Limit, "；")))
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "## 超自然体系\n" + sb.String() + "\n"
+}
+
+// BuildChoreographyPrompt 构建场景提示词中的战斗分解，正文必须按回合推进
+func BuildChoreographyPrompt(ch *models.CombatChoreography) string {
+	if ch == nil || len(ch.Beats) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## 战斗分解\n")
+	if ch.Terrain != "" {
+		sb.WriteString(fmt.Sprintf("战场：%s\n", ch.Terrain))
+	}
+	for i, b := range ch.Beats {
+		sb.WriteString(fmt.Sprintf("%d. %s", i+1, b.Actor))
+		if b.Target != "" {
+			sb.WriteString("→" + b.Target)
+		}
+		if b.Position != "" {
+			sb.WriteString(fmt.Sprintf("（%s）", b.Position))
+		}
+		if b.Ability != "" {
+			sb.WriteString("【" + b.Ability + "】")
+		}
+		sb.WriteString("：" + b.Action)
+		if b.Effect != "" {
+			sb.WriteString("，" + b.Effect)
+		}
+		if b.Cost != "" {
+			sb.WriteString("；代价：" + b.Cost)
+		}
+		if b.Injury != "" {
+			sb.WriteString("；伤势：" + b.Injury)
+		}
+		sb.WriteString("\n")
+	}
+	if ch.Outcome != "" {
+		sb.WriteString(fmt.Sprintf("结果：%s\n", ch.Outcome))
+	}
+	sb.WriteString("打斗必须按以上回合顺序推进，写清每一回合的站位、动作和伤势，不得跳过回合或改变结果。\n\n")
+	return sb.String()
+}
@@ -0,0 +1,185 @@
+// Package writer 写作器 - 细节连续性
+// 从章节正文中提取外貌、伤势、随身物品等物理和状态细节，并对照此前确立的细节找出矛盾
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+const (
+	// continuityTitle 连续性检查提示词标题，同时作为模拟响应的标记
+	continuityTitle = "细节连续性检查\n"
+	// maxContinuityPromptFacts 场景提示词中列出的事实上限
+	maxContinuityPromptFacts = 30
+)
+
+// ContinuityUpdate 本章正文确立或改变的一条细节
+type ContinuityUpdate struct {
+	Subject   string `json:"subject"`
+	Attribute string `json:"attribute"`
+	Value     string `json:"value"`
+	Category  string `json:"category"`
+	Permanent bool   `json:"permanent"`
+	Changed   bool   `json:"changed"` // 正文明确写出了变化（伤愈、遗失、换装等），取代已确立的值
+	Evidence  string `json:"evidence"`
+}
+
+// continuityResponse 连续性检查的响应
+type continuityResponse struct {
+	Facts     []ContinuityUpdate       `json:"facts"`
+	Conflicts []models.ContinuityIssue `json:"conflicts"`
+}
+
+func init() {
+	llm.RegisterMock(continuityTitle, continuityResponse{Facts: []ContinuityUpdate{}, Conflicts: []models.ContinuityIssue{}})
+}
+
+// ContinuityParams 连续性检查参数
+type ContinuityParams struct {
+	Chapter int
+	Prose   string                  // 本章正文
+	Facts   []models.ContinuityFact // 写本章时仍有效的事实
+}
+
+// ContinuityResult 连续性检查结果
+type ContinuityResult struct {
+	Updates []ContinuityUpdate       // 本章新确立或改变的细节
+	Issues  []models.ContinuityIssue // 与已确立细节的矛盾
+}
+
+// CheckContinuity 提取本章确立的细节，并找出与已确立细节矛盾的描写
+// 只送入主体在本章出现过的事实
+func (w *Writer) CheckContinuity(params ContinuityParams) (*ContinuityResult, error) {
+	if strings.TrimSpace(params.Prose) == "" {
+		return nil, fmt.Errorf("第%d章没有正文", params.Chapter)
+	}
+	facts := make([]models.ContinuityFact, 0, len(params.Facts))
+	for _, f := range params.Facts {
+		if strings.Contains(params.Prose, f.Subject) {
+			facts = append(facts, f)
+		}
+	}
+
+	result, err := w.callForRole(roleContinuity, buildContinuityPrompt(params.Chapter, params.Prose, facts),
+		"你是一位细心的小说校对，专门核对人物外貌、伤势和随身物品在前后章节中是否一致。只依据正文明确写出的内容，不做推测。只输出JSON。")
+	if err != nil {
+		return nil, fmt.Errorf("细节连续性检查失败: %w", err)
+	}
+
+	var out continuityResponse
+	if err := jsonx.Unmarshal(result, &out); err != nil {
+		return nil, fmt.Errorf("解析连续性检查结果失败: %w", err)
+	}
+
+	res := &ContinuityResult{
+		Updates: make([]ContinuityUpdate, 0, len(out.Facts)),
+		Issues:  make([]models.ContinuityIssue, 0, len(out.Conflicts)),
+	}
+	for _, u := range out.Facts {
+		u.Subject = strings.TrimSpace(u.Subject)
+		u.Attribute = strings.TrimSpace(u.Attribute)
+		u.Value = strings.TrimSpace(u.Value)
+		if u.Subject == "" || u.Attribute == "" || u.Value == "" {
+			continue
+		}
+		switch u.Category {
+		case models.FactAppearance, models.FactInjury, models.FactPossession, models.FactState:
+		default:
+			u.Category = models.FactState
+		}
+		res.Updates = append(res.Updates, u)
+	}
+	for _, issue := range out.Conflicts {
+		if issue.Subject == "" || issue.Found == "" {
+			continue
+		}
+		// 以事实库为准补全确立的章节
+		for _, f := range facts {
+			if f.Subject == issue.Subject && f.Attribute == issue.Attribute {
+				issue.Established = f.Value
+				issue.EstablishedChapter = f.Chapter
+				break
+			}
+		}
+		res.Issues = append(res.Issues, issue)
+	}
+	return res, nil
+}
+
+// buildContinuityPrompt 构建连续性检查提示词
+func buildContinuityPrompt(chapter int, prose string, facts []models.ContinuityFact) string {
+	var prompt strings.Builder
+
+	prompt.WriteString(fmt.Sprintf("# 第%d章%s\n", chapter, continuityTitle))
+	if len(facts) > 0 {
+		prompt.WriteString("## 已确立的细节\n")
+		for _, f := range facts {
+			prompt.WriteString(fmt.Sprintf("- %s %s：%s", f.Subject, f.Attribute, f.Value))
+			if f.Chapter > 0 {
+				prompt.WriteString(fmt.Sprintf("（第%d章）", f.Chapter))
+			}
+			prompt.WriteString("\n")
+		}
+		prompt.WriteString("\n")
+	}
+	prompt.WriteString("## 本章正文\n")
+	prompt.WriteString(prose)
+	prompt.WriteString("\n\n")
+
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString("1. facts：列出本章正文明确写出的物理和状态细节——外貌特征（appearance）、伤势病症（injury）、随身物品（possession）、其他状态（state）；比喻、回忆和他人的误传不算\n")
+	prompt.WriteString("2. 同一主体的同一属性与已确立的细节相同时不必重复；正文明确写出了变化过程（伤口愈合、丢失、交给他人）时 changed 为 true\n")
+	prompt.WriteString("3. 瞳色、胎记这类通常不会改变的细节 permanent 为 true\n")
+	prompt.WriteString("4. conflicts：正文与已确立细节不一致、且没有写出变化原因的地方，例如瞳色变了、伤势凭空消失、已经丢失的物品又出现；quote 摘录本章原句，suggestion 给出改法\n")
+	prompt.WriteString("5. 属性名尽量沿用已确立细节中的写法\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "facts": [
+    {"subject": "角色或物品", "attribute": "属性", "value": "值", "category": "appearance|injury|possession|state", "permanent": false, "changed": false, "evidence": "原句"}
+  ],
+  "conflicts": [
+    {"subject": "角色或物品", "attribute": "属性", "established": "已确立的值", "found": "本章写成的值", "quote": "原句", "suggestion": "改法"}
+  ]
+}`)
+
+	return prompt.String()
+}
+
+// BuildContinuityPrompt 构建场景提示词中的已确立细节：出场角色的细节，以及场景文字中提到的其他主体的细节
+func BuildContinuityPrompt(facts []models.ContinuityFact, characters []string, texts ...string) string {
+	if len(facts) == 0 {
+		return ""
+	}
+	context := strings.Join(texts, "\n")
+	relevant := func(subject string) bool {
+		for _, c := range characters {
+			if c == subject {
+				return true
+			}
+		}
+		return subject != "" && strings.Contains(context, subject)
+	}
+
+	var sb strings.Builder
+	n := 0
+	for _, f := range facts {
+		if n >= maxContinuityPromptFacts {
+			break
+		}
+		if !relevant(f.Subject) {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("- %s %s：%s\n", f.Subject, f.Attribute, f.Value))
+		n++
+	}
+	if n == 0 {
+		return ""
+	}
+	return "## 已确立的细节\n" + sb.String() + "描写不得与以上细节矛盾；如需改变，必须在正文中写出原因。\n\n"
+}
@@ -0,0 +1,60 @@
+// Package writer 写作器 - 修为境界
+// 战斗场景中列出出场角色的当前境界，让交手的胜负和手段符合境界差距
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// combatKeywords 判断战斗场景的关键词
+var combatKeywords = []string{"战斗", "交手", "厮杀", "打斗", "比试", "对决", "决斗", "围攻", "伏击", "激战", "搏杀", "斗法", "过招", "追杀", "刺杀", "切磋", "大战", "鏖战"}
+
+// IsCombatScene 场景目的、动作或氛围中是否写明了战斗
+func IsCombatScene(instr *models.SceneInstruction) bool {
+	if instr == nil {
+		return false
+	}
+	text := instr.Purpose + instr.Action + instr.Mood
+	for _, k := range combatKeywords {
+		if strings.Contains(text, k) {
+			return true
+		}
+	}
+	return false
+}
+
+// BuildRealmPrompt 构建战斗场景提示词中的境界约束，非战斗场景或出场角色都没有境界记录时返回空
+func BuildRealmPrompt(states []models.RealmState, system *models.CultivationSystem, instr *models.SceneInstruction) string {
+	if len(states) == 0 || system == nil || !IsCombatScene(instr) {
+		return ""
+	}
+	inScene := make(map[string]bool, len(instr.Characters))
+	for _, c := range instr.Characters {
+		inScene[c] = true
+	}
+
+	var sb strings.Builder
+	for _, s := range states {
+		if !inScene[s.Character] {
+			continue
+		}
+		sb.WriteString(fmt.Sprintf("- %s：%s", s.Character, s.Realm))
+		if s.Level > 0 {
+			sb.WriteString(fmt.Sprintf("（第%d境）", s.Level))
+		}
+		sb.WriteString("\n")
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString("## 修为境界\n")
+	prompt.WriteString(fmt.Sprintf("境界由低到高：%s\n", strings.Join(system.Realms, "→")))
+	prompt.WriteString(sb.String())
+	prompt.WriteString("交手的手段和胜负要符合境界差距：低境界者不能正面击败高出一个大境界的对手，以弱胜强必须写出借助的外物、地利或付出的代价；本场景中不得突破境界，除非场景指令明确要求。\n\n")
+	return prompt.String()
+}
@@ -3,9 +3,10 @@
 package writer
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/xlei/xupu/pkg/jsonx"
 )
 
 // DescriptionType 描写类型
@@ -64,15 +65,12 @@ func (w *Writer) GenerateDescription(params DescriptionParams) (*DescriptionOutp
 
 	// 解析结果
 	var output DescriptionOutput
-	if err := json.Unmarshal([]byte(result), &output); err != nil {
-		extracted := extractJSON(result)
-		if err := json.Unmarshal([]byte(extracted), &output); err != nil {
-			// 如果解析失败，使用原始内容
-			output = DescriptionOutput{
-				Type:      params.Type,
-				Content:   result,
-				WordCount: len([]rune(result)),
-			}
+	if err := jsonx.Unmarshal(result, &output); err != nil {
+		// 如果解析失败，使用原始内容
+		output = DescriptionOutput{
+			Type:      params.Type,
+			Content:   result,
+			WordCount: len([]rune(result)),
 		}
 	}
 
@@ -250,10 +248,7 @@ func (w *Writer) GenerateActionSequence(params ActionSequenceParams) ([]string,
 	}
 
 	var actions []string
-	if err := json.Unmarshal([]byte(result), &actions); err != nil {
-		extracted := extractJSON(result)
-		json.Unmarshal([]byte(extracted), &actions)
-	}
+	jsonx.Unmarshal(result, &actions)
 
 	return actions, nil
 }
@@ -3,9 +3,10 @@
 package writer
 
 import (
-	"encoding/json"
 	"fmt"
 	"strings"
+
+	"github.com/xlei/xupu/pkg/jsonx"
 )
 
 // DialogueParams 对话生成参数
@@ -60,15 +61,12 @@ func (w *Writer) GenerateDialogue(params DialogueParams) (*DialogueSegment, erro
 
 	// 解析结果
 	var segment DialogueSegment
-	if err := json.Unmarshal([]byte(result), &segment); err != nil {
-		extracted := extractJSON(result)
-		if err := json.Unmarshal([]byte(extracted), &segment); err != nil {
-			// 如果解析失败，尝试构建简单的对话片段
-			segment = DialogueSegment{
-				Lines: []DialogueLine{
-					{Character: params.Characters[0].Name, Content: result},
-				},
-			}
+	if err := jsonx.Unmarshal(result, &segment); err != nil {
+		// 如果解析失败，尝试构建简单的对话片段
+		segment = DialogueSegment{
+			Lines: []DialogueLine{
+				{Character: params.Characters[0].Name, Content: result},
+			},
 		}
 	}
 
@@ -207,10 +205,7 @@ func (w *Writer) EnhanceDialogue(existingLines []DialogueLine, params Enhancemen
 	}
 
 	var enhanced []DialogueLine
-	if err := json.Unmarshal([]byte(result), &enhanced); err != nil {
-		extracted := extractJSON(result)
-		json.Unmarshal([]byte(extracted), &enhanced)
-	}
+	jsonx.Unmarshal(result, &enhanced)
 
 	return enhanced, nil
 }
@@ -0,0 +1,121 @@
+// Package writer 写作器 - 修改建议
+// 按作者的指令（如“更紧张一些”“删掉副词”）改写章节选段，改写结果与原文逐句对齐，由作者逐句采纳或拒绝
+package writer
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+)
+
+const (
+	// MaxSuggestionRunes 单次修改建议的选段上限（字）
+	MaxSuggestionRunes = 4000
+	// suggestionContextRunes 选段前后附上的上下文长度（字）
+	suggestionContextRunes = 300
+)
+
+// EditSuggestionParams 修改建议参数
+type EditSuggestionParams struct {
+	Passage      string               // 选段原文
+	Instruction  string               // 作者指令
+	Before       string               // 选段之前的正文，只取结尾一段作为上下文
+	After        string               // 选段之后的正文，只取开头一段作为上下文
+	StyleProfile *models.StyleProfile // 项目风格档案（可选）
+}
+
+// EditSuggestionResult 修改建议
+type EditSuggestionResult struct {
+	Revised string            `json:"revised"`
+	Notes   []string          `json:"notes,omitempty"`
+	Hunks   []models.EditHunk `json:"hunks"`
+}
+
+// editSuggestionResponse 修改建议的响应
+type editSuggestionResponse struct {
+	Content string   `json:"content"`
+	Notes   []string `json:"notes"`
+}
+
+// SuggestEdit 按指令改写选段，返回改写结果和逐句差异
+func (w *Writer) SuggestEdit(params EditSuggestionParams) (*EditSuggestionResult, error) {
+	if strings.TrimSpace(params.Passage) == "" {
+		return nil, fmt.Errorf("选段为空")
+	}
+	if n := len([]rune(params.Passage)); n > MaxSuggestionRunes {
+		return nil, fmt.Errorf("选段过长（%d字），单次最多%d字", n, MaxSuggestionRunes)
+	}
+
+	result, err := w.callForRole(roleEditSuggestion, buildEditSuggestionPrompt(params),
+		"你是一位细致的小说编辑，按作者的指令修改选段，只改需要改的地方，保持作者的文风。只输出JSON。")
+	if err != nil {
+		return nil, fmt.Errorf("生成修改建议失败: %w", err)
+	}
+	var resp editSuggestionResponse
+	if err := jsonx.Unmarshal(result, &resp); err != nil {
+		return nil, fmt.Errorf("解析修改建议失败: %w", err)
+	}
+	if strings.TrimSpace(resp.Content) == "" {
+		return nil, fmt.Errorf("修改建议为空")
+	}
+
+	// 选段首尾的空白不交给模型处理，按原样保留
+	lead := params.Passage[:len(params.Passage)-len(strings.TrimLeftFunc(params.Passage, unicode.IsSpace))]
+	trail := params.Passage[len(strings.TrimRightFunc(params.Passage, unicode.IsSpace)):]
+	revised := lead + strings.TrimSpace(resp.Content) + trail
+
+	return &EditSuggestionResult{
+		Revised: revised,
+		Notes:   resp.Notes,
+		Hunks:   DiffSentences(params.Passage, revised),
+	}, nil
+}
+
+// buildEditSuggestionPrompt 构建修改建议提示词
+func buildEditSuggestionPrompt(params EditSuggestionParams) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("# 选段修改任务\n\n")
+	prompt.WriteString(fmt.Sprintf("## 作者指令\n%s\n\n", strings.TrimSpace(params.Instruction)))
+
+	if before := tailRunes(params.Before, suggestionContextRunes); before != "" {
+		prompt.WriteString(fmt.Sprintf("## 前文（仅供参考，不要修改）\n%s\n\n", before))
+	}
+	prompt.WriteString(fmt.Sprintf("## 选段\n%s\n\n", strings.TrimSpace(params.Passage)))
+	if after := headRunes(params.After, suggestionContextRunes); after != "" {
+		prompt.WriteString(fmt.Sprintf("## 后文（仅供参考，不要修改）\n%s\n\n", after))
+	}
+	prompt.WriteString(BuildStyleProfilePrompt(params.StyleProfile))
+
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString("1. 只按作者指令修改选段，与指令无关的句子保持原样，一字不改\n")
+	prompt.WriteString("2. 不改变情节、人物言行和信息量，修改后与前后文自然衔接\n")
+	prompt.WriteString("3. 保留原有的分段\n")
+	prompt.WriteString("4. notes 简要说明做了哪些修改\n\n")
+
+	prompt.WriteString("# 输出格式（JSON）\n")
+	prompt.WriteString(`{"content": "修改后的选段", "notes": ["修改说明"]}`)
+
+	return prompt.String()
+}
+
+// tailRunes 取文本结尾的 n 个字
+func tailRunes(text string, n int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) > n {
+		runes = runes[len(runes)-n:]
+	}
+	return string(runes)
+}
+
+// headRunes 取文本开头的 n 个字
+func headRunes(text string, n int) string {
+	runes := []rune(strings.TrimSpace(text))
+	if len(runes) > n {
+		runes = runes[:n]
+	}
+	return string(runes)
+}
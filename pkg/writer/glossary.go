@@ -0,0 +1,54 @@
+// Package writer 写作器 - 术语表
+// 生成时把相关术语的规范写法写入提示词，生成后校正正文中的偏差写法
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// maxPromptTerms 每个场景提示词中列出的术语上限（境界不计入，始终全部列出）
+const maxPromptTerms = 30
+
+// BuildGlossaryPrompt 场景提示词中的术语表：境界全部列出，其他术语优先列出场景信息中提到的
+func BuildGlossaryPrompt(glossary []models.GlossaryTerm, hints ...string) string {
+	if len(glossary) == 0 {
+		return ""
+	}
+	hint := strings.Join(hints, "\n")
+
+	realms := make([]string, 0)
+	mentioned := make([]models.GlossaryTerm, 0)
+	others := make([]models.GlossaryTerm, 0)
+	for _, t := range glossary {
+		switch {
+		case t.Kind == models.TermRealm:
+			realms = append(realms, t.Term)
+		case strings.Contains(hint, t.Term):
+			mentioned = append(mentioned, t)
+		default:
+			others = append(others, t)
+		}
+	}
+	listed := append(mentioned, others...)
+	if len(listed) > maxPromptTerms {
+		listed = listed[:maxPromptTerms]
+	}
+
+	var b strings.Builder
+	b.WriteString("## 术语表（专有名词必须使用以下写法，不要自创同音或近形写法）\n")
+	if len(realms) > 0 {
+		b.WriteString(fmt.Sprintf("- 境界（从低到高）: %s\n", strings.Join(realms, " → ")))
+	}
+	for _, t := range listed {
+		b.WriteString("- " + t.Term)
+		if len(t.Aliases) > 0 {
+			b.WriteString(fmt.Sprintf("（不要写作%s）", strings.Join(t.Aliases, "、")))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	return b.String()
+}
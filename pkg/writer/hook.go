@@ -0,0 +1,137 @@
+// Package writer 写作器 - 章节钩子评分
+// 评估章节开篇段落和章末悬念的好奇心、紧张感和悬念强度，标记需要重新生成的弱钩子
+package writer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+const (
+	// hookTitle 钩子评分提示词标题，同时作为模拟响应的标记
+	hookTitle = "章节钩子评分\n"
+	// hookExcerptRunes 开篇和章末各取的最多字数
+	hookExcerptRunes = 300
+)
+
+// hookScoreResponse 钩子评分的响应
+type hookScoreResponse struct {
+	Opening models.HookRating `json:"opening"`
+	Ending  models.HookRating `json:"ending"`
+}
+
+func init() {
+	llm.RegisterMock(hookTitle, hookScoreResponse{})
+}
+
+// HookScoreParams 钩子评分参数
+type HookScoreParams struct {
+	Chapter     int
+	Title       string
+	Prose       string // 章节正文
+	PlannedHook string // 章节规划中的结尾悬念（EndingHook），可为空
+}
+
+// ScoreHooks 为章节开篇段落和章末悬念评分
+func (w *Writer) ScoreHooks(params HookScoreParams) (*models.HookScore, error) {
+	opening, ending := HookExcerpts(params.Prose)
+	if opening == "" {
+		return nil, fmt.Errorf("第%d章没有正文", params.Chapter)
+	}
+
+	result, err := w.callForRole(roleHookScore, buildHookScorePrompt(params, opening, ending),
+		"你是一位网络小说责任编辑，熟悉读者的追读习惯，评分严格，不给客气分。只输出JSON。")
+	if err != nil {
+		return nil, fmt.Errorf("钩子评分失败: %w", err)
+	}
+
+	var out hookScoreResponse
+	if err := jsonx.Unmarshal(result, &out); err != nil {
+		return nil, fmt.Errorf("解析钩子评分结果失败: %w", err)
+	}
+
+	score := &models.HookScore{
+		Opening:  out.Opening,
+		Ending:   out.Ending,
+		ScoredAt: time.Now(),
+	}
+	score.Opening.Text = opening
+	score.Ending.Text = ending
+	score.Opening.Score()
+	score.Ending.Score()
+	return score, nil
+}
+
+// HookExcerpts 取正文的开篇段落和章末段落，各不超过 hookExcerptRunes 字
+func HookExcerpts(prose string) (string, string) {
+	paragraphs := make([]string, 0)
+	for _, p := range strings.Split(prose, "\n") {
+		if p = strings.TrimSpace(p); p != "" {
+			paragraphs = append(paragraphs, p)
+		}
+	}
+	if len(paragraphs) == 0 {
+		return "", ""
+	}
+
+	opening := []rune(paragraphs[0])
+	if len(opening) > hookExcerptRunes {
+		opening = opening[:hookExcerptRunes]
+	}
+
+	// 章末往前收集段落，直到接近字数上限
+	ending := ""
+	for i := len(paragraphs) - 1; i > 0; i-- {
+		candidate := paragraphs[i]
+		if ending != "" {
+			candidate += "\n" + ending
+		}
+		if len([]rune(candidate)) > hookExcerptRunes && ending != "" {
+			break
+		}
+		ending = candidate
+	}
+	if runes := []rune(ending); len(runes) > hookExcerptRunes {
+		ending = string(runes[len(runes)-hookExcerptRunes:])
+	}
+	return string(opening), ending
+}
+
+// buildHookScorePrompt 构建钩子评分提示词
+func buildHookScorePrompt(params HookScoreParams, opening, ending string) string {
+	var prompt strings.Builder
+
+	prompt.WriteString(fmt.Sprintf("# 第%d章《%s》%s\n", params.Chapter, params.Title, hookTitle))
+	prompt.WriteString(fmt.Sprintf("## 开篇段落\n%s\n\n", opening))
+	if ending != "" {
+		prompt.WriteString(fmt.Sprintf("## 章末段落\n%s\n\n", ending))
+	} else {
+		prompt.WriteString("## 章末段落\n（本章只有一段，章末钩子按开篇段落评估）\n\n")
+	}
+	if params.PlannedHook != "" {
+		prompt.WriteString(fmt.Sprintf("## 规划中的结尾悬念\n%s\n\n", params.PlannedHook))
+	}
+
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString("分别为开篇段落和章末段落打分（0-10的整数）：\n")
+	prompt.WriteString("1. curiosity 好奇心：是否抛出让读者想知道答案的问题\n")
+	prompt.WriteString("2. tension 紧张感：是否有迫在眉睫的危险、冲突或抉择\n")
+	prompt.WriteString("3. cliffhanger 悬念强度：开篇是否一句话抓住读者，章末是否停在非读下一章不可的位置\n")
+	prompt.WriteString("4. comment 一句话点评，suggestion 给出具体的改进方向\n")
+	if params.PlannedHook != "" {
+		prompt.WriteString("5. 章末没有兑现规划中的结尾悬念时，在 comment 中指出\n")
+	}
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "opening": {"curiosity": 7, "tension": 5, "cliffhanger": 6, "comment": "点评", "suggestion": "改进方向"},
+  "ending": {"curiosity": 8, "tension": 7, "cliffhanger": 9, "comment": "点评", "suggestion": "改进方向"}
+}`)
+
+	return prompt.String()
+}
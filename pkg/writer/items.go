@@ -0,0 +1,60 @@
+// Package writer 写作器 - 关键物品
+// 在场景提示词中列出与本场景相关的关键物品当前在谁手中，避免物品凭空转移
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// maxItemPromptHoldings 场景提示词中列出的物品上限
+const maxItemPromptHoldings = 15
+
+// BuildItemPrompt 构建场景提示词中的关键物品去向
+// 只列出出场角色持有的、放在本场景地点的，以及场景文字中提到的物品
+func BuildItemPrompt(holdings []models.ItemHolding, characters []string, location string, texts ...string) string {
+	if len(holdings) == 0 {
+		return ""
+	}
+	inScene := make(map[string]bool, len(characters))
+	for _, c := range characters {
+		inScene[c] = true
+	}
+	context := strings.Join(texts, "\n")
+
+	var sb strings.Builder
+	n := 0
+	for _, h := range holdings {
+		if n >= maxItemPromptHoldings {
+			break
+		}
+		relevant := (h.Holder != "" && inScene[h.Holder]) ||
+			(location != "" && h.Holder == "" && h.Location == location) ||
+			strings.Contains(context, h.Name)
+		if !relevant {
+			continue
+		}
+		sb.WriteString("- " + describeHolding(h) + "\n")
+		n++
+	}
+	if n == 0 {
+		return ""
+	}
+	return "## 关键物品\n" + sb.String() + "物品只能在持有人手中或所在地出现；要转手必须在正文中写出经过，已毁的物品不得再完好出现。\n\n"
+}
+
+// describeHolding 描述物品的去向
+func describeHolding(h models.ItemHolding) string {
+	switch {
+	case h.Destroyed:
+		return fmt.Sprintf("%s：已毁（第%d章）", h.Name, h.Since)
+	case h.Holder != "":
+		return fmt.Sprintf("%s：在%s手中", h.Name, h.Holder)
+	case h.Location != "":
+		return fmt.Sprintf("%s：在%s", h.Name, h.Location)
+	default:
+		return fmt.Sprintf("%s：下落不明", h.Name)
+	}
+}
@@ -0,0 +1,106 @@
+// Package writer 写作器 - 字数控制
+// 生成后检查字数，偏离目标超过容差时请求LLM扩写或精简
+package writer
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"unicode"
+)
+
+const (
+	// LengthTolerance 允许的字数偏差（±20%）
+	LengthTolerance = 0.2
+	// MaxLengthAdjustments 单个场景最多调整次数
+	MaxLengthAdjustments = 2
+)
+
+// CountWords 统计字数（不计空白字符）
+func CountWords(text string) int {
+	count := 0
+	for _, r := range text {
+		if !unicode.IsSpace(r) {
+			count++
+		}
+	}
+	return count
+}
+
+// LengthDeviation 实际字数相对目标的偏差比例，正数偏长、负数偏短
+func LengthDeviation(actual, target int) float64 {
+	if target <= 0 {
+		return 0
+	}
+	return float64(actual-target) / float64(target)
+}
+
+// WithinTolerance 字数是否在目标容差范围内
+func WithinTolerance(actual, target int) bool {
+	return math.Abs(LengthDeviation(actual, target)) <= LengthTolerance
+}
+
+// enforceLength 字数超出容差时循环请求扩写/精简，返回最终文本和调整次数
+// 调整失败时保留当前文本，不中断生成
+func (w *Writer) enforceLength(content string, target int, params GenerateParams) (string, int) {
+	if target <= 0 {
+		return content, 0
+	}
+
+	adjustments := 0
+	for adjustments < MaxLengthAdjustments {
+		actual := w.countWords(content)
+		if WithinTolerance(actual, target) {
+			break
+		}
+
+		prompt := buildLengthAdjustPrompt(content, actual, target)
+		result, err := w.callForRole(roleLengthAdjust, prompt, w.buildSystemPrompt(params.Style))
+		if err != nil {
+			break
+		}
+
+		var adjusted rewriteResponse
+		if err := json.Unmarshal([]byte(result), &adjusted); err != nil || strings.TrimSpace(adjusted.Content) == "" {
+			break
+		}
+
+		content = adjusted.Content
+		adjustments++
+	}
+
+	return content, adjustments
+}
+
+// buildLengthAdjustPrompt 构建扩写/精简提示词
+func buildLengthAdjustPrompt(content string, actual, target int) string {
+	var prompt strings.Builder
+
+	low := int(float64(target) * (1 - LengthTolerance))
+	high := int(float64(target) * (1 + LengthTolerance))
+
+	if actual < target {
+		prompt.WriteString("# 扩写任务\n\n")
+		prompt.WriteString(fmt.Sprintf("以下场景当前约 %d 字，目标 %d 字（允许范围 %d-%d 字），篇幅不足。\n\n", actual, target, low, high))
+		prompt.WriteString("请在不改变情节走向和人物行为的前提下扩写：\n")
+		prompt.WriteString("1. 补充感官细节和环境描写\n")
+		prompt.WriteString("2. 深化人物的内心活动和情绪变化\n")
+		prompt.WriteString("3. 让对话更充分，但不要注水或重复\n\n")
+	} else {
+		prompt.WriteString("# 精简任务\n\n")
+		prompt.WriteString(fmt.Sprintf("以下场景当前约 %d 字，目标 %d 字（允许范围 %d-%d 字），篇幅过长。\n\n", actual, target, low, high))
+		prompt.WriteString("请在保留全部关键情节、对话要点和伏笔的前提下精简：\n")
+		prompt.WriteString("1. 删去重复的描写和解释\n")
+		prompt.WriteString("2. 合并节奏拖沓的段落\n")
+		prompt.WriteString("3. 不要改变情节走向和人物行为\n\n")
+	}
+
+	prompt.WriteString("## 原文\n\n")
+	prompt.WriteString(content)
+	prompt.WriteString("\n\n# 输出格式（JSON）\n")
+	prompt.WriteString("{\n  \"content\": \"调整后的完整场景文本...\"\n}\n\n")
+	prompt.WriteString("只返回JSON，不要包含其他内容。")
+
+	return prompt.String()
+}
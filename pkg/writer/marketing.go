@@ -0,0 +1,200 @@
+// Package writer 写作器 - 营销文案
+// 根据完成的蓝图和开篇章节生成投稿平台需要的宣传语、简介、作品介绍和标签
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+const (
+	// marketingTitle 营销文案提示词标题，同时作为模拟响应的标记
+	marketingTitle = "作品营销文案\n"
+	// maxMarketingChapterRunes 每个开篇章节送入的最多字数
+	maxMarketingChapterRunes = 1500
+	// maxMarketingTags 标签数量上限
+	maxMarketingTags = 10
+)
+
+// marketingResponse 营销文案的响应
+type marketingResponse struct {
+	Tagline      string   `json:"tagline"`
+	Blurb        string   `json:"blurb"`
+	Introduction string   `json:"introduction"`
+	Tags         []string `json:"tags"`
+}
+
+func init() {
+	llm.RegisterMock(marketingTitle, marketingResponse{Tags: []string{}})
+}
+
+// MarketingParams 营销文案参数
+type MarketingParams struct {
+	Title     string
+	Genre     string
+	Blueprint *models.NarrativeBlueprint
+	Chapters  []string              // 开篇章节的摘要或正文
+	Fields    []string              // 要生成的项（models.MarketingFields 的子集），为空表示全部
+	Current   *models.MarketingCopy // 现有文案，未重新生成的项作为风格参考
+	Guidance  string                // 作者的修改意见
+}
+
+// GenerateMarketingCopy 生成营销文案，只返回请求的项，超出字数上限的在句末截断
+func (w *Writer) GenerateMarketingCopy(params MarketingParams) (*models.MarketingCopy, error) {
+	if len(params.Fields) == 0 {
+		params.Fields = models.MarketingFields
+	}
+	if params.Blueprint == nil && len(params.Chapters) == 0 {
+		return nil, fmt.Errorf("没有蓝图和章节，无法生成营销文案")
+	}
+
+	result, err := w.callForRole(roleMarketingCopy, buildMarketingPrompt(params),
+		"你是一位网络文学平台的资深运营编辑，擅长写让读者点进来、读下去的作品文案。文案必须忠于作品，不虚构作品里没有的设定。只输出JSON。")
+	if err != nil {
+		return nil, fmt.Errorf("生成营销文案失败: %w", err)
+	}
+
+	var out marketingResponse
+	if err := jsonx.Unmarshal(result, &out); err != nil {
+		return nil, fmt.Errorf("解析营销文案失败: %w", err)
+	}
+
+	generated := &models.MarketingCopy{}
+	for _, field := range params.Fields {
+		switch field {
+		case models.MarketingTagline:
+			generated.Tagline = clipSentence(out.Tagline, models.TaglineMaxRunes)
+		case models.MarketingBlurb:
+			generated.Blurb = clipSentence(out.Blurb, models.BlurbMaxRunes)
+		case models.MarketingIntroduction:
+			generated.Introduction = clipSentence(out.Introduction, models.IntroductionMaxRunes)
+		case models.MarketingTags:
+			generated.Tags = normalizeTags(out.Tags)
+		}
+	}
+	return generated, nil
+}
+
+// buildMarketingPrompt 构建营销文案提示词
+func buildMarketingPrompt(params MarketingParams) string {
+	var prompt strings.Builder
+
+	prompt.WriteString(fmt.Sprintf("# 《%s》%s", params.Title, marketingTitle))
+	if params.Genre != "" {
+		prompt.WriteString(fmt.Sprintf("类型：%s\n", params.Genre))
+	}
+
+	if bp := params.Blueprint; bp != nil {
+		outline := bp.StoryOutline
+		prompt.WriteString("\n## 故事大纲\n")
+		prompt.WriteString(fmt.Sprintf("开端：%s\n激励事件：%s\n", outline.Act1.Setup, outline.Act1.IncitingIncident))
+		prompt.WriteString(fmt.Sprintf("中点：%s\n至暗时刻：%s\n", outline.Act2.Midpoint, outline.Act2.AllIsLost))
+		prompt.WriteString(fmt.Sprintf("高潮：%s\n", outline.Act3.Climax))
+		if bp.ThemePlan.CoreTheme != "" {
+			prompt.WriteString(fmt.Sprintf("核心主题：%s\n", bp.ThemePlan.CoreTheme))
+		}
+	}
+
+	if len(params.Chapters) > 0 {
+		prompt.WriteString("\n## 开篇章节\n")
+		for i, ch := range params.Chapters {
+			runes := []rune(ch)
+			if len(runes) > maxMarketingChapterRunes {
+				runes = runes[:maxMarketingChapterRunes]
+			}
+			prompt.WriteString(fmt.Sprintf("### 第%d章\n%s\n\n", i+1, string(runes)))
+		}
+	}
+
+	if cur := params.Current; cur != nil {
+		kept := make([]string, 0, 3)
+		if cur.Tagline != "" && !containsField(params.Fields, models.MarketingTagline) {
+			kept = append(kept, "宣传语："+cur.Tagline)
+		}
+		if cur.Blurb != "" && !containsField(params.Fields, models.MarketingBlurb) {
+			kept = append(kept, "简介："+cur.Blurb)
+		}
+		if len(cur.Tags) > 0 && !containsField(params.Fields, models.MarketingTags) {
+			kept = append(kept, "标签："+strings.Join(cur.Tags, "、"))
+		}
+		if len(kept) > 0 {
+			prompt.WriteString("\n## 保留的现有文案（新文案与之风格一致、不要重复）\n")
+			prompt.WriteString(strings.Join(kept, "\n") + "\n")
+		}
+	}
+	if params.Guidance != "" {
+		prompt.WriteString("\n## 作者意见\n" + params.Guidance + "\n")
+	}
+
+	prompt.WriteString("\n# 要求\n")
+	n := 1
+	for _, field := range params.Fields {
+		switch field {
+		case models.MarketingTagline:
+			prompt.WriteString(fmt.Sprintf("%d. tagline 一句话宣传语，不超过%d字，点出主角处境和最大的看点\n", n, models.TaglineMaxRunes))
+		case models.MarketingBlurb:
+			prompt.WriteString(fmt.Sprintf("%d. blurb 平台简介，不超过%d字，交代主角、目标和阻碍，结尾留悬念\n", n, models.BlurbMaxRunes))
+		case models.MarketingIntroduction:
+			prompt.WriteString(fmt.Sprintf("%d. introduction 作品介绍，不超过%d字，可分段，展开世界观、主要角色和核心冲突，不剧透结局\n", n, models.IntroductionMaxRunes))
+		case models.MarketingTags:
+			prompt.WriteString(fmt.Sprintf("%d. tags 平台标签，5-%d个，每个2-6字，涵盖类型、题材、主角人设和看点\n", n, maxMarketingTags))
+		default:
+			continue
+		}
+		n++
+	}
+	prompt.WriteString(fmt.Sprintf("%d. 只输出要求的字段\n", n))
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{"tagline": "宣传语", "blurb": "简介", "introduction": "作品介绍", "tags": ["标签"]}`)
+
+	return prompt.String()
+}
+
+// clipSentence 超出字数上限时截断到上限内最后一个句末标点
+func clipSentence(text string, maxRunes int) string {
+	text = strings.TrimSpace(text)
+	runes := []rune(text)
+	if len(runes) <= maxRunes {
+		return text
+	}
+	runes = runes[:maxRunes]
+	for i := len(runes) - 1; i >= maxRunes/2; i-- {
+		switch runes[i] {
+		case '。', '！', '？', '…', '.', '!', '?':
+			return string(runes[:i+1])
+		}
+	}
+	return string(runes)
+}
+
+// normalizeTags 去掉空白、重复和 # 前缀，最多保留 maxMarketingTags 个
+func normalizeTags(tags []string) []string {
+	out := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, t := range tags {
+		t = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(t), "#"))
+		if t == "" || seen[t] {
+			continue
+		}
+		seen[t] = true
+		out = append(out, t)
+		if len(out) >= maxMarketingTags {
+			break
+		}
+	}
+	return out
+}
+
+func containsField(fields []string, field string) bool {
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,228 @@
+// Package writer 写作器 - 自我修订
+// 场景正文生成后由LLM审稿，对照场景细纲检查视角、必含元素、禁止透露的信息和氛围，
+// 发现违规时附上审稿意见自动重写，最多重写 MaxRevisions 轮
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// DefaultMaxRevisions 默认最多重写轮数
+const DefaultMaxRevisions = 2
+
+// 审稿规则
+const (
+	RulePOV           = "pov"             // 视角
+	RuleMustInclude   = "must_include"    // 必须包含的元素
+	RuleMustNotReveal = "must_not_reveal" // 绝对不能透露的信息
+	RuleAtmosphere    = "atmosphere"      // 氛围
+)
+
+// 演练模式下审稿默认通过，避免模拟的违规触发重写
+func init() {
+	llm.RegisterMock("# 场景审稿任务", SceneReview{Violations: []SceneViolation{}})
+}
+
+// SceneChecklist 场景审稿清单，来自场景细纲
+type SceneChecklist struct {
+	POVCharacter  string   `json:"pov_character"`
+	MustInclude   []string `json:"must_include,omitempty"`
+	MustNotReveal []string `json:"must_not_reveal,omitempty"`
+	Mood          string   `json:"mood,omitempty"`
+	Pacing        string   `json:"pacing,omitempty"`
+	SensoryFocus  []string `json:"sensory_focus,omitempty"`
+}
+
+// Empty 清单是否没有可检查的项目
+func (c SceneChecklist) Empty() bool {
+	return c.POVCharacter == "" && len(c.MustInclude) == 0 && len(c.MustNotReveal) == 0 &&
+		c.Mood == "" && c.Pacing == "" && len(c.SensoryFocus) == 0
+}
+
+// SceneViolation 审稿发现的违规
+type SceneViolation struct {
+	Rule     string `json:"rule"`               // pov/must_include/must_not_reveal/atmosphere
+	Detail   string `json:"detail"`             // 违规说明
+	Evidence string `json:"evidence,omitempty"` // 原文摘录
+	Fix      string `json:"fix,omitempty"`      // 修改建议
+}
+
+// SceneReview 审稿结果
+type SceneReview struct {
+	Passed     bool             `json:"passed"`
+	Violations []SceneViolation `json:"violations"`
+}
+
+// Critique 将违规格式化为重写提示中的审稿意见
+func (r *SceneReview) Critique() string {
+	var sb strings.Builder
+	for i, v := range r.Violations {
+		sb.WriteString(fmt.Sprintf("%d. [%s] %s\n", i+1, ruleLabel(v.Rule), v.Detail))
+		if v.Evidence != "" {
+			sb.WriteString(fmt.Sprintf("   原文：%s\n", v.Evidence))
+		}
+		if v.Fix != "" {
+			sb.WriteString(fmt.Sprintf("   建议：%s\n", v.Fix))
+		}
+	}
+	return sb.String()
+}
+
+// ReviewScene 对照审稿清单检查场景正文
+func (w *Writer) ReviewScene(content string, checklist SceneChecklist) (*SceneReview, error) {
+	result, err := w.callForRole(roleSceneReview, buildSceneReviewPrompt(content, checklist),
+		"你是一位严格的小说审稿编辑，只依据给定的场景要求检查正文，不评价文笔好坏。只输出JSON。")
+	if err != nil {
+		return nil, fmt.Errorf("审稿失败: %w", err)
+	}
+
+	review := &SceneReview{}
+	if err := jsonx.Unmarshal(result, review); err != nil {
+		return nil, fmt.Errorf("解析审稿结果失败: %w", err)
+	}
+	// 以违规列表为准，避免审稿员给出自相矛盾的结论
+	review.Passed = len(review.Violations) == 0
+	return review, nil
+}
+
+// reviseScene 审稿并在发现违规时重写，返回最终文本、重写轮数和仍未解决的违规
+// 审稿或重写失败时保留当前文本，不中断生成
+func (w *Writer) reviseScene(content string, params GenerateParams) (string, int, []SceneViolation) {
+	if params.Checklist == nil || params.Checklist.Empty() || params.MaxRevisions <= 0 {
+		return content, 0, nil
+	}
+
+	revisions := 0
+	for {
+		review, err := w.ReviewScene(content, *params.Checklist)
+		if err != nil {
+			w.log().Warn("场景审稿失败，跳过修订", "chapter", params.Chapter, "scene", params.Scene, "error", err)
+			return content, revisions, nil
+		}
+		if review.Passed {
+			return content, revisions, nil
+		}
+		if revisions >= params.MaxRevisions {
+			return content, revisions, review.Violations
+		}
+
+		w.log().Info("场景修订重写", "chapter", params.Chapter, "scene", params.Scene, "round", revisions+1, "violations", len(review.Violations))
+		result, err := w.callForRole(roleSceneRevision, buildSceneRevisionPrompt(content, *params.Checklist, review), w.buildSystemPrompt(params.Style))
+		if err != nil {
+			return content, revisions, review.Violations
+		}
+
+		var revised rewriteResponse
+		if err := jsonx.Unmarshal(result, &revised); err != nil || strings.TrimSpace(revised.Content) == "" {
+			return content, revisions, review.Violations
+		}
+
+		content = revised.Content
+		revisions++
+	}
+}
+
+// buildSceneReviewPrompt 构建审稿提示词
+func buildSceneReviewPrompt(content string, checklist SceneChecklist) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("# 场景审稿任务\n\n")
+	prompt.WriteString("## 场景要求\n")
+	prompt.WriteString(formatChecklist(checklist))
+
+	prompt.WriteString(fmt.Sprintf("\n## 场景正文\n%s\n\n", content))
+
+	prompt.WriteString("# 检查项\n")
+	if checklist.POVCharacter != "" {
+		prompt.WriteString(fmt.Sprintf("- pov：全文是否始终限定在%s的视角，没有写出%s无法感知的他人内心或场景\n", checklist.POVCharacter, checklist.POVCharacter))
+	}
+	if len(checklist.MustInclude) > 0 {
+		prompt.WriteString("- must_include：每个必须包含的元素是否都在正文中实际出现（逐项检查，缺一项记一处违规）\n")
+	}
+	if len(checklist.MustNotReveal) > 0 {
+		prompt.WriteString("- must_not_reveal：正文是否直接或通过明显暗示透露了禁止透露的信息\n")
+	}
+	if checklist.Mood != "" || checklist.Pacing != "" || len(checklist.SensoryFocus) > 0 {
+		prompt.WriteString("- atmosphere：情绪基调、节奏和感官侧重是否与要求一致\n")
+	}
+	prompt.WriteString("\n没有违规时 violations 为空数组。只报告确实存在的违规，不要吹毛求疵。\n\n")
+
+	prompt.WriteString("# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "violations": [
+    {
+      "rule": "pov/must_include/must_not_reveal/atmosphere",
+      "detail": "违规说明",
+      "evidence": "原文摘录（缺失类违规留空）",
+      "fix": "修改建议"
+    }
+  ]
+}`)
+
+	return prompt.String()
+}
+
+// buildSceneRevisionPrompt 构建附带审稿意见的重写提示词
+func buildSceneRevisionPrompt(content string, checklist SceneChecklist, review *SceneReview) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("# 场景修订任务\n\n")
+	prompt.WriteString("## 场景要求\n")
+	prompt.WriteString(formatChecklist(checklist))
+	prompt.WriteString(fmt.Sprintf("\n## 审稿意见\n%s\n", review.Critique()))
+	prompt.WriteString(fmt.Sprintf("## 原文\n%s\n\n", content))
+
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString("1. 逐条解决审稿意见中的问题\n")
+	prompt.WriteString("2. 没有问题的段落尽量保持原样，不要改变情节走向\n")
+	prompt.WriteString("3. 字数与原文大致相当\n\n")
+
+	prompt.WriteString("# 输出格式（JSON）\n")
+	prompt.WriteString(`{"content": "修订后的场景正文"}`)
+
+	return prompt.String()
+}
+
+// formatChecklist 格式化审稿清单
+func formatChecklist(c SceneChecklist) string {
+	var sb strings.Builder
+	if c.POVCharacter != "" {
+		sb.WriteString(fmt.Sprintf("- 视角角色：%s\n", c.POVCharacter))
+	}
+	if len(c.MustInclude) > 0 {
+		sb.WriteString(fmt.Sprintf("- 必须包含：%s\n", strings.Join(c.MustInclude, "；")))
+	}
+	if len(c.MustNotReveal) > 0 {
+		sb.WriteString(fmt.Sprintf("- 禁止透露：%s\n", strings.Join(c.MustNotReveal, "；")))
+	}
+	if c.Mood != "" {
+		sb.WriteString(fmt.Sprintf("- 情绪基调：%s\n", c.Mood))
+	}
+	if c.Pacing != "" {
+		sb.WriteString(fmt.Sprintf("- 节奏：%s\n", c.Pacing))
+	}
+	if len(c.SensoryFocus) > 0 {
+		sb.WriteString(fmt.Sprintf("- 感官侧重：%s\n", strings.Join(c.SensoryFocus, "、")))
+	}
+	return sb.String()
+}
+
+// ruleLabel 审稿规则的中文标签
+func ruleLabel(rule string) string {
+	switch rule {
+	case RulePOV:
+		return "视角"
+	case RuleMustInclude:
+		return "必含元素"
+	case RuleMustNotReveal:
+		return "禁止透露"
+	case RuleAtmosphere:
+		return "氛围"
+	default:
+		return rule
+	}
+}
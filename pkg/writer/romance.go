@@ -0,0 +1,43 @@
+// Package writer 写作器 - 感情线
+// 两位感情线角色同场时，在场景提示词中写明本章要完成的节拍和尚不能发生的节拍
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/romance"
+)
+
+// BuildRomancePrompt 构建场景提示词中的感情线要求，出场角色中没有感情线的一对时返回空
+func BuildRomancePrompt(arcs []models.RomanceArc, chapter int, characters []string) string {
+	directives := romance.ChapterDirectives(arcs, chapter, characters)
+	if len(directives) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	sb.WriteString("## 感情线\n")
+	for _, d := range directives {
+		sb.WriteString(fmt.Sprintf("- %s", d.Arc.Pair()))
+		if d.Arc.Dynamic != "" {
+			sb.WriteString(fmt.Sprintf("（%s）", d.Arc.Dynamic))
+		}
+		sb.WriteString("\n")
+		if n := len(d.Reached); n > 0 {
+			last := d.Reached[n-1]
+			sb.WriteString(fmt.Sprintf("  目前进展：已经历%s（第%d章）\n", models.RomanceBeatNames[last.Type], last.Chapter))
+		} else {
+			sb.WriteString("  目前进展：尚未正式产生感情交集\n")
+		}
+		for _, b := range d.Current {
+			sb.WriteString(fmt.Sprintf("  本章节拍：%s——%s\n", models.RomanceBeatNames[b.Type], b.Description))
+		}
+		if d.Next != nil && len(d.Current) == 0 {
+			sb.WriteString(fmt.Sprintf("  下一节拍%s安排在第%d章，本场景不得提前发生，可以铺垫\n", models.RomanceBeatNames[d.Next.Type], d.Next.Chapter))
+		}
+	}
+	sb.WriteString("感情的进退要与以上节拍一致，不要越过尚未到来的节拍。\n\n")
+	return sb.String()
+}
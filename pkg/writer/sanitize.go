@@ -0,0 +1,114 @@
+// Package writer 写作器 - 合规改写
+// 只改写含违规内容的段落，保留情节和人物行为，去掉平台不允许的词语和描写
+package writer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// sanitizeTitle 合规改写提示词标题，同时作为模拟响应的标记
+const sanitizeTitle = "合规改写\n"
+
+// SanitizedParagraph 改写后的段落
+type SanitizedParagraph struct {
+	Paragraph int    `json:"paragraph"`
+	Content   string `json:"content"`
+}
+
+// sanitizeResponse 合规改写的响应
+type sanitizeResponse struct {
+	Paragraphs []SanitizedParagraph `json:"paragraphs"`
+}
+
+func init() {
+	llm.RegisterMock(sanitizeTitle, sanitizeResponse{})
+}
+
+// SanitizeParams 合规改写参数
+type SanitizeParams struct {
+	Chapter    int
+	Paragraphs []string // 章节的全部段落，序号从1开始对应
+	Violations []models.ComplianceViolation
+}
+
+// SanitizeParagraphs 改写有违规的段落，返回 段落序号 -> 改写后的内容
+func (w *Writer) SanitizeParagraphs(params SanitizeParams) (map[int]string, error) {
+	if len(params.Violations) == 0 {
+		return map[int]string{}, nil
+	}
+
+	result, err := w.callForRole(roleSanitize, buildSanitizePrompt(params),
+		"你是一位网络文学平台的内容审核编辑，熟悉平台规则。改写时尽量少动原文，保持文风和情节不变。只输出JSON。")
+	if err != nil {
+		return nil, fmt.Errorf("合规改写失败: %w", err)
+	}
+
+	var out sanitizeResponse
+	if err := jsonx.Unmarshal(result, &out); err != nil {
+		return nil, fmt.Errorf("解析合规改写结果失败: %w", err)
+	}
+
+	flagged := make(map[int]bool, len(params.Violations))
+	for _, v := range params.Violations {
+		flagged[v.Paragraph] = true
+	}
+	rewrites := make(map[int]string, len(out.Paragraphs))
+	for _, p := range out.Paragraphs {
+		// 只接受有违规的段落，避免改动其他内容
+		if flagged[p.Paragraph] && strings.TrimSpace(p.Content) != "" {
+			rewrites[p.Paragraph] = p.Content
+		}
+	}
+	return rewrites, nil
+}
+
+// buildSanitizePrompt 构建合规改写提示词
+func buildSanitizePrompt(params SanitizeParams) string {
+	var prompt strings.Builder
+
+	prompt.WriteString(fmt.Sprintf("# 第%d章%s", params.Chapter, sanitizeTitle))
+
+	byParagraph := make(map[int][]models.ComplianceViolation)
+	for _, v := range params.Violations {
+		byParagraph[v.Paragraph] = append(byParagraph[v.Paragraph], v)
+	}
+	numbers := make([]int, 0, len(byParagraph))
+	for n := range byParagraph {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+
+	prompt.WriteString("## 需要改写的段落\n")
+	for _, n := range numbers {
+		if n < 1 || n > len(params.Paragraphs) {
+			continue
+		}
+		issues := make([]string, 0, len(byParagraph[n]))
+		for _, v := range byParagraph[n] {
+			issues = append(issues, fmt.Sprintf("%s「%s」", v.Category, v.Term))
+		}
+		if n > 1 {
+			prompt.WriteString(fmt.Sprintf("（上文）%s\n", params.Paragraphs[n-2]))
+		}
+		prompt.WriteString(fmt.Sprintf("[%d] %s\n", n, params.Paragraphs[n-1]))
+		prompt.WriteString(fmt.Sprintf("问题: %s\n\n", strings.Join(issues, "、")))
+	}
+
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString("1. 逐段改写上面编号的段落，去掉问题中列出的词语和相应的描写，（上文）只用于理解语境，不要改写\n")
+	prompt.WriteString("2. 用含蓄、侧面或省略的写法替代，保留情节推进和人物的行为结果\n")
+	prompt.WriteString("3. 不得换成同义的违规表达，不要增加原文没有的情节\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "paragraphs": [{"paragraph": 3, "content": "改写后的段落"}]
+}`)
+
+	return prompt.String()
+}
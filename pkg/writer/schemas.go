@@ -0,0 +1,67 @@
+// Package writer 写作器 - 响应结构校验
+// 各提示词角色的 JSON Schema，由LLM客户端校验响应，不通过时自动请求修复
+package writer
+
+import (
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// 提示词角色
+const (
+	roleScene          = "writer.scene"
+	roleSceneReview    = "writer.scene_review"
+	roleSceneRevision  = "writer.scene_revision"
+	roleLengthAdjust   = "writer.length_adjust"
+	roleChapterSummary = "writer.chapter_summary"
+	roleVoiceCheck     = "writer.voice_check"
+	roleHookScore      = "writer.hook_score"
+	roleTropeScan      = "writer.trope_scan"
+	roleTropeRewrite   = "writer.trope_rewrite"
+	roleSanitize       = "writer.sanitize"
+	roleTranslate      = "writer.translate"
+	roleChapterTitle   = "writer.chapter_title"
+	roleMarketingCopy  = "writer.marketing_copy"
+	roleContinuity     = "writer.continuity"
+	roleCombat         = "writer.combat"
+	roleTheme          = "writer.theme"
+	roleChapterDraft   = "writer.chapter_draft"
+	roleChapterRevise  = "writer.chapter_revise"
+	roleChapterPolish  = "writer.chapter_polish"
+	roleEditSuggestion = "writer.edit_suggestion"
+)
+
+// rewriteResponse 重写类任务（修订、扩写/精简）的响应
+type rewriteResponse struct {
+	Content string `json:"content"`
+}
+
+func init() {
+	// 审校、摘要、评分类角色，省钱模式下改用便宜模型
+	llm.MarkNonCritical(roleSceneReview, roleChapterSummary, roleVoiceCheck, roleHookScore, roleTropeScan, roleContinuity)
+
+	llm.RegisterSchema(roleScene, llm.SchemaOf(GeneratedScene{}).Require("content"))
+	llm.RegisterSchema(roleSceneReview, llm.SchemaOf(SceneReview{}).
+		Require("violations").
+		OneOf("violations.*.rule", RulePOV, RuleMustInclude, RuleMustNotReveal, RuleAtmosphere))
+	llm.RegisterSchema(roleSceneRevision, llm.SchemaOf(rewriteResponse{}).Require("content"))
+	llm.RegisterSchema(roleLengthAdjust, llm.SchemaOf(rewriteResponse{}).Require("content"))
+	llm.RegisterSchema(roleChapterSummary, llm.SchemaOf(ChapterSummary{}).Require("summary"))
+	llm.RegisterSchema(roleVoiceCheck, llm.SchemaOf(voiceCheckResponse{}).Require("issues"))
+	llm.RegisterSchema(roleHookScore, llm.SchemaOf(hookScoreResponse{}).Require("opening", "ending"))
+	llm.RegisterSchema(roleTropeScan, llm.SchemaOf(tropeScanResponse{}).Require("occurrences"))
+	llm.RegisterSchema(roleTropeRewrite, llm.SchemaOf(tropeRewriteResponse{}).Require("suggestions"))
+	llm.RegisterSchema(roleSanitize, llm.SchemaOf(sanitizeResponse{}).Require("paragraphs"))
+	llm.RegisterSchema(roleTranslate, llm.SchemaOf(translateResponse{}).Require("paragraphs"))
+	llm.RegisterSchema(roleChapterTitle, llm.SchemaOf(titleResponse{}).Require("candidates"))
+	llm.RegisterSchema(roleMarketingCopy, llm.SchemaOf(marketingResponse{}))
+	llm.RegisterSchema(roleContinuity, llm.SchemaOf(continuityResponse{}).Require("facts", "conflicts"))
+	llm.RegisterSchema(roleCombat, llm.SchemaOf(models.CombatChoreography{}).Require("beats"))
+	llm.RegisterSchema(roleChapterDraft, llm.SchemaOf(rewriteResponse{}).Require("content"))
+	llm.RegisterSchema(roleChapterRevise, llm.SchemaOf(chapterRevisionResponse{}).Require("content"))
+	llm.RegisterSchema(roleChapterPolish, llm.SchemaOf(rewriteResponse{}).Require("content"))
+	llm.RegisterSchema(roleEditSuggestion, llm.SchemaOf(editSuggestionResponse{}).Require("content"))
+	llm.RegisterSchema(roleTheme, llm.SchemaOf(themeResponse{}).
+		Require("depth").
+		OneOf("depth", models.ThemeDepthNone, models.ThemeDepthSurface, models.ThemeDepthDeep, models.ThemeDepthPhilosophical))
+}
@@ -0,0 +1,127 @@
+// Package writer 写作器 - 句子级差异
+// 把选段改写前后的文本按句切分后对齐，得到可逐句采纳或拒绝的修改
+package writer
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// SplitSentences 按句切分文本，句末标点、紧随的引号括号和空白归入前一句，各段拼接后与原文完全一致
+func SplitSentences(text string) []string {
+	runes := []rune(text)
+	var sentences []string
+	start := 0
+	for i := 0; i < len(runes); i++ {
+		if !sentenceEnd(runes, i) {
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && (sentenceEnd(runes, j) || closingMark(runes[j])) {
+			j++
+		}
+		for j < len(runes) && unicode.IsSpace(runes[j]) {
+			j++
+		}
+		sentences = append(sentences, string(runes[start:j]))
+		start = j
+		i = j - 1
+	}
+	if start < len(runes) {
+		sentences = append(sentences, string(runes[start:]))
+	}
+	return sentences
+}
+
+// sentenceEnd 第 i 个字符是否为句末：中文句末标点、换行，或后跟空白的英文句点
+func sentenceEnd(runes []rune, i int) bool {
+	switch runes[i] {
+	case '。', '！', '？', '!', '?', '…', '\n':
+		return true
+	case '.':
+		return i+1 == len(runes) || unicode.IsSpace(runes[i+1])
+	}
+	return false
+}
+
+// closingMark 句末标点后紧随的后引号和后括号
+func closingMark(r rune) bool {
+	return strings.ContainsRune("”’」』）)》\"'", r)
+}
+
+// DiffSentences 对齐改写前后的句子，连续的删除和新增逐句配成改写，每处改写可单独采纳或拒绝
+func DiffSentences(original, revised string) []models.EditHunk {
+	a, b := SplitSentences(original), SplitSentences(revised)
+
+	// 最长公共子序列，句子比较时忽略首尾空白
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if strings.TrimSpace(a[i]) == strings.TrimSpace(b[j]) {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	var hunks []models.EditHunk
+	var deleted, inserted []string
+	flush := func() {
+		n := max(len(deleted), len(inserted))
+		for k := 0; k < n; k++ {
+			h := models.EditHunk{Op: models.HunkReplace}
+			switch {
+			case k >= len(deleted):
+				h.Op, h.Revised = models.HunkInsert, inserted[k]
+			case k >= len(inserted):
+				h.Op, h.Original = models.HunkDelete, deleted[k]
+			default:
+				h.Original, h.Revised = deleted[k], inserted[k]
+			}
+			hunks = append(hunks, h)
+		}
+		deleted, inserted = nil, nil
+	}
+
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && strings.TrimSpace(a[i]) == strings.TrimSpace(b[j]):
+			flush()
+			hunks = append(hunks, models.EditHunk{Op: models.HunkEqual, Original: a[i], Revised: b[j]})
+			i++
+			j++
+		case j == len(b) || (i < len(a) && lcs[i+1][j] >= lcs[i][j+1]):
+			deleted = append(deleted, a[i])
+			i++
+		default:
+			inserted = append(inserted, b[j])
+			j++
+		}
+	}
+	flush()
+
+	for k := range hunks {
+		hunks[k].Index = k
+	}
+	return hunks
+}
+
+// MergeHunks 按各处修改的处理结果拼出最终文本：采纳的用改写后的句子，其余保留原文
+func MergeHunks(hunks []models.EditHunk) string {
+	var sb strings.Builder
+	for _, h := range hunks {
+		if h.Changed() && h.Decision == models.HunkAccepted {
+			sb.WriteString(h.Revised)
+		} else {
+			sb.WriteString(h.Original)
+		}
+	}
+	return sb.String()
+}
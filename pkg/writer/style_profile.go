@@ -0,0 +1,168 @@
+// Package writer 写作风格档案
+// 内置风格预设，以及将风格档案转换为提示词
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/db"
+)
+
+// BuiltinStyleProfiles 内置风格预设（只读）
+var BuiltinStyleProfiles = []*models.StyleProfile{
+	{
+		ID:                "builtin_shuangwen",
+		Name:              "网文爽文",
+		Description:       "节奏明快、爽点密集的网络小说风格",
+		NarrativeDistance: "close",
+		SentenceRhythm:    "短句为主，段落短小，每段推进一个动作或情绪，爽点前压抑、爽点时爆发",
+		VocabularyLevel:   "simple",
+		Tense:             "past",
+		POVConvention:     "第三人称限制视角，紧跟主角，配角视角只在打脸、震惊等反应时短暂切入",
+		BannedPhrases:     []string{"不由得", "与此同时", "眼中闪过一丝", "嘴角勾起一抹弧度", "倒吸一口凉气"},
+		Builtin:           true,
+	},
+	{
+		ID:                "builtin_wenqing",
+		Name:              "文青",
+		Description:       "重意象与留白、注重内心的文学风格",
+		NarrativeDistance: "close",
+		SentenceRhythm:    "长短句交错，注重停顿和留白，用意象承载情绪而非直接点明",
+		VocabularyLevel:   "sophisticated",
+		Tense:             "past",
+		POVConvention:     "第一人称或第三人称限制视角，深入人物内心，避免全知叙述",
+		BannedPhrases:     []string{"心中五味杂陈", "泪水夺眶而出", "仿佛整个世界都安静了", "命运的齿轮开始转动"},
+		Builtin:           true,
+	},
+	{
+		ID:                "builtin_hard_scifi",
+		Name:              "硬科幻",
+		Description:       "技术细节严谨、叙述克制的硬科幻风格",
+		NarrativeDistance: "medium",
+		SentenceRhythm:    "叙述平稳精确，技术说明与情节交替，关键发现处放慢节奏",
+		VocabularyLevel:   "sophisticated",
+		Tense:             "past",
+		POVConvention:     "第三人称，可按章节切换视角人物，同一场景内不跳视角",
+		BannedPhrases:     []string{"黑科技", "不可思议的力量", "神秘的能量", "科学无法解释"},
+		Builtin:           true,
+	},
+	{
+		ID:                "builtin_suspense",
+		Name:              "悬疑",
+		Description:       "严格控制信息、张力持续的悬疑风格",
+		NarrativeDistance: "close",
+		SentenceRhythm:    "短句制造紧张，线索处放慢、细写，场景结尾留下疑问",
+		VocabularyLevel:   "moderate",
+		Tense:             "past",
+		POVConvention:     "第三人称限制视角，读者只知道视角人物知道的信息，不得提前泄露真相",
+		BannedPhrases:     []string{"他不知道的是", "殊不知", "后来他才明白", "这将是他最后一次"},
+		Builtin:           true,
+	},
+}
+
+// BuiltinStyleProfile 按ID获取内置风格预设
+func BuiltinStyleProfile(id string) (*models.StyleProfile, bool) {
+	for _, profile := range BuiltinStyleProfiles {
+		if profile.ID == id {
+			return profile, true
+		}
+	}
+	return nil, false
+}
+
+// ResolveStyleProfile 解析风格档案：内置预设优先，其次数据库；id为空时返回nil
+func ResolveStyleProfile(database db.Database, id string) (*models.StyleProfile, error) {
+	if id == "" {
+		return nil, nil
+	}
+	if profile, ok := BuiltinStyleProfile(id); ok {
+		return profile, nil
+	}
+	profile, err := database.GetStyleProfile(id)
+	if err != nil {
+		return nil, fmt.Errorf("风格档案不存在: %s", id)
+	}
+	return profile, nil
+}
+
+// BuildStyleProfilePrompt 构建风格档案提示词片段
+func BuildStyleProfilePrompt(profile *models.StyleProfile) string {
+	if profile == nil {
+		return ""
+	}
+
+	var prompt strings.Builder
+	prompt.WriteString(fmt.Sprintf("## 风格档案：%s\n", profile.Name))
+	if profile.NarrativeDistance != "" {
+		prompt.WriteString(fmt.Sprintf("- 叙事距离: %s\n", distanceDescription(profile.NarrativeDistance)))
+	}
+	if profile.SentenceRhythm != "" {
+		prompt.WriteString(fmt.Sprintf("- 句子节奏: %s\n", profile.SentenceRhythm))
+	}
+	if profile.VocabularyLevel != "" {
+		prompt.WriteString(fmt.Sprintf("- 词汇水平: %s\n", vocabularyDescription(profile.VocabularyLevel)))
+	}
+	if profile.Tense != "" {
+		prompt.WriteString(fmt.Sprintf("- 时态: %s\n", tenseDescription(profile.Tense)))
+	}
+	if profile.POVConvention != "" {
+		prompt.WriteString(fmt.Sprintf("- 视角约定: %s\n", profile.POVConvention))
+	}
+	if len(profile.BannedPhrases) > 0 {
+		prompt.WriteString(fmt.Sprintf("- 禁用表达（不得出现）: %s\n", strings.Join(profile.BannedPhrases, "、")))
+	}
+	prompt.WriteString("\n")
+
+	return prompt.String()
+}
+
+// FindBannedPhrases 返回文本中出现的禁用表达
+func FindBannedPhrases(text string, phrases []string) []string {
+	found := make([]string, 0)
+	for _, phrase := range phrases {
+		if phrase != "" && strings.Contains(text, phrase) {
+			found = append(found, phrase)
+		}
+	}
+	return found
+}
+
+// distanceDescription 叙事距离描述
+func distanceDescription(distance string) string {
+	descriptions := map[string]string{
+		"close":   "近距离 - 贴近人物感官和内心",
+		"medium":  "中距离 - 内外兼顾",
+		"distant": "远距离 - 冷静旁观",
+	}
+	if desc, ok := descriptions[distance]; ok {
+		return desc
+	}
+	return distance
+}
+
+// vocabularyDescription 词汇水平描述
+func vocabularyDescription(level string) string {
+	descriptions := map[string]string{
+		"simple":        "通俗 - 口语化，易读",
+		"moderate":      "适中 - 书面但不晦涩",
+		"sophisticated": "考究 - 用词精炼讲究",
+	}
+	if desc, ok := descriptions[level]; ok {
+		return desc
+	}
+	return level
+}
+
+// tenseDescription 时态描述
+func tenseDescription(tense string) string {
+	descriptions := map[string]string{
+		"past":    "过去时叙述",
+		"present": "现在时叙述",
+	}
+	if desc, ok := descriptions[tense]; ok {
+		return desc
+	}
+	return tense
+}
@@ -0,0 +1,205 @@
+// Package writer 写作器 - 章节摘要
+// 每章正文写完后由LLM生成本章摘要、滚动梗概和角色状态变化，作为下一章的前情
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+)
+
+const (
+	// RollingSummaryLength 滚动梗概的目标字数
+	RollingSummaryLength = 200
+	// maxSummaryProse 送入摘要的正文上限（字），超出时保留开头和结尾
+	maxSummaryProse = 12000
+)
+
+// ChapterSummaryParams 章节摘要参数
+type ChapterSummaryParams struct {
+	Chapter         int
+	Title           string
+	Prose           string                    // 本章正文
+	PreviousSummary string                    // 上一章的滚动梗概
+	Deceased        []string                  // 此前已故的角色，用于发现没有交代就出场的情况
+	Items           []models.ItemHolding      // 本章开始时关键物品的去向，用于记录转手
+	Cultivation     *models.CultivationSystem // 世界修真体系，为空时不提取境界变化
+	Realms          []models.RealmState       // 本章开始时各角色的境界
+}
+
+// ChapterSummary 章节摘要结果
+type ChapterSummary struct {
+	Summary        string                       `json:"summary"`         // 本章摘要
+	RollingSummary string                       `json:"rolling_summary"` // 截至本章的故事梗概
+	StateDeltas    []models.CharacterStateDelta `json:"state_deltas"`    // 角色状态变化
+	VitalEvents    []VitalChange                `json:"vital_events"`    // 死亡、重伤、伤愈、复活
+	ItemTransfers  []ItemChange                 `json:"item_transfers"`  // 关键物品的去向变化
+	RealmChanges   []RealmChange                `json:"realm_changes"`   // 修为境界变化
+}
+
+// VitalChange 本章发生的生死或重伤变化
+type VitalChange struct {
+	Character string                `json:"character"`
+	Event     models.VitalEventType `json:"event"` // death/injury/recovery/resurrection/reappeared
+	Detail    string                `json:"detail"`
+	Evidence  string                `json:"evidence"`
+}
+
+// ItemChange 本章中关键物品的去向变化
+type ItemChange struct {
+	Item      string `json:"item"`
+	From      string `json:"from"`
+	To        string `json:"to"`       // 新持有人，空表示不在任何人手中
+	Location  string `json:"location"` // 不在人手中时的所在地
+	Note      string `json:"note"`
+	Destroyed bool   `json:"destroyed"`
+	Evidence  string `json:"evidence"`
+}
+
+// RealmChange 本章中角色的境界变化
+type RealmChange struct {
+	Character string                `json:"character"`
+	Kind      models.RealmEventKind `json:"kind"` // initial/breakthrough/regression
+	Realm     string                `json:"realm"`
+	Cost      string                `json:"cost"` // 破除瓶颈的经过、借助的资源或付出的代价
+	Evidence  string                `json:"evidence"`
+}
+
+// SummarizeChapter 根据本章正文生成摘要和角色状态变化
+func (w *Writer) SummarizeChapter(params ChapterSummaryParams) (*ChapterSummary, error) {
+	if strings.TrimSpace(params.Prose) == "" {
+		return nil, fmt.Errorf("第%d章没有正文", params.Chapter)
+	}
+
+	result, err := w.callForRole(roleChapterSummary, buildChapterSummaryPrompt(params), "你是一位严谨的小说编辑，擅长忠实、简洁地概括情节，不添加原文没有的内容。")
+	if err != nil {
+		return nil, fmt.Errorf("生成章节摘要失败: %w", err)
+	}
+
+	summary := &ChapterSummary{}
+	if err := jsonx.Unmarshal(result, summary); err != nil {
+		return nil, fmt.Errorf("解析章节摘要失败: %w", err)
+	}
+	if summary.RollingSummary == "" {
+		summary.RollingSummary = summary.Summary
+	}
+	return summary, nil
+}
+
+// FormatPreviousContext 将滚动梗概和角色状态变化格式化为下一章的前情摘要
+func FormatPreviousContext(rollingSummary string, deltas []models.CharacterStateDelta) string {
+	var sb strings.Builder
+	sb.WriteString(rollingSummary)
+
+	if len(deltas) > 0 {
+		sb.WriteString("\n上一章结束时的角色状态：\n")
+		for _, d := range deltas {
+			parts := make([]string, 0, 5)
+			if d.Location != "" {
+				parts = append(parts, "位于"+d.Location)
+			}
+			if d.Emotion != "" {
+				parts = append(parts, "情绪"+d.Emotion)
+			}
+			if len(d.Learned) > 0 {
+				parts = append(parts, "已得知"+strings.Join(d.Learned, "、"))
+			}
+			if d.Relationship != "" {
+				parts = append(parts, d.Relationship)
+			}
+			if d.Change != "" {
+				parts = append(parts, d.Change)
+			}
+			sb.WriteString(fmt.Sprintf("- %s：%s\n", d.Character, strings.Join(parts, "；")))
+		}
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+// buildChapterSummaryPrompt 构建章节摘要提示词
+func buildChapterSummaryPrompt(params ChapterSummaryParams) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("# 章节摘要任务\n\n")
+	if params.PreviousSummary != "" {
+		prompt.WriteString(fmt.Sprintf("## 此前梗概\n%s\n\n", params.PreviousSummary))
+	}
+	if len(params.Deceased) > 0 {
+		prompt.WriteString(fmt.Sprintf("## 已故角色\n%s\n\n", strings.Join(params.Deceased, "、")))
+	}
+	if len(params.Items) > 0 {
+		prompt.WriteString("## 关键物品（本章开始时）\n")
+		for _, h := range params.Items {
+			prompt.WriteString("- " + describeHolding(h) + "\n")
+		}
+		prompt.WriteString("\n")
+	}
+	if c := params.Cultivation; c != nil {
+		prompt.WriteString(fmt.Sprintf("## 修真体系\n境界：%s\n", strings.Join(c.Realms, "→")))
+		if c.Bottleneck != "" {
+			prompt.WriteString(fmt.Sprintf("瓶颈：%s\n", c.Bottleneck))
+		}
+		if c.ResourceSystem != "" {
+			prompt.WriteString(fmt.Sprintf("资源：%s\n", c.ResourceSystem))
+		}
+		for _, s := range params.Realms {
+			prompt.WriteString(fmt.Sprintf("- %s：%s（第%d章）\n", s.Character, s.Realm, s.Since))
+		}
+		prompt.WriteString("\n")
+	}
+	prompt.WriteString(fmt.Sprintf("## 第%d章《%s》正文\n%s\n\n", params.Chapter, params.Title, truncateProse(params.Prose)))
+
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString("1. summary：本章发生了什么，100字以内\n")
+	prompt.WriteString(fmt.Sprintf("2. rolling_summary：融合此前梗概与本章内容，写成截至本章的故事梗概，不超过%d字；早期情节可以压缩，但不能丢失仍在影响后续的事件\n", RollingSummaryLength))
+	prompt.WriteString("3. state_deltas：本章中状态发生变化的角色，写明章末所在地、情绪及其强度（intensity，0-100，平静为10左右，崩溃、狂喜等极端情绪为90以上）、新得知的信息、关系变化和其他变化；没有变化的字段留空\n")
+	prompt.WriteString("4. vital_events：本章正文明确写出的死亡（death）、重伤（injury）、伤愈（recovery）和复活（resurrection），detail 写死因或伤情，evidence 摘录原句；轻伤、昏迷、假死和生死未卜不算\n")
+	prompt.WriteString("5. 已故角色在本章以活人身份出场（说话、行动），且正文没有交代复活或生还时，记为 reappeared；回忆、梦境、幻象、遗体和他人提及不算\n")
+	prompt.WriteString("6. item_transfers：上面列出的关键物品在本章中转手、遗失、被藏起或被毁时记录一条，item 用列表中的名称，to 为新持有人，不在人手中时写 location；没有变化的物品不要列出\n")
+	next := 7
+	if params.Cultivation != nil {
+		prompt.WriteString("7. realm_changes：本章正文明确写出的境界突破（breakthrough）和跌落、被废（regression），以及上面没有列出的角色首次写明的境界（initial）；realm 使用修真体系中的境界名，cost 写突破借助的资源、付出的代价或跨过瓶颈的经过，正文没写就留空\n")
+		next++
+	}
+	prompt.WriteString(fmt.Sprintf("%d. 只依据正文，不要推测后续情节\n\n", next))
+
+	prompt.WriteString("# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "summary": "本章摘要",
+  "rolling_summary": "截至本章的故事梗概",
+  "state_deltas": [
+    {
+      "character": "角色名",
+      "emotion": "章末情绪",
+      "intensity": 50,
+      "location": "章末所在地",
+      "learned": ["新得知的信息"],
+      "relationship": "关系变化",
+      "change": "其他变化"
+    }
+  ],
+  "vital_events": [
+    {"character": "角色名", "event": "death|injury|recovery|resurrection|reappeared", "detail": "死因或伤情", "evidence": "原句"}
+  ],
+  "item_transfers": [
+    {"item": "物品名", "from": "原持有人", "to": "新持有人", "location": "所在地", "note": "经过", "destroyed": false, "evidence": "原句"}
+  ],
+  "realm_changes": [
+    {"character": "角色名", "kind": "initial|breakthrough|regression", "realm": "境界", "cost": "代价或经过", "evidence": "原句"}
+  ]
+}`)
+
+	return prompt.String()
+}
+
+// truncateProse 正文过长时保留开头和结尾
+func truncateProse(prose string) string {
+	runes := []rune(prose)
+	if len(runes) <= maxSummaryProse {
+		return prose
+	}
+	half := maxSummaryProse / 2
+	return string(runes[:half]) + "\n……（中间略）……\n" + string(runes[len(runes)-half:])
+}
@@ -0,0 +1,144 @@
+// Package writer 写作器 - 主题覆盖
+// 每章写完后检查正文对核心主题的体现深度，以及象征和母题是否出现（字面或暗示）
+package writer
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+// themeTitle 主题覆盖分析提示词标题，同时作为模拟响应的标记
+const themeTitle = "主题覆盖分析\n"
+
+// themeResponse 主题覆盖分析的响应
+type themeResponse struct {
+	Expressed bool                     `json:"expressed"`
+	Depth     string                   `json:"depth"`
+	Evidence  string                   `json:"evidence"`
+	Symbols   []models.ThemeOccurrence `json:"symbols"`
+	Motifs    []models.ThemeOccurrence `json:"motifs"`
+}
+
+func init() {
+	llm.RegisterMock(themeTitle, themeResponse{Depth: models.ThemeDepthNone, Symbols: []models.ThemeOccurrence{}, Motifs: []models.ThemeOccurrence{}})
+}
+
+// ThemeParams 主题覆盖分析参数
+type ThemeParams struct {
+	Chapter int
+	Title   string
+	Prose   string                 // 本章正文
+	Plan    *models.ThemePlan      // 主题规划
+	Planned *models.ThemeThreading // 本章规划的主题表达，可为空
+}
+
+// AnalyzeTheme 分析本章正文对主题规划的体现
+// 象征和母题的字面出现次数直接统计，暗示性的出现和主题深度由LLM判断
+func (w *Writer) AnalyzeTheme(params ThemeParams) (*models.ThemeCoverage, error) {
+	if strings.TrimSpace(params.Prose) == "" {
+		return nil, fmt.Errorf("第%d章没有正文", params.Chapter)
+	}
+	if params.Plan == nil || params.Plan.Empty() {
+		return nil, fmt.Errorf("没有主题规划")
+	}
+
+	result, err := w.callForRole(roleTheme, buildThemePrompt(params),
+		"你是一位文学编辑，擅长分析小说的主题表达与象征手法。只认定正文中确有依据的内容。只输出JSON。")
+	if err != nil {
+		return nil, fmt.Errorf("主题覆盖分析失败: %w", err)
+	}
+
+	var out themeResponse
+	if err := jsonx.Unmarshal(result, &out); err != nil {
+		return nil, fmt.Errorf("解析主题覆盖分析失败: %w", err)
+	}
+
+	symbols := make([]string, 0, len(params.Plan.Symbols))
+	for _, s := range params.Plan.Symbols {
+		symbols = append(symbols, s.Name)
+	}
+	coverage := &models.ThemeCoverage{
+		Expressed:  out.Expressed,
+		Depth:      out.Depth,
+		Evidence:   out.Evidence,
+		Symbols:    mergeOccurrences(symbols, out.Symbols, params.Prose),
+		Motifs:     mergeOccurrences(params.Plan.Motifs, out.Motifs, params.Prose),
+		AnalyzedAt: time.Now(),
+	}
+	if models.ThemeDepthRank(coverage.Depth) == 0 {
+		coverage.Depth = models.ThemeDepthNone
+	}
+	if coverage.Depth == models.ThemeDepthNone {
+		coverage.Expressed = false
+	}
+	return coverage, nil
+}
+
+// mergeOccurrences 合并字面统计和LLM认定的出现，只保留规划中的名称
+func mergeOccurrences(names []string, reported []models.ThemeOccurrence, prose string) []models.ThemeOccurrence {
+	evidence := make(map[string]string, len(reported))
+	for _, o := range reported {
+		evidence[strings.TrimSpace(o.Name)] = o.Evidence
+	}
+
+	out := make([]models.ThemeOccurrence, 0)
+	for _, name := range names {
+		if name == "" {
+			continue
+		}
+		count := strings.Count(prose, name)
+		ev, ok := evidence[name]
+		if count == 0 && !ok {
+			continue
+		}
+		out = append(out, models.ThemeOccurrence{Name: name, Count: count, Evidence: ev})
+	}
+	return out
+}
+
+// buildThemePrompt 构建主题覆盖分析提示词
+func buildThemePrompt(params ThemeParams) string {
+	plan := params.Plan
+	var prompt strings.Builder
+
+	prompt.WriteString(fmt.Sprintf("# 第%d章《%s》%s\n", params.Chapter, params.Title, themeTitle))
+	prompt.WriteString("## 主题规划\n")
+	if plan.CoreTheme != "" {
+		prompt.WriteString(fmt.Sprintf("- 核心主题：%s\n", plan.CoreTheme))
+	}
+	if p := params.Planned; p != nil {
+		prompt.WriteString(fmt.Sprintf("- 本章计划的表达：%s（深度%s）\n", p.Expression, p.Depth))
+	}
+	if len(plan.Symbols) > 0 {
+		prompt.WriteString("- 象征：\n")
+		for _, s := range plan.Symbols {
+			prompt.WriteString(fmt.Sprintf("  - %s：%s\n", s.Name, s.Meaning))
+		}
+	}
+	if len(plan.Motifs) > 0 {
+		prompt.WriteString(fmt.Sprintf("- 母题：%s\n", strings.Join(plan.Motifs, "、")))
+	}
+
+	prompt.WriteString("\n## 正文\n")
+	prompt.WriteString(truncateProse(params.Prose))
+	prompt.WriteString("\n\n# 要求\n")
+	prompt.WriteString("1. depth 判断本章对核心主题的体现：none 没有体现；surface 台词或情节中提及；deep 角色的选择和代价体现主题；philosophical 对主题本身提出追问或给出回答\n")
+	prompt.WriteString("2. expressed 表示本章是否体现了核心主题，evidence 摘录最能体现主题的原文\n")
+	prompt.WriteString("3. symbols 和 motifs 列出本章出现的象征和母题，包括没有点名、以意象或情节暗示的出现；name 必须使用上面列出的名称，evidence 摘录原文\n")
+	prompt.WriteString("4. 没有出现的不要列出\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "expressed": true,
+  "depth": "surface",
+  "evidence": "原文片段",
+  "symbols": [{"name": "象征名称", "evidence": "原文片段"}],
+  "motifs": [{"name": "母题", "evidence": "原文片段"}]
+}`)
+	return prompt.String()
+}
@@ -0,0 +1,122 @@
+// Package writer 写作器 - 章节标题
+// 正文写完后按本章实际内容提出多个标题候选，并为每个候选的点击吸引力评分
+package writer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+const (
+	// titleTitle 标题生成提示词标题，同时作为模拟响应的标记
+	titleTitle = "章节标题生成\n"
+	// DefaultTitleCandidates 默认的标题候选数量
+	DefaultTitleCandidates = 5
+	// maxTitleProse 没有摘要时送入的正文上限（字）
+	maxTitleProse = 3000
+)
+
+// titleResponse 标题生成的响应
+type titleResponse struct {
+	Candidates []models.TitleCandidate `json:"candidates"`
+}
+
+func init() {
+	llm.RegisterMock(titleTitle, titleResponse{Candidates: []models.TitleCandidate{
+		{Title: "章节标题", Curiosity: 6, Specificity: 6, Fit: 7, Comment: "点评"},
+	}})
+}
+
+// TitleParams 章节标题生成参数
+type TitleParams struct {
+	Chapter      int
+	CurrentTitle string   // 当前标题，作为对照
+	Summary      string   // 本章摘要，优先使用
+	Prose        string   // 本章正文，没有摘要时截取使用
+	Neighbors    []string // 前后章节的标题，避免重复并保持风格统一
+	Count        int      // 候选数量，0 表示 DefaultTitleCandidates
+}
+
+// GenerateTitles 根据本章内容生成标题候选，按综合分从高到低排列
+func (w *Writer) GenerateTitles(params TitleParams) ([]models.TitleCandidate, error) {
+	if strings.TrimSpace(params.Summary) == "" && strings.TrimSpace(params.Prose) == "" {
+		return nil, fmt.Errorf("第%d章没有正文", params.Chapter)
+	}
+	if params.Count <= 0 {
+		params.Count = DefaultTitleCandidates
+	}
+
+	result, err := w.callForRole(roleChapterTitle, buildTitlePrompt(params),
+		"你是一位网络小说责任编辑，擅长起让读者忍不住点开的章节标题，但绝不做标题党。只输出JSON。")
+	if err != nil {
+		return nil, fmt.Errorf("生成章节标题失败: %w", err)
+	}
+
+	var out titleResponse
+	if err := jsonx.Unmarshal(result, &out); err != nil {
+		return nil, fmt.Errorf("解析章节标题失败: %w", err)
+	}
+
+	candidates := make([]models.TitleCandidate, 0, len(out.Candidates))
+	seen := make(map[string]bool)
+	for _, c := range out.Candidates {
+		c.Title = strings.Trim(strings.TrimSpace(c.Title), "《》\"“”")
+		if c.Title == "" || seen[c.Title] {
+			continue
+		}
+		seen[c.Title] = true
+		c.Score()
+		candidates = append(candidates, c)
+	}
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("第%d章没有生成可用的标题", params.Chapter)
+	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].Overall > candidates[j].Overall })
+	return candidates, nil
+}
+
+// buildTitlePrompt 构建章节标题提示词
+func buildTitlePrompt(params TitleParams) string {
+	var prompt strings.Builder
+
+	prompt.WriteString(fmt.Sprintf("# 第%d章%s", params.Chapter, titleTitle))
+	if params.CurrentTitle != "" {
+		prompt.WriteString(fmt.Sprintf("当前标题：%s\n\n", params.CurrentTitle))
+	}
+	if strings.TrimSpace(params.Summary) != "" {
+		prompt.WriteString(fmt.Sprintf("## 本章摘要\n%s\n\n", params.Summary))
+	} else {
+		prose := []rune(params.Prose)
+		if len(prose) > maxTitleProse {
+			prose = prose[:maxTitleProse]
+		}
+		prompt.WriteString(fmt.Sprintf("## 本章正文（节选）\n%s\n\n", string(prose)))
+	}
+	if len(params.Neighbors) > 0 {
+		prompt.WriteString("## 相邻章节标题\n")
+		for _, t := range params.Neighbors {
+			prompt.WriteString("- " + t + "\n")
+		}
+		prompt.WriteString("\n")
+	}
+
+	prompt.WriteString("# 要求\n")
+	prompt.WriteString(fmt.Sprintf("1. 给出%d个风格各异的标题，每个不超过15字，不带章节序号\n", params.Count))
+	prompt.WriteString("2. 标题必须来自本章实际发生的事，可以制造悬念，但不能剧透本章最关键的转折，也不能写正文没有的内容\n")
+	prompt.WriteString("3. 避免“风云再起”“危机四伏”这类放在哪一章都成立的空泛标题，也不要与相邻章节标题雷同\n")
+	prompt.WriteString("4. 为每个标题打分（0-10的整数）：curiosity 好奇心，specificity 具体度，fit 贴合度；comment 一句话点评\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "candidates": [
+    {"title": "标题", "curiosity": 7, "specificity": 8, "fit": 8, "comment": "点评"}
+  ]
+}`)
+
+	return prompt.String()
+}
@@ -0,0 +1,151 @@
+// Package writer 写作器 - 章节翻译
+// 按段落分批翻译定稿章节，译文与原文逐段对齐，供双语对照导出
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/locale"
+)
+
+const (
+	// translateTitle 翻译提示词标题，同时作为模拟响应的标记
+	translateTitle = "章节翻译\n"
+	// translateBatchRunes 每批翻译的原文字数上限，单段超过上限时单独成批
+	translateBatchRunes = 3000
+)
+
+// translateResponse 章节翻译的响应
+type translateResponse struct {
+	Title      string               `json:"title"`
+	Paragraphs []SanitizedParagraph `json:"paragraphs"`
+}
+
+func init() {
+	llm.RegisterMock(translateTitle, translateResponse{})
+}
+
+// TranslateParams 章节翻译参数
+type TranslateParams struct {
+	Chapter    int
+	Title      string
+	Paragraphs []string              // 章节的全部段落
+	Source     string                // 原文语言
+	Language   string                // 目标语言
+	Glossary   []models.GlossaryTerm // 可选，专有名词保持前后一致的译法
+}
+
+// TranslateResult 章节翻译结果
+type TranslateResult struct {
+	Title    string
+	Segments []models.TranslationSegment // 与原文段落一一对应
+	Missing  int                         // 模型漏译的段落数，对应译文为空
+}
+
+// TranslateChapter 按段落分批翻译章节
+// 输出语言要求按目标语言追加，与项目自身的输出语言无关
+func (w *Writer) TranslateChapter(params TranslateParams) (*TranslateResult, error) {
+	if len(params.Paragraphs) == 0 {
+		return nil, fmt.Errorf("第%d章没有正文", params.Chapter)
+	}
+	tw := w.WithLanguage(params.Language)
+	system := fmt.Sprintf("你是一位资深文学译者，把%s网络小说翻译为%s，忠实原意，保留人物语气和叙事节奏。只输出JSON。",
+		locale.Name(params.Source), locale.Name(params.Language))
+
+	result := &TranslateResult{Segments: make([]models.TranslationSegment, len(params.Paragraphs))}
+	for i, p := range params.Paragraphs {
+		result.Segments[i].Source = p
+	}
+
+	// 已翻译段落的最后一段作为下一批的上文
+	previous := ""
+	for _, batch := range translateBatches(params.Paragraphs) {
+		first := batch[0] == 0
+		raw, err := tw.callForRole(roleTranslate, buildTranslatePrompt(params, batch, previous, first), system)
+		if err != nil {
+			return nil, fmt.Errorf("翻译第%d章失败: %w", params.Chapter, err)
+		}
+		var out translateResponse
+		if err := jsonx.Unmarshal(raw, &out); err != nil {
+			return nil, fmt.Errorf("解析翻译结果失败: %w", err)
+		}
+		if first {
+			result.Title = strings.TrimSpace(out.Title)
+		}
+
+		start, end := batch[0]+1, batch[1]
+		for _, p := range out.Paragraphs {
+			if p.Paragraph < start || p.Paragraph > end {
+				continue
+			}
+			result.Segments[p.Paragraph-1].Target = strings.TrimSpace(p.Content)
+		}
+		previous = result.Segments[end-1].Target
+	}
+
+	for _, s := range result.Segments {
+		if s.Target == "" {
+			result.Missing++
+		}
+	}
+	return result, nil
+}
+
+// translateBatches 把段落按字数切分为批次，返回每批的 [起始下标, 结束下标)
+func translateBatches(paragraphs []string) [][2]int {
+	batches := make([][2]int, 0)
+	start, runes := 0, 0
+	for i, p := range paragraphs {
+		n := len([]rune(p))
+		if i > start && runes+n > translateBatchRunes {
+			batches = append(batches, [2]int{start, i})
+			start, runes = i, 0
+		}
+		runes += n
+	}
+	return append(batches, [2]int{start, len(paragraphs)})
+}
+
+// buildTranslatePrompt 构建章节翻译提示词
+func buildTranslatePrompt(params TranslateParams, batch [2]int, previous string, withTitle bool) string {
+	var prompt strings.Builder
+
+	prompt.WriteString(fmt.Sprintf("# 第%d章《%s》%s", params.Chapter, params.Title, translateTitle))
+	prompt.WriteString(fmt.Sprintf("原文语言: %s\n目标语言: %s\n\n", locale.Name(params.Source), locale.Name(params.Language)))
+
+	if len(params.Glossary) > 0 {
+		prompt.WriteString("## 专有名词（全书译法保持一致，境界名称按等级体系意译）\n")
+		for _, t := range params.Glossary {
+			prompt.WriteString(fmt.Sprintf("- %s（%s）\n", t.Term, t.Kind))
+		}
+		prompt.WriteString("\n")
+	}
+	if previous != "" {
+		prompt.WriteString(fmt.Sprintf("## 上一段译文（只用于衔接语气，不要重复翻译）\n%s\n\n", previous))
+	}
+
+	prompt.WriteString("## 原文段落\n")
+	for i := batch[0]; i < batch[1]; i++ {
+		prompt.WriteString(fmt.Sprintf("[%d] %s\n", i+1, params.Paragraphs[i]))
+	}
+
+	prompt.WriteString("\n# 要求\n")
+	prompt.WriteString("1. 逐段翻译，每个编号段落对应一段译文，不合并、不拆分、不遗漏\n")
+	prompt.WriteString("2. 对白保留引号和说话人的语气，成语和俗语意译\n")
+	prompt.WriteString("3. 不增删情节，不添加译者注\n")
+	if withTitle {
+		prompt.WriteString("4. 同时翻译章节标题，写入 title\n")
+	}
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "title": "译后章节标题",
+  "paragraphs": [{"paragraph": 1, "content": "译文"}]
+}`)
+
+	return prompt.String()
+}
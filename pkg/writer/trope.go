@@ -0,0 +1,262 @@
+// Package writer 写作器 - 套路检测
+// 内置常见网文套路库，由LLM扫描大纲和章节正文，按项目配置的灵敏度报告套路化的情节，可选给出改写建议
+package writer
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+const (
+	// tropeScanTitle 套路检测提示词标题，同时作为模拟响应的标记
+	tropeScanTitle = "套路检测\n"
+	// tropeRewriteTitle 套路改写建议提示词标题
+	tropeRewriteTitle = "套路改写建议\n"
+	// tropeScanRunes 单次检测送入提示词的最多字数
+	tropeScanRunes = 8000
+	// TropeOther 套路库之外的套路
+	TropeOther = "other"
+)
+
+// BuiltinTropes 内置套路库
+var BuiltinTropes = []models.Trope{
+	{ID: "broken_engagement", Name: "退婚流", Aliases: []string{"退婚", "莫欺少年穷"}, Description: "主角被未婚妻当众退婚受辱，立誓逆袭", Genres: []models.WorldType{models.WorldFantasy}, Weight: 0.9},
+	{ID: "amnesia", Name: "失忆", Aliases: []string{"失去记忆"}, Description: "关键人物失忆，用来制造误会或拖延真相", Weight: 0.8},
+	{ID: "chosen_one", Name: "天选之子", Aliases: []string{"预言之子", "命定之人"}, Description: "主角因预言或血脉注定拯救世界", Genres: []models.WorldType{models.WorldFantasy, models.WorldXianxia}, Weight: 0.7},
+	{ID: "trash_to_genius", Name: "废柴逆袭", Aliases: []string{"废材", "经脉尽断"}, Description: "主角开局被判定为无法修炼的废物，随后得到奇遇", Genres: []models.WorldType{models.WorldXianxia, models.WorldFantasy}, Weight: 0.8},
+	{ID: "grandpa_in_ring", Name: "随身老爷爷", Aliases: []string{"戒指老爷爷", "残魂"}, Description: "主角随身物品中寄居着强者残魂，提供指点", Genres: []models.WorldType{models.WorldXianxia, models.WorldFantasy}, Weight: 0.8},
+	{ID: "face_slapping", Name: "打脸", Aliases: []string{"装逼打脸", "扮猪吃虎"}, Description: "反派嘲讽主角，随后被主角当众碾压", Genres: []models.WorldType{models.WorldUrban, models.WorldXianxia, models.WorldFantasy}, Weight: 0.6},
+	{ID: "cliff_fall", Name: "跳崖奇遇", Aliases: []string{"坠崖"}, Description: "主角坠崖不死，反而得到秘籍或传承", Genres: []models.WorldType{models.WorldWuxia, models.WorldXianxia}, Weight: 0.8},
+	{ID: "secret_heir", Name: "隐藏身份", Aliases: []string{"隐藏大佬", "神秘身世"}, Description: "被轻视的主角真实身份是豪门继承人或大人物", Genres: []models.WorldType{models.WorldUrban}, Weight: 0.7},
+	{ID: "rebirth_revenge", Name: "重生复仇", Aliases: []string{"重生", "回到过去"}, Description: "主角带着前世记忆重生，逐一报复仇人", Weight: 0.6},
+	{ID: "system", Name: "系统金手指", Aliases: []string{"系统", "签到", "面板"}, Description: "主角获得发布任务、给予奖励的系统", Weight: 0.6},
+	{ID: "villain_monologue", Name: "反派话多", Aliases: []string{"死于话多"}, Description: "反派在占优时长篇大论，给主角反击的机会", Weight: 0.9},
+	{ID: "misunderstanding", Name: "误会拖剧情", Aliases: []string{"一句话能说清的误会"}, Description: "一句话就能澄清的误会被刻意拖延多章", Weight: 0.9},
+	{ID: "instant_love", Name: "一见钟情", Aliases: []string{"英雄救美"}, Description: "人物初见即深爱，缺少感情铺垫", Weight: 0.7},
+	{ID: "deus_ex_machina", Name: "天降救兵", Aliases: []string{"关键时刻突破", "神兵天降"}, Description: "危急关头靠突然突破或外援化解，而非人物自身的选择", Weight: 0.9},
+	{ID: "tournament_arc", Name: "比武大会", Aliases: []string{"宗门大比", "擂台赛"}, Description: "以比武或大比串起一连串对手", Genres: []models.WorldType{models.WorldWuxia, models.WorldXianxia, models.WorldFantasy}, Weight: 0.5},
+	{ID: "mentor_death", Name: "导师之死", Aliases: []string{"师父惨死"}, Description: "导师在主角成长前夕死去，成为复仇动机", Weight: 0.6},
+}
+
+// tropeScanResponse 套路检测的响应
+type tropeScanResponse struct {
+	Occurrences []models.TropeOccurrence `json:"occurrences"`
+}
+
+// tropeRewriteResponse 改写建议的响应，与输入的出现一一对应
+type tropeRewriteResponse struct {
+	Suggestions []string `json:"suggestions"`
+}
+
+func init() {
+	llm.RegisterMock(tropeScanTitle, tropeScanResponse{})
+	llm.RegisterMock(tropeRewriteTitle, tropeRewriteResponse{})
+}
+
+// TropeScanParams 套路检测参数
+type TropeScanParams struct {
+	Scope    string // outline/chapter
+	Chapter  int    // 章节正文的章节号
+	Text     string // 大纲文本或章节正文
+	Genre    models.WorldType
+	Settings models.TropeSettings
+}
+
+// FindTrope 按ID查找内置套路
+func FindTrope(id string) (models.Trope, bool) {
+	for _, t := range BuiltinTropes {
+		if t.ID == id {
+			return t, true
+		}
+	}
+	return models.Trope{}, false
+}
+
+// ScanTropes 检测文本中的套路，返回需要报告的出现和被配置过滤掉的数量
+func (w *Writer) ScanTropes(params TropeScanParams) ([]models.TropeOccurrence, int, error) {
+	if strings.TrimSpace(params.Text) == "" {
+		return []models.TropeOccurrence{}, 0, nil
+	}
+
+	result, err := w.callForRole(roleTropeScan, buildTropeScanPrompt(params),
+		"你是一位阅读量极大的网络小说编辑，对陈旧套路非常敏感，但只指出确实出现的套路，不牵强附会。只输出JSON。")
+	if err != nil {
+		return nil, 0, fmt.Errorf("套路检测失败: %w", err)
+	}
+
+	var out tropeScanResponse
+	if err := jsonx.Unmarshal(result, &out); err != nil {
+		return nil, 0, fmt.Errorf("解析套路检测结果失败: %w", err)
+	}
+
+	reported := make([]models.TropeOccurrence, 0, len(out.Occurrences))
+	suppressed := 0
+	for _, occ := range out.Occurrences {
+		occ.Scope = params.Scope
+		occ.Suggestion = ""
+		if params.Scope == models.TropeScopeChapter {
+			occ.Chapter = params.Chapter
+		}
+		trope, known := FindTrope(occ.TropeID)
+		if !known {
+			occ.TropeID = TropeOther
+			trope.Weight = 0.5
+		} else {
+			occ.Name = trope.Name
+		}
+		occ.Strength = max(1, min(10, occ.Strength))
+		occ.Severity = tropeSeverity(occ.Strength, trope.Weight)
+
+		if (known && params.Settings.IsAllowed(trope.ID)) ||
+			(known && !params.Settings.FlagGenreExpected && genreExpected(trope, params.Genre)) ||
+			!params.Settings.Reports(occ.Severity) {
+			suppressed++
+			continue
+		}
+		reported = append(reported, occ)
+	}
+	return reported, suppressed, nil
+}
+
+// SuggestTropeRewrites 为报告的套路出现生成改写建议，写入各出现的 Suggestion
+func (w *Writer) SuggestTropeRewrites(occurrences []models.TropeOccurrence) error {
+	if len(occurrences) == 0 {
+		return nil
+	}
+
+	result, err := w.callForRole(roleTropeRewrite, buildTropeRewritePrompt(occurrences),
+		"你是一位擅长推陈出新的网络小说策划，给出的改写方向要保留原情节的功能，同时避开套路。只输出JSON。")
+	if err != nil {
+		return fmt.Errorf("生成改写建议失败: %w", err)
+	}
+
+	var out tropeRewriteResponse
+	if err := jsonx.Unmarshal(result, &out); err != nil {
+		return fmt.Errorf("解析改写建议失败: %w", err)
+	}
+	for i := range occurrences {
+		if i < len(out.Suggestions) {
+			occurrences[i].Suggestion = out.Suggestions[i]
+		}
+	}
+	return nil
+}
+
+// tropeSeverity 由套路化程度和套路的陈旧程度计算严重程度
+func tropeSeverity(strength int, weight float64) string {
+	score := float64(strength) * (0.6 + 0.4*weight)
+	switch {
+	case score >= 7:
+		return models.TropeSeverityHigh
+	case score >= 4:
+		return models.TropeSeverityMedium
+	default:
+		return models.TropeSeverityLow
+	}
+}
+
+// genreExpected 套路是否在该题材的读者预期之中
+func genreExpected(trope models.Trope, genre models.WorldType) bool {
+	for _, g := range trope.Genres {
+		if g == genre {
+			return true
+		}
+	}
+	return false
+}
+
+// numberParagraphs 为正文段落编号，超出字数上限的部分截断
+func numberParagraphs(text string) string {
+	var b strings.Builder
+	n := 0
+	for _, p := range strings.Split(text, "\n") {
+		if p = strings.TrimSpace(p); p == "" {
+			continue
+		}
+		n++
+		line := fmt.Sprintf("[%d] %s\n", n, p)
+		if len([]rune(b.String()))+len([]rune(line)) > tropeScanRunes {
+			b.WriteString("……（后文略）\n")
+			break
+		}
+		b.WriteString(line)
+	}
+	return b.String()
+}
+
+// buildTropeScanPrompt 构建套路检测提示词
+func buildTropeScanPrompt(params TropeScanParams) string {
+	var prompt strings.Builder
+
+	if params.Scope == models.TropeScopeChapter {
+		prompt.WriteString(fmt.Sprintf("# 第%d章%s", params.Chapter, tropeScanTitle))
+		prompt.WriteString("## 正文（[n] 为段落序号）\n")
+		prompt.WriteString(numberParagraphs(params.Text))
+	} else {
+		prompt.WriteString("# 大纲" + tropeScanTitle)
+		prompt.WriteString("## 大纲与章节规划\n")
+		runes := []rune(params.Text)
+		if len(runes) > tropeScanRunes {
+			runes = runes[:tropeScanRunes]
+		}
+		prompt.WriteString(string(runes) + "\n")
+	}
+
+	prompt.WriteString("\n## 套路库\n")
+	for _, t := range BuiltinTropes {
+		if params.Settings.IsAllowed(t.ID) {
+			continue
+		}
+		prompt.WriteString(fmt.Sprintf("- %s（%s）: %s", t.ID, t.Name, t.Description))
+		if len(t.Aliases) > 0 {
+			prompt.WriteString(fmt.Sprintf("，又称%s", strings.Join(t.Aliases, "、")))
+		}
+		prompt.WriteString("\n")
+	}
+
+	prompt.WriteString("\n# 要求\n")
+	prompt.WriteString("1. 找出上文中出现的套路，trope_id 使用套路库中的ID；套路库之外但明显陈旧的套路，trope_id 填 other 并在 name 中命名\n")
+	prompt.WriteString("2. strength 套路化程度（1-10）：照搬常见写法、毫无变化为高分，对套路有明显颠覆或新意为低分\n")
+	if params.Scope == models.TropeScopeChapter {
+		prompt.WriteString("3. paragraph 为出现的段落序号，excerpt 摘录原文（不超过50字）\n")
+	} else {
+		prompt.WriteString("3. chapter 为出现的章节号（出现在总体大纲中时填0），excerpt 摘录原文（不超过50字）\n")
+	}
+	prompt.WriteString("4. reason 说明为什么判为套路；没有套路时返回空数组\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "occurrences": [{"trope_id": "broken_engagement", "name": "退婚流", "chapter": 1, "paragraph": 3, "excerpt": "原文摘录", "strength": 8, "reason": "原因"}]
+}`)
+
+	return prompt.String()
+}
+
+// buildTropeRewritePrompt 构建改写建议提示词
+func buildTropeRewritePrompt(occurrences []models.TropeOccurrence) string {
+	var prompt strings.Builder
+
+	prompt.WriteString("# " + tropeRewriteTitle)
+	for i, occ := range occurrences {
+		location := "大纲"
+		if occ.Chapter > 0 {
+			location = fmt.Sprintf("第%d章", occ.Chapter)
+		}
+		prompt.WriteString(fmt.Sprintf("%d. [%s] %s：%s（%s）\n", i+1, location, occ.Name, occ.Excerpt, occ.Reason))
+	}
+
+	prompt.WriteString("\n# 要求\n")
+	prompt.WriteString("按顺序为每一处套路给出一条改写建议：保留这段情节在故事中的作用（如激励事件、动机、反转），换一种不落俗套的实现方式，具体到可以直接动笔\n")
+	prompt.WriteString("suggestions 的数量和顺序必须与上面的列表一致\n")
+
+	prompt.WriteString("\n# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "suggestions": ["改写建议"]
+}`)
+
+	return prompt.String()
+}
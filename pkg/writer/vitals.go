@@ -0,0 +1,59 @@
+// Package writer 写作器 - 生死约束
+// 根据生死伤势台账，在场景提示词中列出已故和重伤未愈的角色
+package writer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+)
+
+// maxVitalPromptDeceased 场景提示词中列出的已故角色上限（取最近死亡的）
+const maxVitalPromptDeceased = 20
+
+// BuildVitalPrompt 构建场景提示词中的生死约束：已故角色不得以活人身份出场，出场角色的重伤须体现在描写中
+func BuildVitalPrompt(states []models.VitalState, characters []string) string {
+	if len(states) == 0 {
+		return ""
+	}
+	inScene := make(map[string]bool, len(characters))
+	for _, c := range characters {
+		inScene[c] = true
+	}
+
+	dead := make([]models.VitalState, 0)
+	var injured strings.Builder
+	for _, s := range states {
+		switch s.Status {
+		case models.StatusDead:
+			dead = append(dead, s)
+		case models.StatusInjured:
+			if inScene[s.Character] {
+				injured.WriteString(fmt.Sprintf("- %s 重伤未愈（第%d章）：%s\n", s.Character, s.Since, s.Detail))
+			}
+		}
+	}
+	sort.SliceStable(dead, func(i, j int) bool { return dead[i].Since > dead[j].Since })
+	if len(dead) > maxVitalPromptDeceased {
+		dead = dead[:maxVitalPromptDeceased]
+	}
+
+	var sb strings.Builder
+	if len(dead) > 0 {
+		sb.WriteString("## 已故角色\n")
+		for _, s := range dead {
+			sb.WriteString(fmt.Sprintf("- %s（第%d章身亡", s.Character, s.Since))
+			if s.Detail != "" {
+				sb.WriteString("，" + s.Detail)
+			}
+			sb.WriteString("）\n")
+		}
+		sb.WriteString("以上角色已经死亡，只能出现在回忆、梦境、遗物或他人的谈论中，不得说话、行动或以活人身份出场。\n\n")
+	}
+	if injured.Len() > 0 {
+		sb.WriteString("## 伤势\n" + injured.String() + "描写这些角色的行动时要体现伤势的限制。\n\n")
+	}
+	return sb.String()
+}
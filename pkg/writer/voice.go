@@ -0,0 +1,179 @@
+// Package writer 写作器 - 对话语音一致性
+// 从章节正文中抽样台词，对照说话者的语音档案检查用词、语体和口头禅是否走样
+package writer
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/xlei/xupu/internal/models"
+	"github.com/xlei/xupu/pkg/jsonx"
+	"github.com/xlei/xupu/pkg/llm"
+)
+
+const (
+	// DefaultVoiceSampleSize 默认每章抽样的台词数
+	DefaultVoiceSampleSize = 24
+	// voiceContextRunes 每句台词附带的前文字数，供审稿判断说话者
+	voiceContextRunes = 40
+)
+
+// voiceCheckResponse 对话语音检查的响应
+type voiceCheckResponse struct {
+	Issues []models.VoiceIssue `json:"issues"`
+}
+
+func init() {
+	llm.RegisterMock("对话语音检查\n", voiceCheckResponse{Issues: []models.VoiceIssue{}})
+}
+
+// DialogueSample 抽样的台词
+type DialogueSample struct {
+	Line    string `json:"line"`    // 台词
+	Context string `json:"context"` // 台词前的叙述，通常包含说话者
+}
+
+// VoiceCheckParams 对话语音检查参数
+type VoiceCheckParams struct {
+	Chapter    int
+	Prose      string                          // 章节正文
+	Profiles   map[string]*models.VoiceProfile // 角色名 -> 语音档案
+	SampleSize int                             // 抽样台词数，0使用默认值
+}
+
+// CheckVoice 抽样台词并检查与说话者语音档案的一致性，返回不符的台词
+func (w *Writer) CheckVoice(params VoiceCheckParams) ([]models.VoiceIssue, error) {
+	profiles := make(map[string]*models.VoiceProfile, len(params.Profiles))
+	for name, p := range params.Profiles {
+		if !p.Empty() {
+			profiles[name] = p
+		}
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("没有可用的语音档案")
+	}
+
+	size := params.SampleSize
+	if size <= 0 {
+		size = DefaultVoiceSampleSize
+	}
+	samples := SampleDialogue(params.Prose, size)
+	if len(samples) == 0 {
+		return []models.VoiceIssue{}, nil
+	}
+
+	result, err := w.callForRole(roleVoiceCheck, buildVoiceCheckPrompt(params.Chapter, samples, profiles),
+		"你是一位细致的小说编辑，负责检查角色台词是否符合其一贯的说话方式。只输出JSON。")
+	if err != nil {
+		return nil, fmt.Errorf("对话语音检查失败: %w", err)
+	}
+
+	var out voiceCheckResponse
+	if err := jsonx.Unmarshal(result, &out); err != nil {
+		return nil, fmt.Errorf("解析对话语音检查结果失败: %w", err)
+	}
+
+	// 只保留有档案的说话者，避免审稿员对未建档角色下结论
+	issues := make([]models.VoiceIssue, 0, len(out.Issues))
+	for _, issue := range out.Issues {
+		if _, ok := profiles[issue.Speaker]; ok && issue.Line != "" {
+			issues = append(issues, issue)
+		}
+	}
+	return issues, nil
+}
+
+// SampleDialogue 提取正文中的引号台词，超过 n 句时均匀抽样
+func SampleDialogue(prose string, n int) []DialogueSample {
+	runes := []rune(prose)
+	samples := make([]DialogueSample, 0)
+
+	open := -1
+	for i, r := range runes {
+		switch r {
+		case '“', '「':
+			open = i
+		case '”', '」':
+			if open < 0 {
+				continue
+			}
+			line := strings.TrimSpace(string(runes[open+1 : i]))
+			if len([]rune(line)) >= 2 {
+				start := max(0, open-voiceContextRunes)
+				samples = append(samples, DialogueSample{
+					Line:    line,
+					Context: strings.TrimSpace(string(runes[start:open])),
+				})
+			}
+			open = -1
+		}
+	}
+
+	if n <= 0 || len(samples) <= n {
+		return samples
+	}
+	picked := make([]DialogueSample, 0, n)
+	step := float64(len(samples)) / float64(n)
+	for i := 0; i < n; i++ {
+		picked = append(picked, samples[int(float64(i)*step)])
+	}
+	return picked
+}
+
+// BuildVoiceProfilePrompt 构建出场角色的语音档案提示，角色没有档案时返回空
+func BuildVoiceProfilePrompt(characters []string, profiles map[string]*models.VoiceProfile) string {
+	var sb strings.Builder
+	for _, name := range characters {
+		if desc := profiles[name].Describe(); desc != "" {
+			sb.WriteString(fmt.Sprintf("- %s：%s\n", name, desc))
+		}
+	}
+	if sb.Len() == 0 {
+		return ""
+	}
+	return "## 角色语音\n" + sb.String() + "对话必须符合各角色的说话方式。\n\n"
+}
+
+// buildVoiceCheckPrompt 构建对话语音检查提示词
+func buildVoiceCheckPrompt(chapter int, samples []DialogueSample, profiles map[string]*models.VoiceProfile) string {
+	var prompt strings.Builder
+
+	prompt.WriteString(fmt.Sprintf("# 第%d章对话语音检查\n\n", chapter))
+
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	prompt.WriteString("## 角色语音档案\n")
+	for _, name := range names {
+		prompt.WriteString(fmt.Sprintf("- %s：%s\n", name, profiles[name].Describe()))
+	}
+
+	prompt.WriteString("\n## 抽样台词\n")
+	for i, s := range samples {
+		prompt.WriteString(fmt.Sprintf("%d. （%s）“%s”\n", i+1, s.Context, s.Line))
+	}
+
+	prompt.WriteString("\n# 要求\n")
+	prompt.WriteString("1. 根据台词前的叙述判断说话者，判断不出或说话者不在档案中的台词跳过\n")
+	prompt.WriteString("2. 只标记明显不符合说话者档案的台词：语体错位、用了该角色不会说的词、句式与习惯相反等\n")
+	prompt.WriteString("3. 情绪激动时的合理变化不算问题\n")
+	prompt.WriteString("4. 每条问题给出符合档案的改写建议\n\n")
+
+	prompt.WriteString("# 输出格式（JSON）\n")
+	prompt.WriteString(`{
+  "issues": [
+    {
+      "speaker": "说话者（与档案中的角色名一致）",
+      "line": "台词原文",
+      "problem": "不符之处",
+      "suggestion": "改写建议"
+    }
+  ]
+}`)
+
+	return prompt.String()
+}
@@ -4,14 +4,20 @@ package writer
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strings"
 	"time"
 
 	"github.com/xlei/xupu/internal/models"
 	"github.com/xlei/xupu/pkg/config"
 	"github.com/xlei/xupu/pkg/db"
+	"github.com/xlei/xupu/pkg/glossary"
+	"github.com/xlei/xupu/pkg/jsonx"
 	"github.com/xlei/xupu/pkg/llm"
+	"github.com/xlei/xupu/pkg/locale"
+	"github.com/xlei/xupu/pkg/logx"
 )
 
 // GenerateParams 生成参数
@@ -21,9 +27,59 @@ type GenerateParams struct {
 	Scene            int               // 场景号
 	Instruction      *models.SceneInstruction // 场景指令
 	PreviousSummary  string            // 前情摘要
+	Memories         []string          // 检索召回的相关记忆（前情、角色事实、世界设定）
 	CharacterStates  map[string]*CharacterContext // 角色状态
 	WorldContext     *models.WorldSetting // 世界设定上下文
 	Style            StyleConfig       // 风格配置
+	TargetWordCount  int               // 目标字数，0表示使用场景指令的预期长度
+	SceneID          string            // 覆盖已有场景输出时指定，为空则生成新ID
+	StyleProfile     *models.StyleProfile // 项目风格档案（可选）
+	Checklist        *SceneChecklist   // 审稿清单（可选），为空时不做自我修订
+	MaxRevisions     int               // 审稿不通过时最多重写轮数，0表示不修订
+	VoiceProfiles    map[string]*models.VoiceProfile // 角色名 -> 语音档案（可选）
+	POVConstraint    string            // 蓝图视角策略对本场景的约束（可选）
+	ContinuityFacts  []models.ContinuityFact // 写本章时仍有效的物理和状态细节（可选）
+	VitalStates      []models.VitalState     // 本章开始时已故或重伤未愈的角色（可选）
+	ItemHoldings     []models.ItemHolding    // 本章开始时关键物品的去向（可选）
+	RealmStates      []models.RealmState     // 本章开始时各角色的修为境界（可选，用于战斗场景）
+	RomanceArcs      []models.RomanceArc     // 蓝图的感情线节拍（可选）
+}
+
+// targetWordCount 场景目标字数
+func (p GenerateParams) targetWordCount() int {
+	if p.TargetWordCount > 0 {
+		return p.TargetWordCount
+	}
+	if p.Instruction != nil {
+		return p.Instruction.ExpectedLength
+	}
+	return 0
+}
+
+// maxSceneMinorCharacters 每个场景提示词中列出的龙套上限
+const maxSceneMinorCharacters = 6
+
+// buildMinorCharacterPrompt 场景地点可用的龙套角色，需要路人时从中选用，不临时起名
+func buildMinorCharacterPrompt(world *models.WorldSetting, location string) string {
+	if world == nil {
+		return ""
+	}
+	minors := world.MinorCharactersAt(location, maxSceneMinorCharacters)
+	if len(minors) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("## 可用龙套\n")
+	sb.WriteString("场景需要路人、店家、差役等次要人物时，从以下角色中选用，沿用其姓名和身份，不要另起名字：\n")
+	for _, m := range minors {
+		line := fmt.Sprintf("- %s：%s", m.Name, m.Role)
+		if m.Trait != "" {
+			line += "（" + m.Trait + "）"
+		}
+		sb.WriteString(line + "\n")
+	}
+	sb.WriteString("\n")
+	return sb.String()
 }
 
 // CharacterContext 角色上下文
@@ -34,6 +90,10 @@ type CharacterContext struct {
 	Location      string            `json:"location"`
 	Knowledge     []string          `json:"knowledge"`
 	Relationships map[string]string `json:"relationships"` // 与其他角色的关系
+	Role          string            `json:"role,omitempty"`    // 角色定位
+	Desire        string            `json:"desire,omitempty"`  // 表层欲望
+	Fear          string            `json:"fear,omitempty"`    // 最大恐惧
+	Secrets       []string          `json:"secrets,omitempty"` // 秘密（不可在场景中直接揭露）
 }
 
 // StyleConfig 风格配置
@@ -75,6 +135,9 @@ type GenerationMetadata struct {
 	GeneratedAt    time.Time `json:"generated_at"`
 	TokensUsed     int       `json:"tokens_used"`
 	RetryCount     int       `json:"retry_count"`
+	Revisions      int              `json:"revisions"`                // 审稿后重写轮数
+	Violations     []SceneViolation `json:"violations,omitempty"`    // 重写后仍未解决的违规
+	TermCorrections []models.GlossaryCorrection `json:"term_corrections,omitempty"` // 生成后校正的术语写法
 }
 
 // Writer 写作器
@@ -83,11 +146,15 @@ type Writer struct {
 	cfg     *config.Config
 	client  *llm.Client
 	mapping *config.ModuleMapping
+	logger  *slog.Logger
+	lang    string // 输出语言，为空时为简体中文
+	meter   *llm.Meter
+	budget  llm.Budget
 }
 
 // New 创建写作器
 func New() (*Writer, error) {
-	cfg, err := config.Load("config/config.yaml")
+	cfg, err := config.Current()
 	if err != nil {
 		return nil, fmt.Errorf("加载配置失败: %w", err)
 	}
@@ -105,6 +172,73 @@ func New() (*Writer, error) {
 	}, nil
 }
 
+// WithLogger 返回使用指定日志的写作器副本，LLM客户端一并切换，用于按请求/任务串联日志
+func (w *Writer) WithLogger(l *slog.Logger) *Writer {
+	cp := *w
+	cp.logger = l
+	if w.client != nil {
+		cp.client = w.client.WithLogger(l)
+	}
+	return &cp
+}
+
+// WithMeter 返回把LLM用量记到指定计量器的写作器副本
+func (w *Writer) WithMeter(m *llm.Meter) *Writer {
+	cp := *w
+	cp.meter = m
+	if w.client != nil {
+		cp.client = w.client.WithMeter(m)
+	}
+	return &cp
+}
+
+// WithBudget 返回请求前检查指定预算的写作器副本
+func (w *Writer) WithBudget(b llm.Budget) *Writer {
+	cp := *w
+	cp.budget = b
+	if w.client != nil {
+		cp.client = w.client.WithBudget(b)
+	}
+	return &cp
+}
+
+// decorate 为另建的LLM客户端（如章节流水线各阶段的模块）套上与写作器相同的日志、计量、预算和输出语言
+func (w *Writer) decorate(client *llm.Client) *llm.Client {
+	if w.logger != nil {
+		client = client.WithLogger(w.logger)
+	}
+	if w.meter != nil {
+		client = client.WithMeter(w.meter)
+	}
+	if w.budget != nil {
+		client = client.WithBudget(w.budget)
+	}
+	if w.lang != "" {
+		client = client.WithInstruction(locale.Instruction(w.lang))
+	}
+	return client
+}
+
+// WithLanguage 返回按指定语言输出的写作器副本，字数按该语言的习惯计算
+func (w *Writer) WithLanguage(lang string) *Writer {
+	cp := *w
+	cp.lang = lang
+	if w.client != nil {
+		cp.client = w.client.WithInstruction(locale.Instruction(lang))
+	}
+	return &cp
+}
+
+// countWords 按输出语言计字数
+func (w *Writer) countWords(text string) int {
+	return locale.CountWords(w.lang, text)
+}
+
+// log 当前使用的日志
+func (w *Writer) log() *slog.Logger {
+	return logx.Or(w.logger)
+}
+
 // GenerateScene 生成场景内容
 func (w *Writer) GenerateScene(params GenerateParams) (*SceneGenerationResult, error) {
 	startTime := time.Now()
@@ -119,31 +253,51 @@ func (w *Writer) GenerateScene(params GenerateParams) (*SceneGenerationResult, e
 	systemPrompt := w.buildSystemPrompt(params.Style)
 
 	// 调用LLM生成
-	result, err := w.callWithRetry(prompt, systemPrompt)
+	result, err := w.callForRole(roleScene, prompt, systemPrompt)
 	if err != nil {
 		return nil, fmt.Errorf("LLM调用失败: %w", err)
 	}
 
 	// 解析结果
 	generated := &GeneratedScene{}
-	if err := json.Unmarshal([]byte(result), &generated); err != nil {
-		// 如果JSON解析失败，尝试提取纯文本
-		extracted := extractJSON(result)
-		if err := json.Unmarshal([]byte(extracted), &generated); err != nil {
-			// 如果还是失败，将整个结果作为内容
-			generated = &GeneratedScene{
-				Content:       result,
-				WordCount:     len(strings.Fields(result)),
-				Tone:          params.Style.Tone,
-				POVCharacter:  params.Instruction.POVCharacter,
-				StateChanges:  models.StateUpdates{},
-			}
+	if err := jsonx.Unmarshal(result, &generated); err != nil {
+		// 如果还是失败，将整个结果作为内容
+		generated = &GeneratedScene{
+			Content:       result,
+			WordCount:     len(strings.Fields(result)),
+			Tone:          params.Style.Tone,
+			POVCharacter:  params.Instruction.POVCharacter,
+			StateChanges:  models.StateUpdates{},
 		}
 	}
 
+	// 自我修订：对照场景细纲审稿，不通过时附上审稿意见重写
+	revised, revisions, violations := w.reviseScene(generated.Content, params)
+	generated.Content = revised
+
+	// 字数控制：偏离目标超过容差时扩写或精简
+	target := params.targetWordCount()
+	content, adjustments := w.enforceLength(generated.Content, target, params)
+	if adjustments > 0 {
+		generated.Content = content
+	}
+	// 术语校正：已登记的错误写法和近似写法改为术语表中的规范写法
+	var termCorrections []models.GlossaryCorrection
+	if params.WorldContext != nil && len(params.WorldContext.Glossary) > 0 {
+		generated.Content, termCorrections = glossary.Normalize(generated.Content, params.WorldContext.Glossary)
+	}
+
+	if target > 0 || revisions > 0 || len(termCorrections) > 0 {
+		generated.WordCount = w.countWords(generated.Content)
+	}
+
 	// 创建输出结果
+	sceneID := params.SceneID
+	if sceneID == "" {
+		sceneID = db.GenerateID("scene")
+	}
 	output := &SceneGenerationResult{
-		ID:        db.GenerateID("scene"),
+		ID:        sceneID,
 		Content:   generated.Content,
 		WordCount: generated.WordCount,
 		Metadata: GenerationMetadata{
@@ -152,7 +306,10 @@ func (w *Writer) GenerateScene(params GenerateParams) (*SceneGenerationResult, e
 			Style:       params.Style.Voice,
 			GeneratedAt: startTime,
 			TokensUsed:  len([]rune(result)),
-			RetryCount:  0,
+			RetryCount:  adjustments,
+			Revisions:   revisions,
+			Violations:  violations,
+			TermCorrections: termCorrections,
 		},
 		StateUpdates: generated.StateChanges,
 	}
@@ -208,23 +365,55 @@ func (w *Writer) buildScenePrompt(params GenerateParams) string {
 	prompt.WriteString(fmt.Sprintf("- 目的: %s\n", params.Instruction.Purpose))
 	prompt.WriteString(fmt.Sprintf("- 地点: %s\n", params.Instruction.Location))
 	prompt.WriteString(fmt.Sprintf("- 氛围: %s\n", params.Instruction.Mood))
-	prompt.WriteString(fmt.Sprintf("- 预期长度: %d 字\n\n", params.Instruction.ExpectedLength))
+	prompt.WriteString(fmt.Sprintf("- 预期长度: %d 字\n\n", params.targetWordCount()))
 
 	// 前情摘要
 	if params.PreviousSummary != "" {
 		prompt.WriteString(fmt.Sprintf("## 前情摘要\n%s\n\n", params.PreviousSummary))
 	}
 
+	// 相关记忆
+	if len(params.Memories) > 0 {
+		prompt.WriteString("## 相关记忆\n")
+		for _, m := range params.Memories {
+			prompt.WriteString("- " + m + "\n")
+		}
+		prompt.WriteString("\n")
+	}
+
 	// 角色信息
 	prompt.WriteString(fmt.Sprintf("## 出场角色\n"))
 	if len(params.Instruction.Characters) > 0 {
 		for _, charID := range params.Instruction.Characters {
 			if charCtx, exists := params.CharacterStates[charID]; exists {
-				prompt.WriteString(fmt.Sprintf("- %s: 当前情绪=%s\n", charCtx.Name, charCtx.CurrentEmotion))
+				prompt.WriteString(fmt.Sprintf("- %s: 当前情绪=%s", charCtx.Name, charCtx.CurrentEmotion))
+				if charCtx.Role != "" {
+					prompt.WriteString(fmt.Sprintf("，定位=%s", charCtx.Role))
+				}
+				if charCtx.Desire != "" {
+					prompt.WriteString(fmt.Sprintf("，想要=%s", charCtx.Desire))
+				}
+				if charCtx.Fear != "" {
+					prompt.WriteString(fmt.Sprintf("，恐惧=%s", charCtx.Fear))
+				}
+				prompt.WriteString("\n")
+				if len(charCtx.Secrets) > 0 {
+					prompt.WriteString(fmt.Sprintf("  秘密（只可暗示，不要直接揭露）：%s\n", strings.Join(charCtx.Secrets, "；")))
+				}
 			}
 		}
 	}
 	prompt.WriteString("\n")
+	prompt.WriteString(BuildVoiceProfilePrompt(params.Instruction.Characters, params.VoiceProfiles))
+	prompt.WriteString(buildMinorCharacterPrompt(params.WorldContext, params.Instruction.Location))
+	prompt.WriteString(BuildContinuityPrompt(params.ContinuityFacts, params.Instruction.Characters,
+		params.Instruction.Location, params.Instruction.Purpose, params.Instruction.Action))
+	prompt.WriteString(BuildVitalPrompt(params.VitalStates, params.Instruction.Characters))
+	prompt.WriteString(BuildItemPrompt(params.ItemHoldings, params.Instruction.Characters, params.Instruction.Location,
+		params.Instruction.Purpose, params.Instruction.Action))
+	prompt.WriteString(BuildRealmPrompt(params.RealmStates, params.WorldContext.Cultivation(), params.Instruction))
+	prompt.WriteString(BuildChoreographyPrompt(params.Instruction.Choreography))
+	prompt.WriteString(BuildRomancePrompt(params.RomanceArcs, params.Instruction.Chapter, params.Instruction.Characters))
 
 	// 场景动作
 	if params.Instruction.Action != "" {
@@ -239,9 +428,16 @@ func (w *Writer) buildScenePrompt(params GenerateParams) string {
 	// 风格要求
 	prompt.WriteString(fmt.Sprintf("## 风格要求\n"))
 	prompt.WriteString(fmt.Sprintf("- 叙述视角: %s\n", voiceDescription(params.Style.Voice)))
+	if params.Instruction.POVCharacter != "" {
+		prompt.WriteString(fmt.Sprintf("- 视角角色: %s\n", params.Instruction.POVCharacter))
+	}
+	if params.POVConstraint != "" {
+		prompt.WriteString(fmt.Sprintf("- 视角约束: %s\n", params.POVConstraint))
+	}
 	prompt.WriteString(fmt.Sprintf("- 基调: %s\n", params.Style.Tone))
 	prompt.WriteString(fmt.Sprintf("- 节奏: %s\n", pacingDescription(params.Style.Pacing)))
 	prompt.WriteString(fmt.Sprintf("- 对话占比: %.0f%%\n\n", params.Style.DialogueRatio*100))
+	prompt.WriteString(BuildStyleProfilePrompt(params.StyleProfile))
 
 	// 世界背景信息
 	if params.WorldContext != nil {
@@ -251,6 +447,9 @@ func (w *Writer) buildScenePrompt(params GenerateParams) string {
 			prompt.WriteString(fmt.Sprintf("- 主要区域: %s\n", getRegionNames(params.WorldContext.Geography.Regions)))
 		}
 		prompt.WriteString("\n")
+		prompt.WriteString(BuildGlossaryPrompt(params.WorldContext.Glossary,
+			params.Instruction.Purpose, params.Instruction.Location, params.Instruction.Action,
+			params.PreviousSummary, strings.Join(params.Memories, "\n")))
 	}
 
 	// 输出格式要求
@@ -339,12 +538,19 @@ func getRegionNames(regions []models.Region) string {
 
 // callWithRetry 调用LLM并重试
 func (w *Writer) callWithRetry(prompt, systemPrompt string) (string, error) {
-	retryConfig := w.cfg.System.Retry
+	return w.callForRole("", prompt, systemPrompt)
+}
+
+// callForRole 调用LLM并重试，响应按提示词角色注册的 Schema 校验；
+// 修复后仍未通过校验时不再重试，直接返回错误
+func (w *Writer) callForRole(role, prompt, systemPrompt string) (string, error) {
+	retryConfig := config.Get().System.Retry // 每次读取全局配置，热加载后立即生效
 	maxAttempts := retryConfig.MaxAttempts
 	var lastErr error
 
 	for attempt := 1; attempt <= maxAttempts; attempt++ {
-		result, err := w.client.GenerateJSONWithParams(
+		result, err := w.client.GenerateJSONForRole(
+			role,
 			prompt,
 			systemPrompt,
 			w.mapping.Temperature,
@@ -359,6 +565,10 @@ func (w *Writer) callWithRetry(prompt, systemPrompt string) (string, error) {
 			return string(jsonBytes), nil
 		}
 
+		var schemaErr *llm.SchemaError
+		if errors.As(err, &schemaErr) {
+			return "", err
+		}
 		lastErr = err
 
 		if attempt < maxAttempts {
@@ -372,71 +582,3 @@ func (w *Writer) callWithRetry(prompt, systemPrompt string) (string, error) {
 
 	return "", fmt.Errorf("LLM调用失败（重试%d次后）: %w", maxAttempts, lastErr)
 }
-
-// extractJSON 从文本中提取JSON
-func extractJSON(s string) string {
-	// 查找 ```json```
-	start := -1
-	end := -1
-
-	jsonStart := []byte("```json")
-	if idx := indexOf(s, jsonStart); idx >= 0 {
-		start = idx + len(jsonStart)
-		if idx := indexOf(s[start:], []byte("```")); idx >= 0 {
-			end = start + idx
-			return s[start:end]
-		}
-	}
-
-	// 查找 ````
-	if idx := indexOf(s, []byte("```")); idx >= 0 {
-		start = idx + 3
-		if idx := indexOf(s[start:], []byte("```")); idx >= 0 {
-			end = start + idx
-			return s[start:end]
-		}
-	}
-
-	// 查找 { }
-	if idx := indexOf(s, []byte("{")); idx >= 0 {
-		start = idx
-		if idx := lastIndexOf(s, []byte("}")); idx >= 0 {
-			end = idx + 1
-			return s[start:end]
-		}
-	}
-
-	return s
-}
-
-func indexOf(s string, sep []byte) int {
-	for i := 0; i <= len(s)-len(sep); i++ {
-		match := true
-		for j := 0; j < len(sep); j++ {
-			if s[i+j] != sep[j] {
-				match = false
-				break
-			}
-		}
-		if match {
-			return i
-		}
-	}
-	return -1
-}
-
-func lastIndexOf(s string, sep []byte) int {
-	for i := len(s) - len(sep); i >= 0; i-- {
-		match := true
-		for j := 0; j < len(sep); j++ {
-			if s[i+j] != sep[j] {
-				match = false
-				break
-			}
-		}
-		if match {
-			return i
-		}
-	}
-	return -1
-}
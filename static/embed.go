@@ -0,0 +1,11 @@
+// Package static 前端静态资源，编译进 API 服务的二进制文件
+//
+// uploads/ 为运行时上传目录，不编译进二进制，由服务从磁盘读取
+package static
+
+import "embed"
+
+// FS 内置的前端资源
+//
+//go:embed index.html fanqie_test.html css js assets
+var FS embed.FS